@@ -0,0 +1,15 @@
+package main
+
+import (
+	"os"
+
+	"github.com/eljojo/rememory/internal/recovercmd"
+)
+
+var version = "dev"
+
+func main() {
+	if err := recovercmd.Execute(version); err != nil {
+		os.Exit(1)
+	}
+}
@@ -2,6 +2,7 @@ import { test, expect } from '@playwright/test';
 import * as fs from 'fs';
 import * as path from 'path';
 import * as os from 'os';
+import { execFileSync } from 'child_process';
 import {
   getRememoryBin,
   CreationPage,
@@ -315,6 +316,26 @@ friends:
     await creation.expectThresholdOptions(['2 of 7', '3 of 7', '4 of 7', '5 of 7', '6 of 7', '7 of 7']);
   });
 
+  test('capacity updates as the share count changes', async ({ page }) => {
+    const creation = new CreationPage(page, htmlPath);
+
+    await creation.open();
+    await creation.toggleAnonymousMode();
+
+    // Five pieces fit one QR code each and need no warnings
+    await creation.expectCapacityContains('25 recovery words');
+    await creation.expectCapacityContains('for all 5');
+    await creation.expectCapacityWarnings([]);
+
+    // Past 15, typed words can't say whose piece they are
+    await creation.setNumShares(16);
+    await creation.expectCapacityContains('for all 16');
+    await creation.expectCapacityWarnings(['words_unnumbered']);
+
+    await creation.setNumShares(5);
+    await creation.expectCapacityWarnings([]);
+  });
+
   test('anonymous mode full bundle creation workflow', async ({ page }, testInfo) => {
     testInfo.setTimeout(120000);
     const creation = new CreationPage(page, htmlPath);
@@ -405,3 +426,80 @@ friends:
     await creation.expectFriendCount(2);
   });
 });
+
+test.describe('Pre-filled maker.html', () => {
+  let tmpDir: string;
+  let htmlPath: string;
+
+  test.beforeAll(async () => {
+    const bin = getRememoryBin();
+    if (!fs.existsSync(bin)) {
+      test.skip();
+      return;
+    }
+
+    tmpDir = fs.mkdtempSync(path.join(os.tmpdir(), 'rememory-prefill-e2e-'));
+    const projectDir = path.join(tmpDir, 'prefill-project');
+    execFileSync(bin, [
+      'init', projectDir, '--name', 'Prefill Test', '--threshold', '3',
+      '--friend', 'Alice,alice@test.com', '--friend', 'Bob', '--friend', 'Carol', '--friend', 'David',
+    ], { stdio: 'inherit' });
+
+    htmlPath = path.join(tmpDir, 'maker.html');
+    execFileSync(bin, ['html', 'create', '--prefill', '-o', htmlPath], { cwd: projectDir, stdio: 'inherit' });
+  });
+
+  test.afterAll(async () => {
+    if (tmpDir && fs.existsSync(tmpDir)) {
+      fs.rmSync(tmpDir, { recursive: true, force: true });
+    }
+  });
+
+  test('starts with the project roster and threshold', async ({ page }) => {
+    const creation = new CreationPage(page, htmlPath);
+    await creation.open();
+
+    await creation.expectFriendCount(4);
+    await creation.expectFriendData(0, 'Alice', 'alice@test.com');
+    await creation.expectFriendData(3, 'David');
+    await expect(page.locator('#threshold-select')).toHaveValue('3');
+  });
+});
+
+test.describe('Pre-filled maker.html with first names only', () => {
+  let tmpDir: string;
+  let htmlPath: string;
+
+  test.beforeAll(async () => {
+    const bin = getRememoryBin();
+    if (!fs.existsSync(bin)) {
+      test.skip();
+      return;
+    }
+
+    tmpDir = fs.mkdtempSync(path.join(os.tmpdir(), 'rememory-privacy-e2e-'));
+    const projectDir = path.join(tmpDir, 'privacy-project');
+    execFileSync(bin, [
+      'init', projectDir, '--name', 'Privacy Test', '--threshold', '2', '--privacy', 'first-names',
+      '--friend', 'Alice Smith,alice@test.com', '--friend', 'Bob Jones', '--friend', 'Carol White',
+    ], { stdio: 'inherit' });
+
+    htmlPath = path.join(tmpDir, 'maker.html');
+    execFileSync(bin, ['html', 'create', '--prefill', '-o', htmlPath], { cwd: projectDir, stdio: 'inherit' });
+  });
+
+  test.afterAll(async () => {
+    if (tmpDir && fs.existsSync(tmpDir)) {
+      fs.rmSync(tmpDir, { recursive: true, force: true });
+    }
+  });
+
+  test('keeps the privacy setting in the exported project', async ({ page }) => {
+    const creation = new CreationPage(page, htmlPath);
+    await creation.open();
+
+    await creation.expectFriendData(0, 'Alice Smith', 'alice@test.com');
+    const yamlContent = await creation.exportYAML();
+    expect(yamlContent).toContain('privacy: first-names');
+  });
+});
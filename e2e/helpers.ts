@@ -24,6 +24,8 @@ export function generateStandaloneHTML(tmpDir: string, type: 'recover' | 'create
 // Options for test project creation
 interface TestProjectOptions {
   noEmbedManifest?: boolean;
+  sskr?: boolean;
+  expired?: boolean;  // Review and expiry dates that have already passed
 }
 
 // Cache for test projects within the same worker process.
@@ -33,7 +35,8 @@ const projectCache = new Map<string, string>();
 const cachedPaths = new Set<string>();
 
 function cacheKey(options: TestProjectOptions): string {
-  return options.noEmbedManifest ? 'standard-no-embed' : 'standard';
+  return (options.noEmbedManifest ? 'standard-no-embed' : 'standard') + (options.sskr ? '-sskr' : '') +
+    (options.expired ? '-expired' : '');
 }
 
 // Create a sealed test project with bundles (cached per config within a worker)
@@ -59,6 +62,13 @@ export function createTestProject(options: TestProjectOptions = {}): string {
   fs.writeFileSync(path.join(manifestDir, 'secret.txt'), 'The secret password is: correct-horse-battery-staple');
   fs.writeFileSync(path.join(manifestDir, 'notes.txt'), 'Remember to feed the cat!');
 
+  if (options.sskr) {
+    fs.appendFileSync(path.join(projectDir, 'project.yml'), 'sskr: true\n');
+  }
+  if (options.expired) {
+    fs.appendFileSync(path.join(projectDir, 'project.yml'), 'review_by: "2020-01-10"\nexpires: "2020-01-31"\n');
+  }
+
   // Seal and generate bundles
   const extraFlags = options.noEmbedManifest ? ['--no-embed-manifest'] : [];
   execFileSync(bin, ['seal', ...extraFlags], { cwd: projectDir, stdio: 'inherit' });
@@ -572,6 +582,20 @@ export class CreationPage {
     await expect(this.page.locator('#num-shares')).toHaveValue(String(count));
   }
 
+  // Capacity shown under the threshold
+  async expectCapacityContains(text: string): Promise<void> {
+    await expect(this.page.locator('#capacity-info')).toBeVisible();
+    await expect(this.page.locator('#capacity-info')).toContainText(text);
+  }
+
+  async expectCapacityWarnings(codes: string[]): Promise<void> {
+    const warnings = this.page.locator('#capacity-info .capacity-warning');
+    await expect(warnings).toHaveCount(codes.length);
+    for (let i = 0; i < codes.length; i++) {
+      await expect(warnings.nth(i)).toHaveAttribute('data-code', codes[i]);
+    }
+  }
+
   // Export YAML and return content
   async exportYAML(): Promise<string> {
     // Listen for download event and intercept the Blob
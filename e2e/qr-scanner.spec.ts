@@ -1,4 +1,5 @@
 import { test, expect } from '@playwright/test';
+import * as crypto from 'crypto';
 import * as fs from 'fs';
 import * as path from 'path';
 import {
@@ -248,6 +249,81 @@ test.describe('QR Scanner', () => {
     await expect(page.locator('#qr-scanner-modal')).not.toBeVisible();
   });
 
+  test('scanning a link split over two QR codes adds the share', async ({ page, browserName }) => {
+    test.skip(browserName === 'firefox', 'Firefox canvas.captureStream() does not produce a usable video stream for the mock scanner');
+
+    const [aliceDir, bobDir] = extractBundles(bundlesDir, ['Alice', 'Bob']);
+
+    const bobReadme = fs.readFileSync(findReadmeFile(bobDir), 'utf8');
+    const pemMatch = bobReadme.match(
+      /-----BEGIN REMEMORY SHARE-----([\s\S]*?)-----END REMEMORY SHARE-----/
+    );
+    if (!pemMatch) throw new Error('No PEM share found');
+
+    await page.addInitScript(() => {
+      let parts: string[] = [];
+      let showSecond = false;
+
+      (window as any).__qrTestSetParts = (p: string[]) => {
+        parts = p;
+      };
+      (window as any).__qrTestShowSecond = () => {
+        showSecond = true;
+      };
+
+      (window as any).BarcodeDetector = class {
+        constructor() {}
+        async detect() {
+          if (parts.length === 0) return [];
+          // Hold the first code in front of the camera until the test moves on
+          const rawValue = showSecond ? parts[1] : parts[0];
+          return [{ rawValue, format: 'qr_code', boundingBox: {}, cornerPoints: [] }];
+        }
+        static async getSupportedFormats() { return ['qr_code']; }
+      };
+
+      navigator.mediaDevices.getUserMedia = async () => {
+        const canvas = document.createElement('canvas');
+        canvas.width = 640;
+        canvas.height = 480;
+        const ctx = canvas.getContext('2d')!;
+        ctx.fillStyle = '#000';
+        ctx.fillRect(0, 0, 640, 480);
+        return canvas.captureStream(1);
+      };
+    });
+
+    const recovery = new RecoveryPage(page, aliceDir);
+    await recovery.open();
+
+    const compactShare = await page.evaluate((pem: string) => {
+      const result = (window as any).rememoryParseShare(pem);
+      if (result.error || !result.share) return '';
+      return result.share.compact;
+    }, pemMatch[0]);
+
+    // A recovery URL long enough that 'rememory qr' prints it over two codes
+    const url = `https://files.example.org/${'family/'.repeat(20)}recover.html#share=${encodeURIComponent(compactShare)}`;
+    const id = crypto.createHash('sha256').update(url).digest('hex').slice(0, 4);
+    const half = Math.ceil(url.length / 2);
+    const parts = [url.slice(0, half), url.slice(half)].map((part, i) => `RMQ:${i + 1}/2:${id}:${part}`);
+
+    await page.evaluate((p: string[]) => {
+      (window as any).__qrTestSetParts(p);
+    }, parts);
+
+    await page.locator('#scan-qr-btn').click();
+
+    // After the first code, the scanner asks for the next one
+    await expect(page.locator('#qr-scanner-hint')).toContainText('1 of 2');
+    await recovery.expectShareCount(1);
+
+    await page.evaluate(() => (window as any).__qrTestShowSecond());
+
+    await recovery.expectShareCount(2);
+    await expect(page.locator('#qr-scanner-modal')).not.toBeVisible();
+  });
+
   test('camera permission denied shows error and closes modal', async ({ page }) => {
     const bundleDir = extractBundle(bundlesDir, 'Alice');
 
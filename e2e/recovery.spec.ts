@@ -2,6 +2,7 @@ import { test, expect } from '@playwright/test';
 import * as fs from 'fs';
 import * as path from 'path';
 import * as os from 'os';
+import { execFileSync } from 'child_process';
 import {
   getRememoryBin,
   createTestProject,
@@ -248,6 +249,71 @@ test.describe('Browser Recovery Tool', () => {
     await recovery.expectShareCount(2);
   });
 
+  test('paste area accepts digit groups', async ({ page }) => {
+    const [aliceDir, bobDir] = extractBundles(bundlesDir, ['Alice', 'Bob']);
+    const recovery = new RecoveryPage(page, aliceDir);
+
+    await recovery.open();
+    await recovery.expectShareCount(1);
+
+    // Bob's digit groups, as read over the phone from his README.txt
+    const bobReadme = fs.readFileSync(findReadmeFile(bobDir), 'utf8');
+    const digitsMatch = bobReadme.match(/YOUR PIECE AS DIGITS:\n\n([\s\S]*?)\n\n/);
+    expect(digitsMatch).not.toBeNull();
+    const digits = digitsMatch![1].trim().split(/\s+/);
+    expect(digits.length).toBe(18);
+
+    // A misheard digit is caught, naming the group
+    const misheard = [...digits];
+    misheard[4] = misheard[4].slice(0, 5) + String((Number(misheard[4][5]) + 1) % 10);
+    await recovery.clickPasteButton();
+    await recovery.expectPasteAreaVisible();
+    await recovery.pasteShare(misheard.join(' '));
+    await recovery.submitPaste();
+    await expect(page.locator('.toast-error').first()).toContainText('group 5');
+    await recovery.expectShareCount(1);
+
+    await page.locator('.toast-close').first().click();
+
+    await recovery.clickPasteButton();
+    await recovery.pasteShare(digits.join(' '));
+    await recovery.submitPaste();
+    await recovery.expectShareCount(2);
+  });
+
+  test('paste area accepts the rm1 string', async ({ page }) => {
+    const [aliceDir, bobDir] = extractBundles(bundlesDir, ['Alice', 'Bob']);
+    const recovery = new RecoveryPage(page, aliceDir);
+
+    await recovery.open();
+    await recovery.expectShareCount(1);
+
+    // Bob's piece as printed under the QR code; inspect shows the same string
+    const inspected = execFileSync(getRememoryBin(), ['inspect', findReadmeFile(bobDir)], { encoding: 'utf8' });
+    const typedMatch = inspected.match(/Typed form: (rm1[a-z0-9]+)/);
+    expect(typedMatch).not.toBeNull();
+    const typed = typedMatch![1];
+
+    // A mistyped character is caught by the checksum
+    const last = typed[typed.length - 1];
+    const mistyped = typed.slice(0, -1) + (last === 'q' ? 'p' : 'q');
+    await recovery.clickPasteButton();
+    await recovery.expectPasteAreaVisible();
+    await recovery.pasteShare(mistyped);
+    await recovery.submitPaste();
+    await expect(page.locator('.toast-error').first()).toContainText('mistyped');
+    await recovery.expectShareCount(1);
+
+    await page.locator('.toast-close').first().click();
+
+    // Typed in capitals, in groups of four, as it's printed
+    const grouped = typed.toUpperCase().match(/.{1,4}/g)!.join(' ');
+    await recovery.clickPasteButton();
+    await recovery.pasteShare(grouped);
+    await recovery.submitPaste();
+    await recovery.expectShareCount(2);
+  });
+
   test('detects duplicate shares', async ({ page }) => {
     const bundleDir = extractBundle(bundlesDir, 'Alice');
     const recovery = new RecoveryPage(page, bundleDir);
@@ -259,6 +325,23 @@ test.describe('Browser Recovery Tool', () => {
     await recovery.addShares(bundleDir);
     await recovery.expectShareCount(1); // Still 1, duplicate ignored
   });
+
+  test('checks each piece against the pieces the bundle recorded', async ({ page }) => {
+    const [aliceDir, bobDir] = extractBundles(bundlesDir, ['Alice', 'Bob']);
+    const recovery = new RecoveryPage(page, aliceDir);
+
+    await recovery.open();
+    await recovery.addShares(bobDir);
+    await expect(page.locator('.share-item').filter({ hasText: 'Bob' }).locator('.seal-match'))
+      .toContainText("Matches this bundle's record");
+
+    // Carol's piece from another seal of the same friends
+    const otherProject = createTestProject({ noEmbedManifest: true });
+    const otherCarolDir = extractBundle(path.join(otherProject, 'output', 'bundles'), 'Carol');
+    await recovery.addShares(otherCarolDir);
+    await expect(page.locator('.share-item').filter({ hasText: 'Carol' }).locator('.seal-mismatch'))
+      .toContainText("Not a piece of this bundle's seal");
+  });
 });
 
 test.describe('Anonymous Bundle Recovery', () => {
@@ -337,6 +420,692 @@ test.describe('Anonymous Bundle Recovery', () => {
   });
 });
 
+test.describe('Grouped Bundle Recovery', () => {
+  let tmpDir: string;
+  let bundlesDir: string;
+
+  test.beforeAll(async () => {
+    const bin = getRememoryBin();
+    if (!fs.existsSync(bin)) {
+      test.skip();
+      return;
+    }
+
+    // Two of the three family members, and one of the two lawyers
+    tmpDir = fs.mkdtempSync(path.join(os.tmpdir(), 'rememory-e2e-groups-'));
+    const projectDir = path.join(tmpDir, 'test-groups-project');
+    execFileSync(bin, [
+      'init', projectDir, '--name', 'Grouped E2E Test', '--threshold', '2',
+      '--friend', 'Alice', '--friend', 'Bob', '--friend', 'Carol', '--friend', 'Dave', '--friend', 'Erin',
+    ], { stdio: 'inherit' });
+    fs.writeFileSync(path.join(projectDir, 'project.yml'), [
+      'name: Grouped E2E Test',
+      'threshold: 2',
+      'groups:',
+      '  - name: family',
+      '    threshold: 2',
+      '  - name: lawyers',
+      '    threshold: 1',
+      'friends:',
+      ...['Alice', 'Bob', 'Carol'].map(name => `  - name: ${name}\n    group: family`),
+      ...['Dave', 'Erin'].map(name => `  - name: ${name}\n    group: lawyers`),
+      '',
+    ].join('\n'));
+    fs.writeFileSync(path.join(projectDir, 'manifest', 'secret.txt'), 'Grouped secret: correct-horse-battery-staple');
+
+    execFileSync(bin, ['seal'], { cwd: projectDir, stdio: 'inherit' });
+    execFileSync(bin, ['bundle'], { cwd: projectDir, stdio: 'inherit' });
+    bundlesDir = path.join(projectDir, 'output', 'bundles');
+  });
+
+  test.afterAll(async () => {
+    if (tmpDir) fs.rmSync(tmpDir, { recursive: true, force: true });
+  });
+
+  test('waits for every group needed, then recovers', async ({ page }) => {
+    const [aliceDir, bobDir, carolDir, daveDir] = extractBundles(bundlesDir, ['Alice', 'Bob', 'Carol', 'Dave']);
+    const recovery = new RecoveryPage(page, aliceDir);
+
+    await recovery.open();
+    await recovery.expectManifestLoaded();
+
+    // The whole family is still only one group
+    await recovery.addShares(bobDir, carolDir);
+    await recovery.expectShareCount(3);
+    await expect(page.locator('#threshold-info')).toContainText('1 of 2 groups complete');
+    await recovery.expectRecoverDisabled();
+
+    // One lawyer completes the second group
+    await recovery.addShares(daveDir);
+    await recovery.expectRecoveryComplete();
+  });
+});
+
+test.describe('Weighted Bundle Recovery', () => {
+  let tmpDir: string;
+  let bundlesDir: string;
+
+  test.beforeAll(async () => {
+    const bin = getRememoryBin();
+    if (!fs.existsSync(bin)) {
+      test.skip();
+      return;
+    }
+
+    // Alice holds 2 of the 6 pieces; 3 are needed
+    tmpDir = fs.mkdtempSync(path.join(os.tmpdir(), 'rememory-e2e-weighted-'));
+    const projectDir = path.join(tmpDir, 'test-weighted-project');
+    execFileSync(bin, [
+      'init', projectDir, '--name', 'Weighted E2E Test', '--threshold', '3',
+      '--friend', 'Alice', '--friend', 'Bob', '--friend', 'Carol', '--friend', 'Dave', '--friend', 'Erin',
+    ], { stdio: 'inherit' });
+    fs.writeFileSync(path.join(projectDir, 'project.yml'), [
+      'name: Weighted E2E Test',
+      'threshold: 3',
+      'friends:',
+      '  - name: Alice',
+      '    shares: 2',
+      ...['Bob', 'Carol', 'Dave', 'Erin'].map(name => `  - name: ${name}`),
+      '',
+    ].join('\n'));
+    fs.writeFileSync(path.join(projectDir, 'manifest', 'secret.txt'), 'Weighted secret: correct-horse-battery-staple');
+
+    execFileSync(bin, ['seal'], { cwd: projectDir, stdio: 'inherit' });
+    execFileSync(bin, ['bundle'], { cwd: projectDir, stdio: 'inherit' });
+    bundlesDir = path.join(projectDir, 'output', 'bundles');
+  });
+
+  test.afterAll(async () => {
+    if (tmpDir) fs.rmSync(tmpDir, { recursive: true, force: true });
+  });
+
+  test('holder with two pieces loads both and needs one more friend', async ({ page }) => {
+    const [aliceDir, bobDir] = extractBundles(bundlesDir, ['Alice', 'Bob']);
+    const recovery = new RecoveryPage(page, aliceDir);
+
+    await recovery.open();
+    await recovery.expectManifestLoaded();
+    await recovery.expectShareCount(2);
+    await recovery.expectRecoverDisabled();
+
+    await recovery.addShares(bobDir);
+    await recovery.expectRecoveryComplete();
+  });
+
+  test("a friend's README.txt adds all of their pieces", async ({ page }) => {
+    const [bobDir, aliceDir] = extractBundles(bundlesDir, ['Bob', 'Alice']);
+    const recovery = new RecoveryPage(page, bobDir);
+
+    await recovery.open();
+    await recovery.expectShareCount(1);
+
+    await recovery.addShares(aliceDir);
+    await recovery.expectRecoveryComplete();
+  });
+
+  test("a piece from another project's bundle is turned away", async ({ page }) => {
+    const [aliceDir] = extractBundles(bundlesDir, ['Alice']);
+    const [strangerDir] = extractBundles(path.join(createTestProject(), 'output', 'bundles'), ['Bob']);
+    const recovery = new RecoveryPage(page, aliceDir);
+
+    await recovery.open();
+    await recovery.expectShareCount(2);
+
+    await recovery.clickPasteButton();
+    await recovery.pasteShare(fs.readFileSync(findReadmeFile(strangerDir), 'utf8'));
+    await recovery.submitPaste();
+    await expect(page.locator('.toast-error').first()).toContainText('another set');
+    await recovery.expectShareCount(2);
+  });
+
+  test('a piece changed after sealing is named', async ({ page }) => {
+    const [aliceDir, bobDir] = extractBundles(bundlesDir, ['Alice', 'Bob']);
+    const recovery = new RecoveryPage(page, aliceDir);
+
+    await recovery.open();
+    await recovery.expectShareCount(2);
+
+    // Bob's piece still parses, but its MAC no longer matches the secret
+    const readme = fs.readFileSync(findReadmeFile(bobDir), 'utf8').replace(/^MAC: [0-9a-f]+$/m, 'MAC: 0000000000000000');
+    await recovery.clickPasteButton();
+    await recovery.pasteShare(readme);
+    await recovery.submitPaste();
+    await recovery.expectShareCount(3);
+
+    await expect(page.locator('.toast-error').first()).toContainText('A piece is damaged');
+    await expect(page.locator('.toast-error').first()).toContainText('piece 3 is damaged');
+  });
+});
+
+test.describe('PIN-Locked Pieces', () => {
+  let tmpDir: string;
+  let bundlesDir: string;
+  let bobPIN: string;
+
+  test.beforeAll(async () => {
+    const bin = getRememoryBin();
+    if (!fs.existsSync(bin)) {
+      test.skip();
+      return;
+    }
+
+    // Bob's piece is locked with a PIN; 2 of 3 are needed
+    tmpDir = fs.mkdtempSync(path.join(os.tmpdir(), 'rememory-e2e-pin-'));
+    const projectDir = path.join(tmpDir, 'test-pin-project');
+    execFileSync(bin, [
+      'init', projectDir, '--name', 'PIN E2E Test', '--threshold', '2',
+      '--friend', 'Alice', '--friend', 'Bob', '--friend', 'Carol',
+    ], { stdio: 'inherit' });
+    fs.writeFileSync(path.join(projectDir, 'project.yml'), [
+      'name: PIN E2E Test',
+      'threshold: 2',
+      'friends:',
+      '  - name: Alice',
+      '  - name: Bob',
+      '    pin: true',
+      '  - name: Carol',
+      '',
+    ].join('\n'));
+    fs.writeFileSync(path.join(projectDir, 'manifest', 'secret.txt'), 'PIN secret: correct-horse-battery-staple');
+
+    const out = execFileSync(bin, ['seal'], { cwd: projectDir, encoding: 'utf8' });
+    bobPIN = out.match(/^  Bob: (\d{3}-\d{3})$/m)![1];
+    execFileSync(bin, ['bundle'], { cwd: projectDir, stdio: 'inherit' });
+    bundlesDir = path.join(projectDir, 'output', 'bundles');
+  });
+
+  test.afterAll(async () => {
+    if (tmpDir) fs.rmSync(tmpDir, { recursive: true, force: true });
+  });
+
+  test('a locked piece waits for its PIN, then recovers', async ({ page }) => {
+    const [aliceDir, bobDir] = extractBundles(bundlesDir, ['Alice', 'Bob']);
+    const recovery = new RecoveryPage(page, aliceDir);
+
+    await recovery.open();
+    await recovery.addShares(bobDir);
+    await recovery.expectShareCount(2);
+    await expect(page.locator('.share-locked')).toBeVisible();
+    await recovery.expectRecoverDisabled();
+
+    await page.locator('.pin-input').fill('000-000');
+    await page.locator('.pin-form button').click();
+    await expect(page.locator('.toast-warning').first()).toContainText('Not unlocked');
+    await expect(page.locator('.share-locked')).toBeVisible();
+
+    await page.locator('.pin-input').fill(bobPIN);
+    await page.locator('.pin-form button').click();
+    await recovery.expectRecoveryComplete();
+  });
+});
+
+test.describe('Small Payloads', () => {
+  let tmpDir: string;
+  let bundlesDir: string;
+
+  test.beforeAll(async () => {
+    const bin = getRememoryBin();
+    if (!fs.existsSync(bin)) {
+      test.skip();
+      return;
+    }
+
+    // One small file and an empty folder, nothing else
+    tmpDir = fs.mkdtempSync(path.join(os.tmpdir(), 'rememory-e2e-small-'));
+    const projectDir = path.join(tmpDir, 'test-small-project');
+    execFileSync(bin, [
+      'init', projectDir, '--name', 'Small E2E Test', '--threshold', '2',
+      '--friend', 'Alice', '--friend', 'Bob', '--friend', 'Carol',
+    ], { stdio: 'inherit' });
+    const manifestDir = path.join(projectDir, 'manifest');
+    fs.rmSync(path.join(manifestDir, 'README.md'));
+    fs.writeFileSync(path.join(manifestDir, 'pin.txt'), '4921');
+    fs.mkdirSync(path.join(manifestDir, 'later'));
+
+    const out = execFileSync(bin, ['seal'], { cwd: projectDir, encoding: 'utf8' });
+    expect(out).toContain('manifest/later/ is an empty folder');
+    bundlesDir = path.join(projectDir, 'output', 'bundles');
+  });
+
+  test.afterAll(async () => {
+    if (tmpDir) fs.rmSync(tmpDir, { recursive: true, force: true });
+  });
+
+  test('a single file can be saved on its own', async ({ page }) => {
+    const [aliceDir, bobDir] = extractBundles(bundlesDir, ['Alice', 'Bob']);
+    const recovery = new RecoveryPage(page, aliceDir);
+
+    await recovery.open();
+    await recovery.addShares(bobDir);
+    await recovery.expectRecoveryComplete();
+    await expect(page.locator('#status-message')).toContainText('1 file(s)');
+
+    // The file and the empty folder are both listed
+    await recovery.expectFileCount(2);
+    await expect(page.locator('.file-item.folder')).toContainText('manifest/later/');
+
+    const saveButton = page.locator('#download-file-btn');
+    await expect(saveButton).toBeVisible();
+    await expect(saveButton).toContainText('Save pin.txt');
+    await recovery.expectDownloadVisible();
+
+    const [download] = await Promise.all([
+      page.waitForEvent('download'),
+      saveButton.click(),
+    ]);
+    expect(download.suggestedFilename()).toBe('pin.txt');
+    expect(fs.readFileSync(await download.path(), 'utf8')).toBe('4921');
+  });
+});
+
+test.describe('Recovery-Time Budget', () => {
+  let tmpDir: string;
+  let bundlesDir: string;
+  let workFactor: number;
+
+  test.beforeAll(async () => {
+    const bin = getRememoryBin();
+    if (!fs.existsSync(bin)) {
+      test.skip();
+      return;
+    }
+
+    tmpDir = fs.mkdtempSync(path.join(os.tmpdir(), 'rememory-e2e-kdf-'));
+    const projectDir = path.join(tmpDir, 'test-kdf-project');
+    execFileSync(bin, [
+      'init', projectDir, '--name', 'KDF E2E Test', '--threshold', '2',
+      '--friend', 'Alice', '--friend', 'Bob', '--friend', 'Carol',
+    ], { stdio: 'inherit' });
+    fs.appendFileSync(path.join(projectDir, 'project.yml'), 'recovery_time:\n  budget: 5s\n  device: laptop\n');
+
+    const out = execFileSync(bin, ['seal'], { cwd: projectDir, encoding: 'utf8' });
+    expect(out).toContain('Tuning scrypt for recovery on a laptop');
+    const share = fs.readFileSync(path.join(projectDir, 'output', 'shares', 'SHARE-alice.txt'), 'utf8');
+    const match = share.match(/KDF: scrypt N=2\^(\d+)/);
+    expect(match).not.toBeNull();
+    workFactor = Number(match![1]);
+    bundlesDir = path.join(projectDir, 'output', 'bundles');
+  });
+
+  test.afterAll(async () => {
+    if (tmpDir) fs.rmSync(tmpDir, { recursive: true, force: true });
+  });
+
+  test('the wait is estimated from the work factor the pieces record', async ({ page }) => {
+    const [aliceDir, bobDir] = extractBundles(bundlesDir, ['Alice', 'Bob']);
+    const recovery = new RecoveryPage(page, aliceDir);
+
+    await recovery.open();
+    await page.evaluate(() => {
+      const w = window as any;
+      const estimate = w.rememoryEstimateUnlock;
+      w.estimatedWorkFactors = [];
+      w.rememoryEstimateUnlock = (wf: number) => {
+        w.estimatedWorkFactors.push(wf);
+        return estimate(wf);
+      };
+    });
+    await recovery.addShares(bobDir);
+    await recovery.expectRecoveryComplete();
+
+    const estimated = await page.evaluate(() => (window as any).estimatedWorkFactors);
+    expect(estimated).toEqual([workFactor]);
+  });
+});
+
+test.describe('Password Vault', () => {
+  let tmpDir: string;
+  let bundlesDir: string;
+
+  test.beforeAll(async () => {
+    const bin = getRememoryBin();
+    if (!fs.existsSync(bin)) {
+      test.skip();
+      return;
+    }
+
+    tmpDir = fs.mkdtempSync(path.join(os.tmpdir(), 'rememory-e2e-vault-'));
+    const projectDir = path.join(tmpDir, 'test-vault-project');
+    execFileSync(bin, [
+      'init', projectDir, '--name', 'Vault E2E Test', '--threshold', '2',
+      '--friend', 'Alice', '--friend', 'Bob', '--friend', 'Carol',
+    ], { stdio: 'inherit' });
+
+    const exportPath = path.join(tmpDir, 'bitwarden.json');
+    fs.writeFileSync(exportPath, JSON.stringify({
+      encrypted: false,
+      folders: [{ id: 'f1', name: 'Banking' }],
+      items: [{
+        type: 1, name: 'Credit Union', folderId: 'f1', notes: 'Branch on Main St.',
+        login: {
+          username: 'jo@example.com', password: 'correct-horse-battery',
+          uris: [{ uri: 'https://cu.example.com' }],
+        },
+      }],
+    }));
+    execFileSync(bin, ['seal', '--vault', exportPath], { cwd: projectDir, stdio: 'inherit' });
+    bundlesDir = path.join(projectDir, 'output', 'bundles');
+  });
+
+  test.afterAll(async () => {
+    if (tmpDir) fs.rmSync(tmpDir, { recursive: true, force: true });
+  });
+
+  test('recovered vault is shown as a list with hidden passwords', async ({ page }) => {
+    const [aliceDir, bobDir] = extractBundles(bundlesDir, ['Alice', 'Bob']);
+    const recovery = new RecoveryPage(page, aliceDir);
+
+    await recovery.open();
+    await recovery.addShares(bobDir);
+    await recovery.expectRecoveryComplete();
+
+    const viewer = page.locator('#vault-viewer');
+    await expect(viewer).toBeVisible();
+    await expect(viewer.locator('.vault-entry h4')).toContainText('Credit Union');
+    await expect(viewer).toContainText('jo@example.com');
+    await expect(viewer).not.toContainText('correct-horse-battery');
+
+    await viewer.locator('.vault-toggle').first().click();
+    await expect(viewer).toContainText('correct-horse-battery');
+
+    await viewer.locator('.vault-search').fill('nothing like it');
+    await expect(viewer.locator('.vault-entry')).toBeHidden();
+  });
+});
+
+test.describe('Seed Phrase', () => {
+  const phrase = 'legal winner thank year wave sausage worth useful legal winner thank year wave sausage worth useful legal winner thank year wave sausage worth title';
+  let tmpDir: string;
+  let bundlesDir: string;
+
+  test.beforeAll(async () => {
+    const bin = getRememoryBin();
+    if (!fs.existsSync(bin)) {
+      test.skip();
+      return;
+    }
+
+    tmpDir = fs.mkdtempSync(path.join(os.tmpdir(), 'rememory-e2e-seed-'));
+    const projectDir = path.join(tmpDir, 'test-seed-project');
+    execFileSync(bin, [
+      'init', projectDir, '--name', 'Seed E2E Test', '--threshold', '2',
+      '--friend', 'Alice', '--friend', 'Bob', '--friend', 'Carol',
+    ], { stdio: 'inherit' });
+
+    // The phrase is typed twice; piped lines stand in for the prompts
+    execFileSync(bin, ['seal', '--seed', 'Hardware wallet'], {
+      cwd: projectDir, input: `${phrase}\n${phrase}\n`, stdio: ['pipe', 'inherit', 'inherit'],
+    });
+    bundlesDir = path.join(projectDir, 'output', 'bundles');
+  });
+
+  test.afterAll(async () => {
+    if (tmpDir) fs.rmSync(tmpDir, { recursive: true, force: true });
+  });
+
+  test('recovered seed phrase is numbered and hidden until shown', async ({ page }) => {
+    const [aliceDir, bobDir] = extractBundles(bundlesDir, ['Alice', 'Bob']);
+    const recovery = new RecoveryPage(page, aliceDir);
+
+    await recovery.open();
+    await recovery.addShares(bobDir);
+    await recovery.expectRecoveryComplete();
+
+    const viewer = page.locator('#seed-viewer');
+    await expect(viewer).toBeVisible();
+    await expect(viewer.locator('h3')).toContainText('Hardware wallet');
+    await expect(viewer.locator('.seed-warning')).toBeVisible();
+    await expect(viewer.locator('.seed-words')).toBeHidden();
+
+    await viewer.locator('.seed-toggle').click();
+    const words = viewer.locator('.seed-words li');
+    await expect(words).toHaveCount(24);
+    await expect(words.nth(0)).toHaveText('legal');
+    await expect(words.nth(23)).toHaveText('title');
+  });
+});
+
+test.describe('Estate', () => {
+  let tmpDir: string;
+  let bundlesDir: string;
+
+  test.beforeAll(async () => {
+    const bin = getRememoryBin();
+    if (!fs.existsSync(bin)) {
+      test.skip();
+      return;
+    }
+
+    tmpDir = fs.mkdtempSync(path.join(os.tmpdir(), 'rememory-e2e-estate-'));
+    const projectDir = path.join(tmpDir, 'test-estate-project');
+    execFileSync(bin, [
+      'init', projectDir, '--name', 'Estate E2E Test', '--threshold', '2',
+      '--friend', 'Alice', '--friend', 'Bob', '--friend', 'Carol',
+    ], { stdio: 'inherit' });
+
+    // One account, no subscriptions, one device, one wish; an empty first
+    // answer moves on to the next part
+    const answers = [
+      'Gmail', 'ana@example.com', 'Memorialize it', '', '',
+      '',
+      'iPhone', 'The code is in the safe', '', '', '',
+      'Pets', 'Luna goes to Sam.', '',
+    ];
+    execFileSync(bin, ['estate'], {
+      cwd: projectDir, input: answers.join('\n') + '\n', stdio: ['pipe', 'inherit', 'inherit'],
+    });
+    execFileSync(bin, ['seal'], { cwd: projectDir, stdio: 'inherit' });
+    bundlesDir = path.join(projectDir, 'output', 'bundles');
+  });
+
+  test.afterAll(async () => {
+    if (tmpDir) fs.rmSync(tmpDir, { recursive: true, force: true });
+  });
+
+  test('recovered estate answers are shown by section', async ({ page }) => {
+    const [aliceDir, bobDir] = extractBundles(bundlesDir, ['Alice', 'Bob']);
+    const recovery = new RecoveryPage(page, aliceDir);
+
+    await recovery.open();
+    await recovery.addShares(bobDir);
+    await recovery.expectRecoveryComplete();
+
+    const viewer = page.locator('#estate-viewer');
+    await expect(viewer).toBeVisible();
+    await expect(viewer.locator('.estate-section')).toHaveCount(3);
+    await expect(viewer.locator('.estate-section').nth(0)).toHaveText('Accounts (1)');
+    await expect(viewer.locator('.estate-entry').nth(0)).toContainText('ana@example.com');
+    await expect(viewer.locator('.estate-entry').nth(1)).toContainText('The code is in the safe');
+    await expect(viewer.locator('.estate-text')).toHaveText('Luna goes to Sam.');
+  });
+});
+
+test.describe('Decoy', () => {
+  let tmpDir: string;
+  let projectDir: string;
+  let standaloneRecoverHtml: string;
+
+  test.beforeAll(async () => {
+    const bin = getRememoryBin();
+    if (!fs.existsSync(bin)) {
+      test.skip();
+      return;
+    }
+
+    tmpDir = fs.mkdtempSync(path.join(os.tmpdir(), 'rememory-e2e-decoy-'));
+    projectDir = path.join(tmpDir, 'test-decoy-project');
+    execFileSync(bin, [
+      'init', projectDir, '--name', 'Decoy E2E Test', '--threshold', '2',
+      '--friend', 'Alice', '--friend', 'Bob', '--friend', 'Carol',
+    ], { stdio: 'inherit' });
+    fs.writeFileSync(path.join(projectDir, 'manifest', 'secret.txt'), 'the real thing');
+    fs.mkdirSync(path.join(projectDir, 'decoy'));
+    fs.writeFileSync(path.join(projectDir, 'decoy', 'notes.txt'), 'nothing to see');
+    fs.appendFileSync(path.join(projectDir, 'project.yml'), 'decoy: {}\n');
+    execFileSync(bin, ['seal'], { cwd: projectDir, stdio: 'inherit' });
+    standaloneRecoverHtml = generateStandaloneHTML(tmpDir, 'recover');
+  });
+
+  test.afterAll(async () => {
+    if (tmpDir) fs.rmSync(tmpDir, { recursive: true, force: true });
+  });
+
+  test('duress pieces open the decoy like any other recovery', async ({ page }) => {
+    const [aliceDir] = extractBundles(path.join(projectDir, 'output', 'bundles'), ['Alice']);
+    const recovery = new RecoveryPage(page, tmpDir);
+
+    await recovery.openFile(standaloneRecoverHtml);
+    await page.locator('#share-file-input').setInputFiles([
+      path.join(projectDir, 'output', 'duress', 'SHARE-alice.txt'),
+      path.join(projectDir, 'output', 'duress', 'SHARE-bob.txt'),
+    ]);
+    await recovery.expectShareCount(2);
+    await recovery.addManifestFile(path.join(aliceDir, 'recover.html'));
+    await recovery.expectRecoveryComplete();
+
+    const files = page.locator('#files-list');
+    await expect(files).toContainText('notes.txt');
+    await expect(files).not.toContainText('secret.txt');
+  });
+
+  test('real pieces still open the real files', async ({ page }) => {
+    const [aliceDir, bobDir] = extractBundles(path.join(projectDir, 'output', 'bundles'), ['Alice', 'Bob']);
+    const recovery = new RecoveryPage(page, aliceDir);
+
+    await recovery.open();
+    await recovery.addShares(bobDir);
+    await recovery.expectRecoveryComplete();
+    await expect(page.locator('#files-list')).toContainText('secret.txt');
+  });
+});
+
+test.describe('Recovery delay', () => {
+  let tmpDir: string;
+  let projectDir: string;
+
+  test.beforeAll(async () => {
+    const bin = getRememoryBin();
+    if (!fs.existsSync(bin)) {
+      test.skip();
+      return;
+    }
+
+    tmpDir = fs.mkdtempSync(path.join(os.tmpdir(), 'rememory-e2e-delay-'));
+    projectDir = path.join(tmpDir, 'test-delay-project');
+    execFileSync(bin, [
+      'init', projectDir, '--name', 'Delay E2E Test', '--threshold', '2',
+      '--friend', 'Alice', '--friend', 'Bob', '--friend', 'Carol',
+    ], { stdio: 'inherit' });
+    fs.writeFileSync(path.join(projectDir, 'manifest', 'secret.txt'), 'worth the wait');
+    fs.appendFileSync(path.join(projectDir, 'project.yml'), 'recovery_delay: 1s\n');
+    execFileSync(bin, ['seal'], { cwd: projectDir, stdio: 'inherit' });
+  });
+
+  test.afterAll(async () => {
+    if (tmpDir) fs.rmSync(tmpDir, { recursive: true, force: true });
+  });
+
+  test('the page waits out the delay, then opens the files', async ({ page }) => {
+    const [aliceDir, bobDir] = extractBundles(path.join(projectDir, 'output', 'bundles'), ['Alice', 'Bob']);
+    const recovery = new RecoveryPage(page, aliceDir);
+
+    await recovery.open();
+    await recovery.addShares(bobDir);
+    await expect(page.locator('#status-message')).toContainText('wait the owner chose', { timeout: 30000 });
+    await recovery.expectRecoveryComplete();
+    await expect(page.locator('#files-list')).toContainText('secret.txt');
+  });
+});
+
+test.describe('Quiz', () => {
+  let tmpDir: string;
+  let projectDir: string;
+
+  test.beforeAll(async () => {
+    const bin = getRememoryBin();
+    if (!fs.existsSync(bin)) {
+      test.skip();
+      return;
+    }
+
+    tmpDir = fs.mkdtempSync(path.join(os.tmpdir(), 'rememory-e2e-quiz-'));
+    projectDir = path.join(tmpDir, 'test-quiz-project');
+    execFileSync(bin, [
+      'init', projectDir, '--name', 'Quiz E2E Test', '--threshold', '2',
+      '--friend', 'Alice', '--friend', 'Bob', '--friend', 'Carol',
+    ], { stdio: 'inherit' });
+    fs.writeFileSync(path.join(projectDir, 'manifest', 'secret.txt'), 'quiz test');
+    fs.appendFileSync(path.join(projectDir, 'project.yml'), 'quiz: true\n');
+    execFileSync(bin, ['seal'], { cwd: projectDir, stdio: 'inherit' });
+  });
+
+  test.afterAll(async () => {
+    if (tmpDir) fs.rmSync(tmpDir, { recursive: true, force: true });
+  });
+
+  test('the holder can check what they know before starting', async ({ page }) => {
+    const [aliceDir] = extractBundles(path.join(projectDir, 'output', 'bundles'), ['Alice']);
+    const recovery = new RecoveryPage(page, aliceDir);
+
+    await recovery.open();
+    const quiz = page.locator('#quiz');
+    await expect(quiz).toBeVisible();
+    await quiz.locator('> summary').click();
+    const questions = quiz.locator('.quiz-question');
+    await expect(questions).toHaveCount(4);
+    await questions.nth(0).locator('summary').click();
+    await expect(questions.nth(0)).toContainText('2. One piece alone opens nothing.');
+    await questions.nth(1).locator('summary').click();
+    await expect(questions.nth(1)).toContainText('Bob, Carol');
+  });
+});
+
+test.describe('Catalog', () => {
+  let tmpDir: string;
+  let bundlesDir: string;
+
+  test.beforeAll(async () => {
+    const bin = getRememoryBin();
+    if (!fs.existsSync(bin)) {
+      test.skip();
+      return;
+    }
+
+    tmpDir = fs.mkdtempSync(path.join(os.tmpdir(), 'rememory-e2e-catalog-'));
+    const projectDir = path.join(tmpDir, 'test-catalog-project');
+    execFileSync(bin, [
+      'init', projectDir, '--name', 'Catalog E2E Test', '--threshold', '3',
+      '--friend', 'Alice', '--friend', 'Bob', '--friend', 'Carol', '--friend', 'David',
+    ], { stdio: 'inherit' });
+    fs.writeFileSync(path.join(projectDir, 'manifest', 'will.txt'), 'The will.');
+    fs.appendFileSync(path.join(projectDir, 'project.yml'),
+      'catalog:\n  threshold: 2\n  description: The will and the house deeds\n');
+
+    execFileSync(bin, ['seal'], { cwd: projectDir, stdio: 'inherit' });
+    bundlesDir = path.join(projectDir, 'output', 'bundles');
+  });
+
+  test.afterAll(async () => {
+    if (tmpDir) fs.rmSync(tmpDir, { recursive: true, force: true });
+  });
+
+  test('fewer pieces than the threshold show what is sealed', async ({ page }) => {
+    const [aliceDir, bobDir] = extractBundles(bundlesDir, ['Alice', 'Bob']);
+    const recovery = new RecoveryPage(page, aliceDir);
+
+    await recovery.open();
+    const viewer = page.locator('#catalog-viewer');
+    await expect(viewer).toBeHidden();
+
+    await recovery.addShares(bobDir);
+    await recovery.expectNeedMoreShares(1);
+    await expect(viewer).toBeVisible();
+    await expect(viewer.locator('h3')).toContainText('Catalog E2E Test');
+    await expect(viewer.locator('.catalog-description')).toHaveText('The will and the house deeds');
+    await expect(viewer.locator('.catalog-files')).toContainText('will.txt');
+  });
+});
+
 test.describe('Generic recover.html (no personalization)', () => {
   let projectDir: string;
   let bundlesDir: string;
@@ -394,6 +1163,17 @@ test.describe('Generic recover.html (no personalization)', () => {
     await recovery.expectDownloadVisible();
   });
 
+  test('the page tests itself with its built-in sample bundle', async ({ page }) => {
+    const recovery = new RecoveryPage(page, tmpDir);
+    await recovery.openFile(standaloneRecoverHtml);
+
+    await page.locator('#self-test-btn').click();
+    const result = page.locator('#self-test-result');
+    await expect(result).toHaveClass(/passed/, { timeout: 30000 });
+    await expect(result.locator('li')).toHaveCount(3);
+    await expect(result).toContainText('This page works');
+  });
+
   test('personalized recover.html can be used as manifest source on standalone tool', async ({ page }) => {
     const [aliceDir, bobDir] = extractBundles(bundlesDir, ['Alice', 'Bob']);
     const recovery = new RecoveryPage(page, tmpDir);
@@ -458,6 +1238,44 @@ test.describe('Generic recover.html (no personalization)', () => {
     await recovery.expectFileCount(3); // secret.txt, notes.txt, README.md
     await recovery.expectDownloadVisible();
   });
+
+  test('kept pieces come back after a reload with the code', async ({ page }) => {
+    const [aliceDir, bobDir] = extractBundles(bundlesDir, ['Alice', 'Bob']);
+    const recovery = new RecoveryPage(page, tmpDir);
+
+    await recovery.openFile(standaloneRecoverHtml);
+    await recovery.addShares(aliceDir);
+    await recovery.expectShareCount(1);
+
+    await page.locator('#keep-progress-toggle').check();
+    const code = page.locator('#keep-progress-code-value');
+    await expect(code).toHaveText(/^[0-9A-Z]{4}(-[0-9A-Z]{4}){3}$/);
+    const codeText = await code.textContent();
+
+    await page.reload();
+    await page.waitForFunction(() => (window as any).rememoryAppReady === true, { timeout: 30000 });
+    await recovery.expectShareCount(0);
+    await expect(page.locator('#restore-progress')).toBeVisible();
+
+    // A wrong code brings nothing back
+    await page.locator('#restore-progress-input').fill('0000-0000-0000-0000');
+    await page.locator('#restore-progress-btn').click();
+    await expect(page.locator('.inline-error')).toBeVisible();
+    await recovery.expectShareCount(0);
+
+    // Typed in lowercase without dashes still works
+    await page.locator('#restore-progress-input').fill(codeText!.replace(/-/g, '').toLowerCase());
+    await page.locator('#restore-progress-btn').click();
+    await expect(page.locator('#restore-progress')).toBeHidden();
+    await recovery.expectShareCount(1);
+
+    // Finishing recovery removes the kept pieces
+    await recovery.addShares(bobDir);
+    await recovery.addManifest(aliceDir);
+    await recovery.expectRecoveryComplete();
+    const kept = await page.evaluate(() => sessionStorage.getItem('rememory-progress'));
+    expect(kept).toBeNull();
+  });
 });
 
 test.describe('Embedded Manifest Recovery', () => {
@@ -546,3 +1364,92 @@ test.describe('--no-embed-manifest flag', () => {
     await recovery.expectDownloadVisible();
   });
 });
+
+test.describe('SSKR shares', () => {
+  let projectDir: string;
+  let bundlesDir: string;
+
+  test.beforeAll(async () => {
+    const bin = getRememoryBin();
+    if (!fs.existsSync(bin)) {
+      test.skip();
+      return;
+    }
+
+    projectDir = createTestProject({ sskr: true });
+    bundlesDir = path.join(projectDir, 'output', 'bundles');
+  });
+
+  test.afterAll(async () => {
+    cleanupProject(projectDir);
+  });
+
+  test('recovers from pasted SSKR shares alongside the page\'s own piece', async ({ page }) => {
+    const [aliceDir, bobDir, carolDir] = extractBundles(bundlesDir, ['Alice', 'Bob', 'Carol']);
+    const recovery = new RecoveryPage(page, aliceDir);
+
+    await recovery.open();
+    await recovery.expectShareCount(1);
+
+    // Bob's whole SSKR.txt, as copied from his bundle
+    const bobSSKR = fs.readFileSync(path.join(bobDir, 'SSKR.txt'), 'utf8');
+    expect(bobSSKR).toMatch(/ur:sskr\//);
+    await recovery.clickPasteButton();
+    await recovery.pasteShare(bobSSKR);
+    await recovery.submitPaste();
+    await recovery.expectShareCount(2);
+
+    // Alice's own piece doesn't count towards the SSKR shares
+    await recovery.expectNeedMoreShares(1);
+
+    // Carol's share as the bytewords alone
+    const carolSSKR = fs.readFileSync(path.join(carolDir, 'SSKR.txt'), 'utf8');
+    const bytewords = carolSSKR.split('YOUR SHARE (BYTEWORDS)\n')[1].trim();
+    await recovery.clickPasteButton();
+    await recovery.pasteShare(bytewords);
+    await recovery.submitPaste();
+    await recovery.expectShareCount(3);
+
+    await recovery.expectRecoveryComplete();
+    await recovery.expectFileCount(3);
+  });
+});
+
+test.describe('Expired bundles', () => {
+  let projectDir: string;
+  let bundlesDir: string;
+
+  test.beforeAll(async () => {
+    const bin = getRememoryBin();
+    if (!fs.existsSync(bin)) {
+      test.skip();
+      return;
+    }
+
+    projectDir = createTestProject({ expired: true });
+    bundlesDir = path.join(projectDir, 'output', 'bundles');
+  });
+
+  test.afterAll(async () => {
+    cleanupProject(projectDir);
+  });
+
+  test('waits for the family to choose to recover with expired pieces', async ({ page }) => {
+    const [aliceDir, bobDir] = extractBundles(bundlesDir, ['Alice', 'Bob']);
+    const recovery = new RecoveryPage(page, aliceDir);
+
+    await recovery.open();
+    await recovery.expectShareCount(1);
+    await recovery.addShares(bobDir);
+    await recovery.expectShareCount(2);
+
+    // Enough pieces, but they are past the expiry date: nothing is decrypted
+    const toast = page.locator('.toast-error').first();
+    await expect(toast).toContainText('2020-01-31');
+    await expect(page.locator('.file-item')).toHaveCount(0);
+
+    await toast.locator('[data-action="use-anyway"]').click();
+    await recovery.expectRecoveryComplete();
+    await recovery.expectFileCount(3);
+  });
+});
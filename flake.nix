@@ -47,7 +47,7 @@
             $out/bin/rememory doc $out/share/man/man1
           '';
 
-          subPackages = [ "cmd/rememory" ];
+          subPackages = [ "cmd/rememory" "cmd/rememory-recover" ];
 
           ldflags = [ "-s" "-w" "-X main.version=${self.shortRev or "dev"}" ];
         };
@@ -0,0 +1,486 @@
+// Package analyze looks for single points of failure in a recovery setup:
+// pieces that are all needed, places or households that hold too many of
+// them, encrypted files that only exist outside the bundles or all in one
+// place, and friends nobody can reach.
+package analyze
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/eljojo/rememory/internal/core"
+	"github.com/eljojo/rememory/internal/project"
+)
+
+// Severity ranks a risk.
+type Severity string
+
+const (
+	// High risks can make recovery impossible, or possible for the wrong people.
+	High Severity = "high"
+	// Medium risks weaken the setup or leave part of it unchecked.
+	Medium Severity = "medium"
+)
+
+// Holder is what the analysis knows about one friend.
+type Holder struct {
+	Name      string
+	Contact   string
+	Location  string // Where they keep their bundle; empty if unknown
+	Household string // A tag shared by friends who live together; empty if unknown
+	Bundle    bool   // Their bundle exists
+	Manifest  bool   // Their bundle carries the encrypted files (MANIFEST.age or inside recover.html)
+	Group     int    // 1-indexed group in a grouped project; 0 otherwise
+	Pieces    int    // Pieces they hold; 0 counts as 1
+}
+
+// pieces is how many pieces the holder counts for in recovery.
+func (h Holder) pieces() int {
+	return max(h.Pieces, 1)
+}
+
+// pieces is how many pieces the holders picked by in hold together.
+func (c Config) pieces(in func(Holder) bool) int {
+	n := 0
+	for _, h := range c.Holders {
+		if in(h) {
+			n += h.pieces()
+		}
+	}
+	return n
+}
+
+// Config is the setup to analyze.
+type Config struct {
+	Threshold   int   // Pieces needed; with Groups, the groups needed
+	Groups      []int // Each group's threshold, in a grouped project
+	Holders     []Holder
+	Privacy     project.Privacy // What the bundles tell about the friends
+	Sealed      bool
+	RecoveryURL string // Where the QR codes point; empty for the default
+}
+
+// Risk is one problem found, with a suggested fix.
+type Risk struct {
+	Severity Severity `json:"severity"`
+	Check    string   `json:"check"`
+	Message  string   `json:"message"`
+	Fix      string   `json:"fix"`
+}
+
+// Report is the outcome of an analysis.
+type Report struct {
+	Risks  []Risk   `json:"risks"`
+	Passed []string `json:"passed"`
+}
+
+// Correlated returns the high risks that come from where pieces are kept:
+// one place or household that can recover alone or stop recovery, and every
+// copy of the encrypted files in one place. These are worth a warning every
+// time bundles are made.
+func (r *Report) Correlated() []Risk {
+	var out []Risk
+	for _, risk := range r.Risks {
+		if risk.Severity == High && (risk.Check == "location" || risk.Check == "household" || risk.Check == "copies") {
+			out = append(out, risk)
+		}
+	}
+	return out
+}
+
+// Count returns how many risks have the given severity.
+func (r *Report) Count(s Severity) int {
+	n := 0
+	for _, risk := range r.Risks {
+		if risk.Severity == s {
+			n++
+		}
+	}
+	return n
+}
+
+func (r *Report) risk(s Severity, check, fix, format string, args ...any) {
+	r.Risks = append(r.Risks, Risk{Severity: s, Check: check, Message: fmt.Sprintf(format, args...), Fix: fix})
+}
+
+func (r *Report) pass(format string, args ...any) {
+	r.Passed = append(r.Passed, fmt.Sprintf(format, args...))
+}
+
+// Analyze checks the setup and returns what could go wrong, high risks first.
+func Analyze(c Config) *Report {
+	r := &Report{Risks: []Risk{}, Passed: []string{}}
+	checkThreshold(r, c)
+	checkLocations(r, c)
+	checkHouseholds(r, c)
+	checkContacts(r, c)
+	checkManifest(r, c)
+	checkCopies(r, c)
+	checkHosting(r, c)
+	sort.SliceStable(r.Risks, func(i, j int) bool {
+		return r.Risks[i].Severity == High && r.Risks[j].Severity != High
+	})
+	return r
+}
+
+// recovers reports whether the holders picked by in can recover together.
+func (c Config) recovers(in func(Holder) bool) bool {
+	if len(c.Groups) == 0 {
+		return c.pieces(in) >= c.Threshold
+	}
+	counts := make([]int, len(c.Groups)+1)
+	for _, h := range c.Holders {
+		if in(h) && h.Group > 0 && h.Group < len(counts) {
+			counts[h.Group]++
+		}
+	}
+	complete := 0
+	for g, k := range c.Groups {
+		if counts[g+1] >= k {
+			complete++
+		}
+	}
+	return complete >= c.Threshold
+}
+
+// short says why the pieces left after a loss aren't enough.
+func (c Config) short() string {
+	if len(c.Groups) == 0 {
+		return fmt.Sprintf("and %d are needed", c.Threshold)
+	}
+	return fmt.Sprintf("which don't make up %d complete group%s", c.Threshold, plural(c.Threshold))
+}
+
+func checkThreshold(r *Report, c Config) {
+	if len(c.Groups) > 0 {
+		checkGroupThresholds(r, c)
+		return
+	}
+	n, k := c.pieces(func(Holder) bool { return true }), c.Threshold
+	switch spare := spareBundles(c.Holders, k); {
+	case spare <= 0:
+		r.risk(High, "threshold",
+			"add a friend, or lower the threshold so one lost bundle isn't fatal",
+			"every piece is needed (%d of %d): if any one friend loses their bundle, nothing can be recovered", k, n)
+	default:
+		r.pass("%d bundle%s can be lost and recovery still works", spare, plural(spare))
+	}
+	if k == 2 && len(c.Holders) >= 5 {
+		r.risk(Medium, "threshold",
+			"raise the threshold so recovery takes more than two people",
+			"any 2 of your %d friends can recover without the others", len(c.Holders))
+	}
+}
+
+// spareBundles is how many bundles can be lost with threshold pieces still
+// left, when the ones lost are those holding the most pieces.
+func spareBundles(holders []Holder, threshold int) int {
+	weights := make([]int, len(holders))
+	left := 0
+	for i, h := range holders {
+		weights[i] = h.pieces()
+		left += weights[i]
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(weights)))
+	spare := 0
+	for _, w := range weights {
+		if left-w < threshold {
+			break
+		}
+		left -= w
+		spare++
+	}
+	return spare
+}
+
+// checkGroupThresholds is checkThreshold for a grouped project: recovery
+// stops once enough groups are each short of their threshold.
+func checkGroupThresholds(r *Report, c Config) {
+	sizes := make([]int, len(c.Groups))
+	for _, h := range c.Holders {
+		if h.Group > 0 && h.Group <= len(sizes) {
+			sizes[h.Group-1]++
+		}
+	}
+	// Losses that leave each group short, cheapest first
+	costs := make([]int, len(c.Groups))
+	fewest := make([]int, len(c.Groups))
+	for g, k := range c.Groups {
+		costs[g] = sizes[g] - k + 1
+		fewest[g] = k
+	}
+	sort.Ints(costs)
+	sort.Ints(fewest)
+	fatal := 0
+	for _, cost := range costs[:max(len(costs)-c.Threshold+1, 0)] {
+		fatal += cost
+	}
+	switch spare := fatal - 1; {
+	case spare <= 0:
+		r.risk(High, "threshold",
+			"add a friend to the groups that need every member, or lower their threshold",
+			"losing a single bundle can leave too few complete groups (%d needed of %d), and then nothing can be recovered", c.Threshold, len(c.Groups))
+	default:
+		r.pass("%d bundle%s can be lost and recovery still works", spare, plural(spare))
+	}
+	least := 0
+	for _, k := range fewest[:min(c.Threshold, len(fewest))] {
+		least += k
+	}
+	if least == 2 && len(c.Holders) >= 5 {
+		r.risk(Medium, "threshold",
+			"raise a group's threshold or the number of groups needed",
+			"as few as 2 of your %d friends can recover without the others", len(c.Holders))
+	}
+}
+
+// checkLocations looks for one place that holds enough pieces to recover, or
+// whose loss leaves too few. Friends without a location count as being
+// somewhere else, so the loss check is optimistic about them.
+func checkLocations(r *Report, c Config) {
+	counts := make(map[string]int)
+	names := make(map[string]string) // Normalized → as first written
+	var unknown []string
+	for _, h := range c.Holders {
+		loc := normalizeLocation(h.Location)
+		if loc == "" {
+			unknown = append(unknown, h.Name)
+			continue
+		}
+		if _, ok := names[loc]; !ok {
+			names[loc] = strings.TrimSpace(h.Location)
+		}
+		counts[loc] += h.pieces()
+	}
+	if len(counts) == 0 {
+		r.risk(Medium, "location",
+			"add a location to each friend in project.yml (for example location: Lisbon)",
+			"no friend has a location, so nothing says the pieces aren't all in one place")
+		return
+	}
+
+	locs := make([]string, 0, len(counts))
+	for loc := range counts {
+		locs = append(locs, loc)
+	}
+	sort.Strings(locs)
+
+	n := c.pieces(func(Holder) bool { return true })
+	found := false
+	for _, loc := range locs {
+		inside := func(h Holder) bool { return normalizeLocation(h.Location) == loc }
+		if c.recovers(inside) {
+			found = true
+			r.risk(High, "location",
+				"spread these bundles out so no one place holds enough pieces",
+				"friends in %s hold %d pieces, enough to recover without anyone else — one break-in there could take them all", names[loc], counts[loc])
+		}
+		if !c.recovers(func(h Holder) bool { return !inside(h) }) {
+			found = true
+			r.risk(High, "location",
+				"give a piece to someone who lives elsewhere",
+				"if something happens in %s, the friends elsewhere hold only %d piece%s, %s", names[loc], n-counts[loc], plural(n-counts[loc]), c.short())
+		}
+	}
+	if !found {
+		r.pass("no one place (of %d) holds enough pieces to recover, or to stop recovery", len(locs))
+	}
+	if len(unknown) > 0 {
+		r.risk(Medium, "location",
+			"add a location for them in project.yml",
+			"%s %s no location, so the check above assumes they're somewhere else", strings.Join(unknown, ", "), hasHave(len(unknown)))
+	}
+}
+
+// checkHouseholds is checkLocations for people who live together: one
+// household can pool its pieces without anyone noticing, and one fire can
+// take them all. Households are optional, so friends without one are skipped.
+func checkHouseholds(r *Report, c Config) {
+	members := make(map[string][]string)
+	names := make(map[string]string) // Normalized → as first written
+	for _, h := range c.Holders {
+		key := normalizeLocation(h.Household)
+		if key == "" {
+			continue
+		}
+		if _, ok := names[key]; !ok {
+			names[key] = strings.TrimSpace(h.Household)
+		}
+		members[key] = append(members[key], h.Name)
+	}
+	if len(members) == 0 {
+		return
+	}
+
+	keys := make([]string, 0, len(members))
+	for key := range members {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	n := c.pieces(func(Holder) bool { return true })
+	found := false
+	for _, key := range keys {
+		inside := func(h Holder) bool { return normalizeLocation(h.Household) == key }
+		count := c.pieces(inside)
+		if c.recovers(inside) {
+			found = true
+			r.risk(High, "household",
+				"give these pieces to people in different households",
+				"%s live together (%s) and hold %d pieces, enough to recover without anyone else", strings.Join(members[key], ", "), names[key], count)
+		}
+		if !c.recovers(func(h Holder) bool { return !inside(h) }) {
+			found = true
+			r.risk(High, "household",
+				"give a piece to someone outside this household",
+				"if something happens to the %s household, everyone else holds only %d piece%s, %s", names[key], n-count, plural(n-count), c.short())
+		}
+	}
+	if !found {
+		r.pass("no one household (of %d) holds enough pieces to recover, or to stop recovery", len(keys))
+	}
+}
+
+func checkContacts(r *Report, c Config) {
+	switch c.Privacy {
+	case project.PrivacyAnonymous:
+		r.risk(Medium, "contacts",
+			"make sure someone you trust, such as an executor or lawyer, knows who holds the pieces",
+			"bundles are anonymous: nobody who finds one knows who else holds a piece")
+		return
+	case project.PrivacyFirstNames:
+		r.risk(Medium, "contacts",
+			"make sure the friends know how to reach each other, or set privacy: full",
+			"bundles list the other friends by first name only, with no way to reach them")
+		return
+	}
+	var missing []string
+	for _, h := range c.Holders {
+		if strings.TrimSpace(h.Contact) == "" {
+			missing = append(missing, h.Name)
+		}
+	}
+	if len(missing) == 0 {
+		r.pass("every friend has contact details in the other bundles")
+		return
+	}
+	r.risk(Medium, "contacts",
+		"add contact details in project.yml and regenerate the bundles",
+		"%s %s no contact details, so the others can't reach them", strings.Join(missing, ", "), hasHave(len(missing)))
+}
+
+func checkManifest(r *Report, c Config) {
+	if !c.Sealed {
+		r.risk(Medium, "manifest",
+			"run 'rememory seal', then analyze again",
+			"not sealed yet: the bundles and encrypted files weren't checked")
+		return
+	}
+	var missing []string
+	carried := 0
+	for _, h := range c.Holders {
+		switch {
+		case !h.Bundle:
+			missing = append(missing, h.Name)
+		case h.Manifest:
+			carried++
+		}
+	}
+	if len(missing) > 0 {
+		r.risk(Medium, "bundles",
+			"run 'rememory bundle' to make them",
+			"no bundle for %s", strings.Join(missing, ", "))
+	}
+	switch {
+	case carried == 0 && len(missing) < len(c.Holders):
+		r.risk(High, "manifest",
+			"run 'rememory bundle' so every bundle carries MANIFEST.age",
+			"no bundle carries the encrypted files: recovery depends on a copy kept somewhere else, such as online")
+	case carried > 0:
+		r.pass("%d of %d bundles carry the encrypted files", carried, len(c.Holders)-len(missing))
+	}
+}
+
+// checkCopies looks for every bundle that carries the encrypted files being
+// in one place or household, where one disaster leaves nothing to decrypt
+// even if enough pieces survive elsewhere.
+func checkCopies(r *Report, c Config) {
+	var carriers []Holder
+	for _, h := range c.Holders {
+		if h.Bundle && h.Manifest {
+			carriers = append(carriers, h)
+		}
+	}
+	if len(carriers) == 0 {
+		return
+	}
+	for _, place := range []struct {
+		of   func(Holder) string
+		what string
+	}{
+		{func(h Holder) string { return h.Location }, "in %s"},
+		{func(h Holder) string { return h.Household }, "in the %s household"},
+	} {
+		if sharedValue(carriers, place.of) == "" {
+			continue
+		}
+		if sharedValue(c.Holders, place.of) != "" {
+			return // Everyone is there: checkLocations and checkHouseholds already say so
+		}
+		where := fmt.Sprintf(place.what, strings.TrimSpace(place.of(carriers[0])))
+		r.risk(High, "copies",
+			"give a bundle with the encrypted files to someone elsewhere, or keep a copy of MANIFEST.age in another place",
+			"every bundle that carries the encrypted files is %s: if something happens there, the pieces elsewhere have nothing to open", where)
+		return
+	}
+	if len(carriers) > 1 {
+		r.pass("the encrypted files aren't all in one place")
+	}
+}
+
+// sharedValue returns the normalized value every holder has in common, or
+// "" if any holder's is empty or different.
+func sharedValue(holders []Holder, of func(Holder) string) string {
+	shared := normalizeLocation(of(holders[0]))
+	for _, h := range holders[1:] {
+		if normalizeLocation(of(h)) != shared {
+			return ""
+		}
+	}
+	return shared
+}
+
+func checkHosting(r *Report, c Config) {
+	if c.RecoveryURL == "" || c.RecoveryURL == core.DefaultRecoveryURL {
+		return
+	}
+	host := c.RecoveryURL
+	if u, err := url.Parse(c.RecoveryURL); err == nil && u.Host != "" {
+		host = u.Host
+	}
+	r.risk(Medium, "hosting",
+		"keep the site up, or run 'rememory bundle' without --recovery-url; either way, tell friends recover.html is in their bundle",
+		"the QR codes open recover.html on %s, which has to stay online for them to work", host)
+}
+
+// normalizeLocation makes "Lisbon", " lisbon " and "LISBON" the same place,
+// and does the same for household tags.
+func normalizeLocation(s string) string {
+	return strings.ToLower(strings.Join(strings.Fields(s), " "))
+}
+
+func plural(n int) string {
+	if n == 1 {
+		return ""
+	}
+	return "s"
+}
+
+func hasHave(n int) string {
+	if n == 1 {
+		return "has"
+	}
+	return "have"
+}
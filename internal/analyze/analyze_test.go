@@ -0,0 +1,210 @@
+package analyze
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/eljojo/rememory/internal/project"
+)
+
+// holders makes sealed holders with bundles that carry the encrypted files,
+// one per location.
+func holders(locations ...string) []Holder {
+	hs := make([]Holder, len(locations))
+	for i, loc := range locations {
+		hs[i] = Holder{Name: string(rune('A' + i)), Contact: "x@example.com", Location: loc, Bundle: true, Manifest: true}
+	}
+	return hs
+}
+
+// find returns the risks from a check, as "severity: message" lines.
+func find(r *Report, check string) []string {
+	var out []string
+	for _, risk := range r.Risks {
+		if risk.Check == check {
+			out = append(out, string(risk.Severity)+": "+risk.Message)
+		}
+	}
+	return out
+}
+
+func TestAnalyzeHealthy(t *testing.T) {
+	r := Analyze(Config{Threshold: 2, Holders: holders("Lisbon", "Porto", "Madrid"), Sealed: true})
+	if len(r.Risks) != 0 {
+		t.Errorf("risks = %+v", r.Risks)
+	}
+	if len(r.Passed) == 0 {
+		t.Error("expected passed checks to be listed")
+	}
+}
+
+func TestAnalyzeThreshold(t *testing.T) {
+	r := Analyze(Config{Threshold: 3, Holders: holders("a", "b", "c"), Sealed: true})
+	if got := find(r, "threshold"); len(got) != 1 || !strings.HasPrefix(got[0], "high: every piece is needed") {
+		t.Errorf("3 of 3: %q", got)
+	}
+
+	r = Analyze(Config{Threshold: 2, Holders: holders("a", "b", "c", "d", "e"), Sealed: true})
+	if got := find(r, "threshold"); len(got) != 1 || !strings.HasPrefix(got[0], "medium: any 2") {
+		t.Errorf("2 of 5: %q", got)
+	}
+}
+
+func TestAnalyzeGroups(t *testing.T) {
+	// 2 of 3 family members and 1 of 2 lawyers
+	hs := holders("Lisbon", "Porto", "Madrid", "Paris", "Rome")
+	for i := range hs {
+		hs[i].Group = 1
+		if i >= 3 {
+			hs[i].Group = 2
+		}
+	}
+	r := Analyze(Config{Threshold: 2, Groups: []int{2, 1}, Holders: hs, Sealed: true})
+	if got := find(r, "threshold"); len(got) != 0 {
+		t.Errorf("threshold: %q", got)
+	}
+	if got := find(r, "location"); len(got) != 0 {
+		t.Errorf("location: %q", got)
+	}
+
+	// Every family member is needed, so losing one bundle stops recovery
+	r = Analyze(Config{Threshold: 2, Groups: []int{3, 1}, Holders: hs, Sealed: true})
+	if got := find(r, "threshold"); len(got) != 1 || !strings.HasPrefix(got[0], "high: losing a single bundle") {
+		t.Errorf("3 of 3 family: %q", got)
+	}
+
+	// Two family members and a lawyer in Lisbon could recover alone; losing
+	// it leaves one family member, so no complete family group
+	hs[1].Location, hs[3].Location = "Lisbon", "Lisbon"
+	r = Analyze(Config{Threshold: 2, Groups: []int{2, 1}, Holders: hs, Sealed: true})
+	if got := find(r, "location"); len(got) != 2 || !strings.Contains(got[0], "enough to recover") || !strings.Contains(got[1], "which don't make up 2 complete groups") {
+		t.Errorf("Lisbon: %q", got)
+	}
+}
+
+func TestAnalyzeLocations(t *testing.T) {
+	tests := []struct {
+		name      string
+		threshold int
+		locations []string
+		want      []string
+	}{
+		{"spread out", 2, []string{"Lisbon", "Porto", "Madrid"}, nil},
+		{"enough in one place", 2, []string{"Lisbon", " lisbon", "Madrid", "Porto"}, []string{"high: friends in Lisbon hold 2 pieces"}},
+		{"both in one place", 2, []string{"Lisbon", "LISBON", "Madrid"}, []string{"high: friends in Lisbon hold 2 pieces", "high: if something happens in Lisbon"}},
+		{"too many lost in one place", 3, []string{"Lisbon", "Lisbon", "Porto", "Madrid"}, []string{"high: if something happens in Lisbon"}},
+		{"partly unknown", 2, []string{"Lisbon", "", "Madrid"}, []string{"medium: B has no location"}},
+		{"all unknown", 2, []string{"", "", ""}, []string{"medium: no friend has a location"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := find(Analyze(Config{Threshold: tt.threshold, Holders: holders(tt.locations...), Sealed: true}), "location")
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %q, want %q", got, tt.want)
+			}
+			for i := range got {
+				if !strings.HasPrefix(got[i], tt.want[i]) {
+					t.Errorf("got %q, want prefix %q", got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestAnalyzeManifestAndHosting(t *testing.T) {
+	hs := holders("a", "b", "c")
+	for i := range hs {
+		hs[i].Manifest = false
+	}
+	hs[2].Bundle = false
+	r := Analyze(Config{Threshold: 2, Holders: hs, Sealed: true, RecoveryURL: "https://family.example.com/recover.html"})
+
+	if got := find(r, "manifest"); len(got) != 1 || !strings.HasPrefix(got[0], "high: no bundle carries") {
+		t.Errorf("manifest: %q", got)
+	}
+	if got := find(r, "bundles"); len(got) != 1 || !strings.Contains(got[0], "no bundle for C") {
+		t.Errorf("bundles: %q", got)
+	}
+	if got := find(r, "hosting"); len(got) != 1 || !strings.Contains(got[0], "family.example.com") {
+		t.Errorf("hosting: %q", got)
+	}
+	if r.Risks[0].Severity != High {
+		t.Errorf("high risks should come first: %+v", r.Risks)
+	}
+}
+
+func TestAnalyzeContacts(t *testing.T) {
+	hs := holders("a", "b", "c")
+	hs[1].Contact = ""
+	if got := find(Analyze(Config{Threshold: 2, Holders: hs, Sealed: true}), "contacts"); len(got) != 1 || !strings.Contains(got[0], "B has no contact") {
+		t.Errorf("contacts: %q", got)
+	}
+	if got := find(Analyze(Config{Threshold: 2, Holders: hs, Sealed: true, Privacy: project.PrivacyAnonymous}), "contacts"); len(got) != 1 || !strings.Contains(got[0], "anonymous") {
+		t.Errorf("anonymous: %q", got)
+	}
+	if got := find(Analyze(Config{Threshold: 2, Holders: hs, Sealed: true, Privacy: project.PrivacyFirstNames}), "contacts"); len(got) != 1 || !strings.Contains(got[0], "first name only") {
+		t.Errorf("first names: %q", got)
+	}
+}
+
+func TestAnalyzeHouseholds(t *testing.T) {
+	tests := []struct {
+		name       string
+		threshold  int
+		households []string
+		want       []string
+	}{
+		{"none tagged", 2, []string{"", "", ""}, nil},
+		{"couple can recover", 2, []string{"Smiths", "smiths", "", ""}, []string{"high: A, B live together (Smiths) and hold 2 pieces"}},
+		{"couple below threshold", 3, []string{"Smiths", "Smiths", "", "", ""}, nil},
+		{"one household, too many lost", 3, []string{"Smiths", "Smiths", "", ""}, []string{"high: if something happens to the Smiths household"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hs := holders(make([]string, len(tt.households))...)
+			for i, household := range tt.households {
+				hs[i].Location = string(rune('a' + i))
+				hs[i].Household = household
+			}
+			got := find(Analyze(Config{Threshold: tt.threshold, Holders: hs, Sealed: true}), "household")
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %q, want %q", got, tt.want)
+			}
+			for i := range got {
+				if !strings.HasPrefix(got[i], tt.want[i]) {
+					t.Errorf("got %q, want prefix %q", got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestAnalyzeCopies(t *testing.T) {
+	// Only the two Lisbon bundles carry the encrypted files
+	hs := holders("Lisbon", "lisbon", "Porto")
+	hs[2].Manifest = false
+	r := Analyze(Config{Threshold: 2, Holders: hs, Sealed: true})
+	if got := find(r, "copies"); len(got) != 1 || !strings.Contains(got[0], "is in Lisbon") {
+		t.Errorf("copies: %q", got)
+	}
+	if len(r.Correlated()) == 0 {
+		t.Error("expected the copies risk among the correlated risks")
+	}
+
+	// The same, by household
+	hs = holders("a", "b", "c")
+	hs[0].Household, hs[1].Household = "Smiths", "Smiths"
+	hs[2].Manifest = false
+	if got := find(Analyze(Config{Threshold: 2, Holders: hs, Sealed: true}), "copies"); len(got) != 1 || !strings.Contains(got[0], "Smiths household") {
+		t.Errorf("copies by household: %q", got)
+	}
+
+	// Everyone in one place is already a location risk
+	if got := find(Analyze(Config{Threshold: 2, Holders: holders("Lisbon", "Lisbon", "Lisbon"), Sealed: true}), "copies"); len(got) != 0 {
+		t.Errorf("copies when everyone is in Lisbon: %q", got)
+	}
+	// Spread out: none
+	if got := find(Analyze(Config{Threshold: 2, Holders: holders("Lisbon", "Porto", "Madrid"), Sealed: true}), "copies"); len(got) != 0 {
+		t.Errorf("copies when spread out: %q", got)
+	}
+}
@@ -0,0 +1,234 @@
+// Package beacon writes and reads a project's beacon: a small signed file,
+// published online, that says which seal of the project is current. A
+// holder who is online can fetch it and tell whether their bundle is still
+// the one to keep, without asking the owner. Recovery never needs it.
+//
+// The beacon holds nothing secret, only dates and fingerprints, as listed
+// in SUPERSEDED.txt. It is signed with an Ed25519 key that stays the same
+// from one seal to the next; every bundle carries the public key, so a
+// bundle of an earlier seal can still check a newer beacon.
+package beacon
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/eljojo/rememory/internal/core"
+	"github.com/eljojo/rememory/internal/rotation"
+)
+
+const (
+	// FileName is the beacon's name, in output/ and where it's published.
+	FileName = "beacon.txt"
+
+	// MaxSize is the most that is read of a published beacon.
+	MaxSize = 64 << 10
+
+	beaconHeader = "rememory-beacon-v1"
+	footerMarker = "SIGNED BEACON (machine-parseable)"
+	keyHeader    = "rememory-beacon-key-v1"
+)
+
+// Beacon says which seal of a project is current, and which it replaced.
+type Beacon struct {
+	Published   time.Time
+	Sealed      time.Time
+	Fingerprint string                // The current seal, as rotation.Fingerprint gives it
+	Superseded  []rotation.Generation // Earlier seals, out of date
+	Signature   string                // Base64 signature over the fields above
+}
+
+// GenerateKey makes a new signing key.
+func GenerateKey() (ed25519.PrivateKey, error) {
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("generating beacon key: %w", err)
+	}
+	return priv, nil
+}
+
+// EncodeKey returns the contents of a key file for priv.
+func EncodeKey(priv ed25519.PrivateKey) []byte {
+	return []byte(keyHeader + "\n" + base64.StdEncoding.EncodeToString(priv.Seed()) + "\n")
+}
+
+// ParseKey reads a key file written by EncodeKey.
+func ParseKey(data []byte) (ed25519.PrivateKey, error) {
+	header, encoded, _ := strings.Cut(strings.TrimSpace(string(data)), "\n")
+	if strings.TrimSpace(header) != keyHeader {
+		return nil, fmt.Errorf("not a beacon key")
+	}
+	seed, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil || len(seed) != ed25519.SeedSize {
+		return nil, fmt.Errorf("damaged beacon key")
+	}
+	defer core.Wipe(seed)
+	return ed25519.NewKeyFromSeed(seed), nil
+}
+
+// PublicKey returns priv's public key as it's printed in bundles.
+func PublicKey(priv ed25519.PrivateKey) string {
+	return base64.StdEncoding.EncodeToString(priv.Public().(ed25519.PublicKey))
+}
+
+// Sign makes the beacon for the seal whose MANIFEST.age has the given
+// checksum, published now.
+func Sign(priv ed25519.PrivateKey, published, sealed time.Time, manifestChecksum string, superseded []rotation.Generation) *Beacon {
+	b := &Beacon{
+		Published:   published.UTC(),
+		Sealed:      sealed.UTC(),
+		Fingerprint: rotation.Fingerprint(manifestChecksum),
+		Superseded:  superseded,
+	}
+	b.Signature = base64.StdEncoding.EncodeToString(ed25519.Sign(priv, b.message()))
+	return b
+}
+
+// Verify checks that the beacon was signed with publicKey, the one printed
+// in a bundle.
+func (b *Beacon) Verify(publicKey string) error {
+	pub, err := base64.StdEncoding.DecodeString(publicKey)
+	if err != nil || len(pub) != ed25519.PublicKeySize {
+		return fmt.Errorf("invalid beacon key")
+	}
+	sig, err := base64.StdEncoding.DecodeString(b.Signature)
+	if err != nil || len(sig) != ed25519.SignatureSize {
+		return fmt.Errorf("invalid signature in %s", FileName)
+	}
+	if !ed25519.Verify(pub, b.message(), sig) {
+		return fmt.Errorf("%s signature does not match the bundle's key — it was changed, or isn't this project's", FileName)
+	}
+	return nil
+}
+
+// Current reports whether the bundle with the given manifest checksum
+// belongs to the current seal.
+func (b *Beacon) Current(manifestChecksum string) bool {
+	return rotation.Fingerprint(manifestChecksum) == b.Fingerprint
+}
+
+// Supersedes returns the earlier seal the bundle with the given manifest
+// checksum belongs to, if the beacon lists it.
+func (b *Beacon) Supersedes(manifestChecksum string) (rotation.Generation, bool) {
+	fingerprint := rotation.Fingerprint(manifestChecksum)
+	for _, g := range b.Superseded {
+		if g.Fingerprint == fingerprint {
+			return g, true
+		}
+	}
+	return rotation.Generation{}, false
+}
+
+// message is what the signature covers.
+func (b *Beacon) message() []byte {
+	var sb strings.Builder
+	sb.WriteString(beaconHeader + "\n")
+	writeFields(&sb, b)
+	return []byte(sb.String())
+}
+
+func writeFields(sb *strings.Builder, b *Beacon) {
+	sb.WriteString(fmt.Sprintf("published: %s\n", b.Published.UTC().Format(time.RFC3339)))
+	sb.WriteString(fmt.Sprintf("sealed: %s\n", b.Sealed.UTC().Format(time.RFC3339)))
+	sb.WriteString(fmt.Sprintf("fingerprint: %s\n", b.Fingerprint))
+	for _, g := range b.Superseded {
+		sb.WriteString(fmt.Sprintf("superseded: %s %s\n", g.Sealed.UTC().Format(time.RFC3339), g.Fingerprint))
+	}
+}
+
+// Text returns beacon.txt: an explanation for whoever opens it in a
+// browser, followed by the signed fields. Like SUPERSEDED.txt, it is in
+// English only.
+func (b *Beacon) Text() string {
+	var sb strings.Builder
+
+	sb.WriteString("REMEMORY BEACON\n")
+	sb.WriteString(strings.Repeat("=", 80) + "\n\n")
+	sb.WriteString("This file tells whoever keeps a ReMemory bundle whether it is still the\n")
+	sb.WriteString("current one. It holds nothing secret: only when the bundles were sealed,\n")
+	sb.WriteString("and the fingerprints that tell them apart.\n\n")
+
+	sb.WriteString("To tell which seal a bundle belongs to, look at checksum-manifest at the\n")
+	sb.WriteString("end of its README. The fingerprint is how it starts, after \"sha256:\".\n\n")
+	sb.WriteString("                SEALED       FINGERPRINT\n")
+	sb.WriteString(fmt.Sprintf("  Current:      %s   %s\n", b.Sealed.Format(core.DateFormat), b.Fingerprint))
+	for i, g := range b.Superseded {
+		label := ""
+		if i == 0 {
+			label = "Out of date:"
+		}
+		sb.WriteString(fmt.Sprintf("  %-12s  %s   %s\n", label, g.Sealed.Format(core.DateFormat), g.Fingerprint))
+	}
+	sb.WriteString("\nA bundle that is out of date shouldn't be used for recovery; ask the\n")
+	sb.WriteString("owner for the current one. 'rememory beacon check' checks this file's\n")
+	sb.WriteString("signature with the key printed in the bundle.\n\n")
+	sb.WriteString(fmt.Sprintf("Published %s.\n\n", b.Published.Format(core.DateFormat)))
+
+	sb.WriteString(strings.Repeat("=", 80) + "\n")
+	sb.WriteString(footerMarker + "\n")
+	sb.WriteString(strings.Repeat("=", 80) + "\n")
+	sb.WriteString(beaconHeader + "\n")
+	writeFields(&sb, b)
+	sb.WriteString(fmt.Sprintf("signature: %s\n", b.Signature))
+	sb.WriteString(strings.Repeat("=", 80) + "\n")
+	return sb.String()
+}
+
+var fieldRegex = regexp.MustCompile(`^([a-z-]+):\s*(.*)$`)
+
+// Parse reads the signed fields of beacon.txt and verifies the signature
+// with publicKey, the one printed in a bundle.
+func Parse(text, publicKey string) (*Beacon, error) {
+	start := strings.Index(text, footerMarker)
+	if start == -1 {
+		return nil, fmt.Errorf("not a ReMemory beacon")
+	}
+	lines := strings.Split(strings.ReplaceAll(text[start:], "\r\n", "\n"), "\n")
+
+	b := &Beacon{}
+	header := false
+	for _, line := range lines[1:] {
+		line = strings.TrimSpace(line)
+		if line == beaconHeader {
+			header = true
+			continue
+		}
+		m := fieldRegex.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		var err error
+		switch m[1] {
+		case "published":
+			b.Published, err = time.Parse(time.RFC3339, m[2])
+		case "sealed":
+			b.Sealed, err = time.Parse(time.RFC3339, m[2])
+		case "fingerprint":
+			b.Fingerprint = m[2]
+		case "superseded":
+			date, fingerprint, ok := strings.Cut(m[2], " ")
+			g := rotation.Generation{Fingerprint: fingerprint}
+			if g.Sealed, err = time.Parse(time.RFC3339, date); err == nil && !ok {
+				err = fmt.Errorf("missing fingerprint")
+			}
+			b.Superseded = append(b.Superseded, g)
+		case "signature":
+			b.Signature = m[2]
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s line in %s: %w", m[1], FileName, err)
+		}
+	}
+	if !header {
+		return nil, fmt.Errorf("unrecognized beacon in %s", FileName)
+	}
+	if err := b.Verify(publicKey); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
@@ -0,0 +1,89 @@
+package beacon
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/eljojo/rememory/internal/rotation"
+)
+
+const (
+	oldChecksum = "sha256:3f9a12c0b7e455d1aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"
+	newChecksum = "sha256:ab12cd34ef560789bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb"
+)
+
+func testBeacon(t *testing.T) (*Beacon, string) {
+	t.Helper()
+	priv, err := GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	superseded := []rotation.Generation{{Sealed: time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC), Fingerprint: rotation.Fingerprint(oldChecksum)}}
+	b := Sign(priv, time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC), time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC), newChecksum, superseded)
+	return b, PublicKey(priv)
+}
+
+func TestBeaconRoundTrip(t *testing.T) {
+	b, key := testBeacon(t)
+	text := b.Text()
+	for _, want := range []string{"Current:      2026-01-02   ab12cd34ef560789", "Out of date:  2025-03-01   3f9a12c0b7e455d1", "Published 2026-02-01."} {
+		if !strings.Contains(text, want) {
+			t.Errorf("text is missing %q", want)
+		}
+	}
+
+	got, err := Parse(text, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Fingerprint != "ab12cd34ef560789" || len(got.Superseded) != 1 || !got.Sealed.Equal(b.Sealed) || !got.Published.Equal(b.Published) {
+		t.Errorf("got %+v", got)
+	}
+	if !got.Current(newChecksum) || got.Current(oldChecksum) {
+		t.Error("Current doesn't tell the seals apart")
+	}
+	if g, ok := got.Supersedes(oldChecksum); !ok || !g.Sealed.Equal(b.Superseded[0].Sealed) {
+		t.Errorf("Supersedes(old) = %v, %v", g, ok)
+	}
+	if _, ok := got.Supersedes(newChecksum); ok {
+		t.Error("beacon supersedes its own seal")
+	}
+}
+
+func TestParseRejectsTampering(t *testing.T) {
+	b, key := testBeacon(t)
+	text := b.Text()
+	for _, edit := range [][2]string{
+		{"fingerprint: ab12cd34ef560789", "fingerprint: 3f9a12c0b7e455d1"},
+		{"superseded: 2025-03-01T10:00:00Z 3f9a12c0b7e455d1\n", ""},
+		{"rememory-beacon-v1\n", ""},
+	} {
+		if _, err := Parse(strings.Replace(text, edit[0], edit[1], 1), key); err == nil {
+			t.Errorf("accepted beacon with %q changed", edit[0])
+		}
+	}
+
+	// Signed by another project's key
+	_, other := testBeacon(t)
+	if _, err := Parse(text, other); err == nil {
+		t.Error("accepted beacon signed with another key")
+	}
+}
+
+func TestKeyRoundTrip(t *testing.T) {
+	priv, err := GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := ParseKey(EncodeKey(priv))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !got.Equal(priv) {
+		t.Error("key changed on the way through its file")
+	}
+	if _, err := ParseKey([]byte("rememory-beacon-key-v1\nnot base64\n")); err == nil {
+		t.Error("accepted a damaged key")
+	}
+}
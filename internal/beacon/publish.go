@@ -0,0 +1,57 @@
+package beacon
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// client is what the beacon is fetched and published with. The beacon is
+// small, so a slow server is given up on rather than waited for.
+var client = &http.Client{Timeout: 30 * time.Second}
+
+// Fetch downloads the beacon published at url.
+func Fetch(url string) (string, error) {
+	resp, err := client.Get(url)
+	if err != nil {
+		return "", fmt.Errorf("fetching beacon: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("fetching beacon: %s answered %s", url, resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, MaxSize+1))
+	if err != nil {
+		return "", fmt.Errorf("fetching beacon: %w", err)
+	}
+	if len(data) > MaxSize {
+		return "", fmt.Errorf("fetching beacon: %s is larger than a beacon can be", url)
+	}
+	return string(data), nil
+}
+
+// Publish uploads the beacon's text to url with an HTTP PUT, as WebDAV
+// servers and storage buckets take it. A non-empty token is sent as a
+// bearer token.
+func Publish(url, token, text string) error {
+	req, err := http.NewRequest(http.MethodPut, url, bytes.NewReader([]byte(text)))
+	if err != nil {
+		return fmt.Errorf("publishing beacon: %w", err)
+	}
+	req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("publishing beacon: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, MaxSize))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("publishing beacon: %s answered %s", url, resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package beacon
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestPublishAndFetch(t *testing.T) {
+	var stored, auth string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPut:
+			data, _ := io.ReadAll(r.Body)
+			stored, auth = string(data), r.Header.Get("Authorization")
+			w.WriteHeader(http.StatusCreated)
+		case http.MethodGet:
+			if r.URL.Path == "/big.txt" {
+				io.WriteString(w, strings.Repeat("x", MaxSize+1))
+				return
+			}
+			if stored == "" {
+				http.NotFound(w, r)
+				return
+			}
+			io.WriteString(w, stored)
+		}
+	}))
+	defer srv.Close()
+
+	if _, err := Fetch(srv.URL + "/beacon.txt"); err == nil {
+		t.Error("fetched a beacon that isn't there")
+	}
+	b, key := testBeacon(t)
+	if err := Publish(srv.URL+"/beacon.txt", "s3cret", b.Text()); err != nil {
+		t.Fatal(err)
+	}
+	if auth != "Bearer s3cret" {
+		t.Errorf("Authorization: got %q", auth)
+	}
+	text, err := Fetch(srv.URL + "/beacon.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Parse(text, key); err != nil {
+		t.Errorf("published beacon doesn't check: %v", err)
+	}
+	if _, err := Fetch(srv.URL + "/big.txt"); err == nil {
+		t.Error("fetched a beacon larger than MaxSize")
+	}
+}
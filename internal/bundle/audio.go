@@ -0,0 +1,147 @@
+package bundle
+
+import (
+	"bytes"
+	"embed"
+	"encoding/binary"
+	"fmt"
+	"strings"
+
+	"github.com/eljojo/rememory/internal/core"
+)
+
+// AudioFilename holds a friend's piece read aloud as digit groups, in
+// bundles of projects that set audio.
+const AudioFilename = "PIECE.wav"
+
+// The recordings are 8 kHz, 8-bit unsigned mono PCM, as published with
+// github.com/dchest/captcha (MIT; see sounds/LICENSE).
+//
+//go:embed sounds/*.wav sounds/*/*.wav
+var soundsFS embed.FS
+
+const (
+	audioRate    = 8000
+	audioSilence = 0x80 // The zero level of unsigned 8-bit PCM
+)
+
+// Pauses, in samples. Digits are read slowly enough to write down.
+const (
+	pauseAfterBeep  = audioRate * 6 / 10
+	pauseAfterDigit = audioRate / 2
+	pauseAfterGroup = audioRate * 2
+)
+
+// audioLang returns the recordings used for a bundle language: the bundle
+// language when there are recordings for it, otherwise English.
+func audioLang(lang string) string {
+	switch lang {
+	case "pt":
+		return "pt"
+	case "zh-TW":
+		return "zh"
+	default:
+		return "en"
+	}
+}
+
+// GenerateAudio returns PIECE.wav for a share: its 18 digit groups read
+// aloud by ReadDigitsAloud. The digits are the ones README.txt prints, so
+// whoever listens types them where digits are accepted.
+func GenerateAudio(share *core.Share, lang string) ([]byte, error) {
+	groups, err := share.Digits()
+	if err != nil {
+		return nil, err
+	}
+	return ReadDigitsAloud(groups, lang)
+}
+
+// ReadDigitsAloud returns a WAV file reading groups of digits aloud, in the
+// bundle language when there are recordings for it. Three beeps start and
+// end the recording, and one beep comes before each group.
+func ReadDigitsAloud(groups []string, lang string) ([]byte, error) {
+	for _, group := range groups {
+		if strings.Trim(group, "0123456789") != "" {
+			return nil, fmt.Errorf("%q isn't a group of digits", group)
+		}
+	}
+	beep, err := readSound("sounds/beep.wav")
+	if err != nil {
+		return nil, err
+	}
+	var digits [10][]byte
+	for d := range digits {
+		if digits[d], err = readSound(fmt.Sprintf("sounds/%s/%d.wav", audioLang(lang), d)); err != nil {
+			return nil, err
+		}
+	}
+
+	var pcm bytes.Buffer
+	silence := func(n int) { pcm.Write(bytes.Repeat([]byte{audioSilence}, n)) }
+	beeps := func() {
+		for range 3 {
+			pcm.Write(beep)
+			silence(audioRate / 5)
+		}
+	}
+
+	beeps()
+	silence(pauseAfterGroup)
+	for _, group := range groups {
+		pcm.Write(beep)
+		silence(pauseAfterBeep)
+		for _, c := range group {
+			pcm.Write(digits[c-'0'])
+			silence(pauseAfterDigit)
+		}
+		silence(pauseAfterGroup - pauseAfterDigit)
+	}
+	beeps()
+	return wavFile(pcm.Bytes()), nil
+}
+
+// readSound returns the samples of an embedded recording, checking that it
+// is in the format the recording is assembled in.
+func readSound(name string) ([]byte, error) {
+	data, err := soundsFS.ReadFile(name)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", name, err)
+	}
+	if len(data) < 44 || string(data[0:4]) != "RIFF" || string(data[8:12]) != "WAVE" || string(data[36:40]) != "data" {
+		return nil, fmt.Errorf("%s: not a plain WAV file", name)
+	}
+	channels := binary.LittleEndian.Uint16(data[22:24])
+	rate := binary.LittleEndian.Uint32(data[24:28])
+	bits := binary.LittleEndian.Uint16(data[34:36])
+	if channels != 1 || rate != audioRate || bits != 8 {
+		return nil, fmt.Errorf("%s: want %d Hz 8-bit mono, got %d Hz %d-bit with %d channels", name, audioRate, rate, bits, channels)
+	}
+	size := int(binary.LittleEndian.Uint32(data[40:44]))
+	if size > len(data)-44 {
+		return nil, fmt.Errorf("%s: truncated", name)
+	}
+	return data[44 : 44+size], nil
+}
+
+// wavFile wraps samples in a WAV header, padding them to an even length
+// as RIFF chunks are.
+func wavFile(pcm []byte) []byte {
+	if len(pcm)%2 == 1 {
+		pcm = append(pcm, audioSilence)
+	}
+	var b bytes.Buffer
+	b.Grow(44 + len(pcm))
+	b.WriteString("RIFF")
+	binary.Write(&b, binary.LittleEndian, uint32(36+len(pcm)))
+	b.WriteString("WAVEfmt ")
+	binary.Write(&b, binary.LittleEndian, struct {
+		ChunkSize         uint32
+		Format, Channels  uint16
+		Rate, BytesPerSec uint32
+		BlockAlign, Bits  uint16
+	}{16, 1, 1, audioRate, audioRate, 1, 8}) // PCM, mono, one byte per sample
+	b.WriteString("data")
+	binary.Write(&b, binary.LittleEndian, uint32(len(pcm)))
+	b.Write(pcm)
+	return b.Bytes()
+}
@@ -0,0 +1,131 @@
+package bundle
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"github.com/eljojo/rememory/internal/core"
+	"github.com/eljojo/rememory/internal/crypto"
+)
+
+// BuildInfoFilename is the audit record added to every bundle.
+const BuildInfoFilename = "BUILDINFO.json"
+
+// BuildInfo records exactly what produced a bundle, so that decades later an
+// auditor can rebuild the same tool and check it against the bundle's files.
+type BuildInfo struct {
+	Tool          string            `json:"tool"` // rememory, or maker.html for bundles made in the browser
+	Version       string            `json:"version"`
+	GoVersion     string            `json:"goVersion"`
+	Platform      string            `json:"platform"`                   // GOOS/GOARCH of the binary that sealed
+	Module        *BuildModule      `json:"module,omitempty"`           // The rememory module itself
+	VCS           map[string]string `json:"vcs,omitempty"`              // Commit, time, and whether the tree was modified
+	BuildSettings map[string]string `json:"buildSettings,omitempty"`    // Flags and environment the binary was built with
+	Dependencies  []BuildModule     `json:"dependencies"`               // Every module compiled in, with go.sum hashes
+	Executable    string            `json:"executableSha256,omitempty"` // Checksum of the binary that sealed
+	RecoverWASM   string            `json:"recoverWasmSha256"`          // Checksum of the WASM inside recover.html
+	Artifacts     map[string]string `json:"artifacts"`                  // File in this bundle → checksum
+}
+
+// BuildModule is one Go module, as recorded by the Go toolchain.
+type BuildModule struct {
+	Path    string       `json:"path"`
+	Version string       `json:"version"`
+	Sum     string       `json:"sum,omitempty"`
+	Replace *BuildModule `json:"replace,omitempty"`
+}
+
+// NewBuildInfo describes the running binary. Artifacts are filled in per bundle.
+func NewBuildInfo(version string, wasm []byte) *BuildInfo {
+	info := &BuildInfo{
+		Tool:         "rememory",
+		Version:      version,
+		GoVersion:    runtime.Version(),
+		Platform:     runtime.GOOS + "/" + runtime.GOARCH,
+		Dependencies: []BuildModule{},
+		RecoverWASM:  core.HashBytes(wasm),
+	}
+
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		info.GoVersion = bi.GoVersion
+		if bi.Main.Path != "" {
+			info.Module = buildModule(&bi.Main)
+		}
+		for _, dep := range bi.Deps {
+			info.Dependencies = append(info.Dependencies, *buildModule(dep))
+		}
+		for _, s := range bi.Settings {
+			if name, ok := strings.CutPrefix(s.Key, "vcs."); ok {
+				if info.VCS == nil {
+					info.VCS = make(map[string]string)
+				}
+				info.VCS[name] = s.Value
+				continue
+			}
+			if info.BuildSettings == nil {
+				info.BuildSettings = make(map[string]string)
+			}
+			info.BuildSettings[s.Key] = s.Value
+		}
+	}
+
+	if exe, err := os.Executable(); err == nil {
+		if data, err := os.ReadFile(exe); err == nil {
+			info.Executable = core.HashBytes(data)
+		}
+	}
+	return info
+}
+
+func buildModule(m *debug.Module) *BuildModule {
+	bm := &BuildModule{Path: m.Path, Version: m.Version, Sum: m.Sum}
+	if m.Replace != nil {
+		bm.Replace = buildModule(m.Replace)
+	}
+	return bm
+}
+
+// File renders BUILDINFO.json for a bundle made of files.
+func (b *BuildInfo) File(files []ZipFile, modTime time.Time) (ZipFile, error) {
+	withArtifacts := *b
+	withArtifacts.Artifacts = make(map[string]string, len(files))
+	for _, f := range files {
+		if f.Path == "" {
+			withArtifacts.Artifacts[f.Name] = core.HashBytes(f.Content)
+			continue
+		}
+		checksum, err := crypto.HashFile(f.Path)
+		if err != nil {
+			return ZipFile{}, fmt.Errorf("checksum of %s: %w", f.Name, err)
+		}
+		withArtifacts.Artifacts[f.Name] = checksum
+	}
+	data, err := json.MarshalIndent(withArtifacts, "", "  ")
+	if err != nil {
+		return ZipFile{}, fmt.Errorf("encoding %s: %w", BuildInfoFilename, err)
+	}
+	return ZipFile{Name: BuildInfoFilename, Content: append(data, '\n'), ModTime: modTime}, nil
+}
+
+// verifyArtifacts checks the files in a bundle against its BUILDINFO.json.
+func verifyArtifacts(buildInfo []byte, checksums map[string]string) error {
+	var info BuildInfo
+	if err := json.Unmarshal(buildInfo, &info); err != nil {
+		return fmt.Errorf("parsing %s: %w", BuildInfoFilename, err)
+	}
+	for name, want := range info.Artifacts {
+		got, ok := checksums[name]
+		if !ok {
+			return fmt.Errorf("%s lists %s, which is not in the bundle", BuildInfoFilename, name)
+		}
+		if got != want {
+			return fmt.Errorf("%s checksum doesn't match %s", name, BuildInfoFilename)
+		}
+	}
+	return nil
+}
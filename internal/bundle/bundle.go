@@ -12,9 +12,13 @@ import (
 	"time"
 
 	"github.com/eljojo/rememory/internal/core"
+	"github.com/eljojo/rememory/internal/crypto"
+	"github.com/eljojo/rememory/internal/faq"
 	"github.com/eljojo/rememory/internal/html"
 	"github.com/eljojo/rememory/internal/pdf"
 	"github.com/eljojo/rememory/internal/project"
+	"github.com/eljojo/rememory/internal/recovery"
+	"github.com/eljojo/rememory/internal/rotation"
 	"github.com/eljojo/rememory/internal/translations"
 )
 
@@ -29,32 +33,92 @@ type Config struct {
 
 // GenerateAll creates bundles for all friends in the project.
 func GenerateAll(p *project.Project, cfg Config) error {
+	_, err := Regenerate(p, cfg)
+	return err
+}
+
+// Regenerate is GenerateAll, reporting what changed in each friend's bundle.
+// A bundle whose files would all come out the same as before is left as it
+// was, byte for byte, so only friends whose bundle changed need a new copy.
+func Regenerate(p *project.Project, cfg Config) ([]Change, error) {
 	if p.Sealed == nil {
-		return fmt.Errorf("project must be sealed before generating bundles")
+		return nil, fmt.Errorf("project must be sealed before generating bundles")
 	}
 
 	bundlesDir := filepath.Join(p.OutputPath(), "bundles")
 	if err := os.MkdirAll(bundlesDir, 0755); err != nil {
-		return fmt.Errorf("creating bundles directory: %w", err)
+		return nil, fmt.Errorf("creating bundles directory: %w", err)
 	}
 
 	// Load all shares
 	shares, err := loadShares(p)
 	if err != nil {
-		return fmt.Errorf("loading shares: %w", err)
+		return nil, fmt.Errorf("loading shares: %w", err)
 	}
 
-	// Read MANIFEST.age
+	// MANIFEST.age is only read into memory when it's small enough to
+	// embed; otherwise it streams from disk into each bundle
 	manifestPath := p.ManifestAgePath()
-	manifestData, err := os.ReadFile(manifestPath)
+	manifestInfo, err := os.Stat(manifestPath)
+	if err != nil {
+		return nil, fmt.Errorf("reading manifest: %w", err)
+	}
+	manifestChecksum, err := crypto.HashFile(manifestPath)
+	if err != nil {
+		return nil, fmt.Errorf("reading manifest: %w", err)
+	}
+	var manifestData []byte
+	if !cfg.NoEmbedManifest && manifestInfo.Size() <= html.MaxEmbeddedManifestSize {
+		if manifestData, err = os.ReadFile(manifestPath); err != nil {
+			return nil, fmt.Errorf("reading manifest: %w", err)
+		}
+	}
+	volumes, volumeIndex, err := splitManifest(p, manifestPath, manifestChecksum, manifestInfo.Size())
 	if err != nil {
-		return fmt.Errorf("reading manifest: %w", err)
+		return nil, err
+	}
+	parity, err := writeParity(p, manifestPath, manifestChecksum, manifestInfo.Size())
+	if err != nil {
+		return nil, err
 	}
-	manifestChecksum := core.HashBytes(manifestData)
+
+	catalog, err := p.ReadCatalog()
+	if err != nil {
+		return nil, err
+	}
+	decoy, err := p.ReadDecoy()
+	if err != nil {
+		return nil, err
+	}
+	timelock, err := p.ReadTimelock()
+	if err != nil {
+		return nil, err
+	}
+
+	custom, err := loadCustomizations(p)
+	if err != nil {
+		return nil, err
+	}
+	buildInfo := NewBuildInfo(cfg.Version, cfg.WASMBytes)
+	var all []*core.Share
+	for _, pieces := range shares {
+		all = append(all, pieces...)
+	}
+	commitments := NewCommitments(all)
+	if decoy != nil {
+		// Duress pieces would show up as not belonging to the seal
+		commitments = nil
+	}
+	vss, err := core.ParseVSSCommitments(p.Sealed.VSS)
+	if err != nil {
+		return nil, err
+	}
+	privacy := p.PrivacyLevel()
 
 	// Generate bundle for each friend
+	var changes []Change
 	for i, friend := range p.Friends {
-		share := shares[i]
+		share, extra := shares[i][0], shares[i][1:]
 
 		// Resolve language: friend override > project default > "en"
 		lang := friend.Language
@@ -65,19 +129,22 @@ func GenerateAll(p *project.Project, cfg Config) error {
 			lang = "en"
 		}
 
-		// Get other friends (excluding this one) - empty for anonymous mode
+		// Get other friends (excluding this one), as much as the privacy
+		// setting shows of them; none for anonymous projects
+		holder := privacy.Holder(friend, share.Index)
 		var otherFriends []project.Friend
 		var otherFriendsInfo []html.FriendInfo
-		if !p.Anonymous {
+		if privacy.ListsOthers() {
 			otherFriends = make([]project.Friend, 0, len(p.Friends)-1)
 			otherFriendsInfo = make([]html.FriendInfo, 0, len(p.Friends)-1)
 			for j, f := range p.Friends {
 				if j != i {
-					otherFriends = append(otherFriends, f)
+					shown := privacy.Shown(f)
+					otherFriends = append(otherFriends, shown)
 					otherFriendsInfo = append(otherFriendsInfo, html.FriendInfo{
-						Name:       f.Name,
-						Contact:    f.Contact,
-						ShareIndex: j + 1, // 1-based share index
+						Name:       shown.Name,
+						Contact:    shown.Contact,
+						ShareIndex: shares[j][0].Index, // may skip numbers after a friend is removed
 					})
 				}
 			}
@@ -85,56 +152,159 @@ func GenerateAll(p *project.Project, cfg Config) error {
 
 		// Generate personalized recover.html for this friend
 		personalization := &html.PersonalizationData{
-			Holder:       friend.Name,
-			HolderShare:  share.Encode(),
-			OtherFriends: otherFriendsInfo,
-			Threshold:    p.Threshold,
-			Total:        len(p.Friends),
-			Language:     lang,
+			Holder:         holder,
+			HolderShare:    core.EncodeShares(shares[i]),
+			OtherFriends:   otherFriendsInfo,
+			Threshold:      p.Threshold,
+			Total:          p.TotalPieces(),
+			Language:       lang,
+			Crypto:         p.Sealed.Crypto,
+			ShareChecksums: commitments,
+			VSS:            p.Sealed.VSS,
+			Quiz:           p.Quiz && !p.Grouped(), // the page can't tell a grouped rule
 		}
 
 		// Embed manifest in recover.html when small enough and not disabled
-		manifestEmbedded := !cfg.NoEmbedManifest && len(manifestData) <= html.MaxEmbeddedManifestSize
+		manifestEmbedded := manifestData != nil
 		if manifestEmbedded {
 			personalization.ManifestB64 = base64.StdEncoding.EncodeToString(manifestData)
 		}
 
-		recoverHTML := html.GenerateRecoverHTML(cfg.WASMBytes, cfg.Version, cfg.GitHubReleaseURL, personalization)
-		recoverChecksum := core.HashString(recoverHTML)
+		// README.txt, README.pdf, and recover.html answer the same questions
+		recoveryURL, hosted := faq.RecoveryPage(cfg.RecoveryURL)
+		facts := faq.Facts{
+			Threshold:   p.Threshold,
+			Total:       p.TotalPieces(),
+			Grouped:     p.Grouped(),
+			Embedded:    manifestEmbedded,
+			RecoveryURL: recoveryURL,
+			Hosted:      hosted,
+			Delay:       p.RecoveryDelay,
+			PIN:         share.Locked(),
+			ListsOthers: privacy.ListsOthers(),
+		}
+		if catalog != nil {
+			facts.Catalog = p.Catalog.Threshold
+		}
+		if p.Beacon != nil {
+			facts.Beacon = p.Beacon.URL
+			facts.Fingerprint = rotation.Fingerprint(manifestChecksum)
+		}
+		personalization.FAQ = &facts
+		if catalog != nil {
+			personalization.CatalogB64 = base64.StdEncoding.EncodeToString(catalog)
+		}
+		if decoy != nil {
+			personalization.DecoyB64 = base64.StdEncoding.EncodeToString(decoy)
+		}
+		if timelock != nil {
+			personalization.Timelock = timelock
+		}
 
 		bundlePath := filepath.Join(bundlesDir, fmt.Sprintf("bundle-%s.zip", core.SanitizeFilename(friend.Name)))
 
-		err := GenerateBundle(BundleParams{
-			OutputPath:       bundlePath,
+		// An earlier bundle lends its recover.html nonce and README.pdf, so
+		// that a bundle nothing else changed in comes out the same and is kept
+		recoverHTML := html.GenerateRecoverHTML(cfg.WASMBytes, cfg.Version, cfg.GitHubReleaseURL, personalization, custom.html)
+		var previousPDF []byte
+		before, err := entryChecksums(bundlePath)
+		if err == nil {
+			if page, err := readEntry(bundlePath, "recover.html"); err == nil {
+				recoverHTML = html.KeepNonce(recoverHTML, page)
+			}
+			previousPDF, _ = readEntry(bundlePath, translations.ReadmeFilename(lang, ".pdf"))
+		}
+		recoverChecksum := core.HashString(recoverHTML)
+
+		var slip39 string
+		if p.SLIP39 {
+			data, err := os.ReadFile(p.SLIP39Path(friend))
+			if err != nil {
+				return nil, fmt.Errorf("reading SLIP-0039 words for %s (run 'rememory seal' again): %w", friend.Name, err)
+			}
+			slip39 = strings.TrimSpace(string(data))
+		}
+		var sskr string
+		if p.SSKR {
+			data, err := os.ReadFile(p.SSKRPath(friend))
+			if err != nil {
+				return nil, fmt.Errorf("reading SSKR share for %s (run 'rememory seal' again): %w", friend.Name, err)
+			}
+			sskr = strings.TrimSpace(string(data))
+		}
+		var ssss string
+		if p.SSSS {
+			data, err := os.ReadFile(p.SSSSPath(friend))
+			if err != nil {
+				return nil, fmt.Errorf("reading ssss share for %s (run 'rememory seal' again): %w", friend.Name, err)
+			}
+			ssss = strings.TrimSpace(string(data))
+		}
+
+		err = GenerateBundle(BundleParams{
+			OutputPath:       bundlePath + ".partial",
 			ProjectName:      p.Name,
 			Friend:           friend,
 			Share:            share,
+			Extra:            extra,
+			Weighted:         p.Weighted(),
 			OtherFriends:     otherFriends,
 			Threshold:        p.Threshold,
-			Total:            len(p.Friends),
-			ManifestData:     manifestData,
+			Total:            p.TotalPieces(),
+			Groups:           p.GroupPolicy(friend),
+			ManifestPath:     manifestPath,
 			ManifestChecksum: manifestChecksum,
 			ManifestEmbedded: manifestEmbedded,
+			Volumes:          volumes,
+			VolumeIndex:      volumeIndex,
+			Parity:           parity,
+			Decoy:            decoy,
+			Timelock:         timelock,
+			Delay:            p.RecoveryDelay,
 			RecoverHTML:      recoverHTML,
 			RecoverChecksum:  recoverChecksum,
 			Version:          cfg.Version,
 			GitHubReleaseURL: cfg.GitHubReleaseURL,
 			SealedAt:         p.Sealed.At,
-			Anonymous:        p.Anonymous,
+			Holder:           holder,
+			Privacy:          privacy,
 			RecoveryURL:      cfg.RecoveryURL,
 			Language:         lang,
+			WordList:         friend.Words,
+			PDFLayout:        custom.pdfLayout,
+			ReadmeTemplate:   custom.readmeTemplate,
+			BuildInfo:        buildInfo,
+			Crypto:           p.Sealed.Crypto,
+			SLIP39:           slip39,
+			SSKR:             sskr,
+			SSSS:             ssss,
+			Quiz:             p.Quiz,
+			Audio:            p.Audio,
+			PreviousPDF:      previousPDF,
+			Rotation:         p.Sealed.Rotation,
+			Commitments:      commitments,
+			VSS:              vss,
+			FAQ:              facts,
+			Beacon:           p.Beacon,
 		})
 		if err != nil {
-			return fmt.Errorf("generating bundle for %s: %w", friend.Name, err)
+			os.Remove(bundlePath + ".partial")
+			return nil, fmt.Errorf("generating bundle for %s: %w", friend.Name, err)
+		}
+		change, err := replaceBundle(bundlePath, before)
+		if err != nil {
+			return nil, fmt.Errorf("bundle for %s: %w", friend.Name, err)
 		}
+		change.Friend = friend.Name
+		changes = append(changes, change)
 
 		// Verify the bundle we just created
 		if err := VerifyBundle(bundlePath); err != nil {
-			return fmt.Errorf("verifying bundle for %s: %w", friend.Name, err)
+			return nil, fmt.Errorf("verifying bundle for %s: %w", friend.Name, err)
 		}
 	}
 
-	return nil
+	return changes, nil
 }
 
 // BundleParams contains all parameters for generating a single bundle.
@@ -143,66 +313,190 @@ type BundleParams struct {
 	ProjectName      string
 	Friend           project.Friend
 	Share            *core.Share
+	Extra            []*core.Share // The friend's further pieces, when they hold more than one
+	Weighted         bool          // Some friends hold more than one piece; Total counts pieces
 	OtherFriends     []project.Friend
 	Threshold        int
 	Total            int
-	ManifestData     []byte
+	Groups           *project.GroupPolicy // Recovery rule of a grouped project; nil otherwise
+	ManifestPath     string               // MANIFEST.age on disk, copied into the bundle unless embedded
 	ManifestChecksum string
-	ManifestEmbedded bool // true when manifest is base64-embedded in recover.html
+	ManifestEmbedded bool     // true when manifest is base64-embedded in recover.html
+	Volumes          []string // MANIFEST.age's volumes on disk, copied into the bundle in its place unless embedded; nil when it isn't split
+	VolumeIndex      []byte   // MANIFEST.age.sha256, listing the volumes
+	Parity           string   // MANIFEST.age.parity on disk, copied next to MANIFEST.age when that isn't embedded; empty without parity
+	Decoy            []byte   // DECOY.age, copied next to MANIFEST.age when that isn't embedded; nil without a decoy
+	Timelock         []byte   // TIMELOCK.json, in every bundle of a project with a recovery delay
+	Delay            string   // The recovery_delay, for the READMEs; empty without one
 	RecoverHTML      string
 	RecoverChecksum  string
 	Version          string
 	GitHubReleaseURL string
 	SealedAt         time.Time
-	Anonymous        bool
+	Holder           string          // Name printed for the friend, as the privacy setting allows
+	Privacy          project.Privacy // What the bundle tells about the other friends
 	RecoveryURL      string
-	Language         string // Bundle language for this friend
+	Language         string                // Bundle language for this friend
+	WordList         string                // Recovery word list language; defaults to Language
+	PDFLayout        *pdf.Layout           // nil uses the built-in layout
+	ReadmeTemplate   *ReadmeTemplate       // nil uses the built-in README.txt
+	BuildInfo        *BuildInfo            // nil leaves out BUILDINFO.json
+	Crypto           string                // Crypto profile, recorded in the README metadata
+	SLIP39           string                // The friend's SLIP-0039 words; empty unless the project sets slip39
+	SSKR             string                // The friend's SSKR share as a UR; empty unless the project sets sskr
+	SSSS             string                // The friend's ssss share line; empty unless the project sets ssss
+	Quiz             bool                  // Adds QUIZ.txt, questions on keeping and using the piece
+	Audio            bool                  // Adds PIECE.wav, the piece's digit groups read aloud
+	PreviousPDF      []byte                // README.pdf of the bundle being replaced, kept when it shows the same; nil for none
+	Rotation         *rotation.Note        // Written as SUPERSEDED.txt; nil unless the project was sealed before
+	Commitments      Commitments           // Checksums of every share of the seal, recorded in the README metadata
+	VSS              core.VSSCommitments   // Feldman commitments to the seal's verifiable split; nil for seals without one
+	FAQ              faq.Facts             // What the READMEs' questions are answered from
+	Beacon           *project.BeaconConfig // Where holders check the bundle is current, recorded in the README metadata; nil without a beacon
+}
+
+// CheckProjectTemplates loads the project's custom PDF layout, README
+// template, and HTML additions, if any, so mistakes surface before sealing starts.
+func CheckProjectTemplates(p *project.Project) error {
+	_, err := loadCustomizations(p)
+	return err
+}
+
+// customizations are the project's overrides of the built-in bundle contents.
+// Each field is nil when the project doesn't set it.
+type customizations struct {
+	pdfLayout      *pdf.Layout
+	readmeTemplate *ReadmeTemplate
+	html           *html.Customization
+}
+
+func loadCustomizations(p *project.Project) (*customizations, error) {
+	c := &customizations{}
+	var err error
+	if p.PDFLayout != "" {
+		if c.pdfLayout, err = pdf.LoadLayout(p.ResolvePath(p.PDFLayout)); err != nil {
+			return nil, err
+		}
+	}
+	if p.ReadmeTemplate != "" {
+		if c.readmeTemplate, err = LoadReadmeTemplate(p.ResolvePath(p.ReadmeTemplate)); err != nil {
+			return nil, err
+		}
+	}
+	if c.html, err = LoadHTMLCustomization(p); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// LoadHTMLCustomization reads the files named in the project's html section.
+// It returns nil if the project has none.
+func LoadHTMLCustomization(p *project.Project) (*html.Customization, error) {
+	if p.HTML == nil {
+		return nil, nil
+	}
+	c := &html.Customization{Banner: p.HTML.Banner}
+	for _, f := range []struct {
+		path string
+		dst  *string
+	}{
+		{p.HTML.Header, &c.Header},
+		{p.HTML.Footer, &c.Footer},
+		{p.HTML.CSS, &c.CSS},
+	} {
+		if f.path == "" {
+			continue
+		}
+		data, err := os.ReadFile(p.ResolvePath(f.path))
+		if err != nil {
+			return nil, fmt.Errorf("reading HTML customization: %w", err)
+		}
+		*f.dst = string(data)
+	}
+	if err := c.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid HTML customization: %w", err)
+	}
+	return c, nil
 }
 
 // GenerateBundle creates a single bundle ZIP file for one friend.
 func GenerateBundle(params BundleParams) error {
+	if params.Holder == "" {
+		params.Holder = params.Friend.Name
+	}
 	// Common data for both README formats
 	readmeData := ReadmeData{
 		ProjectName:      params.ProjectName,
-		Holder:           params.Friend.Name,
+		Holder:           params.Holder,
 		Share:            params.Share,
+		Extra:            params.Extra,
+		Weighted:         params.Weighted,
 		OtherFriends:     params.OtherFriends,
 		Threshold:        params.Threshold,
 		Total:            params.Total,
+		Groups:           params.Groups,
 		Version:          params.Version,
 		GitHubReleaseURL: params.GitHubReleaseURL,
 		ManifestChecksum: params.ManifestChecksum,
 		RecoverChecksum:  params.RecoverChecksum,
 		Created:          params.SealedAt,
-		Anonymous:        params.Anonymous,
+		Privacy:          params.Privacy,
 		Language:         params.Language,
+		WordList:         params.WordList,
 		ManifestEmbedded: params.ManifestEmbedded,
+		Crypto:           params.Crypto,
+		Rotation:         params.Rotation,
+		Commitments:      params.Commitments,
+		VSS:              params.VSS,
+		Delay:            params.Delay,
+		Audio:            params.Audio,
+		FAQ:              params.FAQ,
+		Beacon:           params.Beacon,
 	}
 
 	// Generate README.txt
-	readmeContent := GenerateReadme(readmeData)
+	readmeContent := ""
+	if params.ReadmeTemplate != nil {
+		var err error
+		if readmeContent, err = params.ReadmeTemplate.Execute(readmeData); err != nil {
+			return err
+		}
+	} else {
+		readmeContent = GenerateReadme(readmeData)
+	}
 
 	// Generate README.pdf
 	pdfContent, err := pdf.GenerateReadme(pdf.ReadmeData{
 		ProjectName:      readmeData.ProjectName,
 		Holder:           readmeData.Holder,
 		Share:            readmeData.Share,
+		Extra:            readmeData.Extra,
+		Weighted:         readmeData.Weighted,
 		OtherFriends:     readmeData.OtherFriends,
 		Threshold:        readmeData.Threshold,
 		Total:            params.Total,
+		Groups:           params.Groups,
 		Version:          readmeData.Version,
 		GitHubReleaseURL: readmeData.GitHubReleaseURL,
 		ManifestChecksum: readmeData.ManifestChecksum,
 		RecoverChecksum:  readmeData.RecoverChecksum,
 		Created:          readmeData.Created,
-		Anonymous:        readmeData.Anonymous,
+		Privacy:          readmeData.Privacy,
 		RecoveryURL:      params.RecoveryURL,
 		Language:         params.Language,
+		WordList:         params.WordList,
 		ManifestEmbedded: params.ManifestEmbedded,
+		Rotation:         params.Rotation,
+		Delay:            params.Delay,
+		Layout:           params.PDFLayout,
+		FAQ:              params.FAQ,
 	})
 	if err != nil {
 		return fmt.Errorf("generating PDF: %w", err)
 	}
+	if params.PreviousPDF != nil && pdf.SameDocument(params.PreviousPDF, pdfContent) {
+		pdfContent = params.PreviousPDF
+	}
 
 	// Create ZIP with all files, using sealed date as modification time.
 	// When the manifest is embedded in recover.html, skip the separate MANIFEST.age
@@ -214,34 +508,79 @@ func GenerateBundle(params BundleParams) error {
 		{Name: readmeFilePdf, Content: pdfContent, ModTime: params.SealedAt},
 		{Name: "recover.html", Content: []byte(params.RecoverHTML), ModTime: params.SealedAt},
 	}
+	if params.SLIP39 != "" {
+		files = append(files, ZipFile{Name: SLIP39Filename, Content: []byte(GenerateSLIP39Text(readmeData, params.SLIP39)), ModTime: params.SealedAt})
+	}
+	if params.SSKR != "" {
+		text, err := GenerateSSKRText(readmeData, params.SSKR)
+		if err != nil {
+			return fmt.Errorf("writing SSKR share: %w", err)
+		}
+		files = append(files, ZipFile{Name: SSKRFilename, Content: []byte(text), ModTime: params.SealedAt})
+	}
+	if params.SSSS != "" {
+		files = append(files, ZipFile{Name: SSSSFilename, Content: []byte(GenerateSSSSText(readmeData, params.SSSS)), ModTime: params.SealedAt})
+	}
+	if params.Quiz {
+		files = append(files, ZipFile{Name: QuizFilename, Content: []byte(GenerateQuizText(readmeData)), ModTime: params.SealedAt})
+	}
+	if params.Audio {
+		wav, err := GenerateAudio(params.Share, params.Language)
+		if err != nil {
+			return fmt.Errorf("writing %s: %w", AudioFilename, err)
+		}
+		files = append(files, ZipFile{Name: AudioFilename, Content: wav, ModTime: params.SealedAt})
+	}
+	if params.Rotation != nil {
+		files = append(files, ZipFile{Name: rotation.FileName, Content: []byte(params.Rotation.Text()), ModTime: params.SealedAt})
+	}
 	if !params.ManifestEmbedded {
-		files = append(files, ZipFile{Name: "MANIFEST.age", Content: params.ManifestData, ModTime: params.SealedAt})
+		if len(params.Volumes) > 0 {
+			// In volumes, so no file in the bundle is too big for FAT32 or a disc
+			for _, path := range params.Volumes {
+				files = append(files, ZipFile{Name: filepath.Base(path), Path: path, ModTime: params.SealedAt})
+			}
+			files = append(files, ZipFile{Name: "MANIFEST.age" + core.VolumeIndexSuffix, Content: params.VolumeIndex, ModTime: params.SealedAt})
+		} else {
+			files = append(files, ZipFile{Name: "MANIFEST.age", Path: params.ManifestPath, ModTime: params.SealedAt})
+		}
+		if params.Parity != "" {
+			files = append(files, ZipFile{Name: "MANIFEST.age" + core.ParitySuffix, Path: params.Parity, ModTime: params.SealedAt})
+		}
+		if params.Decoy != nil {
+			files = append(files, ZipFile{Name: core.DecoyFile, Content: params.Decoy, ModTime: params.SealedAt})
+		}
+	}
+	if params.Timelock != nil {
+		files = append(files, ZipFile{Name: core.TimelockFile, Content: params.Timelock, ModTime: params.SealedAt})
+	}
+	if params.BuildInfo != nil {
+		buildInfo, err := params.BuildInfo.File(files, params.SealedAt)
+		if err != nil {
+			return err
+		}
+		files = append(files, buildInfo)
 	}
 
 	return CreateZip(params.OutputPath, files)
 }
 
-// loadShares reads all share files from the project's shares directory.
-func loadShares(p *project.Project) ([]*core.Share, error) {
-	sharesDir := p.SharesPath()
-
-	shares := make([]*core.Share, len(p.Friends))
+// loadShares reads all share files from the project's shares directory:
+// each friend's pieces, their first piece first.
+func loadShares(p *project.Project) ([][]*core.Share, error) {
+	shares := make([][]*core.Share, len(p.Friends))
 	for i, friend := range p.Friends {
-		// Try to find share file for this friend
-		filename := fmt.Sprintf("SHARE-%s.txt", core.SanitizeFilename(friend.Name))
-		sharePath := filepath.Join(sharesDir, filename)
-
-		data, err := os.ReadFile(sharePath)
+		data, err := os.ReadFile(p.SharePath(friend))
 		if err != nil {
 			return nil, fmt.Errorf("reading share for %s: %w", friend.Name, err)
 		}
 
-		share, err := core.ParseShare(data)
+		pieces, err := core.ParseShares(data)
 		if err != nil {
 			return nil, fmt.Errorf("parsing share for %s: %w", friend.Name, err)
 		}
 
-		shares[i] = share
+		shares[i] = pieces
 	}
 
 	return shares, nil
@@ -261,6 +600,11 @@ func VerifyBundle(bundlePath string) error {
 	var manifestData []byte
 	var recoverData []byte
 	var pdfData []byte
+	var buildInfo []byte
+	var rotationNote []byte
+	var volumes []core.VolumePart
+	var volumeIndex, parity []byte
+	checksums := make(map[string]string)
 
 	for _, f := range r.File {
 		rc, err := f.Open()
@@ -275,6 +619,7 @@ func VerifyBundle(bundlePath string) error {
 		if err != nil {
 			return fmt.Errorf("reading %s: %w", f.Name, err)
 		}
+		checksums[f.Name] = core.HashBytes(data)
 
 		switch {
 		case translations.IsReadmeFile(f.Name, ".txt"):
@@ -283,8 +628,18 @@ func VerifyBundle(bundlePath string) error {
 			pdfData = data
 		case f.Name == "MANIFEST.age":
 			manifestData = data
+		case f.Name == "MANIFEST.age"+core.VolumeIndexSuffix:
+			volumeIndex = data
+		case f.Name == "MANIFEST.age"+core.ParitySuffix:
+			parity = data
+		case strings.HasPrefix(f.Name, "MANIFEST.age."):
+			volumes = append(volumes, core.VolumePart{Name: f.Name, Data: data})
 		case f.Name == "recover.html":
 			recoverData = data
+		case f.Name == BuildInfoFilename:
+			buildInfo = data
+		case f.Name == rotation.FileName:
+			rotationNote = data
 		}
 	}
 
@@ -298,10 +653,20 @@ func VerifyBundle(bundlePath string) error {
 		return fmt.Errorf("recover.html not found in bundle")
 	}
 
+	if len(manifestData) == 0 && len(volumes) > 0 {
+		index, err := core.ParseVolumeIndex(volumeIndex)
+		if err != nil {
+			return fmt.Errorf("MANIFEST.age%s: %w", core.VolumeIndexSuffix, err)
+		}
+		if manifestData, err = core.JoinVolumes(volumes, index); err != nil {
+			return err
+		}
+	}
+
 	// When MANIFEST.age is not in the ZIP, the manifest is embedded in recover.html.
 	// Extract it from there for checksum verification.
 	if len(manifestData) == 0 {
-		extracted, err := html.ExtractManifestFromHTML(recoverData)
+		extracted, err := recovery.ExtractManifestFromHTML(recoverData)
 		if err != nil {
 			return fmt.Errorf("MANIFEST.age not in bundle and could not extract from recover.html: %w", err)
 		}
@@ -309,7 +674,7 @@ func VerifyBundle(bundlePath string) error {
 	}
 
 	// Parse metadata from footer
-	metadata := parseMetadataFooter(readmeContent)
+	metadata := ParseMetadataFooter(readmeContent)
 
 	// Verify manifest checksum
 	actualManifestChecksum := core.HashBytes(manifestData)
@@ -320,6 +685,18 @@ func VerifyBundle(bundlePath string) error {
 	if actualManifestChecksum != expectedManifestChecksum {
 		return fmt.Errorf("MANIFEST.age checksum mismatch")
 	}
+	if parity != nil {
+		h, damaged, err := core.CheckParity(parity)
+		if err != nil {
+			return fmt.Errorf("MANIFEST.age%s: %w", core.ParitySuffix, err)
+		}
+		if h.Checksum != actualManifestChecksum {
+			return fmt.Errorf("MANIFEST.age%s is for a different MANIFEST.age", core.ParitySuffix)
+		}
+		if damaged > 0 {
+			return fmt.Errorf("MANIFEST.age%s has %d damaged blocks", core.ParitySuffix, damaged)
+		}
+	}
 
 	// Verify recover.html checksum
 	actualRecoverChecksum := core.HashString(string(recoverData))
@@ -331,21 +708,83 @@ func VerifyBundle(bundlePath string) error {
 		return fmt.Errorf("recover.html checksum mismatch")
 	}
 
-	// Verify embedded share
-	share, err := core.ParseShare([]byte(readmeContent))
+	// Verify embedded shares: one, or several when the friend holds more
+	shares, err := core.ParseShares([]byte(readmeContent))
 	if err != nil {
 		return fmt.Errorf("parsing share: %w", err)
 	}
+	commitments := CommitmentsFromMetadata(metadata)
+	vss, err := VSSFromMetadata(metadata)
+	if err != nil {
+		return err
+	}
+	for _, share := range shares {
+		if err := share.Verify(); err != nil {
+			return fmt.Errorf("share verification failed: %w", err)
+		}
+		if len(commitments) > 0 && commitments.Match(share.Data) != share.Index {
+			return fmt.Errorf("share %d doesn't match the commitments in README metadata", share.Index)
+		}
+		if len(vss) > 0 && len(share.VSS) > 0 {
+			if err := vss.Verify(share.Index, share.VSS); err != nil {
+				return fmt.Errorf("share %d doesn't match the VSS commitments in README metadata: %w", share.Index, err)
+			}
+		}
+	}
+
+	// The note must be intact and belong to this seal
+	if rotationNote != nil {
+		note, err := rotation.Parse(string(rotationNote))
+		if err != nil {
+			return err
+		}
+		if note.Fingerprint != rotation.Fingerprint(actualManifestChecksum) {
+			return fmt.Errorf("%s belongs to a different seal than MANIFEST.age", rotation.FileName)
+		}
+	}
 
-	if err := share.Verify(); err != nil {
-		return fmt.Errorf("share verification failed: %w", err)
+	// Bundles from before BUILDINFO.json existed don't have one
+	if buildInfo != nil {
+		if err := verifyArtifacts(buildInfo, checksums); err != nil {
+			return err
+		}
 	}
 
 	return nil
 }
 
-// parseMetadataFooter extracts key-value pairs from the README.txt footer section.
-func parseMetadataFooter(content string) map[string]string {
+// FileChecksums returns the checksum of every file in a bundle ZIP, keyed by
+// file name. VERIFY.html embeds these so holders can check their copy.
+func FileChecksums(bundlePath string) (map[string]string, error) {
+	r, err := zip.OpenReader(bundlePath)
+	if err != nil {
+		return nil, fmt.Errorf("opening bundle: %w", err)
+	}
+	defer r.Close()
+
+	checksums := make(map[string]string)
+	for _, f := range r.File {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, fmt.Errorf("opening %s: %w", f.Name, err)
+		}
+		data, err := io.ReadAll(rc)
+		if closeErr := rc.Close(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %w", f.Name, err)
+		}
+		checksums[f.Name] = core.HashBytes(data)
+	}
+	return checksums, nil
+}
+
+// ParseMetadataFooter extracts key-value pairs from the README.txt footer section.
+func ParseMetadataFooter(content string) map[string]string {
 	metadata := make(map[string]string)
 
 	footerStart := strings.Index(content, "METADATA FOOTER")
@@ -0,0 +1,122 @@
+package bundle
+
+import (
+	"archive/zip"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+
+	"github.com/eljojo/rememory/internal/translations"
+)
+
+// Change is what rebuilding a friend's bundle changed in it.
+type Change struct {
+	Friend string
+	Path   string
+	New    bool     // There was no earlier bundle to compare with
+	Files  []string // Files added, removed, or different from the earlier bundle; empty when it was left as it was
+}
+
+// Unchanged reports whether the earlier bundle was left in place, byte for byte.
+func (c Change) Unchanged() bool {
+	return !c.New && len(c.Files) == 0
+}
+
+// Reprint reports whether a printed copy of the earlier bundle is out of
+// date: its README changed, or there was no earlier bundle.
+func (c Change) Reprint() bool {
+	if c.New {
+		return true
+	}
+	for _, name := range c.Files {
+		if translations.IsReadmeFile(name, ".txt") || translations.IsReadmeFile(name, ".pdf") {
+			return true
+		}
+	}
+	return false
+}
+
+// entryChecksums returns the checksum of each file in a bundle ZIP, by name.
+func entryChecksums(path string) (map[string]string, error) {
+	r, err := zip.OpenReader(path)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	sums := make(map[string]string, len(r.File))
+	for _, f := range r.File {
+		rc, err := f.Open()
+		if err != nil {
+			return nil, fmt.Errorf("opening %s: %w", f.Name, err)
+		}
+		h := sha256.New()
+		_, err = io.Copy(h, rc)
+		if closeErr := rc.Close(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %w", f.Name, err)
+		}
+		sums[f.Name] = "sha256:" + hex.EncodeToString(h.Sum(nil))
+	}
+	return sums, nil
+}
+
+// readEntry returns one file from a bundle ZIP.
+func readEntry(path, name string) ([]byte, error) {
+	r, err := zip.OpenReader(path)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	rc, err := r.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	return io.ReadAll(rc)
+}
+
+// replaceBundle moves the bundle built at path+".partial" into place, unless
+// every file in it matches before, the earlier bundle's checksums; then the
+// earlier bundle stays as it was. before is nil when there was none.
+func replaceBundle(path string, before map[string]string) (Change, error) {
+	partial := path + ".partial"
+	after, err := entryChecksums(partial)
+	if err != nil {
+		os.Remove(partial)
+		return Change{}, fmt.Errorf("reading new bundle: %w", err)
+	}
+
+	change := Change{Path: path, New: before == nil}
+	if before != nil {
+		for name, sum := range after {
+			if before[name] != sum {
+				change.Files = append(change.Files, name)
+			}
+		}
+		for name := range before {
+			if _, ok := after[name]; !ok {
+				change.Files = append(change.Files, name)
+			}
+		}
+		slices.Sort(change.Files)
+	}
+
+	if change.Unchanged() {
+		if err := os.Remove(partial); err != nil {
+			return Change{}, fmt.Errorf("removing new bundle: %w", err)
+		}
+		return change, nil
+	}
+	if err := os.Rename(partial, path); err != nil {
+		os.Remove(partial)
+		return Change{}, fmt.Errorf("replacing bundle: %w", err)
+	}
+	return change, nil
+}
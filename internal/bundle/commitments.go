@@ -0,0 +1,100 @@
+package bundle
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/eljojo/rememory/internal/core"
+)
+
+// commitmentKeyPrefix starts the metadata footer key of each commitment,
+// followed by the share index.
+const commitmentKeyPrefix = "commitment-share-"
+
+// vssKeyPrefix starts the metadata footer key of each Feldman commitment,
+// followed by its coefficient's degree.
+const vssKeyPrefix = "vss-commitment-"
+
+// Commitments are the checksums of every share of one seal, keyed by share
+// index. Every bundle records them in its README metadata footer, so a
+// single share can be checked against any bundle of its seal before
+// anyone gathers enough pieces to recover.
+//
+// They cover the share data, which is split over GF(2^8). Pieces also carry
+// a value of the seal's verifiable split, checked against the Feldman
+// commitments (core.VSSCommitments) the README records next to these.
+type Commitments map[int]string
+
+// NewCommitments records the checksum of each share's data.
+func NewCommitments(shares []*core.Share) Commitments {
+	c := make(Commitments, len(shares))
+	for _, s := range shares {
+		c[s.Index] = core.HashBytes(s.Data)
+	}
+	return c
+}
+
+// CommitmentsFromMetadata reads the commitments from a README metadata
+// footer. Bundles made before commitments existed have none.
+func CommitmentsFromMetadata(metadata map[string]string) Commitments {
+	c := Commitments{}
+	for key, value := range metadata {
+		index, err := strconv.Atoi(strings.TrimPrefix(key, commitmentKeyPrefix))
+		if strings.HasPrefix(key, commitmentKeyPrefix) && err == nil {
+			c[index] = value
+		}
+	}
+	return c
+}
+
+// Match returns the index of the share with this data, or 0 if it isn't
+// one of the shares the commitments record. The data ends with the share's
+// x coordinate, so a matching checksum also settles its index.
+func (c Commitments) Match(data []byte) int {
+	sum := core.HashBytes(data)
+	for index, want := range c {
+		if core.VerifyHash(sum, want) {
+			return index
+		}
+	}
+	return 0
+}
+
+// writeFooterLines writes one metadata footer line per share, in index order.
+func (c Commitments) writeFooterLines(sb *strings.Builder) {
+	indices := make([]int, 0, len(c))
+	for index := range c {
+		indices = append(indices, index)
+	}
+	sort.Ints(indices)
+	for _, index := range indices {
+		sb.WriteString(fmt.Sprintf("%s%d: %s\n", commitmentKeyPrefix, index, c[index]))
+	}
+}
+
+// VSSFromMetadata reads the Feldman commitments from a README metadata
+// footer. Seals without a verifiable split have none.
+func VSSFromMetadata(metadata map[string]string) (core.VSSCommitments, error) {
+	var encoded []string
+	for degree := 0; ; degree++ {
+		value, ok := metadata[fmt.Sprintf("%s%d", vssKeyPrefix, degree)]
+		if !ok {
+			break
+		}
+		encoded = append(encoded, value)
+	}
+	if len(encoded) == 0 {
+		return nil, nil
+	}
+	return core.ParseVSSCommitments(encoded)
+}
+
+// writeVSSFooterLines writes one metadata footer line per Feldman
+// commitment, constant term first.
+func writeVSSFooterLines(sb *strings.Builder, vss core.VSSCommitments) {
+	for degree, c := range vss.Strings() {
+		sb.WriteString(fmt.Sprintf("%s%d: %s\n", vssKeyPrefix, degree, c))
+	}
+}
@@ -0,0 +1,46 @@
+package bundle
+
+import (
+	"fmt"
+	"html"
+	"strings"
+
+	"github.com/eljojo/rememory/internal/translations"
+)
+
+// DriveIndexFilename is the page 'rememory usb prepare' puts at the top of
+// a drive, next to the bundle's files.
+const DriveIndexFilename = "index.html"
+
+// GenerateDriveIndex writes the drive's index page in the bundle language:
+// links to recover.html and to readme, the README.pdf or README.txt beside
+// it. It has no script and runs nothing by itself; it is there for whoever
+// opens the drive and doesn't know which file to start with.
+func GenerateDriveIndex(lang, holder, readme string) []byte {
+	if lang == "" {
+		lang = "en"
+	}
+	t := func(key string, args ...any) string {
+		return html.EscapeString(translations.T("readme", lang, key, args...))
+	}
+
+	var sb strings.Builder
+	sb.WriteString("<!DOCTYPE html>\n")
+	sb.WriteString(fmt.Sprintf("<html lang=\"%s\">\n<head>\n", html.EscapeString(lang)))
+	sb.WriteString("<meta charset=\"utf-8\">\n")
+	sb.WriteString("<meta name=\"viewport\" content=\"width=device-width, initial-scale=1\">\n")
+	sb.WriteString(fmt.Sprintf("<title>%s</title>\n", t("usb_index_title")))
+	sb.WriteString("<style>\n")
+	sb.WriteString("body { font-family: system-ui, sans-serif; max-width: 36rem; margin: 3rem auto; padding: 0 1rem; color: #2e2a26; line-height: 1.5; }\n")
+	sb.WriteString("a.open { display: inline-block; margin: 1rem 0 0.25rem; padding: 0.75rem 1.25rem; border-radius: 0.5rem; background: #2e2a26; color: #fff; text-decoration: none; font-weight: 600; }\n")
+	sb.WriteString(".hint, .private { color: #6c757d; font-size: 0.9rem; }\n")
+	sb.WriteString("</style>\n</head>\n<body>\n")
+	sb.WriteString(fmt.Sprintf("<h1>%s</h1>\n", t("usb_index_title")))
+	sb.WriteString(fmt.Sprintf("<p>%s</p>\n", t("usb_index_intro", holder)))
+	sb.WriteString(fmt.Sprintf("<p><a class=\"open\" href=\"recover.html\">%s</a><br>\n", t("usb_index_recover")))
+	sb.WriteString(fmt.Sprintf("<span class=\"hint\">%s</span></p>\n", t("usb_index_recover_hint")))
+	sb.WriteString(fmt.Sprintf("<p><a href=\"%s\">%s</a> (%s)</p>\n", html.EscapeString(readme), t("usb_index_readme"), html.EscapeString(readme)))
+	sb.WriteString(fmt.Sprintf("<p class=\"private\">%s</p>\n", t("usb_index_private")))
+	sb.WriteString("</body>\n</html>\n")
+	return []byte(sb.String())
+}
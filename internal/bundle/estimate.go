@@ -0,0 +1,33 @@
+package bundle
+
+import (
+	"github.com/eljojo/rememory/internal/html"
+)
+
+// readmeAllowance covers README.txt, README.pdf, and BUILDINFO.json, which
+// come to about 100 KB whatever the split.
+const readmeAllowance = 128 << 10
+
+// BaseSize is about how large one friend's bundle comes out before the
+// encrypted manifest: recover.html with the recovery tool, and the README
+// files. Every friend's bundle is about this size, however many there are.
+func BaseSize(wasmBytes []byte) int64 {
+	return int64(len(html.GenerateRecoverHTML(wasmBytes, "", "", nil, nil))) + readmeAllowance
+}
+
+// RoughBaseSize is BaseSize without building recover.html, which takes about
+// a second: gzip and base64 bring the WASM to about 40% of its size, and the
+// page around it is small, so half the WASM's size errs high.
+func RoughBaseSize(wasmBytes []byte) int64 {
+	return int64(len(wasmBytes))/2 + readmeAllowance
+}
+
+// EstimateSize adds an encrypted manifest of manifestSize bytes to a
+// bundle's BaseSize: embedded in recover.html when small enough, or
+// alongside it when not.
+func EstimateSize(base, manifestSize int64) int64 {
+	if manifestSize <= html.MaxEmbeddedManifestSize {
+		return base + (manifestSize+2)/3*4
+	}
+	return base + manifestSize
+}
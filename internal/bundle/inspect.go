@@ -0,0 +1,123 @@
+package bundle
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/eljojo/rememory/internal/core"
+	"github.com/eljojo/rememory/internal/html"
+	"github.com/eljojo/rememory/internal/recovery"
+	"github.com/eljojo/rememory/internal/rotation"
+	"github.com/eljojo/rememory/internal/translations"
+)
+
+// Info describes the contents of a bundle ZIP without recovering anything.
+type Info struct {
+	Files            []string           // Names of the files in the ZIP, in order
+	Share            *core.Share        // Share parsed from the README
+	Metadata         map[string]string  // Key-value pairs from the README metadata footer
+	ManifestEmbedded bool               // true when MANIFEST.age lives inside recover.html
+	Manifest         []byte             // MANIFEST.age bytes (from the ZIP or recover.html), if found
+	ManifestVolumes  int                // How many volumes MANIFEST.age was put together from; 0 when it wasn't split
+	Parity           *core.ParityHeader // MANIFEST.age.parity's header, if the bundle has one
+	ParityDamaged    int                // How many of MANIFEST.age.parity's own blocks are damaged
+	WASMChecksum     string             // SHA-256 of the recovery WASM inside recover.html, if found
+	Rotation         *rotation.Note     // Parsed SUPERSEDED.txt, if the bundle has one
+	RotationErr      error              // Why SUPERSEDED.txt couldn't be read, if it couldn't
+}
+
+// ReadInfo opens a bundle ZIP and reports what it contains.
+// It does not verify checksums; use VerifyBundle for that.
+func ReadInfo(bundlePath string) (*Info, error) {
+	r, err := zip.OpenReader(bundlePath)
+	if err != nil {
+		return nil, fmt.Errorf("opening bundle: %w", err)
+	}
+	defer r.Close()
+
+	info := &Info{}
+	var readmeContent string
+	var recoverData []byte
+	var volumes []core.VolumePart
+	var volumeIndex []byte
+
+	for _, f := range r.File {
+		info.Files = append(info.Files, f.Name)
+
+		isReadme := translations.IsReadmeFile(f.Name, ".txt")
+		isParity := f.Name == "MANIFEST.age"+core.ParitySuffix
+		isVolume := !isParity && strings.HasPrefix(f.Name, "MANIFEST.age.")
+		if !isReadme && !isVolume && !isParity && f.Name != "MANIFEST.age" && f.Name != "recover.html" && f.Name != rotation.FileName {
+			continue
+		}
+
+		rc, err := f.Open()
+		if err != nil {
+			return nil, fmt.Errorf("opening %s: %w", f.Name, err)
+		}
+		data, err := io.ReadAll(io.LimitReader(rc, core.MaxFileSize+1))
+		if closeErr := rc.Close(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %w", f.Name, err)
+		}
+
+		switch {
+		case isReadme:
+			readmeContent = string(data)
+		case f.Name == "MANIFEST.age":
+			info.Manifest = data
+		case f.Name == "MANIFEST.age"+core.VolumeIndexSuffix:
+			volumeIndex = data
+		case isVolume:
+			volumes = append(volumes, core.VolumePart{Name: f.Name, Data: data})
+		case isParity:
+			if info.Parity, info.ParityDamaged, err = core.CheckParity(data); err != nil {
+				return nil, fmt.Errorf("%s: %w", f.Name, err)
+			}
+		case f.Name == "recover.html":
+			recoverData = data
+		case f.Name == rotation.FileName:
+			info.Rotation, info.RotationErr = rotation.Parse(string(data))
+		}
+	}
+
+	if readmeContent == "" {
+		return nil, fmt.Errorf("README file (.txt) not found in bundle")
+	}
+
+	info.Metadata = ParseMetadataFooter(readmeContent)
+
+	share, err := core.ParseShare([]byte(readmeContent))
+	if err != nil {
+		return nil, fmt.Errorf("parsing share: %w", err)
+	}
+	info.Share = share
+
+	if wasm, err := html.ExtractWASMFromHTML(recoverData); err == nil {
+		info.WASMChecksum = core.HashBytes(wasm)
+	}
+
+	if len(info.Manifest) == 0 && len(volumes) > 0 {
+		index, err := core.ParseVolumeIndex(volumeIndex)
+		if err != nil {
+			return nil, fmt.Errorf("MANIFEST.age%s: %w", core.VolumeIndexSuffix, err)
+		}
+		if info.Manifest, err = core.JoinVolumes(volumes, index); err != nil {
+			return nil, err
+		}
+		info.ManifestVolumes = len(index.Volumes)
+	}
+
+	if len(info.Manifest) == 0 && len(recoverData) > 0 {
+		if embedded, err := recovery.ExtractManifestFromHTML(recoverData); err == nil {
+			info.Manifest = embedded
+			info.ManifestEmbedded = true
+		}
+	}
+
+	return info, nil
+}
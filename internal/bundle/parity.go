@@ -0,0 +1,58 @@
+package bundle
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/eljojo/rememory/internal/core"
+	"github.com/eljojo/rememory/internal/project"
+)
+
+// writeParity writes MANIFEST.age.parity next to MANIFEST.age in output/,
+// when the project sets parity, and removes it when it doesn't. Parity that
+// already matches this MANIFEST.age is kept as it is. It returns the parity
+// file's path, or nothing without parity.
+func writeParity(p *project.Project, manifestPath, manifestChecksum string, manifestSize int64) (string, error) {
+	path := p.ManifestParityPath()
+	if p.Parity == 0 {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return "", fmt.Errorf("removing old parity: %w", err)
+		}
+		return "", nil
+	}
+	if f, err := os.Open(path); err == nil {
+		h, err := core.PeekParityHeader(f)
+		f.Close()
+		if err == nil && h.Checksum == manifestChecksum && h.Percent == p.Parity {
+			return path, nil
+		}
+	}
+
+	src, err := os.Open(manifestPath)
+	if err != nil {
+		return "", fmt.Errorf("reading manifest: %w", err)
+	}
+	defer src.Close()
+	partial := path + ".partial"
+	out, err := os.Create(partial)
+	if err != nil {
+		return "", fmt.Errorf("writing parity: %w", err)
+	}
+	h, err := core.WriteParity(out, src, filepath.Base(manifestPath), manifestSize, p.Parity)
+	if closeErr := out.Close(); closeErr != nil && err == nil {
+		err = fmt.Errorf("writing parity: %w", closeErr)
+	}
+	if err == nil && h.Checksum != manifestChecksum {
+		err = fmt.Errorf("MANIFEST.age changed while its parity was written")
+	}
+	if err != nil {
+		os.Remove(partial)
+		return "", err
+	}
+	if err := os.Rename(partial, path); err != nil {
+		os.Remove(partial)
+		return "", fmt.Errorf("writing parity: %w", err)
+	}
+	return path, nil
+}
@@ -0,0 +1,76 @@
+package bundle
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/eljojo/rememory/internal/translations"
+)
+
+// QuizFilename holds a few questions for the friend about keeping and using
+// their piece, in bundles of projects that set quiz.
+const QuizFilename = "QUIZ.txt"
+
+// GenerateQuizText writes the questions, then their answers for this friend,
+// in the bundle language. The answers come last so the friend can try first.
+func GenerateQuizText(data ReadmeData) string {
+	lang := data.Language
+	if lang == "" {
+		lang = "en"
+	}
+	t := func(key string, args ...any) string {
+		return translations.T("readme", lang, key, args...)
+	}
+
+	questions := []string{"quiz_q_keep", "quiz_q_pieces", "quiz_q_contact", "quiz_q_asked", "quiz_q_never", "quiz_q_install"}
+	answers := []string{t("quiz_a_keep"), quizPieces(data, t), quizContact(data, t), t("quiz_a_asked"), t("quiz_a_never"), t("quiz_a_install")}
+	if data.Share.Locked() {
+		questions = append(questions, "quiz_q_pin")
+		answers = append(answers, t("quiz_a_pin"))
+	}
+
+	var sb strings.Builder
+	sb.WriteString("================================================================================\n")
+	sb.WriteString(fmt.Sprintf("                          %s\n", t("quiz_title")))
+	sb.WriteString(fmt.Sprintf("                              %s\n", t("for", data.Holder)))
+	sb.WriteString("================================================================================\n\n")
+	sb.WriteString(fmt.Sprintf("%s\n\n", t("quiz_intro", data.ProjectName)))
+
+	for i, key := range questions {
+		sb.WriteString(fmt.Sprintf("%d. %s\n", i+1, t(key)))
+		sb.WriteString("   ____________________________________________________________\n\n")
+	}
+
+	sb.WriteString("--------------------------------------------------------------------------------\n")
+	sb.WriteString(fmt.Sprintf("%s\n", t("quiz_answers")))
+	sb.WriteString("--------------------------------------------------------------------------------\n")
+	for i, answer := range answers {
+		sb.WriteString(fmt.Sprintf("%d. %s\n", i+1, answer))
+	}
+	return sb.String()
+}
+
+// quizPieces answers how many pieces recovery needs, in the terms README.txt
+// uses for this project.
+func quizPieces(data ReadmeData, t translateFunc) string {
+	switch {
+	case data.Groups != nil:
+		return t("quiz_a_pieces_groups")
+	case data.Weighted:
+		return t("quiz_a_pieces_weighted", data.Threshold, len(data.Extra)+1)
+	default:
+		return t("quiz_a_pieces", data.Threshold)
+	}
+}
+
+// quizContact answers whom to reach, naming only the friends README.txt lists.
+func quizContact(data ReadmeData, t translateFunc) string {
+	if !data.Privacy.ListsOthers() || len(data.OtherFriends) == 0 {
+		return t("quiz_a_contact_anon")
+	}
+	names := make([]string, len(data.OtherFriends))
+	for i, f := range data.OtherFriends {
+		names[i] = f.Name
+	}
+	return t("quiz_a_contact", strings.Join(names, ", "))
+}
@@ -8,7 +8,9 @@ import (
 	"golang.org/x/text/unicode/norm"
 
 	"github.com/eljojo/rememory/internal/core"
+	"github.com/eljojo/rememory/internal/faq"
 	"github.com/eljojo/rememory/internal/project"
+	"github.com/eljojo/rememory/internal/rotation"
 	"github.com/eljojo/rememory/internal/translations"
 )
 
@@ -17,17 +19,29 @@ type ReadmeData struct {
 	ProjectName      string
 	Holder           string
 	Share            *core.Share
+	Extra            []*core.Share // The holder's further pieces, when they hold more than one
+	Weighted         bool          // Some friends hold more than one piece, so Total counts pieces, not people
 	OtherFriends     []project.Friend
 	Threshold        int
 	Total            int
+	Groups           *project.GroupPolicy // Recovery rule of a grouped project; nil otherwise
 	Version          string
 	GitHubReleaseURL string
 	ManifestChecksum string
 	RecoverChecksum  string
 	Created          time.Time
-	Anonymous        bool
-	Language         string // Bundle language (e.g. "en", "es"); defaults to "en"
-	ManifestEmbedded bool   // true when manifest is embedded in recover.html
+	Privacy          project.Privacy       // What the README tells about the other friends
+	Language         string                // Bundle language (e.g. "en", "es"); defaults to "en"
+	WordList         string                // Recovery word list language; defaults to Language
+	ManifestEmbedded bool                  // true when manifest is embedded in recover.html
+	Crypto           string                // Crypto profile the project was sealed under; empty for the standard set
+	Rotation         *rotation.Note        // Earlier seals these bundles replace; nil when none
+	Commitments      Commitments           // Checksums of every share of the seal; nil leaves them out
+	VSS              core.VSSCommitments   // Feldman commitments to the seal's verifiable split; nil leaves them out
+	Delay            string                // How long recovery waits once enough pieces come together; empty without a delay
+	Audio            bool                  // The bundle has PIECE.wav, reading the digit groups aloud
+	FAQ              faq.Facts             // What the holder's questions are answered from; the zero value leaves them out
+	Beacon           *project.BeaconConfig // Where holders check the bundle is current; nil without a beacon
 }
 
 // writeWordGrid writes a two-column word grid to the string builder.
@@ -46,6 +60,25 @@ func writeWordGrid(sb *strings.Builder, words []string) {
 	}
 }
 
+// writeSunset writes the review and expiry dates the owner set, if any, and
+// points to SUPERSEDED.txt when the bundles replace earlier ones.
+func writeSunset(sb *strings.Builder, data ReadmeData, t translateFunc) {
+	share := data.Share
+	if share.ReviewBy.IsZero() && share.Expires.IsZero() && data.Rotation == nil {
+		return
+	}
+	if !share.ReviewBy.IsZero() {
+		sb.WriteString(fmt.Sprintf("!!  %s\n", t("sunset_review", share.ReviewBy.Format(core.DateFormat))))
+	}
+	if !share.Expires.IsZero() {
+		sb.WriteString(fmt.Sprintf("!!  %s\n", t("sunset_expires", share.Expires.Format(core.DateFormat))))
+	}
+	if data.Rotation != nil {
+		sb.WriteString(fmt.Sprintf("!!  %s\n", t("superseded", data.Rotation.SupersededDates(), rotation.FileName)))
+	}
+	sb.WriteString("\n")
+}
+
 // GenerateReadme creates the README.txt content with all embedded information.
 func GenerateReadme(data ReadmeData) string {
 	lang := data.Language
@@ -69,29 +102,40 @@ func GenerateReadme(data ReadmeData) string {
 	sb.WriteString(fmt.Sprintf("%s\n", t("what_is_this")))
 	sb.WriteString("--------------------------------------------------------------------------------\n")
 	sb.WriteString(fmt.Sprintf("%s\n", t("what_bundle_for", data.ProjectName)))
-	sb.WriteString(fmt.Sprintf("%s\n", t("what_one_of", data.Total)))
-	sb.WriteString(fmt.Sprintf("%s\n\n", t("what_threshold", data.Threshold)))
+	if data.Weighted {
+		sb.WriteString(fmt.Sprintf("%s\n", t("what_pieces", len(data.Extra)+1, data.Total)))
+		sb.WriteString(fmt.Sprintf("%s\n\n", t("what_threshold_pieces", data.Threshold)))
+	} else {
+		sb.WriteString(fmt.Sprintf("%s\n", t("what_one_of", data.Total)))
+		if data.Groups != nil {
+			writeGroups(&sb, data, t)
+			sb.WriteString("\n")
+		} else {
+			sb.WriteString(fmt.Sprintf("%s\n\n", t("what_threshold", data.Threshold)))
+		}
+	}
 
 	// Warning
 	sb.WriteString(fmt.Sprintf("!!  %s\n", t("warning_title")))
-	if data.Anonymous {
+	if !data.Privacy.ListsOthers() {
 		sb.WriteString(fmt.Sprintf("    %s\n\n", t("warning_message_shares")))
 	} else {
 		sb.WriteString(fmt.Sprintf("    %s\n\n", t("warning_message_friends")))
 	}
+	if data.Share.Locked() {
+		sb.WriteString(fmt.Sprintf("!!  %s\n\n", t("pin_locked")))
+	}
+	if data.Delay != "" {
+		sb.WriteString(fmt.Sprintf("!!  %s\n\n", t("recovery_delay", data.Delay)))
+	}
+	writeSunset(&sb, data, t)
 
 	// Other share holders (skip for anonymous mode)
-	if !data.Anonymous {
+	if data.Privacy.ListsOthers() {
 		sb.WriteString("--------------------------------------------------------------------------------\n")
 		sb.WriteString(fmt.Sprintf("%s\n", t("other_holders")))
 		sb.WriteString("--------------------------------------------------------------------------------\n")
-		for _, friend := range data.OtherFriends {
-			sb.WriteString(fmt.Sprintf("%s\n", friend.Name))
-			if friend.Contact != "" {
-				sb.WriteString(fmt.Sprintf("  %s\n", t("contact_label", friend.Contact)))
-			}
-			sb.WriteString("\n")
-		}
+		writeContacts(&sb, data, t)
 	}
 
 	// Sharing your share (what to do when someone asks)
@@ -104,6 +148,72 @@ func GenerateReadme(data ReadmeData) string {
 	sb.WriteString(fmt.Sprintf("  - %s\n", t("sharing_words_phone")))
 	sb.WriteString(fmt.Sprintf("  - %s\n\n", t("sharing_qr_mail")))
 
+	writeInstructions(&sb, data, t)
+	writeFAQ(&sb, data, t)
+
+	// Share block
+	sb.WriteString("--------------------------------------------------------------------------------\n")
+	sb.WriteString(fmt.Sprintf("%s\n", t("your_share")))
+	sb.WriteString("--------------------------------------------------------------------------------\n")
+	writeWords(&sb, data, lang, t)
+	writeDigits(&sb, data, t)
+
+	// PEM block (machine-readable format)
+	sb.WriteString(fmt.Sprintf("%s\n", t("machine_readable")))
+	sb.WriteString(data.Share.Encode())
+	sb.WriteString("\n")
+	for _, extra := range data.Extra {
+		sb.WriteString(fmt.Sprintf("%s\n", t("extra_piece", extra.Index)))
+		sb.WriteString(extra.Encode())
+		sb.WriteString("\n")
+	}
+
+	writeMetadataFooter(&sb, data)
+
+	return sb.String()
+}
+
+type translateFunc func(key string, args ...any) string
+
+// writeGroups writes a grouped project's recovery rule: the groups needed,
+// then each group's name and the pieces it needs, marking the holder's own.
+// It writes nothing for projects without groups.
+func writeGroups(sb *strings.Builder, data ReadmeData, t translateFunc) {
+	if data.Groups == nil {
+		return
+	}
+	sb.WriteString(fmt.Sprintf("%s\n", t("what_groups", data.Groups.Needed, len(data.Groups.Groups))))
+	for _, g := range data.Groups.Groups {
+		key := "group_line"
+		if g.Yours {
+			key = "group_yours"
+		}
+		sb.WriteString(fmt.Sprintf("  - %s\n", t(key, g.Name, g.Threshold, g.Size)))
+	}
+}
+
+// autoStep picks the step saying when recovery starts: after enough pieces,
+// or for grouped projects after enough groups.
+func autoStep(policy *project.GroupPolicy, pieces, groups string) string {
+	if policy != nil {
+		return groups
+	}
+	return pieces
+}
+
+// writeContacts lists the other share holders and how to reach them.
+func writeContacts(sb *strings.Builder, data ReadmeData, t translateFunc) {
+	for _, friend := range data.OtherFriends {
+		sb.WriteString(fmt.Sprintf("%s\n", friend.Name))
+		if friend.Contact != "" {
+			sb.WriteString(fmt.Sprintf("  %s\n", t("contact_label", friend.Contact)))
+		}
+		sb.WriteString("\n")
+	}
+}
+
+// writeInstructions writes the browser and CLI recovery steps.
+func writeInstructions(sb *strings.Builder, data ReadmeData, t translateFunc) {
 	// Primary method - Browser
 	sb.WriteString("--------------------------------------------------------------------------------\n")
 	sb.WriteString(fmt.Sprintf("%s\n", t("recover_browser")))
@@ -119,11 +229,11 @@ func GenerateReadme(data ReadmeData) string {
 		sb.WriteString(fmt.Sprintf("   %s\n", t("recover_step2_drag")))
 		sb.WriteString(fmt.Sprintf("   %s\n\n", t("recover_step2_click")))
 	}
-	if data.Anonymous {
+	if !data.Privacy.ListsOthers() {
 		sb.WriteString(fmt.Sprintf("%s\n", t("recover_anon_step3")))
 		sb.WriteString(fmt.Sprintf("   %s\n", t("recover_anon_step3_drag")))
 		sb.WriteString(fmt.Sprintf("   %s\n\n", t("recover_anon_step3_paste")))
-		sb.WriteString(fmt.Sprintf("%s\n\n", t("recover_anon_step4_auto", data.Threshold)))
+		sb.WriteString(fmt.Sprintf("%s\n\n", autoStep(data.Groups, t("recover_anon_step4_auto", data.Threshold), t("recover_anon_step4_auto_groups"))))
 		sb.WriteString(fmt.Sprintf("%s\n\n", t("recover_anon_step5")))
 	} else {
 		sb.WriteString(fmt.Sprintf("%s\n", t("recover_step3_contact")))
@@ -132,7 +242,7 @@ func GenerateReadme(data ReadmeData) string {
 		sb.WriteString(fmt.Sprintf("   %s\n", t("recover_step4_drag")))
 		sb.WriteString(fmt.Sprintf("   %s\n\n", t("recover_step4_paste")))
 		sb.WriteString(fmt.Sprintf("%s\n", t("recover_step5_checkmarks")))
-		sb.WriteString(fmt.Sprintf("   %s\n\n", t("recover_step5_auto", data.Threshold)))
+		sb.WriteString(fmt.Sprintf("   %s\n\n", autoStep(data.Groups, t("recover_step5_auto", data.Threshold), t("recover_step5_auto_groups"))))
 		sb.WriteString(fmt.Sprintf("%s\n\n", t("recover_step6")))
 	}
 	sb.WriteString(fmt.Sprintf("%s\n\n", t("recover_offline")))
@@ -144,12 +254,30 @@ func GenerateReadme(data ReadmeData) string {
 	sb.WriteString(fmt.Sprintf("%s\n", t("recover_cli_hint")))
 	sb.WriteString(fmt.Sprintf("%s\n\n", data.GitHubReleaseURL))
 	sb.WriteString(fmt.Sprintf("%s\n\n", t("recover_cli_usage")))
+}
 
-	// Share block
+// writeFAQ writes the questions a holder is likely to have, answered from
+// how the project was sealed. It writes nothing without data.FAQ.
+func writeFAQ(sb *strings.Builder, data ReadmeData, t translateFunc) {
+	entries := faq.Build(data.FAQ, t)
+	if len(entries) == 0 {
+		return
+	}
 	sb.WriteString("--------------------------------------------------------------------------------\n")
-	sb.WriteString(fmt.Sprintf("%s\n", t("your_share")))
+	sb.WriteString(fmt.Sprintf("%s\n", t("faq_title")))
 	sb.WriteString("--------------------------------------------------------------------------------\n")
+	for _, e := range entries {
+		sb.WriteString(fmt.Sprintf("%s\n%s\n\n", e.Question, e.Answer))
+	}
+}
 
+// writeWords writes the recovery word grids, in the friend's word list
+// language (the bundle language unless set) first and in English after it
+// when they differ.
+func writeWords(sb *strings.Builder, data ReadmeData, lang string, t translateFunc) {
+	if data.WordList != "" {
+		lang = data.WordList
+	}
 	// Word list (primary human-readable format)
 	nativeWords, _ := data.Share.WordsForLang(core.Lang(lang))
 	if len(nativeWords) > 0 {
@@ -157,27 +285,43 @@ func GenerateReadme(data ReadmeData) string {
 			// Non-English: show native language grid first, then English
 			langName := t("lang_" + lang)
 			sb.WriteString(fmt.Sprintf("%s\n\n", t("recovery_words_title_lang", len(nativeWords), langName)))
-			writeWordGrid(&sb, nativeWords)
+			writeWordGrid(sb, nativeWords)
 			sb.WriteString(fmt.Sprintf("\n%s\n\n", t("recovery_words_hint")))
 
 			// English fallback grid
 			englishWords, _ := data.Share.Words()
 			sb.WriteString(fmt.Sprintf("%s\n\n", t("recovery_words_title_english", len(englishWords))))
-			writeWordGrid(&sb, englishWords)
+			writeWordGrid(sb, englishWords)
 			sb.WriteString(fmt.Sprintf("\n%s\n\n", t("recovery_words_dual_hint")))
 		} else {
 			// English only: single grid
 			sb.WriteString(fmt.Sprintf("%s\n\n", t("recovery_words_title", len(nativeWords))))
-			writeWordGrid(&sb, nativeWords)
+			writeWordGrid(sb, nativeWords)
 			sb.WriteString(fmt.Sprintf("\n%s\n\n", t("recovery_words_hint")))
 		}
 	}
+}
 
-	// PEM block (machine-readable format)
-	sb.WriteString(fmt.Sprintf("%s\n", t("machine_readable")))
-	sb.WriteString(data.Share.Encode())
+// writeDigits writes the share as digit groups, six to a line, for reading
+// over the phone.
+func writeDigits(sb *strings.Builder, data ReadmeData, t translateFunc) {
+	groups, err := data.Share.Digits()
+	if err != nil {
+		return
+	}
+	sb.WriteString(fmt.Sprintf("%s\n\n", t("recovery_digits_title")))
+	for i := 0; i < len(groups); i += 6 {
+		sb.WriteString("  " + strings.Join(groups[i:i+6], "  ") + "\n")
+	}
+	sb.WriteString(fmt.Sprintf("\n%s\n", t("recovery_digits_hint")))
+	if data.Audio {
+		sb.WriteString(fmt.Sprintf("%s\n", t("recovery_digits_audio", AudioFilename)))
+	}
 	sb.WriteString("\n")
+}
 
+// writeMetadataFooter writes the machine-parseable footer read by VerifyBundle.
+func writeMetadataFooter(sb *strings.Builder, data ReadmeData) {
 	// Metadata footer (use fixed English marker for machine parsing)
 	sb.WriteString("================================================================================\n")
 	sb.WriteString("METADATA FOOTER (machine-parseable)\n")
@@ -185,12 +329,22 @@ func GenerateReadme(data ReadmeData) string {
 	sb.WriteString(fmt.Sprintf("rememory-version: %s\n", data.Version))
 	sb.WriteString(fmt.Sprintf("created: %s\n", data.Created.Format(time.RFC3339)))
 	sb.WriteString(fmt.Sprintf("project: %s\n", data.ProjectName))
+	if id := data.Share.BundleID; id != "" {
+		sb.WriteString(fmt.Sprintf("bundle-id: %s\n", id))
+	}
 	sb.WriteString(fmt.Sprintf("threshold: %d\n", data.Threshold))
 	sb.WriteString(fmt.Sprintf("total: %d\n", data.Total))
 	sb.WriteString(fmt.Sprintf("github-release: %s\n", data.GitHubReleaseURL))
 	sb.WriteString(fmt.Sprintf("checksum-manifest: %s\n", data.ManifestChecksum))
 	sb.WriteString(fmt.Sprintf("checksum-recover-html: %s\n", data.RecoverChecksum))
+	if data.Crypto != "" {
+		sb.WriteString(fmt.Sprintf("crypto-profile: %s\n", data.Crypto))
+	}
+	if b := data.Beacon; b != nil {
+		sb.WriteString(fmt.Sprintf("beacon-url: %s\n", b.URL))
+		sb.WriteString(fmt.Sprintf("beacon-key: %s\n", b.PublicKey))
+	}
+	data.Commitments.writeFooterLines(sb)
+	writeVSSFooterLines(sb, data.VSS)
 	sb.WriteString("================================================================================\n")
-
-	return sb.String()
 }
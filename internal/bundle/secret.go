@@ -0,0 +1,50 @@
+package bundle
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/eljojo/rememory/internal/core"
+)
+
+// GenerateSecretReadme explains a piece of a secret split on its own with
+// 'rememory split', which has no MANIFEST.age and no recover.html to go
+// with it. Like SSSS.txt, it is in English only. data.Share and data.Extra
+// are the holder's pieces; the rest of data is read as for README.txt.
+func GenerateSecretReadme(data ReadmeData) string {
+	var sb strings.Builder
+
+	sb.WriteString(fmt.Sprintf("PIECE OF %q FOR %s\n", data.ProjectName, strings.ToUpper(data.Holder)))
+	sb.WriteString(strings.Repeat("=", 80) + "\n\n")
+	sb.WriteString(fmt.Sprintf("This is your piece of %q, a secret that was split into %d pieces\n", data.ProjectName, data.Total))
+	sb.WriteString("and given to people its owner trusts. One piece on its own reveals\n")
+	sb.WriteString(fmt.Sprintf("nothing. Any %d of them together give the secret back, exactly as it was.\n\n", data.Threshold))
+	if n := 1 + len(data.Extra); n > 1 {
+		sb.WriteString(fmt.Sprintf("You hold %d of the pieces.\n\n", n))
+	}
+
+	sb.WriteString("KEEP IT SAFE\n")
+	sb.WriteString("Store this piece somewhere safe and private. Don't pass it on, and don't\n")
+	sb.WriteString("combine it with other pieces until the owner asks you to, or can't.\n\n")
+
+	sb.WriteString("TO GET THE SECRET BACK\n")
+	sb.WriteString(fmt.Sprintf("1. Gather at least %d pieces: this file, or the block below pasted into a\n", data.Threshold))
+	sb.WriteString("   text file, from each person.\n")
+	sb.WriteString(fmt.Sprintf("2. Download ReMemory from %s\n", data.GitHubReleaseURL))
+	sb.WriteString("3. Run:  rememory combine -o secret first.txt second.txt ...\n")
+	sb.WriteString("   or, with the recovery tool alone:\n")
+	sb.WriteString("         rememory-recover combine first.txt second.txt ... > secret\n")
+	sb.WriteString("   The file \"secret\" then holds what was split.\n\n")
+	sb.WriteString("These pieces can't be typed in as words or read by recover.html; keep\n")
+	sb.WriteString("the block below as it is.\n\n")
+
+	sb.WriteString("YOUR PIECE\n")
+	sb.WriteString(core.EncodeShares(append([]*core.Share{data.Share}, data.Extra...)))
+	sb.WriteString("\n")
+
+	sb.WriteString(strings.Repeat("-", 80) + "\n")
+	sb.WriteString(fmt.Sprintf("Created: %s\n", data.Created.Format(time.RFC3339)))
+	sb.WriteString(fmt.Sprintf("ReMemory version: %s\n", data.Version))
+	return sb.String()
+}
@@ -0,0 +1,55 @@
+package bundle
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SLIP39Filename holds a friend's SLIP-0039 words, in bundles of projects
+// that set slip39.
+const SLIP39Filename = "SLIP39.txt"
+
+// GenerateSLIP39Text explains the SLIP-0039 words and lists them in a
+// numbered grid. It is in English only: it is meant for whoever recovers
+// without ReMemory, using tools that are themselves in English.
+func GenerateSLIP39Text(data ReadmeData, mnemonic string) string {
+	words := strings.Fields(mnemonic)
+	var sb strings.Builder
+
+	sb.WriteString(fmt.Sprintf("SLIP-0039 WORDS FOR %s\n", strings.ToUpper(data.Holder)))
+	sb.WriteString(strings.Repeat("=", 80) + "\n\n")
+	sb.WriteString(fmt.Sprintf("These %d words are a second copy of your piece of %q, in SLIP-0039\n", len(words), data.ProjectName))
+	sb.WriteString("(Shamir Backup), a standard that hardware wallets and other tools can read.\n")
+	sb.WriteString("You only need them if ReMemory can't be used. Otherwise, follow README.txt.\n\n")
+	sb.WriteString(fmt.Sprintf("The SLIP-0039 words of any %d friends recover the passphrase. They can't be\n", data.Threshold))
+	sb.WriteString("combined with the ReMemory pieces or the 25 recovery words.\n\n")
+
+	sb.WriteString("WITH REMEMORY\n")
+	sb.WriteString("Give it each friend's SLIP39.txt, or their words typed into a text file:\n")
+	sb.WriteString("  rememory recover first.txt second.txt ...\n\n")
+
+	sb.WriteString("WITHOUT REMEMORY\n")
+	sb.WriteString("1. Enter the words into any SLIP-0039 tool, such as a hardware wallet's\n")
+	sb.WriteString("   recovery or \"shamir recover\" from python-shamir-mnemonic.\n")
+	sb.WriteString("   If it asks for a passphrase, leave it empty.\n")
+	sb.WriteString("2. It gives back a master secret of 64 hexadecimal characters (32 bytes).\n")
+	sb.WriteString("3. Encode those 32 bytes as base64url without padding. The result is the\n")
+	sb.WriteString("   passphrase for MANIFEST.age, which the age tool decrypts:\n")
+	sb.WriteString("     age -d -o manifest.tar.gz MANIFEST.age\n")
+	if data.ManifestEmbedded {
+		sb.WriteString("   This bundle keeps MANIFEST.age inside recover.html, base64-encoded.\n")
+	}
+	sb.WriteString("\n")
+
+	sb.WriteString("YOUR WORDS\n")
+	half := (len(words) + 1) / 2
+	for i := 0; i < half; i++ {
+		left := fmt.Sprintf("%2d. %-12s", i+1, words[i])
+		if j := i + half; j < len(words) {
+			sb.WriteString(fmt.Sprintf("%s %2d. %s\n", left, j+1, words[j]))
+		} else {
+			sb.WriteString(strings.TrimRight(left, " ") + "\n")
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,57 @@
+package bundle
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/eljojo/rememory/internal/core"
+)
+
+// SSKRFilename holds a friend's SSKR share, in bundles of projects that set sskr.
+const SSKRFilename = "SSKR.txt"
+
+// GenerateSSKRText explains the SSKR share and writes it both as a UR and
+// as bytewords. Like SLIP39.txt, it is in English only.
+func GenerateSSKRText(data ReadmeData, ur string) (string, error) {
+	share, err := core.ParseSSKR(ur)
+	if err != nil {
+		return "", err
+	}
+	var sb strings.Builder
+
+	sb.WriteString(fmt.Sprintf("SSKR SHARE FOR %s\n", strings.ToUpper(data.Holder)))
+	sb.WriteString(strings.Repeat("=", 80) + "\n\n")
+	sb.WriteString(fmt.Sprintf("This is a second copy of your piece of %q, as an SSKR share\n", data.ProjectName))
+	sb.WriteString("(Sharded Secret Key Reconstruction), a Blockchain Commons standard that\n")
+	sb.WriteString("wallets and tools such as seedtool can read.\n")
+	sb.WriteString("You only need it if ReMemory can't be used. Otherwise, follow README.txt.\n\n")
+	sb.WriteString(fmt.Sprintf("The SSKR shares of any %d friends recover the passphrase. They can't be\n", data.Threshold))
+	sb.WriteString("combined with the ReMemory pieces, the recovery words, or SLIP-0039 words.\n\n")
+
+	sb.WriteString("WITH REMEMORY\n")
+	sb.WriteString("Give it each friend's SSKR.txt, or their share pasted into a text file:\n")
+	sb.WriteString("  rememory recover first.txt second.txt ...\n")
+	sb.WriteString("Or paste each share into recover.html.\n\n")
+
+	sb.WriteString("WITHOUT REMEMORY\n")
+	sb.WriteString("1. Give the shares, as URs or bytewords, to any SSKR tool, such as\n")
+	sb.WriteString("   \"seedtool --in sskr\" from Blockchain Commons.\n")
+	sb.WriteString("2. It gives back a secret of 64 hexadecimal characters (32 bytes).\n")
+	sb.WriteString("3. Encode those 32 bytes as base64url without padding. The result is the\n")
+	sb.WriteString("   passphrase for MANIFEST.age, which the age tool decrypts:\n")
+	sb.WriteString("     age -d -o manifest.tar.gz MANIFEST.age\n")
+	if data.ManifestEmbedded {
+		sb.WriteString("   This bundle keeps MANIFEST.age inside recover.html, base64-encoded.\n")
+	}
+	sb.WriteString("\n")
+
+	sb.WriteString("YOUR SHARE (UR)\n")
+	sb.WriteString(share.UR() + "\n\n")
+
+	sb.WriteString("YOUR SHARE (BYTEWORDS)\n")
+	words := strings.Fields(share.Bytewords())
+	for i := 0; i < len(words); i += 8 {
+		sb.WriteString(strings.Join(words[i:min(i+8, len(words))], " ") + "\n")
+	}
+	return sb.String(), nil
+}
@@ -0,0 +1,58 @@
+package bundle
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SSSSFilename holds a friend's ssss share, in bundles of projects that set ssss.
+const SSSSFilename = "SSSS.txt"
+
+// GenerateSSSSText explains the ssss share and how to combine it with the
+// classic ssss-combine tool, or by hand if even that is gone. Like
+// SLIP39.txt, it is in English only.
+func GenerateSSSSText(data ReadmeData, share string) string {
+	var sb strings.Builder
+
+	sb.WriteString(fmt.Sprintf("SSSS SHARE FOR %s\n", strings.ToUpper(data.Holder)))
+	sb.WriteString(strings.Repeat("=", 80) + "\n\n")
+	sb.WriteString(fmt.Sprintf("This is a second copy of your piece of %q, for ssss, the classic\n", data.ProjectName))
+	sb.WriteString("\"Shamir's Secret Sharing Scheme\" command-line tool by B. Poettering. It is\n")
+	sb.WriteString("packaged as \"ssss\" in most Linux and BSD distributions and in Homebrew.\n")
+	sb.WriteString("You only need it if ReMemory can't be used. Otherwise, follow README.txt.\n\n")
+	sb.WriteString(fmt.Sprintf("The ssss shares of any %d friends recover the passphrase. They can't be\n", data.Threshold))
+	sb.WriteString("combined with the ReMemory pieces, the recovery words, or other formats.\n\n")
+
+	sb.WriteString("WITH REMEMORY\n")
+	sb.WriteString("Give it each friend's SSSS.txt, or their share line pasted into a text file:\n")
+	sb.WriteString("  rememory recover first.txt second.txt ...\n\n")
+
+	sb.WriteString("WITHOUT REMEMORY\n")
+	sb.WriteString(fmt.Sprintf("1. Run:  ssss-combine -t %d -x -D\n", data.Threshold))
+	sb.WriteString(fmt.Sprintf("   and paste one share line at each prompt, %d in all.\n", data.Threshold))
+	sb.WriteString("   -x reads and prints hexadecimal; -D, because these shares were made\n")
+	sb.WriteString("   without ssss's diffusion layer. Both are needed.\n")
+	sb.WriteString("2. It prints the secret: 64 hexadecimal characters (32 bytes).\n")
+	sb.WriteString("3. Encode those 32 bytes as base64url without padding. The result is the\n")
+	sb.WriteString("   passphrase for MANIFEST.age. On most systems:\n")
+	sb.WriteString("     echo SECRET | xxd -r -p | base64 | tr '+/' '-_' | tr -d '='\n")
+	sb.WriteString("4. Decrypt MANIFEST.age with the age tool, giving it that passphrase:\n")
+	sb.WriteString("     age -d -o manifest.tar.gz MANIFEST.age\n")
+	if data.ManifestEmbedded {
+		sb.WriteString("   This bundle keeps MANIFEST.age inside recover.html, base64-encoded.\n")
+	}
+	sb.WriteString("\n")
+
+	sb.WriteString("IF SSSS IS GONE TOO\n")
+	sb.WriteString("Each share is \"x-y\": x is a number, y a 256-bit value in hexadecimal.\n")
+	sb.WriteString("Read y as a polynomial over GF(2), bit i being the coefficient of z^i,\n")
+	sb.WriteString("in the field GF(2^256) modulo z^256 + z^10 + z^5 + z^2 + 1; x is read the\n")
+	sb.WriteString(fmt.Sprintf("same way. Every share is a point on a polynomial f of degree %d whose\n", data.Threshold))
+	sb.WriteString(fmt.Sprintf("x^%d coefficient is 1 and whose constant term is the secret. Take away\n", data.Threshold))
+	sb.WriteString(fmt.Sprintf("x^%d from each y, then Lagrange interpolation at 0 over any %d shares\n", data.Threshold, data.Threshold))
+	sb.WriteString("gives the secret.\n\n")
+
+	sb.WriteString("YOUR SHARE\n")
+	sb.WriteString(share + "\n")
+	return sb.String()
+}
@@ -0,0 +1,148 @@
+package bundle
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/eljojo/rememory/internal/core"
+	"github.com/eljojo/rememory/internal/project"
+	"github.com/eljojo/rememory/internal/translations"
+)
+
+// ReadmeFields are the placeholders available to a custom README.txt template.
+// ShareBlock and Metadata are required: without them the README can't be used
+// for recovery or checked with verify-bundle.
+type ReadmeFields struct {
+	ProjectName  string
+	Holder       string
+	Threshold    int
+	Total        int
+	Anonymous    bool
+	Language     string
+	OtherFriends []project.Friend
+	Version      string
+	Created      time.Time
+
+	Contacts     string // Other holders and their contact info, as in the built-in README
+	Groups       string // A grouped project's recovery rule, one line per group; empty otherwise
+	Instructions string // Browser and CLI recovery steps
+	FAQ          string // Questions a holder may have, answered from how the project was sealed
+	Words        string // Recovery word grids
+	Digits       string // The piece as digit groups, for reading over the phone
+	Sunset       string // The review and expiry dates the owner set, and any earlier bundles these replace
+	CompactShare string // Single-line share for typing or QR codes
+	ShareBlock   string // The PEM share block (required)
+	Metadata     string // Machine-parseable footer with checksums (required)
+}
+
+// ReadmeTemplate is a parsed custom README.txt template.
+type ReadmeTemplate struct {
+	tmpl *template.Template
+}
+
+// LoadReadmeTemplate reads and checks a README.txt template file.
+func LoadReadmeTemplate(path string) (*ReadmeTemplate, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading README template: %w", err)
+	}
+	return ParseReadmeTemplate(string(data))
+}
+
+// ParseReadmeTemplate parses a template and renders it once with sample data,
+// so unknown placeholders and a missing share block fail before sealing.
+// Besides the fields of ReadmeFields, templates can call {{t "key"}} to use
+// the built-in translations in the friend's language.
+func ParseReadmeTemplate(text string) (*ReadmeTemplate, error) {
+	tmpl, err := template.New("README").
+		Option("missingkey=error").
+		Funcs(template.FuncMap{"t": func(string, ...any) string { return "" }}).
+		Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("parsing README template: %w", err)
+	}
+
+	rt := &ReadmeTemplate{tmpl: tmpl}
+	sample := ReadmeData{
+		ProjectName:  "Sample",
+		Holder:       "Sample",
+		Share:        core.NewShare(2, 1, 2, 2, "Sample", make([]byte, 33)),
+		OtherFriends: []project.Friend{{Name: "Other"}},
+		Threshold:    2,
+		Total:        2,
+	}
+	if _, err := rt.Execute(sample); err != nil {
+		return nil, err
+	}
+	return rt, nil
+}
+
+// Execute renders the README for one friend.
+func (rt *ReadmeTemplate) Execute(data ReadmeData) (string, error) {
+	lang := data.Language
+	if lang == "" {
+		lang = "en"
+	}
+	t := func(key string, args ...any) string {
+		return translations.T("readme", lang, key, args...)
+	}
+
+	fields := ReadmeFields{
+		ProjectName:  data.ProjectName,
+		Holder:       data.Holder,
+		Threshold:    data.Threshold,
+		Total:        data.Total,
+		Anonymous:    !data.Privacy.ListsOthers(),
+		Language:     lang,
+		OtherFriends: data.OtherFriends,
+		Version:      data.Version,
+		Created:      data.Created,
+		CompactShare: data.Share.CompactEncode(),
+		ShareBlock:   core.EncodeShares(append([]*core.Share{data.Share}, data.Extra...)),
+	}
+	var sb strings.Builder
+	writeContacts(&sb, data, t)
+	fields.Contacts = sb.String()
+	sb.Reset()
+	writeGroups(&sb, data, t)
+	fields.Groups = sb.String()
+	sb.Reset()
+	writeInstructions(&sb, data, t)
+	fields.Instructions = sb.String()
+	sb.Reset()
+	writeFAQ(&sb, data, t)
+	fields.FAQ = sb.String()
+	sb.Reset()
+	writeWords(&sb, data, lang, t)
+	fields.Words = sb.String()
+	sb.Reset()
+	writeDigits(&sb, data, t)
+	fields.Digits = sb.String()
+	sb.Reset()
+	writeSunset(&sb, data, t)
+	fields.Sunset = sb.String()
+	sb.Reset()
+	writeMetadataFooter(&sb, data)
+	fields.Metadata = sb.String()
+
+	tmpl, err := rt.tmpl.Clone()
+	if err != nil {
+		return "", err
+	}
+	var out strings.Builder
+	if err := tmpl.Funcs(template.FuncMap{"t": t}).Execute(&out, fields); err != nil {
+		return "", fmt.Errorf("rendering README template: %w", err)
+	}
+
+	result := out.String()
+	if !strings.Contains(result, fields.ShareBlock) {
+		return "", fmt.Errorf("README template must include {{.ShareBlock}}")
+	}
+	if !strings.Contains(result, fields.Metadata) {
+		return "", fmt.Errorf("README template must include {{.Metadata}}")
+	}
+	return result, nil
+}
@@ -0,0 +1,97 @@
+package bundle
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/eljojo/rememory/internal/core"
+	"github.com/eljojo/rememory/internal/project"
+)
+
+// splitManifest writes MANIFEST.age's volumes next to it in output/, when
+// the project sets volume_size and MANIFEST.age is bigger, with their index
+// in MANIFEST.age.sha256. Volumes of an earlier MANIFEST.age are removed; a
+// split that already matches this one is kept as it is. It returns the
+// volumes' paths and the index, or nothing when MANIFEST.age isn't split.
+func splitManifest(p *project.Project, manifestPath, manifestChecksum string, manifestSize int64) ([]string, []byte, error) {
+	volumeSize, err := p.VolumeBytes()
+	if err != nil {
+		return nil, nil, fmt.Errorf("volume_size: %w", err)
+	}
+	old, err := p.ManifestVolumes()
+	if err != nil {
+		return nil, nil, err
+	}
+	split := volumeSize > 0 && manifestSize > volumeSize
+
+	if old != nil {
+		if split && splitMatches(p, old, manifestChecksum, volumeSize) {
+			return volumePaths(p, old), []byte(old.Text()), nil
+		}
+		for _, path := range volumePaths(p, old) {
+			if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+				return nil, nil, fmt.Errorf("removing old volume: %w", err)
+			}
+		}
+		if err := os.Remove(p.ManifestVolumeIndexPath()); err != nil {
+			return nil, nil, fmt.Errorf("removing old volume index: %w", err)
+		}
+	}
+	if !split {
+		return nil, nil, nil
+	}
+
+	f, err := os.Open(manifestPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("reading manifest: %w", err)
+	}
+	defer f.Close()
+	var paths []string
+	x, err := core.SplitVolumes(f, filepath.Base(manifestPath), manifestSize, volumeSize, func(name string) (io.WriteCloser, error) {
+		path := filepath.Join(p.OutputPath(), name)
+		paths = append(paths, path)
+		return os.Create(path)
+	})
+	if err == nil && x.Checksum != manifestChecksum {
+		err = fmt.Errorf("MANIFEST.age changed while it was split")
+	}
+	if err != nil {
+		for _, path := range paths {
+			os.Remove(path)
+		}
+		return nil, nil, err
+	}
+	index := []byte(x.Text())
+	if err := os.WriteFile(p.ManifestVolumeIndexPath(), index, 0644); err != nil {
+		return nil, nil, fmt.Errorf("writing volume index: %w", err)
+	}
+	return paths, index, nil
+}
+
+// volumePaths returns where the volumes in x are: in output/, next to
+// MANIFEST.age.
+func volumePaths(p *project.Project, x *core.VolumeIndex) []string {
+	paths := make([]string, len(x.Volumes))
+	for i, v := range x.Volumes {
+		paths[i] = filepath.Join(p.OutputPath(), filepath.Base(v.Name))
+	}
+	return paths
+}
+
+// splitMatches reports whether the volumes on disk are this MANIFEST.age
+// split into volumeSize pieces. Their checksums were checked when they were
+// written; a volume damaged since shows up in 'rememory verify'.
+func splitMatches(p *project.Project, x *core.VolumeIndex, manifestChecksum string, volumeSize int64) bool {
+	if x.Checksum != manifestChecksum || len(x.Volumes) != int((x.Size+volumeSize-1)/volumeSize) {
+		return false
+	}
+	for i, path := range volumePaths(p, x) {
+		info, err := os.Stat(path)
+		if err != nil || info.Size() != min(volumeSize, x.Size-int64(i)*volumeSize) {
+			return false
+		}
+	}
+	return true
+}
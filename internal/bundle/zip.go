@@ -3,14 +3,18 @@ package bundle
 import (
 	"archive/zip"
 	"fmt"
+	"io"
 	"os"
 	"time"
 )
 
-// ZipFile represents a file to be added to a ZIP archive.
+// ZipFile represents a file to be added to a ZIP archive. A file with a
+// Path is copied from disk as the archive is written, instead of Content,
+// so a large MANIFEST.age never has to fit in memory.
 type ZipFile struct {
 	Name    string
 	Content []byte
+	Path    string
 	ModTime time.Time
 }
 
@@ -37,6 +41,12 @@ func CreateZip(path string, files []ZipFile) error {
 			return fmt.Errorf("creating entry %s: %w", file.Name, err)
 		}
 
+		if file.Path != "" {
+			if err := copyInto(fw, file.Path); err != nil {
+				return fmt.Errorf("writing entry %s: %w", file.Name, err)
+			}
+			continue
+		}
 		if _, err := fw.Write(file.Content); err != nil {
 			return fmt.Errorf("writing entry %s: %w", file.Name, err)
 		}
@@ -44,3 +54,14 @@ func CreateZip(path string, files []ZipFile) error {
 
 	return nil
 }
+
+// copyInto copies the file at path into w.
+func copyInto(w io.Writer, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = io.Copy(w, f)
+	return err
+}
@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/eljojo/rememory/internal/analyze"
+	"github.com/eljojo/rememory/internal/bundle"
+	"github.com/eljojo/rememory/internal/project"
+	"github.com/spf13/cobra"
+)
+
+var analyzeCmd = &cobra.Command{
+	Use:   "analyze",
+	Short: "Look for single points of failure before handing out bundles",
+	Long: `Analyze checks the project for ways recovery could fail, or succeed for
+the wrong people, and suggests a fix for each:
+
+  - every piece is needed, so one lost bundle is fatal
+  - one place or household holds enough pieces to recover, or to stop
+    recovery (set location and household on friends in project.yml)
+  - every copy of the encrypted files is in one place or household
+  - friends without contact details, or anonymous bundles nobody can trace
+  - no bundle carries the encrypted files
+  - QR codes that point to a site you have to keep online
+
+Run it before handing out bundles, and again after changing friends.
+High risks make the command fail, so it can gate a script.
+
+Examples:
+  rememory analyze
+  rememory analyze --json`,
+	Args: cobra.NoArgs,
+	RunE: runAnalyze,
+}
+
+func init() {
+	rootCmd.AddCommand(analyzeCmd)
+}
+
+func runAnalyze(cmd *cobra.Command, args []string) error {
+	p, err := loadFriendProject()
+	if err != nil {
+		return err
+	}
+
+	report := analyze.Analyze(analyzeConfig(p))
+	high := report.Count(analyze.High)
+
+	if jsonOutput {
+		if err := printJSON(report); err != nil {
+			return err
+		}
+	} else {
+		if p.Grouped() {
+			fmt.Printf("%s: %d friends in %d groups, %d groups needed\n\n", p.Name, len(p.Friends), len(p.Groups), p.Threshold)
+		} else if p.Weighted() {
+			fmt.Printf("%s: %d friends holding %d pieces, %d needed\n\n", p.Name, len(p.Friends), p.TotalPieces(), p.Threshold)
+		} else {
+			fmt.Printf("%s: %d friends, %d needed\n\n", p.Name, len(p.Friends), p.Threshold)
+		}
+		for _, risk := range report.Risks {
+			mark := yellow("!")
+			if risk.Severity == analyze.High {
+				mark = red("✗")
+			}
+			fmt.Printf("  %s %s\n", mark, risk.Message)
+			fmt.Printf("    → %s\n", risk.Fix)
+		}
+		for _, passed := range report.Passed {
+			fmt.Printf("  %s %s\n", green("✓"), passed)
+		}
+		fmt.Println()
+		if high == 0 {
+			medium := report.Count(analyze.Medium)
+			fmt.Printf("No high risks, %d warning%s.\n", medium, plural(medium))
+		}
+	}
+
+	if high > 0 {
+		return fmt.Errorf("found %d high risk%s", high, plural(high))
+	}
+	return nil
+}
+
+// analyzeConfig describes the project, and its bundles if sealed, for analysis.
+func analyzeConfig(p *project.Project) analyze.Config {
+	cfg := analyze.Config{
+		Threshold: p.Threshold,
+		Privacy:   p.PrivacyLevel(),
+		Sealed:    p.Sealed != nil,
+	}
+	for _, g := range p.Groups {
+		cfg.Groups = append(cfg.Groups, g.Threshold)
+	}
+	if p.Sealed != nil {
+		cfg.RecoveryURL = p.Sealed.RecoveryURL
+	}
+	for _, f := range p.Friends {
+		h := analyze.Holder{Name: f.Name, Contact: f.Contact, Location: f.Location, Household: f.Household, Group: p.GroupIndex(f), Pieces: f.Pieces()}
+		if p.Sealed != nil {
+			if info, err := bundle.ReadInfo(friendBundlePath(p, f)); err == nil {
+				h.Bundle = true
+				h.Manifest = info.Manifest != nil
+			}
+		}
+		cfg.Holders = append(cfg.Holders, h)
+	}
+	return cfg
+}
+
+// printPlacementWarnings warns, after bundles are made, about places or
+// households that hold too many pieces or every copy of the encrypted files.
+func printPlacementWarnings(p *project.Project) {
+	risks := analyze.Analyze(analyzeConfig(p)).Correlated()
+	if len(risks) == 0 {
+		return
+	}
+	fmt.Println()
+	fmt.Printf("%s Where the bundles will be kept:\n", yellow("Warning:"))
+	for _, risk := range risks {
+		fmt.Printf("  %s %s\n", yellow("!"), risk.Message)
+		fmt.Printf("    → %s\n", risk.Fix)
+	}
+	fmt.Println("  Run 'rememory analyze' for the full check.")
+}
@@ -0,0 +1,261 @@
+package cmd
+
+import (
+	"crypto/ed25519"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/eljojo/rememory/internal/beacon"
+	"github.com/eljojo/rememory/internal/bundle"
+	"github.com/eljojo/rememory/internal/core"
+	"github.com/eljojo/rememory/internal/project"
+	"github.com/eljojo/rememory/internal/rotation"
+	"github.com/spf13/cobra"
+)
+
+var beaconCmd = &cobra.Command{
+	Use:   "beacon",
+	Short: "Publish which bundles are current, for holders who are online",
+	Long: `A beacon is a small signed file, published at an address of your choosing,
+that says which seal of the project is current and which ones it replaced.
+It holds nothing secret: only dates and the fingerprints printed in every
+bundle's README. Every bundle carries the beacon's address and the key it's
+signed with, so a holder who is online can tell whether their bundle is out
+of date, even years after you've sealed again.
+
+Nothing depends on it. Recovery never fetches the beacon, and recover.html
+never goes online: it only tells the holder where to look.
+
+  rememory beacon init https://example.com/rememory/beacon.txt
+  rememory bundle
+  rememory beacon publish
+
+Then, whenever you seal again, publish again. A holder checks their bundle
+with 'rememory beacon check'.`,
+}
+
+var beaconInitCmd = &cobra.Command{
+	Use:   "init <url>",
+	Short: "Set where the beacon is published and make its signing key",
+	Long: `Init records where holders fetch the beacon from, and makes the key it's
+signed with, in beacon.key next to project.yml. Keep the key with the
+project: bundles already handed out only accept beacons signed with it.
+
+The address must be https://. When the beacon is uploaded somewhere else
+than where it's read from, such as a WebDAV folder or a storage bucket
+behind a website, give that with --upload.
+
+Bundles made from now on carry the address and key; run 'rememory bundle'
+to add them to the current ones.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runBeaconInit,
+}
+
+var beaconPublishCmd = &cobra.Command{
+	Use:   "publish",
+	Short: "Write the beacon for the current seal and upload it",
+	Long: `Publish writes output/beacon.txt for the current seal, signed, and uploads
+it with an HTTP PUT to the beacon's upload address, or its address when it
+has none. A token in REMEMORY_BEACON_TOKEN is sent as a bearer token.
+
+With --no-upload, only the file is written, for you to put online yourself.`,
+	Args: cobra.NoArgs,
+	RunE: runBeaconPublish,
+}
+
+var beaconCheckCmd = &cobra.Command{
+	Use:   "check <bundle.zip>",
+	Short: "Check whether a bundle is still the current one",
+	Long: `Check fetches the beacon a bundle points to, checks its signature with
+the key the bundle carries, and says whether the bundle is the current one.
+It needs no project: anyone holding a bundle can run it.
+
+Give --file to check against a beacon.txt you downloaded yourself, without
+going online.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runBeaconCheck,
+}
+
+func init() {
+	beaconInitCmd.Flags().String("upload", "", "Address the beacon is uploaded to, when that isn't where it's read from")
+	beaconPublishCmd.Flags().Bool("no-upload", false, "Only write output/beacon.txt")
+	beaconCheckCmd.Flags().String("file", "", "Check against this beacon.txt instead of fetching it")
+	beaconCmd.AddCommand(beaconInitCmd)
+	beaconCmd.AddCommand(beaconPublishCmd)
+	beaconCmd.AddCommand(beaconCheckCmd)
+	rootCmd.AddCommand(beaconCmd)
+}
+
+func runBeaconInit(cmd *cobra.Command, args []string) error {
+	upload, _ := cmd.Flags().GetString("upload")
+
+	p, err := loadFriendProject()
+	if err != nil {
+		return err
+	}
+	// An existing key is kept: bundles already handed out may carry it
+	var key []byte
+	priv, err := loadBeaconKey(p)
+	switch {
+	case err == nil:
+		fmt.Printf("Using the signing key in %s\n", project.BeaconKeyFile)
+	case os.IsNotExist(err):
+		if priv, err = beacon.GenerateKey(); err != nil {
+			return err
+		}
+		key = beacon.EncodeKey(priv)
+	default:
+		return err
+	}
+
+	previous := p.Beacon
+	p.Beacon = &project.BeaconConfig{URL: strings.TrimSpace(args[0]), Upload: strings.TrimSpace(upload), PublicKey: beacon.PublicKey(priv)}
+	if err := p.Validate(); err != nil {
+		return err
+	}
+	if err := snapshot(p, "beacon-init"); err != nil {
+		return err
+	}
+	if key != nil {
+		if err := os.WriteFile(p.BeaconKeyPath(), key, 0600); err != nil {
+			return fmt.Errorf("saving beacon key: %w", err)
+		}
+		fmt.Printf("%s Made a signing key in %s; keep it with the project\n", green("✓"), project.BeaconKeyFile)
+	}
+	if err := p.Save(); err != nil {
+		return err
+	}
+	logEvent(p, "beacon-init", "beacon at %s", p.Beacon.URL)
+
+	fmt.Printf("%s Beacon address: %s\n", green("✓"), p.Beacon.URL)
+	if previous != nil && previous.URL != p.Beacon.URL {
+		fmt.Printf("  %s Bundles already handed out still look for it at %s; keep publishing there too, or hand out new bundles.\n", yellow("Note:"), previous.URL)
+	}
+	fmt.Println()
+	fmt.Println("Run 'rememory bundle' so bundles carry the address and key, then 'rememory beacon publish'.")
+	return nil
+}
+
+func runBeaconPublish(cmd *cobra.Command, args []string) error {
+	noUpload, _ := cmd.Flags().GetBool("no-upload")
+	cmd.SilenceUsage = true // Failures from here on aren't about how it was called
+
+	p, err := loadFriendProject()
+	if err != nil {
+		return err
+	}
+	if p.Sealed == nil {
+		return fmt.Errorf("project must be sealed first (run 'rememory seal')")
+	}
+	if p.Beacon == nil {
+		return fmt.Errorf("no beacon set up (run 'rememory beacon init <url>')")
+	}
+	priv, err := loadBeaconKey(p)
+	if err != nil {
+		return fmt.Errorf("reading %s: %w", project.BeaconKeyFile, err)
+	}
+	if beacon.PublicKey(priv) != p.Beacon.PublicKey {
+		return fmt.Errorf("%s isn't the key in project.yml's beacon; bundles wouldn't accept what it signs", project.BeaconKeyFile)
+	}
+
+	b := beacon.Sign(priv, core.Now(), p.Sealed.At, p.Sealed.ManifestChecksum, p.Superseded)
+	text := b.Text()
+	path := filepath.Join(p.OutputPath(), beacon.FileName)
+	if err := os.WriteFile(path, []byte(text), 0644); err != nil {
+		return fmt.Errorf("writing beacon: %w", err)
+	}
+	fmt.Printf("%s %s: current seal %s, %d earlier\n", green("✓"), path, b.Fingerprint, len(b.Superseded))
+	if noUpload {
+		fmt.Printf("\nPut it online at %s.\n", p.Beacon.URL)
+		return nil
+	}
+
+	target := p.Beacon.Upload
+	if target == "" {
+		target = p.Beacon.URL
+	}
+	fmt.Printf("Uploading to %s... ", target)
+	if err := beacon.Publish(target, os.Getenv("REMEMORY_BEACON_TOKEN"), text); err != nil {
+		fmt.Println("FAILED")
+		return fmt.Errorf("%w; put %s online yourself, or try again", err, path)
+	}
+	fmt.Println("OK")
+	logEvent(p, "beacon-publish", "current seal %s", b.Fingerprint)
+
+	// Read it back as a holder would, where it's read from
+	if fetched, err := beacon.Fetch(p.Beacon.URL); err != nil {
+		fmt.Printf("  %s Couldn't read it back from %s: %v\n", yellow("Note:"), p.Beacon.URL, err)
+	} else if got, err := beacon.Parse(fetched, p.Beacon.PublicKey); err != nil || got.Fingerprint != b.Fingerprint {
+		fmt.Printf("  %s %s doesn't serve the beacon just published yet; it may take a while to show.\n", yellow("Note:"), p.Beacon.URL)
+	}
+	return nil
+}
+
+func runBeaconCheck(cmd *cobra.Command, args []string) error {
+	file, _ := cmd.Flags().GetString("file")
+
+	info, err := bundle.ReadInfo(args[0])
+	if err != nil {
+		return err
+	}
+	url, key := info.Metadata["beacon-url"], info.Metadata["beacon-key"]
+	if url == "" || key == "" {
+		return fmt.Errorf("this bundle doesn't point to a beacon; ask whoever gave it to you whether it's current")
+	}
+	checksum := info.Metadata["checksum-manifest"]
+	cmd.SilenceUsage = true // Failures from here on aren't about how it was called
+
+	fmt.Printf("Bundle: %s (fingerprint %s)\n", filepath.Base(args[0]), rotation.Fingerprint(checksum))
+	var text string
+	if file != "" {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return fmt.Errorf("reading beacon: %w", err)
+		}
+		text = string(data)
+		fmt.Printf("Beacon: %s\n", file)
+	} else {
+		fmt.Printf("Beacon: %s\n", url)
+		if text, err = beacon.Fetch(url); err != nil {
+			return fmt.Errorf("%w; the bundle still works for recovery, this only tells whether it's current", err)
+		}
+	}
+	b, err := beacon.Parse(text, key)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("  %s Signed with the bundle's key, published %s\n", green("✓"), b.Published.Format(core.DateFormat))
+	fmt.Println()
+
+	if b.Current(checksum) {
+		fmt.Printf("%s This bundle is the current one (sealed %s).\n", green("✓"), b.Sealed.Format(core.DateFormat))
+		return nil
+	}
+	if g, ok := b.Supersedes(checksum); ok {
+		fmt.Printf("%s This bundle is out of date: it's from the seal of %s, replaced on %s.\n", red("✗"), g.Sealed.Format(core.DateFormat), b.Sealed.Format(core.DateFormat))
+	} else {
+		fmt.Printf("%s The beacon doesn't list this bundle's seal; the current one is from %s.\n", yellow("!"), b.Sealed.Format(core.DateFormat))
+	}
+	return fmt.Errorf("ask the owner for the current bundle, and destroy this one as carefully as you kept it")
+}
+
+// loadBeaconKey reads the project's beacon signing key.
+func loadBeaconKey(p *project.Project) (ed25519.PrivateKey, error) {
+	data, err := os.ReadFile(p.BeaconKeyPath())
+	if err != nil {
+		return nil, err
+	}
+	return beacon.ParseKey(data)
+}
+
+// printBeaconReminder reminds to publish the beacon after sealing again, so
+// holders of the earlier bundles can tell.
+func printBeaconReminder(p *project.Project) {
+	if p.Beacon == nil {
+		return
+	}
+	fmt.Println()
+	fmt.Printf("%s Run 'rememory beacon publish' so holders of earlier bundles can tell they're out of date.\n", yellow("Note:"))
+}
@@ -1,12 +1,15 @@
 package cmd
 
 import (
+	"bytes"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/eljojo/rememory/internal/bundle"
 	"github.com/eljojo/rememory/internal/core"
+	"github.com/eljojo/rememory/internal/crypto"
 	"github.com/eljojo/rememory/internal/html"
 	"github.com/eljojo/rememory/internal/project"
 	"github.com/spf13/cobra"
@@ -22,6 +25,12 @@ var bundleCmd = &cobra.Command{
 Note: 'rememory seal' automatically generates bundles, so you typically
 don't need to run this command separately.
 
+With --refresh, bundles are rebuilt with this version's recover.html while
+the pieces and MANIFEST.age stay exactly as they were. It refuses to run if
+any sealed file no longer matches project.yml, and checks afterwards that
+every bundle still carries the same piece and manifest, byte for byte.
+Use it to reissue bundles after a recovery fix without touching the cryptography.
+
 Each bundle contains:
   - README.txt (with embedded share, contacts, instructions)
   - README.pdf (same content, formatted for printing)
@@ -32,6 +41,7 @@ Each bundle contains:
 
 func init() {
 	bundleCmd.Flags().String("recovery-url", core.DefaultRecoveryURL, "Base URL for QR code in PDF")
+	bundleCmd.Flags().Bool("refresh", false, "Rebuild with the current recover.html, verifying pieces and manifest are unchanged")
 	bundleCmd.Flags().Bool("no-embed-manifest", false, "Do not embed MANIFEST.age in recover.html (it is embedded by default when 5 MB or less)")
 	rootCmd.AddCommand(bundleCmd)
 }
@@ -65,11 +75,35 @@ func runBundle(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("recover.wasm not embedded - rebuild with 'make build'")
 	}
 
-	// Generate bundles
-	fmt.Printf("Generating bundles for %d friends...\n\n", len(p.Friends))
-
 	recoveryURL, _ := cmd.Flags().GetString("recovery-url")
 	noEmbedManifest, _ := cmd.Flags().GetBool("no-embed-manifest")
+	refresh, _ := cmd.Flags().GetBool("refresh")
+
+	// Remember what each existing bundle was made with before replacing it
+	previous := make(map[string]*bundle.Info)
+	if refresh {
+		if err := checkSealedFiles(p); err != nil {
+			return fmt.Errorf("refusing to refresh: %w", err)
+		}
+		for _, friend := range p.Friends {
+			if info, err := bundle.ReadInfo(friendBundlePath(p, friend)); err == nil {
+				previous[friend.Name] = info
+			}
+		}
+	}
+
+	if info, err := os.Stat(p.ManifestAgePath()); err == nil {
+		size := info.Size()
+		if decoy, err := os.Stat(p.DecoyPath()); err == nil {
+			size += decoy.Size() // recover.html carries the decoy too
+		}
+		if err := checkSpace(bundleSpace(p, size, wasmBytes)); err != nil {
+			return err
+		}
+	}
+
+	// Generate bundles
+	fmt.Printf("Generating bundles for %d friends...\n\n", len(p.Friends))
 
 	cfg := bundle.Config{
 		Version:          version,
@@ -79,24 +113,165 @@ func runBundle(cmd *cobra.Command, args []string) error {
 		NoEmbedManifest:  noEmbedManifest,
 	}
 
-	if err := bundle.GenerateAll(p, cfg); err != nil {
+	changes, err := bundle.Regenerate(p, cfg)
+	if err != nil {
 		return fmt.Errorf("generating bundles: %w", err)
 	}
+	if err := recordRecoveryURL(p, recoveryURL); err != nil {
+		return err
+	}
+
+	if refresh {
+		logEvent(p, "bundle", "refreshed %d bundles", len(p.Friends))
+		return printRefreshSummary(p, previous, changes)
+	}
+	logEvent(p, "bundle", "made %d bundles", len(p.Friends))
 
 	// Print summary
 	bundlesDir := filepath.Join(p.OutputPath(), "bundles")
-	entries, _ := os.ReadDir(bundlesDir)
-
 	fmt.Println("Created bundles:")
-	for _, entry := range entries {
-		if !entry.IsDir() {
-			info, _ := entry.Info()
-			fmt.Printf("  %s %s (%s)\n", green("✓"), entry.Name(), formatSize(info.Size()))
+	for _, c := range changes {
+		size := ""
+		if info, err := os.Stat(c.Path); err == nil {
+			size = formatSize(info.Size())
 		}
+		fmt.Printf("  %s %s (%s)%s\n", green("✓"), filepath.Base(c.Path), size, describeChange(c))
 	}
 
 	fmt.Printf("\nBundles saved to: %s\n", bundlesDir)
 	fmt.Println("\nNote: Each README contains the friend's share - remind them not to share it!")
+	printReprints(changes)
+	printPlacementWarnings(p)
+
+	if jsonOutput {
+		return printJSON(bundleResult{Bundles: jsonChangedBundles(p, changes)})
+	}
+	return nil
+}
+
+// describeChange says what rebuilding did to an existing bundle, for the
+// end of its summary line; nothing for a bundle that wasn't there before.
+func describeChange(c bundle.Change) string {
+	switch {
+	case c.New:
+		return ""
+	case c.Unchanged():
+		return " unchanged, left as it was"
+	default:
+		return " changed: " + strings.Join(c.Files, ", ")
+	}
+}
+
+// printReprints names the friends whose printed README is out of date, when
+// some earlier bundles were kept or only changed where nothing is printed.
+func printReprints(changes []bundle.Change) {
+	var reprint []string
+	kept := false
+	for _, c := range changes {
+		if c.Reprint() {
+			reprint = append(reprint, c.Friend)
+		} else {
+			kept = true
+		}
+	}
+	if !kept {
+		return
+	}
+	if len(reprint) == 0 {
+		fmt.Println("\nNo README changed: printed copies are still current.")
+		return
+	}
+	fmt.Printf("\nPrint new copies for: %s. Everyone else's printed README is still current.\n", strings.Join(reprint, ", "))
+}
+
+// jsonChangedBundles is jsonBundles with what changed in each bundle.
+func jsonChangedBundles(p *project.Project, changes []bundle.Change) []jsonBundle {
+	out := jsonBundles(p)
+	byFriend := make(map[string]bundle.Change, len(changes))
+	for _, c := range changes {
+		byFriend[c.Friend] = c
+	}
+	for i := range out {
+		if c, ok := byFriend[out[i].Friend]; ok {
+			out[i].Changed = c.Files
+			out[i].Unchanged = c.Unchanged()
+			out[i].Reprint = c.Reprint()
+		}
+	}
+	return out
+}
+
+type bundleResult struct {
+	Refreshed bool         `json:"refreshed,omitempty"`
+	Bundles   []jsonBundle `json:"bundles"`
+}
+
+// checkSealedFiles confirms MANIFEST.age and every share file still match
+// the checksums recorded in project.yml.
+func checkSealedFiles(p *project.Project) error {
+	checksum, err := crypto.HashFile(p.ManifestAgePath())
+	if err != nil {
+		return fmt.Errorf("reading MANIFEST.age: %w", err)
+	}
+	if checksum != p.Sealed.ManifestChecksum {
+		return fmt.Errorf("MANIFEST.age changed since sealing")
+	}
 
+	for _, si := range p.Sealed.Shares {
+		checksum, err := crypto.HashFile(filepath.Join(p.Path, si.File))
+		if err != nil {
+			return fmt.Errorf("reading %s: %w", si.File, err)
+		}
+		if checksum != si.Checksum {
+			return fmt.Errorf("%s changed since sealing", si.File)
+		}
+	}
+	return nil
+}
+
+// printRefreshSummary checks each rebuilt bundle against the sealed files and
+// the bundle it replaced, and shows which version it was made with before.
+func printRefreshSummary(p *project.Project, previous map[string]*bundle.Info, changes []bundle.Change) error {
+	manifestData, err := os.ReadFile(p.ManifestAgePath())
+	if err != nil {
+		return fmt.Errorf("reading manifest: %w", err)
+	}
+
+	fmt.Println("Refreshed bundles:")
+	result := bundleResult{Refreshed: true}
+	bundles := jsonChangedBundles(p, changes)
+	for i, friend := range p.Friends {
+		path := friendBundlePath(p, friend)
+		info, err := bundle.ReadInfo(path)
+		if err != nil {
+			return fmt.Errorf("reading refreshed bundle for %s: %w", friend.Name, err)
+		}
+		if !bytes.Equal(info.Manifest, manifestData) {
+			return fmt.Errorf("bundle for %s: manifest differs from MANIFEST.age", friend.Name)
+		}
+
+		from := "new"
+		if old, ok := previous[friend.Name]; ok {
+			if !bytes.Equal(old.Share.Data, info.Share.Data) {
+				return fmt.Errorf("bundle for %s: piece differs from the one it replaced", friend.Name)
+			}
+			if v := old.Metadata["rememory-version"]; v != "" {
+				from = v
+			}
+		}
+		fmt.Printf("  %s %s (%s → %s)%s\n", green("✓"), filepath.Base(path), from, version, describeChange(changes[i]))
+
+		b := bundles[i]
+		b.PreviousVersion = from
+		result.Bundles = append(result.Bundles, b)
+	}
+
+	fmt.Println()
+	fmt.Println("Pieces and MANIFEST.age are unchanged. Old and new bundles work together.")
+	printReprints(changes)
+
+	if jsonOutput {
+		return printJSON(result)
+	}
 	return nil
 }
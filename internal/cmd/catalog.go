@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/eljojo/rememory/internal/core"
+	"github.com/eljojo/rememory/internal/recovery"
+	"github.com/spf13/cobra"
+)
+
+var catalogCmd = &cobra.Command{
+	Use:   "catalog share1.txt share2.txt ... [--catalog CATALOG.age]",
+	Short: "List what a seal holds, with fewer pieces than recovery needs",
+	Long: `Catalog opens the list of sealed files — names, sizes, and the owner's
+description — without recovering any of them. Projects that set a catalog in
+project.yml need fewer pieces for this than for a recovery, so an executor
+can see what's there before deciding to gather everyone.
+
+Pieces are read like 'rememory recover' reads them, but only share files,
+README.txt, bundle ZIPs, and recover.html carry their part of the catalog;
+recovery words and codes don't. The catalog itself is embedded in every
+bundle's recover.html, so passing a bundle ZIP is enough; otherwise name
+CATALOG.age with --catalog.
+
+Example:
+  rememory catalog bundle-alice.zip bundle-bob.zip`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runCatalog,
+}
+
+var catalogFile string
+
+func init() {
+	catalogCmd.Flags().StringVar(&catalogFile, "catalog", "", "Path to CATALOG.age, or a recover.html or bundle ZIP holding it (default: the first piece that holds it)")
+	rootCmd.AddCommand(catalogCmd)
+}
+
+func runCatalog(cmd *cobra.Command, args []string) error {
+	shares, err := recovery.ReadShareFiles(args)
+	if err != nil {
+		return err
+	}
+	data, err := readCatalogFrom(catalogFile, args)
+	if err != nil {
+		return err
+	}
+	catalog, err := recovery.OpenCatalog(shares, data)
+	if err != nil {
+		return err
+	}
+	printCatalog(catalog)
+	return nil
+}
+
+// readCatalogFrom reads the catalog from path, or else from the first of
+// the inputs that holds one, or else from CATALOG.age here.
+func readCatalogFrom(path string, inputs []string) ([]byte, error) {
+	if path != "" {
+		return recovery.ReadCatalog(path)
+	}
+	for _, input := range inputs {
+		lower := strings.ToLower(input)
+		if !strings.HasSuffix(lower, ".zip") && !recovery.IsHTML(input) {
+			continue
+		}
+		if data, err := recovery.ReadCatalog(input); err == nil {
+			return data, nil
+		}
+	}
+	if _, err := os.Stat(core.CatalogFile); err == nil {
+		return recovery.ReadCatalog(core.CatalogFile)
+	}
+	return nil, fmt.Errorf("no catalog found: pass a bundle ZIP or recover.html, or use --catalog CATALOG.age")
+}
+
+func printCatalog(c *core.Catalog) {
+	fmt.Println()
+	fmt.Printf("%s, sealed %s\n", c.Project, c.Sealed.Format(core.DateFormat))
+	if c.Description != "" {
+		fmt.Println()
+		fmt.Println(c.Description)
+	}
+	var total int64
+	for _, f := range c.Files {
+		total += f.Size
+	}
+	fmt.Println()
+	fmt.Printf("%d file%s, %s:\n", len(c.Files), plural(len(c.Files)), formatSize(total))
+	for _, f := range c.Files {
+		fmt.Printf("  %-40s %10s\n", f.Path, formatSize(f.Size))
+	}
+	fmt.Println()
+	fmt.Println("The files themselves need a full recovery: 'rememory recover' with enough pieces.")
+}
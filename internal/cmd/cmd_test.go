@@ -1,9 +1,26 @@
 package cmd
 
 import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"crypto/rand"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/eljojo/rememory/internal/bundle"
+	"github.com/eljojo/rememory/internal/core"
+	"github.com/eljojo/rememory/internal/estate"
 	"github.com/eljojo/rememory/internal/project"
+	"github.com/eljojo/rememory/internal/recovery"
 )
 
 func TestFormatSize(t *testing.T) {
@@ -28,6 +45,286 @@ func TestFormatSize(t *testing.T) {
 	}
 }
 
+func TestSealMetricsStage(t *testing.T) {
+	var m sealMetrics
+	m.stage("archive", time.Now().Add(-time.Second), 1000)
+	m.stage("split", time.Now(), 0)
+
+	if len(m.Stages) != 2 {
+		t.Fatalf("got %d stages, want 2", len(m.Stages))
+	}
+	archive := m.Stages[0]
+	if archive.Name != "archive" || archive.Millis < 1000 {
+		t.Errorf("archive stage = %+v, want about a second", archive)
+	}
+	if archive.BytesPerSecond <= 0 || archive.BytesPerSecond > 1000 {
+		t.Errorf("archive throughput = %v, want at most 1000 bytes per second", archive.BytesPerSecond)
+	}
+	if m.Stages[1].BytesPerSecond != 0 {
+		t.Errorf("split read no bytes, but has throughput %v", m.Stages[1].BytesPerSecond)
+	}
+}
+
+func TestArchiveEncrypted(t *testing.T) {
+	dir := t.TempDir()
+	manifestDir := filepath.Join(dir, "manifest")
+	os.MkdirAll(manifestDir, 0755)
+	os.WriteFile(filepath.Join(manifestDir, "secret.txt"), []byte("the combination is 12-34-56"), 0644)
+	path := filepath.Join(dir, "MANIFEST.age")
+
+	result, stats, err := archiveEncrypted(manifestDir, path, core.Secret("test-passphrase"), 0, false, "", nil)
+	if err != nil {
+		t.Fatalf("archiveEncrypted: %v", err)
+	}
+	archived := stats.Bytes
+	if len(result.Files) != 1 || archived == 0 {
+		t.Errorf("got %d files and %d archived bytes", len(result.Files), archived)
+	}
+	if stats.KDF <= 0 || stats.Encrypt <= 0 {
+		t.Errorf("stats = %+v, want scrypt and encryption timed", stats)
+	}
+	if _, err := os.Stat(path + ".partial"); !os.IsNotExist(err) {
+		t.Errorf("MANIFEST.age.partial left behind")
+	}
+
+	encrypted, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer encrypted.Close()
+	var archive bytes.Buffer
+	if err := core.Decrypt(&archive, encrypted, "test-passphrase"); err != nil {
+		t.Fatalf("decrypting: %v", err)
+	}
+	if int64(archive.Len()) != archived {
+		t.Errorf("decrypted %d bytes, archived %d", archive.Len(), archived)
+	}
+	files, err := core.ExtractTarGz(archive.Bytes())
+	if err != nil {
+		t.Fatalf("extracting: %v", err)
+	}
+	if len(files) != 1 || string(files[0].Data) != "the combination is 12-34-56" {
+		t.Errorf("extracted %+v", files)
+	}
+
+	// A failed seal removes its partial file and leaves no MANIFEST.age
+	failed := filepath.Join(dir, "failed.age")
+	if _, _, err := archiveEncrypted(filepath.Join(dir, "missing"), failed, core.Secret("test-passphrase"), 0, false, "", nil); err == nil {
+		t.Fatal("expected an error for a missing manifest directory")
+	}
+	for _, p := range []string{failed, failed + ".partial"} {
+		if _, err := os.Stat(p); !os.IsNotExist(err) {
+			t.Errorf("%s left behind after a failed seal", filepath.Base(p))
+		}
+	}
+}
+
+func TestReencryptManifest(t *testing.T) {
+	dir := t.TempDir()
+	manifestDir := filepath.Join(dir, "manifest")
+	os.MkdirAll(manifestDir, 0755)
+	os.WriteFile(filepath.Join(manifestDir, "secret.txt"), []byte("the combination is 12-34-56"), 0644)
+	path := filepath.Join(dir, "MANIFEST.age")
+	if _, _, err := archiveEncrypted(manifestDir, path, core.Secret("old-passphrase"), 0, false, "", nil); err != nil {
+		t.Fatal(err)
+	}
+	before, _ := os.ReadFile(path)
+	var archive bytes.Buffer
+	if err := core.Decrypt(&archive, bytes.NewReader(before), "old-passphrase"); err != nil {
+		t.Fatal(err)
+	}
+
+	// The wrong passphrase leaves MANIFEST.age as it was
+	if _, err := reencryptManifest(path, core.Secret("wrong"), core.Secret("new-passphrase"), 0, false); err == nil {
+		t.Fatal("expected an error for the wrong passphrase")
+	}
+	if after, _ := os.ReadFile(path); !bytes.Equal(after, before) {
+		t.Error("a failed re-encryption changed MANIFEST.age")
+	}
+	if _, err := os.Stat(path + ".partial"); !os.IsNotExist(err) {
+		t.Errorf("MANIFEST.age.partial left behind")
+	}
+
+	stats, err := reencryptManifest(path, core.Secret("old-passphrase"), core.Secret("new-passphrase"), 0, false)
+	if err != nil {
+		t.Fatalf("reencryptManifest: %v", err)
+	}
+	if stats.Bytes != int64(archive.Len()) {
+		t.Errorf("re-encrypted %d bytes, archive has %d", stats.Bytes, archive.Len())
+	}
+
+	// The same archive, byte for byte, opens with the new passphrase only
+	after, _ := os.ReadFile(path)
+	var again bytes.Buffer
+	if err := core.Decrypt(&again, bytes.NewReader(after), "new-passphrase"); err != nil {
+		t.Fatalf("decrypting with the new passphrase: %v", err)
+	}
+	if !bytes.Equal(again.Bytes(), archive.Bytes()) {
+		t.Error("the archive changed")
+	}
+	if err := core.Decrypt(io.Discard, bytes.NewReader(after), "old-passphrase"); err == nil {
+		t.Error("the old passphrase still opens MANIFEST.age")
+	}
+}
+
+func TestSealUpdate(t *testing.T) {
+	p, err := project.New(filepath.Join(t.TempDir(), "project"), "Update", 2, []project.Friend{
+		{Name: "Alice", Contact: "alice@example.com"},
+		{Name: "Bob", Contact: "bob@example.com"},
+		{Name: "Camila", Contact: "camila@example.com"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.WriteFile(filepath.Join(p.ManifestPath(), "secret.txt"), []byte("the combination is 12-34-56"), 0600)
+
+	// Seal and update print their progress
+	devNull, _ := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	defer devNull.Close()
+	stdout := os.Stdout
+	os.Stdout = devNull
+	defer func() { os.Stdout = stdout }()
+
+	if _, err := sealProject(p, "", false, ""); err != nil {
+		t.Fatalf("sealing: %v", err)
+	}
+	sealed := *p.Sealed
+	shares, _ := os.ReadFile(p.SharePath(p.Friends[0]))
+
+	// Nothing changed, so nothing is written
+	metrics, err := sealUpdate(p, "", false)
+	if err != nil || metrics != nil {
+		t.Fatalf("update without changes: %v, %v", metrics, err)
+	}
+	if !p.Sealed.Updated.IsZero() {
+		t.Error("update without changes recorded an update")
+	}
+
+	os.WriteFile(filepath.Join(p.ManifestPath(), "added.txt"), []byte("a new file"), 0600)
+	if metrics, err = sealUpdate(p, "", false); err != nil || metrics == nil {
+		t.Fatalf("update: %v, %v", metrics, err)
+	}
+	if p.Sealed.ManifestChecksum == sealed.ManifestChecksum {
+		t.Error("MANIFEST.age didn't change")
+	}
+	if p.Sealed.BundleID != sealed.BundleID || p.Sealed.VerificationHash != sealed.VerificationHash || p.Sealed.Updated.IsZero() {
+		t.Errorf("the seal changed: %+v", p.Sealed)
+	}
+	if after, _ := os.ReadFile(p.SharePath(p.Friends[0])); !bytes.Equal(after, shares) {
+		t.Error("the pieces changed")
+	}
+	if len(p.Sealed.Files) != 2 || p.Sealed.Files[0].Path != "manifest/added.txt" {
+		t.Errorf("files = %+v", p.Sealed.Files)
+	}
+
+	// The pieces friends already hold open the new MANIFEST.age, and the new
+	// bundles check out
+	passphrase, err := currentOpener(p, nil, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer passphrase.Wipe()
+	f, err := os.Open(p.ManifestAgePath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if err := core.Decrypt(io.Discard, f, string(passphrase)); err != nil {
+		t.Errorf("decrypting the updated MANIFEST.age: %v", err)
+	}
+	if err := bundle.VerifyBundle(friendBundlePath(p, p.Friends[1])); err != nil {
+		t.Errorf("bundle: %v", err)
+	}
+
+	// A catalog's file list can't change without new pieces
+	p.Catalog = &project.Catalog{Threshold: 1}
+	if _, err := sealUpdate(p, "", false); err == nil || !strings.Contains(err.Error(), "CATALOG.age") {
+		t.Errorf("update with a catalog: %v", err)
+	}
+}
+
+func TestCheckSpace(t *testing.T) {
+	dir := t.TempDir()
+	missing := filepath.Join(dir, "output", "bundles")
+	if got := existingDir(missing); got != dir {
+		t.Errorf("existingDir = %s, want %s", got, dir)
+	}
+	free, _, err := diskFree(dir)
+	if err != nil {
+		t.Skipf("free space unavailable: %v", err)
+	}
+
+	if err := checkSpace(spaceNeed{dir: missing, bytes: 1 << 20, what: "bundles"}); err != nil {
+		t.Errorf("1 MB: %v", err)
+	}
+	// Needs on one filesystem add up
+	half := int64(free/2) + 1<<20
+	err = checkSpace(spaceNeed{dir: dir, bytes: half, what: "MANIFEST.age"}, spaceNeed{dir: missing, bytes: half, what: "3 bundles"})
+	if err == nil || !strings.Contains(err.Error(), "for MANIFEST.age and 3 bundles: about") {
+		t.Errorf("more than is free: got %v", err)
+	}
+}
+
+func TestEnableReproducible(t *testing.T) {
+	dir := t.TempDir()
+	manifestDir := filepath.Join(dir, "manifest")
+	os.MkdirAll(manifestDir, 0755)
+	os.WriteFile(filepath.Join(manifestDir, "secret.txt"), []byte("the combination is 12-34-56"), 0644)
+	seedPath := filepath.Join(dir, "seed.yml")
+	p := &project.Project{}
+
+	seal := func(name string) []byte {
+		restore, err := enableReproducible(p, seedPath)
+		if err != nil {
+			t.Fatalf("enableReproducible: %v", err)
+		}
+		defer restore()
+		path := filepath.Join(dir, name)
+		if _, _, err := archiveEncrypted(manifestDir, path, core.Secret("passphrase"), 10, false, "", nil); err != nil {
+			t.Fatal(err)
+		}
+		data, _ := os.ReadFile(path)
+		return data
+	}
+
+	// The first seal writes the seed file, the second reads it
+	first := seal("first.age")
+	info, err := os.Stat(seedPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if runtime.GOOS != "windows" && info.Mode().Perm() != 0600 {
+		t.Errorf("seed file mode %v, want 0600", info.Mode().Perm())
+	}
+	if !bytes.Equal(first, seal("second.age")) {
+		t.Error("MANIFEST.age differs with the same seed")
+	}
+	if core.Deterministic() {
+		t.Error("restore left deterministic mode on")
+	}
+
+	// A seed and time written by hand are used as they are
+	os.WriteFile(seedPath, []byte("seed: "+strings.Repeat("ab", core.ReproducibleSeedSize)+"\ntime: 2030-06-01T12:00:00Z\n"), 0600)
+	restore, err := enableReproducible(p, seedPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := core.Now(); !got.Equal(time.Date(2030, 6, 1, 12, 0, 0, 0, time.UTC)) {
+		t.Errorf("Now() = %v", got)
+	}
+	restore()
+
+	os.WriteFile(seedPath, []byte("seed: abcd\ntime: 2030-06-01T12:00:00Z\n"), 0600)
+	if _, err := enableReproducible(p, seedPath); err == nil {
+		t.Error("accepted a short seed")
+	}
+	for _, p := range []*project.Project{{PostQuantum: true}, {RecoveryDelay: "1h"}, {Crypto: core.CryptoRestricted}} {
+		if _, err := enableReproducible(p, seedPath); err == nil {
+			t.Errorf("accepted %+v", p)
+		}
+	}
+}
+
 func TestTruncateHash(t *testing.T) {
 	tests := []struct {
 		input    string
@@ -66,3 +363,1027 @@ func TestFriendNames(t *testing.T) {
 		}
 	}
 }
+
+func TestCompactFromURL(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+		ok       bool
+	}{
+		{"https://example.com/recover.html#share=RM2%3A1%3A3%3A2%3AAAAA%3Aabcd", "RM2:1:3:2:AAAA:abcd", true},
+		{"recover.html#share=RM2:1:3:2:AAAA:abcd", "RM2:1:3:2:AAAA:abcd", true},
+		{"RM2:1:3:2:AAAA:abcd", "", false},
+		{"https://example.com/recover.html", "", false},
+	}
+
+	for _, tt := range tests {
+		result, ok := compactFromURL(tt.input)
+		if ok != tt.ok || result != tt.expected {
+			t.Errorf("compactFromURL(%q) = %q, %v; want %q, %v", tt.input, result, ok, tt.expected, tt.ok)
+		}
+	}
+}
+
+func TestPrefillFromProject(t *testing.T) {
+	p := &project.Project{
+		Name:      "Family Vault",
+		Threshold: 2,
+		Language:  "es",
+		Friends: []project.Friend{
+			{Name: "Alice", Contact: "alice@example.com"},
+			{Name: "Bob", Language: "de"},
+			{Name: "Carol"},
+		},
+	}
+
+	prefill := prefillFromProject(p)
+	if prefill.ProjectName != "Family Vault" || prefill.Threshold != 2 || prefill.Language != "es" {
+		t.Errorf("unexpected settings: %+v", prefill)
+	}
+	if len(prefill.Friends) != 3 {
+		t.Fatalf("expected 3 friends, got %d", len(prefill.Friends))
+	}
+	if prefill.Friends[0].Contact != "alice@example.com" || prefill.Friends[1].Language != "de" {
+		t.Errorf("friend details not carried over: %+v", prefill.Friends)
+	}
+
+	p.Anonymous = true
+	prefill = prefillFromProject(p)
+	if !prefill.Anonymous || prefill.NumShares != 3 || len(prefill.Friends) != 0 {
+		t.Errorf("anonymous prefill = %+v, want 3 shares and no friends", prefill)
+	}
+}
+
+func TestReadNewPassword(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{"a long password\na long password\n", "a long password", false},
+		{"a long password\nsomething else!\n", "", true},
+		{"short\nshort\n", "", true},
+	}
+
+	for _, tt := range tests {
+		passwordInput = bufio.NewReader(strings.NewReader(tt.input))
+		got, err := readNewPassword("Password")
+		if (err != nil) != tt.wantErr {
+			t.Errorf("readNewPassword(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+		}
+		if got != tt.want {
+			t.Errorf("readNewPassword(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestAskEstate(t *testing.T) {
+	input := "Gmail\nana@example.com\nMemorialize it\n\n\n" + // one account, then on
+		"\n" + // no subscriptions
+		"iPhone\n1234 is in the safe\n" // input ends mid-device
+	doc := estate.New()
+	if err := askEstate(bufio.NewReader(strings.NewReader(input)), io.Discard, doc); err != nil {
+		t.Fatal(err)
+	}
+	if len(doc.Accounts) != 1 || doc.Accounts[0].Login != "ana@example.com" || doc.Accounts[0].Notes != "" {
+		t.Errorf("accounts = %+v", doc.Accounts)
+	}
+	if len(doc.Subscriptions) != 0 || len(doc.Devices) != 1 || doc.Devices[0].Unlock != "1234 is in the safe" {
+		t.Errorf("doc = %+v", doc)
+	}
+	if got := estateSummary(doc); got != "1 account, 1 device" {
+		t.Errorf("estateSummary = %q", got)
+	}
+}
+
+func TestCheckFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "MANIFEST.age")
+	if err := os.WriteFile(path, []byte("sealed"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	checksum := core.HashBytes([]byte("sealed"))
+
+	if c := checkFile(path, checksum); c.Status != "ok" || c.Actual != checksum {
+		t.Errorf("matching file: got %+v", c)
+	}
+	if c := checkFile(path, "sha256:other"); c.Status != "mismatch" {
+		t.Errorf("changed file: got status %q, want mismatch", c.Status)
+	}
+	if c := checkFile(filepath.Join(dir, "missing"), checksum); c.Status != "missing" {
+		t.Errorf("missing file: got status %q, want missing", c.Status)
+	}
+}
+
+func TestShareInconsistencies(t *testing.T) {
+	p := &project.Project{Threshold: 2}
+	newShares := func() []*core.Share {
+		return []*core.Share{
+			core.NewShare(2, 1, 3, 2, "Alice", []byte("a")),
+			core.NewShare(2, 2, 3, 2, "Bob", []byte("b")),
+			core.NewShare(2, 3, 3, 2, "Carol", []byte("c")),
+		}
+	}
+
+	shares := newShares()
+	if problems, mixed := shareInconsistencies(p, shares); len(problems) != 0 || mixed {
+		t.Errorf("consistent shares: got %v, mixed created %v", problems, mixed)
+	}
+
+	shares = newShares()
+	shares[2].Created = shares[0].Created.Add(time.Hour)
+	if problems, mixed := shareInconsistencies(p, shares); len(problems) != 0 || !mixed {
+		t.Errorf("added friend: got %v, mixed created %v", problems, mixed)
+	}
+
+	shares = newShares()
+	shares[1].Index = 1
+	shares[2].Threshold = 3
+	problems, _ := shareInconsistencies(p, shares)
+	if len(problems) != 2 {
+		t.Errorf("got %d problems, want 2: %v", len(problems), problems)
+	}
+}
+
+func TestCopyManifest(t *testing.T) {
+	src := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(src, "keys"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(src, "keys", "ssh.txt"), []byte("secret"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink("/etc/passwd", filepath.Join(src, "link")); err != nil {
+		t.Fatal(err)
+	}
+
+	dst := filepath.Join(t.TempDir(), "manifest")
+	if err := copyManifest(src, dst); err != nil {
+		t.Fatalf("copyManifest: %v", err)
+	}
+	got, err := os.ReadFile(filepath.Join(dst, "keys", "ssh.txt"))
+	if err != nil || string(got) != "secret" {
+		t.Errorf("copied file = %q, %v", got, err)
+	}
+	if _, err := os.Lstat(filepath.Join(dst, "link")); !os.IsNotExist(err) {
+		t.Errorf("symlink was copied")
+	}
+}
+
+func TestServeHandler(t *testing.T) {
+	manifestPath := filepath.Join(t.TempDir(), "MANIFEST.age")
+	if err := os.WriteFile(manifestPath, []byte("age-encryption.org/v1"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	pages := map[string]string{"/recover.html": "<html>recover</html>"}
+
+	get := func(h http.Handler, path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest("GET", path, nil))
+		return rec
+	}
+
+	h := serveHandler(pages, manifestPath)
+	if rec := get(h, "/recover.html"); rec.Code != 200 || rec.Body.String() != pages["/recover.html"] {
+		t.Errorf("recover.html: %d %q", rec.Code, rec.Body.String())
+	}
+	if rec := get(h, "/MANIFEST.age"); rec.Code != 200 || rec.Body.String() != "age-encryption.org/v1" {
+		t.Errorf("MANIFEST.age: %d %q", rec.Code, rec.Body.String())
+	}
+	if rec := get(h, "/project.yml"); rec.Code != 404 {
+		t.Errorf("project.yml: got %d, want 404", rec.Code)
+	}
+	if rec := get(serveHandler(pages, ""), "/MANIFEST.age"); rec.Code != 404 {
+		t.Errorf("MANIFEST.age without a project: got %d, want 404", rec.Code)
+	}
+}
+
+func TestBundlePDF(t *testing.T) {
+	bundlePath := filepath.Join(t.TempDir(), "bundle-camila.zip")
+	err := bundle.CreateZip(bundlePath, []bundle.ZipFile{
+		{Name: "LEEME.txt", Content: []byte("texto"), ModTime: time.Now()},
+		{Name: "LEEME.pdf", Content: []byte("%PDF-1.3"), ModTime: time.Now()},
+		{Name: "recover.html", Content: []byte("<html>"), ModTime: time.Now()},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	name, data, err := bundlePDF(bundlePath)
+	if err != nil {
+		t.Fatalf("bundlePDF: %v", err)
+	}
+	if name != "LEEME.pdf" || string(data) != "%PDF-1.3" {
+		t.Errorf("got %s %q", name, data)
+	}
+}
+
+func TestSelectFriends(t *testing.T) {
+	p := &project.Project{Friends: []project.Friend{{Name: "Alice"}, {Name: "Bob"}, {Name: "Camila"}}}
+
+	all, err := selectFriends(p, nil)
+	if err != nil || len(all) != 3 {
+		t.Errorf("no names: got %v, %v", all, err)
+	}
+	some, err := selectFriends(p, []string{"alice", " CAMILA "})
+	if err != nil || len(some) != 2 || !some["Alice"] || !some["Camila"] {
+		t.Errorf("alice, camila: got %v, %v", some, err)
+	}
+	if _, err := selectFriends(p, []string{"David"}); err == nil {
+		t.Error("expected an error for an unknown friend")
+	}
+}
+
+func TestQRFormat(t *testing.T) {
+	tests := []struct {
+		format, output string
+		want           string
+		wantErr        bool
+	}{
+		{"", "", "terminal", false},
+		{"", "alice.PNG", "png", false},
+		{"", "alice.svg", "svg", false},
+		{"svg", "alice.out", "svg", false},
+		{"", "alice.jpg", "", true},
+		{"png", "", "", true},
+		{"pdf", "alice.pdf", "", true},
+	}
+	for _, tt := range tests {
+		got, err := qrFormat(tt.format, tt.output)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("qrFormat(%q, %q) = %q, %v; want %q", tt.format, tt.output, got, err, tt.want)
+		}
+	}
+}
+
+func TestWriteQRSVG(t *testing.T) {
+	bitmap := [][]bool{
+		{true, true, false},
+		{false, false, false},
+		{false, true, true},
+	}
+	var b strings.Builder
+	if err := writeQRSVG(&b, bitmap, 300); err != nil {
+		t.Fatal(err)
+	}
+	svg := b.String()
+	for _, want := range []string{`width="300"`, `viewBox="0 0 3 3"`, `d="M0 0h2v1h-2zM1 2h2v1h-2z"`} {
+		if !strings.Contains(svg, want) {
+			t.Errorf("SVG missing %s:\n%s", want, svg)
+		}
+	}
+}
+
+func TestMatchPrint(t *testing.T) {
+	shares := []*core.Share{
+		core.NewShare(2, 1, 3, 2, "Alice", []byte("a")),
+		core.NewShare(2, 3, 3, 2, "Carol", []byte("c")),
+	}
+	url := "https://example.com/recover.html#share=" + shares[1].CompactEncode()
+
+	if i, err := matchPrint(url, shares); err != nil || i != 1 {
+		t.Errorf("recovery URL: got %d, %v", i, err)
+	}
+	if i, err := matchPrint(shares[0].CompactEncode(), shares); err != nil || i != 0 {
+		t.Errorf("bare compact share: got %d, %v", i, err)
+	}
+
+	other := core.NewShare(2, 1, 3, 2, "Alice", []byte("z")).CompactEncode()
+	damaged := shares[0].CompactEncode()
+	damaged = damaged[:len(damaged)-1] + "x"
+	missing := core.NewShare(2, 2, 3, 2, "Bob", []byte("b")).CompactEncode()
+	for name, payload := range map[string]string{
+		"other seal": other, "damaged": damaged, "not in project": missing, "not a share": "https://example.com",
+	} {
+		if _, err := matchPrint(payload, shares); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestCopyBundleToDrive(t *testing.T) {
+	p, err := project.New(filepath.Join(t.TempDir(), "project"), "Drive", 2, []project.Friend{
+		{Name: "Alice"},
+		{Name: "Bob", Language: "es"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.WriteFile(filepath.Join(p.ManifestPath(), "secret.txt"), []byte("the combination is 12-34-56"), 0600)
+
+	devNull, _ := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	defer devNull.Close()
+	stdout := os.Stdout
+	os.Stdout = devNull
+	defer func() { os.Stdout = stdout }()
+	if _, err := sealProject(p, "", false, ""); err != nil {
+		t.Fatalf("sealing: %v", err)
+	}
+
+	drive := t.TempDir()
+	bundlePath := friendBundlePath(p, p.Friends[1])
+	written, err := copyBundleToDrive(p, 1, bundlePath, drive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"recover.html", "LEEME.pdf", filepath.Base(bundlePath), bundle.DriveIndexFilename} {
+		if _, err := os.Stat(filepath.Join(drive, name)); err != nil {
+			t.Errorf("%s isn't on the drive: %v", name, err)
+		}
+	}
+	index, _ := os.ReadFile(filepath.Join(drive, bundle.DriveIndexFilename))
+	for _, want := range []string{`lang="es"`, `href="recover.html"`, `href="LEEME.pdf"`, "Bob"} {
+		if !strings.Contains(string(index), want) {
+			t.Errorf("index.html is missing %q", want)
+		}
+	}
+	if strings.Contains(string(index), "<script") {
+		t.Error("index.html has a script")
+	}
+
+	if err := readBack(written); err != nil {
+		t.Errorf("read-back: %v", err)
+	}
+	os.WriteFile(filepath.Join(drive, "recover.html"), []byte("damaged"), 0644)
+	if err := readBack(written); err == nil || !strings.Contains(err.Error(), "recover.html") {
+		t.Errorf("read-back of a damaged file: %v", err)
+	}
+}
+
+func TestReadBackCopy(t *testing.T) {
+	p, err := project.New(filepath.Join(t.TempDir(), "project"), "Media", 2, []project.Friend{
+		{Name: "Alice"},
+		{Name: "Bob"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.WriteFile(filepath.Join(p.ManifestPath(), "secret.txt"), []byte("the combination is 12-34-56"), 0600)
+
+	devNull, _ := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	defer devNull.Close()
+	stdout := os.Stdout
+	os.Stdout = devNull
+	defer func() { os.Stdout = stdout }()
+	if _, err := sealProject(p, "", false, ""); err != nil {
+		t.Fatalf("sealing: %v", err)
+	}
+
+	// Bob's bundle ZIP and its files, as 'usb prepare' leaves them
+	drive := t.TempDir()
+	bundlePath := friendBundlePath(p, p.Friends[1])
+	if _, err := copyBundleToDrive(p, 1, bundlePath, drive); err != nil {
+		t.Fatal(err)
+	}
+	copies, err := findMediumCopies(p, drive, "")
+	if err != nil || len(copies) != 1 || copies[0].friend != 1 || copies[0].zip != filepath.Base(bundlePath) {
+		t.Fatalf("copies: %+v, %v", copies, err)
+	}
+	if _, err := findMediumCopies(p, t.TempDir(), ""); err == nil {
+		t.Error("found a copy on an empty drive")
+	}
+
+	manifest, files := readBackCopy(drive, copies[0].zip, bundlePath)
+	if manifest != p.Sealed.ManifestChecksum {
+		t.Errorf("manifest = %s, want %s", manifest, p.Sealed.ManifestChecksum)
+	}
+	names := map[string]bool{}
+	for _, f := range files {
+		names[f.name] = true
+		if f.problem != "" {
+			t.Errorf("%s: %s", f.name, f.problem)
+		}
+	}
+	if !names["recover.html"] || !names[filepath.Base(bundlePath)] || names[bundle.DriveIndexFilename] {
+		t.Errorf("checked %v", names)
+	}
+
+	// A file that reads back differently from the ZIP fails the copy
+	os.WriteFile(filepath.Join(drive, "recover.html"), []byte("damaged"), 0644)
+	_, files = readBackCopy(drive, copies[0].zip, bundlePath)
+	damaged := false
+	for _, f := range files {
+		damaged = damaged || (f.name == "recover.html" && f.problem != "")
+	}
+	if !damaged {
+		t.Error("damaged recover.html read back as it should")
+	}
+
+	// Reading the same drive back again replaces its record
+	f := &p.Friends[1]
+	f.RecordMedium(project.Medium{Serial: "ABCD-1234", Manifest: manifest})
+	f.RecordMedium(project.Medium{Serial: "ABCD-1234", Manifest: manifest, Failed: "recover.html: damaged"})
+	f.RecordMedium(project.Medium{Serial: "DISC-2", Manifest: "sha256:earlier"})
+	if len(f.Media) != 2 || f.Media[0].Failed == "" {
+		t.Errorf("media: %+v", f.Media)
+	}
+	media := statusMedia(p, *f)
+	if len(media) != 2 || !media[0].Current || media[1].Current {
+		t.Errorf("status: %+v", media)
+	}
+}
+
+func TestSealVolumes(t *testing.T) {
+	p, err := project.New(filepath.Join(t.TempDir(), "project"), "Volumes", 2, []project.Friend{
+		{Name: "Alice"},
+		{Name: "Bob"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Random bytes don't compress, so MANIFEST.age comes out over 1M
+	big := make([]byte, 1_500_000)
+	rand.Read(big)
+	os.WriteFile(filepath.Join(p.ManifestPath(), "photos.bin"), big, 0600)
+	p.VolumeSize = "1M"
+
+	devNull, _ := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	defer devNull.Close()
+	stdout := os.Stdout
+	os.Stdout = devNull
+	defer func() { os.Stdout = stdout }()
+	if _, err := sealProject(p, "", true, ""); err != nil {
+		t.Fatalf("sealing: %v", err)
+	}
+
+	index, err := p.ManifestVolumes()
+	if err != nil || index == nil || len(index.Volumes) != 2 {
+		t.Fatalf("volumes: %+v, %v", index, err)
+	}
+	if index.Checksum != p.Sealed.ManifestChecksum {
+		t.Errorf("the index is for %s, not the sealed MANIFEST.age", index.Checksum)
+	}
+
+	// The bundle carries the volumes and their index in MANIFEST.age's place
+	bundlePath := friendBundlePath(p, p.Friends[0])
+	r, err := zip.OpenReader(bundlePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, f := range r.File {
+		names = append(names, f.Name)
+	}
+	r.Close()
+	got := strings.Join(names, " ")
+	for _, want := range []string{"MANIFEST.age.001", "MANIFEST.age.002", "MANIFEST.age.sha256"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("the bundle is missing %s: %s", want, got)
+		}
+	}
+	if slices.Contains(names, "MANIFEST.age") {
+		t.Error("the bundle has the whole MANIFEST.age too")
+	}
+	if err := bundle.VerifyBundle(bundlePath); err != nil {
+		t.Errorf("bundle: %v", err)
+	}
+
+	// Recovery puts the volumes back together
+	manifest, _ := os.ReadFile(p.ManifestAgePath())
+	joined, n, err := recovery.ReadVolumes(p.ManifestVolumeIndexPath())
+	if err != nil || n != 2 || !bytes.Equal(joined, manifest) {
+		t.Errorf("joining: %d volumes, %v", n, err)
+	}
+
+	// Without volume_size, the volumes go and the bundle has MANIFEST.age again
+	p.VolumeSize = ""
+	if _, err := bundle.Regenerate(p, bundle.Config{NoEmbedManifest: true}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(p.ManifestVolumeIndexPath()); !os.IsNotExist(err) {
+		t.Errorf("the volume index is still there: %v", err)
+	}
+	if err := bundle.VerifyBundle(bundlePath); err != nil {
+		t.Errorf("bundle without volumes: %v", err)
+	}
+}
+
+func TestSealParity(t *testing.T) {
+	p, err := project.New(filepath.Join(t.TempDir(), "project"), "Parity", 2, []project.Friend{
+		{Name: "Alice"},
+		{Name: "Bob"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	big := make([]byte, 200_000)
+	rand.Read(big)
+	os.WriteFile(filepath.Join(p.ManifestPath(), "photos.bin"), big, 0600)
+	p.Parity = 10
+
+	devNull, _ := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	defer devNull.Close()
+	stdout := os.Stdout
+	os.Stdout = devNull
+	defer func() { os.Stdout = stdout }()
+	if _, err := sealProject(p, "", true, ""); err != nil {
+		t.Fatalf("sealing: %v", err)
+	}
+	parity, err := os.ReadFile(p.ManifestParityPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	bundlePath := friendBundlePath(p, p.Friends[0])
+	if err := bundle.VerifyBundle(bundlePath); err != nil {
+		t.Errorf("bundle: %v", err)
+	}
+	info, err := bundle.ReadInfo(bundlePath)
+	if err != nil || info.Parity == nil || info.Parity.Checksum != p.Sealed.ManifestChecksum {
+		t.Errorf("the bundle's parity: %+v, %v", info, err)
+	}
+
+	// Recovery repairs a MANIFEST.age that decayed on a drive
+	manifest, _ := os.ReadFile(p.ManifestAgePath())
+	drive := t.TempDir()
+	damaged := append([]byte{}, manifest...)
+	for i := 10_000; i < 12_000; i++ {
+		damaged[i] ^= 0x55
+	}
+	os.WriteFile(filepath.Join(drive, "MANIFEST.age"), damaged, 0644)
+	os.WriteFile(filepath.Join(drive, "MANIFEST.age"+core.ParitySuffix), parity, 0644)
+	got, repaired, err := recovery.ReadManifest(filepath.Join(drive, "MANIFEST.age"))
+	if err != nil || repaired == 0 || !bytes.Equal(got, manifest) {
+		t.Errorf("repairing: %d blocks, %v", repaired, err)
+	}
+
+	// Without parity in project.yml, the parity file goes
+	p.Parity = 0
+	if _, err := bundle.Regenerate(p, bundle.Config{NoEmbedManifest: true}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(p.ManifestParityPath()); !os.IsNotExist(err) {
+		t.Errorf("the parity is still there: %v", err)
+	}
+}
+
+func TestSealMasterCode(t *testing.T) {
+	p, err := project.New(filepath.Join(t.TempDir(), "project"), "Master", 2, []project.Friend{
+		{Name: "Alice"},
+		{Name: "Bob"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.WriteFile(filepath.Join(p.ManifestPath(), "notes.txt"), []byte("notes"), 0600)
+	code, err := makeMasterCode(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	devNull, _ := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	defer devNull.Close()
+	stdout := os.Stdout
+	os.Stdout = devNull
+	defer func() { os.Stdout = stdout }()
+	if _, err := sealProject(p, "", true, ""); err != nil {
+		t.Fatalf("sealing: %v", err)
+	}
+	if err := writeMasterCodeSheet(p, code); err != nil {
+		t.Fatal(err)
+	}
+	if sheet, err := os.ReadFile(p.MasterCodePath()); err != nil || !bytes.HasPrefix(sheet, []byte("%PDF")) {
+		t.Errorf("the sheet: %v", err)
+	}
+
+	// The words unlock the passphrase, and keep doing so after a new seal
+	recipients := filepath.Join(p.OutputPath(), core.RecipientsFile)
+	unlock := func(seal string) {
+		t.Helper()
+		passwordInput = bufio.NewReader(strings.NewReader(strings.Join(core.MasterCodeWords(code), " ") + "\n"))
+		passphrase, err := unlockMasterCode(recipients)
+		if err != nil {
+			t.Fatalf("%s seal: %v", seal, err)
+		}
+		if !core.VerifyHash(core.HashBytes(passphrase), p.Sealed.VerificationHash) {
+			t.Errorf("%s seal: the master code gave the wrong passphrase", seal)
+		}
+	}
+	unlock("first")
+	if _, err := sealProject(p, "", true, ""); err != nil {
+		t.Fatalf("sealing again: %v", err)
+	}
+	unlock("second")
+
+	other, _ := core.GenerateMasterCode()
+	passwordInput = bufio.NewReader(strings.NewReader(core.MasterCodeKey(other, false) + "\n"))
+	if _, err := unlockMasterCode(recipients); err == nil {
+		t.Error("another master code unlocked the passphrase")
+	}
+}
+
+func TestFriendAddRemove(t *testing.T) {
+	p, err := project.New(filepath.Join(t.TempDir(), "project"), "Friends", 3, []project.Friend{
+		{Name: "Alice"},
+		{Name: "Bob"},
+		{Name: "Camila"},
+		{Name: "Dmitri"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.WriteFile(filepath.Join(p.ManifestPath(), "secret.txt"), []byte("the real one"), 0600)
+	p.Catalog = &project.Catalog{Threshold: 2}
+	p.SLIP39 = true
+	p.SSKR = true
+
+	devNull, _ := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	defer devNull.Close()
+	stdout := os.Stdout
+	os.Stdout = devNull
+	defer func() { os.Stdout = stdout }()
+
+	if _, err := sealProject(p, "", true, ""); err != nil {
+		t.Fatal(err)
+	}
+	manifestAge, _ := os.ReadFile(p.ManifestAgePath())
+	t.Chdir(p.Path)
+	load := func() (*project.Project, []*core.Share) {
+		t.Helper()
+		p, err := project.Load(p.Path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		shares, err := loadSealedShares(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := verifySealedShares(p, shares); err != nil {
+			t.Fatal(err)
+		}
+		return p, shares
+	}
+	indexes := func(shares []*core.Share) []int {
+		var indexes []int
+		for _, s := range shares {
+			indexes = append(indexes, s.Index)
+		}
+		return indexes
+	}
+
+	// Adding keeps everyone's piece
+	_, before := load()
+	if err := runFriendAdd(friendAddCmd, []string{"Eva"}); err != nil {
+		t.Fatal(err)
+	}
+	p, shares := load()
+	if got := indexes(shares); !slices.Equal(got, []int{1, 2, 3, 4, 5}) {
+		t.Errorf("indexes after adding Eva = %v", got)
+	}
+	for i, s := range before {
+		if !bytes.Equal(shares[i].Data, s.Data) {
+			t.Errorf("%s's piece changed when adding Eva", p.Friends[i].Name)
+		}
+	}
+
+	// Removing splits the passphrase again, so Bob's piece is no use
+	bob := before[1]
+	if err := runFriendRemove(friendRemoveCmd, []string{"Bob"}); err != nil {
+		t.Fatal(err)
+	}
+	p, shares = load()
+	if got := indexes(shares); !slices.Equal(got, []int{1, 3, 4, 5}) {
+		t.Errorf("indexes after removing Bob = %v", got)
+	}
+	if shares[0].Total != 4 || bytes.Equal(shares[0].Data, before[0].Data) {
+		t.Errorf("Alice's piece wasn't split again: total %d", shares[0].Total)
+	}
+	if err := verifySealedShares(p, []*core.Share{bob, shares[0], shares[1]}); err == nil {
+		t.Error("Bob's piece still combines with the new ones")
+	}
+	if _, err := os.Stat(p.SharePath(project.Friend{Name: "Bob"})); !os.IsNotExist(err) {
+		t.Errorf("Bob's share file is still there: %v", err)
+	}
+	if data, _ := os.ReadFile(p.ManifestAgePath()); !bytes.Equal(data, manifestAge) {
+		t.Error("MANIFEST.age changed")
+	}
+	catalog, _ := os.ReadFile(p.CatalogPath())
+	key, err := core.CombineCatalogKey(shares[2:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := core.DecryptCatalog(catalog, key); err != nil {
+		t.Errorf("opening the catalog with the new pieces: %v", err)
+	}
+
+	// Bob's index isn't handed out again
+	if err := runFriendAdd(friendAddCmd, []string{"Fede"}); err != nil {
+		t.Fatal(err)
+	}
+	p, shares = load()
+	if got := indexes(shares); !slices.Equal(got, []int{1, 3, 4, 5, 6}) {
+		t.Errorf("indexes after adding Fede = %v", got)
+	}
+	if p.Sealed.LastIndex != 6 {
+		t.Errorf("last index = %d, want 6", p.Sealed.LastIndex)
+	}
+	var words []string
+	for _, f := range p.Friends {
+		data, err := os.ReadFile(p.SLIP39Path(f))
+		if err != nil {
+			t.Fatal(err)
+		}
+		words = append(words, strings.TrimSpace(string(data)))
+	}
+	if _, err := core.SLIP39Combine(words); err != nil {
+		t.Errorf("everyone's SLIP-0039 words: %v", err)
+	}
+	var sskr []*core.SSKRShare
+	for _, f := range p.Friends {
+		data, err := os.ReadFile(p.SSKRPath(f))
+		if err != nil {
+			t.Fatal(err)
+		}
+		share, err := core.ParseSSKR(string(data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		sskr = append(sskr, share)
+	}
+	if last := sskr[len(sskr)-1]; last.MemberIndex != 5 {
+		t.Errorf("Fede's SSKR member index = %d, want 5", last.MemberIndex)
+	}
+	if _, err := core.SSKRCombine(sskr); err != nil {
+		t.Errorf("everyone's SSKR shares: %v", err)
+	}
+	opener, err := currentOpener(p, nil, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer opener.Wipe()
+	if err := recovery.Decrypt(io.Discard, manifestAge, nil, opener); err != nil {
+		t.Errorf("recovering with the new pieces: %v", err)
+	}
+}
+
+func TestSealRestricted(t *testing.T) {
+	p, err := project.New(filepath.Join(t.TempDir(), "project"), "Restricted", 2, []project.Friend{
+		{Name: "Alice"},
+		{Name: "Bob"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.WriteFile(filepath.Join(p.ManifestPath(), "notes.txt"), []byte("notes"), 0600)
+	p.Crypto = core.CryptoRestricted
+
+	devNull, _ := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	defer devNull.Close()
+	stdout := os.Stdout
+	os.Stdout = devNull
+	defer func() { os.Stdout = stdout }()
+
+	if _, err := sealProject(p, "", true, ""); err != nil {
+		t.Fatal(err)
+	}
+	if len(p.Sealed.VSS) > 0 {
+		t.Errorf("restricted seal recorded VSS commitments: %v", p.Sealed.VSS)
+	}
+	shares, err := loadSealedShares(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range shares {
+		if len(s.VSS) > 0 {
+			t.Errorf("piece %d carries a VSS value", s.Index)
+		}
+		if s.MAC != "" {
+			t.Errorf("piece %d carries a MAC", s.Index)
+		}
+	}
+	opener, err := currentOpener(p, nil, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	opener.Wipe()
+}
+
+func TestUndoSeal(t *testing.T) {
+	p, err := project.New(filepath.Join(t.TempDir(), "project"), "Undo", 3, []project.Friend{
+		{Name: "Alice"},
+		{Name: "Bob"},
+		{Name: "Camila"},
+		{Name: "Dmitri"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.WriteFile(filepath.Join(p.ManifestPath(), "secret.txt"), []byte("the real one"), 0600)
+	os.MkdirAll(p.DecoyManifestPath(), 0755)
+	os.WriteFile(filepath.Join(p.DecoyManifestPath(), "notes.txt"), []byte("the decoy"), 0600)
+	p.RecoveryDelay = "1s"
+	p.Catalog = &project.Catalog{Threshold: 2}
+	p.Decoy = &project.Decoy{}
+
+	devNull, _ := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	defer devNull.Close()
+	stdout := os.Stdout
+	os.Stdout = devNull
+	defer func() { os.Stdout = stdout }()
+
+	if _, err := sealProject(p, "", true, ""); err != nil {
+		t.Fatalf("first seal: %v", err)
+	}
+	sealed := map[string][]byte{}
+	for _, path := range []string{p.ManifestAgePath(), p.TimelockPath(), p.CatalogPath(), p.DecoyPath(), p.SharePath(p.Friends[0]), p.DuressSharePath(p.Friends[0])} {
+		if sealed[path], err = os.ReadFile(path); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Seal again, then undo it as 'rememory undo --yes' does
+	if err := snapshot(p, "seal"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sealProject(p, "", true, ""); err != nil {
+		t.Fatalf("second seal: %v", err)
+	}
+	backups, _ := p.ListBackups()
+	made, err := p.Artifacts(backups[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Restore(backups[0]); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.RemoveArtifacts(made); err != nil {
+		t.Fatal(err)
+	}
+
+	// Every file of the first seal is back, none deleted as made
+	for path, want := range sealed {
+		if got, err := os.ReadFile(path); err != nil || !bytes.Equal(got, want) {
+			t.Errorf("%s after undo: %v", filepath.Base(path), err)
+		}
+	}
+	p, err = project.Load(p.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	opener, err := currentOpener(p, nil, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer opener.Wipe()
+	if err := recovery.Decrypt(io.Discard, sealed[p.ManifestAgePath()], nil, opener); err != nil {
+		t.Errorf("recovering after undo: %v", err)
+	}
+}
+
+func TestMissingTimelock(t *testing.T) {
+	p, err := project.New(filepath.Join(t.TempDir(), "project"), "Delay", 2, []project.Friend{
+		{Name: "Alice"},
+		{Name: "Bob"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.WriteFile(filepath.Join(p.ManifestPath(), "notes.txt"), []byte("notes"), 0600)
+	p.RecoveryDelay = "1s"
+
+	devNull, _ := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	defer devNull.Close()
+	stdout := os.Stdout
+	os.Stdout = devNull
+	defer func() { os.Stdout = stdout }()
+
+	if _, err := sealProject(p, "", true, ""); err != nil {
+		t.Fatal(err)
+	}
+	if p.Sealed.TimelockChecksum == "" {
+		t.Fatal("seal didn't record TIMELOCK.json")
+	}
+	for _, c := range sealedFileChecks(p) {
+		if c.Status != "ok" {
+			t.Errorf("%s: %s", filepath.Base(c.Path), c.Status)
+		}
+	}
+
+	if err := os.Remove(p.TimelockPath()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := p.ReadTimelock(); err == nil {
+		t.Error("ReadTimelock didn't notice TIMELOCK.json is gone")
+	}
+	if _, err := bundle.Regenerate(p, bundle.Config{NoEmbedManifest: true}); err == nil {
+		t.Error("bundles were made without the time-lock puzzle")
+	}
+	missing := false
+	for _, c := range sealedFileChecks(p) {
+		missing = missing || filepath.Base(c.Path) == core.TimelockFile && c.Status == "missing"
+	}
+	if !missing {
+		t.Error("doctor and verify don't report TIMELOCK.json missing")
+	}
+}
+
+func TestSealMaxMemory(t *testing.T) {
+	p, err := project.New(filepath.Join(t.TempDir(), "project"), "Memory", 2, []project.Friend{
+		{Name: "Alice"},
+		{Name: "Bob"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.WriteFile(filepath.Join(p.ManifestPath(), "notes.txt"), []byte("notes"), 0600)
+	p.MaxMemory = "128M"
+
+	devNull, _ := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	defer devNull.Close()
+	stdout := os.Stdout
+	os.Stdout = devNull
+	defer func() { os.Stdout = stdout }()
+	if _, err := sealProject(p, "", true, ""); err != nil {
+		t.Fatalf("sealing: %v", err)
+	}
+
+	// MANIFEST.age, project.yml, and the pieces all say what it needs
+	manifest, _ := os.ReadFile(p.ManifestAgePath())
+	info, err := core.InspectManifest(bytes.NewReader(manifest))
+	if err != nil || info.WorkFactor != 17 {
+		t.Errorf("MANIFEST.age: %+v, %v", info, err)
+	}
+	if p.Sealed.WorkFactor != 17 {
+		t.Errorf("sealed work factor = %d, want 17", p.Sealed.WorkFactor)
+	}
+	piece, _ := os.ReadFile(filepath.Join(p.SharesPath(), "SHARE-alice.txt"))
+	if !bytes.Contains(piece, []byte("KDF: scrypt N=2^17 ")) {
+		t.Errorf("the piece doesn't record the work factor:\n%s", piece)
+	}
+
+	// At or above scrypt's default, nothing changes
+	p.MaxMemory = "1G"
+	if wf, err := tuneWorkFactor(p); err != nil || wf != 0 {
+		t.Errorf("max_memory 1G: work factor %d, %v", wf, err)
+	}
+	// and a recovery_time budget stays within it
+	p.MaxMemory = "64M"
+	p.RecoveryTime = &project.RecoveryTime{Budget: "1h", Device: "laptop"}
+	if wf, err := tuneWorkFactor(p); err != nil || wf != core.MinWorkFactor {
+		t.Errorf("max_memory 64M with a budget: work factor %d, %v", wf, err)
+	}
+}
+
+func TestFindScans(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"b.JPG", "a.png", "notes.txt", "sub/c.tiff"} {
+		path := filepath.Join(dir, name)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		os.WriteFile(path, nil, 0644)
+	}
+
+	scans, err := findScans(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"a.png", "b.JPG", filepath.Join("sub", "c.tiff")}
+	if len(scans) != len(want) {
+		t.Fatalf("got %v, want %v", scans, want)
+	}
+	for i := range want {
+		if scans[i] != filepath.Join(dir, want[i]) {
+			t.Errorf("scan %d: got %s, want %s", i, scans[i], want[i])
+		}
+	}
+}
+
+func TestSaveScannedShare(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "pieces")
+	share, err := shareFromQR("https://example.com/recover.html#share=" + core.NewShare(2, 2, 3, 2, "Bob", []byte("b")).CompactEncode())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path, err := saveScannedShare(dir, share)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filepath.Base(path) != "SHARE-2.txt" {
+		t.Errorf("got %s, want SHARE-2.txt", path)
+	}
+	data, _ := os.ReadFile(path)
+	parsed, err := core.ParseShare(data)
+	if err != nil || parsed.Verify() != nil || parsed.Index != 2 || string(parsed.Data) != "b" {
+		t.Errorf("saved share doesn't round-trip: %+v, %v", parsed, err)
+	}
+
+	if _, err := saveScannedShare(dir, share); err == nil {
+		t.Error("expected an error when the file already exists")
+	}
+}
+
+func TestRecordAnswer(t *testing.T) {
+	sent := time.Date(2026, 1, 10, 0, 0, 0, 0, time.UTC)
+	answered := sent.Add(48 * time.Hour)
+
+	f := project.Friend{Name: "Alice", CheckIns: []project.CheckIn{{Sent: sent}}}
+	recordAnswer(&f, "still has it", answered)
+	if len(f.CheckIns) != 1 || f.CheckIns[0].Response != "still has it" || !f.CheckIns[0].Answered.Equal(answered) {
+		t.Errorf("answer to a reminder: got %+v", f.CheckIns)
+	}
+
+	// A second answer, or one without a reminder, gets its own entry
+	recordAnswer(&f, "moved it to the safe", answered.Add(time.Hour))
+	if len(f.CheckIns) != 2 || !f.CheckIns[1].Sent.IsZero() || f.CheckIns[1].Response != "moved it to the safe" {
+		t.Errorf("answer without a reminder: got %+v", f.CheckIns)
+	}
+}
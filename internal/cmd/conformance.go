@@ -0,0 +1,208 @@
+package cmd
+
+import (
+	"crypto/ed25519"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+
+	"github.com/eljojo/rememory/internal/bundle"
+	"github.com/eljojo/rememory/internal/conformance"
+	"github.com/eljojo/rememory/internal/core"
+	"github.com/eljojo/rememory/internal/crypto"
+	"github.com/eljojo/rememory/internal/html"
+	"github.com/eljojo/rememory/internal/project"
+	"github.com/eljojo/rememory/internal/recovery"
+	"github.com/spf13/cobra"
+)
+
+var conformanceCmd = &cobra.Command{
+	Use:   "conformance",
+	Short: "Check that this build behaves like the official releases",
+	Long: `Conformance runs the whole pipeline in a temporary folder and prints a
+signed report of what passed. It:
+
+  - opens bundles sealed by earlier releases, built into rememory, with every
+    combination of pieces, and compares the files with what was sealed
+  - generates the known-answer test vectors, checks every value, and makes
+    sure the malformed inputs among them are refused
+  - seals a small project, makes and verifies its bundles, and recovers the
+    files from every combination of bundles
+
+It is meant for distribution packagers and anyone who builds rememory from
+source: a build that passes reads and writes bundles the same way as the
+official releases. Nothing outside the temporary folder is touched.
+
+The report is signed with your SSH key when --sign-key names one (Ed25519
+only), so others can check it against the key you publish. Without it, the
+report is signed with a key made for it alone, which shows it wasn't
+changed but not who made it. --check verifies a report.
+
+Examples:
+  rememory conformance
+  rememory conformance --sign-key ~/.ssh/id_ed25519 -o conformance.txt
+  rememory conformance --check conformance.txt`,
+	Args: cobra.NoArgs,
+	RunE: runConformance,
+}
+
+func init() {
+	conformanceCmd.Flags().StringP("output", "o", "", "Write the report to this file instead of stdout")
+	conformanceCmd.Flags().String("sign-key", "", "Sign the report with this Ed25519 SSH private key")
+	conformanceCmd.Flags().String("check", "", "Verify the signature of a report instead of running the checks")
+	rootCmd.AddCommand(conformanceCmd)
+}
+
+func runConformance(cmd *cobra.Command, args []string) error {
+	output, _ := cmd.Flags().GetString("output")
+	keyPath, _ := cmd.Flags().GetString("sign-key")
+	checkPath, _ := cmd.Flags().GetString("check")
+	cmd.SilenceUsage = true // Failures from here on aren't about how it was called
+
+	if checkPath != "" {
+		return checkConformanceReport(checkPath)
+	}
+
+	var key ed25519.PrivateKey
+	if keyPath != "" {
+		var err error
+		if key, err = conformance.LoadKey(keyPath, func() (string, error) { return readPassword("Key passphrase") }); err != nil {
+			return err
+		}
+	}
+
+	fmt.Fprintln(os.Stderr, "Running conformance checks...")
+	report := &conformance.Report{
+		Version:     version,
+		Platform:    fmt.Sprintf("%s/%s (%s)", runtime.GOOS, runtime.GOARCH, runtime.Version()),
+		RecoverWASM: core.HashBytes(html.GetRecoverWASMBytes()),
+		Made:        core.Now(),
+	}
+	if exe, err := os.Executable(); err == nil {
+		if sum, err := crypto.HashFile(exe); err == nil {
+			report.Binary = sum
+		}
+	}
+	report.Checks = append(report.Checks, conformance.CheckFixtures()...)
+	report.Checks = append(report.Checks, conformance.CheckVectors("rememory "+version))
+	report.Checks = append(report.Checks, checkSealAndRecover())
+
+	text, err := report.Sign(key)
+	if err != nil {
+		return fmt.Errorf("signing report: %w", err)
+	}
+	if output == "" {
+		fmt.Print(text)
+	} else {
+		if err := os.WriteFile(output, []byte(text), 0644); err != nil {
+			return fmt.Errorf("writing %s: %w", output, err)
+		}
+		fmt.Printf("Wrote %s\n", output)
+	}
+
+	if !report.Passed() {
+		return fmt.Errorf("this build doesn't behave like the official releases; see the failed checks above")
+	}
+	return nil
+}
+
+func checkConformanceReport(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("reading %s: %w", path, err)
+	}
+	fingerprint, passed, err := conformance.Verify(string(data))
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Signature:  %s (key %s)\n", green("OK"), fingerprint)
+	if !passed {
+		fmt.Printf("Result:     %s — the report records failed checks\n", red("FAIL"))
+		return fmt.Errorf("the report records failed checks")
+	}
+	fmt.Printf("Result:     %s\n", green("PASS"))
+	fmt.Println("Compare the key with the one the report's author publishes to know who made it.")
+	return nil
+}
+
+// checkSealAndRecover seals a small project in a temporary folder the way
+// 'rememory seal' does, then recovers its files from the bundles.
+func checkSealAndRecover() conformance.Check {
+	c := conformance.Check{Name: "seals, bundles, and recovers a project", Detail: "3 friends, 2 needed"}
+	fail := func(err error) conformance.Check {
+		c.Err = err
+		return c
+	}
+
+	tmp, err := os.MkdirTemp("", "rememory-conformance-")
+	if err != nil {
+		return fail(err)
+	}
+	defer os.RemoveAll(tmp)
+
+	friends := []project.Friend{
+		{Name: "Alice", Contact: "alice@example.com"},
+		{Name: "Bob", Contact: "bob@example.com"},
+		{Name: "Camila", Contact: "camila@example.com", Language: "es"},
+	}
+	p, err := project.New(filepath.Join(tmp, "project"), "Conformance", 2, friends)
+	if err != nil {
+		return fail(err)
+	}
+	files := map[string]string{
+		"secret.txt":          "The secret password is: correct-horse-battery-staple\n",
+		"notes/año-nuevo.txt": "Files in folders, with names outside ASCII.\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(p.ManifestPath(), filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			return fail(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			return fail(err)
+		}
+	}
+
+	// Seal prints its progress; the report is all that should be shown
+	devNull, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	if err != nil {
+		return fail(err)
+	}
+	defer devNull.Close()
+	stdout := os.Stdout
+	os.Stdout = devNull
+	_, err = sealProject(p, "", false, "")
+	os.Stdout = stdout
+	if err != nil {
+		return fail(fmt.Errorf("sealing: %w", err))
+	}
+
+	var shares []*core.Share
+	for _, f := range p.Friends {
+		path := friendBundlePath(p, f)
+		if err := bundle.VerifyBundle(path); err != nil {
+			return fail(fmt.Errorf("%s: %w", filepath.Base(path), err))
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fail(err)
+		}
+		share, err := recovery.ParseShareInput(path, data)
+		if err != nil {
+			return fail(err)
+		}
+		shares = append(shares, share)
+	}
+	// The manifest as a friend would find it: in the first bundle
+	info, err := bundle.ReadInfo(friendBundlePath(p, p.Friends[0]))
+	if err != nil {
+		return fail(err)
+	}
+	n, err := conformance.RecoverFiles(shares, info.Manifest, os.DirFS(p.ManifestPath()))
+	if err != nil {
+		return fail(err)
+	}
+	c.Detail = fmt.Sprintf("3 friends, 2 needed: %d bundles verified, %d combinations recovered the files", len(shares), n)
+	return c
+}
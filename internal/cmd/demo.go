@@ -5,6 +5,7 @@ import (
 	"os"
 	"path/filepath"
 
+	"github.com/eljojo/rememory/internal/manifest"
 	"github.com/eljojo/rememory/internal/project"
 	"github.com/spf13/cobra"
 )
@@ -41,7 +42,7 @@ func runDemo(cmd *cobra.Command, args []string) error {
 		dirName = args[0]
 	}
 
-	dir, err := filepath.Abs(dirName)
+	dir, err := manifest.AbsPath(dirName)
 	if err != nil {
 		return fmt.Errorf("resolving path: %w", err)
 	}
@@ -120,7 +121,7 @@ Note: In a real project, these would be your actual sensitive credentials.
 	fmt.Printf("  %s manifest/passwords.txt\n", green("✓"))
 	fmt.Println()
 
-	if err := sealProject(p, "", false); err != nil {
+	if _, err := sealProject(p, "", false, ""); err != nil {
 		return err
 	}
 
@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/eljojo/rememory/internal/core"
+	"github.com/eljojo/rememory/internal/project"
+	"gopkg.in/yaml.v3"
+)
+
+// seedFile is what 'rememory seal --deterministic FILE' reads: the seed all
+// of the seal's randomness comes from, and the time it's sealed at.
+type seedFile struct {
+	Seed string    `yaml:"seed"` // ReproducibleSeedSize bytes, in hex
+	Time time.Time `yaml:"time"`
+}
+
+// seedFileHeader opens a new seed file, since nothing else in it says how
+// much it gives away.
+const seedFileHeader = `# Seed for 'rememory seal --deterministic'. With it and the same manifest/
+# and project.yml, the seal can be rebuilt byte for byte. Anyone who has it
+# can also rebuild the passphrase, so keep it as safe as the files you sealed.
+`
+
+// enableReproducible seeds the seal from the file at path, writing a new
+// random seed there first if there's no file yet. It refuses what can't be
+// reproduced: the post-quantum mode, whose ML-KEM encapsulation takes its
+// randomness from the system however crypto/rand is seeded, and a recovery
+// delay, whose puzzle is sized by timing this computer.
+func enableReproducible(p *project.Project, path string) (restore func(), err error) {
+	switch {
+	case core.Deterministic():
+		return nil, fmt.Errorf("--deterministic can't be used with %s", deterministicSeedEnv)
+	case p.Crypto == core.CryptoRestricted:
+		return nil, fmt.Errorf("--deterministic is outside the restricted crypto profile")
+	case p.PostQuantum:
+		return nil, fmt.Errorf("--deterministic can't reproduce a post_quantum seal: ML-KEM draws its own randomness")
+	case p.RecoveryDelay != "":
+		return nil, fmt.Errorf("--deterministic can't reproduce a recovery_delay: its puzzle is sized by timing this computer")
+	}
+
+	sf, err := readSeedFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		sf, err = writeSeedFile(path)
+	}
+	if err != nil {
+		return nil, err
+	}
+	seed, err := hex.DecodeString(sf.Seed)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: seed isn't hex: %w", path, err)
+	}
+	defer core.Wipe(seed)
+	restore, err = core.EnableReproducible(seed, sf.Time)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+	fmt.Printf("Sealing reproducibly with the seed in %s, at %s\n", path, core.Now().Format(time.RFC3339))
+	fmt.Println(yellow("  Anyone with the seed file can rebuild the passphrase. Keep it as safe as the files you sealed."))
+	return restore, nil
+}
+
+// readSeedFile reads a seed file.
+func readSeedFile(path string) (*seedFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var sf seedFile
+	if err := yaml.Unmarshal(data, &sf); err != nil {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+	return &sf, nil
+}
+
+// writeSeedFile creates a seed file at path with a random seed and the
+// current time, readable only by its owner.
+func writeSeedFile(path string) (*seedFile, error) {
+	seed := make([]byte, core.ReproducibleSeedSize)
+	if _, err := rand.Read(seed); err != nil {
+		return nil, fmt.Errorf("generating seed: %w", err)
+	}
+	sf := &seedFile{Seed: hex.EncodeToString(seed), Time: time.Now().UTC().Truncate(time.Second)}
+	data, err := yaml.Marshal(sf)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("creating seed file: %w", err)
+	}
+	if _, err := f.Write(append([]byte(seedFileHeader), data...)); err != nil {
+		f.Close()
+		os.Remove(path)
+		return nil, fmt.Errorf("writing seed file: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(path)
+		return nil, fmt.Errorf("writing seed file: %w", err)
+	}
+	fmt.Printf("Wrote a new seed to %s\n", path)
+	return sf, nil
+}
@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/eljojo/rememory/internal/manifest"
+	"github.com/spf13/cobra"
+)
+
+var diffCmd = &cobra.Command{
+	Use:   "diff",
+	Short: "Show how manifest/ has changed since it was sealed",
+	Long: `Diff compares the files in manifest/ with the list of files, sizes, and
+checksums recorded when the project was sealed, and lists what was added,
+removed, or modified since. Nothing is decrypted.
+
+If anything changed, the sealed MANIFEST.age no longer matches your files
+and it's time to reseal.
+
+With --exit-code, diff exits with an error when anything changed, for use in
+scripts and reminders.
+
+Example:
+  rememory diff
+  rememory diff --exit-code || echo "time to reseal"`,
+	Args: cobra.NoArgs,
+	RunE: runDiff,
+}
+
+func init() {
+	diffCmd.Flags().Bool("exit-code", false, "Exit with an error if anything changed")
+	rootCmd.AddCommand(diffCmd)
+}
+
+// diffResult is the JSON output of diff.
+type diffResult struct {
+	SealedAt time.Time `json:"sealed_at"`
+	Changed  bool      `json:"changed"`
+	*manifest.Changes
+}
+
+func runDiff(cmd *cobra.Command, args []string) error {
+	exitCode, _ := cmd.Flags().GetBool("exit-code")
+
+	p, err := loadFriendProject()
+	if err != nil {
+		return err
+	}
+	if p.Sealed == nil {
+		return fmt.Errorf("project must be sealed first (run 'rememory seal')")
+	}
+	if len(p.Sealed.Files) == 0 {
+		return fmt.Errorf("this project was sealed before file lists were recorded; reseal once to start tracking changes")
+	}
+
+	ig, err := p.ManifestIgnore()
+	if err != nil {
+		return err
+	}
+	current, err := manifest.Snapshot(p.ManifestPath(), ig)
+	if err != nil {
+		return fmt.Errorf("reading manifest directory: %w", err)
+	}
+	changes := manifest.Compare(p.Sealed.Files, current)
+
+	if jsonOutput {
+		if err := printJSON(diffResult{SealedAt: p.Sealed.At, Changed: !changes.Empty(), Changes: changes}); err != nil {
+			return err
+		}
+	} else {
+		printChanges(p.Sealed.At, changes)
+	}
+
+	if exitCode && !changes.Empty() {
+		cmd.SilenceUsage = true
+		return fmt.Errorf("manifest/ has changed since it was sealed")
+	}
+	return nil
+}
+
+func printChanges(sealedAt time.Time, changes *manifest.Changes) {
+	fmt.Printf("Comparing manifest/ with the seal from %s\n\n", sealedAt.Format("2006-01-02 15:04 UTC"))
+
+	if changes.Empty() {
+		fmt.Printf("%s No changes since sealing\n", green("✓"))
+		return
+	}
+
+	for _, path := range changes.Added {
+		fmt.Printf("  %s %s\n", green("+"), path)
+	}
+	for _, path := range changes.Removed {
+		fmt.Printf("  %s %s\n", red("-"), path)
+	}
+	for _, path := range changes.Modified {
+		fmt.Printf("  %s %s\n", yellow("~"), path)
+	}
+
+	fmt.Println()
+	fmt.Printf("%d added, %d removed, %d modified\n", len(changes.Added), len(changes.Removed), len(changes.Modified))
+	fmt.Println("The sealed MANIFEST.age no longer matches these files. Run 'rememory seal' to reseal.")
+}
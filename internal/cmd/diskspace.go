@@ -0,0 +1,139 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"github.com/eljojo/rememory/internal/bundle"
+	"github.com/eljojo/rememory/internal/core"
+	"github.com/eljojo/rememory/internal/manifest"
+	"github.com/eljojo/rememory/internal/project"
+)
+
+// spaceNeed is disk space something is about to take in a folder.
+type spaceNeed struct {
+	dir   string
+	bytes int64
+	what  string
+}
+
+// checkSpace fails early when a filesystem doesn't have room for what's
+// about to be written to it, rather than partway through. Needs on the same
+// filesystem add up. Where the free space can't be read, as on some network
+// filesystems, nothing is checked.
+func checkSpace(needs ...spaceNeed) error {
+	type filesystem struct {
+		dir   string
+		free  uint64
+		bytes int64
+		what  []string
+	}
+	var order []*filesystem
+	byID := make(map[string]*filesystem)
+	for _, n := range needs {
+		if n.bytes <= 0 {
+			continue
+		}
+		dir := existingDir(n.dir)
+		free, id, err := diskFree(dir)
+		if err != nil {
+			continue
+		}
+		fs := byID[id]
+		if fs == nil {
+			fs = &filesystem{dir: dir, free: free}
+			byID[id] = fs
+			order = append(order, fs)
+		}
+		fs.bytes += n.bytes
+		fs.what = append(fs.what, n.what)
+	}
+	for _, fs := range order {
+		if uint64(fs.bytes) > fs.free {
+			return fmt.Errorf("not enough disk space in %s for %s: about %s is needed, and %s is free", fs.dir, strings.Join(fs.what, " and "), formatSize(fs.bytes), formatSize(int64(fs.free)))
+		}
+	}
+	return nil
+}
+
+// existingDir returns dir, or the closest folder above it that exists.
+func existingDir(dir string) string {
+	dir, _ = manifest.AbsPath(dir)
+	for {
+		if info, err := os.Stat(dir); err == nil && info.IsDir() {
+			return dir
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return dir
+		}
+		dir = parent
+	}
+}
+
+// sealSpace returns the space sealing p takes, for size bytes of files. The
+// new MANIFEST.age is written next to the current one before replacing it,
+// and bundles are too.
+func sealSpace(p *project.Project, size int64, wasmBytes []byte) []spaceNeed {
+	archiveSize := archiveOverhead(size)
+	needs := []spaceNeed{{dir: p.OutputPath(), bytes: archiveSize, what: "MANIFEST.age"}}
+	if volumeSize, err := p.VolumeBytes(); err == nil && volumeSize > 0 && archiveSize > volumeSize {
+		needs = append(needs, spaceNeed{dir: p.OutputPath(), bytes: archiveSize, what: "MANIFEST.age's volumes"})
+	}
+	if p.Parity > 0 {
+		paritySize := core.ParitySize(archiveSize, p.Parity)
+		needs = append(needs, spaceNeed{dir: p.OutputPath(), bytes: paritySize, what: "MANIFEST.age's parity"})
+		archiveSize += paritySize // Bundles carry it too
+	}
+	if p.Decoy != nil {
+		if decoySize, err := manifest.DirSize(p.DecoyManifestPath(), nil); err == nil {
+			needs = append(needs, spaceNeed{dir: p.OutputPath(), bytes: archiveOverhead(decoySize), what: "the decoy"})
+			archiveSize += decoySize // recover.html carries the decoy too
+		}
+	}
+	return append(needs, bundleSpace(p, archiveSize, wasmBytes))
+}
+
+// archiveOverhead returns the most MANIFEST.age can come to for size bytes
+// of files: tar headers and gzip can add a little where nothing compresses,
+// and age adds 16 bytes per 64 KiB.
+func archiveOverhead(size int64) int64 {
+	return size + size/100 + 1<<20
+}
+
+// bundleSpace returns the space bundles for p take beyond the current ones,
+// which are replaced one at a time: each new bundle is written in full
+// before the old one goes.
+func bundleSpace(p *project.Project, manifestSize int64, wasmBytes []byte) spaceNeed {
+	each := bundle.EstimateSize(bundle.RoughBaseSize(wasmBytes), manifestSize)
+	var current int64
+	for _, f := range p.Friends {
+		if info, err := os.Stat(friendBundlePath(p, f)); err == nil {
+			current += info.Size()
+		}
+	}
+	return spaceNeed{
+		dir:   filepath.Join(p.OutputPath(), "bundles"),
+		bytes: max(0, each*int64(len(p.Friends))-current) + each,
+		what:  fmt.Sprintf("%d bundle%s", len(p.Friends), plural(len(p.Friends))),
+	}
+}
+
+// spoolError explains running out of room while spooling sealed data.
+func spoolError(err error, spool manifest.Spool) error {
+	switch {
+	case errors.Is(err, manifest.ErrMemorySpoolFull):
+		return fmt.Errorf("%w; --memory-temp is for small secrets, use --tmpdir for this one", err)
+	case errors.Is(err, syscall.ENOSPC):
+		dir := spool.Dir
+		if dir == "" {
+			dir = os.TempDir()
+		}
+		return fmt.Errorf("%w: %s is full; choose another folder with --tmpdir, or keep small data in memory with --memory-temp", err, dir)
+	}
+	return err
+}
@@ -0,0 +1,25 @@
+//go:build !windows
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// diskFree returns the space free to this user on the filesystem holding
+// dir, and an ID that's the same for folders on the same filesystem.
+func diskFree(dir string) (uint64, string, error) {
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(dir, &st); err != nil {
+		return 0, "", err
+	}
+	id := dir
+	if info, err := os.Stat(dir); err == nil {
+		if sys, ok := info.Sys().(*syscall.Stat_t); ok {
+			id = fmt.Sprint(sys.Dev)
+		}
+	}
+	return uint64(st.Bavail) * uint64(st.Bsize), id, nil
+}
@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"path/filepath"
+	"strings"
+	"syscall"
+	"unsafe"
+)
+
+var procGetDiskFreeSpaceEx = syscall.NewLazyDLL("kernel32.dll").NewProc("GetDiskFreeSpaceExW")
+
+// diskFree returns the space free to this user on the volume holding dir,
+// and an ID that's the same for folders on the same volume.
+func diskFree(dir string) (uint64, string, error) {
+	path, err := syscall.UTF16PtrFromString(extendedPath(dir))
+	if err != nil {
+		return 0, "", err
+	}
+	var free uint64
+	if r, _, err := procGetDiskFreeSpaceEx.Call(uintptr(unsafe.Pointer(path)), uintptr(unsafe.Pointer(&free)), 0, 0); r == 0 {
+		return 0, "", err
+	}
+	return free, strings.ToLower(filepath.VolumeName(dir)), nil
+}
+
+// extendedPath gives an absolute folder the \\?\ prefix, as Go's os package
+// does for long paths, since the call above goes around it. It ends in a
+// backslash, which GetDiskFreeSpaceEx needs for network shares.
+func extendedPath(dir string) string {
+	if !strings.HasSuffix(dir, `\`) {
+		dir += `\`
+	}
+	switch {
+	case strings.HasPrefix(dir, `\\?\`):
+		return dir
+	case strings.HasPrefix(dir, `\\`):
+		return `\\?\UNC\` + dir[2:]
+	default:
+		return `\\?\` + dir
+	}
+}
@@ -0,0 +1,356 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/eljojo/rememory/internal/bundle"
+	"github.com/eljojo/rememory/internal/core"
+	"github.com/eljojo/rememory/internal/html"
+	"github.com/eljojo/rememory/internal/project"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check this installation and project for problems",
+	Long: `Doctor checks that this rememory binary is complete and, when run inside a
+project, that the project is healthy:
+
+  - the recovery tool (WASM) is built in
+  - project.yml is valid and has no unknown settings
+  - the output directory is writable
+  - MANIFEST.age and the pieces match the checksums in project.yml
+  - the pieces agree with each other and reconstruct the sealed passphrase
+  - every bundle matches the sealed files and this version's recovery tool
+
+Each problem comes with a suggested fix. Doctor never changes anything.`,
+	Args: cobra.NoArgs,
+	RunE: runDoctor,
+}
+
+func init() {
+	rootCmd.AddCommand(doctorCmd)
+}
+
+// doctorReport counts and prints the outcome of each check.
+type doctorReport struct {
+	problems int
+	warnings int
+}
+
+func (r *doctorReport) ok(format string, args ...any) {
+	fmt.Printf("  %s %s\n", green("✓"), fmt.Sprintf(format, args...))
+}
+
+func (r *doctorReport) warn(msg, fix string) {
+	r.warnings++
+	fmt.Printf("  %s %s\n", yellow("!"), msg)
+	if fix != "" {
+		fmt.Printf("    → %s\n", fix)
+	}
+}
+
+func (r *doctorReport) fail(msg, fix string) {
+	r.problems++
+	fmt.Printf("  %s %s\n", red("✗"), msg)
+	if fix != "" {
+		fmt.Printf("    → %s\n", fix)
+	}
+}
+
+func runDoctor(cmd *cobra.Command, args []string) error {
+	r := &doctorReport{}
+
+	fmt.Printf("rememory %s\n", version)
+	doctorInstallation(r)
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("getting current directory: %w", err)
+	}
+	if projectDir, err := project.FindProjectDir(cwd); err != nil {
+		fmt.Println()
+		fmt.Println("No project here; skipping project checks.")
+	} else {
+		doctorProject(r, projectDir)
+	}
+
+	fmt.Println()
+	switch {
+	case r.problems > 0:
+		return fmt.Errorf("found %d problem%s and %d warning%s", r.problems, plural(r.problems), r.warnings, plural(r.warnings))
+	case r.warnings > 0:
+		fmt.Printf("No problems, %d warning%s.\n", r.warnings, plural(r.warnings))
+	default:
+		fmt.Println("Everything looks good.")
+	}
+	return nil
+}
+
+var wasmMagic = []byte("\x00asm")
+
+func doctorInstallation(r *doctorReport) {
+	fmt.Println()
+	fmt.Println("Installation:")
+
+	recoverWASM := html.GetRecoverWASMBytes()
+	if !bytes.HasPrefix(recoverWASM, wasmMagic) {
+		r.fail("recover.wasm is missing or damaged — bundles can't be made", "rebuild with 'make build', or download a release binary")
+	} else {
+		r.ok("recover.wasm built in (%s, %s)", formatSize(int64(len(recoverWASM))), truncateHash(core.HashBytes(recoverWASM)))
+	}
+
+	if createWASM := html.GetCreateWASMBytes(); !bytes.HasPrefix(createWASM, wasmMagic) {
+		r.warn("create.wasm is missing — 'rememory html create' won't work", "rebuild with 'make build'")
+	} else {
+		r.ok("create.wasm built in (%s)", formatSize(int64(len(createWASM))))
+	}
+}
+
+func doctorProject(r *doctorReport, dir string) {
+	fmt.Println()
+	fmt.Printf("Project (%s):\n", dir)
+
+	p, err := project.Load(dir)
+	if err != nil {
+		r.fail(err.Error(), "fix the YAML syntax in project.yml")
+		return
+	}
+	if unknown := unknownProjectFields(filepath.Join(dir, project.ProjectFileName)); unknown != nil {
+		for _, u := range unknown {
+			r.warn("project.yml: "+u, "check for typos; rememory ignores settings it doesn't know")
+		}
+	}
+	if err := p.Validate(); err != nil {
+		r.fail("project.yml: "+err.Error(), "edit project.yml, or use 'rememory friend add/remove'")
+	} else {
+		r.ok("project.yml is valid (%d friends, threshold %d)", len(p.Friends), p.Threshold)
+	}
+	for _, f := range p.Friends {
+		if f.Language != "" && !validLanguage(f.Language) {
+			r.fail(fmt.Sprintf("%s has unsupported language %q", f.Name, f.Language), "use one of the supported language codes, or remove it")
+		}
+	}
+	if p.Language != "" && !validLanguage(p.Language) {
+		r.fail(fmt.Sprintf("unsupported project language %q", p.Language), "use one of the supported language codes, or remove it")
+	}
+	if err := bundle.CheckProjectTemplates(p); err != nil {
+		r.fail(err.Error(), "fix the file, or remove the setting from project.yml")
+	}
+
+	if err := checkWritable(p.OutputPath()); err != nil {
+		r.fail("output directory is not writable: "+err.Error(), "check the permissions of "+p.OutputPath())
+	} else {
+		r.ok("output directory is writable")
+	}
+
+	if p.Sealed == nil {
+		fmt.Println("  Not sealed yet.")
+		return
+	}
+
+	sealedOK := true
+	for _, c := range sealedFileChecks(p) {
+		switch c.Status {
+		case "ok":
+		case "missing":
+			sealedOK = false
+			r.fail(filepath.Base(c.Path)+" is missing", "restore it from a backup, or run 'rememory seal' and hand out new bundles")
+		case "mismatch":
+			sealedOK = false
+			r.fail(filepath.Base(c.Path)+" doesn't match its checksum in project.yml", "restore it from a backup, or run 'rememory seal' and hand out new bundles")
+		default:
+			sealedOK = false
+			r.fail(filepath.Base(c.Path)+": "+c.Error, "check the file's permissions")
+		}
+	}
+	if sealedOK {
+		r.ok("MANIFEST.age and %d pieces match project.yml", len(p.Sealed.Shares))
+	}
+
+	shares, err := loadSealedPieces(p)
+	if err != nil {
+		r.fail(err.Error(), "restore the share file from a backup")
+		return
+	}
+	problems, mixedCreated := shareInconsistencies(p, shares)
+	for _, problem := range problems {
+		r.fail(problem, "run 'rememory seal' to create a fresh, consistent set of pieces")
+	}
+	// Pieces locked with a PIN can't be read here; check with the others
+	var open []*core.Share
+	for _, s := range shares {
+		if !s.Locked() {
+			open = append(open, s)
+		}
+	}
+	canCheck := len(open) == len(shares) || !p.Grouped() && len(open) >= p.Threshold
+	if len(problems) == 0 && len(shares) >= p.MinPieces() && !canCheck {
+		r.warn(fmt.Sprintf("%d pieces are locked with a PIN, too many to check the others against the passphrase", len(shares)-len(open)), "")
+	}
+	if len(problems) == 0 && len(shares) >= p.MinPieces() && canCheck {
+		check := open
+		if !p.Grouped() {
+			check = open[:p.Threshold]
+		}
+		if err := verifySealedShares(p, check); err != nil {
+			r.fail("pieces don't reconstruct the sealed passphrase: "+err.Error(), "run 'rememory seal' and hand out new bundles")
+		} else {
+			r.ok("pieces agree with each other and reconstruct the passphrase")
+		}
+	}
+	if mixedCreated {
+		r.warn("pieces were created at different times", "expected after 'rememory friend add'; otherwise, re-seal")
+	}
+
+	doctorBundles(r, p, shares)
+}
+
+// doctorBundles compares each friend's bundle with the sealed files and this binary.
+func doctorBundles(r *doctorReport, p *project.Project, shares []*core.Share) {
+	manifestData, err := os.ReadFile(p.ManifestAgePath())
+	if err != nil {
+		return // already reported with the sealed files
+	}
+	wasmChecksum := core.HashBytes(html.GetRecoverWASMBytes())
+
+	healthy := 0
+	outdated := 0
+	expected := make(map[string]bool)
+	for i, friend := range p.Friends {
+		path := friendBundlePath(p, friend)
+		expected[filepath.Base(path)] = true
+
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			r.fail("no bundle for "+friend.Name, "run 'rememory bundle'")
+			continue
+		}
+		if err := bundle.VerifyBundle(path); err != nil {
+			r.fail(fmt.Sprintf("bundle for %s: %v", friend.Name, err), "run 'rememory bundle' to rebuild it")
+			continue
+		}
+		info, err := bundle.ReadInfo(path)
+		if err != nil {
+			r.fail(fmt.Sprintf("bundle for %s: %v", friend.Name, err), "run 'rememory bundle' to rebuild it")
+			continue
+		}
+		if !bytes.Equal(info.Share.Data, shares[i].Data) || !bytes.Equal(info.Manifest, manifestData) {
+			r.fail(fmt.Sprintf("bundle for %s is from a different seal", friend.Name), "run 'rememory bundle' to rebuild it")
+			continue
+		}
+		if info.WASMChecksum != wasmChecksum {
+			outdated++
+		}
+		healthy++
+	}
+
+	if healthy == len(p.Friends) {
+		r.ok("all %d bundles match the sealed files", healthy)
+	}
+	if outdated > 0 {
+		r.warn(fmt.Sprintf("%d bundle%s use a different recovery tool than this version", outdated, plural(outdated)),
+			"run 'rememory bundle --refresh' to update them without changing the pieces")
+	}
+
+	entries, _ := os.ReadDir(filepath.Join(p.OutputPath(), "bundles"))
+	for _, e := range entries {
+		if !e.IsDir() && strings.HasSuffix(e.Name(), ".zip") && !expected[e.Name()] {
+			r.warn(e.Name()+" doesn't belong to anyone in project.yml", "delete it if that friend was removed")
+		}
+	}
+}
+
+// shareInconsistencies lists ways the pieces disagree with each other or
+// with project.yml. Different creation times are reported separately because
+// 'friend add' produces them legitimately.
+func shareInconsistencies(p *project.Project, shares []*core.Share) (problems []string, mixedCreated bool) {
+	if len(shares) == 0 {
+		return []string{"no pieces found"}, false
+	}
+	first := shares[0]
+	seen := make(map[int]string)
+	for i, s := range shares {
+		if s.Version != first.Version {
+			problems = append(problems, fmt.Sprintf("%s's piece is format v%d, others are v%d", s.Holder, s.Version, first.Version))
+		}
+		if p.Grouped() {
+			if i < len(p.Friends) {
+				if g := p.GroupIndex(p.Friends[i]); s.Group != g {
+					problems = append(problems, fmt.Sprintf("%s's piece is for group %d, project.yml puts them in group %d", s.Holder, s.Group, g))
+				} else if want := p.Groups[g-1].Threshold; s.Threshold != want {
+					problems = append(problems, fmt.Sprintf("%s's piece needs %d pieces of its group, project.yml says %d", s.Holder, s.Threshold, want))
+				}
+			}
+			if s.Groups != len(p.Groups) || s.GroupsNeeded != p.Threshold {
+				problems = append(problems, fmt.Sprintf("%s's piece needs %d of %d groups, project.yml says %d of %d", s.Holder, s.GroupsNeeded, s.Groups, p.Threshold, len(p.Groups)))
+			}
+		} else if s.Threshold != p.Threshold {
+			problems = append(problems, fmt.Sprintf("%s's piece needs %d pieces, project.yml says %d", s.Holder, s.Threshold, p.Threshold))
+		}
+		if s.Total != len(shares) {
+			problems = append(problems, fmt.Sprintf("%s's piece says %d pieces in total, there are %d", s.Holder, s.Total, len(shares)))
+		}
+		if other, ok := seen[s.Index]; ok {
+			problems = append(problems, fmt.Sprintf("%s and %s have the same piece number (%d)", other, s.Holder, s.Index))
+		}
+		seen[s.Index] = s.Holder
+		if i < len(p.Friends) && s.Holder != p.PrivacyLevel().Holder(p.Friends[i], s.Index) && s.Holder != p.Friends[i].Name {
+			problems = append(problems, fmt.Sprintf("%s's share file holds %s's piece", p.Friends[i].Name, s.Holder))
+		}
+		// A single seal creates all pieces within moments of each other
+		if d := s.Created.Sub(first.Created); d > time.Minute || d < -time.Minute {
+			mixedCreated = true
+		}
+	}
+	return problems, mixedCreated
+}
+
+// sealedFileChecks compares MANIFEST.age, TIMELOCK.json, and every share
+// file with project.yml.
+func sealedFileChecks(p *project.Project) []fileCheck {
+	checks := []fileCheck{checkFile(p.ManifestAgePath(), p.Sealed.ManifestChecksum)}
+	if p.Sealed.TimelockChecksum != "" {
+		checks = append(checks, checkFile(p.TimelockPath(), p.Sealed.TimelockChecksum))
+	}
+	for _, si := range p.Sealed.Shares {
+		checks = append(checks, checkFile(filepath.Join(p.Path, si.File), si.Checksum))
+	}
+	return checks
+}
+
+// unknownProjectFields returns the strict-decoding complaints about
+// project.yml, usually typos in setting names. Load ignores such settings.
+func unknownProjectFields(path string) []string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	var p project.Project
+	var typeErr *yaml.TypeError
+	if err := dec.Decode(&p); errors.As(err, &typeErr) {
+		return typeErr.Errors
+	}
+	return nil
+}
+
+// checkWritable creates and removes a temporary file in dir.
+func checkWritable(dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(dir, ".doctor-*")
+	if err != nil {
+		return err
+	}
+	f.Close()
+	return os.Remove(f.Name())
+}
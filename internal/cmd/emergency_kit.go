@@ -0,0 +1,159 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/eljojo/rememory/internal/core"
+	"github.com/eljojo/rememory/internal/crypto"
+	"github.com/eljojo/rememory/internal/kit"
+	"github.com/eljojo/rememory/internal/project"
+	"github.com/spf13/cobra"
+)
+
+var emergencyKitCmd = &cobra.Command{
+	Use:   "emergency-kit",
+	Short: "Write a printable summary of the project for yourself",
+	Long: `Emergency-kit writes EMERGENCY-KIT.txt: one page for you, the owner, that
+records how the sealed project is set up. It lists every friend with their
+contact details and share checksum, the checksums of MANIFEST.age and each
+bundle, where the QR codes point, the seals these bundles replace, and the
+steps to seal everything again.
+
+Print it, or keep a copy away from this computer, so losing the computer
+doesn't mean losing track of who holds what.
+
+The kit holds no piece. With --passphrase it also holds the passphrase,
+rebuilt from the pieces in output/shares/ and locked with a password you
+choose, as an age block that 'age -d' opens.
+
+Examples:
+  rememory emergency-kit
+  rememory emergency-kit --passphrase -o /media/usb`,
+	RunE: runEmergencyKit,
+}
+
+func init() {
+	emergencyKitCmd.Flags().StringP("output", "o", "", "Directory to write EMERGENCY-KIT.txt to (default: the project's output/)")
+	emergencyKitCmd.Flags().Bool("passphrase", false, "Include the passphrase, locked with a password of your own")
+	rootCmd.AddCommand(emergencyKitCmd)
+}
+
+func runEmergencyKit(cmd *cobra.Command, args []string) error {
+	outputDir, _ := cmd.Flags().GetString("output")
+	withPassphrase, _ := cmd.Flags().GetBool("passphrase")
+
+	p, err := loadFriendProject()
+	if err != nil {
+		return err
+	}
+	if p.Sealed == nil {
+		return fmt.Errorf("project must be sealed first (run 'rememory seal')")
+	}
+	if withPassphrase && p.Sealed.Crypto == core.CryptoRestricted {
+		return fmt.Errorf("--passphrase is outside the restricted crypto profile this project was sealed under: it locks the passphrase with a password you chose")
+	}
+
+	k, err := emergencyKit(p)
+	if err != nil {
+		return err
+	}
+
+	if withPassphrase {
+		paths := make([]string, len(p.Sealed.Shares))
+		for i, si := range p.Sealed.Shares {
+			paths[i] = filepath.Join(p.Path, si.File)
+		}
+		passphrase, err := passphraseFromShareFiles(paths, false, true)
+		if err != nil {
+			return err
+		}
+		defer passphrase.Wipe()
+		if !core.VerifyHash(core.HashBytes(passphrase), p.Sealed.VerificationHash) {
+			return fmt.Errorf("the pieces in %s don't match this project's seal", p.SharesPath())
+		}
+		password, err := readNewPassword("Kit password")
+		if err != nil {
+			return err
+		}
+		if k.LockedPassphrase, err = kit.LockPassphrase(passphrase, password); err != nil {
+			return fmt.Errorf("locking passphrase: %w", err)
+		}
+	}
+
+	if outputDir == "" {
+		outputDir = p.OutputPath()
+	}
+	if err := os.MkdirAll(outputDir, 0700); err != nil {
+		return fmt.Errorf("creating output directory: %w", err)
+	}
+	path := filepath.Join(outputDir, kit.FileName)
+	if err := os.WriteFile(path, []byte(k.Text()), 0600); err != nil {
+		return fmt.Errorf("writing emergency kit: %w", err)
+	}
+
+	fmt.Printf("Wrote %s\n", path)
+	if withPassphrase {
+		fmt.Printf("  %s It holds the passphrase, locked with your password. Pick a long one, and keep the kit away from your friends' bundles.\n", yellow("Note:"))
+	}
+	fmt.Println("Print it or keep a copy off this computer. Make a new one whenever you seal again or change friends.")
+	return nil
+}
+
+// emergencyKit gathers what the kit records from a sealed project.
+func emergencyKit(p *project.Project) (*kit.Kit, error) {
+	reviewBy, expires, err := p.Sunset()
+	if err != nil {
+		return nil, err
+	}
+	recoveryURL := p.Sealed.RecoveryURL
+	if recoveryURL == "" {
+		recoveryURL = core.DefaultRecoveryURL
+	}
+	k := &kit.Kit{
+		Project:          p.Name,
+		Made:             core.Now(),
+		Version:          version,
+		Sealed:           p.Sealed.At,
+		ManifestChecksum: p.Sealed.ManifestChecksum,
+		Crypto:           p.Sealed.Crypto,
+		Threshold:        p.Threshold,
+		Total:            p.TotalPieces(),
+		Groups:           p.GroupPolicy(project.Friend{}),
+		RecoveryURL:      recoveryURL,
+		ReviewBy:         reviewBy,
+		Expires:          expires,
+		Contact:          p.Contact,
+		Privacy:          p.PrivacyLevel(),
+		Superseded:       p.Superseded,
+		Artifacts: []kit.Artifact{
+			{Name: filepath.Base(p.ManifestAgePath()), Checksum: p.Sealed.ManifestChecksum},
+		},
+	}
+
+	shares, err := loadSealedShares(p)
+	if err != nil {
+		return nil, err
+	}
+	for i, f := range p.Friends {
+		h := kit.Holder{
+			Name:          f.Name,
+			Contact:       f.Contact,
+			Location:      f.Location,
+			Index:         shares[i].Index,
+			ShareChecksum: shares[i].Checksum,
+		}
+		bundlePath := friendBundlePath(p, f)
+		if sum, err := crypto.HashFile(bundlePath); err == nil {
+			h.Bundle = filepath.Base(bundlePath)
+			h.BundleSum = sum
+		}
+		k.Holders = append(k.Holders, h)
+	}
+
+	if sum, err := crypto.HashFile(filepath.Join(p.OutputPath(), ownerEscrowFile)); err == nil {
+		k.Artifacts = append(k.Artifacts, kit.Artifact{Name: ownerEscrowFile, Checksum: sum})
+	}
+	return k, nil
+}
@@ -0,0 +1,221 @@
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/eljojo/rememory/internal/estate"
+	"github.com/spf13/cobra"
+)
+
+var estateCmd = &cobra.Command{
+	Use:   "estate",
+	Short: "Answer a few questions about your accounts, devices, and wishes",
+	Long: `Estate asks about the things an heir has to deal with that aren't in any
+file: online accounts, subscriptions that keep billing, devices with lock
+screens, and anything else you want known, such as a funeral, a pet, or a
+message for someone.
+
+The answers are written to manifest/estate.json and sealed with everything
+else on the next 'rememory seal'. recover.html shows them as a readable
+document, and 'rememory estate show' prints them.
+
+Leave out passwords: seal your password manager's export with
+'rememory seal --vault' instead. Running estate again adds to the answers
+already there; to change or remove one, edit the file, or start over with
+--fresh.
+
+Examples:
+  rememory estate
+  rememory estate show
+  rememory estate show recovered-2026-01-02/estate.json`,
+	Args: cobra.NoArgs,
+	RunE: runEstate,
+}
+
+var estateShowCmd = &cobra.Command{
+	Use:   "show [estate.json]",
+	Short: "Print the answers readably",
+	Args:  cobra.MaximumNArgs(1),
+	RunE:  runEstateShow,
+}
+
+func init() {
+	estateCmd.Flags().Bool("fresh", false, "Start over instead of adding to the answers already there")
+	estateCmd.AddCommand(estateShowCmd)
+	rootCmd.AddCommand(estateCmd)
+}
+
+func runEstate(cmd *cobra.Command, args []string) error {
+	fresh, _ := cmd.Flags().GetBool("fresh")
+
+	p, err := loadFriendProject()
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(p.ManifestPath(), estate.FileName)
+
+	doc := estate.New()
+	if !fresh {
+		if existing, err := estate.Load(path); err == nil {
+			doc = existing
+		} else if !os.IsNotExist(err) {
+			return err
+		}
+	}
+
+	fmt.Printf("Answers go to manifest/%s and are sealed with everything else.\n", estate.FileName)
+	fmt.Println("Leave out passwords: seal your password manager with 'rememory seal --vault' instead.")
+	if !doc.Empty() {
+		fmt.Printf("It already lists %s; new answers are added to them.\n", estateSummary(doc))
+	}
+
+	if err := askEstate(passwordInput, os.Stdout, doc); err != nil {
+		return err
+	}
+	if doc.Empty() {
+		fmt.Println("\nNothing to save.")
+		return nil
+	}
+
+	data, err := doc.Marshal()
+	if err != nil {
+		return fmt.Errorf("encoding answers: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("writing %s: %w", estate.FileName, err)
+	}
+	fmt.Printf("\n%s Saved manifest/%s (%s)\n", green("✓"), estate.FileName, estateSummary(doc))
+	if p.Sealed != nil {
+		fmt.Println("  The sealed copy changes when you run 'rememory seal' again.")
+	} else {
+		fmt.Println("  Run 'rememory seal' to seal it with the rest of manifest/.")
+	}
+	return nil
+}
+
+func runEstateShow(cmd *cobra.Command, args []string) error {
+	var path string
+	if len(args) > 0 {
+		path = args[0]
+	} else {
+		p, err := loadFriendProject()
+		if err != nil {
+			return fmt.Errorf("%w, or name the estate.json to show", err)
+		}
+		path = filepath.Join(p.ManifestPath(), estate.FileName)
+	}
+	doc, err := estate.Load(path)
+	if os.IsNotExist(err) {
+		return fmt.Errorf("no %s here yet (run 'rememory estate' to write one)", estate.FileName)
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Print(doc.Text())
+	return nil
+}
+
+// estateSection is one part of the questionnaire: the questions asked for
+// each entry, the first of which names it, and what to do with the answers.
+type estateSection struct {
+	intro     string
+	questions []string
+	add       func(answers []string)
+}
+
+// askEstate runs the questionnaire on in, adding the answers to doc. Each
+// part asks for entries until the first question is left empty. Input that
+// ends early keeps what was answered so far.
+func askEstate(in *bufio.Reader, out io.Writer, doc *estate.Document) error {
+	sections := []estateSection{
+		{
+			intro:     "Accounts — email, banks, social media: anything someone will need to close, keep, or hand on.",
+			questions: []string{"Service", "Username or email", "What should happen to it", "Anything else"},
+			add: func(a []string) {
+				doc.Accounts = append(doc.Accounts, estate.Account{Service: a[0], Login: a[1], Wish: a[2], Notes: a[3]})
+			},
+		},
+		{
+			intro:     "Subscriptions — anything that bills you every month or year.",
+			questions: []string{"Service", "Cost", "How to cancel it", "Anything else"},
+			add: func(a []string) {
+				doc.Subscriptions = append(doc.Subscriptions, estate.Subscription{Service: a[0], Cost: a[1], Cancel: a[2], Notes: a[3]})
+			},
+		},
+		{
+			intro:     "Devices — phones, computers, tablets: anything with a lock screen.",
+			questions: []string{"Device", "How to unlock it, or where the code is", "What to do with it", "Anything else"},
+			add: func(a []string) {
+				doc.Devices = append(doc.Devices, estate.Device{Name: a[0], Unlock: a[1], Wish: a[2], Notes: a[3]})
+			},
+		},
+		{
+			intro:     "Wishes — a funeral, a pet, a message for someone: anything else they should know.",
+			questions: []string{"About", "Your wish"},
+			add: func(a []string) {
+				doc.Wishes = append(doc.Wishes, estate.Wish{Topic: a[0], Text: a[1]})
+			},
+		},
+	}
+
+	fmt.Fprintln(out, "Leave the first question empty to go on to the next part.")
+	for _, s := range sections {
+		fmt.Fprintf(out, "\n%s\n", s.intro)
+		for {
+			answers := make([]string, len(s.questions))
+			for i, q := range s.questions {
+				fmt.Fprintf(out, "  %s: ", q)
+				line, err := in.ReadString('\n')
+				answers[i] = strings.TrimSpace(line)
+				if errors.Is(err, io.EOF) && line == "" {
+					fmt.Fprintln(out)
+					if i > 0 {
+						s.add(answers)
+					}
+					return nil
+				}
+				if err != nil && !errors.Is(err, io.EOF) {
+					return fmt.Errorf("reading answer: %w", err)
+				}
+				if i == 0 && answers[0] == "" {
+					break
+				}
+			}
+			if answers[0] == "" {
+				break
+			}
+			s.add(answers)
+		}
+	}
+	return nil
+}
+
+// estateSummary counts the answers, such as "2 accounts, 1 device".
+func estateSummary(doc *estate.Document) string {
+	var parts []string
+	for _, c := range []struct {
+		n    int
+		noun string
+	}{
+		{len(doc.Accounts), "account"},
+		{len(doc.Subscriptions), "subscription"},
+		{len(doc.Devices), "device"},
+		{len(doc.Wishes), "wish"},
+	} {
+		switch {
+		case c.n == 1:
+			parts = append(parts, "1 "+c.noun)
+		case c.n > 1 && c.noun == "wish":
+			parts = append(parts, fmt.Sprintf("%d wishes", c.n))
+		case c.n > 1:
+			parts = append(parts, fmt.Sprintf("%d %ss", c.n, c.noun))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
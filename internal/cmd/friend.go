@@ -0,0 +1,702 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/eljojo/rememory/internal/bundle"
+	"github.com/eljojo/rememory/internal/core"
+	"github.com/eljojo/rememory/internal/crypto"
+	"github.com/eljojo/rememory/internal/html"
+	"github.com/eljojo/rememory/internal/project"
+	"github.com/spf13/cobra"
+)
+
+var friendCmd = &cobra.Command{
+	Use:   "friend",
+	Short: "Add or remove a friend, even after sealing",
+	Long: `Add or remove a friend from the project.
+
+Before sealing, this only edits project.yml.
+
+After sealing, the encrypted manifest and the passphrase stay the same.
+Adding someone keeps everyone's pieces — nobody needs a new bundle except
+the person you add. Bundles are regenerated so everyone's contact list is
+current, and you can hand out the refreshed ones whenever it's convenient.
+
+Removing someone splits the passphrase again among the friends left: each
+gets a new piece, and the removed friend's piece doesn't combine with them.
+Give everyone their new bundle and ask them to delete the old one, since
+the old pieces still work together.
+
+Examples:
+  rememory friend add "David,david@example.com"
+  rememory friend add "Eva,eva@example.com,es"
+  rememory friend remove Bob`,
+}
+
+var friendAddCmd = &cobra.Command{
+	Use:   "add <name[,contact[,language]]>",
+	Short: "Add a friend and create their piece without changing the others",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runFriendAdd,
+}
+
+var friendRemoveCmd = &cobra.Command{
+	Use:   "remove <name>",
+	Short: "Remove a friend and their bundle",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runFriendRemove,
+}
+
+func init() {
+	friendCmd.PersistentFlags().String("recovery-url", core.DefaultRecoveryURL, "Base URL for QR code in PDF")
+	friendCmd.PersistentFlags().Bool("no-embed-manifest", false, "Do not embed MANIFEST.age in recover.html (it is embedded by default when 5 MB or less)")
+	friendCmd.AddCommand(friendAddCmd)
+	friendCmd.AddCommand(friendRemoveCmd)
+	rootCmd.AddCommand(friendCmd)
+}
+
+// errFriendGroups is returned by friend add and remove for grouped projects,
+// whose friends are edited in project.yml.
+var errFriendGroups = fmt.Errorf("this project has groups: edit the friends and their groups in project.yml, then run 'rememory seal' again")
+
+// errFriendWeights is returned by friend add and remove once a project whose
+// friends hold different numbers of pieces is sealed: the extra pieces are
+// numbered after everyone's first, so they can't be extended one at a time.
+var errFriendWeights = fmt.Errorf("some friends hold several pieces: edit the friends in project.yml, then run 'rememory seal' again")
+
+// errFriendPINs is returned by friend add and remove once a project with
+// pieces locked by a PIN is sealed: new pieces are made from the others,
+// which can't be read without their PINs.
+var errFriendPINs = fmt.Errorf("some pieces are locked with a PIN: edit the friends in project.yml, then run 'rememory seal' again")
+
+// errFriendDecoy is returned by friend add and remove once a project with a
+// decoy is sealed: every friend's duress piece has to change with their real
+// one, or the two sets would tell themselves apart.
+var errFriendDecoy = fmt.Errorf("this project has a decoy: edit the friends in project.yml, then run 'rememory seal' again")
+
+func loadFriendProject() (*project.Project, error) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return nil, fmt.Errorf("getting current directory: %w", err)
+	}
+
+	projectDir, err := project.FindProjectDir(cwd)
+	if err != nil {
+		return nil, fmt.Errorf("no rememory project found (run 'rememory init' first)")
+	}
+
+	p, err := project.Load(projectDir)
+	if err != nil {
+		return nil, fmt.Errorf("loading project: %w", err)
+	}
+	return p, nil
+}
+
+func runFriendAdd(cmd *cobra.Command, args []string) error {
+	p, err := loadFriendProject()
+	if err != nil {
+		return err
+	}
+
+	parsed, err := parseFriendFlags(args)
+	if err != nil {
+		return err
+	}
+	friend := parsed[0]
+	if p.Grouped() {
+		return errFriendGroups
+	}
+
+	holders := make([]core.Holder, 0, len(p.Friends)+1)
+	for _, f := range p.Friends {
+		holders = append(holders, core.Holder{Name: f.Name, Contact: f.Contact})
+	}
+	holders = append(holders, core.Holder{Name: friend.Name, Contact: friend.Contact})
+	if err := core.ValidateHolders(holders); err != nil {
+		return err
+	}
+
+	if p.Sealed == nil {
+		if err := snapshot(p, "friend-add"); err != nil {
+			return err
+		}
+		p.Friends = append(p.Friends, friend)
+		if err := p.Save(); err != nil {
+			return fmt.Errorf("saving project: %w", err)
+		}
+		logEvent(p, "friend-add", "added %s", friend.Name)
+		fmt.Printf("Added %s. Run 'rememory seal' when you're ready.\n", friend.Name)
+		return nil
+	}
+	if p.Weighted() {
+		return errFriendWeights
+	}
+	if p.Decoy != nil {
+		return errFriendDecoy
+	}
+
+	shares, err := loadSealedShares(p)
+	if err != nil {
+		return err
+	}
+	for _, s := range shares {
+		if s.Locked() {
+			return errFriendPINs
+		}
+	}
+	if err := verifySealedShares(p, shares); err != nil {
+		return err
+	}
+	if err := snapshot(p, "friend-add"); err != nil {
+		return err
+	}
+
+	existing := make([][]byte, len(shares))
+	for i, s := range shares {
+		existing[i] = s.Data
+	}
+
+	fmt.Printf("Creating a piece for %s...\n", friend.Name)
+	data, err := core.Extend(existing)
+	if err != nil {
+		return fmt.Errorf("creating share: %w", err)
+	}
+
+	// Indices are never reused, not even a removed friend's: recover.html
+	// treats equal indices as duplicates
+	total := len(p.Friends) + 1
+	index := nextPieceIndex(p, shares)
+	newShare := core.NewShare(shares[0].Version, index, total, p.Threshold, p.PrivacyLevel().Holder(friend, index), data)
+	newShare.ReviewBy, newShare.Expires = shares[0].ReviewBy, shares[0].Expires // same generation, same dates
+	newShare.WorkFactor = shares[0].WorkFactor
+	if id := shares[0].BundleID; id != "" {
+		newShare.Bind(id)
+	}
+	if shares[0].MAC != "" {
+		secret, err := core.Combine(existing)
+		if err != nil {
+			return fmt.Errorf("creating share: %w", err)
+		}
+		newShare.Authenticate(secret)
+	}
+	if shares[0].CatalogThreshold > 0 {
+		catalog := make([][]byte, len(shares))
+		for i, s := range shares {
+			catalog[i] = s.CatalogData
+		}
+		if newShare.CatalogData, err = core.Extend(catalog); err != nil {
+			return fmt.Errorf("extending catalog key: %w", err)
+		}
+		newShare.CatalogThreshold = shares[0].CatalogThreshold
+	}
+	if len(p.Sealed.VSS) > 0 {
+		if err := core.ExtendVSS(shares, p.Threshold, newShare); err != nil {
+			return err
+		}
+	}
+	if lang := p.WordList(friend); lang != core.LangEN {
+		newShare.WordList = lang
+	}
+	p.Friends = append(p.Friends, friend)
+
+	// Verify the new piece plus threshold-1 others reconstructs the same passphrase
+	check := append([]*core.Share{newShare}, shares[:p.Threshold-1]...)
+	if err := verifySealedShares(p, check); err != nil {
+		return fmt.Errorf("new piece failed verification: %w", err)
+	}
+	shares = append(shares, newShare)
+	p.Sealed.LastIndex = index
+
+	if p.SLIP39 {
+		if err := extendSLIP39Words(p, friend, index-1); err != nil {
+			return err
+		}
+	}
+	if p.SSKR {
+		if err := extendSSKRShares(p, friend, index-1); err != nil {
+			return err
+		}
+	}
+	if p.SSSS {
+		if err := extendSSSSShares(p, friend); err != nil {
+			return err
+		}
+	}
+	if err := writeSealedShares(p, shares); err != nil {
+		return err
+	}
+
+	if err := regenerateFriendBundles(cmd, p); err != nil {
+		return err
+	}
+
+	logEvent(p, "friend-add", "added %s with piece %d", friend.Name, newShare.Index)
+	fmt.Println()
+	fmt.Printf("%s Added %s (piece %d).\n", green("✓"), friend.Name, newShare.Index)
+	fmt.Printf("  Give them: %s\n", friendBundlePath(p, friend))
+	fmt.Println("  Everyone else's pieces still work. Their refreshed bundles include the new contact.")
+	printPlacementWarnings(p)
+	return nil
+}
+
+func runFriendRemove(cmd *cobra.Command, args []string) error {
+	p, err := loadFriendProject()
+	if err != nil {
+		return err
+	}
+
+	name := strings.TrimSpace(args[0])
+	idx := -1
+	for i, f := range p.Friends {
+		if strings.EqualFold(f.Name, name) {
+			idx = i
+			break
+		}
+	}
+	if idx == -1 {
+		return fmt.Errorf("no friend named %q in the project", name)
+	}
+	removed := p.Friends[idx]
+	if p.Grouped() {
+		return errFriendGroups
+	}
+
+	if left := p.TotalPieces() - removed.Pieces(); left < p.Threshold {
+		return fmt.Errorf("removing %s would leave %d pieces, fewer than the threshold of %d", removed.Name, left, p.Threshold)
+	}
+	if len(p.Friends)-1 < 2 {
+		return fmt.Errorf("a project needs at least 2 friends")
+	}
+
+	if p.Sealed == nil {
+		if err := snapshot(p, "friend-remove"); err != nil {
+			return err
+		}
+		p.Friends = append(p.Friends[:idx], p.Friends[idx+1:]...)
+		if err := p.Save(); err != nil {
+			return fmt.Errorf("saving project: %w", err)
+		}
+		logEvent(p, "friend-remove", "removed %s", removed.Name)
+		fmt.Printf("Removed %s.\n", removed.Name)
+		return nil
+	}
+	if p.Weighted() {
+		return errFriendWeights
+	}
+	if p.Decoy != nil {
+		return errFriendDecoy
+	}
+
+	shares, err := loadSealedShares(p)
+	if err != nil {
+		return err
+	}
+	for _, s := range shares {
+		if s.Locked() {
+			return errFriendPINs
+		}
+	}
+	if err := verifySealedShares(p, shares); err != nil {
+		return err
+	}
+	if err := snapshot(p, "friend-remove"); err != nil {
+		return err
+	}
+
+	data := make([][]byte, len(shares))
+	for i, s := range shares {
+		data[i] = s.Data
+	}
+	raw, err := core.Combine(data)
+	if err != nil {
+		return err
+	}
+	defer core.Wipe(raw)
+
+	p.Sealed.LastIndex = nextPieceIndex(p, shares) - 1
+	p.Friends = append(p.Friends[:idx], p.Friends[idx+1:]...)
+	fmt.Printf("Splitting the passphrase again for the %d friends left...\n", len(p.Friends))
+	resplit, err := resplitShares(p, raw, shares, idx)
+	if err != nil {
+		return fmt.Errorf("splitting passphrase: %w", err)
+	}
+	if err := verifySealedShares(p, resplit[:p.Threshold]); err != nil {
+		return fmt.Errorf("new pieces failed verification: %w", err)
+	}
+
+	if err := writeSLIP39Words(p, raw); err != nil {
+		return err
+	}
+	if err := writeSSKRShares(p, raw); err != nil {
+		return err
+	}
+	if err := writeSSSSShares(p, raw); err != nil {
+		return err
+	}
+	if err := writeSealedShares(p, resplit); err != nil {
+		return err
+	}
+
+	sharePath := p.SharePath(removed)
+	if err := os.Remove(sharePath); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("removing share file: %w", err)
+	}
+	if err := os.Remove(p.SLIP39Path(removed)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("removing SLIP-0039 words: %w", err)
+	}
+	if err := os.Remove(p.SSKRPath(removed)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("removing SSKR share: %w", err)
+	}
+	if err := os.Remove(p.SSSSPath(removed)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("removing ssss share: %w", err)
+	}
+	if err := os.Remove(friendBundlePath(p, removed)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("removing bundle: %w", err)
+	}
+
+	if err := regenerateFriendBundles(cmd, p); err != nil {
+		return err
+	}
+
+	logEvent(p, "friend-remove", "removed %s and their bundle, and split the passphrase again for the others", removed.Name)
+	fmt.Println()
+	fmt.Printf("%s Removed %s.\n", green("✓"), removed.Name)
+	fmt.Printf("  Everyone else has a new piece, which %s's doesn't combine with.\n", removed.Name)
+	fmt.Printf("  %s Give everyone their new bundle and ask them to delete the old one: the old pieces still work together.\n", yellow("Note:"))
+	printPlacementWarnings(p)
+	return nil
+}
+
+// nextPieceIndex returns the index for a piece added to the seal: one past
+// the highest it has handed out, so a removed friend's index never comes
+// back. Seals from before that was recorded count from the pieces left.
+func nextPieceIndex(p *project.Project, shares []*core.Share) int {
+	last := p.Sealed.LastIndex
+	for _, s := range shares {
+		last = max(last, s.Index)
+	}
+	return last + 1
+}
+
+// resplitShares splits raw, the passphrase shares rebuild, again among all
+// of them but the one at drop. Each new piece keeps its index, holder, and
+// dates, with a new MAC, VSS value, and share of the catalog key when the
+// seal has them, so the dropped piece doesn't combine with the new ones. It
+// records the seal's new VSS commitments.
+func resplitShares(p *project.Project, raw []byte, shares []*core.Share, drop int) ([]*core.Share, error) {
+	first := shares[0]
+	total := len(shares) - 1
+	data, err := core.Split(raw, total, p.Threshold)
+	if err != nil {
+		return nil, err
+	}
+	if first.BundleID != "" && core.DataBundle(first.Data) != "" {
+		if err := core.BindShares(data, first.BundleID); err != nil {
+			return nil, err
+		}
+	}
+
+	resplit := make([]*core.Share, 0, total)
+	for i, s := range shares {
+		if i == drop {
+			continue
+		}
+		share := core.NewShare(s.Version, s.Index, total, p.Threshold, s.Holder, data[len(resplit)])
+		share.ReviewBy, share.Expires = s.ReviewBy, s.Expires
+		share.WorkFactor, share.WordList = s.WorkFactor, s.WordList
+		if s.MAC != "" {
+			share.Authenticate(raw)
+		}
+		if s.BundleID != "" {
+			share.Bind(s.BundleID)
+		}
+		resplit = append(resplit, share)
+	}
+
+	if first.CatalogThreshold > 0 {
+		key, err := core.CombineCatalogKey(shares)
+		if err != nil {
+			return nil, err
+		}
+		defer core.Wipe(key)
+		if err := core.SplitCatalogKey(key, first.CatalogThreshold, resplit); err != nil {
+			return nil, err
+		}
+	}
+	if len(p.Sealed.VSS) > 0 {
+		vss, err := core.SplitVSS(raw, p.Threshold, resplit)
+		if err != nil {
+			return nil, err
+		}
+		p.Sealed.VSS = vss.Strings()
+	}
+	return resplit, nil
+}
+
+// extendSLIP39Words writes SLIP-0039 words for a newly added friend, from
+// everyone else's, and checks they recover the same secret. member is the
+// SLIP-0039 member index to give them: their piece's index less one, which
+// is past any the words have had, as the piece's is.
+func extendSLIP39Words(p *project.Project, added project.Friend, member int) error {
+	var existing []string
+	for _, f := range p.Friends {
+		if f.Name == added.Name {
+			continue
+		}
+		data, err := os.ReadFile(p.SLIP39Path(f))
+		if err != nil {
+			return fmt.Errorf("reading SLIP-0039 words for %s (run 'rememory seal' again): %w", f.Name, err)
+		}
+		existing = append(existing, strings.TrimSpace(string(data)))
+	}
+
+	mnemonic, err := core.SLIP39Extend(existing, member)
+	if err != nil {
+		return fmt.Errorf("creating SLIP-0039 words: %w", err)
+	}
+	want, err := core.SLIP39Combine(existing)
+	if err != nil {
+		return fmt.Errorf("checking SLIP-0039 words: %w", err)
+	}
+	got, err := core.SLIP39Combine(append([]string{mnemonic}, existing[:p.Threshold-1]...))
+	if err != nil || !bytes.Equal(got, want) {
+		return fmt.Errorf("new SLIP-0039 words failed verification")
+	}
+	return os.WriteFile(p.SLIP39Path(added), []byte(mnemonic+"\n"), 0600)
+}
+
+// extendSSKRShares writes an SSKR share for a newly added friend, from
+// everyone else's, and checks it recovers the same secret. member is the SSKR
+// member index to give them, as for extendSLIP39Words.
+func extendSSKRShares(p *project.Project, added project.Friend, member int) error {
+	var existing []*core.SSKRShare
+	for _, f := range p.Friends {
+		if f.Name == added.Name {
+			continue
+		}
+		data, err := os.ReadFile(p.SSKRPath(f))
+		if err != nil {
+			return fmt.Errorf("reading SSKR share for %s (run 'rememory seal' again): %w", f.Name, err)
+		}
+		share, err := core.ParseSSKR(string(data))
+		if err != nil {
+			return fmt.Errorf("reading SSKR share for %s: %w", f.Name, err)
+		}
+		existing = append(existing, share)
+	}
+
+	share, err := core.SSKRExtend(existing, member)
+	if err != nil {
+		return fmt.Errorf("creating SSKR share: %w", err)
+	}
+	want, err := core.SSKRCombine(existing)
+	if err != nil {
+		return fmt.Errorf("checking SSKR shares: %w", err)
+	}
+	got, err := core.SSKRCombine(append([]*core.SSKRShare{share}, existing[:p.Threshold-1]...))
+	if err != nil || !bytes.Equal(got, want) {
+		return fmt.Errorf("new SSKR share failed verification")
+	}
+	return os.WriteFile(p.SSKRPath(added), []byte(share.UR()+"\n"), 0600)
+}
+
+// extendSSSSShares writes an ssss share for a newly added friend, from
+// everyone else's, and checks it recovers the same secret.
+func extendSSSSShares(p *project.Project, added project.Friend) error {
+	var existing []string
+	for _, f := range p.Friends {
+		if f.Name == added.Name {
+			continue
+		}
+		data, err := os.ReadFile(p.SSSSPath(f))
+		if err != nil {
+			return fmt.Errorf("reading ssss share for %s (run 'rememory seal' again): %w", f.Name, err)
+		}
+		existing = append(existing, strings.TrimSpace(string(data)))
+	}
+
+	share, err := core.SSSSExtend(existing, p.Threshold)
+	if err != nil {
+		return fmt.Errorf("creating ssss share: %w", err)
+	}
+	want, err := core.SSSSCombine(existing, p.Threshold)
+	if err != nil {
+		return fmt.Errorf("checking ssss shares: %w", err)
+	}
+	got, err := core.SSSSCombine(append([]string{share}, existing[:p.Threshold-1]...), p.Threshold)
+	if err != nil || !bytes.Equal(got, want) {
+		return fmt.Errorf("new ssss share failed verification")
+	}
+	return os.WriteFile(p.SSSSPath(added), []byte(share+"\n"), 0600)
+}
+
+// loadSealedShares reads every friend's share file, in project order. Each
+// friend's first piece stands for them; see loadSealedPieces for all pieces.
+func loadSealedShares(p *project.Project) ([]*core.Share, error) {
+	shares := make([]*core.Share, len(p.Friends))
+	for i, f := range p.Friends {
+		pieces, err := readFriendShares(p, f)
+		if err != nil {
+			return nil, err
+		}
+		shares[i] = pieces[0]
+	}
+	return shares, nil
+}
+
+// loadSealedPieces reads every piece: each friend's first, in project order,
+// then the extra pieces of friends who hold more than one.
+func loadSealedPieces(p *project.Project) ([]*core.Share, error) {
+	var first, extra []*core.Share
+	for _, f := range p.Friends {
+		pieces, err := readFriendShares(p, f)
+		if err != nil {
+			return nil, err
+		}
+		first = append(first, pieces[0])
+		extra = append(extra, pieces[1:]...)
+	}
+	return append(first, extra...), nil
+}
+
+// readFriendShares reads and verifies the pieces in a friend's share file.
+func readFriendShares(p *project.Project, f project.Friend) ([]*core.Share, error) {
+	data, err := os.ReadFile(p.SharePath(f))
+	if err != nil {
+		return nil, fmt.Errorf("reading share for %s: %w", f.Name, err)
+	}
+	shares, err := core.ParseShares(data)
+	if err != nil {
+		return nil, fmt.Errorf("parsing share for %s: %w", f.Name, err)
+	}
+	for _, share := range shares {
+		if err := share.Verify(); err != nil {
+			return nil, fmt.Errorf("share for %s: %w", f.Name, err)
+		}
+	}
+	return shares, nil
+}
+
+// verifySealedShares checks that the shares reconstruct the sealed passphrase.
+func verifySealedShares(p *project.Project, shares []*core.Share) error {
+	if len(shares) < 2 {
+		return fmt.Errorf("need at least 2 shares")
+	}
+	var recovered []byte
+	var err error
+	if p.Grouped() {
+		recovered, err = core.CombineGroupShares(shares)
+	} else {
+		data := make([][]byte, len(shares))
+		for i, s := range shares {
+			data[i] = s.Data
+		}
+		recovered, err = core.Combine(data)
+	}
+	if err != nil {
+		return err
+	}
+	defer core.Wipe(recovered)
+	passphrase := core.RecoverPassphrase(recovered, shares[0].Version)
+	defer passphrase.Wipe()
+	if core.HashBytes(passphrase) != p.Sealed.VerificationHash {
+		return fmt.Errorf("shares don't match the sealed passphrase")
+	}
+	if len(p.Sealed.VSS) > 0 {
+		vss, err := core.ParseVSSCommitments(p.Sealed.VSS)
+		if err != nil {
+			return err
+		}
+		for _, s := range shares {
+			if len(s.VSS) == 0 {
+				continue
+			}
+			if err := vss.Verify(s.Index, s.VSS); err != nil {
+				return err
+			}
+		}
+	}
+	return core.CheckMACs(shares, recovered)
+}
+
+// writeSealedShares rewrites the share files and the seal record in project.yml.
+func writeSealedShares(p *project.Project, shares []*core.Share) error {
+	shareInfos := make([]project.ShareInfo, len(shares))
+	for i, s := range shares {
+		s.Total = len(shares)
+
+		friend := p.Friends[i]
+		sharePath := p.SharePath(friend)
+		if err := os.WriteFile(sharePath, []byte(s.Encode()), 0600); err != nil {
+			return fmt.Errorf("writing share for %s: %w", friend.Name, err)
+		}
+
+		checksum, err := crypto.HashFile(sharePath)
+		if err != nil {
+			return fmt.Errorf("computing checksum: %w", err)
+		}
+		relPath, _ := filepath.Rel(p.Path, sharePath)
+		shareInfos[i] = project.ShareInfo{
+			Friend:   friend.Name,
+			File:     relPath,
+			Checksum: checksum,
+		}
+	}
+
+	p.Sealed.Shares = shareInfos
+	if err := p.Save(); err != nil {
+		return fmt.Errorf("saving project: %w", err)
+	}
+	return nil
+}
+
+func regenerateFriendBundles(cmd *cobra.Command, p *project.Project) error {
+	wasmBytes := html.GetRecoverWASMBytes()
+	if len(wasmBytes) == 0 {
+		return fmt.Errorf("recover.wasm not embedded - rebuild with 'make build'")
+	}
+
+	recoveryURL, _ := cmd.Flags().GetString("recovery-url")
+	noEmbedManifest, _ := cmd.Flags().GetBool("no-embed-manifest")
+
+	fmt.Printf("Regenerating bundles for %d friends...\n", len(p.Friends))
+	cfg := bundle.Config{
+		Version:          version,
+		GitHubReleaseURL: fmt.Sprintf("https://github.com/eljojo/rememory/releases/tag/%s", version),
+		WASMBytes:        wasmBytes,
+		RecoveryURL:      recoveryURL,
+		NoEmbedManifest:  noEmbedManifest,
+	}
+	if err := bundle.GenerateAll(p, cfg); err != nil {
+		return fmt.Errorf("generating bundles: %w", err)
+	}
+	return recordRecoveryURL(p, recoveryURL)
+}
+
+// recordedRecoveryURL is how a QR code base URL is kept in the seal record:
+// empty for the default.
+func recordedRecoveryURL(recoveryURL string) string {
+	if recoveryURL == core.DefaultRecoveryURL {
+		return ""
+	}
+	return recoveryURL
+}
+
+// recordRecoveryURL notes in project.yml where freshly made bundles' QR codes
+// point, for 'rememory analyze'.
+func recordRecoveryURL(p *project.Project, recoveryURL string) error {
+	if p.Sealed.RecoveryURL == recordedRecoveryURL(recoveryURL) {
+		return nil
+	}
+	p.Sealed.RecoveryURL = recordedRecoveryURL(recoveryURL)
+	return p.Save()
+}
+
+func friendBundlePath(p *project.Project, f project.Friend) string {
+	return filepath.Join(p.OutputPath(), "bundles", fmt.Sprintf("bundle-%s.zip", core.SanitizeFilename(f.Name)))
+}
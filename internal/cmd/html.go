@@ -3,14 +3,18 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 
+	"github.com/eljojo/rememory/internal/bundle"
+	"github.com/eljojo/rememory/internal/core"
 	"github.com/eljojo/rememory/internal/html"
+	"github.com/eljojo/rememory/internal/project"
 	"github.com/spf13/cobra"
 )
 
 var htmlCmd = &cobra.Command{
-	Use:   "html [index|create|docs|recover]",
+	Use:   "html [index|create|docs|recover|verify]",
 	Short: "Generate standalone HTML files for static hosting",
 	Long: `Generate standalone HTML files that can be hosted on a static website.
 
@@ -19,29 +23,78 @@ Commands:
   create   Generate maker.html (bundle creation tool)
   docs     Generate docs.html (documentation page)
   recover  Generate recover.html (recovery tool for collecting shares)
+  verify   Generate a VERIFY.html per friend (bundle check, no recovery)
 
 The create and recover HTML files are self-contained with embedded WASM binary,
 JavaScript, and CSS. They work fully offline.
 
+With --prefill, "create" reads the project in the current directory and bakes
+its name, threshold, language, and friends into maker.html. You can then carry
+that single file to an offline machine and make the bundles there without
+typing anything again. Only the roster is included — never your files.
+
+With --customize, "create" and "recover" include the banner, header, footer,
+and CSS set in the html section of the current project's project.yml.
+
+"verify" reads the sealed project in the current directory and writes one
+VERIFY-<name>.html per friend into output/verify/ (or the directory given
+with -o). Each page only knows the checksums of that friend's piece and
+bundle files, so holders can check their copy once a year without being
+handed anything that could recover the secret.
+
 Examples:
   rememory html index > index.html
   rememory html create > maker.html
+  rememory html create --prefill -o maker.html
   rememory html docs > docs.html
-  rememory html recover > recover.html`,
+  rememory html recover > recover.html
+  rememory html recover --customize -o recover.html
+  rememory html verify`,
 	Args: cobra.ExactArgs(1),
 	RunE: runHTML,
 }
 
-var htmlOutputFile string
+var (
+	htmlOutputFile string
+	htmlPrefill    bool
+	htmlCustomize  bool
+)
 
 func init() {
-	htmlCmd.Flags().StringVarP(&htmlOutputFile, "output", "o", "", "Output file path (default: stdout)")
+	htmlCmd.Flags().StringVarP(&htmlOutputFile, "output", "o", "", "Output file path (default: stdout; for verify, a directory)")
+	htmlCmd.Flags().BoolVar(&htmlPrefill, "prefill", false, "Pre-fill maker.html with the current project's friends and settings (create only)")
+	htmlCmd.Flags().BoolVar(&htmlCustomize, "customize", false, "Apply the current project's HTML customization (create and recover only)")
 	rootCmd.AddCommand(htmlCmd)
 }
 
 func runHTML(cmd *cobra.Command, args []string) error {
 	subcommand := args[0]
 
+	if htmlPrefill && subcommand != "create" {
+		return fmt.Errorf("--prefill only works with 'html create'")
+	}
+	if htmlCustomize && subcommand != "create" && subcommand != "recover" {
+		return fmt.Errorf("--customize only works with 'html create' and 'html recover'")
+	}
+
+	if subcommand == "verify" {
+		return runHTMLVerify()
+	}
+
+	var custom *html.Customization
+	if htmlCustomize {
+		p, err := loadHTMLProject("--customize")
+		if err != nil {
+			return err
+		}
+		if custom, err = bundle.LoadHTMLCustomization(p); err != nil {
+			return err
+		}
+		if custom == nil {
+			return fmt.Errorf("project.yml has no html section to apply")
+		}
+	}
+
 	var content string
 	// Use specific release URL if version is a tag, otherwise use latest
 	var githubURL string
@@ -67,7 +120,7 @@ func runHTML(cmd *cobra.Command, args []string) error {
 		if len(recoverWASM) == 0 {
 			return fmt.Errorf("recover.wasm not embedded - rebuild with 'make build'")
 		}
-		content = html.GenerateRecoverHTML(recoverWASM, version, githubURL, nil)
+		content = html.GenerateRecoverHTML(recoverWASM, version, githubURL, nil, custom)
 
 	case "create":
 		// Generate maker.html (bundle creation tool)
@@ -76,10 +129,18 @@ func runHTML(cmd *cobra.Command, args []string) error {
 		if len(createWASM) == 0 {
 			return fmt.Errorf("create.wasm not embedded - rebuild with 'make build'")
 		}
-		content = html.GenerateMakerHTML(createWASM, version, githubURL)
+		var prefill *html.PrefillData
+		if htmlPrefill {
+			p, err := loadHTMLProject("--prefill")
+			if err != nil {
+				return err
+			}
+			prefill = prefillFromProject(p)
+		}
+		content = html.GenerateMakerHTML(createWASM, version, githubURL, prefill, custom)
 
 	default:
-		return fmt.Errorf("unknown subcommand: %s (use 'index', 'create', 'docs', or 'recover')", subcommand)
+		return fmt.Errorf("unknown subcommand: %s (use 'index', 'create', 'docs', 'recover', or 'verify')", subcommand)
 	}
 
 	// Output to file or stdout
@@ -94,3 +155,96 @@ func runHTML(cmd *cobra.Command, args []string) error {
 
 	return nil
 }
+
+// runHTMLVerify writes a VERIFY.html for each friend of the sealed project.
+func runHTMLVerify() error {
+	verifyWASM := html.GetVerifyWASMBytes()
+	if len(verifyWASM) == 0 {
+		return fmt.Errorf("verify.wasm not embedded - rebuild with 'make build'")
+	}
+
+	p, err := loadHTMLProject("'html verify'")
+	if err != nil {
+		return err
+	}
+	if p.Sealed == nil {
+		return fmt.Errorf("project must be sealed first (run 'rememory seal')")
+	}
+	shares, err := loadSealedShares(p)
+	if err != nil {
+		return err
+	}
+
+	outDir := htmlOutputFile
+	if outDir == "" {
+		outDir = filepath.Join(p.OutputPath(), "verify")
+	}
+	if err := os.MkdirAll(outDir, 0755); err != nil {
+		return fmt.Errorf("creating output directory: %w", err)
+	}
+
+	for i, f := range p.Friends {
+		files, err := bundle.FileChecksums(friendBundlePath(p, f))
+		if err != nil {
+			return fmt.Errorf("bundle for %s: %w", f.Name, err)
+		}
+		lang := f.Language
+		if lang == "" {
+			lang = p.Language
+		}
+		content := html.GenerateVerifyHTML(verifyWASM, version, &html.VerifyData{
+			Holder:        f.Name,
+			ShareIndex:    shares[i].Index,
+			Total:         shares[i].Total,
+			Sealed:        p.Sealed.At.Format("2006-01-02"),
+			Language:      lang,
+			ShareChecksum: shares[i].Checksum,
+			Files:         files,
+		})
+
+		path := filepath.Join(outDir, fmt.Sprintf("VERIFY-%s.html", core.SanitizeFilename(f.Name)))
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			return fmt.Errorf("writing file: %w", err)
+		}
+		fmt.Fprintf(os.Stderr, "Generated %s (%s)\n", path, formatSize(int64(len(content))))
+	}
+	return nil
+}
+
+// loadHTMLProject finds and loads the project in the current directory.
+// flag names the option that needs it, for the error message.
+func loadHTMLProject(flag string) (*project.Project, error) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+	dir, err := project.FindProjectDir(cwd)
+	if err != nil {
+		return nil, fmt.Errorf("%s needs a project: %w", flag, err)
+	}
+	return project.Load(dir)
+}
+
+// prefillFromProject converts project settings into maker.html prefill data.
+// Anonymous projects only carry the share count; their friend names are synthetic.
+func prefillFromProject(p *project.Project) *html.PrefillData {
+	prefill := &html.PrefillData{
+		ProjectName: p.Name,
+		Threshold:   p.Threshold,
+		Anonymous:   p.PrivacyLevel() == project.PrivacyAnonymous,
+		Privacy:     string(p.PrivacyLevel()),
+		Language:    p.Language,
+	}
+	if prefill.Anonymous {
+		prefill.NumShares = len(p.Friends)
+		return prefill
+	}
+	for _, f := range p.Friends {
+		prefill.Friends = append(prefill.Friends, html.PrefillFriend{
+			Name:     f.Name,
+			Contact:  f.Contact,
+			Language: f.Language,
+		})
+	}
+	return prefill
+}
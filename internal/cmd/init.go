@@ -8,7 +8,12 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/eljojo/rememory/internal/bundle"
+	"github.com/eljojo/rememory/internal/core"
+	"github.com/eljojo/rememory/internal/html"
+	"github.com/eljojo/rememory/internal/manifest"
 	"github.com/eljojo/rememory/internal/project"
+	"github.com/eljojo/rememory/internal/rotation"
 	"github.com/eljojo/rememory/internal/translations"
 	"github.com/spf13/cobra"
 )
@@ -35,17 +40,12 @@ var (
 	initThreshold int
 	initFriends   []string
 	initAnonymous bool
+	initPrivacy   string
+	initCrypto    string
 	initShares    int
 	initLanguage  string
 )
 
-const (
-	// MaxNameLength is the maximum allowed length for friend names
-	MaxNameLength = 200
-	// MaxContactLength is the maximum allowed length for contact info
-	MaxContactLength = 500
-)
-
 func init() {
 	rootCmd.AddCommand(initCmd)
 	initCmd.Flags().StringVar(&initFrom, "from", "", "Base new project on existing project (copies friends)")
@@ -53,8 +53,10 @@ func init() {
 	initCmd.Flags().IntVar(&initThreshold, "threshold", 0, "Number of shares needed to recover")
 	initCmd.Flags().StringArrayVar(&initFriends, "friend", nil, "Friend in format 'Name' or 'Name,contact info' (repeatable)")
 	initCmd.Flags().BoolVar(&initAnonymous, "anonymous", false, "Anonymous mode (no contact info for shareholders)")
+	initCmd.Flags().StringVar(&initPrivacy, "privacy", "", "What bundles tell about the friends: full, first-names, or anonymous")
 	initCmd.Flags().IntVar(&initShares, "shares", 0, "Number of shares (for anonymous mode)")
 	initCmd.Flags().StringVar(&initLanguage, "language", "", "Default bundle language (en, es, de, fr, sl)")
+	initCmd.Flags().StringVar(&initCrypto, "crypto", "", "Crypto profile: \"restricted\" allows only a fixed, documented set of primitives")
 }
 
 // validLanguage returns true if the given language code is supported.
@@ -72,6 +74,19 @@ func runInit(cmd *cobra.Command, args []string) error {
 	if initLanguage != "" && !validLanguage(initLanguage) {
 		return fmt.Errorf("unsupported language %q (supported: %s)", initLanguage, strings.Join(translations.Languages, ", "))
 	}
+	if err := core.ValidCryptoProfile(initCrypto); err != nil {
+		return err
+	}
+	privacy, err := project.ParsePrivacy(initPrivacy)
+	if err != nil {
+		return err
+	}
+	if initAnonymous {
+		if initPrivacy != "" && privacy != project.PrivacyAnonymous {
+			return fmt.Errorf("--anonymous contradicts --privacy %s", privacy)
+		}
+		privacy = project.PrivacyAnonymous
+	}
 
 	// Determine project directory from args
 	dirName := "recovery"
@@ -79,7 +94,7 @@ func runInit(cmd *cobra.Command, args []string) error {
 		dirName = args[0]
 	}
 
-	dir, err := filepath.Abs(dirName)
+	dir, err := manifest.AbsPath(dirName)
 	if err != nil {
 		return fmt.Errorf("resolving path: %w", err)
 	}
@@ -99,11 +114,12 @@ func runInit(cmd *cobra.Command, args []string) error {
 
 	var friends []project.Friend
 	var threshold int
-	var anonymous bool
+	var groups []project.Group
+	var contact string
+	var superseded []rotation.Generation
 
 	// Anonymous mode
-	if initAnonymous {
-		anonymous = true
+	if privacy == project.PrivacyAnonymous {
 		reader := bufio.NewReader(os.Stdin)
 
 		numShares := initShares
@@ -140,8 +156,8 @@ func runInit(cmd *cobra.Command, args []string) error {
 			}
 		}
 
-		if threshold < 2 || threshold > numShares {
-			return fmt.Errorf("invalid threshold: must be between 2 and %d", numShares)
+		if err := core.ValidateThreshold(threshold, numShares); err != nil {
+			return err
 		}
 
 		// Generate synthetic friends
@@ -151,6 +167,7 @@ func runInit(cmd *cobra.Command, args []string) error {
 		}
 
 		fmt.Printf("\nAnonymous mode: %d shares, threshold %d of %d\n\n", numShares, threshold, numShares)
+		printCapacity(numShares, threshold, 0, numShares)
 	} else if len(initFriends) > 0 {
 		// Non-interactive mode: use flags
 		friends, err = parseFriendFlags(initFriends)
@@ -166,14 +183,15 @@ func runInit(cmd *cobra.Command, args []string) error {
 			}
 		}
 
-		if threshold < 2 || threshold > len(friends) {
-			return fmt.Errorf("invalid threshold: must be between 2 and %d", len(friends))
+		if err := core.ValidateThreshold(threshold, len(friends)); err != nil {
+			return err
 		}
 
 		fmt.Printf("Friends: %s\n", friendNames(friends))
 		fmt.Printf("Threshold: %d of %d\n\n", threshold, len(friends))
+		printCapacity(len(friends), threshold, 0, len(friends))
 	} else if initFrom != "" {
-		fromDir, err := filepath.Abs(initFrom)
+		fromDir, err := manifest.AbsPath(initFrom)
 		if err != nil {
 			return fmt.Errorf("resolving --from path: %w", err)
 		}
@@ -185,9 +203,28 @@ func runInit(cmd *cobra.Command, args []string) error {
 
 		friends = existing.Friends
 		threshold = existing.Threshold
+		groups = existing.Groups
+		if initPrivacy == "" {
+			privacy = existing.PrivacyLevel()
+		}
+		contact = existing.Contact
+		superseded = existing.Superseded
+		if existing.Sealed != nil {
+			// The new project's bundles replace the old project's
+			superseded = rotation.Record(superseded, rotation.Generation{Sealed: existing.Sealed.At, Fingerprint: rotation.Fingerprint(existing.Sealed.ManifestChecksum)})
+		}
 		fmt.Printf("Copying configuration from: %s\n", initFrom)
 		fmt.Printf("  Friends: %s\n", friendNames(friends))
-		fmt.Printf("  Threshold: %d of %d\n\n", threshold, len(friends))
+		if len(groups) > 0 {
+			fmt.Printf("  Threshold: %d of %d groups\n", threshold, len(groups))
+		} else {
+			fmt.Printf("  Threshold: %d of %d\n", threshold, len(friends))
+		}
+		if len(superseded) > 0 {
+			fmt.Printf("  Replaces: %d earlier seal%s (listed in the new bundles)\n", len(superseded), plural(len(superseded)))
+		}
+		fmt.Println()
+		printCapacity(existing.TotalPieces(), threshold, len(groups), len(friends))
 	} else {
 		// Interactive prompts
 		reader := bufio.NewReader(os.Stdin)
@@ -223,6 +260,7 @@ func runInit(cmd *cobra.Command, args []string) error {
 		}
 
 		fmt.Println()
+		printCapacity(numFriends, threshold, 0, numFriends)
 
 		// Collect friend information
 		friends = make([]project.Friend, numFriends)
@@ -232,19 +270,16 @@ func runInit(cmd *cobra.Command, args []string) error {
 			fmt.Print("  Name: ")
 			nameStr, _ := reader.ReadString('\n')
 			nameStr = strings.TrimSpace(nameStr)
-			if nameStr == "" {
-				return fmt.Errorf("name is required")
-			}
-			if len(nameStr) > MaxNameLength {
-				return fmt.Errorf("name too long (max %d characters)", MaxNameLength)
+			if err := core.ValidateName(nameStr); err != nil {
+				return err
 			}
 			friends[i].Name = nameStr
 
 			fmt.Print("  Contact info (optional): ")
 			contactStr, _ := reader.ReadString('\n')
 			contactStr = strings.TrimSpace(contactStr)
-			if len(contactStr) > MaxContactLength {
-				return fmt.Errorf("contact info too long (max %d characters)", MaxContactLength)
+			if err := core.ValidateContact(contactStr); err != nil {
+				return err
 			}
 			friends[i].Contact = contactStr
 
@@ -253,16 +288,19 @@ func runInit(cmd *cobra.Command, args []string) error {
 	}
 
 	// Create the project
-	p, err := project.NewWithOptions(dir, name, threshold, friends, anonymous)
+	p, err := project.NewGrouped(dir, name, threshold, groups, friends, privacy)
 	if err != nil {
 		return fmt.Errorf("creating project: %w", err)
 	}
 
-	// Set project-level language if specified
-	if initLanguage != "" {
+	// Set project-level language, crypto profile, and rotation history if specified
+	if initLanguage != "" || initCrypto != "" || contact != "" || len(superseded) > 0 {
 		p.Language = initLanguage
+		p.Crypto = initCrypto
+		p.Contact = contact
+		p.Superseded = superseded
 		if err := p.Save(); err != nil {
-			return fmt.Errorf("saving project with language: %w", err)
+			return fmt.Errorf("saving project settings: %w", err)
 		}
 	}
 
@@ -276,15 +314,67 @@ func runInit(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("creating manifest README: %w", err)
 	}
 
+	logEvent(p, "init", "%d friends, %d needed", len(p.Friends), p.Threshold)
 	fmt.Printf("Created %s/\n", name)
 	fmt.Printf("  - project.yml (edit to update friends)\n")
 	fmt.Printf("  - manifest/README.md (add your secrets here)\n")
 	fmt.Println()
 	fmt.Println("Next: Add files to manifest/, then run `rememory seal`")
 
+	if jsonOutput {
+		return printJSON(initResult{
+			Project:   p.Name,
+			Path:      p.Path,
+			Manifest:  p.ManifestPath(),
+			Threshold: p.Threshold,
+			Anonymous: p.Anonymous,
+			Privacy:   string(p.PrivacyLevel()),
+			Language:  p.Language,
+			Friends:   jsonFriends(p.Friends),
+			Capacity:  core.EstimateCapacity(p.TotalPieces(), p.Threshold, len(p.Groups), ""),
+		})
+	}
 	return nil
 }
 
+type initResult struct {
+	Project   string        `json:"project"`
+	Path      string        `json:"path"`
+	Manifest  string        `json:"manifest"`
+	Threshold int           `json:"threshold"`
+	Anonymous bool          `json:"anonymous,omitempty"`
+	Privacy   string        `json:"privacy"`
+	Language  string        `json:"language,omitempty"`
+	Friends   []jsonFriend  `json:"friends"`
+	Capacity  core.Capacity `json:"capacity"`
+}
+
+// printCapacity shows how large each piece and each of the bundles will
+// come out for a split of pieces needing threshold, with anything that would
+// make them harder to use, while the numbers can still be changed.
+func printCapacity(pieces, threshold, groups, bundles int) {
+	c := core.EstimateCapacity(pieces, threshold, groups, "")
+	fmt.Println("Each piece will print as:")
+	if c.QRCodes > 1 {
+		fmt.Printf("  QR code:        %d codes, up to version %d (%d×%d modules)\n", c.QRCodes, c.QRVersion, c.QRModules, c.QRModules)
+	} else {
+		fmt.Printf("  QR code:        version %d (%d×%d modules), %d characters\n", c.QRVersion, c.QRModules, c.QRModules, c.QRContent)
+	}
+	fmt.Printf("  Share block:    %d characters\n", c.ShareBlock)
+	fmt.Printf("  RM2 string:     %d characters\n", c.Compact)
+	if c.Words > 0 {
+		fmt.Printf("  Recovery words: %d words, %d digit groups, %d-character rm1 string\n", c.Words, c.DigitGroups, c.Bech32)
+	}
+	if wasm := html.GetRecoverWASMBytes(); len(wasm) > 0 {
+		size := bundle.BaseSize(wasm)
+		fmt.Printf("  Bundles:        about %s each before your files, %s for all %d\n", formatSize(size), formatSize(size*int64(bundles)), bundles)
+	}
+	for _, w := range c.Warnings {
+		fmt.Printf("  %s %s\n", yellow("!"), w.Message)
+	}
+	fmt.Println()
+}
+
 func friendNames(friends []project.Friend) string {
 	names := make([]string, len(friends))
 	for i, f := range friends {
@@ -296,6 +386,7 @@ func friendNames(friends []project.Friend) string {
 // parseFriendFlags parses --friend flags in format "Name", "Name,contact", or "Name,contact,lang"
 func parseFriendFlags(flags []string) ([]project.Friend, error) {
 	friends := make([]project.Friend, len(flags))
+	holders := make([]core.Holder, len(flags))
 	for i, f := range flags {
 		parts := strings.SplitN(f, ",", 3)
 		name := strings.TrimSpace(parts[0])
@@ -317,15 +408,10 @@ func parseFriendFlags(flags []string) ([]project.Friend, error) {
 			Language: lang,
 		}
 
-		if friends[i].Name == "" {
-			return nil, fmt.Errorf("friend name cannot be empty")
-		}
-		if len(friends[i].Name) > MaxNameLength {
-			return nil, fmt.Errorf("friend name too long (max %d characters)", MaxNameLength)
-		}
-		if len(friends[i].Contact) > MaxContactLength {
-			return nil, fmt.Errorf("friend contact too long (max %d characters)", MaxContactLength)
-		}
+		holders[i] = core.Holder{Name: name, Contact: contact}
+	}
+	if err := core.ValidateHolders(holders); err != nil {
+		return nil, err
 	}
 	return friends, nil
 }
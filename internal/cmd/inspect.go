@@ -0,0 +1,378 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/eljojo/rememory/internal/bundle"
+	"github.com/eljojo/rememory/internal/core"
+	"github.com/eljojo/rememory/internal/rotation"
+	"github.com/spf13/cobra"
+)
+
+var inspectCmd = &cobra.Command{
+	Use:   "inspect <file-or-share>",
+	Short: "Show what a share, bundle, or manifest contains without recovering",
+	Long: `Inspect identifies a share, bundle, or encrypted manifest and prints its
+metadata. Nothing is combined or decrypted.
+
+Accepted inputs:
+  - SHARE-*.txt or README.txt (the PEM-style share block)
+  - A compact share string (RM2:...) or a recovery URL with #share=...
+  - The rm1... string printed under the QR code
+  - The 25 recovery words, quoted as a single argument
+  - A bundle ZIP (bundle-*.zip)
+  - MANIFEST.age
+
+This is useful for identifying a piece someone sent you — for example, a
+photo of their printed page — before you start a recovery.
+
+With --bundle, the share is also checked against the share checksums and
+the Feldman commitments that every bundle records for its seal, which
+tells you whether the piece was handed out together with that bundle and
+lies on the polynomial it committed to, without needing any other piece.
+
+Examples:
+  rememory inspect SHARE-alice.txt
+  rememory inspect bundle-bob.zip
+  rememory inspect MANIFEST.age
+  rememory inspect "RM2:3:5:3:...:a1b2"
+  rememory inspect --bundle bundle-bob.zip "RM2:3:5:3:...:a1b2"`,
+	Args: cobra.ExactArgs(1),
+	RunE: runInspect,
+}
+
+func init() {
+	inspectCmd.Flags().String("bundle", "", "Check the share against the seal of this bundle ZIP")
+	rootCmd.AddCommand(inspectCmd)
+}
+
+// sealRecord is the bundle a share is checked against with --bundle.
+type sealRecord struct {
+	name        string
+	fingerprint string
+	commitments bundle.Commitments
+	vss         core.VSSCommitments
+}
+
+func runInspect(cmd *cobra.Command, args []string) error {
+	input := args[0]
+
+	var against *sealRecord
+	if bundlePath, _ := cmd.Flags().GetString("bundle"); bundlePath != "" {
+		info, err := bundle.ReadInfo(bundlePath)
+		if err != nil {
+			return err
+		}
+		vss, err := bundle.VSSFromMetadata(info.Metadata)
+		if err != nil {
+			return err
+		}
+		against = &sealRecord{
+			name:        filepath.Base(bundlePath),
+			fingerprint: rotation.Fingerprint(info.Metadata["checksum-manifest"]),
+			commitments: bundle.CommitmentsFromMetadata(info.Metadata),
+			vss:         vss,
+		}
+	}
+
+	info, err := os.Stat(input)
+	if err != nil || info.IsDir() {
+		// Not a file: treat the argument itself as a share string
+		return inspectShareString(input, against)
+	}
+
+	content, err := os.ReadFile(input)
+	if err != nil {
+		return fmt.Errorf("reading %s: %w", input, err)
+	}
+
+	switch {
+	case bytes.HasPrefix(content, []byte("PK\x03\x04")):
+		return inspectBundle(input, against)
+	case bytes.HasPrefix(content, []byte("age-encryption.org/")):
+		return inspectManifest(input, content)
+	case bytes.Contains(content, []byte(core.ShareBegin)):
+		return inspectShareFile(input, content, against)
+	default:
+		return inspectShareString(string(content), against)
+	}
+}
+
+// inspectShareFile prints metadata for a SHARE-*.txt or README.txt file.
+func inspectShareFile(path string, content []byte, against *sealRecord) error {
+	share, err := core.ParseShare(content)
+	if err != nil {
+		return fmt.Errorf("parsing share: %w", err)
+	}
+
+	kind := "Share file"
+	metadata := bundle.ParseMetadataFooter(string(content))
+	if len(metadata) > 0 {
+		kind = "README with share"
+	}
+
+	fmt.Printf("%s: %s\n\n", kind, filepath.Base(path))
+	printShareInfo(share, shareChecksumStatus(share))
+	printSealMatch(share.Data, against)
+	printVSSMatch(share, against)
+
+	if len(metadata) > 0 {
+		fmt.Println()
+		printBundleMetadata(metadata)
+	}
+	return nil
+}
+
+// inspectShareString prints metadata for a compact share, recovery URL, rm1
+// string, or word list.
+func inspectShareString(input string, against *sealRecord) error {
+	input = strings.TrimSpace(input)
+
+	if compact, ok := compactFromURL(input); ok {
+		input = compact
+	}
+
+	if core.IsBech32Share(input) {
+		share, err := core.ParseBech32Share(input)
+		if err != nil {
+			return err
+		}
+		fmt.Println("Bech32 share")
+		fmt.Println()
+		printShareInfo(share, green("OK")+" (bech32 checksum matched)")
+		printSealMatch(share.Data, against)
+		printVSSMatch(share, against)
+		return nil
+	}
+
+	if strings.HasPrefix(input, "RM") {
+		share, err := core.ParseCompact(input)
+		if err != nil {
+			return err
+		}
+		fmt.Println("Compact share")
+		fmt.Println()
+		printShareInfo(share, green("OK")+" (short check matched)")
+		printSealMatch(share.Data, against)
+		printVSSMatch(share, against)
+		return nil
+	}
+
+	words := strings.Fields(input)
+	if core.IsShareWordCount(len(words)) {
+		data, index, lang, err := core.DecodeShareWordsAuto(words)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Recovery words (%s)\n\n", lang)
+		fmt.Printf("  Version:    2\n")
+		if index > 0 {
+			fmt.Printf("  Index:      %d\n", index)
+		} else {
+			fmt.Printf("  Index:      unknown (share numbers above 15 aren't stored in the words)\n")
+		}
+		fmt.Printf("  Data:       %d bytes\n", len(data))
+		fmt.Printf("  Checksum:   %s (word checksum matched)\n", green("OK"))
+		printSealMatch(data, against)
+		return nil
+	}
+
+	return fmt.Errorf("not a file, compact share (RM...), rm1 string, or 25 recovery words")
+}
+
+// inspectBundle prints metadata for a bundle ZIP and checks its integrity.
+func inspectBundle(path string, against *sealRecord) error {
+	info, err := bundle.ReadInfo(path)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Bundle: %s\n\n", filepath.Base(path))
+	printShareInfo(info.Share, shareChecksumStatus(info.Share))
+	printSealMatch(info.Share.Data, against)
+	printVSSMatch(info.Share, against)
+	fmt.Println()
+	printBundleMetadata(info.Metadata)
+
+	fmt.Println()
+	fmt.Println("  Files:")
+	for _, name := range info.Files {
+		fmt.Printf("    - %s\n", name)
+	}
+	switch {
+	case info.ManifestEmbedded:
+		fmt.Printf("  Contents:   embedded in recover.html (%s)\n", formatSize(int64(len(info.Manifest))))
+	case info.ManifestVolumes > 0:
+		fmt.Printf("  Contents:   MANIFEST.age in %d volumes (%s)\n", info.ManifestVolumes, formatSize(int64(len(info.Manifest))))
+	case len(info.Manifest) > 0:
+		fmt.Printf("  Contents:   MANIFEST.age (%s)\n", formatSize(int64(len(info.Manifest))))
+	default:
+		fmt.Printf("  Contents:   %s\n", yellow("not found"))
+	}
+	if h := info.Parity; h != nil {
+		switch {
+		case h.Checksum != core.HashBytes(info.Manifest):
+			fmt.Printf("  Parity:     %d%% %s (for a different MANIFEST.age)\n", h.Percent, red("FAILED"))
+		case info.ParityDamaged > 0:
+			fmt.Printf("  Parity:     %d%% %s (%d of its blocks are damaged)\n", h.Percent, yellow("DAMAGED"), info.ParityDamaged)
+		default:
+			fmt.Printf("  Parity:     %d%% %s\n", h.Percent, green("OK"))
+		}
+	}
+
+	switch {
+	case info.RotationErr != nil:
+		fmt.Printf("  Replaces:   %s (%v)\n", red("FAILED"), info.RotationErr)
+	case info.Rotation != nil:
+		fmt.Printf("  Replaces:   %d earlier seal%s (%s signature %s)\n", len(info.Rotation.Superseded), plural(len(info.Rotation.Superseded)), rotation.FileName, green("OK"))
+		for _, g := range info.Rotation.Superseded {
+			fmt.Printf("    - sealed %s, fingerprint %s\n", g.Sealed.Format(core.DateFormat), g.Fingerprint)
+		}
+		if info.Rotation.Contact != "" {
+			fmt.Printf("  Contact:    %s\n", info.Rotation.Contact)
+		}
+	}
+
+	if url := info.Metadata["beacon-url"]; url != "" {
+		fmt.Printf("  Beacon:     %s ('rememory beacon check' tells whether this bundle is current)\n", url)
+	}
+
+	if err := bundle.VerifyBundle(path); err != nil {
+		fmt.Printf("  Integrity:  %s (%v)\n", red("FAILED"), err)
+	} else {
+		fmt.Printf("  Integrity:  %s\n", green("OK"))
+	}
+	return nil
+}
+
+// inspectManifest prints the unencrypted header details of MANIFEST.age.
+func inspectManifest(path string, content []byte) error {
+	info, err := core.InspectManifest(bytes.NewReader(content))
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Encrypted manifest: %s\n\n", filepath.Base(path))
+	fmt.Printf("  Format:     age (%s)\n", strings.Join(info.Recipients, ", "))
+	if info.WorkFactor > 0 {
+		fmt.Printf("  Work:       scrypt 2^%d, %s of memory to unlock\n", info.WorkFactor, formatSize(core.ScryptMemory(info.WorkFactor)))
+	}
+	if len(info.Recipients) == 1 && info.Recipients[0] == core.PQStanzaType {
+		fmt.Printf("  Hybrid:     scrypt + ML-KEM-768 (post-quantum)\n")
+	}
+	fmt.Printf("  Size:       %s\n", formatSize(int64(len(content))))
+	fmt.Printf("  Checksum:   %s\n", core.HashBytes(content))
+	return nil
+}
+
+func printShareInfo(share *core.Share, checksumStatus string) {
+	fmt.Printf("  Version:    %d\n", share.Version)
+	fmt.Printf("  Index:      %d of %d\n", share.Index, share.Total)
+	fmt.Printf("  Threshold:  %d\n", share.Threshold)
+	if share.Holder != "" {
+		fmt.Printf("  Holder:     %s\n", share.Holder)
+	}
+	if share.WordList != "" {
+		fmt.Printf("  Words:      %s\n", share.WordList)
+	}
+	if share.BundleID != "" {
+		fmt.Printf("  Bundle:     %s (%s)\n", core.BundleFingerprint(share.BundleID), share.BundleID)
+	} else if named := core.DataBundle(share.Data); named != "" {
+		fmt.Printf("  Bundle:     %s (named in the piece's data)\n", named)
+	}
+	if share.CatalogThreshold > 0 {
+		fmt.Printf("  Catalog:    opens with %d pieces ('rememory catalog')\n", share.CatalogThreshold)
+	}
+	if !share.Created.IsZero() {
+		fmt.Printf("  Created:    %s\n", share.Created.Format("2006-01-02 15:04 UTC"))
+	}
+	if !share.ReviewBy.IsZero() {
+		fmt.Printf("  Review by:  %s\n", share.ReviewBy.Format(core.DateFormat))
+	}
+	if !share.Expires.IsZero() {
+		fmt.Printf("  Expires:    %s\n", share.Expires.Format(core.DateFormat))
+	}
+	fmt.Printf("  Checksum:   %s\n", checksumStatus)
+	if share.Repaired > 0 {
+		fmt.Printf("  Repaired:   %d character%s of the printed data\n", share.Repaired, plural(share.Repaired))
+	}
+	if typed, err := share.Bech32(); err == nil {
+		fmt.Printf("  Typed form: %s\n", typed)
+	}
+}
+
+// printSealMatch says whether the share data is one of the shares recorded
+// in the bundle given with --bundle. It prints nothing without --bundle.
+func printSealMatch(data []byte, against *sealRecord) {
+	switch {
+	case against == nil:
+	case len(against.commitments) == 0:
+		fmt.Printf("  Seal:       %s (%s was made before bundles recorded their shares)\n", yellow("unknown"), against.name)
+	default:
+		if index := against.commitments.Match(data); index > 0 {
+			fmt.Printf("  Seal:       %s consistent with %s (piece %d, seal %s)\n", green("OK"), against.name, index, against.fingerprint)
+		} else {
+			fmt.Printf("  Seal:       %s not a piece of the seal %s belongs to\n", red("MISMATCH"), against.name)
+		}
+	}
+}
+
+// printVSSMatch says whether the share's value in the verifiable split lies
+// on the polynomial the bundle given with --bundle commits to. It prints
+// nothing without --bundle, or when that bundle's seal has no such split.
+func printVSSMatch(share *core.Share, against *sealRecord) {
+	switch {
+	case against == nil || len(against.vss) == 0:
+	case len(share.VSS) == 0:
+		fmt.Printf("  VSS:        %s (this form of the piece carries no VSS value)\n", yellow("unchecked"))
+	default:
+		if err := against.vss.Verify(share.Index, share.VSS); err != nil {
+			fmt.Printf("  VSS:        %s %v\n", red("MISMATCH"), err)
+		} else {
+			fmt.Printf("  VSS:        %s consistent with %s (Feldman commitments, seal %s)\n", green("OK"), against.name, against.fingerprint)
+		}
+	}
+}
+
+func printBundleMetadata(metadata map[string]string) {
+	fields := [][2]string{
+		{"project", "Project:"},
+		{"rememory-version", "Made with:"},
+		{"created", "Sealed:"},
+		{"checksum-manifest", "Manifest:"},
+	}
+	for _, f := range fields {
+		if v := metadata[f[0]]; v != "" {
+			fmt.Printf("  %-11s %s\n", f[1], v)
+		}
+	}
+}
+
+func shareChecksumStatus(share *core.Share) string {
+	if share.Checksum == "" {
+		return yellow("not present")
+	}
+	if err := share.Verify(); err != nil {
+		return red("MISMATCH")
+	}
+	return green("OK")
+}
+
+// compactFromURL extracts the compact share from a recovery URL such as
+// https://example.com/recover.html#share=RM2%3A...
+func compactFromURL(s string) (string, bool) {
+	idx := strings.Index(s, "#share=")
+	if idx == -1 {
+		return "", false
+	}
+	compact, err := url.QueryUnescape(s[idx+len("#share="):])
+	if err != nil {
+		return "", false
+	}
+	return compact, true
+}
@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/eljojo/rememory/internal/project"
+	"github.com/spf13/cobra"
+)
+
+var logCmd = &cobra.Command{
+	Use:   "log",
+	Short: "Show what was done to the project, and when",
+	Long: `Every command that changes a project adds a line to events.jsonl in the
+project directory: init, seal, bundle, friend add and remove, notify,
+print, and undo. Log shows them, oldest first, with who ran each one.
+
+Lines are only ever added. Undo puts project.yml back but keeps the log,
+and adds a line of its own.
+
+Example:
+  rememory log
+  rememory log -n 5`,
+	Args: cobra.NoArgs,
+	RunE: runLog,
+}
+
+func init() {
+	logCmd.Flags().IntP("number", "n", 0, "Show only the last n events")
+	rootCmd.AddCommand(logCmd)
+}
+
+func runLog(cmd *cobra.Command, args []string) error {
+	number, _ := cmd.Flags().GetInt("number")
+
+	p, err := loadFriendProject()
+	if err != nil {
+		return err
+	}
+	events, err := p.Events()
+	if err != nil {
+		return err
+	}
+	if number > 0 && number < len(events) {
+		events = events[len(events)-number:]
+	}
+
+	if jsonOutput {
+		if events == nil {
+			events = []project.Event{}
+		}
+		return printJSON(events)
+	}
+	if len(events) == 0 {
+		fmt.Println("Nothing logged yet.")
+		return nil
+	}
+	for _, e := range events {
+		fmt.Printf("%s  %-14s %s", e.At.Local().Format("2006-01-02 15:04"), e.Command, e.Detail)
+		if e.Who != "" {
+			fmt.Printf("  (%s)", e.Who)
+		}
+		fmt.Println()
+	}
+	return nil
+}
+
+// logEvent records command in the project's event log. The change it
+// describes is already made, so failing to log it is only warned about.
+func logEvent(p *project.Project, command, format string, args ...any) {
+	if err := p.LogEvent(command, fmt.Sprintf(format, args...)); err != nil {
+		fmt.Fprintf(os.Stderr, "%s %v\n", yellow("Warning:"), err)
+	}
+}
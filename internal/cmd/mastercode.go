@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/eljojo/rememory/internal/core"
+	"github.com/eljojo/rememory/internal/pdf"
+	"github.com/eljojo/rememory/internal/project"
+)
+
+// makeMasterCode makes a master code for p and puts its public key in
+// master_code, where every seal from now on finds it. The code itself is
+// only printed, by writeMasterCodeSheet.
+func makeMasterCode(p *project.Project) (core.Secret, error) {
+	code, err := core.GenerateMasterCode()
+	if err != nil {
+		return nil, err
+	}
+	key, err := core.MasterCodeRecipient(code, p.PostQuantum)
+	if err != nil {
+		code.Wipe()
+		return nil, err
+	}
+	p.MasterCode = key
+	return code, nil
+}
+
+// writeMasterCodeSheet writes MASTER-CODE.pdf for code, for the owner to
+// print and then delete.
+func writeMasterCodeSheet(p *project.Project, code core.Secret) error {
+	sheet, err := pdf.GenerateMasterCodeSheet(pdf.MasterCodeData{
+		ProjectName:      p.Name,
+		Key:              core.MasterCodeKey(code, p.PostQuantum),
+		Words:            core.MasterCodeWords(code),
+		Recipient:        p.MasterCode,
+		Version:          version,
+		GitHubReleaseURL: fmt.Sprintf("https://github.com/eljojo/rememory/releases/tag/%s", version),
+		Created:          core.Now(),
+	})
+	if err != nil {
+		return fmt.Errorf("generating %s: %w", project.MasterCodeFile, err)
+	}
+	if err := os.WriteFile(p.MasterCodePath(), sheet, 0600); err != nil {
+		return fmt.Errorf("writing %s (seal again with --new-master-code): %w", project.MasterCodeFile, err)
+	}
+	return nil
+}
+
+// unlockMasterCode asks for the master code and decrypts the RECIPIENTS.age
+// at path with it.
+func unlockMasterCode(path string) (core.Secret, error) {
+	data, err := readRecipientsFile(path)
+	if err != nil {
+		return nil, err
+	}
+	text, err := readPassword(fmt.Sprintf("Master code (the %d words, or the key its QR code holds)", core.MasterCodeWordCount))
+	if err != nil {
+		return nil, err
+	}
+	identities, err := core.ParseMasterCode(text)
+	if err != nil {
+		return nil, err
+	}
+	passphrase, err := core.DecryptWithIdentities(data, identities)
+	if err != nil {
+		return nil, fmt.Errorf("this master code doesn't unlock %s (it may be from another project, or replaced since)", core.RecipientsFile)
+	}
+	return passphrase, nil
+}
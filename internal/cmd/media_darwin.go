@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// mediumID asks diskutil about the volume folder is on, for its UUID: macOS
+// doesn't say what a USB drive's serial number is.
+func mediumID(folder string) (serial, label string, err error) {
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(folder, &st); err != nil {
+		return "", "", err
+	}
+	mount := make([]byte, 0, len(st.Mntonname))
+	for _, c := range st.Mntonname {
+		if c == 0 {
+			break
+		}
+		mount = append(mount, byte(c))
+	}
+	info, err := diskInfo(string(mount))
+	if err != nil {
+		return "", "", err
+	}
+	serial = info["Volume UUID"]
+	if serial == "" {
+		serial = info["Disk / Partition UUID"]
+	}
+	if serial == "" {
+		return "", "", fmt.Errorf("diskutil doesn't give %s a UUID", mount)
+	}
+	return serial, info["Volume Name"], nil
+}
+
+// dropCache has reads of f bypass what the system keeps in memory, so they
+// go to the drive.
+func dropCache(f *os.File) {
+	unix.FcntlInt(f.Fd(), unix.F_NOCACHE, 1)
+}
@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"golang.org/x/sys/unix"
+)
+
+// mediumID asks findmnt which device folder is on, and lsblk for the serial
+// number of its drive, or for the filesystem's UUID when the drive has none.
+func mediumID(folder string) (serial, label string, err error) {
+	out, err := runDriveTool("", "findmnt", "-nvo", "SOURCE", "-T", folder)
+	if err != nil {
+		return "", "", err
+	}
+	device := strings.TrimSpace(out)
+	if !strings.HasPrefix(device, "/dev/") {
+		return "", "", fmt.Errorf("it's on %s, not on a drive", device)
+	}
+	fields, err := lsblkPairs(device, "SERIAL,UUID,LABEL,PKNAME")
+	if err != nil {
+		return "", "", err
+	}
+	serial = fields["SERIAL"]
+	if serial == "" && fields["PKNAME"] != "" {
+		disk, err := lsblkPairs(fields["PKNAME"], "SERIAL")
+		if err != nil {
+			return "", "", err
+		}
+		serial = disk["SERIAL"]
+	}
+	if serial == "" {
+		serial = fields["UUID"]
+	}
+	if serial == "" {
+		return "", "", fmt.Errorf("%s has no serial number or UUID", device)
+	}
+	return serial, fields["LABEL"], nil
+}
+
+// lsblkPair is one NAME="value" of 'lsblk -P', where the value has any
+// quote or unusual character written as \xNN.
+var lsblkPair = regexp.MustCompile(`([A-Z:-]+)="([^"]*)"`)
+
+// lsblkPairs asks lsblk for columns of device.
+func lsblkPairs(device, columns string) (map[string]string, error) {
+	out, err := runDriveTool("", "lsblk", "-ndpPo", columns, device)
+	if err != nil {
+		return nil, err
+	}
+	fields := map[string]string{}
+	for _, m := range lsblkPair.FindAllStringSubmatch(out, -1) {
+		value, err := strconv.Unquote(`"` + m[2] + `"`)
+		if err != nil {
+			value = m[2]
+		}
+		fields[m[1]] = strings.TrimSpace(value)
+	}
+	return fields, nil
+}
+
+// dropCache has the system forget what it kept in memory of f, so reading
+// it goes to the drive. Written files have been synced, so nothing is lost.
+func dropCache(f *os.File) {
+	unix.Fadvise(int(f.Fd()), 0, 0, unix.FADV_DONTNEED)
+}
@@ -0,0 +1,14 @@
+//go:build !linux && !darwin && !windows
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+)
+
+func mediumID(folder string) (serial, label string, err error) {
+	return "", "", fmt.Errorf("telling drives apart isn't supported on this system")
+}
+
+func dropCache(f *os.File) {}
@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"syscall"
+	"unsafe"
+)
+
+var (
+	procGetVolumePathName    = syscall.NewLazyDLL("kernel32.dll").NewProc("GetVolumePathNameW")
+	procGetVolumeInformation = syscall.NewLazyDLL("kernel32.dll").NewProc("GetVolumeInformationW")
+)
+
+// mediumID asks Windows for the serial number and label of the volume
+// folder is on. Formatting gives a volume a new serial number.
+func mediumID(folder string) (serial, label string, err error) {
+	abs, err := filepath.Abs(folder)
+	if err != nil {
+		return "", "", err
+	}
+	path, err := syscall.UTF16PtrFromString(abs)
+	if err != nil {
+		return "", "", err
+	}
+	root := make([]uint16, syscall.MAX_PATH+1)
+	if r, _, err := procGetVolumePathName.Call(uintptr(unsafe.Pointer(path)), uintptr(unsafe.Pointer(&root[0])), uintptr(len(root))); r == 0 {
+		return "", "", err
+	}
+	name := make([]uint16, syscall.MAX_PATH+1)
+	var number uint32
+	if r, _, err := procGetVolumeInformation.Call(uintptr(unsafe.Pointer(&root[0])), uintptr(unsafe.Pointer(&name[0])), uintptr(len(name)),
+		uintptr(unsafe.Pointer(&number)), 0, 0, 0, 0); r == 0 {
+		return "", "", err
+	}
+	return fmt.Sprintf("%04X-%04X", number>>16, number&0xffff), syscall.UTF16ToString(name), nil
+}
+
+// dropCache does nothing on Windows, where reading around the cache needs
+// reads aligned to the drive's sectors.
+func dropCache(f *os.File) {}
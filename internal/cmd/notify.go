@@ -0,0 +1,342 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/eljojo/rememory/internal/notify"
+	"github.com/eljojo/rememory/internal/project"
+	"github.com/spf13/cobra"
+)
+
+var notifyCmd = &cobra.Command{
+	Use:   "notify [friend]...",
+	Short: "Ask friends by email whether they still have their bundle",
+	Long: `Notify sends each friend a short check-in message asking whether they
+still have their bundle, to the email address in their contact info.
+Paper gets thrown out and USB drives get lost without anyone noticing;
+a yearly reminder catches that while there's still time to hand out a new
+copy.
+
+Messages go through the SMTP server in the notify section of project.yml.
+The password is read from REMEMORY_SMTP_PASSWORD, or asked for. Each sent
+reminder is recorded in project.yml. When a friend answers, record it with
+'rememory notify record', and see who hasn't answered with
+'rememory notify status'.
+
+Without arguments every friend with an email address gets a message.
+Friends without one are listed so you can reach them another way.
+
+Examples:
+  rememory notify --dry-run
+  rememory notify
+  rememory notify Alice Bob
+  rememory notify record Alice "still has it, in the safe"
+  rememory notify status
+  rememory notify calendar rememory.ics`,
+	RunE: runNotify,
+}
+
+var notifyRecordCmd = &cobra.Command{
+	Use:   "record <friend> <response>",
+	Short: "Record a friend's answer to a reminder",
+	Args:  cobra.ExactArgs(2),
+	RunE:  runNotifyRecord,
+}
+
+var notifyStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show when each friend was last reminded and what they said",
+	Args:  cobra.NoArgs,
+	RunE:  runNotifyStatus,
+}
+
+var notifyCalendarCmd = &cobra.Command{
+	Use:   "calendar [file]",
+	Short: "Write a calendar of when copies on drives are due to be written again",
+	Long: `Calendar writes an iCalendar (.ics) file with a reminder for each copy of
+a bundle on a USB drive, SD card, or disc recorded by 'rememory usb prepare'
+or 'rememory verify-media', on the day it's due to be written again. Import
+it into your calendar app; importing a newer one updates the reminders
+instead of adding them twice.
+
+How long a copy is trusted depends on the type of medium: 3 years for flash,
+5 for hard drives, 10 for discs, and 25 for M-DISC. Change it in project.yml:
+
+  media_refresh:
+    usb: 2
+    disc: 15
+
+Without a file it writes rememory.ics in the project; "-" writes it to the
+standard output.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runNotifyCalendar,
+}
+
+func init() {
+	notifyCmd.Flags().Bool("dry-run", false, "Show the messages without sending or recording anything")
+	notifyCmd.Flags().BoolP("yes", "y", false, "Send without asking for confirmation")
+	notifyCmd.AddCommand(notifyRecordCmd)
+	notifyCmd.AddCommand(notifyStatusCmd)
+	notifyCmd.AddCommand(notifyCalendarCmd)
+	rootCmd.AddCommand(notifyCmd)
+}
+
+func runNotify(cmd *cobra.Command, args []string) error {
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	yes, _ := cmd.Flags().GetBool("yes")
+
+	p, err := loadFriendProject()
+	if err != nil {
+		return err
+	}
+	if p.Sealed == nil {
+		return fmt.Errorf("project must be sealed first (run 'rememory seal')")
+	}
+	cfg := p.Notify
+	if cfg == nil {
+		cfg = &project.NotifyConfig{}
+	}
+	if cfg.SMTP == nil && !dryRun {
+		return fmt.Errorf("no mail server configured; add a notify.smtp section to project.yml (see 'rememory notify --help')")
+	}
+
+	var tmpl *notify.Template
+	if cfg.Template != "" {
+		if tmpl, err = notify.LoadTemplate(p.ResolvePath(cfg.Template)); err != nil {
+			return err
+		}
+	}
+
+	friends, err := selectFriends(p, args)
+	if err != nil {
+		return err
+	}
+	shares, err := loadSealedShares(p)
+	if err != nil {
+		return err
+	}
+
+	type reminder struct {
+		friend int // Index into p.Friends
+		msg    *notify.Message
+	}
+	var reminders []reminder
+	var unreachable []string
+	for i, f := range p.Friends {
+		if !friends[f.Name] {
+			continue
+		}
+		to, ok := notify.EmailAddress(f.Contact)
+		if !ok {
+			unreachable = append(unreachable, f.Name)
+			continue
+		}
+		lang := f.Language
+		if lang == "" {
+			lang = p.Language
+		}
+		msg, err := notify.Compose(to, notify.Fields{
+			Holder:      f.Name,
+			ProjectName: p.Name,
+			Piece:       shares[i].Index,
+			Total:       shares[i].Total,
+			Threshold:   p.Threshold,
+			Sealed:      p.Sealed.At,
+			Language:    lang,
+		}, cfg.Subject, tmpl)
+		if err != nil {
+			return fmt.Errorf("%s: %w", f.Name, err)
+		}
+		reminders = append(reminders, reminder{friend: i, msg: msg})
+	}
+
+	if dryRun {
+		for _, r := range reminders {
+			fmt.Printf("To: %s <%s>\nSubject: %s\n\n%s\n\n", p.Friends[r.friend].Name, r.msg.To, r.msg.Subject, r.msg.Body)
+		}
+	} else {
+		for _, r := range reminders {
+			fmt.Printf("  %s — %s\n", p.Friends[r.friend].Name, r.msg.To)
+		}
+	}
+	for _, name := range unreachable {
+		fmt.Printf("%s %s: no email address in their contact info; reach them another way\n", yellow("!"), name)
+	}
+	if len(reminders) == 0 {
+		fmt.Println("Nobody to send a reminder to.")
+		return nil
+	}
+	if dryRun {
+		return nil
+	}
+
+	if !yes {
+		fmt.Printf("Send %d reminder%s through %s? [y/N]: ", len(reminders), plural(len(reminders)), cfg.SMTP.Host)
+		answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+		if a := strings.ToLower(strings.TrimSpace(answer)); a != "y" && a != "yes" {
+			fmt.Println("Nothing sent.")
+			return nil
+		}
+	}
+
+	password := os.Getenv("REMEMORY_SMTP_PASSWORD")
+	if password == "" && cfg.SMTP.Username != "" {
+		if password, err = readPassword(fmt.Sprintf("Password for %s", cfg.SMTP.Username)); err != nil {
+			return err
+		}
+	}
+	sender := &notify.Sender{Config: *cfg.SMTP, Password: password}
+
+	if err := snapshot(p, "notify"); err != nil {
+		return err
+	}
+	var sendErr error
+	var sent []string
+	for _, r := range reminders {
+		f := &p.Friends[r.friend]
+		if err := sender.Send(r.msg); err != nil {
+			sendErr = fmt.Errorf("sending to %s: %w", f.Name, err)
+			break
+		}
+		f.CheckIns = append(f.CheckIns, project.CheckIn{Sent: time.Now().UTC()})
+		sent = append(sent, f.Name)
+		fmt.Printf("%s Sent to %s\n", green("✓"), f.Name)
+	}
+
+	// Record what was sent even if a later message failed
+	if len(sent) > 0 {
+		if err := p.Save(); err != nil {
+			return fmt.Errorf("recording reminders: %w", err)
+		}
+		logEvent(p, "notify", "reminded %s", strings.Join(sent, ", "))
+	}
+	if sendErr != nil {
+		return sendErr
+	}
+
+	fmt.Println()
+	fmt.Println("When friends answer, record it with 'rememory notify record <friend> <response>'.")
+	return nil
+}
+
+func runNotifyRecord(cmd *cobra.Command, args []string) error {
+	p, err := loadFriendProject()
+	if err != nil {
+		return err
+	}
+	selected, err := selectFriends(p, args[:1])
+	if err != nil {
+		return err
+	}
+	response := strings.TrimSpace(args[1])
+	if response == "" {
+		return fmt.Errorf("response cannot be empty")
+	}
+	if err := snapshot(p, "notify-record"); err != nil {
+		return err
+	}
+
+	for i := range p.Friends {
+		f := &p.Friends[i]
+		if !selected[f.Name] {
+			continue
+		}
+		recordAnswer(f, response, time.Now().UTC())
+		if err := p.Save(); err != nil {
+			return err
+		}
+		logEvent(p, "notify-record", "%s answered %q", f.Name, response)
+		fmt.Printf("%s Recorded %s's answer\n", green("✓"), f.Name)
+	}
+	return nil
+}
+
+func runNotifyCalendar(cmd *cobra.Command, args []string) error {
+	p, err := loadFriendProject()
+	if err != nil {
+		return err
+	}
+	events := notify.MediaEvents(p)
+	if len(events) == 0 {
+		return fmt.Errorf("no copies on drives or discs recorded yet (see 'rememory verify-media --help')")
+	}
+	data := notify.Calendar("ReMemory: "+p.Name, events, time.Now())
+
+	path := filepath.Join(p.Path, "rememory.ics")
+	if len(args) == 1 {
+		path = args[0]
+	}
+	if path == "-" {
+		_, err := os.Stdout.Write(data)
+		return err
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("writing calendar: %w", err)
+	}
+	fmt.Printf("%s Wrote %d reminder%s to %s\n", green("✓"), len(events), plural(len(events)), path)
+	return nil
+}
+
+// recordAnswer attaches a response to the friend's latest unanswered
+// reminder, or records it on its own if there is none.
+func recordAnswer(f *project.Friend, response string, at time.Time) {
+	if last := f.LastCheckIn(); last != nil && !last.Sent.IsZero() && last.Answered.IsZero() {
+		last.Answered = at
+		last.Response = response
+		return
+	}
+	f.CheckIns = append(f.CheckIns, project.CheckIn{Answered: at, Response: response})
+}
+
+// notifyStatus is one friend's line in 'rememory notify status --json'.
+type notifyStatus struct {
+	Friend   string     `json:"friend"`
+	Sent     *time.Time `json:"sent,omitempty"`
+	Answered *time.Time `json:"answered,omitempty"`
+	Response string     `json:"response,omitempty"`
+}
+
+func runNotifyStatus(cmd *cobra.Command, args []string) error {
+	p, err := loadFriendProject()
+	if err != nil {
+		return err
+	}
+
+	if jsonOutput {
+		result := make([]notifyStatus, 0, len(p.Friends))
+		for i := range p.Friends {
+			s := notifyStatus{Friend: p.Friends[i].Name}
+			if last := p.Friends[i].LastCheckIn(); last != nil {
+				if !last.Sent.IsZero() {
+					s.Sent = &last.Sent
+				}
+				if !last.Answered.IsZero() {
+					s.Answered = &last.Answered
+				}
+				s.Response = last.Response
+			}
+			result = append(result, s)
+		}
+		return printJSON(result)
+	}
+
+	const day = "2006-01-02"
+	for i := range p.Friends {
+		f := &p.Friends[i]
+		last := f.LastCheckIn()
+		switch {
+		case last == nil:
+			fmt.Printf("  %s %s: never reminded\n", yellow("?"), f.Name)
+		case last.Answered.IsZero():
+			fmt.Printf("  %s %s: reminded %s, no answer yet\n", yellow("…"), f.Name, last.Sent.Format(day))
+		default:
+			fmt.Printf("  %s %s: answered %s — %q\n", green("✓"), f.Name, last.Answered.Format(day), last.Response)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+package cmd
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/eljojo/rememory/internal/core"
+	"github.com/eljojo/rememory/internal/project"
+)
+
+// jsonOutput is set by the global --json flag. Commands that support it print
+// one JSON document on stdout; their usual progress text moves to stderr.
+var jsonOutput bool
+
+var (
+	// jsonWriter is the real stdout, saved before os.Stdout is pointed at stderr.
+	jsonWriter io.Writer = os.Stdout
+	// jsonWritten records that a result was printed, so errors aren't printed twice.
+	jsonWritten bool
+)
+
+// beginJSONOutput moves everything printed with fmt.Print* to stderr, leaving
+// stdout for the JSON result alone.
+func beginJSONOutput() {
+	if !jsonOutput {
+		return
+	}
+	jsonWriter = os.Stdout
+	os.Stdout = os.Stderr
+}
+
+func printJSON(v any) error {
+	jsonWritten = true
+	enc := json.NewEncoder(jsonWriter)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// printJSONError reports a failed command, unless it already printed a result.
+func printJSONError(err error) {
+	if !jsonOutput || jsonWritten {
+		return
+	}
+	out := jsonError{Error: err.Error()}
+	if ve := core.AsValidationError(err); ve != nil {
+		out.Field = ve.Field
+	}
+	printJSON(out)
+}
+
+type jsonError struct {
+	Error string `json:"error"`
+	Field string `json:"field,omitempty"` // The setting a failed check is about, such as "threshold"
+}
+
+type jsonFriend struct {
+	Name     string `json:"name"`
+	Contact  string `json:"contact,omitempty"`
+	Language string `json:"language,omitempty"`
+	Words    string `json:"words,omitempty"`
+	Shares   int    `json:"shares,omitempty"`
+}
+
+type jsonShare struct {
+	Friend   string `json:"friend"`
+	Index    int    `json:"index,omitempty"`
+	File     string `json:"file"`
+	Checksum string `json:"checksum"`
+}
+
+type jsonBundle struct {
+	Friend          string   `json:"friend"`
+	Path            string   `json:"path"`
+	Size            int64    `json:"size"`
+	PreviousVersion string   `json:"previousVersion,omitempty"` // Only with bundle --refresh
+	Changed         []string `json:"changed,omitempty"`         // Files that differ from the bundle it replaced
+	Unchanged       bool     `json:"unchanged,omitempty"`       // The earlier bundle was kept, byte for byte
+	Reprint         bool     `json:"reprint,omitempty"`         // Its README changed, so a printed copy is out of date
+}
+
+func jsonFriends(friends []project.Friend) []jsonFriend {
+	out := make([]jsonFriend, len(friends))
+	for i, f := range friends {
+		out[i] = jsonFriend{Name: f.Name, Contact: f.Contact, Language: f.Language, Words: f.Words, Shares: f.Shares}
+	}
+	return out
+}
+
+// jsonShares lists the sealed shares, with their index read from each share file.
+func jsonShares(p *project.Project) []jsonShare {
+	out := make([]jsonShare, len(p.Sealed.Shares))
+	for i, si := range p.Sealed.Shares {
+		path := filepath.Join(p.Path, si.File)
+		out[i] = jsonShare{Friend: si.Friend, File: path, Checksum: si.Checksum}
+		if data, err := os.ReadFile(path); err == nil {
+			if share, err := core.ParseShare(data); err == nil {
+				out[i].Index = share.Index
+			}
+		}
+	}
+	return out
+}
+
+func jsonBundles(p *project.Project) []jsonBundle {
+	out := make([]jsonBundle, 0, len(p.Friends))
+	for _, f := range p.Friends {
+		path := friendBundlePath(p, f)
+		info, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+		out = append(out, jsonBundle{Friend: f.Name, Path: path, Size: info.Size()})
+	}
+	return out
+}
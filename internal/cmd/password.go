@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/eljojo/rememory/internal/core"
+	"github.com/eljojo/rememory/internal/recovery"
+	"golang.org/x/term"
+)
+
+// minOwnerPasswordLength keeps the owner escrow from being trivially guessable.
+// age's scrypt work factor slows guessing down, but only so much.
+const minOwnerPasswordLength = 12
+
+// passwordInput is shared so consecutive prompts read consecutive piped lines.
+var passwordInput = bufio.NewReader(os.Stdin)
+
+// readPassword prompts on stderr and reads a line without echo when stdin is
+// a terminal. Piped input is read as a plain line so scripts and tests work.
+func readPassword(prompt string) (string, error) {
+	fmt.Fprintf(os.Stderr, "%s: ", prompt)
+
+	fd := int(os.Stdin.Fd())
+	if term.IsTerminal(fd) {
+		b, err := term.ReadPassword(fd)
+		fmt.Fprintln(os.Stderr)
+		if err != nil {
+			return "", fmt.Errorf("reading password: %w", err)
+		}
+		return string(b), nil
+	}
+
+	line, err := passwordInput.ReadString('\n')
+	if err != nil && line == "" {
+		return "", fmt.Errorf("reading password: %w", err)
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// askPIN reads a locked piece's PIN, for recovery.UnlockPINs.
+func askPIN(share *core.Share, attempt int) (string, error) {
+	if attempt > 1 {
+		fmt.Fprintln(os.Stderr, "Wrong PIN, try again.")
+	}
+	return readPassword(recovery.PINPrompt(share))
+}
+
+// readNewPassword asks for a password twice and checks that both match.
+func readNewPassword(prompt string) (string, error) {
+	password, err := readPassword(prompt)
+	if err != nil {
+		return "", err
+	}
+	if len(password) < minOwnerPasswordLength {
+		return "", fmt.Errorf("password must be at least %d characters", minOwnerPasswordLength)
+	}
+
+	confirm, err := readPassword("Repeat " + strings.ToLower(prompt))
+	if err != nil {
+		return "", err
+	}
+	if confirm != password {
+		return "", fmt.Errorf("passwords don't match")
+	}
+	return password, nil
+}
@@ -0,0 +1,134 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/eljojo/rememory/internal/core"
+	"github.com/eljojo/rememory/internal/manifest"
+	"github.com/eljojo/rememory/internal/project"
+	"github.com/eljojo/rememory/internal/rotation"
+	"github.com/eljojo/rememory/internal/transfer"
+	"github.com/spf13/cobra"
+)
+
+var prepareCmd = &cobra.Command{
+	Use:   "prepare",
+	Short: "Package a project for sealing on an offline machine",
+	Long: `Prepare packages project.yml and the manifest/ directory into a single
+signed transfer file. Carry it to an air-gapped machine and run
+'rememory seal --offline <file>' there; all cryptography happens on that machine.
+
+The rememory binary needs nothing from the internet, so copying it along with
+the transfer file is all the offline machine needs.
+
+Prepare prints a fingerprint. Write it down and compare it with the one shown
+on the offline machine — if they match, the file arrived unchanged.
+
+Examples:
+  rememory prepare
+  rememory prepare -o /media/usb/transfer.rememory`,
+	RunE: runPrepare,
+}
+
+var prepareOutput string
+
+func init() {
+	prepareCmd.Flags().StringVarP(&prepareOutput, "output", "o", "", "Output file path (default: output/"+transfer.FileName+")")
+	rootCmd.AddCommand(prepareCmd)
+}
+
+func runPrepare(cmd *cobra.Command, args []string) error {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("getting current directory: %w", err)
+	}
+
+	projectDir, err := project.FindProjectDir(cwd)
+	if err != nil {
+		return err
+	}
+
+	p, err := project.Load(projectDir)
+	if err != nil {
+		return fmt.Errorf("loading project: %w", err)
+	}
+
+	if err := p.Validate(); err != nil {
+		return fmt.Errorf("invalid project: %w", err)
+	}
+
+	manifestDir := p.ManifestPath()
+	ig, err := p.ManifestIgnore()
+	if err != nil {
+		return err
+	}
+	contentWarnings, err := manifest.Check(manifestDir, ig)
+	if err != nil {
+		return err
+	}
+	fileCount, err := manifest.CountFiles(manifestDir, ig)
+	if err != nil {
+		return fmt.Errorf("checking manifest directory: %w", err)
+	}
+
+	fmt.Printf("Archiving manifest/ (%d files)...\n", fileCount)
+
+	var archiveBuf bytes.Buffer
+	archiveResult, err := manifest.ArchiveAs(&archiveBuf, manifestDir, "", ig)
+	if err != nil {
+		return fmt.Errorf("archiving manifest: %w", err)
+	}
+	for _, warning := range archiveResult.Warnings {
+		fmt.Printf("  Warning: %s\n", warning)
+	}
+	for _, warning := range contentWarnings {
+		fmt.Printf("  Warning: %s\n", warning)
+	}
+	printIgnored(archiveResult.Ignored)
+
+	// Only the configuration travels; any previous seal stays behind, except
+	// as an entry in the list of seals the new bundles replace
+	unsealed := *p
+	if p.Sealed != nil {
+		unsealed.Superseded = rotation.Record(slices.Clone(p.Superseded), rotation.Generation{Sealed: p.Sealed.At, Fingerprint: rotation.Fingerprint(p.Sealed.ManifestChecksum)})
+	}
+	unsealed.Sealed = nil
+	projectYAML, err := unsealed.Encode()
+	if err != nil {
+		return err
+	}
+
+	outPath := prepareOutput
+	if outPath == "" {
+		if err := os.MkdirAll(p.OutputPath(), 0755); err != nil {
+			return fmt.Errorf("creating output directory: %w", err)
+		}
+		outPath = filepath.Join(p.OutputPath(), transfer.FileName)
+	}
+
+	fingerprint, err := transfer.Write(outPath, &transfer.Contents{
+		Project:  projectYAML,
+		Manifest: archiveBuf.Bytes(),
+		Created:  core.Now(),
+	})
+	if err != nil {
+		return err
+	}
+
+	info, _ := os.Stat(outPath)
+	fmt.Println()
+	fmt.Printf("%s %s (%s)\n", green("✓"), outPath, formatSize(info.Size()))
+	fmt.Println()
+	fmt.Printf("Fingerprint: %s\n", fingerprint)
+	fmt.Println()
+	fmt.Println("Write the fingerprint down. On the offline machine, run:")
+	fmt.Printf("  rememory seal --offline %s --fingerprint %s\n", filepath.Base(outPath), fingerprint)
+	fmt.Println()
+	fmt.Printf("%s The transfer file contains your secrets unencrypted. Delete it once you've sealed.\n", yellow("Note:"))
+
+	return nil
+}
@@ -0,0 +1,201 @@
+package cmd
+
+import (
+	"archive/zip"
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/eljojo/rememory/internal/core"
+	"github.com/eljojo/rememory/internal/project"
+	"github.com/eljojo/rememory/internal/translations"
+	"github.com/spf13/cobra"
+)
+
+var printCmd = &cobra.Command{
+	Use:   "print [friend]...",
+	Short: "Send friends' README.pdf straight to a printer",
+	Long: `Print sends each friend's README.pdf from their bundle directly to a
+printer: CUPS (lp) on macOS and Linux, the print spooler on Windows.
+
+The PDF goes from the bundle to the print queue without being written
+anywhere else, so no copies are left in temporary folders or a PDF viewer's
+recent files. Before anything is sent, you see which friend's piece will be
+printed on which printer.
+
+Without arguments every friend's README.pdf is printed. On Windows the
+printer must accept PDF directly, as most network printers do.
+
+Examples:
+  rememory print
+  rememory print Alice Bob --printer Office_LaserJet
+  rememory print --list`,
+	RunE: runPrint,
+}
+
+func init() {
+	printCmd.Flags().String("printer", "", "Printer to use (default: the system's default printer)")
+	printCmd.Flags().Int("copies", 1, "Copies of each README.pdf")
+	printCmd.Flags().BoolP("yes", "y", false, "Print without asking for confirmation")
+	printCmd.Flags().Bool("list", false, "List available printers and exit")
+	rootCmd.AddCommand(printCmd)
+}
+
+// printJob is one friend's README.pdf, read from their bundle.
+type printJob struct {
+	friend project.Friend
+	share  *core.Share
+	name   string // File name inside the bundle, e.g. README.pdf or LEEME.pdf
+	pdf    []byte
+}
+
+func runPrint(cmd *cobra.Command, args []string) error {
+	printer, _ := cmd.Flags().GetString("printer")
+	copies, _ := cmd.Flags().GetInt("copies")
+	yes, _ := cmd.Flags().GetBool("yes")
+	list, _ := cmd.Flags().GetBool("list")
+
+	if list {
+		printers, defaultPrinter, err := listPrinters()
+		if err != nil {
+			return err
+		}
+		if len(printers) == 0 {
+			fmt.Println("No printers found.")
+			return nil
+		}
+		for _, name := range printers {
+			if name == defaultPrinter {
+				fmt.Printf("%s (default)\n", name)
+			} else {
+				fmt.Println(name)
+			}
+		}
+		return nil
+	}
+
+	if copies < 1 {
+		return fmt.Errorf("--copies must be at least 1")
+	}
+
+	p, err := loadFriendProject()
+	if err != nil {
+		return err
+	}
+	if p.Sealed == nil {
+		return fmt.Errorf("project must be sealed first (run 'rememory seal')")
+	}
+
+	friends, err := selectFriends(p, args)
+	if err != nil {
+		return err
+	}
+	shares, err := loadSealedShares(p)
+	if err != nil {
+		return err
+	}
+
+	var jobs []printJob
+	for i, f := range p.Friends {
+		if !friends[f.Name] {
+			continue
+		}
+		name, pdf, err := bundlePDF(friendBundlePath(p, f))
+		if err != nil {
+			return fmt.Errorf("%s: %w (run 'rememory bundle' to regenerate it)", f.Name, err)
+		}
+		jobs = append(jobs, printJob{friend: f, share: shares[i], name: name, pdf: pdf})
+	}
+
+	if printer == "" {
+		if _, printer, err = listPrinters(); err != nil {
+			return err
+		}
+		if printer == "" {
+			return fmt.Errorf("no default printer is set; choose one with --printer (see 'rememory print --list')")
+		}
+	}
+
+	fmt.Printf("Printer: %s\n", printer)
+	for _, job := range jobs {
+		fmt.Printf("  %s — %s, piece %d of %d", job.friend.Name, job.name, job.share.Index, job.share.Total)
+		if copies > 1 {
+			fmt.Printf(", %d copies", copies)
+		}
+		fmt.Println()
+	}
+	fmt.Println()
+	fmt.Printf("  %s Each printout contains that friend's piece. Collect them from the printer right away.\n", yellow("Note:"))
+
+	if !yes {
+		fmt.Printf("Print %d document%s? [y/N]: ", len(jobs), plural(len(jobs)))
+		answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+		if a := strings.ToLower(strings.TrimSpace(answer)); a != "y" && a != "yes" {
+			fmt.Println("Nothing printed.")
+			return nil
+		}
+	}
+
+	for _, job := range jobs {
+		// The job title shows in print queues; it names the friend, never the piece
+		title := fmt.Sprintf("ReMemory - %s", job.friend.Name)
+		if err := printPDF(printer, title, job.pdf, copies); err != nil {
+			return fmt.Errorf("printing %s's %s: %w", job.friend.Name, job.name, err)
+		}
+		logEvent(p, "print", "printed %s's %s on %s", job.friend.Name, job.name, printer)
+		fmt.Printf("%s Sent %s's %s\n", green("✓"), job.friend.Name, job.name)
+	}
+	return nil
+}
+
+// selectFriends returns the names to print: the ones given, or everyone.
+func selectFriends(p *project.Project, names []string) (map[string]bool, error) {
+	selected := make(map[string]bool)
+	if len(names) == 0 {
+		for _, f := range p.Friends {
+			selected[f.Name] = true
+		}
+		return selected, nil
+	}
+	for _, name := range names {
+		found := false
+		for _, f := range p.Friends {
+			if strings.EqualFold(f.Name, strings.TrimSpace(name)) {
+				selected[f.Name] = true
+				found = true
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("no friend named %q in the project", name)
+		}
+	}
+	return selected, nil
+}
+
+// bundlePDF reads the README PDF out of a bundle ZIP, in whatever language it was written.
+func bundlePDF(bundlePath string) (string, []byte, error) {
+	r, err := zip.OpenReader(bundlePath)
+	if err != nil {
+		return "", nil, fmt.Errorf("opening bundle: %w", err)
+	}
+	defer r.Close()
+
+	for _, f := range r.File {
+		if !translations.IsReadmeFile(f.Name, ".pdf") {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return "", nil, fmt.Errorf("opening %s: %w", f.Name, err)
+		}
+		data, err := io.ReadAll(io.LimitReader(rc, core.MaxFileSize+1))
+		rc.Close()
+		if err != nil {
+			return "", nil, fmt.Errorf("reading %s: %w", f.Name, err)
+		}
+		return f.Name, data, nil
+	}
+	return "", nil, fmt.Errorf("no README PDF in bundle")
+}
@@ -0,0 +1,48 @@
+//go:build !windows
+
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// listPrinters asks CUPS for its printers and the default one.
+func listPrinters() ([]string, string, error) {
+	if _, err := exec.LookPath("lpstat"); err != nil {
+		return nil, "", fmt.Errorf("CUPS is not installed (lpstat not found)")
+	}
+
+	out, err := exec.Command("lpstat", "-e").Output()
+	if err != nil {
+		return nil, "", fmt.Errorf("listing printers: %w", err)
+	}
+	printers := strings.Fields(string(out))
+
+	// "system default destination: NAME", or an error when none is set
+	var defaultPrinter string
+	if out, err := exec.Command("lpstat", "-d").Output(); err == nil {
+		if _, name, ok := strings.Cut(strings.TrimSpace(string(out)), ": "); ok {
+			defaultPrinter = name
+		}
+	}
+	return printers, defaultPrinter, nil
+}
+
+// printPDF hands the PDF to lp on stdin, so it never touches the disk outside
+// the CUPS spool, which removes it once printed.
+func printPDF(printer, title string, pdf []byte, copies int) error {
+	cmd := exec.Command("lp", "-d", printer, "-t", title, "-n", fmt.Sprint(copies), "-")
+	cmd.Stdin = bytes.NewReader(pdf)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return fmt.Errorf("%s", msg)
+		}
+		return err
+	}
+	return nil
+}
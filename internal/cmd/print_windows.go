@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"fmt"
+	"syscall"
+	"unsafe"
+)
+
+var (
+	winspool             = syscall.NewLazyDLL("winspool.drv")
+	procEnumPrinters     = winspool.NewProc("EnumPrintersW")
+	procGetDefault       = winspool.NewProc("GetDefaultPrinterW")
+	procOpenPrinter      = winspool.NewProc("OpenPrinterW")
+	procClosePrinter     = winspool.NewProc("ClosePrinter")
+	procStartDocPrinter  = winspool.NewProc("StartDocPrinterW")
+	procEndDocPrinter    = winspool.NewProc("EndDocPrinter")
+	procStartPagePrinter = winspool.NewProc("StartPagePrinter")
+	procEndPagePrinter   = winspool.NewProc("EndPagePrinter")
+	procWritePrinter     = winspool.NewProc("WritePrinter")
+)
+
+const (
+	printerEnumLocal       = 0x2
+	printerEnumConnections = 0x4
+)
+
+// docInfo1 is DOC_INFO_1.
+type docInfo1 struct {
+	docName    *uint16
+	outputFile *uint16
+	datatype   *uint16
+}
+
+// printerInfo4 is PRINTER_INFO_4.
+type printerInfo4 struct {
+	printerName *uint16
+	serverName  *uint16
+	attributes  uint32
+}
+
+// listPrinters asks the spooler for local and connected printers and the default one.
+func listPrinters() ([]string, string, error) {
+	var needed, returned uint32
+	flags := uintptr(printerEnumLocal | printerEnumConnections)
+	procEnumPrinters.Call(flags, 0, 4, 0, 0, uintptr(unsafe.Pointer(&needed)), uintptr(unsafe.Pointer(&returned)))
+
+	var printers []string
+	if needed > 0 {
+		buf := make([]byte, needed)
+		r, _, err := procEnumPrinters.Call(flags, 0, 4, uintptr(unsafe.Pointer(&buf[0])), uintptr(needed),
+			uintptr(unsafe.Pointer(&needed)), uintptr(unsafe.Pointer(&returned)))
+		if r == 0 {
+			return nil, "", fmt.Errorf("listing printers: %w", err)
+		}
+		infos := unsafe.Slice((*printerInfo4)(unsafe.Pointer(&buf[0])), returned)
+		for _, info := range infos {
+			printers = append(printers, utf16PtrToString(info.printerName))
+		}
+	}
+
+	var defaultPrinter string
+	var size uint32
+	procGetDefault.Call(0, uintptr(unsafe.Pointer(&size)))
+	if size > 0 {
+		name := make([]uint16, size)
+		if r, _, _ := procGetDefault.Call(uintptr(unsafe.Pointer(&name[0])), uintptr(unsafe.Pointer(&size))); r != 0 {
+			defaultPrinter = syscall.UTF16ToString(name)
+		}
+	}
+	return printers, defaultPrinter, nil
+}
+
+// printPDF writes the PDF to the spooler as a RAW job, so it reaches the
+// printer without being converted or saved by another program.
+func printPDF(printer, title string, pdf []byte, copies int) error {
+	printerName, err := syscall.UTF16PtrFromString(printer)
+	if err != nil {
+		return err
+	}
+	var handle syscall.Handle
+	if r, _, err := procOpenPrinter.Call(uintptr(unsafe.Pointer(printerName)), uintptr(unsafe.Pointer(&handle)), 0); r == 0 {
+		return fmt.Errorf("opening printer: %w", err)
+	}
+	defer procClosePrinter.Call(uintptr(handle))
+
+	docName, _ := syscall.UTF16PtrFromString(title)
+	datatype, _ := syscall.UTF16PtrFromString("RAW")
+	doc := docInfo1{docName: docName, datatype: datatype}
+
+	for range copies {
+		if r, _, err := procStartDocPrinter.Call(uintptr(handle), 1, uintptr(unsafe.Pointer(&doc))); r == 0 {
+			return fmt.Errorf("starting print job: %w", err)
+		}
+		procStartPagePrinter.Call(uintptr(handle))
+		var written uint32
+		r, _, err := procWritePrinter.Call(uintptr(handle), uintptr(unsafe.Pointer(&pdf[0])), uintptr(len(pdf)), uintptr(unsafe.Pointer(&written)))
+		procEndPagePrinter.Call(uintptr(handle))
+		procEndDocPrinter.Call(uintptr(handle))
+		if r == 0 {
+			return fmt.Errorf("sending to printer: %w", err)
+		}
+		if int(written) != len(pdf) {
+			return fmt.Errorf("sending to printer: only %d of %d bytes written", written, len(pdf))
+		}
+	}
+	return nil
+}
+
+func utf16PtrToString(p *uint16) string {
+	if p == nil {
+		return ""
+	}
+	var chars []uint16
+	for ptr := unsafe.Pointer(p); *(*uint16)(ptr) != 0; ptr = unsafe.Add(ptr, 2) {
+		chars = append(chars, *(*uint16)(ptr))
+	}
+	return syscall.UTF16ToString(chars)
+}
@@ -0,0 +1,226 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/eljojo/rememory/internal/core"
+	"github.com/eljojo/rememory/internal/pdf"
+	"github.com/eljojo/rememory/internal/recovery"
+	"github.com/skip2/go-qrcode"
+	"github.com/spf13/cobra"
+)
+
+var qrCmd = &cobra.Command{
+	Use:   "qr <share-file>",
+	Short: "Render a share as a QR code",
+	Long: `QR renders a share as a QR code, on its own rather than inside README.pdf.
+The share file can be a SHARE-*.txt or a whole README.txt.
+
+By default the code holds the same thing as the one in README.pdf: a link to
+the recovery tool with the piece in it, so scanning it with a phone opens
+recover.html with the piece already filled in. With --compact-only it holds
+just the compact share (RM2:...), for scanners that shouldn't open links.
+
+The format follows the --output extension (.png or .svg). Without --output,
+the code is drawn in the terminal.
+
+A link too long for one code to scan reliably, as with a long --recovery-url,
+is split over several codes, written as alice-1.png, alice-2.png, and so on.
+recover.html's scanner and 'rememory scan' put them back together.
+
+Examples:
+  rememory qr SHARE-alice.txt
+  rememory qr SHARE-alice.txt -o alice.png --size 1024
+  rememory qr SHARE-alice.txt -o alice.svg --level H --compact-only`,
+	Args: cobra.ExactArgs(1),
+	RunE: runQR,
+}
+
+func init() {
+	qrCmd.Flags().StringP("output", "o", "", "Write the QR code to this file (.png or .svg) instead of the terminal")
+	qrCmd.Flags().String("format", "", "Output format: png, svg, or terminal (default: from --output, else terminal)")
+	qrCmd.Flags().Int("size", 512, "Image width and height in pixels (png and svg)")
+	qrCmd.Flags().String("level", "M", "Error correction level: L, M, Q, or H")
+	qrCmd.Flags().String("recovery-url", core.DefaultRecoveryURL, "Base URL for the recovery link in the QR code")
+	qrCmd.Flags().Bool("compact-only", false, "Encode only the compact share, without the recovery link")
+	qrCmd.Flags().Bool("invert", false, "Swap dark and light in the terminal, for light-on-dark terminals")
+	rootCmd.AddCommand(qrCmd)
+}
+
+func runQR(cmd *cobra.Command, args []string) error {
+	output, _ := cmd.Flags().GetString("output")
+	format, _ := cmd.Flags().GetString("format")
+	size, _ := cmd.Flags().GetInt("size")
+	levelName, _ := cmd.Flags().GetString("level")
+	recoveryURL, _ := cmd.Flags().GetString("recovery-url")
+	compactOnly, _ := cmd.Flags().GetBool("compact-only")
+	invert, _ := cmd.Flags().GetBool("invert")
+
+	level, err := qrLevel(levelName)
+	if err != nil {
+		return err
+	}
+	format, err = qrFormat(format, output)
+	if err != nil {
+		return err
+	}
+	if format != "terminal" && size < 64 {
+		return fmt.Errorf("--size must be at least 64 pixels")
+	}
+
+	shares, err := recovery.ReadShareFiles(args)
+	if err != nil {
+		return err
+	}
+	share := shares[0]
+	if share.Threshold == 0 {
+		return fmt.Errorf("recovery words don't record how many pieces are needed; make the QR code from the share file or README.txt")
+	}
+
+	content := share.CompactEncode()
+	if !compactOnly {
+		content = pdf.ReadmeData{Share: share, RecoveryURL: recoveryURL}.QRContent()
+	}
+
+	// Content too long for one code to scan reliably is split over several
+	codes := core.SplitQR(content)
+	for i, part := range codes {
+		code, err := qrcode.New(part, level)
+		if err != nil {
+			return fmt.Errorf("encoding QR code: %w", err)
+		}
+		path := output
+		if len(codes) > 1 {
+			if path != "" {
+				ext := filepath.Ext(path)
+				path = fmt.Sprintf("%s-%d%s", strings.TrimSuffix(path, ext), i+1, ext)
+			} else {
+				fmt.Printf("Code %d of %d:\n", i+1, len(codes))
+			}
+		}
+		if err := writeQR(code, format, path, size, invert); err != nil {
+			return err
+		}
+		if path != "" {
+			fmt.Printf("%s Wrote QR code for piece %d of %d (%s) to %s\n", green("✓"), share.Index, share.Total, share.Holder, path)
+		}
+	}
+	if len(codes) > 1 {
+		fmt.Printf("The link is too long for one code, so it is split over %d. Scan them all with recover.html or 'rememory scan'.\n", len(codes))
+	}
+	return nil
+}
+
+// writeQR renders a QR code in the given format, to path or, for the
+// terminal format without a path, to stdout.
+func writeQR(code *qrcode.QRCode, format, path string, size int, invert bool) error {
+	switch format {
+	case "terminal":
+		if path == "" {
+			fmt.Print(code.ToSmallString(invert))
+			return nil
+		}
+		if err := os.WriteFile(path, []byte(code.ToSmallString(invert)), 0600); err != nil {
+			return fmt.Errorf("writing %s: %w", path, err)
+		}
+	case "png":
+		data, err := code.PNG(size)
+		if err != nil {
+			return fmt.Errorf("rendering PNG: %w", err)
+		}
+		if err := os.WriteFile(path, data, 0600); err != nil {
+			return fmt.Errorf("writing %s: %w", path, err)
+		}
+	case "svg":
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+		if err != nil {
+			return fmt.Errorf("writing %s: %w", path, err)
+		}
+		if err := writeQRSVG(f, code.Bitmap(), size); err != nil {
+			f.Close()
+			return fmt.Errorf("writing %s: %w", path, err)
+		}
+		if err := f.Close(); err != nil {
+			return fmt.Errorf("writing %s: %w", path, err)
+		}
+	}
+	return nil
+}
+
+// qrLevel maps an error correction letter to the qrcode recovery level.
+func qrLevel(name string) (qrcode.RecoveryLevel, error) {
+	switch strings.ToUpper(name) {
+	case "L":
+		return qrcode.Low, nil
+	case "M":
+		return qrcode.Medium, nil
+	case "Q":
+		return qrcode.High, nil
+	case "H":
+		return qrcode.Highest, nil
+	}
+	return 0, fmt.Errorf("unknown error correction level %q (use L, M, Q, or H)", name)
+}
+
+// qrFormat picks the output format from --format, falling back to the
+// output file's extension, or the terminal when there is no output file.
+func qrFormat(format, output string) (string, error) {
+	if format == "" {
+		switch strings.ToLower(filepath.Ext(output)) {
+		case "":
+			if output != "" {
+				return "", fmt.Errorf("can't tell the format of %s; use --format", output)
+			}
+			format = "terminal"
+		case ".png":
+			format = "png"
+		case ".svg":
+			format = "svg"
+		default:
+			return "", fmt.Errorf("can't tell the format of %s; use --format", output)
+		}
+	}
+
+	format = strings.ToLower(format)
+	switch format {
+	case "terminal":
+		return format, nil
+	case "png", "svg":
+		if output == "" {
+			return "", fmt.Errorf("--format %s needs --output", format)
+		}
+		return format, nil
+	}
+	return "", fmt.Errorf("unknown format %q (use png, svg, or terminal)", format)
+}
+
+// writeQRSVG draws the QR bitmap as an SVG of size×size pixels. Dark modules
+// are merged into horizontal runs so the path stays small.
+func writeQRSVG(w io.Writer, bitmap [][]bool, size int) error {
+	n := len(bitmap)
+	var path strings.Builder
+	for y, row := range bitmap {
+		for x := 0; x < len(row); x++ {
+			if !row[x] {
+				continue
+			}
+			start := x
+			for x < len(row) && row[x] {
+				x++
+			}
+			fmt.Fprintf(&path, "M%d %dh%dv1h-%dz", start, y, x-start, x-start)
+		}
+	}
+
+	_, err := fmt.Fprintf(w, `<?xml version="1.0" encoding="UTF-8"?>
+<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" shape-rendering="crispEdges">
+<rect width="%d" height="%d" fill="#fff"/>
+<path fill="#000" d="%s"/>
+</svg>
+`, size, size, n, n, n, n, path.String())
+	return err
+}
@@ -1,16 +1,22 @@
 package cmd
 
 import (
-	"bytes"
 	"fmt"
+	"io"
+	"maps"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"time"
 
+	"github.com/eljojo/rememory/internal/bundle"
 	"github.com/eljojo/rememory/internal/core"
-	"github.com/eljojo/rememory/internal/html"
+	"github.com/eljojo/rememory/internal/estate"
 	"github.com/eljojo/rememory/internal/manifest"
+	"github.com/eljojo/rememory/internal/recovery"
+	"github.com/eljojo/rememory/internal/rotation"
+	"github.com/eljojo/rememory/internal/seed"
 	"github.com/spf13/cobra"
 )
 
@@ -22,8 +28,43 @@ var recoverCmd = &cobra.Command{
 This command can be run from anywhere (doesn't need a project directory).
 You need at least the threshold number of shares to recover.
 
+Each share can be a share file, README.txt, bundle ZIP, or personalized
+recover.html, or a text file holding the compact code from the QR code, the
+recovery link, or the 25 recovery words. Forms can be mixed freely.
+
+Projects that set slip39 also give each friend SLIP-0039 words (SLIP39.txt).
+Those are recovered on their own: pass only SLIP-0039 files, one per friend.
+Likewise for projects that set sskr: pass only SSKR.txt files, or files
+holding one SSKR share each, as a UR or bytewords. And for ssss: pass only
+SSSS.txt files, or files holding one ssss share line each.
+
 Example:
-  rememory recover SHARE-alice.txt SHARE-bob.txt SHARE-carol.txt -m MANIFEST.age`,
+  rememory recover SHARE-alice.txt SHARE-bob.txt SHARE-carol.txt -m MANIFEST.age
+  rememory recover bundle-alice.zip bob-words.txt -m MANIFEST.age
+
+For a project sealed with the restricted crypto profile, add --restricted to
+refuse anything outside it.
+
+If the owner set an expiry date on the bundles and it has passed, recovery
+stops, since there may be newer bundles; --ignore-expiry goes ahead anyway.
+A passed review date only prints a warning.
+
+When MANIFEST.age was split into volumes (MANIFEST.age.001, .002, ...),
+give any one of them, or MANIFEST.age.sha256, as --manifest, with the rest
+in the same folder. They are put back together in order and each is checked
+against MANIFEST.age.sha256 when it's there.
+
+If one bundle ZIP lists another in its SUPERSEDED.txt, the older one is out
+of date and recovery stops. Once the passphrase is back, the notes in the
+bundles are checked against it.
+
+Each file extracted is checked against the index of files kept in the
+manifest, and any that doesn't match it is named. --list prints the files
+in the manifest, with their sizes and dates, without extracting anything.
+
+Files and folders get back the permissions, modification times, and
+extended attributes they were sealed with; --no-metadata leaves them as
+any new file is, dated when they're recovered.`,
 	Args: cobra.MinimumNArgs(1),
 	RunE: runRecover,
 }
@@ -32,92 +73,53 @@ var (
 	recoverManifest   string
 	recoverOutput     string
 	recoverPassphrase bool
+	recoverRestricted bool
+	recoverList       bool
+	recoverNoMetadata bool
+
+	recoverIgnoreExpiry bool
 )
 
 func init() {
 	rootCmd.AddCommand(recoverCmd)
-	recoverCmd.Flags().StringVarP(&recoverManifest, "manifest", "m", "", "Path to MANIFEST.age file")
+	recoverCmd.Flags().StringVarP(&recoverManifest, "manifest", "m", "", "Path to MANIFEST.age, one of its volumes, or a personalized recover.html")
 	recoverCmd.Flags().StringVarP(&recoverOutput, "output", "o", "", "Output directory (default: recovered-TIMESTAMP)")
 	recoverCmd.Flags().BoolVar(&recoverPassphrase, "passphrase-only", false, "Only output the passphrase, don't decrypt")
+	recoverCmd.Flags().BoolVar(&recoverList, "list", false, "List the files in the manifest without extracting them")
+	recoverCmd.Flags().BoolVar(&recoverNoMetadata, "no-metadata", false, "Don't restore the files' permissions, times, and extended attributes")
+	recoverCmd.Flags().BoolVar(&recoverRestricted, "restricted", false, "Refuse shares and manifests outside the restricted crypto profile")
+	recoverCmd.Flags().BoolVar(&recoverIgnoreExpiry, "ignore-expiry", false, "Recover even if the pieces are past the expiry date their owner set")
 }
 
 func runRecover(cmd *cobra.Command, args []string) error {
 	// Parse all share files
 	fmt.Printf("Reading %d share files...\n", len(args))
 
-	shares := make([]*core.Share, len(args))
-	for i, path := range args {
-		content, err := os.ReadFile(path)
-		if err != nil {
-			return fmt.Errorf("reading share %s: %w", path, err)
-		}
-
-		share, err := core.ParseShare(content)
-		if err != nil {
-			return fmt.Errorf("parsing share %s: %w", path, err)
-		}
-
-		// Verify checksum
-		if err := share.Verify(); err != nil {
-			return fmt.Errorf("share %s: %w", path, err)
-		}
-
-		shares[i] = share
-	}
-
-	// Validate shares are compatible
-	if len(shares) == 0 {
-		return fmt.Errorf("no shares provided")
-	}
-
-	first := shares[0]
-	for i, share := range shares[1:] {
-		if share.Version != first.Version {
-			return fmt.Errorf("share %d has different version (v%d vs v%d) — all shares must be from the same bundle", i+2, share.Version, first.Version)
-		}
-		if share.Total != first.Total {
-			return fmt.Errorf("share %d has different total (%d vs %d)", i+2, share.Total, first.Total)
-		}
-		if share.Threshold != first.Threshold {
-			return fmt.Errorf("share %d has different threshold (%d vs %d)", i+2, share.Threshold, first.Threshold)
-		}
+	notes, err := checkRotation(args)
+	if err != nil {
+		return err
 	}
 
-	// Check we have enough shares
-	if len(shares) < first.Threshold {
-		return fmt.Errorf("need at least %d shares to recover (you provided %d)", first.Threshold, len(shares))
+	passphrase, err := passphraseFromShareFiles(args, recoverRestricted, recoverIgnoreExpiry)
+	if err != nil {
+		return err
 	}
+	defer passphrase.Wipe()
 
-	// Check for duplicate indices
-	seen := make(map[int]bool)
-	for _, share := range shares {
-		if seen[share.Index] {
-			return fmt.Errorf("duplicate share index %d", share.Index)
+	// Only the owner of this seal could have made a key the passphrase rebuilds
+	for _, name := range slices.Sorted(maps.Keys(notes)) {
+		if notes[name].SignedBy(passphrase) {
+			fmt.Printf("  %s %s in %s was made by the owner of these pieces\n", green("✓"), rotation.FileName, name)
+		} else {
+			fmt.Printf("  Warning: %s in %s was not made with these pieces' passphrase; don't trust what it says\n", rotation.FileName, name)
 		}
-		seen[share.Index] = true
-	}
-
-	fmt.Printf("Combining %d shares...\n", len(shares))
-
-	// Extract raw share data
-	shareData := make([][]byte, len(shares))
-	for i, share := range shares {
-		shareData[i] = share.Data
-	}
-
-	// Reconstruct passphrase
-	recovered, err := core.Combine(shareData)
-	if err != nil {
-		return fmt.Errorf("combining shares: %w", err)
 	}
 
-	passphrase := core.RecoverPassphrase(recovered, first.Version)
-
 	if recoverPassphrase {
 		fmt.Println()
 		fmt.Println("Recovered passphrase:")
-		fmt.Println(passphrase)
-		return nil
+		_, err := os.Stdout.Write(append(passphrase, '\n'))
+		return err
 	}
 
 	// Find manifest file
@@ -126,6 +128,8 @@ func runRecover(cmd *cobra.Command, args []string) error {
 		// Try to find MANIFEST.age in current directory, then recover.html
 		if _, err := os.Stat("MANIFEST.age"); err == nil {
 			manifestPath = "MANIFEST.age"
+		} else if volume := recovery.FindVolumes(".", "MANIFEST.age"); volume != "" {
+			manifestPath = volume
 		} else if _, err := os.Stat("recover.html"); err == nil {
 			manifestPath = "recover.html"
 		} else {
@@ -135,30 +139,62 @@ func runRecover(cmd *cobra.Command, args []string) error {
 
 	fmt.Println("Decrypting manifest...")
 
-	// Read manifest data — either directly from .age file or extracted from .html
-	var encryptedData []byte
-	if strings.HasSuffix(strings.ToLower(manifestPath), ".html") || strings.HasSuffix(strings.ToLower(manifestPath), ".htm") {
-		htmlContent, err := os.ReadFile(manifestPath)
-		if err != nil {
-			return fmt.Errorf("reading %s: %w", manifestPath, err)
-		}
-		encryptedData, err = html.ExtractManifestFromHTML(htmlContent)
-		if err != nil {
-			return fmt.Errorf("extracting manifest from %s: %w", manifestPath, err)
-		}
+	encryptedData, repaired, err := recovery.ReadManifest(manifestPath)
+	if err != nil {
+		return err
+	}
+	if recovery.IsHTML(manifestPath) {
 		fmt.Printf("Extracted manifest from %s\n", manifestPath)
-	} else {
-		encryptedData, err = os.ReadFile(manifestPath)
-		if err != nil {
-			return fmt.Errorf("reading manifest: %w", err)
+	} else if recovery.IsVolume(manifestPath) {
+		fmt.Println("Put MANIFEST.age back together from its volumes")
+	}
+	if repaired > 0 {
+		fmt.Printf("%s Repaired %d damaged block%s of MANIFEST.age with MANIFEST.age.parity\n", yellow("Note:"), repaired, plural(repaired))
+	}
+	if recoverRestricted {
+		if err := core.CheckRestrictedManifest(encryptedData); err != nil {
+			return err
 		}
 	}
 
-	var decryptedBuf bytes.Buffer
-	if err := core.Decrypt(&decryptedBuf, bytes.NewReader(encryptedData), passphrase); err != nil {
+	decoy, err := recovery.ReadDecoy(manifestPath)
+	if err != nil {
+		return err
+	}
+	timelock, err := recovery.ReadTimelock(manifestPath)
+	if err != nil {
+		return err
+	}
+	opener, err := recovery.WaitOut(os.Stdout, timelock, passphrase)
+	if err != nil {
+		return err
+	}
+	defer opener.Wipe()
+
+	// Decrypted as it's read, so none of the manifest waits in memory
+	decrypt := func(w io.Writer) error { return recovery.Decrypt(w, encryptedData, decoy, opener) }
+	decryptFailed := func(err error) error {
 		return fmt.Errorf("decryption failed (shares may be corrupted or from different operation): %w", err)
 	}
 
+	if recoverList {
+		var entries []core.IndexEntry
+		var indexed bool
+		decryptErr, err := recovery.Stream(decrypt, func(r io.Reader) error {
+			var err error
+			entries, indexed, err = core.ListTarGz(r)
+			return err
+		})
+		if decryptErr != nil {
+			return decryptFailed(decryptErr)
+		}
+		if err != nil {
+			return fmt.Errorf("listing manifest: %w", err)
+		}
+		printArchiveList(entries, indexed)
+		return nil
+	}
+
 	// Determine output directory
 	outputDir := recoverOutput
 	if outputDir == "" {
@@ -166,28 +202,128 @@ func runRecover(cmd *cobra.Command, args []string) error {
 	}
 
 	// Extract archive
-	extractResult, err := manifest.Extract(&decryptedBuf, outputDir)
+	extractResult, err := extractDecrypted(decrypt, decryptFailed, outputDir, !recoverNoMetadata)
 	if err != nil {
-		return fmt.Errorf("extracting manifest: %w", err)
+		return err
 	}
 
 	// Warn about any skipped files (symlinks, etc.)
 	for _, warning := range extractResult.Warnings {
 		fmt.Printf("  Warning: %s\n", warning)
 	}
+	printIndexCheck(extractResult.Index)
 
-	// List recovered files
+	return printRecoveredFiles(extractResult.Path)
+}
+
+// extractDecrypted unpacks the archive decrypt writes into outputDir as it
+// is decrypted, with decryptFailed wrapping an error decrypting it, and
+// with metadata, restores what manifest.Extract does. What was unpacked
+// before an error stays in outputDir.
+func extractDecrypted(decrypt func(io.Writer) error, decryptFailed func(error) error, outputDir string, metadata bool) (*manifest.ExtractResult, error) {
+	var result *manifest.ExtractResult
+	decryptErr, err := recovery.Stream(decrypt, func(r io.Reader) error {
+		var err error
+		result, err = manifest.Extract(r, outputDir, metadata)
+		return err
+	})
+	if decryptErr != nil {
+		return nil, decryptFailed(decryptErr)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("extracting manifest: %w", err)
+	}
+	return result, nil
+}
+
+// printArchiveList prints the files in a decrypted archive, as
+// core.ListTarGz lists them: from its index, or from the archive itself if
+// it has none.
+func printArchiveList(entries []core.IndexEntry, indexed bool) {
 	fmt.Println()
-	fmt.Printf("Recovered to: %s/\n", extractResult.Path)
+	var total int64
+	for _, e := range entries {
+		fmt.Printf("  %10s  %s  %s\n", formatSize(e.Size), e.Modified.Local().Format(core.DateFormat), e.Path)
+		total += e.Size
+	}
+	fmt.Printf("\n%d files, %s\n", len(entries), formatSize(total))
+	if !indexed {
+		fmt.Println("This archive was sealed without a file index, so its files can't be checked against one.")
+	}
+}
 
-	err = filepath.Walk(extractResult.Path, func(path string, info os.FileInfo, err error) error {
+// printIndexCheck sums up how the extracted files compared to the
+// archive's index. Each problem has been warned about already.
+func printIndexCheck(checks []core.IndexCheck) {
+	if checks == nil {
+		return
+	}
+	listed := 0
+	for _, c := range checks {
+		if c.Status != core.IndexUnlisted {
+			listed++
+		}
+	}
+	if problems := core.Problems(checks); len(problems) > 0 {
+		fmt.Printf("  %s %d of the %d files in the archive's index don't match it\n", yellow("!"), len(problems), listed)
+		return
+	}
+	fmt.Printf("  %s All %d files match the archive's index\n", green("✓"), listed)
+}
+
+// checkRotation reads SUPERSEDED.txt from any bundle ZIPs among the inputs,
+// and stops if one bundle's note says another is from an earlier seal.
+// It returns the notes by file name.
+func checkRotation(paths []string) (map[string]*rotation.Note, error) {
+	notes := make(map[string]*rotation.Note)
+	checksums := make(map[string]string)
+	for _, path := range paths {
+		if !strings.EqualFold(filepath.Ext(path), ".zip") {
+			continue
+		}
+		info, err := bundle.ReadInfo(path)
+		if err != nil {
+			continue // reading the share reports the problem
+		}
+		name := filepath.Base(path)
+		if info.RotationErr != nil {
+			return nil, fmt.Errorf("%s: %w", name, info.RotationErr)
+		}
+		if info.Rotation != nil {
+			notes[name] = info.Rotation
+		}
+		checksums[name] = info.Metadata["checksum-manifest"]
+	}
+
+	for newer, note := range notes {
+		for older, checksum := range checksums {
+			if g, ok := note.Supersedes(checksum); ok {
+				return nil, fmt.Errorf("%s is from the seal of %s, which %s replaces: ask its holder for their current bundle", older, g.Sealed.Format(core.DateFormat), newer)
+			}
+		}
+	}
+	return notes, nil
+}
+
+// combineShares checks that the shares belong together and reconstructs the passphrase.
+func combineShares(shares []*core.Share) (core.Secret, error) {
+	fmt.Printf("Combining %d shares...\n", len(shares))
+	return recovery.Combine(shares)
+}
+
+// printRecoveredFiles lists the files extracted under dir.
+func printRecoveredFiles(dir string) error {
+	fmt.Println()
+	fmt.Printf("Recovered to: %s/\n", dir)
+
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
 		}
-		if path == extractResult.Path {
+		if path == dir {
 			return nil
 		}
-		relPath, _ := filepath.Rel(extractResult.Path, path)
+		relPath, _ := filepath.Rel(dir, path)
 		if info.IsDir() {
 			fmt.Printf("  %s/\n", relPath)
 		} else {
@@ -199,5 +335,14 @@ func runRecover(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("listing recovered files: %w", err)
 	}
 
+	if _, err := os.Stat(filepath.Join(dir, seed.FileName)); err == nil {
+		fmt.Printf("\n%s %s holds a wallet's seed phrase. Never type it into a website or a\n", yellow("!"), seed.FileName)
+		fmt.Println("  computer connected to the internet: anyone who sees it can take everything in the wallet.")
+	}
+	estatePath := filepath.Join(dir, estate.FileName)
+	if _, err := os.Stat(estatePath); err == nil {
+		fmt.Printf("\n%s holds accounts, devices, and wishes; read it with:\n", estate.FileName)
+		fmt.Printf("  rememory estate show %s\n", estatePath)
+	}
 	return nil
 }
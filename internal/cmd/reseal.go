@@ -0,0 +1,201 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/eljojo/rememory/internal/core"
+	"github.com/eljojo/rememory/internal/project"
+	"github.com/eljojo/rememory/internal/recovery"
+	"github.com/spf13/cobra"
+)
+
+var resealCmd = &cobra.Command{
+	Use:   "reseal --keys-only [share files...]",
+	Short: "Seal again under a new passphrase without archiving the files again",
+	Long: `Reseal --keys-only rotates the passphrase and the pieces, keeping the sealed
+files as they are. It decrypts the current MANIFEST.age and encrypts the
+same archive under a new passphrase in one stream, so manifest/ isn't read
+or compressed again and doesn't even need to be there. Everything else is
+a new seal: new pieces, a new bundle ID, and new bundles for every friend.
+
+Decrypting needs the current passphrase, from one of:
+  - the share files you pass as arguments (at least the threshold)
+  - --owner: the owner escrow written by 'rememory seal --owner-escrow'
+  - otherwise, the pieces in output/shares/
+
+With a recovery delay, the current one is waited out first.
+
+Examples:
+  rememory reseal --keys-only
+  rememory reseal --keys-only --owner
+  rememory reseal --keys-only SHARE-alice.txt SHARE-bob.txt`,
+	RunE: runReseal,
+}
+
+func init() {
+	resealCmd.Flags().Bool("keys-only", false, "Rotate the passphrase and pieces, keeping the sealed archive (required)")
+	resealCmd.Flags().Bool("owner", false, "Unlock the current seal with your owner password instead of shares")
+	resealCmd.Flags().String("recovery-url", core.DefaultRecoveryURL, "Base URL for QR code in PDF")
+	resealCmd.Flags().Bool("no-embed-manifest", false, "Do not embed MANIFEST.age in recover.html (it is embedded by default when 5 MB or less)")
+	resealCmd.Flags().Bool("owner-escrow", false, "Also keep a copy of the new passphrase locked with a password of your own")
+	rootCmd.AddCommand(resealCmd)
+}
+
+func runReseal(cmd *cobra.Command, args []string) error {
+	if keysOnly, _ := cmd.Flags().GetBool("keys-only"); !keysOnly {
+		return fmt.Errorf("reseal only rotates keys for now; pass --keys-only, or run 'rememory seal' to archive manifest/ again")
+	}
+	owner, _ := cmd.Flags().GetBool("owner")
+	recoveryURL, _ := cmd.Flags().GetString("recovery-url")
+	noEmbedManifest, _ := cmd.Flags().GetBool("no-embed-manifest")
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("getting current directory: %w", err)
+	}
+	projectDir, err := project.FindProjectDir(cwd)
+	if err != nil {
+		return err
+	}
+	p, err := project.Load(projectDir)
+	if err != nil {
+		return fmt.Errorf("loading project: %w", err)
+	}
+	if p.Sealed == nil {
+		return fmt.Errorf("project is not sealed — run 'rememory seal' first")
+	}
+	if len(p.Sealed.Files) == 0 {
+		return fmt.Errorf("this seal doesn't record its files (it predates the list); run 'rememory seal' instead")
+	}
+	if err := p.Validate(); err != nil {
+		return fmt.Errorf("invalid project: %w", err)
+	}
+
+	var ownerPassword string
+	if escrow, _ := cmd.Flags().GetBool("owner-escrow"); escrow {
+		if ownerPassword, err = readNewPassword("Owner password"); err != nil {
+			return err
+		}
+	}
+
+	opener, err := currentOpener(p, args, owner)
+	if err != nil {
+		return err
+	}
+	defer opener.Wipe()
+
+	if err := snapshot(p, "reseal"); err != nil {
+		return err
+	}
+	metrics, err := sealProjectFrom(p, recoveryURL, noEmbedManifest, ownerPassword, opener)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("\nSaved to: %s\n", filepath.Join(p.OutputPath(), "bundles"))
+	return printSealJSON(p, metrics)
+}
+
+// currentOpener returns what opens the project's current MANIFEST.age: its
+// passphrase, from share files, the project's own pieces, or the owner
+// escrow, checked against the seal and taken through the recovery delay.
+func currentOpener(p *project.Project, args []string, owner bool) (core.Secret, error) {
+	restricted := p.Sealed.Crypto == core.CryptoRestricted
+	var passphrase core.Secret
+	var err error
+	switch {
+	case owner:
+		if len(args) > 0 {
+			return nil, fmt.Errorf("--owner doesn't take share files")
+		}
+		if restricted {
+			return nil, fmt.Errorf("--owner is outside the restricted crypto profile this project was sealed under")
+		}
+		passphrase, err = unlockOwnerEscrow(p)
+	case len(args) > 0:
+		fmt.Printf("Reading %d share files...\n", len(args))
+		passphrase, err = passphraseFromShareFiles(args, restricted, true)
+	default:
+		paths := make([]string, len(p.Sealed.Shares))
+		for i, si := range p.Sealed.Shares {
+			paths[i] = filepath.Join(p.Path, si.File)
+		}
+		fmt.Printf("Using the %d pieces in %s...\n", len(paths), p.SharesPath())
+		passphrase, err = passphraseFromShareFiles(paths, restricted, true)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if !core.VerifyHash(core.HashBytes(passphrase), p.Sealed.VerificationHash) {
+		passphrase.Wipe()
+		return nil, fmt.Errorf("the passphrase doesn't match this project's seal")
+	}
+	timelockData, err := p.ReadTimelock()
+	if err != nil || timelockData == nil {
+		return passphrase, err
+	}
+	defer passphrase.Wipe()
+	timelock, err := core.ParseTimelock(timelockData)
+	if err != nil {
+		return nil, err
+	}
+	return recovery.WaitOut(os.Stdout, timelock, passphrase)
+}
+
+// reencryptManifest decrypts the MANIFEST.age at path with old and encrypts
+// what it holds under passphrase, with scrypt at workFactor (in the
+// post-quantum hybrid mode when postQuantum is set), in one stream:
+// age decrypts and encrypts in 64 KiB chunks, so memory use doesn't grow
+// with the archive. Like archiveEncrypted, it writes to a temporary file
+// first, so the current MANIFEST.age stays in place until the new one is
+// complete.
+func reencryptManifest(path string, old, passphrase core.Secret, workFactor int, postQuantum bool) (archiveStats, error) {
+	var stats archiveStats
+	src, err := os.Open(path)
+	if err != nil {
+		return stats, fmt.Errorf("opening sealed manifest: %w", err)
+	}
+	defer src.Close()
+
+	partial := path + ".partial"
+	f, err := os.OpenFile(partial, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return stats, fmt.Errorf("creating encrypted manifest: %w", err)
+	}
+	fail := func(err error) (archiveStats, error) {
+		f.Close()
+		os.Remove(partial)
+		return stats, err
+	}
+
+	kdfStart := time.Now()
+	enc, err := manifestWriter(f, passphrase, workFactor, postQuantum)
+	if err != nil {
+		return fail(err)
+	}
+	stats.KDF = time.Since(kdfStart)
+
+	counted := &countingWriter{w: enc}
+	if err := core.Decrypt(counted, src, string(old)); err != nil {
+		return fail(fmt.Errorf("decrypting the sealed manifest: %w", err))
+	}
+	closeStart := time.Now()
+	if err := enc.Close(); err != nil {
+		return fail(fmt.Errorf("finalizing encryption: %w", err))
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(partial)
+		return stats, fmt.Errorf("writing encrypted manifest: %w", err)
+	}
+	stats.Encrypt = counted.spent + time.Since(closeStart)
+	stats.Bytes = counted.n
+	if err := os.Rename(partial, path); err != nil {
+		os.Remove(partial)
+		return stats, fmt.Errorf("writing encrypted manifest: %w", err)
+	}
+	return stats, nil
+}
@@ -1,6 +1,10 @@
 package cmd
 
 import (
+	"fmt"
+	"os"
+
+	"github.com/eljojo/rememory/internal/core"
 	"github.com/spf13/cobra"
 )
 
@@ -16,12 +20,40 @@ using Shamir's Secret Sharing, and creates recovery bundles for trusted friends.
 Create a project:    rememory init my-recovery
 Seal the manifest:   rememory seal
 Recover from shares: rememory recover share1.txt share2.txt share3.txt`,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		beginJSONOutput()
+		return enableDeterministicFromEnv(cmd, args)
+	},
+}
+
+func init() {
+	rootCmd.PersistentFlags().BoolVar(&jsonOutput, "json", false, "Print a JSON result on stdout (supported by init, seal, seal-all, bundle, verify, status, log, diff, analyze, notify status); progress goes to stderr")
 }
 
+// deterministicSeedEnv seeds all randomness for reproducible test fixtures.
+// Regular builds refuse it; see core.EnableDeterministic.
+const deterministicSeedEnv = "REMEMORY_INSECURE_DETERMINISTIC_SEED"
+
 func Execute(v string) error {
 	version = v
 	rootCmd.Version = v
-	return rootCmd.Execute()
+	err := rootCmd.Execute()
+	if err != nil {
+		printJSONError(err)
+	}
+	return err
+}
+
+func enableDeterministicFromEnv(cmd *cobra.Command, args []string) error {
+	seed := os.Getenv(deterministicSeedEnv)
+	if seed == "" {
+		return nil
+	}
+	if err := core.EnableDeterministic(seed); err != nil {
+		return fmt.Errorf("%s is set: %w", deterministicSeedEnv, err)
+	}
+	fmt.Fprintln(os.Stderr, yellow("Warning: deterministic mode — output is reproducible and NOT secure. Never use it for real secrets."))
+	return nil
 }
 
 // Color helpers (ANSI escape codes)
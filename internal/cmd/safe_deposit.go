@@ -0,0 +1,185 @@
+package cmd
+
+import (
+	"archive/zip"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/eljojo/rememory/internal/core"
+	"github.com/eljojo/rememory/internal/crypto"
+	"github.com/eljojo/rememory/internal/deposit"
+	"github.com/eljojo/rememory/internal/project"
+	"github.com/spf13/cobra"
+)
+
+var safeDepositCmd = &cobra.Command{
+	Use:   "safe-deposit <friend>",
+	Short: "Write a cover sheet for a bundle kept in a safe-deposit box",
+	Long: `Safe-deposit writes SAFE-DEPOSIT-<name>.txt: a cover sheet to keep on top of
+a friend's bundle, on a USB drive or printed, in a bank's safe-deposit box.
+It records the box's details, what the box holds and why, what whoever
+opens it should do, and an inventory of the bundle's files with their
+checksums, with room to note each visit.
+
+The bank, branch, box number, and who may open the box come from the flags.
+Anything left out is printed as a line to fill in by hand. The friend's
+bundle must exist (run 'rememory bundle'); make a new sheet whenever it is
+replaced. The sheet holds no piece.
+
+Examples:
+  rememory safe-deposit Alice --bank "First National" --box 1234
+  rememory safe-deposit "Family box" --access "Maria Lopez" --access "Executor" -o /media/usb`,
+	Args: cobra.ExactArgs(1),
+	RunE: runSafeDeposit,
+}
+
+func init() {
+	safeDepositCmd.Flags().StringP("output", "o", "", "Directory to write the sheet to (default: the project's output/)")
+	safeDepositCmd.Flags().String("bank", "", "The bank holding the box")
+	safeDepositCmd.Flags().String("branch", "", "The bank's branch")
+	safeDepositCmd.Flags().String("box", "", "The box number")
+	safeDepositCmd.Flags().StringArray("access", nil, "Someone who may open the box (repeatable)")
+	rootCmd.AddCommand(safeDepositCmd)
+}
+
+func runSafeDeposit(cmd *cobra.Command, args []string) error {
+	outputDir, _ := cmd.Flags().GetString("output")
+	bank, _ := cmd.Flags().GetString("bank")
+	branch, _ := cmd.Flags().GetString("branch")
+	box, _ := cmd.Flags().GetString("box")
+	access, _ := cmd.Flags().GetStringArray("access")
+
+	p, err := loadFriendProject()
+	if err != nil {
+		return err
+	}
+	if p.Sealed == nil {
+		return fmt.Errorf("project must be sealed first (run 'rememory seal')")
+	}
+	idx := -1
+	for i, f := range p.Friends {
+		if strings.EqualFold(f.Name, strings.TrimSpace(args[0])) {
+			idx = i
+			break
+		}
+	}
+	if idx == -1 {
+		return fmt.Errorf("no friend named %q in the project", args[0])
+	}
+	friend := p.Friends[idx]
+
+	s, err := safeDepositSheet(p, idx)
+	if err != nil {
+		return err
+	}
+	s.Bank = strings.TrimSpace(bank)
+	s.Branch = strings.TrimSpace(branch)
+	s.Box = strings.TrimSpace(box)
+	for _, name := range access {
+		if name = strings.TrimSpace(name); name != "" {
+			s.Access = append(s.Access, name)
+		}
+	}
+
+	if outputDir == "" {
+		outputDir = p.OutputPath()
+	}
+	if err := os.MkdirAll(outputDir, 0700); err != nil {
+		return fmt.Errorf("creating output directory: %w", err)
+	}
+	path := filepath.Join(outputDir, deposit.FileName(friend))
+	if err := os.WriteFile(path, []byte(s.Text()), 0600); err != nil {
+		return fmt.Errorf("writing safe-deposit sheet: %w", err)
+	}
+
+	fmt.Printf("Wrote %s\n", path)
+	fmt.Printf("Print it and keep it in the box on top of %s's bundle. Make a new one whenever the bundle is replaced.\n", friend.Name)
+	return nil
+}
+
+// safeDepositSheet gathers what the sheet records about friend i's bundle
+// from a sealed project.
+func safeDepositSheet(p *project.Project, i int) (*deposit.Sheet, error) {
+	friend := p.Friends[i]
+	reviewBy, expires, err := p.Sunset()
+	if err != nil {
+		return nil, err
+	}
+	recoveryURL := p.Sealed.RecoveryURL
+	if recoveryURL == "" {
+		recoveryURL = core.DefaultRecoveryURL
+	}
+	shares, err := loadSealedShares(p)
+	if err != nil {
+		return nil, err
+	}
+	privacy := p.PrivacyLevel()
+	s := &deposit.Sheet{
+		Project:          p.Name,
+		Holder:           privacy.Holder(friend, shares[i].Index),
+		Pieces:           p.PieceIndexes(i),
+		Threshold:        p.Threshold,
+		Total:            p.TotalPieces(),
+		Groups:           p.GroupPolicy(project.Friend{}),
+		Sealed:           p.Sealed.At,
+		ManifestChecksum: p.Sealed.ManifestChecksum,
+		RecoveryURL:      recoveryURL,
+		ReviewBy:         reviewBy,
+		Expires:          expires,
+		Contact:          p.Contact,
+		Made:             core.Now(),
+		Version:          version,
+	}
+	if privacy.ListsOthers() {
+		s.Others = []project.Friend{}
+		for j, f := range p.Friends {
+			if j != i {
+				s.Others = append(s.Others, privacy.Shown(f))
+			}
+		}
+	}
+
+	bundlePath := friendBundlePath(p, friend)
+	if s.BundleSum, err = crypto.HashFile(bundlePath); err != nil {
+		return nil, fmt.Errorf("%s: %w (run 'rememory bundle' to make it)", friend.Name, err)
+	}
+	s.Bundle = filepath.Base(bundlePath)
+	if s.Contents, err = bundleInventory(bundlePath); err != nil {
+		return nil, fmt.Errorf("%s: %w", friend.Name, err)
+	}
+	return s, nil
+}
+
+// bundleInventory lists the files in a bundle ZIP with their sizes and
+// checksums, in the bundle's order.
+func bundleInventory(bundlePath string) ([]deposit.Item, error) {
+	r, err := zip.OpenReader(bundlePath)
+	if err != nil {
+		return nil, fmt.Errorf("opening bundle: %w", err)
+	}
+	defer r.Close()
+
+	var items []deposit.Item
+	for _, f := range r.File {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, fmt.Errorf("opening %s: %w", f.Name, err)
+		}
+		h := sha256.New()
+		n, err := io.Copy(h, rc)
+		rc.Close()
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %w", f.Name, err)
+		}
+		items = append(items, deposit.Item{Name: f.Name, Size: n, Checksum: "sha256:" + hex.EncodeToString(h.Sum(nil))})
+	}
+	return items, nil
+}
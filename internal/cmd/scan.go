@@ -0,0 +1,238 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"maps"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/eljojo/rememory/internal/core"
+	"github.com/spf13/cobra"
+)
+
+var scanCmd = &cobra.Command{
+	Use:   "scan [image]... [--camera]",
+	Short: "Read a share from a photo of its QR code, or from a webcam",
+	Long: `Scan decodes the QR code on a printed README, from a photo or scan of the
+page or live from a webcam, so nobody has to retype the piece by hand.
+
+A piece whose link was too long for one QR code is printed as several; scan
+them all, from one photo or several, and they are put back together.
+
+Each piece found is shown as 'rememory inspect' would. With --output the
+pieces are also saved as share files, ready for 'rememory recover'.
+
+QR codes are decoded with ZBar (brew install zbar, apt install zbar-tools):
+zbarimg for images, zbarcam for --camera.
+
+Examples:
+  rememory scan photo-from-bob.jpg
+  rememory scan photo-from-bob.jpg photo-from-camila.jpg -o pieces/
+  rememory recover pieces/SHARE-*.txt SHARE-alice.txt -m MANIFEST.age
+
+  rememory scan --camera -o pieces/
+  rememory scan photo.jpg --compact       # just the RM2:... string`,
+	RunE: runScan,
+}
+
+func init() {
+	scanCmd.Flags().Bool("camera", false, "Read the QR code from a webcam instead of an image")
+	scanCmd.Flags().String("device", "", "Video device for --camera (default: the system's first camera)")
+	scanCmd.Flags().StringP("output", "o", "", "Save each piece as a share file in this directory")
+	scanCmd.Flags().Bool("compact", false, "Print only the compact share string for each piece")
+	rootCmd.AddCommand(scanCmd)
+}
+
+func runScan(cmd *cobra.Command, args []string) error {
+	camera, _ := cmd.Flags().GetBool("camera")
+	device, _ := cmd.Flags().GetString("device")
+	outputDir, _ := cmd.Flags().GetString("output")
+	compactOnly, _ := cmd.Flags().GetBool("compact")
+
+	type found struct {
+		source string
+		share  *core.Share
+	}
+	var pieces []found
+	var chunks []*core.QRChunk
+	chunkSources := make(map[string][]string)
+	add := func(source string, payloads []string) error {
+		for _, payload := range payloads {
+			// Parts of a split code are put together once all are read
+			if core.IsQRChunk(payload) {
+				chunk, err := core.ParseQRChunk(payload)
+				if err != nil {
+					return fmt.Errorf("%s: %w", source, err)
+				}
+				chunks = append(chunks, chunk)
+				chunkSources[chunk.ID] = append(chunkSources[chunk.ID], source)
+				continue
+			}
+			share, err := shareFromQR(payload)
+			if err != nil {
+				return fmt.Errorf("%s: %w", source, err)
+			}
+			pieces = append(pieces, found{source, share})
+		}
+		return nil
+	}
+
+	switch {
+	case camera && len(args) > 0:
+		return fmt.Errorf("pass either images or --camera, not both")
+	case camera:
+		for {
+			payloads, err := scanCamera(device)
+			if err != nil {
+				return err
+			}
+			if err := add("camera", payloads); err != nil {
+				return err
+			}
+			if len(chunks) == 0 {
+				break
+			}
+			group := core.GroupQRChunks(chunks)[chunks[len(chunks)-1].ID]
+			missing := core.MissingQRChunks(group)
+			if len(missing) == 0 {
+				break
+			}
+			fmt.Fprintf(os.Stderr, "Read %d of %d codes for this piece.\n", group[0].Total-len(missing), group[0].Total)
+		}
+	case len(args) > 0:
+		if _, err := exec.LookPath("zbarimg"); err != nil {
+			return fmt.Errorf("zbarimg not found; install ZBar to decode QR codes (brew install zbar, apt install zbar-tools)")
+		}
+		for _, path := range args {
+			payloads, err := decodeQR(path)
+			if err != nil {
+				return fmt.Errorf("%s: %w", path, err)
+			}
+			if err := add(path, payloads); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("no images given (or use --camera)")
+	}
+
+	groups := core.GroupQRChunks(chunks)
+	for _, id := range slices.Sorted(maps.Keys(groups)) {
+		group := groups[id]
+		source := strings.Join(slices.Compact(chunkSources[id]), ", ")
+		content, err := core.JoinQRChunks(group)
+		if err != nil {
+			return fmt.Errorf("%s: %w", source, err)
+		}
+		share, err := shareFromQR(content)
+		if err != nil {
+			return fmt.Errorf("%s: %w", source, err)
+		}
+		pieces = append(pieces, found{source, share})
+	}
+
+	var saved []string
+	for i, p := range pieces {
+		if compactOnly {
+			fmt.Println(p.share.CompactEncode())
+		} else {
+			if i > 0 {
+				fmt.Println()
+			}
+			fmt.Printf("Piece from %s\n\n", p.source)
+			printShareInfo(p.share, green("OK")+" (short check matched)")
+		}
+
+		if outputDir != "" {
+			path, err := saveScannedShare(outputDir, p.share)
+			if err != nil {
+				return err
+			}
+			saved = append(saved, path)
+		}
+	}
+
+	if len(saved) > 0 && !compactOnly {
+		fmt.Println()
+		for _, path := range saved {
+			fmt.Printf("%s Saved %s\n", green("✓"), path)
+		}
+		fmt.Printf("Next: rememory recover %s ... --manifest MANIFEST.age\n", strings.Join(saved, " "))
+	}
+	return nil
+}
+
+// shareFromQR parses a QR code's contents, either a recovery URL with
+// #share=... or a bare compact share.
+func shareFromQR(payload string) (*core.Share, error) {
+	compact, ok := compactFromURL(payload)
+	if !ok {
+		compact = strings.TrimSpace(payload)
+	}
+	if !strings.HasPrefix(compact, "RM") {
+		return nil, fmt.Errorf("QR code doesn't hold a ReMemory piece")
+	}
+	share, err := core.ParseCompact(compact)
+	if err != nil {
+		return nil, fmt.Errorf("QR code is damaged: %w", err)
+	}
+	return share, nil
+}
+
+// saveScannedShare writes the share as SHARE-<index>.txt in dir, refusing to
+// overwrite an existing file.
+func saveScannedShare(dir string, share *core.Share) (string, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", fmt.Errorf("creating %s: %w", dir, err)
+	}
+	path := filepath.Join(dir, share.Filename())
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		if os.IsExist(err) {
+			return "", fmt.Errorf("%s already exists", path)
+		}
+		return "", fmt.Errorf("writing %s: %w", path, err)
+	}
+	if _, err := f.WriteString(share.Encode()); err != nil {
+		f.Close()
+		return "", fmt.Errorf("writing %s: %w", path, err)
+	}
+	if err := f.Close(); err != nil {
+		return "", fmt.Errorf("writing %s: %w", path, err)
+	}
+	return path, nil
+}
+
+// scanCamera opens a webcam preview with zbarcam and returns the first QR
+// code it sees.
+func scanCamera(device string) ([]string, error) {
+	if _, err := exec.LookPath("zbarcam"); err != nil {
+		return nil, fmt.Errorf("zbarcam not found; install ZBar with camera support (apt install zbar-tools), or take a photo and scan that instead")
+	}
+	args := []string{"--raw", "--oneshot", "-Sdisable", "-Sqrcode.enable"}
+	if device != "" {
+		args = append(args, device)
+	}
+
+	fmt.Fprintln(os.Stderr, "Hold the QR code up to the camera...")
+	cmd := exec.Command("zbarcam", args...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("reading camera: %s", msg)
+		}
+		return nil, fmt.Errorf("reading camera: %w", err)
+	}
+
+	payload := strings.TrimSpace(stdout.String())
+	if payload == "" {
+		return nil, fmt.Errorf("no QR code read from the camera")
+	}
+	return []string{payload}, nil
+}
@@ -2,10 +2,14 @@ package cmd
 
 import (
 	"bytes"
-	"encoding/base64"
 	"fmt"
+	"io"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/eljojo/rememory/internal/bundle"
@@ -14,7 +18,14 @@ import (
 	"github.com/eljojo/rememory/internal/html"
 	"github.com/eljojo/rememory/internal/manifest"
 	"github.com/eljojo/rememory/internal/project"
+	"github.com/eljojo/rememory/internal/recovery"
+	"github.com/eljojo/rememory/internal/rotation"
+	"github.com/eljojo/rememory/internal/seed"
+	"github.com/eljojo/rememory/internal/transfer"
+	"github.com/eljojo/rememory/internal/vault"
 	"github.com/spf13/cobra"
+	"golang.org/x/term"
+	"gopkg.in/yaml.v3"
 )
 
 var sealCmd = &cobra.Command{
@@ -31,17 +42,235 @@ This command:
   5. Generates ZIP bundles for distribution
   6. Writes checksums to project.yml
 
-Run this command inside a project directory (created with 'rememory init').`,
+Run this command inside a project directory (created with 'rememory init').
+
+To seal on an air-gapped machine, run 'rememory prepare' on your everyday
+computer, carry the transfer file over, and run:
+  rememory seal --offline transfer.rememory --fingerprint <fingerprint>
+This creates the project in a new directory, named after the project, inside
+the current directory and seals it there.
+
+--stdin and --exec add data that shouldn't sit unencrypted in manifest/,
+such as a database dump or exported keys, as one more file in the sealed
+manifest. It's named with --name and recorded in the file list like the
+others:
+  pg_dump mydb | rememory seal --stdin --name mydb.sql
+  rememory seal --exec "pg_dump mydb" --name mydb.sql
+
+--vault seals a password-manager export (Bitwarden .json, 1Password .1pux,
+KeePass .xml, or a CSV export) as manifest/passwords.json, in one format
+that recover.html shows as a readable list. --vault-from runs the manager's
+own tool instead, so no export file is written:
+  rememory seal --vault bitwarden_export.json
+  rememory seal --vault-from bitwarden
+  rememory seal --vault-from keepassxc:passwords.kdbx
+
+--seed seals a cryptocurrency wallet's seed phrase as manifest/seed-phrase.json.
+You type the phrase twice, and it's checked against the BIP39 word lists and
+checksum before anything is sealed; --seed-unchecked skips that check for
+wallets that don't use BIP39, like Electrum. recover.html shows the words
+numbered, hidden until asked:
+  rememory seal --seed "Ledger (savings)"
+
+--format also gives each friend their piece in another tool's format, and
+saves the choice in project.yml:
+  slip39  SLIP-0039 words, for hardware wallets and python-shamir-mnemonic
+  sskr    an SSKR share, for Blockchain Commons tools
+  ssss    a share line for the classic ssss-combine tool
+
+--volume-size also splits MANIFEST.age into volumes of at most that size,
+MANIFEST.age.001, .002, and so on, with their checksums in MANIFEST.age.sha256,
+so a big archive fits on FAT32 drives (4G) or DVDs (4.7G). Bundles carry the
+volumes in place of MANIFEST.age, and recovery puts them back together. The
+size is saved in project.yml as volume_size, so later seals keep it:
+  rememory seal --volume-size 4G
+
+--parity writes MANIFEST.age.parity next to it: Reed-Solomon parity, that
+percent of MANIFEST.age's size, with a checksum of every block. Bundles carry
+it, and recovery uses it to repair blocks that went bad on a drive or a disc
+before decrypting. It is saved in project.yml as parity:
+  rememory seal --parity 10
+
+--exclude leaves out files in manifest/ that match a pattern, in .gitignore's
+syntax, on top of what manifest/.rememoryignore lists: caches, node_modules,
+or the files a Mac or Windows leaves behind. Give it once per pattern; the
+patterns are saved in project.yml as exclude, and --exclude "" clears them.
+What was left out is listed after sealing:
+  rememory seal --exclude node_modules/ --exclude "*.tmp"
+
+--symlinks says what is done with symlinks in manifest/. By default they're
+skipped, with a warning. keep seals each as a link, and recovery on a
+computer makes it again when it points within the recovered folder; follow
+seals what each points to under the link's name, a linked folder with
+everything in it. It is saved in project.yml as symlinks:
+  rememory seal --symlinks keep
+
+Every file and folder is sealed with its permissions and modification time,
+which recovery on a computer restores. --xattrs also seals their extended
+attributes, like a Mac's Finder tags or the user.* attributes some photo
+tools keep on Linux; Linux's security and ACL attributes stay behind. It is
+saved in project.yml as xattrs, and --xattrs=false turns it off:
+  rememory seal --xattrs
+
+--max-memory caps the memory unlocking MANIFEST.age needs, for heirs who
+may recover on an old phone or a small laptop. scrypt, which guards it,
+takes 256M by default, more than some browsers give a tab; below that, it
+is given the most that fits, which the pieces record, so recover.html can
+say up front when a device can't spare it. It is saved in project.yml as
+max_memory, and also caps what a recovery_time budget chooses:
+  rememory seal --max-memory 128M
+
+--master-code also makes a master code: an age key that unlocks the
+passphrase on its own, through RECIPIENTS.age, for when you want a way back
+in that needs none of your friends. It is written once, as a QR code and 24
+words, to output/MASTER-CODE.pdf, and its public key is saved in project.yml
+as master_code, so later seals keep working with the same printed code. Print
+the sheet, keep it in a vault or a safe deposit box, and delete the file.
+'rememory unseal --master-code' uses it. --new-master-code replaces a code
+that was lost or seen by someone else:
+  rememory seal --master-code
+
+--update seals manifest/ again under the current passphrase, when files
+changed since the last seal. The pieces stay the same, so bundles friends
+already hold keep working and only MANIFEST.age needs handing out again.
+Every file is encrypted again, unchanged ones too: reusing the old
+MANIFEST.age's encrypted chunks would reuse its key stream. It needs the
+pieces in output/shares/, and seals with a recovery delay or a catalog need
+a full seal:
+  rememory seal --update
+
+--deterministic seals reproducibly: the randomness comes from a seed in the
+given file, and the seal's time is fixed, so sealing the same manifest/ and
+project.yml again gives byte-identical MANIFEST.age, pieces, and bundles.
+An auditor with the seed file can rebuild what you handed out and compare.
+The file is created, with a random seed, the first time. Anyone who has it
+can rebuild the passphrase:
+  rememory seal --deterministic ../seal-seed.yml`,
 	RunE: runSeal,
 }
 
 func init() {
 	sealCmd.Flags().String("recovery-url", core.DefaultRecoveryURL, "Base URL for QR code in PDF")
 	sealCmd.Flags().Bool("no-embed-manifest", false, "Do not embed MANIFEST.age in recover.html (it is embedded by default when 5 MB or less)")
+	sealCmd.Flags().Bool("owner-escrow", false, "Also keep a copy of the passphrase locked with a password of your own (for 'rememory unseal --owner')")
+	sealCmd.Flags().String("offline", "", "Seal from a transfer file made by 'rememory prepare'")
+	sealCmd.Flags().String("fingerprint", "", "Expected transfer fingerprint (with --offline)")
+	sealCmd.Flags().StringSlice("format", nil, "Also write each piece as slip39, sskr, or ssss (comma-separated)")
+	sealCmd.Flags().Bool("stdin", false, "Also seal the data piped to standard input, as a file named by --name")
+	sealCmd.Flags().String("exec", "", "Also seal the output of this shell command, as a file named by --name")
+	sealCmd.Flags().String("vault", "", "Also seal this password-manager export as passwords.json (- reads standard input)")
+	sealCmd.Flags().String("vault-from", "", "Also seal a vault exported by its manager's tool: bitwarden, or keepassxc:FILE")
+	sealCmd.Flags().String("seed", "", "Also seal a wallet's seed phrase, typed in twice; the value names the wallet")
+	sealCmd.Flags().Bool("seed-unchecked", false, "Seal a --seed phrase that isn't BIP39 without checking its words")
+	sealCmd.Flags().String("name", "", "File name for the --stdin or --exec data inside manifest/ (default for --exec: the program's name with .out)")
+	sealCmd.Flags().String("tmpdir", "", "Folder where --stdin, --exec, --vault, and --seed data wait, encrypted, until archived (default: the system's temporary folder)")
+	sealCmd.Flags().Bool("memory-temp", false, "Keep --stdin, --exec, --vault, and --seed data in memory instead of a temporary file (up to 64 MB)")
+	sealCmd.Flags().Int("parity", 0, "Also write Reed-Solomon parity of this percent of MANIFEST.age, like 10, to repair bit rot, and save it in project.yml")
+	sealCmd.Flags().String("volume-size", "", "Also split MANIFEST.age into volumes of at most this size, like 4G or 700M, and save it in project.yml")
+	sealCmd.Flags().StringArray("exclude", nil, "Leave out files in manifest/ matching this .gitignore pattern (repeatable), and save the patterns in project.yml")
+	sealCmd.Flags().String("symlinks", "", "What to do with symlinks in manifest/: skip, keep (as links), or follow, and save it in project.yml (default skip)")
+	sealCmd.Flags().Bool("xattrs", false, "Also seal the extended attributes of files in manifest/, and save it in project.yml")
+	sealCmd.Flags().String("max-memory", "", "Most memory unlocking MANIFEST.age may need, like 128M, for low-memory devices, and save it in project.yml (default 256M)")
+	sealCmd.Flags().Bool("master-code", false, "Also make a master code that unlocks the passphrase alone, printed once to output/MASTER-CODE.pdf")
+	sealCmd.Flags().Bool("new-master-code", false, "Replace the project's master code with a new one, printed to output/MASTER-CODE.pdf")
+	sealCmd.Flags().Bool("update", false, "Seal manifest/ again under the current passphrase and pieces, if it changed, so only MANIFEST.age is new")
+	sealCmd.Flags().String("deterministic", "", "Seal reproducibly from the seed and time in this file, creating it if it doesn't exist")
 	rootCmd.AddCommand(sealCmd)
 }
 
 func runSeal(cmd *cobra.Command, args []string) error {
+	recoveryURL, _ := cmd.Flags().GetString("recovery-url")
+	noEmbedManifest, _ := cmd.Flags().GetBool("no-embed-manifest")
+	formats, _ := cmd.Flags().GetStringSlice("format")
+	if err := checkShareFormats(formats); err != nil {
+		return err
+	}
+	volumeSize, _ := cmd.Flags().GetString("volume-size")
+	if volumeSize != "" {
+		if _, err := core.ParseVolumeSize(volumeSize); err != nil {
+			return fmt.Errorf("--volume-size: %w", err)
+		}
+	}
+	parity, _ := cmd.Flags().GetInt("parity")
+	if cmd.Flags().Changed("parity") && (parity < 0 || parity > 100) {
+		return fmt.Errorf("--parity must be a percent from 1 to 100, or 0 for none")
+	}
+
+	var ownerPassword string
+	if escrow, _ := cmd.Flags().GetBool("owner-escrow"); escrow {
+		var err error
+		ownerPassword, err = readNewPassword("Owner password")
+		if err != nil {
+			return err
+		}
+	}
+
+	fromStdin, _ := cmd.Flags().GetBool("stdin")
+	command, _ := cmd.Flags().GetString("exec")
+	name, _ := cmd.Flags().GetString("name")
+	vaultPath, _ := cmd.Flags().GetString("vault")
+	vaultFrom, _ := cmd.Flags().GetString("vault-from")
+	seedLabel, _ := cmd.Flags().GetString("seed")
+	seedUnchecked, _ := cmd.Flags().GetBool("seed-unchecked")
+	var spool manifest.Spool
+	spool.Dir, _ = cmd.Flags().GetString("tmpdir")
+	spool.Memory, _ = cmd.Flags().GetBool("memory-temp")
+	if spool.Dir != "" && spool.Memory {
+		return fmt.Errorf("use either --tmpdir or --memory-temp, not both")
+	}
+	seedPath, _ := cmd.Flags().GetString("deterministic")
+	masterCode, _ := cmd.Flags().GetBool("master-code")
+	newMasterCode, _ := cmd.Flags().GetBool("new-master-code")
+	update, _ := cmd.Flags().GetBool("update")
+	if update {
+		switch {
+		case masterCode || newMasterCode:
+			return fmt.Errorf("--update keeps RECIPIENTS.age as it is; --master-code and --new-master-code need a new seal")
+		case seedPath != "":
+			return fmt.Errorf("--update can't be used with --deterministic, which makes a new passphrase from its seed")
+		case ownerPassword != "":
+			return fmt.Errorf("--update keeps the passphrase, and with it the owner escrow; leave out --owner-escrow")
+		case len(formats) > 0:
+			return fmt.Errorf("--update keeps the pieces as they are; --format needs a new seal")
+		case cmd.Flags().Changed("max-memory"):
+			return fmt.Errorf("--update keeps the work factor the pieces record; --max-memory needs a new seal")
+		}
+	}
+	if offline, _ := cmd.Flags().GetString("offline"); offline != "" {
+		if seedPath != "" {
+			return fmt.Errorf("--deterministic can't be used with --offline; seal the project it creates with --deterministic instead")
+		}
+		if fromStdin || command != "" || vaultPath != "" || vaultFrom != "" || seedLabel != "" {
+			return fmt.Errorf("--stdin, --exec, --vault, and --seed can't be used with --offline; put the data in manifest/ before 'rememory prepare'")
+		}
+		if update {
+			return fmt.Errorf("--update can't be used with --offline, which seals a new project")
+		}
+		if volumeSize != "" {
+			return fmt.Errorf("--volume-size can't be used with --offline; set volume_size in project.yml before 'rememory prepare'")
+		}
+		if cmd.Flags().Changed("parity") {
+			return fmt.Errorf("--parity can't be used with --offline; set parity in project.yml before 'rememory prepare'")
+		}
+		if cmd.Flags().Changed("exclude") {
+			return fmt.Errorf("--exclude can't be used with --offline; set exclude in project.yml before 'rememory prepare'")
+		}
+		if cmd.Flags().Changed("symlinks") {
+			return fmt.Errorf("--symlinks can't be used with --offline; set symlinks in project.yml before 'rememory prepare'")
+		}
+		if cmd.Flags().Changed("xattrs") {
+			return fmt.Errorf("--xattrs can't be used with --offline; set xattrs in project.yml before 'rememory prepare'")
+		}
+		if cmd.Flags().Changed("max-memory") {
+			return fmt.Errorf("--max-memory can't be used with --offline; set max_memory in project.yml before 'rememory prepare'")
+		}
+		if masterCode || newMasterCode {
+			return fmt.Errorf("--master-code can't be used with --offline; run 'rememory seal --master-code' in the project it creates")
+		}
+		fingerprint, _ := cmd.Flags().GetString("fingerprint")
+		return runSealOffline(offline, fingerprint, recoveryURL, noEmbedManifest, ownerPassword, formats)
+	}
+
 	// Find and load the project
 	cwd, err := os.Getwd()
 	if err != nil {
@@ -57,108 +286,657 @@ func runSeal(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return fmt.Errorf("loading project: %w", err)
 	}
+	applyShareFormats(p, formats)
+	if volumeSize != "" {
+		p.VolumeSize = volumeSize
+	}
+	if cmd.Flags().Changed("parity") {
+		p.Parity = parity
+	}
+	if cmd.Flags().Changed("exclude") {
+		excludes, _ := cmd.Flags().GetStringArray("exclude")
+		p.Exclude = slices.DeleteFunc(excludes, func(s string) bool { return strings.TrimSpace(s) == "" })
+	}
+	if cmd.Flags().Changed("symlinks") {
+		p.Symlinks, _ = cmd.Flags().GetString("symlinks")
+	}
+	if cmd.Flags().Changed("xattrs") {
+		p.Xattrs, _ = cmd.Flags().GetBool("xattrs")
+	}
+	if cmd.Flags().Changed("max-memory") {
+		p.MaxMemory, _ = cmd.Flags().GetString("max-memory")
+	}
+	if newMasterCode {
+		p.MasterCode = "" // Made again below
+	}
 
 	if err := p.Validate(); err != nil {
 		return fmt.Errorf("invalid project: %w", err)
 	}
 
-	recoveryURL, _ := cmd.Flags().GetString("recovery-url")
-	noEmbedManifest, _ := cmd.Flags().GetBool("no-embed-manifest")
+	if seedPath != "" {
+		restore, err := enableReproducible(p, seedPath)
+		if err != nil {
+			return err
+		}
+		defer restore()
+	}
+
+	// Made after the seed, so a deterministic seal makes the same code
+	var code core.Secret
+	if newMasterCode || masterCode && p.MasterCode == "" {
+		if p.Crypto == core.CryptoRestricted {
+			return fmt.Errorf("--master-code is outside the restricted crypto profile: it unlocks the passphrase without the pieces")
+		}
+		if code, err = makeMasterCode(p); err != nil {
+			return err
+		}
+		defer code.Wipe()
+	}
+
+	var payloads []*manifest.Payload
+	if fromStdin || command != "" {
+		payload, err := readPayload(spool, fromStdin, command, name)
+		if err != nil {
+			return err
+		}
+		defer payload.Remove()
+		payloads = append(payloads, payload)
+	} else if name != "" {
+		return fmt.Errorf("--name only applies to --stdin or --exec")
+	}
+	if vaultPath != "" || vaultFrom != "" {
+		if vaultPath == "-" && fromStdin {
+			return fmt.Errorf("--stdin and --vault - both read standard input; use one of them")
+		}
+		payload, err := readVault(spool, vaultPath, vaultFrom)
+		if err != nil {
+			return err
+		}
+		defer payload.Remove()
+		payloads = append(payloads, payload)
+	}
+	if seedLabel != "" {
+		if fromStdin || vaultPath == "-" {
+			return fmt.Errorf("--seed is typed in on standard input, which --stdin and --vault - already read")
+		}
+		payload, err := readSeed(spool, seedLabel, seedUnchecked)
+		if err != nil {
+			return err
+		}
+		defer payload.Remove()
+		payloads = append(payloads, payload)
+	} else if seedUnchecked {
+		return fmt.Errorf("--seed-unchecked only applies to --seed")
+	}
+
+	if update {
+		metrics, err := sealUpdate(p, recoveryURL, noEmbedManifest, payloads...)
+		if err != nil {
+			return err
+		}
+		if metrics != nil {
+			fmt.Printf("\nSaved to: %s\n", filepath.Join(p.OutputPath(), "bundles"))
+		}
+		return printSealJSON(p, metrics)
+	}
 
-	if err := sealProject(p, recoveryURL, noEmbedManifest); err != nil {
+	if err := snapshot(p, "seal"); err != nil {
+		return err
+	}
+
+	metrics, err := sealProject(p, recoveryURL, noEmbedManifest, ownerPassword, payloads...)
+	if err != nil {
 		return err
 	}
 
 	bundlesDir := filepath.Join(p.OutputPath(), "bundles")
 	fmt.Printf("\nSaved to: %s\n", bundlesDir)
 
+	if code != nil {
+		if err := writeMasterCodeSheet(p, code); err != nil {
+			return err
+		}
+		relSheet, _ := filepath.Rel(p.Path, p.MasterCodePath())
+		fmt.Printf("\n%s Your master code is in %s, and nowhere else.\n", yellow("!"), relSheet)
+		fmt.Println("  It opens everything without your friends' pieces: print it, keep it in a vault")
+		fmt.Println("  or a safe deposit box, and delete the file.")
+	} else if masterCode {
+		fmt.Println("\nThe project already has a master code; the printed one still works.")
+	}
+
+	return printSealJSON(p, metrics)
+}
+
+// runSealOffline unpacks a transfer file into a new project directory and seals it.
+func runSealOffline(transferPath, expectedFingerprint, recoveryURL string, noEmbedManifest bool, ownerPassword string, formats []string) error {
+	contents, fingerprint, err := transfer.Read(transferPath)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Transfer fingerprint: %s\n", fingerprint)
+	if expectedFingerprint != "" {
+		if !strings.EqualFold(strings.TrimSpace(expectedFingerprint), fingerprint) {
+			return fmt.Errorf("fingerprint mismatch: expected %s — this is not the file you prepared", expectedFingerprint)
+		}
+		fmt.Printf("  %s matches\n", green("✓"))
+	} else {
+		fmt.Printf("  %s compare it with the fingerprint shown by 'rememory prepare'\n", yellow("!"))
+	}
+	fmt.Println()
+
+	// Read the name first so the directory can be named after the project
+	var p project.Project
+	if err := yaml.Unmarshal(contents.Project, &p); err != nil {
+		return fmt.Errorf("parsing project file: %w", err)
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("getting current directory: %w", err)
+	}
+	projectDir := filepath.Join(cwd, core.SanitizeFilename(p.Name))
+	if _, err := os.Stat(projectDir); err == nil {
+		return fmt.Errorf("%s already exists", projectDir)
+	}
+
+	if err := os.MkdirAll(projectDir, 0755); err != nil {
+		return fmt.Errorf("creating project directory: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(projectDir, project.ProjectFileName), contents.Project, 0644); err != nil {
+		return fmt.Errorf("writing project file: %w", err)
+	}
+	extractResult, err := manifest.Extract(bytes.NewReader(contents.Manifest), projectDir, true)
+	if err != nil {
+		return fmt.Errorf("extracting manifest: %w", err)
+	}
+	for _, warning := range extractResult.Warnings {
+		fmt.Printf("  Warning: %s\n", warning)
+	}
+
+	loaded, err := project.Load(projectDir)
+	if err != nil {
+		return fmt.Errorf("loading project: %w", err)
+	}
+	applyShareFormats(loaded, formats)
+	if err := loaded.Validate(); err != nil {
+		return fmt.Errorf("invalid project: %w", err)
+	}
+
+	metrics, err := sealProject(loaded, recoveryURL, noEmbedManifest, ownerPassword)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("\nSaved to: %s\n", filepath.Join(loaded.OutputPath(), "bundles"))
+	return printSealJSON(loaded, metrics)
+}
+
+// shareFormats are the --format values: other tools' formats each piece can
+// also be written in.
+var shareFormats = []string{"slip39", "sskr", "ssss"}
+
+func checkShareFormats(formats []string) error {
+	for _, f := range formats {
+		if !slices.Contains(shareFormats, f) {
+			return fmt.Errorf("unknown --format %q (available: %s)", f, strings.Join(shareFormats, ", "))
+		}
+	}
 	return nil
 }
 
+// applyShareFormats turns on the project settings for the --format values.
+// sealProject saves them to project.yml, so later seals keep them.
+func applyShareFormats(p *project.Project, formats []string) {
+	for _, f := range formats {
+		switch f {
+		case "slip39":
+			p.SLIP39 = true
+		case "sskr":
+			p.SSKR = true
+		case "ssss":
+			p.SSSS = true
+		}
+	}
+}
+
+type sealResult struct {
+	Project          string       `json:"project"`
+	Path             string       `json:"path"`
+	SealedAt         time.Time    `json:"sealedAt"`
+	Manifest         string       `json:"manifest"`
+	ManifestChecksum string       `json:"manifestChecksum"`
+	Threshold        int          `json:"threshold"`
+	Total            int          `json:"total"`
+	Shares           []jsonShare  `json:"shares"`
+	Bundles          []jsonBundle `json:"bundles"`
+	OwnerEscrow      string       `json:"ownerEscrow,omitempty"`
+	MasterCode       string       `json:"masterCode,omitempty"` // The master code sheet, until it's deleted
+	Crypto           string       `json:"crypto,omitempty"`
+	Metrics          *sealMetrics `json:"metrics,omitempty"`
+}
+
+// sealMetrics measures the manifest as it goes through the seal, and how
+// long each stage took, so archive growth can be followed from seal to seal.
+type sealMetrics struct {
+	Files            int         `json:"files"`
+	InputBytes       int64       `json:"inputBytes"`               // manifest/ on disk
+	ArchiveBytes     int64       `json:"archiveBytes"`             // The compressed tar.gz
+	EncryptedBytes   int64       `json:"encryptedBytes"`           // MANIFEST.age
+	CompressionRatio float64     `json:"compressionRatio"`         // archiveBytes / inputBytes
+	Ignored          []string    `json:"ignored,omitempty"`        // Left out by .rememoryignore and exclude
+	DuplicateBytes   int64       `json:"duplicateBytes,omitempty"` // Copies of other files, stored once
+	Stages           []sealStage `json:"stages"`
+	TotalMillis      float64     `json:"totalMs"`
+}
+
+// sealStage is one step of a seal. Throughput is the bytes the stage read
+// per second, for the stages that read the manifest.
+type sealStage struct {
+	Name           string  `json:"name"`
+	Millis         float64 `json:"ms"`
+	Bytes          int64   `json:"bytes,omitempty"`
+	BytesPerSecond float64 `json:"bytesPerSecond,omitempty"`
+}
+
+// stage records a stage that started at start and read n bytes, if any.
+func (m *sealMetrics) stage(name string, start time.Time, n int64) {
+	m.took(name, time.Since(start), n)
+}
+
+// took records a stage that took elapsed and read n bytes, if any, for
+// stages timed in pieces.
+func (m *sealMetrics) took(name string, elapsed time.Duration, n int64) {
+	st := sealStage{Name: name, Millis: float64(elapsed.Microseconds()) / 1000, Bytes: n}
+	if n > 0 && elapsed > 0 {
+		st.BytesPerSecond = float64(n) / elapsed.Seconds()
+	}
+	m.Stages = append(m.Stages, st)
+}
+
+// printSealJSON prints the --json result for a freshly sealed project.
+func printSealJSON(p *project.Project, metrics *sealMetrics) error {
+	if !jsonOutput {
+		return nil
+	}
+	result := sealResult{
+		Project:          p.Name,
+		Path:             p.Path,
+		SealedAt:         p.Sealed.At,
+		Manifest:         p.ManifestAgePath(),
+		ManifestChecksum: p.Sealed.ManifestChecksum,
+		Threshold:        p.Threshold,
+		Total:            p.TotalPieces(),
+		Shares:           jsonShares(p),
+		Bundles:          jsonBundles(p),
+		Crypto:           p.Sealed.Crypto,
+		Metrics:          metrics,
+	}
+	escrowPath := filepath.Join(p.OutputPath(), ownerEscrowFile)
+	if _, err := os.Stat(escrowPath); err == nil {
+		result.OwnerEscrow = escrowPath
+	}
+	if _, err := os.Stat(p.MasterCodePath()); err == nil {
+		result.MasterCode = p.MasterCodePath()
+	}
+	return printJSON(result)
+}
+
+// splitPassphrase splits the raw passphrase into one share per piece, in
+// index order: each friend's first piece, in the order of p.Friends, then
+// the extra pieces of friends who hold more than one. Grouped projects split
+// it by group first; each share then records its group. Every share starts
+// with the header naming bundleID, as raw does.
+func splitPassphrase(p *project.Project, raw []byte, bundleID string) ([]*core.Share, error) {
+	privacy := p.PrivacyLevel()
+	if !p.Grouped() {
+		total := p.TotalPieces()
+		data, err := core.Split(raw, total, p.Threshold)
+		if err != nil {
+			return nil, err
+		}
+		if err := core.BindShares(data, bundleID); err != nil {
+			return nil, err
+		}
+		shares := make([]*core.Share, total)
+		for i, friend := range p.Friends {
+			for _, index := range p.PieceIndexes(i) {
+				shares[index-1] = core.NewShare(2, index, total, p.Threshold, privacy.Holder(friend, index), data[index-1])
+			}
+		}
+		return shares, nil
+	}
+
+	shares := make([]*core.Share, len(p.Friends))
+
+	pieces, err := core.SplitGroups(raw, p.Threshold, p.GroupSpecs())
+	if err != nil {
+		return nil, err
+	}
+	for _, group := range pieces {
+		if err := core.BindShares(group, bundleID); err != nil {
+			return nil, err
+		}
+	}
+	next := make([]int, len(p.Groups))
+	for i, friend := range p.Friends {
+		g := p.GroupIndex(friend)
+		share := core.NewShare(2, i+1, len(p.Friends), p.Groups[g-1].Threshold, privacy.Holder(friend, i+1), pieces[g-1][next[g-1]])
+		share.Group, share.Groups, share.GroupsNeeded = g, len(p.Groups), p.Threshold
+		next[g-1]++
+		shares[i] = share
+	}
+	return shares, nil
+}
+
+// sealCatalog encrypts the list of sealed files under a new catalog key,
+// when the project has a catalog, and gives each piece its share of the
+// key. It returns nil without a catalog.
+func sealCatalog(p *project.Project, shares []*core.Share, files []manifest.File) ([]byte, error) {
+	if p.Catalog == nil {
+		return nil, nil
+	}
+	key, err := core.NewCatalogKey()
+	if err != nil {
+		return nil, err
+	}
+	defer core.Wipe(key)
+	if err := core.SplitCatalogKey(key, p.Catalog.Threshold, shares); err != nil {
+		return nil, err
+	}
+	catalog := &core.Catalog{Project: p.Name, Description: p.Catalog.Description, Sealed: core.Now().UTC()}
+	for _, f := range files {
+		catalog.Files = append(catalog.Files, core.CatalogEntry{Path: f.Path, Size: f.Size})
+	}
+	fmt.Printf("Writing the catalog (%d file%s, opens with %d pieces)...\n", len(files), plural(len(files)), p.Catalog.Threshold)
+	return core.EncryptCatalog(catalog, key)
+}
+
+// friendPieces sorts shares in index order, as splitPassphrase returns
+// them, into each friend's pieces.
+func friendPieces(p *project.Project, shares []*core.Share) [][]*core.Share {
+	pieces := make([][]*core.Share, len(p.Friends))
+	for i := range p.Friends {
+		for _, index := range p.PieceIndexes(i) {
+			pieces[i] = append(pieces[i], shares[index-1])
+		}
+	}
+	return pieces
+}
+
+// combineFewest recovers the passphrase from as few of the shares as the
+// project allows: the first threshold of them, or for grouped projects the
+// first threshold of each of the groups needed.
+func combineFewest(p *project.Project, shares []*core.Share) ([]byte, error) {
+	if !p.Grouped() {
+		data := make([][]byte, p.Threshold)
+		for i := range data {
+			data[i] = shares[i].Data
+		}
+		return core.Combine(data)
+	}
+	var fewest []*core.Share
+	taken := make(map[int]int)
+	for _, s := range shares {
+		if s.Group <= p.Threshold && taken[s.Group] < s.Threshold {
+			fewest = append(fewest, s)
+			taken[s.Group]++
+		}
+	}
+	return core.CombineGroupShares(fewest)
+}
+
 // sealProject archives, encrypts, splits, verifies, saves, and generates bundles
 // for an already-loaded project. Both runSeal and runDemo share this logic.
 // recoveryURL is the base URL for QR codes in the PDF. If empty, the PDF defaults to the production URL.
 // noEmbedManifest controls whether MANIFEST.age is embedded in recover.html.
-func sealProject(p *project.Project, recoveryURL string, noEmbedManifest bool) error {
-	// Check manifest directory exists and has content
+// ownerPassword, if set, also writes OWNER.age: the passphrase locked with that password.
+// The project's recipients, if any, get RECIPIENTS.age: the passphrase encrypted to their keys.
+// payloads are sealed as extra files in manifest/ (from --stdin and --exec).
+func sealProject(p *project.Project, recoveryURL string, noEmbedManifest bool, ownerPassword string, payloads ...*manifest.Payload) (*sealMetrics, error) {
+	return sealProjectFrom(p, recoveryURL, noEmbedManifest, ownerPassword, nil, payloads...)
+}
+
+// sealProjectFrom is sealProject, except that with reencrypt set it doesn't
+// archive manifest/: it decrypts the current MANIFEST.age with reencrypt,
+// what opens it, and encrypts the same archive again under the new
+// passphrase, as 'rememory reseal --keys-only' does.
+func sealProjectFrom(p *project.Project, recoveryURL string, noEmbedManifest bool, ownerPassword string, reencrypt core.Secret, payloads ...*manifest.Payload) (*sealMetrics, error) {
+	restricted := p.Crypto == core.CryptoRestricted
+	if restricted {
+		if ownerPassword != "" {
+			return nil, fmt.Errorf("--owner-escrow is outside the restricted crypto profile: it locks the passphrase with a password you chose")
+		}
+		if core.Deterministic() {
+			return nil, fmt.Errorf("deterministic mode is outside the restricted crypto profile")
+		}
+		if p.SLIP39 {
+			return nil, fmt.Errorf("slip39 is outside the restricted crypto profile: SLIP-0039 splits the passphrase a second way, with PBKDF2")
+		}
+		if p.SSKR {
+			return nil, fmt.Errorf("sskr is outside the restricted crypto profile: SSKR splits the passphrase a second way")
+		}
+		if p.SSSS {
+			return nil, fmt.Errorf("ssss is outside the restricted crypto profile: it splits the passphrase a second way")
+		}
+	}
+
+	// A broken PDF layout or README template would otherwise only show up after the shares are written
+	if err := bundle.CheckProjectTemplates(p); err != nil {
+		return nil, err
+	}
+
+	// Check manifest directory exists and has content. Encrypting the sealed
+	// archive again needs neither, only what the last seal recorded.
 	manifestDir := p.ManifestPath()
-	fileCount, err := manifest.CountFiles(manifestDir)
+	var ig *manifest.Ignore
+	var contentWarnings []string
+	var fileCount int
+	var dirSize int64
+	if reencrypt != nil {
+		if p.Sealed == nil {
+			return nil, fmt.Errorf("project is not sealed; there is no archive to encrypt again")
+		}
+		fileCount = len(p.Sealed.Files)
+		for _, f := range p.Sealed.Files {
+			dirSize += f.Size
+		}
+	} else {
+		var err error
+		if ig, err = p.ManifestIgnore(); err != nil {
+			return nil, err
+		}
+		if contentWarnings, err = manifest.Check(manifestDir, ig, payloads...); err != nil {
+			return nil, err
+		}
+		if fileCount, err = manifest.CountFiles(manifestDir, ig); err != nil {
+			return nil, fmt.Errorf("checking manifest directory: %w", err)
+		}
+		if dirSize, err = manifest.DirSize(manifestDir, ig); err != nil {
+			return nil, fmt.Errorf("calculating manifest size: %w", err)
+		}
+	}
+	for _, pl := range payloads {
+		fileCount++
+		dirSize += pl.Size
+	}
+
+	// Fail before anything is written, rather than with half the bundles made
+	wasmBytes := html.GetRecoverWASMBytes()
+	if len(wasmBytes) == 0 {
+		return nil, fmt.Errorf("recover.wasm not embedded - rebuild with 'make build'")
+	}
+	if err := checkSpace(sealSpace(p, dirSize, wasmBytes)...); err != nil {
+		return nil, err
+	}
+
+	// Generate passphrase (v2: split raw bytes, not the base64 string),
+	// starting with the bundle header every piece will carry
+	bundleID, err := core.NewBundleID()
 	if err != nil {
-		return fmt.Errorf("checking manifest directory: %w", err)
+		return nil, err
 	}
-	if fileCount == 0 {
-		return fmt.Errorf("manifest directory is empty: %s", manifestDir)
+	raw, passphrase, err := crypto.GenerateBundlePassphrase(crypto.DefaultPassphraseBytes, bundleID)
+	if err != nil {
+		return nil, fmt.Errorf("generating passphrase: %w", err)
 	}
+	defer core.Wipe(raw)
+	defer passphrase.Wipe()
 
-	dirSize, err := manifest.DirSize(manifestDir)
+	workFactor, err := tuneWorkFactor(p)
 	if err != nil {
-		return fmt.Errorf("calculating manifest size: %w", err)
+		return nil, err
+	}
+	timelock, err := newTimelock(p)
+	if err != nil {
+		return nil, err
+	}
+	// With a recovery delay, MANIFEST.age opens with what the puzzle turns
+	// the pieces' passphrase into
+	manifestPassphrase := passphrase
+	if timelock != nil {
+		manifestPassphrase = timelock.Lock(passphrase)
+		defer manifestPassphrase.Wipe()
 	}
 
-	fmt.Printf("Archiving manifest/ (%d files, %s)...\n", fileCount, formatSize(dirSize))
+	// Create output directories
+	sharesDir := p.SharesPath()
+	if err := os.MkdirAll(sharesDir, 0755); err != nil {
+		return nil, fmt.Errorf("creating output directories: %w", err)
+	}
 
-	// Archive the manifest directory
-	var archiveBuf bytes.Buffer
-	archiveResult, err := manifest.Archive(&archiveBuf, manifestDir)
-	if err != nil {
-		return fmt.Errorf("archiving manifest: %w", err)
+	metrics := &sealMetrics{Files: fileCount, InputBytes: dirSize}
+	sealStart := time.Now()
+	manifestAgePath := p.ManifestAgePath()
+	var archiveResult *manifest.ArchiveResult
+	var stats archiveStats
+	if reencrypt != nil {
+		// Decrypt and encrypt again in one stream; the archive inside stays
+		// byte for byte the same, so the file list does too
+		fmt.Printf("Encrypting the sealed archive again (%d files, %s)...\n", fileCount, formatSize(dirSize))
+		if stats, err = reencryptManifest(manifestAgePath, reencrypt, manifestPassphrase, workFactor, p.PostQuantum); err != nil {
+			return nil, err
+		}
+		archiveResult = &manifest.ArchiveResult{Files: p.Sealed.Files}
+		metrics.took("kdf", stats.KDF, 0)
+		metrics.took("decrypt", time.Since(sealStart)-stats.KDF-stats.Encrypt, stats.Bytes)
+	} else {
+		// Archive, compress, and encrypt in one stream, straight to disk
+		fmt.Printf("Archiving and encrypting manifest/ (%d files, %s)...\n", fileCount, formatSize(dirSize))
+		if archiveResult, stats, err = archiveEncrypted(manifestDir, manifestAgePath, manifestPassphrase, workFactor, p.PostQuantum, "", ig, payloads...); err != nil {
+			return nil, err
+		}
+		// The three run as one stream: the archive stage is what's left once
+		// scrypt and the time spent encrypting are taken out
+		metrics.took("kdf", stats.KDF, 0)
+		metrics.took("archive", time.Since(sealStart)-stats.KDF-stats.Encrypt, dirSize)
 	}
+	metrics.took("encrypt", stats.Encrypt, stats.Bytes)
+	metrics.ArchiveBytes = stats.Bytes
+	if dirSize > 0 {
+		metrics.CompressionRatio = float64(metrics.ArchiveBytes) / float64(dirSize)
+	}
+	if info, err := os.Stat(manifestAgePath); err == nil {
+		metrics.EncryptedBytes = info.Size()
+	}
+	metrics.Ignored = archiveResult.Ignored
+	metrics.DuplicateBytes = archiveResult.DuplicateBytes
 
 	for _, warning := range archiveResult.Warnings {
 		fmt.Printf("  Warning: %s\n", warning)
 	}
+	for _, warning := range contentWarnings {
+		fmt.Printf("  Warning: %s\n", warning)
+	}
 
-	// Generate passphrase (v2: split raw bytes, not the base64 string)
-	raw, passphrase, err := crypto.GenerateRawPassphrase(crypto.DefaultPassphraseBytes)
-	if err != nil {
-		return fmt.Errorf("generating passphrase: %w", err)
+	if restricted {
+		f, err := os.Open(manifestAgePath)
+		if err != nil {
+			return nil, fmt.Errorf("reading encrypted manifest: %w", err)
+		}
+		err = core.CheckRestrictedManifestReader(f)
+		f.Close()
+		if err != nil {
+			return nil, err
+		}
 	}
 
-	fmt.Println("Encrypting with age...")
+	if p.Grouped() {
+		fmt.Printf("Splitting into %d shares across %d groups (%d groups needed)...\n", len(p.Friends), len(p.Groups), p.Threshold)
+	} else {
+		fmt.Printf("Splitting into %d shares (threshold: %d)...\n", p.TotalPieces(), p.Threshold)
+	}
 
-	// Encrypt the archive
-	var encryptedBuf bytes.Buffer
-	archiveReader := bytes.NewReader(archiveBuf.Bytes())
-	if err := core.Encrypt(&encryptedBuf, archiveReader, passphrase); err != nil {
-		return fmt.Errorf("encrypting: %w", err)
+	// Split the raw bytes (v2: 32 bytes instead of 43-byte base64 string)
+	splitStart := time.Now()
+	shares, err := splitPassphrase(p, raw, bundleID)
+	if err != nil {
+		return nil, fmt.Errorf("splitting passphrase: %w", err)
 	}
 
-	// Create output directories
-	sharesDir := p.SharesPath()
-	if err := os.MkdirAll(sharesDir, 0755); err != nil {
-		return fmt.Errorf("creating output directories: %w", err)
+	reviewBy, expires, err := p.Sunset()
+	if err != nil {
+		return nil, err
 	}
 
-	// Write encrypted manifest
-	manifestAgePath := p.ManifestAgePath()
-	if err := os.WriteFile(manifestAgePath, encryptedBuf.Bytes(), 0644); err != nil {
-		return fmt.Errorf("writing encrypted manifest: %w", err)
+	// Verify reconstruction
+	fmt.Print("Verifying reconstruction... ")
+	recovered, err := combineFewest(p, shares)
+	if err != nil {
+		fmt.Println("FAILED")
+		return nil, fmt.Errorf("verification failed: %w", err)
 	}
+	matches := bytes.Equal(recovered, raw)
+	core.Wipe(recovered)
+	if !matches {
+		fmt.Println("FAILED")
+		return nil, fmt.Errorf("verification failed: reconstructed passphrase doesn't match")
+	}
+	fmt.Println("OK")
 
-	fmt.Printf("Splitting into %d shares (threshold: %d)...\n", len(p.Friends), p.Threshold)
+	// Duress pieces couldn't carry a value that checks out, grouped pieces
+	// split group parts rather than the passphrase, and the restricted
+	// profile leaves out the elliptic curve it needs
+	var vss core.VSSCommitments
+	if !p.Grouped() && p.Decoy == nil && !restricted {
+		if vss, err = core.SplitVSS(raw, p.Threshold, shares); err != nil {
+			return nil, err
+		}
+	}
+	metrics.stage("split", splitStart, 0)
+	sharesStart := time.Now()
 
-	// Split the raw bytes (v2: 32 bytes instead of 43-byte base64 string)
-	shares, err := core.Split(raw, len(p.Friends), p.Threshold)
+	catalog, err := sealCatalog(p, shares, archiveResult.Files)
 	if err != nil {
-		return fmt.Errorf("splitting passphrase: %w", err)
+		return nil, err
 	}
 
-	// Create share files
-	shareInfos := make([]project.ShareInfo, len(shares))
-	for i, shareData := range shares {
+	// Create share files, one per friend with all of their pieces
+	shareInfos := make([]project.ShareInfo, len(p.Friends))
+	pins := make(map[string]string)
+	for i, pieces := range friendPieces(p, shares) {
 		friend := p.Friends[i]
-		share := core.NewShare(2, i+1, len(p.Friends), p.Threshold, friend.Name, shareData)
+		if friend.PIN {
+			if pins[friend.Name], err = core.NewPIN(); err != nil {
+				return nil, err
+			}
+		}
+		for _, share := range pieces {
+			if err := finishShare(p, friend, share, raw, bundleID, workFactor, reviewBy, expires, pins[friend.Name]); err != nil {
+				return nil, err
+			}
+		}
 
-		filename := share.Filename()
-		sharePath := filepath.Join(sharesDir, filename)
+		sharePath := p.SharePath(friend)
 
-		if err := os.WriteFile(sharePath, []byte(share.Encode()), 0600); err != nil {
-			return fmt.Errorf("writing share for %s: %w", friend.Name, err)
+		if err := os.WriteFile(sharePath, []byte(core.EncodeShares(pieces)), 0600); err != nil {
+			return nil, fmt.Errorf("writing share for %s: %w", friend.Name, err)
 		}
 
 		fileChecksum, err := crypto.HashFile(sharePath)
 		if err != nil {
-			return fmt.Errorf("computing checksum: %w", err)
+			return nil, fmt.Errorf("computing checksum: %w", err)
 		}
 
 		relPath, _ := filepath.Rel(p.Path, sharePath)
@@ -169,38 +947,118 @@ func sealProject(p *project.Project, recoveryURL string, noEmbedManifest bool) e
 		}
 	}
 
-	// Verify reconstruction
-	fmt.Print("Verifying reconstruction... ")
-	testShares := make([][]byte, p.Threshold)
-	for i := 0; i < p.Threshold; i++ {
-		testShares[i] = shares[i]
+	if err := writeSLIP39Words(p, raw); err != nil {
+		return nil, err
 	}
-	recovered, err := core.Combine(testShares)
-	if err != nil {
-		fmt.Println("FAILED")
-		return fmt.Errorf("verification failed: %w", err)
+	if err := writeSSKRShares(p, raw); err != nil {
+		return nil, err
 	}
-	if base64.RawURLEncoding.EncodeToString(recovered) != passphrase {
-		fmt.Println("FAILED")
-		return fmt.Errorf("verification failed: reconstructed passphrase doesn't match")
+	if err := writeSSSSShares(p, raw); err != nil {
+		return nil, err
+	}
+
+	// Owner escrow: the passphrase, encrypted with the owner's own password
+	ownerEscrowPath := filepath.Join(p.OutputPath(), ownerEscrowFile)
+	if ownerPassword != "" {
+		var escrowBuf bytes.Buffer
+		if err := core.Encrypt(&escrowBuf, bytes.NewReader(passphrase), ownerPassword); err != nil {
+			return nil, fmt.Errorf("encrypting owner escrow: %w", err)
+		}
+		if err := os.WriteFile(ownerEscrowPath, escrowBuf.Bytes(), 0600); err != nil {
+			return nil, fmt.Errorf("writing owner escrow: %w", err)
+		}
+	} else if err := os.Remove(ownerEscrowPath); err != nil && !os.IsNotExist(err) {
+		// A stale escrow from a previous seal would hold the wrong passphrase
+		return nil, fmt.Errorf("removing old owner escrow: %w", err)
+	}
+
+	// Recipients: the passphrase, encrypted to the age keys in project.yml
+	// and the master code's
+	recipientsPath := filepath.Join(p.OutputPath(), core.RecipientsFile)
+	if keys := p.RecipientKeys(); len(keys) > 0 {
+		recipients, err := crypto.ParseRecipients(keys)
+		if err != nil {
+			return nil, err
+		}
+		var recipientsBuf bytes.Buffer
+		if err := core.EncryptToRecipients(&recipientsBuf, bytes.NewReader(passphrase), recipients); err != nil {
+			return nil, fmt.Errorf("encrypting to recipients: %w", crypto.PluginHint(err))
+		}
+		if err := os.WriteFile(recipientsPath, recipientsBuf.Bytes(), 0600); err != nil {
+			return nil, fmt.Errorf("writing %s: %w", core.RecipientsFile, err)
+		}
+	} else if err := os.Remove(recipientsPath); err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("removing old %s: %w", core.RecipientsFile, err)
+	}
+
+	// Catalog: what the seal holds, for fewer pieces than the threshold
+	catalogPath := p.CatalogPath()
+	if catalog != nil {
+		if err := os.WriteFile(catalogPath, catalog, 0644); err != nil {
+			return nil, fmt.Errorf("writing %s: %w", core.CatalogFile, err)
+		}
+	} else if err := os.Remove(catalogPath); err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("removing old %s: %w", core.CatalogFile, err)
+	}
+
+	// Time-lock: the puzzle recovery solves before MANIFEST.age opens
+	timelockPath := p.TimelockPath()
+	var timelockChecksum string
+	if timelock != nil {
+		data, err := timelock.Encode()
+		if err != nil {
+			return nil, err
+		}
+		timelockChecksum = core.HashBytes(data)
+		if err := os.WriteFile(timelockPath, data, 0644); err != nil {
+			return nil, fmt.Errorf("writing %s: %w", core.TimelockFile, err)
+		}
+	} else if err := os.Remove(timelockPath); err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("removing old %s: %w", core.TimelockFile, err)
+	}
+
+	// Decoy: harmless files the duress pieces open instead
+	if err := sealDecoy(p, bundleID, workFactor, timelock, reviewBy, expires, pins); err != nil {
+		return nil, err
 	}
-	fmt.Println("OK")
 
 	// Update project with seal information
 	manifestChecksum, err := crypto.HashFile(manifestAgePath)
 	if err != nil {
-		return fmt.Errorf("computing manifest checksum: %w", err)
+		return nil, fmt.Errorf("computing manifest checksum: %w", err)
+	}
+
+	// Bundles from the previous seal are out of date now; the new ones say so
+	if previous := p.Sealed; previous != nil && previous.ManifestChecksum != manifestChecksum {
+		p.Superseded = rotation.Record(p.Superseded, rotation.Generation{Sealed: previous.At, Fingerprint: rotation.Fingerprint(previous.ManifestChecksum)})
+	}
+	sealedAt := core.Now()
+	var note *rotation.Note
+	if len(p.Superseded) > 0 {
+		if note, err = rotation.Sign(p.Name, sealedAt, manifestChecksum, p.Contact, p.Superseded, passphrase); err != nil {
+			return nil, err
+		}
 	}
 
 	p.Sealed = &project.Sealed{
-		At:               time.Now().UTC(),
+		At:               sealedAt,
 		ManifestChecksum: manifestChecksum,
-		VerificationHash: core.HashString(passphrase),
+		VerificationHash: core.HashBytes(passphrase),
+		Crypto:           p.Crypto,
+		RecoveryURL:      recordedRecoveryURL(recoveryURL),
+		BundleID:         bundleID,
+		WorkFactor:       workFactor,
+		VSS:              vss.Strings(),
+		PostQuantum:      p.PostQuantum,
+		TimelockChecksum: timelockChecksum,
 		Shares:           shareInfos,
+		LastIndex:        len(shares),
+		Files:            archiveResult.Files,
+		Rotation:         note,
 	}
 
 	if err := p.Save(); err != nil {
-		return fmt.Errorf("saving project: %w", err)
+		return nil, fmt.Errorf("saving project: %w", err)
 	}
 
 	// Print seal summary
@@ -208,19 +1066,76 @@ func sealProject(p *project.Project, recoveryURL string, noEmbedManifest bool) e
 	fmt.Println("Sealed:")
 	relManifest, _ := filepath.Rel(p.Path, manifestAgePath)
 	fmt.Printf("  %s %s\n", green("✓"), relManifest)
+	printDuplicates(archiveResult)
 	for _, si := range shareInfos {
 		fmt.Printf("  %s %s\n", green("✓"), si.File)
 	}
+	if p.SLIP39 {
+		fmt.Printf("  %s SLIP-0039 words for %d friends\n", green("✓"), len(p.Friends))
+	}
+	if p.SSKR {
+		fmt.Printf("  %s SSKR shares for %d friends\n", green("✓"), len(p.Friends))
+	}
+	if p.SSSS {
+		fmt.Printf("  %s ssss shares for %d friends\n", green("✓"), len(p.Friends))
+	}
+	if ownerPassword != "" {
+		relEscrow, _ := filepath.Rel(p.Path, ownerEscrowPath)
+		fmt.Printf("  %s %s (owner escrow — keep it to yourself)\n", green("✓"), relEscrow)
+	}
+	if len(p.RecipientKeys()) > 0 {
+		relRecipients, _ := filepath.Rel(p.Path, recipientsPath)
+		switch {
+		case p.MasterCode == "":
+			fmt.Printf("  %s %s (for %d age key%s)\n", green("✓"), relRecipients, len(p.Recipients), plural(len(p.Recipients)))
+		case len(p.Recipients) == 0:
+			fmt.Printf("  %s %s (for the master code)\n", green("✓"), relRecipients)
+		default:
+			fmt.Printf("  %s %s (for %d age key%s and the master code)\n", green("✓"), relRecipients, len(p.Recipients), plural(len(p.Recipients)))
+		}
+	}
+	if catalog != nil {
+		relCatalog, _ := filepath.Rel(p.Path, catalogPath)
+		fmt.Printf("  %s %s (opens with %d of %d pieces)\n", green("✓"), relCatalog, p.Catalog.Threshold, p.TotalPieces())
+	}
+	if p.Decoy != nil {
+		relDecoy, _ := filepath.Rel(p.Path, p.DecoyPath())
+		relDuress, _ := filepath.Rel(p.Path, p.DuressPath())
+		fmt.Printf("  %s %s, with a duress piece for each friend in %s/\n", green("✓"), relDecoy, relDuress)
+	}
+	if timelock != nil {
+		relTimelock, _ := filepath.Rel(p.Path, timelockPath)
+		fmt.Printf("  %s %s (recovery waits about %s once enough pieces come together)\n", green("✓"), relTimelock, p.RecoveryDelay)
+	}
+	if restricted {
+		fmt.Printf("  %s crypto profile: %s\n", green("✓"), core.CryptoRestricted)
+	}
+	if p.PostQuantum {
+		fmt.Printf("  %s post-quantum hybrid mode (scrypt + ML-KEM-768)\n", green("✓"))
+	}
+	if note != nil {
+		fmt.Printf("  %s %s: replaces %d earlier seal%s\n", green("✓"), rotation.FileName, len(note.Superseded), plural(len(note.Superseded)))
+	}
+	if !reviewBy.IsZero() {
+		fmt.Printf("  %s review by %s\n", green("✓"), p.ReviewBy)
+	}
+	if !expires.IsZero() {
+		fmt.Printf("  %s expires %s\n", green("✓"), p.Expires)
+	}
+	if now := core.Now(); recovery.Past(expires, now) {
+		fmt.Printf("  %s expires is already past: recovery tools will refuse these bundles unless told otherwise\n", yellow("!"))
+	} else if recovery.Past(reviewBy, now) {
+		fmt.Printf("  %s review_by is already past: recovery tools will warn about these bundles\n", yellow("!"))
+	}
+
+	printIgnored(archiveResult.Ignored)
+
+	metrics.stage("shares", sharesStart, 0)
 
 	// Generate bundles
 	fmt.Println()
 	fmt.Printf("Generating bundles for %d friends...\n", len(p.Friends))
 
-	wasmBytes := html.GetRecoverWASMBytes()
-	if len(wasmBytes) == 0 {
-		return fmt.Errorf("recover.wasm not embedded - rebuild with 'make build'")
-	}
-
 	cfg := bundle.Config{
 		Version:          version,
 		GitHubReleaseURL: fmt.Sprintf("https://github.com/eljojo/rememory/releases/tag/%s", version),
@@ -229,9 +1144,12 @@ func sealProject(p *project.Project, recoveryURL string, noEmbedManifest bool) e
 		NoEmbedManifest:  noEmbedManifest,
 	}
 
+	bundleStart := time.Now()
 	if err := bundle.GenerateAll(p, cfg); err != nil {
-		return fmt.Errorf("generating bundles: %w", err)
+		return nil, fmt.Errorf("generating bundles: %w", err)
 	}
+	metrics.stage("bundles", bundleStart, 0)
+	metrics.TotalMillis = float64(time.Since(sealStart).Microseconds()) / 1000
 
 	// Print bundle listing
 	bundlesDir := filepath.Join(p.OutputPath(), "bundles")
@@ -245,7 +1163,403 @@ func sealProject(p *project.Project, recoveryURL string, noEmbedManifest bool) e
 			fmt.Printf("  %s %s (%s)\n", green("✓"), entry.Name(), formatSize(info.Size()))
 		}
 	}
+	printPlacementWarnings(p)
+	printPINs(p, pins)
+	printBeaconReminder(p)
+
+	logEvent(p, "seal", "%d pieces, %d needed, bundle ID %s", p.TotalPieces(), p.Threshold, core.BundleFingerprint(p.Sealed.BundleID))
+	return metrics, nil
+}
+
+// printIgnored lists what .rememoryignore and the exclude patterns left out
+// of the archive, the first few by name.
+// printDuplicates says how many files were copies of others, stored once.
+func printDuplicates(result *manifest.ArchiveResult) {
+	if result.Duplicates > 0 {
+		fmt.Printf("  %s %d file%s identical to another, stored once (%s saved)\n", green("✓"), result.Duplicates, plural(result.Duplicates), formatSize(result.DuplicateBytes))
+	}
+}
+
+func printIgnored(ignored []string) {
+	if len(ignored) == 0 {
+		return
+	}
+	fmt.Printf("\nLeft out by %s and exclude:\n", manifest.IgnoreFile)
+	for i, name := range ignored {
+		if i == 10 {
+			fmt.Printf("  ... and %d more\n", len(ignored)-i)
+			break
+		}
+		fmt.Printf("  - %s\n", name)
+	}
+}
 
+// finishShare fills in what a piece carries besides its data: dates, MAC,
+// PIN lock, bundle ID, work factor, and word list. Real pieces and duress
+// pieces go through it alike, so nothing on a piece tells them apart. The
+// restricted profile leaves the MAC out, as an HMAC outside age.
+func finishShare(p *project.Project, friend project.Friend, share *core.Share, raw []byte, bundleID string, workFactor int, reviewBy, expires time.Time, pin string) error {
+	share.ReviewBy, share.Expires = reviewBy, expires
+	if p.Crypto != core.CryptoRestricted {
+		share.Authenticate(raw)
+	}
+	if friend.PIN {
+		share.VSS = nil // It would open without the PIN
+		if err := share.LockWithPIN(pin); err != nil {
+			return err
+		}
+	}
+	share.Bind(bundleID)
+	share.WorkFactor = workFactor
+	if lang := p.WordList(friend); lang != core.LangEN {
+		share.WordList = lang
+	}
+	return nil
+}
+
+// sealDecoy seals the project's decoy folder into DECOY.age under a
+// passphrase of its own, and writes each friend's duress piece: a share of
+// that passphrase with the same holder, index, bundle ID, and PIN as their
+// real one. With a recovery delay, the decoy waits behind the same puzzle,
+// so the wait doesn't tell them apart either. Without a decoy, it removes
+// what an earlier seal left.
+func sealDecoy(p *project.Project, bundleID string, workFactor int, timelock *core.TimelockSeal, reviewBy, expires time.Time, pins map[string]string) error {
+	decoyPath := p.DecoyPath()
+	if p.Decoy == nil {
+		if err := os.Remove(decoyPath); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("removing old %s: %w", core.DecoyFile, err)
+		}
+		if err := os.RemoveAll(p.DuressPath()); err != nil {
+			return fmt.Errorf("removing old duress pieces: %w", err)
+		}
+		return nil
+	}
+
+	decoyDir := p.DecoyManifestPath()
+	if _, err := manifest.Check(decoyDir, nil); err != nil {
+		return fmt.Errorf("decoy: %w", err)
+	}
+	raw, passphrase, err := crypto.GenerateBundlePassphrase(crypto.DefaultPassphraseBytes, bundleID)
+	if err != nil {
+		return fmt.Errorf("generating decoy passphrase: %w", err)
+	}
+	defer core.Wipe(raw)
+	defer passphrase.Wipe()
+	decoyPassphrase := passphrase
+	if timelock != nil {
+		decoyPassphrase = timelock.Lock(passphrase)
+		defer decoyPassphrase.Wipe()
+	}
+
+	relDir, _ := filepath.Rel(p.Path, decoyDir)
+	fmt.Printf("Sealing the decoy from %s/...\n", relDir)
+	// Archived under the real folder's name, so it extracts like the real files
+	if _, _, err := archiveEncrypted(decoyDir, decoyPath, decoyPassphrase, workFactor, p.PostQuantum, project.ManifestDir, nil); err != nil {
+		return err
+	}
+	// recover.html always carries the decoy, so it has to fit
+	if info, err := os.Stat(decoyPath); err == nil && info.Size() > html.MaxEmbeddedManifestSize {
+		os.Remove(decoyPath)
+		return fmt.Errorf("the decoy comes to %s sealed; keep it under %s so it fits in recover.html", formatSize(info.Size()), formatSize(html.MaxEmbeddedManifestSize))
+	}
+
+	shares, err := splitPassphrase(p, raw, bundleID)
+	if err != nil {
+		return fmt.Errorf("splitting decoy passphrase: %w", err)
+	}
+	recovered, err := combineFewest(p, shares)
+	if err != nil {
+		return fmt.Errorf("decoy verification failed: %w", err)
+	}
+	matches := bytes.Equal(recovered, raw)
+	core.Wipe(recovered)
+	if !matches {
+		return fmt.Errorf("decoy verification failed: reconstructed passphrase doesn't match")
+	}
+
+	if err := os.RemoveAll(p.DuressPath()); err != nil {
+		return fmt.Errorf("removing old duress pieces: %w", err)
+	}
+	if err := os.MkdirAll(p.DuressPath(), 0755); err != nil {
+		return fmt.Errorf("creating duress directory: %w", err)
+	}
+	for i, pieces := range friendPieces(p, shares) {
+		friend := p.Friends[i]
+		for _, share := range pieces {
+			if err := finishShare(p, friend, share, raw, bundleID, workFactor, reviewBy, expires, pins[friend.Name]); err != nil {
+				return err
+			}
+		}
+		if err := os.WriteFile(p.DuressSharePath(friend), []byte(core.EncodeShares(pieces)), 0600); err != nil {
+			return fmt.Errorf("writing duress piece for %s: %w", friend.Name, err)
+		}
+	}
+	return nil
+}
+
+// tuneWorkFactor picks the scrypt work factor for the project's
+// recovery_time budget, measuring scrypt on this computer, within its
+// max_memory; 0 when neither changes age's default. Deterministic builds
+// and --deterministic seals use a fixed measurement, so the work factor,
+// and with it MANIFEST.age, doesn't depend on the machine.
+func tuneWorkFactor(p *project.Project) (int, error) {
+	budget, device, err := p.RecoveryBudget()
+	if err != nil {
+		return 0, err
+	}
+	max, err := p.MaxWorkFactor()
+	if err != nil {
+		return 0, err
+	}
+	if budget == 0 {
+		if max == 0 || max >= core.DefaultWorkFactor {
+			return 0, nil
+		}
+		fmt.Printf("Limiting scrypt to %s of memory for low-memory devices: N=2^%d\n", formatSize(core.ScryptMemory(max)), max)
+		return max, nil
+	}
+	min := core.MinWorkFactor
+	if p.Crypto == core.CryptoRestricted {
+		min = core.RestrictedMinWorkFactor
+	}
+	bench := 25 * time.Millisecond
+	if !core.Deterministic() {
+		bench = core.BenchmarkScrypt()
+	}
+	workFactor, estimate, err := core.TuneWorkFactor(budget, device, bench, min, max)
+	if err != nil {
+		return 0, err
+	}
+	fmt.Printf("Tuning scrypt for recovery on %s in %s: N=2^%d (%s of memory), about %s\n", device.Describe(), budget, workFactor, formatSize(core.ScryptMemory(workFactor)), formatEstimate(estimate))
+	if estimate > budget {
+		fmt.Printf("  Warning: even the lowest work factor takes longer than %s on %s\n", budget, device.Describe())
+	}
+	return workFactor, nil
+}
+
+// newTimelock makes the time-lock puzzle for the project's recovery_delay,
+// measuring squarings on this computer; nil when no delay is set. Like
+// tuneWorkFactor, deterministic builds use a fixed measurement.
+func newTimelock(p *project.Project) (*core.TimelockSeal, error) {
+	delay, err := p.Delay()
+	if err != nil || delay == 0 {
+		return nil, err
+	}
+	rate := 500000.0
+	if !core.Deterministic() {
+		rate = core.BenchmarkTimelock()
+	}
+	timelock, err := core.NewTimelock(delay, rate)
+	if err != nil {
+		return nil, err
+	}
+	fmt.Printf("Adding a recovery delay of %s: %d squarings, one after another\n", delay, timelock.Squarings)
+	return timelock, nil
+}
+
+// formatEstimate rounds a time estimate for display.
+func formatEstimate(d time.Duration) string {
+	if d < time.Second {
+		return "under a second"
+	}
+	return d.Round(time.Second).String()
+}
+
+// archiveStats times the stream archiveEncrypted writes: scrypt, which age
+// runs once before the first byte, and the time spent encrypting and
+// writing what tar and gzip produce.
+type archiveStats struct {
+	Bytes   int64 // The compressed archive
+	KDF     time.Duration
+	Encrypt time.Duration
+}
+
+// manifestWriter returns the writer MANIFEST.age is encrypted through:
+// age's scrypt recipient, or the post-quantum hybrid mode's.
+func manifestWriter(dst io.Writer, passphrase core.Secret, workFactor int, postQuantum bool) (io.WriteCloser, error) {
+	if postQuantum {
+		return core.EncryptWriterPQ(dst, string(passphrase), workFactor)
+	}
+	return core.EncryptWriter(dst, string(passphrase), workFactor)
+}
+
+// archiveEncrypted archives manifestDir and encrypts it with passphrase into
+// path as one stream, with scrypt at workFactor (0 for age's default), in
+// the post-quantum hybrid mode when postQuantum is set, and with the
+// archive's folder named root (empty for manifestDir's own name), leaving
+// out what ig matches: tar, gzip, and age each hold only a small buffer, so
+// memory use doesn't grow with the files. It writes to a temporary file
+// first, so a seal that fails leaves the previous MANIFEST.age in place. It
+// returns the archive result and how the stream went.
+func archiveEncrypted(manifestDir, path string, passphrase core.Secret, workFactor int, postQuantum bool, root string, ig *manifest.Ignore, payloads ...*manifest.Payload) (*manifest.ArchiveResult, archiveStats, error) {
+	var stats archiveStats
+	partial := path + ".partial"
+	f, err := os.OpenFile(partial, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return nil, stats, fmt.Errorf("creating encrypted manifest: %w", err)
+	}
+	fail := func(err error) (*manifest.ArchiveResult, archiveStats, error) {
+		f.Close()
+		os.Remove(partial)
+		return nil, stats, err
+	}
+
+	kdfStart := time.Now()
+	enc, err := manifestWriter(f, passphrase, workFactor, postQuantum)
+	if err != nil {
+		return fail(err)
+	}
+	stats.KDF = time.Since(kdfStart)
+
+	counted := &countingWriter{w: enc}
+	result, err := manifest.ArchiveAs(counted, manifestDir, root, ig, payloads...)
+	if err != nil {
+		return fail(fmt.Errorf("archiving manifest: %w", err))
+	}
+	closeStart := time.Now()
+	if err := enc.Close(); err != nil {
+		return fail(fmt.Errorf("finalizing encryption: %w", err))
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(partial)
+		return nil, stats, fmt.Errorf("writing encrypted manifest: %w", err)
+	}
+	stats.Encrypt = counted.spent + time.Since(closeStart)
+	stats.Bytes = counted.n
+	if err := os.Rename(partial, path); err != nil {
+		os.Remove(partial)
+		return nil, stats, fmt.Errorf("writing encrypted manifest: %w", err)
+	}
+	return result, stats, nil
+}
+
+// countingWriter counts the bytes written through it and the time spent
+// writing them.
+type countingWriter struct {
+	w     io.Writer
+	n     int64
+	spent time.Duration
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	start := time.Now()
+	n, err := c.w.Write(p)
+	c.spent += time.Since(start)
+	c.n += int64(n)
+	return n, err
+}
+
+// printPINs lists the PINs friends' pieces were just locked with. They
+// aren't saved anywhere, so this is the only time they are shown.
+func printPINs(p *project.Project, pins map[string]string) {
+	if len(pins) == 0 {
+		return
+	}
+	fmt.Println()
+	fmt.Println("PINs: tell each friend theirs in person, never with the bundle. They aren't saved anywhere.")
+	for _, f := range p.Friends {
+		if pin, ok := pins[f.Name]; ok {
+			fmt.Printf("  %s: %s\n", f.Name, pin)
+		}
+	}
+}
+
+// writeSLIP39Words splits raw again as SLIP-0039 words, one file per friend,
+// when the project asks for them. Otherwise it removes words left by an
+// earlier seal, which would recover the old passphrase.
+func writeSLIP39Words(p *project.Project, raw []byte) error {
+	if !p.SLIP39 {
+		for _, f := range p.Friends {
+			if err := os.Remove(p.SLIP39Path(f)); err != nil && !os.IsNotExist(err) {
+				return fmt.Errorf("removing old SLIP-0039 words: %w", err)
+			}
+		}
+		return nil
+	}
+
+	fmt.Print("Splitting into SLIP-0039 words... ")
+	mnemonics, err := core.SLIP39Split(raw, p.Threshold, len(p.Friends))
+	if err != nil {
+		fmt.Println("FAILED")
+		return fmt.Errorf("splitting as SLIP-0039: %w", err)
+	}
+	recovered, err := core.SLIP39Combine(mnemonics[len(mnemonics)-p.Threshold:])
+	if err != nil || !bytes.Equal(recovered, raw) {
+		fmt.Println("FAILED")
+		return fmt.Errorf("verification failed: SLIP-0039 words don't reconstruct the passphrase")
+	}
+	for i, f := range p.Friends {
+		if err := os.WriteFile(p.SLIP39Path(f), []byte(mnemonics[i]+"\n"), 0600); err != nil {
+			return fmt.Errorf("writing SLIP-0039 words for %s: %w", f.Name, err)
+		}
+	}
+	fmt.Println("OK")
+	return nil
+}
+
+// writeSSKRShares splits raw again as SSKR shares, one UR per friend, when
+// the project asks for them. Otherwise it removes shares left by an earlier
+// seal, which would recover the old passphrase.
+func writeSSKRShares(p *project.Project, raw []byte) error {
+	if !p.SSKR {
+		for _, f := range p.Friends {
+			if err := os.Remove(p.SSKRPath(f)); err != nil && !os.IsNotExist(err) {
+				return fmt.Errorf("removing old SSKR shares: %w", err)
+			}
+		}
+		return nil
+	}
+
+	fmt.Print("Splitting into SSKR shares... ")
+	shares, err := core.SSKRSplit(raw, p.Threshold, len(p.Friends))
+	if err != nil {
+		fmt.Println("FAILED")
+		return fmt.Errorf("splitting as SSKR: %w", err)
+	}
+	recovered, err := core.SSKRCombine(shares[len(shares)-p.Threshold:])
+	if err != nil || !bytes.Equal(recovered, raw) {
+		fmt.Println("FAILED")
+		return fmt.Errorf("verification failed: SSKR shares don't reconstruct the passphrase")
+	}
+	for i, f := range p.Friends {
+		if err := os.WriteFile(p.SSKRPath(f), []byte(shares[i].UR()+"\n"), 0600); err != nil {
+			return fmt.Errorf("writing SSKR share for %s: %w", f.Name, err)
+		}
+	}
+	fmt.Println("OK")
+	return nil
+}
+
+// writeSSSSShares splits raw again as ssss share lines, one per friend, when
+// the project asks for them. Otherwise it removes shares left by an earlier
+// seal, which would recover the old passphrase.
+func writeSSSSShares(p *project.Project, raw []byte) error {
+	if !p.SSSS {
+		for _, f := range p.Friends {
+			if err := os.Remove(p.SSSSPath(f)); err != nil && !os.IsNotExist(err) {
+				return fmt.Errorf("removing old ssss shares: %w", err)
+			}
+		}
+		return nil
+	}
+
+	fmt.Print("Splitting into ssss shares... ")
+	shares, err := core.SSSSSplit(raw, p.Threshold, len(p.Friends))
+	if err != nil {
+		fmt.Println("FAILED")
+		return fmt.Errorf("splitting as ssss: %w", err)
+	}
+	recovered, err := core.SSSSCombine(shares[len(shares)-p.Threshold:], p.Threshold)
+	if err != nil || !bytes.Equal(recovered, raw) {
+		fmt.Println("FAILED")
+		return fmt.Errorf("verification failed: ssss shares don't reconstruct the passphrase")
+	}
+	for i, f := range p.Friends {
+		if err := os.WriteFile(p.SSSSPath(f), []byte(shares[i]+"\n"), 0600); err != nil {
+			return fmt.Errorf("writing ssss share for %s: %w", f.Name, err)
+		}
+	}
+	fmt.Println("OK")
 	return nil
 }
 
@@ -269,3 +1583,138 @@ func truncateHash(hash string) string {
 	}
 	return hash
 }
+
+// readPayload spools the data piped in for --stdin, or the output of the
+// --exec command, into a payload named name. The command runs through the
+// shell with its errors on stderr; if it fails, nothing is sealed. The data
+// waits where spool says.
+func readPayload(spool manifest.Spool, fromStdin bool, command, name string) (*manifest.Payload, error) {
+	if fromStdin && command != "" {
+		return nil, fmt.Errorf("use either --stdin or --exec, not both")
+	}
+	if fromStdin {
+		if term.IsTerminal(int(os.Stdin.Fd())) {
+			return nil, fmt.Errorf("--stdin reads data piped in, for example: pg_dump mydb | rememory seal --stdin --name mydb.sql")
+		}
+		if err := manifest.CheckPayloadName(name); err != nil {
+			return nil, err
+		}
+		fmt.Printf("Reading %s from standard input...\n", name)
+		payload, err := spool.Payload(name, manifest.SourceStdin, os.Stdin)
+		return payload, spoolError(err, spool)
+	}
+
+	if name == "" {
+		if fields := strings.Fields(command); len(fields) > 0 {
+			name = filepath.Base(fields[0]) + ".out"
+		}
+	}
+	if err := manifest.CheckPayloadName(name); err != nil {
+		return nil, err
+	}
+	c := shellCommand(command)
+	c.Stderr = os.Stderr
+	out, err := c.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	// The command itself isn't printed or recorded: it may hold a password
+	fmt.Printf("Reading %s from the --exec command...\n", name)
+	if err := c.Start(); err != nil {
+		return nil, fmt.Errorf("running --exec command: %w", err)
+	}
+	payload, err := spool.Payload(name, manifest.SourceCommand, out)
+	if waitErr := c.Wait(); err == nil && waitErr != nil {
+		payload.Remove()
+		err = fmt.Errorf("--exec command failed, so nothing was sealed: %w", waitErr)
+	}
+	return payload, spoolError(err, spool)
+}
+
+// readVault reads a password-manager export from path ("-" for standard
+// input), or from the manager's own tool for --vault-from, and spools it,
+// normalized, as passwords.json.
+func readVault(spool manifest.Spool, path, from string) (*manifest.Payload, error) {
+	var data []byte
+	var err error
+	switch {
+	case path != "" && from != "":
+		return nil, fmt.Errorf("use either --vault or --vault-from, not both")
+	case from != "":
+		tool, args, err := vault.Command(from)
+		if err != nil {
+			return nil, err
+		}
+		c := exec.Command(tool, args...)
+		c.Stdin, c.Stderr = os.Stdin, os.Stderr // The tool asks for the vault's password
+		if data, err = c.Output(); err != nil {
+			return nil, fmt.Errorf("running %s: %w", tool, err)
+		}
+	case path == "-":
+		data, err = io.ReadAll(os.Stdin)
+	default:
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading vault export: %w", err)
+	}
+
+	v, err := vault.Parse(data)
+	if err != nil {
+		return nil, err
+	}
+	normalized, err := v.Marshal()
+	if err != nil {
+		return nil, err
+	}
+	fmt.Printf("Read the %s export (%d item%s), to seal as %s\n", v.Source, len(v.Entries), plural(len(v.Entries)), vault.FileName)
+	if path != "" && path != "-" {
+		fmt.Printf("  %s %s holds every password unencrypted; delete it once the seal is done\n", yellow("!"), path)
+	}
+	payload, err := spool.Payload(vault.FileName, manifest.SourceVault, bytes.NewReader(normalized))
+	return payload, spoolError(err, spool)
+}
+
+// readSeed asks for a seed phrase twice, without echo, checks it, and spools
+// it as seed-phrase.json. Neither the words nor which word was mistyped
+// are printed.
+func readSeed(spool manifest.Spool, label string, unchecked bool) (*manifest.Payload, error) {
+	fmt.Fprintf(os.Stderr, "%s Only type a seed phrase on a computer you trust, ideally one that's offline.\n", yellow("!"))
+	fmt.Fprintln(os.Stderr, "  Anyone who sees these words can take everything in the wallet.")
+
+	text, err := readPassword(fmt.Sprintf("Seed phrase for %s (the words, separated by spaces)", label))
+	if err != nil {
+		return nil, err
+	}
+	phrase, err := seed.New(label, text, unchecked)
+	if err != nil {
+		return nil, err
+	}
+	again, err := readPassword("Type the seed phrase again to confirm")
+	if err != nil {
+		return nil, err
+	}
+	if n := seed.Differs(phrase.Words, seed.Split(again)); n != 0 {
+		return nil, fmt.Errorf("the two seed phrases differ at word %d; nothing was sealed", n)
+	}
+
+	data, err := phrase.Marshal()
+	if err != nil {
+		return nil, err
+	}
+	checked := "not checked (--seed-unchecked)"
+	if phrase.Checked() {
+		checked = phrase.Wordlist + ", checksum OK"
+	}
+	fmt.Printf("Read the seed phrase for %s (%d words, %s), to seal as %s\n", label, len(phrase.Words), checked, seed.FileName)
+	payload, err := spool.Payload(seed.FileName, manifest.SourceSeed, bytes.NewReader(data))
+	return payload, spoolError(err, spool)
+}
+
+// shellCommand runs command through the system shell.
+func shellCommand(command string) *exec.Cmd {
+	if runtime.GOOS == "windows" {
+		return exec.Command("cmd", "/C", command)
+	}
+	return exec.Command("sh", "-c", command)
+}
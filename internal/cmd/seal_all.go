@@ -0,0 +1,286 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/eljojo/rememory/internal/core"
+	"github.com/eljojo/rememory/internal/manifest"
+	"github.com/eljojo/rememory/internal/project"
+	"github.com/eljojo/rememory/internal/translations"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+var sealAllCmd = &cobra.Command{
+	Use:   "seal-all <directory>",
+	Short: "Create, seal, and bundle a project in one step",
+	Long: `Seal-all runs init, seal, and bundle in a single non-interactive step, for
+scripts, cron jobs, and CI.
+
+The project is described by flags, or by a config file in the same format as
+project.yml (name, threshold, friends, language, and so on). Flags override
+the config file. The files to protect are copied from --manifest.
+
+Running it again is safe:
+  - If the directory doesn't exist, the project is created and sealed. If
+    anything fails, the directory is removed again, so the next run starts
+    clean.
+  - If it holds a project that isn't sealed yet, that project is sealed.
+  - If the project is already sealed, nothing is touched and seal-all exits
+    with an error. Use 'rememory seal' inside the project to re-seal.
+
+Example:
+  rememory seal-all ./recovery --manifest ~/secrets \
+    --friend "Alice,alice@example.com" --friend "Bob" --friend "Camila,,es" \
+    --threshold 2
+  rememory seal-all ./recovery --config recovery.yml --manifest ~/secrets`,
+	Args: cobra.ExactArgs(1),
+	RunE: runSealAll,
+}
+
+func init() {
+	sealAllCmd.Flags().String("config", "", "Project config file (project.yml format)")
+	sealAllCmd.Flags().String("manifest", "", "Directory whose contents are copied into manifest/")
+	sealAllCmd.Flags().String("name", "", "Project name (defaults to directory name)")
+	sealAllCmd.Flags().Int("threshold", 0, "Number of shares needed to recover (defaults to a majority)")
+	sealAllCmd.Flags().StringArray("friend", nil, "Friend in format 'Name', 'Name,contact', or 'Name,contact,lang' (repeatable)")
+	sealAllCmd.Flags().String("language", "", "Default bundle language")
+	sealAllCmd.Flags().String("recovery-url", core.DefaultRecoveryURL, "Base URL for QR code in PDF")
+	sealAllCmd.Flags().Bool("no-embed-manifest", false, "Do not embed MANIFEST.age in recover.html")
+	rootCmd.AddCommand(sealAllCmd)
+}
+
+func runSealAll(cmd *cobra.Command, args []string) error {
+	dir, err := manifest.AbsPath(args[0])
+	if err != nil {
+		return fmt.Errorf("resolving path: %w", err)
+	}
+	manifestSrc, _ := cmd.Flags().GetString("manifest")
+	recoveryURL, _ := cmd.Flags().GetString("recovery-url")
+	noEmbedManifest, _ := cmd.Flags().GetBool("no-embed-manifest")
+
+	want, err := sealAllConfig(cmd, dir)
+	if err != nil {
+		return err
+	}
+
+	var p *project.Project
+	created := false
+	if _, err := os.Stat(filepath.Join(dir, project.ProjectFileName)); err == nil {
+		p, err = project.Load(dir)
+		if err != nil {
+			return fmt.Errorf("loading project: %w", err)
+		}
+		if p.Sealed != nil {
+			return fmt.Errorf("%s is already sealed (at %s); run 'rememory seal' inside it to re-seal", dir, p.Sealed.At.Format("2006-01-02 15:04"))
+		}
+		if want != nil && !sameSetup(p, want) {
+			return fmt.Errorf("%s already has a different project.yml; remove it or drop the config flags", dir)
+		}
+		fmt.Printf("Continuing with existing project: %s\n\n", dir)
+	} else {
+		if want == nil {
+			return fmt.Errorf("no project in %s: pass --config or --friend to create one", dir)
+		}
+		if manifestSrc == "" {
+			return fmt.Errorf("--manifest is required when creating a new project")
+		}
+		if _, err := os.Stat(dir); err == nil {
+			return fmt.Errorf("directory already exists: %s", dir)
+		}
+		p, err = createSealAllProject(dir, want)
+		if err != nil {
+			os.RemoveAll(dir)
+			return err
+		}
+		created = true
+		fmt.Printf("Created project: %s\n\n", dir)
+	}
+
+	// Undo a half-made project so the next run starts from scratch
+	fail := func(err error) error {
+		if created {
+			os.RemoveAll(dir)
+		}
+		return err
+	}
+
+	if manifestSrc != "" {
+		if n, _ := manifest.CountFiles(p.ManifestPath(), nil); n > 0 {
+			return fail(fmt.Errorf("%s already has files; drop --manifest to seal them as they are", p.ManifestPath()))
+		}
+		if err := copyManifest(manifestSrc, p.ManifestPath()); err != nil {
+			return fail(err)
+		}
+	}
+
+	if err := p.Validate(); err != nil {
+		return fail(fmt.Errorf("invalid project: %w", err))
+	}
+	if !created {
+		if err := snapshot(p, "seal-all"); err != nil {
+			return err
+		}
+	}
+	metrics, err := sealProject(p, recoveryURL, noEmbedManifest, "")
+	if err != nil {
+		return fail(err)
+	}
+
+	fmt.Printf("\nSaved to: %s\n", filepath.Join(p.OutputPath(), "bundles"))
+	return printSealJSON(p, metrics)
+}
+
+// sealAllConfig builds the requested project from --config and the flags.
+// It returns nil if neither describes a project.
+func sealAllConfig(cmd *cobra.Command, dir string) (*project.Project, error) {
+	configPath, _ := cmd.Flags().GetString("config")
+	name, _ := cmd.Flags().GetString("name")
+	threshold, _ := cmd.Flags().GetInt("threshold")
+	friendFlags, _ := cmd.Flags().GetStringArray("friend")
+	language, _ := cmd.Flags().GetString("language")
+
+	if configPath == "" && len(friendFlags) == 0 {
+		return nil, nil
+	}
+
+	want := &project.Project{}
+	if configPath != "" {
+		data, err := os.ReadFile(configPath)
+		if err != nil {
+			return nil, fmt.Errorf("reading config: %w", err)
+		}
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(true)
+		if err := dec.Decode(want); err != nil && err != io.EOF {
+			return nil, fmt.Errorf("parsing config: %w", err)
+		}
+		if want.Sealed != nil {
+			return nil, fmt.Errorf("config %s is from a sealed project; remove its 'sealed' section", configPath)
+		}
+	}
+
+	if len(friendFlags) > 0 {
+		friends, err := parseFriendFlags(friendFlags)
+		if err != nil {
+			return nil, err
+		}
+		want.Friends = friends
+	}
+	if name != "" {
+		want.Name = name
+	}
+	if want.Name == "" {
+		want.Name = filepath.Base(dir)
+	}
+	if threshold != 0 {
+		want.Threshold = threshold
+	}
+	if want.Threshold == 0 {
+		want.Threshold = max((len(want.Friends)+1)/2, 2) // Majority, as in init
+	}
+	if language != "" {
+		want.Language = language
+	}
+
+	if want.Language != "" && !validLanguage(want.Language) {
+		return nil, fmt.Errorf("unsupported language %q (supported: %s)", want.Language, strings.Join(translations.Languages, ", "))
+	}
+	for _, f := range want.Friends {
+		if f.Language != "" && !validLanguage(f.Language) {
+			return nil, fmt.Errorf("friend %q: unsupported language %q", f.Name, f.Language)
+		}
+	}
+	if err := want.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+	return want, nil
+}
+
+// sameSetup reports whether an existing project matches the requested one
+// closely enough to seal it in its place.
+func sameSetup(p, want *project.Project) bool {
+	return p.Name == want.Name &&
+		p.Threshold == want.Threshold &&
+		slices.Equal(p.Groups, want.Groups) &&
+		p.PrivacyLevel() == want.PrivacyLevel() &&
+		p.Language == want.Language &&
+		slices.EqualFunc(p.Friends, want.Friends, func(a, b project.Friend) bool {
+			// Check-ins are history, not setup
+			return a.Name == b.Name && a.Contact == b.Contact && a.Language == b.Language && a.Words == b.Words && a.Group == b.Group
+		})
+}
+
+// createSealAllProject writes a new project directory with an empty manifest/.
+func createSealAllProject(dir string, want *project.Project) (*project.Project, error) {
+	p, err := project.NewGrouped(dir, want.Name, want.Threshold, want.Groups, want.Friends, want.PrivacyLevel())
+	if err != nil {
+		return nil, fmt.Errorf("creating project: %w", err)
+	}
+	p.Language = want.Language
+	p.PDFLayout = want.PDFLayout
+	p.ReadmeTemplate = want.ReadmeTemplate
+	p.HTML = want.HTML
+	if err := p.Save(); err != nil {
+		return nil, fmt.Errorf("saving project: %w", err)
+	}
+	return p, nil
+}
+
+// copyManifest copies the regular files and directories under src into dst.
+// Symlinks and special files are skipped, as they would be when archiving.
+func copyManifest(src, dst string) error {
+	info, err := os.Stat(src)
+	if err != nil {
+		return fmt.Errorf("reading manifest source: %w", err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("manifest source is not a directory: %s", src)
+	}
+
+	return filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+
+		switch {
+		case d.IsDir():
+			return os.MkdirAll(target, 0755)
+		case d.Type().IsRegular():
+			return copyFile(path, target)
+		default:
+			fmt.Printf("  Warning: skipping %s (only regular files and directories are copied)\n", rel)
+			return nil
+		}
+	})
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return fmt.Errorf("copying %s: %w", src, err)
+	}
+	return out.Close()
+}
@@ -0,0 +1,220 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/eljojo/rememory/internal/bundle"
+	"github.com/eljojo/rememory/internal/core"
+	"github.com/eljojo/rememory/internal/crypto"
+	"github.com/eljojo/rememory/internal/html"
+	"github.com/eljojo/rememory/internal/manifest"
+	"github.com/eljojo/rememory/internal/project"
+	"github.com/eljojo/rememory/internal/rotation"
+)
+
+// sealUpdate seals manifest/ again under the current passphrase, as
+// 'rememory seal --update' does. The pieces, the bundle ID, and everything
+// else made from the passphrase stay as they are, so the bundles friends
+// already hold keep working, and only MANIFEST.age is new.
+//
+// Files the recorded hashes show unchanged are archived again all the same:
+// age never encrypts twice under the same key and nonce, so reusing any of
+// the old MANIFEST.age's chunks would mean reusing its key stream, and the
+// compressed archive shifts after the first changed byte anyway. What an
+// update saves is the new passphrase, and with it new bundles for everyone.
+//
+// It returns nil metrics when nothing changed and nothing was written.
+func sealUpdate(p *project.Project, recoveryURL string, noEmbedManifest bool, payloads ...*manifest.Payload) (*sealMetrics, error) {
+	if p.Sealed == nil {
+		return nil, fmt.Errorf("project is not sealed — run 'rememory seal' first")
+	}
+	if len(p.Sealed.Files) == 0 {
+		return nil, fmt.Errorf("this seal doesn't record its files (it predates the list); run 'rememory seal' instead")
+	}
+	switch {
+	case p.Crypto != p.Sealed.Crypto:
+		return nil, fmt.Errorf("the crypto profile changed since sealing; run 'rememory seal' to seal under it")
+	case p.PostQuantum != p.Sealed.PostQuantum:
+		return nil, fmt.Errorf("post_quantum changed since sealing; run 'rememory seal' to seal under it")
+	case p.RecoveryDelay != "":
+		// MANIFEST.age opens with what the puzzle makes of the passphrase,
+		// and locking it again needs the puzzle's secret, which only the
+		// first seal had
+		return nil, fmt.Errorf("--update can't keep a recovery delay; run 'rememory seal' instead")
+	case p.Catalog != nil:
+		return nil, fmt.Errorf("--update can't change CATALOG.age, whose key is in every piece; run 'rememory seal' instead")
+	}
+	if timelock, err := p.ReadTimelock(); err != nil {
+		return nil, err
+	} else if timelock != nil {
+		return nil, fmt.Errorf("--update can't keep a recovery delay; run 'rememory seal' instead")
+	}
+	if err := checkSealedFiles(p); err != nil {
+		return nil, fmt.Errorf("refusing to update: %w", err)
+	}
+	if err := bundle.CheckProjectTemplates(p); err != nil {
+		return nil, err
+	}
+
+	manifestDir := p.ManifestPath()
+	ig, err := p.ManifestIgnore()
+	if err != nil {
+		return nil, err
+	}
+	contentWarnings, err := manifest.Check(manifestDir, ig, payloads...)
+	if err != nil {
+		return nil, err
+	}
+	current, err := manifest.Snapshot(manifestDir, ig)
+	if err != nil {
+		return nil, err
+	}
+	changes := manifest.Compare(p.Sealed.Files, current)
+	// Data sealed with --stdin, --exec, --vault, or --seed was never in
+	// manifest/, so it's only kept if it's given again
+	var dropped []string
+	for _, f := range p.Sealed.Files {
+		if f.Source != "" && !slices.ContainsFunc(payloads, func(pl *manifest.Payload) bool { return path.Base(f.Path) == pl.Name }) {
+			dropped = append(dropped, f.Path)
+		}
+	}
+	if changes.Empty() && len(dropped) == 0 && len(payloads) == 0 {
+		fmt.Println("Nothing in manifest/ changed since it was sealed; MANIFEST.age is up to date.")
+		return nil, nil
+	}
+
+	fileCount := len(current) + len(payloads)
+	var dirSize int64
+	for _, f := range current {
+		dirSize += f.Size
+	}
+	for _, pl := range payloads {
+		dirSize += pl.Size
+	}
+	wasmBytes := html.GetRecoverWASMBytes()
+	if len(wasmBytes) == 0 {
+		return nil, fmt.Errorf("recover.wasm not embedded - rebuild with 'make build'")
+	}
+	if err := checkSpace(sealSpace(p, dirSize, wasmBytes)...); err != nil {
+		return nil, err
+	}
+
+	if err := snapshot(p, "seal"); err != nil {
+		return nil, err
+	}
+	passphrase, err := currentOpener(p, nil, false)
+	if err != nil {
+		return nil, err
+	}
+	defer passphrase.Wipe()
+
+	fmt.Println("Changes since the last seal:")
+	for _, name := range changes.Added {
+		fmt.Printf("  %s %s\n", green("+"), name)
+	}
+	for _, name := range changes.Modified {
+		fmt.Printf("  %s %s\n", yellow("~"), name)
+	}
+	for _, name := range changes.Removed {
+		fmt.Printf("  %s %s\n", red("-"), name)
+	}
+	for _, name := range dropped {
+		fmt.Printf("  %s %s (not given again)\n", red("-"), name)
+	}
+	for _, pl := range payloads {
+		fmt.Printf("  %s %s (from %s)\n", yellow("~"), pl.Name, pl.Source)
+	}
+	fmt.Println()
+
+	metrics := &sealMetrics{Files: fileCount, InputBytes: dirSize}
+	sealStart := time.Now()
+	manifestAgePath := p.ManifestAgePath()
+	fmt.Printf("Archiving and encrypting manifest/ under the current passphrase (%d files, %s)...\n", fileCount, formatSize(dirSize))
+	archiveResult, stats, err := archiveEncrypted(manifestDir, manifestAgePath, passphrase, p.Sealed.WorkFactor, p.Sealed.PostQuantum, "", ig, payloads...)
+	if err != nil {
+		return nil, err
+	}
+	metrics.took("kdf", stats.KDF, 0)
+	metrics.took("archive", time.Since(sealStart)-stats.KDF-stats.Encrypt, dirSize)
+	metrics.took("encrypt", stats.Encrypt, stats.Bytes)
+	metrics.ArchiveBytes = stats.Bytes
+	if dirSize > 0 {
+		metrics.CompressionRatio = float64(metrics.ArchiveBytes) / float64(dirSize)
+	}
+	if info, err := os.Stat(manifestAgePath); err == nil {
+		metrics.EncryptedBytes = info.Size()
+	}
+	metrics.Ignored = archiveResult.Ignored
+	metrics.DuplicateBytes = archiveResult.DuplicateBytes
+	for _, warning := range archiveResult.Warnings {
+		fmt.Printf("  Warning: %s\n", warning)
+	}
+	for _, warning := range contentWarnings {
+		fmt.Printf("  Warning: %s\n", warning)
+	}
+
+	if p.Sealed.Crypto == core.CryptoRestricted {
+		f, err := os.Open(manifestAgePath)
+		if err != nil {
+			return nil, fmt.Errorf("reading encrypted manifest: %w", err)
+		}
+		err = core.CheckRestrictedManifestReader(f)
+		f.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	manifestChecksum, err := crypto.HashFile(manifestAgePath)
+	if err != nil {
+		return nil, fmt.Errorf("computing manifest checksum: %w", err)
+	}
+	// SUPERSEDED.txt names the MANIFEST.age it came with; the seals it
+	// replaces are the same
+	if p.Sealed.Rotation != nil {
+		if p.Sealed.Rotation, err = rotation.Sign(p.Name, p.Sealed.At, manifestChecksum, p.Contact, p.Superseded, passphrase); err != nil {
+			return nil, err
+		}
+	}
+	p.Sealed.ManifestChecksum = manifestChecksum
+	p.Sealed.Files = archiveResult.Files
+	p.Sealed.Updated = core.Now()
+	p.Sealed.RecoveryURL = recordedRecoveryURL(recoveryURL)
+	if err := p.Save(); err != nil {
+		return nil, fmt.Errorf("saving project: %w", err)
+	}
+	relManifest, _ := filepath.Rel(p.Path, manifestAgePath)
+	fmt.Printf("  %s %s\n", green("✓"), relManifest)
+	printDuplicates(archiveResult)
+	printIgnored(archiveResult.Ignored)
+
+	fmt.Println()
+	fmt.Printf("Generating bundles for %d friends...\n", len(p.Friends))
+	cfg := bundle.Config{
+		Version:          version,
+		GitHubReleaseURL: fmt.Sprintf("https://github.com/eljojo/rememory/releases/tag/%s", version),
+		WASMBytes:        wasmBytes,
+		RecoveryURL:      recoveryURL,
+		NoEmbedManifest:  noEmbedManifest,
+	}
+	bundleStart := time.Now()
+	if err := bundle.GenerateAll(p, cfg); err != nil {
+		return nil, fmt.Errorf("generating bundles: %w", err)
+	}
+	metrics.stage("bundles", bundleStart, 0)
+	metrics.TotalMillis = float64(time.Since(sealStart).Microseconds()) / 1000
+
+	fmt.Println()
+	fmt.Println("The pieces are unchanged, so the bundles friends already hold keep working.")
+	fmt.Printf("Only %s is new: give friends this one, or their new bundle.\n", relManifest)
+	fmt.Println("recover.html opens it once the copy it carries is removed and this one added.")
+	printBeaconReminder(p)
+
+	logEvent(p, "seal", "updated MANIFEST.age (%d added, %d changed, %d removed), pieces kept", len(changes.Added), len(changes.Modified)+len(payloads), len(changes.Removed)+len(dropped))
+	return metrics, nil
+}
@@ -0,0 +1,241 @@
+package cmd
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/eljojo/rememory/internal/bundle"
+	"github.com/eljojo/rememory/internal/html"
+	"github.com/eljojo/rememory/internal/project"
+	"github.com/spf13/cobra"
+)
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serve the recovery and creation tools over HTTP",
+	Long: `Serve starts a local web server with the recovery tool (recover.html), the
+bundle creator (maker.html), and the documentation, all from this binary.
+
+Inside a sealed project it also serves the project's MANIFEST.age, and the
+recovery page loads it automatically, so everyone in the room only needs to
+bring their piece. Nobody has to email bundles around.
+
+By default the server only listens on this computer. With --lan it listens on
+the local network too and prints the addresses to open on other devices.
+Pieces are combined in each visitor's browser; the server never sees them.
+
+Example:
+  rememory serve
+  rememory serve --lan --port 8080`,
+	Args: cobra.NoArgs,
+	RunE: runServe,
+}
+
+func init() {
+	serveCmd.Flags().Int("port", 8000, "Port to listen on")
+	serveCmd.Flags().Bool("lan", false, "Listen on the local network, not just this computer")
+	rootCmd.AddCommand(serveCmd)
+}
+
+func runServe(cmd *cobra.Command, args []string) error {
+	port, _ := cmd.Flags().GetInt("port")
+	lan, _ := cmd.Flags().GetBool("lan")
+
+	var p *project.Project
+	if cwd, err := os.Getwd(); err == nil {
+		if dir, err := project.FindProjectDir(cwd); err == nil {
+			if p, err = project.Load(dir); err != nil {
+				return fmt.Errorf("loading project: %w", err)
+			}
+		}
+	}
+
+	fmt.Println("Preparing pages...")
+	pages, err := servePages(p)
+	if err != nil {
+		return err
+	}
+	manifestPath := ""
+	if p != nil && p.Sealed != nil {
+		manifestPath = p.ManifestAgePath()
+	}
+
+	host := "127.0.0.1"
+	if lan {
+		host = "0.0.0.0"
+	}
+	listener, err := net.Listen("tcp", net.JoinHostPort(host, fmt.Sprint(port)))
+	if err != nil {
+		return fmt.Errorf("listening on port %d: %w", port, err)
+	}
+
+	fmt.Println()
+	fmt.Printf("Serving at http://localhost:%d/\n", port)
+	if lan {
+		for _, addr := range lanAddresses() {
+			fmt.Printf("  On your network: http://%s/\n", net.JoinHostPort(addr, fmt.Sprint(port)))
+		}
+	}
+	if manifestPath != "" {
+		fmt.Printf("  %s MANIFEST.age from %s is loaded into the recovery page\n", green("✓"), p.Name)
+	}
+	fmt.Println("Press Ctrl+C to stop.")
+
+	server := &http.Server{
+		Handler:           serveHandler(pages, manifestPath),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		server.Shutdown(shutdownCtx)
+	}()
+
+	if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	fmt.Println("\nStopped.")
+	return nil
+}
+
+// servePages renders every page once at startup. With a sealed project, the
+// recovery page carries the roster and, when small enough, MANIFEST.age.
+func servePages(p *project.Project) (map[string]string, error) {
+	recoverWASM := html.GetRecoverWASMBytes()
+	if len(recoverWASM) == 0 {
+		return nil, fmt.Errorf("recover.wasm not embedded - rebuild with 'make build'")
+	}
+	createWASM := html.GetCreateWASMBytes()
+	if len(createWASM) == 0 {
+		return nil, fmt.Errorf("create.wasm not embedded - rebuild with 'make build'")
+	}
+
+	githubURL := "https://github.com/eljojo/rememory/releases/latest"
+	if strings.HasPrefix(version, "v") {
+		githubURL = fmt.Sprintf("https://github.com/eljojo/rememory/releases/tag/%s", version)
+	}
+
+	var custom *html.Customization
+	var personalization *html.PersonalizationData
+	if p != nil {
+		var err error
+		if custom, err = bundle.LoadHTMLCustomization(p); err != nil {
+			return nil, err
+		}
+		if p.Sealed != nil {
+			if personalization, err = servePersonalization(p); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return map[string]string{
+		"/index.html":   html.GenerateIndexHTML(version, githubURL),
+		"/docs.html":    html.GenerateDocsHTML(version, githubURL),
+		"/recover.html": html.GenerateRecoverHTML(recoverWASM, version, githubURL, personalization, custom),
+		"/maker.html":   html.GenerateMakerHTML(createWASM, version, githubURL, nil, custom),
+	}, nil
+}
+
+// servePersonalization describes the project to the recovery page without
+// any holder: everyone brings their own piece.
+func servePersonalization(p *project.Project) (*html.PersonalizationData, error) {
+	personalization := &html.PersonalizationData{
+		Threshold: p.Threshold,
+		Total:     p.TotalPieces(),
+		Language:  p.Language,
+		Crypto:    p.Sealed.Crypto,
+	}
+	if privacy := p.PrivacyLevel(); privacy.ListsOthers() {
+		for i, f := range p.Friends {
+			shown := privacy.Shown(f)
+			personalization.OtherFriends = append(personalization.OtherFriends, html.FriendInfo{
+				Name:       shown.Name,
+				Contact:    shown.Contact,
+				ShareIndex: i + 1,
+			})
+		}
+		// Indices can skip numbers after a friend is removed; the share files know
+		if shares, err := loadSealedShares(p); err == nil {
+			for i, s := range shares {
+				personalization.OtherFriends[i].ShareIndex = s.Index
+			}
+		}
+	}
+
+	manifestData, err := os.ReadFile(p.ManifestAgePath())
+	if err != nil {
+		return nil, fmt.Errorf("reading MANIFEST.age: %w", err)
+	}
+	if len(manifestData) <= html.MaxEmbeddedManifestSize {
+		personalization.ManifestB64 = base64.StdEncoding.EncodeToString(manifestData)
+	}
+	catalog, err := p.ReadCatalog()
+	if err != nil {
+		return nil, err
+	}
+	if catalog != nil {
+		personalization.CatalogB64 = base64.StdEncoding.EncodeToString(catalog)
+	}
+	return personalization, nil
+}
+
+// serveHandler serves the pre-rendered pages and, if manifestPath is set,
+// MANIFEST.age straight from disk.
+func serveHandler(pages map[string]string, manifestPath string) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{$}", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/index.html", http.StatusFound)
+	})
+	for path, content := range pages {
+		mux.HandleFunc("GET "+path, func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			w.Header().Set("Cache-Control", "no-store")
+			w.Write([]byte(content))
+		})
+	}
+	mux.HandleFunc("GET /MANIFEST.age", func(w http.ResponseWriter, r *http.Request) {
+		if manifestPath == "" {
+			http.Error(w, "no sealed project is being served", http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.Header().Set("Content-Disposition", `attachment; filename="MANIFEST.age"`)
+		w.Header().Set("Cache-Control", "no-store")
+		http.ServeFile(w, r, manifestPath)
+	})
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		w.Header().Set("Referrer-Policy", "no-referrer")
+		mux.ServeHTTP(w, r)
+	})
+}
+
+// lanAddresses lists this computer's IPv4 addresses on the local network.
+func lanAddresses() []string {
+	var addrs []string
+	ifaceAddrs, err := net.InterfaceAddrs()
+	if err != nil {
+		return nil
+	}
+	for _, a := range ifaceAddrs {
+		if ipNet, ok := a.(*net.IPNet); ok && !ipNet.IP.IsLoopback() && ipNet.IP.To4() != nil {
+			addrs = append(addrs, ipNet.IP.String())
+		}
+	}
+	return addrs
+}
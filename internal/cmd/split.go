@@ -0,0 +1,239 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/eljojo/rememory/internal/bundle"
+	"github.com/eljojo/rememory/internal/core"
+	"github.com/eljojo/rememory/internal/pdf"
+	"github.com/eljojo/rememory/internal/project"
+	"github.com/eljojo/rememory/internal/recovery"
+	"github.com/spf13/cobra"
+)
+
+var splitCmd = &cobra.Command{
+	Use:   "split --in FILE",
+	Short: "Split a secret of your own among the project's friends, without sealing files",
+	Long: `Split divides a short secret, like a password manager's master password or
+an age identity, into pieces for the project's friends, as seal does the
+passphrase, without archiving or encrypting any files. The friends, the
+threshold, and who holds more than one piece come from project.yml.
+
+The secret is read from the file given with --in, or from stdin with
+--in -, and kept byte for byte, trailing newline included. It can be up
+to 1024 bytes. Each friend gets, in output/split/:
+  SHARE-<name>.txt   their piece
+  bundle-<name>.zip  README.txt and README.pdf explaining the piece, and
+                     the piece itself
+
+As many pieces as the threshold give the secret back with 'rememory combine'
+or 'rememory-recover combine'. These pieces can't be written as recovery
+words and aren't read by recover.html, and they don't open a seal: each
+split has its own bundle ID and checks itself against the secret.
+
+Examples:
+  rememory split --in master-password.txt
+  age-keygen | rememory split --in - --name "age key"`,
+	Args: cobra.NoArgs,
+	RunE: runSplit,
+}
+
+var combineCmd = &cobra.Command{
+	Use:   "combine <piece>...",
+	Short: "Get back a secret split with 'rememory split'",
+	Long: `Combine rebuilds a secret split with 'rememory split' from enough of its
+pieces: share files, or the README.txt of their bundles. It writes the
+secret to the file given with -o, which must not exist yet, or to stdout.
+
+Pieces of a seal rebuild its passphrase instead; use 'rememory recover'
+for them.
+
+Examples:
+  rememory combine -o secret SHARE-alice.txt SHARE-bob.txt
+  rememory combine SHARE-alice.txt SHARE-bob.txt > secret`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runCombine,
+}
+
+func init() {
+	splitCmd.Flags().String("in", "", "File holding the secret to split, or - for stdin (required)")
+	splitCmd.Flags().String("name", "", "What the pieces call the secret (default: the file's name)")
+	splitCmd.MarkFlagRequired("in")
+	combineCmd.Flags().StringP("output", "o", "", "File to write the secret to (default: stdout)")
+	rootCmd.AddCommand(splitCmd)
+	rootCmd.AddCommand(combineCmd)
+}
+
+func runSplit(cmd *cobra.Command, args []string) error {
+	in, _ := cmd.Flags().GetString("in")
+	name, _ := cmd.Flags().GetString("name")
+
+	p, err := loadFriendProject()
+	if err != nil {
+		return err
+	}
+	if err := p.Validate(); err != nil {
+		return fmt.Errorf("invalid project: %w", err)
+	}
+	if p.Grouped() {
+		return fmt.Errorf("split doesn't support projects with groups yet")
+	}
+	if p.Crypto == core.CryptoRestricted {
+		return fmt.Errorf("split is outside the restricted crypto profile")
+	}
+
+	secret, err := readSecret(in)
+	if err != nil {
+		return err
+	}
+	defer core.Wipe(secret)
+	if name == "" {
+		name = "secret"
+		if in != "-" {
+			name = filepath.Base(in)
+		}
+	}
+
+	bundleID, err := core.NewBundleID()
+	if err != nil {
+		return err
+	}
+	total := p.TotalPieces()
+	shares, err := core.SplitSecret(secret, total, p.Threshold, bundleID)
+	if err != nil {
+		return err
+	}
+
+	dir := p.SplitPath()
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return fmt.Errorf("creating %s: %w", dir, err)
+	}
+	fmt.Printf("Splitting %q (%d bytes) into %d pieces, %d needed...\n", name, len(secret), total, p.Threshold)
+	privacy := p.PrivacyLevel()
+	created := core.Now()
+	for i, friend := range p.Friends {
+		var pieces []*core.Share
+		for _, index := range p.PieceIndexes(i) {
+			share := shares[index-1]
+			share.Holder = privacy.Holder(friend, index)
+			pieces = append(pieces, share)
+		}
+		if err := writeSecretPieces(p, dir, friend, name, pieces, total, created); err != nil {
+			return err
+		}
+		fmt.Printf("  %s: %s\n", friend.Name, filepath.Join(project.OutputDir, project.SplitDir, secretBundleName(friend)))
+	}
+	fmt.Printf("\nSaved to: %s\n", dir)
+	fmt.Printf("Bundle ID: %s\n", core.BundleFingerprint(bundleID))
+	return nil
+}
+
+// readSecret reads the secret to split from path, or stdin for "-".
+func readSecret(path string) ([]byte, error) {
+	var r io.Reader = os.Stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("reading secret: %w", err)
+		}
+		defer f.Close()
+		r = f
+	}
+	// Read one byte past the limit so SplitSecret can say the secret is too long
+	secret, err := io.ReadAll(io.LimitReader(r, core.MaxSplitSecret+1))
+	if err != nil {
+		return nil, fmt.Errorf("reading secret: %w", err)
+	}
+	return secret, nil
+}
+
+// secretBundleName is the name of a friend's bundle from 'rememory split'.
+func secretBundleName(friend project.Friend) string {
+	return fmt.Sprintf("bundle-%s.zip", core.SanitizeFilename(friend.Name))
+}
+
+// writeSecretPieces writes a friend's share file and bundle for a split
+// secret into dir.
+func writeSecretPieces(p *project.Project, dir string, friend project.Friend, name string, pieces []*core.Share, total int, created time.Time) error {
+	encoded := []byte(core.EncodeShares(pieces))
+	sharePath := filepath.Join(dir, fmt.Sprintf("SHARE-%s.txt", core.SanitizeFilename(friend.Name)))
+	if err := os.WriteFile(sharePath, encoded, 0600); err != nil {
+		return fmt.Errorf("writing %s: %w", sharePath, err)
+	}
+
+	releaseURL := fmt.Sprintf("https://github.com/eljojo/rememory/releases/tag/%s", version)
+	readme := bundle.GenerateSecretReadme(bundle.ReadmeData{
+		ProjectName:      name,
+		Holder:           pieces[0].Holder,
+		Share:            pieces[0],
+		Extra:            pieces[1:],
+		Threshold:        p.Threshold,
+		Total:            total,
+		Version:          version,
+		GitHubReleaseURL: releaseURL,
+		Created:          created,
+	})
+	readmePDF, err := pdf.GenerateSecretPiece(pdf.SecretPieceData{
+		Name:             name,
+		Holder:           pieces[0].Holder,
+		Shares:           pieces,
+		Threshold:        p.Threshold,
+		Total:            total,
+		Version:          version,
+		GitHubReleaseURL: releaseURL,
+		Created:          created,
+	})
+	if err != nil {
+		return fmt.Errorf("generating README.pdf for %s: %w", friend.Name, err)
+	}
+	return bundle.CreateZip(filepath.Join(dir, secretBundleName(friend)), []bundle.ZipFile{
+		{Name: "README.txt", Content: []byte(readme), ModTime: created},
+		{Name: "README.pdf", Content: readmePDF, ModTime: created},
+		{Name: filepath.Base(sharePath), Content: encoded, ModTime: created},
+	})
+}
+
+func runCombine(cmd *cobra.Command, args []string) error {
+	output, _ := cmd.Flags().GetString("output")
+
+	shares, err := recovery.ReadShareFiles(args)
+	if err != nil {
+		return err
+	}
+	if len(shares) > 0 && !shares[0].Secret {
+		return fmt.Errorf("these pieces are from a seal; open it with 'rememory recover'")
+	}
+	secret, err := recovery.CombineSecret(shares)
+	if err != nil {
+		return err
+	}
+	defer secret.Wipe()
+
+	if output == "" {
+		_, err = os.Stdout.Write(secret)
+		return err
+	}
+	f, err := os.OpenFile(output, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if errors.Is(err, os.ErrExist) {
+		return fmt.Errorf("%s already exists; choose another file", output)
+	}
+	if err != nil {
+		return fmt.Errorf("creating %s: %w", output, err)
+	}
+	if _, err := f.Write(secret); err != nil {
+		f.Close()
+		os.Remove(output)
+		return fmt.Errorf("writing %s: %w", output, err)
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(output)
+		return fmt.Errorf("writing %s: %w", output, err)
+	}
+	fmt.Printf("Combined %d pieces into %s (%d bytes)\n", len(shares), output, len(secret))
+	return nil
+}
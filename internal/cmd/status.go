@@ -40,6 +40,10 @@ func runStatus(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("loading project: %w", err)
 	}
 
+	if jsonOutput {
+		return printJSON(statusFor(p))
+	}
+
 	// Print status
 	fmt.Printf("Project: %s\n", p.Name)
 	fmt.Printf("Path: %s\n\n", p.Path)
@@ -47,14 +51,36 @@ func runStatus(cmd *cobra.Command, args []string) error {
 	// Sealed status
 	if p.Sealed != nil {
 		fmt.Printf("Sealed: %s (%s)\n", green("Yes"), p.Sealed.At.Format("2006-01-02 15:04:05 UTC"))
+		if !p.Sealed.Updated.IsZero() {
+			fmt.Printf("Manifest Updated: %s (same pieces)\n", p.Sealed.Updated.Format("2006-01-02 15:04:05 UTC"))
+		}
 		fmt.Printf("Manifest Checksum: %s\n", truncateHash(p.Sealed.ManifestChecksum))
+		if id := p.Sealed.BundleID; id != "" {
+			fmt.Printf("Bundle ID: %s (%s)\n", core.BundleFingerprint(id), id)
+		}
 	} else {
 		fmt.Printf("Sealed: %s\n", yellow("No"))
 		fmt.Println("  Run 'rememory seal' to encrypt and split the passphrase")
 	}
 
+	if p.Crypto != "" {
+		fmt.Printf("Crypto Profile: %s\n", p.Crypto)
+	}
+	if p.PostQuantum {
+		fmt.Println("Post-Quantum: scrypt + ML-KEM-768")
+	}
+
 	// Threshold
-	fmt.Printf("\nThreshold: %d of %d\n", p.Threshold, len(p.Friends))
+	if p.Grouped() {
+		fmt.Printf("\nThreshold: %d of %d groups\n", p.Threshold, len(p.Groups))
+		for i, g := range p.Groups {
+			fmt.Printf("  %s: %d of %d\n", g.Name, g.Threshold, len(p.GroupMembers(i+1)))
+		}
+	} else if p.Weighted() {
+		fmt.Printf("\nThreshold: %d of %d pieces\n", p.Threshold, p.TotalPieces())
+	} else {
+		fmt.Printf("\nThreshold: %d of %d\n", p.Threshold, len(p.Friends))
+	}
 
 	// Friends
 	fmt.Println("\nShare holders:")
@@ -68,7 +94,33 @@ func runStatus(cmd *cobra.Command, args []string) error {
 		if contactInfo == "" {
 			contactInfo = "no contact info"
 		}
+		if n := friend.Pieces(); n > 1 {
+			contactInfo += fmt.Sprintf(", %d pieces", n)
+		}
 		fmt.Printf("  %d. %s %s (%s)\n", i+1, status, friend.Name, contactInfo)
+		for _, m := range statusMedia(p, friend) {
+			name := m.Serial
+			if m.Label != "" {
+				name += " (" + m.Label + ")"
+			}
+			if m.Type != "" {
+				name += ", " + m.Type
+			}
+			dates := "verified " + m.Verified.Local().Format("2006-01-02")
+			if m.Written != nil {
+				dates = "written " + m.Written.Local().Format("2006-01-02") + ", " + dates
+			}
+			switch {
+			case m.Failed != "":
+				fmt.Printf("       %s %s: read back %s, %s\n", red("✗"), name, m.Verified.Local().Format("2006-01-02"), red("FAILED"))
+			case m.Due:
+				fmt.Printf("       %s %s: %s, %s\n", yellow("!"), name, dates, yellow("due to be written again since "+m.RefreshDue.Local().Format("2006-01-02")))
+			case !m.Current:
+				fmt.Printf("       %s %s: %s, %s\n", yellow("○"), name, dates, yellow("from an earlier seal"))
+			default:
+				fmt.Printf("       %s %s: %s\n", green("✓"), name, dates)
+			}
+		}
 	}
 
 	// Bundles status
@@ -84,6 +136,16 @@ func runStatus(cmd *cobra.Command, args []string) error {
 		fmt.Printf("Bundles: %s (seal first)\n", yellow("Not available"))
 	}
 
+	// Last change, from the event log
+	if last := lastEvent(p); last != nil {
+		fmt.Printf("\nLast change: %s on %s", last.Command, last.At.Local().Format("2006-01-02 15:04"))
+		if last.Detail != "" {
+			fmt.Printf(" (%s)", last.Detail)
+		}
+		fmt.Println()
+		fmt.Println("  Run 'rememory log' for the full history")
+	}
+
 	// Rotation reminder
 	if p.Sealed != nil {
 		age := time.Since(p.Sealed.At)
@@ -100,10 +162,95 @@ func runStatus(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+type statusResult struct {
+	Project          string         `json:"project"`
+	Path             string         `json:"path"`
+	Sealed           bool           `json:"sealed"`
+	SealedAt         *time.Time     `json:"sealedAt,omitempty"`
+	UpdatedAt        *time.Time     `json:"updatedAt,omitempty"` // Last 'seal --update'
+	ManifestChecksum string         `json:"manifestChecksum,omitempty"`
+	BundleID         string         `json:"bundleId,omitempty"`
+	Threshold        int            `json:"threshold"`
+	Total            int            `json:"total"`
+	Friends          []statusFriend `json:"friends"`
+	Bundles          []jsonBundle   `json:"bundles"`
+	LastEvent        *project.Event `json:"lastEvent,omitempty"`
+}
+
+type statusFriend struct {
+	jsonFriend
+	HasShare bool           `json:"hasShare"`
+	Media    []statusMedium `json:"media,omitempty"`
+}
+
+// statusMedium is a physical copy of a friend's bundle, as last read back.
+type statusMedium struct {
+	Serial     string     `json:"serial"`
+	Label      string     `json:"label,omitempty"`
+	Type       string     `json:"type,omitempty"`
+	Written    *time.Time `json:"written,omitempty"`
+	Verified   time.Time  `json:"verified"`
+	RefreshDue time.Time  `json:"refreshDue"`
+	Due        bool       `json:"due"`     // Old enough that it's due to be written again
+	Current    bool       `json:"current"` // Holds the current seal
+	Failed     string     `json:"failed,omitempty"`
+}
+
+// statusMedia lists the friend's physical copies, telling which hold the
+// current seal and which are due to be written again.
+func statusMedia(p *project.Project, f project.Friend) []statusMedium {
+	var media []statusMedium
+	for _, m := range f.Media {
+		current := p.Sealed != nil && m.Manifest == p.Sealed.ManifestChecksum
+		due := p.RefreshDue(m)
+		sm := statusMedium{
+			Serial: m.Serial, Label: m.Label, Type: m.Type, Verified: m.Verified,
+			RefreshDue: due, Due: !due.After(time.Now()), Current: current, Failed: m.Failed,
+		}
+		if written := m.Written; !written.IsZero() {
+			sm.Written = &written
+		}
+		media = append(media, sm)
+	}
+	return media
+}
+
+func statusFor(p *project.Project) statusResult {
+	result := statusResult{
+		Project:   p.Name,
+		Path:      p.Path,
+		Sealed:    p.Sealed != nil,
+		Threshold: p.Threshold,
+		Total:     p.TotalPieces(),
+		Bundles:   jsonBundles(p),
+		LastEvent: lastEvent(p),
+	}
+	if p.Sealed != nil {
+		result.SealedAt = &p.Sealed.At
+		if !p.Sealed.Updated.IsZero() {
+			result.UpdatedAt = &p.Sealed.Updated
+		}
+		result.ManifestChecksum = p.Sealed.ManifestChecksum
+		result.BundleID = p.Sealed.BundleID
+	}
+	for i, f := range jsonFriends(p.Friends) {
+		result.Friends = append(result.Friends, statusFriend{jsonFriend: f, HasShare: checkShareExists(p, p.Friends[i]), Media: statusMedia(p, p.Friends[i])})
+	}
+	return result
+}
+
+// lastEvent returns the newest event in the project's log, or nil when
+// there is none.
+func lastEvent(p *project.Project) *project.Event {
+	events, err := p.Events()
+	if err != nil || len(events) == 0 {
+		return nil
+	}
+	return &events[len(events)-1]
+}
+
 func checkShareExists(p *project.Project, friend project.Friend) bool {
-	sharesDir := p.SharesPath()
-	filename := fmt.Sprintf("SHARE-%s.txt", core.SanitizeFilename(friend.Name))
-	_, err := os.Stat(filepath.Join(sharesDir, filename))
+	_, err := os.Stat(p.SharePath(friend))
 	return err == nil
 }
 
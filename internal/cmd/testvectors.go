@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/eljojo/rememory/internal/testvectors"
+	"github.com/spf13/cobra"
+)
+
+var testvectorsCmd = &cobra.Command{
+	Use:   "testvectors",
+	Short: "Write test vectors for independent recovery implementations",
+	Long: `Testvectors writes a JSON file of known passphrases, shares in every
+encoding (PEM, compact, and 25 words in each language), age-encrypted
+manifests, and the files they contain, plus malformed shares that must be
+rejected.
+
+Use it to check a mobile app, an audit tool, or a port to another language
+against the reference implementation. Every value is verified by rememory
+itself before it is written. Shares and ciphertexts are freshly random on each
+run; the secrets are fixed.
+
+Example:
+  rememory testvectors -o vectors.json`,
+	Args: cobra.NoArgs,
+	RunE: runTestvectors,
+}
+
+func init() {
+	testvectorsCmd.Flags().StringP("output", "o", "", "Write to this file instead of stdout")
+	rootCmd.AddCommand(testvectorsCmd)
+}
+
+func runTestvectors(cmd *cobra.Command, args []string) error {
+	output, _ := cmd.Flags().GetString("output")
+
+	v, err := testvectors.Generate("rememory " + version)
+	if err != nil {
+		return fmt.Errorf("generating test vectors: %w", err)
+	}
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+
+	if output == "" {
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+	if err := os.WriteFile(output, data, 0644); err != nil {
+		return fmt.Errorf("writing %s: %w", output, err)
+	}
+	fmt.Printf("Wrote %d cases and %d invalid inputs to %s\n", len(v.Cases), len(v.Invalid), output)
+	return nil
+}
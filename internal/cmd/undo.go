@@ -0,0 +1,127 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/eljojo/rememory/internal/project"
+	"github.com/spf13/cobra"
+)
+
+var undoCmd = &cobra.Command{
+	Use:   "undo",
+	Short: "Put the project back as it was before the last change",
+	Long: `Before seal, seal-all, friend add, friend remove, and notify change a
+project, they copy project.yml into backups/, along with what a seal writes
+that bundles are made from when the project is sealed: MANIFEST.age, the
+share files, and TIMELOCK.json, CATALOG.age, DECOY.age, the duress pieces,
+OWNER.age, RECIPIENTS.age, and MASTER-CODE.pdf when it has them. Undo puts the newest of these copies
+back and removes it, so running it again goes one step further back.
+
+Files the undone command made or rewrote under output/, such as bundles and
+printouts from a seal, would hold the wrong pieces once the project is put
+back. Undo lists them and asks before deleting them along with restoring
+(--yes skips the question). Bundles aren't copied; after undoing a seal or a
+friend change, run 'rememory bundle' to make them again from the restored
+files. The newest
+10 copies are kept, or as many as 'backups' in project.yml says (a negative
+number turns them off). Like output/, backups/ holds every piece: keep it
+as safe as the project itself.
+
+Example:
+  rememory undo --list
+  rememory undo`,
+	Args: cobra.NoArgs,
+	RunE: runUndo,
+}
+
+func init() {
+	undoCmd.Flags().Bool("list", false, "List the backups instead of restoring one")
+	undoCmd.Flags().BoolP("yes", "y", false, "Delete the files the undone command made without asking")
+	rootCmd.AddCommand(undoCmd)
+}
+
+func runUndo(cmd *cobra.Command, args []string) error {
+	list, _ := cmd.Flags().GetBool("list")
+	yes, _ := cmd.Flags().GetBool("yes")
+
+	p, err := loadFriendProject()
+	if err != nil {
+		return err
+	}
+	backups, err := p.ListBackups()
+	if err != nil {
+		return err
+	}
+
+	if list {
+		if len(backups) == 0 {
+			fmt.Println("No backups yet.")
+			return nil
+		}
+		for _, b := range backups {
+			fmt.Printf("  %s  before %s%s\n", b.At.Local().Format("2006-01-02 15:04:05"), b.Command, sealedNote(b))
+		}
+		return nil
+	}
+
+	if len(backups) == 0 {
+		return fmt.Errorf("nothing to undo: no backups in %s", p.BackupsPath())
+	}
+	b := backups[0]
+	withShares := sealedNote(b) != ""
+	made, err := p.Artifacts(b)
+	if err != nil {
+		return err
+	}
+	if len(made) > 0 {
+		fmt.Printf("'%s' made or rewrote %d file%s, which undo deletes:\n", b.Command, len(made), plural(len(made)))
+		for _, path := range made {
+			fmt.Printf("  %s\n", path)
+		}
+		if !yes {
+			fmt.Printf("Undo '%s' and delete them? [y/N]: ", b.Command)
+			answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+			if a := strings.ToLower(strings.TrimSpace(answer)); a != "y" && a != "yes" {
+				fmt.Println("Nothing undone.")
+				return nil
+			}
+		}
+	}
+
+	if err := p.Restore(b); err != nil {
+		return err
+	}
+	if err := p.RemoveArtifacts(made); err != nil {
+		return err
+	}
+	logEvent(p, "undo", "undid %s from %s", b.Command, b.At.Local().Format("2006-01-02 15:04"))
+	fmt.Printf("%s Restored the project as it was before '%s' (%s)\n", green("✓"), b.Command, b.At.Local().Format("2006-01-02 15:04"))
+	if len(made) > 0 {
+		fmt.Printf("%s Deleted %d file%s it made\n", green("✓"), len(made), plural(len(made)))
+	}
+	if withShares {
+		fmt.Println("Run 'rememory bundle' to make the bundles again from the restored shares.")
+	}
+	return nil
+}
+
+// sealedNote marks a backup that holds a seal's MANIFEST.age and shares.
+func sealedNote(b project.Backup) string {
+	if _, err := os.Stat(filepath.Join(b.Path, project.OutputDir, project.SharesDir)); err == nil {
+		return " (with shares)"
+	}
+	return ""
+}
+
+// snapshot backs the project up before command changes it, for 'rememory
+// undo'.
+func snapshot(p *project.Project, command string) error {
+	if _, err := p.Snapshot(command); err != nil {
+		return fmt.Errorf("backing up project: %w", err)
+	}
+	return nil
+}
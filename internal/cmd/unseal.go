@@ -0,0 +1,290 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/eljojo/rememory/internal/core"
+	"github.com/eljojo/rememory/internal/crypto"
+	"github.com/eljojo/rememory/internal/project"
+	"github.com/eljojo/rememory/internal/recovery"
+	"github.com/spf13/cobra"
+)
+
+// ownerEscrowFile holds the passphrase encrypted with the owner's password.
+const ownerEscrowFile = project.OwnerEscrowFile
+
+var unsealCmd = &cobra.Command{
+	Use:   "unseal [share files...]",
+	Short: "Decrypt your own sealed project back into files",
+	Long: `Unseal decrypts the project's MANIFEST.age and restores the original files,
+without going through the browser recovery flow.
+
+Run it inside a sealed project. The passphrase comes from one of:
+  - the share files you pass as arguments (at least the threshold)
+  - --owner: the owner escrow written by 'rememory seal --owner-escrow',
+    unlocked with your own password
+  - --identity: RECIPIENTS.age, written when project.yml lists recipients,
+    unlocked with an age identity file (tried in the order given)
+  - --master-code: RECIPIENTS.age, unlocked with the master code printed by
+    'rememory seal --master-code', typed in as words or as its key
+  - otherwise, the pieces in output/shares/
+
+Examples:
+  rememory unseal
+  rememory unseal --owner -o ~/restored
+  rememory unseal --identity ~/.config/age/key.txt
+  rememory unseal --master-code
+  rememory unseal SHARE-alice.txt SHARE-bob.txt
+
+Files and folders get back the permissions, modification times, and
+extended attributes they were sealed with, unless --no-metadata is given.`,
+	RunE: runUnseal,
+}
+
+var (
+	unsealOutput     string
+	unsealOwner      bool
+	unsealIdentities []string
+	unsealMasterCode bool
+	unsealNoMetadata bool
+)
+
+func init() {
+	unsealCmd.Flags().StringVarP(&unsealOutput, "output", "o", "", "Output directory (default: unsealed-DATE)")
+	unsealCmd.Flags().BoolVar(&unsealOwner, "owner", false, "Unlock with your owner password instead of shares")
+	unsealCmd.Flags().StringArrayVarP(&unsealIdentities, "identity", "i", nil, "Unlock RECIPIENTS.age with this age identity file instead of shares (repeatable)")
+	unsealCmd.Flags().BoolVar(&unsealMasterCode, "master-code", false, "Unlock RECIPIENTS.age with the master code instead of shares")
+	unsealCmd.Flags().BoolVar(&unsealNoMetadata, "no-metadata", false, "Don't restore the files' permissions, times, and extended attributes")
+	rootCmd.AddCommand(unsealCmd)
+}
+
+func runUnseal(cmd *cobra.Command, args []string) error {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("getting current directory: %w", err)
+	}
+
+	projectDir, err := project.FindProjectDir(cwd)
+	if err != nil {
+		return fmt.Errorf("no rememory project found (use 'rememory recover' outside a project)")
+	}
+
+	p, err := project.Load(projectDir)
+	if err != nil {
+		return fmt.Errorf("loading project: %w", err)
+	}
+	if p.Sealed == nil {
+		return fmt.Errorf("project is not sealed — your files are still in %s", p.ManifestPath())
+	}
+
+	restricted := p.Sealed.Crypto == core.CryptoRestricted
+	if restricted && unsealOwner {
+		return fmt.Errorf("--owner is outside the restricted crypto profile this project was sealed under")
+	}
+	if restricted && unsealMasterCode {
+		return fmt.Errorf("--master-code is outside the restricted crypto profile this project was sealed under")
+	}
+	unlocks := 0
+	for _, set := range []bool{unsealOwner, len(unsealIdentities) > 0, unsealMasterCode} {
+		if set {
+			unlocks++
+		}
+	}
+	if unlocks > 1 {
+		return fmt.Errorf("use only one of --owner, --identity, and --master-code")
+	}
+
+	var passphrase core.Secret
+	defer func() { passphrase.Wipe() }()
+	switch {
+	case unsealOwner:
+		if len(args) > 0 {
+			return fmt.Errorf("--owner doesn't take share files")
+		}
+		passphrase, err = unlockOwnerEscrow(p)
+	case len(unsealIdentities) > 0:
+		if len(args) > 0 {
+			return fmt.Errorf("--identity doesn't take share files")
+		}
+		passphrase, err = unlockRecipients(filepath.Join(p.OutputPath(), core.RecipientsFile), unsealIdentities)
+	case unsealMasterCode:
+		if len(args) > 0 {
+			return fmt.Errorf("--master-code doesn't take share files")
+		}
+		passphrase, err = unlockMasterCode(filepath.Join(p.OutputPath(), core.RecipientsFile))
+	case len(args) > 0:
+		fmt.Printf("Reading %d share files...\n", len(args))
+		passphrase, err = passphraseFromShareFiles(args, restricted, true)
+	default:
+		paths := make([]string, len(p.Sealed.Shares))
+		for i, si := range p.Sealed.Shares {
+			paths[i] = filepath.Join(p.Path, si.File)
+		}
+		fmt.Printf("Using the %d pieces in %s...\n", len(paths), p.SharesPath())
+		passphrase, err = passphraseFromShareFiles(paths, restricted, true)
+	}
+	if err != nil {
+		return err
+	}
+
+	if !core.VerifyHash(core.HashBytes(passphrase), p.Sealed.VerificationHash) {
+		return fmt.Errorf("the passphrase doesn't match this project's seal")
+	}
+
+	fmt.Println("Decrypting manifest...")
+	encryptedData, err := os.ReadFile(p.ManifestAgePath())
+	if err != nil {
+		return fmt.Errorf("reading manifest: %w", err)
+	}
+	if restricted {
+		if err := core.CheckRestrictedManifest(encryptedData); err != nil {
+			return err
+		}
+	}
+
+	// The owner waits out the recovery delay too: nothing kept skips it
+	timelockData, err := p.ReadTimelock()
+	if err != nil {
+		return err
+	}
+	var timelock *core.Timelock
+	if timelockData != nil {
+		if timelock, err = core.ParseTimelock(timelockData); err != nil {
+			return err
+		}
+	}
+	opener, err := recovery.WaitOut(os.Stdout, timelock, passphrase)
+	if err != nil {
+		return err
+	}
+	defer opener.Wipe()
+
+	decrypt := func(w io.Writer) error { return core.Decrypt(w, bytes.NewReader(encryptedData), string(opener)) }
+	decryptFailed := func(err error) error { return fmt.Errorf("decryption failed: %w", err) }
+
+	outputDir := unsealOutput
+	if outputDir == "" {
+		outputDir = fmt.Sprintf("unsealed-%s", core.Now().Format("2006-01-02"))
+	}
+
+	extractResult, err := extractDecrypted(decrypt, decryptFailed, outputDir, !unsealNoMetadata)
+	if err != nil {
+		return err
+	}
+	for _, warning := range extractResult.Warnings {
+		fmt.Printf("  Warning: %s\n", warning)
+	}
+	printIndexCheck(extractResult.Index)
+
+	return printRecoveredFiles(extractResult.Path)
+}
+
+func passphraseFromShareFiles(paths []string, restricted, ignoreExpiry bool) (core.Secret, error) {
+	if mnemonics, ok, err := recovery.ReadSLIP39Files(paths); err != nil {
+		return nil, err
+	} else if ok {
+		if restricted {
+			return nil, fmt.Errorf("SLIP-0039 words are outside the restricted crypto profile")
+		}
+		fmt.Printf("Combining %d sets of SLIP-0039 words...\n", len(mnemonics))
+		return recovery.CombineSLIP39(mnemonics)
+	}
+	if sskr, ok, err := recovery.ReadSSKRFiles(paths); err != nil {
+		return nil, err
+	} else if ok {
+		if restricted {
+			return nil, fmt.Errorf("SSKR shares are outside the restricted crypto profile")
+		}
+		fmt.Printf("Combining %d SSKR shares...\n", len(sskr))
+		return recovery.CombineSSKR(sskr)
+	}
+	if ssss, threshold, ok, err := recovery.ReadSSSSFiles(paths); err != nil {
+		return nil, err
+	} else if ok {
+		if restricted {
+			return nil, fmt.Errorf("ssss shares are outside the restricted crypto profile")
+		}
+		fmt.Printf("Combining %d ssss shares...\n", len(ssss))
+		return recovery.CombineSSSS(ssss, threshold)
+	}
+
+	shares, err := recovery.ReadShareFiles(paths)
+	if err != nil {
+		return nil, err
+	}
+	if restricted {
+		if err := recovery.CheckRestricted(shares, nil); err != nil {
+			return nil, err
+		}
+	}
+	if err := recovery.UnlockPINs(shares, askPIN); err != nil {
+		return nil, err
+	}
+	warning, err := recovery.SunsetOf(shares).Check(time.Now(), ignoreExpiry)
+	if err != nil {
+		return nil, err
+	}
+	if warning != "" {
+		fmt.Printf("  Warning: %s\n", warning)
+	}
+	return combineShares(shares)
+}
+
+// unlockOwnerEscrow asks for the owner password and decrypts OWNER.age.
+func unlockOwnerEscrow(p *project.Project) (core.Secret, error) {
+	escrowPath := filepath.Join(p.OutputPath(), ownerEscrowFile)
+	data, err := os.ReadFile(escrowPath)
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("no owner escrow in this project (seal with --owner-escrow to create one)")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading owner escrow: %w", err)
+	}
+
+	password, err := readPassword("Owner password")
+	if err != nil {
+		return nil, err
+	}
+
+	passphrase, err := core.DecryptBytes(data, password)
+	if err != nil {
+		return nil, fmt.Errorf("wrong owner password, or the escrow file is damaged")
+	}
+	return passphrase, nil
+}
+
+// unlockRecipients decrypts the RECIPIENTS.age at path with the age identity
+// files, trying their identities in order.
+func unlockRecipients(path string, identityFiles []string) (core.Secret, error) {
+	data, err := readRecipientsFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	identities, err := crypto.ReadIdentities(identityFiles)
+	if err != nil {
+		return nil, err
+	}
+	passphrase, err := core.DecryptWithIdentities(data, identities)
+	if err != nil {
+		return nil, fmt.Errorf("none of the identities unlock %s: %w", core.RecipientsFile, crypto.PluginHint(err))
+	}
+	return passphrase, nil
+}
+
+// readRecipientsFile reads the RECIPIENTS.age at path.
+func readRecipientsFile(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("no %s here (add recipients to project.yml, or seal with --master-code, to create one)", core.RecipientsFile)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", core.RecipientsFile, err)
+	}
+	return data, nil
+}
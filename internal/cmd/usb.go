@@ -0,0 +1,347 @@
+package cmd
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/eljojo/rememory/internal/bundle"
+	"github.com/eljojo/rememory/internal/project"
+	"github.com/eljojo/rememory/internal/translations"
+	"github.com/spf13/cobra"
+)
+
+var usbCmd = &cobra.Command{
+	Use:   "usb",
+	Short: "Put a friend's bundle on a USB drive",
+}
+
+var usbPrepareCmd = &cobra.Command{
+	Use:   "prepare <device | folder> --friend NAME",
+	Short: "Format a USB drive and copy a friend's bundle onto it",
+	Long: `Prepare puts a friend's bundle on a USB drive, ready to hand over: the
+bundle's files at the top of the drive, the bundle ZIP itself, and an
+index.html that links to recover.html and the README, for whoever opens the
+drive and doesn't know where to start. Nothing on the drive runs by itself:
+there is no autorun file, and index.html has no script.
+
+Given a device, it erases and formats the drive first, with a volume label,
+after showing which drive it is and asking you to confirm:
+  Linux    /dev/sdb (the whole drive) or /dev/sdb1 (one partition)
+  macOS    /dev/disk4 or /dev/disk4s1
+  Windows  E:
+Formatting needs the system's own tools (sfdisk and mkfs.vfat or mkfs.exfat
+with udisksctl on Linux, diskutil on macOS, PowerShell on Windows) and
+usually administrator rights. Only removable drives are formatted.
+
+Given the folder a drive is mounted at, or with --no-format, it copies onto
+the drive as it is.
+
+Every file is read back from the drive after copying and compared with the
+bundle, so a failing drive shows up before it's handed over. The drive is
+recorded in project.yml with its type and the date, as 'rememory
+verify-media' does, so 'rememory status' can tell when it's due to be
+written again; give --type sd for an SD card.
+
+Examples:
+  sudo rememory usb prepare /dev/sdb --friend Alice
+  rememory usb prepare /dev/disk4 --friend Bob --label BOB
+  rememory usb prepare /media/me/STICK --friend Camila
+  rememory usb prepare E: --friend Alice --no-format`,
+	Args: cobra.ExactArgs(1),
+	RunE: runUSBPrepare,
+}
+
+func init() {
+	usbPrepareCmd.Flags().String("friend", "", "Friend whose bundle goes on the drive (required)")
+	usbPrepareCmd.Flags().String("label", "REMEMORY", "Volume label, up to 11 letters, digits, spaces, - or _")
+	usbPrepareCmd.Flags().String("filesystem", "fat32", "fat32, which every system reads, or exfat, for files over 4 GB")
+	usbPrepareCmd.Flags().Bool("no-format", false, "Copy onto the drive as it is, without formatting it")
+	usbPrepareCmd.Flags().String("type", project.MediaUSB, "Type of drive, for when it's due to be written again: "+strings.Join(project.MediaTypes, ", "))
+	usbPrepareCmd.Flags().BoolP("yes", "y", false, "Format without asking for confirmation")
+	usbPrepareCmd.MarkFlagRequired("friend")
+	usbCmd.AddCommand(usbPrepareCmd)
+	rootCmd.AddCommand(usbCmd)
+}
+
+// usbDrive is a drive 'usb prepare' may format.
+type usbDrive struct {
+	Device      string // As given, e.g. /dev/sdb, /dev/disk4s1, or E:
+	Description string // Model and size, as the system reports them
+	Removable   bool
+	Partition   bool // Device is one partition, not the whole drive
+}
+
+// volumeLabel is what FAT32 and exFAT both take as a label.
+var volumeLabel = regexp.MustCompile(`^[A-Za-z0-9 _-]{1,11}$`)
+
+func runUSBPrepare(cmd *cobra.Command, args []string) error {
+	target := args[0]
+	name, _ := cmd.Flags().GetString("friend")
+	label, _ := cmd.Flags().GetString("label")
+	filesystem, _ := cmd.Flags().GetString("filesystem")
+	noFormat, _ := cmd.Flags().GetBool("no-format")
+	yes, _ := cmd.Flags().GetBool("yes")
+	kind, _ := cmd.Flags().GetString("type")
+
+	filesystem = strings.ToLower(filesystem)
+	if filesystem != "fat32" && filesystem != "exfat" {
+		return fmt.Errorf("unknown --filesystem %q: use fat32 or exfat", filesystem)
+	}
+	if !volumeLabel.MatchString(label) {
+		return fmt.Errorf("--label must be 1 to 11 letters, digits, spaces, - or _")
+	}
+	label = strings.ToUpper(label)
+	kind = strings.ToLower(strings.TrimSpace(kind))
+	if err := project.CheckMediaType(kind); err != nil {
+		return fmt.Errorf("--type: %w", err)
+	}
+
+	p, err := loadFriendProject()
+	if err != nil {
+		return err
+	}
+	if p.Sealed == nil {
+		return fmt.Errorf("project must be sealed first (run 'rememory seal')")
+	}
+	idx := -1
+	for i, f := range p.Friends {
+		if strings.EqualFold(f.Name, strings.TrimSpace(name)) {
+			idx = i
+			break
+		}
+	}
+	if idx == -1 {
+		return fmt.Errorf("no friend named %q in the project", name)
+	}
+	friend := p.Friends[idx]
+	bundlePath := friendBundlePath(p, friend)
+	if err := bundle.VerifyBundle(bundlePath); err != nil {
+		return fmt.Errorf("%s's bundle: %w (run 'rememory bundle' to regenerate it)", friend.Name, err)
+	}
+
+	format := !noFormat && isDriveDevice(target)
+	mount := target
+	if format {
+		drive, err := inspectDrive(target)
+		if err != nil {
+			return err
+		}
+		if !drive.Removable {
+			return fmt.Errorf("%s (%s) isn't a removable drive; refusing to format it", drive.Device, drive.Description)
+		}
+		fmt.Printf("Drive: %s (%s)\n", drive.Device, drive.Description)
+		fmt.Printf("  %s Everything on it will be erased and it will be formatted as %s, labeled %s.\n", yellow("Note:"), strings.ToUpper(filesystem), label)
+		if !yes {
+			fmt.Printf("Erase %s? [y/N]: ", drive.Device)
+			answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+			if a := strings.ToLower(strings.TrimSpace(answer)); a != "y" && a != "yes" {
+				fmt.Println("Nothing changed.")
+				return nil
+			}
+		}
+		fmt.Printf("Formatting %s...\n", drive.Device)
+		if mount, err = formatDrive(drive, label, filesystem); err != nil {
+			return err
+		}
+	} else {
+		if cmd.Flags().Changed("label") || cmd.Flags().Changed("filesystem") {
+			return fmt.Errorf("--label and --filesystem only apply when formatting; give the drive's device instead of a folder")
+		}
+		mount = driveFolder(mount)
+		if info, err := os.Stat(mount); err != nil || !info.IsDir() {
+			return fmt.Errorf("%s is not a folder; give the folder the drive is mounted at, or its device to format it", target)
+		}
+	}
+
+	fmt.Printf("Copying %s's bundle to %s...\n", friend.Name, mount)
+	written, err := copyBundleToDrive(p, idx, bundlePath, mount)
+	if err != nil {
+		return err
+	}
+
+	fmt.Print("Reading it back... ")
+	readErr := readBack(written)
+	if readErr != nil {
+		fmt.Println("FAILED")
+	} else {
+		fmt.Println("OK")
+	}
+	recordDrive(p, idx, bundlePath, mount, kind, readErr)
+	if readErr != nil {
+		return fmt.Errorf("%w; the drive may be failing, so try another one", readErr)
+	}
+
+	for _, f := range written {
+		fmt.Printf("  %s %s\n", green("✓"), filepath.Base(f.path))
+	}
+	fmt.Println()
+	fmt.Printf("%s's drive is ready. Eject it before unplugging it.\n", friend.Name)
+	fmt.Printf("  %s It holds %s's piece: hand it to them in person, or send it the way you would the bundle.\n", yellow("Note:"), friend.Name)
+	logEvent(p, "usb", "prepared a drive for %s at %s", friend.Name, mount)
+	return nil
+}
+
+// driveFile is a file copied onto the drive, with the SHA-256 it should
+// read back as.
+type driveFile struct {
+	path string
+	sum  []byte
+}
+
+// copyBundleToDrive writes the bundle's files, the bundle ZIP, and the
+// index page to the top of the drive mounted at mount, syncing each one.
+func copyBundleToDrive(p *project.Project, i int, bundlePath, mount string) ([]driveFile, error) {
+	r, err := zip.OpenReader(bundlePath)
+	if err != nil {
+		return nil, fmt.Errorf("opening bundle: %w", err)
+	}
+	defer r.Close()
+
+	var written []driveFile
+	readme := ""
+	for _, f := range r.File {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+		// A bundle's files are all at its top; anything else isn't ours
+		if strings.ContainsAny(f.Name, `/\:`) || f.Name == "." || f.Name == ".." {
+			return nil, fmt.Errorf("unexpected file %q in bundle", f.Name)
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, fmt.Errorf("opening %s: %w", f.Name, err)
+		}
+		file, err := writeDriveFile(filepath.Join(mount, f.Name), rc)
+		rc.Close()
+		if err != nil {
+			return nil, err
+		}
+		written = append(written, file)
+		if translations.IsReadmeFile(f.Name, ".pdf") || (readme == "" && translations.IsReadmeFile(f.Name, ".txt")) {
+			readme = f.Name
+		}
+	}
+
+	src, err := os.Open(bundlePath)
+	if err != nil {
+		return nil, fmt.Errorf("opening bundle: %w", err)
+	}
+	file, err := writeDriveFile(filepath.Join(mount, filepath.Base(bundlePath)), src)
+	src.Close()
+	if err != nil {
+		return nil, err
+	}
+	written = append(written, file)
+
+	friend := p.Friends[i]
+	lang := friend.Language
+	if lang == "" {
+		lang = p.Language
+	}
+	holder := p.PrivacyLevel().Holder(friend, p.PieceIndexes(i)[0])
+	index := bundle.GenerateDriveIndex(lang, holder, readme)
+	if file, err = writeDriveFile(filepath.Join(mount, bundle.DriveIndexFilename), bytes.NewReader(index)); err != nil {
+		return nil, err
+	}
+	return append(written, file), nil
+}
+
+// writeDriveFile copies r to path and syncs it to the drive.
+func writeDriveFile(path string, r io.Reader) (driveFile, error) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return driveFile{}, fmt.Errorf("creating %s: %w", path, err)
+	}
+	h := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(f, h), r); err != nil {
+		f.Close()
+		return driveFile{}, fmt.Errorf("writing %s: %w", path, err)
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return driveFile{}, fmt.Errorf("writing %s: %w", path, err)
+	}
+	if err := f.Close(); err != nil {
+		return driveFile{}, fmt.Errorf("writing %s: %w", path, err)
+	}
+	return driveFile{path: path, sum: h.Sum(nil)}, nil
+}
+
+// readBack reads every file written to the drive again, from the drive
+// rather than from memory, and checks it against what was written.
+func readBack(files []driveFile) error {
+	for _, file := range files {
+		f, err := os.Open(file.path)
+		if err != nil {
+			return fmt.Errorf("reading %s back: %w", file.path, err)
+		}
+		dropCache(f)
+		h := sha256.New()
+		_, err = io.Copy(h, f)
+		f.Close()
+		if err != nil {
+			return fmt.Errorf("reading %s back: %w", file.path, err)
+		}
+		if !bytes.Equal(h.Sum(nil), file.sum) {
+			return fmt.Errorf("%s reads back differently from what was written (sha256:%s)", file.path, hex.EncodeToString(h.Sum(nil)))
+		}
+	}
+	return nil
+}
+
+// recordDrive records the read-back of the drive mounted at mount in the
+// friend's media, as a medium of type kind written just now, for 'rememory
+// status'. A drive the system can't tell apart is left for 'rememory
+// verify-media --serial'.
+func recordDrive(p *project.Project, i int, bundlePath, mount, kind string, readErr error) {
+	serial, label, err := mediumID(mount)
+	if err != nil {
+		fmt.Printf("  %s Can't tell which drive this is (%v), so it isn't recorded; run 'rememory verify-media %s --serial NAME' to record it.\n", yellow("Note:"), err, mount)
+		return
+	}
+	now := time.Now().UTC()
+	m := project.Medium{Serial: serial, Label: label, Type: kind, Written: now, Verified: now}
+	if info, err := bundle.ReadInfo(bundlePath); err == nil {
+		m.Manifest = info.Metadata["checksum-manifest"]
+	}
+	if readErr != nil {
+		m.Failed = readErr.Error()
+	}
+	p.Friends[i].RecordMedium(m)
+	if err := p.Save(); err != nil {
+		fmt.Fprintf(os.Stderr, "%s %v\n", yellow("Warning:"), err)
+	}
+}
+
+// runDriveTool runs one of the system's disk tools, with what it printed to
+// stderr in the error.
+func runDriveTool(stdin string, name string, args ...string) (string, error) {
+	if _, err := exec.LookPath(name); err != nil {
+		return "", fmt.Errorf("%s not found; install it, or format the drive yourself and give the folder it's mounted at", name)
+	}
+	c := exec.Command(name, args...)
+	if stdin != "" {
+		c.Stdin = strings.NewReader(stdin)
+	}
+	var stdout, stderr bytes.Buffer
+	c.Stdout = &stdout
+	c.Stderr = &stderr
+	if err := c.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%s: %s", name, msg)
+		}
+		return "", fmt.Errorf("%s: %w", name, err)
+	}
+	return stdout.String(), nil
+}
@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+)
+
+// diskInfo reads the "Name: value" lines of 'diskutil info'.
+func diskInfo(device string) (map[string]string, error) {
+	out, err := runDriveTool("", "diskutil", "info", device)
+	if err != nil {
+		return nil, err
+	}
+	info := map[string]string{}
+	for _, line := range strings.Split(out, "\n") {
+		if name, value, ok := strings.Cut(line, ":"); ok {
+			info[strings.TrimSpace(name)] = strings.TrimSpace(value)
+		}
+	}
+	return info, nil
+}
+
+// inspectDrive asks diskutil about device.
+func inspectDrive(device string) (*usbDrive, error) {
+	info, err := diskInfo(device)
+	if err != nil {
+		return nil, err
+	}
+	drive := &usbDrive{
+		Device:    device,
+		Partition: info["Whole"] == "No",
+		Removable: info["Removable Media"] == "Removable" || info["Device Location"] == "External",
+	}
+	if info["Protocol"] == "Disk Image" {
+		drive.Removable = false
+	}
+	size, _, _ := strings.Cut(info["Disk Size"], " (")
+	drive.Description = strings.TrimSpace(info["Device / Media Name"] + " " + size)
+	return drive, nil
+}
+
+// formatDrive erases the drive, or just the partition, with diskutil,
+// which mounts it again under /Volumes, and returns where.
+func formatDrive(drive *usbDrive, label, filesystem string) (string, error) {
+	format := "FAT32"
+	if filesystem == "exfat" {
+		format = "ExFAT"
+	}
+	volume := drive.Device
+	if drive.Partition {
+		if _, err := runDriveTool("", "diskutil", "eraseVolume", format, label, drive.Device); err != nil {
+			return "", err
+		}
+	} else {
+		if _, err := runDriveTool("", "diskutil", "eraseDisk", format, label, "MBRFormat", drive.Device); err != nil {
+			return "", err
+		}
+		volume = drive.Device + "s1"
+	}
+	info, err := diskInfo(volume)
+	if err != nil {
+		return "", err
+	}
+	mount := info["Mount Point"]
+	if mount == "" {
+		return "", fmt.Errorf("%s isn't mounted after formatting", volume)
+	}
+	return mount, nil
+}
@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"unicode"
+)
+
+// inspectDrive asks lsblk about device, and about the whole drive it is on
+// when it's a partition.
+func inspectDrive(device string) (*usbDrive, error) {
+	out, err := runDriveTool("", "lsblk", "-dnpo", "TYPE,PKNAME", device)
+	if err != nil {
+		return nil, err
+	}
+	fields := strings.Fields(out)
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("lsblk doesn't know %s", device)
+	}
+	drive := &usbDrive{Device: device, Partition: fields[0] == "part"}
+	disk := device
+	if drive.Partition {
+		if len(fields) < 2 {
+			return nil, fmt.Errorf("lsblk doesn't say which drive %s is on", device)
+		}
+		disk = fields[1]
+	} else if fields[0] != "disk" {
+		return nil, fmt.Errorf("%s is a %s, not a drive or partition", device, fields[0])
+	}
+
+	// RM and HOTPLUG are 0 or 1; MODEL may have spaces, so it comes last
+	out, err = runDriveTool("", "lsblk", "-dnpo", "RM,HOTPLUG,SIZE,MODEL", disk)
+	if err != nil {
+		return nil, err
+	}
+	fields = strings.Fields(out)
+	if len(fields) < 3 {
+		return nil, fmt.Errorf("lsblk doesn't know %s", disk)
+	}
+	drive.Removable = fields[0] == "1" || fields[1] == "1"
+	drive.Description = strings.Join(append(fields[3:], fields[2]), " ")
+	return drive, nil
+}
+
+// formatDrive unmounts the drive, gives a whole drive one partition,
+// formats it, and mounts it through udisks, returning where.
+func formatDrive(drive *usbDrive, label, filesystem string) (string, error) {
+	out, err := runDriveTool("", "lsblk", "-lnpo", "NAME,MOUNTPOINT", drive.Device)
+	if err != nil {
+		return "", err
+	}
+	for _, line := range strings.Split(out, "\n") {
+		if fields := strings.Fields(line); len(fields) > 1 {
+			if _, err := runDriveTool("", "umount", fields[0]); err != nil {
+				return "", err
+			}
+		}
+	}
+
+	partition := drive.Device
+	if !drive.Partition {
+		partitionType := "c" // FAT32 (LBA)
+		if filesystem == "exfat" {
+			partitionType = "7"
+		}
+		if _, err := runDriveTool("type="+partitionType+"\n", "sfdisk", "--label", "dos", drive.Device); err != nil {
+			return "", err
+		}
+		partition = firstPartition(drive.Device)
+		// Let udev catch up with the new partition table
+		runDriveTool("", "udevadm", "settle")
+	}
+
+	if filesystem == "exfat" {
+		_, err = runDriveTool("", "mkfs.exfat", "-L", label, partition)
+	} else {
+		_, err = runDriveTool("", "mkfs.vfat", "-F", "32", "-n", label, partition)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	// "Mounted /dev/sdb1 at /media/me/REMEMORY"
+	out, err = runDriveTool("", "udisksctl", "mount", "-b", partition)
+	if err != nil {
+		return "", err
+	}
+	_, mount, ok := strings.Cut(strings.TrimSpace(out), " at ")
+	if !ok {
+		return "", fmt.Errorf("udisksctl didn't say where it mounted %s: %s", partition, strings.TrimSpace(out))
+	}
+	return strings.TrimSuffix(mount, "."), nil
+}
+
+// firstPartition names a drive's first partition: /dev/sdb1, but
+// /dev/mmcblk0p1 and /dev/nvme0n1p1 when the drive's name ends in a digit.
+func firstPartition(disk string) string {
+	base := filepath.Base(disk)
+	if base != "" && unicode.IsDigit(rune(base[len(base)-1])) {
+		return disk + "p1"
+	}
+	return disk + "1"
+}
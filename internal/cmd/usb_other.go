@@ -0,0 +1,13 @@
+//go:build !linux && !darwin && !windows
+
+package cmd
+
+import "fmt"
+
+func inspectDrive(device string) (*usbDrive, error) {
+	return nil, fmt.Errorf("formatting drives isn't supported on this system; format it yourself and give the folder it's mounted at")
+}
+
+func formatDrive(drive *usbDrive, label, filesystem string) (string, error) {
+	return "", fmt.Errorf("formatting drives isn't supported on this system")
+}
@@ -0,0 +1,17 @@
+//go:build !windows
+
+package cmd
+
+import "os"
+
+// isDriveDevice reports whether target is a device node, not a folder.
+func isDriveDevice(target string) bool {
+	info, err := os.Stat(target)
+	return err == nil && info.Mode()&os.ModeDevice != 0
+}
+
+// driveFolder returns the folder to copy into for a target that isn't
+// formatted first.
+func driveFolder(target string) string {
+	return target
+}
@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"syscall"
+	"unsafe"
+)
+
+var procGetDriveType = syscall.NewLazyDLL("kernel32.dll").NewProc("GetDriveTypeW")
+
+const driveRemovable = 2 // DRIVE_REMOVABLE
+
+// driveLetter is a bare drive, such as E: or E:\.
+var driveLetter = regexp.MustCompile(`^[A-Za-z]:\\?$`)
+
+// isDriveDevice reports whether target names a whole drive by its letter.
+func isDriveDevice(target string) bool {
+	return driveLetter.MatchString(target)
+}
+
+// driveFolder returns the folder to copy into: the top of the drive for a
+// bare letter, which on its own means the drive's current folder.
+func driveFolder(target string) string {
+	if driveLetter.MatchString(target) {
+		return target[:2] + `\`
+	}
+	return target
+}
+
+// inspectDrive asks Windows what kind of drive the letter is.
+func inspectDrive(device string) (*usbDrive, error) {
+	root := strings.ToUpper(device[:1]) + `:\`
+	drive := &usbDrive{Device: root[:2], Partition: true, Description: "system drive"}
+	if strings.EqualFold(root[:2], os.Getenv("SystemDrive")) {
+		return drive, nil
+	}
+	rootPtr, err := syscall.UTF16PtrFromString(root)
+	if err != nil {
+		return nil, err
+	}
+	kind, _, _ := procGetDriveType.Call(uintptr(unsafe.Pointer(rootPtr)))
+	drive.Removable = kind == driveRemovable
+	if !drive.Removable {
+		drive.Description = "fixed drive"
+		return drive, nil
+	}
+	drive.Description = "removable drive"
+	var total uint64
+	if ok, _, _ := procGetDiskFreeSpaceEx.Call(uintptr(unsafe.Pointer(rootPtr)), 0, uintptr(unsafe.Pointer(&total)), 0); ok != 0 {
+		drive.Description = fmt.Sprintf("removable drive, %s", formatSize(int64(total)))
+	}
+	return drive, nil
+}
+
+// formatDrive formats the volume with PowerShell's Format-Volume.
+func formatDrive(drive *usbDrive, label, filesystem string) (string, error) {
+	format := "FAT32"
+	if filesystem == "exfat" {
+		format = "exFAT"
+	}
+	script := fmt.Sprintf("Format-Volume -DriveLetter %s -FileSystem %s -NewFileSystemLabel '%s' -Force -Confirm:$false | Out-Null",
+		drive.Device[:1], format, label)
+	if _, err := runDriveTool("", "powershell", "-NoProfile", "-NonInteractive", "-Command", script); err != nil {
+		return "", err
+	}
+	return drive.Device + `\`, nil
+}
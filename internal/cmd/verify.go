@@ -5,6 +5,7 @@ import (
 	"os"
 	"path/filepath"
 
+	"github.com/eljojo/rememory/internal/core"
 	"github.com/eljojo/rememory/internal/crypto"
 	"github.com/eljojo/rememory/internal/project"
 	"github.com/spf13/cobra"
@@ -18,6 +19,7 @@ the checksums stored in project.yml.
 
 Run this command inside a project directory to verify:
   - MANIFEST.age exists and matches its checksum
+  - TIMELOCK.json, when the project has a recovery delay, does too
   - All share files exist and match their checksums
 
 This helps detect if files have been corrupted or modified.`,
@@ -49,63 +51,110 @@ func runVerify(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("project has not been sealed yet; run 'rememory seal' first")
 	}
 
+	var checks []fileCheck
 	allOK := true
-
-	// Verify manifest file
-	manifestPath := p.ManifestAgePath()
-	fmt.Printf("Checking %s... ", filepath.Base(manifestPath))
-
-	if _, err := os.Stat(manifestPath); os.IsNotExist(err) {
-		fmt.Println("MISSING")
-		allOK = false
-	} else {
-		checksum, err := crypto.HashFile(manifestPath)
-		if err != nil {
-			fmt.Printf("ERROR: %v\n", err)
-			allOK = false
-		} else if checksum != p.Sealed.ManifestChecksum {
-			fmt.Println("CHECKSUM MISMATCH")
-			fmt.Printf("  Expected: %s\n", p.Sealed.ManifestChecksum)
-			fmt.Printf("  Got:      %s\n", checksum)
-			allOK = false
-		} else {
+	record := func(path, expected string) {
+		fmt.Printf("Checking %s... ", filepath.Base(path))
+		c := checkFile(path, expected)
+		switch c.Status {
+		case "ok":
 			fmt.Println("OK")
+		case "missing":
+			fmt.Println("MISSING")
+		case "mismatch":
+			fmt.Println("CHECKSUM MISMATCH")
+			fmt.Printf("  Expected: %s\n", c.Expected)
+			fmt.Printf("  Got:      %s\n", c.Actual)
+		default:
+			fmt.Printf("ERROR: %s\n", c.Error)
 		}
+		allOK = allOK && c.Status == "ok"
+		checks = append(checks, c)
 	}
 
-	// Verify share files
-	for _, shareInfo := range p.Sealed.Shares {
-		sharePath := filepath.Join(p.Path, shareInfo.File)
-		fmt.Printf("Checking %s... ", filepath.Base(sharePath))
-
-		if _, err := os.Stat(sharePath); os.IsNotExist(err) {
-			fmt.Println("MISSING")
+	record(p.ManifestAgePath(), p.Sealed.ManifestChecksum)
+	volumes, err := p.ManifestVolumes()
+	if err != nil {
+		return err
+	}
+	if volumes != nil {
+		if volumes.Checksum != p.Sealed.ManifestChecksum {
+			fmt.Printf("Checking %s... FROM ANOTHER SEAL (run 'rememory bundle' to split MANIFEST.age again)\n", filepath.Base(p.ManifestVolumeIndexPath()))
 			allOK = false
-			continue
 		}
-
-		checksum, err := crypto.HashFile(sharePath)
-		if err != nil {
-			fmt.Printf("ERROR: %v\n", err)
-			allOK = false
-			continue
+		for _, v := range volumes.Volumes {
+			record(filepath.Join(p.OutputPath(), filepath.Base(v.Name)), v.Checksum)
 		}
-
-		if checksum != shareInfo.Checksum {
-			fmt.Println("CHECKSUM MISMATCH")
-			fmt.Printf("  Expected: %s\n", shareInfo.Checksum)
-			fmt.Printf("  Got:      %s\n", checksum)
-			allOK = false
-		} else {
+	}
+	if parity, err := os.ReadFile(p.ManifestParityPath()); err == nil {
+		fmt.Printf("Checking %s... ", filepath.Base(p.ManifestParityPath()))
+		h, damaged, err := core.CheckParity(parity)
+		switch {
+		case err != nil:
+			fmt.Printf("ERROR: %v\n", err)
+		case h.Checksum != p.Sealed.ManifestChecksum:
+			fmt.Println("FROM ANOTHER SEAL (run 'rememory bundle' to write it again)")
+		case damaged > 0:
+			fmt.Printf("DAMAGED (%d blocks; delete it and run 'rememory bundle' to write it again)\n", damaged)
+		default:
 			fmt.Println("OK")
 		}
+		allOK = allOK && err == nil && h.Checksum == p.Sealed.ManifestChecksum && damaged == 0
+	}
+	if p.Sealed.TimelockChecksum != "" {
+		record(p.TimelockPath(), p.Sealed.TimelockChecksum)
+	}
+	for _, shareInfo := range p.Sealed.Shares {
+		record(filepath.Join(p.Path, shareInfo.File), shareInfo.Checksum)
 	}
 
 	fmt.Println()
 	if allOK {
 		fmt.Println("All files verified.")
-		return nil
 	}
 
-	return fmt.Errorf("verification failed")
+	if jsonOutput {
+		if err := printJSON(verifyResult{OK: allOK, Files: checks}); err != nil {
+			return err
+		}
+	}
+	if !allOK {
+		return fmt.Errorf("verification failed")
+	}
+	return nil
+}
+
+type verifyResult struct {
+	OK    bool        `json:"ok"`
+	Files []fileCheck `json:"files"`
+}
+
+// fileCheck is the outcome of comparing one sealed file with its recorded checksum.
+type fileCheck struct {
+	Path     string `json:"path"`
+	Status   string `json:"status"` // ok, missing, mismatch, or error
+	Expected string `json:"expected"`
+	Actual   string `json:"actual,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+func checkFile(path, expected string) fileCheck {
+	c := fileCheck{Path: path, Expected: expected}
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		c.Status = "missing"
+		return c
+	}
+	checksum, err := crypto.HashFile(path)
+	if err != nil {
+		c.Status = "error"
+		c.Error = err.Error()
+		return c
+	}
+	c.Actual = checksum
+	if checksum != expected {
+		c.Status = "mismatch"
+	} else {
+		c.Status = "ok"
+	}
+	return c
 }
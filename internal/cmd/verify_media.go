@@ -0,0 +1,341 @@
+package cmd
+
+import (
+	"archive/zip"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/eljojo/rememory/internal/bundle"
+	"github.com/eljojo/rememory/internal/core"
+	"github.com/eljojo/rememory/internal/crypto"
+	"github.com/eljojo/rememory/internal/project"
+	"github.com/spf13/cobra"
+)
+
+var verifyMediaCmd = &cobra.Command{
+	Use:   "verify-media <folder>",
+	Short: "Read back a bundle copied to a USB drive, SD card, or disc",
+	Long: `Verify-media reads back every file on a USB drive, SD card, or disc
+that holds a friend's bundle, and checks it: the bundle ZIP against the one
+in output/bundles/, or on its own when it's from another 'rememory bundle',
+and the bundle's files next to it against the ZIP. Files are read from the
+drive itself, not from what the system kept in memory while copying them.
+
+The drive's serial number, or its volume's ID when it has none, is recorded
+in project.yml with the date and what the read-back found, so 'rememory
+status' can show which copies were verified, and which hold an earlier seal.
+Give --serial when the system can't tell, or to name the copy yourself.
+
+Give --type, usb, sd, ssd, hdd, disc, or mdisc, and --written, the date the
+bundle was copied onto it, so 'rememory status' and 'rememory calendar' can
+tell when it's due to be written again: flash keeps a copy for a few years
+without power, a disc for longer. Without --written, a medium seen for the
+first time is taken as written when its files were last changed.
+
+Give the folder the drive is mounted at. The bundle ZIPs on it say whose
+copy it is; a copy of only the bundle's files needs --friend.
+
+Examples:
+  rememory verify-media /media/me/REMEMORY
+  rememory verify-media /Volumes/BOB --friend Bob
+  rememory verify-media E: --serial "Alice's SD card" --type sd
+  rememory verify-media /media/me/DVD --type disc --written 2024-03-01`,
+	Args: cobra.ExactArgs(1),
+	RunE: runVerifyMedia,
+}
+
+func init() {
+	verifyMediaCmd.Flags().String("friend", "", "Friend whose bundle is on the drive, when there's no bundle ZIP on it")
+	verifyMediaCmd.Flags().String("serial", "", "Name the drive by this instead of its serial number")
+	verifyMediaCmd.Flags().String("type", "", "Type of medium: "+strings.Join(project.MediaTypes, ", "))
+	verifyMediaCmd.Flags().String("written", "", "Date the bundle was copied onto it, as YYYY-MM-DD")
+	rootCmd.AddCommand(verifyMediaCmd)
+}
+
+// mediumCopy is a friend's bundle on a medium: the bundle ZIP, when it's
+// there, and the bundle's files next to it.
+type mediumCopy struct {
+	friend int
+	zip    string // Name of the bundle ZIP on the medium, or empty
+}
+
+// mediumFile is what reading one file back found.
+type mediumFile struct {
+	name    string
+	problem string // Empty when the file read back as it should
+	note    string
+}
+
+func runVerifyMedia(cmd *cobra.Command, args []string) error {
+	name, _ := cmd.Flags().GetString("friend")
+	serial, _ := cmd.Flags().GetString("serial")
+	kind, _ := cmd.Flags().GetString("type")
+	writtenFlag, _ := cmd.Flags().GetString("written")
+	folder := driveFolder(args[0])
+	if info, err := os.Stat(folder); err != nil || !info.IsDir() {
+		return fmt.Errorf("%s is not a folder; give the folder the drive is mounted at", args[0])
+	}
+	if kind = strings.ToLower(strings.TrimSpace(kind)); kind != "" {
+		if err := project.CheckMediaType(kind); err != nil {
+			return fmt.Errorf("--type: %w", err)
+		}
+	}
+	var written time.Time
+	if writtenFlag != "" {
+		t, err := time.Parse(core.DateFormat, strings.TrimSpace(writtenFlag))
+		if err != nil {
+			return fmt.Errorf("--written must be a date like 2024-03-01, not %q", writtenFlag)
+		}
+		if t.After(time.Now()) {
+			return fmt.Errorf("--written is in the future: %s", writtenFlag)
+		}
+		written = t
+	}
+	cmd.SilenceUsage = true // Failures from here on aren't about how it was called
+
+	p, err := loadFriendProject()
+	if err != nil {
+		return err
+	}
+	if p.Sealed == nil {
+		return fmt.Errorf("project must be sealed first (run 'rememory seal')")
+	}
+	copies, err := findMediumCopies(p, folder, name)
+	if err != nil {
+		return err
+	}
+
+	label := ""
+	if serial = strings.TrimSpace(serial); serial == "" {
+		if serial, label, err = mediumID(folder); err != nil {
+			return fmt.Errorf("can't tell which drive %s is (%v); name it with --serial", folder, err)
+		}
+	}
+	if label != "" {
+		fmt.Printf("Medium: %s (%s)\n", serial, label)
+	} else {
+		fmt.Printf("Medium: %s\n", serial)
+	}
+
+	ours := make(map[string]bool)
+	media := make([]project.Medium, len(copies))
+	now := time.Now().UTC()
+	for i, c := range copies {
+		friend := p.Friends[c.friend]
+		fmt.Printf("\n%s's bundle:\n", friend.Name)
+		manifest, files := readBackCopy(folder, c.zip, friendBundlePath(p, friend))
+		media[i] = project.Medium{Serial: serial, Label: label, Type: kind, Written: written, Verified: now, Manifest: manifest}
+		if earlier, ok := friend.Medium(serial); written.IsZero() && (!ok || earlier.Written.IsZero()) {
+			media[i].Written = lastChanged(folder, files)
+		}
+		for _, f := range files {
+			ours[f.name] = true
+			printMediumFile(f)
+			if f.problem != "" && media[i].Failed == "" {
+				media[i].Failed = f.name + ": " + f.problem
+			}
+		}
+		if media[i].Failed == "" && manifest != p.Sealed.ManifestChecksum {
+			fmt.Printf("  %s It's from an earlier seal: copy the current bundle onto it.\n", yellow("Note:"))
+		}
+	}
+
+	// Anything else on the drive is read too, as a sector that can't be read
+	// back is a sign of a failing drive
+	entries, err := os.ReadDir(folder)
+	if err != nil {
+		return fmt.Errorf("reading %s: %w", folder, err)
+	}
+	var others []mediumFile
+	for _, e := range entries {
+		if !e.Type().IsRegular() || ours[e.Name()] || strings.HasPrefix(e.Name(), ".") {
+			continue
+		}
+		f := mediumFile{name: e.Name(), note: "read, not part of a bundle"}
+		if _, err := hashUncached(filepath.Join(folder, e.Name())); err != nil {
+			f.problem = err.Error()
+		}
+		others = append(others, f)
+	}
+	if len(others) > 0 {
+		fmt.Println("\nOther files:")
+	}
+	for _, f := range others {
+		printMediumFile(f)
+		for i := range media {
+			if f.problem != "" && media[i].Failed == "" {
+				media[i].Failed = f.name + ": " + f.problem
+			}
+		}
+	}
+
+	failed := false
+	for i, c := range copies {
+		p.Friends[c.friend].RecordMedium(media[i])
+		failed = failed || media[i].Failed != ""
+		result := "verified"
+		if media[i].Failed != "" {
+			result = "FAILED: " + media[i].Failed
+		}
+		logEvent(p, "verify-media", "%s's copy on %s %s", p.Friends[c.friend].Name, serial, result)
+	}
+	if err := p.Save(); err != nil {
+		return err
+	}
+
+	fmt.Println()
+	if failed {
+		return fmt.Errorf("the drive didn't read back as it should; it may be failing, so copy the bundle to another one")
+	}
+	fmt.Println("Everything read back as it should. Recorded in project.yml.")
+	return nil
+}
+
+// findMediumCopies finds whose bundles are in folder by their ZIPs, or
+// takes the friend named, whose ZIP needn't be there.
+func findMediumCopies(p *project.Project, folder, name string) ([]mediumCopy, error) {
+	var copies []mediumCopy
+	for i, f := range p.Friends {
+		if name != "" && !strings.EqualFold(f.Name, strings.TrimSpace(name)) {
+			continue
+		}
+		c := mediumCopy{friend: i}
+		zipName := filepath.Base(friendBundlePath(p, f))
+		if _, err := os.Stat(filepath.Join(folder, zipName)); err == nil {
+			c.zip = zipName
+		} else if name == "" {
+			continue
+		}
+		copies = append(copies, c)
+	}
+	if len(copies) == 0 {
+		if name != "" {
+			return nil, fmt.Errorf("no friend named %q in the project", name)
+		}
+		return nil, fmt.Errorf("no bundle ZIP of this project in %s; give --friend to check a copy of only the bundle's files", folder)
+	}
+	return copies, nil
+}
+
+// readBackCopy reads back a friend's copy in folder and returns the
+// checksum of the MANIFEST.age sealed into it, with what each file found.
+// The bundle ZIP on the medium is checked against reference, the one in
+// output/bundles/, or on its own when it's another; the files next to it
+// are checked against the ZIP on the medium when it's sound.
+func readBackCopy(folder, zipName, reference string) (string, []mediumFile) {
+	var files []mediumFile
+	source := reference
+	if zipName != "" {
+		f := mediumFile{name: zipName}
+		path := filepath.Join(folder, zipName)
+		sum, err := hashUncached(path)
+		if err != nil {
+			f.problem = err.Error()
+		} else if want, err := crypto.HashFile(reference); err != nil || sum != want {
+			if err := bundle.VerifyBundle(path); err != nil {
+				f.problem = err.Error()
+			} else {
+				f.note = "intact, but not the bundle in output/bundles/"
+			}
+		}
+		files = append(files, f)
+		if f.problem == "" {
+			source = path
+		}
+	}
+
+	info, err := bundle.ReadInfo(source)
+	if err != nil {
+		return "", append(files, mediumFile{name: filepath.Base(source), problem: err.Error()})
+	}
+	r, err := zip.OpenReader(source)
+	if err != nil {
+		return "", append(files, mediumFile{name: filepath.Base(source), problem: err.Error()})
+	}
+	defer r.Close()
+	for _, entry := range r.File {
+		if entry.FileInfo().IsDir() || strings.ContainsAny(entry.Name, `/\:`) {
+			continue
+		}
+		path := filepath.Join(folder, entry.Name)
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			continue
+		}
+		f := mediumFile{name: entry.Name}
+		want, err := hashZipEntry(entry)
+		if err != nil {
+			f.problem = err.Error()
+		} else if got, err := hashUncached(path); err != nil {
+			f.problem = err.Error()
+		} else if got != want {
+			f.problem = "reads back differently from the bundle (" + truncateHash(got) + ")"
+		}
+		files = append(files, f)
+	}
+	if len(files) == 0 {
+		files = append(files, mediumFile{name: filepath.Base(reference), problem: "none of the bundle's files are on the drive"})
+	}
+	return info.Metadata["checksum-manifest"], files
+}
+
+// lastChanged returns when the files of a copy in folder were last changed,
+// as the best guess at when the copy was written, or the zero time when
+// none can tell.
+func lastChanged(folder string, files []mediumFile) time.Time {
+	var last time.Time
+	for _, f := range files {
+		if info, err := os.Stat(filepath.Join(folder, f.name)); err == nil && info.ModTime().After(last) {
+			last = info.ModTime()
+		}
+	}
+	if last.After(time.Now()) {
+		return time.Time{}
+	}
+	return last.UTC()
+}
+
+func printMediumFile(f mediumFile) {
+	switch {
+	case f.problem != "":
+		fmt.Printf("  %s %s: %s\n", red("✗"), f.name, f.problem)
+	case f.note != "":
+		fmt.Printf("  %s %s (%s)\n", green("✓"), f.name, f.note)
+	default:
+		fmt.Printf("  %s %s\n", green("✓"), f.name)
+	}
+}
+
+// hashUncached reads path from the drive, not from memory, and returns its
+// checksum.
+func hashUncached(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("reading back: %w", err)
+	}
+	defer f.Close()
+	dropCache(f)
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", fmt.Errorf("reading back: %w", err)
+	}
+	return "sha256:" + hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func hashZipEntry(f *zip.File) (string, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return "", fmt.Errorf("opening %s: %w", f.Name, err)
+	}
+	defer rc.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, rc); err != nil {
+		return "", fmt.Errorf("reading %s: %w", f.Name, err)
+	}
+	return "sha256:" + hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,176 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/eljojo/rememory/internal/core"
+	"github.com/spf13/cobra"
+)
+
+var verifyPrintsCmd = &cobra.Command{
+	Use:   "verify-prints <scans-dir>",
+	Short: "Check scans or photos of printed READMEs against the sealed pieces",
+	Long: `Verify-prints reads every image in a folder of scans or photos of the
+printed README pages, decodes the QR code on each one, and matches it to the
+piece it should hold. Pages whose QR code can't be read, or that hold a
+piece from another seal, are reported so they can be reprinted.
+
+Run it inside a sealed project. QR codes are decoded with ZBar's zbarimg,
+which has to be installed (brew install zbar, apt install zbar-tools).
+
+Example:
+  rememory print
+  # scan or photograph each page into scans/
+  rememory verify-prints scans/`,
+	Args: cobra.ExactArgs(1),
+	RunE: runVerifyPrints,
+}
+
+func init() {
+	rootCmd.AddCommand(verifyPrintsCmd)
+}
+
+// scanExtensions are the image types zbarimg reads.
+var scanExtensions = map[string]bool{
+	".png": true, ".jpg": true, ".jpeg": true, ".gif": true,
+	".tif": true, ".tiff": true, ".bmp": true, ".webp": true,
+}
+
+func runVerifyPrints(cmd *cobra.Command, args []string) error {
+	p, err := loadFriendProject()
+	if err != nil {
+		return err
+	}
+	if p.Sealed == nil {
+		return fmt.Errorf("project must be sealed first (run 'rememory seal')")
+	}
+	shares, err := loadSealedShares(p)
+	if err != nil {
+		return err
+	}
+
+	scans, err := findScans(args[0])
+	if err != nil {
+		return err
+	}
+	if len(scans) == 0 {
+		return fmt.Errorf("no images found in %s", args[0])
+	}
+	if _, err := exec.LookPath("zbarimg"); err != nil {
+		return fmt.Errorf("zbarimg not found; install ZBar to decode QR codes (brew install zbar, apt install zbar-tools)")
+	}
+
+	fmt.Printf("Checking %d scan%s against %d pieces...\n\n", len(scans), plural(len(scans)), len(shares))
+
+	printed := make(map[int]bool)
+	problems := 0
+	for _, scan := range scans {
+		payloads, err := decodeQR(scan)
+		if err != nil {
+			fmt.Printf("%s %s: %v\n", red("✗"), scan, err)
+			problems++
+			continue
+		}
+		for _, payload := range payloads {
+			i, err := matchPrint(payload, shares)
+			if err != nil {
+				fmt.Printf("%s %s: %v\n", red("✗"), scan, err)
+				problems++
+				continue
+			}
+			fmt.Printf("%s %s: piece %d of %d (%s)\n", green("✓"), scan, shares[i].Index, shares[i].Total, p.Friends[i].Name)
+			printed[i] = true
+		}
+	}
+
+	fmt.Println()
+	for i, f := range p.Friends {
+		if !printed[i] {
+			fmt.Printf("%s %s (piece %d): no readable print\n", yellow("!"), f.Name, shares[i].Index)
+		}
+	}
+	fmt.Printf("Pieces with a readable print: %d of %d\n", len(printed), len(shares))
+
+	if problems > 0 {
+		return fmt.Errorf("%d page%s didn't scan cleanly; reprint them with 'rememory print <friend>'", problems, plural(problems))
+	}
+	return nil
+}
+
+// findScans lists the images in dir and its subfolders, in name order.
+func findScans(dir string) ([]string, error) {
+	var scans []string
+	err := filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() && scanExtensions[strings.ToLower(filepath.Ext(path))] {
+			scans = append(scans, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", dir, err)
+	}
+	sort.Strings(scans)
+	return scans, nil
+}
+
+// decodeQR returns the contents of every QR code zbarimg finds in the image.
+func decodeQR(path string) ([]string, error) {
+	cmd := exec.Command("zbarimg", "--quiet", "--raw", "-Sdisable", "-Sqrcode.enable", path)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+
+	// zbarimg exits with 4 when the image has no barcode in it
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == 4 {
+		return nil, fmt.Errorf("no QR code found (rescan it, or reprint the page)")
+	}
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("reading image: %s", msg)
+		}
+		return nil, fmt.Errorf("reading image: %w", err)
+	}
+
+	var payloads []string
+	for _, line := range strings.Split(stdout.String(), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			payloads = append(payloads, line)
+		}
+	}
+	if len(payloads) == 0 {
+		return nil, fmt.Errorf("no QR code found (rescan it, or reprint the page)")
+	}
+	return payloads, nil
+}
+
+// matchPrint finds which of the sealed shares a decoded QR code holds,
+// returning its position in shares.
+func matchPrint(payload string, shares []*core.Share) (int, error) {
+	printed, err := shareFromQR(payload)
+	if err != nil {
+		return 0, err
+	}
+
+	for i, s := range shares {
+		if s.Index != printed.Index {
+			continue
+		}
+		if !bytes.Equal(s.Data, printed.Data) {
+			return 0, fmt.Errorf("piece %d is from a different seal (an old printout?)", printed.Index)
+		}
+		return i, nil
+	}
+	return 0, fmt.Errorf("piece %d is not one of this project's pieces", printed.Index)
+}
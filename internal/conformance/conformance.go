@@ -0,0 +1,324 @@
+// Package conformance checks that a build of rememory behaves like the
+// official releases: it still opens bundles made by earlier releases, it
+// agrees with the known-answer test vectors, and it seals and recovers a
+// project end to end. 'rememory conformance' runs the checks and prints a
+// signed report, for distribution packagers and anyone who builds from
+// source.
+//
+// The report is signed with an Ed25519 key: the packager's own SSH key when
+// one is given, otherwise a key made for this one report. Either way the
+// signature shows the report wasn't changed after it was made; only a key
+// others already know also shows who made it.
+package conformance
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/eljojo/rememory/internal/core"
+	"github.com/eljojo/rememory/internal/manifest"
+	"github.com/eljojo/rememory/internal/recovery"
+	"golang.org/x/crypto/ssh"
+)
+
+const (
+	reportHeader    = "rememory-conformance-v1"
+	signatureMarker = "SIGNATURE (machine-parseable)"
+)
+
+// Check is the outcome of one step of the run.
+type Check struct {
+	Name   string
+	Detail string // What was checked
+	Err    error  // Why it failed; nil when it passed
+}
+
+// Report is what 'rememory conformance' prints.
+type Report struct {
+	Version     string
+	Platform    string // GOOS/GOARCH and Go version
+	Binary      string // Checksum of the executable that ran the checks; empty if unknown
+	RecoverWASM string // Checksum of the recovery tool built into it
+	Made        time.Time
+	Checks      []Check
+}
+
+// Passed reports whether every check passed.
+func (r *Report) Passed() bool {
+	for _, c := range r.Checks {
+		if c.Err != nil {
+			return false
+		}
+	}
+	return len(r.Checks) > 0
+}
+
+// body is the part of the report the signature covers.
+func (r *Report) body() string {
+	var sb strings.Builder
+	rule := strings.Repeat("=", 80) + "\n"
+	sb.WriteString(rule)
+	sb.WriteString("REMEMORY CONFORMANCE REPORT\n")
+	sb.WriteString(rule)
+	sb.WriteString(reportHeader + "\n\n")
+	field := func(name, value string) {
+		if value != "" {
+			sb.WriteString(fmt.Sprintf("%-15s %s\n", name+":", value))
+		}
+	}
+	field("Version", r.Version)
+	field("Platform", r.Platform)
+	field("Binary", r.Binary)
+	field("Recovery tool", r.RecoverWASM)
+	field("Made", r.Made.UTC().Format("2006-01-02 15:04 UTC"))
+	sb.WriteString("\n")
+
+	passed := 0
+	for _, c := range r.Checks {
+		status := "PASS"
+		if c.Err != nil {
+			status = "FAIL"
+		} else {
+			passed++
+		}
+		sb.WriteString(fmt.Sprintf("  %s  %s\n", status, c.Name))
+		sb.WriteString(fmt.Sprintf("        %s\n", c.Detail))
+		if c.Err != nil {
+			sb.WriteString(fmt.Sprintf("        %s\n", oneLine(c.Err.Error())))
+		}
+	}
+	result := "PASS"
+	if !r.Passed() {
+		result = "FAIL"
+	}
+	sb.WriteString(fmt.Sprintf("\nResult: %s (%d of %d checks passed)\n", result, passed, len(r.Checks)))
+	return sb.String()
+}
+
+// Sign returns the report with a signature footer. With a nil key, the
+// report is signed with a key made for it alone.
+func (r *Report) Sign(key ed25519.PrivateKey) (string, error) {
+	if key == nil {
+		var err error
+		if _, key, err = ed25519.GenerateKey(rand.Reader); err != nil {
+			return "", fmt.Errorf("making a signing key: %w", err)
+		}
+	}
+	pub, err := ssh.NewPublicKey(key.Public())
+	if err != nil {
+		return "", err
+	}
+	body := r.body()
+	var sb strings.Builder
+	rule := strings.Repeat("=", 80) + "\n"
+	sb.WriteString(body)
+	sb.WriteString(rule)
+	sb.WriteString(signatureMarker + "\n")
+	sb.WriteString(rule)
+	sb.WriteString(fmt.Sprintf("key: %s", ssh.MarshalAuthorizedKey(pub)))
+	sb.WriteString(fmt.Sprintf("fingerprint: %s\n", ssh.FingerprintSHA256(pub)))
+	sb.WriteString(fmt.Sprintf("signature: %s\n", base64.StdEncoding.EncodeToString(ed25519.Sign(key, []byte(body)))))
+	sb.WriteString(rule)
+	return sb.String(), nil
+}
+
+// Verify checks the signature of a report written by Sign. It returns the
+// fingerprint of the key that signed it and whether the report says every
+// check passed.
+func Verify(report string) (fingerprint string, passed bool, err error) {
+	report = strings.ReplaceAll(report, "\r\n", "\n")
+	markerAt := strings.Index(report, signatureMarker)
+	if markerAt == -1 || !strings.Contains(report, reportHeader+"\n") {
+		return "", false, fmt.Errorf("not a signed conformance report")
+	}
+	// The signed body ends before the rule above the marker
+	body := report[:markerAt]
+	body = body[:strings.LastIndex(strings.TrimSuffix(body, "\n"), "\n")+1]
+
+	fields := map[string]string{}
+	for _, line := range strings.Split(report[markerAt:], "\n") {
+		if key, value, ok := strings.Cut(line, ": "); ok {
+			fields[key] = strings.TrimSpace(value)
+		}
+	}
+	pub, _, _, _, err := ssh.ParseAuthorizedKey([]byte(fields["key"]))
+	if err != nil {
+		return "", false, fmt.Errorf("invalid key in the report: %w", err)
+	}
+	cryptoPub, ok := pub.(ssh.CryptoPublicKey)
+	if !ok {
+		return "", false, fmt.Errorf("the report's key isn't an Ed25519 key")
+	}
+	edPub, ok := cryptoPub.CryptoPublicKey().(ed25519.PublicKey)
+	if !ok {
+		return "", false, fmt.Errorf("the report's key isn't an Ed25519 key")
+	}
+	sig, err := base64.StdEncoding.DecodeString(fields["signature"])
+	if err != nil || len(sig) != ed25519.SignatureSize {
+		return "", false, fmt.Errorf("invalid signature in the report")
+	}
+	if !ed25519.Verify(edPub, []byte(body), sig) {
+		return "", false, fmt.Errorf("signature does not match — the report was changed or damaged")
+	}
+	return ssh.FingerprintSHA256(pub), strings.Contains(body, "\nResult: PASS "), nil
+}
+
+// LoadKey reads an Ed25519 key in OpenSSH format, such as ~/.ssh/id_ed25519.
+// password is asked for only if the key is locked with one.
+func LoadKey(path string, password func() (string, error)) (ed25519.PrivateKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading signing key: %w", err)
+	}
+	raw, err := ssh.ParseRawPrivateKey(data)
+	if _, locked := err.(*ssh.PassphraseMissingError); locked {
+		pass, perr := password()
+		if perr != nil {
+			return nil, perr
+		}
+		raw, err = ssh.ParseRawPrivateKeyWithPassphrase(data, []byte(pass))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading signing key %s: %w", filepath.Base(path), err)
+	}
+	key, ok := raw.(*ed25519.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("%s isn't an Ed25519 key (make one with 'ssh-keygen -t ed25519')", filepath.Base(path))
+	}
+	return *key, nil
+}
+
+// RecoverFiles combines every set of threshold pieces, checks that each
+// rebuilds the same passphrase, opens the manifest with it, and compares
+// what comes out with want, the manifest folder as it was sealed. It
+// returns how many combinations it tried.
+func RecoverFiles(shares []*core.Share, manifestData []byte, want fs.FS) (int, error) {
+	if len(shares) == 0 {
+		return 0, fmt.Errorf("no pieces")
+	}
+	threshold := shares[0].Threshold
+	var passphrase core.Secret
+	defer func() { passphrase.Wipe() }()
+	combos := combinations(len(shares), threshold)
+	for _, combo := range combos {
+		subset := make([]*core.Share, len(combo))
+		for i, j := range combo {
+			subset[i] = shares[j]
+		}
+		got, err := recovery.Combine(subset)
+		if err != nil {
+			return 0, fmt.Errorf("pieces %v: %w", indices(subset), err)
+		}
+		switch {
+		case passphrase == nil:
+			passphrase = got
+		case !bytes.Equal(got, passphrase):
+			got.Wipe()
+			return 0, fmt.Errorf("pieces %v rebuild a different passphrase", indices(subset))
+		default:
+			got.Wipe()
+		}
+	}
+	// Fewer pieces than the threshold must not rebuild it
+	if threshold > 1 {
+		got, err := recovery.Combine(shares[:threshold-1])
+		rebuilt := err == nil && bytes.Equal(got, passphrase)
+		got.Wipe()
+		if rebuilt {
+			return 0, fmt.Errorf("%d pieces rebuilt the passphrase; %d should be needed", threshold-1, threshold)
+		}
+	}
+
+	var archive bytes.Buffer
+	if err := core.Decrypt(&archive, bytes.NewReader(manifestData), string(passphrase)); err != nil {
+		return 0, fmt.Errorf("decrypting MANIFEST.age: %w", err)
+	}
+	dir, err := os.MkdirTemp("", "rememory-conformance-")
+	if err != nil {
+		return 0, err
+	}
+	defer os.RemoveAll(dir)
+	result, err := manifest.Extract(&archive, dir, true)
+	if err != nil {
+		return 0, fmt.Errorf("extracting: %w", err)
+	}
+	if err := compareTree(os.DirFS(result.Path), want); err != nil {
+		return 0, err
+	}
+	return len(combos), nil
+}
+
+// compareTree checks that got holds the same files as want, byte for byte.
+func compareTree(got, want fs.FS) error {
+	files := func(fsys fs.FS) (map[string][]byte, error) {
+		out := map[string][]byte{}
+		err := fs.WalkDir(fsys, ".", func(path string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() {
+				return err
+			}
+			out[path], err = fs.ReadFile(fsys, path)
+			return err
+		})
+		return out, err
+	}
+	gotFiles, err := files(got)
+	if err != nil {
+		return err
+	}
+	wantFiles, err := files(want)
+	if err != nil {
+		return err
+	}
+	for path, data := range wantFiles {
+		g, ok := gotFiles[path]
+		switch {
+		case !ok:
+			return fmt.Errorf("%s is missing from the recovered files", path)
+		case !bytes.Equal(g, data):
+			return fmt.Errorf("%s was recovered with different contents", path)
+		}
+	}
+	for path := range gotFiles {
+		if _, ok := wantFiles[path]; !ok {
+			return fmt.Errorf("%s was recovered but never sealed", path)
+		}
+	}
+	return nil
+}
+
+// combinations lists every k-element subset of 0..n-1.
+func combinations(n, k int) [][]int {
+	var out [][]int
+	var pick func(start int, combo []int)
+	pick = func(start int, combo []int) {
+		if len(combo) == k {
+			out = append(out, append([]int(nil), combo...))
+			return
+		}
+		for i := start; i < n; i++ {
+			pick(i+1, append(combo, i))
+		}
+	}
+	pick(0, nil)
+	return out
+}
+
+func indices(shares []*core.Share) []int {
+	out := make([]int, len(shares))
+	for i, s := range shares {
+		out[i] = s.Index
+	}
+	return out
+}
+
+func oneLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
@@ -0,0 +1,106 @@
+package conformance
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func TestFixturesOpen(t *testing.T) {
+	checks := CheckFixtures()
+	if len(checks) != 2 {
+		t.Fatalf("got %d fixture checks, want 2", len(checks))
+	}
+	for _, c := range checks {
+		if c.Err != nil {
+			t.Errorf("%s: %v", c.Name, c.Err)
+		}
+	}
+}
+
+// The fixtures are copies of the golden bundles in internal/core/testdata,
+// which can't be embedded from here.
+func TestFixturesMatchCoreTestdata(t *testing.T) {
+	for _, version := range []string{"v1", "v2"} {
+		want := os.DirFS(filepath.Join("..", "core", "testdata", version+"-bundle"))
+		got, err := fs.Sub(fixtures, "fixtures/"+version)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := compareTree(got, want); err != nil {
+			t.Errorf("%s: %v", version, err)
+		}
+	}
+}
+
+func TestCompareTree(t *testing.T) {
+	want := fstest.MapFS{"a.txt": {Data: []byte("a")}, "dir/b.txt": {Data: []byte("b")}}
+	if err := compareTree(want, want); err != nil {
+		t.Error(err)
+	}
+	changed := fstest.MapFS{"a.txt": {Data: []byte("a")}, "dir/b.txt": {Data: []byte("c")}}
+	extra := fstest.MapFS{"a.txt": {Data: []byte("a")}, "dir/b.txt": {Data: []byte("b")}, "c.txt": {}}
+	for name, got := range map[string]fs.FS{"changed": changed, "extra": extra, "missing": fstest.MapFS{"a.txt": {Data: []byte("a")}}} {
+		if err := compareTree(got, want); err == nil {
+			t.Errorf("%s file went unnoticed", name)
+		}
+	}
+}
+
+func testReport() *Report {
+	return &Report{
+		Version:  "v1.2.3",
+		Platform: "linux/amd64 (go1.25)",
+		Made:     time.Date(2026, 1, 2, 3, 4, 0, 0, time.UTC),
+		Checks: []Check{
+			{Name: "opens v1 bundles from earlier releases", Detail: "5 pieces"},
+			{Name: "agrees with the test vectors", Detail: "3 cases"},
+		},
+	}
+}
+
+func TestSignedReport(t *testing.T) {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	text, err := testReport().Sign(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"REMEMORY CONFORMANCE REPORT", "  PASS  opens v1 bundles", "Result: PASS (2 of 2 checks passed)", "key: ssh-ed25519 "} {
+		if !strings.Contains(text, want) {
+			t.Errorf("report is missing %q", want)
+		}
+	}
+
+	fingerprint, passed, err := Verify(text)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !passed || !strings.HasPrefix(fingerprint, "SHA256:") {
+		t.Errorf("passed = %v, fingerprint = %q", passed, fingerprint)
+	}
+
+	// A failed check can't be edited into a pass
+	failed := testReport()
+	failed.Checks[1].Err = errors.New("share 2 didn't match")
+	text, err = failed.Sign(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, passed, err := Verify(text); err != nil || passed {
+		t.Errorf("failed report: passed = %v, err = %v", passed, err)
+	}
+	edited := strings.Replace(strings.Replace(text, "  FAIL  agrees", "  PASS  agrees", 1), "Result: FAIL (1 of 2", "Result: PASS (2 of 2", 1)
+	if _, _, err := Verify(edited); err == nil {
+		t.Error("edited report verified")
+	}
+}
@@ -0,0 +1,107 @@
+package conformance
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+
+	"github.com/eljojo/rememory/internal/core"
+	"github.com/eljojo/rememory/internal/recovery"
+	"github.com/eljojo/rememory/internal/testvectors"
+)
+
+// Pieces and manifests sealed by earlier releases, one folder per share
+// format version, with the files they hold under expected-output/. They are
+// copies of internal/core/testdata, which the tests keep in step.
+//
+//go:embed fixtures
+var fixtures embed.FS
+
+// CheckFixtures opens each bundle sealed by an earlier release with every
+// combination of threshold pieces.
+func CheckFixtures() []Check {
+	entries, err := fs.ReadDir(fixtures, "fixtures")
+	if err != nil {
+		return []Check{{Name: "earlier releases", Detail: "reading the built-in fixtures", Err: err}}
+	}
+	var checks []Check
+	for _, e := range entries {
+		checks = append(checks, checkFixture(e.Name()))
+	}
+	return checks
+}
+
+func checkFixture(name string) Check {
+	dir := path.Join("fixtures", name)
+	c := Check{Name: fmt.Sprintf("opens %s bundles from earlier releases", name)}
+
+	entries, err := fs.ReadDir(fixtures, dir)
+	if err != nil {
+		c.Err = err
+		return c
+	}
+	var shares []*core.Share
+	for _, e := range entries {
+		if !strings.HasPrefix(e.Name(), "SHARE-") {
+			continue
+		}
+		data, err := fs.ReadFile(fixtures, path.Join(dir, e.Name()))
+		if err != nil {
+			c.Err = err
+			return c
+		}
+		share, err := recovery.ParseShareInput(e.Name(), data)
+		if err != nil {
+			c.Err = err
+			return c
+		}
+		shares = append(shares, share)
+	}
+	manifestData, err := fs.ReadFile(fixtures, path.Join(dir, "MANIFEST.age"))
+	if err != nil {
+		c.Err = err
+		return c
+	}
+	want, err := fs.Sub(fixtures, path.Join(dir, "expected-output", "manifest"))
+	if err != nil {
+		c.Err = err
+		return c
+	}
+
+	n, err := RecoverFiles(shares, manifestData, want)
+	if err != nil {
+		c.Err = err
+		c.Detail = fmt.Sprintf("%d pieces", len(shares))
+		return c
+	}
+	c.Detail = fmt.Sprintf("%d pieces, %d combinations of %d, files match", len(shares), n, shares[0].Threshold)
+	return c
+}
+
+// CheckVectors generates the known-answer test vectors, which
+// testvectors.Generate checks as it goes, and makes sure the malformed
+// inputs among them are refused.
+func CheckVectors(generator string) Check {
+	c := Check{Name: "agrees with the test vectors"}
+	v, err := testvectors.Generate(generator)
+	if err != nil {
+		c.Err = err
+		c.Detail = "generating and checking test vectors"
+		return c
+	}
+	for _, inv := range v.Invalid {
+		share, err := recovery.ParseShareInput(inv.Name, []byte(inv.Input))
+		if err == nil {
+			err = share.Verify()
+		}
+		if err == nil {
+			c.Err = fmt.Errorf("malformed input %q was accepted", inv.Name)
+			c.Detail = fmt.Sprintf("%d cases", len(v.Cases))
+			return c
+		}
+	}
+	c.Detail = fmt.Sprintf("%d cases in every share encoding, %d malformed inputs rejected", len(v.Cases), len(v.Invalid))
+	return c
+}
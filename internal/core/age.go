@@ -1,10 +1,13 @@
 package core
 
 import (
+	"bufio"
 	"bytes"
 	"errors"
 	"fmt"
 	"io"
+	"strconv"
+	"strings"
 
 	"filippo.io/age"
 )
@@ -15,17 +18,9 @@ var ErrEmptyPassphrase = errors.New("passphrase cannot be empty")
 // Encrypt encrypts data using age with a passphrase (scrypt mode).
 // The passphrase is used to derive an encryption key using scrypt.
 func Encrypt(dst io.Writer, src io.Reader, passphrase string) error {
-	if passphrase == "" {
-		return ErrEmptyPassphrase
-	}
-	recipient, err := age.NewScryptRecipient(passphrase)
+	writer, err := EncryptWriter(dst, passphrase, 0)
 	if err != nil {
-		return fmt.Errorf("creating recipient: %w", err)
-	}
-
-	writer, err := age.Encrypt(dst, recipient)
-	if err != nil {
-		return fmt.Errorf("creating encryptor: %w", err)
+		return err
 	}
 
 	if _, err := io.Copy(writer, src); err != nil {
@@ -39,17 +34,38 @@ func Encrypt(dst io.Writer, src io.Reader, passphrase string) error {
 	return nil
 }
 
-// Decrypt decrypts age-encrypted data using a passphrase.
-func Decrypt(dst io.Writer, src io.Reader, passphrase string) error {
+// EncryptWriter returns a writer that encrypts what is written to it, as
+// Encrypt does, into dst. Close must be called to finish the file. age
+// encrypts in 64 KiB chunks, so data of any size streams through it.
+// workFactor sets scrypt's log2(N); 0 keeps age's default of 18.
+func EncryptWriter(dst io.Writer, passphrase string, workFactor int) (io.WriteCloser, error) {
 	if passphrase == "" {
-		return ErrEmptyPassphrase
+		return nil, ErrEmptyPassphrase
 	}
-	identity, err := age.NewScryptIdentity(passphrase)
+	recipient, err := age.NewScryptRecipient(passphrase)
 	if err != nil {
-		return fmt.Errorf("creating identity: %w", err)
+		return nil, fmt.Errorf("creating recipient: %w", err)
+	}
+	if workFactor > 0 {
+		recipient.SetWorkFactor(workFactor)
 	}
 
-	reader, err := age.Decrypt(src, identity)
+	writer, err := age.Encrypt(dst, recipient)
+	if err != nil {
+		return nil, fmt.Errorf("creating encryptor: %w", err)
+	}
+	return writer, nil
+}
+
+// Decrypt decrypts age-encrypted data using a passphrase, sealed either
+// with age's scrypt or in the post-quantum hybrid mode (see EncryptWriterPQ).
+func Decrypt(dst io.Writer, src io.Reader, passphrase string) error {
+	identities, err := passphraseIdentities(passphrase)
+	if err != nil {
+		return err
+	}
+
+	reader, err := age.Decrypt(src, identities...)
 	if err != nil {
 		return fmt.Errorf("decrypting: %w", err)
 	}
@@ -63,15 +79,12 @@ func Decrypt(dst io.Writer, src io.Reader, passphrase string) error {
 
 // DecryptBytes is a convenience function that decrypts data and returns bytes.
 func DecryptBytes(encryptedData []byte, passphrase string) ([]byte, error) {
-	if passphrase == "" {
-		return nil, ErrEmptyPassphrase
-	}
-	identity, err := age.NewScryptIdentity(passphrase)
+	identities, err := passphraseIdentities(passphrase)
 	if err != nil {
-		return nil, fmt.Errorf("creating identity: %w", err)
+		return nil, err
 	}
 
-	reader, err := age.Decrypt(bytes.NewReader(encryptedData), identity)
+	reader, err := age.Decrypt(bytes.NewReader(encryptedData), identities...)
 	if err != nil {
 		return nil, fmt.Errorf("decrypting: %w", err)
 	}
@@ -83,3 +96,137 @@ func DecryptBytes(encryptedData []byte, passphrase string) ([]byte, error) {
 
 	return decrypted, nil
 }
+
+// RecipientsFile holds the passphrase encrypted to a project's recipients,
+// written next to MANIFEST.age.
+const RecipientsFile = "RECIPIENTS.age"
+
+// DecoyFile holds a project's decoy: harmless files sealed under a
+// passphrase of their own, which the duress pieces rebuild. It's written
+// next to MANIFEST.age.
+const DecoyFile = "DECOY.age"
+
+// ParseRecipients parses age X25519 public keys ("age1...") and hybrid
+// ML-KEM-768 + X25519 keys ("age1pq1..."). An scrypt recipient must be alone
+// in an age file, so these can't be added to MANIFEST.age itself; they lock
+// a copy of the passphrase instead. The CLI uses crypto.ParseRecipients,
+// which also takes plugin recipients.
+func ParseRecipients(keys []string) ([]age.Recipient, error) {
+	recipients := make([]age.Recipient, len(keys))
+	for i, key := range keys {
+		key = strings.TrimSpace(key)
+		var r age.Recipient
+		var err error
+		if IsHybridRecipient(key) {
+			r, err = age.ParseHybridRecipient(key)
+		} else {
+			r, err = age.ParseX25519Recipient(key)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("recipient %q: %w", key, err)
+		}
+		recipients[i] = r
+	}
+	return recipients, nil
+}
+
+// IsHybridRecipient reports whether key is an age hybrid ML-KEM-768 +
+// X25519 public key, the only recipients the post-quantum mode allows.
+func IsHybridRecipient(key string) bool {
+	return strings.HasPrefix(strings.TrimSpace(key), "age1pq1")
+}
+
+// EncryptToRecipients encrypts data with age to recipients, any one of
+// whose identities can decrypt it.
+func EncryptToRecipients(dst io.Writer, src io.Reader, recipients []age.Recipient) error {
+	if len(recipients) == 0 {
+		return fmt.Errorf("no recipients")
+	}
+	writer, err := age.Encrypt(dst, recipients...)
+	if err != nil {
+		return fmt.Errorf("creating encryptor: %w", err)
+	}
+	if _, err := io.Copy(writer, src); err != nil {
+		return fmt.Errorf("encrypting: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("finalizing encryption: %w", err)
+	}
+	return nil
+}
+
+// DecryptWithIdentities decrypts age-encrypted data with identities, tried
+// in order until one matches.
+func DecryptWithIdentities(encryptedData []byte, identities []age.Identity) ([]byte, error) {
+	if len(identities) == 0 {
+		return nil, fmt.Errorf("no identities")
+	}
+	reader, err := age.Decrypt(bytes.NewReader(encryptedData), identities...)
+	if err != nil {
+		return nil, fmt.Errorf("decrypting: %w", err)
+	}
+	decrypted, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("reading decrypted data: %w", err)
+	}
+	return decrypted, nil
+}
+
+// ageHeaderLine is the first line of every age-encrypted file.
+const ageHeaderLine = "age-encryption.org/v1"
+
+// maxAgeHeaderSize bounds how much of a file InspectManifest will read
+// while looking for the end of the age header.
+const maxAgeHeaderSize = 64 * 1024
+
+// ManifestInfo describes the unencrypted header of an age file.
+// Reading it requires no passphrase and reveals nothing about the contents.
+type ManifestInfo struct {
+	Recipients []string // Recipient stanza types, in order (e.g. "scrypt")
+	WorkFactor int      // scrypt log2(N) work factor; 0 when there is no scrypt or post-quantum stanza
+}
+
+// InspectManifest parses the age header from r without decrypting anything.
+// Returns an error if r does not look like an age-encrypted file.
+func InspectManifest(r io.Reader) (*ManifestInfo, error) {
+	br := bufio.NewReader(io.LimitReader(r, maxAgeHeaderSize))
+
+	first, err := br.ReadString('\n')
+	if err != nil || strings.TrimRight(first, "\n") != ageHeaderLine {
+		return nil, fmt.Errorf("not an age-encrypted file")
+	}
+
+	info := &ManifestInfo{}
+	for {
+		line, err := br.ReadString('\n')
+		if err != nil {
+			return nil, fmt.Errorf("truncated age header")
+		}
+		line = strings.TrimRight(line, "\n")
+
+		if strings.HasPrefix(line, "---") {
+			break
+		}
+		if !strings.HasPrefix(line, "-> ") {
+			continue // stanza body
+		}
+
+		args := strings.Fields(strings.TrimPrefix(line, "-> "))
+		if len(args) == 0 {
+			return nil, fmt.Errorf("malformed age header: empty stanza")
+		}
+		info.Recipients = append(info.Recipients, args[0])
+		if (args[0] == "scrypt" || args[0] == PQStanzaType) && len(args) == 3 {
+			logN, err := strconv.Atoi(args[2])
+			if err != nil {
+				return nil, fmt.Errorf("malformed scrypt stanza: %w", err)
+			}
+			info.WorkFactor = logN
+		}
+	}
+
+	if len(info.Recipients) == 0 {
+		return nil, fmt.Errorf("malformed age header: no recipients")
+	}
+	return info, nil
+}
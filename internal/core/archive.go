@@ -6,40 +6,70 @@ import (
 	"compress/gzip"
 	"fmt"
 	"io"
+	"path"
 	"regexp"
+	"strings"
 )
 
 const (
-	// MaxFileSize is the maximum size of a single file during extraction (100 MB).
+	// MaxFileSize is the maximum size of a single file during extraction in
+	// memory (100 MB).
 	MaxFileSize = 100 * 1024 * 1024
-	// MaxTotalSize is the maximum total size of all extracted files (1 GB).
+	// MaxTotalSize is the maximum total size of all files extracted in
+	// memory (1 GB).
 	MaxTotalSize = 1024 * 1024 * 1024
 )
 
-// ExtractedFile represents a file extracted from a tar.gz archive.
+// ExtractedFile represents a file extracted from a tar.gz archive. Dir is
+// set for a folder that was empty when sealed: it has no Data, and is kept so
+// the folder isn't lost on the way back. Link is set for a symlink sealed
+// as one, to where it points; it has no Data either.
 type ExtractedFile struct {
-	Name string
-	Data []byte
+	Name   string
+	Data   []byte
+	Dir    bool
+	Link   string
+	Status string // How the file compared to the archive's index (IndexOK, ...); empty without one
 }
 
-// ExtractTarGz extracts files from tar.gz data in memory.
-// This is used by both CLI and WASM for in-memory extraction.
-// For file-based extraction, use the manifest package.
+// ExtractTarGz extracts files from tar.gz data in memory, within MaxFileSize
+// and MaxTotalSize. This is used by WASM, which has nowhere else to put
+// them; the CLI unpacks to disk as it decrypts, with manifest.Extract.
 func ExtractTarGz(tarGzData []byte) ([]ExtractedFile, error) {
 	return ExtractTarGzReader(bytes.NewReader(tarGzData))
 }
 
-// ExtractTarGzReader extracts files from a tar.gz reader.
+// ExtractTarGzReader extracts files from a tar.gz reader. Names that
+// collide on case-insensitive file systems are renamed as CaseNames does,
+// as manifest.Extract renames them on disk. A hard link to a file before
+// it, which is how a copy of a file is sealed, comes out as a file of its
+// own sharing that file's Data.
 func ExtractTarGzReader(r io.Reader) ([]ExtractedFile, error) {
+	files, _, err := ExtractTarGzChecked(r)
+	return files, err
+}
+
+// ExtractTarGzChecked extracts files as ExtractTarGzReader does, and checks
+// each one against the archive's index, setting its Status. The checks,
+// missing files included, are nil for an archive sealed without an index.
+func ExtractTarGzChecked(r io.Reader) ([]ExtractedFile, []IndexCheck, error) {
 	gzr, err := gzip.NewReader(r)
 	if err != nil {
-		return nil, fmt.Errorf("creating gzip reader: %w", err)
+		return nil, nil, fmt.Errorf("creating gzip reader: %w", err)
 	}
 	defer gzr.Close()
 
 	tr := tar.NewReader(gzr)
 	var files []ExtractedFile
+	var dirs []string
+	var links []ExtractedFile
 	var totalSize int64
+	// Folders something else in the archive is in
+	filled := make(map[string]bool)
+	names := NewCaseNames()
+	var index *Index
+	var hashes []FileHash
+	read := make(map[string][]byte) // Each file's data, by its name in the archive
 
 	// Regex to detect path traversal
 	pathTraversal := regexp.MustCompile(`(^|/)\.\.(/|$)`)
@@ -50,44 +80,137 @@ func ExtractTarGzReader(r io.Reader) ([]ExtractedFile, error) {
 			break
 		}
 		if err != nil {
-			return nil, fmt.Errorf("reading tar: %w", err)
+			return nil, nil, fmt.Errorf("reading tar: %w", err)
 		}
 
 		// Security: reject path traversal
 		if pathTraversal.MatchString(header.Name) {
-			return nil, fmt.Errorf("archive contains invalid path: %s", header.Name)
+			return nil, nil, fmt.Errorf("archive contains invalid path: %s", header.Name)
+		}
+
+		if header.Name == IndexFile && header.Typeflag == tar.TypeReg {
+			if index, err = ReadIndex(tr); err != nil {
+				return nil, nil, err
+			}
+			continue
+		}
+
+		placed := header.Name
+		if header.Typeflag == tar.TypeDir || header.Typeflag == tar.TypeReg || header.Typeflag == tar.TypeSymlink || header.Typeflag == tar.TypeLink {
+			placed, _ = names.Place(header.Name)
+		}
+		name := strings.TrimSuffix(placed, "/")
+		for dir := path.Dir(name); dir != "." && dir != "/"; dir = path.Dir(dir) {
+			filled[dir] = true
+		}
+		if header.Typeflag == tar.TypeDir {
+			dirs = append(dirs, name)
+			continue
 		}
 
-		// Skip directories, symlinks, and other special files
+		// Symlinks are listed with where they point, for whoever unpacks
+		// the archive; other special files are never sealed
+		if header.Typeflag == tar.TypeSymlink {
+			links = append(links, ExtractedFile{Name: placed, Link: header.Linkname})
+			continue
+		}
+		if header.Typeflag == tar.TypeLink {
+			if data, ok := read[header.Linkname]; ok {
+				files = append(files, ExtractedFile{Name: placed, Data: data})
+				hashes = append(hashes, HashFile(header.Name, data))
+				read[header.Name] = data
+			}
+			continue
+		}
 		if header.Typeflag != tar.TypeReg {
 			continue
 		}
 
 		// Security: enforce file size limits
 		if header.Size > MaxFileSize {
-			return nil, fmt.Errorf("file %s exceeds maximum allowed size (%d bytes)", header.Name, MaxFileSize)
+			return nil, nil, fmt.Errorf("file %s exceeds maximum allowed size (%d bytes)", header.Name, MaxFileSize)
 		}
 		totalSize += header.Size
 		if totalSize > MaxTotalSize {
-			return nil, fmt.Errorf("archive exceeds maximum total size (%d bytes)", MaxTotalSize)
+			return nil, nil, fmt.Errorf("archive exceeds maximum total size (%d bytes)", MaxTotalSize)
 		}
 
 		// Use LimitReader for additional safety
 		limitedReader := io.LimitReader(tr, MaxFileSize)
 		data, err := io.ReadAll(limitedReader)
 		if err != nil {
-			return nil, fmt.Errorf("reading file %s from archive: %w", header.Name, err)
+			return nil, nil, fmt.Errorf("reading file %s from archive: %w", header.Name, err)
 		}
 
 		files = append(files, ExtractedFile{
-			Name: header.Name,
+			Name: placed,
 			Data: data,
 		})
+		hashes = append(hashes, HashFile(header.Name, data))
+		read[header.Name] = data
 	}
 
+	var checks []IndexCheck
+	if index != nil {
+		checks = index.Check(hashes)
+		status := make(map[string]string, len(checks))
+		for _, c := range checks {
+			status[c.Path] = c.Status
+		}
+		for i, h := range hashes {
+			files[i].Status = status[h.Path]
+		}
+	}
+
+	// After the files, so their statuses line up with the hashes
+	files = append(files, links...)
+	for _, dir := range dirs {
+		if !filled[dir] {
+			files = append(files, ExtractedFile{Name: dir + "/", Dir: true})
+			filled[dir] = true // Once, when folders differing in case merged
+		}
+	}
 	if len(files) == 0 {
-		return nil, fmt.Errorf("empty archive")
+		return nil, nil, fmt.Errorf("empty archive")
+	}
+
+	return files, checks, nil
+}
+
+// ListTarGz returns the files in a tar.gz archive without extracting them,
+// from its index, and whether it had one. For an archive sealed without an
+// index they come from the archive's entries, with no SHA-256.
+func ListTarGz(r io.Reader) ([]IndexEntry, bool, error) {
+	gzr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, false, fmt.Errorf("creating gzip reader: %w", err)
 	}
+	defer gzr.Close()
 
-	return files, nil
+	tr := tar.NewReader(gzr)
+	var entries []IndexEntry
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, false, fmt.Errorf("reading tar: %w", err)
+		}
+		if header.Name == IndexFile && header.Typeflag == tar.TypeReg {
+			index, err := ReadIndex(tr)
+			if err != nil {
+				return nil, false, err
+			}
+			return index.Files, true, nil
+		}
+		if header.Typeflag == tar.TypeReg {
+			entries = append(entries, IndexEntry{
+				Path:     header.Name,
+				Size:     header.Size,
+				Modified: header.ModTime.UTC(),
+			})
+		}
+	}
+	return entries, false, nil
 }
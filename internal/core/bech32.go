@@ -0,0 +1,206 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Bech32 form of a piece, for typing by hand from paper: "rm1" and the
+// piece in Bech32m (BIP-350). Its alphabet leaves out 1, b, i, and o, so
+// nothing can be read two ways, and the checksum catches any four mistyped
+// characters instead of letting Shamir combine produce garbage.
+//
+// The payload is version, index, total, and threshold, one byte each, then
+// the share data.
+const (
+	Bech32SharePrefix = "rm1"
+
+	bech32HRP     = "rm"
+	bech32Charset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+	bech32Const   = 1 // BIP-173, which age keys use
+	bech32mConst  = 0x2bc830a3
+	bech32MaxLen  = 90
+)
+
+// Bech32 returns the piece as a Bech32m string starting with "rm1".
+func (s *Share) Bech32() (string, error) {
+	if s.Groups > 0 {
+		return "", errGroupedShare
+	}
+	if s.Locked() {
+		return "", errLockedShare
+	}
+	if s.Secret {
+		return "", errSecretShare
+	}
+	for _, v := range []int{s.Version, s.Index, s.Total, s.Threshold} {
+		if v < 0 || v > 255 {
+			return "", fmt.Errorf("bech32 pieces hold numbers up to 255, got %d", v)
+		}
+	}
+	payload := append([]byte{byte(s.Version), byte(s.Index), byte(s.Total), byte(s.Threshold)}, s.Data...)
+	text := bech32Encode(bech32HRP, payload, bech32mConst)
+	if len(text) > bech32MaxLen {
+		return "", fmt.Errorf("piece is too long for bech32")
+	}
+	return text, nil
+}
+
+// IsBech32Share reports whether text looks like a Bech32 piece, ignoring
+// case, spaces, and dashes. Version 1 compact shares ("RM1:...") also start
+// with rm1, but they have colons.
+func IsBech32Share(text string) bool {
+	text = normalizeBech32(text)
+	return strings.HasPrefix(text, Bech32SharePrefix) && !strings.Contains(text, ":")
+}
+
+// ParseBech32Share reads a piece written by Share.Bech32. Case, spaces, and
+// dashes don't matter, so it can be typed in groups.
+func ParseBech32Share(text string) (*Share, error) {
+	text = normalizeBech32(text)
+	if !strings.HasPrefix(text, Bech32SharePrefix) {
+		return nil, fmt.Errorf("invalid bech32 piece: must start with %q", Bech32SharePrefix)
+	}
+	if len(text) > bech32MaxLen {
+		return nil, fmt.Errorf("invalid bech32 piece: too long")
+	}
+
+	body := text[len(Bech32SharePrefix):]
+	values := make([]byte, len(body))
+	for i, c := range body {
+		v := strings.IndexRune(bech32Charset, c)
+		if v < 0 {
+			return nil, fmt.Errorf("invalid bech32 piece: character %d %s", len(Bech32SharePrefix)+i+1, bech32Hint(c))
+		}
+		values[i] = byte(v)
+	}
+	if bech32Polymod(append(bech32HRPExpand(bech32HRP), values...)) != bech32mConst {
+		return nil, fmt.Errorf("invalid bech32 piece: checksum mismatch — a character may be mistyped")
+	}
+
+	if len(values) <= 6 {
+		return nil, fmt.Errorf("invalid bech32 piece: too short")
+	}
+	payload, err := bech32ConvertBitsStrict(values[:len(values)-6])
+	if err != nil {
+		return nil, fmt.Errorf("invalid bech32 piece: %w", err)
+	}
+	if len(payload) < 5 {
+		return nil, fmt.Errorf("invalid bech32 piece: too short")
+	}
+	share := &Share{
+		Version:   int(payload[0]),
+		Index:     int(payload[1]),
+		Total:     int(payload[2]),
+		Threshold: int(payload[3]),
+		Data:      payload[4:],
+	}
+	if share.Version < 1 || share.Index < 1 || share.Total < 1 || share.Threshold < 1 {
+		return nil, fmt.Errorf("invalid bech32 piece: bad header")
+	}
+	share.Checksum = HashBytes(share.Data)
+	return share, nil
+}
+
+func normalizeBech32(text string) string {
+	return strings.ToLower(strings.Map(func(r rune) rune {
+		if r == ' ' || r == '-' || r == '\n' || r == '\r' || r == '\t' {
+			return -1
+		}
+		return r
+	}, text))
+}
+
+// bech32Hint explains a character Bech32 leaves out, pointing at the one
+// that was probably meant.
+func bech32Hint(c rune) string {
+	switch c {
+	case 'o':
+		return "is the letter o, which isn't used — did you mean the digit 0?"
+	case '1', 'i':
+		return fmt.Sprintf("is %q, which isn't used — did you mean the letter l?", c)
+	case 'b':
+		return "is the letter b, which isn't used — did you mean the digit 6 or 8?"
+	default:
+		return fmt.Sprintf("%q isn't used in bech32", c)
+	}
+}
+
+func bech32Polymod(values []byte) uint32 {
+	gen := [5]uint32{0x3b6a57b2, 0x26508e6d, 0x1ea119fa, 0x3d4233dd, 0x2a1462b3}
+	chk := uint32(1)
+	for _, v := range values {
+		top := chk >> 25
+		chk = (chk&0x1ffffff)<<5 ^ uint32(v)
+		for i := 0; i < 5; i++ {
+			if (top>>i)&1 == 1 {
+				chk ^= gen[i]
+			}
+		}
+	}
+	return chk
+}
+
+func bech32HRPExpand(hrp string) []byte {
+	out := make([]byte, 0, len(hrp)*2+1)
+	for _, c := range hrp {
+		out = append(out, byte(c>>5))
+	}
+	out = append(out, 0)
+	for _, c := range hrp {
+		out = append(out, byte(c&31))
+	}
+	return out
+}
+
+func bech32Checksum(hrp string, values []byte, constant uint32) []byte {
+	mod := bech32Polymod(append(append(bech32HRPExpand(hrp), values...), 0, 0, 0, 0, 0, 0)) ^ constant
+	out := make([]byte, 6)
+	for i := range out {
+		out[i] = byte(mod>>(5*(5-i))) & 31
+	}
+	return out
+}
+
+// bech32Encode encodes data as Bech32 under a lowercase hrp, with the
+// checksum constant of Bech32 or Bech32m.
+func bech32Encode(hrp string, data []byte, constant uint32) string {
+	values := bech32ConvertBits(data, 8, 5, true)
+	var sb strings.Builder
+	sb.WriteString(hrp + "1")
+	for _, v := range append(values, bech32Checksum(hrp, values, constant)...) {
+		sb.WriteByte(bech32Charset[v])
+	}
+	return sb.String()
+}
+
+// bech32ConvertBits regroups data from fromBits-bit to toBits-bit values,
+// padding the last one with zeros.
+func bech32ConvertBits(data []byte, fromBits, toBits uint, pad bool) []byte {
+	var acc uint32
+	var bits uint
+	var out []byte
+	maxv := uint32(1)<<toBits - 1
+	for _, b := range data {
+		acc = acc<<fromBits | uint32(b)
+		bits += fromBits
+		for bits >= toBits {
+			bits -= toBits
+			out = append(out, byte(acc>>bits&maxv))
+		}
+	}
+	if pad && bits > 0 {
+		out = append(out, byte(acc<<(toBits-bits)&maxv))
+	}
+	return out
+}
+
+// bech32ConvertBitsStrict turns 5-bit values back into bytes, rejecting
+// leftover bits that aren't zero padding.
+func bech32ConvertBitsStrict(values []byte) ([]byte, error) {
+	out := bech32ConvertBits(values, 5, 8, false)
+	if bits := len(values) * 5 % 8; bits >= 5 || values[len(values)-1]&(1<<bits-1) != 0 {
+		return nil, fmt.Errorf("bad padding")
+	}
+	return out, nil
+}
@@ -0,0 +1,116 @@
+package core
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestBech32mChecksum(t *testing.T) {
+	// Valid strings from the BIP-350 test vectors
+	for _, s := range []string{"a1lqfn3a", "abcdef1l7aum6echk45nj3s0wdvt2fg8x9yrzpqzd3ryx"} {
+		hrp, body, _ := strings.Cut(s, "1")
+		values := make([]byte, len(body))
+		for i, c := range body {
+			values[i] = byte(strings.IndexRune(bech32Charset, c))
+		}
+		if bech32Polymod(append(bech32HRPExpand(hrp), values...)) != bech32mConst {
+			t.Errorf("%s doesn't validate", s)
+		}
+	}
+}
+
+func TestShareBech32RoundTrip(t *testing.T) {
+	share := testDigitShare(t, 7)
+	text, err := share.Bech32()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(text, Bech32SharePrefix) || strings.ContainsAny(text[len(Bech32SharePrefix):], "1bio") {
+		t.Errorf("Bech32() = %q", text)
+	}
+
+	var grouped strings.Builder
+	for i := 0; i < len(text); i += 4 {
+		grouped.WriteString(text[i:min(i+4, len(text))] + " ")
+	}
+	for _, s := range []string{text, strings.ToUpper(text), grouped.String(), strings.ReplaceAll(grouped.String(), " ", "-")} {
+		if !IsBech32Share(s) {
+			t.Errorf("IsBech32Share(%q) = false", s)
+		}
+		got, err := ParseBech32Share(s)
+		if err != nil {
+			t.Fatalf("%q: %v", s, err)
+		}
+		if got.Version != 2 || got.Index != 7 || got.Total != 3 || got.Threshold != 2 || !bytes.Equal(got.Data, share.Data) {
+			t.Errorf("got %+v", got)
+		}
+		if got.Checksum != share.Checksum {
+			t.Errorf("checksum = %s, want %s", got.Checksum, share.Checksum)
+		}
+	}
+}
+
+func TestShareBech32CatchesMistakes(t *testing.T) {
+	text, err := testDigitShare(t, 2).Bech32()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Every single mistyped character is caught
+	for i := len(Bech32SharePrefix); i < len(text); i++ {
+		b := []byte(text)
+		b[i] = bech32Charset[(strings.IndexByte(bech32Charset, b[i])+5)%32]
+		if _, err := ParseBech32Share(string(b)); err == nil || !strings.Contains(err.Error(), "checksum mismatch") {
+			t.Errorf("character %d: got %v", i+1, err)
+		}
+	}
+
+	// Letters Bech32 leaves out point at what was probably meant
+	zero := strings.IndexByte(text[len(Bech32SharePrefix):], '0')
+	ell := strings.IndexByte(text[len(Bech32SharePrefix):], 'l')
+	for _, tc := range []struct {
+		pos  int
+		c    byte
+		want string
+	}{
+		{zero, 'o', "digit 0"},
+		{ell, '1', "letter l"},
+	} {
+		if tc.pos < 0 {
+			continue
+		}
+		b := []byte(text)
+		b[len(Bech32SharePrefix)+tc.pos] = tc.c
+		if _, err := ParseBech32Share(string(b)); err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("%c: got %v", tc.c, err)
+		}
+	}
+
+	if _, err := ParseBech32Share(text[:len(text)-1]); err == nil {
+		t.Error("missing character not caught")
+	}
+	if IsBech32Share("RM1:2:5:3:QUJD:abcd") {
+		t.Error("v1 compact share taken for bech32")
+	}
+	if _, err := ParseBech32Share("RM2:1:1:2:abc:def"); err == nil {
+		t.Error("compact share accepted as bech32")
+	}
+}
+
+func TestBech32ConvertBitsStrict(t *testing.T) {
+	payload := []byte{1, 2, 3}
+	values := bech32ConvertBits(payload, 8, 5, true)
+	got, err := bech32ConvertBitsStrict(values)
+	if err != nil || !bytes.Equal(got, payload) {
+		t.Errorf("got %x, %v", got, err)
+	}
+
+	// Leftover bits must be zero, and no more than four of them
+	if _, err := bech32ConvertBitsStrict(append(values[:len(values)-1], values[len(values)-1]|1)); err == nil {
+		t.Error("nonzero padding accepted")
+	}
+	if _, err := bech32ConvertBitsStrict(append(values, 0)); err == nil {
+		t.Error("extra padding accepted")
+	}
+}
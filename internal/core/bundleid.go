@@ -0,0 +1,174 @@
+package core
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// Bundle IDs name one seal: every piece of it records the same random ID,
+// so pieces from two different projects, or two seals of one, are told
+// apart before they are combined. Each piece also carries a tag binding the
+// ID to its own data, so a piece can't be moved to another set by editing
+// its header.
+//
+// The ID's first four bytes, which its fingerprint shows, also go in the
+// share data itself, so words, digits, and rm1 strings carry them too: the
+// secret starts with a bundle header, and so does every piece of it. Each
+// byte of a piece is that byte's polynomial evaluated at the piece's x, and
+// a byte that is the same in every piece lies on the polynomial of degree
+// 0, so combining gives the header back while the rest of the secret stays
+// split as it was. The header is part of the passphrase, so a piece can't
+// be moved to another set by rewriting it either: the passphrase combined
+// would come out wrong.
+const (
+	bundleIDSize     = 8 // Random bytes in a bundle ID
+	bundleTagSize    = 4 // Bytes of HMAC kept in a piece's tag
+	bundleHeaderSize = 8 // The ID's first four bytes, then four check bytes
+)
+
+// NewBundleID returns a random bundle ID as 16 lowercase hex characters.
+func NewBundleID() (string, error) {
+	b := make([]byte, bundleIDSize)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("generating bundle ID: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// ValidBundleID reports whether id looks like one NewBundleID made.
+func ValidBundleID(id string) bool {
+	b, err := hex.DecodeString(id)
+	return err == nil && len(b) == bundleIDSize && id == strings.ToLower(id)
+}
+
+// BundleFingerprint is the short form of a bundle ID people compare by eye,
+// such as "9F3A-1C2E". It returns "" for an empty ID.
+func BundleFingerprint(id string) string {
+	if len(id) < 8 {
+		return ""
+	}
+	return strings.ToUpper(id[:4] + "-" + id[4:8])
+}
+
+// Bind records the bundle ID on the share and tags its data with it.
+func (s *Share) Bind(id string) {
+	s.BundleID = id
+	s.BundleTag = bundleTag(id, s.Index, s.Data)
+}
+
+// checkBundle checks that a share's tag matches its bundle ID and data, and
+// that the bundle its data names, if any, is the one it records. Shares
+// without a bundle ID, from before they were recorded or read from words,
+// digits, or an rm1 string, pass.
+func (s *Share) checkBundle() error {
+	if s.BundleID == "" && s.BundleTag == "" {
+		return nil
+	}
+	if !ValidBundleID(s.BundleID) {
+		return fmt.Errorf("invalid bundle ID %q", s.BundleID)
+	}
+	if !hmac.Equal([]byte(s.BundleTag), []byte(bundleTag(s.BundleID, s.Index, s.Data))) {
+		return fmt.Errorf("piece %d doesn't belong to bundle %s", s.Index, BundleFingerprint(s.BundleID))
+	}
+	if named := DataBundle(s.Data); named != "" && named != BundleFingerprint(s.BundleID) {
+		return fmt.Errorf("piece %d is from bundle %s, but records bundle %s", s.Index, named, BundleFingerprint(s.BundleID))
+	}
+	return nil
+}
+
+// bundleHeader returns the header naming the bundle whose ID starts with
+// prefix: prefix, then four bytes of its SHA-256 that tell a header apart
+// from random share data.
+func bundleHeader(prefix []byte) []byte {
+	prefix = bytes.Clone(prefix[:4])
+	sum := sha256.Sum256(append([]byte("rememory bundle header "), prefix...))
+	return append(prefix, sum[:bundleHeaderSize-4]...)
+}
+
+// BindSecret writes the header naming bundle id over the start of a secret
+// that is about to be split, leaving that many fewer random bytes: 24 of a
+// 32-byte passphrase.
+func BindSecret(secret []byte, id string) error {
+	if !ValidBundleID(id) {
+		return fmt.Errorf("invalid bundle ID %q", id)
+	}
+	if len(secret) < bundleHeaderSize+16 {
+		return fmt.Errorf("a %d-byte secret is too short to carry a bundle header", len(secret))
+	}
+	prefix, _ := hex.DecodeString(id)
+	copy(secret, bundleHeader(prefix))
+	return nil
+}
+
+// BindShares writes the header naming bundle id over the start of each
+// share of a secret BindSecret bound, as Split doesn't keep it in place.
+func BindShares(shares [][]byte, id string) error {
+	if !ValidBundleID(id) {
+		return fmt.Errorf("invalid bundle ID %q", id)
+	}
+	prefix, _ := hex.DecodeString(id)
+	header := bundleHeader(prefix)
+	for _, s := range shares {
+		if len(s) < bundleHeaderSize+1 {
+			return fmt.Errorf("a %d-byte share is too short to carry a bundle header", len(s))
+		}
+		copy(s, header)
+	}
+	return nil
+}
+
+// DataBundle returns the fingerprint of the bundle a piece's data names,
+// such as "9F3A-1C2E", or "" when its data carries no bundle header: pieces
+// sealed before headers were written, and pieces locked with a PIN.
+func DataBundle(data []byte) string {
+	if len(data) < bundleHeaderSize+1 || !bytes.Equal(data[:bundleHeaderSize], bundleHeader(data[:4])) {
+		return ""
+	}
+	return BundleFingerprint(hex.EncodeToString(data[:4]))
+}
+
+// Fingerprint returns the short form of the bundle the share belongs to:
+// the one its data names, or else the one it records. It returns "" when
+// the share says neither.
+func (s *Share) Fingerprint() string {
+	if named := DataBundle(s.Data); named != "" {
+		return named
+	}
+	return BundleFingerprint(s.BundleID)
+}
+
+// bundleTag is the HMAC-SHA256, keyed with the bundle ID, of a piece's
+// index and data, cut to bundleTagSize bytes of hex.
+func bundleTag(id string, index int, data []byte) string {
+	mac := hmac.New(sha256.New, []byte(id))
+	binary.Write(mac, binary.BigEndian, uint16(index))
+	mac.Write(data)
+	return hex.EncodeToString(mac.Sum(nil)[:bundleTagSize])
+}
+
+// CheckSameBundle returns an error when two of the shares belong to
+// different bundles, by the ID they record or the header their data
+// carries. Shares with neither are accepted alongside any other.
+func CheckSameBundle(shares []*Share) error {
+	first := -1
+	for i, s := range shares {
+		if s.Fingerprint() == "" {
+			continue
+		}
+		if first == -1 {
+			first = i
+			continue
+		}
+		if s.Fingerprint() != shares[first].Fingerprint() {
+			return fmt.Errorf("share %d is from bundle %s, but share %d is from bundle %s — all shares must be from the same bundle",
+				i+1, s.Fingerprint(), first+1, shares[first].Fingerprint())
+		}
+	}
+	return nil
+}
@@ -0,0 +1,166 @@
+package core
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBundleID(t *testing.T) {
+	id, err := NewBundleID()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ValidBundleID(id) {
+		t.Fatalf("NewBundleID() = %q, not a valid ID", id)
+	}
+	if fp := BundleFingerprint(id); len(fp) != 9 || fp[4] != '-' || fp != strings.ToUpper(fp) {
+		t.Errorf("BundleFingerprint(%q) = %q", id, fp)
+	}
+
+	share := NewShare(2, 3, 5, 3, "Carol", []byte("share-data-for-the-bundle-test"))
+	share.Bind(id)
+
+	// Both the PEM block and the compact form carry the ID and its tag
+	fromPEM, err := ParseShare([]byte(share.Encode()))
+	if err != nil {
+		t.Fatalf("ParseShare: %v", err)
+	}
+	fromCompact, err := ParseCompact(share.CompactEncode())
+	if err != nil {
+		t.Fatalf("ParseCompact: %v", err)
+	}
+	for name, got := range map[string]*Share{"pem": fromPEM, "compact": fromCompact} {
+		if got.BundleID != id || got.BundleTag != share.BundleTag {
+			t.Errorf("%s: got bundle %q %q, want %q %q", name, got.BundleID, got.BundleTag, id, share.BundleTag)
+		}
+	}
+
+	// With groups, the bundle field comes last
+	share.Group, share.Groups, share.GroupsNeeded = 1, 2, 2
+	if got, err := ParseCompact(share.CompactEncode()); err != nil || got.Group != 1 || got.BundleID != id {
+		t.Errorf("grouped compact: got %+v, %v", got, err)
+	}
+	share.Group, share.Groups, share.GroupsNeeded = 0, 0, 0
+
+	// Moving a piece to another bundle by editing its header is caught
+	other, _ := NewBundleID()
+	forged := strings.Replace(share.Encode(), id, other, 1)
+	if _, err := ParseShare([]byte(forged)); err == nil || !strings.Contains(err.Error(), "doesn't belong") {
+		t.Errorf("forged PEM: got %v, want a bundle error", err)
+	}
+	forged = strings.Replace(share.CompactEncode(), id, other, 1)
+	if _, err := ParseCompact(forged); err == nil || !strings.Contains(err.Error(), "doesn't belong") {
+		t.Errorf("forged compact: got %v, want a bundle error", err)
+	}
+}
+
+func TestCheckSameBundle(t *testing.T) {
+	a, _ := NewBundleID()
+	b, _ := NewBundleID()
+	tests := []struct {
+		name string
+		ids  []string
+		ok   bool
+	}{
+		{"same", []string{a, a, a}, true},
+		{"unrecorded alongside", []string{a, "", a}, true},
+		{"none recorded", []string{"", ""}, true},
+		{"different", []string{a, "", b}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			shares := make([]*Share, len(tt.ids))
+			for i, id := range tt.ids {
+				shares[i] = &Share{Index: i + 1, BundleID: id}
+			}
+			err := CheckSameBundle(shares)
+			if (err == nil) != tt.ok {
+				t.Errorf("got %v, want ok=%v", err, tt.ok)
+			}
+		})
+	}
+}
+
+func TestBundleHeader(t *testing.T) {
+	id, _ := NewBundleID()
+	secret := make([]byte, 32)
+	for i := range secret {
+		secret[i] = byte(i * 7)
+	}
+	if DataBundle(secret) != "" {
+		t.Fatal("unbound secret names a bundle")
+	}
+	if err := BindSecret(secret, id); err != nil {
+		t.Fatal(err)
+	}
+	data, err := Split(secret, 5, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := BindShares(data, id); err != nil {
+		t.Fatal(err)
+	}
+
+	// The pieces still combine into the secret, and extend on it
+	got, err := Combine([][]byte{data[4], data[0], data[2]})
+	if err != nil || string(got) != string(secret) {
+		t.Fatalf("Combine = %x, %v; want %x", got, err, secret)
+	}
+	extra, err := Extend(data[1:4])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := Combine([][]byte{extra, data[0], data[4]}); string(got) != string(secret) {
+		t.Error("a piece added later doesn't combine with the others")
+	}
+
+	// Words, digits, and rm1 strings carry the bundle, with no header line
+	share := NewShare(2, 2, 5, 3, "", data[1])
+	want := BundleFingerprint(id)
+	words, err := share.Words()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fromWords, _, err := DecodeShareWords(words)
+	if err != nil || DataBundle(fromWords) != want {
+		t.Errorf("words: bundle %q, %v; want %q", DataBundle(fromWords), err, want)
+	}
+	groups, err := share.Digits()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fromDigits, _, err := DecodeShareDigits(strings.Join(groups, " "))
+	if err != nil || DataBundle(fromDigits) != want {
+		t.Errorf("digits: bundle %q, %v; want %q", DataBundle(fromDigits), err, want)
+	}
+	rm1, err := share.Bech32()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fromRM1, err := ParseBech32Share(rm1); err != nil || fromRM1.Fingerprint() != want {
+		t.Errorf("rm1: bundle %q, %v; want %q", fromRM1.Fingerprint(), err, want)
+	}
+
+	// A piece of another bundle is told apart from its data alone
+	other, _ := NewBundleID()
+	foreign := []byte(string(data[3]))
+	if err := BindShares([][]byte{foreign}, other); err != nil {
+		t.Fatal(err)
+	}
+	pieces := []*Share{{Index: 1, Data: data[0]}, {Index: 4, Data: foreign}}
+	if err := CheckSameBundle(pieces); err == nil {
+		t.Error("pieces of two bundles passed CheckSameBundle")
+	}
+	pieces[1].Data = data[3]
+	if err := CheckSameBundle(pieces); err != nil {
+		t.Errorf("pieces of one bundle: %v", err)
+	}
+
+	// A piece whose data names another bundle than the one it records is
+	// refused when it's read
+	share = NewShare(2, 4, 5, 3, "", foreign)
+	share.Bind(id)
+	if _, err := ParseShare([]byte(share.Encode())); err == nil || !strings.Contains(err.Error(), "but records bundle") {
+		t.Errorf("mismatched PEM: got %v", err)
+	}
+}
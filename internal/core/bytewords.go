@@ -0,0 +1,101 @@
+package core
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"strings"
+	"sync"
+)
+
+// Bytewords (Blockchain Commons BCR-2020-012) writes each byte as one of 256
+// four-letter words, followed by a CRC-32 of the data. The minimal form keeps
+// only each word's first and last letters, as used inside UR strings.
+const (
+	BytewordsSourceURL    = "https://github.com/BlockchainCommons/Research/blob/master/papers/bcr-2020-012-bytewords.md"
+	BytewordsExpectedHash = "59a1ed91db2432f6a50fa5cd013d8c634cfca77fa9367ebf5791279690f48a3e"
+)
+
+var (
+	bytewords        []string
+	bytewordsIndexes map[string]byte // Full words and their minimal (first and last letter) forms
+	bytewordsOnce    sync.Once
+)
+
+func loadBytewords() {
+	bytewordsOnce.Do(func() {
+		data, err := wordlistFS.ReadFile("wordlists/bytewords.txt")
+		if err != nil {
+			panic(fmt.Sprintf("loading bytewords: %v", err))
+		}
+		bytewords = strings.Fields(string(data))
+		if len(bytewords) != 256 {
+			panic(fmt.Sprintf("bytewords list has %d words, expected 256", len(bytewords)))
+		}
+		bytewordsIndexes = make(map[string]byte, 512)
+		for i, w := range bytewords {
+			bytewordsIndexes[w] = byte(i)
+			bytewordsIndexes[w[:1]+w[3:]] = byte(i)
+		}
+	})
+}
+
+// BytewordsEncode writes data and its checksum as space-separated words.
+func BytewordsEncode(data []byte) string {
+	loadBytewords()
+	words := make([]string, 0, len(data)+4)
+	for _, b := range bytewordsWithChecksum(data) {
+		words = append(words, bytewords[b])
+	}
+	return strings.Join(words, " ")
+}
+
+// BytewordsEncodeMinimal writes data and its checksum as two letters per byte.
+func BytewordsEncodeMinimal(data []byte) string {
+	loadBytewords()
+	var sb strings.Builder
+	for _, b := range bytewordsWithChecksum(data) {
+		w := bytewords[b]
+		sb.WriteString(w[:1] + w[3:])
+	}
+	return sb.String()
+}
+
+// BytewordsDecode reads words separated by spaces or dashes, or the minimal
+// form, and checks the checksum.
+func BytewordsDecode(text string) ([]byte, error) {
+	loadBytewords()
+	text = strings.ToLower(strings.TrimSpace(text))
+	fields := strings.FieldsFunc(text, func(r rune) bool { return r == '-' || r == ' ' || r == '\n' || r == '\r' || r == '\t' })
+
+	var tokens []string
+	switch {
+	case len(fields) == 1 && len(fields[0])%2 == 0 && len(fields[0]) != 4:
+		for i := 0; i < len(fields[0]); i += 2 {
+			tokens = append(tokens, fields[0][i:i+2])
+		}
+	default:
+		tokens = fields
+	}
+	if len(tokens) < 5 {
+		return nil, fmt.Errorf("too short for bytewords")
+	}
+
+	data := make([]byte, len(tokens))
+	for i, tok := range tokens {
+		b, ok := bytewordsIndexes[tok]
+		if !ok || (len(tok) != 2 && len(tok) != 4) {
+			return nil, fmt.Errorf("%q isn't a byteword", tok)
+		}
+		data[i] = b
+	}
+	body, checksum := data[:len(data)-4], data[len(data)-4:]
+	if crc32.ChecksumIEEE(body) != binary.BigEndian.Uint32(checksum) {
+		return nil, fmt.Errorf("bytewords checksum doesn't match; a word may be mistyped")
+	}
+	return body, nil
+}
+
+func bytewordsWithChecksum(data []byte) []byte {
+	return binary.BigEndian.AppendUint32(append([]byte(nil), data...), crc32.ChecksumIEEE(data))
+}
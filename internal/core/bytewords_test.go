@@ -0,0 +1,75 @@
+package core
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestBytewordsListIntegrity(t *testing.T) {
+	data, err := wordlistFS.ReadFile("wordlists/bytewords.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sum := sha256.Sum256(data)
+	if hash := hex.EncodeToString(sum[:]); hash != BytewordsExpectedHash {
+		t.Errorf("hash mismatch:\n  got:  %s\n  want: %s\n  source: %s", hash, BytewordsExpectedHash, BytewordsSourceURL)
+	}
+
+	// The minimal form relies on first and last letters being unique
+	words := strings.Fields(string(data))
+	if !sort.StringsAreSorted(words) {
+		t.Error("word list isn't sorted")
+	}
+	minimal := make(map[string]bool, len(words))
+	for _, w := range words {
+		if len(w) != 4 {
+			t.Errorf("%q isn't 4 letters", w)
+		}
+		if minimal[w[:1]+w[3:]] {
+			t.Errorf("%q shares its first and last letters with another word", w)
+		}
+		minimal[w[:1]+w[3:]] = true
+	}
+}
+
+// Vector from BCR-2020-012.
+func TestBytewordsVector(t *testing.T) {
+	data := []byte{0, 1, 2, 128, 255}
+	if got, want := BytewordsEncode(data), "able acid also lava zoom jade need echo taxi"; got != want {
+		t.Errorf("standard:\n  got:  %s\n  want: %s", got, want)
+	}
+	if got, want := BytewordsEncodeMinimal(data), "aeadaolazmjendeoti"; got != want {
+		t.Errorf("minimal:\n  got:  %s\n  want: %s", got, want)
+	}
+
+	for _, text := range []string{
+		"able acid also lava zoom jade need echo taxi",
+		"able-acid-also-lava-zoom-jade-need-echo-taxi",
+		"AEADAOLAZMJENDEOTI",
+	} {
+		got, err := BytewordsDecode(text)
+		if err != nil {
+			t.Errorf("%q: %v", text, err)
+			continue
+		}
+		if !bytes.Equal(got, data) {
+			t.Errorf("%q decoded to %x", text, got)
+		}
+	}
+}
+
+func TestBytewordsMistakes(t *testing.T) {
+	for _, text := range []string{
+		"able acid also lava zoom jade need echo tied", // Wrong checksum
+		"able acid also lava zoom jade need echo taxo", // Not a byteword
+		"able acid also",
+	} {
+		if _, err := BytewordsDecode(text); err == nil {
+			t.Errorf("%q: expected an error", text)
+		}
+	}
+}
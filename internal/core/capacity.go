@@ -0,0 +1,100 @@
+package core
+
+import (
+	"fmt"
+	"net/url"
+)
+
+// qrBytesMedium is how many bytes a QR code holds at medium error
+// correction, by version: the code is 17+4×version modules across.
+var qrBytesMedium = []int{14, 26, 42, 62, 84, 106, 122, 152, 180, 213, 251, 287, 331, 362, 412, 450, 504, 560, 624, 666}
+
+// Capacity is how large one piece of a split comes out in each form it is
+// printed in, for choosing the number of pieces and the threshold before
+// anything is sealed.
+type Capacity struct {
+	ShareBlock  int               `json:"shareBlock"`  // Characters in the share block
+	Compact     int               `json:"compact"`     // Characters in the RM2: string
+	QRContent   int               `json:"qrContent"`   // Characters in the recovery link a QR code holds
+	QRCodes     int               `json:"qrCodes"`     // Codes the link is printed over
+	QRVersion   int               `json:"qrVersion"`   // Version of the largest code
+	QRModules   int               `json:"qrModules"`   // Modules across the largest code
+	Words       int               `json:"words"`       // Recovery words, 0 when not printed
+	DigitGroups int               `json:"digitGroups"` // Groups of digits, 0 when not printed
+	Bech32      int               `json:"rm1"`         // Characters in the rm1 string, 0 when not printed
+	Warnings    []CapacityWarning `json:"warnings,omitempty"`
+}
+
+// CapacityWarning is a way a split prints less reliably than a small one.
+// Code names it for callers that translate it; Count is the number it is
+// about.
+type CapacityWarning struct {
+	Code    string `json:"code"`
+	Count   int    `json:"count"`
+	Message string `json:"message"`
+}
+
+// EstimateCapacity measures the last, longest-numbered piece of a total-
+// piece split needing threshold, as seal would print it for recoveryURL
+// (DefaultRecoveryURL when empty). Groups, when set, are recorded on the
+// piece as seal does.
+func EstimateCapacity(total, threshold, groups int, recoveryURL string) Capacity {
+	if recoveryURL == "" {
+		recoveryURL = DefaultRecoveryURL
+	}
+	// A v2 piece of a 32-byte passphrase, with a bundle ID, numbered last
+	share := NewShare(2, total, total, threshold, "", make([]byte, 33))
+	if groups > 0 {
+		share.Group, share.Groups, share.GroupsNeeded = groups, groups, threshold
+	}
+	share.Bind("0000000000000000")
+
+	link := recoveryURL + "#share=" + url.QueryEscape(share.CompactEncode())
+	codes := SplitQR(link)
+	c := Capacity{
+		ShareBlock: len(share.Encode()),
+		Compact:    len(share.CompactEncode()),
+		QRContent:  len(link),
+		QRCodes:    len(codes),
+	}
+	for _, code := range codes {
+		c.QRVersion = max(c.QRVersion, qrVersion(len(code)))
+	}
+	c.QRModules = 17 + 4*c.QRVersion
+	if words, err := share.Words(); err == nil {
+		c.Words = len(words)
+	}
+	if digits, err := share.Digits(); err == nil {
+		c.DigitGroups = len(digits)
+	}
+	if rm1, err := share.Bech32(); err == nil {
+		c.Bech32 = len(rm1)
+	}
+
+	if c.QRCodes > 1 {
+		c.Warnings = append(c.Warnings, CapacityWarning{
+			Code:    "qr_split",
+			Count:   c.QRCodes,
+			Message: fmt.Sprintf("each piece's QR code is too dense to scan reliably, so it is printed over %d codes that must all be scanned; a shorter --recovery-url keeps it to one", c.QRCodes),
+		})
+	}
+	if total > word25MaxIndex && c.Words > 0 {
+		c.Warnings = append(c.Warnings, CapacityWarning{
+			Code:    "words_unnumbered",
+			Count:   word25MaxIndex,
+			Message: fmt.Sprintf("recovery words only record piece numbers up to %d; typed words of later pieces recover, but can't say whose they are", word25MaxIndex),
+		})
+	}
+	return c
+}
+
+// qrVersion is the smallest QR version that holds n bytes at medium error
+// correction, or 0 when none listed does.
+func qrVersion(n int) int {
+	for i, capacity := range qrBytesMedium {
+		if n <= capacity {
+			return i + 1
+		}
+	}
+	return 0
+}
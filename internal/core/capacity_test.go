@@ -0,0 +1,49 @@
+package core
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEstimateCapacity(t *testing.T) {
+	c := EstimateCapacity(5, 3, 0, "")
+	if c.QRCodes != 1 || c.QRVersion < 1 || c.QRVersion > 9 || c.QRModules != 17+4*c.QRVersion {
+		t.Errorf("5 pieces: QR %d codes, version %d, %d modules", c.QRCodes, c.QRVersion, c.QRModules)
+	}
+	if c.Words != 25 || c.DigitGroups != DigitGroups || c.Bech32 == 0 {
+		t.Errorf("5 pieces: %d words, %d digit groups, %d rm1", c.Words, c.DigitGroups, c.Bech32)
+	}
+	if len(c.Warnings) != 0 {
+		t.Errorf("5 pieces: unexpected warnings %v", c.Warnings)
+	}
+
+	// What seal prints for the last piece is what was measured
+	share := NewShare(2, 5, 5, 3, "", make([]byte, 33))
+	share.Bind("0123456789abcdef")
+	if c.Compact != len(share.CompactEncode()) {
+		t.Errorf("compact = %d, want %d", c.Compact, len(share.CompactEncode()))
+	}
+
+	tests := []struct {
+		name                     string
+		total, threshold, groups int
+		url                      string
+		warning                  string
+	}{
+		{"more pieces than words number", 16, 3, 0, "", "words_unnumbered"},
+		{"long recovery link", 5, 3, 0, "https://example.com/" + strings.Repeat("family/", 20) + "recover.html", "qr_split"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := EstimateCapacity(tt.total, tt.threshold, tt.groups, tt.url)
+			if len(c.Warnings) != 1 || c.Warnings[0].Code != tt.warning {
+				t.Errorf("got warnings %v, want %s", c.Warnings, tt.warning)
+			}
+		})
+	}
+
+	// Grouped pieces can't be typed as words, so there's nothing to warn about
+	if c := EstimateCapacity(20, 2, 3, ""); c.Words != 0 || c.Bech32 != 0 || len(c.Warnings) != 0 {
+		t.Errorf("grouped: %d words, %d rm1, warnings %v", c.Words, c.Bech32, c.Warnings)
+	}
+}
@@ -0,0 +1,131 @@
+package core
+
+import (
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Heirs often recover on a Mac or a Windows computer, whose file systems
+// don't tell "Notes.txt" from "notes.txt", and on a Mac not "café" written
+// with a combining accent from "café" without. An archive sealed on Linux
+// can hold both; extracted as they are, one would silently replace the
+// other. Seals refuse such names (CaseCollisions), and extraction renames
+// the later of two that collide anyway (CaseNames), the same way on every
+// system, so older archives still come back whole.
+
+// FoldName returns the key two slash-separated names share when a
+// case-insensitive file system would take them for the same one.
+func FoldName(name string) string {
+	return strings.ToLower(strings.ToUpper(norm.NFC.String(name)))
+}
+
+// CaseCollisions returns the groups of names, slash-separated with folders
+// ending in "/", that would land on the same file on a case-insensitive
+// file system, each group in the order given. Folders that differ only in
+// case merge there and lose nothing, so they don't count on their own; a
+// file that collides with a folder or another file does.
+func CaseCollisions(names []string) [][]string {
+	groups := make(map[string][]string)
+	var keys []string
+	for _, name := range names {
+		key := FoldName(strings.TrimSuffix(name, "/"))
+		if _, ok := groups[key]; !ok {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], name)
+	}
+	var collisions [][]string
+	for _, key := range keys {
+		group := groups[key]
+		files := 0
+		for _, name := range group {
+			if !strings.HasSuffix(name, "/") {
+				files++
+			}
+		}
+		distinct := slices.Compact(slices.Sorted(slices.Values(group)))
+		if files > 0 && len(distinct) > 1 {
+			collisions = append(collisions, group)
+		}
+	}
+	return collisions
+}
+
+// CaseNames places the entries of an archive being extracted so that none
+// collides with an earlier one on a case-insensitive file system. Feed it
+// every entry in archive order; its choices depend on nothing else.
+type CaseNames struct {
+	taken  map[string]placedName // FoldName of a placed name
+	placed map[string]string     // A name in the archive, "/" after a folder's, to where it was placed
+}
+
+// placedName is a name CaseNames gave out.
+type placedName struct {
+	name   string
+	folder bool
+}
+
+// NewCaseNames returns a CaseNames with nothing placed yet.
+func NewCaseNames() *CaseNames {
+	return &CaseNames{taken: make(map[string]placedName), placed: make(map[string]string)}
+}
+
+// Place returns where the entry name, slash-separated and ending in "/"
+// for a folder, is extracted to, and whether it had to be renamed. A folder
+// whose name differs only in case from an earlier folder's is merged into
+// it. A file, or a folder colliding with a file, is renamed by adding ~2,
+// ~3, and so on before its extension: Notes.txt becomes Notes~2.txt.
+// Everything under a renamed folder moves with it. The same name placed
+// twice gets the same answer, as extracting it twice overwrites.
+func (c *CaseNames) Place(name string) (string, bool) {
+	folder := strings.HasSuffix(name, "/")
+	parts := strings.Split(strings.TrimSuffix(name, "/"), "/")
+	placed := ""
+	for i, part := range parts {
+		isFolder := folder || i < len(parts)-1
+		original := strings.Join(parts[:i+1], "/")
+		if isFolder {
+			original += "/"
+		}
+		if to, ok := c.placed[original]; ok {
+			placed = to
+			continue
+		}
+		candidate := path.Join(placed, part)
+		if prev, ok := c.taken[FoldName(candidate)]; ok {
+			if prev.folder && isFolder {
+				candidate = prev.name
+			} else {
+				for n := 2; ; n++ {
+					candidate = path.Join(placed, numberedName(part, n, isFolder))
+					if _, ok := c.taken[FoldName(candidate)]; !ok {
+						break
+					}
+				}
+			}
+		}
+		c.taken[FoldName(candidate)] = placedName{name: candidate, folder: isFolder}
+		c.placed[original] = candidate
+		placed = candidate
+	}
+	if folder {
+		placed += "/"
+	}
+	return placed, FoldName(placed) != FoldName(name)
+}
+
+// numberedName adds ~n to name, before the extension of a file.
+func numberedName(name string, n int, folder bool) string {
+	ext := ""
+	if !folder {
+		ext = path.Ext(name)
+		if ext == name {
+			ext = "" // A dotfile, like .env, is all name
+		}
+	}
+	return fmt.Sprintf("%s~%d%s", strings.TrimSuffix(name, ext), n, ext)
+}
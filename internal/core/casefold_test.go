@@ -0,0 +1,62 @@
+package core
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCaseCollisions(t *testing.T) {
+	got := CaseCollisions([]string{
+		"manifest/",
+		"manifest/Notes.txt",
+		"manifest/notes.txt",
+		"manifest/Docs/", // Folders merge: not a collision
+		"manifest/docs/",
+		"manifest/docs/a.txt",
+		"manifest/Docs/b.txt",
+		"manifest/Photos", // A file and a folder
+		"manifest/photos/",
+		"manifest/cafe\u0301", // The same name, normalized differently
+		"manifest/café",
+		"manifest/unrelated.md",
+	})
+	want := [][]string{
+		{"manifest/Notes.txt", "manifest/notes.txt"},
+		{"manifest/Photos", "manifest/photos/"},
+		{"manifest/cafe\u0301", "manifest/café"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if got := CaseCollisions([]string{"manifest/a.txt", "manifest/b.txt"}); got != nil {
+		t.Errorf("distinct names: got %q", got)
+	}
+}
+
+func TestCaseNames(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"manifest/", "manifest/"},
+		{"manifest/Notes.txt", "manifest/Notes.txt"},
+		{"manifest/notes.txt", "manifest/notes~2.txt"},
+		{"manifest/NOTES.TXT", "manifest/NOTES~3.TXT"},
+		{"manifest/notes.txt", "manifest/notes~2.txt"}, // The same entry again
+		{"manifest/Docs/a.txt", "manifest/Docs/a.txt"},
+		{"manifest/docs/b.txt", "manifest/Docs/b.txt"}, // Folders merge
+		{"manifest/docs/A.txt", "manifest/Docs/A~2.txt"},
+		{"manifest/Photos", "manifest/Photos"},
+		{"manifest/photos/", "manifest/photos~2/"}, // A folder after a file of its name
+		{"manifest/photos/x.jpg", "manifest/photos~2/x.jpg"},
+		{"manifest/.env", "manifest/.env"},
+		{"manifest/.ENV", "manifest/.ENV~2"},
+	}
+	names := NewCaseNames()
+	for _, tt := range tests {
+		got, renamed := names.Place(tt.in)
+		if got != tt.want {
+			t.Errorf("Place(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+		if wantRenamed := FoldName(tt.want) != FoldName(tt.in); renamed != wantRenamed {
+			t.Errorf("Place(%q) renamed = %v, want %v", tt.in, renamed, wantRenamed)
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package core
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// A catalog lets fewer pieces than the threshold show what a seal holds —
+// file names, sizes, and the owner's description — without opening any of
+// it, so an executor can decide whether a full recovery is warranted. It's
+// a second layer with its own random key, split with a lower threshold than
+// the passphrase; each piece carries its share of that key in the PEM
+// "Catalog:" line. The catalog is encrypted under a passphrase derived from
+// the key, never under anything derived from the manifest's passphrase, so
+// opening it says nothing about the files themselves.
+
+// CatalogFile is the name of the encrypted catalog, next to MANIFEST.age.
+const CatalogFile = "CATALOG.age"
+
+const (
+	catalogKeySize = 32
+
+	// catalogWorkFactor is scrypt's log2(N) for the catalog. Its key is
+	// random, not chosen by a person, so slowing down guesses adds nothing.
+	catalogWorkFactor = 10
+)
+
+// catalogLabel separates the catalog passphrase from anything else derived
+// from the catalog key.
+var catalogLabel = []byte("rememory catalog v1")
+
+// Catalog is what a catalog shows: the sealed files, without their
+// contents.
+type Catalog struct {
+	Project     string         `json:"project"`
+	Description string         `json:"description,omitempty"`
+	Sealed      time.Time      `json:"sealed"`
+	Files       []CatalogEntry `json:"files"`
+}
+
+// CatalogEntry is one sealed file.
+type CatalogEntry struct {
+	Path string `json:"path"` // Slash-separated, as named in the archive
+	Size int64  `json:"size"`
+}
+
+// NewCatalogKey returns a random key for a seal's catalog.
+func NewCatalogKey() ([]byte, error) {
+	key := make([]byte, catalogKeySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, fmt.Errorf("generating catalog key: %w", err)
+	}
+	return key, nil
+}
+
+// catalogPassphrase derives the age passphrase the catalog is encrypted
+// with from its key.
+func catalogPassphrase(key []byte) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(catalogLabel)
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// EncryptCatalog encrypts c under key, for CATALOG.age.
+func EncryptCatalog(c *Catalog, key []byte) ([]byte, error) {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return nil, fmt.Errorf("encoding catalog: %w", err)
+	}
+	var buf bytes.Buffer
+	w, err := EncryptWriter(&buf, catalogPassphrase(key), catalogWorkFactor)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(data); err != nil {
+		return nil, fmt.Errorf("encrypting catalog: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return nil, fmt.Errorf("encrypting catalog: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// DecryptCatalog opens CATALOG.age with its key.
+func DecryptCatalog(data, key []byte) (*Catalog, error) {
+	plain, err := DecryptBytes(data, catalogPassphrase(key))
+	if err != nil {
+		return nil, fmt.Errorf("opening catalog: %w", err)
+	}
+	var c Catalog
+	if err := json.Unmarshal(plain, &c); err != nil {
+		return nil, fmt.Errorf("reading catalog: %w", err)
+	}
+	return &c, nil
+}
+
+// SplitCatalogKey splits key among a seal's pieces, threshold needed, and
+// records each piece's share of it. The pieces' indices needn't run from 1:
+// after 'rememory friend remove', the ones left keep theirs.
+func SplitCatalogKey(key []byte, threshold int, pieces []*Share) error {
+	parts, err := Split(key, len(pieces), threshold)
+	if err != nil {
+		return fmt.Errorf("splitting catalog key: %w", err)
+	}
+	for i, s := range pieces {
+		s.CatalogThreshold = threshold
+		s.CatalogData = parts[i]
+	}
+	return nil
+}
+
+// CombineCatalogKey recovers the catalog key from pieces that carry a share
+// of it. Pieces read from words, digits, or rm1 strings don't, and the same
+// piece given twice counts once.
+func CombineCatalogKey(pieces []*Share) ([]byte, error) {
+	threshold := 0
+	seen := make(map[int]bool)
+	var parts [][]byte
+	for _, s := range pieces {
+		if len(s.CatalogData) == 0 || seen[s.Index] {
+			continue
+		}
+		seen[s.Index] = true
+		threshold = s.CatalogThreshold
+		parts = append(parts, s.CatalogData)
+	}
+	switch {
+	case len(parts) == 0:
+		return nil, fmt.Errorf("these pieces don't carry a catalog (it's only in share files and README.txt, and only when the project has one)")
+	case len(parts) < threshold:
+		return nil, fmt.Errorf("the catalog needs %d pieces that carry it; got %d", threshold, len(parts))
+	}
+	key, err := Combine(parts)
+	if err != nil {
+		return nil, fmt.Errorf("combining catalog key: %w", err)
+	}
+	return key, nil
+}
@@ -0,0 +1,71 @@
+package core
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCatalog(t *testing.T) {
+	secret := []byte("the manifest passphrase")
+	data, err := Split(secret, 4, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pieces := make([]*Share, 4)
+	for i := range pieces {
+		pieces[i] = NewShare(2, i+1, 4, 3, "", data[i])
+	}
+
+	key, err := NewCatalogKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := SplitCatalogKey(key, 2, pieces); err != nil {
+		t.Fatal(err)
+	}
+	want := &Catalog{
+		Project:     "Family",
+		Description: "Accounts and the will",
+		Sealed:      time.Date(2026, 1, 2, 3, 4, 0, 0, time.UTC),
+		Files:       []CatalogEntry{{Path: "manifest/will.pdf", Size: 1234}},
+	}
+	encrypted, err := EncryptCatalog(want, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The catalog share survives the PEM form
+	parsed, err := ParseShare([]byte(pieces[3].Encode()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsed.CatalogThreshold != 2 || string(parsed.CatalogData) != string(pieces[3].CatalogData) {
+		t.Fatalf("parsed catalog = %d %x", parsed.CatalogThreshold, parsed.CatalogData)
+	}
+
+	got, err := CombineCatalogKey([]*Share{pieces[1], parsed})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := DecryptCatalog(encrypted, got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Project != want.Project || c.Description != want.Description || !c.Sealed.Equal(want.Sealed) || len(c.Files) != 1 || c.Files[0] != want.Files[0] {
+		t.Errorf("catalog = %+v", c)
+	}
+
+	// Fewer pieces than the catalog needs, counting the same piece once
+	if _, err := CombineCatalogKey([]*Share{pieces[0], pieces[0]}); err == nil || !strings.Contains(err.Error(), "needs 2 pieces") {
+		t.Errorf("one piece twice: err = %v", err)
+	}
+	if _, err := CombineCatalogKey([]*Share{NewShare(2, 1, 4, 3, "", data[0])}); err == nil || !strings.Contains(err.Error(), "don't carry a catalog") {
+		t.Errorf("no catalog: err = %v", err)
+	}
+
+	// The manifest's passphrase doesn't open the catalog
+	if _, err := DecryptCatalog(encrypted, secret); err == nil {
+		t.Error("catalog opened with the wrong key")
+	}
+}
@@ -0,0 +1,28 @@
+package core
+
+import "time"
+
+// deterministic is set by EnableDeterministic in test builds, and by
+// EnableReproducible.
+var deterministic bool
+
+// DeterministicTime is the fixed clock used in deterministic mode.
+var DeterministicTime = time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// fixedTime is the clock in deterministic mode: DeterministicTime, or the
+// time given to EnableReproducible.
+var fixedTime = DeterministicTime
+
+// Now returns the current UTC time, or the fixed clock in deterministic mode.
+// Use it for any timestamp that ends up in sealed output.
+func Now() time.Time {
+	if deterministic {
+		return fixedTime
+	}
+	return time.Now().UTC()
+}
+
+// Deterministic reports whether deterministic mode is active.
+func Deterministic() bool {
+	return deterministic
+}
@@ -6,6 +6,9 @@ import (
 	"compress/gzip"
 	"strings"
 	"testing"
+	"time"
+
+	"filippo.io/age"
 )
 
 func TestHashString(t *testing.T) {
@@ -127,6 +130,49 @@ func TestDecryptWrongPassphrase(t *testing.T) {
 	}
 }
 
+func TestEncryptToRecipients(t *testing.T) {
+	owner, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatal(err)
+	}
+	escrow, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stranger, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var encrypted bytes.Buffer
+	recipients, err := ParseRecipients([]string{owner.Recipient().String(), escrow.Recipient().String()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := EncryptToRecipients(&encrypted, strings.NewReader("passphrase"), recipients); err != nil {
+		t.Fatalf("encrypt: %v", err)
+	}
+
+	// Identities are tried in order; the second one matches
+	decrypted, err := DecryptWithIdentities(encrypted.Bytes(), []age.Identity{stranger, escrow})
+	if err != nil {
+		t.Fatalf("decrypt: %v", err)
+	}
+	if string(decrypted) != "passphrase" {
+		t.Errorf("got %q, want %q", decrypted, "passphrase")
+	}
+
+	if _, err := DecryptWithIdentities(encrypted.Bytes(), []age.Identity{stranger}); err == nil {
+		t.Error("expected error with an identity that isn't a recipient")
+	}
+	if _, err := ParseRecipients([]string{"age1notakey"}); err == nil {
+		t.Error("expected error for a malformed recipient")
+	}
+	if err := EncryptToRecipients(&encrypted, strings.NewReader("passphrase"), nil); err == nil {
+		t.Error("expected error with no recipients")
+	}
+}
+
 func TestSplitCombine(t *testing.T) {
 	secret := []byte("my-super-secret-passphrase")
 
@@ -165,6 +211,75 @@ func TestSplitCombine(t *testing.T) {
 	}
 }
 
+func TestSplitFrom(t *testing.T) {
+	secret := []byte("my-super-secret-passphrase")
+	seed := bytes.Repeat([]byte{7}, 200)
+
+	shares, err := SplitFrom(bytes.NewReader(seed), secret, 5, 3)
+	if err != nil {
+		t.Fatalf("split: %v", err)
+	}
+	again, err := SplitFrom(bytes.NewReader(seed), secret, 5, 3)
+	if err != nil {
+		t.Fatalf("split: %v", err)
+	}
+	for i := range shares {
+		if !bytes.Equal(shares[i], again[i]) {
+			t.Errorf("share %d differs with the same random bytes", i+1)
+		}
+		if x := shares[i][len(shares[i])-1]; x != byte(i+1) {
+			t.Errorf("share %d has x coordinate %d", i+1, x)
+		}
+	}
+
+	// Any threshold of them combine, as with Split's shares
+	recovered, err := Combine([][]byte{shares[4], shares[1], shares[2]})
+	if err != nil || string(recovered) != string(secret) {
+		t.Errorf("combine: got %q, %v", recovered, err)
+	}
+
+	if _, err := SplitFrom(bytes.NewReader(seed[:10]), secret, 5, 3); err == nil {
+		t.Error("expected an error when random runs out")
+	}
+}
+
+func TestExtend(t *testing.T) {
+	secret := []byte("my-super-secret-passphrase")
+
+	shares, err := Split(secret, 3, 2)
+	if err != nil {
+		t.Fatalf("split: %v", err)
+	}
+
+	extra, err := Extend(shares[:2])
+	if err != nil {
+		t.Fatalf("extend: %v", err)
+	}
+	for _, s := range shares {
+		if s[len(s)-1] == extra[len(extra)-1] {
+			t.Fatal("new share reuses an existing x coordinate")
+		}
+	}
+
+	// The new share works together with any one of the original shares
+	for i, s := range shares {
+		recovered, err := Combine([][]byte{extra, s})
+		if err != nil {
+			t.Fatalf("combine with share %d: %v", i+1, err)
+		}
+		if string(recovered) != string(secret) {
+			t.Errorf("combine with share %d: got %q, want %q", i+1, recovered, secret)
+		}
+	}
+
+	if _, err := Extend(shares[:1]); err == nil {
+		t.Error("expected error with a single share")
+	}
+	if _, err := Extend([][]byte{shares[0], shares[0]}); err == nil {
+		t.Error("expected error with duplicate shares")
+	}
+}
+
 func TestValidateShamirParams(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -224,6 +339,76 @@ func TestShareEncodeDecode(t *testing.T) {
 	}
 }
 
+func TestEncodeParseShares(t *testing.T) {
+	shares := []*Share{
+		NewShare(2, 1, 6, 3, "Alice", []byte("first-piece")),
+		NewShare(2, 6, 6, 3, "Alice", []byte("extra-piece")),
+	}
+
+	parsed, err := ParseShares([]byte("Hello\n\n" + EncodeShares(shares) + "\nBye\n"))
+	if err != nil {
+		t.Fatalf("ParseShares: %v", err)
+	}
+	if len(parsed) != 2 || parsed[0].Index != 1 || parsed[1].Index != 6 || string(parsed[1].Data) != "extra-piece" {
+		t.Fatalf("got %+v, want pieces 1 and 6", parsed)
+	}
+
+	// ParseShare still reads the first of them
+	first, err := ParseShare([]byte(EncodeShares(shares)))
+	if err != nil || first.Index != 1 {
+		t.Errorf("ParseShare: got %+v, %v", first, err)
+	}
+
+	if _, err := ParseShares([]byte("no shares here")); err == nil {
+		t.Error("expected an error for text without a share")
+	}
+}
+
+func TestShareWordListMetadata(t *testing.T) {
+	share := NewShare(2, 2, 3, 2, "Abuela", make([]byte, 33))
+	if strings.Contains(share.Encode(), "Words:") {
+		t.Error("English shares shouldn't record a word list")
+	}
+
+	share.WordList = LangES
+	encoded := share.Encode()
+	if !strings.Contains(encoded, "Words: es\n") {
+		t.Errorf("missing word list header:\n%s", encoded)
+	}
+	decoded, err := ParseShare([]byte(encoded))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decoded.WordList != LangES {
+		t.Errorf("word list: got %q, want %q", decoded.WordList, LangES)
+	}
+}
+
+func TestShareSunsetMetadata(t *testing.T) {
+	share := NewShare(2, 2, 3, 2, "Abuela", make([]byte, 33))
+	if encoded := share.Encode(); strings.Contains(encoded, "Review-By:") || strings.Contains(encoded, "Expires:") {
+		t.Error("shares without dates shouldn't record them")
+	}
+
+	share.ReviewBy = time.Date(2030, 1, 31, 0, 0, 0, 0, time.UTC)
+	share.Expires = time.Date(2031, 1, 31, 0, 0, 0, 0, time.UTC)
+	encoded := share.Encode()
+	if !strings.Contains(encoded, "Review-By: 2030-01-31\n") || !strings.Contains(encoded, "Expires: 2031-01-31\n") {
+		t.Errorf("missing date headers:\n%s", encoded)
+	}
+	decoded, err := ParseShare([]byte(encoded))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !decoded.ReviewBy.Equal(share.ReviewBy) || !decoded.Expires.Equal(share.Expires) {
+		t.Errorf("dates: got %v and %v", decoded.ReviewBy, decoded.Expires)
+	}
+
+	if _, err := ParseShare([]byte(strings.Replace(encoded, "2031-01-31", "soon", 1))); err == nil {
+		t.Error("expected an error for a bad expiry date")
+	}
+}
+
 func TestShareVerify(t *testing.T) {
 	share := NewShare(1, 1, 5, 3, "Alice", []byte("test-data"))
 
@@ -403,13 +588,13 @@ func createTarGz(t *testing.T, entries map[string]string) []byte {
 	gzw := gzip.NewWriter(&buf)
 	tw := tar.NewWriter(gzw)
 
+	// Names ending in "/" are written as folders
 	for name, content := range entries {
-		if err := tw.WriteHeader(&tar.Header{
-			Name:     name,
-			Size:     int64(len(content)),
-			Mode:     0644,
-			Typeflag: tar.TypeReg,
-		}); err != nil {
+		header := &tar.Header{Name: name, Size: int64(len(content)), Mode: 0644, Typeflag: tar.TypeReg}
+		if strings.HasSuffix(name, "/") {
+			header = &tar.Header{Name: name, Mode: 0755, Typeflag: tar.TypeDir}
+		}
+		if err := tw.WriteHeader(header); err != nil {
 			t.Fatalf("writing tar header for %q: %v", name, err)
 		}
 		if _, err := tw.Write([]byte(content)); err != nil {
@@ -486,6 +671,36 @@ func TestExtractTarGzPathTraversal(t *testing.T) {
 		}
 	})
 
+	t.Run("empty folders", func(t *testing.T) {
+		data := createTarGz(t, map[string]string{
+			"manifest/":            "",
+			"manifest/notes/":      "",
+			"manifest/notes/a.txt": "",
+			"manifest/photos/":     "",
+		})
+		files, err := ExtractTarGz(data)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		got := make(map[string]bool)
+		for _, f := range files {
+			got[f.Name] = f.Dir
+		}
+		if len(got) != 2 || got["manifest/notes/a.txt"] || !got["manifest/photos/"] {
+			t.Errorf("got %v, want the empty file and only the empty folder", got)
+		}
+	})
+
+	t.Run("only an empty folder", func(t *testing.T) {
+		files, err := ExtractTarGz(createTarGz(t, map[string]string{"manifest/": ""}))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(files) != 1 || !files[0].Dir {
+			t.Errorf("got %+v, want the folder", files)
+		}
+	})
+
 	t.Run("empty input", func(t *testing.T) {
 		_, err := ExtractTarGz([]byte{})
 		if err == nil {
@@ -531,3 +746,68 @@ func TestSanitizeFilename(t *testing.T) {
 		}
 	}
 }
+
+func TestInspectManifest(t *testing.T) {
+	var encrypted bytes.Buffer
+	if err := Encrypt(&encrypted, strings.NewReader("secret"), "test-passphrase-12345"); err != nil {
+		t.Fatalf("encrypt: %v", err)
+	}
+
+	info, err := InspectManifest(bytes.NewReader(encrypted.Bytes()))
+	if err != nil {
+		t.Fatalf("InspectManifest: %v", err)
+	}
+	if len(info.Recipients) != 1 || info.Recipients[0] != "scrypt" {
+		t.Errorf("recipients = %v, want [scrypt]", info.Recipients)
+	}
+	if info.WorkFactor < 10 {
+		t.Errorf("unexpectedly low scrypt work factor: %d", info.WorkFactor)
+	}
+}
+
+func TestInspectManifestRejectsNonAge(t *testing.T) {
+	inputs := []string{
+		"",
+		"hello world\n",
+		"age-encryption.org/v1\n-> scrypt abc 18\n", // truncated before the MAC line
+	}
+	for _, in := range inputs {
+		if _, err := InspectManifest(strings.NewReader(in)); err == nil {
+			t.Errorf("InspectManifest(%q) should fail", in)
+		}
+	}
+}
+
+func TestRestrictedProfile(t *testing.T) {
+	var encrypted bytes.Buffer
+	if err := Encrypt(&encrypted, strings.NewReader("secret"), "test-passphrase-12345"); err != nil {
+		t.Fatalf("encrypt: %v", err)
+	}
+	if err := CheckRestrictedManifest(encrypted.Bytes()); err != nil {
+		t.Errorf("Encrypt output rejected: %v", err)
+	}
+
+	for name, header := range map[string]string{
+		"x25519":      "age-encryption.org/v1\n-> X25519 abc\nbody\n--- mac\n",
+		"two":         "age-encryption.org/v1\n-> scrypt salt 18\nbody\n-> X25519 abc\nbody\n--- mac\n",
+		"weak scrypt": "age-encryption.org/v1\n-> scrypt salt 10\nbody\n--- mac\n",
+	} {
+		if err := CheckRestrictedManifest([]byte(header)); err == nil {
+			t.Errorf("%s: accepted", name)
+		}
+	}
+
+	if err := CheckRestrictedShare(&Share{Version: 2}); err != nil {
+		t.Errorf("v2 share rejected: %v", err)
+	}
+	if err := CheckRestrictedShare(&Share{Version: 1}); err == nil {
+		t.Error("v1 share accepted")
+	}
+
+	if err := ValidCryptoProfile(""); err != nil {
+		t.Error(err)
+	}
+	if err := ValidCryptoProfile("fips"); err == nil {
+		t.Error("unknown profile accepted")
+	}
+}
@@ -0,0 +1,26 @@
+//go:build deterministic
+
+package core
+
+import (
+	cryptorand "crypto/rand"
+	"crypto/sha256"
+	"fmt"
+	"math/rand/v2"
+)
+
+// EnableDeterministic replaces every source of randomness and the clock with
+// fixed values derived from seed, so sealing the same inputs twice produces
+// identical bytes. It is only compiled into builds with the "deterministic"
+// tag and must never be used for real secrets: anyone with the seed can
+// reproduce the passphrase.
+func EnableDeterministic(seed string) error {
+	if seed == "" {
+		return fmt.Errorf("deterministic seed cannot be empty")
+	}
+	// crypto/rand.Read (used by age, vault/shamir, and rememory itself)
+	// reads from Reader whenever it has been replaced.
+	cryptorand.Reader = rand.NewChaCha8(sha256.Sum256([]byte(seed)))
+	deterministic = true
+	return nil
+}
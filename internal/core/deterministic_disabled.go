@@ -0,0 +1,11 @@
+//go:build !deterministic
+
+package core
+
+import "fmt"
+
+// EnableDeterministic is unavailable in regular builds. Build with
+// -tags deterministic to get reproducible output for tests and fixtures.
+func EnableDeterministic(seed string) error {
+	return fmt.Errorf("deterministic mode is only available in test builds (go build -tags deterministic)")
+}
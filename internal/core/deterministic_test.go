@@ -0,0 +1,58 @@
+//go:build deterministic
+
+package core
+
+import (
+	"bytes"
+	cryptorand "crypto/rand"
+	"testing"
+)
+
+func TestDeterministicMode(t *testing.T) {
+	original := cryptorand.Reader
+	t.Cleanup(func() {
+		cryptorand.Reader = original
+		deterministic = false
+	})
+
+	run := func() ([][]byte, []byte) {
+		if err := EnableDeterministic("fixture-seed"); err != nil {
+			t.Fatalf("EnableDeterministic: %v", err)
+		}
+		shares, err := Split([]byte("correct-horse-battery-staple"), 5, 3)
+		if err != nil {
+			t.Fatalf("Split: %v", err)
+		}
+		var encrypted bytes.Buffer
+		if err := Encrypt(&encrypted, bytes.NewReader([]byte("secret")), "passphrase"); err != nil {
+			t.Fatalf("Encrypt: %v", err)
+		}
+		return shares, encrypted.Bytes()
+	}
+
+	shares1, enc1 := run()
+	shares2, enc2 := run()
+
+	for i := range shares1 {
+		if !bytes.Equal(shares1[i], shares2[i]) {
+			t.Errorf("share %d differs between runs", i+1)
+		}
+		if x := shares1[i][len(shares1[i])-1]; x != byte(i+1) {
+			t.Errorf("share %d has x coordinate %d, want %d", i+1, x, i+1)
+		}
+	}
+	if !bytes.Equal(enc1, enc2) {
+		t.Error("encrypted output differs between runs")
+	}
+	if !Now().Equal(DeterministicTime) {
+		t.Errorf("Now() = %v, want %v", Now(), DeterministicTime)
+	}
+
+	recovered, err := Combine([][]byte{shares1[4], shares1[0], shares1[2]})
+	if err != nil {
+		t.Fatalf("Combine: %v", err)
+	}
+	if string(recovered) != "correct-horse-battery-staple" {
+		t.Errorf("recovered %q", recovered)
+	}
+}
@@ -0,0 +1,153 @@
+package core
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Digit encoding, for reading a piece over the phone: 18 groups of six
+// digits.
+//
+//	groups 1–16   data bytes 0–31, two per group
+//	group 17      data byte 32 and the share index
+//	group 18      16 bits of SHA-256(data ‖ index)
+//
+// Each group's first five digits are a 16-bit value (00000–65535). The
+// sixth is a Damm check digit over the group's number and those five
+// digits, so a misheard digit, two swapped digits, or a group read in the
+// wrong place is caught as soon as that group is typed.
+const (
+	DigitGroups    = 18
+	digitGroupSize = 6
+	digitDataBytes = 33
+)
+
+// dammTable is the order-10 quasigroup from H. Michael Damm's check digit
+// algorithm: it catches every single-digit error and every swap of two
+// neighbouring digits.
+var dammTable = [10][10]byte{
+	{0, 3, 1, 7, 5, 9, 8, 6, 4, 2},
+	{7, 0, 9, 2, 1, 5, 4, 8, 6, 3},
+	{4, 2, 0, 6, 8, 7, 1, 3, 5, 9},
+	{1, 7, 5, 0, 9, 8, 3, 4, 2, 6},
+	{6, 1, 2, 3, 0, 4, 5, 9, 7, 8},
+	{3, 6, 7, 4, 2, 0, 9, 5, 8, 1},
+	{5, 8, 6, 9, 7, 2, 0, 1, 3, 4},
+	{8, 9, 4, 5, 3, 6, 2, 0, 1, 7},
+	{9, 4, 3, 8, 6, 1, 7, 2, 0, 5},
+	{2, 5, 8, 1, 4, 3, 6, 7, 9, 0},
+}
+
+// dammDigit returns the check digit for a string of digits.
+func dammDigit(digits string) byte {
+	var interim byte
+	for _, c := range digits {
+		interim = dammTable[interim][c-'0']
+	}
+	return '0' + interim
+}
+
+// Digits returns this share as 18 groups of six digits. Shares numbered
+// above 255 are written with index 0, as the 25th word does above 15.
+func (s *Share) Digits() ([]string, error) {
+	if s.Version < 2 {
+		return nil, fmt.Errorf("digit encoding requires share version 2 or later (got v%d)", s.Version)
+	}
+	if s.Groups > 0 {
+		return nil, errGroupedShare
+	}
+	if s.Locked() {
+		return nil, errLockedShare
+	}
+	if s.Secret {
+		return nil, errSecretShare
+	}
+	if len(s.Data) != digitDataBytes {
+		return nil, fmt.Errorf("digit encoding needs %d bytes of share data, got %d", digitDataBytes, len(s.Data))
+	}
+	index := s.Index
+	if index < 0 || index > 255 {
+		index = 0
+	}
+
+	values := make([]int, 0, DigitGroups)
+	for i := 0; i < 32; i += 2 {
+		values = append(values, int(s.Data[i])<<8|int(s.Data[i+1]))
+	}
+	values = append(values, int(s.Data[32])<<8|index, digitsChecksum(s.Data, index))
+
+	groups := make([]string, DigitGroups)
+	for i, v := range values {
+		body := fmt.Sprintf("%05d", v)
+		groups[i] = body + string(dammDigit(fmt.Sprintf("%02d", i+1)+body))
+	}
+	return groups, nil
+}
+
+// digitsChecksum is the first two bytes of SHA-256 over the data and index.
+func digitsChecksum(data []byte, index int) int {
+	h := sha256.Sum256(append(append([]byte(nil), data...), byte(index)))
+	return int(h[0])<<8 | int(h[1])
+}
+
+// DecodeShareDigits reads the 108 digits of a share, grouped or not, and
+// returns its data and index. Spaces, dashes, and line breaks are ignored.
+// Returns index=0 if the share was numbered above 255.
+func DecodeShareDigits(text string) (data []byte, index int, err error) {
+	var digits strings.Builder
+	for _, c := range text {
+		switch {
+		case c >= '0' && c <= '9':
+			digits.WriteRune(c)
+		case c == ' ' || c == '-' || c == '\n' || c == '\r' || c == '\t':
+		default:
+			return nil, 0, fmt.Errorf("%q isn't a digit", c)
+		}
+	}
+	all := digits.String()
+	if len(all) != DigitGroups*digitGroupSize {
+		return nil, 0, fmt.Errorf("expected %d digits, got %d", DigitGroups*digitGroupSize, len(all))
+	}
+
+	values := make([]int, DigitGroups)
+	for i := range values {
+		group := all[i*digitGroupSize : (i+1)*digitGroupSize]
+		if dammDigit(fmt.Sprintf("%02d", i+1)+group) != '0' {
+			return nil, 0, fmt.Errorf("group %d (%s) doesn't check out — a digit may be misheard, or the group is out of order", i+1, group)
+		}
+		v, _ := strconv.Atoi(group[:digitGroupSize-1])
+		if v > 0xffff {
+			return nil, 0, fmt.Errorf("group %d (%s) is out of range", i+1, group)
+		}
+		values[i] = v
+	}
+
+	data = make([]byte, 0, digitDataBytes)
+	for _, v := range values[:16] {
+		data = append(data, byte(v>>8), byte(v))
+	}
+	data = append(data, byte(values[16]>>8))
+	index = values[16] & 0xff
+	if digitsChecksum(data, index) != values[17] {
+		return nil, 0, fmt.Errorf("digits don't match their checksum; a group may be wrong")
+	}
+	return data, index, nil
+}
+
+// LooksLikeShareDigits reports whether text is only digits and separators,
+// with enough digits to be meant as a share.
+func LooksLikeShareDigits(text string) bool {
+	count := 0
+	for _, c := range strings.TrimSpace(text) {
+		switch {
+		case c >= '0' && c <= '9':
+			count++
+		case c == ' ' || c == '-' || c == '\n' || c == '\r' || c == '\t':
+		default:
+			return false
+		}
+	}
+	return count >= DigitGroups*digitGroupSize/2
+}
@@ -0,0 +1,109 @@
+package core
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestDammDigit(t *testing.T) {
+	// The worked example from Damm's algorithm: 572 gets check digit 4
+	if got := dammDigit("572"); got != '4' {
+		t.Errorf("check digit for 572 = %c, want 4", got)
+	}
+	if got := dammDigit("5724"); got != '0' {
+		t.Errorf("5724 doesn't validate: %c", got)
+	}
+}
+
+func testDigitShare(t *testing.T, index int) *Share {
+	t.Helper()
+	shares, err := Split(bytes.Repeat([]byte{0x42}, 32), 3, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewShare(2, index, 3, 2, "Alice", shares[0])
+}
+
+func TestShareDigitsRoundTrip(t *testing.T) {
+	share := testDigitShare(t, 7)
+	groups, err := share.Digits()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(groups) != DigitGroups {
+		t.Fatalf("got %d groups", len(groups))
+	}
+	for i, g := range groups {
+		if len(g) != 6 || strings.Trim(g, "0123456789") != "" {
+			t.Errorf("group %d = %q", i+1, g)
+		}
+	}
+
+	for _, text := range []string{
+		strings.Join(groups, " "),
+		strings.Join(groups, ""),
+		strings.Join(groups[:9], "-") + "\n" + strings.Join(groups[9:], "-") + "\n",
+	} {
+		data, index, err := DecodeShareDigits(text)
+		if err != nil {
+			t.Fatalf("%q: %v", text, err)
+		}
+		if !bytes.Equal(data, share.Data) || index != 7 {
+			t.Errorf("got index %d, data %x", index, data)
+		}
+	}
+	if !LooksLikeShareDigits(strings.Join(groups, " ")) {
+		t.Error("LooksLikeShareDigits")
+	}
+}
+
+func TestShareDigitsCatchMistakes(t *testing.T) {
+	groups, err := testDigitShare(t, 2).Digits()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Every single misheard digit is caught by its group
+	for i := range groups {
+		for pos := 0; pos < 6; pos++ {
+			wrong := append([]string(nil), groups...)
+			b := []byte(wrong[i])
+			b[pos] = '0' + (b[pos]-'0'+3)%10
+			wrong[i] = string(b)
+			if _, _, err := DecodeShareDigits(strings.Join(wrong, " ")); err == nil {
+				t.Errorf("group %d digit %d: mistake not caught", i+1, pos+1)
+			}
+		}
+	}
+
+	// Two groups read in the wrong order
+	swapped := append([]string(nil), groups...)
+	swapped[3], swapped[4] = swapped[4], swapped[3]
+	if _, _, err := DecodeShareDigits(strings.Join(swapped, " ")); err == nil {
+		t.Error("swapped groups not caught")
+	}
+
+	if _, _, err := DecodeShareDigits(strings.Join(groups[1:], " ")); err == nil || !strings.Contains(err.Error(), "expected 108 digits") {
+		t.Errorf("missing group: %v", err)
+	}
+	if _, _, err := DecodeShareDigits("abc " + strings.Join(groups, " ")); err == nil {
+		t.Error("letters accepted")
+	}
+}
+
+func TestShareDigitsLargeIndex(t *testing.T) {
+	groups, err := testDigitShare(t, 300).Digits()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, index, err := DecodeShareDigits(strings.Join(groups, " ")); err != nil || index != 0 {
+		t.Errorf("index %d, %v", index, err)
+	}
+
+	v1 := testDigitShare(t, 1)
+	v1.Version = 1
+	if _, err := v1.Digits(); err == nil {
+		t.Error("expected an error for a v1 share")
+	}
+}
@@ -0,0 +1,220 @@
+package core
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// The EFF's diceware lists aren't BIP39 lists: they hold 7,776 and 1,296
+// words, one for each roll of five or four dice. Recovery words from them
+// carry the same share as the 25 BIP39 words, with the 25th word's index and
+// checksum packed in at the end: the long list's common English words take
+// 22 words, and the short list's 27 words each start with three letters no
+// other word starts with, so those three are enough to type.
+
+const (
+	WordListEFFLong  Lang = "eff-long"
+	WordListEFFShort Lang = "eff-short"
+)
+
+// EFFLists returns the EFF word lists, after the BIP39 ones in messages.
+func EFFLists() []Lang {
+	return []Lang{WordListEFFLong, WordListEFFShort}
+}
+
+// effShareBytes is the share data the EFF words carry: the 33 bytes the 24
+// BIP39 data words hold.
+const effShareBytes = 33
+
+// effListSpecs defines the source and expected hash of each EFF list. The
+// files are vendored as published, dice rolls included.
+//
+// https://www.eff.org/deeplinks/2016/07/new-wordlists-random-passphrases
+var effListSpecs = []wordListSpec{
+	{WordListEFFLong, "wordlists/eff_large_wordlist.txt", "https://www.eff.org/files/2016/07/18/eff_large_wordlist.txt", "addd35536511597a02fa0a9ff1e5284677b8883b83e986e43f15a3db996b903e"},
+	{WordListEFFShort, "wordlists/eff_short_wordlist_2_0.txt", "https://www.eff.org/files/2016/09/08/eff_short_wordlist_2_0.txt", "22b45c52e0bd0bbf03aa522240b111eb4c7c0c1d86c4e518e1be2a7eb2a625e4"},
+}
+
+// effList is a loaded EFF list.
+type effList struct {
+	ID     Lang
+	Words  []string
+	Count  int            // Words a share takes
+	Prefix int            // Letters that tell every word apart; 0 when whole words are needed
+	index  map[string]int // Lowercase word, and the word without hyphens ("tshirt"), → index
+}
+
+var (
+	effRegistry map[Lang]*effList
+	effOnce     sync.Once
+)
+
+func initEFFLists() {
+	effOnce.Do(func() {
+		effRegistry = make(map[Lang]*effList, len(effListSpecs))
+		for _, spec := range effListSpecs {
+			data, err := wordlistFS.ReadFile(spec.File)
+			if err != nil {
+				panic(fmt.Sprintf("loading word list %s: %v", spec.Lang, err))
+			}
+			l := &effList{ID: spec.Lang, index: make(map[string]int)}
+			for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+				_, word, ok := strings.Cut(line, "\t")
+				if !ok {
+					panic(fmt.Sprintf("word list %s: line %q has no dice roll", spec.Lang, line))
+				}
+				l.index[word] = len(l.Words)
+				l.Words = append(l.Words, word)
+			}
+			for i, w := range l.Words {
+				// "yoyo" and "yo-yo" are both on the long list
+				if plain := strings.ReplaceAll(w, "-", ""); plain != w {
+					if _, taken := l.index[plain]; !taken {
+						l.index[plain] = i
+					}
+				}
+			}
+			l.Count = effWordCount(len(l.Words))
+			if uniquePrefixes(l.Words, 3) {
+				l.Prefix = 3
+			}
+			effRegistry[spec.Lang] = l
+		}
+	})
+}
+
+// getEFFList returns the EFF list id names, or nil.
+func getEFFList(id Lang) *effList {
+	initEFFLists()
+	return effRegistry[id]
+}
+
+// effListForCount returns the EFF list whose shares take count words, or nil.
+func effListForCount(count int) *effList {
+	initEFFLists()
+	for _, id := range EFFLists() {
+		if l := effRegistry[id]; l.Count == count {
+			return l
+		}
+	}
+	return nil
+}
+
+// effWordCount returns how many words from a list of size words hold the
+// share data and the 11 bits of index and checksum.
+func effWordCount(size int) int {
+	limit := new(big.Int).Lsh(big.NewInt(1), effShareBytes*8+word25IndexBits+word25CheckBits)
+	n, capacity := 0, big.NewInt(1)
+	for capacity.Cmp(limit) < 0 {
+		capacity.Mul(capacity, big.NewInt(int64(size)))
+		n++
+	}
+	return n
+}
+
+// uniquePrefixes reports whether no two words start with the same n letters.
+func uniquePrefixes(words []string, n int) bool {
+	seen := make(map[string]bool, len(words))
+	for _, w := range words {
+		if len(w) < n || seen[w[:n]] {
+			return false
+		}
+		seen[w[:n]] = true
+	}
+	return true
+}
+
+// lookup finds a word's index: the word itself, without its hyphen, or, on
+// a list whose words start differently, its first letters.
+func (l *effList) lookup(word string) (int, bool) {
+	word = norm.NFC.String(strings.ToLower(strings.TrimSpace(word)))
+	if i, ok := l.index[word]; ok {
+		return i, true
+	}
+	if l.Prefix > 0 && len(word) >= l.Prefix {
+		for i, w := range l.Words {
+			if strings.HasPrefix(w, word) {
+				return i, true
+			}
+		}
+	}
+	return 0, false
+}
+
+// encode returns the share as words from the list: data and the 11 bits of
+// index and checksum read as one number, written in base len(Words).
+func (l *effList) encode(index int, data []byte) []string {
+	value := new(big.Int).SetBytes(data)
+	value.Lsh(value, word25IndexBits+word25CheckBits)
+	value.Or(value, big.NewInt(int64(word25Encode(index, data))))
+
+	base := big.NewInt(int64(len(l.Words)))
+	digit := new(big.Int)
+	words := make([]string, l.Count)
+	for i := l.Count - 1; i >= 0; i-- {
+		value.DivMod(value, base, digit)
+		words[i] = l.Words[digit.Int64()]
+	}
+	return words
+}
+
+// decode reads share data and index back from words, checking the checksum.
+func (l *effList) decode(words []string) ([]byte, int, error) {
+	if len(words) != l.Count {
+		return nil, 0, fmt.Errorf("expected %d words from the %s list, got %d", l.Count, l.ID, len(words))
+	}
+	base := big.NewInt(int64(len(l.Words)))
+	value := new(big.Int)
+	for i, w := range words {
+		idx, ok := l.lookup(w)
+		if !ok {
+			if s := SuggestWordLang(w, l.ID); s != "" {
+				return nil, 0, fmt.Errorf("word %d %q not recognized — did you mean %q?", i+1, w, s)
+			}
+			return nil, 0, fmt.Errorf("word %d %q not recognized", i+1, w)
+		}
+		value.Mul(value, base)
+		value.Add(value, big.NewInt(int64(idx)))
+	}
+
+	bits := effShareBytes*8 + word25IndexBits + word25CheckBits
+	if value.BitLen() > bits {
+		return nil, 0, fmt.Errorf("word checksum failed — check word order and spelling")
+	}
+	last := int(new(big.Int).And(value, big.NewInt(1<<(word25IndexBits+word25CheckBits)-1)).Int64())
+	value.Rsh(value, word25IndexBits+word25CheckBits)
+	data := value.FillBytes(make([]byte, effShareBytes))
+
+	index, expectedCheck := word25Decode(last)
+	if word25Checksum(data) != expectedCheck {
+		return nil, 0, fmt.Errorf("word checksum failed — check word order and spelling")
+	}
+	return data, index, nil
+}
+
+// EFFListHash returns the SHA-256 of an EFF list's file as vendored, which
+// must match the file the EFF publishes.
+func EFFListHash(id Lang) string {
+	for _, spec := range effListSpecs {
+		if spec.Lang == id {
+			data, err := wordlistFS.ReadFile(spec.File)
+			if err != nil {
+				return ""
+			}
+			h := sha256.Sum256(data)
+			return hex.EncodeToString(h[:])
+		}
+	}
+	return ""
+}
+
+// GetEFFListSpecs returns the spec metadata for the EFF lists (for testing).
+func GetEFFListSpecs() []wordListSpec {
+	return effListSpecs
+}
@@ -160,7 +160,7 @@ func TestGenerateGoldenFixtures(t *testing.T) {
 	if err != nil {
 		t.Fatalf("combining v2 shares: %v", err)
 	}
-	if RecoverPassphrase(recoveredV2, 2) != goldenPassphrase {
+	if string(RecoverPassphrase(recoveredV2, 2)) != goldenPassphrase {
 		t.Fatal("v2 share reconstruction failed — shares are broken")
 	}
 
@@ -374,7 +374,7 @@ func TestGoldenCombine(t *testing.T) {
 				t.Fatalf("Combine: %v", err)
 			}
 
-			passphrase := RecoverPassphrase(recovered, golden.Version)
+			passphrase := string(RecoverPassphrase(recovered, golden.Version))
 			if passphrase != golden.Passphrase {
 				t.Errorf("passphrase: got %q, want %q", passphrase, golden.Passphrase)
 			}
@@ -417,7 +417,7 @@ func TestGoldenCombineAllSubsets(t *testing.T) {
 						t.Fatalf("Combine: %v", err)
 					}
 
-					passphrase := RecoverPassphrase(recovered, golden.Version)
+					passphrase := string(RecoverPassphrase(recovered, golden.Version))
 					if passphrase != golden.Passphrase {
 						t.Errorf("passphrase: got %q, want %q", passphrase, golden.Passphrase)
 					}
@@ -460,7 +460,7 @@ func TestGoldenCombineBelowThreshold(t *testing.T) {
 							return
 						}
 
-						passphrase := RecoverPassphrase(recovered, golden.Version)
+						passphrase := string(RecoverPassphrase(recovered, golden.Version))
 						if passphrase == golden.Passphrase {
 							t.Errorf("below-threshold subset recovered the passphrase")
 						}
@@ -503,7 +503,7 @@ func TestGoldenDecrypt(t *testing.T) {
 				t.Fatalf("Combine: %v", err)
 			}
 
-			passphrase := RecoverPassphrase(recovered, golden.Version)
+			passphrase := string(RecoverPassphrase(recovered, golden.Version))
 			if passphrase != golden.Passphrase {
 				t.Fatalf("passphrase mismatch: got %q, want %q", passphrase, golden.Passphrase)
 			}
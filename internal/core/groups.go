@@ -0,0 +1,204 @@
+package core
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Grouped sharing layers two Shamir splits, for policies like "2 of the 3
+// family members and 1 of the 2 lawyers". The secret is first split into one
+// part per group, any GroupThreshold of which rebuild it; each group's part
+// is then split among its members, any Threshold of whom rebuild the part.
+//
+// A threshold of 1 is allowed at either level: every piece of that level is
+// then the secret itself, tagged with its own x coordinate, as a
+// constant polynomial would give.
+
+// errGroupedShare is returned for the forms that can't record a piece's
+// group: recovery words, digit groups, and rm1 strings.
+var errGroupedShare = fmt.Errorf("pieces of a grouped project can't be written in this form, which doesn't record the group")
+
+// GroupSpec is one group of a grouped split: Size pieces, any Threshold of
+// which rebuild the group's part.
+type GroupSpec struct {
+	Size      int
+	Threshold int
+}
+
+// ValidateGroupParams checks a grouped split: groupThreshold of the groups
+// are needed, and each group needs its own threshold of pieces.
+func ValidateGroupParams(groupThreshold int, groups []GroupSpec) error {
+	if len(groups) < 2 {
+		return fmt.Errorf("need at least 2 groups, got %d", len(groups))
+	}
+	if len(groups) > 255 {
+		return fmt.Errorf("maximum 255 groups supported, got %d", len(groups))
+	}
+	if groupThreshold < 1 || groupThreshold > len(groups) {
+		return fmt.Errorf("groups needed must be between 1 and %d, got %d", len(groups), groupThreshold)
+	}
+	for i, g := range groups {
+		if g.Size < 1 || g.Size > 255 {
+			return fmt.Errorf("group %d: must have between 1 and 255 pieces, got %d", i+1, g.Size)
+		}
+		if g.Threshold < 1 || g.Threshold > g.Size {
+			return fmt.Errorf("group %d: threshold must be between 1 and %d, got %d", i+1, g.Size, g.Threshold)
+		}
+	}
+	if MinGroupPieces(groupThreshold, groups) < 2 {
+		return fmt.Errorf("a single piece would be enough to recover; raise a group's threshold or the number of groups needed")
+	}
+	return nil
+}
+
+// MinGroupPieces is the fewest pieces that can recover a grouped split: the
+// smallest groupThreshold group thresholds, added up.
+func MinGroupPieces(groupThreshold int, groups []GroupSpec) int {
+	thresholds := make([]int, len(groups))
+	for i, g := range groups {
+		thresholds[i] = g.Threshold
+	}
+	sort.Ints(thresholds)
+	total := 0
+	for _, k := range thresholds[:min(groupThreshold, len(thresholds))] {
+		total += k
+	}
+	return total
+}
+
+// SplitGroups splits secret for a grouped policy. It returns the pieces of
+// each group, in the order the groups were given.
+func SplitGroups(secret []byte, groupThreshold int, groups []GroupSpec) ([][][]byte, error) {
+	if err := ValidateGroupParams(groupThreshold, groups); err != nil {
+		return nil, err
+	}
+	parts, err := splitAny(secret, len(groups), groupThreshold)
+	if err != nil {
+		return nil, err
+	}
+	pieces := make([][][]byte, len(groups))
+	for i, g := range groups {
+		if pieces[i], err = splitAny(parts[i], g.Size, g.Threshold); err != nil {
+			return nil, fmt.Errorf("group %d: %w", i+1, err)
+		}
+	}
+	return pieces, nil
+}
+
+// CombineGroups rebuilds the secret from the pieces of complete groups: each
+// entry holds at least its group's threshold of pieces, and there are at
+// least as many entries as groups needed. Like Combine, too few pieces give
+// garbage rather than an error.
+func CombineGroups(groups [][][]byte) ([]byte, error) {
+	if len(groups) == 0 {
+		return nil, fmt.Errorf("no groups given")
+	}
+	// Each group's part is itself a piece of the secret
+	parts := make([][]byte, len(groups))
+	defer func() { Wipe(parts...) }()
+	for i, pieces := range groups {
+		part, err := combineAny(pieces)
+		if err != nil {
+			return nil, fmt.Errorf("group %d: %w", i+1, err)
+		}
+		parts[i] = part
+	}
+	return combineAny(parts)
+}
+
+// splitAny is Split that also allows a threshold of 1.
+func splitAny(secret []byte, n, k int) ([][]byte, error) {
+	if k != 1 {
+		return Split(secret, n, k)
+	}
+	if n > 255 {
+		return nil, fmt.Errorf("maximum 255 shares supported, got %d", n)
+	}
+	shares := make([][]byte, n)
+	for i := range shares {
+		shares[i] = append(append([]byte{}, secret...), byte(i+1))
+	}
+	return shares, nil
+}
+
+// combineAny is Combine that also accepts the single piece a threshold of 1
+// needs. The result is always a new slice, so it can be wiped on its own.
+func combineAny(shares [][]byte) ([]byte, error) {
+	if len(shares) == 1 {
+		if len(shares[0]) < 2 {
+			return nil, fmt.Errorf("shares must be at least two bytes")
+		}
+		return append([]byte(nil), shares[0][:len(shares[0])-1]...), nil
+	}
+	return Combine(shares)
+}
+
+// CombineGroupShares checks grouped pieces and rebuilds the secret from them.
+// Groups with fewer pieces than their threshold are left out.
+func CombineGroupShares(shares []*Share) ([]byte, error) {
+	complete, err := completeGroups(shares)
+	if err != nil {
+		return nil, err
+	}
+	return CombineGroups(complete)
+}
+
+// CheckGroupShares reports whether grouped pieces are enough to recover: at
+// least the groups needed, each with its threshold of pieces. The error says
+// which groups are still short.
+func CheckGroupShares(shares []*Share) error {
+	_, err := completeGroups(shares)
+	return err
+}
+
+// completeGroups sorts grouped pieces by group and returns the data of the
+// groups that have enough pieces.
+func completeGroups(shares []*Share) ([][][]byte, error) {
+	if len(shares) == 0 {
+		return nil, fmt.Errorf("no shares provided")
+	}
+	first := shares[0]
+	byGroup := make(map[int][]*Share)
+	for i, s := range shares {
+		if s.Groups == 0 {
+			return nil, fmt.Errorf("share %d doesn't record its group; pieces of a grouped project must be read from README.txt, the QR code, or recover.html", i+1)
+		}
+		if s.Groups != first.Groups || s.GroupsNeeded != first.GroupsNeeded {
+			return nil, fmt.Errorf("share %d belongs to a different set of groups", i+1)
+		}
+		if s.Group < 1 || s.Group > s.Groups {
+			return nil, fmt.Errorf("share %d: group %d out of range", i+1, s.Group)
+		}
+		if other := byGroup[s.Group]; len(other) > 0 && other[0].Threshold != s.Threshold {
+			return nil, fmt.Errorf("share %d has different threshold from the rest of group %d (%d vs %d)", i+1, s.Group, s.Threshold, other[0].Threshold)
+		}
+		byGroup[s.Group] = append(byGroup[s.Group], s)
+	}
+
+	var complete [][][]byte
+	var short []string
+	for g := 1; g <= first.Groups; g++ {
+		members := byGroup[g]
+		if len(members) == 0 {
+			continue
+		}
+		if len(members) < members[0].Threshold {
+			short = append(short, fmt.Sprintf("group %d has %d of the %d pieces it needs", g, len(members), members[0].Threshold))
+			continue
+		}
+		data := make([][]byte, len(members))
+		for i, s := range members {
+			data[i] = s.Data
+		}
+		complete = append(complete, data)
+	}
+	if len(complete) < first.GroupsNeeded {
+		msg := fmt.Sprintf("need %d complete groups to recover (you have %d)", first.GroupsNeeded, len(complete))
+		if len(short) > 0 {
+			msg += ": " + strings.Join(short, ", ")
+		}
+		return nil, fmt.Errorf("%s", msg)
+	}
+	return complete, nil
+}
@@ -0,0 +1,164 @@
+package core
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestSplitCombineGroups(t *testing.T) {
+	secret := []byte("my-super-secret-passphrase")
+
+	tests := []struct {
+		name   string
+		needed int
+		groups []GroupSpec
+	}{
+		{"family-and-lawyer", 2, []GroupSpec{{3, 2}, {2, 1}}},
+		{"any-two-of-three", 2, []GroupSpec{{3, 2}, {3, 2}, {2, 2}}},
+		{"one-group-of-two", 1, []GroupSpec{{3, 2}, {4, 3}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pieces, err := SplitGroups(secret, tt.needed, tt.groups)
+			if err != nil {
+				t.Fatalf("split: %v", err)
+			}
+			if len(pieces) != len(tt.groups) {
+				t.Fatalf("got %d groups, want %d", len(pieces), len(tt.groups))
+			}
+
+			// The last groups, each with exactly its threshold of pieces
+			var complete [][][]byte
+			for i := len(tt.groups) - tt.needed; i < len(tt.groups); i++ {
+				if len(pieces[i]) != tt.groups[i].Size {
+					t.Fatalf("group %d: got %d pieces, want %d", i+1, len(pieces[i]), tt.groups[i].Size)
+				}
+				complete = append(complete, pieces[i][:tt.groups[i].Threshold])
+			}
+			recovered, err := CombineGroups(complete)
+			if err != nil {
+				t.Fatalf("combine: %v", err)
+			}
+			if !bytes.Equal(recovered, secret) {
+				t.Errorf("got %q, want %q", recovered, secret)
+			}
+		})
+	}
+}
+
+func TestValidateGroupParams(t *testing.T) {
+	tests := []struct {
+		name    string
+		needed  int
+		groups  []GroupSpec
+		wantErr string
+	}{
+		{"valid", 2, []GroupSpec{{3, 2}, {2, 1}}, ""},
+		{"one group", 1, []GroupSpec{{3, 2}}, "at least 2 groups"},
+		{"too many needed", 3, []GroupSpec{{3, 2}, {2, 1}}, "groups needed"},
+		{"threshold above size", 2, []GroupSpec{{2, 3}, {2, 1}}, "group 1: threshold"},
+		{"empty group", 2, []GroupSpec{{3, 2}, {0, 0}}, "group 2: must have"},
+		{"single piece recovers", 1, []GroupSpec{{3, 2}, {2, 1}}, "single piece"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateGroupParams(tt.needed, tt.groups)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("got %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCombineGroupShares(t *testing.T) {
+	secret := []byte("my-super-secret-passphrase")
+	groups := []GroupSpec{{3, 2}, {2, 1}}
+	pieces, err := SplitGroups(secret, 2, groups)
+	if err != nil {
+		t.Fatalf("split: %v", err)
+	}
+
+	// Number the pieces across the project, as seal does
+	var shares []*Share
+	for g, group := range pieces {
+		for _, data := range group {
+			s := NewShare(2, len(shares)+1, 5, groups[g].Threshold, "", data)
+			s.Group, s.Groups, s.GroupsNeeded = g+1, len(groups), 2
+			shares = append(shares, s)
+		}
+	}
+
+	// Two family members and one lawyer
+	recovered, err := CombineGroupShares([]*Share{shares[0], shares[2], shares[4]})
+	if err != nil {
+		t.Fatalf("combine: %v", err)
+	}
+	if !bytes.Equal(recovered, secret) {
+		t.Errorf("got %q, want %q", recovered, secret)
+	}
+
+	// Three family members but no lawyer
+	_, err = CombineGroupShares(shares[:3])
+	if err == nil || !strings.Contains(err.Error(), "need 2 complete groups") {
+		t.Errorf("expected a missing-group error, got %v", err)
+	}
+
+	// One family member and one lawyer
+	_, err = CombineGroupShares([]*Share{shares[0], shares[3]})
+	if err == nil || !strings.Contains(err.Error(), "group 1 has 1 of the 2 pieces") {
+		t.Errorf("expected a short-group error, got %v", err)
+	}
+
+	// A piece that doesn't record its group
+	plain := NewShare(2, 6, 5, 2, "", shares[1].Data)
+	if _, err := CombineGroupShares([]*Share{shares[0], plain, shares[4]}); err == nil {
+		t.Error("expected an error for a piece without a group")
+	}
+}
+
+func TestGroupedShareEncoding(t *testing.T) {
+	s := NewShare(2, 4, 5, 1, "Dana", []byte("some share data bytes here!!!!!!!!"))
+	s.Group, s.Groups, s.GroupsNeeded = 2, 2, 2
+
+	parsed, err := ParseShare([]byte(s.Encode()))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if parsed.Group != 2 || parsed.Groups != 2 || parsed.GroupsNeeded != 2 {
+		t.Errorf("group: got %d/%d (%d needed)", parsed.Group, parsed.Groups, parsed.GroupsNeeded)
+	}
+
+	compact := s.CompactEncode()
+	if !strings.HasSuffix(compact, ":2.2.2") {
+		t.Errorf("compact should end with the group, got %q", compact)
+	}
+	fromCompact, err := ParseCompact(compact)
+	if err != nil {
+		t.Fatalf("parse compact: %v", err)
+	}
+	if fromCompact.Group != 2 || fromCompact.Groups != 2 || fromCompact.GroupsNeeded != 2 {
+		t.Errorf("compact group: got %d/%d (%d needed)", fromCompact.Group, fromCompact.Groups, fromCompact.GroupsNeeded)
+	}
+
+	// The forms that don't record the group refuse grouped pieces
+	if _, err := s.Words(); err == nil {
+		t.Error("Words should refuse a grouped piece")
+	}
+	if _, err := s.Bech32(); err == nil {
+		t.Error("Bech32 should refuse a grouped piece")
+	}
+
+	bad := strings.Replace(s.Encode(), "Groups-Needed: 2", "Groups-Needed: 3", 1)
+	if _, err := ParseShare([]byte(bad)); err == nil {
+		t.Error("expected an error for more groups needed than there are")
+	}
+}
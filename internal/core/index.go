@@ -0,0 +1,161 @@
+package core
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+// A sealed archive ends with an index of its files: each one's path, size,
+// SHA-256, and modification time. It's a tar entry of its own, after every
+// file and outside the archive's root folder, so it travels encrypted with
+// them, and extraction reads it instead of writing it out. Recovery lists
+// the files from it and checks each one it extracts against it.
+
+// IndexFile is the name of the index entry in a sealed archive.
+const IndexFile = ".rememory-index.json"
+
+// MaxIndexSize is the largest index extraction reads.
+const MaxIndexSize = 16 * 1024 * 1024
+
+const (
+	indexFormat  = "rememory-index"
+	indexVersion = 1
+)
+
+// Index lists the files in a sealed archive.
+type Index struct {
+	Format  string       `json:"format"` // "rememory-index"
+	Version int          `json:"version"`
+	Files   []IndexEntry `json:"files"`
+}
+
+// IndexEntry is one file in an Index.
+type IndexEntry struct {
+	Path     string    `json:"path"` // As named in the archive (e.g. "manifest/notes.txt")
+	Size     int64     `json:"size"`
+	SHA256   string    `json:"sha256"` // In hex
+	Modified time.Time `json:"modified"`
+}
+
+// NewIndex returns an empty index.
+func NewIndex() *Index {
+	return &Index{Format: indexFormat, Version: indexVersion}
+}
+
+// Add records a file with its SHA-256 sum.
+func (ix *Index) Add(path string, size int64, sum []byte, modified time.Time) {
+	ix.Files = append(ix.Files, IndexEntry{
+		Path:     path,
+		Size:     size,
+		SHA256:   hex.EncodeToString(sum),
+		Modified: modified.UTC().Truncate(time.Second),
+	})
+}
+
+// Encode returns the index as it is stored in the archive.
+func (ix *Index) Encode() ([]byte, error) {
+	return json.MarshalIndent(ix, "", "  ")
+}
+
+// ParseIndex reads an index written by Encode.
+func ParseIndex(data []byte) (*Index, error) {
+	var ix Index
+	if err := json.Unmarshal(data, &ix); err != nil {
+		return nil, fmt.Errorf("reading the file index: %w", err)
+	}
+	if ix.Format != indexFormat {
+		return nil, fmt.Errorf("reading the file index: unexpected format %q", ix.Format)
+	}
+	if ix.Version != indexVersion {
+		return nil, fmt.Errorf("the file index is version %d; this version of ReMemory reads version %d", ix.Version, indexVersion)
+	}
+	return &ix, nil
+}
+
+// ReadIndex reads an archive's index entry, the tar entry named IndexFile.
+func ReadIndex(r io.Reader) (*Index, error) {
+	data, err := io.ReadAll(io.LimitReader(r, MaxIndexSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("reading the file index: %w", err)
+	}
+	if len(data) > MaxIndexSize {
+		return nil, fmt.Errorf("the file index is over %d bytes", MaxIndexSize)
+	}
+	return ParseIndex(data)
+}
+
+// What checking a file against the index found.
+const (
+	IndexOK        = "ok"        // The file matches the index
+	IndexCorrupted = "corrupted" // Its size or SHA-256 doesn't match
+	IndexMissing   = "missing"   // The index lists it, but the archive doesn't have it
+	IndexUnlisted  = "unlisted"  // The archive has it, but the index doesn't list it
+)
+
+// IndexCheck is how one file compared to the index.
+type IndexCheck struct {
+	Path     string
+	Size     int64
+	Modified time.Time // Zero for an unlisted file
+	Status   string    // IndexOK, IndexCorrupted, IndexMissing, or IndexUnlisted
+}
+
+// FileHash is the size and SHA-256 sum of a file as extracted, for Check.
+type FileHash struct {
+	Path string // As named in the archive
+	Size int64
+	Sum  []byte
+}
+
+// HashFile returns the FileHash of data.
+func HashFile(path string, data []byte) FileHash {
+	sum := sha256.Sum256(data)
+	return FileHash{Path: path, Size: int64(len(data)), Sum: sum[:]}
+}
+
+// Check compares the files extracted with the index, in the index's order,
+// followed by any file it doesn't list in the order they were extracted.
+func (ix *Index) Check(extracted []FileHash) []IndexCheck {
+	hashes := make(map[string]FileHash, len(extracted))
+	for _, h := range extracted {
+		hashes[h.Path] = h
+	}
+	listed := make(map[string]bool, len(ix.Files))
+	checks := make([]IndexCheck, 0, len(ix.Files))
+	for _, f := range ix.Files {
+		listed[f.Path] = true
+		check := IndexCheck{Path: f.Path, Size: f.Size, Modified: f.Modified, Status: IndexOK}
+		got, ok := hashes[f.Path]
+		switch {
+		case !ok:
+			check.Status = IndexMissing
+		case got.Size != f.Size || hex.EncodeToString(got.Sum) != f.SHA256:
+			check.Status = IndexCorrupted
+		}
+		checks = append(checks, check)
+	}
+	for _, h := range extracted {
+		if !listed[h.Path] {
+			listed[h.Path] = true
+			checks = append(checks, IndexCheck{Path: h.Path, Size: h.Size, Status: IndexUnlisted})
+		}
+	}
+	return checks
+}
+
+// Problems returns the checks that didn't find a file as the index lists
+// it: corrupted and missing files. Unlisted ones aren't a problem on their
+// own, since the index can't vouch for them either way.
+func Problems(checks []IndexCheck) []IndexCheck {
+	var problems []IndexCheck
+	for _, c := range checks {
+		if c.Status == IndexCorrupted || c.Status == IndexMissing {
+			problems = append(problems, c)
+		}
+	}
+	return problems
+}
@@ -0,0 +1,69 @@
+package core
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestIndexCheck(t *testing.T) {
+	modified := time.Date(2026, 3, 1, 12, 0, 0, 500, time.FixedZone("", 3600))
+	ix := NewIndex()
+	for _, f := range []FileHash{
+		HashFile("manifest/a.txt", []byte("alpha")),
+		HashFile("manifest/b.txt", []byte("bravo")),
+		HashFile("manifest/c.txt", []byte("charlie")),
+	} {
+		ix.Add(f.Path, f.Size, f.Sum, modified)
+	}
+
+	data, err := ix.Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := ReadIndex(strings.NewReader(string(data)))
+	if err != nil {
+		t.Fatalf("ReadIndex: %v", err)
+	}
+	if want := modified.UTC().Truncate(time.Second); !parsed.Files[0].Modified.Equal(want) {
+		t.Errorf("modified = %v, want %v", parsed.Files[0].Modified, want)
+	}
+
+	checks := parsed.Check([]FileHash{
+		HashFile("manifest/a.txt", []byte("alpha")),
+		HashFile("manifest/b.txt", []byte("bravO")), // Same size, different bytes
+		HashFile("manifest/d.txt", []byte("delta")),
+	})
+	var got []string
+	for _, c := range checks {
+		got = append(got, c.Path+" "+c.Status)
+	}
+	want := []string{
+		"manifest/a.txt " + IndexOK,
+		"manifest/b.txt " + IndexCorrupted,
+		"manifest/c.txt " + IndexMissing,
+		"manifest/d.txt " + IndexUnlisted,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("checks = %q, want %q", got, want)
+	}
+	if problems := Problems(checks); len(problems) != 2 || problems[0].Path != "manifest/b.txt" || problems[1].Path != "manifest/c.txt" {
+		t.Errorf("problems = %v", problems)
+	}
+}
+
+func TestParseIndexErrors(t *testing.T) {
+	for _, data := range []string{
+		`not json`,
+		`{"format":"something-else","version":1}`,
+		`{"format":"rememory-index","version":2}`,
+	} {
+		if _, err := ParseIndex([]byte(data)); err == nil {
+			t.Errorf("ParseIndex(%s) succeeded", data)
+		}
+	}
+	if _, err := ReadIndex(strings.NewReader(strings.Repeat(" ", MaxIndexSize+1))); err == nil {
+		t.Error("ReadIndex read an index over MaxIndexSize")
+	}
+}
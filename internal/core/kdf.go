@@ -0,0 +1,159 @@
+package core
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+// MANIFEST.age is locked with scrypt. age's default work factor, 2^18, takes
+// about a second on a laptop and much longer on an old phone. A project can
+// instead set a recovery-time budget for the slowest device a friend is
+// likely to recover on; sealing then measures scrypt on this computer and
+// picks the highest work factor that still fits the budget on that device.
+//
+// scrypt also needs memory, 1 KiB × N with age's r=8: 256 MiB at the
+// default. A project can cap it with max_memory for heirs on old phones or
+// small laptops, whose browser tab may not be given that much.
+
+// DeviceClass is a kind of device recovery is expected to run on.
+type DeviceClass string
+
+const (
+	DeviceOldPhone DeviceClass = "old-phone"
+	DevicePhone    DeviceClass = "phone"
+	DeviceLaptop   DeviceClass = "laptop"
+)
+
+// DeviceClasses lists the device classes, slowest first.
+var DeviceClasses = []DeviceClass{DeviceOldPhone, DevicePhone, DeviceLaptop}
+
+// deviceProfile describes a device class relative to the computer that
+// seals: how many times slower scrypt runs in its browser, and the highest
+// work factor whose memory (1 KiB × N) it can be expected to spare.
+type deviceProfile struct {
+	slowdown      float64
+	maxWorkFactor int
+}
+
+// The slowdowns assume the sealing computer is a typical laptop running the
+// CLI; recover.html runs scrypt in WebAssembly, which is itself about three
+// times slower than native code.
+var deviceProfiles = map[DeviceClass]deviceProfile{
+	DeviceOldPhone: {slowdown: 15, maxWorkFactor: 18}, // 256 MiB
+	DevicePhone:    {slowdown: 6, maxWorkFactor: 19},  // 512 MiB
+	DeviceLaptop:   {slowdown: 3, maxWorkFactor: 20},  // 1 GiB
+}
+
+// DefaultWorkFactor is age's own work factor, which Encrypt uses when
+// neither a recovery-time budget nor max_memory sets one.
+const DefaultWorkFactor = 18
+
+// MinWorkFactor is the lowest work factor tuning will choose. The
+// passphrase is 256 random bits, so scrypt adds little against guessing it;
+// the floor only keeps a tiny budget from removing it altogether.
+const MinWorkFactor = 16
+
+// benchWorkFactor is the work factor BenchmarkScrypt measures: 16 MiB of
+// memory, a few tens of milliseconds on a laptop.
+const benchWorkFactor = 14
+
+// Describe names the device class in a sentence, such as "an old phone".
+func (c DeviceClass) Describe() string {
+	switch c {
+	case DeviceOldPhone:
+		return "an old phone"
+	case DevicePhone:
+		return "a phone"
+	case DeviceLaptop:
+		return "a laptop"
+	}
+	return string(c)
+}
+
+// ValidDeviceClass reports whether class is one of DeviceClasses.
+func ValidDeviceClass(class DeviceClass) error {
+	if _, ok := deviceProfiles[class]; !ok {
+		return fmt.Errorf("unknown device %q (choose %s, %s, or %s)", class, DeviceOldPhone, DevicePhone, DeviceLaptop)
+	}
+	return nil
+}
+
+// ScryptMemory returns how many bytes of memory scrypt takes at workFactor.
+func ScryptMemory(workFactor int) int64 {
+	return 1024 << workFactor
+}
+
+// ParseMaxMemory reads the most memory scrypt may take: a size such as 128M
+// or 1G (MB, MiB, and so on too), counted in powers of 1024 as memory is. It
+// returns the highest work factor that fits.
+func ParseMaxMemory(s string) (int, error) {
+	text := strings.ToUpper(strings.TrimSpace(s))
+	text = strings.TrimSuffix(strings.TrimSuffix(text, "B"), "I")
+	shift := 0
+	if n := len(text); n > 0 {
+		if i := strings.IndexByte("KMG", text[n-1]); i >= 0 {
+			shift = 10 * (i + 1)
+			text = text[:n-1]
+		}
+	}
+	n, err := strconv.ParseFloat(strings.TrimSpace(text), 64)
+	if err != nil || n <= 0 || math.IsInf(n, 0) {
+		return 0, fmt.Errorf("memory %q isn't a size like 128M or 1G", s)
+	}
+	workFactor := int(math.Floor(math.Log2(n * math.Exp2(float64(shift)) / 1024)))
+	if workFactor < MinWorkFactor {
+		return 0, fmt.Errorf("memory %q is below the %dM scrypt is always given", s, ScryptMemory(MinWorkFactor)>>20)
+	}
+	return workFactor, nil
+}
+
+// BenchmarkScrypt measures one scrypt run at N=2^14, r=8, p=1 on this
+// device, the fastest of three, to leave out a slow first run.
+func BenchmarkScrypt() time.Duration {
+	best := time.Duration(math.MaxInt64)
+	for range 3 {
+		start := time.Now()
+		scrypt.Key([]byte("benchmark"), make([]byte, 16), 1<<benchWorkFactor, 8, 1, 32)
+		if elapsed := time.Since(start); elapsed < best {
+			best = elapsed
+		}
+	}
+	return best
+}
+
+// EstimateScrypt scales a BenchmarkScrypt result to workFactor: scrypt's
+// time doubles with each step.
+func EstimateScrypt(bench time.Duration, workFactor int) time.Duration {
+	return time.Duration(float64(bench) * math.Exp2(float64(workFactor-benchWorkFactor)))
+}
+
+// TuneWorkFactor picks the highest work factor, at least min, whose
+// estimated time on class fits within budget, given a BenchmarkScrypt result
+// from the sealing computer. max, unless 0, caps it below what class can
+// spare, as max_memory does. It returns the work factor and how long it's
+// expected to take there. When even min doesn't fit, it returns min anyway,
+// with an estimate over budget for the caller to warn about.
+func TuneWorkFactor(budget time.Duration, class DeviceClass, bench time.Duration, min, max int) (int, time.Duration, error) {
+	profile, ok := deviceProfiles[class]
+	if !ok {
+		return 0, 0, ValidDeviceClass(class)
+	}
+	onDevice := time.Duration(float64(bench) * profile.slowdown)
+	if max == 0 || max > profile.maxWorkFactor {
+		max = profile.maxWorkFactor
+	}
+
+	chosen := min
+	for wf := min + 1; wf <= max; wf++ {
+		if EstimateScrypt(onDevice, wf) > budget {
+			break
+		}
+		chosen = wf
+	}
+	return chosen, EstimateScrypt(onDevice, chosen), nil
+}
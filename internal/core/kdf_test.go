@@ -0,0 +1,91 @@
+package core
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTuneWorkFactor(t *testing.T) {
+	bench := 20 * time.Millisecond // 2^14 on the sealing computer
+
+	tests := []struct {
+		budget time.Duration
+		class  DeviceClass
+		min    int
+		max    int
+		want   int
+	}{
+		// laptop: 60ms at 2^14, so 2^18 takes ~1s and 2^20 ~4s
+		{5 * time.Second, DeviceLaptop, MinWorkFactor, 0, 20},
+		{2 * time.Second, DeviceLaptop, MinWorkFactor, 0, 19},
+		{time.Hour, DeviceLaptop, MinWorkFactor, 0, 20},  // capped by memory
+		{time.Hour, DeviceLaptop, MinWorkFactor, 17, 17}, // capped by max_memory
+		{time.Hour, DeviceLaptop, MinWorkFactor, 24, 20}, // max_memory above what the device spares
+		// old phone: 300ms at 2^14, so 2^16 takes ~1.2s
+		{3 * time.Second, DeviceOldPhone, MinWorkFactor, 0, 17},
+		{time.Second, DeviceOldPhone, MinWorkFactor, 0, 16}, // over budget, kept at the floor
+		{time.Second, DeviceOldPhone, RestrictedMinWorkFactor, 0, 18},
+	}
+	for _, tt := range tests {
+		got, estimate, err := TuneWorkFactor(tt.budget, tt.class, bench, tt.min, tt.max)
+		if err != nil {
+			t.Fatalf("TuneWorkFactor(%s, %s): %v", tt.budget, tt.class, err)
+		}
+		if got != tt.want {
+			t.Errorf("TuneWorkFactor(%s, %s, min %d, max %d) = %d, want %d", tt.budget, tt.class, tt.min, tt.max, got, tt.want)
+		}
+		if want := EstimateScrypt(time.Duration(float64(bench)*deviceProfiles[tt.class].slowdown), got); estimate != want {
+			t.Errorf("estimate = %s, want %s", estimate, want)
+		}
+	}
+
+	if _, _, err := TuneWorkFactor(time.Second, "toaster", bench, MinWorkFactor, 0); err == nil {
+		t.Error("expected an error for an unknown device")
+	}
+}
+
+func TestParseMaxMemory(t *testing.T) {
+	for in, want := range map[string]int{
+		"64M":     16,
+		"128MiB":  17,
+		"256mb":   18,
+		"300M":    18, // rounded down to what fits
+		"1G":      20,
+		"1.5 GiB": 20,
+	} {
+		got, err := ParseMaxMemory(in)
+		if err != nil || got != want {
+			t.Errorf("ParseMaxMemory(%q) = %d, %v; want %d", in, got, err, want)
+		}
+	}
+	for _, in := range []string{"", "lots", "32M", "-1G", "65536"} {
+		if _, err := ParseMaxMemory(in); err == nil {
+			t.Errorf("ParseMaxMemory(%q) was accepted", in)
+		}
+	}
+	if got := ScryptMemory(DefaultWorkFactor); got != 256<<20 {
+		t.Errorf("ScryptMemory(%d) = %d, want 256 MiB", DefaultWorkFactor, got)
+	}
+}
+
+func TestShareWorkFactor(t *testing.T) {
+	share := NewShare(2, 1, 3, 2, "Alice", []byte("0123456789abcdef0123456789abcdef"))
+	share.WorkFactor = 19
+	encoded := share.Encode()
+	if !strings.Contains(encoded, "KDF: scrypt N=2^19 r=8 p=1\n") {
+		t.Fatalf("encoded share has no KDF line:\n%s", encoded)
+	}
+	parsed, err := ParseShare([]byte(encoded))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsed.WorkFactor != 19 {
+		t.Errorf("WorkFactor = %d, want 19", parsed.WorkFactor)
+	}
+
+	bad := strings.Replace(encoded, "N=2^19", "N=lots", 1)
+	if _, err := ParseShare([]byte(bad)); err == nil {
+		t.Error("expected an error for a malformed KDF line")
+	}
+}
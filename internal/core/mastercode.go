@@ -0,0 +1,126 @@
+package core
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"fmt"
+	"strings"
+
+	"filippo.io/age"
+)
+
+// A master code is the owner's own way around the pieces: an age key, added
+// to the recipients RECIPIENTS.age is encrypted to, whose secret is printed
+// once as a QR code and 24 words and kept somewhere like a vault or a safe
+// deposit box. On its own it unlocks the passphrase, so it needs the care
+// of all the pieces together.
+//
+// The code is 32 random bytes. They are an X25519 key, or, for a project
+// sealed in the post-quantum mode, the seed of a hybrid ML-KEM-768 + X25519
+// key, so the same words work either way.
+const MasterCodeSize = 32
+
+// MasterCodeWordCount is how many words a master code is written as: its
+// bytes and a checksum byte, 11 bits to a word.
+const MasterCodeWordCount = 24
+
+const (
+	masterCodeHRP   = "age-secret-key-"
+	masterCodeHRPPQ = "age-secret-key-pq-"
+)
+
+// GenerateMasterCode returns a new random master code.
+func GenerateMasterCode() (Secret, error) {
+	code := make(Secret, MasterCodeSize)
+	if _, err := rand.Read(code); err != nil {
+		return nil, fmt.Errorf("generating master code: %w", err)
+	}
+	return code, nil
+}
+
+// MasterCodeWords returns code as 24 BIP39 English words, the last of which
+// holds a checksum that catches a mistyped or misplaced word.
+func MasterCodeWords(code Secret) []string {
+	sum := sha256.Sum256(code)
+	return EncodeWords(append(append([]byte{}, code...), sum[0]))
+}
+
+// MasterCodeKey returns code as an age secret key ("AGE-SECRET-KEY-1...",
+// or "AGE-SECRET-KEY-PQ-1..." for a post-quantum project), which age and
+// 'rememory unseal --identity' read as they are.
+func MasterCodeKey(code Secret, postQuantum bool) string {
+	hrp := masterCodeHRP
+	if postQuantum {
+		hrp = masterCodeHRPPQ
+	}
+	return strings.ToUpper(bech32Encode(hrp, code, bech32Const))
+}
+
+// MasterCodeRecipient returns the age public key that code unlocks, for
+// project.yml.
+func MasterCodeRecipient(code Secret, postQuantum bool) (string, error) {
+	key := MasterCodeKey(code, postQuantum)
+	if postQuantum {
+		id, err := age.ParseHybridIdentity(key)
+		if err != nil {
+			return "", fmt.Errorf("master code: %w", err)
+		}
+		return id.Recipient().String(), nil
+	}
+	id, err := age.ParseX25519Identity(key)
+	if err != nil {
+		return "", fmt.Errorf("master code: %w", err)
+	}
+	return id.Recipient().String(), nil
+}
+
+// ParseMasterCode reads a master code as it is printed: its age secret key,
+// as the QR code holds it or in groups, or its 24 words, numbered or not.
+// Words give both the X25519 and the post-quantum identity, since they
+// don't say which the project was sealed with.
+func ParseMasterCode(text string) ([]age.Identity, error) {
+	// The sheet prints the key in groups
+	switch upper := strings.ToUpper(strings.Join(strings.Fields(text), "")); {
+	case strings.HasPrefix(upper, strings.ToUpper(masterCodeHRPPQ)):
+		id, err := age.ParseHybridIdentity(upper)
+		if err != nil {
+			return nil, fmt.Errorf("invalid master code: %w", err)
+		}
+		return []age.Identity{id}, nil
+	case strings.HasPrefix(upper, strings.ToUpper(masterCodeHRP)):
+		id, err := age.ParseX25519Identity(upper)
+		if err != nil {
+			return nil, fmt.Errorf("invalid master code: %w", err)
+		}
+		return []age.Identity{id}, nil
+	}
+
+	var words []string
+	for _, field := range strings.Fields(text) {
+		if strings.Trim(field, "0123456789.") != "" {
+			words = append(words, field)
+		}
+	}
+	if len(words) != MasterCodeWordCount {
+		return nil, fmt.Errorf("a master code is %d words, got %d", MasterCodeWordCount, len(words))
+	}
+	data, err := DecodeWords(words)
+	if err != nil {
+		return nil, fmt.Errorf("invalid master code: %w", err)
+	}
+	defer Wipe(data)
+	code := Secret(data[:MasterCodeSize])
+	if sum := sha256.Sum256(code); sum[0] != data[MasterCodeSize] {
+		return nil, fmt.Errorf("invalid master code: checksum mismatch — a word may be mistyped or out of order")
+	}
+
+	classic, err := age.ParseX25519Identity(MasterCodeKey(code, false))
+	if err != nil {
+		return nil, fmt.Errorf("invalid master code: %w", err)
+	}
+	hybrid, err := age.ParseHybridIdentity(MasterCodeKey(code, true))
+	if err != nil {
+		return nil, fmt.Errorf("invalid master code: %w", err)
+	}
+	return []age.Identity{classic, hybrid}, nil
+}
@@ -0,0 +1,76 @@
+package core
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"filippo.io/age"
+)
+
+func TestMasterCodeUnlocks(t *testing.T) {
+	code, err := GenerateMasterCode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	words := MasterCodeWords(code)
+	if len(words) != MasterCodeWordCount {
+		t.Fatalf("got %d words", len(words))
+	}
+	var numbered strings.Builder
+	for i, w := range words {
+		fmt.Fprintf(&numbered, "%2d. %s\n", i+1, w)
+	}
+
+	for _, pq := range []bool{false, true} {
+		key, err := MasterCodeRecipient(code, pq)
+		if err != nil {
+			t.Fatal(err)
+		}
+		recipients, err := ParseRecipients([]string{key})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var sealed bytes.Buffer
+		if err := EncryptToRecipients(&sealed, strings.NewReader("passphrase"), recipients); err != nil {
+			t.Fatal(err)
+		}
+
+		for _, text := range []string{strings.Join(words, " "), numbered.String(), MasterCodeKey(code, pq), strings.ToLower(MasterCodeKey(code, pq))} {
+			ids, err := ParseMasterCode(text)
+			if err != nil {
+				t.Fatalf("pq=%v: %q: %v", pq, text, err)
+			}
+			got, err := DecryptWithIdentities(sealed.Bytes(), ids)
+			if err != nil || string(got) != "passphrase" {
+				t.Errorf("pq=%v: %q: got %q, %v", pq, text, got, err)
+			}
+		}
+	}
+
+	// age reads the key as any other identity
+	if _, err := age.ParseX25519Identity(MasterCodeKey(code, false)); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestMasterCodeCatchesMistakes(t *testing.T) {
+	code := make(Secret, MasterCodeSize)
+	for i := range code {
+		code[i] = byte(i * 7)
+	}
+	words := MasterCodeWords(code)
+
+	swapped := append([]string{}, words...)
+	swapped[0], swapped[1] = swapped[1], swapped[0]
+	if _, err := ParseMasterCode(strings.Join(swapped, " ")); err == nil || !strings.Contains(err.Error(), "checksum") {
+		t.Errorf("swapped words: %v", err)
+	}
+	if _, err := ParseMasterCode(strings.Join(words[1:], " ")); err == nil {
+		t.Error("23 words accepted")
+	}
+	if _, err := ParseMasterCode("AGE-SECRET-KEY-1QQQQ"); err == nil {
+		t.Error("bad key accepted")
+	}
+}
@@ -0,0 +1,459 @@
+package core
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// Parity protects a file against bit rot on a USB stick or a disc. The file
+// is cut into blocks, each with its SHA-256, and the blocks are dealt into
+// stripes: block i goes to stripe i mod stripes, so damage to a run of
+// neighbouring blocks lands in different stripes. Each stripe gets Cauchy
+// Reed-Solomon parity blocks over GF(256), and any of its blocks whose hash
+// doesn't match can be rebuilt from the rest as long as no more are damaged
+// than the stripe has parity blocks.
+//
+// The parity file holds a header line, the parity blocks each after its
+// SHA-256, the header line again, and the header line's length, so either
+// copy of the header is enough:
+//
+//	<sha256 of JSON> <JSON>\n
+//	<sha256><parity block> ...
+//	<sha256 of JSON> <JSON>\n
+//	<length of the header line, 16 hex digits>\n
+
+const (
+	// ParitySuffix names the parity file of MANIFEST.age: MANIFEST.age.parity.
+	ParitySuffix = ".parity"
+
+	parityVersion   = 1
+	parityMinBlock  = 4096
+	parityMaxBlocks = 1 << 15 // Keeps the list of block hashes under 1 MB
+	parityFieldSize = 256     // Blocks in a stripe, data and parity together
+	parityTrailer   = 17
+)
+
+// ParityHeader describes a parity file: the file it protects, how that file
+// is cut into blocks, and each block's SHA-256.
+type ParityHeader struct {
+	Version   int    `json:"version"`
+	Name      string `json:"name"`
+	Size      int64  `json:"size"`
+	Checksum  string `json:"checksum"`
+	Percent   int    `json:"percent"`
+	BlockSize int    `json:"block_size"`
+	Stripes   int    `json:"stripes"`
+	Hashes    []byte `json:"hashes"` // SHA-256 of each block, one after another
+}
+
+// parityBlockSize returns the block size for a file of size bytes: 4 KiB,
+// or more so the file is no more than parityMaxBlocks blocks.
+func parityBlockSize(size int64) int {
+	block := (size + parityMaxBlocks - 1) / parityMaxBlocks
+	block = (block + parityMinBlock - 1) / parityMinBlock * parityMinBlock
+	return int(max(block, parityMinBlock))
+}
+
+// parityCount returns how many parity blocks a stripe of k blocks gets.
+func parityCount(k, percent int) int {
+	return max(1, (k*percent+99)/100)
+}
+
+// Blocks returns how many blocks the file is cut into.
+func (h *ParityHeader) Blocks() int {
+	return int((h.Size + int64(h.BlockSize) - 1) / int64(h.BlockSize))
+}
+
+// stripe returns the blocks dealt into stripe s, and the index of its first
+// parity block in the parity file.
+func (h *ParityHeader) stripe(s int) (blocks []int, firstParity int) {
+	n := h.Blocks()
+	for i := s; i < n; i += h.Stripes {
+		blocks = append(blocks, i)
+	}
+	for t := 0; t < s; t++ {
+		firstParity += parityCount((n-t+h.Stripes-1)/h.Stripes, h.Percent)
+	}
+	return blocks, firstParity
+}
+
+// parityStripes returns how many stripes a file of so many blocks is dealt
+// into, so no stripe needs more blocks than GF(256) has elements.
+func parityStripes(blocks, percent int) int {
+	k := parityFieldSize - 1
+	for k > 1 && k+parityCount(k, percent) > parityFieldSize {
+		k--
+	}
+	return max(1, (blocks+k-1)/k)
+}
+
+// ParitySize returns how big the parity file of a file of size bytes comes
+// out, near enough to check there's room for it.
+func ParitySize(size int64, percent int) int64 {
+	block := int64(parityBlockSize(size))
+	blocks := (size + block - 1) / block
+	parity := (blocks*int64(percent)+99)/100 + int64(parityStripes(int(blocks), percent))
+	return parity*(block+sha256.Size) + 2*(blocks*sha256.Size*4/3+1024)
+}
+
+// WriteParity writes the parity of the file name, size bytes long, read from
+// r, to w, with percent parity blocks for every hundred blocks of the file.
+func WriteParity(w io.Writer, r io.ReaderAt, name string, size int64, percent int) (*ParityHeader, error) {
+	if percent < 1 || percent > 100 {
+		return nil, fmt.Errorf("parity must be between 1 and 100 percent, not %d", percent)
+	}
+	h := &ParityHeader{Version: parityVersion, Name: name, Size: size, Percent: percent, BlockSize: parityBlockSize(size)}
+	h.Stripes = parityStripes(h.Blocks(), percent)
+
+	// The header comes first, so the blocks are hashed before any parity
+	whole := sha256.New()
+	buf := make([]byte, h.BlockSize)
+	for i := 0; i < h.Blocks(); i++ {
+		block := buf[:h.blockLen(i)]
+		if _, err := r.ReadAt(block, int64(i)*int64(h.BlockSize)); err != nil {
+			return nil, fmt.Errorf("reading %s: %w", name, err)
+		}
+		whole.Write(block)
+		sum := sha256.Sum256(block)
+		h.Hashes = append(h.Hashes, sum[:]...)
+	}
+	h.Checksum = "sha256:" + hex.EncodeToString(whole.Sum(nil))
+	line, err := h.line()
+	if err != nil {
+		return nil, err
+	}
+
+	bw := bufio.NewWriter(w)
+	bw.Write(line)
+	for s := 0; s < h.Stripes; s++ {
+		blocks, _ := h.stripe(s)
+		data := make([][]byte, len(blocks))
+		for j, i := range blocks {
+			data[j] = make([]byte, h.BlockSize)
+			if _, err := r.ReadAt(data[j][:h.blockLen(i)], int64(i)*int64(h.BlockSize)); err != nil {
+				return nil, fmt.Errorf("reading %s: %w", name, err)
+			}
+		}
+		m := parityCount(len(blocks), percent)
+		for p := 0; p < m; p++ {
+			parity := make([]byte, h.BlockSize)
+			for j := range data {
+				gf256MulAdd(parity, data[j], cauchy(p, j, m))
+			}
+			sum := sha256.Sum256(parity)
+			bw.Write(sum[:])
+			bw.Write(parity)
+		}
+	}
+	bw.Write(line)
+	fmt.Fprintf(bw, "%016x\n", len(line))
+	if err := bw.Flush(); err != nil {
+		return nil, fmt.Errorf("writing parity: %w", err)
+	}
+	return h, nil
+}
+
+// blockLen returns how long block i is: BlockSize, except for the last.
+func (h *ParityHeader) blockLen(i int) int {
+	return int(min(int64(h.BlockSize), h.Size-int64(i)*int64(h.BlockSize)))
+}
+
+// line returns the header as written to the parity file.
+func (h *ParityHeader) line() ([]byte, error) {
+	data, err := json.Marshal(h)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(data)
+	return fmt.Appendf(nil, "%x %s\n", sum, data), nil
+}
+
+// ReadParityHeader reads the header of a parity file, from its second copy
+// when the first is damaged. It also returns where the parity blocks start.
+func ReadParityHeader(parity []byte) (*ParityHeader, int, error) {
+	if i := bytes.IndexByte(parity, '\n'); i > 0 {
+		if h, err := parseParityLine(parity[:i+1]); err == nil {
+			return h, i + 1, nil
+		}
+	}
+	if len(parity) > parityTrailer {
+		n, err := strconv.ParseUint(string(parity[len(parity)-parityTrailer:len(parity)-1]), 16, 64)
+		if err == nil && n < uint64(len(parity)-parityTrailer) {
+			end := len(parity) - parityTrailer
+			if h, err := parseParityLine(parity[end-int(n) : end]); err == nil {
+				return h, int(n), nil
+			}
+		}
+	}
+	return nil, 0, fmt.Errorf("not a parity file, or both copies of its header are damaged")
+}
+
+// PeekParityHeader reads the first copy of a parity file's header from r,
+// without reading the parity blocks.
+func PeekParityHeader(r io.Reader) (*ParityHeader, error) {
+	line, err := bufio.NewReader(r).ReadBytes('\n')
+	if err != nil {
+		return nil, fmt.Errorf("reading parity header: %w", err)
+	}
+	return parseParityLine(line)
+}
+
+func parseParityLine(line []byte) (*ParityHeader, error) {
+	sum, data, ok := bytes.Cut(bytes.TrimSuffix(line, []byte("\n")), []byte(" "))
+	if !ok {
+		return nil, fmt.Errorf("invalid parity header")
+	}
+	got := sha256.Sum256(data)
+	if hex.EncodeToString(got[:]) != string(sum) {
+		return nil, fmt.Errorf("parity header is damaged")
+	}
+	h := &ParityHeader{}
+	if err := json.Unmarshal(data, h); err != nil {
+		return nil, fmt.Errorf("invalid parity header: %w", err)
+	}
+	if h.Version != parityVersion {
+		return nil, fmt.Errorf("parity version %d is not supported", h.Version)
+	}
+	if h.BlockSize < 1 || h.Size < 0 || h.Percent < 1 || h.Percent > 100 || h.Stripes < 1 || len(h.Hashes) != h.Blocks()*sha256.Size {
+		return nil, fmt.Errorf("invalid parity header")
+	}
+	return h, nil
+}
+
+// parityBlock returns parity block p, or nil when it's damaged or missing.
+func (h *ParityHeader) parityBlock(parity []byte, start, p int) []byte {
+	at := start + p*(sha256.Size+h.BlockSize)
+	if at+sha256.Size+h.BlockSize > len(parity) {
+		return nil
+	}
+	block := parity[at+sha256.Size : at+sha256.Size+h.BlockSize]
+	if sum := sha256.Sum256(block); !bytes.Equal(sum[:], parity[at:at+sha256.Size]) {
+		return nil
+	}
+	return block
+}
+
+// CheckParity reports how many of the parity file's own blocks are damaged.
+func CheckParity(parity []byte) (*ParityHeader, int, error) {
+	h, start, err := ReadParityHeader(parity)
+	if err != nil {
+		return nil, 0, err
+	}
+	damaged := 0
+	for s := 0; s < h.Stripes; s++ {
+		blocks, first := h.stripe(s)
+		for p := 0; p < parityCount(len(blocks), h.Percent); p++ {
+			if h.parityBlock(parity, start, first+p) == nil {
+				damaged++
+			}
+		}
+	}
+	return h, damaged, nil
+}
+
+// RepairParity checks data block by block against the parity made for it by
+// WriteParity and rebuilds the blocks that don't match. It returns the
+// repaired data and how many blocks were repaired; data that matches comes
+// back as it is.
+func RepairParity(data, parity []byte) ([]byte, int, error) {
+	h, start, err := ReadParityHeader(parity)
+	if err != nil {
+		return nil, 0, err
+	}
+	if int64(len(data)) == h.Size && HashBytes(data) == h.Checksum {
+		return data, 0, nil
+	}
+
+	fixed := make([]byte, h.Size)
+	copy(fixed, data)
+	blockData := func(i int) []byte {
+		return fixed[int64(i)*int64(h.BlockSize) : int64(i)*int64(h.BlockSize)+int64(h.blockLen(i))]
+	}
+	damaged := 0
+	for s := 0; s < h.Stripes; s++ {
+		blocks, first := h.stripe(s)
+		var bad []int // Positions in the stripe
+		for j, i := range blocks {
+			sum := sha256.Sum256(blockData(i))
+			if !bytes.Equal(sum[:], h.Hashes[i*sha256.Size:(i+1)*sha256.Size]) {
+				bad = append(bad, j)
+			}
+		}
+		if len(bad) == 0 {
+			continue
+		}
+		m := parityCount(len(blocks), h.Percent)
+		var rows []int
+		var parityBlocks [][]byte
+		for p := 0; p < m && len(rows) < len(bad); p++ {
+			if block := h.parityBlock(parity, start, first+p); block != nil {
+				rows = append(rows, p)
+				parityBlocks = append(parityBlocks, block)
+			}
+		}
+		if len(rows) < len(bad) {
+			return nil, 0, fmt.Errorf("%s is too damaged to repair: %d blocks in one stripe are damaged, and its parity can rebuild %d", h.Name, len(bad), len(rows))
+		}
+		if err := repairStripe(h, blocks, bad, rows, parityBlocks, m, blockData); err != nil {
+			return nil, 0, err
+		}
+		damaged += len(bad)
+	}
+	if HashBytes(fixed) != h.Checksum {
+		return nil, 0, fmt.Errorf("%s couldn't be repaired: the parity is for a different file", h.Name)
+	}
+	return fixed, damaged, nil
+}
+
+// repairStripe rebuilds the damaged blocks of a stripe. Each parity block p
+// is the sum of cauchy(p, j) times block j, so taking away the intact blocks
+// leaves as many equations as damaged blocks, and a Cauchy matrix's square
+// parts can always be inverted.
+func repairStripe(h *ParityHeader, blocks, bad, rows []int, parityBlocks [][]byte, m int, blockData func(int) []byte) error {
+	isBad := make(map[int]bool, len(bad))
+	for _, j := range bad {
+		isBad[j] = true
+	}
+	rhs := make([][]byte, len(rows))
+	for r, p := range rows {
+		rhs[r] = append([]byte{}, parityBlocks[r]...)
+		for j, i := range blocks {
+			if !isBad[j] {
+				block := make([]byte, h.BlockSize)
+				copy(block, blockData(i))
+				gf256MulAdd(rhs[r], block, cauchy(p, j, m))
+			}
+		}
+	}
+	matrix := make([][]byte, len(rows))
+	for r, p := range rows {
+		matrix[r] = make([]byte, len(bad))
+		for c, j := range bad {
+			matrix[r][c] = cauchy(p, j, m)
+		}
+	}
+	inverse, err := gf256Invert(matrix)
+	if err != nil {
+		return err
+	}
+	for c, j := range bad {
+		block := make([]byte, h.BlockSize)
+		for r := range rows {
+			gf256MulAdd(block, rhs[r], inverse[c][r])
+		}
+		copy(blockData(blocks[j]), block)
+	}
+	return nil
+}
+
+// cauchy returns the coefficient of block j in parity block p of a stripe
+// with m parity blocks: 1 / (x_p + y_j), with x_p = p and y_j = m + j all
+// different, so no sum is zero.
+func cauchy(p, j, m int) byte {
+	return gf256Inv(byte(p) ^ byte(m+j))
+}
+
+// GF(256) with the primitive polynomial x^8 + x^4 + x^3 + x^2 + 1.
+var gf256Exp, gf256Log = func() ([510]byte, [256]byte) {
+	var exp [510]byte
+	var log [256]byte
+	x := 1
+	for i := 0; i < 255; i++ {
+		exp[i] = byte(x)
+		exp[i+255] = byte(x)
+		log[x] = byte(i)
+		x <<= 1
+		if x&0x100 != 0 {
+			x ^= 0x11d
+		}
+	}
+	return exp, log
+}()
+
+func gf256Mul(a, b byte) byte {
+	if a == 0 || b == 0 {
+		return 0
+	}
+	return gf256Exp[int(gf256Log[a])+int(gf256Log[b])]
+}
+
+func gf256Inv(a byte) byte {
+	return gf256Exp[255-int(gf256Log[a])]
+}
+
+// gf256MulAdd adds c times src to dst.
+func gf256MulAdd(dst, src []byte, c byte) {
+	if c == 0 {
+		return
+	}
+	var table [256]byte
+	for x := 1; x < 256; x++ {
+		table[x] = gf256Mul(byte(x), c)
+	}
+	for i, x := range src {
+		dst[i] ^= table[x]
+	}
+}
+
+// gf256Invert inverts a square matrix by Gauss-Jordan elimination.
+func gf256Invert(matrix [][]byte) ([][]byte, error) {
+	n := len(matrix)
+	a := make([][]byte, n)
+	for i := range matrix {
+		a[i] = make([]byte, 2*n)
+		copy(a[i], matrix[i])
+		a[i][n+i] = 1
+	}
+	for col := 0; col < n; col++ {
+		pivot := col
+		for pivot < n && a[pivot][col] == 0 {
+			pivot++
+		}
+		if pivot == n {
+			return nil, fmt.Errorf("parity matrix can't be inverted")
+		}
+		a[col], a[pivot] = a[pivot], a[col]
+		scale := gf256Inv(a[col][col])
+		for k := range a[col] {
+			a[col][k] = gf256Mul(a[col][k], scale)
+		}
+		for row := 0; row < n; row++ {
+			if row != col && a[row][col] != 0 {
+				f := a[row][col]
+				for k := range a[row] {
+					a[row][k] ^= gf256Mul(f, a[col][k])
+				}
+			}
+		}
+	}
+	inverse := make([][]byte, n)
+	for i := range a {
+		inverse[i] = a[i][n:]
+	}
+	return inverse, nil
+}
+
+// JoinVolumesWithParity is JoinVolumes for a file with parity: volumes
+// that don't match their index are joined anyway, and the parity repairs
+// them. Without parity it is JoinVolumes. It also returns how many blocks
+// were repaired.
+func JoinVolumesWithParity(parts []VolumePart, index *VolumeIndex, parity []byte) ([]byte, int, error) {
+	data, err := JoinVolumes(parts, index)
+	if parity == nil {
+		return data, 0, err
+	}
+	if err != nil {
+		joined, joinErr := JoinVolumes(parts, nil)
+		if joinErr != nil {
+			return nil, 0, err
+		}
+		data = joined
+	}
+	return RepairParity(data, parity)
+}
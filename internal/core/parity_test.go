@@ -0,0 +1,117 @@
+package core
+
+import (
+	"bytes"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+// parityForTest returns data of size random bytes and its parity.
+func parityForTest(t *testing.T, size, percent int) ([]byte, []byte) {
+	t.Helper()
+	data := make([]byte, size)
+	rand.New(rand.NewSource(int64(size))).Read(data)
+	var parity bytes.Buffer
+	h, err := WriteParity(&parity, bytes.NewReader(data), "MANIFEST.age", int64(size), percent)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h.Checksum != HashBytes(data) {
+		t.Fatalf("checksum = %s", h.Checksum)
+	}
+	return data, parity.Bytes()
+}
+
+func TestRepairParity(t *testing.T) {
+	data, parity := parityForTest(t, 1_000_003, 10)
+	h, _, err := ReadParityHeader(parity)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h.Blocks() != 245 || h.Stripes != 2 {
+		t.Fatalf("%d blocks in %d stripes", h.Blocks(), h.Stripes)
+	}
+	if size := ParitySize(int64(len(data)), 10); size < int64(len(parity)) {
+		t.Errorf("ParitySize = %d, but the parity is %d bytes", size, len(parity))
+	}
+
+	// Intact data comes back as it is
+	got, repaired, err := RepairParity(data, parity)
+	if err != nil || repaired != 0 || !bytes.Equal(got, data) {
+		t.Fatalf("intact: %d repaired, %v", repaired, err)
+	}
+
+	// A run of damage, as from a bad sector, spreads over both stripes;
+	// the last block is damaged too, and the end is cut off
+	damaged := append([]byte{}, data[:len(data)-2]...)
+	for i := 40_000; i < 80_000; i++ {
+		damaged[i] ^= 0xff
+	}
+	damaged[len(damaged)-1] ^= 1
+	got, repaired, err = RepairParity(damaged, parity)
+	if err != nil || !bytes.Equal(got, data) {
+		t.Fatalf("damaged: %v", err)
+	}
+	if repaired != 12 {
+		t.Errorf("repaired %d blocks, want 12", repaired)
+	}
+
+	// Too much damage for the parity
+	damaged = append([]byte{}, data...)
+	for i := 0; i < 300_000; i++ {
+		damaged[i] = 0
+	}
+	if _, _, err := RepairParity(damaged, parity); err == nil || !strings.Contains(err.Error(), "too damaged") {
+		t.Errorf("too damaged: %v", err)
+	}
+}
+
+func TestParityDamaged(t *testing.T) {
+	data, parity := parityForTest(t, 50_000, 20)
+	damagedData := append([]byte{}, data...)
+	damagedData[10] ^= 1
+
+	// The first header copy damaged: the second one is read
+	p := append([]byte{}, parity...)
+	p[100] ^= 1
+	if got, n, err := RepairParity(damagedData, p); err != nil || n != 1 || !bytes.Equal(got, data) {
+		t.Errorf("first header damaged: %d, %v", n, err)
+	}
+
+	// A damaged parity block is skipped, and the other one repairs
+	_, start, _ := ReadParityHeader(parity)
+	p = append([]byte{}, parity...)
+	p[start+40] ^= 1
+	if _, n, err := CheckParity(p); err != nil || n != 1 {
+		t.Errorf("CheckParity = %d, %v", n, err)
+	}
+	if got, _, err := RepairParity(damagedData, p); err != nil || !bytes.Equal(got, data) {
+		t.Errorf("parity block damaged: %v", err)
+	}
+
+	// Parity of another file doesn't repair this one
+	_, other := parityForTest(t, 50_001, 20)
+	if _, _, err := RepairParity(damagedData, other); err == nil {
+		t.Error("repaired with another file's parity")
+	}
+	if _, _, err := ReadParityHeader([]byte("not parity\n")); err == nil {
+		t.Error("read a header from text")
+	}
+}
+
+func TestJoinVolumesWithParity(t *testing.T) {
+	data, parity := parityForTest(t, 30_000, 10)
+	parts, index := splitForTest(t, data, 12_000)
+	damaged := append([]byte{}, parts[1].Data...)
+	damaged[5] ^= 1
+	parts[1].Data = damaged
+
+	if _, err := JoinVolumes(parts, index); err == nil {
+		t.Fatal("joined a damaged volume")
+	}
+	got, repaired, err := JoinVolumesWithParity(parts, index, parity)
+	if err != nil || repaired != 1 || !bytes.Equal(got, data) {
+		t.Errorf("with parity: %d, %v", repaired, err)
+	}
+}
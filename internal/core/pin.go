@@ -0,0 +1,135 @@
+package core
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+// A piece can be locked with a short PIN its owner tells the holder in
+// person, so a stolen envelope or bundle isn't usable on its own. The PIN,
+// stretched with scrypt and a salt of the piece's own, gives a key stream as
+// long as the piece's data, which is XORed into it: the locked piece is the
+// same size and prints as the same share block and QR code. Two more bytes
+// of the stream are kept as a check, so a mistyped PIN is almost always
+// caught before combining; the rest slip through to the MAC check.
+//
+// scrypt makes each guess slow, but a six-digit PIN is still found by
+// someone willing to spend a day or so of computing on one stolen piece. A PIN
+// buys time to notice and reseal, not lasting secrecy.
+const (
+	pinDigits    = 6
+	pinSaltSize  = 8 // Random bytes of salt per piece
+	pinCheckSize = 2 // Bytes of key stream kept to catch a wrong PIN
+
+	pinScryptN = 1 << 15
+	pinScryptR = 8
+	pinScryptP = 1
+)
+
+// ErrWrongPIN is returned when a PIN doesn't unlock a piece.
+var ErrWrongPIN = errors.New("wrong PIN")
+
+// errLockedShare is returned for the forms that can't record that a piece is
+// locked with a PIN.
+var errLockedShare = fmt.Errorf("pieces locked with a PIN can't be written in this form, which doesn't record the lock")
+
+// NewPIN returns a random six-digit PIN, written as two groups of three
+// such as "482-107".
+func NewPIN() (string, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(1_000_000))
+	if err != nil {
+		return "", fmt.Errorf("generating PIN: %w", err)
+	}
+	digits := fmt.Sprintf("%0*d", pinDigits, n.Int64())
+	return digits[:3] + "-" + digits[3:], nil
+}
+
+// normalizePIN keeps only the digits of a PIN as typed, so "482-107" and
+// "482 107" are the same PIN.
+func normalizePIN(pin string) string {
+	var sb strings.Builder
+	for _, r := range pin {
+		if r >= '0' && r <= '9' {
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
+
+// Locked reports whether the share's data is locked with a PIN.
+func (s *Share) Locked() bool {
+	return s.PINSalt != ""
+}
+
+// LockWithPIN locks the share's data with pin and records the salt and check
+// needed to unlock it. Bind the share after locking, so its bundle tag covers
+// the data as printed.
+func (s *Share) LockWithPIN(pin string) error {
+	if s.Locked() {
+		return fmt.Errorf("piece %d is already locked", s.Index)
+	}
+	if len(normalizePIN(pin)) < 4 {
+		return fmt.Errorf("a PIN needs at least 4 digits")
+	}
+	salt := make([]byte, pinSaltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return fmt.Errorf("generating PIN salt: %w", err)
+	}
+	stream, check, err := pinStream(pin, salt, len(s.Data))
+	if err != nil {
+		return err
+	}
+	s.Data = xorBytes(s.Data, stream)
+	s.Checksum = HashBytes(s.Data)
+	s.PINSalt, s.PINCheck = hex.EncodeToString(salt), hex.EncodeToString(check)
+	return nil
+}
+
+// UnlockPIN unlocks the share's data with pin, returning ErrWrongPIN when
+// the check doesn't match. A share that isn't locked is left as it is.
+func (s *Share) UnlockPIN(pin string) error {
+	if !s.Locked() {
+		return nil
+	}
+	salt, err := hex.DecodeString(s.PINSalt)
+	if err != nil || len(salt) != pinSaltSize {
+		return fmt.Errorf("piece %d: invalid PIN salt %q", s.Index, s.PINSalt)
+	}
+	stream, check, err := pinStream(pin, salt, len(s.Data))
+	if err != nil {
+		return err
+	}
+	if !hmac.Equal([]byte(hex.EncodeToString(check)), []byte(s.PINCheck)) {
+		return ErrWrongPIN
+	}
+	s.Data = xorBytes(s.Data, stream)
+	s.Checksum = HashBytes(s.Data)
+	s.PINSalt, s.PINCheck = "", ""
+	return nil
+}
+
+// pinStream stretches pin with salt into n bytes of key stream and the
+// check that follows them.
+func pinStream(pin string, salt []byte, n int) (stream, check []byte, err error) {
+	out, err := scrypt.Key([]byte(normalizePIN(pin)), salt, pinScryptN, pinScryptR, pinScryptP, n+pinCheckSize)
+	if err != nil {
+		return nil, nil, fmt.Errorf("stretching PIN: %w", err)
+	}
+	return out[:n], out[n:], nil
+}
+
+// xorBytes returns a XOR b, which have the same length.
+func xorBytes(a, b []byte) []byte {
+	out := make([]byte, len(a))
+	for i := range a {
+		out[i] = a[i] ^ b[i]
+	}
+	return out
+}
@@ -0,0 +1,73 @@
+package core
+
+import (
+	"bytes"
+	"errors"
+	"regexp"
+	"testing"
+)
+
+func TestNewPIN(t *testing.T) {
+	pin, err := NewPIN()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !regexp.MustCompile(`^\d{3}-\d{3}$`).MatchString(pin) {
+		t.Errorf("NewPIN() = %q, want ddd-ddd", pin)
+	}
+}
+
+func TestLockWithPIN(t *testing.T) {
+	data := bytes.Repeat([]byte{0xA5}, 33)
+	share := NewShare(2, 2, 3, 2, "Bob", bytes.Clone(data))
+	if err := share.LockWithPIN("482-107"); err != nil {
+		t.Fatal(err)
+	}
+	if !share.Locked() || bytes.Equal(share.Data, data) || len(share.Data) != len(data) {
+		t.Fatalf("locked share: locked=%v, data %x", share.Locked(), share.Data)
+	}
+	if share.Verify() != nil {
+		t.Error("checksum should cover the locked data")
+	}
+	if _, err := share.Words(); err == nil {
+		t.Error("Words should refuse a locked piece")
+	}
+	if _, err := share.Digits(); err == nil {
+		t.Error("Digits should refuse a locked piece")
+	}
+	if _, err := share.Bech32(); err == nil {
+		t.Error("Bech32 should refuse a locked piece")
+	}
+
+	// The lock survives both the PEM block and the compact form
+	fromPEM, err := ParseShare([]byte(share.Encode()))
+	if err != nil {
+		t.Fatalf("ParseShare: %v", err)
+	}
+	share.Bind("0123456789abcdef")
+	share.Group, share.Groups, share.GroupsNeeded = 1, 2, 2
+	fromCompact, err := ParseCompact(share.CompactEncode())
+	if err != nil {
+		t.Fatalf("ParseCompact: %v", err)
+	}
+	if fromCompact.Group != 1 || fromCompact.BundleID == "" {
+		t.Errorf("compact lost other fields: %+v", fromCompact)
+	}
+
+	for name, got := range map[string]*Share{"pem": fromPEM, "compact": fromCompact} {
+		if !got.Locked() || got.PINSalt != share.PINSalt || got.PINCheck != share.PINCheck {
+			t.Errorf("%s: lock %q %q, want %q %q", name, got.PINSalt, got.PINCheck, share.PINSalt, share.PINCheck)
+			continue
+		}
+		if err := got.UnlockPIN("000-000"); !errors.Is(err, ErrWrongPIN) {
+			t.Errorf("%s: wrong PIN: got %v", name, err)
+		}
+		// Separators don't matter
+		if err := got.UnlockPIN("482 107"); err != nil {
+			t.Fatalf("%s: UnlockPIN: %v", name, err)
+		}
+		if got.Locked() || !bytes.Equal(got.Data, data) || got.Verify() != nil {
+			t.Errorf("%s: unlocked data %x, want %x", name, got.Data, data)
+		}
+	}
+}
@@ -0,0 +1,205 @@
+package core
+
+import (
+	"crypto/hkdf"
+	"crypto/mlkem"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+
+	"filippo.io/age"
+	"golang.org/x/crypto/chacha20poly1305"
+	"golang.org/x/crypto/scrypt"
+)
+
+// A project can seal in a post-quantum hybrid mode. scrypt and
+// ChaCha20-Poly1305, which lock MANIFEST.age, are symmetric and already stand
+// up to a quantum computer; what an attacker recording files today could
+// break later is an elliptic-curve key exchange. The hybrid mode leaves none
+// in the path to the archive: MANIFEST.age's file key is wrapped in a single
+// stanza whose key is derived from both scrypt of the passphrase and an
+// ML-KEM-768 shared secret, and recipients must be age's hybrid
+// ML-KEM-768 + X25519 keys ("age1pq1..."). The ML-KEM key pair comes from the
+// passphrase, so recovery still needs nothing but the pieces.
+//
+// The stanza is
+//
+//	-> rememory-pq <salt> <log2 N>
+//	<ML-KEM-768 ciphertext || ChaCha20-Poly1305(wrap key, file key)>
+//
+// where the scrypt key k = scrypt(passphrase, label || salt, N, 8, 1), the
+// ML-KEM seed = HKDF-SHA-256(k, salt, "rememory pq seed"), and the wrap key
+// = HKDF-SHA-256(k || shared secret, salt, "rememory pq wrap"). Like age's
+// scrypt stanza, it must be the only one in the file.
+
+// PQStanzaType is the type of the hybrid mode's stanza in MANIFEST.age.
+const PQStanzaType = "rememory-pq"
+
+const (
+	pqLabel             = "rememory.app/pq"
+	pqSaltSize          = 16
+	pqFileKeySize       = 16 // age's file key
+	pqDefaultWorkFactor = 18 // age's default for scrypt
+	pqMaxWorkFactor     = 22 // age's default limit for scrypt identities
+)
+
+// pqRecipient wraps an age file key for the hybrid mode.
+type pqRecipient struct {
+	password   []byte
+	workFactor int
+}
+
+// pqIdentity unwraps a file key wrapped by a pqRecipient.
+type pqIdentity struct {
+	password []byte
+}
+
+// pqKeys derives the scrypt key and the ML-KEM decapsulation key for salt.
+func pqKeys(password, salt []byte, logN int) ([]byte, *mlkem.DecapsulationKey768, error) {
+	k, err := scrypt.Key(password, append([]byte(pqLabel), salt...), 1<<logN, 8, 1, chacha20poly1305.KeySize)
+	if err != nil {
+		return nil, nil, fmt.Errorf("deriving scrypt key: %w", err)
+	}
+	seed, err := hkdf.Key(sha256.New, k, salt, "rememory pq seed", mlkem.SeedSize)
+	if err != nil {
+		return nil, nil, err
+	}
+	dk, err := mlkem.NewDecapsulationKey768(seed)
+	if err != nil {
+		return nil, nil, fmt.Errorf("deriving ML-KEM key: %w", err)
+	}
+	return k, dk, nil
+}
+
+// pqWrapKey combines the scrypt key and the ML-KEM shared secret.
+func pqWrapKey(k, shared, salt []byte) ([]byte, error) {
+	return hkdf.Key(sha256.New, append(append([]byte{}, k...), shared...), salt, "rememory pq wrap", chacha20poly1305.KeySize)
+}
+
+func (r *pqRecipient) Wrap(fileKey []byte) ([]*age.Stanza, error) {
+	salt := make([]byte, pqSaltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	k, dk, err := pqKeys(r.password, salt, r.workFactor)
+	if err != nil {
+		return nil, err
+	}
+	shared, ciphertext := dk.EncapsulationKey().Encapsulate()
+	wrapKey, err := pqWrapKey(k, shared, salt)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := chacha20poly1305.New(wrapKey)
+	if err != nil {
+		return nil, err
+	}
+	body := aead.Seal(ciphertext, make([]byte, chacha20poly1305.NonceSize), fileKey, nil)
+	return []*age.Stanza{{
+		Type: PQStanzaType,
+		Args: []string{base64.RawStdEncoding.EncodeToString(salt), strconv.Itoa(r.workFactor)},
+		Body: body,
+	}}, nil
+}
+
+// WrapWithLabels gives the stanza a random label, as age does for scrypt, so
+// no other recipient can be added next to it.
+func (r *pqRecipient) WrapWithLabels(fileKey []byte) ([]*age.Stanza, []string, error) {
+	stanzas, err := r.Wrap(fileKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	random := make([]byte, 16)
+	if _, err := rand.Read(random); err != nil {
+		return nil, nil, err
+	}
+	return stanzas, []string{base64.RawStdEncoding.EncodeToString(random)}, nil
+}
+
+var pqDigits = regexp.MustCompile(`^[1-9][0-9]*$`)
+
+func (i *pqIdentity) Unwrap(stanzas []*age.Stanza) ([]byte, error) {
+	for _, s := range stanzas {
+		if s.Type != PQStanzaType {
+			continue
+		}
+		if len(stanzas) != 1 {
+			return nil, errors.New("a post-quantum passphrase stanza must be the only one")
+		}
+		return i.unwrap(s)
+	}
+	return nil, fmt.Errorf("%w: file is not sealed in post-quantum mode", age.ErrIncorrectIdentity)
+}
+
+func (i *pqIdentity) unwrap(s *age.Stanza) ([]byte, error) {
+	if len(s.Args) != 2 || !pqDigits.MatchString(s.Args[1]) {
+		return nil, errors.New("invalid post-quantum stanza")
+	}
+	salt, err := base64.RawStdEncoding.Strict().DecodeString(s.Args[0])
+	if err != nil || len(salt) != pqSaltSize {
+		return nil, errors.New("invalid post-quantum stanza salt")
+	}
+	logN, err := strconv.Atoi(s.Args[1])
+	if err != nil || logN > pqMaxWorkFactor {
+		return nil, fmt.Errorf("post-quantum stanza work factor too large: %s", s.Args[1])
+	}
+	if len(s.Body) != mlkem.CiphertextSize768+pqFileKeySize+chacha20poly1305.Overhead {
+		return nil, errors.New("invalid post-quantum stanza: wrong body size")
+	}
+
+	k, dk, err := pqKeys(i.password, salt, logN)
+	if err != nil {
+		return nil, err
+	}
+	shared, err := dk.Decapsulate(s.Body[:mlkem.CiphertextSize768])
+	if err != nil {
+		return nil, fmt.Errorf("invalid post-quantum stanza: %w", err)
+	}
+	wrapKey, err := pqWrapKey(k, shared, salt)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := chacha20poly1305.New(wrapKey)
+	if err != nil {
+		return nil, err
+	}
+	fileKey, err := aead.Open(nil, make([]byte, chacha20poly1305.NonceSize), s.Body[mlkem.CiphertextSize768:], nil)
+	if err != nil {
+		// As with scrypt, wrap ErrIncorrectIdentity so other identities are tried
+		return nil, fmt.Errorf("%w: incorrect passphrase", age.ErrIncorrectIdentity)
+	}
+	return fileKey, nil
+}
+
+// passphraseIdentities returns the identities a passphrase opens
+// MANIFEST.age with: age's scrypt, or the hybrid mode's stanza.
+func passphraseIdentities(passphrase string) ([]age.Identity, error) {
+	if passphrase == "" {
+		return nil, ErrEmptyPassphrase
+	}
+	scryptID, err := age.NewScryptIdentity(passphrase)
+	if err != nil {
+		return nil, fmt.Errorf("creating identity: %w", err)
+	}
+	return []age.Identity{scryptID, &pqIdentity{password: []byte(passphrase)}}, nil
+}
+
+// EncryptWriterPQ is EncryptWriter in the post-quantum hybrid mode.
+func EncryptWriterPQ(dst io.Writer, passphrase string, workFactor int) (io.WriteCloser, error) {
+	if passphrase == "" {
+		return nil, ErrEmptyPassphrase
+	}
+	if workFactor == 0 {
+		workFactor = pqDefaultWorkFactor
+	}
+	writer, err := age.Encrypt(dst, &pqRecipient{password: []byte(passphrase), workFactor: workFactor})
+	if err != nil {
+		return nil, fmt.Errorf("creating encryptor: %w", err)
+	}
+	return writer, nil
+}
@@ -0,0 +1,101 @@
+package core
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"filippo.io/age"
+)
+
+func TestPostQuantum(t *testing.T) {
+	const passphrase = "correct horse battery staple"
+	secret := []byte("the combination is 12-34-56")
+
+	var sealed bytes.Buffer
+	w, err := EncryptWriterPQ(&sealed, passphrase, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Write(secret)
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// The header names the hybrid stanza alone, with its work factor
+	info, err := InspectManifest(bytes.NewReader(sealed.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(info.Recipients) != 1 || info.Recipients[0] != PQStanzaType || info.WorkFactor != 10 {
+		t.Errorf("header: %+v", info)
+	}
+	if err := CheckRestrictedManifest(sealed.Bytes()); err == nil {
+		t.Error("the restricted profile accepted the hybrid stanza")
+	}
+
+	// Decrypt and DecryptBytes open either kind of file with the passphrase
+	got, err := DecryptBytes(sealed.Bytes(), passphrase)
+	if err != nil {
+		t.Fatalf("DecryptBytes: %v", err)
+	}
+	if !bytes.Equal(got, secret) {
+		t.Errorf("got %q, want %q", got, secret)
+	}
+	var streamed bytes.Buffer
+	if err := Decrypt(&streamed, bytes.NewReader(sealed.Bytes()), passphrase); err != nil || !bytes.Equal(streamed.Bytes(), secret) {
+		t.Errorf("Decrypt: %q, %v", streamed.Bytes(), err)
+	}
+	var classic bytes.Buffer
+	if err := Encrypt(&classic, bytes.NewReader(secret), passphrase); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := DecryptBytes(classic.Bytes(), passphrase); err != nil || !bytes.Equal(got, secret) {
+		t.Errorf("scrypt file: %q, %v", got, err)
+	}
+
+	if _, err := DecryptBytes(sealed.Bytes(), "wrong passphrase"); err == nil {
+		t.Error("a wrong passphrase opened the file")
+	}
+	if err := Decrypt(io.Discard, bytes.NewReader(sealed.Bytes()), ""); err != ErrEmptyPassphrase {
+		t.Errorf("empty passphrase: got %v", err)
+	}
+
+	// The stanza must be alone, like scrypt's
+	id := &pqIdentity{password: []byte(passphrase)}
+	stanzas, err := (&pqRecipient{password: []byte(passphrase), workFactor: 10}).Wrap(make([]byte, pqFileKeySize))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := id.Unwrap(append(stanzas, &age.Stanza{Type: "X25519"})); err == nil || !strings.Contains(err.Error(), "only one") {
+		t.Errorf("with another stanza: got %v", err)
+	}
+	stanzas[0].Body[0] ^= 1
+	if _, err := id.Unwrap(stanzas); err == nil {
+		t.Error("a tampered ML-KEM ciphertext unwrapped")
+	}
+}
+
+func TestParseHybridRecipient(t *testing.T) {
+	id, err := age.GenerateHybridIdentity()
+	if err != nil {
+		t.Fatal(err)
+	}
+	key := id.Recipient().String()
+	if !IsHybridRecipient(key) {
+		t.Errorf("IsHybridRecipient(%q) = false", key)
+	}
+	recipients, err := ParseRecipients([]string{key})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var encrypted bytes.Buffer
+	if err := EncryptToRecipients(&encrypted, strings.NewReader("passphrase"), recipients); err != nil {
+		t.Fatal(err)
+	}
+	got, err := DecryptWithIdentities(encrypted.Bytes(), []age.Identity{id})
+	if err != nil || string(got) != "passphrase" {
+		t.Errorf("got %q, %v", got, err)
+	}
+}
@@ -0,0 +1,66 @@
+package core
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// CryptoRestricted is the crypto profile for regulated environments. It
+// allows only the primitives in RestrictedPrimitives, with no optional
+// extras, so the set can be written down once and relied on.
+const CryptoRestricted = "restricted"
+
+// RestrictedMinWorkFactor is the lowest scrypt log2(N) the restricted profile
+// accepts. It is age's default, which Encrypt uses unless a recovery-time
+// budget tunes it (see TuneWorkFactor).
+const RestrictedMinWorkFactor = 18
+
+// RestrictedPrimitives documents every primitive a restricted project uses.
+var RestrictedPrimitives = []string{
+	"Random passphrase: 32 bytes from the operating system's CSPRNG",
+	"Secret sharing: Shamir over GF(2^8), share protocol v2 (raw 32-byte secret)",
+	"Integrity: SHA-256 checksums of shares and MANIFEST.age",
+	"Encryption: age v1, a single scrypt recipient (N=2^18 or higher, r=8, p=1)",
+	"Inside age: HKDF-SHA-256, HMAC-SHA-256, ChaCha20-Poly1305",
+}
+
+// ValidCryptoProfile reports whether profile is empty (the standard set) or
+// a profile this version knows.
+func ValidCryptoProfile(profile string) error {
+	if profile != "" && profile != CryptoRestricted {
+		return fmt.Errorf("unknown crypto profile %q (the only one is %q)", profile, CryptoRestricted)
+	}
+	return nil
+}
+
+// CheckRestrictedShare rejects a share that the restricted profile doesn't allow.
+func CheckRestrictedShare(s *Share) error {
+	if s.Version != 2 {
+		return fmt.Errorf("piece %d uses share protocol v%d; the restricted crypto profile only allows v2", s.Index, s.Version)
+	}
+	return nil
+}
+
+// CheckRestrictedManifest rejects an encrypted manifest that the restricted
+// profile doesn't allow: anything but one scrypt recipient at the full work factor.
+func CheckRestrictedManifest(data []byte) error {
+	return CheckRestrictedManifestReader(bytes.NewReader(data))
+}
+
+// CheckRestrictedManifestReader is CheckRestrictedManifest for a manifest
+// read from r. Only the header is read.
+func CheckRestrictedManifestReader(r io.Reader) error {
+	info, err := InspectManifest(r)
+	if err != nil {
+		return err
+	}
+	if len(info.Recipients) != 1 || info.Recipients[0] != "scrypt" {
+		return fmt.Errorf("MANIFEST.age is encrypted to %s; the restricted crypto profile only allows a single scrypt recipient", strings.Join(info.Recipients, ", "))
+	}
+	if info.WorkFactor < RestrictedMinWorkFactor {
+		return fmt.Errorf("MANIFEST.age uses scrypt work factor %d; the restricted crypto profile requires at least %d", info.WorkFactor, RestrictedMinWorkFactor)
+	}
+	return nil
+}
@@ -0,0 +1,164 @@
+package core
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	// MaxQRContent is the most a single printed QR code holds. Past it the
+	// modules get small enough that home printers and phone cameras start to
+	// miss them, so SplitQR spreads the content over several codes. 180 bytes
+	// is a version 9 code at medium error correction.
+	MaxQRContent = 180
+
+	// QRChunkPrefix starts each part of content split over several QR codes:
+	// RMQ:{seq}/{total}:{id}:{part}, where id is the short checksum of the
+	// whole content, so parts of different codes can't be mixed.
+	QRChunkPrefix = "RMQ:"
+)
+
+// QRChunk is one part of content split over several QR codes.
+type QRChunk struct {
+	Seq   int
+	Total int
+	ID    string
+	Part  string
+}
+
+// SplitQR returns the content as it should be printed: on its own when it
+// fits in one QR code, otherwise as parts of about the same length, each
+// small enough for one code.
+func SplitQR(content string) []string {
+	if len(content) <= MaxQRContent {
+		return []string{content}
+	}
+	runes := []rune(content)
+	id := shortChecksum([]byte(content))
+
+	// Leave room for the longest header, with two-digit counts to spare
+	room := MaxQRContent - len(fmt.Sprintf("%s99/99:%s:", QRChunkPrefix, id))
+	total := (len(content) + room - 1) / room
+	per := (len(runes) + total - 1) / total
+	for {
+		parts := chunkRunes(runes, per)
+		fits := true
+		for _, part := range parts {
+			fits = fits && len(part) <= room
+		}
+		if fits {
+			chunks := make([]string, len(parts))
+			for i, part := range parts {
+				chunks[i] = fmt.Sprintf("%s%d/%d:%s:%s", QRChunkPrefix, i+1, len(parts), id, part)
+			}
+			return chunks
+		}
+		// Multibyte characters made a part too long
+		per--
+	}
+}
+
+func chunkRunes(runes []rune, per int) []string {
+	var parts []string
+	for i := 0; i < len(runes); i += per {
+		parts = append(parts, string(runes[i:min(i+per, len(runes))]))
+	}
+	return parts
+}
+
+// IsQRChunk reports whether s is one part of content split over several QR codes.
+func IsQRChunk(s string) bool {
+	return strings.HasPrefix(strings.TrimSpace(s), QRChunkPrefix)
+}
+
+// ParseQRChunk reads one part of content split over several QR codes.
+func ParseQRChunk(s string) (*QRChunk, error) {
+	rest, ok := strings.CutPrefix(strings.TrimSpace(s), QRChunkPrefix)
+	if !ok {
+		return nil, fmt.Errorf("not part of a split QR code")
+	}
+	fields := strings.SplitN(rest, ":", 3)
+	if len(fields) != 3 {
+		return nil, fmt.Errorf("invalid QR code part: expected seq/total:id:part")
+	}
+	seqText, totalText, ok := strings.Cut(fields[0], "/")
+	seq, err1 := strconv.Atoi(seqText)
+	total, err2 := strconv.Atoi(totalText)
+	if !ok || err1 != nil || err2 != nil || total < 2 || seq < 1 || seq > total {
+		return nil, fmt.Errorf("invalid QR code part: bad sequence %q", fields[0])
+	}
+	if len(fields[1]) != 4 {
+		return nil, fmt.Errorf("invalid QR code part: bad id %q", fields[1])
+	}
+	return &QRChunk{Seq: seq, Total: total, ID: fields[1], Part: fields[2]}, nil
+}
+
+// JoinQRChunks puts split content back together from its parts, in any
+// order. Repeated parts, as when the same code is scanned twice, are fine.
+func JoinQRChunks(chunks []*QRChunk) (string, error) {
+	if len(chunks) == 0 {
+		return "", fmt.Errorf("no QR code parts")
+	}
+	first := chunks[0]
+	parts := make(map[int]string)
+	for _, c := range chunks {
+		if c.ID != first.ID || c.Total != first.Total {
+			return "", fmt.Errorf("QR code parts come from different codes")
+		}
+		parts[c.Seq] = c.Part
+	}
+	if missing := MissingQRChunks(chunks); len(missing) > 0 {
+		return "", fmt.Errorf("missing QR code part %s of %d", joinInts(missing), first.Total)
+	}
+
+	var sb strings.Builder
+	for seq := 1; seq <= first.Total; seq++ {
+		sb.WriteString(parts[seq])
+	}
+	content := sb.String()
+	if shortChecksum([]byte(content)) != first.ID {
+		return "", fmt.Errorf("QR code parts don't fit together: one may be misread or damaged")
+	}
+	return content, nil
+}
+
+// MissingQRChunks returns the sequence numbers of the parts not yet read,
+// judging by the first chunk's total.
+func MissingQRChunks(chunks []*QRChunk) []int {
+	if len(chunks) == 0 {
+		return nil
+	}
+	seen := make(map[int]bool)
+	for _, c := range chunks {
+		seen[c.Seq] = true
+	}
+	var missing []int
+	for seq := 1; seq <= chunks[0].Total; seq++ {
+		if !seen[seq] {
+			missing = append(missing, seq)
+		}
+	}
+	return missing
+}
+
+// GroupQRChunks sorts parts by the content they belong to, keyed by id.
+func GroupQRChunks(chunks []*QRChunk) map[string][]*QRChunk {
+	groups := make(map[string][]*QRChunk)
+	for _, c := range chunks {
+		groups[c.ID] = append(groups[c.ID], c)
+	}
+	for _, g := range groups {
+		sort.Slice(g, func(i, j int) bool { return g[i].Seq < g[j].Seq })
+	}
+	return groups
+}
+
+func joinInts(ns []int) string {
+	s := make([]string, len(ns))
+	for i, n := range ns {
+		s[i] = strconv.Itoa(n)
+	}
+	return strings.Join(s, ", ")
+}
@@ -0,0 +1,88 @@
+package core
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSplitQRShortContent(t *testing.T) {
+	content := DefaultRecoveryURL + "#share=RM2%3A1%3A5%3A3%3Aabc%3Aabcd"
+	if got := SplitQR(content); len(got) != 1 || got[0] != content {
+		t.Errorf("SplitQR = %q", got)
+	}
+}
+
+func TestSplitQRRoundTrip(t *testing.T) {
+	for _, content := range []string{
+		"https://files.example.org/" + strings.Repeat("family/", 30) + "recover.html#share=RM2%3A1%3A5%3A3%3Aabc%3Aabcd",
+		"https://例子.example/" + strings.Repeat("恢复/", 60) + "recover.html#share=x",
+	} {
+		chunks := SplitQR(content)
+		if len(chunks) < 2 {
+			t.Fatalf("content of %d bytes not split", len(content))
+		}
+		var parsed []*QRChunk
+		for _, c := range chunks {
+			if len(c) > MaxQRContent {
+				t.Errorf("chunk of %d bytes", len(c))
+			}
+			if !IsQRChunk(c) {
+				t.Errorf("IsQRChunk(%q) = false", c)
+			}
+			chunk, err := ParseQRChunk(c)
+			if err != nil {
+				t.Fatal(err)
+			}
+			parsed = append(parsed, chunk)
+		}
+
+		// Any order, with repeats
+		shuffled := append([]*QRChunk{parsed[len(parsed)-1]}, parsed...)
+		got, err := JoinQRChunks(shuffled)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != content {
+			t.Errorf("joined %q, want %q", got, content)
+		}
+
+		if _, err := JoinQRChunks(parsed[1:]); err == nil || !strings.Contains(err.Error(), "missing QR code part 1") {
+			t.Errorf("missing part: got %v", err)
+		}
+		if missing := MissingQRChunks(parsed[1:]); len(missing) != 1 || missing[0] != 1 {
+			t.Errorf("MissingQRChunks = %v", missing)
+		}
+	}
+}
+
+func TestJoinQRChunksCatchesMistakes(t *testing.T) {
+	a := SplitQR(strings.Repeat("a", 400))
+	b := SplitQR(strings.Repeat("b", 400))
+	parse := func(s string) *QRChunk {
+		c, err := ParseQRChunk(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return c
+	}
+
+	if _, err := JoinQRChunks([]*QRChunk{parse(a[0]), parse(b[1]), parse(a[2])}); err == nil {
+		t.Error("parts of different codes joined")
+	}
+	if groups := GroupQRChunks([]*QRChunk{parse(a[1]), parse(b[0]), parse(a[0])}); len(groups) != 2 {
+		t.Errorf("got %d groups", len(groups))
+	}
+
+	// A misread part with a valid header
+	damaged := parse(a[1])
+	damaged.Part = strings.Replace(damaged.Part, "a", "x", 1)
+	if _, err := JoinQRChunks([]*QRChunk{parse(a[0]), damaged, parse(a[2])}); err == nil || !strings.Contains(err.Error(), "don't fit together") {
+		t.Errorf("damaged part: got %v", err)
+	}
+
+	for _, s := range []string{"RMQ:1:abcd:x", "RMQ:3/2:abcd:x", "RMQ:1/1:abcd:x", "RMQ:1/2:abc:x", "RM2:1:2:2:abc:def"} {
+		if _, err := ParseQRChunk(s); err == nil {
+			t.Errorf("ParseQRChunk(%q) accepted", s)
+		}
+	}
+}
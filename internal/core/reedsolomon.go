@@ -0,0 +1,327 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Printed share data is written in base32, and every line carries
+// Reed-Solomon parity over GF(32), one symbol per character. A few
+// characters lost to water damage, smudges, or bad handwriting are repaired
+// while parsing; a character that can't be read at all can be written as
+// "?" and is repaired too. The share checksum still guards the result.
+
+const (
+	// PaperEncoding is the Encoding header of shares whose data is printed
+	// as base32 lines with Reed-Solomon parity.
+	PaperEncoding = "base32-rs"
+
+	paperAlphabet   = "ABCDEFGHIJKLMNOPQRSTUVWXYZ234567"
+	paperDataChars  = 20 // Data characters per line
+	paperParity     = 4  // Parity characters per line: repairs 2 misread or 4 unreadable characters
+	paperGroupChars = 4  // Characters per space-separated group
+)
+
+// Characters that aren't in the alphabet but are easily written for one
+// that is.
+var paperConfusables = map[rune]rune{'0': 'O', '1': 'I', '8': 'B'}
+
+// GF(32) with the primitive polynomial x^5 + x^2 + 1.
+var gf32Exp, gf32Log = func() ([62]byte, [32]byte) {
+	var exp [62]byte
+	var log [32]byte
+	x := 1
+	for i := 0; i < 31; i++ {
+		exp[i] = byte(x)
+		exp[i+31] = byte(x)
+		log[x] = byte(i)
+		x <<= 1
+		if x&32 != 0 {
+			x ^= 0x25
+		}
+	}
+	return exp, log
+}()
+
+func gf32Mul(a, b byte) byte {
+	if a == 0 || b == 0 {
+		return 0
+	}
+	return gf32Exp[int(gf32Log[a])+int(gf32Log[b])]
+}
+
+func gf32Div(a, b byte) byte {
+	if a == 0 {
+		return 0
+	}
+	return gf32Exp[(int(gf32Log[a])+31-int(gf32Log[b]))%31]
+}
+
+// gf32Pow returns α^n.
+func gf32Pow(n int) byte {
+	return gf32Exp[((n%31)+31)%31]
+}
+
+// Polynomials are stored highest degree first.
+
+func polyScale(p []byte, x byte) []byte {
+	r := make([]byte, len(p))
+	for i, c := range p {
+		r[i] = gf32Mul(c, x)
+	}
+	return r
+}
+
+func polyAdd(p, q []byte) []byte {
+	r := make([]byte, max(len(p), len(q)))
+	for i, c := range p {
+		r[i+len(r)-len(p)] = c
+	}
+	for i, c := range q {
+		r[i+len(r)-len(q)] ^= c
+	}
+	return r
+}
+
+func polyMul(p, q []byte) []byte {
+	r := make([]byte, len(p)+len(q)-1)
+	for j, b := range q {
+		for i, a := range p {
+			r[i+j] ^= gf32Mul(a, b)
+		}
+	}
+	return r
+}
+
+func polyEval(p []byte, x byte) byte {
+	y := p[0]
+	for _, c := range p[1:] {
+		y = gf32Mul(y, x) ^ c
+	}
+	return y
+}
+
+// rsParity returns the parity symbols for msg.
+func rsParity(msg []byte, nsym int) []byte {
+	gen := []byte{1}
+	for i := 0; i < nsym; i++ {
+		gen = polyMul(gen, []byte{1, gf32Pow(i)})
+	}
+	out := make([]byte, len(msg)+nsym)
+	copy(out, msg)
+	for i := range msg {
+		if coef := out[i]; coef != 0 {
+			for j := 1; j < len(gen); j++ {
+				out[i+j] ^= gf32Mul(gen[j], coef)
+			}
+		}
+	}
+	return out[len(msg):]
+}
+
+// rsSyndromes evaluates the codeword at the roots of the generator, with a
+// leading zero so indices line up with the locator arithmetic.
+func rsSyndromes(code []byte, nsym int) []byte {
+	synd := make([]byte, nsym+1)
+	for i := 0; i < nsym; i++ {
+		synd[i+1] = polyEval(code, gf32Pow(i))
+	}
+	return synd
+}
+
+// rsCorrect repairs code in place, given the positions of symbols known to
+// be unreadable, and returns how many symbols it changed.
+func rsCorrect(code []byte, nsym int, erasures []int) (int, error) {
+	if len(erasures) > nsym {
+		return 0, fmt.Errorf("too many unreadable characters")
+	}
+	for _, e := range erasures {
+		code[e] = 0
+	}
+	synd := rsSyndromes(code, nsym)
+	if allZero(synd) {
+		return len(erasures), nil
+	}
+
+	// Berlekamp-Massey on the syndromes with the erasures taken out
+	fsynd := append([]byte(nil), synd[1:]...)
+	for _, e := range erasures {
+		x := gf32Pow(len(code) - 1 - e)
+		for j := 0; j < len(fsynd)-1; j++ {
+			fsynd[j] = gf32Mul(fsynd[j], x) ^ fsynd[j+1]
+		}
+	}
+	errLoc, oldLoc := []byte{1}, []byte{1}
+	for i := 0; i < nsym-len(erasures); i++ {
+		delta := fsynd[i]
+		for j := 1; j < len(errLoc); j++ {
+			delta ^= gf32Mul(errLoc[len(errLoc)-1-j], fsynd[i-j])
+		}
+		oldLoc = append(oldLoc, 0)
+		if delta != 0 {
+			if len(oldLoc) > len(errLoc) {
+				newLoc := polyScale(oldLoc, delta)
+				oldLoc = polyScale(errLoc, gf32Div(1, delta))
+				errLoc = newLoc
+			}
+			errLoc = polyAdd(errLoc, polyScale(oldLoc, delta))
+		}
+	}
+	for len(errLoc) > 0 && errLoc[0] == 0 {
+		errLoc = errLoc[1:]
+	}
+	errs := len(errLoc) - 1
+	if 2*errs+len(erasures) > nsym {
+		return 0, fmt.Errorf("too many misread characters")
+	}
+
+	// Chien search for the misread positions
+	reversed := make([]byte, len(errLoc))
+	for i, c := range errLoc {
+		reversed[len(errLoc)-1-i] = c
+	}
+	positions := append([]int(nil), erasures...)
+	for i := 0; i < len(code); i++ {
+		if polyEval(reversed, gf32Pow(i)) == 0 {
+			positions = append(positions, len(code)-1-i)
+		}
+	}
+	if len(positions)-len(erasures) != errs {
+		return 0, fmt.Errorf("too many misread characters")
+	}
+
+	// Forney: the value of each error
+	coefPos := make([]int, len(positions))
+	loc := []byte{1}
+	for i, p := range positions {
+		coefPos[i] = len(code) - 1 - p
+		loc = polyMul(loc, []byte{gf32Pow(coefPos[i]), 1})
+	}
+	reversedSynd := make([]byte, len(synd))
+	for i, c := range synd {
+		reversedSynd[len(synd)-1-i] = c
+	}
+	product := polyMul(reversedSynd, loc)
+	eval := product[max(0, len(product)-len(loc)):]
+
+	for i, p := range positions {
+		xi := gf32Pow(coefPos[i])
+		xiInv := gf32Div(1, xi)
+		var prime byte = 1
+		for j := range positions {
+			if j != i {
+				prime = gf32Mul(prime, 1^gf32Mul(xiInv, gf32Pow(coefPos[j])))
+			}
+		}
+		if prime == 0 {
+			return 0, fmt.Errorf("too many misread characters")
+		}
+		code[p] ^= gf32Div(gf32Mul(xi, polyEval(eval, xiInv)), prime)
+	}
+	if !allZero(rsSyndromes(code, nsym)) {
+		return 0, fmt.Errorf("too many misread characters")
+	}
+	return len(positions), nil
+}
+
+func allZero(b []byte) bool {
+	for _, c := range b {
+		if c != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// EncodePaperLines writes data as base32 lines of up to 20 characters, each
+// followed by 4 parity characters, in groups of four.
+func EncodePaperLines(data []byte) []string {
+	symbols := bytesToSymbols(data)
+	var lines []string
+	for start := 0; start < len(symbols); start += paperDataChars {
+		msg := symbols[start:min(start+paperDataChars, len(symbols))]
+		code := append(append([]byte(nil), msg...), rsParity(msg, paperParity)...)
+		var sb strings.Builder
+		for i, s := range code {
+			if i > 0 && i%paperGroupChars == 0 {
+				sb.WriteByte(' ')
+			}
+			sb.WriteByte(paperAlphabet[s])
+		}
+		lines = append(lines, sb.String())
+	}
+	return lines
+}
+
+// DecodePaperLines reads data written by EncodePaperLines, repairing what
+// it can, and returns how many characters it repaired. Case and spacing
+// don't matter, 0, 1, and 8 are read as O, I, and B, and any other
+// character outside the alphabet is treated as unreadable.
+func DecodePaperLines(lines []string) ([]byte, int, error) {
+	var symbols []byte
+	repaired := 0
+	for n, line := range lines {
+		var code []byte
+		var erasures []int
+		for _, r := range strings.ToUpper(line) {
+			if r == ' ' || r == '\t' || r == '-' {
+				continue
+			}
+			if c, ok := paperConfusables[r]; ok {
+				r = c
+			}
+			i := strings.IndexRune(paperAlphabet, r)
+			if i == -1 {
+				erasures = append(erasures, len(code))
+				i = 0
+			}
+			code = append(code, byte(i))
+		}
+		if len(code) <= paperParity || len(code) > paperDataChars+paperParity {
+			return nil, 0, fmt.Errorf("line %d of the share data has %d characters, expected up to %d", n+1, len(code), paperDataChars+paperParity)
+		}
+		changed, err := rsCorrect(code, paperParity, erasures)
+		if err != nil {
+			return nil, 0, fmt.Errorf("line %d of the share data: %w to repair", n+1, err)
+		}
+		repaired += changed
+		symbols = append(symbols, code[:len(code)-paperParity]...)
+	}
+	if len(symbols) == 0 {
+		return nil, 0, fmt.Errorf("missing share data")
+	}
+	return symbolsToBytes(symbols), repaired, nil
+}
+
+// bytesToSymbols splits data into 5-bit symbols, zero-padding the last one.
+func bytesToSymbols(data []byte) []byte {
+	var symbols []byte
+	acc, bits := 0, 0
+	for _, b := range data {
+		acc = acc<<8 | int(b)
+		bits += 8
+		for bits >= 5 {
+			bits -= 5
+			symbols = append(symbols, byte(acc>>bits)&31)
+		}
+	}
+	if bits > 0 {
+		symbols = append(symbols, byte(acc<<(5-bits))&31)
+	}
+	return symbols
+}
+
+// symbolsToBytes joins 5-bit symbols back into bytes, dropping the padding.
+func symbolsToBytes(symbols []byte) []byte {
+	var data []byte
+	acc, bits := 0, 0
+	for _, s := range symbols {
+		acc = acc<<5 | int(s)
+		bits += 5
+		if bits >= 8 {
+			bits -= 8
+			data = append(data, byte(acc>>bits))
+		}
+	}
+	return data
+}
@@ -0,0 +1,117 @@
+package core
+
+import (
+	"bytes"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+func TestReedSolomonRepairs(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for trial := 0; trial < 5000; trial++ {
+		msg := make([]byte, 1+r.Intn(paperDataChars))
+		for i := range msg {
+			msg[i] = byte(r.Intn(32))
+		}
+		code := append(append([]byte(nil), msg...), rsParity(msg, paperParity)...)
+		want := append([]byte(nil), code...)
+
+		// Up to 2 misread characters, or fewer alongside unreadable ones
+		erased := r.Intn(paperParity + 1)
+		misread := (paperParity - erased) / 2
+		positions := r.Perm(len(code))
+		var erasures []int
+		for _, p := range positions[:erased] {
+			erasures = append(erasures, p)
+			code[p] = byte(r.Intn(32))
+		}
+		for _, p := range positions[erased : erased+misread] {
+			code[p] ^= byte(1 + r.Intn(31))
+		}
+
+		if _, err := rsCorrect(code, paperParity, erasures); err != nil || !bytes.Equal(code, want) {
+			t.Fatalf("trial %d: %d erased, %d misread: %v", trial, erased, misread, err)
+		}
+	}
+}
+
+func TestPaperLines(t *testing.T) {
+	data := make([]byte, 33)
+	for i := range data {
+		data[i] = byte(i * 37)
+	}
+	lines := EncodePaperLines(data)
+	if len(lines) != 3 || lines[0][4] != ' ' {
+		t.Fatalf("lines = %q", lines)
+	}
+
+	worn := append([]string(nil), lines...)
+	worn[0] = "?" + strings.ToLower(worn[0][1:]) // unreadable, and typed in lower case
+	worn[1] = strings.ReplaceAll(worn[1], " ", "")
+	worn[1] = flip(worn[1], 3)
+	worn[2] = strings.NewReplacer("O", "0", "I", "1", "B", "8").Replace(worn[2])
+
+	got, repaired, err := DecodePaperLines(worn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, data) {
+		t.Errorf("got %x, want %x", got, data)
+	}
+	if repaired != 2 {
+		t.Errorf("repaired %d characters, want 2", repaired)
+	}
+
+	// Three misread characters on one line are past repair
+	bad := append([]string(nil), lines...)
+	bad[1] = flip(flip(flip(bad[1], 0), 1), 2)
+	if got, _, err := DecodePaperLines(bad); err == nil && bytes.Equal(got, data) {
+		t.Error("three misread characters went unnoticed")
+	}
+	if _, _, err := DecodePaperLines([]string{"ABCD"}); err == nil {
+		t.Error("line without data accepted")
+	}
+}
+
+func flip(line string, i int) string {
+	c := byte('A')
+	if line[i] == 'A' {
+		c = 'B'
+	}
+	return line[:i] + string(c) + line[i+1:]
+}
+
+func TestShareRepairedWhileParsing(t *testing.T) {
+	share := NewShare(2, 1, 3, 2, "Alice", bytes.Repeat([]byte{0xa5}, 33))
+	encoded := share.Encode()
+	if !strings.Contains(encoded, "Encoding: "+PaperEncoding+"\n") {
+		t.Fatalf("encoded share has no Encoding header:\n%s", encoded)
+	}
+
+	lines := strings.Split(encoded, "\n")
+	for i, line := range lines {
+		if i > 0 && lines[i-1] == "" {
+			lines[i] = "?" + line[1:]
+			break
+		}
+	}
+	parsed, err := ParseShare([]byte(strings.Join(lines, "\n")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := parsed.Verify(); err != nil {
+		t.Error(err)
+	}
+	if parsed.Repaired != 1 || parsed.Encoding != PaperEncoding {
+		t.Errorf("Repaired = %d, Encoding = %q", parsed.Repaired, parsed.Encoding)
+	}
+	if parsed.Encode() != encoded {
+		t.Error("re-encoding changed the share")
+	}
+
+	// v1 shares stay in base64 for old recovery tools
+	if v1 := NewShare(1, 1, 3, 2, "", []byte("secret")).Encode(); strings.Contains(v1, "Encoding:") {
+		t.Errorf("v1 share has an Encoding header:\n%s", v1)
+	}
+}
@@ -0,0 +1,41 @@
+package core
+
+import (
+	cryptorand "crypto/rand"
+	"crypto/sha256"
+	"fmt"
+	"math/rand/v2"
+	"time"
+)
+
+// ReproducibleSeedSize is the size of a seed for EnableReproducible.
+const ReproducibleSeedSize = 32
+
+// reproducibleLabel keeps a reproducible seal's random stream apart from
+// the test builds' EnableDeterministic, whose seeds aren't secret.
+const reproducibleLabel = "rememory reproducible seal v1\x00"
+
+// EnableReproducible makes a seal reproducible, for 'rememory seal
+// --deterministic': every source of randomness is derived from seed, and the
+// clock stops at at, so sealing the same inputs with the same seed and time
+// gives identical MANIFEST.age, pieces, and bundles. Unlike
+// EnableDeterministic it is in every build, since the seed is random and
+// kept secret — but anyone who has it can rebuild the passphrase. restore
+// puts the system's randomness and clock back.
+func EnableReproducible(seed []byte, at time.Time) (restore func(), err error) {
+	if len(seed) != ReproducibleSeedSize {
+		return nil, fmt.Errorf("reproducible seed must be %d bytes, got %d", ReproducibleSeedSize, len(seed))
+	}
+	if at.IsZero() {
+		return nil, fmt.Errorf("reproducible seal needs a time")
+	}
+	original := cryptorand.Reader
+	// crypto/rand.Read (used by age, vault/shamir, and rememory itself)
+	// reads from Reader whenever it has been replaced.
+	cryptorand.Reader = rand.NewChaCha8(sha256.Sum256(append([]byte(reproducibleLabel), seed...)))
+	deterministic, fixedTime = true, at.UTC().Truncate(time.Second)
+	return func() {
+		cryptorand.Reader = original
+		deterministic, fixedTime = false, DeterministicTime
+	}, nil
+}
@@ -0,0 +1,26 @@
+package core
+
+// Secret holds key material — a recovered passphrase, the secret shares
+// combine to, a decrypted key — as bytes that can be zeroed once they're no
+// longer needed. A Go string can't be: it stays in memory, and possibly in a
+// core dump or swap, until the garbage collector reuses it. age takes its
+// passphrase as a string, so convert with string(s) only in that call.
+type Secret []byte
+
+// Wipe zeroes the secret in place.
+func (s Secret) Wipe() {
+	clear(s)
+}
+
+// String keeps a secret out of logs and error messages that format it.
+func (s Secret) String() string {
+	return "[secret]"
+}
+
+// Wipe zeroes each buffer in place: Shamir intermediates, raw passphrase
+// bytes, and anything else that held a secret on its way somewhere else.
+func Wipe(bufs ...[]byte) {
+	for _, b := range bufs {
+		clear(b)
+	}
+}
@@ -0,0 +1,54 @@
+package core
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+)
+
+func TestSecret(t *testing.T) {
+	recovered := []byte{0xde, 0xad, 0xbe, 0xef}
+	s := RecoverPassphrase(recovered, 2)
+	if string(s) != "3q2-7w" {
+		t.Fatalf("passphrase = %q", string(s))
+	}
+	if got := fmt.Sprintf("%s %v", s, s); got != "[secret] [secret]" {
+		t.Errorf("formatted = %q", got)
+	}
+
+	// v1 passphrases are copied, so wiping one leaves the other alone
+	v1 := RecoverPassphrase(recovered, 1)
+	Wipe(recovered)
+	if !bytes.Equal(v1, []byte{0xde, 0xad, 0xbe, 0xef}) {
+		t.Errorf("v1 passphrase shares memory with what was recovered")
+	}
+
+	s.Wipe()
+	v1.Wipe()
+	for _, b := range [][]byte{s, v1, recovered} {
+		if !bytes.Equal(b, make([]byte, len(b))) {
+			t.Errorf("not wiped: %x", b)
+		}
+	}
+}
+
+func TestCombineGroupsLeavesPiecesAlone(t *testing.T) {
+	// A group with a threshold of 1 is rebuilt from its one piece; wiping
+	// the intermediate must not wipe the piece
+	secret := []byte("group secret")
+	pieces, err := SplitGroups(secret, 2, []GroupSpec{{Size: 2, Threshold: 1}, {Size: 3, Threshold: 2}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	one := append([]byte(nil), pieces[0][0]...)
+	got, err := CombineGroups([][][]byte{{pieces[0][0]}, pieces[1][:2]})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, secret) {
+		t.Errorf("combined = %q", got)
+	}
+	if !bytes.Equal(pieces[0][0], one) {
+		t.Error("combining wiped the piece it was given")
+	}
+}
@@ -1,7 +1,9 @@
 package core
 
 import (
+	"crypto/rand"
 	"fmt"
+	"io"
 
 	vault "github.com/hashicorp/vault/shamir"
 )
@@ -12,6 +14,13 @@ import (
 //   - n: total number of shares to create (2-255)
 //   - k: minimum shares needed to reconstruct (2-n)
 func Split(secret []byte, n, k int) ([][]byte, error) {
+	// vault picks x coordinates with math/rand, which can't be seeded.
+	// In deterministic mode, draw the polynomial from crypto/rand's Reader,
+	// which is seeded, and put the shares at x = 1..n.
+	if deterministic {
+		return SplitFrom(rand.Reader, secret, n, k)
+	}
+
 	if err := ValidateShamirParams(n, k); err != nil {
 		return nil, err
 	}
@@ -24,6 +33,43 @@ func Split(secret []byte, n, k int) ([][]byte, error) {
 	return shares, nil
 }
 
+// SplitFrom is Split with the polynomial's coefficients read from random and
+// the shares at x = 1..n, so the same secret and random bytes always give
+// the same shares. Its shares combine like Split's.
+func SplitFrom(random io.Reader, secret []byte, n, k int) ([][]byte, error) {
+	if err := ValidateShamirParams(n, k); err != nil {
+		return nil, err
+	}
+	if len(secret) == 0 {
+		return nil, fmt.Errorf("cannot split an empty secret")
+	}
+
+	// k-1 random coefficients for each byte of the secret, which is the constant term
+	coeffs := make([]byte, len(secret)*(k-1))
+	if _, err := io.ReadFull(random, coeffs); err != nil {
+		return nil, fmt.Errorf("generating polynomial: %w", err)
+	}
+	defer Wipe(coeffs)
+
+	shares := make([][]byte, n)
+	for i := range shares {
+		x := byte(i + 1)
+		share := make([]byte, len(secret)+1)
+		share[len(secret)] = x
+		for b, s := range secret {
+			// Horner's rule, highest coefficient first
+			c := coeffs[b*(k-1) : (b+1)*(k-1)]
+			var y byte
+			for j := len(c) - 1; j >= 0; j-- {
+				y = gfMul(y, x) ^ c[j]
+			}
+			share[b] = gfMul(y, x) ^ s
+		}
+		shares[i] = share
+	}
+	return shares, nil
+}
+
 // Combine reconstructs the secret from k or more shares.
 // Returns an error if fewer than 2 shares are provided.
 // Note: If corrupted or wrong shares are provided, this may return
@@ -41,6 +87,99 @@ func Combine(shares [][]byte) ([]byte, error) {
 	return secret, nil
 }
 
+// Extend creates one more share of the same secret from existing shares,
+// without changing them. The shares passed in must be at least the original
+// threshold; with fewer, the result is a valid-looking share of a different
+// secret. The new share uses an x coordinate not taken by any given share.
+func Extend(shares [][]byte) ([]byte, error) {
+	if len(shares) < 2 {
+		return nil, fmt.Errorf("need at least 2 shares, got %d", len(shares))
+	}
+
+	size := len(shares[0])
+	if size < 2 {
+		return nil, fmt.Errorf("shares must be at least two bytes")
+	}
+	used := make(map[byte]bool, len(shares))
+	for _, s := range shares {
+		if len(s) != size {
+			return nil, fmt.Errorf("all shares must be the same length")
+		}
+		x := s[size-1]
+		if x == 0 || used[x] {
+			return nil, fmt.Errorf("duplicate or invalid share")
+		}
+		used[x] = true
+	}
+	if len(used) >= 255 {
+		return nil, fmt.Errorf("maximum 255 shares supported")
+	}
+
+	// Pick a random unused x coordinate (1-255), as Split does
+	var x byte
+	for x == 0 || used[x] {
+		var b [1]byte
+		if _, err := rand.Read(b[:]); err != nil {
+			return nil, fmt.Errorf("choosing share coordinate: %w", err)
+		}
+		x = b[0]
+	}
+
+	return interpolate(shares, x), nil
+}
+
+// interpolate evaluates the polynomial through the given shares at x, using
+// Lagrange interpolation over GF(2^8) byte by byte. It returns a share with
+// x as its tag.
+func interpolate(shares [][]byte, x byte) []byte {
+	size := len(shares[0])
+	out := make([]byte, size)
+	out[size-1] = x
+	for i, si := range shares {
+		xi := si[size-1]
+		basis := byte(1)
+		for j, sj := range shares {
+			if i == j {
+				continue
+			}
+			xj := sj[size-1]
+			basis = gfMul(basis, gfDiv(x^xj, xi^xj))
+		}
+		for b := 0; b < size-1; b++ {
+			out[b] ^= gfMul(si[b], basis)
+		}
+	}
+	return out
+}
+
+// gfMul multiplies in GF(2^8) with the AES polynomial, matching the field
+// used by the vault shamir package.
+func gfMul(a, b byte) byte {
+	var r byte
+	for b > 0 {
+		if b&1 == 1 {
+			r ^= a
+		}
+		carry := a & 0x80
+		a <<= 1
+		if carry != 0 {
+			a ^= 0x1b
+		}
+		b >>= 1
+	}
+	return r
+}
+
+// gfDiv divides in GF(2^8). b must be non-zero.
+func gfDiv(a, b byte) byte {
+	// b^254 is the multiplicative inverse of b
+	inv := byte(1)
+	for i := 0; i < 254; i++ {
+		inv = gfMul(inv, b)
+	}
+	return gfMul(a, inv)
+}
+
 // ValidateShamirParams validates the parameters for Shamir's Secret Sharing.
 func ValidateShamirParams(n, k int) error {
 	if k < 2 {
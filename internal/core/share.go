@@ -20,18 +20,40 @@ const (
 	// DefaultRecoveryURL is the default base URL for QR codes in PDFs.
 	// Points to the recover.html hosted on GitHub Pages.
 	DefaultRecoveryURL = "https://eljojo.github.io/rememory/recover.html"
+
+	// DateFormat is how review and expiry dates are written, in shares and
+	// in project.yml.
+	DateFormat = "2006-01-02"
 )
 
 // Share represents a single Shamir share with metadata.
 type Share struct {
-	Version   int       // Format version (1 or 2)
-	Index     int       // Which share (1-indexed for humans)
-	Total     int       // Total shares (N)
-	Threshold int       // Required shares (K)
-	Holder    string    // Name of the person holding this share
-	Created   time.Time // When the share was created
-	Data      []byte    // The actual share bytes
-	Checksum  string    // SHA-256 of Data
+	Version          int       // Format version (1 or 2)
+	Index            int       // Which share (1-indexed for humans)
+	Total            int       // Total shares (N)
+	Threshold        int       // Required shares (K)
+	Holder           string    // Name of the person holding this share
+	Group            int       // Group this piece belongs to (1-indexed); 0 when the project has no groups
+	Groups           int       // Number of groups; Index, Total, and Threshold then count pieces across the project, pieces needed within the group
+	GroupsNeeded     int       // Groups that must each bring Threshold pieces
+	WordList         Lang      // Word list the recovery words are printed from; empty for English
+	BundleID         string    // Random ID shared by every piece of one seal; empty when not recorded
+	BundleTag        string    // Binds BundleID to this piece's index and data (see Bind)
+	MAC              string    // Authenticates index and data against the secret (see Authenticate); empty when not recorded
+	PINSalt          string    // Salt Data was locked with (see LockWithPIN); empty when the piece isn't locked
+	PINCheck         string    // Catches a wrong PIN before combining
+	WorkFactor       int       // scrypt log2(N) MANIFEST.age was sealed with when a recovery-time budget or max_memory set it; 0 otherwise
+	CatalogThreshold int       // Pieces needed to open CATALOG.age (see SplitCatalogKey); 0 when the seal has no catalog
+	CatalogData      []byte    // This piece's share of the catalog key
+	VSS              []byte    // This piece's value in the seal's verifiable split (see SplitVSS); nil when not recorded
+	Secret           bool      // Data splits a secret given to 'rememory split' as it is, rather than a seal's passphrase
+	Created          time.Time // When the share was created
+	ReviewBy         time.Time // Date after which recovery tools suggest looking for newer bundles; zero if unset
+	Expires          time.Time // Date after which recovery tools refuse the share unless told otherwise; zero if unset
+	Data             []byte    // The actual share bytes
+	Checksum         string    // SHA-256 of Data
+	Encoding         string    // How Data is printed: PaperEncoding, or empty for base64
+	Repaired         int       // Characters of the printed data fixed by error correction while parsing
 }
 
 // NewShare creates a Share with the given parameters and computes its checksum.
@@ -42,20 +64,33 @@ func NewShare(version, index, total, threshold int, holder string, data []byte)
 		Total:     total,
 		Threshold: threshold,
 		Holder:    holder,
-		Created:   time.Now().UTC(),
+		Created:   Now(),
 		Data:      data,
 		Checksum:  HashBytes(data),
+		Encoding:  paperEncodingFor(version),
+	}
+}
+
+// paperEncodingFor returns the encoding new shares of a version are printed
+// with. v1 keeps base64 for old recovery tools.
+func paperEncodingFor(version int) string {
+	if version >= 2 {
+		return PaperEncoding
 	}
+	return ""
 }
 
 // RecoverPassphrase converts raw bytes from Combine() into the age passphrase.
 // V1 shares contain the passphrase string directly; v2+ shares contain raw bytes
-// that must be base64url-encoded.
-func RecoverPassphrase(recovered []byte, version int) string {
+// that must be base64url-encoded. recovered is left as it is; the caller
+// wipes both once the passphrase has been used.
+func RecoverPassphrase(recovered []byte, version int) Secret {
 	if version >= 2 {
-		return base64.RawURLEncoding.EncodeToString(recovered)
+		passphrase := make(Secret, base64.RawURLEncoding.EncodedLen(len(recovered)))
+		base64.RawURLEncoding.Encode(passphrase, recovered)
+		return passphrase
 	}
-	return string(recovered)
+	return Secret(append([]byte(nil), recovered...))
 }
 
 // Encode converts the share to a human-readable PEM-like format.
@@ -70,6 +105,35 @@ func (s *Share) Encode() string {
 	if s.Holder != "" {
 		sb.WriteString(fmt.Sprintf("Holder: %s\n", s.Holder))
 	}
+	if s.Groups > 0 {
+		sb.WriteString(fmt.Sprintf("Group: %d\n", s.Group))
+		sb.WriteString(fmt.Sprintf("Groups: %d\n", s.Groups))
+		sb.WriteString(fmt.Sprintf("Groups-Needed: %d\n", s.GroupsNeeded))
+	}
+	if s.WordList != "" {
+		sb.WriteString(fmt.Sprintf("Words: %s\n", s.WordList))
+	}
+	if s.BundleID != "" {
+		sb.WriteString(fmt.Sprintf("Bundle: %s %s\n", s.BundleID, s.BundleTag))
+	}
+	if s.MAC != "" {
+		sb.WriteString(fmt.Sprintf("MAC: %s\n", s.MAC))
+	}
+	if s.Secret {
+		sb.WriteString("Content: secret\n")
+	}
+	if s.Locked() {
+		sb.WriteString(fmt.Sprintf("PIN: %s %s\n", s.PINSalt, s.PINCheck))
+	}
+	if s.WorkFactor > 0 {
+		sb.WriteString(fmt.Sprintf("KDF: scrypt N=2^%d r=8 p=1\n", s.WorkFactor))
+	}
+	if len(s.CatalogData) > 0 {
+		sb.WriteString(fmt.Sprintf("Catalog: %d %s\n", s.CatalogThreshold, base64.RawURLEncoding.EncodeToString(s.CatalogData)))
+	}
+	if len(s.VSS) > 0 {
+		sb.WriteString(fmt.Sprintf("VSS: %s\n", base64.RawURLEncoding.EncodeToString(s.VSS)))
+	}
 	// v1 used RFC3339; v2+ uses a shorter human-friendly format.
 	// Keep v1 encoding compatible with old recovery tools.
 	timeFormat := "2006-01-02 15:04"
@@ -77,15 +141,66 @@ func (s *Share) Encode() string {
 		timeFormat = time.RFC3339
 	}
 	sb.WriteString(fmt.Sprintf("Created: %s\n", s.Created.Format(timeFormat)))
+	if !s.ReviewBy.IsZero() {
+		sb.WriteString(fmt.Sprintf("Review-By: %s\n", s.ReviewBy.Format(DateFormat)))
+	}
+	if !s.Expires.IsZero() {
+		sb.WriteString(fmt.Sprintf("Expires: %s\n", s.Expires.Format(DateFormat)))
+	}
 	sb.WriteString(fmt.Sprintf("Checksum: %s\n", s.Checksum))
-	sb.WriteString("\n")
-	sb.WriteString(base64.StdEncoding.EncodeToString(s.Data))
-	sb.WriteString("\n")
+	if s.Encoding == PaperEncoding {
+		sb.WriteString(fmt.Sprintf("Encoding: %s\n", PaperEncoding))
+		sb.WriteString("\n")
+		for _, line := range EncodePaperLines(s.Data) {
+			sb.WriteString(line + "\n")
+		}
+	} else {
+		sb.WriteString("\n")
+		sb.WriteString(base64.StdEncoding.EncodeToString(s.Data))
+		sb.WriteString("\n")
+	}
 	sb.WriteString(ShareEnd + "\n")
 
 	return sb.String()
 }
 
+// ParseShares parses every share block in content, in order. A friend who
+// holds several pieces has them all in one share file and README.txt.
+func ParseShares(content []byte) ([]*Share, error) {
+	text := string(content)
+	var shares []*Share
+	for {
+		begin := strings.Index(text, ShareBegin)
+		if begin == -1 {
+			break
+		}
+		end := strings.Index(text[begin:], ShareEnd)
+		if end == -1 {
+			break
+		}
+		end += begin + len(ShareEnd)
+		share, err := ParseShare([]byte(text[begin:end]))
+		if err != nil {
+			return nil, fmt.Errorf("share block %d: %w", len(shares)+1, err)
+		}
+		shares = append(shares, share)
+		text = text[end:]
+	}
+	if len(shares) == 0 {
+		return nil, fmt.Errorf("invalid share format: missing BEGIN/END markers")
+	}
+	return shares, nil
+}
+
+// EncodeShares writes shares one after another, as ParseShares reads them.
+func EncodeShares(shares []*Share) string {
+	blocks := make([]string, len(shares))
+	for i, s := range shares {
+		blocks[i] = s.Encode()
+	}
+	return strings.Join(blocks, "\n")
+}
+
 // ParseShare parses a share from its encoded format.
 // The content can be a full README.txt file - it will find the share block.
 func ParseShare(content []byte) (*Share, error) {
@@ -121,7 +236,7 @@ func ParseShare(content []byte) (*Share, error) {
 		// Parse header fields
 		parts := strings.SplitN(line, ": ", 2)
 		if len(parts) != 2 {
-			// Line doesn't look like a header - must be base64 data
+			// Line doesn't look like a header - must be data
 			// This handles cases where empty line is missing (e.g., copied from PDF)
 			inData = true
 			dataLines = append(dataLines, line)
@@ -156,6 +271,55 @@ func ParseShare(content []byte) (*Share, error) {
 			share.Threshold = v
 		case "Holder":
 			share.Holder = value
+		case "Group", "Groups", "Groups-Needed":
+			v, err := strconv.Atoi(value)
+			if err != nil || v < 1 {
+				return nil, fmt.Errorf("invalid %s: %q", strings.ToLower(key), value)
+			}
+			switch key {
+			case "Group":
+				share.Group = v
+			case "Groups":
+				share.Groups = v
+			default:
+				share.GroupsNeeded = v
+			}
+		case "Words":
+			share.WordList = Lang(value)
+		case "Bundle":
+			id, tag, _ := strings.Cut(value, " ")
+			share.BundleID, share.BundleTag = id, tag
+		case "MAC":
+			share.MAC = value
+		case "Content":
+			if value != "secret" {
+				return nil, fmt.Errorf("unsupported share content %q", value)
+			}
+			share.Secret = true
+		case "PIN":
+			share.PINSalt, share.PINCheck, _ = strings.Cut(value, " ")
+		case "KDF":
+			var wf int
+			if _, err := fmt.Sscanf(value, "scrypt N=2^%d", &wf); err != nil || wf < 1 {
+				return nil, fmt.Errorf("invalid KDF: %q", value)
+			}
+			share.WorkFactor = wf
+		case "Catalog":
+			threshold, data, _ := strings.Cut(value, " ")
+			t, err := strconv.Atoi(threshold)
+			if err != nil || t < 2 {
+				return nil, fmt.Errorf("invalid catalog: %q", value)
+			}
+			if share.CatalogData, err = base64.RawURLEncoding.DecodeString(data); err != nil || len(share.CatalogData) == 0 {
+				return nil, fmt.Errorf("invalid catalog: %q", value)
+			}
+			share.CatalogThreshold = t
+		case "VSS":
+			v, err := base64.RawURLEncoding.DecodeString(value)
+			if err != nil || len(v) != vssValueSize {
+				return nil, fmt.Errorf("invalid VSS value: %q", value)
+			}
+			share.VSS = v
 		case "Created":
 			t, err := time.Parse("2006-01-02 15:04", value)
 			if err != nil {
@@ -165,18 +329,45 @@ func ParseShare(content []byte) (*Share, error) {
 				return nil, fmt.Errorf("invalid created time: %w", err)
 			}
 			share.Created = t
+		case "Review-By":
+			t, err := time.Parse(DateFormat, value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid review date: %w", err)
+			}
+			share.ReviewBy = t
+		case "Expires":
+			t, err := time.Parse(DateFormat, value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid expiry date: %w", err)
+			}
+			share.Expires = t
 		case "Checksum":
 			share.Checksum = value
+		case "Encoding":
+			share.Encoding = value
 		}
 	}
 
-	// Decode base64 data
-	dataStr := strings.Join(dataLines, "")
-	data, err := base64.StdEncoding.DecodeString(dataStr)
-	if err != nil {
-		return nil, fmt.Errorf("invalid base64 data: %w", err)
+	switch share.Encoding {
+	case "":
+		data, err := base64.StdEncoding.DecodeString(strings.Join(dataLines, ""))
+		if err != nil {
+			return nil, fmt.Errorf("invalid base64 data: %w", err)
+		}
+		share.Data = data
+	case PaperEncoding:
+		if len(dataLines) == 0 {
+			return nil, fmt.Errorf("missing share data")
+		}
+		data, repaired, err := DecodePaperLines(dataLines)
+		if err != nil {
+			return nil, err
+		}
+		share.Data = data
+		share.Repaired = repaired
+	default:
+		return nil, fmt.Errorf("unsupported share encoding %q", share.Encoding)
 	}
-	share.Data = data
 
 	// Validate required fields
 	if share.Version == 0 {
@@ -194,10 +385,31 @@ func ParseShare(content []byte) (*Share, error) {
 	if len(share.Data) == 0 {
 		return nil, fmt.Errorf("missing share data")
 	}
+	if err := share.checkGroup(); err != nil {
+		return nil, err
+	}
+	if err := share.checkBundle(); err != nil {
+		return nil, err
+	}
 
 	return share, nil
 }
 
+// checkGroup checks that a grouped piece records its group, the number of
+// groups, and the groups needed together.
+func (s *Share) checkGroup() error {
+	if s.Group == 0 && s.Groups == 0 && s.GroupsNeeded == 0 {
+		return nil
+	}
+	if s.Group < 1 || s.Groups < 1 || s.GroupsNeeded < 1 {
+		return fmt.Errorf("incomplete group information")
+	}
+	if s.Group > s.Groups || s.GroupsNeeded > s.Groups {
+		return fmt.Errorf("invalid group %d of %d (%d needed)", s.Group, s.Groups, s.GroupsNeeded)
+	}
+	return nil
+}
+
 // Verify checks that the share's checksum matches its data.
 // Uses constant-time comparison to prevent timing attacks.
 func (s *Share) Verify() error {
@@ -214,17 +426,31 @@ func (s *Share) Verify() error {
 // CompactEncode returns a short string encoding of the share suitable for
 // QR codes and URL fragments. Format: RM{version}:{index}:{total}:{threshold}:{base64url_data}:{short_check}
 // The short_check is the first 4 hex characters of the SHA-256 of the raw share data.
+// Grouped pieces add a field, {group}.{groups}.{groups_needed}, which
+// older recovery tools refuse rather than combine wrongly. Pieces locked with
+// a PIN add p{salt}.{check}, which older tools refuse the same way. Pieces
+// with a bundle ID end with b{bundle_id}.{bundle_tag}.
 func (s *Share) CompactEncode() string {
 	data := base64.RawURLEncoding.EncodeToString(s.Data)
 	check := shortChecksum(s.Data)
-	return fmt.Sprintf("RM%d:%d:%d:%d:%s:%s", s.Version, s.Index, s.Total, s.Threshold, data, check)
+	compact := fmt.Sprintf("RM%d:%d:%d:%d:%s:%s", s.Version, s.Index, s.Total, s.Threshold, data, check)
+	if s.Groups > 0 {
+		compact += fmt.Sprintf(":%d.%d.%d", s.Group, s.Groups, s.GroupsNeeded)
+	}
+	if s.Locked() {
+		compact += fmt.Sprintf(":p%s.%s", s.PINSalt, s.PINCheck)
+	}
+	if s.BundleID != "" {
+		compact += fmt.Sprintf(":b%s.%s", s.BundleID, s.BundleTag)
+	}
+	return compact
 }
 
 // ParseCompact parses a compact-encoded share string back into a Share.
 // It validates the format, decodes the data, and verifies the short checksum.
 func ParseCompact(s string) (*Share, error) {
 	parts := strings.Split(s, ":")
-	if len(parts) != 6 {
+	if len(parts) < 6 || len(parts) > 9 {
 		return nil, fmt.Errorf("invalid compact share: expected 6 colon-separated fields, got %d", len(parts))
 	}
 
@@ -267,14 +493,37 @@ func ParseCompact(s string) (*Share, error) {
 		return nil, fmt.Errorf("invalid compact share: checksum mismatch (got %s, want %s)", parts[5], expectedCheck)
 	}
 
-	return &Share{
+	share := &Share{
 		Version:   version,
 		Index:     index,
 		Total:     total,
 		Threshold: threshold,
 		Data:      data,
 		Checksum:  HashBytes(data),
-	}, nil
+	}
+	for i, field := range parts[6:] {
+		if bundle, ok := strings.CutPrefix(field, "b"); ok && i == len(parts[6:])-1 {
+			share.BundleID, share.BundleTag, _ = strings.Cut(bundle, ".")
+			continue
+		}
+		if lock, ok := strings.CutPrefix(field, "p"); ok && !share.Locked() {
+			share.PINSalt, share.PINCheck, _ = strings.Cut(lock, ".")
+			continue
+		}
+		if i > 0 {
+			return nil, fmt.Errorf("invalid compact share: unexpected field %q", field)
+		}
+		if _, err := fmt.Sscanf(field, "%d.%d.%d", &share.Group, &share.Groups, &share.GroupsNeeded); err != nil {
+			return nil, fmt.Errorf("invalid compact share: bad group %q", field)
+		}
+		if err := share.checkGroup(); err != nil {
+			return nil, fmt.Errorf("invalid compact share: %w", err)
+		}
+	}
+	if err := share.checkBundle(); err != nil {
+		return nil, fmt.Errorf("invalid compact share: %w", err)
+	}
+	return share, nil
 }
 
 // shortChecksum returns the first 4 hex characters of the SHA-256 of data.
@@ -0,0 +1,227 @@
+package core
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Share MACs let recovery tell a damaged or altered piece from a right one.
+// Shamir combines any pieces into some secret, and a piece with one wrong
+// byte gives a wrong one without complaint; age then only says the
+// passphrase doesn't open the manifest. Each piece records an HMAC of its
+// index and data, keyed by a key derived from the secret it was split from,
+// so once pieces are combined the result is checked against every piece
+// that carries one. Only the secret makes or checks a MAC: a piece on its
+// own says nothing about it.
+const (
+	shareMACSize = 8 // Bytes of HMAC kept in a piece's MAC
+
+	// maxLocateTries caps the sets of pieces tried when looking for the
+	// damaged one among spare pieces.
+	maxLocateTries = 5000
+)
+
+// shareMACLabel separates the MAC key from anything else derived from the
+// secret.
+var shareMACLabel = []byte("rememory share mac v1")
+
+// Authenticate records on the share a MAC of its index and data, keyed by
+// the secret it was split from.
+func (s *Share) Authenticate(secret []byte) {
+	s.MAC = shareMAC(shareMACKey(secret), s.Index, s.Data)
+}
+
+// shareMACKey derives the key pieces of secret are authenticated with.
+func shareMACKey(secret []byte) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(shareMACLabel)
+	return mac.Sum(nil)
+}
+
+// shareMAC is the HMAC-SHA256 of a piece's index and data, cut to
+// shareMACSize bytes of hex.
+func shareMAC(key []byte, index int, data []byte) string {
+	mac := hmac.New(sha256.New, key)
+	binary.Write(mac, binary.BigEndian, uint16(index))
+	mac.Write(data)
+	return hex.EncodeToString(mac.Sum(nil)[:shareMACSize])
+}
+
+// MACError reports pieces whose MACs don't match the secret they combined
+// to. When Located is false, the combined secret itself is wrong, and
+// Indexes are every piece that was checked: one or more of them is damaged,
+// but not which.
+type MACError struct {
+	Indexes []int
+	Located bool
+}
+
+func (e *MACError) Error() string {
+	pieces := make([]string, len(e.Indexes))
+	for i, index := range e.Indexes {
+		pieces[i] = strconv.Itoa(index)
+	}
+	if e.Located {
+		if len(pieces) == 1 {
+			return fmt.Sprintf("piece %s is damaged or was changed since sealing — recover without it", pieces[0])
+		}
+		return fmt.Sprintf("pieces %s are damaged or were changed since sealing — recover without them", strings.Join(pieces, ", "))
+	}
+	return "these pieces don't combine to the secret they were sealed with: at least one is damaged or was changed since sealing; " +
+		"add another piece to find which"
+}
+
+// CheckMACs checks the MAC of every share that carries one against the
+// secret they combined to, returning a *MACError when any doesn't match.
+// Shares without a MAC, from before they were recorded or read from a QR
+// code, words, digits, or an rm1 string, pass.
+func CheckMACs(shares []*Share, secret []byte) error {
+	key := shareMACKey(secret)
+	var bad, checked []int
+	for _, s := range shares {
+		if s.MAC == "" {
+			continue
+		}
+		checked = append(checked, s.Index)
+		if !hmac.Equal([]byte(s.MAC), []byte(shareMAC(key, s.Index, s.Data))) {
+			bad = append(bad, s.Index)
+		}
+	}
+	if len(bad) == 0 {
+		return nil
+	}
+	// A wrong piece makes the whole secret wrong, so every MAC fails. When
+	// some pass, the secret is right and only the failing MACs were changed.
+	if len(bad) < len(checked) {
+		return &MACError{Indexes: bad, Located: true}
+	}
+	return &MACError{Indexes: checked}
+}
+
+// CombineAuthenticated combines the shares of an ungrouped split needing
+// threshold of them and checks the result against their MACs. When the
+// check fails and there are more shares than threshold, it looks for a set
+// of threshold shares whose MACs do match and returns a located *MACError
+// naming the shares that disagree with it, so recovery can go on without
+// them.
+func CombineAuthenticated(shares []*Share, threshold int) ([]byte, error) {
+	secret, err := Combine(shareData(shares))
+	if err != nil {
+		return nil, err
+	}
+	macErr := CheckMACs(shares, secret)
+	if macErr == nil {
+		return secret, nil
+	}
+	Wipe(secret)
+	if e, ok := macErr.(*MACError); ok && e.Located {
+		return nil, macErr
+	}
+	if located, ok := locateDamaged(shares, threshold); ok {
+		return nil, located
+	}
+	return nil, macErr
+}
+
+// locateDamaged tries sets of threshold shares until one combines to a
+// secret every MAC among it matches, then names the shares that don't agree
+// with that secret.
+func locateDamaged(shares []*Share, threshold int) (*MACError, bool) {
+	if threshold < 2 || len(shares) <= threshold {
+		return nil, false
+	}
+	tries := 0
+	set := make([]int, threshold)
+	for i := range set {
+		set[i] = i
+	}
+	for {
+		if tries++; tries > maxLocateTries {
+			return nil, false
+		}
+		subset := make([]*Share, threshold)
+		for i, j := range set {
+			subset[i] = shares[j]
+		}
+		if secret, ok := authenticSecret(subset); ok {
+			located := disagreeing(shares, subset[:threshold-1], secret)
+			Wipe(secret)
+			return located, len(located.Indexes) > 0
+		}
+		if !nextCombination(set, len(shares)) {
+			return nil, false
+		}
+	}
+}
+
+// authenticSecret combines subset and reports whether the secret matches
+// the MAC of at least one share and of every share carrying one.
+func authenticSecret(subset []*Share) ([]byte, bool) {
+	secret, err := Combine(shareData(subset))
+	if err != nil {
+		return nil, false
+	}
+	for _, s := range subset {
+		if s.MAC != "" {
+			if CheckMACs(subset, secret) == nil {
+				return secret, true
+			}
+			break
+		}
+	}
+	Wipe(secret)
+	return nil, false
+}
+
+// disagreeing names the shares that, combined with base, give something
+// other than secret. Base is threshold-1 shares known to be right, so each
+// share is judged on its own, whether it carries a MAC or not.
+func disagreeing(shares, base []*Share, secret []byte) *MACError {
+	inBase := make(map[*Share]bool, len(base))
+	for _, s := range base {
+		inBase[s] = true
+	}
+	e := &MACError{Located: true}
+	for _, s := range shares {
+		if inBase[s] {
+			continue
+		}
+		got, err := Combine(shareData(append(append([]*Share{}, base...), s)))
+		if err != nil || !bytes.Equal(got, secret) {
+			e.Indexes = append(e.Indexes, s.Index)
+		}
+		Wipe(got)
+	}
+	return e
+}
+
+// nextCombination advances set, sorted indexes into n items, to the next
+// combination in order, reporting false after the last.
+func nextCombination(set []int, n int) bool {
+	k := len(set)
+	for i := k - 1; i >= 0; i-- {
+		if set[i] < n-k+i {
+			set[i]++
+			for j := i + 1; j < k; j++ {
+				set[j] = set[j-1] + 1
+			}
+			return true
+		}
+	}
+	return false
+}
+
+// shareData returns the shares' data, for Combine.
+func shareData(shares []*Share) [][]byte {
+	data := make([][]byte, len(shares))
+	for i, s := range shares {
+		data[i] = s.Data
+	}
+	return data
+}
@@ -0,0 +1,114 @@
+package core
+
+import (
+	"bytes"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// macShares splits secret into n authenticated pieces needing k.
+func macShares(t *testing.T, secret []byte, n, k int) []*Share {
+	t.Helper()
+	data, err := Split(secret, n, k)
+	if err != nil {
+		t.Fatal(err)
+	}
+	shares := make([]*Share, n)
+	for i := range data {
+		shares[i] = NewShare(2, i+1, n, k, "", data[i])
+		shares[i].Authenticate(secret)
+	}
+	return shares
+}
+
+// damage returns a copy of s with one byte of its data changed.
+func damage(s *Share) *Share {
+	c := *s
+	c.Data = bytes.Clone(s.Data)
+	c.Data[0] ^= 0x01
+	return &c
+}
+
+func TestShareMAC(t *testing.T) {
+	secret := bytes.Repeat([]byte{0x42}, 32)
+	shares := macShares(t, secret, 5, 3)
+
+	// The PEM block carries the MAC
+	parsed, err := ParseShare([]byte(shares[0].Encode()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsed.MAC == "" || parsed.MAC != shares[0].MAC {
+		t.Errorf("parsed MAC = %q, want %q", parsed.MAC, shares[0].MAC)
+	}
+
+	if err := CheckMACs(shares, secret); err != nil {
+		t.Errorf("CheckMACs with the right secret: %v", err)
+	}
+	var macErr *MACError
+	if err := CheckMACs(shares, []byte("wrong")); !errors.As(err, &macErr) || macErr.Located || len(macErr.Indexes) != 5 {
+		t.Errorf("CheckMACs with a wrong secret: got %v", err)
+	}
+
+	// A changed MAC on a right piece is located by the others
+	edited := *shares[1]
+	edited.MAC = strings.Repeat("0", len(edited.MAC))
+	err = CheckMACs([]*Share{shares[0], &edited, shares[2]}, secret)
+	if !errors.As(err, &macErr) || !macErr.Located || !slices.Equal(macErr.Indexes, []int{2}) {
+		t.Errorf("edited MAC: got %v", err)
+	}
+
+	// Pieces without a MAC aren't checked
+	bare := *shares[0]
+	bare.MAC = ""
+	if err := CheckMACs([]*Share{&bare}, []byte("wrong")); err != nil {
+		t.Errorf("piece without a MAC: %v", err)
+	}
+}
+
+func TestCombineAuthenticated(t *testing.T) {
+	secret := bytes.Repeat([]byte{0x17}, 32)
+	shares := macShares(t, secret, 5, 3)
+
+	got, err := CombineAuthenticated(shares[:3], 3)
+	if err != nil || !bytes.Equal(got, secret) {
+		t.Fatalf("CombineAuthenticated = %x, %v", got, err)
+	}
+
+	// Exactly the threshold with one damaged: detected, but not which
+	var macErr *MACError
+	_, err = CombineAuthenticated([]*Share{shares[0], damage(shares[1]), shares[2]}, 3)
+	if !errors.As(err, &macErr) || macErr.Located {
+		t.Errorf("damaged at threshold: got %v, want an unlocated MACError", err)
+	}
+	if err != nil && !strings.Contains(err.Error(), "add another piece") {
+		t.Errorf("damaged at threshold: message %q", err)
+	}
+
+	// A spare piece finds it
+	_, err = CombineAuthenticated([]*Share{shares[0], damage(shares[1]), shares[2], shares[3]}, 3)
+	if !errors.As(err, &macErr) || !macErr.Located || !slices.Equal(macErr.Indexes, []int{2}) {
+		t.Errorf("damaged with a spare: got %v, want piece 2 located", err)
+	}
+	if err != nil && !strings.Contains(err.Error(), "piece 2 is damaged") {
+		t.Errorf("damaged with a spare: message %q", err)
+	}
+
+	// A changed MAC line on a right piece is named without searching
+	edited := *shares[3]
+	edited.MAC = strings.Repeat("0", len(edited.MAC))
+	_, err = CombineAuthenticated([]*Share{shares[0], shares[1], &edited}, 3)
+	if !errors.As(err, &macErr) || !macErr.Located || !slices.Equal(macErr.Indexes, []int{4}) {
+		t.Errorf("edited MAC: got %v, want piece 4 located", err)
+	}
+
+	// Even when the damaged piece is one without a MAC
+	bare := damage(shares[0])
+	bare.MAC = ""
+	_, err = CombineAuthenticated([]*Share{bare, shares[1], shares[2], shares[3]}, 3)
+	if !errors.As(err, &macErr) || !macErr.Located || !slices.Equal(macErr.Indexes, []int{1}) {
+		t.Errorf("damaged piece without a MAC: got %v, want piece 1 located", err)
+	}
+}
@@ -0,0 +1,498 @@
+package core
+
+import (
+	"crypto/hmac"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+)
+
+// SLIP-0039 (Shamir Backup) is the share format hardware wallets use. ReMemory
+// can write a second set of pieces in it, so a passphrase can still be
+// recovered with someone else's tools: https://github.com/satoshilabs/slips/blob/master/slip-0039.md
+//
+// Pieces are made as a single group of extendable shares with no SLIP-0039
+// passphrase. Reading accepts any valid set, including several groups.
+const (
+	SLIP39SourceURL    = "https://github.com/satoshilabs/slips/blob/master/slip-0039/wordlist.txt"
+	SLIP39ExpectedHash = "bcc4555340332d169718aed8bf31dd9d5248cb7da6e5d355140ef4f1e601eec3"
+
+	// SLIP39MaxShares is the most pieces one SLIP-0039 group can have.
+	SLIP39MaxShares = 16
+
+	slip39IterationExponent = 1 // 20,000 PBKDF2 rounds, the reference default
+	slip39BaseIterations    = 10000
+	slip39Rounds            = 4
+	slip39DigestIndex       = 254
+	slip39SecretIndex       = 255
+	slip39DigestLength      = 4
+	slip39ChecksumWords     = 3
+	slip39HeaderWords       = 4
+	slip39MinSecretBytes    = 16
+	slip39MinWords          = slip39HeaderWords + 13 + slip39ChecksumWords // 128-bit secret
+)
+
+var slip39Generator = [10]uint32{
+	0xE0E040, 0x1C1C080, 0x3838100, 0x7070200, 0xE0E0009,
+	0x1C0C2412, 0x38086C24, 0x3090FC48, 0x21B1F890, 0x3F3F120,
+}
+
+var (
+	slip39Words   []string
+	slip39Indexes map[string]int // Full words and their unique 4-letter prefixes
+	slip39Once    sync.Once
+)
+
+func loadSLIP39() {
+	slip39Once.Do(func() {
+		data, err := wordlistFS.ReadFile("wordlists/slip39.txt")
+		if err != nil {
+			panic(fmt.Sprintf("loading SLIP-0039 word list: %v", err))
+		}
+		slip39Words = strings.Fields(string(data))
+		if len(slip39Words) != 1024 {
+			panic(fmt.Sprintf("SLIP-0039 word list has %d words, expected 1024", len(slip39Words)))
+		}
+		slip39Indexes = make(map[string]int, 2*len(slip39Words))
+		for i, w := range slip39Words {
+			slip39Indexes[w] = i
+			slip39Indexes[w[:4]] = i
+		}
+	})
+}
+
+// SLIP39WordList returns the 1024 SLIP-0039 words.
+func SLIP39WordList() []string {
+	loadSLIP39()
+	return slip39Words
+}
+
+// slip39Index looks up a word, which may be shortened to its first four
+// letters, as the standard allows.
+func slip39Index(word string) (int, bool) {
+	loadSLIP39()
+	word = strings.ToLower(strings.TrimSpace(word))
+	i, ok := slip39Indexes[word]
+	if !ok && len(word) > 4 {
+		if i, ok = slip39Indexes[word[:4]]; ok && !strings.HasPrefix(slip39Words[i], word) {
+			ok = false
+		}
+	}
+	return i, ok
+}
+
+// IsSLIP39Words reports whether words could be a SLIP-0039 share: long
+// enough, and every word on the SLIP-0039 list. It doesn't check the checksum.
+func IsSLIP39Words(words []string) bool {
+	if len(words) < slip39MinWords {
+		return false
+	}
+	for _, w := range words {
+		if _, ok := slip39Index(w); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// slip39Share is one decoded SLIP-0039 mnemonic.
+type slip39Share struct {
+	identifier        int
+	extendable        bool
+	iterationExponent int
+	groupIndex        int
+	groupThreshold    int
+	groupCount        int
+	memberIndex       int
+	memberThreshold   int
+	value             []byte
+}
+
+// SLIP39Split splits secret into count SLIP-0039 mnemonics, any threshold of
+// which recover it. The secret must be at least 16 bytes and an even length.
+func SLIP39Split(secret []byte, threshold, count int) ([]string, error) {
+	if len(secret) < slip39MinSecretBytes || len(secret)%2 != 0 {
+		return nil, fmt.Errorf("SLIP-0039 secrets must be an even number of bytes, at least %d", slip39MinSecretBytes)
+	}
+	if threshold < 1 || threshold > count {
+		return nil, fmt.Errorf("threshold must be between 1 and %d", count)
+	}
+	if count > SLIP39MaxShares {
+		return nil, fmt.Errorf("SLIP-0039 allows at most %d shares, got %d", SLIP39MaxShares, count)
+	}
+	if threshold == 1 && count > 1 {
+		return nil, fmt.Errorf("SLIP-0039 doesn't allow a threshold of 1 with several shares")
+	}
+
+	var id [2]byte
+	if _, err := rand.Read(id[:]); err != nil {
+		return nil, fmt.Errorf("generating identifier: %w", err)
+	}
+	identifier := (int(id[0])<<8 | int(id[1])) & 0x7fff
+
+	encrypted, err := slip39Crypt(secret, "", identifier, true, slip39IterationExponent, true)
+	if err != nil {
+		return nil, err
+	}
+	values, err := slip39SplitSecret(encrypted, threshold, count)
+	if err != nil {
+		return nil, err
+	}
+
+	mnemonics := make([]string, count)
+	for i, value := range values {
+		mnemonics[i] = (&slip39Share{
+			identifier:        identifier,
+			extendable:        true,
+			iterationExponent: slip39IterationExponent,
+			groupThreshold:    1,
+			groupCount:        1,
+			memberIndex:       i,
+			memberThreshold:   threshold,
+			value:             value,
+		}).encode()
+	}
+	return mnemonics, nil
+}
+
+// SLIP39Extend makes one more mnemonic for a single-group set, from at least
+// threshold of the existing ones, with the given member index. Removed
+// members' mnemonics aren't among those given, so the caller picks one no
+// mnemonic of the set has ever had; it is refused if a given one has it.
+func SLIP39Extend(mnemonics []string, member int) (string, error) {
+	shares, err := parseSLIP39Set(mnemonics)
+	if err != nil {
+		return "", err
+	}
+	first := shares[0]
+	if first.groupCount != 1 {
+		return "", fmt.Errorf("only single-group SLIP-0039 sets can be extended")
+	}
+	if !first.extendable {
+		return "", fmt.Errorf("SLIP-0039 shares made without the extendable flag can't be extended")
+	}
+	if len(shares) < first.memberThreshold {
+		return "", fmt.Errorf("need %d SLIP-0039 shares to make another, got %d", first.memberThreshold, len(shares))
+	}
+	if member < 0 || member >= SLIP39MaxShares {
+		return "", fmt.Errorf("SLIP-0039 member indexes go from 0 to %d, got %d", SLIP39MaxShares-1, member)
+	}
+	for _, s := range shares {
+		if s.memberIndex == member {
+			return "", fmt.Errorf("SLIP-0039 member index %d is taken", member)
+		}
+	}
+
+	xs, ys := slip39Points(shares)
+	added := *first
+	added.memberIndex = member
+	added.value = slip39Interpolate(xs, ys, byte(member))
+	return added.encode(), nil
+}
+
+// SLIP39Combine recovers the secret from SLIP-0039 mnemonics, assuming an
+// empty SLIP-0039 passphrase.
+func SLIP39Combine(mnemonics []string) ([]byte, error) {
+	return slip39Combine(mnemonics, "")
+}
+
+func slip39Combine(mnemonics []string, passphrase string) ([]byte, error) {
+	shares, err := parseSLIP39Set(mnemonics)
+	if err != nil {
+		return nil, err
+	}
+	first := shares[0]
+
+	groups := make(map[int][]*slip39Share)
+	for _, s := range shares {
+		groups[s.groupIndex] = append(groups[s.groupIndex], s)
+	}
+	if len(groups) < first.groupThreshold {
+		return nil, fmt.Errorf("need shares from %d groups, got %d", first.groupThreshold, len(groups))
+	}
+
+	var groupXs []byte
+	var groupYs [][]byte
+	for index, members := range groups {
+		threshold := members[0].memberThreshold
+		for _, m := range members {
+			if m.memberThreshold != threshold {
+				return nil, fmt.Errorf("SLIP-0039 shares in group %d disagree on the threshold", index+1)
+			}
+		}
+		if len(members) < threshold {
+			return nil, fmt.Errorf("need %d SLIP-0039 shares, got %d", threshold, len(members))
+		}
+		xs, ys := slip39Points(members)
+		value, err := slip39RecoverSecret(threshold, xs, ys)
+		if err != nil {
+			return nil, err
+		}
+		groupXs = append(groupXs, byte(index))
+		groupYs = append(groupYs, value)
+	}
+
+	encrypted, err := slip39RecoverSecret(first.groupThreshold, groupXs, groupYs)
+	if err != nil {
+		return nil, err
+	}
+	return slip39Crypt(encrypted, passphrase, first.identifier, first.extendable, first.iterationExponent, false)
+}
+
+// parseSLIP39Set decodes mnemonics and checks that they come from the same
+// split and aren't repeated.
+func parseSLIP39Set(mnemonics []string) ([]*slip39Share, error) {
+	if len(mnemonics) == 0 {
+		return nil, fmt.Errorf("no SLIP-0039 shares provided")
+	}
+	shares := make([]*slip39Share, len(mnemonics))
+	for i, m := range mnemonics {
+		s, err := parseSLIP39(strings.Fields(m))
+		if err != nil {
+			return nil, fmt.Errorf("SLIP-0039 share %d: %w", i+1, err)
+		}
+		shares[i] = s
+	}
+
+	first := shares[0]
+	seen := make(map[[2]int]bool)
+	for i, s := range shares {
+		if s.identifier != first.identifier || s.extendable != first.extendable || s.iterationExponent != first.iterationExponent ||
+			s.groupThreshold != first.groupThreshold || s.groupCount != first.groupCount || len(s.value) != len(first.value) {
+			return nil, fmt.Errorf("SLIP-0039 share %d is from a different set", i+1)
+		}
+		key := [2]int{s.groupIndex, s.memberIndex}
+		if seen[key] {
+			return nil, fmt.Errorf("SLIP-0039 share %d is a duplicate", i+1)
+		}
+		seen[key] = true
+	}
+	return shares, nil
+}
+
+// parseSLIP39 decodes and checksum-verifies one mnemonic.
+func parseSLIP39(words []string) (*slip39Share, error) {
+	if len(words) < slip39MinWords {
+		return nil, fmt.Errorf("too short: %d words, need at least %d", len(words), slip39MinWords)
+	}
+	data := make([]int, len(words))
+	for i, w := range words {
+		index, ok := slip39Index(w)
+		if !ok {
+			return nil, fmt.Errorf("word %d (%q) isn't on the SLIP-0039 word list", i+1, w)
+		}
+		data[i] = index
+	}
+
+	header := data[0]<<10 | data[1]
+	s := &slip39Share{
+		identifier:        header >> 5,
+		extendable:        header>>4&1 == 1,
+		iterationExponent: header & 0xf,
+	}
+	if slip39Polymod(slip39Customization(s.extendable), data) != 1 {
+		return nil, fmt.Errorf("checksum doesn't match; a word may be mistyped")
+	}
+
+	params := data[2]<<10 | data[3]
+	s.groupIndex = params >> 16
+	s.groupThreshold = params>>12&0xf + 1
+	s.groupCount = params>>8&0xf + 1
+	s.memberIndex = params >> 4 & 0xf
+	s.memberThreshold = params&0xf + 1
+	if s.groupThreshold > s.groupCount {
+		return nil, fmt.Errorf("group threshold %d is more than the %d groups", s.groupThreshold, s.groupCount)
+	}
+
+	valueWords := data[slip39HeaderWords : len(data)-slip39ChecksumWords]
+	padding := len(valueWords) * 10 % 16
+	if padding > 8 {
+		return nil, fmt.Errorf("invalid length: %d words", len(words))
+	}
+	value := new(big.Int)
+	for _, w := range valueWords {
+		value.Lsh(value, 10).Or(value, big.NewInt(int64(w)))
+	}
+	size := (len(valueWords)*10 - padding) / 8
+	if value.BitLen() > size*8 {
+		return nil, fmt.Errorf("padding isn't zero")
+	}
+	s.value = value.FillBytes(make([]byte, size))
+	return s, nil
+}
+
+// encode writes the share as space-separated words.
+func (s *slip39Share) encode() string {
+	ext := 0
+	if s.extendable {
+		ext = 1
+	}
+	header := s.identifier<<5 | ext<<4 | s.iterationExponent
+	params := s.groupIndex<<16 | (s.groupThreshold-1)<<12 | (s.groupCount-1)<<8 | s.memberIndex<<4 | (s.memberThreshold - 1)
+	data := []int{header >> 10, header & 0x3ff, params >> 10, params & 0x3ff}
+
+	valueWords := (len(s.value)*8 + 9) / 10
+	value := new(big.Int).SetBytes(s.value)
+	for i := valueWords - 1; i >= 0; i-- {
+		data = append(data, int(new(big.Int).Rsh(value, uint(10*i)).Int64()&0x3ff))
+	}
+
+	cs := slip39Customization(s.extendable)
+	checksum := slip39Polymod(cs, append(data, 0, 0, 0)) ^ 1
+	for i := 2; i >= 0; i-- {
+		data = append(data, int(checksum>>(10*i)&0x3ff))
+	}
+
+	loadSLIP39()
+	words := make([]string, len(data))
+	for i, d := range data {
+		words[i] = slip39Words[d]
+	}
+	return strings.Join(words, " ")
+}
+
+func slip39Customization(extendable bool) string {
+	if extendable {
+		return "shamir_extendable"
+	}
+	return "shamir"
+}
+
+// slip39Polymod is the RS1024 checksum over the customization string and the
+// word indexes.
+func slip39Polymod(customization string, data []int) uint32 {
+	chk := uint32(1)
+	step := func(v uint32) {
+		b := chk >> 20
+		chk = (chk&0xfffff)<<10 ^ v
+		for i := 0; i < 10; i++ {
+			if b>>i&1 == 1 {
+				chk ^= slip39Generator[i]
+			}
+		}
+	}
+	for _, c := range []byte(customization) {
+		step(uint32(c))
+	}
+	for _, d := range data {
+		step(uint32(d))
+	}
+	return chk
+}
+
+// slip39Crypt runs the four-round Feistel cipher that turns the secret into
+// the value that is split, or back when encrypt is false.
+func slip39Crypt(in []byte, passphrase string, identifier int, extendable bool, exponent int, encrypt bool) ([]byte, error) {
+	var salt []byte
+	if !extendable {
+		salt = []byte{'s', 'h', 'a', 'm', 'i', 'r', byte(identifier >> 8), byte(identifier)}
+	}
+	half := len(in) / 2
+	l, r := append([]byte(nil), in[:half]...), append([]byte(nil), in[half:]...)
+	iterations := (slip39BaseIterations << exponent) / slip39Rounds
+	for round := 0; round < slip39Rounds; round++ {
+		i := round
+		if !encrypt {
+			i = slip39Rounds - 1 - round
+		}
+		f, err := pbkdf2.Key(sha256.New, string(byte(i))+passphrase, append(append([]byte(nil), salt...), r...), iterations, half)
+		if err != nil {
+			return nil, fmt.Errorf("deriving round key: %w", err)
+		}
+		for j := range l {
+			l[j] ^= f[j]
+		}
+		l, r = r, l
+	}
+	return append(r, l...), nil
+}
+
+// slip39SplitSecret splits secret into count shares at x = 0..count-1, with
+// a digest at x = 254 so recovery can tell a wrong combination.
+func slip39SplitSecret(secret []byte, threshold, count int) ([][]byte, error) {
+	if threshold == 1 {
+		shares := make([][]byte, count)
+		for i := range shares {
+			shares[i] = append([]byte(nil), secret...)
+		}
+		return shares, nil
+	}
+
+	shares := make([][]byte, count)
+	var xs []byte
+	var ys [][]byte
+	for i := 0; i < threshold-2; i++ {
+		shares[i] = make([]byte, len(secret))
+		if _, err := rand.Read(shares[i]); err != nil {
+			return nil, fmt.Errorf("generating share: %w", err)
+		}
+		xs, ys = append(xs, byte(i)), append(ys, shares[i])
+	}
+	randomPart := make([]byte, len(secret)-slip39DigestLength)
+	if _, err := rand.Read(randomPart); err != nil {
+		return nil, fmt.Errorf("generating digest: %w", err)
+	}
+	digest := append(slip39Digest(randomPart, secret), randomPart...)
+	xs, ys = append(xs, slip39DigestIndex, slip39SecretIndex), append(ys, digest, secret)
+
+	for i := threshold - 2; i < count; i++ {
+		shares[i] = slip39Interpolate(xs, ys, byte(i))
+	}
+	return shares, nil
+}
+
+// slip39RecoverSecret interpolates the secret and checks it against the digest.
+func slip39RecoverSecret(threshold int, xs []byte, ys [][]byte) ([]byte, error) {
+	if threshold == 1 {
+		return ys[0], nil
+	}
+	secret := slip39Interpolate(xs, ys, slip39SecretIndex)
+	digest := slip39Interpolate(xs, ys, slip39DigestIndex)
+	if !hmac.Equal(digest[:slip39DigestLength], slip39Digest(digest[slip39DigestLength:], secret)) {
+		return nil, fmt.Errorf("SLIP-0039 shares don't combine; they may come from different sets")
+	}
+	return secret, nil
+}
+
+func slip39Digest(key, secret []byte) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(secret)
+	return mac.Sum(nil)[:slip39DigestLength]
+}
+
+func slip39Points(shares []*slip39Share) ([]byte, [][]byte) {
+	xs := make([]byte, len(shares))
+	ys := make([][]byte, len(shares))
+	for i, s := range shares {
+		xs[i], ys[i] = byte(s.memberIndex), s.value
+	}
+	return xs, ys
+}
+
+// slip39Interpolate evaluates the polynomial through (xs, ys) at x, in the
+// same GF(2^8) field as interpolate. Unlike vault shares, SLIP-0039 keeps x
+// apart from the value and uses x = 0.
+func slip39Interpolate(xs []byte, ys [][]byte, x byte) []byte {
+	for i, xi := range xs {
+		if xi == x {
+			return append([]byte(nil), ys[i]...)
+		}
+	}
+	out := make([]byte, len(ys[0]))
+	for i, xi := range xs {
+		basis := byte(1)
+		for j, xj := range xs {
+			if i != j {
+				basis = gfMul(basis, gfDiv(x^xj, xi^xj))
+			}
+		}
+		for b := range out {
+			out[b] ^= gfMul(ys[i][b], basis)
+		}
+	}
+	return out
+}
@@ -0,0 +1,199 @@
+package core
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestSLIP39WordListIntegrity(t *testing.T) {
+	data, err := wordlistFS.ReadFile("wordlists/slip39.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sum := sha256.Sum256(data)
+	if hash := hex.EncodeToString(sum[:]); hash != SLIP39ExpectedHash {
+		t.Errorf("hash mismatch:\n  got:  %s\n  want: %s\n  source: %s", hash, SLIP39ExpectedHash, SLIP39SourceURL)
+	}
+
+	// The standard's own properties: sorted, 4-8 letters, unique first four letters
+	words := SLIP39WordList()
+	if !sort.StringsAreSorted(words) {
+		t.Error("word list isn't sorted")
+	}
+	prefixes := make(map[string]bool, len(words))
+	for _, w := range words {
+		if len(w) < 4 || len(w) > 8 {
+			t.Errorf("%q isn't 4-8 letters", w)
+		}
+		if prefixes[w[:4]] {
+			t.Errorf("%q shares its first four letters with another word", w)
+		}
+		prefixes[w[:4]] = true
+	}
+}
+
+// Vectors from the SLIP-0039 reference (vectors.json), all with passphrase "TREZOR".
+func TestSLIP39Vectors(t *testing.T) {
+	tests := []struct {
+		name      string
+		mnemonics []string
+		secret    string
+	}{
+		{
+			name:      "128 bits, no sharing",
+			mnemonics: []string{"duckling enlarge academic academic agency result length solution fridge kidney coal piece deal husband erode duke ajar critical decision keyboard"},
+			secret:    "bb54aac4b89dc868ba37d9cc21b2cece",
+		},
+		{
+			name: "128 bits, 2 of 3",
+			mnemonics: []string{
+				"shadow pistol academic always adequate wildlife fancy gross oasis cylinder mustang wrist rescue view short owner flip making coding armed",
+				"shadow pistol academic acid actress prayer class unknown daughter sweater depict flip twice unkind craft early superior advocate guest smoking",
+			},
+			secret: "b43ceb7e57a0ea8766221624d01b0864",
+		},
+		{
+			name:      "256 bits, no sharing",
+			mnemonics: []string{"theory painting academic academic armed sweater year military elder discuss acne wildlife boring employer fused large satoshi bundle carbon diagnose anatomy hamster leaves tracks paces beyond phantom capital marvel lips brave detect luck"},
+			secret:    "989baf9dcaad5b10ca33dfd8cc75e42477025dce88ae83e75a230086a0e00e92",
+		},
+		{
+			name:      "128 bits, extendable",
+			mnemonics: []string{"testify swimming academic academic column loyalty smear include exotic bedroom exotic wrist lobe cover grief golden smart junior estimate learn"},
+			secret:    "1679b4516e0ee5954351d288a838f45e",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := slip39Combine(tt.mnemonics, "TREZOR")
+			if err != nil {
+				t.Fatalf("combine: %v", err)
+			}
+			if hex.EncodeToString(got) != tt.secret {
+				t.Errorf("got %x, want %s", got, tt.secret)
+			}
+
+			// Re-encoding what was parsed gives back the same words
+			for _, m := range tt.mnemonics {
+				s, err := parseSLIP39(strings.Fields(m))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if enc := s.encode(); enc != m {
+					t.Errorf("re-encoded:\n  got:  %s\n  want: %s", enc, m)
+				}
+			}
+		})
+	}
+}
+
+func TestSLIP39SplitCombine(t *testing.T) {
+	secret := bytes.Repeat([]byte{0x5a, 0xc3}, 16)
+	mnemonics, err := SLIP39Split(secret, 3, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := len(strings.Fields(mnemonics[0])); n != 33 {
+		t.Errorf("a 256-bit share has %d words, want 33", n)
+	}
+
+	for _, pick := range [][]int{{0, 1, 2}, {4, 2, 0}, {1, 3, 4}, {0, 1, 2, 3, 4}} {
+		var subset []string
+		for _, i := range pick {
+			subset = append(subset, mnemonics[i])
+		}
+		got, err := SLIP39Combine(subset)
+		if err != nil {
+			t.Fatalf("%v: %v", pick, err)
+		}
+		if !bytes.Equal(got, secret) {
+			t.Errorf("%v: wrong secret", pick)
+		}
+	}
+
+	if _, err := SLIP39Combine(mnemonics[:2]); err == nil {
+		t.Error("expected an error with too few shares")
+	}
+	if _, err := SLIP39Combine([]string{mnemonics[0], mnemonics[0], mnemonics[1]}); err == nil {
+		t.Error("expected an error with a repeated share")
+	}
+
+	other, _ := SLIP39Split(secret, 3, 5)
+	if _, err := SLIP39Combine([]string{mnemonics[0], mnemonics[1], other[2]}); err == nil {
+		t.Error("expected an error mixing two sets")
+	}
+}
+
+func TestSLIP39Extend(t *testing.T) {
+	secret := bytes.Repeat([]byte{7}, 32)
+	mnemonics, err := SLIP39Split(secret, 2, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Member 2 was removed: the new share comes after it
+	added, err := SLIP39Extend(mnemonics[:2], 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s, _ := parseSLIP39(strings.Fields(added)); s.memberIndex != 3 {
+		t.Errorf("member index = %d, want 3", s.memberIndex)
+	}
+	got, err := SLIP39Combine([]string{added, mnemonics[1]})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, secret) {
+		t.Error("the new share doesn't recover the secret")
+	}
+
+	if _, err := SLIP39Extend(mnemonics, 1); err == nil {
+		t.Error("extended with a member index that's taken")
+	}
+	if _, err := SLIP39Extend(mnemonics, SLIP39MaxShares); err == nil {
+		t.Error("extended past the last member index")
+	}
+}
+
+func TestParseSLIP39Mistakes(t *testing.T) {
+	secret := bytes.Repeat([]byte{1, 2}, 8)
+	mnemonics, err := SLIP39Split(secret, 2, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	words := strings.Fields(mnemonics[0])
+
+	// Four-letter abbreviations are part of the standard
+	short := make([]string, len(words))
+	for i, w := range words {
+		short[i] = strings.ToUpper(w[:4])
+	}
+	if _, err := parseSLIP39(short); err != nil {
+		t.Errorf("abbreviated words: %v", err)
+	}
+
+	typo := append([]string(nil), words...)
+	typo[7] = SLIP39WordList()[(mustSLIP39Index(t, typo[7])+1)%1024]
+	if _, err := parseSLIP39(typo); err == nil || !strings.Contains(err.Error(), "checksum") {
+		t.Errorf("expected a checksum error, got %v", err)
+	}
+
+	if IsSLIP39Words(strings.Fields("merit merit merit")) {
+		t.Error("three words aren't a SLIP-0039 share")
+	}
+	if !IsSLIP39Words(words) {
+		t.Error("expected a SLIP-0039 share")
+	}
+}
+
+func mustSLIP39Index(t *testing.T, word string) int {
+	t.Helper()
+	i, ok := slip39Index(word)
+	if !ok {
+		t.Fatalf("%q not found", word)
+	}
+	return i
+}
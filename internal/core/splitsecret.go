@@ -0,0 +1,50 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+)
+
+// A secret can also be split as it is, with 'rememory split', for people
+// who want the pieces without sealing any files: a password manager's
+// master password, or an age identity. Its pieces say "Content: secret",
+// and combining them gives the secret's own bytes, where a seal's pieces
+// give the raw passphrase for MANIFEST.age. Only the PEM form records that,
+// so these pieces aren't written as words, digits, or rm1 strings.
+
+// MaxSplitSecret is the largest secret SplitSecret takes. Each piece is as
+// long as the secret, and has to fit on paper.
+const MaxSplitSecret = 1024
+
+// ErrSecretPiece is returned when pieces of a secret split on its own are
+// given to something that recovers a seal.
+var ErrSecretPiece = errors.New("these pieces split a secret on its own, not a passphrase for MANIFEST.age; get it back with 'rememory combine'")
+
+// errSecretShare is returned for the forms that can't record that a piece
+// splits a secret on its own.
+var errSecretShare = fmt.Errorf("pieces of a secret split on its own can't be written in this form, which doesn't record it")
+
+// SplitSecret splits secret as it is into n pieces, k of them needed, each
+// recording bundle id and authenticated against the secret, so combining
+// them checks the result. Holders are for the caller to fill in.
+func SplitSecret(secret []byte, n, k int, id string) ([]*Share, error) {
+	if len(secret) == 0 {
+		return nil, fmt.Errorf("the secret is empty")
+	}
+	if len(secret) > MaxSplitSecret {
+		return nil, fmt.Errorf("the secret is %d bytes; split takes secrets up to %d bytes (seal larger files instead)", len(secret), MaxSplitSecret)
+	}
+	data, err := Split(secret, n, k)
+	if err != nil {
+		return nil, err
+	}
+	shares := make([]*Share, n)
+	for i, d := range data {
+		share := NewShare(2, i+1, n, k, "", d)
+		share.Secret = true
+		share.Bind(id)
+		share.Authenticate(secret)
+		shares[i] = share
+	}
+	return shares, nil
+}
@@ -0,0 +1,57 @@
+package core
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestSplitSecret(t *testing.T) {
+	id, err := NewBundleID()
+	if err != nil {
+		t.Fatal(err)
+	}
+	secret := []byte("AGE-SECRET-KEY-1EXAMPLE\n")
+	shares, err := SplitSecret(secret, 3, 2, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The PEM block records that the pieces split a secret on its own
+	parsed := make([]*Share, len(shares))
+	for i, s := range shares {
+		encoded := s.Encode()
+		if !strings.Contains(encoded, "Content: secret\n") {
+			t.Fatalf("piece %d doesn't record its content:\n%s", s.Index, encoded)
+		}
+		if parsed[i], err = ParseShare([]byte(encoded)); err != nil {
+			t.Fatal(err)
+		}
+		if !parsed[i].Secret || parsed[i].BundleID != id {
+			t.Errorf("piece %d parsed as %+v", s.Index, parsed[i])
+		}
+	}
+	got, err := CombineAuthenticated(parsed[1:], 2)
+	if err != nil || !bytes.Equal(got, secret) {
+		t.Errorf("combined %q, %v; want %q", got, err, secret)
+	}
+
+	// Forms that can't record it refuse these pieces
+	if _, err := shares[0].Words(); !errors.Is(err, errSecretShare) {
+		t.Errorf("Words: got %v", err)
+	}
+	if _, err := shares[0].Digits(); !errors.Is(err, errSecretShare) {
+		t.Errorf("Digits: got %v", err)
+	}
+
+	if _, err := ParseShare([]byte(strings.Replace(shares[0].Encode(), "Content: secret", "Content: other", 1))); err == nil {
+		t.Error("an unknown Content parsed")
+	}
+	if _, err := SplitSecret(nil, 3, 2, id); err == nil {
+		t.Error("split an empty secret")
+	}
+	if _, err := SplitSecret(make([]byte, MaxSplitSecret+1), 3, 2, id); err == nil {
+		t.Error("split a secret over the limit")
+	}
+}
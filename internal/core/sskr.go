@@ -0,0 +1,293 @@
+package core
+
+import (
+	"crypto/rand"
+	"fmt"
+	"strings"
+)
+
+// SSKR (Blockchain Commons Sharded Secret Key Reconstruction, BCR-2020-011)
+// uses the same Shamir scheme as SLIP-0039, without its encryption, and
+// writes each share as bytes: a 5-byte header and the share value. Shares
+// are exchanged as UR strings ("ur:sskr/...") or as bytewords.
+const (
+	SSKRMaxShares = 16
+
+	sskrHeaderBytes    = 5
+	sskrMinSecretBytes = 16
+	sskrMaxSecretBytes = 32
+	sskrURPrefix       = "ur:sskr/"
+	sskrLegacyURPrefix = "ur:crypto-sskr/"
+)
+
+// CBOR tags for SSKR shares: the registered tag, and the one older tools used.
+const (
+	sskrTag       = 309
+	sskrLegacyTag = 40309
+)
+
+// SSKRShare is one decoded SSKR share. Indexes are from 0, thresholds from 1.
+type SSKRShare struct {
+	Identifier      int
+	GroupIndex      int
+	GroupThreshold  int
+	GroupCount      int
+	MemberIndex     int
+	MemberThreshold int
+	Value           []byte
+}
+
+// SSKRSplit splits secret into count single-group SSKR shares, any threshold
+// of which recover it. The secret must be 16 to 32 bytes and an even length.
+func SSKRSplit(secret []byte, threshold, count int) ([]*SSKRShare, error) {
+	if len(secret) < sskrMinSecretBytes || len(secret) > sskrMaxSecretBytes || len(secret)%2 != 0 {
+		return nil, fmt.Errorf("SSKR secrets must be an even number of bytes, from %d to %d", sskrMinSecretBytes, sskrMaxSecretBytes)
+	}
+	if threshold < 1 || threshold > count {
+		return nil, fmt.Errorf("threshold must be between 1 and %d", count)
+	}
+	if count > SSKRMaxShares {
+		return nil, fmt.Errorf("SSKR allows at most %d shares, got %d", SSKRMaxShares, count)
+	}
+
+	var id [2]byte
+	if _, err := rand.Read(id[:]); err != nil {
+		return nil, fmt.Errorf("generating identifier: %w", err)
+	}
+	values, err := slip39SplitSecret(secret, threshold, count)
+	if err != nil {
+		return nil, err
+	}
+
+	shares := make([]*SSKRShare, count)
+	for i, value := range values {
+		shares[i] = &SSKRShare{
+			Identifier:      int(id[0])<<8 | int(id[1]),
+			GroupThreshold:  1,
+			GroupCount:      1,
+			MemberIndex:     i,
+			MemberThreshold: threshold,
+			Value:           value,
+		}
+	}
+	return shares, nil
+}
+
+// SSKRExtend makes one more share for a single-group set, from at least
+// threshold of the existing ones, with the given member index. Removed
+// members' shares aren't among those given, so the caller picks one no
+// share of the set has ever had; it is refused if a given one has it.
+func SSKRExtend(shares []*SSKRShare, member int) (*SSKRShare, error) {
+	if err := checkSSKRSet(shares); err != nil {
+		return nil, err
+	}
+	first := shares[0]
+	if first.GroupCount != 1 {
+		return nil, fmt.Errorf("only single-group SSKR sets can be extended")
+	}
+	if len(shares) < first.MemberThreshold {
+		return nil, fmt.Errorf("need %d SSKR shares to make another, got %d", first.MemberThreshold, len(shares))
+	}
+	if member < 0 || member >= SSKRMaxShares {
+		return nil, fmt.Errorf("SSKR member indexes go from 0 to %d, got %d", SSKRMaxShares-1, member)
+	}
+	for _, s := range shares {
+		if s.MemberIndex == member {
+			return nil, fmt.Errorf("SSKR member index %d is taken", member)
+		}
+	}
+
+	xs, ys := sskrPoints(shares)
+	added := *first
+	added.MemberIndex = member
+	added.Value = slip39Interpolate(xs, ys, byte(member))
+	return &added, nil
+}
+
+// SSKRCombine recovers the secret from SSKR shares, including sets split
+// into several groups.
+func SSKRCombine(shares []*SSKRShare) ([]byte, error) {
+	if err := checkSSKRSet(shares); err != nil {
+		return nil, err
+	}
+	first := shares[0]
+
+	groups := make(map[int][]*SSKRShare)
+	for _, s := range shares {
+		groups[s.GroupIndex] = append(groups[s.GroupIndex], s)
+	}
+	if len(groups) < first.GroupThreshold {
+		return nil, fmt.Errorf("need SSKR shares from %d groups, got %d", first.GroupThreshold, len(groups))
+	}
+
+	var groupXs []byte
+	var groupYs [][]byte
+	for index, members := range groups {
+		threshold := members[0].MemberThreshold
+		for _, m := range members {
+			if m.MemberThreshold != threshold {
+				return nil, fmt.Errorf("SSKR shares in group %d disagree on the threshold", index+1)
+			}
+		}
+		if len(members) < threshold {
+			return nil, fmt.Errorf("need %d SSKR shares, got %d", threshold, len(members))
+		}
+		xs, ys := sskrPoints(members)
+		value, err := slip39RecoverSecret(threshold, xs, ys)
+		if err != nil {
+			return nil, fmt.Errorf("SSKR shares don't combine; they may come from different sets")
+		}
+		groupXs = append(groupXs, byte(index))
+		groupYs = append(groupYs, value)
+	}
+
+	secret, err := slip39RecoverSecret(first.GroupThreshold, groupXs, groupYs)
+	if err != nil {
+		return nil, fmt.Errorf("SSKR shares don't combine; they may come from different sets")
+	}
+	return secret, nil
+}
+
+// checkSSKRSet checks that shares come from the same split and aren't repeated.
+func checkSSKRSet(shares []*SSKRShare) error {
+	if len(shares) == 0 {
+		return fmt.Errorf("no SSKR shares provided")
+	}
+	first := shares[0]
+	seen := make(map[[2]int]bool)
+	for i, s := range shares {
+		if s.Identifier != first.Identifier || s.GroupThreshold != first.GroupThreshold ||
+			s.GroupCount != first.GroupCount || len(s.Value) != len(first.Value) {
+			return fmt.Errorf("SSKR share %d is from a different set", i+1)
+		}
+		key := [2]int{s.GroupIndex, s.MemberIndex}
+		if seen[key] {
+			return fmt.Errorf("SSKR share %d is a duplicate", i+1)
+		}
+		seen[key] = true
+	}
+	return nil
+}
+
+func sskrPoints(shares []*SSKRShare) ([]byte, [][]byte) {
+	xs := make([]byte, len(shares))
+	ys := make([][]byte, len(shares))
+	for i, s := range shares {
+		xs[i], ys[i] = byte(s.MemberIndex), s.Value
+	}
+	return xs, ys
+}
+
+// Bytes returns the share's header and value.
+func (s *SSKRShare) Bytes() []byte {
+	return append([]byte{
+		byte(s.Identifier >> 8),
+		byte(s.Identifier),
+		byte(s.GroupThreshold-1)<<4 | byte(s.GroupCount-1),
+		byte(s.GroupIndex)<<4 | byte(s.MemberThreshold-1),
+		byte(s.MemberIndex),
+	}, s.Value...)
+}
+
+// UR returns the share as a single-part UR string, "ur:sskr/" and the
+// minimal bytewords of its CBOR byte string.
+func (s *SSKRShare) UR() string {
+	return sskrURPrefix + BytewordsEncodeMinimal(cborBytes(s.Bytes()))
+}
+
+// Bytewords returns the share as space-separated bytewords of its tagged
+// CBOR byte string, the form tools such as Gordian Seed Tool print.
+func (s *SSKRShare) Bytewords() string {
+	return BytewordsEncode(append(cborTag(sskrTag), cborBytes(s.Bytes())...))
+}
+
+// ParseSSKR reads one share written as a UR string or as bytewords, in the
+// standard or minimal style. The CBOR tag is optional.
+func ParseSSKR(text string) (*SSKRShare, error) {
+	text = strings.ToLower(strings.TrimSpace(text))
+	for _, prefix := range []string{sskrURPrefix, sskrLegacyURPrefix} {
+		if strings.HasPrefix(text, prefix) {
+			text = strings.TrimPrefix(text, prefix)
+			if strings.Contains(text, "/") {
+				return nil, fmt.Errorf("multi-part SSKR URs aren't supported")
+			}
+			break
+		}
+	}
+	data, err := BytewordsDecode(text)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := sskrUnwrapCBOR(data)
+	if err != nil {
+		return nil, err
+	}
+	return ParseSSKRBytes(raw)
+}
+
+// ParseSSKRBytes decodes a share's header and value.
+func ParseSSKRBytes(raw []byte) (*SSKRShare, error) {
+	size := len(raw) - sskrHeaderBytes
+	if size < sskrMinSecretBytes || size > sskrMaxSecretBytes || size%2 != 0 {
+		return nil, fmt.Errorf("SSKR share has the wrong length: %d bytes", len(raw))
+	}
+	if raw[4]>>4 != 0 {
+		return nil, fmt.Errorf("SSKR share has reserved bits set")
+	}
+	s := &SSKRShare{
+		Identifier:      int(raw[0])<<8 | int(raw[1]),
+		GroupThreshold:  int(raw[2]>>4) + 1,
+		GroupCount:      int(raw[2]&0xf) + 1,
+		GroupIndex:      int(raw[3] >> 4),
+		MemberThreshold: int(raw[3]&0xf) + 1,
+		MemberIndex:     int(raw[4] & 0xf),
+		Value:           append([]byte(nil), raw[sskrHeaderBytes:]...),
+	}
+	if s.GroupThreshold > s.GroupCount {
+		return nil, fmt.Errorf("SSKR group threshold %d is more than the %d groups", s.GroupThreshold, s.GroupCount)
+	}
+	return s, nil
+}
+
+// sskrUnwrapCBOR returns the byte string inside data, after an optional
+// SSKR tag.
+func sskrUnwrapCBOR(data []byte) ([]byte, error) {
+	for _, tag := range []int{sskrTag, sskrLegacyTag} {
+		if prefix := cborTag(tag); len(data) > len(prefix) && string(data[:len(prefix)]) == string(prefix) {
+			data = data[len(prefix):]
+			break
+		}
+	}
+	if len(data) == 0 {
+		return nil, fmt.Errorf("SSKR share is empty")
+	}
+	var n, header int
+	switch {
+	case data[0] >= 0x40 && data[0] < 0x58:
+		n, header = int(data[0]-0x40), 1
+	case data[0] == 0x58 && len(data) > 1:
+		n, header = int(data[1]), 2
+	default:
+		return nil, fmt.Errorf("SSKR share isn't a CBOR byte string")
+	}
+	if len(data) != header+n {
+		return nil, fmt.Errorf("SSKR share has the wrong length")
+	}
+	return data[header:], nil
+}
+
+// cborBytes encodes b as a CBOR byte string. SSKR shares are under 256 bytes.
+func cborBytes(b []byte) []byte {
+	if len(b) < 24 {
+		return append([]byte{0x40 | byte(len(b))}, b...)
+	}
+	return append([]byte{0x58, byte(len(b))}, b...)
+}
+
+// cborTag encodes a CBOR tag number that fits in two bytes.
+func cborTag(tag int) []byte {
+	if tag < 256 {
+		return []byte{0xd8, byte(tag)}
+	}
+	return []byte{0xd9, byte(tag >> 8), byte(tag)}
+}
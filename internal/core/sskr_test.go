@@ -0,0 +1,121 @@
+package core
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestSSKRSplitCombine(t *testing.T) {
+	secret := bytes.Repeat([]byte{0x5a}, 32)
+	shares, err := SSKRSplit(secret, 2, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, pair := range [][2]int{{0, 1}, {0, 2}, {2, 1}} {
+		got, err := SSKRCombine([]*SSKRShare{shares[pair[0]], shares[pair[1]]})
+		if err != nil {
+			t.Fatalf("%v: %v", pair, err)
+		}
+		if !bytes.Equal(got, secret) {
+			t.Errorf("%v: got %x", pair, got)
+		}
+	}
+	if _, err := SSKRCombine(shares[:1]); err == nil {
+		t.Error("expected an error below the threshold")
+	}
+	if _, err := SSKRCombine([]*SSKRShare{shares[0], shares[0]}); err == nil {
+		t.Error("expected an error for a duplicate")
+	}
+}
+
+func TestSSKREncodings(t *testing.T) {
+	shares, err := SSKRSplit(bytes.Repeat([]byte{0x11}, 32), 2, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := shares[1]
+
+	ur := s.UR()
+	if !strings.HasPrefix(ur, "ur:sskr/") {
+		t.Errorf("UR = %q", ur)
+	}
+	// d9 0135 is tag 309, 58 25 a 37-byte string
+	words := s.Bytewords()
+	if !strings.HasPrefix(words, "tuna acid epic hard data ") {
+		t.Errorf("bytewords = %q", words)
+	}
+
+	legacyTagged := BytewordsEncode(append(cborTag(sskrLegacyTag), cborBytes(s.Bytes())...))
+	for _, text := range []string{ur, strings.ToUpper(ur), "ur:crypto-sskr/" + ur[len("ur:sskr/"):], words, legacyTagged, BytewordsEncodeMinimal(cborBytes(s.Bytes()))} {
+		got, err := ParseSSKR(text)
+		if err != nil {
+			t.Errorf("%q: %v", text, err)
+			continue
+		}
+		if !bytes.Equal(got.Bytes(), s.Bytes()) {
+			t.Errorf("%q: got %x, want %x", text, got.Bytes(), s.Bytes())
+		}
+	}
+	if got, _ := ParseSSKR(ur); got.MemberIndex != 1 || got.MemberThreshold != 2 || got.GroupCount != 1 {
+		t.Errorf("header = %+v", got)
+	}
+}
+
+func TestSSKRGroups(t *testing.T) {
+	// Two groups, both needed: 2 of 3 and 1 of 1, built by hand as SSKR tools do
+	secret := bytes.Repeat([]byte{0x42}, 16)
+	groupSecrets, err := slip39SplitSecret(secret, 2, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	members, err := slip39SplitSecret(groupSecrets[0], 2, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	shares := []*SSKRShare{
+		{Identifier: 7, GroupThreshold: 2, GroupCount: 2, GroupIndex: 0, MemberIndex: 2, MemberThreshold: 2, Value: members[2]},
+		{Identifier: 7, GroupThreshold: 2, GroupCount: 2, GroupIndex: 1, MemberIndex: 0, MemberThreshold: 1, Value: groupSecrets[1]},
+		{Identifier: 7, GroupThreshold: 2, GroupCount: 2, GroupIndex: 0, MemberIndex: 0, MemberThreshold: 2, Value: members[0]},
+	}
+	got, err := SSKRCombine(shares)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, secret) {
+		t.Errorf("got %x", got)
+	}
+	if _, err := SSKRCombine(shares[:2]); err == nil {
+		t.Error("expected an error with one group short")
+	}
+}
+
+func TestSSKRExtend(t *testing.T) {
+	secret := bytes.Repeat([]byte{0x77}, 32)
+	shares, err := SSKRSplit(secret, 2, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Member 2 was removed: the new share comes after it
+	added, err := SSKRExtend(shares[:2], 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if added.MemberIndex != 3 {
+		t.Errorf("member index = %d, want 3", added.MemberIndex)
+	}
+	got, err := SSKRCombine([]*SSKRShare{added, shares[0]})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, secret) {
+		t.Errorf("got %x", got)
+	}
+
+	if _, err := SSKRExtend(shares, 1); err == nil {
+		t.Error("extended with a member index that's taken")
+	}
+	if _, err := SSKRExtend(shares, SSKRMaxShares); err == nil {
+		t.Error("extended past the last member index")
+	}
+}
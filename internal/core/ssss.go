@@ -0,0 +1,207 @@
+package core
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// ssss (B. Poettering's "Shamir's Secret Sharing Scheme" tool) splits a
+// secret of d bits over GF(2^d), evaluating at x = 1, 2, ... a polynomial
+// whose constant term is the secret and whose x^threshold term is 1, and
+// prints each share as "index-hex". ReMemory writes 32-byte secrets, so the
+// field is GF(2^256), and skips ssss's diffusion layer: the shares are
+// recovered with "ssss-combine -t K -x -D".
+const (
+	SSSSSecretBytes = 32
+	ssssDegree      = SSSSSecretBytes * 8
+)
+
+// ssssPoly is x^256 + x^10 + x^5 + x^2 + 1, the GF(2^256) reduction
+// polynomial in ssss's table of irreducible polynomials.
+var ssssPoly = new(big.Int).SetBit(big.NewInt(1<<10|1<<5|1<<2|1), ssssDegree, 1)
+
+// SSSSSplit splits a 32-byte secret into count ssss shares, any threshold of
+// which recover it.
+func SSSSSplit(secret []byte, threshold, count int) ([]string, error) {
+	if len(secret) != SSSSSecretBytes {
+		return nil, fmt.Errorf("ssss shares are made from %d-byte secrets, got %d", SSSSSecretBytes, len(secret))
+	}
+	if threshold < 2 || threshold > count {
+		return nil, fmt.Errorf("threshold must be between 2 and %d", count)
+	}
+
+	coeffs := []*big.Int{new(big.Int).SetBytes(secret)}
+	for i := 1; i < threshold; i++ {
+		b := make([]byte, SSSSSecretBytes)
+		if _, err := rand.Read(b); err != nil {
+			return nil, fmt.Errorf("generating coefficient: %w", err)
+		}
+		coeffs = append(coeffs, new(big.Int).SetBytes(b))
+	}
+
+	// ssss pads indexes to the width of the share count
+	width := len(strconv.Itoa(count))
+	shares := make([]string, count)
+	for i := range shares {
+		// Horner's rule, as ssss does it, starting from the x^threshold term
+		x := big.NewInt(int64(i + 1))
+		y := new(big.Int).Set(x)
+		for j := threshold - 1; j > 0; j-- {
+			y = gf2Mul(gf2Add(y, coeffs[j]), x)
+		}
+		y = gf2Add(y, coeffs[0])
+		shares[i] = formatSSSS(i+1, width, y)
+	}
+	return shares, nil
+}
+
+// SSSSExtend makes one more share from at least threshold existing ones,
+// at the next index after the highest given.
+func SSSSExtend(shares []string, threshold int) (string, error) {
+	xs, ys, err := parseSSSSSet(shares)
+	if err != nil {
+		return "", err
+	}
+	if len(xs) < threshold {
+		return "", fmt.Errorf("need %d ssss shares to make another, got %d", threshold, len(xs))
+	}
+	next, width := 0, 0
+	for i, x := range xs {
+		next = max(next, int(x.Int64())+1)
+		parts := strings.Split(strings.TrimSpace(shares[i]), "-")
+		width = max(width, len(parts[len(parts)-2]))
+	}
+	x := big.NewInt(int64(next))
+	y := gf2Add(ssssInterpolate(xs[:threshold], ys[:threshold], threshold, x), gf2Pow(x, threshold))
+	return formatSSSS(next, width, y), nil
+}
+
+// SSSSCombine recovers the secret from threshold ssss shares, as
+// "ssss-combine -t threshold -x -D" would. Only the first threshold shares
+// are used.
+func SSSSCombine(shares []string, threshold int) ([]byte, error) {
+	xs, ys, err := parseSSSSSet(shares)
+	if err != nil {
+		return nil, err
+	}
+	if threshold < 2 {
+		return nil, fmt.Errorf("need at least 2 ssss shares")
+	}
+	if len(xs) < threshold {
+		return nil, fmt.Errorf("need %d ssss shares, got %d", threshold, len(xs))
+	}
+	secret := ssssInterpolate(xs[:threshold], ys[:threshold], threshold, new(big.Int))
+	return secret.FillBytes(make([]byte, SSSSSecretBytes)), nil
+}
+
+// IsSSSSShare reports whether line looks like an ssss share of a 32-byte
+// secret: an optional token, an index, and 64 hex digits.
+func IsSSSSShare(line string) bool {
+	_, _, err := parseSSSS(line)
+	return err == nil
+}
+
+func formatSSSS(index, width int, y *big.Int) string {
+	return fmt.Sprintf("%0*d-%s", width, index, hex.EncodeToString(y.FillBytes(make([]byte, SSSSSecretBytes))))
+}
+
+// parseSSSS reads "[token-]index-hex".
+func parseSSSS(line string) (x, y *big.Int, err error) {
+	parts := strings.Split(strings.TrimSpace(line), "-")
+	if len(parts) < 2 {
+		return nil, nil, fmt.Errorf("not an ssss share")
+	}
+	index, err := strconv.Atoi(parts[len(parts)-2])
+	if err != nil || index < 1 {
+		return nil, nil, fmt.Errorf("invalid ssss share index %q", parts[len(parts)-2])
+	}
+	value := parts[len(parts)-1]
+	if len(value) != ssssDegree/4 {
+		return nil, nil, fmt.Errorf("ssss share has %d hex digits, expected %d", len(value), ssssDegree/4)
+	}
+	data, err := hex.DecodeString(value)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid ssss share: %w", err)
+	}
+	return big.NewInt(int64(index)), new(big.Int).SetBytes(data), nil
+}
+
+func parseSSSSSet(shares []string) (xs, ys []*big.Int, err error) {
+	seen := make(map[int64]bool)
+	for i, line := range shares {
+		x, y, err := parseSSSS(line)
+		if err != nil {
+			return nil, nil, fmt.Errorf("ssss share %d: %w", i+1, err)
+		}
+		if seen[x.Int64()] {
+			return nil, nil, fmt.Errorf("ssss share %d is a duplicate", i+1)
+		}
+		seen[x.Int64()] = true
+		xs, ys = append(xs, x), append(ys, y)
+	}
+	return xs, ys, nil
+}
+
+// ssssInterpolate evaluates at x the polynomial through the points (xs, ys),
+// less its x^threshold term.
+func ssssInterpolate(xs, ys []*big.Int, threshold int, x *big.Int) *big.Int {
+	result := new(big.Int)
+	for i := range xs {
+		y := gf2Add(ys[i], gf2Pow(xs[i], threshold))
+		num, den := big.NewInt(1), big.NewInt(1)
+		for j := range xs {
+			if i == j {
+				continue
+			}
+			num = gf2Mul(num, gf2Add(x, xs[j]))
+			den = gf2Mul(den, gf2Add(xs[i], xs[j]))
+		}
+		result = gf2Add(result, gf2Mul(y, gf2Mul(num, gf2Inverse(den))))
+	}
+	return result
+}
+
+// gf2Add adds in GF(2^256), which is XOR.
+func gf2Add(a, b *big.Int) *big.Int {
+	return new(big.Int).Xor(a, b)
+}
+
+// gf2Mul multiplies in GF(2^256), reducing by ssssPoly.
+func gf2Mul(a, b *big.Int) *big.Int {
+	result := new(big.Int)
+	a = new(big.Int).Set(a)
+	for i := 0; i < b.BitLen(); i++ {
+		if b.Bit(i) == 1 {
+			result.Xor(result, a)
+		}
+		a.Lsh(a, 1)
+		if a.Bit(ssssDegree) == 1 {
+			a.Xor(a, ssssPoly)
+		}
+	}
+	return result
+}
+
+// gf2Pow returns a^n for a small n.
+func gf2Pow(a *big.Int, n int) *big.Int {
+	result := big.NewInt(1)
+	for range n {
+		result = gf2Mul(result, a)
+	}
+	return result
+}
+
+// gf2Inverse returns a^(2^256 - 2), the inverse of a non-zero a.
+func gf2Inverse(a *big.Int) *big.Int {
+	result := big.NewInt(1)
+	square := new(big.Int).Set(a)
+	for i := 1; i < ssssDegree; i++ {
+		square = gf2Mul(square, square)
+		result = gf2Mul(result, square)
+	}
+	return result
+}
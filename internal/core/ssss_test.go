@@ -0,0 +1,107 @@
+package core
+
+import (
+	"bytes"
+	"math/big"
+	"strings"
+	"testing"
+)
+
+func TestSSSSField(t *testing.T) {
+	// x^255 * x wraps around to x^10 + x^5 + x^2 + 1
+	top := new(big.Int).SetBit(new(big.Int), 255, 1)
+	if got := gf2Mul(top, big.NewInt(2)); got.Cmp(big.NewInt(1<<10|1<<5|1<<2|1)) != 0 {
+		t.Errorf("x^256 = %x", got)
+	}
+	for _, a := range []int64{1, 2, 3, 0x1234} {
+		if got := gf2Mul(big.NewInt(a), gf2Inverse(big.NewInt(a))); got.Cmp(big.NewInt(1)) != 0 {
+			t.Errorf("%d * 1/%d = %x", a, a, got)
+		}
+	}
+}
+
+func TestSSSSSplitCombine(t *testing.T) {
+	secret := bytes.Repeat([]byte{0xa5}, 32)
+	shares, err := SSSSSplit(secret, 3, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, s := range shares {
+		if !strings.HasPrefix(s, string(rune('1'+i))+"-") || len(s) != 2+64 {
+			t.Errorf("share %d = %q", i, s)
+		}
+	}
+	for _, set := range [][]string{shares[:3], shares[2:], {shares[4], "tok-" + shares[0], shares[2]}} {
+		got, err := SSSSCombine(set, 3)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, secret) {
+			t.Errorf("got %x", got)
+		}
+	}
+	if _, err := SSSSCombine(shares[:2], 3); err == nil {
+		t.Error("expected an error below the threshold")
+	}
+	if _, err := SSSSCombine([]string{shares[0], shares[0], shares[1]}, 3); err == nil {
+		t.Error("expected an error for a duplicate")
+	}
+
+	// Two shares of a threshold-3 split don't give the secret as if the
+	// threshold were 2
+	if got, _ := SSSSCombine(shares[:2], 2); bytes.Equal(got, secret) {
+		t.Error("two shares recovered a threshold-3 secret")
+	}
+}
+
+func TestSSSSPolynomialIsMonic(t *testing.T) {
+	// With threshold 2, share x is x^2 + c1*x + secret, so shares 1 and 2
+	// of the zero secret satisfy y2 = 2*y1 + 6 (4 + 2 in GF(2^n))
+	shares, err := SSSSSplit(make([]byte, 32), 2, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, y1, _ := parseSSSS(shares[0])
+	_, y2, _ := parseSSSS(shares[1])
+	if want := gf2Add(gf2Mul(y1, big.NewInt(2)), big.NewInt(6)); y2.Cmp(want) != 0 {
+		t.Errorf("y2 = %x, want %x", y2, want)
+	}
+}
+
+func TestSSSSIndexWidth(t *testing.T) {
+	shares, err := SSSSSplit(bytes.Repeat([]byte{1}, 32), 2, 12)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(shares[0], "01-") || !strings.HasPrefix(shares[11], "12-") {
+		t.Errorf("shares = %q, %q", shares[0], shares[11])
+	}
+	if !IsSSSSShare(shares[3]) || IsSSSSShare("4-abcd") || IsSSSSShare("not a share") {
+		t.Error("IsSSSSShare")
+	}
+}
+
+func TestSSSSExtend(t *testing.T) {
+	secret := bytes.Repeat([]byte{0x3c}, 32)
+	shares, err := SSSSSplit(secret, 2, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	added, err := SSSSExtend(shares[1:], 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(added, "4-") {
+		t.Errorf("added = %q", added)
+	}
+	got, err := SSSSCombine([]string{added, shares[0]}, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, secret) {
+		t.Errorf("got %x", got)
+	}
+	if _, err := SSSSExtend(shares[:1], 2); err == nil {
+		t.Error("expected an error below the threshold")
+	}
+}
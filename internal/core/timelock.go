@@ -0,0 +1,258 @@
+package core
+
+import (
+	cryptorand "crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"time"
+)
+
+// A recovery delay makes recovery wait even once enough pieces come
+// together: the passphrase they rebuild doesn't open MANIFEST.age until it
+// has gone through a time-lock puzzle (Rivest, Shamir, and Wagner's): a
+// number squared over and over modulo N. Each squaring needs the one
+// before, so more computers don't finish any sooner. Sealing knows N's
+// factors, which shorten the whole chain to one exponentiation; they're
+// thrown away once the seal is done.
+
+// TimelockFile holds the puzzle of a project with a recovery delay. It's
+// written next to MANIFEST.age and embedded in recover.html. It holds
+// nothing secret: the puzzle starts from the passphrase.
+const TimelockFile = "TIMELOCK.json"
+
+const timelockFormat = "rememory-timelock/v1"
+
+// timelockBits is the size of N. Factoring it would skip the wait.
+const timelockBits = 2048
+
+// timelockChunk is how many squarings one big.Int.Exp call does; Exp
+// squares in Montgomery form, about twice as fast as Mul and Mod.
+const timelockChunk = 1 << 12
+
+// Timelock is the puzzle in TIMELOCK.json.
+type Timelock struct {
+	Format    string `json:"format"`
+	Modulus   string `json:"modulus"`   // N, in hex
+	Squarings uint64 `json:"squarings"` // How many times recovery squares, one after another
+	Delay     string `json:"delay"`     // How long that took on the computer that sealed, e.g. "72h0m0s"
+}
+
+// TimelockSeal is a puzzle with the factors of its modulus, which only
+// sealing has.
+type TimelockSeal struct {
+	Timelock
+	n, phi *big.Int
+}
+
+// BenchmarkTimelock measures how many squarings per second this computer
+// does, the best of three runs.
+func BenchmarkTimelock() float64 {
+	n := new(big.Int).Lsh(big.NewInt(1), timelockBits)
+	n.Sub(n, big.NewInt(159)) // Any odd modulus of the same size squares as fast
+	exp := new(big.Int).Lsh(big.NewInt(1), timelockChunk)
+	best := 0.0
+	for range 3 {
+		x := big.NewInt(3)
+		start := time.Now()
+		for range 4 {
+			x.Exp(x, exp, n)
+		}
+		if rate := 4 * timelockChunk / time.Since(start).Seconds(); rate > best {
+			best = rate
+		}
+	}
+	return best
+}
+
+// NewTimelock makes a puzzle that takes about delay to solve at rate
+// squarings per second, a BenchmarkTimelock result.
+func NewTimelock(delay time.Duration, rate float64) (*TimelockSeal, error) {
+	squarings := uint64(math.Max(1, math.Round(delay.Seconds()*rate)))
+	for {
+		p, err := cryptorand.Prime(cryptorand.Reader, timelockBits/2)
+		if err != nil {
+			return nil, fmt.Errorf("generating time-lock modulus: %w", err)
+		}
+		q, err := cryptorand.Prime(cryptorand.Reader, timelockBits/2)
+		if err != nil {
+			return nil, fmt.Errorf("generating time-lock modulus: %w", err)
+		}
+		if p.Cmp(q) == 0 {
+			continue
+		}
+		one := big.NewInt(1)
+		n := new(big.Int).Mul(p, q)
+		phi := new(big.Int).Mul(new(big.Int).Sub(p, one), new(big.Int).Sub(q, one))
+		return &TimelockSeal{
+			Timelock: Timelock{
+				Format:    timelockFormat,
+				Modulus:   n.Text(16),
+				Squarings: squarings,
+				Delay:     delay.String(),
+			},
+			n:   n,
+			phi: phi,
+		}, nil
+	}
+}
+
+// Lock returns the passphrase that MANIFEST.age is encrypted with, for the
+// passphrase the pieces rebuild: what recovery gets after solving the
+// puzzle, without the wait.
+func (s *TimelockSeal) Lock(passphrase Secret) Secret {
+	// x^(2^T) = x^(2^T mod φ(N)) mod N
+	e := new(big.Int).Exp(big.NewInt(2), new(big.Int).SetUint64(s.Squarings), s.phi)
+	x := new(big.Int).Exp(timelockStart(passphrase, s.n), e, s.n)
+	return timelockKey(passphrase, x, s.n)
+}
+
+// ParseTimelock reads TIMELOCK.json.
+func ParseTimelock(data []byte) (*Timelock, error) {
+	var tl Timelock
+	if err := json.Unmarshal(data, &tl); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", TimelockFile, err)
+	}
+	if err := tl.check(); err != nil {
+		return nil, err
+	}
+	return &tl, nil
+}
+
+// Encode returns the puzzle as TIMELOCK.json.
+func (tl *Timelock) Encode() ([]byte, error) {
+	data, err := json.MarshalIndent(tl, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("encoding %s: %w", TimelockFile, err)
+	}
+	return append(data, '\n'), nil
+}
+
+// SealedDelay returns how long the puzzle took on the computer that sealed,
+// or zero if TIMELOCK.json doesn't say.
+func (tl *Timelock) SealedDelay() time.Duration {
+	d, _ := time.ParseDuration(tl.Delay)
+	return d
+}
+
+func (tl *Timelock) check() error {
+	if tl.Format != timelockFormat {
+		return fmt.Errorf("%s: unknown format %q", TimelockFile, tl.Format)
+	}
+	if _, err := tl.modulus(); err != nil {
+		return err
+	}
+	if tl.Squarings == 0 {
+		return fmt.Errorf("%s: no squarings", TimelockFile)
+	}
+	return nil
+}
+
+func (tl *Timelock) modulus() (*big.Int, error) {
+	n, ok := new(big.Int).SetString(tl.Modulus, 16)
+	if !ok || n.BitLen() < timelockBits || n.Bit(0) == 0 {
+		return nil, fmt.Errorf("%s: invalid modulus", TimelockFile)
+	}
+	return n, nil
+}
+
+// TimelockSolver works through a puzzle a step at a time, so callers can
+// show progress and stop.
+type TimelockSolver struct {
+	tl         *Timelock
+	n, x       *big.Int
+	done       uint64
+	passphrase Secret
+}
+
+// Solver starts the puzzle for the passphrase the pieces rebuilt.
+func (tl *Timelock) Solver(passphrase Secret) (*TimelockSolver, error) {
+	if err := tl.check(); err != nil {
+		return nil, err
+	}
+	n, _ := tl.modulus()
+	return &TimelockSolver{
+		tl:         tl,
+		n:          n,
+		x:          timelockStart(passphrase, n),
+		passphrase: append(Secret(nil), passphrase...),
+	}, nil
+}
+
+// Step does up to count more squarings and reports whether the puzzle is
+// solved.
+func (s *TimelockSolver) Step(count uint64) bool {
+	for count > 0 && s.done < s.tl.Squarings {
+		k := min(count, s.tl.Squarings-s.done, timelockChunk)
+		s.x.Exp(s.x, new(big.Int).Lsh(big.NewInt(1), uint(k)), s.n)
+		s.done += k
+		count -= k
+	}
+	return s.done == s.tl.Squarings
+}
+
+// Progress returns the squarings done and the total.
+func (s *TimelockSolver) Progress() (done, total uint64) {
+	return s.done, s.tl.Squarings
+}
+
+// Passphrase returns the passphrase that opens MANIFEST.age, once Step
+// reports the puzzle solved.
+func (s *TimelockSolver) Passphrase() (Secret, error) {
+	if s.done < s.tl.Squarings {
+		return nil, fmt.Errorf("the recovery delay isn't over: %d of %d squarings done", s.done, s.tl.Squarings)
+	}
+	return timelockKey(s.passphrase, s.x, s.n), nil
+}
+
+// Wipe clears the passphrase the solver holds.
+func (s *TimelockSolver) Wipe() {
+	s.passphrase.Wipe()
+	s.x.SetInt64(0)
+}
+
+// Unlock solves the puzzle in one go, calling progress now and then, and
+// returns the passphrase that opens MANIFEST.age.
+func (tl *Timelock) Unlock(passphrase Secret, progress func(done, total uint64)) (Secret, error) {
+	s, err := tl.Solver(passphrase)
+	if err != nil {
+		return nil, err
+	}
+	defer s.Wipe()
+	for !s.Step(64 * timelockChunk) {
+		if progress != nil {
+			progress(s.Progress())
+		}
+	}
+	return s.Passphrase()
+}
+
+// timelockStart derives the number the puzzle squares from the passphrase,
+// so no one can start the wait before they have enough pieces.
+func timelockStart(passphrase Secret, n *big.Int) *big.Int {
+	h := sha256.New()
+	h.Write([]byte("rememory timelock start\x00"))
+	h.Write(passphrase)
+	x := new(big.Int).SetBytes(h.Sum(nil))
+	return x.Mod(x, n)
+}
+
+// timelockKey derives the passphrase that opens MANIFEST.age from the
+// pieces' passphrase and the solved puzzle, in the pieces' passphrase
+// format.
+func timelockKey(passphrase Secret, x, n *big.Int) Secret {
+	solved := x.FillBytes(make([]byte, (n.BitLen()+7)/8))
+	h := sha256.New()
+	h.Write([]byte("rememory timelock key\x00"))
+	h.Write(passphrase)
+	h.Write(solved)
+	Wipe(solved)
+	sum := h.Sum(nil)
+	defer Wipe(sum)
+	key := make(Secret, base64.RawURLEncoding.EncodedLen(len(sum)))
+	base64.RawURLEncoding.Encode(key, sum)
+	return key
+}
@@ -0,0 +1,94 @@
+package core
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTimelock(t *testing.T) {
+	seal, err := NewTimelock(time.Second, 10000)
+	if err != nil {
+		t.Fatalf("NewTimelock: %v", err)
+	}
+	if seal.Squarings != 10000 || seal.SealedDelay() != time.Second {
+		t.Fatalf("puzzle has %d squarings over %s, want 10000 over 1s", seal.Squarings, seal.SealedDelay())
+	}
+
+	passphrase := Secret("the passphrase the pieces rebuild")
+	locked := seal.Lock(passphrase)
+	if bytes.Equal(locked, passphrase) {
+		t.Fatal("Lock returned the passphrase itself")
+	}
+
+	data, err := seal.Encode()
+	if err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+	tl, err := ParseTimelock(data)
+	if err != nil {
+		t.Fatalf("ParseTimelock: %v", err)
+	}
+
+	unlocked, err := tl.Unlock(passphrase, func(done, total uint64) {
+		if done >= total {
+			t.Errorf("progress reported %d of %d before the end", done, total)
+		}
+	})
+	if err != nil {
+		t.Fatalf("Unlock: %v", err)
+	}
+	if !bytes.Equal(unlocked, locked) {
+		t.Errorf("solving the puzzle gave %q, sealing gave %q", unlocked, locked)
+	}
+
+	other, err := tl.Unlock(Secret("another passphrase"), nil)
+	if err != nil {
+		t.Fatalf("Unlock: %v", err)
+	}
+	if bytes.Equal(other, locked) {
+		t.Error("a different passphrase solved to the same key")
+	}
+}
+
+func TestTimelockSolverSteps(t *testing.T) {
+	seal, err := NewTimelock(time.Second, 5000)
+	if err != nil {
+		t.Fatalf("NewTimelock: %v", err)
+	}
+	passphrase := Secret("pieces")
+	s, err := seal.Solver(passphrase)
+	if err != nil {
+		t.Fatalf("Solver: %v", err)
+	}
+	if _, err := s.Passphrase(); err == nil {
+		t.Error("expected an error before the puzzle is solved")
+	}
+	for !s.Step(777) {
+	}
+	if done, total := s.Progress(); done != total {
+		t.Errorf("progress = %d of %d after solving", done, total)
+	}
+	got, err := s.Passphrase()
+	if err != nil {
+		t.Fatalf("Passphrase: %v", err)
+	}
+	if want := seal.Lock(passphrase); !bytes.Equal(got, want) {
+		t.Errorf("stepping gave %q, sealing gave %q", got, want)
+	}
+}
+
+func TestParseTimelockRejects(t *testing.T) {
+	tests := map[string]string{
+		"format":    `{"format":"other","modulus":"ff","squarings":1}`,
+		"modulus":   `{"format":"rememory-timelock/v1","modulus":"ff","squarings":1}`,
+		"squarings": `{"format":"rememory-timelock/v1","modulus":"` + strings.Repeat("f", 512) + `","squarings":0}`,
+		"json":      `{`,
+	}
+	for name, data := range tests {
+		if _, err := ParseTimelock([]byte(data)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
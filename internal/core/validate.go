@@ -0,0 +1,126 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Limits on a friend's details. Names and contacts are printed on every
+// bundle, so they need to fit on a line.
+const (
+	MaxNameLength    = 200
+	MaxContactLength = 500
+)
+
+// ValidationError is a problem with a project's setup, naming the field it
+// is about ("threshold", "friends[2].name") for callers that show it next
+// to the input or print it as JSON.
+type ValidationError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+func (e *ValidationError) Error() string {
+	return e.Message
+}
+
+// invalid returns a ValidationError for field.
+func invalid(field, format string, args ...any) *ValidationError {
+	return &ValidationError{Field: field, Message: fmt.Sprintf(format, args...)}
+}
+
+// AsValidationError returns the ValidationError in err's chain, or nil.
+func AsValidationError(err error) *ValidationError {
+	var ve *ValidationError
+	if errors.As(err, &ve) {
+		return ve
+	}
+	return nil
+}
+
+// Holder is a friend as ValidateHolders sees them.
+type Holder struct {
+	Name    string
+	Contact string
+}
+
+// ValidateThreshold checks that threshold of total pieces can be split:
+// at least two of each, and no more than Split allows.
+func ValidateThreshold(threshold, total int) error {
+	if total < 2 {
+		return invalid("friends", "need at least 2 friends, got %d", total)
+	}
+	if total > 255 {
+		return invalid("friends", "maximum 255 friends supported, got %d", total)
+	}
+	if threshold < 2 {
+		return invalid("threshold", "threshold must be at least 2, got %d", threshold)
+	}
+	if threshold > total {
+		return invalid("threshold", "threshold (%d) cannot exceed number of friends (%d)", threshold, total)
+	}
+	return nil
+}
+
+// ValidateName checks a holder's name. Names head every README and become
+// file names, so they can't be empty, overlong, or hold line breaks.
+func ValidateName(name string) error {
+	if strings.TrimSpace(name) == "" {
+		return invalid("name", "name is required")
+	}
+	if len(name) > MaxNameLength {
+		return invalid("name", "name too long (max %d characters)", MaxNameLength)
+	}
+	if strings.IndexFunc(name, unicode.IsControl) >= 0 {
+		return invalid("name", "name %q has a line break or control character", name)
+	}
+	return nil
+}
+
+// ValidateContact checks a holder's contact details. Other bundles print
+// them on one line, where a line break could pass for text of ReMemory's.
+func ValidateContact(contact string) error {
+	if len(contact) > MaxContactLength {
+		return invalid("contact", "contact info too long (max %d characters)", MaxContactLength)
+	}
+	if strings.IndexFunc(contact, unicode.IsControl) >= 0 {
+		return invalid("contact", "contact %q has a line break or control character", contact)
+	}
+	return nil
+}
+
+// ValidateHolders checks every holder's name and contact, and that no two
+// share a file name: SanitizeFilename keeps only Latin letters and digits,
+// so "José" and "jose", or two names in other scripts, would overwrite
+// each other's share file and bundle.
+func ValidateHolders(holders []Holder) error {
+	seen := make(map[string]string)
+	for i, h := range holders {
+		if err := ValidateName(h.Name); err != nil {
+			return holderError(i, err)
+		}
+		if err := ValidateContact(h.Contact); err != nil {
+			return holderError(i, err)
+		}
+		key := SanitizeFilename(h.Name)
+		if other, ok := seen[key]; ok {
+			if key == "" {
+				return invalid(fmt.Sprintf("friends[%d].name", i), "friends %q and %q have no Latin letters or digits for their file names; add a Latin spelling to one, such as \"李明 (Li Ming)\"", other, h.Name)
+			}
+			return invalid(fmt.Sprintf("friends[%d].name", i), "friends %q and %q would share the file name %q", other, h.Name, key)
+		}
+		seen[key] = h.Name
+	}
+	return nil
+}
+
+// holderError places a name or contact error under the holder's field.
+func holderError(i int, err error) error {
+	ve := AsValidationError(err)
+	if ve == nil {
+		return err
+	}
+	return invalid(fmt.Sprintf("friends[%d].%s", i, ve.Field), "friend %d: %s", i+1, ve.Message)
+}
@@ -0,0 +1,68 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestValidateThreshold(t *testing.T) {
+	tests := []struct {
+		threshold, total int
+		field            string
+	}{
+		{2, 3, ""},
+		{3, 3, ""},
+		{2, 1, "friends"},
+		{2, 256, "friends"},
+		{1, 3, "threshold"},
+		{4, 3, "threshold"},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%d-of-%d", tt.threshold, tt.total), func(t *testing.T) {
+			err := ValidateThreshold(tt.threshold, tt.total)
+			if tt.field == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if ve := AsValidationError(err); ve == nil || ve.Field != tt.field {
+				t.Errorf("got %v, want an error about %q", err, tt.field)
+			}
+		})
+	}
+}
+
+func TestValidateHolders(t *testing.T) {
+	tests := []struct {
+		name    string
+		holders []Holder
+		field   string
+		wantErr string
+	}{
+		{"valid", []Holder{{Name: "Alice", Contact: "alice@example.com"}, {Name: "李明"}}, "", ""},
+		{"empty name", []Holder{{Name: "Alice"}, {Name: " "}}, "friends[1].name", "friend 2: name is required"},
+		{"long name", []Holder{{Name: strings.Repeat("a", MaxNameLength+1)}}, "friends[0].name", "too long"},
+		{"line break in name", []Holder{{Name: "Alice\nBEGIN REMEMORY SHARE"}}, "friends[0].name", "line break"},
+		{"long contact", []Holder{{Name: "Alice", Contact: strings.Repeat("a", MaxContactLength+1)}}, "friends[0].contact", "too long"},
+		{"line break in contact", []Holder{{Name: "Alice", Contact: "a@example.com\r\nEve"}}, "friends[0].contact", "line break"},
+		{"same file name", []Holder{{Name: "José"}, {Name: "jose"}}, "friends[1].name", `file name "jose"`},
+		{"no Latin letters", []Holder{{Name: "李明"}, {Name: "王芳"}}, "friends[1].name", "Latin spelling"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateHolders(tt.holders)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			ve := AsValidationError(err)
+			if ve == nil || ve.Field != tt.field || !strings.Contains(ve.Message, tt.wantErr) {
+				t.Errorf("got %#v, want %s error containing %q", ve, tt.field, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,258 @@
+package core
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"math"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	// MinVolumeSize is the smallest volume MANIFEST.age is split into, so a
+	// typo can't turn it into thousands of files.
+	MinVolumeSize = 1_000_000
+
+	// VolumeIndexSuffix names the file listing a split file's volumes and
+	// their checksums, in the format sha256sum -c reads: MANIFEST.age.sha256.
+	VolumeIndexSuffix = ".sha256"
+)
+
+// ParseVolumeSize reads a volume size: a number of bytes, or one with K, M,
+// G, or T after it (KB, MB, ... too), in powers of 1000 as disc and drive
+// makers count, so 4G fits on FAT32 and 4.7G on a DVD.
+func ParseVolumeSize(s string) (int64, error) {
+	text := strings.ToUpper(strings.TrimSpace(s))
+	text = strings.TrimSuffix(text, "B")
+	multiplier := 1.0
+	if text != "" {
+		switch text[len(text)-1] {
+		case 'K':
+			multiplier = 1e3
+		case 'M':
+			multiplier = 1e6
+		case 'G':
+			multiplier = 1e9
+		case 'T':
+			multiplier = 1e12
+		}
+		if multiplier > 1 {
+			text = text[:len(text)-1]
+		}
+	}
+	n, err := strconv.ParseFloat(strings.TrimSpace(text), 64)
+	if err != nil || n <= 0 || math.IsInf(n, 0) {
+		return 0, fmt.Errorf("invalid volume size %q: use a size like 4G or 700M", s)
+	}
+	size := int64(n * multiplier)
+	if size < MinVolumeSize {
+		return 0, fmt.Errorf("volume size %q is too small: volumes must be at least 1M", s)
+	}
+	return size, nil
+}
+
+// VolumeName names volume seq of a file split into total volumes:
+// MANIFEST.age.001, MANIFEST.age.002, and so on, with more digits past 999.
+func VolumeName(base string, seq, total int) string {
+	digits := max(3, len(strconv.Itoa(total)))
+	return fmt.Sprintf("%s.%0*d", base, digits, seq)
+}
+
+// ParseVolumeName reads a volume's file name back into the name of the
+// file it is part of and its number.
+func ParseVolumeName(name string) (base string, seq int, ok bool) {
+	name = path.Base(strings.ReplaceAll(name, `\`, "/"))
+	i := strings.LastIndexByte(name, '.')
+	if i <= 0 || len(name)-i-1 < 3 {
+		return "", 0, false
+	}
+	for _, c := range name[i+1:] {
+		if c < '0' || c > '9' {
+			return "", 0, false
+		}
+	}
+	seq, err := strconv.Atoi(name[i+1:])
+	if err != nil || seq < 1 {
+		return "", 0, false
+	}
+	return name[:i], seq, true
+}
+
+// Volume is one part of a split file.
+type Volume struct {
+	Name     string
+	Size     int64
+	Checksum string // sha256:...
+}
+
+// VolumeIndex lists the volumes a file was split into, with the checksum
+// and size of each and of the whole file.
+type VolumeIndex struct {
+	Name     string // The whole file's name, e.g. MANIFEST.age
+	Size     int64
+	Checksum string
+	Volumes  []Volume
+}
+
+// Text returns the index as written to MANIFEST.age.sha256: a comment that
+// says what the files are and records the whole file, then a line for each
+// volume that sha256sum -c can check.
+func (x *VolumeIndex) Text() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# %s in %d volumes. rememory recover and recover.html put them back\n", x.Name, len(x.Volumes))
+	fmt.Fprintf(&sb, "# together; so does: cat %s.[0-9]* > %s\n", x.Name, x.Name)
+	fmt.Fprintf(&sb, "# whole: %s %d %s\n", x.Checksum, x.Size, x.Name)
+	for _, v := range x.Volumes {
+		fmt.Fprintf(&sb, "%s  %s\n", strings.TrimPrefix(v.Checksum, "sha256:"), v.Name)
+	}
+	return sb.String()
+}
+
+// ParseVolumeIndex reads a MANIFEST.age.sha256 file.
+func ParseVolumeIndex(data []byte) (*VolumeIndex, error) {
+	x := &VolumeIndex{}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if rest, ok := strings.CutPrefix(line, "# whole:"); ok {
+			fields := strings.Fields(rest)
+			if len(fields) != 3 {
+				return nil, fmt.Errorf("invalid volume index: bad whole line")
+			}
+			size, err := strconv.ParseInt(fields[1], 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid volume index: bad size %q", fields[1])
+			}
+			x.Checksum, x.Size, x.Name = fields[0], size, fields[2]
+			continue
+		}
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		sum, name, ok := strings.Cut(line, "  ")
+		if !ok || len(sum) != 64 {
+			return nil, fmt.Errorf("invalid volume index line: %q", line)
+		}
+		x.Volumes = append(x.Volumes, Volume{Name: strings.TrimSpace(name), Checksum: "sha256:" + sum})
+	}
+	if x.Name == "" || len(x.Volumes) == 0 {
+		return nil, fmt.Errorf("invalid volume index: it doesn't list a file and its volumes")
+	}
+	return x, nil
+}
+
+// SplitVolumes copies the file name, size bytes long, from r into volumes
+// of at most volumeSize bytes, each written to what create returns for its
+// name, and returns their index.
+func SplitVolumes(r io.Reader, name string, size, volumeSize int64, create func(name string) (io.WriteCloser, error)) (*VolumeIndex, error) {
+	total := int((size + volumeSize - 1) / volumeSize)
+	x := &VolumeIndex{Name: name, Size: size}
+	whole := sha256.New()
+	for seq := 1; seq <= total; seq++ {
+		v := Volume{Name: VolumeName(name, seq, total), Size: min(volumeSize, size-int64(seq-1)*volumeSize)}
+		w, err := create(v.Name)
+		if err != nil {
+			return nil, err
+		}
+		h := sha256.New()
+		_, err = io.CopyN(io.MultiWriter(w, h, whole), r, v.Size)
+		if closeErr := w.Close(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return nil, fmt.Errorf("writing %s: %w", v.Name, err)
+		}
+		v.Checksum = "sha256:" + hex.EncodeToString(h.Sum(nil))
+		x.Volumes = append(x.Volumes, v)
+	}
+	x.Checksum = "sha256:" + hex.EncodeToString(whole.Sum(nil))
+	return x, nil
+}
+
+// VolumePart is a volume's file name and content, as given to JoinVolumes.
+type VolumePart struct {
+	Name string
+	Data []byte
+}
+
+// JoinVolumes puts a split file back together from its volumes, in any
+// order. With the index, it checks every volume is there and intact, and
+// the whole file too; without it, it can only check that the numbers run
+// on from 1 without a gap and the sizes fit a split, and leaves a missing
+// last volume to the decryption, which notices the file ends too soon.
+func JoinVolumes(parts []VolumePart, index *VolumeIndex) ([]byte, error) {
+	if len(parts) == 0 {
+		return nil, fmt.Errorf("no volumes")
+	}
+	bySeq := make(map[int]VolumePart)
+	base := ""
+	for _, part := range parts {
+		name, seq, ok := ParseVolumeName(part.Name)
+		if !ok {
+			return nil, fmt.Errorf("%s isn't a numbered volume like MANIFEST.age.001", part.Name)
+		}
+		if base == "" {
+			base = name
+		} else if name != base {
+			return nil, fmt.Errorf("volumes of different files: %s and %s", base, name)
+		}
+		if prev, ok := bySeq[seq]; ok && !bytes.Equal(prev.Data, part.Data) {
+			return nil, fmt.Errorf("two different files are volume %d of %s", seq, base)
+		}
+		bySeq[seq] = part
+	}
+	seqs := make([]int, 0, len(bySeq))
+	for seq := range bySeq {
+		seqs = append(seqs, seq)
+	}
+	sort.Ints(seqs)
+
+	total := seqs[len(seqs)-1]
+	if index != nil {
+		if index.Name != base {
+			return nil, fmt.Errorf("the volume index is for %s, not %s", index.Name, base)
+		}
+		total = len(index.Volumes)
+	}
+	var missing []int
+	for seq := 1; seq <= total; seq++ {
+		if _, ok := bySeq[seq]; !ok {
+			missing = append(missing, seq)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("missing volume %s of %d of %s", joinInts(missing), total, base)
+	}
+	if seqs[len(seqs)-1] > total {
+		return nil, fmt.Errorf("%s has %d volumes, but volume %d was given", base, total, seqs[len(seqs)-1])
+	}
+
+	var joined bytes.Buffer
+	for seq := 1; seq <= total; seq++ {
+		part := bySeq[seq]
+		if index != nil {
+			want := index.Volumes[seq-1]
+			if _, wantSeq, ok := ParseVolumeName(want.Name); !ok || wantSeq != seq {
+				return nil, fmt.Errorf("invalid volume index: volume %d is listed as %s", seq, want.Name)
+			}
+			if HashBytes(part.Data) != want.Checksum {
+				return nil, fmt.Errorf("%s is damaged, or from a different seal", part.Name)
+			}
+		} else if (seq < total && len(part.Data) != len(bySeq[1].Data)) || (seq == total && len(part.Data) > len(bySeq[1].Data)) {
+			// A split makes every volume the same size but the last
+			return nil, fmt.Errorf("%s isn't the size of the other volumes; it may be damaged, or from a different seal", part.Name)
+		}
+		joined.Write(part.Data)
+	}
+	data := joined.Bytes()
+	if index != nil && (int64(len(data)) != index.Size || HashBytes(data) != index.Checksum) {
+		return nil, fmt.Errorf("the volumes of %s don't fit together", base)
+	}
+	return data, nil
+}
@@ -0,0 +1,130 @@
+package core
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestParseVolumeSize(t *testing.T) {
+	for _, tc := range []struct {
+		in   string
+		want int64
+	}{
+		{"4G", 4_000_000_000},
+		{"4.7g", 4_700_000_000},
+		{"700MB", 700_000_000},
+		{"2000000", 2_000_000},
+		{" 1T ", 1_000_000_000_000},
+	} {
+		got, err := ParseVolumeSize(tc.in)
+		if err != nil || got != tc.want {
+			t.Errorf("ParseVolumeSize(%q) = %d, %v; want %d", tc.in, got, err, tc.want)
+		}
+	}
+	for _, bad := range []string{"", "G", "-4G", "4X", "10K", "0"} {
+		if _, err := ParseVolumeSize(bad); err == nil {
+			t.Errorf("ParseVolumeSize(%q) should fail", bad)
+		}
+	}
+}
+
+func TestVolumeName(t *testing.T) {
+	if got := VolumeName("MANIFEST.age", 2, 5); got != "MANIFEST.age.002" {
+		t.Errorf("got %s", got)
+	}
+	if got := VolumeName("MANIFEST.age", 7, 1200); got != "MANIFEST.age.0007" {
+		t.Errorf("got %s", got)
+	}
+	base, seq, ok := ParseVolumeName("/media/usb/MANIFEST.age.0012")
+	if !ok || base != "MANIFEST.age" || seq != 12 {
+		t.Errorf("ParseVolumeName = %q, %d, %v", base, seq, ok)
+	}
+	for _, name := range []string{"MANIFEST.age", "MANIFEST.age.sha256", "MANIFEST.age.01", "MANIFEST.age.000", ".001"} {
+		if _, _, ok := ParseVolumeName(name); ok {
+			t.Errorf("%s isn't a volume", name)
+		}
+	}
+}
+
+// splitForTest splits data into volumes of size bytes.
+func splitForTest(t *testing.T, data []byte, size int64) ([]VolumePart, *VolumeIndex) {
+	t.Helper()
+	files := map[string]*bytes.Buffer{}
+	index, err := SplitVolumes(bytes.NewReader(data), "MANIFEST.age", int64(len(data)), size, func(name string) (io.WriteCloser, error) {
+		files[name] = &bytes.Buffer{}
+		return nopCloser{files[name]}, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var parts []VolumePart
+	for _, v := range index.Volumes {
+		parts = append(parts, VolumePart{Name: v.Name, Data: files[v.Name].Bytes()})
+	}
+	return parts, index
+}
+
+type nopCloser struct{ io.Writer }
+
+func (nopCloser) Close() error { return nil }
+
+func TestSplitAndJoinVolumes(t *testing.T) {
+	data := bytes.Repeat([]byte("0123456789"), 25)
+	parts, index := splitForTest(t, data, 100)
+	if len(parts) != 3 || len(parts[2].Data) != 50 || parts[0].Name != "MANIFEST.age.001" {
+		t.Fatalf("split into %d volumes: %+v", len(parts), index.Volumes)
+	}
+	if index.Checksum != HashBytes(data) || index.Size != 250 {
+		t.Errorf("index = %+v", index)
+	}
+
+	// The index round-trips through its text
+	parsed, err := ParseVolumeIndex([]byte(index.Text()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsed.Name != "MANIFEST.age" || parsed.Checksum != index.Checksum || len(parsed.Volumes) != 3 || parsed.Volumes[1].Checksum != index.Volumes[1].Checksum {
+		t.Errorf("parsed index = %+v", parsed)
+	}
+
+	// In any order, with or without the index, and with a volume given twice
+	shuffled := []VolumePart{parts[2], parts[0], parts[1], parts[0]}
+	for _, x := range []*VolumeIndex{parsed, nil} {
+		joined, err := JoinVolumes(shuffled, x)
+		if err != nil || !bytes.Equal(joined, data) {
+			t.Errorf("join (index %v): %v", x != nil, err)
+		}
+	}
+}
+
+func TestJoinVolumesChecks(t *testing.T) {
+	data := bytes.Repeat([]byte("0123456789"), 25)
+	parts, index := splitForTest(t, data, 100)
+
+	damaged := append([]byte{}, parts[1].Data...)
+	damaged[0] ^= 1
+	other, _ := splitForTest(t, bytes.Repeat([]byte("x"), 250), 100)
+
+	for _, tc := range []struct {
+		name  string
+		parts []VolumePart
+		index *VolumeIndex
+		want  string
+	}{
+		{"missing middle", []VolumePart{parts[0], parts[2]}, nil, "missing volume 2 of 3"},
+		{"missing last", []VolumePart{parts[0], parts[1]}, index, "missing volume 3 of 3"},
+		{"damaged", []VolumePart{parts[0], {parts[1].Name, damaged}, parts[2]}, index, "MANIFEST.age.002 is damaged"},
+		{"mixed", []VolumePart{parts[0], other[1], parts[2]}, index, "MANIFEST.age.002 is damaged"},
+		{"short middle", []VolumePart{parts[0], {parts[1].Name, parts[1].Data[:10]}, parts[2]}, nil, "isn't the size"},
+		{"conflict", []VolumePart{parts[0], parts[1], {parts[1].Name, damaged}, parts[2]}, nil, "two different files"},
+		{"not a volume", []VolumePart{{"MANIFEST.age", data}}, nil, "isn't a numbered volume"},
+		{"too many", append(parts, VolumePart{"MANIFEST.age.004", nil}), index, "has 3 volumes"},
+	} {
+		_, err := JoinVolumes(tc.parts, tc.index)
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("%s: got %v, want %q", tc.name, err, tc.want)
+		}
+	}
+}
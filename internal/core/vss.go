@@ -0,0 +1,192 @@
+package core
+
+import (
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+)
+
+// Verifiable secret sharing lets one piece be checked on its own, before
+// enough pieces are gathered to recover. Pieces are split over GF(2^8),
+// which has no group to commit in, so each piece also carries its share of
+// the same secret in a second split, over the scalars of P-256, in the PEM
+// "VSS:" line. The seal publishes Feldman commitments to that split's
+// polynomial, a_j·G for each coefficient, in every bundle. A piece's value
+// y checks out when y·G equals the sum of C_j·i^j: then it lies on the
+// polynomial the seal committed to, and any threshold of such values
+// rebuild the secret C_0 commits to. The commitments reveal nothing about
+// the secret short of a discrete logarithm.
+//
+// Only the PEM form carries the value. Pieces locked with a PIN leave it
+// out, since it would open without the PIN, and so do grouped projects and
+// projects with a decoy, whose duress pieces couldn't carry a value that
+// checks out.
+
+// vssCurve is the group the commitments are points of.
+var vssCurve = elliptic.P256()
+
+// vssValueSize is the length of a piece's value: a P-256 scalar.
+const vssValueSize = 32
+
+// VSSCommitments are a seal's Feldman commitments, one compressed P-256
+// point per coefficient of the polynomial, constant term first. There are
+// as many as the threshold.
+type VSSCommitments [][]byte
+
+// SplitVSS splits secret a second time over the P-256 scalar field,
+// threshold needed, records each piece's value by the piece's index, and
+// returns the commitments to the polynomial.
+func SplitVSS(secret []byte, threshold int, pieces []*Share) (VSSCommitments, error) {
+	if threshold < 2 {
+		return nil, fmt.Errorf("VSS threshold must be at least 2, got %d", threshold)
+	}
+	n := vssCurve.Params().N
+	coeffs := make([]*big.Int, threshold)
+	coeffs[0] = new(big.Int).Mod(new(big.Int).SetBytes(secret), n)
+	for j := 1; j < threshold; j++ {
+		c, err := rand.Int(rand.Reader, n)
+		if err != nil {
+			return nil, fmt.Errorf("generating VSS polynomial: %w", err)
+		}
+		coeffs[j] = c
+	}
+	defer func() {
+		for _, c := range coeffs {
+			c.SetInt64(0)
+		}
+	}()
+
+	commitments := make(VSSCommitments, threshold)
+	for j, c := range coeffs {
+		x, y := vssCurve.ScalarBaseMult(c.FillBytes(make([]byte, vssValueSize)))
+		commitments[j] = elliptic.MarshalCompressed(vssCurve, x, y)
+	}
+	for _, s := range pieces {
+		if s.Index < 1 {
+			return nil, fmt.Errorf("piece %d has no place in the VSS split", s.Index)
+		}
+		s.VSS = vssEval(coeffs, s.Index).FillBytes(make([]byte, vssValueSize))
+	}
+	return commitments, nil
+}
+
+// vssEval evaluates the polynomial at x, modulo the group order.
+func vssEval(coeffs []*big.Int, x int) *big.Int {
+	n := vssCurve.Params().N
+	bx := big.NewInt(int64(x))
+	y := new(big.Int)
+	for j := len(coeffs) - 1; j >= 0; j-- {
+		y.Mul(y, bx)
+		y.Add(y, coeffs[j])
+		y.Mod(y, n)
+	}
+	return y
+}
+
+// ExtendVSS gives share the value of the same split at its index, by
+// interpolating the values of pieces, which must be at least threshold.
+func ExtendVSS(pieces []*Share, threshold int, share *Share) error {
+	n := vssCurve.Params().N
+	var xs []int
+	var ys []*big.Int
+	seen := make(map[int]bool)
+	for _, s := range pieces {
+		if len(s.VSS) == 0 || seen[s.Index] {
+			continue
+		}
+		seen[s.Index] = true
+		xs = append(xs, s.Index)
+		ys = append(ys, new(big.Int).SetBytes(s.VSS))
+		if len(xs) == threshold {
+			break
+		}
+	}
+	if len(xs) < threshold {
+		return fmt.Errorf("extending the VSS split needs %d pieces that carry it; got %d", threshold, len(xs))
+	}
+
+	// Lagrange interpolation at the new index
+	x := big.NewInt(int64(share.Index))
+	value := new(big.Int)
+	for i, xi := range xs {
+		num, den := big.NewInt(1), big.NewInt(1)
+		for j, xj := range xs {
+			if i == j {
+				continue
+			}
+			num.Mul(num, new(big.Int).Sub(x, big.NewInt(int64(xj))))
+			num.Mod(num, n)
+			den.Mul(den, big.NewInt(int64(xi-xj)))
+			den.Mod(den, n)
+		}
+		term := new(big.Int).Mul(ys[i], num)
+		term.Mul(term, new(big.Int).ModInverse(den, n))
+		value.Add(value, term)
+		value.Mod(value, n)
+	}
+	share.VSS = value.FillBytes(make([]byte, vssValueSize))
+	return nil
+}
+
+// Verify checks a piece's VSS value against the commitments.
+func (c VSSCommitments) Verify(index int, value []byte) error {
+	if len(c) < 2 {
+		return fmt.Errorf("no VSS commitments to check against")
+	}
+	if index < 1 {
+		return fmt.Errorf("piece %d has no place in the VSS split", index)
+	}
+	if len(value) != vssValueSize || new(big.Int).SetBytes(value).Cmp(vssCurve.Params().N) >= 0 {
+		return fmt.Errorf("piece %d: invalid VSS value", index)
+	}
+
+	// Σ C_j·i^j, by Horner's rule: ((C_{t-1}·i + C_{t-2})·i + …)·i + C_0
+	bx := big.NewInt(int64(index)).FillBytes(make([]byte, vssValueSize))
+	var ex, ey *big.Int
+	for j := len(c) - 1; j >= 0; j-- {
+		cx, cy := elliptic.UnmarshalCompressed(vssCurve, c[j])
+		if cx == nil {
+			return fmt.Errorf("VSS commitment %d isn't a point on P-256", j)
+		}
+		if ex == nil {
+			ex, ey = cx, cy
+			continue
+		}
+		ex, ey = vssCurve.ScalarMult(ex, ey, bx)
+		ex, ey = vssCurve.Add(ex, ey, cx, cy)
+	}
+
+	gx, gy := vssCurve.ScalarBaseMult(value)
+	if gx.Cmp(ex) != 0 || gy.Cmp(ey) != 0 {
+		return fmt.Errorf("piece %d doesn't lie on the polynomial the seal committed to", index)
+	}
+	return nil
+}
+
+// Strings returns the commitments as base64url, for README metadata and
+// project.yml.
+func (c VSSCommitments) Strings() []string {
+	out := make([]string, len(c))
+	for i, p := range c {
+		out[i] = base64.RawURLEncoding.EncodeToString(p)
+	}
+	return out
+}
+
+// ParseVSSCommitments reads commitments written by Strings.
+func ParseVSSCommitments(encoded []string) (VSSCommitments, error) {
+	c := make(VSSCommitments, len(encoded))
+	for i, s := range encoded {
+		p, err := base64.RawURLEncoding.DecodeString(s)
+		if err != nil {
+			return nil, fmt.Errorf("VSS commitment %d: %w", i, err)
+		}
+		if x, _ := elliptic.UnmarshalCompressed(vssCurve, p); x == nil {
+			return nil, fmt.Errorf("VSS commitment %d isn't a point on P-256", i)
+		}
+		c[i] = p
+	}
+	return c, nil
+}
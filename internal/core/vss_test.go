@@ -0,0 +1,75 @@
+package core
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestVSS(t *testing.T) {
+	secret := []byte("the manifest passphrase")
+	data, err := Split(secret, 5, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pieces := make([]*Share, 5)
+	for i := range pieces {
+		pieces[i] = NewShare(2, i+1, 5, 3, "", data[i])
+	}
+
+	commitments, err := SplitVSS(secret, 3, pieces)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(commitments) != 3 {
+		t.Fatalf("got %d commitments, want one per coefficient", len(commitments))
+	}
+	for _, p := range pieces {
+		if err := commitments.Verify(p.Index, p.VSS); err != nil {
+			t.Errorf("piece %d: %v", p.Index, err)
+		}
+	}
+
+	// The value survives the PEM form
+	parsed, err := ParseShare([]byte(pieces[2].Encode()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(parsed.VSS, pieces[2].VSS) {
+		t.Error("VSS value changed through the PEM form")
+	}
+
+	// A value checked at another index, or altered, doesn't verify
+	if err := commitments.Verify(2, pieces[0].VSS); err == nil {
+		t.Error("piece 1's value verified as piece 2's")
+	}
+	tampered := bytes.Clone(pieces[0].VSS)
+	tampered[31] ^= 1
+	if err := commitments.Verify(1, tampered); err == nil || !strings.Contains(err.Error(), "doesn't lie on the polynomial") {
+		t.Errorf("tampered value: got %v", err)
+	}
+
+	// Commitments survive their string form
+	reparsed, err := ParseVSSCommitments(commitments.Strings())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := reparsed.Verify(4, pieces[3].VSS); err != nil {
+		t.Errorf("after reparsing: %v", err)
+	}
+	if _, err := ParseVSSCommitments([]string{"AAAA"}); err == nil {
+		t.Error("parsed a commitment that isn't a point")
+	}
+
+	// A piece added later gets a value on the same polynomial
+	added := NewShare(2, 6, 6, 3, "", nil)
+	if err := ExtendVSS(pieces[1:4], 3, added); err != nil {
+		t.Fatal(err)
+	}
+	if err := commitments.Verify(6, added.VSS); err != nil {
+		t.Errorf("added piece: %v", err)
+	}
+	if err := ExtendVSS(pieces[:2], 3, NewShare(2, 7, 7, 3, "", nil)); err == nil {
+		t.Error("extended with fewer pieces than the threshold")
+	}
+}
@@ -158,6 +158,21 @@ func (s *Share) WordsForLang(lang Lang) ([]string, error) {
 	if s.Index < 0 {
 		return nil, fmt.Errorf("share index must be non-negative (got %d)", s.Index)
 	}
+	if s.Groups > 0 {
+		return nil, errGroupedShare
+	}
+	if s.Locked() {
+		return nil, errLockedShare
+	}
+	if s.Secret {
+		return nil, errSecretShare
+	}
+	if l := getEFFList(lang); l != nil {
+		if len(s.Data) != effShareBytes {
+			return nil, fmt.Errorf("the %s list holds %d-byte shares (got %d bytes)", lang, effShareBytes, len(s.Data))
+		}
+		return l.encode(s.Index, s.Data), nil
+	}
 	wl := GetWordList(lang)
 	if wl == nil {
 		wl = GetWordList(LangEN)
@@ -179,14 +194,25 @@ func DecodeShareWords(words []string) (data []byte, index int, err error) {
 }
 
 // DecodeShareWordsAuto decodes 25 BIP39 words with auto-detected language.
+// Words can be on more than one list (English and French share about 100),
+// so each list holding most of them is tried, best match first, and the
+// 25th word's checksum decides. Words from an EFF list are told apart by
+// their count.
 // Returns the decoded data, share index, detected language, and any error.
 func DecodeShareWordsAuto(words []string) (data []byte, index int, lang Lang, err error) {
+	if l := effListForCount(len(words)); l != nil {
+		data, index, err := l.decode(words)
+		if err != nil {
+			return nil, 0, "", err
+		}
+		return data, index, l.ID, nil
+	}
 	if len(words) != 25 {
 		return nil, 0, "", fmt.Errorf("expected 25 words, got %d", len(words))
 	}
 
-	lang = DetectWordListLang(words)
-	if lang == "" {
+	candidates := wordListCandidates(words)
+	if len(candidates) == 0 {
 		// Try to give a helpful suggestion from any language
 		for _, w := range words {
 			if suggestion := SuggestWordAllLangs(w); suggestion != "" {
@@ -196,20 +222,46 @@ func DecodeShareWordsAuto(words []string) (data []byte, index int, lang Lang, er
 		return nil, 0, "", fmt.Errorf("could not identify word list language")
 	}
 
+	var firstErr error
+	for _, lang := range candidates {
+		data, index, err := DecodeShareWordsLang(words, lang)
+		if err == nil {
+			return data, index, lang, nil
+		}
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+	return nil, 0, "", firstErr
+}
+
+// DecodeShareWordsLang decodes 25 BIP39 words from a known word list, such
+// as the one a share's metadata records.
+func DecodeShareWordsLang(words []string, lang Lang) (data []byte, index int, err error) {
+	if l := getEFFList(lang); l != nil {
+		return l.decode(words)
+	}
+	if len(words) != 25 {
+		return nil, 0, fmt.Errorf("expected 25 words, got %d", len(words))
+	}
+	if GetWordList(lang) == nil {
+		return nil, 0, fmt.Errorf("no %q word list (available: %s)", lang, WordListLangs())
+	}
+
 	// Look up the 25th word
 	lastIdx, ok := LookupWord(lang, words[len(words)-1])
 	if !ok {
 		suggestion := SuggestWordLang(words[len(words)-1], lang)
 		if suggestion != "" {
-			return nil, 0, "", fmt.Errorf("word %d %q not recognized — did you mean %q?", len(words), words[len(words)-1], suggestion)
+			return nil, 0, fmt.Errorf("word %d %q not recognized — did you mean %q?", len(words), words[len(words)-1], suggestion)
 		}
-		return nil, 0, "", fmt.Errorf("word %d %q not recognized", len(words), words[len(words)-1])
+		return nil, 0, fmt.Errorf("word %d %q not recognized", len(words), words[len(words)-1])
 	}
 
 	// Decode the data words (all but the last)
 	data, err = DecodeWordsLang(words[:len(words)-1], lang)
 	if err != nil {
-		return nil, 0, "", err
+		return nil, 0, err
 	}
 
 	// Unpack index and checksum from the 25th word
@@ -218,10 +270,10 @@ func DecodeShareWordsAuto(words []string) (data []byte, index int, lang Lang, er
 	// Verify checksum against the decoded data
 	actualCheck := word25Checksum(data)
 	if actualCheck != expectedCheck {
-		return nil, 0, "", fmt.Errorf("word checksum failed — check word order and spelling")
+		return nil, 0, fmt.Errorf("word checksum failed — check word order and spelling")
 	}
 
-	return data, index, lang, nil
+	return data, index, nil
 }
 
 // SuggestWord finds the closest BIP39 English word by Levenshtein distance (max 2).
@@ -232,8 +284,12 @@ func SuggestWord(input string) string {
 
 // SuggestWordLang finds the closest word in a specific language's list (max distance 2).
 func SuggestWordLang(input string, lang Lang) string {
-	wl := GetWordList(lang)
-	if wl == nil {
+	var words []string
+	if wl := GetWordList(lang); wl != nil {
+		words = wl.Words[:]
+	} else if l := getEFFList(lang); l != nil {
+		words = l.Words
+	} else {
 		return ""
 	}
 	normalized := NormalizeWord(input)
@@ -244,7 +300,7 @@ func SuggestWordLang(input string, lang Lang) string {
 	bestWord := ""
 	bestDist := 3 // only suggest if distance <= 2
 
-	for _, w := range wl.Words {
+	for _, w := range words {
 		d := levenshtein(normalized, NormalizeWord(w))
 		if d < bestDist {
 			bestDist = d
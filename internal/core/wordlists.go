@@ -5,6 +5,7 @@ import (
 	"embed"
 	"encoding/hex"
 	"fmt"
+	"slices"
 	"strings"
 	"sync"
 	"unicode"
@@ -26,11 +27,57 @@ const (
 	LangSL    Lang = "sl"
 	LangPT    Lang = "pt"
 	LangZH_TW Lang = "zh-TW"
+	LangZH_CN Lang = "zh-CN"
+	LangJA    Lang = "ja"
 )
 
 // AllLangs returns all supported word list languages.
 func AllLangs() []Lang {
-	return []Lang{LangEN, LangES, LangFR, LangDE, LangSL, LangPT, LangZH_TW}
+	return []Lang{LangEN, LangES, LangFR, LangDE, LangSL, LangPT, LangZH_TW, LangZH_CN, LangJA}
+}
+
+// WordListLangs lists the supported word lists, for messages.
+func WordListLangs() string {
+	names := make([]string, 0, len(AllWordLists()))
+	for _, lang := range AllWordLists() {
+		names = append(names, string(lang))
+	}
+	return strings.Join(names, ", ")
+}
+
+// AllWordLists returns every list recovery words can be printed from: the
+// BIP39 languages, then the EFF lists.
+func AllWordLists() []Lang {
+	return append(AllLangs(), EFFLists()...)
+}
+
+// IsWordList reports whether recovery words can be printed from list.
+func IsWordList(list Lang) bool {
+	return GetWordList(list) != nil || getEFFList(list) != nil
+}
+
+// ShareWordCount returns how many recovery words a share takes on list: 25
+// on the BIP39 lists.
+func ShareWordCount(list Lang) int {
+	if l := getEFFList(list); l != nil {
+		return l.Count
+	}
+	return 25
+}
+
+// MaxShareWords returns the most recovery words a share takes on any list.
+func MaxShareWords() int {
+	most := 25
+	for _, list := range EFFLists() {
+		most = max(most, ShareWordCount(list))
+	}
+	return most
+}
+
+// IsShareWordCount reports whether count words could be a share's recovery
+// words, from any list.
+func IsShareWordCount(count int) bool {
+	return count == 25 || effListForCount(count) != nil
 }
 
 // WordListInfo describes a BIP39 word list: its source, expected hash, and words.
@@ -62,6 +109,8 @@ var wordListSpecs = []wordListSpec{
 	{LangSL, "wordlists/slovenian.txt", "https://github.com/StellarStoic/BIP39_Exotica/8a5c0d93be825fab837dd293c94c635d6a39aa70/main/WRDL/nonStandard/slovenian.txt", "bdc73f14501843be9ae38fea61d6070298df4a83c67a8710e9755c557880467a"},
 	{LangPT, "wordlists/portuguese.txt", "https://github.com/bitcoin/bips/blob/ed7af6ae7e80c90bcfc69b3936073505e2fc2503/bip-0039/portuguese.txt", "2685e9c194c82ae67e10ba59d9ea5345a23dc093e92276fc5361f6667d79cd3f"},
 	{LangZH_TW, "wordlists/chinese_traditional.txt", "https://github.com/bitcoin/bips/blob/7d77befd2b14359b9386fc1f9fb15f82d418fb34/bip-0039/chinese_traditional.txt", "417b26b3d8500a4ae3d59717d7011952db6fc2fb84b807f3f94ac734e89c1b5f"},
+	{LangZH_CN, "wordlists/chinese_simplified.txt", "https://github.com/bitcoin/bips/blob/7d77befd2b14359b9386fc1f9fb15f82d418fb34/bip-0039/chinese_simplified.txt", "5c5942792bd8340cb8b27cd592f1015edf56a8c5b26276ee18a482428e7c5726"},
+	{LangJA, "wordlists/japanese.txt", "https://github.com/bitcoin/bips/blob/7d77befd2b14359b9386fc1f9fb15f82d418fb34/bip-0039/japanese.txt", "2eed0aef492291e061633d7ad8117f1a2b03eb80a29d0e4e3117ac2528d05ffd"},
 }
 
 var (
@@ -158,7 +207,9 @@ func initLangIndices() {
 				idx.digraph = make(map[string]int, 2048)
 			}
 			for i, w := range info.Words {
-				lower := strings.ToLower(w)
+				// The French, Spanish, and Japanese files are decomposed;
+				// keyboards type composed letters and kana
+				lower := norm.NFC.String(strings.ToLower(w))
 				idx.exact[lower] = i
 
 				normalized := NormalizeWord(w)
@@ -190,7 +241,7 @@ func LookupWord(lang Lang, word string) (int, bool) {
 		return 0, false
 	}
 
-	lower := strings.ToLower(strings.TrimSpace(word))
+	lower := norm.NFC.String(strings.ToLower(strings.TrimSpace(word)))
 
 	// 1. Exact lowercase match
 	if i, ok := idx.exact[lower]; ok {
@@ -220,25 +271,30 @@ func LookupWord(lang Lang, word string) (int, bool) {
 // Returns the language where the most words match. Requires >50% match.
 // Returns empty string if no language matches.
 func DetectWordListLang(words []string) Lang {
+	if candidates := wordListCandidates(words); len(candidates) > 0 {
+		return candidates[0]
+	}
+	return ""
+}
+
+// wordListCandidates returns the languages holding more than half of words,
+// most matches first, in AllLangs order on ties.
+func wordListCandidates(words []string) []Lang {
 	initLangIndices()
-	bestLang := Lang("")
-	bestCount := 0
+	var candidates []Lang
+	counts := make(map[Lang]int)
 	for _, lang := range AllLangs() {
-		count := 0
 		for _, w := range words {
 			if _, ok := LookupWord(lang, w); ok {
-				count++
+				counts[lang]++
 			}
 		}
-		if count > bestCount {
-			bestCount = count
-			bestLang = lang
+		if counts[lang] > len(words)/2 {
+			candidates = append(candidates, lang)
 		}
 	}
-	if bestCount <= len(words)/2 {
-		return ""
-	}
-	return bestLang
+	slices.SortStableFunc(candidates, func(a, b Lang) int { return counts[b] - counts[a] })
+	return candidates
 }
 
 // --- Hash verification (used by tests) ---
@@ -4,6 +4,8 @@ import (
 	"bytes"
 	"strings"
 	"testing"
+
+	"golang.org/x/text/unicode/norm"
 )
 
 func TestAllWordListIntegrity(t *testing.T) {
@@ -74,6 +76,8 @@ func TestLookupWordExact(t *testing.T) {
 		{LangFR, "abaisser"},
 		{LangDE, "abbau"},
 		{LangSL, "abeceda"},
+		{LangZH_CN, "的"},
+		{LangJA, "あいこくしん"},
 	}
 
 	for _, tt := range tests {
@@ -141,6 +145,48 @@ func TestLookupWordGermanDigraph(t *testing.T) {
 	}
 }
 
+func TestLookupWordJapaneseForms(t *testing.T) {
+	// japanese.txt is decomposed (か + ゙); keyboards type composed kana.
+	// Both forms, and kana with the marks left off, find the word.
+	wl := GetWordList(LangJA)
+	for i, w := range wl.Words {
+		for _, form := range []string{w, norm.NFC.String(w), NormalizeWord(w)} {
+			if idx, ok := LookupWord(LangJA, form); !ok || idx != i {
+				t.Fatalf("LookupWord(ja, %q) = %d, %v; want %d", form, idx, ok, i)
+			}
+		}
+	}
+}
+
+func TestDetectWordListLangCJK(t *testing.T) {
+	data := make([]byte, 33)
+	for i := range data {
+		data[i] = byte(i*37 + 5)
+	}
+	for _, lang := range []Lang{LangZH_CN, LangJA} {
+		share := NewShare(2, 1, 3, 2, "Test", data)
+		words, err := share.WordsForLang(lang)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i, w := range words {
+			words[i] = norm.NFC.String(w)
+		}
+		decoded, index, detected, err := DecodeShareWordsAuto(words)
+		if err != nil {
+			t.Fatalf("%s: %v", lang, err)
+		}
+		if !bytes.Equal(decoded, data) || index != 1 {
+			t.Errorf("%s: decoded share %d doesn't match", lang, index)
+		}
+		// Most simplified characters are also traditional ones, at the
+		// same place in both lists, so either is a right answer for them
+		if lang == LangJA && detected != LangJA {
+			t.Errorf("detected %q, want ja", detected)
+		}
+	}
+}
+
 func TestLookupWordCaseInsensitive(t *testing.T) {
 	tests := []struct {
 		lang Lang
@@ -246,6 +292,49 @@ func TestDetectWordListLang(t *testing.T) {
 	}
 }
 
+// Words on both the English and French lists count the same for both, so
+// English is tried first; the checksum has to pick French.
+func TestDecodeShareWordsOnTwoLists(t *testing.T) {
+	fr := GetWordList(LangFR)
+	var shared []int // French indices of words also on the English list
+	for i, w := range fr.Words {
+		if _, ok := LookupWord(LangEN, w); ok {
+			shared = append(shared, i)
+		}
+	}
+	if len(shared) < 24 {
+		t.Fatalf("only %d words on both lists", len(shared))
+	}
+
+	for seed := 0; seed < 10000; seed++ {
+		data := make([]byte, 33)
+		for i := 0; i < 24; i++ {
+			set11Bits(data, i*11, shared[(seed*7+i*31)%len(shared)])
+		}
+		share := NewShare(2, 3, 5, 3, "Test", data)
+		words, err := share.WordsForLang(LangFR)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := LookupWord(LangEN, words[24]); !ok {
+			continue
+		}
+		if _, _, err := DecodeShareWordsLang(words, LangEN); err == nil {
+			continue // The English reading happens to pass its checksum too
+		}
+
+		got, index, lang, err := DecodeShareWordsAuto(words)
+		if err != nil {
+			t.Fatalf("%v (%s)", err, strings.Join(words, " "))
+		}
+		if lang != LangFR || index != 3 || !bytes.Equal(got, data) {
+			t.Errorf("got lang %s, index %d", lang, index)
+		}
+		return
+	}
+	t.Skip("no share found with all its words on both lists")
+}
+
 func TestAutoDetectWithNormalization(t *testing.T) {
 	data := make([]byte, 33)
 	for i := range data {
@@ -359,3 +448,99 @@ func TestWordListsHaveUniqueWords(t *testing.T) {
 		})
 	}
 }
+
+func TestEFFListIntegrity(t *testing.T) {
+	sizes := map[Lang]int{WordListEFFLong: 7776, WordListEFFShort: 1296}
+	counts := map[Lang]int{WordListEFFLong: 22, WordListEFFShort: 27}
+	for _, spec := range GetEFFListSpecs() {
+		t.Run(string(spec.Lang), func(t *testing.T) {
+			if hash := EFFListHash(spec.Lang); hash != spec.ExpectedHash {
+				t.Errorf("hash mismatch:\n  got:  %s\n  want: %s\n  source: %s", hash, spec.ExpectedHash, spec.SourceURL)
+			}
+			l := getEFFList(spec.Lang)
+			if len(l.Words) != sizes[spec.Lang] {
+				t.Errorf("%d words, want %d", len(l.Words), sizes[spec.Lang])
+			}
+			if l.Count != counts[spec.Lang] || ShareWordCount(spec.Lang) != l.Count {
+				t.Errorf("a share takes %d words, want %d", l.Count, counts[spec.Lang])
+			}
+			seen := make(map[string]bool, len(l.Words))
+			for i, w := range l.Words {
+				if w == "" || seen[w] {
+					t.Errorf("empty or duplicate word %q at index %d", w, i)
+				}
+				seen[w] = true
+			}
+		})
+	}
+	if getEFFList(WordListEFFShort).Prefix != 3 {
+		t.Error("the short list's words should be told apart by three letters")
+	}
+}
+
+func TestEFFShareWords(t *testing.T) {
+	data := make([]byte, 33)
+	for i := range data {
+		data[i] = byte(255 - i*5)
+	}
+	for _, list := range EFFLists() {
+		for _, index := range []int{1, 15, 16} {
+			share := NewShare(2, index, 20, 3, "Test", data)
+			words, err := share.WordsForLang(list)
+			if err != nil {
+				t.Fatalf("%s: %v", list, err)
+			}
+			wantIndex := index
+			if index > 15 {
+				wantIndex = 0
+			}
+			got, gotIndex, detected, err := DecodeShareWordsAuto(words)
+			if err != nil {
+				t.Fatalf("%s, piece %d: %v", list, index, err)
+			}
+			if !bytes.Equal(got, data) || gotIndex != wantIndex || detected != list {
+				t.Errorf("%s, piece %d: decoded piece %d from %q", list, index, gotIndex, detected)
+			}
+			if _, _, err := DecodeShareWordsLang(words, list); err != nil {
+				t.Errorf("%s: DecodeShareWordsLang: %v", list, err)
+			}
+
+			swapped := append([]string(nil), words...)
+			swapped[3], swapped[4] = swapped[4], swapped[3]
+			if swapped[3] != swapped[4] {
+				if _, _, _, err := DecodeShareWordsAuto(swapped); err == nil {
+					t.Errorf("%s: swapped words should fail the checksum", list)
+				}
+			}
+		}
+	}
+}
+
+func TestEFFLookupForms(t *testing.T) {
+	long := getEFFList(WordListEFFLong)
+	for word, want := range map[string]string{
+		"t-shirt": "t-shirt",
+		"TShirt":  "t-shirt",
+		"yo-yo":   "yo-yo",
+		"yoyo":    "yoyo", // Both are on the list
+	} {
+		i, ok := long.lookup(word)
+		if !ok || long.Words[i] != want {
+			t.Errorf("lookup(%q) = %v, want %q", word, ok, want)
+		}
+	}
+	if _, ok := long.lookup("aba"); ok {
+		t.Error("the long list shouldn't match a word by its first letters")
+	}
+
+	short := getEFFList(WordListEFFShort)
+	if i, ok := short.lookup("aar"); !ok || short.Words[i] != "aardvark" {
+		t.Errorf("lookup(%q) should find aardvark", "aar")
+	}
+	if _, ok := short.lookup("aa"); ok {
+		t.Error("two letters shouldn't be enough")
+	}
+	if s := SuggestWordLang("aardvrak", WordListEFFShort); s != "aardvark" {
+		t.Errorf("SuggestWordLang = %q, want aardvark", s)
+	}
+}
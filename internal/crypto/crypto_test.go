@@ -1,10 +1,14 @@
 package crypto
 
 import (
+	"encoding/base64"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
+	"filippo.io/age"
+	"filippo.io/age/plugin"
 	"github.com/eljojo/rememory/internal/core"
 )
 
@@ -50,6 +54,20 @@ func TestGeneratePassphrase(t *testing.T) {
 			t.Error("passphrases should be unique")
 		}
 	})
+
+	t.Run("bundle", func(t *testing.T) {
+		id, _ := core.NewBundleID()
+		raw, pass, err := GenerateBundlePassphrase(DefaultPassphraseBytes, id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := core.DataBundle(raw); got != core.BundleFingerprint(id) {
+			t.Errorf("raw bytes name bundle %q, want %q", got, core.BundleFingerprint(id))
+		}
+		if string(pass) != base64.RawURLEncoding.EncodeToString(raw) {
+			t.Error("passphrase isn't the raw bytes encoded")
+		}
+	})
 }
 
 func TestHashFile(t *testing.T) {
@@ -83,3 +101,112 @@ func TestHashFileNotFound(t *testing.T) {
 		t.Error("expected error for nonexistent file")
 	}
 }
+
+func TestParseRecipients(t *testing.T) {
+	id, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatal(err)
+	}
+	hybrid, err := age.GenerateHybridIdentity()
+	if err != nil {
+		t.Fatal(err)
+	}
+	pluginKey := plugin.EncodeRecipient("rememorytest", []byte("key"))
+
+	recipients, err := ParseRecipients([]string{id.Recipient().String(), pluginKey, hybrid.Recipient().String()})
+	if err != nil {
+		t.Fatalf("ParseRecipients: %v", err)
+	}
+	if _, ok := recipients[0].(*age.X25519Recipient); !ok {
+		t.Errorf("first recipient is %T, want *age.X25519Recipient", recipients[0])
+	}
+	if r, ok := recipients[1].(*plugin.Recipient); !ok || r.Name() != "rememorytest" {
+		t.Errorf("second recipient is %T, want the rememorytest plugin", recipients[1])
+	}
+	if _, ok := recipients[2].(*age.HybridRecipient); !ok {
+		t.Errorf("third recipient is %T, want *age.HybridRecipient", recipients[2])
+	}
+
+	if _, err := ParseRecipients([]string{"age1notakey"}); err == nil {
+		t.Error("expected error for a malformed recipient")
+	}
+
+	// Without the plugin installed, encrypting says what to install
+	var buf strings.Builder
+	err = core.EncryptToRecipients(&buf, strings.NewReader("passphrase"), recipients[1:])
+	if err == nil {
+		t.Fatal("expected error without age-plugin-rememorytest")
+	}
+	if hint := PluginHint(err).Error(); !strings.Contains(hint, "install age-plugin-rememorytest") {
+		t.Errorf("PluginHint = %q, want it to name the plugin", hint)
+	}
+}
+
+func TestReadIdentities(t *testing.T) {
+	id, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatal(err)
+	}
+	hybrid, err := age.GenerateHybridIdentity()
+	if err != nil {
+		t.Fatal(err)
+	}
+	pluginID := plugin.EncodeIdentity("rememorytest", []byte("key"))
+
+	path := filepath.Join(t.TempDir(), "key.txt")
+	content := "# created: today\n" + id.String() + "\n\n" + pluginID + "\n" + hybrid.String() + "\n"
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	identities, err := ReadIdentities([]string{path})
+	if err != nil {
+		t.Fatalf("ReadIdentities: %v", err)
+	}
+	if len(identities) != 3 {
+		t.Fatalf("got %d identities, want 3", len(identities))
+	}
+	if _, ok := identities[1].(*plugin.Identity); !ok {
+		t.Errorf("second identity is %T, want a plugin identity", identities[1])
+	}
+	if _, ok := identities[2].(*age.HybridIdentity); !ok {
+		t.Errorf("third identity is %T, want *age.HybridIdentity", identities[2])
+	}
+
+	empty := filepath.Join(t.TempDir(), "empty.txt")
+	if err := os.WriteFile(empty, []byte("# nothing here\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ReadIdentities([]string{empty}); err == nil {
+		t.Error("expected error for a file without identities")
+	}
+}
+
+func TestReadIdentitiesMasterCode(t *testing.T) {
+	code, err := core.GenerateMasterCode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	words := core.MasterCodeWords(code)
+	dir := t.TempDir()
+
+	path := filepath.Join(dir, "code.txt")
+	if err := os.WriteFile(path, []byte(strings.Join(words, " ")+"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	identities, err := ReadIdentities([]string{path})
+	if err != nil {
+		t.Fatalf("ReadIdentities: %v", err)
+	}
+	if len(identities) != 2 {
+		t.Errorf("got %d identities, want the X25519 and the post-quantum one", len(identities))
+	}
+
+	words[3] = "zzzz"
+	if err := os.WriteFile(path, []byte(strings.Join(words, " ")), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ReadIdentities([]string{path}); err == nil || !strings.Contains(err.Error(), "word 4") {
+		t.Errorf("got %v, want the mistyped word named", err)
+	}
+}
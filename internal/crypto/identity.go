@@ -0,0 +1,122 @@
+package crypto
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"filippo.io/age"
+	"filippo.io/age/plugin"
+	"github.com/eljojo/rememory/internal/core"
+)
+
+// pluginUI shows an age plugin's messages, such as asking to touch a
+// YubiKey, on stderr, and asks for PINs on the terminal.
+var pluginUI = plugin.NewTerminalUI(
+	func(format string, v ...any) { fmt.Fprintf(os.Stderr, format+"\n", v...) },
+	func(format string, v ...any) { fmt.Fprintf(os.Stderr, "Warning: "+format+"\n", v...) },
+)
+
+// ParseRecipients parses age public keys: X25519 keys ("age1..."), hybrid
+// post-quantum keys ("age1pq1..."), and plugin recipients
+// ("age1yubikey1...", "age1tpm1..."), which are handled by an
+// age-plugin-NAME program on the PATH when encrypting, as the age command
+// does. This function is not available in WASM.
+func ParseRecipients(keys []string) ([]age.Recipient, error) {
+	recipients := make([]age.Recipient, len(keys))
+	for i, key := range keys {
+		key = strings.TrimSpace(key)
+		// Plugin recipients have a second "1": age1<plugin>1<data>. Bech32
+		// data never holds a "1", so an X25519 key has only the one.
+		if strings.Count(key, "1") < 2 || !strings.HasPrefix(key, "age1") || core.IsHybridRecipient(key) {
+			native, err := core.ParseRecipients([]string{key})
+			if err != nil {
+				return nil, err
+			}
+			recipients[i] = native[0]
+			continue
+		}
+		if strings.HasPrefix(key, "age1tag") {
+			return nil, fmt.Errorf("recipient %q: tag recipients aren't supported", key)
+		}
+		r, err := plugin.NewRecipient(key, pluginUI)
+		if err != nil {
+			return nil, fmt.Errorf("recipient %q: %w", key, err)
+		}
+		recipients[i] = r
+	}
+	return recipients, nil
+}
+
+// ReadIdentities reads age identity files, such as one made by age-keygen
+// or age-plugin-yubikey, keeping their identities in the order given.
+// Plugin identities ("AGE-PLUGIN-...") run age-plugin-NAME when used. This
+// function requires file system access and is not available in WASM.
+func ReadIdentities(paths []string) ([]age.Identity, error) {
+	var identities []age.Identity
+	for _, path := range paths {
+		ids, err := readIdentityFile(path)
+		if err != nil {
+			return nil, err
+		}
+		identities = append(identities, ids...)
+	}
+	return identities, nil
+}
+
+// readIdentityFile is like age.ParseIdentities, but also takes plugin
+// identities, the way the age command reads them, and a master code's words
+// or its key in groups, as they're copied from its sheet.
+func readIdentityFile(path string) ([]age.Identity, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("opening identity file: %w", err)
+	}
+	if ids, err := core.ParseMasterCode(string(data)); err == nil {
+		return ids, nil
+	} else if len(bytes.Fields(data)) > 1 && !bytes.Contains(data, []byte("AGE-")) && !bytes.Contains(data, []byte("#")) {
+		// Words, but not a master code's
+		return nil, fmt.Errorf("reading identity file %s: %w", path, err)
+	}
+
+	var ids []age.Identity
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		var id age.Identity
+		switch {
+		case strings.HasPrefix(line, "AGE-PLUGIN-"):
+			id, err = plugin.NewIdentity(line, pluginUI)
+		case strings.HasPrefix(line, "AGE-SECRET-KEY-PQ-"):
+			id, err = age.ParseHybridIdentity(line)
+		default:
+			id, err = age.ParseX25519Identity(line)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading identity file %s: line %d: %w", path, n, err)
+		}
+		ids = append(ids, id)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading identity file %s: %w", path, err)
+	}
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("reading identity file %s: no identities found", path)
+	}
+	return ids, nil
+}
+
+// PluginHint adds to err how to install the age plugin it couldn't find,
+// if that is what went wrong.
+func PluginHint(err error) error {
+	if e := new(plugin.NotFoundError); errors.As(err, &e) {
+		return fmt.Errorf("%w (install age-plugin-%s; see https://age-encryption.org/awesome#plugins)", err, e.Name)
+	}
+	return err
+}
@@ -4,6 +4,8 @@ import (
 	"crypto/rand"
 	"encoding/base64"
 	"fmt"
+
+	"github.com/eljojo/rememory/internal/core"
 )
 
 const (
@@ -15,25 +17,48 @@ const (
 // GeneratePassphrase creates a cryptographically secure passphrase.
 // The passphrase is URL-safe base64 encoded (no padding) for easy handling.
 func GeneratePassphrase(numBytes int) (string, error) {
-	_, passphrase, err := GenerateRawPassphrase(numBytes)
-	return passphrase, err
+	raw, passphrase, err := GenerateRawPassphrase(numBytes)
+	core.Wipe(raw)
+	return string(passphrase), err
 }
 
 // GenerateRawPassphrase creates random bytes and returns both the raw bytes
-// and the base64url-encoded passphrase string. Protocol v2 splits the raw bytes
+// and the base64url-encoded passphrase. Protocol v2 splits the raw bytes
 // via Shamir (instead of the encoded string), then base64url-encodes after
-// recombining. The passphrase string is used for age encryption.
-func GenerateRawPassphrase(numBytes int) (raw []byte, passphrase string, err error) {
+// recombining. The passphrase is used for age encryption. The caller wipes
+// both once the seal is written.
+func GenerateRawPassphrase(numBytes int) (raw []byte, passphrase core.Secret, err error) {
 	if numBytes < 16 {
-		return nil, "", fmt.Errorf("passphrase must be at least 16 bytes, got %d", numBytes)
+		return nil, nil, fmt.Errorf("passphrase must be at least 16 bytes, got %d", numBytes)
 	}
 
 	raw = make([]byte, numBytes)
 	if _, err := rand.Read(raw); err != nil {
-		return nil, "", fmt.Errorf("generating random bytes: %w", err)
+		return nil, nil, fmt.Errorf("generating random bytes: %w", err)
+	}
+	return raw, encodePassphrase(raw), nil
+}
+
+// GenerateBundlePassphrase is GenerateRawPassphrase for a seal: the raw
+// bytes start with the header naming bundleID (see core.BindSecret), which
+// every piece split from them carries too.
+func GenerateBundlePassphrase(numBytes int, bundleID string) (raw []byte, passphrase core.Secret, err error) {
+	raw, passphrase, err = GenerateRawPassphrase(numBytes)
+	if err != nil {
+		return nil, nil, err
+	}
+	passphrase.Wipe()
+	if err := core.BindSecret(raw, bundleID); err != nil {
+		core.Wipe(raw)
+		return nil, nil, err
 	}
+	return raw, encodePassphrase(raw), nil
+}
 
-	// URL-safe base64 without padding for easy copy-paste
-	passphrase = base64.RawURLEncoding.EncodeToString(raw)
-	return raw, passphrase, nil
+// encodePassphrase encodes raw bytes as URL-safe base64 without padding,
+// for easy copy-paste.
+func encodePassphrase(raw []byte) core.Secret {
+	passphrase := make(core.Secret, base64.RawURLEncoding.EncodedLen(len(raw)))
+	base64.RawURLEncoding.Encode(passphrase, raw)
+	return passphrase
 }
@@ -0,0 +1,212 @@
+// Package deposit writes the cover sheet for a bundle kept in a bank's
+// safe-deposit box: the box's details, what the box holds and why, what
+// whoever opens it should do, and an inventory of its contents with their
+// checksums, to tick off at each visit. Bank staff, an executor, or the
+// holder can read it without knowing what ReMemory is.
+//
+// Like the emergency kit, the sheet holds no piece: it goes in the box on
+// top of the bundle, not in place of it.
+package deposit
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/eljojo/rememory/internal/core"
+	"github.com/eljojo/rememory/internal/project"
+	"github.com/eljojo/rememory/internal/rotation"
+)
+
+// FileName returns the sheet's name in the output directory for the
+// bundle of friend.
+func FileName(friend project.Friend) string {
+	return fmt.Sprintf("SAFE-DEPOSIT-%s.txt", core.SanitizeFilename(friend.Name))
+}
+
+// Item is one file in the box, with its size and checksum.
+type Item struct {
+	Name     string
+	Size     int64
+	Checksum string
+}
+
+// Sheet is everything the cover sheet records. Bank, Branch, and Box are
+// left as lines to fill in by hand when empty, and so is Access.
+type Sheet struct {
+	Project          string
+	Holder           string // The friend whose bundle goes in the box, as the privacy setting names them
+	Pieces           []int  // The holder's piece numbers
+	Threshold        int
+	Total            int
+	Groups           *project.GroupPolicy // Nil unless the project has groups; Threshold then counts groups
+	Sealed           time.Time
+	ManifestChecksum string
+	RecoveryURL      string
+	ReviewBy         time.Time
+	Expires          time.Time
+	Contact          string           // How to reach the owner
+	Others           []project.Friend // The other holders, as the privacy setting shows them; nil when bundles don't list them
+
+	Bank   string
+	Branch string
+	Box    string
+	Access []string // Who may open the box
+
+	Bundle    string // Bundle file name
+	BundleSum string // Checksum of the bundle file
+	Contents  []Item // The files inside the bundle
+
+	Made    time.Time
+	Version string // rememory version that made it
+}
+
+// blank is a line to fill in by hand.
+const blank = "________________________________"
+
+// Text returns the sheet as a page to print. Like the emergency kit, it is
+// in English only: banks and executors are the ones who read it, and the
+// bundle under it is in the holder's language.
+func (s *Sheet) Text() string {
+	var sb strings.Builder
+	rule := strings.Repeat("=", 80) + "\n"
+	section := func(title string) {
+		sb.WriteString("\n" + strings.Repeat("-", 80) + "\n")
+		sb.WriteString(title + "\n")
+		sb.WriteString(strings.Repeat("-", 80) + "\n")
+	}
+	field := func(name, value string) {
+		if value == "" {
+			value = blank
+		}
+		if name != "" {
+			name += ":"
+		}
+		sb.WriteString(fmt.Sprintf("  %-18s %s\n", name, value))
+	}
+
+	sb.WriteString(rule)
+	sb.WriteString("SAFE-DEPOSIT BOX: REMEMORY RECOVERY BUNDLE\n")
+	sb.WriteString(fmt.Sprintf("For: %s\n", s.Holder))
+	sb.WriteString(rule)
+	sb.WriteString("Keep this sheet on top of the box's contents. It holds no secret and can't\n")
+	sb.WriteString("open anything on its own; it says what the box holds, who may open it, and\n")
+	sb.WriteString("what to do with what's inside.\n")
+
+	section("THE BOX")
+	field("Bank", s.Bank)
+	field("Branch", s.Branch)
+	field("Box number", s.Box)
+	field("Keys held by", "")
+	if len(s.Access) == 0 {
+		field("May open the box", "")
+		field("", "")
+	} else {
+		for i, name := range s.Access {
+			if i == 0 {
+				field("May open the box", name)
+			} else {
+				field("", name)
+			}
+		}
+	}
+	field("Deposited on", "")
+	field("Deposited by", "")
+
+	section("WHAT THIS BOX HOLDS")
+	field("Project", s.Project)
+	field("Bundle for", s.Holder)
+	field("Pieces", pieceList(s.Pieces, s.Total))
+	if s.Groups != nil {
+		field("Groups needed", fmt.Sprintf("%d of %d", s.Threshold, len(s.Groups.Groups)))
+	} else {
+		field("Pieces needed", fmt.Sprintf("%d of %d", s.Threshold, s.Total))
+	}
+	field("Sealed", s.Sealed.UTC().Format(core.DateFormat))
+	field("Fingerprint", rotation.Fingerprint(s.ManifestChecksum))
+	if !s.ReviewBy.IsZero() {
+		field("Review by", s.ReviewBy.Format(core.DateFormat))
+	}
+	if !s.Expires.IsZero() {
+		field("Expires", s.Expires.Format(core.DateFormat))
+	}
+	if s.Contact != "" {
+		field("Owner's contact", s.Contact)
+	}
+	sb.WriteString(fmt.Sprintf("\nThis box holds %s's ReMemory recovery bundle: a piece of the key to files\n", s.Holder))
+	sb.WriteString("its owner encrypted, and the tools to open them. The piece alone reveals\n")
+	if s.Groups != nil {
+		sb.WriteString(fmt.Sprintf("nothing. Pieces from %d of the groups of holders, put together, open the\nfiles.\n", s.Threshold))
+	} else {
+		sb.WriteString(fmt.Sprintf("nothing. Any %d of the %d pieces, put together, open the files.\n", s.Threshold, s.Total))
+	}
+
+	section("IF YOU ARE OPENING THIS BOX")
+	steps := []string{
+		"Check the contents against the inventory below, and note anything\n   missing or damaged.",
+		"Keep the bundle private. Don't copy, photograph, or hand it over except\n   to the people recovering the owner's files.",
+	}
+	contact := "Contact the owner"
+	if s.Contact != "" {
+		contact += ", at the contact above"
+	}
+	if s.Others != nil {
+		contact += ".\n   If they can't be reached, contact the other holders listed below, who\n   have the other pieces."
+	} else {
+		contact += ".\n   If they can't be reached, the README in the bundle says how to find the\n   other pieces."
+	}
+	steps = append(steps, contact,
+		fmt.Sprintf("To recover, open recover.html from the bundle in a web browser, or scan\n   the QR code on the printed README with a phone, which opens\n     %s\n   The README has the full instructions.", s.RecoveryURL),
+	)
+	for i, step := range steps {
+		sb.WriteString(fmt.Sprintf("%d. %s\n", i+1, step))
+	}
+	if len(s.Others) > 0 {
+		sb.WriteString("\nThe other holders:\n")
+		for _, f := range s.Others {
+			if f.Contact != "" {
+				sb.WriteString(fmt.Sprintf("  %s - %s\n", f.Name, f.Contact))
+			} else {
+				sb.WriteString(fmt.Sprintf("  %s\n", f.Name))
+			}
+		}
+	}
+
+	section("INVENTORY")
+	sb.WriteString(fmt.Sprintf("[ ] %s on a USB drive or disc\n", s.Bundle))
+	sb.WriteString(fmt.Sprintf("    %s\n", s.BundleSum))
+	sb.WriteString("    holding:\n")
+	for _, item := range s.Contents {
+		sb.WriteString(fmt.Sprintf("[ ]   %-28s %d bytes\n", item.Name, item.Size))
+		sb.WriteString(fmt.Sprintf("      %s\n", item.Checksum))
+	}
+	sb.WriteString("[ ] The README, printed\n")
+	sb.WriteString("[ ] This sheet\n")
+	sb.WriteString(fmt.Sprintf("\nTo check the drive, run 'rememory verify-bundle %s', or compare\n", s.Bundle))
+	sb.WriteString("the SHA-256 of each file with the checksums above.\n")
+
+	section("VISITS")
+	sb.WriteString("  Date          Checked by                      Contents as listed\n")
+	for range 4 {
+		sb.WriteString("  ____-__-__    ______________________________  [ ] yes   [ ] no\n")
+	}
+
+	sb.WriteString("\n" + rule)
+	sb.WriteString(fmt.Sprintf("Made %s with rememory %s. Make a new sheet whenever the bundle in\n", s.Made.UTC().Format(core.DateFormat), s.Version))
+	sb.WriteString("the box is replaced.\n")
+	sb.WriteString(rule)
+	return sb.String()
+}
+
+// pieceList describes a holder's pieces: "3 of 5", or "1 and 4 of 5".
+func pieceList(pieces []int, total int) string {
+	names := make([]string, len(pieces))
+	for i, n := range pieces {
+		names[i] = fmt.Sprintf("%d", n)
+	}
+	list := strings.Join(names, ", ")
+	if len(names) > 1 {
+		list = strings.Join(names[:len(names)-1], ", ") + " and " + names[len(names)-1]
+	}
+	return fmt.Sprintf("%s of %d", list, total)
+}
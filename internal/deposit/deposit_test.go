@@ -0,0 +1,96 @@
+package deposit
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/eljojo/rememory/internal/project"
+)
+
+func testSheet() *Sheet {
+	return &Sheet{
+		Project:          "Family",
+		Holder:           "Alice",
+		Pieces:           []int{1, 4},
+		Threshold:        3,
+		Total:            5,
+		Sealed:           time.Date(2026, 1, 2, 3, 4, 0, 0, time.UTC),
+		ManifestChecksum: "sha256:ab12cd34ef560789bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb",
+		RecoveryURL:      "https://files.example.org/recover.html",
+		Contact:          "owner@example.com",
+		Others:           []project.Friend{{Name: "Bob", Contact: "bob@example.com"}, {Name: "Carol"}},
+		Bank:             "First National",
+		Box:              "1234",
+		Access:           []string{"Maria Lopez", "Executor"},
+		Bundle:           "bundle-alice.zip",
+		BundleSum:        "sha256:bbbb",
+		Contents: []Item{
+			{Name: "README.txt", Size: 6746, Checksum: "sha256:cccc"},
+			{Name: "recover.html", Size: 3163766, Checksum: "sha256:dddd"},
+		},
+		Made:    time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC),
+		Version: "v1.2.3",
+	}
+}
+
+func TestSheetText(t *testing.T) {
+	text := testSheet().Text()
+	for _, want := range []string{
+		"For: Alice",
+		"  Bank:              First National\n  Branch:            ________________________________\n  Box number:        1234",
+		"  May open the box:  Maria Lopez\n                     Executor\n",
+		"  Pieces:            1 and 4 of 5\n  Pieces needed:     3 of 5",
+		"  Fingerprint:       ab12cd34ef560789",
+		"  Owner's contact:   owner@example.com",
+		"Any 3 of the 5 pieces",
+		"     https://files.example.org/recover.html\n",
+		"  Bob - bob@example.com\n  Carol\n",
+		"[ ] bundle-alice.zip on a USB drive or disc\n    sha256:bbbb\n",
+		"[ ]   recover.html                 3163766 bytes\n      sha256:dddd\n",
+		"rememory verify-bundle bundle-alice.zip",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("sheet is missing %q", want)
+		}
+	}
+	for i, line := range strings.Split(text, "\n") {
+		if len([]rune(line)) > 80 {
+			t.Errorf("line %d is %d characters: %q", i+1, len([]rune(line)), line)
+		}
+	}
+}
+
+func TestSheetBlanks(t *testing.T) {
+	s := testSheet()
+	s.Bank, s.Box, s.Access, s.Contact, s.Others = "", "", nil, "", nil
+	text := s.Text()
+	for _, want := range []string{
+		"  Bank:              " + blank,
+		"  Box number:        " + blank,
+		"  May open the box:  " + blank + "\n                     " + blank + "\n",
+		"the README in the bundle says how to find the\n   other pieces",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("sheet is missing %q", want)
+		}
+	}
+	if strings.Contains(text, "Owner's contact") || strings.Contains(text, "The other holders") {
+		t.Errorf("sheet names what it wasn't given:\n%s", text)
+	}
+}
+
+func TestPieceList(t *testing.T) {
+	for _, tc := range []struct {
+		pieces []int
+		want   string
+	}{
+		{[]int{3}, "3 of 5"},
+		{[]int{1, 4}, "1 and 4 of 5"},
+		{[]int{1, 2, 5}, "1, 2 and 5 of 5"},
+	} {
+		if got := pieceList(tc.pieces, 5); got != tc.want {
+			t.Errorf("pieceList(%v) = %q, want %q", tc.pieces, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,160 @@
+// Package estate holds the answers to 'rememory estate': the owner's
+// accounts, subscriptions, devices, and wishes, written as estate.json in
+// manifest/ and sealed with everything else. It's the part of an archive an
+// heir can act on without knowing anything about computers, so
+// recover.html recognizes the file and shows it as a readable document.
+package estate
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/eljojo/rememory/internal/core"
+)
+
+// FileName is the name the answers are kept under, inside manifest/.
+const FileName = "estate.json"
+
+// Format marks the answers, so recover.html can tell them from any other
+// JSON file.
+const Format = "rememory-estate"
+
+// Version is the version of the format this package writes.
+const Version = 1
+
+// Document is the questionnaire's answers.
+type Document struct {
+	Format        string         `json:"format"`
+	Version       int            `json:"version"`
+	Updated       time.Time      `json:"updated"`
+	Accounts      []Account      `json:"accounts,omitempty"`
+	Subscriptions []Subscription `json:"subscriptions,omitempty"`
+	Devices       []Device       `json:"devices,omitempty"`
+	Wishes        []Wish         `json:"wishes,omitempty"`
+}
+
+// Account is an online account someone will need to close, keep, or hand on.
+type Account struct {
+	Service string `json:"service"`
+	Login   string `json:"login,omitempty"` // Username or email; passwords belong in a sealed vault
+	Wish    string `json:"wish,omitempty"`  // What should happen to it
+	Notes   string `json:"notes,omitempty"`
+}
+
+// Subscription is something that keeps billing until someone cancels it.
+type Subscription struct {
+	Service string `json:"service"`
+	Cost    string `json:"cost,omitempty"`   // As the owner wrote it, such as "$12 a month"
+	Cancel  string `json:"cancel,omitempty"` // How to cancel it
+	Notes   string `json:"notes,omitempty"`
+}
+
+// Device is a phone, computer, or anything else that locks.
+type Device struct {
+	Name   string `json:"name"`
+	Unlock string `json:"unlock,omitempty"` // How to get in, or where the code is kept
+	Wish   string `json:"wish,omitempty"`   // What to do with it
+	Notes  string `json:"notes,omitempty"`
+}
+
+// Wish is anything else the owner wants known: a funeral, a pet, a message.
+type Wish struct {
+	Topic string `json:"topic"`
+	Text  string `json:"text"`
+}
+
+// New returns an empty document.
+func New() *Document {
+	return &Document{Format: Format, Version: Version}
+}
+
+// Load reads a document written by Marshal.
+func Load(path string) (*Document, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var d Document
+	if err := json.Unmarshal(data, &d); err != nil {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+	if d.Format != Format {
+		return nil, fmt.Errorf("%s isn't a rememory estate file", path)
+	}
+	if d.Version > Version {
+		return nil, fmt.Errorf("%s was written by a newer version of rememory (format version %d)", path, d.Version)
+	}
+	return &d, nil
+}
+
+// Empty reports whether nothing has been answered.
+func (d *Document) Empty() bool {
+	return len(d.Accounts)+len(d.Subscriptions)+len(d.Devices)+len(d.Wishes) == 0
+}
+
+// Marshal writes d as indented JSON, stamped with the time it was written.
+func (d *Document) Marshal() ([]byte, error) {
+	d.Updated = core.Now().UTC()
+	data, err := json.MarshalIndent(d, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// Text renders d for reading in a terminal.
+func (d *Document) Text() string {
+	var sb strings.Builder
+	if d.Updated.IsZero() {
+		sb.WriteString("Digital estate\n")
+	} else {
+		fmt.Fprintf(&sb, "Digital estate, last updated %s\n", d.Updated.Format(core.DateFormat))
+	}
+
+	section := func(title string, n int) {
+		if n > 0 {
+			fmt.Fprintf(&sb, "\n%s (%d)\n", title, n)
+		}
+	}
+	field := func(label, value string) {
+		if value != "" {
+			fmt.Fprintf(&sb, "    %-11s %s\n", label+":", indent(value))
+		}
+	}
+
+	section("Accounts", len(d.Accounts))
+	for _, a := range d.Accounts {
+		fmt.Fprintf(&sb, "  %s\n", a.Service)
+		field("Login", a.Login)
+		field("Wish", a.Wish)
+		field("Notes", a.Notes)
+	}
+	section("Subscriptions", len(d.Subscriptions))
+	for _, s := range d.Subscriptions {
+		fmt.Fprintf(&sb, "  %s\n", s.Service)
+		field("Cost", s.Cost)
+		field("To cancel", s.Cancel)
+		field("Notes", s.Notes)
+	}
+	section("Devices", len(d.Devices))
+	for _, dev := range d.Devices {
+		fmt.Fprintf(&sb, "  %s\n", dev.Name)
+		field("To unlock", dev.Unlock)
+		field("Wish", dev.Wish)
+		field("Notes", dev.Notes)
+	}
+	section("Wishes", len(d.Wishes))
+	for _, w := range d.Wishes {
+		fmt.Fprintf(&sb, "  %s\n", w.Topic)
+		fmt.Fprintf(&sb, "    %s\n", strings.ReplaceAll(w.Text, "\n", "\n    "))
+	}
+	return sb.String()
+}
+
+// indent lines a multi-line value up under its first line.
+func indent(value string) string {
+	return strings.ReplaceAll(value, "\n", "\n"+strings.Repeat(" ", 16))
+}
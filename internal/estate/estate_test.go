@@ -0,0 +1,54 @@
+package estate
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRoundTrip(t *testing.T) {
+	d := New()
+	if !d.Empty() {
+		t.Fatal("new document isn't empty")
+	}
+	d.Accounts = append(d.Accounts, Account{Service: "Gmail", Login: "ana@example.com", Wish: "Memorialize it"})
+	d.Subscriptions = append(d.Subscriptions, Subscription{Service: "Netflix", Cost: "$15 a month", Cancel: "Account page, Cancel membership"})
+	d.Devices = append(d.Devices, Device{Name: "iPhone", Unlock: "The code is in the safe"})
+	d.Wishes = append(d.Wishes, Wish{Topic: "Pets", Text: "Luna goes to Sam.\nHer vet is Dr. Ruiz."})
+
+	data, err := d.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), FileName)
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+	got, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Empty() || got.Updated.IsZero() || got.Accounts[0] != d.Accounts[0] || got.Wishes[0] != d.Wishes[0] {
+		t.Errorf("loaded = %+v", got)
+	}
+
+	text := got.Text()
+	for _, want := range []string{"Accounts (1)", "Gmail", "Login:      ana@example.com", "To cancel:  Account page", "To unlock:  The code is in the safe", "Pets\n    Luna goes to Sam.\n    Her vet is Dr. Ruiz."} {
+		if !strings.Contains(text, want) {
+			t.Errorf("text is missing %q:\n%s", want, text)
+		}
+	}
+}
+
+func TestLoadRefusesOtherJSON(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "other.json")
+	os.WriteFile(path, []byte(`{"format":"rememory-vault","version":1}`), 0600)
+	if _, err := Load(path); err == nil || !strings.Contains(err.Error(), "isn't a rememory estate file") {
+		t.Errorf("err = %v", err)
+	}
+	os.WriteFile(path, []byte(`{"format":"rememory-estate","version":99}`), 0600)
+	if _, err := Load(path); err == nil || !strings.Contains(err.Error(), "newer version") {
+		t.Errorf("err = %v", err)
+	}
+}
@@ -0,0 +1,91 @@
+// Package faq answers the questions a holder is likely to have about their
+// bundle: what if it's lost, what else recovery needs, whether it needs the
+// internet, and so on. The answers come from how the project was actually
+// sealed, not from fixed text, so a bundle never tells its holder something
+// that isn't so. README.txt, README.pdf, and recover.html all show them.
+package faq
+
+import "github.com/eljojo/rememory/internal/core"
+
+// Facts is what the answers depend on. The zero value has no threshold and
+// leaves the FAQ out.
+type Facts struct {
+	Threshold   int    `json:"threshold"`
+	Total       int    `json:"total"`
+	Grouped     bool   `json:"grouped,omitempty"`     // Recovery needs enough groups, not just enough pieces
+	Embedded    bool   `json:"embedded,omitempty"`    // MANIFEST.age is inside recover.html
+	RecoveryURL string `json:"recoveryUrl"`           // The recovery page the QR code opens
+	Hosted      bool   `json:"hosted,omitempty"`      // RecoveryURL is the owner's own copy, not the default
+	Catalog     int    `json:"catalog,omitempty"`     // Pieces that open the catalog; 0 without one
+	Delay       string `json:"delay,omitempty"`       // The recovery delay; empty without one
+	PIN         bool   `json:"pin,omitempty"`         // The holder's piece is locked with a PIN
+	ListsOthers bool   `json:"listsOthers,omitempty"` // The bundle names the other holders
+	Beacon      string `json:"beacon,omitempty"`      // Where the project's beacon is published; empty without one
+	Fingerprint string `json:"fingerprint,omitempty"` // The bundle's seal, as the beacon names it
+}
+
+// RecoveryPage returns Facts.RecoveryURL and Facts.Hosted for the base URL
+// of a bundle's QR code, empty for the default.
+func RecoveryPage(recoveryURL string) (string, bool) {
+	if recoveryURL == "" || recoveryURL == core.DefaultRecoveryURL {
+		return core.DefaultRecoveryURL, false
+	}
+	return recoveryURL, true
+}
+
+// Entry is one question and its answer.
+type Entry struct {
+	Question string
+	Answer   string
+}
+
+// Build returns the questions and answers for f, written with t, which
+// looks up keys of the "readme" translations in the bundle's language.
+// It returns nil for the zero Facts.
+func Build(f Facts, t func(key string, args ...any) string) []Entry {
+	if f.Threshold == 0 {
+		return nil
+	}
+	var entries []Entry
+	add := func(question, answer string) {
+		entries = append(entries, Entry{Question: t(question), Answer: answer})
+	}
+
+	if f.Grouped {
+		add("faq_lose_q", t("faq_lose_groups_a"))
+	} else {
+		add("faq_lose_q", t("faq_lose_a", f.Threshold, f.Total))
+	}
+	if f.Embedded {
+		add("faq_manifest_q", t("faq_manifest_embedded_a"))
+	} else {
+		add("faq_manifest_q", t("faq_manifest_file_a"))
+	}
+	if f.Hosted {
+		add("faq_online_q", t("faq_online_hosted_a", f.RecoveryURL))
+	} else {
+		add("faq_online_q", t("faq_online_a", f.RecoveryURL))
+	}
+	if f.Catalog > 0 {
+		add("faq_peek_q", t("faq_peek_catalog_a", f.Catalog))
+	} else {
+		add("faq_peek_q", t("faq_peek_none_a"))
+	}
+	if f.Delay != "" {
+		add("faq_wait_q", t("faq_wait_delay_a", f.Delay))
+	} else {
+		add("faq_wait_q", t("faq_wait_a"))
+	}
+	if f.Beacon != "" {
+		add("faq_current_q", t("faq_current_a", f.Beacon, f.Fingerprint))
+	}
+	if f.PIN {
+		add("faq_pin_q", t("faq_pin_a"))
+	}
+	if f.ListsOthers {
+		add("faq_others_q", t("faq_others_listed_a"))
+	} else {
+		add("faq_others_q", t("faq_others_private_a"))
+	}
+	return entries
+}
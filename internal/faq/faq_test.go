@@ -0,0 +1,81 @@
+package faq
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/eljojo/rememory/internal/core"
+	"github.com/eljojo/rememory/internal/translations"
+)
+
+func english(key string, args ...any) string {
+	return translations.T("readme", "en", key, args...)
+}
+
+// answers returns the answers Build gives for f, by question key.
+func answers(t *testing.T, f Facts) map[string]string {
+	t.Helper()
+	got := make(map[string]string)
+	for _, e := range Build(f, func(key string, args ...any) string {
+		return fmt.Sprintf("%s%v", key, args)
+	}) {
+		got[e.Question] = e.Answer
+	}
+	return got
+}
+
+func TestBuild(t *testing.T) {
+	if entries := Build(Facts{}, english); entries != nil {
+		t.Errorf("zero Facts: got %d entries", len(entries))
+	}
+
+	url, hosted := RecoveryPage("")
+	if url != core.DefaultRecoveryURL || hosted {
+		t.Errorf("RecoveryPage(\"\") = %q, %v", url, hosted)
+	}
+	plain := answers(t, Facts{Threshold: 2, Total: 3, Embedded: true, RecoveryURL: url, ListsOthers: true})
+	for question, want := range map[string]string{
+		"faq_lose_q[]":     "faq_lose_a[2 3]",
+		"faq_manifest_q[]": "faq_manifest_embedded_a[]",
+		"faq_online_q[]":   "faq_online_a[" + core.DefaultRecoveryURL + "]",
+		"faq_peek_q[]":     "faq_peek_none_a[]",
+		"faq_wait_q[]":     "faq_wait_a[]",
+		"faq_others_q[]":   "faq_others_listed_a[]",
+	} {
+		if plain[question] != want {
+			t.Errorf("%s: got %q, want %q", question, plain[question], want)
+		}
+	}
+	if _, ok := plain["faq_pin_q[]"]; ok {
+		t.Error("asked about a PIN for a piece without one")
+	}
+	if _, ok := plain["faq_current_q[]"]; ok {
+		t.Error("pointed to a beacon the project doesn't have")
+	}
+
+	url, hosted = RecoveryPage("https://example.com/recover.html")
+	sealed := answers(t, Facts{Threshold: 3, Total: 5, Grouped: true, RecoveryURL: url, Hosted: hosted, Catalog: 2, Delay: "72h", PIN: true,
+		Beacon: "https://example.com/beacon.txt", Fingerprint: "ab12cd34ef560789"})
+	for question, want := range map[string]string{
+		"faq_lose_q[]":     "faq_lose_groups_a[]",
+		"faq_manifest_q[]": "faq_manifest_file_a[]",
+		"faq_online_q[]":   "faq_online_hosted_a[https://example.com/recover.html]",
+		"faq_peek_q[]":     "faq_peek_catalog_a[2]",
+		"faq_wait_q[]":     "faq_wait_delay_a[72h]",
+		"faq_current_q[]":  "faq_current_a[https://example.com/beacon.txt ab12cd34ef560789]",
+		"faq_pin_q[]":      "faq_pin_a[]",
+		"faq_others_q[]":   "faq_others_private_a[]",
+	} {
+		if sealed[question] != want {
+			t.Errorf("%s: got %q, want %q", question, sealed[question], want)
+		}
+	}
+
+	// The translations have every question and answer
+	for _, e := range Build(Facts{Threshold: 2, Total: 3, RecoveryURL: url, Catalog: 1, Delay: "1h", PIN: true, Beacon: "https://example.com/beacon.txt"}, english) {
+		if strings.HasPrefix(e.Question, "faq_") || strings.HasPrefix(e.Answer, "faq_") {
+			t.Errorf("untranslated: %q %q", e.Question, e.Answer)
+		}
+	}
+}
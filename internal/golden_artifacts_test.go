@@ -0,0 +1,329 @@
+//go:build deterministic
+
+package integration_test
+
+import (
+	"archive/zip"
+	"bytes"
+	cryptorand "crypto/rand"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/eljojo/rememory/internal/bundle"
+	"github.com/eljojo/rememory/internal/core"
+	"github.com/eljojo/rememory/internal/crypto"
+	"github.com/eljojo/rememory/internal/manifest"
+	"github.com/eljojo/rememory/internal/pdf"
+	"github.com/eljojo/rememory/internal/project"
+	"github.com/eljojo/rememory/internal/translations"
+)
+
+var generateArtifacts = flag.Bool("generate", false, "regenerate golden artifacts (writes to testdata/golden-artifacts/)")
+
+const (
+	goldenArtifactsDir  = "testdata/golden-artifacts"
+	goldenArtifactsSeed = "rememory-golden-artifacts"
+	goldenRecoveryURL   = "https://example.com/recover.html"
+
+	// goldenRecoverChecksum stands in for the build-dependent recover.html checksum.
+	goldenRecoverChecksum = "sha256:recover-html-checksum-varies-by-build"
+)
+
+// TestGoldenArtifacts seals a fixed project in deterministic mode and compares
+// everything a friend receives against checked-in files, so wording, share
+// layout, and QR content changes show up as diffs in review.
+//
+// recover.html embeds the compiled JS and WASM, which change with every
+// build, so only its personalization data is compared, and its checksum is
+// masked in the READMEs. PDFs are compared by checksum.
+//
+// Regenerate with: make generate-golden
+func TestGoldenArtifacts(t *testing.T) {
+	original := cryptorand.Reader
+	t.Cleanup(func() { cryptorand.Reader = original })
+	if err := core.EnableDeterministic(goldenArtifactsSeed); err != nil {
+		t.Fatalf("EnableDeterministic: %v", err)
+	}
+
+	artifacts := sealGoldenProject(t)
+
+	if *generateArtifacts {
+		if err := os.RemoveAll(goldenArtifactsDir); err != nil {
+			t.Fatal(err)
+		}
+		for name, data := range artifacts {
+			path := filepath.Join(goldenArtifactsDir, name)
+			if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(path, data, 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		t.Logf("wrote %d golden artifacts to %s", len(artifacts), goldenArtifactsDir)
+		return
+	}
+
+	names := make([]string, 0, len(artifacts))
+	for name := range artifacts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		t.Run(name, func(t *testing.T) {
+			want, err := os.ReadFile(filepath.Join(goldenArtifactsDir, name))
+			if err != nil {
+				t.Fatalf("missing golden file (run 'make generate-golden'): %v", err)
+			}
+			if got := artifacts[name]; !bytes.Equal(got, want) {
+				t.Errorf("%s differs from golden:\n%s", name, firstDifference(string(want), string(got)))
+			}
+		})
+	}
+
+	// Catch golden files that are no longer produced
+	err := filepath.Walk(goldenArtifactsDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		rel, _ := filepath.Rel(goldenArtifactsDir, path)
+		if _, ok := artifacts[filepath.ToSlash(rel)]; !ok {
+			t.Errorf("stale golden file %s is no longer generated", rel)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// sealGoldenProject builds and seals the fixed project and returns the
+// artifacts to compare, keyed by path relative to the golden directory.
+func sealGoldenProject(t *testing.T) map[string][]byte {
+	t.Helper()
+
+	friends := []project.Friend{
+		{Name: "Alice", Contact: "alice@example.com"},
+		{Name: "Bob", Contact: "+1 555 0100"},
+		{Name: "Carol", Contact: "carol@example.com", Language: "es"},
+	}
+	threshold := 2
+
+	projectDir := filepath.Join(t.TempDir(), "golden-project")
+	p, err := project.New(projectDir, "Golden Project", threshold, friends)
+	if err != nil {
+		t.Fatalf("creating project: %v", err)
+	}
+	secret := filepath.Join(p.ManifestPath(), "secret.txt")
+	if err := os.WriteFile(secret, []byte("The treasure is under the oak tree.\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var archiveBuf bytes.Buffer
+	if _, err := manifest.Archive(&archiveBuf, p.ManifestPath()); err != nil {
+		t.Fatalf("archiving: %v", err)
+	}
+
+	raw, passphrase, err := crypto.GenerateRawPassphrase(crypto.DefaultPassphraseBytes)
+	if err != nil {
+		t.Fatalf("generating passphrase: %v", err)
+	}
+
+	if err := os.MkdirAll(p.SharesPath(), 0755); err != nil {
+		t.Fatal(err)
+	}
+	var encrypted bytes.Buffer
+	if err := core.Encrypt(&encrypted, bytes.NewReader(archiveBuf.Bytes()), string(passphrase)); err != nil {
+		t.Fatalf("encrypting: %v", err)
+	}
+	if err := os.WriteFile(p.ManifestAgePath(), encrypted.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	shareData, err := core.Split(raw, len(friends), threshold)
+	if err != nil {
+		t.Fatalf("splitting: %v", err)
+	}
+
+	artifacts := make(map[string][]byte)
+	shareInfos := make([]project.ShareInfo, len(friends))
+	for i, data := range shareData {
+		share := core.NewShare(2, i+1, len(friends), threshold, friends[i].Name, data)
+		if lang := p.WordList(friends[i]); lang != core.LangEN {
+			share.WordList = lang
+		}
+		sharePath := filepath.Join(p.SharesPath(), share.Filename())
+		if err := os.WriteFile(sharePath, []byte(share.Encode()), 0600); err != nil {
+			t.Fatal(err)
+		}
+		checksum, err := crypto.HashFile(sharePath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rel, _ := filepath.Rel(p.Path, sharePath)
+		shareInfos[i] = project.ShareInfo{Friend: friends[i].Name, File: rel, Checksum: checksum}
+
+		dir := core.SanitizeFilename(friends[i].Name)
+		artifacts[dir+"/"+share.Filename()] = []byte(share.Encode())
+		qr := pdf.ReadmeData{Share: share, RecoveryURL: goldenRecoveryURL}.QRContent()
+		artifacts[dir+"/qr.txt"] = []byte(qr + "\n")
+	}
+
+	p.Sealed = &project.Sealed{
+		At:               core.Now(),
+		ManifestChecksum: core.HashBytes(encrypted.Bytes()),
+		VerificationHash: core.HashBytes(passphrase),
+		Shares:           shareInfos,
+	}
+	if err := p.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := bundle.Config{
+		Version:          "v0.0.0-golden",
+		GitHubReleaseURL: "https://github.com/eljojo/rememory/releases/tag/v0.0.0-golden",
+		WASMBytes:        []byte("golden-wasm-placeholder"),
+		RecoveryURL:      goldenRecoveryURL,
+	}
+	if err := bundle.GenerateAll(p, cfg); err != nil {
+		t.Fatalf("generating bundles: %v", err)
+	}
+
+	projectYAML, err := os.ReadFile(filepath.Join(p.Path, project.ProjectFileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	artifacts["project.yml"] = projectYAML
+	artifacts["MANIFEST.age"] = encrypted.Bytes()
+
+	var checksums strings.Builder
+	for i, friend := range friends {
+		dir := core.SanitizeFilename(friend.Name)
+		bundlePath := filepath.Join(p.OutputPath(), "bundles", "bundle-"+dir+".zip")
+		entries := readZipEntries(t, bundlePath)
+
+		// The recover.html checksum depends on the compiled JS, not on anything
+		// sealed, so it is replaced with a placeholder before comparing.
+		recoverHTML := entries["recover.html"]
+		recoverChecksum := core.HashBytes(recoverHTML)
+		artifacts[dir+"/personalization.json"] = extractPersonalization(t, recoverHTML)
+
+		for name, data := range entries {
+			if translations.IsReadmeFile(name, ".txt") {
+				artifacts[dir+"/"+name] = bytes.ReplaceAll(data, []byte(recoverChecksum), []byte(goldenRecoverChecksum))
+			}
+		}
+
+		// PDFs are rendered again with the placeholder checksum for the same reason
+		lang := friend.Language
+		if lang == "" {
+			lang = "en"
+		}
+		var others []project.Friend
+		for j, f := range friends {
+			if j != i {
+				others = append(others, f)
+			}
+		}
+		share, err := core.ParseShare(artifacts[dir+"/SHARE-"+dir+".txt"])
+		if err != nil {
+			t.Fatal(err)
+		}
+		pdfData, err := pdf.GenerateReadme(pdf.ReadmeData{
+			ProjectName:      p.Name,
+			Holder:           friend.Name,
+			Share:            share,
+			OtherFriends:     others,
+			Threshold:        threshold,
+			Total:            len(friends),
+			Version:          cfg.Version,
+			GitHubReleaseURL: cfg.GitHubReleaseURL,
+			ManifestChecksum: p.Sealed.ManifestChecksum,
+			RecoverChecksum:  goldenRecoverChecksum,
+			Created:          p.Sealed.At,
+			RecoveryURL:      goldenRecoveryURL,
+			Language:         lang,
+			ManifestEmbedded: true,
+		})
+		if err != nil {
+			t.Fatalf("generating PDF: %v", err)
+		}
+		fmt.Fprintf(&checksums, "%s  %s/%s\n", core.HashBytes(pdfData), dir, translations.ReadmeFilename(lang, ".pdf"))
+	}
+	artifacts["checksums.txt"] = []byte(sortLines(checksums.String()))
+
+	return artifacts
+}
+
+func readZipEntries(t *testing.T, path string) map[string][]byte {
+	t.Helper()
+	r, err := zip.OpenReader(path)
+	if err != nil {
+		t.Fatalf("opening %s: %v", path, err)
+	}
+	defer r.Close()
+
+	entries := make(map[string][]byte)
+	for _, f := range r.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		entries[f.Name] = data
+	}
+	return entries
+}
+
+// extractPersonalization returns the JSON assigned to window.PERSONALIZATION.
+func extractPersonalization(t *testing.T, htmlData []byte) []byte {
+	t.Helper()
+	const marker = "window.PERSONALIZATION = "
+	s := string(htmlData)
+	start := strings.Index(s, marker)
+	if start == -1 {
+		t.Fatal("recover.html has no personalization data")
+	}
+	s = s[start+len(marker):]
+	end := strings.Index(s, ";\n")
+	if end == -1 {
+		t.Fatal("unterminated personalization data")
+	}
+	return []byte(s[:end] + "\n")
+}
+
+func sortLines(s string) string {
+	lines := strings.Split(strings.TrimSpace(s), "\n")
+	sort.Strings(lines)
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// firstDifference shows the first line that differs, to keep failures readable.
+func firstDifference(want, got string) string {
+	wantLines := strings.Split(want, "\n")
+	gotLines := strings.Split(got, "\n")
+	for i := 0; i < len(wantLines) || i < len(gotLines); i++ {
+		var w, g string
+		if i < len(wantLines) {
+			w = wantLines[i]
+		}
+		if i < len(gotLines) {
+			g = gotLines[i]
+		}
+		if w != g {
+			return fmt.Sprintf("line %d:\n  want: %q\n  got:  %q", i+1, w, g)
+		}
+	}
+	return "(binary or trailing difference)"
+}
@@ -4,10 +4,18 @@ import type {
   RecoveryState,
   PersonalizationData,
   FriendInfo,
+  ParsedShare,
   ShareInput,
+  SelfTestResult,
   ToastAction,
   TranslationFunction
 } from './types';
+import { findVault, renderVault } from './vault-view';
+import { findSeed, renderSeed } from './seed-view';
+import { findEstate, renderEstate } from './estate-view';
+import { renderCatalog } from './catalog-view';
+import { renderQuiz } from './quiz-view';
+import { renderFAQ } from './faq-view';
 
 // Translation function (defined in HTML)
 declare const t: TranslationFunction;
@@ -22,6 +30,8 @@ declare const t: TranslationFunction;
   const state: RecoveryState = {
     shares: [],
     manifest: null,
+    decoy: null,
+    timelock: null,
     threshold: 0,
     total: 0,
     wasmReady: false,
@@ -46,6 +56,13 @@ declare const t: TranslationFunction;
     filesList: HTMLElement | null;
     downloadActions: HTMLElement | null;
     downloadAllBtn: HTMLButtonElement | null;
+    downloadFileBtn: HTMLButtonElement | null;
+    vaultViewer: HTMLElement | null;
+    seedViewer: HTMLElement | null;
+    estateViewer: HTMLElement | null;
+    catalogViewer: HTMLElement | null;
+    quiz: HTMLElement | null;
+    faq: HTMLElement | null;
     pasteToggleBtn: HTMLButtonElement | null;
     pasteArea: HTMLElement | null;
     pasteInput: HTMLTextAreaElement | null;
@@ -58,6 +75,17 @@ declare const t: TranslationFunction;
     qrScannerModal: HTMLElement | null;
     qrVideo: HTMLVideoElement | null;
     qrScannerClose: HTMLButtonElement | null;
+    qrScannerHint: HTMLElement | null;
+    keepProgress: HTMLElement | null;
+    keepProgressToggle: HTMLInputElement | null;
+    keepProgressCode: HTMLElement | null;
+    keepProgressCodeValue: HTMLElement | null;
+    restoreProgress: HTMLElement | null;
+    restoreProgressInput: HTMLInputElement | null;
+    restoreProgressBtn: HTMLButtonElement | null;
+    restoreProgressDiscardBtn: HTMLButtonElement | null;
+    selfTestBtn: HTMLButtonElement | null;
+    selfTestResult: HTMLElement | null;
   }
 
   // DOM elements
@@ -77,6 +105,13 @@ declare const t: TranslationFunction;
     filesList: document.getElementById('files-list'),
     downloadActions: document.getElementById('download-actions'),
     downloadAllBtn: document.getElementById('download-all-btn') as HTMLButtonElement | null,
+    downloadFileBtn: document.getElementById('download-file-btn') as HTMLButtonElement | null,
+    vaultViewer: document.getElementById('vault-viewer'),
+    seedViewer: document.getElementById('seed-viewer'),
+    estateViewer: document.getElementById('estate-viewer'),
+    catalogViewer: document.getElementById('catalog-viewer'),
+    quiz: document.getElementById('quiz'),
+    faq: document.getElementById('faq'),
     pasteToggleBtn: document.getElementById('paste-toggle-btn') as HTMLButtonElement | null,
     pasteArea: document.getElementById('paste-area'),
     pasteInput: document.getElementById('paste-input') as HTMLTextAreaElement | null,
@@ -89,6 +124,17 @@ declare const t: TranslationFunction;
     qrScannerModal: document.getElementById('qr-scanner-modal'),
     qrVideo: document.getElementById('qr-video') as HTMLVideoElement | null,
     qrScannerClose: document.getElementById('qr-scanner-close') as HTMLButtonElement | null,
+    qrScannerHint: document.getElementById('qr-scanner-hint'),
+    keepProgress: document.getElementById('keep-progress'),
+    keepProgressToggle: document.getElementById('keep-progress-toggle') as HTMLInputElement | null,
+    keepProgressCode: document.getElementById('keep-progress-code'),
+    keepProgressCodeValue: document.getElementById('keep-progress-code-value'),
+    restoreProgress: document.getElementById('restore-progress'),
+    restoreProgressInput: document.getElementById('restore-progress-input') as HTMLInputElement | null,
+    restoreProgressBtn: document.getElementById('restore-progress-btn') as HTMLButtonElement | null,
+    restoreProgressDiscardBtn: document.getElementById('restore-progress-discard-btn') as HTMLButtonElement | null,
+    selfTestBtn: document.getElementById('self-test-btn') as HTMLButtonElement | null,
+    selfTestResult: document.getElementById('self-test-result'),
   };
 
   // Personalization data (embedded in HTML)
@@ -98,8 +144,22 @@ declare const t: TranslationFunction;
   // Share regex to extract from README.txt content
   const shareRegex = /-----BEGIN REMEMORY SHARE-----([\s\S]*?)-----END REMEMORY SHARE-----/;
 
-  // Compact share format regex: RM{version}:{index}:{total}:{threshold}:{base64url}:{check}
-  const compactShareRegex = /^RM\d+:\d+:\d+:\d+:[A-Za-z0-9_-]+:[0-9a-f]{4}$/;
+  // Compact share format regex: RM{version}:{index}:{total}:{threshold}:{base64url}:{check},
+  // with :{group}.{groups}.{groupsNeeded} after it for a grouped project's pieces
+  const compactShareRegex = /^RM\d+:\d+:\d+:\d+:[A-Za-z0-9_-]+:[0-9a-f]{4}(?::\d+\.\d+\.\d+)?(?::b[0-9a-f]{16}\.[0-9a-f]{8})?$/;
+  // One part of a QR code split over several: RMQ:{seq}/{total}:{id}:{part}
+  const qrChunkRegex = /^RMQ:(\d+)\/(\d+):([0-9a-f]{4}):/;
+
+  // The rm1 string printed under the QR code, typed in any case and in groups.
+  // Letters Bech32 leaves out are let through so the error can name them.
+  const bech32ShareRegex = /^rm1[\s-]*[a-z0-9][a-z0-9\s-]{5,}$/i;
+
+  // SSKR shares: a UR (ur:sskr/...), or at least 30 bytewords (four letters each)
+  const sskrURRegex = /\bur:(?:crypto-)?sskr\/[a-z]+/i;
+  const bytewordsRegex = /^(?:[a-z]{4}[\s-]+){29,}[a-z]{4}$/i;
+
+  // Digit groups read over the phone: only digits, spaces, and dashes
+  const digitsRegex = /^[\d\s-]*\d{6}[\d\s-]*$/;
 
   // ============================================
   // Error Handlers
@@ -172,6 +232,14 @@ declare const t: TranslationFunction;
       );
     },
 
+    otherBundle(bundle: string, have: string): void {
+      toast.error(
+        t('error_other_bundle_title'),
+        t('error_other_bundle_message', bundle, have),
+        t('error_other_bundle_guidance')
+      );
+    },
+
     fileReadFailed(filename: string): void {
       showError(
         t('error_file_read_message', filename),
@@ -232,6 +300,14 @@ declare const t: TranslationFunction;
       renderContactList();
       elements.contactListSection?.classList.remove('hidden');
     }
+    if (personalization?.quiz && elements.quiz) {
+      renderQuiz(elements.quiz, personalization);
+      elements.quiz.classList.remove('hidden');
+    }
+    if (personalization?.faq && elements.faq) {
+      renderFAQ(elements.faq, personalization.faq);
+      elements.faq.classList.remove('hidden');
+    }
 
     await loadWasm();
 
@@ -242,6 +318,9 @@ declare const t: TranslationFunction;
 
     // Check URL fragment for compact share (e.g. #share=RM1:2:5:3:BASE64:CHECK)
     loadShareFromFragment();
+
+    // Offer to bring back pieces from before a reload, once the page's own are in
+    setupReloadProtection();
   }
 
   // ============================================
@@ -260,6 +339,7 @@ declare const t: TranslationFunction;
         state.threshold = share.threshold;
         state.total = share.total;
         state.shares.push(share);
+        addExtraShares(result.extra, true);
 
         updateSharesUI();
         updateContactList();
@@ -276,6 +356,12 @@ declare const t: TranslationFunction;
       state.manifest = bytes;
       showManifestLoaded('MANIFEST.age', state.manifest.length, 'embedded');
     }
+    if (personalization.decoyB64) {
+      state.decoy = base64ToBytes(personalization.decoyB64);
+    }
+    if (personalization.timelock) {
+      state.timelock = JSON.stringify(personalization.timelock);
+    }
 
     checkRecoverReady();
   }
@@ -299,6 +385,7 @@ declare const t: TranslationFunction;
     const share = result.share;
 
     if (state.shares.some(s => s.index === share.index)) return;
+    if (fromOtherBundle(share)) return;
 
     if (state.shares.length === 0 || (state.threshold === 0 && share.threshold > 0)) {
       state.threshold = share.threshold;
@@ -348,7 +435,7 @@ declare const t: TranslationFunction;
     const collectedNames = new Set(
       state.shares.map(s => s.holder?.toLowerCase()).filter(Boolean)
     );
-    const collectedIndices = new Set(state.shares.map(s => s.index));
+    const collectedIndices = new Set(state.shares.filter(s => !s.format).map(s => s.index));
 
     elements.contactList.querySelectorAll('.contact-item').forEach(item => {
       const el = item as HTMLElement;
@@ -367,9 +454,14 @@ declare const t: TranslationFunction;
   // WASM Loading
   // ============================================
 
+  // The running Go program, to tell when it has stopped for good: Go ends
+  // when the browser won't give it more memory, and every call after fails.
+  let wasmGo: GoInstance | null = null;
+
   async function loadWasm(): Promise<void> {
     try {
       const go = new window.Go();
+      wasmGo = go;
       const result = await WebAssembly.instantiateStreaming(
         fetch('recover.wasm'),
         go.importObject
@@ -385,6 +477,7 @@ declare const t: TranslationFunction;
       if (typeof window.WASM_BINARY !== 'undefined') {
         try {
           const go = new window.Go();
+          wasmGo = go;
           const bytes = await decodeAndDecompressWasm(window.WASM_BINARY);
           const result = await WebAssembly.instantiate(bytes, go.importObject);
           go.run(result.instance);
@@ -522,8 +615,9 @@ declare const t: TranslationFunction;
 
     // Try compact format first, then PEM format
     let share: import('./types').ParsedShare | undefined;
+    let extra: import('./types').ParsedShare[] | undefined;
 
-    if (compactShareRegex.test(content.trim())) {
+    if (compactShareRegex.test(content.trim()) || bech32ShareRegex.test(content.trim())) {
       const result = window.rememoryParseCompactShare(content.trim());
       if (result.error || !result.share) {
         showError(
@@ -536,6 +630,32 @@ declare const t: TranslationFunction;
         return;
       }
       share = result.share;
+    } else if (digitsRegex.test(content.trim())) {
+      const digitsResult = window.rememoryDecodeDigits(content.trim());
+      if (digitsResult.error || digitsResult.index === 0) {
+        toast.error(
+          t('error_invalid_digits_title'),
+          digitsResult.error || t('error_paste_no_share_message'),
+          t('error_invalid_digits_guidance')
+        );
+        return;
+      }
+      share = buildShareFromWords(digitsResult);
+      if (!share) return; // error already shown
+    } else if (sskrURRegex.test(content) || bytewordsRegex.test(content.trim())) {
+      const urMatch = content.match(sskrURRegex);
+      const result = window.rememoryParseSSKRShare(urMatch ? urMatch[0] : content.trim());
+      if (result.error || !result.share) {
+        showError(
+          result.error || t('error_invalid_share_message', t('pasted_content')),
+          {
+            title: t('error_invalid_share_title'),
+            guidance: t('error_invalid_share_guidance')
+          }
+        );
+        return;
+      }
+      share = result.share;
     } else if (shareRegex.test(content)) {
       const result = window.rememoryParseShare(content);
       if (result.error || !result.share) {
@@ -549,10 +669,11 @@ declare const t: TranslationFunction;
         return;
       }
       share = result.share;
+      extra = result.extra;
     } else {
       // Try to extract BIP39 words from the pasted text
       const extractedWords = extractWordsFromText(content);
-      if (extractedWords.length >= 25) {
+      if (SHARE_WORD_COUNTS.includes(extractedWords.length)) {
         const wordResult = window.rememoryDecodeWords(extractedWords);
         if (!wordResult.error && wordResult.index > 0) {
           // Valid words found — add share directly (25th word provides the index)
@@ -581,10 +702,11 @@ declare const t: TranslationFunction;
       }
     }
 
-    if (state.shares.some(s => s.index === share.index)) {
+    if (hasShare(share)) {
       errorHandlers.duplicateShare(share.index);
       return;
     }
+    if (fromOtherBundle(share)) return;
 
     if (state.shares.length === 0 || (state.threshold === 0 && share.threshold > 0)) {
       state.threshold = share.threshold;
@@ -592,15 +714,66 @@ declare const t: TranslationFunction;
     }
 
     state.shares.push(share);
+    addExtraShares(extra);
     updateSharesUI();
     checkRecoverReady();
   }
 
+  // SSKR shares number friends on their own, so they only clash with each other
+  function hasShare(share: import('./types').ParsedShare): boolean {
+    return state.shares.some(s => s.index === share.index && s.format === share.format);
+  }
+
+  // Pieces that name their bundle, by ID or in their data, must all name
+  // the same one: a piece from another project, or another seal of this
+  // one, would only fail later
+  function fromOtherBundle(share: import('./types').ParsedShare): boolean {
+    if (!share.bundle) return false;
+    const other = state.shares.find(s => s.bundle && s.bundle !== share.bundle);
+    if (!other) return false;
+    errorHandlers.otherBundle(share.bundle || '', other.bundle || '');
+    return true;
+  }
+
+  // A friend who holds several pieces hands them over together: the ones
+  // after the first arrive as extra and count like anyone else's
+  function addExtraShares(extra: import('./types').ParsedShare[] | undefined, isHolder = false): void {
+    for (const share of extra || []) {
+      if (hasShare(share)) continue;
+      if (isHolder) share.isHolder = true;
+      state.shares.push(share);
+    }
+  }
+
+  // SSKR shares combine only with each other, so once one is added the
+  // others (such as this page's own piece) are set aside. Pieces still
+  // locked with a PIN don't count until they're unlocked.
+  function usableShares(): import('./types').ParsedShare[] {
+    const sskr = state.shares.filter(s => s.format === 'sskr');
+    return sskr.length > 0 ? sskr : state.shares.filter(s => !s.pinSalt);
+  }
+
+  // Pieces of a grouped project count by group: a group is complete once it
+  // has its own threshold of pieces. Returns null for ungrouped pieces.
+  function groupProgress(): { complete: number; needed: number } | null {
+    const grouped = usableShares().filter(s => (s.groups || 0) > 0);
+    if (grouped.length === 0) return null;
+    const counts = new Map<number, { count: number; threshold: number }>();
+    for (const s of grouped) {
+      const entry = counts.get(s.group || 0) || { count: 0, threshold: s.threshold };
+      entry.count++;
+      counts.set(s.group || 0, entry);
+    }
+    let complete = 0;
+    counts.forEach(g => { if (g.count >= g.threshold) complete++; });
+    return { complete, needed: grouped[0].groupsNeeded || 0 };
+  }
+
   // ============================================
   // Build Share from Decoded Words
   // ============================================
 
-  function buildShareFromWords(wordResult: { data: Uint8Array; index: number; checksum: string }): import('./types').ParsedShare | null {
+  function buildShareFromWords(wordResult: { data: Uint8Array; index: number; checksum: string; bundle?: string }): import('./types').ParsedShare | null {
     const shareIndex = wordResult.index;
 
     // Get version/total/threshold from first loaded share or personalization
@@ -624,7 +797,8 @@ declare const t: TranslationFunction;
       index: shareIndex,
       threshold,
       total,
-      dataB64
+      dataB64,
+      bundle: wordResult.bundle || undefined
     };
   }
 
@@ -634,6 +808,8 @@ declare const t: TranslationFunction;
 
   let scannerStream: MediaStream | null = null;
   let scannerAnimFrame: number | null = null;
+  // Parts of split QR codes read so far, by id and then sequence number
+  const scannedParts = new Map<string, Map<number, string>>();
 
   function setupScanner(): void {
     // Only show the button if BarcodeDetector is available
@@ -667,6 +843,8 @@ declare const t: TranslationFunction;
     if (elements.qrVideo) {
       elements.qrVideo.srcObject = scannerStream;
     }
+    scannedParts.clear();
+    if (elements.qrScannerHint) elements.qrScannerHint.textContent = t('scan_hint');
 
     const detector = new BarcodeDetector({ formats: ['qr_code'] });
 
@@ -683,27 +861,16 @@ declare const t: TranslationFunction;
         if (!scannerStream) return; // Scanner was closed
 
         for (const barcode of barcodes) {
-          const value = barcode.rawValue.trim();
-          // Check for compact share format directly or URL with fragment
-          let compact = '';
-          if (compactShareRegex.test(value)) {
-            compact = value;
-          } else {
-            // Check for URL with #share= fragment
-            try {
-              const url = new URL(value);
-              const hash = url.hash;
-              if (hash && hash.startsWith('#share=')) {
-                const decoded = decodeURIComponent(hash.slice('#share='.length));
-                if (compactShareRegex.test(decoded)) {
-                  compact = decoded;
-                }
-              }
-            } catch {
-              // Not a URL, ignore
-            }
+          let value = barcode.rawValue.trim();
+          // A part of a split code: wait until all of them are read
+          const chunk = value.match(qrChunkRegex);
+          if (chunk) {
+            const joined = addScannedPart(value, Number(chunk[1]), Number(chunk[2]), chunk[3]);
+            if (!joined) continue;
+            value = joined;
           }
 
+          const compact = compactFromScan(value);
           if (compact) {
             handleScannedShare(compact);
             return;
@@ -720,6 +887,45 @@ declare const t: TranslationFunction;
     scannerAnimFrame = requestAnimationFrame(scanLoop);
   }
 
+  // compactFromScan returns the compact share in a scanned code, whether the
+  // code holds it directly or as a link with a #share= fragment.
+  function compactFromScan(value: string): string {
+    if (compactShareRegex.test(value)) return value;
+    try {
+      const hash = new URL(value).hash;
+      if (hash && hash.startsWith('#share=')) {
+        const decoded = decodeURIComponent(hash.slice('#share='.length));
+        if (compactShareRegex.test(decoded)) return decoded;
+      }
+    } catch {
+      // Not a URL, ignore
+    }
+    return '';
+  }
+
+  // addScannedPart records one part of a split QR code. It returns what the
+  // whole code holds once every part is read, and '' until then.
+  function addScannedPart(value: string, seq: number, total: number, id: string): string {
+    let parts = scannedParts.get(id);
+    if (!parts) {
+      parts = new Map();
+      scannedParts.set(id, parts);
+    }
+    parts.set(seq, value);
+    if (elements.qrScannerHint) {
+      elements.qrScannerHint.textContent = t('scan_parts', parts.size, total);
+    }
+    if (parts.size < total) return '';
+
+    const result = window.rememoryJoinQRChunks(Array.from(parts.values()));
+    scannedParts.delete(id);
+    if (result.error) {
+      if (elements.qrScannerHint) elements.qrScannerHint.textContent = t('scan_parts_mismatch');
+      return '';
+    }
+    return result.content;
+  }
+
   async function handleScannedShare(compact: string): Promise<void> {
     closeScanner();
     await parseAndAddShareFromPaste(compact);
@@ -797,6 +1003,7 @@ declare const t: TranslationFunction;
       errorHandlers.duplicateShare(share.index);
       return;
     }
+    if (fromOtherBundle(share)) return;
 
     if (state.shares.length === 0 || (state.threshold === 0 && share.threshold > 0)) {
       state.threshold = share.threshold;
@@ -804,11 +1011,19 @@ declare const t: TranslationFunction;
     }
 
     state.shares.push(share);
+    addExtraShares(result.extra);
     updateSharesUI();
 
     if (result.manifest && !state.manifest) {
       state.manifest = result.manifest;
       showManifestLoaded('MANIFEST.age', state.manifest.length, 'bundle');
+      showRepaired(result.repaired);
+    }
+    if (result.decoy && !state.decoy) {
+      state.decoy = result.decoy;
+    }
+    if (result.timelock && !state.timelock) {
+      state.timelock = result.timelock;
     }
 
     checkRecoverReady();
@@ -837,6 +1052,7 @@ declare const t: TranslationFunction;
       errorHandlers.duplicateShare(share.index);
       return;
     }
+    if (fromOtherBundle(share)) return;
 
     if (state.shares.length === 0 || (state.threshold === 0 && share.threshold > 0)) {
       state.threshold = share.threshold;
@@ -844,6 +1060,7 @@ declare const t: TranslationFunction;
     }
 
     state.shares.push(share);
+    addExtraShares(result.extra);
     updateSharesUI();
     checkRecoverReady();
   }
@@ -856,11 +1073,11 @@ declare const t: TranslationFunction;
   // the friend name from personalization data by share index, fall back to generic.
   function resolveShareName(share: import('./types').ParsedShare): string {
     if (share.holder) return share.holder;
+    if (share.format === 'sskr') return 'SSKR ' + share.index;
     if (personalization) {
       if (personalization.holder) {
         // Check if this matches the bundle holder's own share index
-        const holderShare = state.shares.find(s => s.isHolder);
-        if (holderShare && holderShare.index === share.index) {
+        if (state.shares.some(s => s.isHolder && s.index === share.index)) {
           return personalization.holder;
         }
       }
@@ -870,6 +1087,71 @@ declare const t: TranslationFunction;
     return 'Share ' + share.index;
   }
 
+  // Checksums of the pieces added so far, keyed by their data, worked out as
+  // they arrive. An empty string means it is still being worked out.
+  const shareDataChecksums = new Map<string, string>();
+
+  async function shareDataChecksum(dataB64: string): Promise<string> {
+    const data = Uint8Array.from(atob(dataB64), c => c.charCodeAt(0));
+    const digest = new Uint8Array(await crypto.subtle.digest('SHA-256', data));
+    return 'sha256:' + Array.from(digest, b => b.toString(16).padStart(2, '0')).join('');
+  }
+
+  // Says whether a piece is one of those this bundle recorded for its seal.
+  // Bundles made before they recorded their pieces say nothing.
+  function sealMatchHTML(share: import('./types').ParsedShare): string {
+    const recorded = personalization?.shareChecksums;
+    if (!recorded || share.format === 'sskr' || !share.dataB64) return '';
+
+    const checksum = shareDataChecksums.get(share.dataB64);
+    if (checksum === undefined) {
+      shareDataChecksums.set(share.dataB64, '');
+      shareDataChecksum(share.dataB64)
+        .then(sum => {
+          shareDataChecksums.set(share.dataB64, sum);
+          updateSharesUI();
+        })
+        .catch(() => { /* No Web Crypto: leave the piece unchecked */ });
+      return '';
+    }
+    if (!checksum) return '';
+    if (!Object.values(recorded).includes(checksum)) {
+      return `<div class="meta seal-mismatch">&#9888;&#65039; ${t('share_not_in_bundle')}</div>`;
+    }
+    // A piece whose PEM form carries a VSS value is also checked against
+    // the seal's Feldman commitments.
+    const vss = personalization?.vss;
+    if (vss && share.vssB64 && !window.rememoryCheckVSS(vss, share.index, share.vssB64).ok) {
+      return `<div class="meta seal-mismatch">&#9888;&#65039; ${t('share_vss_mismatch')}</div>`;
+    }
+    return `<div class="meta seal-match">&#10003; ${t('share_matches_bundle')}</div>`;
+  }
+
+  // Unlocks a piece locked with the PIN its holder was told in person. The
+  // holder's other pieces share the PIN, so they're unlocked with it too.
+  function unlockShare(share: import('./types').ParsedShare, pin: string): void {
+    const result = window.rememoryUnlockShare(share, pin);
+    if (result.error || !result.dataB64) {
+      toast.warning(t('pin_wrong_title'), result.wrongPin ? t('pin_wrong') : (result.error || ''));
+      return;
+    }
+    applyUnlock(share, result.dataB64);
+    for (const other of state.shares) {
+      if (other.pinSalt && other.holder && other.holder === share.holder) {
+        const more = window.rememoryUnlockShare(other, pin);
+        if (more.dataB64) applyUnlock(other, more.dataB64);
+      }
+    }
+    updateSharesUI();
+    checkRecoverReady();
+  }
+
+  function applyUnlock(share: import('./types').ParsedShare, dataB64: string): void {
+    share.dataB64 = dataB64;
+    delete share.pinSalt;
+    delete share.pinCheck;
+  }
+
   function updateSharesUI(): void {
     if (!elements.sharesList) return;
 
@@ -890,10 +1172,19 @@ declare const t: TranslationFunction;
       const holderLabel = isHolderShare ? ` (${t('your_share')})` : '';
       const showRemove = !isHolderShare;
 
+      const lockedHTML = share.pinSalt ? `
+          <div class="meta share-locked">${t('share_locked')}</div>
+          <form class="pin-form" data-idx="${idx}">
+            <input type="password" inputmode="numeric" autocomplete="off" class="pin-input" aria-label="PIN" placeholder="PIN">
+            <button type="submit" class="btn btn-secondary">${t('pin_unlock')}</button>
+          </form>` : '';
+
       item.innerHTML = `
-        <span class="icon">&#9989;</span>
+        <span class="icon">${share.pinSalt ? '&#128274;' : '&#9989;'}</span>
         <div class="details">
           <div class="name">${escapeHtml(displayName)}${holderLabel}</div>
+          ${sealMatchHTML(share)}
+          ${lockedHTML}
         </div>
         ${showRemove ? `<button class="remove" data-idx="${idx}" title="${t('remove')}">&times;</button>` : ''}
       `;
@@ -916,13 +1207,33 @@ declare const t: TranslationFunction;
       });
     });
 
+    elements.sharesList.querySelectorAll<HTMLFormElement>('.pin-form').forEach(form => {
+      form.addEventListener('submit', (e) => {
+        e.preventDefault();
+        const share = state.shares[parseInt(form.dataset.idx || '0', 10)];
+        const input = form.querySelector<HTMLInputElement>('.pin-input');
+        if (share && input) unlockShare(share, input.value);
+      });
+    });
+
     // Update threshold info
-    if (state.threshold > 0 && elements.thresholdInfo) {
-      const needed = Math.max(0, state.threshold - state.shares.length);
+    const groups = groupProgress();
+    if (groups && elements.thresholdInfo) {
+      const ready = groups.complete >= groups.needed;
+      const progress = t('groups_of', groups.complete, groups.needed);
+      elements.thresholdInfo.innerHTML = ready
+        ? `&#9989; ${t('ready')} (${progress})`
+        : `&#128274; ${progress}`;
+      elements.thresholdInfo.className = 'threshold-info' + (ready ? ' ready' : '');
+      elements.thresholdInfo.classList.remove('hidden');
+      elements.step1Card?.classList.toggle('threshold-met', ready);
+    } else if (state.threshold > 0 && elements.thresholdInfo) {
+      const count = usableShares().length;
+      const needed = Math.max(0, state.threshold - count);
       const needLabel = needed === 1 ? t('need_more_one') : t('need_more', needed);
       elements.thresholdInfo.innerHTML = needed > 0
-        ? `&#128274; ${needLabel} (${t('shares_of', state.shares.length, state.threshold)})`
-        : `&#9989; ${t('ready')} (${t('shares_of', state.shares.length, state.threshold)})`;
+        ? `&#128274; ${needLabel} (${t('shares_of', count, state.threshold)})`
+        : `&#9989; ${t('ready')} (${t('shares_of', count, state.threshold)})`;
       elements.thresholdInfo.className = 'threshold-info' + (needed === 0 ? ' ready' : '');
       elements.thresholdInfo.classList.remove('hidden');
 
@@ -933,7 +1244,43 @@ declare const t: TranslationFunction;
       elements.step1Card?.classList.remove('threshold-met');
     }
 
+    updateCatalog();
     updateContactList();
+    void saveProgress();
+  }
+
+  // Shows what's sealed once enough pieces carry the catalog, which projects
+  // that set one in project.yml open with fewer pieces than a recovery needs.
+  // The catalog's own shares aren't PIN-locked, so locked pieces count too.
+  let catalogOpenedWith = '';
+
+  function updateCatalog(): void {
+    const viewer = elements.catalogViewer;
+    if (!viewer || !personalization?.catalogB64) return;
+
+    const pieces = new Map<number, ParsedShare>();
+    for (const share of state.shares) {
+      if (share.catalogB64 && share.catalogThreshold) pieces.set(share.index, share);
+    }
+    const needed = pieces.size > 0 ? [...pieces.values()][0].catalogThreshold || 0 : 0;
+    if (pieces.size === 0 || pieces.size < needed) {
+      viewer.classList.add('hidden');
+      catalogOpenedWith = '';
+      return;
+    }
+
+    const key = [...pieces.keys()].sort().join(',');
+    if (key === catalogOpenedWith) return;
+
+    const result = window.rememoryOpenCatalog(personalization.catalogB64, [...pieces.values()]);
+    if (result.error || !result.catalog) {
+      viewer.classList.add('hidden');
+      catalogOpenedWith = '';
+      return;
+    }
+    renderCatalog(viewer, result.catalog);
+    viewer.classList.remove('hidden');
+    catalogOpenedWith = key;
   }
 
   // ============================================
@@ -949,7 +1296,24 @@ declare const t: TranslationFunction;
     }
 
     try {
-      const file = fileArray[0];
+      // TIMELOCK.json comes with MANIFEST.age when the project has a recovery delay
+      const timelockFile = fileArray.find(f => f.name === 'TIMELOCK.json');
+      if (timelockFile) {
+        state.timelock = await readFileAsText(timelockFile);
+        if (fileArray.length === 1) return;
+      }
+      const file = fileArray.find(f => f !== timelockFile && !f.name.endsWith('.age.parity')) || fileArray[0];
+
+      // MANIFEST.age.parity, from seal --parity, repairs bit rot in MANIFEST.age
+      const parityFile = fileArray.find(f => f.name.endsWith('.age.parity'));
+      const parity = parityFile ? new Uint8Array(await readFileAsArrayBuffer(parityFile)) : undefined;
+
+      // A MANIFEST.age split with --volume-size comes as MANIFEST.age.001, .002, ...
+      const volumeFiles = fileArray.filter(f => /\.age\.\d{3,}$/.test(f.name));
+      if (volumeFiles.length > 0) {
+        await handleManifestVolumes(volumeFiles, fileArray.find(f => f.name.endsWith('.age.sha256')), parity);
+        return;
+      }
 
       if (file.name.endsWith('.zip') || file.type === 'application/zip') {
         await handleBundleZip(file);
@@ -977,15 +1341,85 @@ declare const t: TranslationFunction;
       }
 
       const buffer = await readFileAsArrayBuffer(file);
-      state.manifest = new Uint8Array(buffer);
+      let manifest = new Uint8Array(buffer);
+      let repaired = 0;
+      if (parity) {
+        if (!state.wasmReady) {
+          toast.warning(t('error_not_ready_title'), t('error_not_ready_message'), t('error_not_ready_guidance'));
+          return;
+        }
+        const result = window.rememoryRepairManifest(manifest, parity);
+        if (result.error || !result.data) {
+          showRepairError(result.error || '');
+          return;
+        }
+        manifest = result.data;
+        repaired = result.repaired || 0;
+      }
+      state.manifest = manifest;
 
       showManifestLoaded(file.name, state.manifest.length);
+      showRepaired(repaired);
       checkRecoverReady();
     } catch (_err) {
       errorHandlers.fileReadFailed(fileArray[0]?.name || 'file');
     }
   }
 
+  async function handleManifestVolumes(files: File[], indexFile?: File, parity?: Uint8Array): Promise<void> {
+    if (!state.wasmReady) {
+      toast.warning(t('error_not_ready_title'), t('error_not_ready_message'), t('error_not_ready_guidance'));
+      return;
+    }
+
+    const volumes = await Promise.all(files.map(async f => ({
+      name: f.name,
+      data: new Uint8Array(await readFileAsArrayBuffer(f)),
+    })));
+    const index = indexFile ? await readFileAsText(indexFile) : '';
+
+    const result = window.rememoryJoinVolumes(volumes, index, parity);
+    if (result.error || !result.data) {
+      if (elements.manifestDropZone) {
+        showError(
+          t('error_volumes_message', result.error || ''),
+          {
+            title: t('error_volumes_title'),
+            guidance: t('error_volumes_guidance'),
+            inline: true,
+            targetElement: elements.manifestDropZone
+          }
+        );
+      }
+      return;
+    }
+
+    state.manifest = result.data;
+    showManifestLoaded('MANIFEST.age', state.manifest.length, 'volumes', files.length);
+    showRepaired(result.repaired);
+    checkRecoverReady();
+  }
+
+  function showRepaired(repaired?: number): void {
+    if (repaired) {
+      toast.info(t('manifest_repaired_title'), t('manifest_repaired_message', repaired), t('manifest_repaired_guidance'));
+    }
+  }
+
+  function showRepairError(detail: string): void {
+    if (elements.manifestDropZone) {
+      showError(
+        t('error_repair_message', detail),
+        {
+          title: t('error_repair_title'),
+          guidance: t('error_repair_guidance'),
+          inline: true,
+          targetElement: elements.manifestDropZone
+        }
+      );
+    }
+  }
+
   async function handleManifestFromHTML(file: File): Promise<void> {
     const text = await readFileAsText(file);
 
@@ -1030,6 +1464,12 @@ declare const t: TranslationFunction;
       }
       state.manifest = bytes;
       showManifestLoaded('MANIFEST.age', state.manifest.length, 'html');
+      if (personalizationData.decoyB64) {
+        state.decoy = base64ToBytes(personalizationData.decoyB64);
+      }
+      if (personalizationData.timelock) {
+        state.timelock = JSON.stringify(personalizationData.timelock);
+      }
 
       // Also extract the share if present and we don't already have one
       if (personalizationData.holderShare && state.wasmReady) {
@@ -1042,6 +1482,7 @@ declare const t: TranslationFunction;
               state.total = share.total;
             }
             state.shares.push(share);
+            addExtraShares(result.extra);
             updateSharesUI();
           }
         }
@@ -1063,7 +1504,7 @@ declare const t: TranslationFunction;
     }
   }
 
-  function showManifestLoaded(filename: string, size: number, source: 'file' | 'bundle' | 'embedded' | 'html' = 'file'): void {
+  function showManifestLoaded(filename: string, size: number, source: 'file' | 'bundle' | 'embedded' | 'html' | 'volumes' = 'file', volumes = 0): void {
     elements.manifestDropZone?.classList.add('hidden');
 
     if (elements.manifestStatus) {
@@ -1072,6 +1513,7 @@ declare const t: TranslationFunction;
         bundle: t('manifest_loaded_bundle'),
         embedded: t('manifest_loaded_embedded'),
         html: t('manifest_loaded_html'),
+        volumes: t('manifest_loaded_volumes', volumes),
       };
       const sourceLabel = sourceLabels[source] || t('loaded');
       elements.manifestStatus.innerHTML = `
@@ -1105,13 +1547,17 @@ declare const t: TranslationFunction;
   function setupButtons(): void {
     elements.recoverBtn?.addEventListener('click', startRecovery);
     elements.downloadAllBtn?.addEventListener('click', downloadAll);
+    elements.downloadFileBtn?.addEventListener('click', downloadFile);
+    elements.selfTestBtn?.addEventListener('click', runSelfTest);
   }
 
   function checkRecoverReady(): void {
-    const ready = state.manifest !== null && (
-      (state.threshold > 0 && state.shares.length >= state.threshold) ||
-      (state.threshold === 0 && state.shares.length >= 2)
-    );
+    const count = usableShares().length;
+    const groups = groupProgress();
+    const ready = state.manifest !== null && (groups ? groups.complete >= groups.needed : (
+      (state.threshold > 0 && count >= state.threshold) ||
+      (state.threshold === 0 && count >= 2)
+    ));
 
     if (elements.recoverBtn) {
       elements.recoverBtn.disabled = !ready;
@@ -1131,8 +1577,65 @@ declare const t: TranslationFunction;
   // Recovery Process
   // ============================================
 
+  // Owners can set review and expiry dates on their bundles. Past the review
+  // date, recovery goes ahead with a warning; past expiry, it waits until the
+  // family chooses to use these pieces anyway.
+  function checkSunset(): boolean {
+    const today = new Date().toISOString().slice(0, 10);
+    const passed = (dates: (string | undefined)[]): string | undefined =>
+      dates.filter((d): d is string => !!d && d < today).sort()[0];
+    const shares = usableShares();
+
+    const expired = passed(shares.map(s => s.expires));
+    if (expired && !state.expiryAccepted) {
+      toast.error(
+        t('sunset_expired_title'),
+        t('sunset_expired_message', expired),
+        t('sunset_expired_guidance'),
+        [
+          {
+            id: 'use-anyway', label: t('action_use_anyway'), primary: true, onClick: () => {
+              state.expiryAccepted = true;
+              startRecovery();
+            }
+          }
+        ]
+      );
+      return false;
+    }
+
+    const review = passed(shares.map(s => s.reviewBy));
+    if (review && !expired && !state.reviewWarned) {
+      state.reviewWarned = true;
+      toast.warning(t('sunset_review_title'), t('sunset_review_message', review), t('sunset_review_guidance'));
+    }
+    return true;
+  }
+
+  // An archive sealed for a fast computer can need more memory to unlock
+  // than a phone gives a page. Running out partway would stop the recovery
+  // tool mid-way, so ask before trying and say what the device is missing.
+  function checkMemory(): boolean {
+    if (!state.manifest) return true;
+    const result = window.rememoryCheckMemory(state.manifest);
+    if (result.error || result.ok !== false) return true; // decrypting explains a damaged archive better
+
+    toast.error(
+      t('error_memory_title'),
+      t('error_memory_message', formatSize(result.bytes || 0)),
+      t('error_memory_guidance'),
+      [
+        { id: 'cli', label: t('action_use_cli'), onClick: () => window.open('https://github.com/eljojo/rememory', '_blank') }
+      ]
+    );
+    setStatus(t('error_memory_status'), 'error');
+    return false;
+  }
+
   async function startRecovery(): Promise<void> {
     if (state.recovering) return;
+    if (!checkSunset()) return;
+    if (!checkMemory()) return;
     state.recovering = true;
 
     collapseInputSteps();
@@ -1142,16 +1645,27 @@ declare const t: TranslationFunction;
     if (elements.statusMessage) elements.statusMessage.className = 'status-message';
     if (elements.filesList) elements.filesList.innerHTML = '';
     elements.downloadActions?.classList.add('hidden');
+    elements.downloadFileBtn?.classList.add('hidden');
+    elements.downloadAllBtn?.classList.replace('btn-secondary', 'btn-success');
+    elements.vaultViewer?.classList.add('hidden');
+    elements.seedViewer?.classList.add('hidden');
+    elements.estateViewer?.classList.add('hidden');
 
     try {
       setProgress(10);
       setStatus(t('combining'));
 
-      const sharesForCombine: ShareInput[] = state.shares.map(s => ({
+      const sharesForCombine: ShareInput[] = usableShares().map(s => ({
         version: s.version,
         index: s.index,
         threshold: s.threshold,
-        dataB64: s.dataB64
+        group: s.group,
+        groups: s.groups,
+        groupsNeeded: s.groupsNeeded,
+        dataB64: s.dataB64,
+        format: s.format,
+        bundleId: s.bundleId,
+        mac: s.mac
       }));
 
       const combineResult = window.rememoryCombineShares(sharesForCombine);
@@ -1159,11 +1673,33 @@ declare const t: TranslationFunction;
         throw new Error(combineResult.error || 'Failed to combine shares');
       }
 
-      const passphrase = combineResult.passphrase;
+      let passphrase = combineResult.passphrase;
       setProgress(30);
 
-      setStatus(t('decrypting'));
-      const decryptResult = window.rememoryDecryptManifest(state.manifest!, passphrase);
+      if (state.timelock) {
+        const opener = await waitOutTimelock(state.timelock, passphrase);
+        passphrase.fill(0);
+        passphrase = opener;
+      }
+
+      // Pieces from a seal tuned to a recovery-time budget say how hard
+      // scrypt is, so the wait can be estimated for this device
+      const workFactor = usableShares().find(s => s.workFactor)?.workFactor;
+      const estimate = workFactor ? window.rememoryEstimateUnlock(workFactor) : undefined;
+      if (estimate?.seconds && estimate.seconds >= 2) {
+        setStatus(t('decrypting_estimate', Math.round(estimate.seconds)));
+        await new Promise(resolve => setTimeout(resolve, 50)); // let the estimate show before the page is busy
+      } else {
+        setStatus(t('decrypting'));
+      }
+      let decryptResult = window.rememoryDecryptManifest(state.manifest!, passphrase);
+      // Duress pieces open the decoy instead; nothing on the page says so,
+      // in case someone is watching
+      if ((decryptResult.error || !decryptResult.data) && state.decoy) {
+        const decoyResult = window.rememoryDecryptManifest(state.decoy, passphrase);
+        if (!decoyResult.error && decoyResult.data) decryptResult = decoyResult;
+      }
+      passphrase.fill(0);
       if (decryptResult.error || !decryptResult.data) {
         throw new Error(decryptResult.error || 'Failed to decrypt');
       }
@@ -1172,7 +1708,25 @@ declare const t: TranslationFunction;
 
       state.decryptedArchive = decryptResult.data;
 
-      setStatus(t('reading'));
+      // The archive's index lists its files before they are unpacked
+      const listResult = window.rememoryListTarGz(decryptResult.data);
+      if (!listResult.error && listResult.files) {
+        listResult.files.forEach(entry => {
+          const item = document.createElement('div');
+          item.className = 'file-item';
+          item.innerHTML = `
+            <span class="icon">&#128196;</span>
+            <span class="name">${escapeHtml(entry.path)}</span>
+            <span class="size">${formatSize(entry.size)}</span>
+          `;
+          elements.filesList?.appendChild(item);
+        });
+        setStatus(t('reading_listed', listResult.files.length));
+        await new Promise(resolve => setTimeout(resolve, 50)); // let the list show before the page is busy
+      } else {
+        setStatus(t('reading'));
+      }
+
       const extractResult = window.rememoryExtractTarGz(decryptResult.data);
       if (extractResult.error || !extractResult.files) {
         throw new Error(extractResult.error || 'Failed to extract');
@@ -1181,30 +1735,107 @@ declare const t: TranslationFunction;
       setProgress(90);
 
       const files = extractResult.files;
+      if (elements.filesList) elements.filesList.innerHTML = '';
 
       files.forEach(file => {
         const item = document.createElement('div');
-        item.className = 'file-item';
-        item.innerHTML = `
+        const corrupted = file.status === 'corrupted';
+        item.className = 'file-item' + (file.dir ? ' folder' : '') + (corrupted ? ' corrupted' : '');
+        item.innerHTML = file.dir ? `
+          <span class="icon">&#128193;</span>
+          <span class="name">${escapeHtml(file.name)}</span>
+          <span class="size">${t('empty_folder')}</span>
+        ` : file.link ? `
+          <span class="icon">&#128279;</span>
+          <span class="name">${escapeHtml(file.name)}</span>
+          <span class="size">&rarr; ${escapeHtml(file.link)}</span>
+        ` : `
           <span class="icon">&#128196;</span>
           <span class="name">${escapeHtml(file.name)}</span>
+          ${corrupted ? `<span class="flag">${t('file_corrupted')}</span>` : ''}
           <span class="size">${formatSize(file.data.length)}</span>
         `;
         elements.filesList?.appendChild(item);
       });
 
+      // A file the index lists but the archive doesn't have isn't among the files
+      const missing = (extractResult.index ?? []).filter(c => c.status === 'missing');
+      missing.forEach(entry => {
+        const item = document.createElement('div');
+        item.className = 'file-item missing';
+        item.innerHTML = `
+          <span class="icon">&#128196;</span>
+          <span class="name">${escapeHtml(entry.path)}</span>
+          <span class="flag">${t('file_missing')}</span>
+          <span class="size">${formatSize(entry.size)}</span>
+        `;
+        elements.filesList?.appendChild(item);
+      });
+      const problems = missing.length + files.filter(f => f.status === 'corrupted').length;
+
+      // A single file can be saved as it is, without unpacking an archive
+      const regular = files.filter(f => !f.dir && !f.link);
+      if (regular.length === 1 && elements.downloadFileBtn) {
+        state.singleFile = regular[0];
+        const label = elements.downloadFileBtn.querySelector('.label');
+        if (label) label.textContent = t('download_file', baseName(regular[0].name));
+        elements.downloadFileBtn.classList.remove('hidden');
+        elements.downloadAllBtn?.classList.replace('btn-success', 'btn-secondary');
+      }
+
+      // A sealed password vault is shown as a list, not just as passwords.json
+      const vault = findVault(regular);
+      if (vault && elements.vaultViewer) {
+        renderVault(elements.vaultViewer, vault);
+        elements.vaultViewer.classList.remove('hidden');
+      }
+      const seed = findSeed(regular);
+      if (seed && elements.seedViewer) {
+        renderSeed(elements.seedViewer, seed);
+        elements.seedViewer.classList.remove('hidden');
+      }
+      const estate = findEstate(regular);
+      if (estate && elements.estateViewer) {
+        renderEstate(elements.estateViewer, estate);
+        elements.estateViewer.classList.remove('hidden');
+      }
+
       setProgress(100);
-      setStatus(t('complete', files.length), 'success');
+      if (problems > 0) {
+        setStatus(t('complete_with_problems', regular.length, problems), 'warning');
+      } else if (regular.length === 0) {
+        setStatus(t('complete_folders_only', files.length), 'success');
+      } else {
+        setStatus(t('complete', regular.length), 'success');
+      }
       elements.downloadActions?.classList.remove('hidden');
       elements.recoverBtn?.classList.add('hidden');
       state.recoveryComplete = true;
 
+      // The pieces did their job; don't leave them behind in the tab
+      disableReloadProtection();
+
     } catch (err) {
       const errorMsg = (err instanceof Error) ? err.message : String(err);
 
-      if (errorMsg.includes('decrypt') || errorMsg.includes('passphrase') || errorMsg.includes('incorrect')) {
+      if (wasmGo?.exited) {
+        // Out of memory after all; the tool can't be used again until the page reloads
+        toast.error(
+          t('error_memory_title'),
+          t('error_memory_exited'),
+          t('error_memory_guidance'),
+          [
+            { id: 'reload', label: t('action_reload'), primary: true, onClick: () => window.location.reload() }
+          ]
+        );
+        setStatus(t('error_memory_status'), 'error');
+      } else if (errorMsg.includes('decrypt') || errorMsg.includes('passphrase') || errorMsg.includes('incorrect')) {
         errorHandlers.decryptionFailed(err);
         setStatus(t('error_decrypt_status'), 'error');
+      } else if (errorMsg.includes('changed since sealing')) {
+        // A piece's MAC doesn't match what the pieces combined to
+        toast.error(t('error_damaged_piece_title'), errorMsg, t('error_damaged_piece_guidance'));
+        setStatus(t('error', errorMsg), 'error');
       } else if (errorMsg.includes('extract') || errorMsg.includes('tar') || errorMsg.includes('gzip')) {
         errorHandlers.extractionFailed(err);
         setStatus(t('error_extract_status'), 'error');
@@ -1228,6 +1859,44 @@ declare const t: TranslationFunction;
     }
   }
 
+  // waitOutTimelock solves the recovery-delay puzzle a step at a time,
+  // letting the page update between steps, and returns the passphrase that
+  // opens MANIFEST.age.
+  async function waitOutTimelock(timelock: string, passphrase: Uint8Array): Promise<Uint8Array> {
+    const start = window.rememoryTimelockStart(timelock, passphrase);
+    if (start.error || start.handle === undefined) {
+      throw new Error(start.error || 'Failed to start the recovery delay');
+    }
+    const notice = t('timelock_starting', formatWait(start.delaySeconds || 0));
+    setStatus(notice);
+    const began = Date.now();
+    let count = 1000;
+    for (;;) {
+      await new Promise(resolve => setTimeout(resolve, 0));
+      const stepStart = Date.now();
+      const step = window.rememoryTimelockStep(start.handle, count);
+      if (step.error) throw new Error(step.error);
+      if (step.passphrase) return step.passphrase;
+
+      // Steps of about a quarter of a second keep the page responsive
+      const took = Math.max(Date.now() - stepStart, 1);
+      count = Math.max(1000, Math.min(count * 4, Math.round(count * 250 / took)));
+      const done = step.done || 1;
+      const total = step.total || done;
+      const left = (Date.now() - began) / 1000 * (total - done) / done;
+      setProgress(30 + Math.floor(25 * done / total));
+      setStatus(notice + ' ' + t('timelock_waiting', Math.floor(100 * done / total), formatWait(left)));
+    }
+  }
+
+  // formatWait rounds a wait for display, such as "3h 20m" or "45s".
+  function formatWait(seconds: number): string {
+    const s = Math.round(seconds);
+    if (s >= 3600) return `${Math.floor(s / 3600)}h ${Math.floor(s % 3600 / 60)}m`;
+    if (s >= 60) return `${Math.floor(s / 60)}m ${s % 60}s`;
+    return `${s}s`;
+  }
+
   function setProgress(percent: number): void {
     const fill = elements.progressBar?.querySelector('.fill') as HTMLElement | null;
     if (fill) {
@@ -1242,6 +1911,55 @@ declare const t: TranslationFunction;
     }
   }
 
+  // ============================================
+  // Self-Test
+  // ============================================
+
+  // A holder can check, long after saving this page, that it still opens a
+  // bundle on their device: the WASM opens a sample sealed by an earlier
+  // release, built into it, with nothing of theirs and nothing sent anywhere.
+  let selfTestResult: SelfTestResult | null = null;
+
+  async function runSelfTest(): Promise<void> {
+    if (!state.wasmReady) {
+      toast.warning(t('error_not_ready_title'), t('error_not_ready_message'), t('error_not_ready_guidance'));
+      return;
+    }
+    if (!elements.selfTestBtn || !elements.selfTestResult) return;
+
+    elements.selfTestBtn.disabled = true;
+    selfTestResult = null;
+    elements.selfTestResult.className = 'self-test-result';
+    elements.selfTestResult.textContent = t('selftest_running');
+    await new Promise(resolve => setTimeout(resolve, 50)); // let the message show before the page is busy
+
+    try {
+      selfTestResult = window.rememorySelfTest() || { error: t('error_memory_exited') };
+    } catch (err) {
+      selfTestResult = { error: (err instanceof Error) ? err.message : String(err) };
+    }
+    elements.selfTestBtn.disabled = false;
+    renderSelfTest();
+  }
+
+  function renderSelfTest(): void {
+    const el = elements.selfTestResult;
+    if (!el || !selfTestResult) return;
+
+    const checks = selfTestResult.checks || [];
+    const passed = !selfTestResult.error && checks.length > 0 && checks.every(c => c.ok);
+    el.className = `self-test-result ${passed ? 'passed' : 'failed'}`;
+
+    const items = checks.map(c => {
+      const detail = c.ok ? '' : `: ${escapeHtml(c.error || '')}`;
+      return `<li>${c.ok ? '&#10003;' : '&#10007;'} ${escapeHtml(t('selftest_' + c.name))}${detail}</li>`;
+    });
+    if (selfTestResult.error) {
+      items.push(`<li>&#10007; ${escapeHtml(selfTestResult.error)}</li>`);
+    }
+    el.innerHTML = `<strong>${escapeHtml(t(passed ? 'selftest_passed' : 'selftest_failed'))}</strong><ul>${items.join('')}</ul>`;
+  }
+
   // ============================================
   // Download
   // ============================================
@@ -1260,39 +1978,74 @@ declare const t: TranslationFunction;
     clearSensitiveState();
   }
 
+  // downloadFile saves the only recovered file under its own name.
+  function downloadFile(): void {
+    if (!state.singleFile) return;
+
+    const blob = new Blob([state.singleFile.data as BlobPart], { type: 'application/octet-stream' });
+    const url = URL.createObjectURL(blob);
+    const a = document.createElement('a');
+    a.href = url;
+    a.download = baseName(state.singleFile.name);
+    a.click();
+    URL.revokeObjectURL(url);
+
+    clearSensitiveState();
+  }
+
+  function baseName(path: string): string {
+    return path.split('/').filter(p => p).pop() || path;
+  }
+
   function clearSensitiveState(): void {
     state.decryptedArchive = undefined;
+    state.singleFile = undefined;
     state.manifest = null;
+    state.decoy = null;
+    state.timelock = null;
   }
 
   // ============================================
   // Word Extraction
   // ============================================
 
-  // extractWordsFromText extracts BIP39 words from text, handling:
+  // Recovery words take 25 words on the BIP39 lists, 22 on the EFF long
+  // list, and 27 on the EFF short one.
+  const SHARE_WORD_COUNTS = [22, 25, 27];
+
+  // extractWordsFromText extracts recovery words from text, handling:
   //   - Numbered two-column grids: " 1. merit   14. beef" (sorted by number)
   //   - Plain word lists: "merit often shuffle wedding"
-  // Supports Unicode letters (accented/umlauted characters like ábaco, günther).
+  // Supports Unicode letters (accented/umlauted characters like ábaco, günther)
+  // and the hyphens of some EFF words (t-shirt). Numbering restarts at 1 for
+  // each grid; when a README has a native and an English grid, the first wins.
   function extractWordsFromText(text: string): string[] {
     // Try to parse numbered format first (e.g. "1. word", "13. ábaco")
-    const numbered: { idx: number; word: string }[] = [];
-    const re = /(\d+)\.\s+([\p{L}]+)/gu;
+    let numbered = new Map<number, string>();
+    const complete = () => SHARE_WORD_COUNTS.includes(numbered.size) &&
+      [...Array(numbered.size).keys()].every(i => numbered.has(i + 1));
+    const re = /(\d+)\.\s+([\p{L}\p{M}]+(?:-[\p{L}\p{M}]+)*)/gu;
     let m;
     while ((m = re.exec(text)) !== null) {
-      numbered.push({ idx: parseInt(m[1], 10), word: m[2].toLowerCase() });
+      const n = parseInt(m[1], 10);
+      if (n === 1) {
+        if (complete()) break;
+        numbered = new Map();
+      }
+      if (!numbered.has(n) && n <= Math.max(...SHARE_WORD_COUNTS)) {
+        numbered.set(n, m[2].toLowerCase());
+      }
     }
 
-    if (numbered.length >= 25) {
-      // Sort by number to handle two-column grids correctly
-      numbered.sort((a, b) => a.idx - b.idx);
-      return numbered.map(e => e.word);
+    if (complete()) {
+      return [...numbered.keys()].sort((a, b) => a - b).map(n => numbered.get(n)!);
     }
 
     // Fallback: plain word list (no numbers)
     return text
       .toLowerCase()
       .split(/\s+/)
-      .filter(w => w.length > 0 && /^[\p{L}]+$/u.test(w));
+      .filter(w => w.length > 0 && /^[\p{L}\p{M}]+(?:-[\p{L}\p{M}]+)*$/u.test(w));
   }
 
   // ============================================
@@ -1317,6 +2070,220 @@ declare const t: TranslationFunction;
     });
   }
 
+  // ============================================
+  // Reload Protection
+  // ============================================
+
+  // When turned on, the pieces added so far are kept in sessionStorage,
+  // encrypted with a key derived from a random code shown on screen. The code
+  // is never stored, so the snapshot is useless without having seen the page.
+  const PROGRESS_STORAGE_KEY = 'rememory-progress';
+  const PROGRESS_CODE_ALPHABET = '0123456789ABCDEFGHJKMNPQRSTVWXYZ'; // Crockford base32
+  const PROGRESS_CODE_LENGTH = 16; // 80 bits
+  const PROGRESS_KDF_ITERATIONS = 200000;
+
+  interface ProgressSnapshot {
+    salt: string; // base64
+    iv: string;   // base64
+    data: string; // base64 AES-GCM ciphertext of the shares as JSON
+  }
+
+  let progressKey: CryptoKey | null = null;
+  let progressSalt: Uint8Array | null = null;
+  let progressSaving: Promise<void> = Promise.resolve();
+
+  function setupReloadProtection(): void {
+    if (!window.crypto?.subtle || !progressStorage()) {
+      elements.keepProgress?.classList.add('hidden');
+      return;
+    }
+
+    elements.keepProgressToggle?.addEventListener('change', () => {
+      if (elements.keepProgressToggle?.checked) {
+        enableReloadProtection(generateProgressCode());
+      } else {
+        disableReloadProtection();
+      }
+    });
+    elements.restoreProgressBtn?.addEventListener('click', restoreProgress);
+    elements.restoreProgressInput?.addEventListener('keydown', (e) => {
+      if (e.key === 'Enter') restoreProgress();
+    });
+    elements.restoreProgressDiscardBtn?.addEventListener('click', () => {
+      progressStorage()?.removeItem(PROGRESS_STORAGE_KEY);
+      elements.restoreProgress?.classList.add('hidden');
+    });
+
+    if (loadProgressSnapshot()) {
+      elements.restoreProgress?.classList.remove('hidden');
+    }
+  }
+
+  async function enableReloadProtection(code: string, salt?: Uint8Array): Promise<void> {
+    progressSalt = salt || crypto.getRandomValues(new Uint8Array(16));
+    progressKey = await deriveProgressKey(code, progressSalt);
+    await saveProgress();
+
+    // Only show the code once there is something it unlocks
+    if (elements.keepProgressToggle) elements.keepProgressToggle.checked = true;
+    if (elements.keepProgressCodeValue) elements.keepProgressCodeValue.textContent = formatProgressCode(code);
+    elements.keepProgressCode?.classList.remove('hidden');
+  }
+
+  function disableReloadProtection(): void {
+    progressKey = null;
+    progressSalt = null;
+    progressStorage()?.removeItem(PROGRESS_STORAGE_KEY);
+
+    if (elements.keepProgressToggle) elements.keepProgressToggle.checked = false;
+    if (elements.keepProgressCodeValue) elements.keepProgressCodeValue.textContent = '';
+    elements.keepProgressCode?.classList.add('hidden');
+  }
+
+  // saveProgress queues a snapshot of the current pieces, so an older one
+  // can never finish last and overwrite a newer one.
+  function saveProgress(): Promise<void> {
+    progressSaving = progressSaving.then(writeProgress, writeProgress);
+    return progressSaving;
+  }
+
+  async function writeProgress(): Promise<void> {
+    const key = progressKey;
+    const salt = progressSalt;
+    if (!key || !salt) return;
+
+    // The holder's own piece comes back with the page itself
+    const shares = state.shares.filter(s => !s.isHolder);
+    const iv = crypto.getRandomValues(new Uint8Array(12));
+    const plaintext = new TextEncoder().encode(JSON.stringify(shares));
+    const ciphertext = await crypto.subtle.encrypt({ name: 'AES-GCM', iv }, key, plaintext);
+
+    // Turned off while encrypting
+    if (progressKey !== key) return;
+
+    const snapshot: ProgressSnapshot = {
+      salt: bytesToBase64(salt),
+      iv: bytesToBase64(iv),
+      data: bytesToBase64(new Uint8Array(ciphertext))
+    };
+    progressStorage()?.setItem(PROGRESS_STORAGE_KEY, JSON.stringify(snapshot));
+  }
+
+  async function restoreProgress(): Promise<void> {
+    const snapshot = loadProgressSnapshot();
+    const input = elements.restoreProgressInput;
+    if (!snapshot || !input) return;
+
+    const code = normalizeProgressCode(input.value);
+    const salt = base64ToBytes(snapshot.salt);
+    let shares: ParsedShare[];
+    try {
+      const key = await deriveProgressKey(code, salt);
+      const plaintext = await crypto.subtle.decrypt(
+        { name: 'AES-GCM', iv: base64ToBytes(snapshot.iv) as BufferSource },
+        key,
+        base64ToBytes(snapshot.data) as BufferSource
+      );
+      shares = JSON.parse(new TextDecoder().decode(plaintext));
+    } catch {
+      showInlineError(input, t('restore_progress_wrong_code'));
+      return;
+    }
+    clearInlineError(input);
+    input.value = '';
+    elements.restoreProgress?.classList.add('hidden');
+
+    let added = 0;
+    for (const share of shares) {
+      if (hasShare(share)) continue;
+      if (state.shares.length === 0 || (state.threshold === 0 && share.threshold > 0)) {
+        state.threshold = share.threshold;
+        state.total = share.total;
+      }
+      state.shares.push(share);
+      added++;
+    }
+    toast.success(t('restore_progress_title'), t('restore_progress_done', added));
+
+    // Keep protecting with the same code, so it doesn't need writing down again
+    await enableReloadProtection(code, salt);
+    updateSharesUI();
+    checkRecoverReady();
+  }
+
+  function loadProgressSnapshot(): ProgressSnapshot | null {
+    const raw = progressStorage()?.getItem(PROGRESS_STORAGE_KEY);
+    if (!raw) return null;
+    try {
+      const snapshot = JSON.parse(raw) as ProgressSnapshot;
+      return snapshot.salt && snapshot.iv && snapshot.data ? snapshot : null;
+    } catch {
+      return null;
+    }
+  }
+
+  // progressStorage returns sessionStorage, or null where the browser blocks it.
+  function progressStorage(): Storage | null {
+    try {
+      return window.sessionStorage;
+    } catch {
+      return null;
+    }
+  }
+
+  async function deriveProgressKey(code: string, salt: Uint8Array): Promise<CryptoKey> {
+    const material = await crypto.subtle.importKey(
+      'raw',
+      new TextEncoder().encode(normalizeProgressCode(code)),
+      'PBKDF2',
+      false,
+      ['deriveKey']
+    );
+    return crypto.subtle.deriveKey(
+      { name: 'PBKDF2', salt: salt as BufferSource, iterations: PROGRESS_KDF_ITERATIONS, hash: 'SHA-256' },
+      material,
+      { name: 'AES-GCM', length: 256 },
+      false,
+      ['encrypt', 'decrypt']
+    );
+  }
+
+  function generateProgressCode(): string {
+    // 256 is a multiple of 32, so masking keeps every character equally likely
+    const bytes = crypto.getRandomValues(new Uint8Array(PROGRESS_CODE_LENGTH));
+    return Array.from(bytes, b => PROGRESS_CODE_ALPHABET[b & 31]).join('');
+  }
+
+  // normalizeProgressCode forgives dashes, spaces, lowercase, and the letters
+  // Crockford base32 leaves out because they look like digits.
+  function normalizeProgressCode(code: string): string {
+    return code.toUpperCase()
+      .replace(/O/g, '0')
+      .replace(/[IL]/g, '1')
+      .replace(/[^0-9A-Z]/g, '');
+  }
+
+  function formatProgressCode(code: string): string {
+    return normalizeProgressCode(code).match(/.{1,4}/g)?.join('-') || '';
+  }
+
+  function bytesToBase64(bytes: Uint8Array): string {
+    let binary = '';
+    for (let i = 0; i < bytes.length; i++) {
+      binary += String.fromCharCode(bytes[i]);
+    }
+    return btoa(binary);
+  }
+
+  function base64ToBytes(b64: string): Uint8Array {
+    const binary = atob(b64);
+    const bytes = new Uint8Array(binary.length);
+    for (let i = 0; i < binary.length; i++) {
+      bytes[i] = binary.charCodeAt(i);
+    }
+    return bytes;
+  }
+
   // ============================================
   // Global Exports
   // ============================================
@@ -1324,6 +2291,13 @@ declare const t: TranslationFunction;
   window.rememoryUpdateUI = function(): void {
     updateSharesUI();
     updateContactList();
+    if (personalization?.quiz && elements.quiz) {
+      renderQuiz(elements.quiz, personalization);
+    }
+    if (personalization?.faq && elements.faq) {
+      renderFAQ(elements.faq, personalization.faq);
+    }
+    renderSelfTest();
   };
 
   // Start
@@ -0,0 +1,43 @@
+// Catalog viewer: when a project sets a catalog in project.yml, fewer pieces
+// than the threshold open a list of what's sealed, shown while gathering.
+
+import type { Catalog, TranslationFunction } from './types';
+
+declare const t: TranslationFunction;
+
+// renderCatalog fills container with the catalog's description and file list.
+export function renderCatalog(container: HTMLElement, catalog: Catalog): void {
+  const { formatSize } = window.rememoryUtils;
+  container.innerHTML = '';
+
+  const heading = document.createElement('h3');
+  heading.textContent = t('catalog_title', catalog.project, catalog.sealed);
+  container.appendChild(heading);
+
+  const hint = document.createElement('p');
+  hint.className = 'hint';
+  hint.textContent = t('catalog_hint');
+  container.appendChild(hint);
+
+  if (catalog.description) {
+    const description = document.createElement('p');
+    description.className = 'catalog-description';
+    description.textContent = catalog.description;
+    container.appendChild(description);
+  }
+
+  const list = document.createElement('ul');
+  list.className = 'catalog-files';
+  for (const file of catalog.files) {
+    const li = document.createElement('li');
+    const name = document.createElement('span');
+    name.className = 'name';
+    name.textContent = file.path;
+    const size = document.createElement('span');
+    size.className = 'size';
+    size.textContent = formatSize(file.size);
+    li.append(name, size);
+    list.appendChild(li);
+  }
+  container.appendChild(list);
+}
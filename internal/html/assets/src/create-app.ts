@@ -4,12 +4,14 @@ import type {
   CreationState,
   BundleFile,
   GeneratedBundle,
+  PrefillData,
   TranslationFunction
 } from './types';
 
 // Translation function and language state (defined in HTML)
 declare const t: TranslationFunction;
 declare let currentLang: string;
+declare function setLanguage(lang: string): void;
 
 (function() {
   'use strict';
@@ -49,7 +51,7 @@ declare let currentLang: string;
   }
 
   // State
-  const state: CreationState & { anonymous: boolean; numShares: number } = {
+  const state: CreationState & { anonymous: boolean; numShares: number; privacy: string } = {
     projectName: generateProjectName(),
     friends: [],
     threshold: 2,
@@ -59,7 +61,8 @@ declare let currentLang: string;
     generating: false,
     generationComplete: false,
     anonymous: false,
-    numShares: 5
+    numShares: 5,
+    privacy: 'full' // 'first-names' comes from a project: the bundles list others by first name only
   };
 
   // DOM elements interface
@@ -80,6 +83,7 @@ declare let currentLang: string;
     thresholdSelect: HTMLSelectElement | null;
     thresholdSection: HTMLElement | null;
     thresholdGuidance: HTMLElement | null;
+    capacityInfo: HTMLElement | null;
     friendsValidation: HTMLElement | null;
     filesDropZone: HTMLElement | null;
     filesInput: HTMLInputElement | null;
@@ -113,6 +117,7 @@ declare let currentLang: string;
     thresholdSelect: document.getElementById('threshold-select') as HTMLSelectElement | null,
     thresholdSection: document.getElementById('threshold-section'),
     thresholdGuidance: document.getElementById('threshold-guidance'),
+    capacityInfo: document.getElementById('capacity-info'),
     friendsValidation: document.getElementById('friends-validation'),
     filesDropZone: document.getElementById('files-drop-zone'),
     filesInput: document.getElementById('files-input') as HTMLInputElement | null,
@@ -149,14 +154,55 @@ declare let currentLang: string;
     setupFiles();
     setupGenerate();
 
-    // Add initial 2 friends
-    addFriend();
-    addFriend();
+    if (window.PREFILL) {
+      applyPrefill(window.PREFILL);
+    } else {
+      // Add initial 2 friends
+      addFriend();
+      addFriend();
+    }
     updateThresholdOptions();
 
     await waitForWasm();
   }
 
+  // Apply settings baked in by `rememory html create --prefill`
+  function applyPrefill(prefill: PrefillData): void {
+    if (prefill.language) {
+      setLanguage(prefill.language);
+    }
+    if (prefill.projectName) {
+      state.projectName = prefill.projectName;
+    }
+
+    const friends = prefill.friends || [];
+    if (friends.some(f => f.language) && elements.customLanguageMode) {
+      elements.customLanguageMode.checked = true;
+      document.querySelector('.container')?.classList.add('custom-language-active');
+    }
+    friends.forEach(f => addFriend(f.name, f.contact || '', f.language || ''));
+    while (state.friends.length < 2) {
+      addFriend();
+    }
+
+    if (prefill.privacy === 'first-names') {
+      state.privacy = prefill.privacy;
+    }
+    if (prefill.anonymous) {
+      state.anonymous = true;
+      state.numShares = Math.max(2, Math.min(20, prefill.numShares || 5));
+      if (elements.numShares) elements.numShares.value = String(state.numShares);
+      elements.modeTabs?.querySelectorAll('.mode-tab').forEach(tab => {
+        tab.classList.toggle('active', (tab as HTMLElement).dataset.mode === 'anonymous');
+      });
+      updateAnonymousModeUI();
+    }
+
+    if (prefill.threshold >= 2) {
+      state.threshold = prefill.threshold;
+    }
+  }
+
   // ============================================
   // Anonymous Mode
   // ============================================
@@ -233,6 +279,7 @@ declare let currentLang: string;
     return window.rememoryUtils.waitForWasm().then(() => {
       state.wasmReady = true;
       elements.wasmLoadingIndicator?.classList.add('hidden');
+      updateCapacity();
       checkGenerateReady();
     });
   }
@@ -282,6 +329,7 @@ declare let currentLang: string;
       if (project.threshold && project.threshold >= 2) {
         state.threshold = project.threshold;
       }
+      state.privacy = project.privacy === 'first-names' ? project.privacy : 'full';
 
       updateThresholdOptions();
       if (elements.yamlImport) elements.yamlImport.value = '';
@@ -299,6 +347,7 @@ declare let currentLang: string;
 
     elements.thresholdSelect?.addEventListener('change', () => {
       state.threshold = parseInt(elements.thresholdSelect?.value || '2', 10);
+      updateCapacity();
     });
   }
 
@@ -431,6 +480,43 @@ declare let currentLang: string;
       : state.friends.filter(f => f.name.trim().length > 0).length >= 2;
     elements.thresholdSection?.classList.toggle('hidden', !show);
     elements.thresholdGuidance?.classList.toggle('hidden', !show);
+    updateCapacity();
+  }
+
+  // Show how large each piece and bundle will come out while the numbers
+  // are being chosen, with a warning when the QR codes or recovery words
+  // would be harder to use
+  function updateCapacity(): void {
+    const info = elements.capacityInfo;
+    if (!info) return;
+    const n = state.anonymous ? state.numShares : state.friends.length;
+    if (!state.wasmReady || elements.thresholdSection?.classList.contains('hidden') || n < 2) {
+      info.classList.add('hidden');
+      return;
+    }
+
+    const manifestSize = state.files.reduce((sum, f) => sum + f.data.length, 0);
+    const result = window.rememoryEstimateCapacity(n, state.threshold, manifestSize);
+    if (result.error || !result.capacity) {
+      info.classList.add('hidden');
+      return;
+    }
+
+    const c = result.capacity;
+    const bundleSize = result.bundleSize || 0;
+    info.innerHTML = '';
+    const summary = document.createElement('p');
+    summary.textContent = t('capacity_summary', c.qrVersion, c.qrModules, c.compact, c.words,
+      formatSize(bundleSize), formatSize(bundleSize * n), n);
+    info.appendChild(summary);
+    for (const w of c.warnings) {
+      const warning = document.createElement('p');
+      warning.className = 'capacity-warning';
+      warning.dataset.code = w.code;
+      warning.textContent = t('capacity_warn_' + w.code, w.count);
+      info.appendChild(warning);
+    }
+    info.classList.remove('hidden');
   }
 
   // ============================================
@@ -560,6 +646,7 @@ declare let currentLang: string;
     if (state.files.length === 0) {
       elements.filesPreview?.classList.add('hidden');
       elements.filesSummary?.classList.add('hidden');
+      updateCapacity();
       return;
     }
 
@@ -588,6 +675,7 @@ declare let currentLang: string;
       elements.filesSummary.textContent = t('files_summary', state.files.length, formatSize(totalSize));
     }
     elements.filesSummary?.classList.remove('hidden');
+    updateCapacity();
   }
 
   function removeFile(index: number): void {
@@ -753,6 +841,7 @@ declare let currentLang: string;
         version: window.VERSION || 'dev',
         githubURL: window.GITHUB_URL || 'https://github.com/eljojo/rememory',
         anonymous: state.anonymous,
+        privacy: state.anonymous ? 'anonymous' : state.privacy,
         defaultLanguage: currentLang || 'en'
       };
 
@@ -856,6 +945,8 @@ declare let currentLang: string;
     yaml += `threshold: ${state.threshold}\n`;
     if (state.anonymous) {
       yaml += `anonymous: true\n`;
+    } else if (state.privacy !== 'full') {
+      yaml += `privacy: ${state.privacy}\n`;
     }
     if (currentLang && currentLang !== 'en') {
       yaml += `language: ${currentLang}\n`;
@@ -0,0 +1,89 @@
+// Estate viewer: the answers to 'rememory estate' are shown as a readable
+// document of accounts, subscriptions, devices, and wishes.
+
+import type { Estate, ExtractedFile, TranslationFunction } from './types';
+
+declare const t: TranslationFunction;
+
+const ESTATE_FORMAT = 'rememory-estate';
+
+// findEstate returns the first recovered file that holds estate answers.
+export function findEstate(files: ExtractedFile[]): Estate | null {
+  for (const file of files) {
+    if (file.dir || !file.name.endsWith('.json')) continue;
+    try {
+      const parsed = JSON.parse(new TextDecoder().decode(file.data));
+      if (parsed && parsed.format === ESTATE_FORMAT) {
+        return parsed as Estate;
+      }
+    } catch {
+      // Any other JSON file
+    }
+  }
+  return null;
+}
+
+// renderEstate fills container with one section per kind of answer. Every
+// answer is set as text, since the owner may have typed anything.
+export function renderEstate(container: HTMLElement, estate: Estate): void {
+  container.innerHTML = '';
+
+  const heading = document.createElement('h3');
+  heading.textContent = t('estate_title');
+  container.appendChild(heading);
+
+  if (estate.updated) {
+    const updated = document.createElement('p');
+    updated.className = 'hint';
+    updated.textContent = t('estate_updated', estate.updated.slice(0, 10));
+    container.appendChild(updated);
+  }
+
+  section(container, t('estate_accounts'), (estate.accounts || []).map(a =>
+    entry(a.service, [[t('estate_login'), a.login], [t('estate_wish'), a.wish], [t('estate_notes'), a.notes]])));
+  section(container, t('estate_subscriptions'), (estate.subscriptions || []).map(s =>
+    entry(s.service, [[t('estate_cost'), s.cost], [t('estate_cancel'), s.cancel], [t('estate_notes'), s.notes]])));
+  section(container, t('estate_devices'), (estate.devices || []).map(d =>
+    entry(d.name, [[t('estate_unlock'), d.unlock], [t('estate_wish'), d.wish], [t('estate_notes'), d.notes]])));
+  section(container, t('estate_wishes'), (estate.wishes || []).map(w => {
+    const item = entry(w.topic, []);
+    const text = document.createElement('p');
+    text.className = 'estate-text';
+    text.textContent = w.text;
+    item.appendChild(text);
+    return item;
+  }));
+}
+
+function section(container: HTMLElement, title: string, items: HTMLElement[]): void {
+  if (items.length === 0) return;
+  const heading = document.createElement('h4');
+  heading.className = 'estate-section';
+  heading.textContent = `${title} (${items.length})`;
+  container.appendChild(heading);
+  container.append(...items);
+}
+
+// entry is one answer: its name, then whichever of rows were filled in.
+function entry(name: string, rows: [string, string | undefined][]): HTMLElement {
+  const item = document.createElement('div');
+  item.className = 'estate-entry';
+
+  const title = document.createElement('h5');
+  title.textContent = name;
+  item.appendChild(title);
+
+  const filled = rows.filter(([, value]) => value);
+  if (filled.length > 0) {
+    const dl = document.createElement('dl');
+    for (const [label, value] of filled) {
+      const dt = document.createElement('dt');
+      dt.textContent = label;
+      const dd = document.createElement('dd');
+      dd.textContent = value!;
+      dl.append(dt, dd);
+    }
+    item.appendChild(dl);
+  }
+  return item;
+}
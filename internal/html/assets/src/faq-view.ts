@@ -0,0 +1,39 @@
+// Holder FAQ: a friend's recover.html answers the questions they're likely
+// to have, from how the project was sealed (personalization.faq), as their
+// README.txt and README.pdf do. Each answer is folded away until asked for.
+
+import type { FAQFacts, TranslationFunction } from './types';
+
+declare const t: TranslationFunction;
+
+// renderFAQ fills container with the questions, in the current language.
+export function renderFAQ(container: HTMLElement, facts: FAQFacts): void {
+  container.innerHTML = '';
+
+  const summary = document.createElement('summary');
+  summary.textContent = t('faq_title');
+  container.appendChild(summary);
+
+  // The same questions, in the same order, as faq.Build
+  const entries: [string, string][] = [
+    [t('faq_lose_q'), facts.grouped ? t('faq_lose_groups_a') : t('faq_lose_a', facts.threshold, facts.total)],
+    [t('faq_manifest_q'), facts.embedded ? t('faq_manifest_embedded_a') : t('faq_manifest_file_a')],
+    [t('faq_online_q'), facts.hosted ? t('faq_online_hosted_a', facts.recoveryUrl) : t('faq_online_a', facts.recoveryUrl)],
+    [t('faq_peek_q'), facts.catalog ? t('faq_peek_catalog_a', facts.catalog) : t('faq_peek_none_a')],
+    [t('faq_wait_q'), facts.delay ? t('faq_wait_delay_a', facts.delay) : t('faq_wait_a')],
+  ];
+  if (facts.beacon) entries.push([t('faq_current_q'), t('faq_current_a', facts.beacon, facts.fingerprint ?? '')]);
+  if (facts.pin) entries.push([t('faq_pin_q'), t('faq_pin_a')]);
+  entries.push([t('faq_others_q'), facts.listsOthers ? t('faq_others_listed_a') : t('faq_others_private_a')]);
+
+  for (const [question, answer] of entries) {
+    const item = document.createElement('details');
+    item.className = 'quiz-question';
+    const q = document.createElement('summary');
+    q.textContent = question;
+    const a = document.createElement('p');
+    a.textContent = answer;
+    item.append(q, a);
+    container.appendChild(item);
+  }
+}
@@ -0,0 +1,40 @@
+// Holder quiz: when a project sets quiz in project.yml, a friend's
+// recover.html opens with a few questions about their piece, each answer
+// folded away until asked for.
+
+import type { PersonalizationData, TranslationFunction } from './types';
+
+declare const t: TranslationFunction;
+
+// renderQuiz fills container with the questions, in the current language.
+export function renderQuiz(container: HTMLElement, personalization: PersonalizationData): void {
+  container.innerHTML = '';
+
+  const summary = document.createElement('summary');
+  summary.textContent = t('quiz_title');
+  container.appendChild(summary);
+
+  const hint = document.createElement('p');
+  hint.className = 'hint';
+  hint.textContent = t('quiz_hint');
+  container.appendChild(hint);
+
+  const names = (personalization.otherFriends || []).map(f => f.name);
+  const contact = names.length > 0 ? t('quiz_a_contact', names.join(', ')) : t('quiz_a_contact_anon');
+  const questions: [string, string][] = [
+    [t('quiz_q_pieces'), t('quiz_a_pieces', personalization.threshold)],
+    [t('quiz_q_contact'), contact],
+    [t('quiz_q_asked'), t('quiz_a_asked')],
+    [t('quiz_q_never'), t('quiz_a_never')],
+  ];
+  for (const [question, answer] of questions) {
+    const item = document.createElement('details');
+    item.className = 'quiz-question';
+    const q = document.createElement('summary');
+    q.textContent = question;
+    const a = document.createElement('p');
+    a.textContent = answer;
+    item.append(q, a);
+    container.appendChild(item);
+  }
+}
@@ -0,0 +1,70 @@
+// Seed phrase viewer: a wallet's seed phrase sealed with 'rememory seal --seed'
+// is shown with its words numbered, hidden until asked, under a warning.
+
+import type { ExtractedFile, SeedPhrase, TranslationFunction } from './types';
+
+declare const t: TranslationFunction;
+
+const SEED_FORMAT = 'rememory-seed';
+
+// findSeed returns the first recovered file that is a sealed seed phrase.
+export function findSeed(files: ExtractedFile[]): SeedPhrase | null {
+  for (const file of files) {
+    if (file.dir || !file.name.endsWith('.json')) continue;
+    try {
+      const parsed = JSON.parse(new TextDecoder().decode(file.data));
+      if (parsed && parsed.format === SEED_FORMAT && Array.isArray(parsed.words)) {
+        return parsed as SeedPhrase;
+      }
+    } catch {
+      // Any other JSON file
+    }
+  }
+  return null;
+}
+
+// renderSeed fills container with the phrase's warning and its numbered
+// words, which stay hidden until someone chooses to show them.
+export function renderSeed(container: HTMLElement, seed: SeedPhrase): void {
+  container.innerHTML = '';
+
+  const heading = document.createElement('h3');
+  heading.textContent = t('seed_title', seed.label);
+  container.appendChild(heading);
+
+  const warning = document.createElement('div');
+  warning.className = 'seed-warning';
+  for (const key of ['seed_warning', 'seed_warning_offline', 'seed_passphrase_note']) {
+    const p = document.createElement('p');
+    p.textContent = t(key);
+    warning.appendChild(p);
+  }
+  container.appendChild(warning);
+
+  const words = document.createElement('ol');
+  words.className = 'seed-words hidden';
+  for (const word of seed.words) {
+    const li = document.createElement('li');
+    li.textContent = word;
+    words.appendChild(li);
+  }
+
+  const toggle = document.createElement('button');
+  toggle.type = 'button';
+  toggle.className = 'btn btn-secondary seed-toggle';
+  toggle.textContent = t('seed_show', seed.words.length);
+  toggle.addEventListener('click', () => {
+    const show = words.classList.contains('hidden');
+    words.classList.toggle('hidden', !show);
+    toggle.textContent = show ? t('seed_hide') : t('seed_show', seed.words.length);
+  });
+
+  container.append(toggle, words);
+
+  if (seed.wordlist) {
+    const note = document.createElement('p');
+    note.className = 'seed-wordlist';
+    note.textContent = t('seed_wordlist', seed.wordlist);
+    container.appendChild(note);
+  }
+}
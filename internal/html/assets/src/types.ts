@@ -11,26 +11,54 @@ export interface ParsedShare {
   threshold: number;
   total: number;
   holder?: string;
+  group?: number;        // Group of a grouped project's piece; 0 or absent otherwise
+  groups?: number;       // Number of groups
+  groupsNeeded?: number; // Groups that must each bring their threshold of pieces
   dataB64: string;
   compact?: string;   // Compact-encoded string (e.g. RM1:2:5:3:BASE64:CHECK)
   isHolder?: boolean;  // True if this is the current user's share
+  format?: string;     // "sskr" for SSKR shares
+  reviewBy?: string;   // Review date (YYYY-MM-DD) the owner set
+  expires?: string;    // Expiry date (YYYY-MM-DD) the owner set
+  bundleId?: string;   // Bundle ID shared by every piece of one seal; empty for words and digits
+  bundle?: string;     // Bundle the piece belongs to, such as "9F3A-1C2E", from bundleId or the header its data carries
+  mac?: string;        // Authenticates the piece against the recovered secret; empty for QR codes, words, and digits
+  pinSalt?: string;    // Set while the piece is locked with a PIN
+  pinCheck?: string;
+  workFactor?: number; // scrypt log2(N) MANIFEST.age was sealed with, when a recovery-time budget tuned it
+  catalogThreshold?: number; // Pieces needed to open the catalog; 0 or absent without one
+  catalogB64?: string;       // This piece's share of the catalog key
+  vssB64?: string;           // This piece's VSS value; empty for QR codes, words, digits, and PIN pieces
 }
 
 export interface ShareInput {
   version: number;
   index: number;
   threshold: number;
+  group?: number;
+  groups?: number;
+  groupsNeeded?: number;
   dataB64: string;
+  format?: string;
+  bundleId?: string;
+  mac?: string;
 }
 
 export interface ShareParseResult {
   error?: string;
   share?: ParsedShare;
+  extra?: ParsedShare[];  // The holder's further pieces, when they hold more than one
 }
 
 export interface CombineResult {
   error?: string;
-  passphrase?: string;
+  passphrase?: Uint8Array; // Bytes, so they can be zeroed once the manifest is open
+}
+
+export interface UnlockResult {
+  error?: string;
+  dataB64?: string;
+  wrongPin?: boolean;
 }
 
 // ============================================
@@ -40,7 +68,11 @@ export interface CombineResult {
 export interface BundleExtractResult {
   error?: string;
   share?: ParsedShare;
+  extra?: ParsedShare[];  // The holder's further pieces, when they hold more than one
   manifest?: Uint8Array;
+  decoy?: Uint8Array;     // DECOY.age, when the project sealed a decoy
+  timelock?: string;      // TIMELOCK.json, when the project has a recovery delay
+  repaired?: number;      // Blocks of MANIFEST.age repaired with MANIFEST.age.parity
 }
 
 export interface BundleFile {
@@ -65,6 +97,7 @@ export interface GeneratedBundle {
 
 export interface BundleCreateResult {
   error?: string;
+  field?: string; // The setting the error is about, such as "threshold" or "friends[2].name"
   bundles?: GeneratedBundle[];
 }
 
@@ -77,14 +110,115 @@ export interface DecryptResult {
   data?: Uint8Array;
 }
 
+export interface EstimateResult {
+  error?: string;
+  seconds?: number;
+}
+
+// A recovery-delay puzzle, solved a step at a time
+export interface TimelockStartResult {
+  error?: string;
+  handle?: number;
+  total?: number;        // Squarings the puzzle takes
+  delaySeconds?: number; // How long they took on the computer that sealed
+}
+
+export interface TimelockStepResult {
+  error?: string;
+  done?: number;
+  total?: number;
+  passphrase?: Uint8Array | null; // Opens MANIFEST.age, once the puzzle is solved
+}
+
+// What a seal holds, opened with fewer pieces than the threshold
+export interface Catalog {
+  project: string;
+  description?: string;
+  sealed: string; // YYYY-MM-DD
+  files: { path: string; size: number }[];
+}
+
+export interface CatalogResult {
+  error?: string;
+  catalog?: Catalog;
+}
+
 export interface ExtractedFile {
   name: string;
   data: Uint8Array;
+  dir?: boolean;  // A folder that was empty when sealed
+  link?: string;  // Where a symlink sealed as one points; it has no data
+  status?: string;  // How it compared to the archive's index: "ok", "corrupted", or "unlisted"
+}
+
+// A password-manager export sealed with 'rememory seal --vault' (passwords.json)
+export interface Vault {
+  format: string;   // "rememory-vault"
+  version: number;
+  source: string;   // "bitwarden", "1password", "keepass", or "csv"
+  entries: VaultEntry[];
+}
+
+export interface VaultEntry {
+  title: string;
+  folder?: string;
+  username?: string;
+  password?: string;
+  urls?: string[];
+  totp?: string;    // One-time code secret or otpauth:// URL
+  notes?: string;
+  fields?: { name: string; value: string }[];
+}
+
+// A wallet's seed phrase sealed with 'rememory seal --seed' (seed-phrase.json)
+export interface SeedPhrase {
+  format: string;     // "rememory-seed"
+  version: number;
+  label: string;      // Which wallet it belongs to
+  words: string[];
+  wordlist?: string;  // e.g. "BIP39 English", when the words were checked
+}
+
+// The answers to 'rememory estate' (estate.json)
+export interface Estate {
+  format: string;     // "rememory-estate"
+  version: number;
+  updated?: string;   // RFC 3339
+  accounts?: { service: string; login?: string; wish?: string; notes?: string }[];
+  subscriptions?: { service: string; cost?: string; cancel?: string; notes?: string }[];
+  devices?: { name: string; unlock?: string; wish?: string; notes?: string }[];
+  wishes?: { topic: string; text: string }[];
 }
 
 export interface ExtractResult {
   error?: string;
   files?: ExtractedFile[];
+  index?: IndexCheck[] | null;  // Null for an archive sealed without an index
+}
+
+// A file as the archive's index lists it, and how it compared
+export interface IndexCheck {
+  path: string;
+  size: number;
+  status: string;  // "ok", "corrupted", "missing", or "unlisted"
+}
+
+export interface ListResult {
+  error?: string;
+  files?: { path: string; size: number }[];
+  indexed?: boolean;  // Whether the list came from the archive's index
+}
+
+// The page's self-test: known answers, sample pieces, and a sample archive
+export interface MemoryCheckResult {
+  error?: string;
+  ok?: boolean;
+  bytes?: number;
+}
+
+export interface SelfTestResult {
+  error?: string;
+  checks?: { name: 'kat' | 'share' | 'decrypt'; ok: boolean; error?: string }[];
 }
 
 // ============================================
@@ -106,6 +240,7 @@ export interface FriendInput {
 export interface ProjectConfig {
   name?: string;
   threshold?: number;
+  privacy?: string; // 'full', 'first-names', or 'anonymous'
   friends?: FriendInfo[];
 }
 
@@ -114,6 +249,32 @@ export interface ProjectParseResult {
   project?: ProjectConfig;
 }
 
+export interface CapacityWarning {
+  code: string;
+  count: number;
+  message: string;
+}
+
+// How large one piece comes out in each printed form (see core.Capacity)
+export interface Capacity {
+  shareBlock: number;
+  compact: number;
+  qrContent: number;
+  qrCodes: number;
+  qrVersion: number;
+  qrModules: number;
+  words: number;
+  digitGroups: number;
+  rm1: number;
+  warnings: CapacityWarning[];
+}
+
+export interface CapacityResult {
+  error?: string;
+  capacity?: Capacity;
+  bundleSize?: number;
+}
+
 // ============================================
 // Personalization Types (for recover.html)
 // ============================================
@@ -126,6 +287,74 @@ export interface PersonalizationData {
   total: number;
   language?: string;
   manifestB64?: string; // Base64-encoded MANIFEST.age (when small enough to embed)
+  shareChecksums?: Record<string, string>; // Share index → checksum of every share of the seal
+  vss?: string[]; // Feldman commitments of the seal, constant term first
+  catalogB64?: string; // Base64-encoded CATALOG.age, when the project has a catalog
+  decoyB64?: string; // Base64-encoded DECOY.age, when the project has a decoy
+  timelock?: object; // TIMELOCK.json, when the project has a recovery delay
+  quiz?: boolean; // Show a few questions about the holder's piece
+  faq?: FAQFacts; // What the holder's FAQ is answered from
+}
+
+// How the project was sealed, as the holder's FAQ needs it (faq.Facts)
+export interface FAQFacts {
+  threshold: number;
+  total: number;
+  grouped?: boolean;
+  embedded?: boolean;     // MANIFEST.age is inside recover.html
+  recoveryUrl: string;    // The recovery page the QR code opens
+  hosted?: boolean;       // recoveryUrl is the owner's own copy
+  catalog?: number;       // Pieces that open the catalog
+  delay?: string;         // The recovery delay
+  pin?: boolean;          // The holder's piece is locked with a PIN
+  listsOthers?: boolean;  // The bundle names the other holders
+  beacon?: string;        // Where the project's beacon is published; the page never fetches it
+  fingerprint?: string;   // The bundle's seal, as the beacon names it
+}
+
+// ============================================
+// Verification Types (for VERIFY.html)
+// ============================================
+
+export interface VerifyData {
+  holder: string;
+  shareIndex: number;
+  total: number;
+  sealed: string;
+  language?: string;
+  shareChecksum: string;
+  files: Record<string, string>; // File name in the bundle → "sha256:..."
+}
+
+export interface VerifyFileCheck {
+  name: string;
+  status: 'ok' | 'changed' | 'missing' | 'unexpected';
+}
+
+export interface VerifyBundleResult {
+  files: VerifyFileCheck[];
+  piece: boolean | null; // Whether the README.txt among the files holds this piece
+  error?: string;
+}
+
+// ============================================
+// Prefill Types (for maker.html)
+// ============================================
+
+export interface PrefillFriend {
+  name: string;
+  contact?: string;
+  language?: string;
+}
+
+export interface PrefillData {
+  projectName?: string;
+  threshold: number;
+  anonymous?: boolean;
+  privacy?: string; // 'full', 'first-names', or 'anonymous'
+  numShares?: number;
+  language?: string;
+  friends?: PrefillFriend[];
 }
 
 // ============================================
@@ -135,12 +364,17 @@ export interface PersonalizationData {
 export interface RecoveryState {
   shares: ParsedShare[];
   manifest: Uint8Array | null;
+  decoy: Uint8Array | null;  // DECOY.age, opened instead when the pieces are duress pieces
+  timelock: string | null;   // TIMELOCK.json, solved before MANIFEST.age opens
   threshold: number;
   total: number;
   wasmReady: boolean;
   recovering: boolean;
   recoveryComplete: boolean;
+  reviewWarned?: boolean;    // The passed review date has been pointed out
+  expiryAccepted?: boolean;  // The family chose to recover with expired pieces
   decryptedArchive?: Uint8Array;
+  singleFile?: ExtractedFile;  // The only file recovered, offered on its own
 }
 
 export interface CreationState {
@@ -189,15 +423,34 @@ declare global {
     // Recovery functions (recover.wasm)
     rememoryParseShare(content: string): ShareParseResult;
     rememoryCombineShares(shares: ShareInput[]): CombineResult;
-    rememoryDecryptManifest(manifest: Uint8Array, passphrase: string): DecryptResult;
+    rememoryUnlockShare(share: ParsedShare, pin: string): UnlockResult;
+    rememoryDecryptManifest(manifest: Uint8Array, passphrase: Uint8Array): DecryptResult;
+    rememoryEstimateUnlock(workFactor: number): EstimateResult;
+    rememoryTimelockStart(timelockJSON: string, passphrase: Uint8Array): TimelockStartResult;
+    rememoryTimelockStep(handle: number, count: number): TimelockStepResult;
+    rememoryOpenCatalog(catalogB64: string, shares: ParsedShare[]): CatalogResult;
+    rememoryCheckVSS(commitments: string[], index: number, vssB64: string): { ok: boolean; error?: string };
     rememoryExtractTarGz(data: Uint8Array): ExtractResult;
+    rememoryListTarGz(data: Uint8Array): ListResult;
+    rememoryCheckMemory(manifest: Uint8Array): MemoryCheckResult;
+    rememorySelfTest(): SelfTestResult;
     rememoryExtractBundle(zipData: Uint8Array): BundleExtractResult;
     rememoryParseCompactShare(compact: string): ShareParseResult;
-    rememoryDecodeWords(words: string[]): { data: Uint8Array; index: number; checksum: string; error?: string };
+    rememoryDecodeWords(words: string[]): { data: Uint8Array; index: number; checksum: string; bundle?: string; error?: string };
+    rememoryDecodeDigits(text: string): { data: Uint8Array; index: number; checksum: string; bundle?: string; error?: string };
+    rememoryParseSSKRShare(text: string): ShareParseResult;
+    rememoryJoinQRChunks(parts: string[]): { content: string; error?: string };
+    rememoryJoinVolumes(volumes: { name: string; data: Uint8Array }[], index: string, parity?: Uint8Array): { data?: Uint8Array; repaired?: number; error?: string };
+    rememoryRepairManifest(manifest: Uint8Array, parity: Uint8Array): { data?: Uint8Array; repaired?: number; error?: string };
+
+    // Verification functions (verify.wasm)
+    rememoryVerifyPiece(text: string): { match: boolean; error?: string };
+    rememoryVerifyBundleFiles(files: { name: string; data: Uint8Array }[]): VerifyBundleResult;
 
     // Creation functions (create.wasm)
     rememoryCreateBundles(config: BundleConfig): BundleCreateResult;
     rememoryParseProjectYAML(yaml: string): ProjectParseResult;
+    rememoryEstimateCapacity(total: number, threshold: number, manifestSize: number): CapacityResult;
 
     // Shared utilities (exposed by shared.ts)
     rememoryUtils: {
@@ -215,6 +468,12 @@ declare global {
     // Personalization data (embedded in recover.html)
     PERSONALIZATION?: PersonalizationData | null;
 
+    // Expected checksums (embedded in VERIFY.html)
+    VERIFY?: VerifyData | null;
+
+    // Pre-filled project settings (embedded in maker.html)
+    PREFILL?: PrefillData | null;
+
     // Embedded constants
     WASM_BINARY?: string;
     VERSION?: string;
@@ -227,6 +486,7 @@ declare global {
   interface GoInstance {
     importObject: WebAssembly.Imports;
     run(instance: WebAssembly.Instance): Promise<void>;
+    exited: boolean;
   }
 }
 
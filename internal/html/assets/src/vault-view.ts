@@ -0,0 +1,142 @@
+// Password vault viewer: a vault sealed with 'rememory seal --vault' is
+// shown as a readable list of entries, so nobody has to read passwords.json.
+
+import type { ExtractedFile, TranslationFunction, Vault, VaultEntry } from './types';
+
+declare const t: TranslationFunction;
+
+const VAULT_FORMAT = 'rememory-vault';
+
+// findVault returns the first recovered file that is a sealed vault.
+export function findVault(files: ExtractedFile[]): Vault | null {
+  for (const file of files) {
+    if (file.dir || !file.name.endsWith('.json')) continue;
+    try {
+      const parsed = JSON.parse(new TextDecoder().decode(file.data));
+      if (parsed && parsed.format === VAULT_FORMAT && Array.isArray(parsed.entries)) {
+        return parsed as Vault;
+      }
+    } catch {
+      // Any other JSON file
+    }
+  }
+  return null;
+}
+
+// renderVault fills container with a searchable list of the vault's entries.
+// Everything is set as text: entries come from an export and may hold
+// anything.
+export function renderVault(container: HTMLElement, vault: Vault): void {
+  container.innerHTML = '';
+
+  const heading = document.createElement('h3');
+  heading.textContent = t('vault_title', vault.entries.length);
+  container.appendChild(heading);
+
+  const hint = document.createElement('p');
+  hint.className = 'vault-hint';
+  hint.textContent = t('vault_hint');
+  container.appendChild(hint);
+
+  const search = document.createElement('input');
+  search.type = 'search';
+  search.className = 'vault-search';
+  search.placeholder = t('vault_search');
+  container.appendChild(search);
+
+  const list = document.createElement('div');
+  list.className = 'vault-entries';
+  container.appendChild(list);
+
+  const items = vault.entries.map(entry => {
+    const item = renderEntry(entry);
+    list.appendChild(item);
+    return { item, text: searchText(entry) };
+  });
+
+  search.addEventListener('input', () => {
+    const query = search.value.trim().toLowerCase();
+    for (const { item, text } of items) {
+      item.classList.toggle('hidden', query !== '' && !text.includes(query));
+    }
+  });
+}
+
+function searchText(entry: VaultEntry): string {
+  return [entry.title, entry.folder, entry.username, ...(entry.urls || [])]
+    .filter(Boolean).join(' ').toLowerCase();
+}
+
+function renderEntry(entry: VaultEntry): HTMLElement {
+  const item = document.createElement('div');
+  item.className = 'vault-entry';
+
+  const title = document.createElement('h4');
+  title.textContent = entry.title;
+  if (entry.folder) {
+    const folder = document.createElement('span');
+    folder.className = 'vault-folder';
+    folder.textContent = entry.folder;
+    title.appendChild(folder);
+  }
+  item.appendChild(title);
+
+  const rows = document.createElement('dl');
+  if (entry.username) addRow(rows, t('vault_username'), text(entry.username));
+  if (entry.password) addRow(rows, t('vault_password'), secret(entry.password));
+  for (const url of entry.urls || []) addRow(rows, t('vault_website'), link(url));
+  if (entry.totp) addRow(rows, t('vault_totp'), secret(entry.totp));
+  for (const field of entry.fields || []) addRow(rows, field.name, text(field.value));
+  if (entry.notes) {
+    const notes = text(entry.notes);
+    notes.classList.add('vault-notes');
+    addRow(rows, t('vault_notes'), notes);
+  }
+  item.appendChild(rows);
+  return item;
+}
+
+function addRow(rows: HTMLElement, label: string, value: HTMLElement): void {
+  const dt = document.createElement('dt');
+  dt.textContent = label;
+  const dd = document.createElement('dd');
+  dd.appendChild(value);
+  rows.append(dt, dd);
+}
+
+function text(value: string): HTMLElement {
+  const span = document.createElement('span');
+  span.textContent = value;
+  return span;
+}
+
+// secret hides a password until asked, in case someone is looking over
+// the shoulder of whoever is recovering.
+function secret(value: string): HTMLElement {
+  const wrap = document.createElement('span');
+  wrap.className = 'vault-secret';
+  const shown = document.createElement('code');
+  shown.textContent = '••••••••';
+  const toggle = document.createElement('button');
+  toggle.type = 'button';
+  toggle.className = 'vault-toggle';
+  toggle.textContent = t('vault_show');
+  toggle.addEventListener('click', () => {
+    const hidden = toggle.textContent === t('vault_show');
+    shown.textContent = hidden ? value : '••••••••';
+    toggle.textContent = hidden ? t('vault_hide') : t('vault_show');
+  });
+  wrap.append(shown, toggle);
+  return wrap;
+}
+
+// link makes web addresses clickable; anything else stays text.
+function link(url: string): HTMLElement {
+  if (!/^https?:\/\//i.test(url)) return text(url);
+  const a = document.createElement('a');
+  a.href = url;
+  a.target = '_blank';
+  a.rel = 'noopener noreferrer';
+  a.textContent = url;
+  return a;
+}
@@ -0,0 +1,169 @@
+// ReMemory Bundle Check - VERIFY.html, a holder's yearly check of their own bundle
+
+import type { VerifyFileCheck, TranslationFunction } from './types';
+
+// Translation function (defined in HTML)
+declare const t: TranslationFunction;
+
+(function() {
+  'use strict';
+
+  const { escapeHtml, toast, waitForWasm } = window.rememoryUtils;
+
+  const data = window.VERIFY;
+
+  // Last results, re-rendered when the language changes
+  let bundleChecks: VerifyFileCheck[] | null = null;
+  let bundlePiece: boolean | null = null;
+  let pieceMatch: boolean | null = null;
+
+  const elements = {
+    holderLine: document.getElementById('holder-line'),
+    dropZone: document.getElementById('bundle-drop-zone'),
+    fileInput: document.getElementById('bundle-file-input') as HTMLInputElement | null,
+    bundleResults: document.getElementById('bundle-results'),
+    pieceInput: document.getElementById('piece-input') as HTMLTextAreaElement | null,
+    pieceCheckBtn: document.getElementById('piece-check-btn') as HTMLButtonElement | null,
+    pieceResult: document.getElementById('piece-result'),
+    wasmLoadingIndicator: document.getElementById('wasm-loading-indicator')
+  };
+
+  async function init(): Promise<void> {
+    setupDropZone();
+    elements.pieceCheckBtn?.addEventListener('click', checkPiece);
+    window.rememoryUpdateUI = render;
+    render();
+
+    try {
+      await waitForWasm();
+      elements.wasmLoadingIndicator?.classList.add('hidden');
+    } catch {
+      // The loader in the page already shows the failure
+    }
+  }
+
+  // ============================================
+  // Checks
+  // ============================================
+
+  async function checkBundleFiles(files: FileList | File[]): Promise<void> {
+    if (!window.rememoryReady) return;
+
+    const inputs = await Promise.all(Array.from(files).map(async f => ({
+      name: f.name,
+      data: new Uint8Array(await f.arrayBuffer())
+    })));
+
+    const result = window.rememoryVerifyBundleFiles(inputs);
+    if (result.error) {
+      toast.error(t('error_title'), result.error);
+      return;
+    }
+    bundleChecks = result.files;
+    bundlePiece = result.piece;
+    render();
+  }
+
+  function checkPiece(): void {
+    if (!window.rememoryReady || !elements.pieceInput) return;
+    const text = elements.pieceInput.value.trim();
+    if (!text) return;
+
+    const result = window.rememoryVerifyPiece(text);
+    if (result.error) {
+      toast.error(t('error_title'), result.error, t('piece_bad'));
+      pieceMatch = null;
+    } else {
+      pieceMatch = result.match;
+    }
+    render();
+  }
+
+  // ============================================
+  // Rendering
+  // ============================================
+
+  function render(): void {
+    if (elements.holderLine && data) {
+      elements.holderLine.textContent = t('holder_line', data.holder, data.shareIndex, data.total, data.sealed);
+    }
+
+    if (elements.bundleResults) {
+      elements.bundleResults.innerHTML = '';
+      if (bundleChecks) {
+        bundleChecks.forEach(check => {
+          elements.bundleResults?.appendChild(resultItem(check.status === 'ok', check.name, t('status_' + check.status)));
+        });
+        if (bundlePiece !== null) {
+          elements.bundleResults.appendChild(resultItem(bundlePiece, t('your_piece'), bundlePiece ? t('piece_ok') : t('piece_bad')));
+        }
+        const allOk = bundleChecks.every(c => c.status === 'ok') && bundlePiece !== false;
+        elements.bundleResults.appendChild(summary(allOk));
+      }
+    }
+
+    if (elements.pieceResult) {
+      elements.pieceResult.innerHTML = '';
+      if (pieceMatch !== null) {
+        elements.pieceResult.appendChild(resultItem(pieceMatch, t('your_piece'), pieceMatch ? t('piece_ok') : t('piece_bad')));
+      }
+    }
+  }
+
+  function resultItem(ok: boolean, name: string, meta: string): HTMLElement {
+    const item = document.createElement('div');
+    item.className = `share-item ${ok ? 'valid' : 'invalid'}`;
+    item.innerHTML = `
+      <span class="icon">${ok ? '&#9989;' : '&#10060;'}</span>
+      <div class="details">
+        <div class="name">${escapeHtml(name)}</div>
+        <div class="meta">${escapeHtml(meta)}</div>
+      </div>
+    `;
+    return item;
+  }
+
+  function summary(ok: boolean): HTMLElement {
+    const p = document.createElement('p');
+    p.className = `status-message ${ok ? 'success' : 'error'}`;
+    p.textContent = ok ? t('all_ok') : t('some_bad');
+    return p;
+  }
+
+  // ============================================
+  // Drop Zone
+  // ============================================
+
+  function setupDropZone(): void {
+    const { dropZone, fileInput } = elements;
+    if (!dropZone || !fileInput) return;
+
+    dropZone.addEventListener('click', () => fileInput.click());
+
+    dropZone.addEventListener('dragover', (e) => {
+      e.preventDefault();
+      dropZone.classList.add('dragover');
+    });
+
+    dropZone.addEventListener('dragleave', () => {
+      dropZone.classList.remove('dragover');
+    });
+
+    dropZone.addEventListener('drop', (e) => {
+      e.preventDefault();
+      dropZone.classList.remove('dragover');
+      if (e.dataTransfer?.files) {
+        checkBundleFiles(e.dataTransfer.files);
+      }
+    });
+
+    fileInput.addEventListener('change', () => {
+      if (fileInput.files) {
+        checkBundleFiles(fileInput.files);
+      }
+      fileInput.value = '';
+    });
+  }
+
+  document.addEventListener('DOMContentLoaded', init);
+})();
@@ -1,17 +1,40 @@
 package html
 
 import (
+	"encoding/json"
 	"strings"
 
 	"github.com/eljojo/rememory/internal/translations"
 )
 
+// PrefillFriend is a friend entry pre-filled into maker.html.
+type PrefillFriend struct {
+	Name     string `json:"name"`
+	Contact  string `json:"contact,omitempty"`
+	Language string `json:"language,omitempty"`
+}
+
+// PrefillData holds project settings baked into maker.html so the creation
+// ceremony can run later (for example, on an air-gapped machine) without
+// retyping the friend roster.
+type PrefillData struct {
+	ProjectName string          `json:"projectName,omitempty"`
+	Threshold   int             `json:"threshold"`
+	Anonymous   bool            `json:"anonymous,omitempty"`
+	Privacy     string          `json:"privacy,omitempty"`   // What bundles tell about the friends; see project.Privacy
+	NumShares   int             `json:"numShares,omitempty"` // Only used in anonymous mode
+	Language    string          `json:"language,omitempty"`  // Default UI and bundle language
+	Friends     []PrefillFriend `json:"friends,omitempty"`
+}
+
 // GenerateMakerHTML creates the complete maker.html with all assets embedded.
 // createWASMBytes is the create.wasm binary (runs in browser for bundle creation).
 // Note: create.wasm self-contains recover.wasm embedded within it (via html.GetRecoverWASMBytes()).
 // version is the rememory version string.
 // githubURL is the URL to download CLI binaries.
-func GenerateMakerHTML(createWASMBytes []byte, version, githubURL string) string {
+// prefill is optional; when provided, the page starts with those settings filled in.
+// custom is optional; see Customization.
+func GenerateMakerHTML(createWASMBytes []byte, version, githubURL string, prefill *PrefillData, custom *Customization) string {
 	html := makerHTMLTemplate
 
 	// Embed translations
@@ -39,6 +62,16 @@ func GenerateMakerHTML(createWASMBytes []byte, version, githubURL string) string
 	html = strings.Replace(html, "{{VERSION}}", version, -1)
 	html = strings.Replace(html, "{{GITHUB_URL}}", githubURL, -1)
 
+	// Embed prefill data as JSON (or null if not provided)
+	prefillJSON := "null"
+	if prefill != nil {
+		data, _ := json.Marshal(prefill)
+		prefillJSON = string(data)
+	}
+	html = strings.Replace(html, "{{PREFILL_DATA}}", prefillJSON, 1)
+
+	html = applyCustomization(html, custom)
+
 	// Apply CSP nonce to all script tags
 	html = applyCSPNonce(html)
 
@@ -3,6 +3,7 @@ package html
 import (
 	"crypto/rand"
 	"encoding/base64"
+	"regexp"
 	"strings"
 )
 
@@ -20,3 +21,21 @@ func applyCSPNonce(html string) string {
 	nonce := generateCSPNonce()
 	return strings.ReplaceAll(html, "{{CSP_NONCE}}", nonce)
 }
+
+// noncePattern finds a page's nonce in its Content-Security-Policy.
+var noncePattern = regexp.MustCompile(`'nonce-([A-Za-z0-9+/=]+)'`)
+
+// KeepNonce gives page the nonce of previous, an earlier build of the same
+// page, so a page that hasn't otherwise changed comes out byte for byte the
+// same. page is returned as it is when either one has no nonce.
+func KeepNonce(page string, previous []byte) string {
+	fresh := noncePattern.FindStringSubmatch(page)
+	old := noncePattern.FindSubmatch(previous)
+	if fresh == nil || old == nil {
+		return page
+	}
+	return strings.NewReplacer(
+		"'nonce-"+fresh[1]+"'", "'nonce-"+string(old[1])+"'",
+		`nonce="`+fresh[1]+`"`, `nonce="`+string(old[1])+`"`,
+	).Replace(page)
+}
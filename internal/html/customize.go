@@ -0,0 +1,66 @@
+package html
+
+import (
+	"fmt"
+	"html"
+	"strings"
+)
+
+// MaxCustomizationSize limits each piece of custom HTML or CSS.
+const MaxCustomizationSize = 64 * 1024
+
+// Customization holds the sanctioned extension points for recover.html and
+// maker.html, so hosted copies can add context without patching the assets.
+// Header and footer are trusted HTML from the project owner; scripts are
+// refused, and the page's Content-Security-Policy would block them anyway.
+type Customization struct {
+	Banner string // Plain text shown above the page content (HTML-escaped)
+	Header string // HTML inserted at the top of the page
+	Footer string // HTML appended to the page footer
+	CSS    string // Extra styles, applied after the built-in ones
+}
+
+// Validate rejects content that could break out of its slot.
+func (c *Customization) Validate() error {
+	for _, part := range []struct{ name, value string }{
+		{"banner", c.Banner},
+		{"header", c.Header},
+		{"footer", c.Footer},
+		{"css", c.CSS},
+	} {
+		if len(part.value) > MaxCustomizationSize {
+			return fmt.Errorf("custom %s is too large (max %d KB)", part.name, MaxCustomizationSize/1024)
+		}
+		// Template placeholders such as {{CSP_NONCE}} are filled in after customization
+		if strings.Contains(part.value, "{{") {
+			return fmt.Errorf("custom %s must not contain \"{{\"", part.name)
+		}
+		lower := strings.ToLower(part.value)
+		if strings.Contains(lower, "<script") {
+			return fmt.Errorf("custom %s must not contain scripts", part.name)
+		}
+		if part.name == "css" && strings.Contains(lower, "</style") {
+			return fmt.Errorf("custom css must not contain \"</style\"")
+		}
+	}
+	return nil
+}
+
+// applyCustomization fills the CUSTOM_* slots, leaving them empty when c is nil.
+func applyCustomization(page string, c *Customization) string {
+	var css, header, footer string
+	if c != nil {
+		if c.CSS != "" {
+			css = "<style>" + c.CSS + "</style>"
+		}
+		if c.Banner != "" {
+			header = `<div class="custom-banner">` + html.EscapeString(c.Banner) + "</div>\n"
+		}
+		header += c.Header
+		footer = c.Footer
+	}
+	page = strings.Replace(page, "{{CUSTOM_CSS}}", css, 1)
+	page = strings.Replace(page, "{{CUSTOM_HEADER}}", header, 1)
+	page = strings.Replace(page, "{{CUSTOM_FOOTER}}", footer, 1)
+	return page
+}
@@ -0,0 +1,25 @@
+//go:build !js
+
+package html
+
+import (
+	_ "embed"
+)
+
+// Embedded assets for the holder's VERIFY.html. Only the CLI makes these
+// pages, so they stay out of the WASM builds.
+
+//go:embed assets/verify.html
+var verifyHTMLTemplate string
+
+//go:embed assets/verify-app.js
+var verifyAppJS string
+
+//go:embed assets/verify.wasm
+var verifyWASM []byte
+
+// GetVerifyWASMBytes returns the embedded verification-only WASM binary.
+// It can check a piece and a bundle against their checksums, and nothing else.
+func GetVerifyWASMBytes() []byte {
+	return verifyWASM
+}
@@ -1,50 +1,33 @@
 package html
 
 import (
+	"bytes"
+	"compress/gzip"
 	"encoding/base64"
-	"encoding/json"
 	"fmt"
+	"io"
 	"regexp"
 )
 
-// personalizationManifest is a minimal struct for extracting just the manifest
-// from the PERSONALIZATION JSON embedded in recover.html.
-type personalizationManifest struct {
-	ManifestB64 string `json:"manifestB64"`
-}
-
-// personalizationRe matches the PERSONALIZATION JSON in recover.html.
-// The JSON is single-line (produced by json.Marshal) and appears as:
-//
-//	window.PERSONALIZATION = {...};
-var personalizationRe = regexp.MustCompile(`window\.PERSONALIZATION\s*=\s*(\{[^\n]*\})\s*;`)
+// wasmRe matches the gzip-compressed, base64-encoded WASM in recover.html.
+var wasmRe = regexp.MustCompile(`window\.WASM_BINARY\s*=\s*"([A-Za-z0-9+/=]+)"`)
 
-// ExtractManifestFromHTML extracts the MANIFEST.age bytes from a personalized
-// recover.html file. It finds the embedded PERSONALIZATION JSON, parses the
-// manifestB64 field, and base64-decodes it.
-//
-// Returns an error if the HTML doesn't contain personalization data, or if
-// the personalization doesn't include an embedded manifest (e.g., when
-// --no-embed-manifest was used or the manifest was too large).
-func ExtractManifestFromHTML(htmlContent []byte) ([]byte, error) {
-	matches := personalizationRe.FindSubmatch(htmlContent)
+// ExtractWASMFromHTML returns the WASM binary embedded in a recover.html file,
+// so it can be compared with the one built into this binary.
+func ExtractWASMFromHTML(htmlContent []byte) ([]byte, error) {
+	matches := wasmRe.FindSubmatch(htmlContent)
 	if len(matches) < 2 {
-		return nil, fmt.Errorf("no PERSONALIZATION data found in HTML")
-	}
-
-	var p personalizationManifest
-	if err := json.Unmarshal(matches[1], &p); err != nil {
-		return nil, fmt.Errorf("parsing PERSONALIZATION JSON: %w", err)
+		return nil, fmt.Errorf("no embedded WASM found in HTML")
 	}
 
-	if p.ManifestB64 == "" {
-		return nil, fmt.Errorf("no embedded manifest in HTML (manifestB64 is empty)")
+	compressed, err := base64.StdEncoding.DecodeString(string(matches[1]))
+	if err != nil {
+		return nil, fmt.Errorf("decoding WASM base64: %w", err)
 	}
-
-	data, err := base64.StdEncoding.DecodeString(p.ManifestB64)
+	gz, err := gzip.NewReader(bytes.NewReader(compressed))
 	if err != nil {
-		return nil, fmt.Errorf("decoding manifest base64: %w", err)
+		return nil, fmt.Errorf("decompressing WASM: %w", err)
 	}
-
-	return data, nil
+	defer gz.Close()
+	return io.ReadAll(gz)
 }
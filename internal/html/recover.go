@@ -7,6 +7,7 @@ import (
 	"encoding/json"
 	"strings"
 
+	"github.com/eljojo/rememory/internal/faq"
 	"github.com/eljojo/rememory/internal/translations"
 )
 
@@ -24,13 +25,21 @@ const MaxEmbeddedManifestSize = 5 << 20 // 5 MiB
 
 // PersonalizationData holds the data to personalize recover.html for a specific friend.
 type PersonalizationData struct {
-	Holder       string       `json:"holder"`                // This friend's name
-	HolderShare  string       `json:"holderShare"`           // This friend's encoded share
-	OtherFriends []FriendInfo `json:"otherFriends"`          // List of other friends
-	Threshold    int          `json:"threshold"`             // Required shares (K)
-	Total        int          `json:"total"`                 // Total shares (N)
-	Language     string       `json:"language,omitempty"`    // Default UI language for this friend
-	ManifestB64  string       `json:"manifestB64,omitempty"` // Base64-encoded MANIFEST.age (when <= MaxEmbeddedManifestSize)
+	Holder         string          `json:"holder"`                   // This friend's name
+	HolderShare    string          `json:"holderShare"`              // This friend's encoded share
+	OtherFriends   []FriendInfo    `json:"otherFriends"`             // List of other friends
+	Threshold      int             `json:"threshold"`                // Required shares (K)
+	Total          int             `json:"total"`                    // Total shares (N)
+	Language       string          `json:"language,omitempty"`       // Default UI language for this friend
+	ManifestB64    string          `json:"manifestB64,omitempty"`    // Base64-encoded MANIFEST.age (when <= MaxEmbeddedManifestSize)
+	Crypto         string          `json:"crypto,omitempty"`         // Crypto profile the recovery must stay within
+	ShareChecksums map[int]string  `json:"shareChecksums,omitempty"` // Checksum of every share of the seal, by index
+	VSS            []string        `json:"vss,omitempty"`            // Feldman commitments to the pieces' verifiable split, constant term first
+	CatalogB64     string          `json:"catalogB64,omitempty"`     // Base64-encoded CATALOG.age, when the project has a catalog
+	DecoyB64       string          `json:"decoyB64,omitempty"`       // Base64-encoded DECOY.age, when the project has a decoy
+	Timelock       json.RawMessage `json:"timelock,omitempty"`       // TIMELOCK.json, when the project has a recovery delay
+	Quiz           bool            `json:"quiz,omitempty"`           // Show holders a few questions about their piece
+	FAQ            *faq.Facts      `json:"faq,omitempty"`            // What the page's FAQ is answered from; nil leaves it out
 }
 
 // GenerateRecoverHTML creates the complete recover.html with all assets embedded.
@@ -38,7 +47,8 @@ type PersonalizationData struct {
 // version is the rememory version string.
 // githubURL is the URL to download CLI binaries.
 // personalization can be nil for a generic recover.html, or provided to personalize for a specific friend.
-func GenerateRecoverHTML(wasmBytes []byte, version, githubURL string, personalization *PersonalizationData) string {
+// custom is optional; see Customization.
+func GenerateRecoverHTML(wasmBytes []byte, version, githubURL string, personalization *PersonalizationData, custom *Customization) string {
 	html := recoverHTMLTemplate
 
 	// Embed translations
@@ -75,6 +85,8 @@ func GenerateRecoverHTML(wasmBytes []byte, version, githubURL string, personaliz
 	}
 	html = strings.Replace(html, "{{PERSONALIZATION_DATA}}", personalizationJSON, 1)
 
+	html = applyCustomization(html, custom)
+
 	// Apply CSP nonce to all script tags
 	html = applyCSPNonce(html)
 
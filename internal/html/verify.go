@@ -0,0 +1,42 @@
+//go:build !js
+
+package html
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/eljojo/rememory/internal/translations"
+)
+
+// VerifyData is what VERIFY.html knows about one friend's bundle. It holds
+// checksums only: never the piece, the passphrase, or the manifest.
+type VerifyData struct {
+	Holder        string            `json:"holder"`
+	ShareIndex    int               `json:"shareIndex"`
+	Total         int               `json:"total"`
+	Sealed        string            `json:"sealed"`             // Date the project was sealed, e.g. "2026-02-13"
+	Language      string            `json:"language,omitempty"` // Default UI language for this friend
+	ShareChecksum string            `json:"shareChecksum"`      // "sha256:..." of the raw piece
+	Files         map[string]string `json:"files"`              // File name in the bundle → "sha256:..."
+}
+
+// GenerateVerifyHTML creates a friend's VERIFY.html with all assets embedded.
+// wasmBytes should be the compiled verify.wasm binary.
+func GenerateVerifyHTML(wasmBytes []byte, version string, data *VerifyData) string {
+	html := verifyHTMLTemplate
+
+	html = strings.Replace(html, "{{TRANSLATIONS}}", translations.GetTranslationsJS("verify"), 1)
+	html = strings.Replace(html, "{{LANG_OPTIONS}}", translations.LangSelectOptions(), 1)
+	html = strings.Replace(html, "{{LANG_DETECT}}", translations.LangDetectJS(), 1)
+	html = strings.Replace(html, "{{STYLES}}", stylesCSS, 1)
+	html = strings.Replace(html, "{{WASM_EXEC}}", wasmExecJS, 1)
+	html = strings.Replace(html, "{{APP_JS}}", sharedJS+"\n"+verifyAppJS, 1)
+	html = strings.Replace(html, "{{WASM_BASE64}}", compressAndEncode(wasmBytes), 1)
+	html = strings.Replace(html, "{{VERSION}}", version, 1)
+
+	dataJSON, _ := json.Marshal(data)
+	html = strings.Replace(html, "{{VERIFY_DATA}}", string(dataJSON), 1)
+
+	return applyCSPNonce(html)
+}
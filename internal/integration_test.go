@@ -5,21 +5,27 @@ import (
 	"bytes"
 	cryptorand "crypto/rand"
 	"encoding/base64"
+	"encoding/binary"
 	"encoding/json"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
 	"time"
 
+	"golang.org/x/text/unicode/norm"
+
 	"github.com/eljojo/rememory/internal/bundle"
 	"github.com/eljojo/rememory/internal/core"
 	"github.com/eljojo/rememory/internal/crypto"
 	"github.com/eljojo/rememory/internal/html"
 	"github.com/eljojo/rememory/internal/manifest"
 	"github.com/eljojo/rememory/internal/project"
+	"github.com/eljojo/rememory/internal/recovery"
+	"github.com/eljojo/rememory/internal/rotation"
 	"github.com/eljojo/rememory/internal/translations"
 )
 
@@ -143,7 +149,7 @@ func TestFullWorkflow(t *testing.T) {
 
 			// Extract
 			extractDir := t.TempDir()
-			extractResult, err := manifest.Extract(&decryptedBuf, extractDir)
+			extractResult, err := manifest.Extract(&decryptedBuf, extractDir, true)
 			if err != nil {
 				t.Fatalf("extracting: %v", err)
 			}
@@ -225,6 +231,39 @@ func TestWrongPassphrase(t *testing.T) {
 	}
 }
 
+func TestReadDigitsAloud(t *testing.T) {
+	groups := []string{"123456", "789012"}
+	wav, err := bundle.ReadDigitsAloud(groups, "en")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 8 kHz, 8-bit mono PCM, the data chunk running to the end
+	if len(wav) < 44 || string(wav[:4]) != "RIFF" || string(wav[8:16]) != "WAVEfmt " || string(wav[36:40]) != "data" {
+		t.Fatal("not a WAV file")
+	}
+	if rate := binary.LittleEndian.Uint32(wav[24:28]); rate != 8000 {
+		t.Errorf("sample rate %d, want 8000", rate)
+	}
+	if size := binary.LittleEndian.Uint32(wav[40:44]); int(size) != len(wav)-44 {
+		t.Errorf("data chunk says %d bytes, file has %d", size, len(wav)-44)
+	}
+	// Twelve digits read slowly, with beeps and pauses: 10 to 30 seconds
+	if seconds := (len(wav) - 44) / 8000; seconds < 10 || seconds > 30 {
+		t.Errorf("recording is %ds long", seconds)
+	}
+
+	pt, err := bundle.ReadDigitsAloud(groups, "pt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(pt, wav) {
+		t.Error("Portuguese reads the digits like English")
+	}
+	if _, err := bundle.ReadDigitsAloud([]string{"12a456"}, "en"); err == nil {
+		t.Error("read a group with a letter in it")
+	}
+}
+
 // TestLargeManifest tests with a larger payload (approaching 10MB limit)
 func TestLargeManifest(t *testing.T) {
 	if testing.Short() {
@@ -264,7 +303,7 @@ func TestLargeManifest(t *testing.T) {
 
 	// Extract and verify
 	extractDir := t.TempDir()
-	extractResult, err := manifest.Extract(&decrypted, extractDir)
+	extractResult, err := manifest.Extract(&decrypted, extractDir, true)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -659,7 +698,7 @@ func TestBundleRecovery(t *testing.T) {
 
 	// Extract
 	extractDir := t.TempDir()
-	extractResult, err := manifest.Extract(&decrypted, extractDir)
+	extractResult, err := manifest.Extract(&decrypted, extractDir, true)
 	if err != nil {
 		t.Fatalf("extracting: %v", err)
 	}
@@ -727,7 +766,7 @@ func extractManifestFromBundle(t *testing.T, bundlePath string) []byte {
 
 	// Fall back to extracting manifest from recover.html personalization data
 	if len(recoverData) > 0 {
-		manifest, err := html.ExtractManifestFromHTML(recoverData)
+		manifest, err := recovery.ExtractManifestFromHTML(recoverData)
 		if err != nil {
 			t.Fatalf("extracting manifest from recover.html: %v", err)
 		}
@@ -982,7 +1021,7 @@ func TestAnonymousBundleRecovery(t *testing.T) {
 
 	// Extract and verify
 	extractDir := t.TempDir()
-	extractResult, err := manifest.Extract(&decrypted, extractDir)
+	extractResult, err := manifest.Extract(&decrypted, extractDir, true)
 	if err != nil {
 		t.Fatalf("extracting: %v", err)
 	}
@@ -996,6 +1035,352 @@ func TestAnonymousBundleRecovery(t *testing.T) {
 	}
 }
 
+// TestFirstNamesBundleGeneration tests that first-names projects sign each
+// piece with its holder's first name and list the others without contacts.
+func TestFirstNamesBundleGeneration(t *testing.T) {
+	projectDir := filepath.Join(t.TempDir(), "test-first-names")
+	friends := []project.Friend{
+		{Name: "Alice Martin", Contact: "alice@example.com"},
+		{Name: "Bob Stone", Contact: "555-1234"},
+		{Name: "Carol Diaz", Contact: "carol@example.com"},
+	}
+	p, err := project.NewWithOptions(projectDir, "test-first-names", 2, friends, project.PrivacyFirstNames)
+	if err != nil {
+		t.Fatalf("creating project: %v", err)
+	}
+	if p.PrivacyLevel() != project.PrivacyFirstNames {
+		t.Fatalf("privacy: got %q, want %q", p.PrivacyLevel(), project.PrivacyFirstNames)
+	}
+	os.WriteFile(filepath.Join(p.ManifestPath(), "secrets.txt"), []byte("first names only"), 0644)
+
+	// Seal the project
+	var archiveBuf bytes.Buffer
+	if _, err := manifest.Archive(&archiveBuf, p.ManifestPath()); err != nil {
+		t.Fatalf("archiving: %v", err)
+	}
+	passphrase, _ := crypto.GeneratePassphrase(crypto.DefaultPassphraseBytes)
+	os.MkdirAll(p.SharesPath(), 0755)
+	manifestFile, _ := os.Create(p.ManifestAgePath())
+	core.Encrypt(manifestFile, bytes.NewReader(archiveBuf.Bytes()), passphrase)
+	manifestFile.Close()
+
+	shares, _ := core.Split([]byte(passphrase), len(p.Friends), p.Threshold)
+	shareInfos := make([]project.ShareInfo, len(p.Friends))
+	for i, data := range shares {
+		friend := p.Friends[i]
+		share := core.NewShare(1, i+1, len(p.Friends), p.Threshold, p.PrivacyLevel().Holder(friend, i+1), data)
+		os.WriteFile(p.SharePath(friend), []byte(share.Encode()), 0644)
+		shareInfos[i] = project.ShareInfo{
+			Friend:   friend.Name,
+			File:     filepath.Base(p.SharePath(friend)),
+			Checksum: share.Checksum,
+		}
+	}
+	manifestData, _ := os.ReadFile(p.ManifestAgePath())
+	p.Sealed = &project.Sealed{
+		At:               time.Now(),
+		ManifestChecksum: core.HashBytes(manifestData),
+		VerificationHash: core.HashString(passphrase),
+		Shares:           shareInfos,
+	}
+	p.Save()
+
+	cfg := bundle.Config{
+		Version:          "v1.0.0-test",
+		GitHubReleaseURL: "https://example.com",
+		WASMBytes:        []byte("fake-wasm"),
+	}
+	if err := bundle.GenerateAll(p, cfg); err != nil {
+		t.Fatalf("generating bundles: %v", err)
+	}
+
+	r, err := zip.OpenReader(filepath.Join(p.OutputPath(), "bundles", "bundle-"+core.SanitizeFilename("Alice Martin")+".zip"))
+	if err != nil {
+		t.Fatalf("opening bundle: %v", err)
+	}
+	defer r.Close()
+
+	var readmeContent string
+	for _, f := range r.File {
+		if translations.IsReadmeFile(f.Name, ".txt") {
+			rc, _ := f.Open()
+			data, _ := io.ReadAll(rc)
+			rc.Close()
+			readmeContent = string(data)
+		}
+	}
+	if readmeContent == "" {
+		t.Fatal("README file not found")
+	}
+
+	// The others are listed by first name, without surnames or contacts
+	if !strings.Contains(readmeContent, "OTHER SHARE HOLDERS") {
+		t.Error("README should list the other share holders")
+	}
+	for _, want := range []string{"Bob", "Carol"} {
+		if !strings.Contains(readmeContent, want) {
+			t.Errorf("README should mention %q", want)
+		}
+	}
+	for _, hidden := range []string{"Stone", "Diaz", "555-1234", "carol@example.com", "alice@example.com"} {
+		if strings.Contains(readmeContent, hidden) {
+			t.Errorf("README should not contain %q", hidden)
+		}
+	}
+
+	share, err := core.ParseShare([]byte(readmeContent))
+	if err != nil {
+		t.Fatalf("parsing share: %v", err)
+	}
+	if share.Holder != "Alice" {
+		t.Errorf("holder: got %q, want %q", share.Holder, "Alice")
+	}
+}
+
+// TestGroupedBundleRecovery seals a grouped project, checks the bundles spell
+// out the groups, and recovers from two family members and one lawyer.
+func TestGroupedBundleRecovery(t *testing.T) {
+	projectDir := filepath.Join(t.TempDir(), "test-groups")
+	groups := []project.Group{{Name: "family", Threshold: 2}, {Name: "lawyers", Threshold: 1}}
+	friends := []project.Friend{
+		{Name: "Alice", Group: "family"},
+		{Name: "Bob", Group: "family"},
+		{Name: "Carol", Group: "family"},
+		{Name: "Dave", Group: "lawyers"},
+		{Name: "Erin", Group: "lawyers"},
+	}
+	p, err := project.NewGrouped(projectDir, "test-groups", 2, groups, friends, project.PrivacyFull)
+	if err != nil {
+		t.Fatalf("creating project: %v", err)
+	}
+	content := []byte("family and lawyers")
+	os.WriteFile(filepath.Join(p.ManifestPath(), "secrets.txt"), content, 0644)
+
+	// Seal the project
+	var archiveBuf bytes.Buffer
+	if _, err := manifest.Archive(&archiveBuf, p.ManifestPath()); err != nil {
+		t.Fatalf("archiving: %v", err)
+	}
+	passphrase, _ := crypto.GeneratePassphrase(crypto.DefaultPassphraseBytes)
+	os.MkdirAll(p.SharesPath(), 0755)
+	manifestFile, _ := os.Create(p.ManifestAgePath())
+	core.Encrypt(manifestFile, bytes.NewReader(archiveBuf.Bytes()), passphrase)
+	manifestFile.Close()
+
+	pieces, err := core.SplitGroups([]byte(passphrase), p.Threshold, p.GroupSpecs())
+	if err != nil {
+		t.Fatalf("splitting: %v", err)
+	}
+	taken := make([]int, len(groups))
+	shareInfos := make([]project.ShareInfo, len(p.Friends))
+	for i, friend := range p.Friends {
+		g := p.GroupIndex(friend)
+		share := core.NewShare(1, i+1, len(p.Friends), groups[g-1].Threshold, friend.Name, pieces[g-1][taken[g-1]])
+		share.Group, share.Groups, share.GroupsNeeded = g, len(groups), p.Threshold
+		taken[g-1]++
+		os.WriteFile(p.SharePath(friend), []byte(share.Encode()), 0644)
+		shareInfos[i] = project.ShareInfo{
+			Friend:   friend.Name,
+			File:     filepath.Base(p.SharePath(friend)),
+			Checksum: share.Checksum,
+		}
+	}
+	manifestData, _ := os.ReadFile(p.ManifestAgePath())
+	p.Sealed = &project.Sealed{
+		At:               time.Now(),
+		ManifestChecksum: core.HashBytes(manifestData),
+		VerificationHash: core.HashString(passphrase),
+		Shares:           shareInfos,
+	}
+	p.Save()
+
+	cfg := bundle.Config{
+		Version:          "v1.0.0-test",
+		GitHubReleaseURL: "https://example.com",
+		WASMBytes:        []byte("fake-wasm"),
+	}
+	if err := bundle.GenerateAll(p, cfg); err != nil {
+		t.Fatalf("generating bundles: %v", err)
+	}
+
+	bundlePath := func(name string) string {
+		return filepath.Join(p.OutputPath(), "bundles", "bundle-"+core.SanitizeFilename(name)+".zip")
+	}
+	r, err := zip.OpenReader(bundlePath("Dave"))
+	if err != nil {
+		t.Fatalf("opening bundle: %v", err)
+	}
+	for _, f := range r.File {
+		if translations.IsReadmeFile(f.Name, ".txt") {
+			rc, _ := f.Open()
+			data, _ := io.ReadAll(rc)
+			rc.Close()
+			for _, want := range []string{"family: 2 of 3 pieces", "lawyers: 1 of 2 pieces (your group)"} {
+				if !strings.Contains(string(data), want) {
+					t.Errorf("README should contain %q", want)
+				}
+			}
+			if strings.Contains(string(data), "RECOVERY WORDS") {
+				t.Error("README should not offer recovery words for a grouped piece")
+			}
+		}
+	}
+	r.Close()
+
+	// Three family members are one complete group, not two
+	family := []*core.Share{
+		extractShareFromBundle(t, bundlePath("Alice")),
+		extractShareFromBundle(t, bundlePath("Bob")),
+		extractShareFromBundle(t, bundlePath("Carol")),
+	}
+	if err := recovery.CheckCompatible(family); err == nil || !strings.Contains(err.Error(), "need 2 complete groups") {
+		t.Errorf("expected a missing-group error, got %v", err)
+	}
+
+	shares := []*core.Share{family[1], family[2], extractShareFromBundle(t, bundlePath("Dave"))}
+	if err := recovery.CheckCompatible(shares); err != nil {
+		t.Fatalf("checking pieces: %v", err)
+	}
+	recovered, err := recovery.Combine(shares)
+	if err != nil {
+		t.Fatalf("combining: %v", err)
+	}
+	if string(recovered) != passphrase {
+		t.Fatal("recovered passphrase doesn't match")
+	}
+}
+
+// TestWeightedBundleRecovery seals a 3-of-6 split where Alice holds two
+// pieces, and recovers with her bundle and one other.
+func TestWeightedBundleRecovery(t *testing.T) {
+	projectDir := filepath.Join(t.TempDir(), "test-weighted")
+	friends := []project.Friend{
+		{Name: "Alice", Shares: 2},
+		{Name: "Bob"},
+		{Name: "Carol"},
+		{Name: "Dave"},
+		{Name: "Erin"},
+	}
+	p, err := project.New(projectDir, "test-weighted", 3, friends)
+	if err != nil {
+		t.Fatalf("creating project: %v", err)
+	}
+	os.WriteFile(filepath.Join(p.ManifestPath(), "secrets.txt"), []byte("spouse holds two"), 0644)
+
+	// Seal the project
+	var archiveBuf bytes.Buffer
+	if _, err := manifest.Archive(&archiveBuf, p.ManifestPath()); err != nil {
+		t.Fatalf("archiving: %v", err)
+	}
+	passphrase, _ := crypto.GeneratePassphrase(crypto.DefaultPassphraseBytes)
+	os.MkdirAll(p.SharesPath(), 0755)
+	manifestFile, _ := os.Create(p.ManifestAgePath())
+	core.Encrypt(manifestFile, bytes.NewReader(archiveBuf.Bytes()), passphrase)
+	manifestFile.Close()
+
+	total := p.TotalPieces()
+	data, err := core.Split([]byte(passphrase), total, p.Threshold)
+	if err != nil {
+		t.Fatalf("splitting: %v", err)
+	}
+	bundleID, _ := core.NewBundleID()
+	shareInfos := make([]project.ShareInfo, len(p.Friends))
+	for i, friend := range p.Friends {
+		var pieces []*core.Share
+		for _, index := range p.PieceIndexes(i) {
+			piece := core.NewShare(1, index, total, p.Threshold, friend.Name, data[index-1])
+			piece.Bind(bundleID)
+			piece.Authenticate([]byte(passphrase))
+			pieces = append(pieces, piece)
+		}
+		os.WriteFile(p.SharePath(friend), []byte(core.EncodeShares(pieces)), 0644)
+		shareInfos[i] = project.ShareInfo{
+			Friend:   friend.Name,
+			File:     filepath.Base(p.SharePath(friend)),
+			Checksum: pieces[0].Checksum,
+		}
+	}
+	manifestData, _ := os.ReadFile(p.ManifestAgePath())
+	p.Sealed = &project.Sealed{
+		At:               time.Now(),
+		ManifestChecksum: core.HashBytes(manifestData),
+		VerificationHash: core.HashString(passphrase),
+		Shares:           shareInfos,
+		BundleID:         bundleID,
+	}
+	p.Save()
+
+	cfg := bundle.Config{
+		Version:          "v1.0.0-test",
+		GitHubReleaseURL: "https://example.com",
+		WASMBytes:        []byte("fake-wasm"),
+	}
+	if err := bundle.GenerateAll(p, cfg); err != nil {
+		t.Fatalf("generating bundles: %v", err)
+	}
+
+	bundlePath := func(name string) string {
+		return filepath.Join(p.OutputPath(), "bundles", "bundle-"+core.SanitizeFilename(name)+".zip")
+	}
+	r, err := zip.OpenReader(bundlePath("Alice"))
+	if err != nil {
+		t.Fatalf("opening bundle: %v", err)
+	}
+	for _, f := range r.File {
+		if translations.IsReadmeFile(f.Name, ".txt") {
+			rc, _ := f.Open()
+			readme, _ := io.ReadAll(rc)
+			rc.Close()
+			if !strings.Contains(string(readme), "You hold 2 of the 6 pieces") {
+				t.Error("README should say Alice holds 2 of the 6 pieces")
+			}
+			if n := strings.Count(string(readme), core.ShareBegin); n != 2 {
+				t.Errorf("README has %d share blocks, want 2", n)
+			}
+			if !strings.Contains(string(readme), "bundle-id: "+bundleID) {
+				t.Error("README metadata should record the bundle ID")
+			}
+		}
+	}
+	r.Close()
+
+	// Alice's bundle brings both of her pieces; with Bob's that's enough
+	shares, err := recovery.ReadShareFiles([]string{bundlePath("Alice"), bundlePath("Bob")})
+	if err != nil {
+		t.Fatalf("reading shares: %v", err)
+	}
+	if len(shares) != 3 {
+		t.Fatalf("got %d pieces, want 3", len(shares))
+	}
+	for _, s := range shares {
+		if s.BundleID != bundleID {
+			t.Errorf("piece %d has bundle ID %q, want %q", s.Index, s.BundleID, bundleID)
+		}
+		if s.MAC == "" {
+			t.Errorf("piece %d lost its MAC in the bundle", s.Index)
+		}
+	}
+	if err := recovery.CheckCompatible(shares); err != nil {
+		t.Fatalf("checking pieces: %v", err)
+	}
+	recovered, err := recovery.Combine(shares)
+	if err != nil {
+		t.Fatalf("combining: %v", err)
+	}
+	if string(recovered) != passphrase {
+		t.Fatal("recovered passphrase doesn't match")
+	}
+
+	// Bob and Carol alone are two pieces, one short
+	shares, err = recovery.ReadShareFiles([]string{bundlePath("Bob"), bundlePath("Carol")})
+	if err != nil {
+		t.Fatalf("reading shares: %v", err)
+	}
+	if err := recovery.CheckCompatible(shares); err == nil {
+		t.Error("expected two of three pieces to be too few")
+	}
+}
+
 // TestManifestEmbedding verifies that small manifests are embedded in recover.html
 // and that the NoEmbedManifest flag disables embedding.
 func TestManifestEmbedding(t *testing.T) {
@@ -1194,3 +1579,1044 @@ func TestManifestEmbedding(t *testing.T) {
 		}
 	})
 }
+
+// sealForBundleTest seals a small project directly, without the CLI.
+func sealForBundleTest(t *testing.T, friends []project.Friend, threshold int) *project.Project {
+	t.Helper()
+	p, err := project.New(filepath.Join(t.TempDir(), "project"), "template-test", threshold, friends)
+	if err != nil {
+		t.Fatalf("creating project: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(p.ManifestPath(), "secret.txt"), []byte("secret"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var archiveBuf, encrypted bytes.Buffer
+	if _, err := manifest.Archive(&archiveBuf, p.ManifestPath()); err != nil {
+		t.Fatalf("archiving: %v", err)
+	}
+	raw, passphrase, err := crypto.GenerateRawPassphrase(crypto.DefaultPassphraseBytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := core.Encrypt(&encrypted, &archiveBuf, string(passphrase)); err != nil {
+		t.Fatalf("encrypting: %v", err)
+	}
+	if err := os.MkdirAll(p.SharesPath(), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(p.ManifestAgePath(), encrypted.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	shares, err := core.Split(raw, len(friends), threshold)
+	if err != nil {
+		t.Fatalf("splitting: %v", err)
+	}
+	for i, data := range shares {
+		share := core.NewShare(2, i+1, len(friends), threshold, friends[i].Name, data)
+		if err := os.WriteFile(filepath.Join(p.SharesPath(), share.Filename()), []byte(share.Encode()), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	p.Sealed = &project.Sealed{
+		At:               time.Now(),
+		ManifestChecksum: core.HashBytes(encrypted.Bytes()),
+		VerificationHash: core.HashBytes(passphrase),
+	}
+	return p
+}
+
+func TestCustomReadmeTemplate(t *testing.T) {
+	friends := []project.Friend{
+		{Name: "Alice", Contact: "alice@example.com"},
+		{Name: "Bob", Contact: "bob@example.com"},
+	}
+	p := sealForBundleTest(t, friends, 2)
+
+	tmpl := `Hello {{.Holder}}! You need {{.Threshold}} of {{.Total}} pieces.
+{{range .OtherFriends}}Ask {{.Name}}.
+{{end}}{{t "your_share"}}
+{{.ShareBlock}}
+{{.Metadata}}`
+	if err := os.WriteFile(filepath.Join(p.Path, "README.tmpl"), []byte(tmpl), 0644); err != nil {
+		t.Fatal(err)
+	}
+	p.ReadmeTemplate = "README.tmpl"
+
+	cfg := bundle.Config{
+		Version:          "v1.0.0-test",
+		GitHubReleaseURL: "https://github.com/eljojo/rememory/releases/tag/v1.0.0-test",
+		WASMBytes:        []byte("fake-wasm-for-testing"),
+	}
+	// GenerateAll runs VerifyBundle on every bundle it writes
+	if err := bundle.GenerateAll(p, cfg); err != nil {
+		t.Fatalf("generating bundles: %v", err)
+	}
+
+	r, err := zip.OpenReader(filepath.Join(p.OutputPath(), "bundles", "bundle-alice.zip"))
+	if err != nil {
+		t.Fatalf("opening bundle: %v", err)
+	}
+	defer r.Close()
+	rc, err := r.Open("README.txt")
+	if err != nil {
+		t.Fatalf("opening README.txt: %v", err)
+	}
+	readme, _ := io.ReadAll(rc)
+	rc.Close()
+	if !strings.HasPrefix(string(readme), "Hello Alice! You need 2 of 2 pieces.\nAsk Bob.\n") {
+		t.Errorf("README does not use the template:\n%s", readme)
+	}
+}
+
+func TestCustomReadmeTemplateRejected(t *testing.T) {
+	tests := []struct {
+		name string
+		tmpl string
+		want string
+	}{
+		{"missing share", "Hello {{.Holder}}\n{{.Metadata}}", "{{.ShareBlock}}"},
+		{"missing metadata", "{{.ShareBlock}}", "{{.Metadata}}"},
+		{"unknown placeholder", "{{.ShareBlock}}{{.Metadata}}{{.Passphrase}}", "Passphrase"},
+		{"syntax", "{{.ShareBlock", "parsing README template"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := bundle.ParseReadmeTemplate(tt.tmpl)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("got %v, want error containing %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestHTMLCustomization(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "footer.html"), []byte(`<p class="family">Hosted by the Smiths</p>`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "extra.css"), []byte(".family { color: teal; }"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	p := &project.Project{
+		Path: dir,
+		HTML: &project.HTMLCustomization{
+			Banner: "Questions? Call <Ana>",
+			Footer: "footer.html",
+			CSS:    "extra.css",
+		},
+	}
+
+	custom, err := bundle.LoadHTMLCustomization(p)
+	if err != nil {
+		t.Fatalf("LoadHTMLCustomization: %v", err)
+	}
+	page := html.GenerateRecoverHTML([]byte("fake-wasm"), "v1.0.0", "https://example.com", nil, custom)
+
+	for _, want := range []string{
+		`<div class="custom-banner">Questions? Call &lt;Ana&gt;</div>`,
+		`<p class="family">Hosted by the Smiths</p>`,
+		`<style>.family { color: teal; }</style>`,
+	} {
+		if !strings.Contains(page, want) {
+			t.Errorf("recover.html missing %q", want)
+		}
+	}
+
+	plain := html.GenerateRecoverHTML([]byte("fake-wasm"), "v1.0.0", "https://example.com", nil, nil)
+	if strings.Contains(plain, "{{CUSTOM_") {
+		t.Error("recover.html without customization still has CUSTOM_ placeholders")
+	}
+
+	for _, bad := range []html.Customization{
+		{Header: `<script>alert(1)</script>`},
+		{Footer: `<p>{{CSP_NONCE}}</p>`},
+		{CSS: `</style><p>`},
+	} {
+		if err := bad.Validate(); err == nil {
+			t.Errorf("expected %+v to be rejected", bad)
+		}
+	}
+}
+
+func TestBundleBuildInfo(t *testing.T) {
+	friends := []project.Friend{{Name: "Alice"}, {Name: "Bob"}}
+	p := sealForBundleTest(t, friends, 2)
+	wasm := []byte("fake-wasm-for-testing")
+	if err := bundle.GenerateAll(p, bundle.Config{Version: "v1.0.0-test", WASMBytes: wasm}); err != nil {
+		t.Fatalf("generating bundles: %v", err)
+	}
+
+	bundlePath := filepath.Join(p.OutputPath(), "bundles", "bundle-alice.zip")
+	r, err := zip.OpenReader(bundlePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var files []bundle.ZipFile
+	var info bundle.BuildInfo
+	for _, f := range r.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if f.Name == bundle.BuildInfoFilename {
+			if err := json.Unmarshal(data, &info); err != nil {
+				t.Fatalf("parsing %s: %v", f.Name, err)
+			}
+			continue
+		}
+		files = append(files, bundle.ZipFile{Name: f.Name, Content: data, ModTime: f.Modified})
+	}
+	r.Close()
+
+	if info.Version != "v1.0.0-test" {
+		t.Errorf("version = %q", info.Version)
+	}
+	if info.GoVersion == "" || len(info.Dependencies) == 0 {
+		t.Errorf("missing toolchain details: go %q, %d dependencies", info.GoVersion, len(info.Dependencies))
+	}
+	if info.RecoverWASM != core.HashBytes(wasm) {
+		t.Errorf("recoverWasmSha256 = %q", info.RecoverWASM)
+	}
+	if len(info.Artifacts) != len(files) {
+		t.Errorf("%d artifacts listed for %d files", len(info.Artifacts), len(files))
+	}
+	for _, f := range files {
+		if info.Artifacts[f.Name] != core.HashBytes(f.Content) {
+			t.Errorf("artifact %s: got %q", f.Name, info.Artifacts[f.Name])
+		}
+	}
+
+	// README.pdf has no checksum of its own in the README; BUILDINFO.json catches changes
+	for i, f := range files {
+		if translations.IsReadmeFile(f.Name, ".pdf") {
+			files[i].Content = append(f.Content, ' ')
+		}
+	}
+	original, err := json.Marshal(info)
+	if err != nil {
+		t.Fatal(err)
+	}
+	files = append(files, bundle.ZipFile{Name: bundle.BuildInfoFilename, Content: original, ModTime: time.Now()})
+	if err := bundle.CreateZip(bundlePath, files); err != nil {
+		t.Fatal(err)
+	}
+	if err := bundle.VerifyBundle(bundlePath); err == nil {
+		t.Error("VerifyBundle accepted a changed README.pdf")
+	}
+}
+
+func TestVerifyPage(t *testing.T) {
+	friends := []project.Friend{{Name: "Alice"}, {Name: "Bob"}}
+	p := sealForBundleTest(t, friends, 2)
+	if err := bundle.GenerateAll(p, bundle.Config{Version: "v1.0.0-test", WASMBytes: []byte("fake-wasm")}); err != nil {
+		t.Fatalf("generating bundles: %v", err)
+	}
+
+	bundlePath := filepath.Join(p.OutputPath(), "bundles", "bundle-alice.zip")
+	files, err := bundle.FileChecksums(bundlePath)
+	if err != nil {
+		t.Fatalf("FileChecksums: %v", err)
+	}
+	if _, ok := files["recover.html"]; !ok {
+		t.Errorf("recover.html missing from checksums: %v", files)
+	}
+
+	shareData, err := os.ReadFile(filepath.Join(p.SharesPath(), "SHARE-alice.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	share, err := core.ParseShare(shareData)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	page := html.GenerateVerifyHTML([]byte("fake-wasm"), "v1.0.0", &html.VerifyData{
+		Holder:        "Alice",
+		ShareIndex:    share.Index,
+		Total:         share.Total,
+		ShareChecksum: share.Checksum,
+		Files:         files,
+	})
+	if !strings.Contains(page, share.Checksum) || !strings.Contains(page, files["recover.html"]) {
+		t.Error("VERIFY.html is missing the checksums")
+	}
+	// The page must not be able to recover anything on its own
+	for _, secret := range []string{core.ShareBegin, share.CompactEncode()} {
+		if strings.Contains(page, secret) {
+			t.Errorf("VERIFY.html contains %q", secret)
+		}
+	}
+}
+
+func TestFriendWordList(t *testing.T) {
+	// Camila and Hiro read English but can only reliably write down words
+	// in their own languages
+	friends := []project.Friend{{Name: "Alice"}, {Name: "Camila", Words: "es"}, {Name: "Hiro", Words: "ja"}}
+	p := sealForBundleTest(t, friends, 2)
+	if err := bundle.GenerateAll(p, bundle.Config{Version: "v1.0.0-test", WASMBytes: []byte("fake-wasm")}); err != nil {
+		t.Fatalf("generating bundles: %v", err)
+	}
+
+	for _, tc := range []struct {
+		friend, name string
+		lang         core.Lang
+	}{
+		{"camila", "Spanish", core.LangES},
+		{"hiro", "Japanese", core.LangJA},
+	} {
+		shareData, err := os.ReadFile(filepath.Join(p.SharesPath(), "SHARE-"+tc.friend+".txt"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		share, err := core.ParseShare(shareData)
+		if err != nil {
+			t.Fatal(err)
+		}
+		native, _ := share.WordsForLang(tc.lang)
+
+		r, err := zip.OpenReader(filepath.Join(p.OutputPath(), "bundles", "bundle-"+tc.friend+".zip"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		rc, err := r.Open("README.txt")
+		if err != nil {
+			t.Fatal(err)
+		}
+		content, _ := io.ReadAll(rc)
+		rc.Close()
+		r.Close()
+		readme := string(content)
+		if !strings.Contains(readme, tc.name) || !strings.Contains(readme, norm.NFC.String(native[0])) {
+			t.Errorf("%s: README.txt should lead with the %s word grid", tc.friend, tc.name)
+		}
+		english, _ := share.Words()
+		if !strings.Contains(readme, english[0]) {
+			t.Errorf("%s: README.txt should still carry the English word grid", tc.friend)
+		}
+
+		// The words come back without saying which list they're from
+		data, index, lang, err := core.DecodeShareWordsAuto(native)
+		if err != nil || lang != tc.lang || index != share.Index || !bytes.Equal(data, share.Data) {
+			t.Errorf("decoding %s words: index %d, lang %q, %v", tc.name, index, lang, err)
+		}
+	}
+}
+
+func TestSLIP39Bundles(t *testing.T) {
+	friends := []project.Friend{{Name: "Alice"}, {Name: "Bob"}, {Name: "Camila"}}
+	p := sealForBundleTest(t, friends, 2)
+
+	shares, err := recovery.ReadShareFiles([]string{
+		filepath.Join(p.SharesPath(), "SHARE-alice.txt"),
+		filepath.Join(p.SharesPath(), "SHARE-bob.txt"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	passphrase, err := recovery.Combine(shares)
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(string(passphrase))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// What seal writes when the project sets slip39
+	p.SLIP39 = true
+	mnemonics, err := core.SLIP39Split(raw, 2, len(friends))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, f := range friends {
+		if err := os.WriteFile(p.SLIP39Path(f), []byte(mnemonics[i]+"\n"), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := bundle.GenerateAll(p, bundle.Config{Version: "v1.0.0-test", WASMBytes: []byte("fake-wasm")}); err != nil {
+		t.Fatalf("generating bundles: %v", err)
+	}
+
+	// Each friend's SLIP39.txt, as found in their bundle, recovers the passphrase
+	dir := t.TempDir()
+	var paths []string
+	for _, name := range []string{"camila", "alice"} {
+		r, err := zip.OpenReader(filepath.Join(p.OutputPath(), "bundles", "bundle-"+name+".zip"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		rc, err := r.Open(bundle.SLIP39Filename)
+		if err != nil {
+			t.Fatalf("%s's bundle has no %s: %v", name, bundle.SLIP39Filename, err)
+		}
+		content, _ := io.ReadAll(rc)
+		rc.Close()
+		r.Close()
+		path := filepath.Join(dir, name+".txt")
+		if err := os.WriteFile(path, content, 0600); err != nil {
+			t.Fatal(err)
+		}
+		paths = append(paths, path)
+	}
+	found, ok, err := recovery.ReadSLIP39Files(paths)
+	if err != nil || !ok {
+		t.Fatalf("reading SLIP39.txt: ok=%v, %v", ok, err)
+	}
+	got, err := recovery.CombineSLIP39(found)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, passphrase) {
+		t.Error("SLIP-0039 words recovered the wrong passphrase")
+	}
+
+	// They don't mix with ReMemory pieces
+	mixed := []string{filepath.Join(p.SharesPath(), "SHARE-bob.txt"), paths[0]}
+	if _, err := recovery.ReadShareFiles(mixed); err == nil || !strings.Contains(err.Error(), "SLIP-0039") {
+		t.Errorf("expected a SLIP-0039 error, got %v", err)
+	}
+}
+
+func TestSSKRBundles(t *testing.T) {
+	friends := []project.Friend{{Name: "Alice"}, {Name: "Bob"}, {Name: "Camila"}}
+	p := sealForBundleTest(t, friends, 2)
+
+	shares, err := recovery.ReadShareFiles([]string{
+		filepath.Join(p.SharesPath(), "SHARE-alice.txt"),
+		filepath.Join(p.SharesPath(), "SHARE-bob.txt"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	passphrase, err := recovery.Combine(shares)
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(string(passphrase))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// What seal writes when the project sets sskr
+	p.SSKR = true
+	sskr, err := core.SSKRSplit(raw, 2, len(friends))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, f := range friends {
+		if err := os.WriteFile(p.SSKRPath(f), []byte(sskr[i].UR()+"\n"), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := bundle.GenerateAll(p, bundle.Config{Version: "v1.0.0-test", WASMBytes: []byte("fake-wasm")}); err != nil {
+		t.Fatalf("generating bundles: %v", err)
+	}
+
+	// Camila's SSKR.txt from her bundle, and Alice's share typed as bytewords
+	dir := t.TempDir()
+	r, err := zip.OpenReader(filepath.Join(p.OutputPath(), "bundles", "bundle-camila.zip"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rc, err := r.Open(bundle.SSKRFilename)
+	if err != nil {
+		t.Fatalf("bundle has no %s: %v", bundle.SSKRFilename, err)
+	}
+	content, _ := io.ReadAll(rc)
+	rc.Close()
+	r.Close()
+	paths := []string{filepath.Join(dir, "camila.txt"), filepath.Join(dir, "alice.txt")}
+	if err := os.WriteFile(paths[0], content, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(paths[1], []byte(sskr[0].Bytewords()+"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	found, ok, err := recovery.ReadSSKRFiles(paths)
+	if err != nil || !ok {
+		t.Fatalf("reading SSKR shares: ok=%v, %v", ok, err)
+	}
+	got, err := recovery.CombineSSKR(found)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, passphrase) {
+		t.Error("SSKR shares recovered the wrong passphrase")
+	}
+
+	// They don't mix with ReMemory pieces
+	mixed := []string{filepath.Join(p.SharesPath(), "SHARE-bob.txt"), paths[0]}
+	if _, err := recovery.ReadShareFiles(mixed); err == nil || !strings.Contains(err.Error(), "SSKR") {
+		t.Errorf("expected an SSKR error, got %v", err)
+	}
+}
+
+func TestSSSSBundles(t *testing.T) {
+	friends := []project.Friend{{Name: "Alice"}, {Name: "Bob"}, {Name: "Camila"}, {Name: "Dev"}}
+	p := sealForBundleTest(t, friends, 3)
+
+	shares, err := recovery.ReadShareFiles([]string{
+		filepath.Join(p.SharesPath(), "SHARE-alice.txt"),
+		filepath.Join(p.SharesPath(), "SHARE-bob.txt"),
+		filepath.Join(p.SharesPath(), "SHARE-dev.txt"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	passphrase, err := recovery.Combine(shares)
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(string(passphrase))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// What seal writes when the project sets ssss
+	p.SSSS = true
+	ssss, err := core.SSSSSplit(raw, 3, len(friends))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, f := range friends {
+		if err := os.WriteFile(p.SSSSPath(f), []byte(ssss[i]+"\n"), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := bundle.GenerateAll(p, bundle.Config{Version: "v1.0.0-test", WASMBytes: []byte("fake-wasm")}); err != nil {
+		t.Fatalf("generating bundles: %v", err)
+	}
+
+	// Camila's SSSS.txt from her bundle, and everyone else's bare share line.
+	// Four shares are given; SSSS.txt says three are needed.
+	dir := t.TempDir()
+	r, err := zip.OpenReader(filepath.Join(p.OutputPath(), "bundles", "bundle-camila.zip"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rc, err := r.Open(bundle.SSSSFilename)
+	if err != nil {
+		t.Fatalf("bundle has no %s: %v", bundle.SSSSFilename, err)
+	}
+	content, _ := io.ReadAll(rc)
+	rc.Close()
+	r.Close()
+	if !strings.Contains(string(content), "ssss-combine -t 3 -x -D") {
+		t.Errorf("SSSS.txt doesn't give the ssss-combine command:\n%s", content)
+	}
+	paths := []string{filepath.Join(dir, "camila.txt")}
+	if err := os.WriteFile(paths[0], content, 0600); err != nil {
+		t.Fatal(err)
+	}
+	for _, i := range []int{0, 1, 3} {
+		path := filepath.Join(dir, fmt.Sprintf("line-%d.txt", i))
+		if err := os.WriteFile(path, []byte(ssss[i]+"\n"), 0600); err != nil {
+			t.Fatal(err)
+		}
+		paths = append(paths, path)
+	}
+
+	found, threshold, ok, err := recovery.ReadSSSSFiles(paths)
+	if err != nil || !ok {
+		t.Fatalf("reading ssss shares: ok=%v, %v", ok, err)
+	}
+	if threshold != 3 {
+		t.Errorf("threshold = %d, want 3", threshold)
+	}
+	got, err := recovery.CombineSSSS(found, threshold)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, passphrase) {
+		t.Error("ssss shares recovered the wrong passphrase")
+	}
+
+	// They don't mix with ReMemory pieces
+	mixed := []string{filepath.Join(p.SharesPath(), "SHARE-bob.txt"), paths[1]}
+	if _, err := recovery.ReadShareFiles(mixed); err == nil || !strings.Contains(err.Error(), "ssss") {
+		t.Errorf("expected an ssss error, got %v", err)
+	}
+}
+
+func TestRegenerateKeepsUnchangedBundles(t *testing.T) {
+	friends := []project.Friend{{Name: "Alice"}, {Name: "Bob"}, {Name: "Carol"}}
+	p := sealForBundleTest(t, friends, 2)
+	cfg := bundle.Config{Version: "v1.0.0-test", WASMBytes: []byte("fake-wasm")}
+	if err := bundle.GenerateAll(p, cfg); err != nil {
+		t.Fatalf("generating bundles: %v", err)
+	}
+	read := func(name string) []byte {
+		data, err := os.ReadFile(filepath.Join(p.OutputPath(), "bundles", "bundle-"+name+".zip"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return data
+	}
+	carol := read("carol")
+
+	// Nothing changed: every bundle is kept as it was
+	changes, err := bundle.Regenerate(p, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range changes {
+		if !c.Unchanged() || c.Reprint() {
+			t.Errorf("%s: changed %v with nothing to change", c.Friend, c.Files)
+		}
+	}
+	if !bytes.Equal(read("carol"), carol) {
+		t.Error("Carol's bundle was rewritten though nothing changed")
+	}
+
+	// Carol's contact shows in the others' READMEs, not her own
+	p.Friends[2].Contact = "carol@example.com"
+	changes, err = bundle.Regenerate(p, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range changes {
+		if c.Friend == "Carol" {
+			if !c.Unchanged() {
+				t.Errorf("Carol: changed %v", c.Files)
+			}
+			continue
+		}
+		if !c.Reprint() || !slices.Contains(c.Files, "README.txt") {
+			t.Errorf("%s: changed %v, want README.txt and a reprint", c.Friend, c.Files)
+		}
+	}
+	if !bytes.Equal(read("carol"), carol) {
+		t.Error("Carol's bundle was rewritten though nothing in it changed")
+	}
+	if err := bundle.VerifyBundle(filepath.Join(p.OutputPath(), "bundles", "bundle-alice.zip")); err != nil {
+		t.Errorf("Alice's new bundle: %v", err)
+	}
+}
+
+func TestQuizBundles(t *testing.T) {
+	friends := []project.Friend{{Name: "Alice"}, {Name: "Bob"}, {Name: "Camila"}}
+	p := sealForBundleTest(t, friends, 2)
+	p.Quiz = true
+	if err := bundle.GenerateAll(p, bundle.Config{Version: "v1.0.0-test", WASMBytes: []byte("fake-wasm")}); err != nil {
+		t.Fatalf("generating bundles: %v", err)
+	}
+
+	r, err := zip.OpenReader(filepath.Join(p.OutputPath(), "bundles", "bundle-bob.zip"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	read := func(name string) string {
+		rc, err := r.Open(name)
+		if err != nil {
+			t.Fatalf("bundle has no %s: %v", name, err)
+		}
+		defer rc.Close()
+		data, _ := io.ReadAll(rc)
+		return string(data)
+	}
+
+	// The answers are Bob's: the threshold and the friends he can call
+	quiz := read(bundle.QuizFilename)
+	for _, want := range []string{"For: Bob", "How many pieces are needed", "ANSWERS", "2. Your piece alone opens nothing.", "The other holders listed in README.txt: Alice, Camila."} {
+		if !strings.Contains(quiz, want) {
+			t.Errorf("QUIZ.txt is missing %q:\n%s", want, quiz)
+		}
+	}
+	if strings.Contains(quiz, "PIN") {
+		t.Error("QUIZ.txt asks about a PIN for a piece that has none")
+	}
+	if !strings.Contains(read("recover.html"), `"quiz":true`) {
+		t.Error("recover.html doesn't show the quiz")
+	}
+}
+
+func TestAudioBundles(t *testing.T) {
+	friends := []project.Friend{{Name: "Alice"}, {Name: "Bob"}, {Name: "Camila", Language: "pt"}}
+	p := sealForBundleTest(t, friends, 2)
+	p.Audio = true
+	if err := bundle.GenerateAll(p, bundle.Config{Version: "v1.0.0-test", WASMBytes: []byte("fake-wasm")}); err != nil {
+		t.Fatalf("generating bundles: %v", err)
+	}
+
+	recordings := map[string][]byte{}
+	for _, name := range []string{"alice", "bob", "camila"} {
+		r, err := zip.OpenReader(filepath.Join(p.OutputPath(), "bundles", "bundle-"+name+".zip"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		rc, err := r.Open(bundle.AudioFilename)
+		if err != nil {
+			t.Fatalf("%s's bundle has no %s: %v", name, bundle.AudioFilename, err)
+		}
+		wav, _ := io.ReadAll(rc)
+		rc.Close()
+		if name == "bob" {
+			rc, err := r.Open("README.txt")
+			if err != nil {
+				t.Fatal(err)
+			}
+			readme, _ := io.ReadAll(rc)
+			rc.Close()
+			if !strings.Contains(string(readme), "PIECE.wav in this bundle reads these digits aloud") {
+				t.Error("README.txt doesn't mention PIECE.wav")
+			}
+		}
+		r.Close()
+		recordings[name] = wav
+
+		// 8 kHz, 8-bit mono PCM, long enough to read 108 digits slowly
+		if len(wav) < 44 || string(wav[:4]) != "RIFF" || string(wav[8:16]) != "WAVEfmt " || string(wav[36:40]) != "data" {
+			t.Fatalf("%s: %s isn't a WAV file", name, bundle.AudioFilename)
+		}
+		if rate := binary.LittleEndian.Uint32(wav[24:28]); rate != 8000 {
+			t.Errorf("%s: sample rate %d, want 8000", name, rate)
+		}
+		if size := binary.LittleEndian.Uint32(wav[40:44]); int(size) != len(wav)-44 {
+			t.Errorf("%s: data chunk says %d bytes, file has %d", name, size, len(wav)-44)
+		}
+		if seconds := (len(wav) - 44) / 8000; seconds < 60 || seconds > 300 {
+			t.Errorf("%s: recording is %ds long", name, seconds)
+		}
+	}
+	if bytes.Equal(recordings["alice"], recordings["bob"]) {
+		t.Error("Alice and Bob have the same recording")
+	}
+
+	// Whoever listens types the digits README.txt prints
+	data, err := os.ReadFile(p.SharePath(friends[1]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	share, err := core.ParseShare(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	groups, err := share.Digits()
+	if err != nil {
+		t.Fatal(err)
+	}
+	wav, err := bundle.GenerateAudio(share, "en")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(wav, recordings["bob"]) {
+		t.Error("Bob's recording doesn't read his piece's digits")
+	}
+	typed, index, err := core.DecodeShareDigits(strings.Join(groups, " "))
+	if err != nil || index != share.Index || !bytes.Equal(typed, share.Data) {
+		t.Errorf("typed digits: index %d, %v", index, err)
+	}
+}
+
+func TestRecoveryDelayBundles(t *testing.T) {
+	friends := []project.Friend{{Name: "Alice"}, {Name: "Bob"}}
+	p := sealForBundleTest(t, friends, 2)
+
+	// What seal writes when the project sets recovery_delay
+	p.RecoveryDelay = "72h"
+	seal, err := core.NewTimelock(72*time.Hour, 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := seal.Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(p.TimelockPath(), data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := bundle.GenerateAll(p, bundle.Config{Version: "v1.0.0-test", WASMBytes: []byte("fake-wasm")}); err != nil {
+		t.Fatalf("generating bundles: %v", err)
+	}
+
+	bundlePath := filepath.Join(p.OutputPath(), "bundles", "bundle-bob.zip")
+	found, err := recovery.Identify(bundlePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if found.Timelock == nil || found.Timelock.Modulus != seal.Modulus {
+		t.Fatalf("bundle has no %s: %+v", core.TimelockFile, found.Timelock)
+	}
+
+	r, err := zip.OpenReader(bundlePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	for _, name := range []string{"README.txt", "recover.html"} {
+		rc, err := r.Open(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		content, _ := io.ReadAll(rc)
+		rc.Close()
+		switch name {
+		case "README.txt":
+			if !strings.Contains(string(content), "the computer works for about 72h before the files open") {
+				t.Error("README.txt doesn't mention the recovery delay")
+			}
+		case "recover.html":
+			tl, err := recovery.ExtractTimelockFromHTML(content)
+			if err != nil || tl.Squarings != seal.Squarings {
+				t.Errorf("recover.html timelock: %+v, %v", tl, err)
+			}
+		}
+	}
+}
+
+func TestSunsetBundles(t *testing.T) {
+	friends := []project.Friend{{Name: "Alice"}, {Name: "Bob"}}
+	p := sealForBundleTest(t, friends, 2)
+
+	// What seal writes when the project sets review_by and expires
+	p.ReviewBy, p.Expires = "2020-01-10", "2020-01-31"
+	reviewBy, expires, err := p.Sunset()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range friends {
+		path := filepath.Join(p.SharesPath(), "SHARE-"+strings.ToLower(f.Name)+".txt")
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		share, err := core.ParseShare(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		share.ReviewBy, share.Expires = reviewBy, expires
+		if err := os.WriteFile(path, []byte(share.Encode()), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := bundle.GenerateAll(p, bundle.Config{Version: "v1.0.0-test", WASMBytes: []byte("fake-wasm")}); err != nil {
+		t.Fatalf("generating bundles: %v", err)
+	}
+
+	bundles := []string{
+		filepath.Join(p.OutputPath(), "bundles", "bundle-alice.zip"),
+		filepath.Join(p.OutputPath(), "bundles", "bundle-bob.zip"),
+	}
+	r, err := zip.OpenReader(bundles[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	rc, err := r.Open("README.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	readme, _ := io.ReadAll(rc)
+	rc.Close()
+	r.Close()
+	for _, want := range []string{"Review by 2020-01-10", "Expires 2020-01-31", "Expires: 2020-01-31"} {
+		if !strings.Contains(string(readme), want) {
+			t.Errorf("README.txt is missing %q", want)
+		}
+	}
+
+	// Past expiry, recovery stops unless told to go on
+	shares, err := recovery.ReadShareFiles(bundles)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := recovery.SunsetOf(shares).Check(time.Now(), false); err == nil || !strings.Contains(err.Error(), "--ignore-expiry") {
+		t.Errorf("expected expired pieces to be refused, got %v", err)
+	}
+	if warning, err := recovery.SunsetOf(shares).Check(time.Now(), true); err != nil || !strings.Contains(warning, "expired on 2020-01-31") {
+		t.Errorf("ignoring expiry: %q, %v", warning, err)
+	}
+	if _, err := recovery.Combine(shares); err != nil {
+		t.Errorf("combining: %v", err)
+	}
+}
+
+func TestRotationBundles(t *testing.T) {
+	friends := []project.Friend{{Name: "Alice"}, {Name: "Bob"}}
+	cfg := bundle.Config{Version: "v1.0.0-test", WASMBytes: []byte("fake-wasm")}
+	old := sealForBundleTest(t, friends, 2)
+	if err := bundle.GenerateAll(old, cfg); err != nil {
+		t.Fatalf("generating old bundles: %v", err)
+	}
+
+	// What seal writes when it seals a project again
+	p := sealForBundleTest(t, friends, 2)
+	shares, err := recovery.ReadShareFiles([]string{
+		filepath.Join(p.SharesPath(), "SHARE-alice.txt"),
+		filepath.Join(p.SharesPath(), "SHARE-bob.txt"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	passphrase, err := recovery.Combine(shares)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.Superseded = rotation.Record(nil, rotation.Generation{Sealed: old.Sealed.At, Fingerprint: rotation.Fingerprint(old.Sealed.ManifestChecksum)})
+	p.Sealed.Rotation, err = rotation.Sign(p.Name, p.Sealed.At, p.Sealed.ManifestChecksum, "Ana", p.Superseded, passphrase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := bundle.GenerateAll(p, cfg); err != nil {
+		t.Fatalf("generating bundles: %v", err)
+	}
+
+	info, err := bundle.ReadInfo(filepath.Join(p.OutputPath(), "bundles", "bundle-alice.zip"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.RotationErr != nil || info.Rotation == nil {
+		t.Fatalf("reading %s: %v", rotation.FileName, info.RotationErr)
+	}
+	oldInfo, err := bundle.ReadInfo(filepath.Join(old.OutputPath(), "bundles", "bundle-bob.zip"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if oldInfo.Rotation != nil {
+		t.Error("first seal has a rotation note")
+	}
+	if _, ok := info.Rotation.Supersedes(oldInfo.Metadata["checksum-manifest"]); !ok {
+		t.Error("new bundle doesn't supersede the old one")
+	}
+	if _, ok := info.Rotation.Supersedes(info.Metadata["checksum-manifest"]); ok {
+		t.Error("new bundle supersedes itself")
+	}
+	if !info.Rotation.SignedBy(passphrase) {
+		t.Error("note isn't signed with the new passphrase")
+	}
+
+	// A note moved from another seal's bundle is caught
+	p.Sealed.Rotation, _ = rotation.Sign(p.Name, p.Sealed.At, old.Sealed.ManifestChecksum, "", p.Superseded, passphrase)
+	if err := bundle.GenerateAll(p, cfg); err == nil || !strings.Contains(err.Error(), "different seal") {
+		t.Errorf("expected a mismatched note to fail verification, got %v", err)
+	}
+}
+
+func TestShareCommitments(t *testing.T) {
+	friends := []project.Friend{{Name: "Alice"}, {Name: "Bob"}, {Name: "Carol"}}
+	cfg := bundle.Config{Version: "v1.0.0-test", WASMBytes: []byte("fake-wasm")}
+	p := sealForBundleTest(t, friends, 2)
+	if err := bundle.GenerateAll(p, cfg); err != nil {
+		t.Fatalf("generating bundles: %v", err)
+	}
+	other := sealForBundleTest(t, friends, 2)
+
+	info, err := bundle.ReadInfo(filepath.Join(p.OutputPath(), "bundles", "bundle-alice.zip"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	commitments := bundle.CommitmentsFromMetadata(info.Metadata)
+	if len(commitments) != len(friends) {
+		t.Fatalf("README records %d commitments, want %d", len(commitments), len(friends))
+	}
+
+	// Every piece of the seal matches Alice's bundle on its own; pieces of
+	// another seal don't
+	for i, f := range friends {
+		name := "SHARE-" + strings.ToLower(f.Name) + ".txt"
+		shares, err := recovery.ReadShareFiles([]string{filepath.Join(p.SharesPath(), name)})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := commitments.Match(shares[0].Data); got != i+1 {
+			t.Errorf("%s matched piece %d, want %d", name, got, i+1)
+		}
+		foreign, err := recovery.ReadShareFiles([]string{filepath.Join(other.SharesPath(), name)})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := commitments.Match(foreign[0].Data); got != 0 {
+			t.Errorf("%s of another seal matched piece %d", name, got)
+		}
+	}
+
+	// recover.html carries them too, for the browser to check pieces as they're added
+	rc, err := zip.OpenReader(filepath.Join(p.OutputPath(), "bundles", "bundle-alice.zip"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rc.Close()
+	for _, f := range rc.File {
+		if f.Name != "recover.html" {
+			continue
+		}
+		r, _ := f.Open()
+		data, _ := io.ReadAll(r)
+		r.Close()
+		if !strings.Contains(string(data), commitments[2]) {
+			t.Error("recover.html doesn't record Bob's share checksum")
+		}
+	}
+}
+
+func TestVSSCommitments(t *testing.T) {
+	friends := []project.Friend{{Name: "Alice"}, {Name: "Bob"}, {Name: "Carol"}}
+	p := sealForBundleTest(t, friends, 2)
+
+	// Split the passphrase a second time, as seal does
+	shares := make([]*core.Share, len(friends))
+	for i, f := range friends {
+		data, err := os.ReadFile(p.SharePath(f))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if shares[i], err = core.ParseShare(data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	raw, err := core.Combine([][]byte{shares[0].Data, shares[1].Data})
+	if err != nil {
+		t.Fatal(err)
+	}
+	vss, err := core.SplitVSS(raw, 2, shares)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, s := range shares {
+		if err := os.WriteFile(p.SharePath(friends[i]), []byte(s.Encode()), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	p.Sealed.VSS = vss.Strings()
+	if err := bundle.GenerateAll(p, bundle.Config{Version: "v1.0.0-test", WASMBytes: []byte("fake-wasm")}); err != nil {
+		t.Fatalf("generating bundles: %v", err)
+	}
+
+	// Alice's README records the commitments, and every piece checks out
+	// against them on its own
+	alice := filepath.Join(p.OutputPath(), "bundles", "bundle-alice.zip")
+	info, err := bundle.ReadInfo(alice)
+	if err != nil {
+		t.Fatal(err)
+	}
+	recorded, err := bundle.VSSFromMetadata(info.Metadata)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recorded) != 2 {
+		t.Fatalf("README records %d VSS commitments, want 2", len(recorded))
+	}
+	for _, s := range shares {
+		if err := recorded.Verify(s.Index, s.VSS); err != nil {
+			t.Errorf("piece %d: %v", s.Index, err)
+		}
+	}
+	if err := bundle.VerifyBundle(alice); err != nil {
+		t.Errorf("verifying Alice's bundle: %v", err)
+	}
+
+	// A piece with an altered value doesn't
+	shares[1].VSS[0] ^= 1
+	if err := recorded.Verify(2, shares[1].VSS); err == nil {
+		t.Error("an altered VSS value verified")
+	}
+}
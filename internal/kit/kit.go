@@ -0,0 +1,213 @@
+// Package kit writes the owner's emergency kit: one printable page that
+// sums up a sealed project — who holds a piece, how to reach them, which
+// files belong to the seal, where the recovery page is hosted, and how to
+// seal everything again. Losing the computer the project lives on then
+// doesn't mean losing track of how the scheme was set up.
+//
+// The passphrase is left out unless asked for, and even then it is only
+// printed locked with a password of the owner's own, as an armored age
+// block.
+package kit
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"time"
+
+	"filippo.io/age/armor"
+	"github.com/eljojo/rememory/internal/core"
+	"github.com/eljojo/rememory/internal/project"
+	"github.com/eljojo/rememory/internal/rotation"
+)
+
+// FileName is the kit's name in the output directory.
+const FileName = "EMERGENCY-KIT.txt"
+
+// Holder is one friend with a piece of the current seal.
+type Holder struct {
+	Name          string
+	Contact       string
+	Location      string
+	Index         int
+	ShareChecksum string // As in the Checksum line of the friend's share block
+	Bundle        string // Bundle file name, if it was made
+	BundleSum     string // Checksum of the bundle file
+}
+
+// Artifact is a file that belongs to the seal, with its checksum.
+type Artifact struct {
+	Name     string
+	Checksum string
+}
+
+// Kit is everything the page records.
+type Kit struct {
+	Project          string
+	Made             time.Time // When the kit was made
+	Version          string    // rememory version that made it
+	Sealed           time.Time
+	ManifestChecksum string
+	Crypto           string
+	Threshold        int
+	Total            int
+	Groups           *project.GroupPolicy // Nil unless the project has groups; Threshold then counts groups
+	RecoveryURL      string
+	ReviewBy         time.Time
+	Expires          time.Time
+	Contact          string
+	Privacy          project.Privacy
+	Holders          []Holder
+	Artifacts        []Artifact
+	Superseded       []rotation.Generation
+
+	// LockedPassphrase is the passphrase as an armored age block, locked with
+	// the owner's password; empty to leave it out.
+	LockedPassphrase string
+}
+
+// LockPassphrase encrypts the passphrase with the owner's password and
+// armors it, so it can be printed and typed or scanned back in.
+func LockPassphrase(passphrase []byte, password string) (string, error) {
+	var buf bytes.Buffer
+	w := armor.NewWriter(&buf)
+	if err := core.Encrypt(w, bytes.NewReader(passphrase), password); err != nil {
+		return "", err
+	}
+	if err := w.Close(); err != nil {
+		return "", fmt.Errorf("armoring passphrase: %w", err)
+	}
+	return buf.String(), nil
+}
+
+// Text returns the kit as a page to print. Like SUPERSEDED.txt, it is in
+// English only: it is for the owner, not the friends.
+func (k *Kit) Text() string {
+	var sb strings.Builder
+	rule := strings.Repeat("=", 80) + "\n"
+	section := func(title string) {
+		sb.WriteString("\n" + strings.Repeat("-", 80) + "\n")
+		sb.WriteString(title + "\n")
+		sb.WriteString(strings.Repeat("-", 80) + "\n")
+	}
+
+	sb.WriteString(rule)
+	sb.WriteString(fmt.Sprintf("EMERGENCY KIT FOR %s\n", strings.ToUpper(k.Project)))
+	sb.WriteString(rule)
+	sb.WriteString("This page is for you, the owner. It records how this project was set up,\n")
+	sb.WriteString("so you can check on it, replace a bundle, or seal it again even if the\n")
+	sb.WriteString("computer it was made on is gone. It holds no piece and can't open anything\n")
+	if k.LockedPassphrase != "" {
+		sb.WriteString("on its own: the passphrase below is locked with a password only you know.\n")
+	} else {
+		sb.WriteString("on its own.\n")
+	}
+	sb.WriteString("Keep it somewhere safe, away from your friends' bundles.\n")
+
+	section("THE SEAL")
+	field := func(name, value string) {
+		if value != "" {
+			sb.WriteString(fmt.Sprintf("  %-18s %s\n", name+":", value))
+		}
+	}
+	field("Project", k.Project)
+	field("Sealed", k.Sealed.UTC().Format("2006-01-02 15:04 UTC"))
+	field("Fingerprint", rotation.Fingerprint(k.ManifestChecksum))
+	if k.Groups != nil {
+		field("Groups needed", fmt.Sprintf("%d of %d", k.Threshold, len(k.Groups.Groups)))
+		for _, g := range k.Groups.Groups {
+			field("  "+g.Name, fmt.Sprintf("%d of %d pieces", g.Threshold, g.Size))
+		}
+	} else {
+		field("Pieces needed", fmt.Sprintf("%d of %d", k.Threshold, k.Total))
+	}
+	field("Recovery page", k.RecoveryURL)
+	if k.Crypto != "" {
+		field("Crypto profile", k.Crypto)
+	}
+	if !k.ReviewBy.IsZero() {
+		field("Review by", k.ReviewBy.Format(core.DateFormat))
+	}
+	if !k.Expires.IsZero() {
+		field("Expires", k.Expires.Format(core.DateFormat))
+	}
+	field("Your contact", k.Contact)
+	field("Made with", k.Version)
+	sb.WriteString("\nThe fingerprint is how checksum-manifest starts in every README of this\n")
+	sb.WriteString("seal. The QR codes on the printed pages open the recovery page above; if it\n")
+	sb.WriteString("is hosted by you, keep it online, or point friends to recover.html in their\n")
+	sb.WriteString("bundles.\n")
+
+	section("WHO HOLDS A PIECE")
+	for _, h := range k.Holders {
+		sb.WriteString(fmt.Sprintf("  %d. %s\n", h.Index, h.Name))
+		if h.Contact != "" {
+			sb.WriteString(fmt.Sprintf("     Contact:  %s\n", h.Contact))
+		}
+		if h.Location != "" {
+			sb.WriteString(fmt.Sprintf("     Location: %s\n", h.Location))
+		}
+		sb.WriteString(fmt.Sprintf("     Share:    %s\n", h.ShareChecksum))
+		if h.Bundle != "" {
+			sb.WriteString(fmt.Sprintf("     Bundle:   %s\n", h.Bundle))
+			sb.WriteString(fmt.Sprintf("               %s\n", h.BundleSum))
+		}
+	}
+	sb.WriteString("\nA piece's Share line matches the Checksum line in its share block; use it\n")
+	sb.WriteString("to tell which friend a piece someone sends you belongs to.\n")
+	switch k.Privacy {
+	case project.PrivacyAnonymous:
+		sb.WriteString("This project is anonymous: the bundles don't name the other friends, so\n")
+		sb.WriteString("this list is the only record of who has a piece.\n")
+	case project.PrivacyFirstNames:
+		sb.WriteString("The bundles name the other friends by first name only, without contact\n")
+		sb.WriteString("details, so this list is the only record of how to reach them.\n")
+	}
+
+	section("FILES OF THIS SEAL")
+	for _, a := range k.Artifacts {
+		sb.WriteString(fmt.Sprintf("  %s\n    %s\n", a.Name, a.Checksum))
+	}
+	if len(k.Superseded) > 0 {
+		sb.WriteString("\nEarlier seals, whose bundles these replace:\n")
+		for _, g := range k.Superseded {
+			sb.WriteString(fmt.Sprintf("  %s   %s\n", g.Sealed.Format(core.DateFormat), g.Fingerprint))
+		}
+	}
+
+	if k.LockedPassphrase != "" {
+		section("PASSPHRASE (LOCKED WITH YOUR PASSWORD)")
+		sb.WriteString("The passphrase that opens MANIFEST.age, encrypted with age. Copy the block\n")
+		sb.WriteString("into a file, or type it in, and unlock it with your password:\n\n")
+		sb.WriteString("  age -d passphrase.txt\n\n")
+		sb.WriteString(k.LockedPassphrase)
+		if !strings.HasSuffix(k.LockedPassphrase, "\n") {
+			sb.WriteString("\n")
+		}
+	}
+
+	section("IF YOUR COMPUTER IS LOST")
+	steps := []string{
+		"Install rememory again (https://github.com/eljojo/rememory). Any later\n   version reads these bundles.",
+		fmt.Sprintf("Get the files back. Ask %d of the friends above for their pieces and\n   run 'rememory recover' on them, or open recover.html from any bundle.", k.Threshold),
+	}
+	if k.Groups != nil {
+		steps[1] = fmt.Sprintf("Get the files back. Ask friends from %d of the groups above, as many\n   of each as it needs, for their pieces and run 'rememory recover' on\n   them, or open recover.html from any bundle.", k.Threshold)
+	}
+	if k.LockedPassphrase != "" {
+		steps[1] += "\n   With the passphrase above and a bundle that has MANIFEST.age,\n   'age -d MANIFEST.age | tar xz' works too."
+	}
+	steps = append(steps,
+		"Start a new project with 'rememory init', put the files in manifest/, and\n   add the friends listed above with 'rememory friend add' or in project.yml.",
+		"Seal it with 'rememory seal', and give every friend their new bundle.\n   The old project folder is gone, so the new bundles can't say which seal\n   they replace: tell friends yourself, using the fingerprints on this page.",
+		"Ask friends to destroy their old bundles, and make a new emergency kit\n   with 'rememory emergency-kit'.",
+	)
+	for i, step := range steps {
+		sb.WriteString(fmt.Sprintf("%d. %s\n", i+1, step))
+	}
+
+	sb.WriteString("\n" + rule)
+	sb.WriteString(fmt.Sprintf("Made %s. Make a new kit whenever you seal again or change friends.\n", k.Made.UTC().Format(core.DateFormat)))
+	sb.WriteString(rule)
+	return sb.String()
+}
@@ -0,0 +1,95 @@
+package kit
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"filippo.io/age/armor"
+	"github.com/eljojo/rememory/internal/core"
+	"github.com/eljojo/rememory/internal/rotation"
+)
+
+func testKit() *Kit {
+	return &Kit{
+		Project:          "Family",
+		Made:             time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC),
+		Version:          "v1.2.3",
+		Sealed:           time.Date(2026, 1, 2, 3, 4, 0, 0, time.UTC),
+		ManifestChecksum: "sha256:ab12cd34ef560789bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb",
+		Threshold:        2,
+		Total:            3,
+		RecoveryURL:      "https://files.example.org/recover.html",
+		Expires:          time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC),
+		Holders: []Holder{
+			{Name: "Alice", Contact: "alice@example.com", Index: 1, ShareChecksum: "sha256:aaaa", Bundle: "bundle-alice.zip", BundleSum: "sha256:bbbb"},
+			{Name: "Bob", Location: "Lisbon", Index: 2, ShareChecksum: "sha256:cccc"},
+		},
+		Artifacts:  []Artifact{{Name: "MANIFEST.age", Checksum: "sha256:ab12cd34"}},
+		Superseded: []rotation.Generation{{Sealed: time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC), Fingerprint: "3f9a12c0b7e455d1"}},
+	}
+}
+
+func TestKitText(t *testing.T) {
+	text := testKit().Text()
+	for _, want := range []string{
+		"EMERGENCY KIT FOR FAMILY",
+		"Fingerprint:       ab12cd34ef560789",
+		"Pieces needed:     2 of 3",
+		"Recovery page:     https://files.example.org/recover.html",
+		"Expires:           2030-01-01",
+		"  1. Alice\n     Contact:  alice@example.com\n     Share:    sha256:aaaa\n     Bundle:   bundle-alice.zip",
+		"     Location: Lisbon",
+		"  2025-03-01   3f9a12c0b7e455d1",
+		"Ask 2 of the friends above",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("kit is missing %q", want)
+		}
+	}
+	if strings.Contains(text, "PASSPHRASE") {
+		t.Error("kit without a passphrase has a passphrase section")
+	}
+	for i, line := range strings.Split(text, "\n") {
+		if len([]rune(line)) > 80 {
+			t.Errorf("line %d is %d characters: %q", i+1, len([]rune(line)), line)
+		}
+	}
+}
+
+func TestLockedPassphrase(t *testing.T) {
+	locked, err := LockPassphrase([]byte("the-passphrase"), "a long owner password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	k := testKit()
+	k.LockedPassphrase = locked
+	text := k.Text()
+	if !strings.Contains(text, "-----BEGIN AGE ENCRYPTED FILE-----") || strings.Contains(text, "the-passphrase") {
+		t.Fatalf("passphrase section:\n%s", text)
+	}
+
+	// The block can be cut out of the printed page and unlocked
+	start := strings.Index(text, "-----BEGIN AGE")
+	end := strings.Index(text, "-----END AGE ENCRYPTED FILE-----") + len("-----END AGE ENCRYPTED FILE-----")
+	got, err := unlock(text[start:end], "a long owner password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "the-passphrase" {
+		t.Errorf("unlocked %q", got)
+	}
+	if _, err := unlock(locked, "wrong password"); err == nil {
+		t.Error("wrong password accepted")
+	}
+}
+
+// unlock is what 'age -d' does with the block.
+func unlock(locked, password string) (string, error) {
+	var buf bytes.Buffer
+	if err := core.Decrypt(&buf, armor.NewReader(strings.NewReader(locked)), password); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
@@ -3,11 +3,15 @@ package manifest
 import (
 	"archive/tar"
 	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/eljojo/rememory/internal/core"
 )
@@ -16,18 +20,45 @@ import (
 type ArchiveResult struct {
 	// Warnings contains messages about files that were skipped (symlinks, etc.)
 	Warnings []string
+	// Ignored lists what .rememoryignore and the exclude patterns left out,
+	// as named in the archive, with a slash after folders
+	Ignored []string
+	// Files lists every regular file archived, with its size and checksum
+	Files []File
+	// Duplicates counts the files stored as a hard link to an identical
+	// one archived before them, and DuplicateBytes their size
+	Duplicates     int
+	DuplicateBytes int64
 }
 
 // Archive creates a tar.gz archive of the given directory.
 // The archive preserves the directory structure relative to the source.
+// Payloads are added after the directory's files, directly inside it, and
+// the index of every file (see core.Index) after them. A file identical to
+// one archived before it is stored once: it goes in as a hard link to the
+// first, and extraction copies the content back. Each entry records its
+// permissions and modification time, which Extract restores.
 // Returns warnings about any skipped files (symlinks, special files, etc.)
-func Archive(w io.Writer, sourceDir string) (*ArchiveResult, error) {
+// Symlinks are skipped; ArchiveAs takes a policy for them with ig.
+func Archive(w io.Writer, sourceDir string, payloads ...*Payload) (*ArchiveResult, error) {
+	return ArchiveAs(w, sourceDir, "", nil, payloads...)
+}
+
+// ArchiveAs archives sourceDir as Archive does, but under the folder name
+// root instead of the directory's own name, where an empty root keeps it,
+// leaving out what ig matches, archiving symlinks as ig's Symlinks says,
+// and with ig's Xattrs, the extended attributes of files and folders, as
+// PAX records that GNU tar and bsdtar read too.
+func ArchiveAs(w io.Writer, sourceDir, root string, ig *Ignore, payloads ...*Payload) (*ArchiveResult, error) {
 	result := &ArchiveResult{}
 
-	sourceDir, err := filepath.Abs(sourceDir)
+	sourceDir, err := AbsPath(sourceDir)
 	if err != nil {
 		return nil, fmt.Errorf("resolving path: %w", err)
 	}
+	if root == "" {
+		root = filepath.Base(sourceDir)
+	}
 
 	info, err := os.Stat(sourceDir)
 	if err != nil {
@@ -37,50 +68,133 @@ func Archive(w io.Writer, sourceDir string) (*ArchiveResult, error) {
 		return nil, fmt.Errorf("not a directory: %s", sourceDir)
 	}
 
-	gzw := gzip.NewWriter(w)
-	defer gzw.Close()
+	// Only a file whose size another shares can be a copy; those are
+	// hashed before they're written, to find the copies
+	sizes, err := fileSizes(sourceDir, ig)
+	if err != nil {
+		return nil, fmt.Errorf("walking directory: %w", err)
+	}
+	stored := make(map[string]string) // By SHA-256, the name first archived with it
+	var xattrFailures []string
 
+	gzw := gzip.NewWriter(w)
 	tw := tar.NewWriter(gzw)
-	defer tw.Close()
+	index := core.NewIndex()
 
-	err = filepath.Walk(sourceDir, func(path string, info os.FileInfo, err error) error {
+	err = walk(sourceDir, ig, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
 		}
 
 		// Compute relative path for display
-		relPath, err := filepath.Rel(filepath.Dir(sourceDir), path)
+		relPath, err := filepath.Rel(sourceDir, path)
 		if err != nil {
 			return fmt.Errorf("computing relative path: %w", err)
 		}
+		relPath = filepath.Join(root, relPath)
+
+		if skip, err := ig.skip(sourceDir, path, info); skip {
+			if info.IsDir() {
+				relPath += "/"
+			}
+			result.Ignored = append(result.Ignored, filepath.ToSlash(relPath))
+			return err
+		}
 
-		// Check for symlinks and other special files
+		// Symlinks go as ig's policy says; other special files never go
 		mode := info.Mode()
-		if mode&os.ModeSymlink != 0 {
+		var link string
+		switch {
+		case mode&os.ModeSymlink != 0 && ig.symlinks() == SymlinksKeep:
+			target, within, err := linkTarget(sourceDir, path)
+			if err != nil {
+				return err
+			}
+			if !within {
+				result.Warnings = append(result.Warnings,
+					fmt.Sprintf("keeping symlink: %s -> %s (it points outside the folder, so it won't lead anywhere once recovered)", relPath, target))
+			}
+			link = target
+		case mode&os.ModeSymlink != 0 && ig.symlinks() == SymlinksFollow:
+			// walk passes on the links it can't follow
+			reason := "it points to nothing"
+			if _, err := os.Stat(path); err == nil {
+				reason = "it points to a folder it is in"
+			}
 			result.Warnings = append(result.Warnings,
-				fmt.Sprintf("skipping symlink: %s (symlinks are not preserved for security)", relPath))
+				fmt.Sprintf("skipping symlink: %s (%s)", relPath, reason))
 			return nil
-		}
-		if !mode.IsRegular() && !mode.IsDir() {
+		case mode&os.ModeSymlink != 0:
+			result.Warnings = append(result.Warnings,
+				fmt.Sprintf("skipping symlink: %s (seal with --symlinks keep or follow to include it)", relPath))
+			return nil
+		case !mode.IsRegular() && !mode.IsDir():
 			typeName := describeFileType(mode)
 			result.Warnings = append(result.Warnings,
-				fmt.Sprintf("skipping %s: %s (only regular files and directories are archived)", typeName, relPath))
+				fmt.Sprintf("skipping %s: %s (only regular files, directories, and symlinks are archived)", typeName, relPath))
 			return nil
 		}
 
 		// Create tar header
-		header, err := tar.FileInfoHeader(info, "")
+		header, err := tar.FileInfoHeader(info, link)
 		if err != nil {
 			return fmt.Errorf("creating header for %s: %w", path, err)
 		}
 
-		header.Name = relPath
+		header.Name = filepath.ToSlash(relPath)
+
+		// Reproducible archives for test fixtures
+		if core.Deterministic() {
+			header.ModTime = core.Now()
+			header.AccessTime = time.Time{}
+			header.ChangeTime = time.Time{}
+			header.Uid, header.Gid = 0, 0
+			header.Uname, header.Gname = "", ""
+		}
 
 		// Ensure directory entries end with /
 		if info.IsDir() {
 			header.Name += "/"
 		}
 
+		if ig != nil && ig.Xattrs && (mode.IsRegular() || mode.IsDir()) {
+			attrs, err := readXattrs(path)
+			if err != nil {
+				xattrFailures = append(xattrFailures, fmt.Sprintf("%s: %v", relPath, err))
+			}
+			for name, value := range attrs {
+				if header.PAXRecords == nil {
+					header.PAXRecords = make(map[string]string)
+				}
+				header.PAXRecords[xattrRecord+name] = value
+			}
+		}
+
+		shared := mode.IsRegular() && info.Size() > 0 && sizes[info.Size()] > 1
+		if shared {
+			sum, err := hashFile(path)
+			if err != nil {
+				return err
+			}
+			if first, ok := stored[string(sum)]; ok {
+				header.Typeflag = tar.TypeLink
+				header.Linkname = first
+				header.Size = 0
+				if err := tw.WriteHeader(header); err != nil {
+					return fmt.Errorf("writing header for %s: %w", path, err)
+				}
+				result.Files = append(result.Files, File{
+					Path:     filepath.ToSlash(relPath),
+					Size:     info.Size(),
+					Checksum: "sha256:" + hex.EncodeToString(sum),
+				})
+				index.Add(header.Name, info.Size(), sum, header.ModTime)
+				result.Duplicates++
+				result.DuplicateBytes += info.Size()
+				return nil
+			}
+		}
+
 		if err := tw.WriteHeader(header); err != nil {
 			return fmt.Errorf("writing header for %s: %w", path, err)
 		}
@@ -96,9 +210,21 @@ func Archive(w io.Writer, sourceDir string) (*ArchiveResult, error) {
 		}
 		defer f.Close()
 
-		if _, err := io.Copy(tw, f); err != nil {
+		h := sha256.New()
+		size, err := io.Copy(io.MultiWriter(tw, h), f)
+		if err != nil {
 			return fmt.Errorf("copying %s: %w", path, err)
 		}
+		result.Files = append(result.Files, File{
+			Path:     filepath.ToSlash(relPath),
+			Size:     size,
+			Checksum: "sha256:" + hex.EncodeToString(h.Sum(nil)),
+		})
+		index.Add(header.Name, size, h.Sum(nil), header.ModTime)
+		// By what was written, should the file have changed since hashing
+		if shared {
+			stored[string(h.Sum(nil))] = header.Name
+		}
 
 		return nil
 	})
@@ -106,10 +232,128 @@ func Archive(w io.Writer, sourceDir string) (*ArchiveResult, error) {
 	if err != nil {
 		return nil, fmt.Errorf("walking directory: %w", err)
 	}
+	if len(xattrFailures) > 0 {
+		result.Warnings = append(result.Warnings,
+			fmt.Sprintf("couldn't read the extended attributes of %d files or folders, sealed without them (%s)", len(xattrFailures), xattrFailures[0]))
+	}
+
+	for _, pl := range payloads {
+		file, err := archivePayload(tw, root, pl, result.Files, index)
+		if err != nil {
+			return nil, err
+		}
+		result.Files = append(result.Files, file)
+	}
+	if err := writeIndex(tw, index); err != nil {
+		return nil, err
+	}
+
+	// Closing flushes the last blocks, so a full disk shows up here
+	if err := tw.Close(); err != nil {
+		return nil, fmt.Errorf("finishing archive: %w", err)
+	}
+	if err := gzw.Close(); err != nil {
+		return nil, fmt.Errorf("finishing compression: %w", err)
+	}
 
 	return result, nil
 }
 
+// archivePayload writes pl into the archive as a file in the root folder,
+// refusing a name already taken by one of the files archived so far, and
+// adds it to index.
+func archivePayload(tw *tar.Writer, root string, pl *Payload, archived []File, index *core.Index) (File, error) {
+	name := root + "/" + pl.Name
+	for _, f := range archived {
+		if f.Path == name {
+			return File{}, fmt.Errorf("%s is already in the manifest; choose another --name", name)
+		}
+	}
+
+	r, err := pl.open()
+	if err != nil {
+		return File{}, fmt.Errorf("reading %s: %w", pl.Name, err)
+	}
+	defer r.Close()
+
+	header := &tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     name,
+		Mode:     0600,
+		Size:     pl.Size,
+		ModTime:  core.Now(),
+	}
+	if err := tw.WriteHeader(header); err != nil {
+		return File{}, fmt.Errorf("writing header for %s: %w", name, err)
+	}
+	h := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(tw, h), r); err != nil {
+		return File{}, fmt.Errorf("copying %s: %w", name, err)
+	}
+	index.Add(name, pl.Size, h.Sum(nil), header.ModTime)
+	return File{
+		Path:     name,
+		Size:     pl.Size,
+		Checksum: "sha256:" + hex.EncodeToString(h.Sum(nil)),
+		Source:   pl.Source,
+	}, nil
+}
+
+// writeIndex writes the index as the archive's last entry.
+func writeIndex(tw *tar.Writer, index *core.Index) error {
+	data, err := index.Encode()
+	if err != nil {
+		return fmt.Errorf("encoding the file index: %w", err)
+	}
+	header := &tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     core.IndexFile,
+		Mode:     0600,
+		Size:     int64(len(data)),
+		ModTime:  core.Now(),
+	}
+	if err := tw.WriteHeader(header); err != nil {
+		return fmt.Errorf("writing the file index: %w", err)
+	}
+	if _, err := tw.Write(data); err != nil {
+		return fmt.Errorf("writing the file index: %w", err)
+	}
+	return nil
+}
+
+// fileSizes counts the regular files of each size in dir, leaving out what
+// ig matches.
+func fileSizes(dir string, ig *Ignore) (map[int64]int, error) {
+	sizes := make(map[int64]int)
+	err := walk(dir, ig, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if skip, err := ig.skip(dir, path, info); skip {
+			return err
+		}
+		if info.Mode().IsRegular() {
+			sizes[info.Size()]++
+		}
+		return nil
+	})
+	return sizes, err
+}
+
+// hashFile returns the SHA-256 sum of the file at path.
+func hashFile(path string) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("opening %s: %w", path, err)
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+	return h.Sum(nil), nil
+}
+
 // describeFileType returns a human-readable description of a file type.
 func describeFileType(mode os.FileMode) string {
 	switch {
@@ -136,31 +380,80 @@ type ExtractResult struct {
 	Path string
 	// Warnings contains messages about files that were skipped (symlinks, etc.)
 	Warnings []string
+	// Index is how each file compared to the archive's index; nil for an
+	// archive sealed without one
+	Index []core.IndexCheck
+}
+
+// extractedLink is a symlink Extract makes at path once the files are out,
+// named name in the archive (after any renaming for case).
+type extractedLink struct {
+	path, target, name string
 }
 
-// Extract unpacks a tar.gz archive to the destination directory.
-// Returns the path to the extracted directory and any warnings about skipped files.
-func Extract(r io.Reader, destDir string) (*ExtractResult, error) {
+// xattrRecord starts the PAX records that hold extended attributes, as GNU
+// tar and bsdtar write them.
+const xattrRecord = "SCHILY.xattr."
+
+// keptXattr reports whether the extended attribute name is sealed and
+// restored: all but Linux's security., system., and trusted. ones. SELinux
+// labels and ACLs belong to the computer they were set on, and a file
+// capability restored as root would be as dangerous as setuid.
+func keptXattr(name string) bool {
+	for _, ns := range []string{"security.", "system.", "trusted."} {
+		if strings.HasPrefix(name, ns) {
+			return false
+		}
+	}
+	return name != ""
+}
+
+// extractedEntry is a file or folder Extract wrote at path, whose
+// permissions, time, and extended attributes it restores from header once
+// everything is out.
+type extractedEntry struct {
+	path   string
+	header *tar.Header
+}
+
+// Extract unpacks a tar.gz archive to the destination directory as it reads
+// it, checking each file against the archive's index as it is written. Symlinks are made
+// again when they point within the archive's folder, and skipped when they
+// don't. A hard link to a file before it, which is how ArchiveAs stores a
+// copy, comes out as a copy of that file.
+// With metadata, each file and folder then gets back the permissions,
+// modification time, and extended attributes the archive records, but
+// never setuid, setgid, or sticky bits; without it, they are made as any
+// new file is, at the time they're written.
+// Returns the path to the extracted directory and any warnings about skipped
+// files, or files that don't match the index.
+func Extract(r io.Reader, destDir string, metadata bool) (*ExtractResult, error) {
 	result := &ExtractResult{}
 
-	destDir, err := filepath.Abs(destDir)
+	destDir, err := AbsPath(destDir)
 	if err != nil {
 		return nil, fmt.Errorf("resolving path: %w", err)
 	}
 
-	if err := os.MkdirAll(destDir, 0755); err != nil {
-		return nil, fmt.Errorf("creating destination: %w", err)
-	}
-
+	// Made once the archive starts, so a stream that fails to decrypt
+	// leaves nothing behind
 	gzr, err := gzip.NewReader(r)
 	if err != nil {
 		return nil, fmt.Errorf("creating gzip reader: %w", err)
 	}
 	defer gzr.Close()
+	if err := os.MkdirAll(destDir, 0755); err != nil {
+		return nil, fmt.Errorf("creating destination: %w", err)
+	}
 
 	tr := tar.NewReader(gzr)
 	var rootDir string
-	var totalSize int64
+	names := core.NewCaseNames()
+	var index *core.Index
+	var hashes []core.FileHash
+	var links []extractedLink
+	var entries []extractedEntry
+	written := make(map[string]string) // Each file's path on disk, by its name in the archive
 
 	for {
 		header, err := tr.Next()
@@ -171,13 +464,31 @@ func Extract(r io.Reader, destDir string) (*ExtractResult, error) {
 			return nil, fmt.Errorf("reading tar: %w", err)
 		}
 
+		if header.Name == core.IndexFile && header.Typeflag == tar.TypeReg {
+			if index, err = core.ReadIndex(tr); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		// Names that collide on case-insensitive file systems are renamed
+		// the same way everywhere, so no file replaces another on a Mac
+		name := header.Name
+		if placedType(header.Typeflag) {
+			var renamed bool
+			if name, renamed = names.Place(name); renamed {
+				result.Warnings = append(result.Warnings,
+					fmt.Sprintf("extracting %s as %s: another name in the archive differs from it only in case", header.Name, name))
+			}
+		}
+
 		// Track the root directory
-		parts := strings.Split(header.Name, string(filepath.Separator))
+		parts := strings.Split(name, "/")
 		if len(parts) > 0 && rootDir == "" {
 			rootDir = parts[0]
 		}
 
-		target := filepath.Join(destDir, header.Name)
+		target := filepath.Join(destDir, name)
 
 		// Security: prevent path traversal
 		if !strings.HasPrefix(filepath.Clean(target)+string(filepath.Separator), filepath.Clean(destDir)+string(filepath.Separator)) {
@@ -186,32 +497,26 @@ func Extract(r io.Reader, destDir string) (*ExtractResult, error) {
 
 		switch header.Typeflag {
 		case tar.TypeDir:
-			if err := os.MkdirAll(target, os.FileMode(header.Mode)&0777); err != nil {
+			// Made writable; its own permissions come once it's filled
+			if err := os.MkdirAll(target, 0777); err != nil {
 				return nil, fmt.Errorf("creating directory %s: %w", target, err)
 			}
+			entries = append(entries, extractedEntry{target, header})
 
 		case tar.TypeReg:
-			// Security: enforce file size limit
-			if header.Size > core.MaxFileSize {
-				return nil, fmt.Errorf("file exceeds maximum size of %d bytes", core.MaxFileSize)
-			}
-			totalSize += header.Size
-			if totalSize > core.MaxTotalSize {
-				return nil, fmt.Errorf("archive exceeds maximum total size of %d bytes", core.MaxTotalSize)
-			}
-
+			// Files go to disk as they come, so no size is limited,
+			// unlike in memory, where core.ExtractTarGz limits them
 			if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
 				return nil, fmt.Errorf("creating parent directory: %w", err)
 			}
 
-			f, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, os.FileMode(header.Mode)&0666)
+			f, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0666)
 			if err != nil {
 				return nil, fmt.Errorf("creating file %s: %w", target, err)
 			}
 
-			// Use LimitReader to enforce size limit during actual copy
-			limitedReader := io.LimitReader(tr, core.MaxFileSize+1)
-			written, err := io.Copy(f, limitedReader)
+			h := sha256.New()
+			n, err := io.Copy(io.MultiWriter(f, h), tr)
 			closeErr := f.Close()
 			if err != nil {
 				return nil, fmt.Errorf("writing file %s: %w", target, err)
@@ -219,17 +524,37 @@ func Extract(r io.Reader, destDir string) (*ExtractResult, error) {
 			if closeErr != nil {
 				return nil, fmt.Errorf("closing file %s: %w", target, closeErr)
 			}
-			if written > core.MaxFileSize {
-				return nil, fmt.Errorf("file exceeds maximum size during extraction")
-			}
+			hashes = append(hashes, core.FileHash{Path: header.Name, Size: n, Sum: h.Sum(nil)})
+			written[header.Name] = target
+			entries = append(entries, extractedEntry{target, header})
 
 		case tar.TypeSymlink:
-			result.Warnings = append(result.Warnings,
-				fmt.Sprintf("skipping symlink in archive: %s (symlinks not extracted for security)", header.Name))
+			// Made once everything else is out, so nothing is written
+			// through one
+			_, inRoot, _ := strings.Cut(name, "/")
+			if !linkWithin(inRoot, header.Linkname) {
+				result.Warnings = append(result.Warnings,
+					fmt.Sprintf("skipping symlink in archive: %s -> %s (it points outside the recovered folder)", header.Name, header.Linkname))
+				continue
+			}
+			links = append(links, extractedLink{target, header.Linkname, name})
 
 		case tar.TypeLink:
-			result.Warnings = append(result.Warnings,
-				fmt.Sprintf("skipping hard link in archive: %s (hard links not extracted for security)", header.Name))
+			// Copied rather than linked, so changing one later doesn't
+			// change the other, and only from a file already written
+			source, ok := written[header.Linkname]
+			if !ok {
+				result.Warnings = append(result.Warnings,
+					fmt.Sprintf("skipping hard link in archive: %s (it isn't to a file before it)", header.Name))
+				continue
+			}
+			n, sum, err := copyFile(source, target, 0666)
+			if err != nil {
+				return nil, err
+			}
+			hashes = append(hashes, core.FileHash{Path: header.Name, Size: n, Sum: sum})
+			written[header.Name] = target
+			entries = append(entries, extractedEntry{target, header})
 
 		default:
 			typeName := describeTarType(header.Typeflag)
@@ -242,10 +567,113 @@ func Extract(r io.Reader, destDir string) (*ExtractResult, error) {
 		return nil, fmt.Errorf("empty archive")
 	}
 
+	// Links are placed as files, so none is inside another, and the
+	// folders they're in were made without going through one
+	for _, l := range links {
+		if err := os.MkdirAll(filepath.Dir(l.path), 0755); err != nil {
+			return nil, fmt.Errorf("creating parent directory: %w", err)
+		}
+		if err := os.Symlink(filepath.FromSlash(l.target), l.path); err != nil {
+			result.Warnings = append(result.Warnings,
+				fmt.Sprintf("couldn't make symlink %s -> %s: %v", l.name, l.target, err))
+		}
+	}
+	if metadata {
+		result.Warnings = append(result.Warnings, restoreMetadata(entries)...)
+	}
+
+	if index != nil {
+		result.Index = index.Check(hashes)
+		for _, c := range core.Problems(result.Index) {
+			if c.Status == core.IndexMissing {
+				result.Warnings = append(result.Warnings, fmt.Sprintf("%s is listed in the archive's index but isn't in the archive", c.Path))
+			} else {
+				result.Warnings = append(result.Warnings, fmt.Sprintf("%s doesn't match the archive's index: it may be damaged", c.Path))
+			}
+		}
+	}
+
 	result.Path = filepath.Join(destDir, rootDir)
 	return result, nil
 }
 
+// restoreMetadata gives each entry back the permissions, modification time,
+// and extended attributes its header records, returning warnings for what
+// couldn't be. It goes backwards through the archive, so each folder comes
+// after everything in it: filling a folder changes its time, and one made
+// read-only can't be filled. Attributes that can't be set are summed up in
+// one warning, since an archive sealed on a Mac may carry some in every
+// file that Linux refuses.
+func restoreMetadata(entries []extractedEntry) []string {
+	var warnings, xattrFailures []string
+	for i := len(entries) - 1; i >= 0; i-- {
+		e := entries[i]
+		for key, value := range e.header.PAXRecords {
+			if name, ok := strings.CutPrefix(key, xattrRecord); ok && keptXattr(name) {
+				if err := writeXattr(e.path, name, value); err != nil {
+					xattrFailures = append(xattrFailures, fmt.Sprintf("%s on %s: %v", name, e.header.Name, err))
+				}
+			}
+		}
+		if err := os.Chmod(e.path, os.FileMode(e.header.Mode)&os.ModePerm); err != nil {
+			warnings = append(warnings, fmt.Sprintf("couldn't restore the permissions of %s: %v", e.header.Name, err))
+		}
+		if e.header.ModTime.IsZero() {
+			continue
+		}
+		accessed := e.header.AccessTime
+		if accessed.IsZero() {
+			accessed = e.header.ModTime
+		}
+		if err := os.Chtimes(e.path, accessed, e.header.ModTime); err != nil {
+			warnings = append(warnings, fmt.Sprintf("couldn't restore the time of %s: %v", e.header.Name, err))
+		}
+	}
+	if len(xattrFailures) > 0 {
+		slices.Sort(xattrFailures)
+		warnings = append(warnings,
+			fmt.Sprintf("couldn't restore %d extended attributes (%s)", len(xattrFailures), xattrFailures[0]))
+	}
+	return warnings
+}
+
+// placedType reports whether entries of a tar type are placed through
+// core.CaseNames: everything Extract writes out.
+func placedType(typeflag byte) bool {
+	switch typeflag {
+	case tar.TypeDir, tar.TypeReg, tar.TypeSymlink, tar.TypeLink:
+		return true
+	}
+	return false
+}
+
+// copyFile copies the file at source to target, made with perm, returning
+// its size and SHA-256 sum as written.
+func copyFile(source, target string, perm os.FileMode) (int64, []byte, error) {
+	in, err := os.Open(source)
+	if err != nil {
+		return 0, nil, fmt.Errorf("opening %s: %w", source, err)
+	}
+	defer in.Close()
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return 0, nil, fmt.Errorf("creating parent directory: %w", err)
+	}
+	out, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, perm)
+	if err != nil {
+		return 0, nil, fmt.Errorf("creating file %s: %w", target, err)
+	}
+	h := sha256.New()
+	n, err := io.Copy(io.MultiWriter(out, h), in)
+	closeErr := out.Close()
+	if err != nil {
+		return 0, nil, fmt.Errorf("writing file %s: %w", target, err)
+	}
+	if closeErr != nil {
+		return 0, nil, fmt.Errorf("closing file %s: %w", target, closeErr)
+	}
+	return n, h.Sum(nil), nil
+}
+
 // describeTarType returns a human-readable description of a tar entry type.
 func describeTarType(typeflag byte) string {
 	switch typeflag {
@@ -264,13 +692,92 @@ func describeTarType(typeflag byte) string {
 	}
 }
 
-// CountFiles counts the number of regular files in a directory.
-func CountFiles(dir string) (int, error) {
+// Check looks over a directory before it is sealed. It fails when there is
+// nothing in it at all, or when names in it differ only in case (see
+// core.CaseCollisions), and warns about what would come back looking
+// different from what was meant: empty files, and empty folders, which are
+// kept, but on their own are all that recovery would bring back. Payloads
+// count as files; what ig matches doesn't.
+func Check(dir string, ig *Ignore, payloads ...*Payload) (warnings []string, err error) {
+	var files, folders int
+	var empty, names []string
+	err = walk(dir, ig, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if skip, err := ig.skip(dir, path, info); skip {
+			return err
+		}
+		rel, err := filepath.Rel(filepath.Dir(dir), path)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if info.IsDir() {
+			names = append(names, rel+"/")
+		} else {
+			names = append(names, rel)
+		}
+		switch {
+		case info.Mode().IsRegular(), info.Mode()&os.ModeSymlink != 0 && ig.symlinks() == SymlinksKeep:
+			files++
+			if info.Size() == 0 {
+				warnings = append(warnings, fmt.Sprintf("%s is empty (0 bytes): only its name is kept", rel))
+			}
+		case info.IsDir() && path != dir:
+			folders++
+			entries, err := os.ReadDir(path)
+			if err != nil {
+				return err
+			}
+			if len(entries) == 0 {
+				empty = append(empty, rel+"/")
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("checking manifest directory: %w", err)
+	}
+
+	for _, pl := range payloads {
+		files++
+		names = append(names, filepath.Base(dir)+"/"+pl.Name)
+		if pl.Size == 0 {
+			warnings = append(warnings, fmt.Sprintf("%s is empty (0 bytes): nothing was read from %s", pl.Name, pl.Source))
+		}
+	}
+
+	if files == 0 && folders == 0 {
+		return nil, fmt.Errorf("manifest directory is empty: %s", dir)
+	}
+	if collisions := core.CaseCollisions(names); len(collisions) > 0 {
+		lines := make([]string, len(collisions))
+		for i, group := range collisions {
+			lines[i] = strings.Join(group, ", ")
+		}
+		return nil, fmt.Errorf("these names differ only in case, so on a Mac or Windows computer one would overwrite the other when recovered; rename all but one of each:\n  %s", strings.Join(lines, "\n  "))
+	}
+	if files == 0 {
+		warnings = append(warnings, "there are no files, only folders: recovery brings back the folders and nothing else")
+	}
+	for _, folder := range empty {
+		warnings = append(warnings, fmt.Sprintf("%s is an empty folder: it's kept, and comes back empty", folder))
+	}
+	return warnings, nil
+}
+
+// CountFiles counts the number of regular files in a directory, leaving
+// out what ig matches.
+func CountFiles(dir string, ig *Ignore) (int, error) {
 	count := 0
-	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+	err := walk(dir, ig, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
 		}
+		if skip, err := ig.skip(dir, path, info); skip {
+			return err
+		}
 		if info.Mode().IsRegular() {
 			count++
 		}
@@ -279,13 +786,17 @@ func CountFiles(dir string) (int, error) {
 	return count, err
 }
 
-// DirSize calculates the total size of all files in a directory.
-func DirSize(dir string) (int64, error) {
+// DirSize calculates the total size of all files in a directory, leaving
+// out what ig matches.
+func DirSize(dir string, ig *Ignore) (int64, error) {
 	var size int64
-	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+	err := walk(dir, ig, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
 		}
+		if skip, err := ig.skip(dir, path, info); skip {
+			return err
+		}
 		if info.Mode().IsRegular() {
 			size += info.Size()
 		}
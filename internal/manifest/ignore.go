@@ -0,0 +1,217 @@
+package manifest
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// IgnoreFile is the file in a manifest directory that lists what isn't
+// sealed, one pattern per line in .gitignore's syntax.
+const IgnoreFile = ".rememoryignore"
+
+// Ignore decides which files and folders of a manifest directory are left
+// out of its archive, what is done with the symlinks in it, and whether
+// extended attributes go in. A nil Ignore leaves out nothing but symlinks,
+// and extended attributes.
+type Ignore struct {
+	// Symlinks is what is done with symlinks; empty means SymlinksSkip
+	Symlinks Symlinks
+	// Xattrs records each file's and folder's extended attributes, which
+	// the archive otherwise leaves out
+	Xattrs bool
+
+	rules []ignoreRule
+}
+
+// ignoreRule is one pattern, matched against slash-separated paths relative
+// to the manifest directory.
+type ignoreRule struct {
+	pattern string
+	re      *regexp.Regexp
+	negate  bool // "!pattern" brings back what an earlier pattern left out
+	dirOnly bool // "pattern/" matches folders only
+}
+
+// LoadIgnore reads dir's .rememoryignore, if it has one, and adds the
+// exclude patterns after it, so they win over it.
+func LoadIgnore(dir string, exclude []string) (*Ignore, error) {
+	ig := &Ignore{}
+	data, err := os.ReadFile(filepath.Join(dir, IgnoreFile))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("reading %s: %w", IgnoreFile, err)
+	}
+	for i, line := range strings.Split(string(data), "\n") {
+		if err := ig.add(line); err != nil {
+			return nil, fmt.Errorf("%s, line %d: %w", IgnoreFile, i+1, err)
+		}
+	}
+	for _, pattern := range exclude {
+		if err := ig.add(pattern); err != nil {
+			return nil, fmt.Errorf("exclude %q: %w", pattern, err)
+		}
+	}
+	return ig, nil
+}
+
+// add parses one line of .gitignore syntax. Blank lines and comments add
+// nothing.
+func (ig *Ignore) add(line string) error {
+	line = strings.TrimSuffix(line, "\r")
+	// Trailing spaces don't count, unless escaped with a backslash
+	for strings.HasSuffix(line, " ") && !strings.HasSuffix(line, `\ `) {
+		line = line[:len(line)-1]
+	}
+	if line == "" || strings.HasPrefix(line, "#") {
+		return nil
+	}
+	rule := ignoreRule{pattern: line}
+	if strings.HasPrefix(line, "!") {
+		rule.negate = true
+		line = line[1:]
+	}
+	if strings.HasSuffix(line, "/") {
+		rule.dirOnly = true
+		line = strings.TrimRight(line, "/")
+	}
+	// A slash anywhere but at the end ties the pattern to the manifest
+	// directory; without one it matches a name at any depth
+	anchored := strings.Contains(line, "/")
+	line = strings.TrimPrefix(line, "/")
+	if line == "" {
+		return nil
+	}
+	body, err := globRegexp(line)
+	if err != nil {
+		return err
+	}
+	prefix := "^(?:.*/)?"
+	if anchored {
+		prefix = "^"
+	}
+	if rule.re, err = regexp.Compile(prefix + body + "$"); err != nil {
+		return err
+	}
+	ig.rules = append(ig.rules, rule)
+	return nil
+}
+
+// globRegexp translates a .gitignore pattern into a regular expression:
+// * and ? match within a name, ** matches across folders when it stands
+// between slashes or at either end, [...] is a class of characters, and a
+// backslash takes the next character as it is.
+func globRegexp(pattern string) (string, error) {
+	p := []rune(pattern)
+	var b strings.Builder
+	for i := 0; i < len(p); i++ {
+		switch c := p[i]; c {
+		case '*':
+			if i+1 < len(p) && p[i+1] == '*' {
+				start := i == 0 || p[i-1] == '/'
+				end := i+2 == len(p) || p[i+2] == '/'
+				if start && end {
+					if i+2 == len(p) {
+						b.WriteString(".*")
+					} else {
+						b.WriteString("(?:.*/)?")
+					}
+					i += 2
+					continue
+				}
+				for i+1 < len(p) && p[i+1] == '*' {
+					i++
+				}
+			}
+			b.WriteString("[^/]*")
+		case '?':
+			b.WriteString("[^/]")
+		case '[':
+			class, n := globClass(p[i+1:])
+			if n == 0 {
+				b.WriteString(`\[`)
+				continue
+			}
+			b.WriteString(class)
+			i += n
+		case '\\':
+			if i+1 == len(p) {
+				return "", fmt.Errorf("pattern ends with a backslash")
+			}
+			i++
+			b.WriteString(regexp.QuoteMeta(string(p[i])))
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	return b.String(), nil
+}
+
+// globClass translates the class of characters that p starts with, just
+// after its "[", returning it and how many runes it took, or 0 when the
+// class isn't closed.
+func globClass(p []rune) (string, int) {
+	var b strings.Builder
+	b.WriteString("[")
+	i := 0
+	if i < len(p) && (p[i] == '!' || p[i] == '^') {
+		b.WriteString("^/")
+		i++
+	}
+	for first := true; i < len(p); i++ {
+		c := p[i]
+		switch {
+		case c == ']' && !first:
+			return b.String() + "]", i + 1
+		case c == '\\' && i+1 < len(p):
+			i++
+			b.WriteString(regexp.QuoteMeta(string(p[i])))
+		case c == '-':
+			b.WriteRune('-')
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+		first = false
+	}
+	return "", 0
+}
+
+// Match reports whether rel, a slash-separated path relative to the
+// manifest directory, is left out. As in .gitignore, the last pattern that
+// matches decides. Nothing in a folder that is left out is looked at, so a
+// "!" pattern can't bring back a file inside one.
+func (ig *Ignore) Match(rel string, isDir bool) bool {
+	if ig == nil {
+		return false
+	}
+	ignored := false
+	for _, r := range ig.rules {
+		if r.dirOnly && !isDir {
+			continue
+		}
+		if r.re.MatchString(rel) {
+			ignored = !r.negate
+		}
+	}
+	return ignored
+}
+
+// skip reports whether ig leaves out path, found walking dir, with
+// filepath.SkipDir for a folder so that nothing in it is walked.
+func (ig *Ignore) skip(dir, path string, info os.FileInfo) (bool, error) {
+	if ig == nil || path == dir {
+		return false, nil
+	}
+	rel, err := filepath.Rel(dir, path)
+	if err != nil {
+		return false, fmt.Errorf("computing relative path: %w", err)
+	}
+	if !ig.Match(filepath.ToSlash(rel), info.IsDir()) {
+		return false, nil
+	}
+	if info.IsDir() {
+		return true, filepath.SkipDir
+	}
+	return true, nil
+}
@@ -0,0 +1,118 @@
+package manifest
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+)
+
+// File describes one regular file in the manifest directory.
+type File struct {
+	Path     string `yaml:"path" json:"path"` // Slash-separated, as named in the archive (e.g. "manifest/notes.txt")
+	Size     int64  `yaml:"size" json:"size"`
+	Checksum string `yaml:"checksum" json:"checksum"`                 // "sha256:..."
+	Source   string `yaml:"source,omitempty" json:"source,omitempty"` // SourceStdin, SourceCommand, or SourceVault for a Payload; empty for files from manifest/
+}
+
+// Snapshot lists the regular files ArchiveAs would include from sourceDir,
+// leaving out what ig matches, with their sizes and checksums, without
+// building an archive.
+func Snapshot(sourceDir string, ig *Ignore) ([]File, error) {
+	sourceDir, err := AbsPath(sourceDir)
+	if err != nil {
+		return nil, fmt.Errorf("resolving path: %w", err)
+	}
+
+	var files []File
+	err = walk(sourceDir, ig, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if skip, err := ig.skip(sourceDir, path, info); skip {
+			return err
+		}
+		// Only regular files drift: followed links are seen as what they
+		// point to, and a kept link's target isn't sealed
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+
+		relPath, err := filepath.Rel(filepath.Dir(sourceDir), path)
+		if err != nil {
+			return fmt.Errorf("computing relative path: %w", err)
+		}
+
+		f, err := os.Open(path)
+		if err != nil {
+			return fmt.Errorf("opening %s: %w", path, err)
+		}
+		defer f.Close()
+
+		h := sha256.New()
+		size, err := io.Copy(h, f)
+		if err != nil {
+			return fmt.Errorf("reading %s: %w", path, err)
+		}
+		files = append(files, File{
+			Path:     filepath.ToSlash(relPath),
+			Size:     size,
+			Checksum: "sha256:" + hex.EncodeToString(h.Sum(nil)),
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("walking directory: %w", err)
+	}
+	return files, nil
+}
+
+// Changes lists how a directory differs from an earlier snapshot of it.
+type Changes struct {
+	Added    []string `json:"added"`
+	Removed  []string `json:"removed"`
+	Modified []string `json:"modified"`
+}
+
+// Empty reports whether nothing changed.
+func (c *Changes) Empty() bool {
+	return len(c.Added) == 0 && len(c.Removed) == 0 && len(c.Modified) == 0
+}
+
+// Compare reports the files added, removed, and modified between the old
+// and new listings, each sorted by path. Payloads in old are left out.
+func Compare(old, current []File) *Changes {
+	// Payloads were never on disk, so they can't be compared
+	old = slices.DeleteFunc(slices.Clone(old), func(f File) bool { return f.Source != "" })
+	before := make(map[string]File, len(old))
+	for _, f := range old {
+		before[f.Path] = f
+	}
+
+	changes := &Changes{Added: []string{}, Removed: []string{}, Modified: []string{}}
+	seen := make(map[string]bool, len(current))
+	for _, f := range current {
+		seen[f.Path] = true
+		prev, ok := before[f.Path]
+		switch {
+		case !ok:
+			changes.Added = append(changes.Added, f.Path)
+		case prev.Size != f.Size || prev.Checksum != f.Checksum:
+			changes.Modified = append(changes.Modified, f.Path)
+		}
+	}
+	for _, f := range old {
+		if !seen[f.Path] {
+			changes.Removed = append(changes.Removed, f.Path)
+		}
+	}
+
+	sort.Strings(changes.Added)
+	sort.Strings(changes.Removed)
+	sort.Strings(changes.Modified)
+	return changes
+}
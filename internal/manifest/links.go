@@ -0,0 +1,162 @@
+package manifest
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Symlinks says what sealing does with the symbolic links in a manifest
+// directory.
+type Symlinks string
+
+const (
+	// SymlinksSkip leaves links out, with a warning. It's what a nil Ignore,
+	// or one without a policy, does.
+	SymlinksSkip Symlinks = "skip"
+	// SymlinksKeep stores each link as a link, pointing where it did.
+	// Recovery on a computer makes it again when it points within the
+	// recovered folder.
+	SymlinksKeep Symlinks = "keep"
+	// SymlinksFollow stores what each link points to, under the link's name,
+	// and a linked folder with everything in it.
+	SymlinksFollow Symlinks = "follow"
+)
+
+// SymlinkPolicies lists the policies, in the order they're offered.
+var SymlinkPolicies = []Symlinks{SymlinksSkip, SymlinksKeep, SymlinksFollow}
+
+// ParseSymlinks reads a policy as it's written in project.yml or given to
+// --symlinks. An empty one is SymlinksSkip.
+func ParseSymlinks(s string) (Symlinks, error) {
+	if s == "" {
+		return SymlinksSkip, nil
+	}
+	if !slices.Contains(SymlinkPolicies, Symlinks(s)) {
+		return "", fmt.Errorf("unknown symlinks policy %q (use skip, keep, or follow)", s)
+	}
+	return Symlinks(s), nil
+}
+
+// symlinks returns ig's policy.
+func (ig *Ignore) symlinks() Symlinks {
+	if ig == nil || ig.Symlinks == "" {
+		return SymlinksSkip
+	}
+	return ig.Symlinks
+}
+
+// walk walks dir as filepath.Walk does, except that when ig follows
+// symlinks, a link is passed to fn as what it points to, under the link's
+// own path, and a linked folder is walked there. A link that points to
+// nothing, or to a folder it is in, which would be walked forever, is
+// passed as the link itself.
+func walk(dir string, ig *Ignore, fn filepath.WalkFunc) error {
+	if ig.symlinks() != SymlinksFollow {
+		return filepath.Walk(dir, fn)
+	}
+	info, err := os.Lstat(dir)
+	if err != nil {
+		err = fn(dir, nil, err)
+	} else {
+		err = walkFollowing(dir, info, nil, fn)
+	}
+	if err == filepath.SkipDir || err == filepath.SkipAll {
+		return nil
+	}
+	return err
+}
+
+// walkFollowing walks path for walk, where within holds the real paths of
+// the folders it is in.
+func walkFollowing(path string, info os.FileInfo, within []string, fn filepath.WalkFunc) error {
+	if info.Mode()&os.ModeSymlink != 0 {
+		target, err := os.Stat(path)
+		if err != nil {
+			return fn(path, info, nil)
+		}
+		if target.IsDir() {
+			real, err := filepath.EvalSymlinks(path)
+			if err != nil {
+				return fn(path, info, err)
+			}
+			if slices.Contains(within, real) {
+				return fn(path, info, nil)
+			}
+		}
+		info = target
+	}
+	if !info.IsDir() {
+		return fn(path, info, nil)
+	}
+
+	real, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return fn(path, info, err)
+	}
+	if err := fn(path, info, nil); err != nil {
+		return err
+	}
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return fn(path, info, err)
+	}
+	within = append(within, real)
+	for _, entry := range entries {
+		child := filepath.Join(path, entry.Name())
+		childInfo, err := os.Lstat(child)
+		if err != nil {
+			err = fn(child, nil, err)
+		} else {
+			err = walkFollowing(child, childInfo, within, fn)
+		}
+		if err != nil && err != filepath.SkipDir {
+			return err
+		}
+	}
+	return nil
+}
+
+// linkTarget reads where the link at path points, and reports whether
+// it stays within root once recovered: a relative target that doesn't
+// climb out of it. An absolute one never does, as it names a place on this
+// computer.
+func linkTarget(root, path string) (string, bool, error) {
+	target, err := os.Readlink(path)
+	if err != nil {
+		return "", false, fmt.Errorf("reading symlink %s: %w", path, err)
+	}
+	rel, err := filepath.Rel(root, path)
+	if err != nil {
+		return "", false, fmt.Errorf("computing relative path: %w", err)
+	}
+	slashed := filepath.ToSlash(target)
+	return slashed, linkWithin(filepath.ToSlash(rel), slashed), nil
+}
+
+// linkWithin reports whether a link named name, slash-separated and
+// relative to a folder, points within that folder when it points to target.
+// A ".." after a name is refused, as the name may be another link, which
+// would make it climb from somewhere else than it seems.
+func linkWithin(name, target string) bool {
+	if target == "" || path.IsAbs(target) || filepath.IsAbs(filepath.FromSlash(target)) {
+		return false
+	}
+	named := false
+	for _, part := range strings.Split(target, "/") {
+		switch part {
+		case "..":
+			if named {
+				return false
+			}
+		case "", ".":
+		default:
+			named = true
+		}
+	}
+	resolved := path.Join(path.Dir(name), target)
+	return resolved != ".." && !strings.HasPrefix(resolved, "../")
+}
@@ -4,10 +4,16 @@ import (
 	"archive/tar"
 	"bytes"
 	"compress/gzip"
+	"errors"
+	"io"
 	"os"
 	"path/filepath"
+	"runtime"
 	"strings"
 	"testing"
+	"time"
+
+	"github.com/eljojo/rememory/internal/core"
 )
 
 func TestArchiveExtract(t *testing.T) {
@@ -47,7 +53,7 @@ func TestArchiveExtract(t *testing.T) {
 
 	// Extract to new location
 	dstDir := t.TempDir()
-	extractResult, err := Extract(&buf, dstDir)
+	extractResult, err := Extract(&buf, dstDir, true)
 	if err != nil {
 		t.Fatalf("extract: %v", err)
 	}
@@ -66,6 +72,32 @@ func TestArchiveExtract(t *testing.T) {
 	}
 }
 
+func TestArchiveAs(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "decoy")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("harmless"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	result, err := ArchiveAs(&buf, dir, "manifest", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Files) != 1 || result.Files[0].Path != "manifest/notes.txt" {
+		t.Errorf("files = %+v", result.Files)
+	}
+	extracted, err := Extract(&buf, t.TempDir(), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filepath.Base(extracted.Path) != "manifest" {
+		t.Errorf("extracted to %s, want a manifest folder", extracted.Path)
+	}
+}
+
 func TestArchiveNotDirectory(t *testing.T) {
 	// Create a temp file
 	f, err := os.CreateTemp("", "test")
@@ -130,7 +162,7 @@ func TestExtractPathTraversal(t *testing.T) {
 			t.Run(tt.name, func(t *testing.T) {
 				data := createTarGzBytes(t, map[string]string{tt.entry: "malicious"})
 				destDir := t.TempDir()
-				_, err := Extract(bytes.NewReader(data), destDir)
+				_, err := Extract(bytes.NewReader(data), destDir, true)
 				if err == nil {
 					t.Errorf("expected error for path %q, got nil", tt.entry)
 				}
@@ -152,7 +184,7 @@ func TestExtractPathTraversal(t *testing.T) {
 			"manifest/safe.txt": "safe content",
 		})
 		destDir := t.TempDir()
-		_, err := Extract(bytes.NewReader(data), destDir)
+		_, err := Extract(bytes.NewReader(data), destDir, true)
 		if err != nil {
 			t.Fatalf("unexpected error for safe path: %v", err)
 		}
@@ -175,7 +207,7 @@ func TestExtractPathTraversal(t *testing.T) {
 			"foo/../bar.txt": "resolved content",
 		})
 		destDir := t.TempDir()
-		_, err := Extract(bytes.NewReader(data), destDir)
+		_, err := Extract(bytes.NewReader(data), destDir, true)
 		if err != nil {
 			t.Fatalf("unexpected error for non-escaping dotdot: %v", err)
 		}
@@ -199,7 +231,7 @@ func TestCountFiles(t *testing.T) {
 	os.MkdirAll(filepath.Join(dir, "sub"), 0755)
 	os.WriteFile(filepath.Join(dir, "sub", "c.txt"), []byte("c"), 0644)
 
-	count, err := CountFiles(dir)
+	count, err := CountFiles(dir, nil)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -215,7 +247,7 @@ func TestDirSize(t *testing.T) {
 	os.WriteFile(filepath.Join(dir, "a.txt"), []byte("12345"), 0644)
 	os.WriteFile(filepath.Join(dir, "b.txt"), []byte("67890"), 0644)
 
-	size, err := DirSize(dir)
+	size, err := DirSize(dir, nil)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -235,7 +267,7 @@ func TestArchiveNonexistent(t *testing.T) {
 func TestExtractInvalidGzip(t *testing.T) {
 	// Not valid gzip data
 	data := bytes.NewReader([]byte("not gzip data"))
-	_, err := Extract(data, t.TempDir())
+	_, err := Extract(data, t.TempDir(), true)
 	if err == nil {
 		t.Error("expected error for invalid gzip")
 	}
@@ -247,21 +279,21 @@ func TestExtractEmptyArchive(t *testing.T) {
 	gzw := gzip.NewWriter(&buf)
 	gzw.Close()
 
-	_, err := Extract(&buf, t.TempDir())
+	_, err := Extract(&buf, t.TempDir(), true)
 	if err == nil {
 		t.Error("expected error for empty archive")
 	}
 }
 
 func TestCountFilesNonexistent(t *testing.T) {
-	_, err := CountFiles("/nonexistent/path")
+	_, err := CountFiles("/nonexistent/path", nil)
 	if err == nil {
 		t.Error("expected error for nonexistent directory")
 	}
 }
 
 func TestDirSizeNonexistent(t *testing.T) {
-	_, err := DirSize("/nonexistent/path")
+	_, err := DirSize("/nonexistent/path", nil)
 	if err == nil {
 		t.Error("expected error for nonexistent directory")
 	}
@@ -312,7 +344,7 @@ func TestArchiveSymlinkWarning(t *testing.T) {
 
 	// Extract and verify only regular file is present
 	dstDir := t.TempDir()
-	extractResult, err := Extract(&buf, dstDir)
+	extractResult, err := Extract(&buf, dstDir, true)
 	if err != nil {
 		t.Fatalf("extract: %v", err)
 	}
@@ -328,6 +360,213 @@ func TestArchiveSymlinkWarning(t *testing.T) {
 	}
 }
 
+func TestArchiveSymlinks(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "manifest")
+	os.MkdirAll(filepath.Join(dir, "photos"), 0755)
+	os.WriteFile(filepath.Join(dir, "photos", "cat.jpg"), []byte("meow"), 0644)
+	outside := t.TempDir()
+	os.WriteFile(filepath.Join(outside, "elsewhere.txt"), []byte("far"), 0644)
+	if err := os.Symlink("photos/cat.jpg", filepath.Join(dir, "latest.jpg")); err != nil {
+		t.Skip("symlinks not supported on this platform")
+	}
+	os.Symlink("photos", filepath.Join(dir, "album"))
+	os.Symlink("..", filepath.Join(dir, "photos", "up"))
+	os.Symlink(filepath.Join(outside, "elsewhere.txt"), filepath.Join(dir, "far.txt"))
+	os.Symlink("missing.txt", filepath.Join(dir, "dangling.txt"))
+
+	t.Run("keep", func(t *testing.T) {
+		var buf bytes.Buffer
+		result, err := ArchiveAs(&buf, dir, "", &Ignore{Symlinks: SymlinksKeep})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(result.Warnings) != 1 || !strings.Contains(result.Warnings[0], "far.txt") {
+			t.Errorf("warnings = %q, want one for the link outside", result.Warnings)
+		}
+
+		dest := t.TempDir()
+		extracted, err := Extract(&buf, dest, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if target, err := os.Readlink(filepath.Join(extracted.Path, "latest.jpg")); err != nil || target != "photos/cat.jpg" {
+			t.Errorf("latest.jpg -> %q, %v", target, err)
+		}
+		if data, err := os.ReadFile(filepath.Join(extracted.Path, "album", "cat.jpg")); err != nil || string(data) != "meow" {
+			t.Errorf("album/cat.jpg = %q, %v", data, err)
+		}
+		if _, err := os.Lstat(filepath.Join(extracted.Path, "far.txt")); err == nil {
+			t.Error("a link outside the folder was made")
+		}
+	})
+
+	t.Run("follow", func(t *testing.T) {
+		ig := &Ignore{Symlinks: SymlinksFollow}
+		var buf bytes.Buffer
+		result, err := ArchiveAs(&buf, dir, "", ig)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var paths []string
+		for _, f := range result.Files {
+			paths = append(paths, f.Path)
+		}
+		want := []string{"manifest/album/cat.jpg", "manifest/far.txt", "manifest/latest.jpg", "manifest/photos/cat.jpg"}
+		if strings.Join(paths, " ") != strings.Join(want, " ") {
+			t.Errorf("files = %q, want %q", paths, want)
+		}
+		// The dangling link, and the loop at photos/up and album/up
+		if len(result.Warnings) != 3 {
+			t.Errorf("warnings = %q", result.Warnings)
+		}
+		if n, _ := CountFiles(dir, ig); n != len(want) {
+			t.Errorf("CountFiles = %d, want %d", n, len(want))
+		}
+		snapshot, _ := Snapshot(dir, ig)
+		if len(snapshot) != len(want) {
+			t.Errorf("Snapshot has %d files, want %d", len(snapshot), len(want))
+		}
+	})
+}
+
+func TestArchiveDuplicates(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "manifest")
+	os.MkdirAll(filepath.Join(dir, "copies"), 0755)
+	photo := bytes.Repeat([]byte("photo "), 1000)
+	os.WriteFile(filepath.Join(dir, "photo.jpg"), photo, 0644)
+	os.WriteFile(filepath.Join(dir, "copies", "photo (1).jpg"), photo, 0644)
+	os.WriteFile(filepath.Join(dir, "copies", "other.jpg"), bytes.Repeat([]byte("other "), 1000), 0644) // Same size
+	os.WriteFile(filepath.Join(dir, "empty1"), nil, 0644)
+	os.WriteFile(filepath.Join(dir, "empty2"), nil, 0644)
+
+	var buf bytes.Buffer
+	result, err := Archive(&buf, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Duplicates != 1 || result.DuplicateBytes != int64(len(photo)) {
+		t.Errorf("duplicates = %d, %d bytes; want 1, %d", result.Duplicates, result.DuplicateBytes, len(photo))
+	}
+	if len(result.Files) != 5 {
+		t.Errorf("files = %+v, want all 5", result.Files)
+	}
+	data := buf.Bytes()
+
+	dest := t.TempDir()
+	extracted, err := Extract(bytes.NewReader(data), dest, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(extracted.Warnings) != 0 || len(core.Problems(extracted.Index)) != 0 {
+		t.Errorf("warnings %q, problems %+v", extracted.Warnings, core.Problems(extracted.Index))
+	}
+	got, err := os.ReadFile(filepath.Join(dest, "manifest", "copies", "photo (1).jpg"))
+	if err != nil || !bytes.Equal(got, photo) {
+		t.Errorf("the copy: %d bytes, %v", len(got), err)
+	}
+	// A copy, not a link, so changing one leaves the other
+	os.WriteFile(filepath.Join(dest, "manifest", "photo.jpg"), []byte("edited"), 0644)
+	if got, _ := os.ReadFile(filepath.Join(dest, "manifest", "copies", "photo (1).jpg")); !bytes.Equal(got, photo) {
+		t.Error("the copy changed with the original")
+	}
+
+	files, checks, err := core.ExtractTarGzChecked(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(core.Problems(checks)) != 0 {
+		t.Errorf("problems in memory: %+v", core.Problems(checks))
+	}
+	for _, f := range files {
+		if f.Name == "manifest/copies/photo (1).jpg" && (!bytes.Equal(f.Data, photo) || f.Status != core.IndexOK) {
+			t.Errorf("the copy in memory: %d bytes, %s", len(f.Data), f.Status)
+		}
+	}
+
+	// A hard link only ever copies a file extracted before it
+	buf.Reset()
+	gzw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gzw)
+	tw.WriteHeader(&tar.Header{Typeflag: tar.TypeLink, Name: "manifest/passwd", Linkname: "/etc/passwd"})
+	tw.WriteHeader(&tar.Header{Typeflag: tar.TypeReg, Name: "manifest/later.txt", Mode: 0644, Size: 2})
+	tw.Write([]byte("hi"))
+	tw.WriteHeader(&tar.Header{Typeflag: tar.TypeLink, Name: "manifest/copy.txt", Linkname: "manifest/later.txt"})
+	tw.Close()
+	gzw.Close()
+	dest = t.TempDir()
+	extracted, err = Extract(bytes.NewReader(buf.Bytes()), dest, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dest, "manifest", "passwd")); err == nil || len(extracted.Warnings) != 1 {
+		t.Errorf("a link to a file outside: %v, warnings %q", err, extracted.Warnings)
+	}
+	if got, _ := os.ReadFile(filepath.Join(dest, "manifest", "copy.txt")); string(got) != "hi" {
+		t.Errorf("copy.txt = %q", got)
+	}
+}
+
+func TestExtractSymlinks(t *testing.T) {
+	var buf bytes.Buffer
+	gzw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gzw)
+	links := map[string]string{
+		"manifest/ok":       "notes.txt",
+		"manifest/escape":   "../../outside",
+		"manifest/absolute": "/etc/passwd",
+		"manifest/a/b/top":  "../..",
+		"manifest/through":  "a/b/top/../../outside", // Lands two folders above manifest/
+	}
+	tw.WriteHeader(&tar.Header{Typeflag: tar.TypeReg, Name: "manifest/notes.txt", Mode: 0644, Size: 2})
+	tw.Write([]byte("hi"))
+	for name, target := range links {
+		tw.WriteHeader(&tar.Header{Typeflag: tar.TypeSymlink, Name: name, Linkname: target})
+	}
+	tw.Close()
+	gzw.Close()
+	data := buf.Bytes()
+
+	dest := t.TempDir()
+	result, err := Extract(bytes.NewReader(data), dest, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if target, err := os.Readlink(filepath.Join(dest, "manifest", "ok")); err != nil {
+		t.Skipf("symlinks not supported on this platform: %v", err)
+	} else if target != "notes.txt" {
+		t.Errorf("ok -> %q", target)
+	}
+	if _, err := os.Readlink(filepath.Join(dest, "manifest", "a", "b", "top")); err != nil {
+		t.Errorf("a/b/top wasn't made: %v", err)
+	}
+	for _, name := range []string{"escape", "absolute", "through"} {
+		if _, err := os.Lstat(filepath.Join(dest, "manifest", filepath.FromSlash(name))); err == nil {
+			t.Errorf("%s was made", name)
+		}
+	}
+	if len(result.Warnings) != 3 {
+		t.Errorf("warnings = %q, want 3", result.Warnings)
+	}
+
+	// In memory they're listed with where they point
+	files, err := core.ExtractTarGz(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var listed int
+	for _, f := range files {
+		if f.Link != "" {
+			listed++
+			if f.Link != links[f.Name] || f.Data != nil {
+				t.Errorf("%s -> %q, %d bytes", f.Name, f.Link, len(f.Data))
+			}
+		}
+	}
+	if listed != len(links) {
+		t.Errorf("listed %d links, want %d", listed, len(links))
+	}
+}
+
 func TestArchiveEmptyDir(t *testing.T) {
 	dir := t.TempDir()
 	emptyDir := filepath.Join(dir, "empty")
@@ -341,8 +580,686 @@ func TestArchiveEmptyDir(t *testing.T) {
 
 	// Should still be valid archive
 	dstDir := t.TempDir()
-	_, err = Extract(&buf, dstDir)
+	_, err = Extract(&buf, dstDir, true)
 	if err != nil {
 		t.Fatalf("Extract empty archive: %v", err)
 	}
 }
+
+func TestCheck(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "manifest")
+	os.MkdirAll(dir, 0755)
+
+	if _, err := Check(dir, nil); err == nil {
+		t.Error("expected error for an empty manifest")
+	}
+
+	// Only an empty folder: allowed, with warnings
+	os.MkdirAll(filepath.Join(dir, "later"), 0755)
+	warnings, err := Check(dir, nil)
+	if err != nil {
+		t.Fatalf("Check: %v", err)
+	}
+	if len(warnings) != 2 || !strings.Contains(warnings[0], "no files") || !strings.Contains(warnings[1], "manifest/later/") {
+		t.Errorf("warnings = %q, want one for no files and one for the empty folder", warnings)
+	}
+
+	os.WriteFile(filepath.Join(dir, "later", "note.txt"), []byte("hi"), 0644)
+	os.WriteFile(filepath.Join(dir, "blank.txt"), nil, 0644)
+	warnings, err = Check(dir, nil)
+	if err != nil {
+		t.Fatalf("Check: %v", err)
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "manifest/blank.txt is empty") {
+		t.Errorf("warnings = %q, want one for the empty file", warnings)
+	}
+}
+
+func TestCheckCaseCollisions(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "manifest")
+	os.MkdirAll(dir, 0755)
+	os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("mine"), 0644)
+
+	// A payload named like a file, in another case
+	pl := &Payload{Name: "Notes.txt", Source: SourceStdin}
+	if _, err := Check(dir, nil, pl); err == nil || !strings.Contains(err.Error(), "manifest/notes.txt, manifest/Notes.txt") {
+		t.Errorf("payload: got %v", err)
+	}
+
+	// Two files, on a file system that keeps them apart
+	if err := os.WriteFile(filepath.Join(dir, "NOTES.txt"), []byte("theirs"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) < 2 {
+		t.Skip("the file system here is case-insensitive")
+	}
+	if _, err := Check(dir, nil); err == nil || !strings.Contains(err.Error(), "differ only in case") {
+		t.Errorf("files: got %v", err)
+	}
+}
+
+func TestExtractCaseCollisions(t *testing.T) {
+	var buf bytes.Buffer
+	gzw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gzw)
+	for _, name := range []string{"manifest/Notes.txt", "manifest/notes.txt", "manifest/docs/a.txt", "manifest/Docs/b.txt"} {
+		tw.WriteHeader(&tar.Header{Name: name, Typeflag: tar.TypeReg, Mode: 0644, Size: int64(len(name))})
+		tw.Write([]byte(name))
+	}
+	tw.Close()
+	gzw.Close()
+
+	want := map[string]string{
+		"manifest/Notes.txt":   "manifest/Notes.txt",
+		"manifest/notes~2.txt": "manifest/notes.txt",
+		"manifest/docs/a.txt":  "manifest/docs/a.txt",
+		"manifest/docs/b.txt":  "manifest/Docs/b.txt",
+	}
+	result, err := Extract(bytes.NewReader(buf.Bytes()), t.TempDir(), true)
+	if err != nil {
+		t.Fatalf("Extract: %v", err)
+	}
+	for name, content := range want {
+		got, err := os.ReadFile(filepath.Join(filepath.Dir(result.Path), filepath.FromSlash(name)))
+		if err != nil || string(got) != content {
+			t.Errorf("%s: got %q, %v; want %q", name, got, err, content)
+		}
+	}
+	if len(result.Warnings) != 1 || !strings.Contains(result.Warnings[0], "manifest/notes.txt as manifest/notes~2.txt") {
+		t.Errorf("warnings = %q", result.Warnings)
+	}
+
+	// In memory, the same names
+	files, err := core.ExtractTarGz(buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range files {
+		if want[f.Name] != string(f.Data) {
+			t.Errorf("in memory: %s holds %q", f.Name, f.Data)
+		}
+	}
+}
+
+func TestArchiveIndex(t *testing.T) {
+	srcDir := filepath.Join(t.TempDir(), "manifest")
+	for name, content := range map[string]string{"a.txt": "alpha", "sub/b.txt": "bravo", "c.txt": "charlie"} {
+		path := filepath.Join(srcDir, filepath.FromSlash(name))
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	payload, err := SpoolPayload("extra.json", SourceStdin, strings.NewReader("{}"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer payload.Remove()
+	var buf bytes.Buffer
+	if _, err := Archive(&buf, srcDir, payload); err != nil {
+		t.Fatal(err)
+	}
+	archive := buf.Bytes()
+
+	entries, indexed, err := core.ListTarGz(bytes.NewReader(archive))
+	if err != nil || !indexed || len(entries) != 4 {
+		t.Fatalf("ListTarGz = %v, %v, %v", entries, indexed, err)
+	}
+
+	result, err := Extract(bytes.NewReader(archive), t.TempDir(), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Index) != 4 || len(core.Problems(result.Index)) != 0 || len(result.Warnings) != 0 {
+		t.Errorf("index = %v, warnings = %q", result.Index, result.Warnings)
+	}
+	if _, err := os.Stat(filepath.Join(filepath.Dir(result.Path), core.IndexFile)); !os.IsNotExist(err) {
+		t.Errorf("the index was extracted as a file: %v", err)
+	}
+
+	// Rewrite the archive with one file changed and another left out
+	gzr, err := gzip.NewReader(bytes.NewReader(archive))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr := tar.NewReader(gzr)
+	var damaged bytes.Buffer
+	gzw := gzip.NewWriter(&damaged)
+	tw := tar.NewWriter(gzw)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, _ := io.ReadAll(tr)
+		switch header.Name {
+		case "manifest/a.txt":
+			data = []byte("alphA")
+		case "manifest/c.txt":
+			continue
+		}
+		tw.WriteHeader(header)
+		tw.Write(data)
+	}
+	tw.Close()
+	gzw.Close()
+
+	result, err = Extract(bytes.NewReader(damaged.Bytes()), t.TempDir(), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	status := make(map[string]string)
+	for _, c := range result.Index {
+		status[c.Path] = c.Status
+	}
+	if status["manifest/a.txt"] != core.IndexCorrupted || status["manifest/c.txt"] != core.IndexMissing || status["manifest/sub/b.txt"] != core.IndexOK {
+		t.Errorf("statuses = %v", status)
+	}
+	if len(result.Warnings) != 2 {
+		t.Errorf("warnings = %q", result.Warnings)
+	}
+
+	// In memory, the same
+	files, checks, err := core.ExtractTarGzChecked(bytes.NewReader(damaged.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(core.Problems(checks)) != 2 {
+		t.Errorf("in memory: checks = %v", checks)
+	}
+	for _, f := range files {
+		if f.Status != status[f.Name] {
+			t.Errorf("in memory: %s is %q, want %q", f.Name, f.Status, status[f.Name])
+		}
+	}
+}
+
+func TestExtractKeepsEmptyFolders(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "manifest")
+	os.MkdirAll(filepath.Join(dir, "later"), 0755)
+
+	var buf bytes.Buffer
+	if _, err := Archive(&buf, dir); err != nil {
+		t.Fatalf("Archive: %v", err)
+	}
+	files, err := core.ExtractTarGz(buf.Bytes())
+	if err != nil {
+		t.Fatalf("ExtractTarGz: %v", err)
+	}
+	if len(files) != 1 || files[0].Name != "manifest/later/" || !files[0].Dir {
+		t.Errorf("got %+v, want the empty folder", files)
+	}
+
+	dst := t.TempDir()
+	result, err := Extract(bytes.NewReader(buf.Bytes()), dst, true)
+	if err != nil {
+		t.Fatalf("Extract: %v", err)
+	}
+	if info, err := os.Stat(filepath.Join(result.Path, "later")); err != nil || !info.IsDir() {
+		t.Errorf("empty folder not restored: %v", err)
+	}
+}
+
+func TestArchiveFilesMatchSnapshot(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "manifest")
+	os.MkdirAll(filepath.Join(dir, "sub"), 0755)
+	os.WriteFile(filepath.Join(dir, "a.txt"), []byte("alpha"), 0644)
+	os.WriteFile(filepath.Join(dir, "sub", "b.txt"), []byte("beta"), 0644)
+
+	var buf bytes.Buffer
+	result, err := Archive(&buf, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Files) != 2 || result.Files[0].Path != "manifest/a.txt" || result.Files[1].Path != "manifest/sub/b.txt" {
+		t.Fatalf("unexpected files: %+v", result.Files)
+	}
+	if result.Files[0].Size != 5 || result.Files[0].Checksum != core.HashBytes([]byte("alpha")) {
+		t.Errorf("a.txt: got %+v", result.Files[0])
+	}
+
+	snapshot, err := Snapshot(dir, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if changes := Compare(result.Files, snapshot); !changes.Empty() {
+		t.Errorf("snapshot differs from archive: %+v", changes)
+	}
+}
+
+func TestCompare(t *testing.T) {
+	old := []File{
+		{Path: "manifest/keep.txt", Size: 1, Checksum: "sha256:aa"},
+		{Path: "manifest/edit.txt", Size: 1, Checksum: "sha256:bb"},
+		{Path: "manifest/gone.txt", Size: 1, Checksum: "sha256:cc"},
+	}
+	current := []File{
+		{Path: "manifest/keep.txt", Size: 1, Checksum: "sha256:aa"},
+		{Path: "manifest/edit.txt", Size: 1, Checksum: "sha256:dd"},
+		{Path: "manifest/new.txt", Size: 2, Checksum: "sha256:ee"},
+	}
+
+	changes := Compare(old, current)
+	if strings.Join(changes.Added, ",") != "manifest/new.txt" ||
+		strings.Join(changes.Removed, ",") != "manifest/gone.txt" ||
+		strings.Join(changes.Modified, ",") != "manifest/edit.txt" {
+		t.Errorf("got %+v", changes)
+	}
+	if !Compare(old, old).Empty() {
+		t.Error("identical listings should have no changes")
+	}
+}
+
+func TestArchivePayload(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "manifest")
+	os.MkdirAll(dir, 0755)
+	os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("notes"), 0644)
+
+	payload, err := SpoolPayload("dump.sql", SourceStdin, strings.NewReader("CREATE TABLE secrets;"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer payload.Remove()
+	if payload.Size != 21 {
+		t.Errorf("Size = %d, want 21", payload.Size)
+	}
+	spooled, err := os.ReadFile(payload.path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(spooled, []byte("secrets")) {
+		t.Error("spool file holds the data unencrypted")
+	}
+
+	var buf bytes.Buffer
+	result, err := Archive(&buf, dir, payload)
+	if err != nil {
+		t.Fatalf("Archive: %v", err)
+	}
+	last := result.Files[len(result.Files)-1]
+	if last.Path != "manifest/dump.sql" || last.Size != 21 || last.Source != SourceStdin {
+		t.Errorf("payload recorded as %+v", last)
+	}
+
+	files, err := core.ExtractTarGz(buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var found bool
+	for _, f := range files {
+		if f.Name == "manifest/dump.sql" {
+			found = string(f.Data) == "CREATE TABLE secrets;"
+		}
+	}
+	if !found {
+		t.Error("payload missing from the archive")
+	}
+
+	// The payload was never in manifest/, so diff doesn't report it removed
+	current, err := Snapshot(dir, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if changes := Compare(result.Files, current); !changes.Empty() {
+		t.Errorf("Compare = %+v, want no changes", changes)
+	}
+
+	clash, err := SpoolPayload("notes.txt", SourceCommand, strings.NewReader("x"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer clash.Remove()
+	if _, err := Archive(&bytes.Buffer{}, dir, clash); err == nil {
+		t.Error("expected an error for a name already in manifest/")
+	}
+}
+
+func TestSpool(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "manifest")
+	os.MkdirAll(dir, 0755)
+	tmp := t.TempDir()
+
+	// --tmpdir: the spool file goes in the folder given
+	onDisk, err := Spool{Dir: tmp}.Payload("dump.sql", SourceStdin, strings.NewReader("CREATE TABLE secrets;"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filepath.Dir(onDisk.path) != tmp {
+		t.Errorf("spooled to %s, want %s", onDisk.path, tmp)
+	}
+	if err := onDisk.Remove(); err != nil {
+		t.Fatal(err)
+	}
+	if entries, _ := os.ReadDir(tmp); len(entries) != 0 {
+		t.Errorf("Remove left %d files in the spool folder", len(entries))
+	}
+
+	// --memory-temp: nothing is written, and the data archives the same
+	inMemory, err := Spool{Memory: true}.Payload("seed.txt", SourceSeed, strings.NewReader("abandon ability able"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer inMemory.Remove()
+	if inMemory.path != "" || bytes.Contains(inMemory.memory.Bytes(), []byte("abandon")) {
+		t.Error("memory spool wrote a file or holds the data unencrypted")
+	}
+	var buf bytes.Buffer
+	if _, err := Archive(&buf, dir, inMemory); err != nil {
+		t.Fatal(err)
+	}
+	files, err := core.ExtractTarGz(buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if last := files[len(files)-1]; last.Name != "manifest/seed.txt" || string(last.Data) != "abandon ability able" {
+		t.Errorf("archived %s = %q", last.Name, last.Data)
+	}
+
+	// Too much for memory fails rather than filling it
+	_, err = Spool{Memory: true}.Payload("big.bin", SourceStdin, io.LimitReader(zeros{}, MaxMemorySpool+1))
+	if !errors.Is(err, ErrMemorySpoolFull) {
+		t.Errorf("oversized memory spool: got %v", err)
+	}
+}
+
+type zeros struct{}
+
+func (zeros) Read(p []byte) (int, error) {
+	clear(p)
+	return len(p), nil
+}
+
+func TestCheckPayloadName(t *testing.T) {
+	for _, name := range []string{"dump.sql", "keys.asc"} {
+		if err := CheckPayloadName(name); err != nil {
+			t.Errorf("CheckPayloadName(%q) = %v", name, err)
+		}
+	}
+	for _, name := range []string{"", ".", "..", "sub/dump.sql", `..\dump.sql`} {
+		if err := CheckPayloadName(name); err == nil {
+			t.Errorf("CheckPayloadName(%q) should fail", name)
+		}
+	}
+}
+
+func TestPlainPath(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{`\\?\C:\Family\archive`, `C:\Family\archive`},
+		{`\\?\C:`, `C:\`},
+		{`\\?\UNC\nas\family\archive`, `\\nas\family\archive`},
+		{`\\?\unc\nas\family`, `\\nas\family`},
+		{`\\nas\family\archive`, `\\nas\family\archive`},
+		{`D:\archive`, `D:\archive`},
+		{`\\?\Volume{26a21bda-a627-11d7-9931-806e6f6e6963}\archive`, `\\?\Volume{26a21bda-a627-11d7-9931-806e6f6e6963}\archive`},
+	}
+	for _, tt := range tests {
+		if got := plainPath(tt.in); got != tt.want {
+			t.Errorf("plainPath(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestIgnoreMatch(t *testing.T) {
+	ig := &Ignore{}
+	for _, line := range []string{
+		"# a comment",
+		"",
+		".DS_Store",
+		"node_modules/",
+		"*.tmp",
+		"!keep.tmp",
+		"/build",
+		"docs/**/*.log",
+		"**/cache/**",
+		`\#notes`,
+		"photo-[0-9].jpg",
+		"trailing   ",
+	} {
+		if err := ig.add(line); err != nil {
+			t.Fatalf("%q: %v", line, err)
+		}
+	}
+	tests := []struct {
+		path  string
+		isDir bool
+		want  bool
+	}{
+		{".DS_Store", false, true},
+		{"photos/.DS_Store", false, true},
+		{"node_modules", true, true},
+		{"app/node_modules", true, true},
+		{"node_modules", false, false}, // A file of that name isn't a folder
+		{"draft.tmp", false, true},
+		{"sub/keep.tmp", false, false},
+		{"build", true, true},
+		{"sub/build", true, false}, // Anchored to the manifest directory
+		{"docs/a.log", false, true},
+		{"docs/x/y/a.log", false, true},
+		{"a.log", false, false},
+		{"x/cache/y", false, true},
+		{"#notes", false, true},
+		{"photo-1.jpg", false, true},
+		{"photo-a.jpg", false, false},
+		{"trailing", false, true},
+		{"secret.txt", false, false},
+	}
+	for _, tt := range tests {
+		if got := ig.Match(tt.path, tt.isDir); got != tt.want {
+			t.Errorf("Match(%q, %v) = %v, want %v", tt.path, tt.isDir, got, tt.want)
+		}
+	}
+	if (*Ignore)(nil).Match("anything", false) {
+		t.Error("a nil Ignore left something out")
+	}
+	if err := ig.add(`ends\`); err == nil {
+		t.Error("took a pattern ending with a backslash")
+	}
+}
+
+func TestArchiveIgnore(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "manifest")
+	os.MkdirAll(filepath.Join(dir, "node_modules", "pkg"), 0755)
+	os.WriteFile(filepath.Join(dir, "secret.txt"), []byte("keep me"), 0644)
+	os.WriteFile(filepath.Join(dir, "draft.tmp"), []byte("scratch"), 0644)
+	os.WriteFile(filepath.Join(dir, "node_modules", "pkg", "index.js"), []byte("module"), 0644)
+	os.WriteFile(filepath.Join(dir, IgnoreFile), []byte("node_modules/\n"), 0644)
+
+	ig, err := LoadIgnore(dir, []string{"*.tmp"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	result, err := ArchiveAs(&buf, dir, "", ig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var paths []string
+	for _, f := range result.Files {
+		paths = append(paths, f.Path)
+	}
+	if got := strings.Join(paths, " "); got != "manifest/.rememoryignore manifest/secret.txt" {
+		t.Errorf("archived %s", got)
+	}
+	if got := strings.Join(result.Ignored, " "); got != "manifest/draft.tmp manifest/node_modules/" {
+		t.Errorf("ignored %s", got)
+	}
+
+	// Everything that walks the directory leaves out the same files
+	snapshot, err := Snapshot(dir, ig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if changes := Compare(result.Files, snapshot); !changes.Empty() {
+		t.Errorf("snapshot differs from archive: %+v", changes)
+	}
+	if n, err := CountFiles(dir, ig); err != nil || n != 2 {
+		t.Errorf("CountFiles = %d, %v", n, err)
+	}
+	if size, err := DirSize(dir, ig); err != nil || size != int64(len("keep me")+len("node_modules/\n")) {
+		t.Errorf("DirSize = %d, %v", size, err)
+	}
+
+	os.WriteFile(filepath.Join(dir, IgnoreFile), []byte("ok\nbad\\\n"), 0644)
+	if _, err := LoadIgnore(dir, nil); err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("bad pattern: %v", err)
+	}
+}
+
+func TestExtractMetadata(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "manifest")
+	os.MkdirAll(filepath.Join(dir, "photos"), 0755)
+	os.WriteFile(filepath.Join(dir, "run.sh"), []byte("#!/bin/sh\n"), 0755)
+	os.WriteFile(filepath.Join(dir, "photos", "beach.jpg"), []byte("jpeg"), 0644)
+	os.WriteFile(filepath.Join(dir, "photos", "copy.jpg"), []byte("jpeg"), 0640)
+	taken := time.Date(2009, 7, 14, 16, 30, 0, 0, time.UTC)
+	for _, name := range []string{"run.sh", "photos/beach.jpg", "photos/copy.jpg", "photos"} {
+		if err := os.Chtimes(filepath.Join(dir, name), taken, taken); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// A folder that can't be written to still gets its files
+	if err := os.Chmod(filepath.Join(dir, "photos"), 0555); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chmod(filepath.Join(dir, "photos"), 0755) })
+
+	var buf bytes.Buffer
+	if _, err := Archive(&buf, dir); err != nil {
+		t.Fatal(err)
+	}
+	archive := buf.Bytes()
+
+	result, err := Extract(bytes.NewReader(archive), t.TempDir(), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chmod(filepath.Join(result.Path, "photos"), 0755) })
+	for _, w := range result.Warnings {
+		t.Errorf("warning: %s", w)
+	}
+	for name, mode := range map[string]os.FileMode{"run.sh": 0755, "photos/beach.jpg": 0644, "photos/copy.jpg": 0640, "photos": 0555} {
+		info, err := os.Stat(filepath.Join(result.Path, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !info.ModTime().Equal(taken) {
+			t.Errorf("%s: modified %v, want %v", name, info.ModTime(), taken)
+		}
+		if runtime.GOOS != "windows" && info.Mode().Perm() != mode {
+			t.Errorf("%s: mode %v, want %v", name, info.Mode().Perm(), mode)
+		}
+	}
+
+	// Without metadata, files are as new as they're written
+	result, err = Extract(bytes.NewReader(archive), t.TempDir(), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"run.sh", "photos/copy.jpg", "photos"} {
+		info, err := os.Stat(filepath.Join(result.Path, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.ModTime().Equal(taken) {
+			t.Errorf("%s: got the sealed time without metadata", name)
+		}
+		if runtime.GOOS != "windows" && info.Mode().Perm()&0111 != 0 && !info.IsDir() {
+			t.Errorf("%s: mode %v, want it not executable", name, info.Mode().Perm())
+		}
+	}
+}
+
+func TestArchiveXattrs(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "manifest")
+	os.MkdirAll(dir, 0755)
+	path := filepath.Join(dir, "tagged.txt")
+	os.WriteFile(path, []byte("tagged"), 0644)
+	if err := writeXattr(path, "user.rememory.test", "red\x00blue"); err != nil {
+		t.Skipf("extended attributes aren't available here: %v", err)
+	}
+
+	// Only sealed when asked for
+	var buf bytes.Buffer
+	if _, err := ArchiveAs(&buf, dir, "", nil); err != nil {
+		t.Fatal(err)
+	}
+	if records := xattrRecords(t, buf.Bytes(), "manifest/tagged.txt"); len(records) != 0 {
+		t.Errorf("sealed %v without Xattrs", records)
+	}
+
+	buf.Reset()
+	if _, err := ArchiveAs(&buf, dir, "", &Ignore{Xattrs: true}); err != nil {
+		t.Fatal(err)
+	}
+	archive := buf.Bytes()
+	records := xattrRecords(t, archive, "manifest/tagged.txt")
+	if records[xattrRecord+"user.rememory.test"] != "red\x00blue" {
+		t.Fatalf("sealed %v", records)
+	}
+
+	result, err := Extract(bytes.NewReader(archive), t.TempDir(), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	attrs, err := readXattrs(filepath.Join(result.Path, "tagged.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if attrs["user.rememory.test"] != "red\x00blue" {
+		t.Errorf("restored %v", attrs)
+	}
+
+	result, err = Extract(bytes.NewReader(archive), t.TempDir(), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if attrs, _ := readXattrs(filepath.Join(result.Path, "tagged.txt")); len(attrs) != 0 {
+		t.Errorf("restored %v without metadata", attrs)
+	}
+}
+
+func TestKeptXattr(t *testing.T) {
+	for name, want := range map[string]bool{
+		"user.xdg.tags":                       true,
+		"com.apple.metadata:_kMDItemUserTags": true,
+		"security.capability":                 false,
+		"security.selinux":                    false,
+		"system.posix_acl_access":             false,
+		"trusted.overlay.opaque":              false,
+		"":                                    false,
+	} {
+		if got := keptXattr(name); got != want {
+			t.Errorf("keptXattr(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
+
+// xattrRecords returns the extended attribute records of the entry named
+// name in a tar.gz archive.
+func xattrRecords(t *testing.T, archive []byte, name string) map[string]string {
+	t.Helper()
+	gzr, err := gzip.NewReader(bytes.NewReader(archive))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr := tar.NewReader(gzr)
+	for {
+		header, err := tr.Next()
+		if err != nil {
+			t.Fatalf("%s not in the archive: %v", name, err)
+		}
+		if header.Name != name {
+			continue
+		}
+		records := make(map[string]string)
+		for key, value := range header.PAXRecords {
+			if strings.HasPrefix(key, xattrRecord) {
+				records[key] = value
+			}
+		}
+		return records
+	}
+}
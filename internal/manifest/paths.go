@@ -0,0 +1,44 @@
+package manifest
+
+import (
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// AbsPath is filepath.Abs for the folders people keep archives in, which on
+// Windows are often network shares (\\nas\family) or long paths given as
+// \\?\C:\... or \\?\UNC\nas\family. Those come back in their plain form,
+// C:\... or \\nas\family, so they read normally in messages and compare
+// equal to the same folder given without the prefix; Go's os package adds
+// the prefix back where a path is too long for Windows.
+func AbsPath(path string) (string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	if runtime.GOOS == "windows" {
+		abs = plainPath(abs)
+	}
+	return abs, nil
+}
+
+// plainPath strips the \\?\ prefix from a Windows path: \\?\C:\x becomes
+// C:\x and \\?\UNC\server\share\x becomes \\server\share\x. Device paths
+// that have no plain form, like \\?\Volume{...}\, are left alone.
+func plainPath(path string) string {
+	rest, ok := strings.CutPrefix(path, `\\?\`)
+	if !ok {
+		return path
+	}
+	if len(rest) >= 4 && strings.EqualFold(rest[:4], `UNC\`) {
+		return `\\` + rest[4:]
+	}
+	if len(rest) >= 2 && rest[1] == ':' && (rest[0]|0x20 >= 'a' && rest[0]|0x20 <= 'z') {
+		if len(rest) == 2 {
+			return rest + `\`
+		}
+		return rest
+	}
+	return path
+}
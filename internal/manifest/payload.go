@@ -0,0 +1,158 @@
+package manifest
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"filippo.io/age"
+)
+
+// Payload sources, recorded in File.Source.
+const (
+	SourceStdin   = "stdin"
+	SourceCommand = "command"
+	SourceVault   = "vault" // A password-manager export, normalized by the vault package
+	SourceSeed    = "seed"  // A wallet's seed phrase, checked by the seed package
+)
+
+// Payload is a file for the archive that isn't in manifest/: data piped to
+// 'rememory seal --stdin' or printed by a command for '--exec'. A tar
+// header needs the file's size before its contents, so the data is spooled
+// to a temporary file first, encrypted to a key that only lives in memory,
+// so it never touches the disk unencrypted.
+type Payload struct {
+	Name   string // File name inside manifest/
+	Source string // SourceStdin, SourceCommand, SourceVault, or SourceSeed
+	Size   int64
+
+	path     string        // Spool file; empty when the data is held in memory
+	memory   *bytes.Buffer // The encrypted data, with Spool.Memory
+	identity *age.X25519Identity
+}
+
+// MaxMemorySpool is the most data Spool.Memory keeps in memory.
+const MaxMemorySpool = 64 << 20
+
+// ErrMemorySpoolFull is returned when data spooled to memory outgrows
+// MaxMemorySpool.
+var ErrMemorySpoolFull = errors.New("too large to keep in memory")
+
+// Spool says where payloads wait, encrypted, until they're archived.
+type Spool struct {
+	Dir    string // Folder for spool files; empty for the system's temporary folder
+	Memory bool   // Keep the data in memory instead, up to MaxMemorySpool: nothing is written to disk
+}
+
+// CheckPayloadName checks that name can be a file directly inside manifest/.
+func CheckPayloadName(name string) error {
+	switch {
+	case name == "":
+		return fmt.Errorf("a name is needed for the sealed data (--name)")
+	case name == "." || name == "..", strings.ContainsAny(name, `/\`):
+		return fmt.Errorf("--name %q must be a plain file name, without folders", name)
+	}
+	return nil
+}
+
+// SpoolPayload reads r to the end into a new Payload, spooled to the
+// system's temporary folder. Call Remove when done with it, whether or not
+// it was archived.
+func SpoolPayload(name, source string, r io.Reader) (*Payload, error) {
+	return Spool{}.Payload(name, source, r)
+}
+
+// Payload is SpoolPayload, spooling where s says.
+func (s Spool) Payload(name, source string, r io.Reader) (*Payload, error) {
+	if err := CheckPayloadName(name); err != nil {
+		return nil, err
+	}
+	identity, err := age.GenerateX25519Identity()
+	if err != nil {
+		return nil, fmt.Errorf("generating spool key: %w", err)
+	}
+	pl := &Payload{Name: name, Source: source, identity: identity}
+
+	var dst io.WriteCloser
+	if s.Memory {
+		pl.memory = new(bytes.Buffer)
+		dst = nopCloser{&limitedWriter{w: pl.memory, n: MaxMemorySpool}}
+	} else {
+		f, err := os.CreateTemp(s.Dir, "rememory-spool-*.age")
+		if err != nil {
+			return nil, fmt.Errorf("creating spool file: %w", err)
+		}
+		pl.path = f.Name()
+		dst = f
+	}
+
+	enc, err := age.Encrypt(dst, identity.Recipient())
+	if err == nil {
+		pl.Size, err = io.Copy(enc, r)
+		if err == nil {
+			err = enc.Close()
+		}
+	}
+	if closeErr := dst.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		pl.Remove()
+		return nil, fmt.Errorf("reading %s: %w", name, err)
+	}
+	return pl, nil
+}
+
+// open returns the spooled data, decrypted as it's read.
+func (pl *Payload) open() (io.ReadCloser, error) {
+	if pl.memory != nil {
+		r, err := age.Decrypt(bytes.NewReader(pl.memory.Bytes()), pl.identity)
+		if err != nil {
+			return nil, err
+		}
+		return io.NopCloser(r), nil
+	}
+	f, err := os.Open(pl.path)
+	if err != nil {
+		return nil, err
+	}
+	r, err := age.Decrypt(f, pl.identity)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return struct {
+		io.Reader
+		io.Closer
+	}{r, f}, nil
+}
+
+// Remove deletes the spool file, or drops the data held in memory.
+func (pl *Payload) Remove() error {
+	if pl.path == "" {
+		pl.memory = nil
+		return nil
+	}
+	return os.Remove(pl.path)
+}
+
+// limitedWriter writes to w until n bytes have gone through, then fails.
+type limitedWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (l *limitedWriter) Write(p []byte) (int, error) {
+	if int64(len(p)) > l.n {
+		return 0, fmt.Errorf("more than %d MB: %w", MaxMemorySpool>>20, ErrMemorySpoolFull)
+	}
+	l.n -= int64(len(p))
+	return l.w.Write(p)
+}
+
+type nopCloser struct{ io.Writer }
+
+func (nopCloser) Close() error { return nil }
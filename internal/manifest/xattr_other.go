@@ -0,0 +1,15 @@
+//go:build !linux && !darwin
+
+package manifest
+
+import "errors"
+
+var errNoXattrs = errors.New("extended attributes aren't supported on this system")
+
+func readXattrs(path string) (map[string]string, error) {
+	return nil, errNoXattrs
+}
+
+func writeXattr(path, name, value string) error {
+	return errNoXattrs
+}
@@ -0,0 +1,50 @@
+//go:build linux || darwin
+
+package manifest
+
+import (
+	"errors"
+	"strings"
+
+	"golang.org/x/sys/unix"
+)
+
+// readXattrs returns the extended attributes of the file or folder at path
+// that keptXattr lets through. A file system without extended attributes
+// has none.
+func readXattrs(path string) (map[string]string, error) {
+	size, err := unix.Listxattr(path, nil)
+	if err != nil || size == 0 {
+		if errors.Is(err, unix.ENOTSUP) {
+			err = nil
+		}
+		return nil, err
+	}
+	list := make([]byte, size)
+	if size, err = unix.Listxattr(path, list); err != nil {
+		return nil, err
+	}
+
+	attrs := make(map[string]string)
+	for _, name := range strings.Split(string(list[:size]), "\x00") {
+		if !keptXattr(name) {
+			continue
+		}
+		size, err := unix.Getxattr(path, name, nil)
+		if err != nil {
+			return nil, err
+		}
+		value := make([]byte, size)
+		if size, err = unix.Getxattr(path, name, value); err != nil {
+			return nil, err
+		}
+		attrs[name] = string(value[:size])
+	}
+	return attrs, nil
+}
+
+// writeXattr sets one extended attribute on the file or folder at path,
+// never on what a link there points to.
+func writeXattr(path, name, value string) error {
+	return unix.Lsetxattr(path, name, []byte(value), 0)
+}
@@ -0,0 +1,108 @@
+package notify
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/eljojo/rememory/internal/project"
+)
+
+// Event is a day to be reminded of something, in a calendar.
+type Event struct {
+	UID         string // Stays the same across exports, so importing again updates the event
+	Date        time.Time
+	Summary     string
+	Description string
+}
+
+// MediaEvents returns a reminder for each copy of a bundle on a drive or
+// disc, on the day it's due to be written again. Copies whose read-back
+// failed are left out: they're due now, and 'rememory status' says so.
+func MediaEvents(p *project.Project) []Event {
+	var events []Event
+	for _, f := range p.Friends {
+		for _, m := range f.Media {
+			if m.Failed != "" {
+				continue
+			}
+			kind := m.Type
+			if kind == "" {
+				kind = "drive"
+			}
+			name := m.Serial
+			if m.Label != "" {
+				name += " (" + m.Label + ")"
+			}
+			uid := sha256.Sum256([]byte(f.Name + "\x00" + m.Serial))
+			events = append(events, Event{
+				UID:     hex.EncodeToString(uid[:8]) + "@rememory",
+				Date:    p.RefreshDue(m),
+				Summary: fmt.Sprintf("Rewrite %s's ReMemory %s", f.Name, kind),
+				Description: fmt.Sprintf("%s's copy of the ReMemory bundle for %s is on %s, which is trusted for %d years. "+
+					"Copy the current bundle onto a new one, check it with 'rememory verify-media', and hand it over.",
+					f.Name, p.Name, name, p.RefreshYears(m.Type)),
+			})
+		}
+	}
+	return events
+}
+
+// Calendar writes events as an iCalendar file (RFC 5545) that calendar apps
+// import, each an all-day event with an alert on the morning.
+func Calendar(name string, events []Event, now time.Time) []byte {
+	var b bytes.Buffer
+	line := func(s string) { b.WriteString(foldLine(s) + "\r\n") }
+	line("BEGIN:VCALENDAR")
+	line("VERSION:2.0")
+	line("PRODID:-//ReMemory//rememory//EN")
+	line("CALSCALE:GREGORIAN")
+	line("X-WR-CALNAME:" + escapeText(name))
+	stamp := now.UTC().Format("20060102T150405Z")
+	for _, e := range events {
+		line("BEGIN:VEVENT")
+		line("UID:" + e.UID)
+		line("DTSTAMP:" + stamp)
+		line("DTSTART;VALUE=DATE:" + e.Date.Format("20060102"))
+		line("DTEND;VALUE=DATE:" + e.Date.AddDate(0, 0, 1).Format("20060102"))
+		line("SUMMARY:" + escapeText(e.Summary))
+		if e.Description != "" {
+			line("DESCRIPTION:" + escapeText(e.Description))
+		}
+		line("BEGIN:VALARM")
+		line("ACTION:DISPLAY")
+		line("DESCRIPTION:" + escapeText(e.Summary))
+		line("TRIGGER:PT9H")
+		line("END:VALARM")
+		line("END:VEVENT")
+	}
+	line("END:VCALENDAR")
+	return b.Bytes()
+}
+
+// escapeText escapes a TEXT value: backslashes, semicolons, commas, and
+// line breaks.
+func escapeText(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`).Replace(s)
+}
+
+// foldLine breaks a content line longer than 75 octets, continuing it on
+// lines that start with a space, without splitting a UTF-8 character.
+func foldLine(s string) string {
+	const limit = 75
+	var b strings.Builder
+	n := 0
+	for _, r := range s {
+		size := len(string(r))
+		if n+size > limit {
+			b.WriteString("\r\n ")
+			n = 1
+		}
+		b.WriteRune(r)
+		n += size
+	}
+	return b.String()
+}
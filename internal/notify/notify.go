@@ -0,0 +1,228 @@
+// Package notify composes and sends the reminders that ask friends whether
+// they still have their bundle, and writes the calendar of when copies on
+// drives and discs are due to be written again.
+package notify
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"os"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/eljojo/rememory/internal/project"
+	"github.com/eljojo/rememory/internal/translations"
+)
+
+// DefaultSMTPPort is used when project.yml doesn't set one.
+const DefaultSMTPPort = 587
+
+// Fields are the placeholders available to a custom message template.
+type Fields struct {
+	Holder      string
+	ProjectName string
+	Piece       int // This friend's piece number
+	Total       int
+	Threshold   int
+	Sealed      time.Time
+	Language    string
+}
+
+// Message is one reminder, ready to send.
+type Message struct {
+	To      string // Email address
+	Subject string
+	Body    string
+}
+
+// Template is a parsed custom message template.
+type Template struct {
+	tmpl *template.Template
+}
+
+// LoadTemplate reads and checks a message template file.
+func LoadTemplate(path string) (*Template, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading message template: %w", err)
+	}
+	return ParseTemplate(string(data))
+}
+
+// ParseTemplate parses a message template and renders it once with sample
+// data, so unknown placeholders fail before anything is sent.
+func ParseTemplate(text string) (*Template, error) {
+	tmpl, err := template.New("message").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("parsing message template: %w", err)
+	}
+	t := &Template{tmpl: tmpl}
+	if _, err := t.Execute(Fields{Holder: "Sample", ProjectName: "Sample", Piece: 1, Total: 2, Threshold: 2}); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// Execute renders the message body for one friend.
+func (t *Template) Execute(f Fields) (string, error) {
+	var buf bytes.Buffer
+	if err := t.tmpl.Execute(&buf, f); err != nil {
+		return "", fmt.Errorf("rendering message template: %w", err)
+	}
+	return buf.String(), nil
+}
+
+// Compose builds the reminder for one friend. Without a custom template or
+// subject, the built-in text is used in the friend's language.
+func Compose(to string, f Fields, subject string, tmpl *Template) (*Message, error) {
+	msg := &Message{To: to, Subject: subject}
+	if msg.Subject == "" {
+		msg.Subject = translations.T("readme", f.Language, "notify_subject")
+	}
+	if tmpl == nil {
+		readme := translations.ReadmeFilename(f.Language, ".txt")
+		msg.Body = translations.T("readme", f.Language, "notify_body", f.Holder, f.ProjectName, readme)
+		return msg, nil
+	}
+	body, err := tmpl.Execute(f)
+	if err != nil {
+		return nil, err
+	}
+	msg.Body = body
+	return msg, nil
+}
+
+// EmailAddress finds an email address in a friend's contact info, which may
+// also hold a phone number or other notes. It reports false if there is none.
+func EmailAddress(contact string) (string, bool) {
+	if addr, err := mail.ParseAddress(contact); err == nil {
+		return addr.Address, true
+	}
+	for _, field := range strings.FieldsFunc(contact, func(r rune) bool {
+		return r == ',' || r == ';' || r == ' ' || r == '\t'
+	}) {
+		field = strings.Trim(field, "<>()")
+		if !strings.Contains(field, "@") {
+			continue
+		}
+		if addr, err := mail.ParseAddress(field); err == nil {
+			return addr.Address, true
+		}
+	}
+	return "", false
+}
+
+// Format renders the message as an RFC 5322 email from the given sender.
+func (m *Message) Format(from string, date time.Time) ([]byte, error) {
+	sender, err := mail.ParseAddress(from)
+	if err != nil {
+		return nil, fmt.Errorf("invalid from address %q: %w", from, err)
+	}
+	domain := sender.Address[strings.LastIndex(sender.Address, "@")+1:]
+
+	id := make([]byte, 12)
+	if _, err := rand.Read(id); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", sender.String())
+	fmt.Fprintf(&buf, "To: %s\r\n", (&mail.Address{Address: m.To}).String())
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", m.Subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", date.Format(time.RFC1123Z))
+	fmt.Fprintf(&buf, "Message-ID: <%s@%s>\r\n", hex.EncodeToString(id), domain)
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	buf.WriteString("Content-Transfer-Encoding: quoted-printable\r\n")
+	buf.WriteString("\r\n")
+
+	qp := quotedprintable.NewWriter(&buf)
+	body := strings.ReplaceAll(strings.ReplaceAll(m.Body, "\r\n", "\n"), "\n", "\r\n")
+	if _, err := qp.Write([]byte(body)); err != nil {
+		return nil, err
+	}
+	if err := qp.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Sender delivers messages through an SMTP server.
+type Sender struct {
+	Config   project.SMTPConfig
+	Password string
+}
+
+// Send delivers one message. Port 465 uses TLS from the start; any other
+// port upgrades with STARTTLS, which is required before authenticating.
+func (s *Sender) Send(m *Message) error {
+	data, err := m.Format(s.Config.From, time.Now())
+	if err != nil {
+		return err
+	}
+	from, _ := mail.ParseAddress(s.Config.From)
+
+	port := s.Config.Port
+	if port == 0 {
+		port = DefaultSMTPPort
+	}
+	addr := net.JoinHostPort(s.Config.Host, strconv.Itoa(port))
+	tlsConfig := &tls.Config{ServerName: s.Config.Host}
+
+	var c *smtp.Client
+	if port == 465 {
+		conn, err := tls.Dial("tcp", addr, tlsConfig)
+		if err != nil {
+			return fmt.Errorf("connecting to %s: %w", addr, err)
+		}
+		if c, err = smtp.NewClient(conn, s.Config.Host); err != nil {
+			conn.Close()
+			return fmt.Errorf("connecting to %s: %w", addr, err)
+		}
+	} else {
+		if c, err = smtp.Dial(addr); err != nil {
+			return fmt.Errorf("connecting to %s: %w", addr, err)
+		}
+		if ok, _ := c.Extension("STARTTLS"); ok {
+			if err := c.StartTLS(tlsConfig); err != nil {
+				c.Close()
+				return fmt.Errorf("starting TLS: %w", err)
+			}
+		}
+	}
+	defer c.Close()
+
+	if s.Config.Username != "" {
+		// PlainAuth refuses to send the password over an unencrypted connection
+		if err := c.Auth(smtp.PlainAuth("", s.Config.Username, s.Password, s.Config.Host)); err != nil {
+			return fmt.Errorf("logging in to %s: %w", s.Config.Host, err)
+		}
+	}
+	if err := c.Mail(from.Address); err != nil {
+		return err
+	}
+	if err := c.Rcpt(m.To); err != nil {
+		return err
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(data); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
@@ -0,0 +1,121 @@
+package notify
+
+import (
+	"mime"
+	"net/mail"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+func TestEmailAddress(t *testing.T) {
+	tests := []struct {
+		contact string
+		want    string
+	}{
+		{"alice@example.com", "alice@example.com"},
+		{"Alice <alice@example.com>", "alice@example.com"},
+		{"+1 555 0100, bob@example.com", "bob@example.com"},
+		{"call: +1 555 0100; (camila@example.com)", "camila@example.com"},
+		{"+1 555 0100", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		got, ok := EmailAddress(tt.contact)
+		if got != tt.want || ok != (tt.want != "") {
+			t.Errorf("EmailAddress(%q) = %q, %v; want %q", tt.contact, got, ok, tt.want)
+		}
+	}
+}
+
+func TestCompose(t *testing.T) {
+	f := Fields{Holder: "Alice", ProjectName: "Family Vault", Piece: 2, Total: 3, Threshold: 2, Language: "es"}
+
+	msg, err := Compose("alice@example.com", f, "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(msg.Body, "Alice") || !strings.Contains(msg.Body, "Family Vault") || !strings.Contains(msg.Body, "LEEME.txt") {
+		t.Errorf("built-in body missing details: %q", msg.Body)
+	}
+	if !strings.Contains(msg.Subject, "ReMemory") {
+		t.Errorf("subject = %q", msg.Subject)
+	}
+
+	tmpl, err := ParseTemplate("Hi {{.Holder}}, piece {{.Piece}} of {{.Total}}?")
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg, err = Compose("alice@example.com", f, "Checking in", tmpl)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if msg.Subject != "Checking in" || msg.Body != "Hi Alice, piece 2 of 3?" {
+		t.Errorf("custom message = %q / %q", msg.Subject, msg.Body)
+	}
+
+	if _, err := ParseTemplate("Hi {{.Nickname}}"); err == nil {
+		t.Error("expected an unknown field to be rejected")
+	}
+}
+
+func TestFormat(t *testing.T) {
+	msg := &Message{To: "alice@example.com", Subject: "¿Todavía?", Body: "Hola Alice,\n\n¿Todavía lo tienes?"}
+	data, err := msg.Format("Ana <ana@example.com>", time.Date(2026, 2, 13, 10, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parsed, err := mail.ReadMessage(strings.NewReader(string(data)))
+	if err != nil {
+		t.Fatalf("reading formatted message: %v", err)
+	}
+	subject, err := new(mime.WordDecoder).DecodeHeader(parsed.Header.Get("Subject"))
+	if err != nil || subject != msg.Subject {
+		t.Errorf("subject = %q, %v", subject, err)
+	}
+	if got := parsed.Header.Get("To"); got != "<alice@example.com>" {
+		t.Errorf("To = %q", got)
+	}
+	if !strings.HasSuffix(parsed.Header.Get("Message-ID"), "@example.com>") {
+		t.Errorf("Message-ID = %q", parsed.Header.Get("Message-ID"))
+	}
+
+	if _, err := msg.Format("not an address", time.Now()); err == nil {
+		t.Error("expected an invalid from address to be rejected")
+	}
+}
+
+func TestCalendar(t *testing.T) {
+	due := time.Date(2027, 3, 1, 0, 0, 0, 0, time.UTC)
+	events := []Event{{
+		UID:         "abc@rememory",
+		Date:        due,
+		Summary:     "Rewrite Alice's ReMemory usb",
+		Description: "On ALICE; copy the bundle, again\nthen check it. " + strings.Repeat("é", 60),
+	}}
+	ics := string(Calendar("ReMemory: test", events, due))
+
+	for _, want := range []string{
+		"BEGIN:VCALENDAR\r\n",
+		"UID:abc@rememory\r\n",
+		"DTSTART;VALUE=DATE:20270301\r\n",
+		"DTEND;VALUE=DATE:20270302\r\n",
+		"DESCRIPTION:On ALICE\\; copy the bundle\\, again\\nthen check it.",
+		"TRIGGER:PT9H\r\n",
+		"END:VCALENDAR\r\n",
+	} {
+		if !strings.Contains(ics, want) {
+			t.Errorf("missing %q in:\n%s", want, ics)
+		}
+	}
+	for _, line := range strings.Split(strings.TrimSuffix(ics, "\r\n"), "\r\n") {
+		if len(line) > 75 {
+			t.Errorf("line of %d octets: %q", len(line), line)
+		}
+		if !utf8.ValidString(line) {
+			t.Errorf("folded inside a character: %q", line)
+		}
+	}
+}
@@ -0,0 +1,31 @@
+package pdf
+
+import (
+	"bytes"
+	"regexp"
+	"slices"
+)
+
+// pdfObject matches one numbered object of a PDF as fpdf writes it.
+var pdfObject = regexp.MustCompile(`(?s)\n(\d+ 0) obj\n(.*?)\nendobj`)
+
+// SameDocument reports whether two PDFs made by this package show the same
+// thing. fpdf doesn't subset a font the same way every time when several
+// characters share a glyph, so the embedded font programs are left out of
+// the comparison, along with the cross-reference table that points past them.
+func SameDocument(a, b []byte) bool {
+	objects := pdfObjects(a)
+	return len(objects) > 0 && slices.Equal(objects, pdfObjects(b))
+}
+
+// pdfObjects returns each object but the font programs, with its number.
+func pdfObjects(doc []byte) []string {
+	var objects []string
+	for _, m := range pdfObject.FindAllSubmatch(doc, -1) {
+		if bytes.Contains(m[2], []byte("/Length1")) {
+			continue
+		}
+		objects = append(objects, string(m[1])+"\n"+string(m[2]))
+	}
+	return objects
+}
@@ -0,0 +1,30 @@
+package pdf
+
+import "testing"
+
+func TestSameDocument(t *testing.T) {
+	data := testReadmeData()
+	first, err := GenerateReadme(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := GenerateReadme(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !SameDocument(first, second) {
+		t.Error("the same README rendered twice isn't the same document")
+	}
+
+	data.OtherFriends[0].Contact = "bob@example.org"
+	changed, err := GenerateReadme(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if SameDocument(first, changed) {
+		t.Error("a changed contact still counts as the same document")
+	}
+	if SameDocument(nil, nil) {
+		t.Error("no document counts as the same document")
+	}
+}
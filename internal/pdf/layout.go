@@ -0,0 +1,129 @@
+package pdf
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Layout controls the page setup of README.pdf, which sections it
+// contains, in what order, and how each language writes them. Projects can
+// override it with a YAML file (pdf_layout in project.yml); anything left
+// out keeps the default.
+type Layout struct {
+	PageSize string            `yaml:"page_size"` // A4, A5, Letter, or Legal
+	Margins  Margins           `yaml:"margins"`
+	Sections []string          `yaml:"sections"`
+	Locales  map[string]Locale `yaml:"locales"` // Keyed by language code
+}
+
+// Margins are page margins in millimetres.
+type Margins struct {
+	Left   float64 `yaml:"left"`
+	Top    float64 `yaml:"top"`
+	Right  float64 `yaml:"right"`
+	Bottom float64 `yaml:"bottom"`
+}
+
+// DefaultSections is the built-in section order.
+var DefaultSections = []string{
+	"title",
+	"about",
+	"warning",
+	"recovery_rule",
+	"contacts",
+	"sharing",
+	"share",
+	"words",
+	"machine_readable",
+	"recover_browser",
+	"recover_cli",
+	"faq",
+	"metadata",
+}
+
+// shareSections carry the share itself. A layout must keep at least one,
+// otherwise the printed page can't be used for recovery.
+var shareSections = []string{"share", "words", "machine_readable"}
+
+var pageSizes = []string{"A4", "A5", "Letter", "Legal"}
+
+const (
+	defaultMargin = 20.0
+	minMargin     = 5.0
+	maxMargin     = 60.0
+)
+
+// DefaultLayout returns the built-in layout.
+func DefaultLayout() *Layout {
+	return &Layout{
+		PageSize: "A4",
+		Margins:  Margins{Left: defaultMargin, Top: defaultMargin, Right: defaultMargin, Bottom: defaultMargin},
+		Sections: append([]string(nil), DefaultSections...),
+	}
+}
+
+// LoadLayout reads a layout file. Fields it doesn't set keep their defaults.
+func LoadLayout(path string) (*Layout, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading PDF layout: %w", err)
+	}
+	return ParseLayout(data)
+}
+
+// ParseLayout parses a YAML layout over the defaults and validates it.
+func ParseLayout(data []byte) (*Layout, error) {
+	// Unmarshalling over the defaults keeps whatever the file leaves out
+	l := DefaultLayout()
+	if err := yaml.Unmarshal(data, l); err != nil {
+		return nil, fmt.Errorf("parsing PDF layout: %w", err)
+	}
+
+	if err := l.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid PDF layout: %w", err)
+	}
+	return l, nil
+}
+
+// Validate checks the page size, margins, section names, and locales.
+func (l *Layout) Validate() error {
+	validSize := false
+	for _, s := range pageSizes {
+		if strings.EqualFold(l.PageSize, s) {
+			validSize = true
+		}
+	}
+	if !validSize {
+		return fmt.Errorf("unknown page size %q (supported: %s)", l.PageSize, strings.Join(pageSizes, ", "))
+	}
+
+	for _, m := range []float64{l.Margins.Left, l.Margins.Top, l.Margins.Right, l.Margins.Bottom} {
+		if m < minMargin || m > maxMargin {
+			return fmt.Errorf("margins must be between %g and %g mm, got %g", minMargin, maxMargin, m)
+		}
+	}
+
+	seen := make(map[string]bool)
+	hasShare := false
+	for _, name := range l.Sections {
+		if _, ok := sections[name]; !ok {
+			return fmt.Errorf("unknown section %q (available: %s)", name, strings.Join(DefaultSections, ", "))
+		}
+		if seen[name] {
+			return fmt.Errorf("section %q is listed twice", name)
+		}
+		seen[name] = true
+		for _, s := range shareSections {
+			if name == s {
+				hasShare = true
+			}
+		}
+	}
+	if !hasShare {
+		return fmt.Errorf("sections must include at least one of %s, or the PDF would not contain the share", strings.Join(shareSections, ", "))
+	}
+	return l.validateLocales()
+}
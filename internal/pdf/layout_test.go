@@ -0,0 +1,119 @@
+package pdf
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestParseLayoutKeepsDefaults(t *testing.T) {
+	l, err := ParseLayout([]byte("page_size: Letter\nmargins:\n  left: 25\n"))
+	if err != nil {
+		t.Fatalf("ParseLayout: %v", err)
+	}
+	if l.PageSize != "Letter" {
+		t.Errorf("page size = %q, want Letter", l.PageSize)
+	}
+	if l.Margins.Left != 25 || l.Margins.Top != defaultMargin {
+		t.Errorf("margins = %+v, want left 25 and the rest default", l.Margins)
+	}
+	if len(l.Sections) != len(DefaultSections) {
+		t.Errorf("sections = %v, want defaults", l.Sections)
+	}
+}
+
+func TestParseLayoutRejectsInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		yaml string
+		want string
+	}{
+		{"unknown section", "sections: [title, share, footer]", "unknown section"},
+		{"duplicate section", "sections: [share, share]", "listed twice"},
+		{"no share", "sections: [title, about, metadata]", "would not contain the share"},
+		{"page size", "page_size: B5", "unknown page size"},
+		{"margin", "margins:\n  top: 2", "margins must be"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseLayout([]byte(tt.yaml))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("got %v, want error containing %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestGenerateReadmeCustomLayout(t *testing.T) {
+	l, err := ParseLayout([]byte("page_size: Letter\nsections: [share, title, machine_readable]\n"))
+	if err != nil {
+		t.Fatalf("ParseLayout: %v", err)
+	}
+	data := testReadmeData()
+
+	full, err := GenerateReadme(data)
+	if err != nil {
+		t.Fatalf("GenerateReadme (default): %v", err)
+	}
+	data.Layout = l
+	custom, err := GenerateReadme(data)
+	if err != nil {
+		t.Fatalf("GenerateReadme (custom): %v", err)
+	}
+	if !bytes.HasPrefix(custom, []byte("%PDF-")) {
+		t.Error("output does not start with PDF header")
+	}
+	if bytes.Equal(full, custom) {
+		t.Error("custom layout produced the same PDF as the default")
+	}
+}
+
+func TestParseLayoutLocales(t *testing.T) {
+	l, err := ParseLayout([]byte(`locales:
+  de:
+    steps: [open, contact, manifest, add, wait, download]
+    text:
+      recover_offline: "Funktioniert ohne Internet."
+  en:
+    date_format: "January 2, 2006"
+`))
+	if err != nil {
+		t.Fatalf("ParseLayout: %v", err)
+	}
+	de := l.locale("de")
+	if de.DateFormat != "02.01.2006" {
+		t.Errorf("de date format = %q, want the built-in one", de.DateFormat)
+	}
+	if de.Steps[1] != "contact" || de.Text["recover_offline"] == "" {
+		t.Errorf("de locale = %+v, want the layout's steps and text", de)
+	}
+	if en := l.locale("en"); en.DateFormat != "January 2, 2006" || len(en.Steps) != len(DefaultSteps) {
+		t.Errorf("en locale = %+v, want the layout's date format and the default steps", en)
+	}
+	if es := l.locale("es"); es.DateFormat != "02/01/2006" {
+		t.Errorf("es date format = %q, want the built-in one", es.DateFormat)
+	}
+}
+
+func TestParseLayoutRejectsInvalidLocales(t *testing.T) {
+	tests := []struct {
+		name string
+		yaml string
+		want string
+	}{
+		{"language", "locales:\n  fi:\n    date_format: 02.01.2006", "unknown language"},
+		{"date without year", "locales:\n  de:\n    date_format: 02.01.", "must show the day, month, and year"},
+		{"unknown step", "locales:\n  de:\n    steps: [open, manifest, contact, add, wait, download, celebrate]", "unknown recovery step"},
+		{"missing step", "locales:\n  de:\n    steps: [open, manifest, add, wait, download]", `"contact" is missing`},
+		{"duplicate step", "locales:\n  de:\n    steps: [open, open, manifest, contact, add, wait, download]", "listed twice"},
+		{"text key", "locales:\n  de:\n    text:\n      recover_stepp1: Los", "unknown text keys: recover_stepp1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseLayout([]byte(tt.yaml))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("got %v, want error containing %q", err, tt.want)
+			}
+		})
+	}
+}
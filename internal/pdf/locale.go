@@ -0,0 +1,129 @@
+package pdf
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/eljojo/rememory/internal/translations"
+)
+
+// Locale adapts README.pdf to a language beyond its translated strings: how
+// dates are written, the order of the browser recovery steps, and the
+// wording of any string. Layouts set them per language under locales;
+// anything left out keeps the built-in value.
+type Locale struct {
+	DateFormat string            `yaml:"date_format"` // Go time layout for dates in the text
+	Steps      []string          `yaml:"steps"`       // Browser recovery steps, in order
+	Text       map[string]string `yaml:"text"`        // Replaces readme translations by key
+}
+
+// DefaultSteps is the built-in order of the browser recovery steps. Bundles
+// without contacts skip "contact".
+var DefaultSteps = []string{"open", "manifest", "contact", "add", "wait", "download"}
+
+// defaultLocales are the date formats each language writes by default. The
+// metadata section keeps RFC 3339 whatever the language, so it stays
+// machine-readable.
+var defaultLocales = map[string]Locale{
+	"en":    {DateFormat: "2006-01-02"},
+	"es":    {DateFormat: "02/01/2006"},
+	"de":    {DateFormat: "02.01.2006"},
+	"fr":    {DateFormat: "02/01/2006"},
+	"sl":    {DateFormat: "2. 1. 2006"},
+	"pt":    {DateFormat: "02/01/2006"},
+	"zh-TW": {DateFormat: "2006年1月2日"},
+}
+
+// locale returns the rules for lang: the built-in ones with whatever the
+// layout sets on top.
+func (l *Layout) locale(lang string) Locale {
+	loc := defaultLocales[lang]
+	if loc.DateFormat == "" {
+		loc.DateFormat = defaultLocales["en"].DateFormat
+	}
+	loc.Steps = DefaultSteps
+	custom := l.Locales[lang]
+	if custom.DateFormat != "" {
+		loc.DateFormat = custom.DateFormat
+	}
+	if len(custom.Steps) > 0 {
+		loc.Steps = custom.Steps
+	}
+	loc.Text = custom.Text
+	return loc
+}
+
+// validate checks that the date format writes the whole date, that the
+// steps leave none out, and that every text key is a readme translation.
+func (loc Locale) validate(keys map[string]bool) error {
+	if loc.DateFormat != "" {
+		day := time.Date(2031, 12, 25, 0, 0, 0, 0, time.UTC)
+		parsed, err := time.Parse(loc.DateFormat, day.Format(loc.DateFormat))
+		if err != nil || !parsed.Equal(day) {
+			return fmt.Errorf("date_format %q must show the day, month, and year (it uses Go's layout: 2006-01-02 is year-month-day)", loc.DateFormat)
+		}
+	}
+
+	if len(loc.Steps) > 0 {
+		seen := make(map[string]bool)
+		for _, step := range loc.Steps {
+			if _, ok := recoverySteps[step]; !ok {
+				return fmt.Errorf("unknown recovery step %q (available: %s)", step, strings.Join(DefaultSteps, ", "))
+			}
+			if seen[step] {
+				return fmt.Errorf("recovery step %q is listed twice", step)
+			}
+			seen[step] = true
+		}
+		// Dropping a step would leave whoever recovers without an instruction
+		for _, step := range DefaultSteps {
+			if !seen[step] {
+				return fmt.Errorf("steps must include every recovery step; %q is missing", step)
+			}
+		}
+	}
+
+	unknown := []string{}
+	for key := range loc.Text {
+		if !keys[key] {
+			unknown = append(unknown, key)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return fmt.Errorf("unknown text keys: %s", strings.Join(unknown, ", "))
+	}
+	return nil
+}
+
+// validateLocales checks the locales a layout sets.
+func (l *Layout) validateLocales() error {
+	if len(l.Locales) == 0 {
+		return nil
+	}
+	keyList, err := translations.GetComponentKeys("readme")
+	if err != nil {
+		return err
+	}
+	keys := make(map[string]bool, len(keyList))
+	for _, k := range keyList {
+		keys[k] = true
+	}
+	langs := make([]string, 0, len(l.Locales))
+	for lang := range l.Locales {
+		langs = append(langs, lang)
+	}
+	sort.Strings(langs)
+	for _, lang := range langs {
+		if !slices.Contains(translations.Languages, lang) {
+			return fmt.Errorf("locales: unknown language %q (supported: %s)", lang, strings.Join(translations.Languages, ", "))
+		}
+		if err := l.Locales[lang].validate(keys); err != nil {
+			return fmt.Errorf("locales: %s: %w", lang, err)
+		}
+	}
+	return nil
+}
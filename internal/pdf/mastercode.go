@@ -0,0 +1,97 @@
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+
+	"github.com/go-pdf/fpdf"
+)
+
+// MasterCodeData is what MASTER-CODE.pdf shows.
+type MasterCodeData struct {
+	ProjectName      string
+	Key              string   // The code as an age secret key, which the QR code holds
+	Words            []string // The code as words, for typing it in
+	Recipient        string   // Its public key, as project.yml has it
+	Version          string
+	GitHubReleaseURL string
+	Created          time.Time
+}
+
+// GenerateMasterCodeSheet creates MASTER-CODE.pdf: the owner's master code
+// as a QR code and words, with what it opens and how to use it. Like the
+// pieces of 'rememory split', it is in English only.
+func GenerateMasterCodeSheet(data MasterCodeData) ([]byte, error) {
+	if data.Key == "" || len(data.Words) == 0 {
+		return nil, fmt.Errorf("no master code to print")
+	}
+	layout := DefaultLayout()
+	p := fpdf.New("P", "mm", layout.PageSize, "")
+	if !data.Created.IsZero() {
+		p.SetCreationDate(data.Created)
+		p.SetModificationDate(data.Created)
+	}
+	p.SetCatalogSort(true)
+	p.SetMargins(layout.Margins.Left, layout.Margins.Top, layout.Margins.Right)
+	p.SetAutoPageBreak(true, layout.Margins.Bottom)
+	registerUTF8Fonts(p)
+
+	p.AddPage()
+	pageWidth, _ := p.GetPageSize()
+	leftMargin, _, rightMargin, _ := p.GetMargins()
+	contentWidth := pageWidth - leftMargin - rightMargin
+	p.SetFillColor(46, 42, 38)
+	p.Rect(0, 0, pageWidth, 4, "F")
+
+	p.Ln(12)
+	fitCell(p, "Master Recovery Code", "B", titleSize, 12, "C", false)
+	p.Ln(3)
+	fitCell(p, fmt.Sprintf("for %q", data.ProjectName), "", 14, 8, "C", false)
+	p.Ln(10)
+
+	addSection(p, "What is this?")
+	addBody(p, fmt.Sprintf("This code opens %q on its own, without any of the pieces given to friends. "+
+		"It was printed once and isn't kept anywhere else: keep it in a vault, a safe deposit box, or a safe, "+
+		"and don't photograph it or type it into a computer you don't trust.", data.ProjectName))
+	addBody(p, "Anyone holding it can open everything the pieces protect. If it is lost or seen by someone else, "+
+		"seal the project again with --new-master-code and destroy this sheet.")
+	p.Ln(5)
+
+	addSection(p, "To use it")
+	addBody(p, "1. Download ReMemory from "+data.GitHubReleaseURL)
+	addBody(p, "2. In the project folder, run the command below and type the words, or scan the QR code and paste what it holds:")
+	p.SetFont(fontMono, "", monoSize)
+	p.CellFormat(0, 5, "   rememory unseal --master-code", "", 1, "L", false, 0, "")
+	addBody(p, "Without the project, put the words or the QR code's text in a file and, with the MANIFEST.age and RECIPIENTS.age from its output folder, run:")
+	p.SetFont(fontMono, "", monoSize)
+	p.CellFormat(0, 5, "   rememory-recover decrypt -m MANIFEST.age -i code.txt", "", 1, "L", false, 0, "")
+	p.Ln(5)
+
+	ensureSpace(p, 10+2+qrSizeMM+3)
+	addSection(p, "The code")
+	if err := placeQR(p, "master-code", data.Key, leftMargin+(contentWidth-qrSizeMM)/2, qrSizeMM); err != nil {
+		return nil, err
+	}
+	p.SetY(p.GetY() + qrSizeMM + 3)
+	p.SetFont(fontMono, "", smallMono)
+	p.CellFormat(0, 4, groupChars(data.Key, 6), "", 1, "C", false, 0, "")
+	p.Ln(4)
+
+	renderWordGridPDF(p, data.Words, fmt.Sprintf("The same code as %d words", len(data.Words)), leftMargin, contentWidth)
+	p.Ln(5)
+
+	p.SetFont(fontSans, "B", smallMono)
+	p.CellFormat(0, 5, "METADATA", "", 1, "L", false, 0, "")
+	p.SetFont(fontMono, "", smallMono)
+	p.SetFillColor(245, 245, 245)
+	addMeta(p, "rememory-version", data.Version)
+	addMeta(p, "created", data.Created.Format(time.RFC3339))
+	addMeta(p, "unlocks", data.Recipient)
+
+	var buf bytes.Buffer
+	if err := p.Output(&buf); err != nil {
+		return nil, fmt.Errorf("writing PDF: %w", err)
+	}
+	return buf.Bytes(), nil
+}
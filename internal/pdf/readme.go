@@ -3,7 +3,9 @@ package pdf
 import (
 	"bytes"
 	"fmt"
+	"math"
 	"net/url"
+	"regexp"
 	"strings"
 	"time"
 
@@ -12,7 +14,9 @@ import (
 	"golang.org/x/text/unicode/norm"
 
 	"github.com/eljojo/rememory/internal/core"
+	"github.com/eljojo/rememory/internal/faq"
 	"github.com/eljojo/rememory/internal/project"
+	"github.com/eljojo/rememory/internal/rotation"
 	"github.com/eljojo/rememory/internal/translations"
 )
 
@@ -21,18 +25,26 @@ type ReadmeData struct {
 	ProjectName      string
 	Holder           string
 	Share            *core.Share
+	Extra            []*core.Share // The holder's further pieces, when they hold more than one
+	Weighted         bool          // Some friends hold more than one piece, so Total counts pieces, not people
 	OtherFriends     []project.Friend
 	Threshold        int
 	Total            int
+	Groups           *project.GroupPolicy // Recovery rule of a grouped project; nil otherwise
 	Version          string
 	GitHubReleaseURL string
 	ManifestChecksum string
 	RecoverChecksum  string
 	Created          time.Time
-	Anonymous        bool
-	RecoveryURL      string // Base URL for QR code (e.g. "https://example.com/recover.html")
-	Language         string // Bundle language (e.g. "en", "es"); defaults to "en"
-	ManifestEmbedded bool   // true when manifest is embedded in recover.html
+	Privacy          project.Privacy // What the PDF tells about the other friends
+	RecoveryURL      string          // Base URL for QR code (e.g. "https://example.com/recover.html")
+	Language         string          // Bundle language (e.g. "en", "es"); defaults to "en"
+	WordList         string          // Recovery word list language; defaults to Language
+	ManifestEmbedded bool            // true when manifest is embedded in recover.html
+	Rotation         *rotation.Note  // Earlier seals these bundles replace; nil when none
+	Delay            string          // How long recovery waits once enough pieces come together; empty without a delay
+	Layout           *Layout         // Page setup and section order; nil uses DefaultLayout
+	FAQ              faq.Facts       // What the holder's questions are answered from; the zero value leaves them out
 }
 
 // Font sizes
@@ -63,7 +75,13 @@ const qrSizeMM = 70.0
 // QRContent returns the string that will be encoded in the QR code.
 // Returns "URL#share=COMPACT". If RecoveryURL is not set, defaults to the production URL.
 func (d ReadmeData) QRContent() string {
-	compact := d.Share.CompactEncode()
+	return d.qrContent(d.Share)
+}
+
+// qrContent returns what a QR code for share holds, the holder's own or
+// one of their further pieces.
+func (d ReadmeData) qrContent(share *core.Share) string {
+	compact := share.CompactEncode()
 	recoveryURL := d.RecoveryURL
 	if recoveryURL == "" {
 		recoveryURL = core.DefaultRecoveryURL
@@ -71,19 +89,33 @@ func (d ReadmeData) QRContent() string {
 	return recoveryURL + "#share=" + url.QueryEscape(compact)
 }
 
+// QRCodes returns what each printed QR code holds: QRContent on its own, or
+// split over several codes when it is too long for one to scan reliably.
+func (d ReadmeData) QRCodes() []string {
+	return core.SplitQR(d.QRContent())
+}
+
 // GenerateReadme creates the README.pdf content.
 func GenerateReadme(data ReadmeData) ([]byte, error) {
+	layout := data.Layout
+	if layout == nil {
+		layout = DefaultLayout()
+	}
+
 	lang := data.Language
 	if lang == "" {
 		lang = "en"
 	}
-	t := func(key string, args ...any) string {
-		return translations.T("readme", lang, key, args...)
-	}
 
-	p := fpdf.New("P", "mm", "A4", "")
-	p.SetMargins(20, 20, 20)
-	p.SetAutoPageBreak(true, 20)
+	p := fpdf.New("P", "mm", layout.PageSize, "")
+	// Same inputs, same bytes: take dates from the seal and sort the catalog
+	if !data.Created.IsZero() {
+		p.SetCreationDate(data.Created)
+		p.SetModificationDate(data.Created)
+	}
+	p.SetCatalogSort(true)
+	p.SetMargins(layout.Margins.Left, layout.Margins.Top, layout.Margins.Right)
+	p.SetAutoPageBreak(true, layout.Margins.Bottom)
 
 	// Register embedded UTF-8 TrueType fonts (DejaVu Sans)
 	registerUTF8Fonts(p)
@@ -121,168 +153,416 @@ func GenerateReadme(data ReadmeData) ([]byte, error) {
 	p.Rect(0, 0, pageWidth, 4, "F")
 
 	leftMargin, _, rightMargin, _ := p.GetMargins()
-	contentWidth := pageWidth - leftMargin - rightMargin
+	r := &readmeRenderer{
+		p:            p,
+		data:         data,
+		lang:         lang,
+		pageWidth:    pageWidth,
+		leftMargin:   leftMargin,
+		rightMargin:  rightMargin,
+		contentWidth: pageWidth - leftMargin - rightMargin,
+		locale:       layout.locale(lang),
+	}
+
+	for _, name := range layout.Sections {
+		if err := sections[name](r); err != nil {
+			return nil, err
+		}
+	}
+
+	// Write to buffer
+	var buf bytes.Buffer
+	if err := p.Output(&buf); err != nil {
+		return nil, fmt.Errorf("writing PDF: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
 
-	// ── Title area — certificate feel with breathing room ──
+// readmeRenderer holds what every section of README.pdf needs.
+type readmeRenderer struct {
+	p            *fpdf.Fpdf
+	data         ReadmeData
+	lang         string
+	pageWidth    float64
+	leftMargin   float64
+	rightMargin  float64
+	contentWidth float64
+	locale       Locale
+}
+
+func (r *readmeRenderer) t(key string, args ...any) string {
+	text, ok := r.locale.Text[key]
+	if !ok {
+		return translations.T("readme", r.lang, key, args...)
+	}
+	for i, arg := range args {
+		text = strings.Replace(text, fmt.Sprintf("{%d}", i), fmt.Sprint(arg), 1)
+	}
+	return text
+}
+
+// date writes d the way the bundle's language does.
+func (r *readmeRenderer) date(d time.Time) string {
+	return d.Format(r.locale.DateFormat)
+}
+
+// sections maps the names used in a Layout to the code that draws them.
+var sections = map[string]func(r *readmeRenderer) error{
+	"title":            renderTitle,
+	"about":            renderAbout,
+	"warning":          renderWarning,
+	"recovery_rule":    renderRecoveryRule,
+	"contacts":         renderContacts,
+	"sharing":          renderSharing,
+	"share":            renderShare,
+	"words":            renderWords,
+	"machine_readable": renderMachineReadable,
+	"recover_browser":  renderRecoverBrowser,
+	"recover_cli":      renderRecoverCLI,
+	"faq":              renderFAQ,
+	"metadata":         renderMetadata,
+}
+
+// ── Title area — certificate feel with breathing room ──
+func renderTitle(r *readmeRenderer) error {
+	p := r.p
 	p.Ln(12)
-	p.SetFont(fontSans, "B", titleSize)
-	p.CellFormat(0, 12, t("title"), "", 1, "C", false, 0, "")
+	fitCell(p, r.t("title"), "B", titleSize, 12, "C", false)
 	p.Ln(3)
 	// Decorative horizontal rule
 	p.SetDrawColor(180, 180, 180)
 	p.SetLineWidth(0.4)
 	ruleInset := 35.0
-	p.Line(leftMargin+ruleInset, p.GetY(), pageWidth-rightMargin-ruleInset, p.GetY())
+	p.Line(r.leftMargin+ruleInset, p.GetY(), r.pageWidth-r.rightMargin-ruleInset, p.GetY())
 	p.Ln(4)
-	p.SetFont(fontSans, "", 14)
-	p.CellFormat(0, 8, t("for", data.Holder), "", 1, "C", false, 0, "")
+	fitCell(p, r.t("for", r.data.Holder), "", 14, 8, "C", false)
 	p.Ln(12)
+	return nil
+}
 
-	// ── What is this? — context first ──
+// ── What is this? — context first ──
+func renderAbout(r *readmeRenderer) error {
+	p := r.p
 	p.SetFont(fontSans, "B", bodySize)
-	p.CellFormat(0, 6, t("what_is_this"), "", 1, "L", false, 0, "")
+	p.CellFormat(0, 6, r.t("what_is_this"), "", 1, "L", false, 0, "")
 	p.Ln(1)
-	addBody(p, t("what_bundle_for", data.ProjectName))
-	addBody(p, t("what_one_of", data.Total))
+	addBody(p, r.t("what_bundle_for", r.data.ProjectName))
+	if r.data.Weighted {
+		addBody(p, r.t("what_pieces", len(r.data.Extra)+1, r.data.Total))
+		addBody(p, r.t("what_threshold_pieces", r.data.Threshold))
+	} else {
+		addBody(p, r.t("what_one_of", r.data.Total))
+	}
+	if g := r.data.Groups; g != nil {
+		addBody(p, r.t("what_groups", g.Needed, len(g.Groups)))
+		for _, rule := range g.Groups {
+			key := "group_line"
+			if rule.Yours {
+				key = "group_yours"
+			}
+			addBody(p, "   \u2022 "+r.t(key, rule.Name, rule.Threshold, rule.Size))
+		}
+	}
 	p.Ln(5)
+	return nil
+}
 
-	// ── Warning stamp — soft, centered, calm ──
+// ── Warning stamp — soft, centered, calm ──
+func renderWarning(r *readmeRenderer) error {
+	p := r.p
 	p.SetFillColor(232, 239, 234)
 	p.SetTextColor(46, 42, 38)
-	p.SetFont(fontSans, "B", headingSize)
-	p.CellFormat(0, 11, t("warning_title"), "", 1, "C", true, 0, "")
+	fitCell(p, r.t("warning_title"), "B", headingSize, 11, "C", true)
 	p.SetFillColor(232, 242, 234)
 	p.SetFont(fontSans, "", 9)
-	if data.Anonymous {
-		p.MultiCell(0, 5, t("warning_message_shares"), "", "C", true)
+	if !r.data.Privacy.ListsOthers() {
+		p.MultiCell(0, 5, r.t("warning_message_shares"), "", "C", true)
 	} else {
-		p.MultiCell(0, 5, t("warning_message_friends"), "", "C", true)
+		p.MultiCell(0, 5, r.t("warning_message_friends"), "", "C", true)
+	}
+	if r.data.Share.Locked() {
+		p.SetFont(fontSans, "B", 9)
+		p.MultiCell(0, 5, r.t("pin_locked"), "", "C", true)
+	}
+	if r.data.Delay != "" {
+		p.SetFont(fontSans, "B", 9)
+		p.MultiCell(0, 5, r.t("recovery_delay", r.data.Delay), "", "C", true)
+	}
+	// The review and expiry dates the owner set, if any
+	if d := r.data.Share.ReviewBy; !d.IsZero() {
+		p.SetFont(fontSans, "B", 9)
+		p.MultiCell(0, 5, r.t("sunset_review", r.date(d)), "", "C", true)
+	}
+	if d := r.data.Share.Expires; !d.IsZero() {
+		p.SetFont(fontSans, "B", 9)
+		p.MultiCell(0, 5, r.t("sunset_expires", r.date(d)), "", "C", true)
+	}
+	if n := r.data.Rotation; n != nil {
+		p.SetFont(fontSans, "B", 9)
+		dates := make([]string, len(n.Superseded))
+		for i, g := range n.Superseded {
+			dates[i] = r.date(g.Sealed)
+		}
+		p.MultiCell(0, 5, r.t("superseded", strings.Join(dates, ", "), rotation.FileName), "", "C", true)
 	}
 	p.Ln(8)
+	return nil
+}
+
+// ── Recovery rule — prominent standalone box ──
+func renderRecoveryRule(r *readmeRenderer) error {
+	p := r.p
+	// The box grows to fit the text: in some languages the rule takes two lines
+	ruleSize, ruleLines := fitLines(p, r.t("recovery_rule"), "", 9, r.contentWidth)
+	count := r.t("recovery_rule_count", r.data.Threshold, r.data.Total)
+	if g := r.data.Groups; g != nil {
+		count = r.t("recovery_rule_groups", g.Needed, len(g.Groups))
+	}
+	countSize, countLines := fitLines(p, count, "B", 18, r.contentWidth)
+	ruleBoxH := 2 + 5*float64(len(ruleLines)) + 1 + 10*float64(len(countLines)) + 2
+	ensureSpace(p, ruleBoxH)
 
-	// ── Recovery rule — prominent standalone box ──
 	p.SetFillColor(242, 242, 248)
 	p.SetDrawColor(140, 140, 160)
 	p.SetLineWidth(0.5)
 	ruleBoxY := p.GetY()
-	ruleBoxH := 20.0
-	p.Rect(leftMargin, ruleBoxY, contentWidth, ruleBoxH, "FD")
-	p.SetFont(fontSans, "", 9)
-	p.SetXY(leftMargin, ruleBoxY+2)
-	p.CellFormat(contentWidth, 5, t("recovery_rule"), "", 1, "C", false, 0, "")
-	p.SetFont(fontSans, "B", 18)
-	p.SetXY(leftMargin, ruleBoxY+8)
-	p.CellFormat(contentWidth, 10, t("recovery_rule_count", data.Threshold, data.Total), "", 1, "C", false, 0, "")
+	p.Rect(r.leftMargin, ruleBoxY, r.contentWidth, ruleBoxH, "FD")
+	p.SetXY(r.leftMargin, ruleBoxY+2)
+	p.SetFont(fontSans, "", ruleSize)
+	for _, line := range ruleLines {
+		p.CellFormat(r.contentWidth, 5, line, "", 1, "C", false, 0, "")
+	}
+	p.SetY(p.GetY() + 1)
+	p.SetFont(fontSans, "B", countSize)
+	for _, line := range countLines {
+		p.CellFormat(r.contentWidth, 10, line, "", 1, "C", false, 0, "")
+	}
 	p.SetY(ruleBoxY + ruleBoxH + 8)
 	p.SetDrawColor(0, 0, 0)
 	p.SetLineWidth(0.2)
+	return nil
+}
 
-	// ── Other share holders — contact card layout ──
-	if !data.Anonymous {
-		addSection(p, t("other_holders"))
-		for i, friend := range data.OtherFriends {
-			p.SetFont(fontSans, "B", bodySize)
-			if friend.Contact != "" {
-				nameStr := "   " + friend.Name + "  "
-				nameW := p.GetStringWidth(nameStr)
-				p.CellFormat(nameW, 7, nameStr, "", 0, "L", false, 0, "")
+// ── Other share holders — contact card layout ──
+func renderContacts(r *readmeRenderer) error {
+	if !r.data.Privacy.ListsOthers() {
+		return nil
+	}
+	p := r.p
+	addSection(p, r.t("other_holders"))
+	for i, friend := range r.data.OtherFriends {
+		p.SetFont(fontSans, "B", bodySize)
+		if friend.Contact != "" {
+			nameStr := "   " + friend.Name + "  "
+			nameW := p.GetStringWidth(nameStr)
+			contactStr := "\u2014  " + friend.Contact
+			p.SetFont(fontSans, "", bodySize)
+			if nameW+p.GetStringWidth(contactStr)+2*p.GetCellMargin() > r.contentWidth {
+				// Too long for one line: the contact goes under the name
+				p.SetFont(fontSans, "B", bodySize)
+				p.CellFormat(0, 7, nameStr, "", 1, "L", false, 0, "")
 				p.SetFont(fontSans, "", bodySize)
-				p.CellFormat(0, 7, "\u2014  "+friend.Contact, "", 1, "L", false, 0, "")
+				p.MultiCell(0, 5, "      "+friend.Contact, "", "L", false)
 			} else {
-				p.CellFormat(0, 7, "   "+friend.Name, "", 1, "L", false, 0, "")
-			}
-			if i < len(data.OtherFriends)-1 {
-				p.Ln(2)
+				p.SetFont(fontSans, "B", bodySize)
+				p.CellFormat(nameW, 7, nameStr, "", 0, "L", false, 0, "")
+				p.SetFont(fontSans, "", bodySize)
+				p.CellFormat(0, 7, contactStr, "", 1, "L", false, 0, "")
 			}
+		} else {
+			p.CellFormat(0, 7, "   "+friend.Name, "", 1, "L", false, 0, "")
+		}
+		if i < len(r.data.OtherFriends)-1 {
+			p.Ln(2)
 		}
-		p.Ln(8)
 	}
+	p.Ln(8)
+	return nil
+}
 
-	// ── Sharing your share — procedure card with grey background ──
+// ── Sharing your share — procedure card with grey background ──
+func renderSharing(r *readmeRenderer) error {
+	p := r.p
 	p.SetFillColor(245, 245, 245)
-	p.SetFont(fontSans, "B", headingSize)
-	p.CellFormat(0, 10, " "+t("sharing_title"), "", 1, "L", true, 0, "")
+	fitCell(p, " "+r.t("sharing_title"), "B", headingSize, 10, "L", true)
 	p.CellFormat(0, 2, "", "", 1, "", true, 0, "")
 	p.SetFont(fontSans, "", bodySize)
-	p.MultiCell(0, 5, " "+t("sharing_verify"), "", "L", true)
+	p.MultiCell(0, 5, " "+r.t("sharing_verify"), "", "L", true)
 	p.CellFormat(0, 3, "", "", 1, "", true, 0, "")
-	p.MultiCell(0, 5, "   \u2022 "+t("sharing_easiest"), "", "L", true)
-	p.MultiCell(0, 5, "   \u2022 "+t("sharing_readme_only"), "", "L", true)
-	p.MultiCell(0, 5, "   \u2022 "+t("sharing_words_phone"), "", "L", true)
-	p.MultiCell(0, 5, "   \u2022 "+t("sharing_qr_mail"), "", "L", true)
+	p.MultiCell(0, 5, "   \u2022 "+r.t("sharing_easiest"), "", "L", true)
+	p.MultiCell(0, 5, "   \u2022 "+r.t("sharing_readme_only"), "", "L", true)
+	p.MultiCell(0, 5, "   \u2022 "+r.t("sharing_words_phone"), "", "L", true)
+	p.MultiCell(0, 5, "   \u2022 "+r.t("sharing_qr_mail"), "", "L", true)
 	p.CellFormat(0, 3, "", "", 1, "", true, 0, "")
 	p.Ln(5)
+	return nil
+}
 
-	// Section: Your Share (QR code + PEM block)
-	// Ensure the section header + QR code + caption + compact string stay together
-	qrBlockHeight := 10.0 + 2.0 + qrSizeMM + 3.0 + 5.0 + 2.0 + 4.0 // header + gap + QR + gap + caption + gap + compact
-	{
-		_, pageHeight := p.GetPageSize()
-		_, _, _, bottomMargin := p.GetMargins()
-		usableBottom := pageHeight - bottomMargin
-		if p.GetY()+qrBlockHeight > usableBottom {
-			p.AddPage()
-		}
-	}
-	addSection(p, t("your_share"))
+// Section: Your Share (QR code + typed form)
+func renderShare(r *readmeRenderer) error {
+	p := r.p
+	// Ensure the section header + QR code + caption + typed form stay together
+	qrBlockHeight := 10.0 + 2.0 + qrSizeMM + 3.0 + 5.0 + 2.0 + 4.0 // header + gap + QR + gap + caption + gap + typed form
+	ensureSpace(p, qrBlockHeight)
+	addSection(p, r.t("your_share"))
 	p.Ln(2)
+	if err := renderPiece(r, "qrcode", r.data.QRCodes(), r.data.Share); err != nil {
+		return err
+	}
 
-	// Generate QR code PNG
-	qrContent := data.QRContent()
-	qrPNG, err := generateQRPNG(qrContent)
-	if err != nil {
-		return nil, fmt.Errorf("generating QR code: %w", err)
+	// A friend holding several pieces gets a QR code for each of them
+	for _, extra := range r.data.Extra {
+		ensureSpace(p, qrBlockHeight)
+		addSection(p, r.t("extra_piece", extra.Index))
+		p.Ln(2)
+		name := fmt.Sprintf("qrcode-piece-%d", extra.Index)
+		if err := renderPiece(r, name, core.SplitQR(r.data.qrContent(extra)), extra); err != nil {
+			return err
+		}
 	}
+	return nil
+}
 
-	// Register QR image and place it centered
-	qrReader := bytes.NewReader(qrPNG)
-	opts := fpdf.ImageOptions{ImageType: "PNG", ReadDpi: true}
-	p.RegisterImageOptionsReader("qrcode", opts, qrReader)
-	qrX := leftMargin + (contentWidth-qrSizeMM)/2
-	p.ImageOptions("qrcode", qrX, p.GetY(), qrSizeMM, qrSizeMM, false, opts, 0, "")
-	p.SetY(p.GetY() + qrSizeMM + 3)
+// renderPiece draws one piece's QR codes, named after name, and its typed form.
+func renderPiece(r *readmeRenderer, name string, codes []string, share *core.Share) error {
+	p := r.p
+	if len(codes) == 1 {
+		if err := placeQR(p, name, codes[0], r.leftMargin+(r.contentWidth-qrSizeMM)/2, qrSizeMM); err != nil {
+			return err
+		}
+		p.SetY(p.GetY() + qrSizeMM + 3)
 
-	// Caption under QR code
-	p.SetFont(fontSans, "I", bodySize)
-	p.CellFormat(0, 5, t("qr_caption"), "", 1, "C", false, 0, "")
-	p.Ln(2)
+		// Caption under QR code
+		fitCell(p, r.t("qr_caption"), "I", bodySize, 5, "C", false)
+		p.Ln(2)
+	} else if err := renderSplitQR(r, name, codes); err != nil {
+		return err
+	}
 
-	// Show the compact string below the QR for manual entry
-	compact := data.Share.CompactEncode()
+	// Show the piece below the QR for manual entry, in Bech32 so a mistyped
+	// character is caught; in groups of four so it's easy to keep one's place
+	typed := share.CompactEncode()
+	if b32, err := share.Bech32(); err == nil {
+		typed = groupChars(b32, 4)
+	}
 	p.SetFont(fontMono, "", smallMono)
 	p.SetFillColor(245, 245, 245)
-	p.CellFormat(0, 4, compact, "", 1, "C", true, 0, "")
+	p.CellFormat(0, 4, typed, "", 1, "C", true, 0, "")
 	p.Ln(8)
+	return nil
+}
 
-	// Word grids (recovery words in two columns)
-	nativeWords, _ := data.Share.WordsForLang(core.Lang(lang))
-	if len(nativeWords) > 0 {
-		if lang != "en" {
-			// Non-English: show native language grid first, then English
-			langName := t("lang_" + lang)
-			renderWordGridPDF(p, nativeWords, t("recovery_words_title_lang", len(nativeWords), langName), leftMargin, contentWidth)
-			p.SetFont(fontSans, "I", bodySize)
-			p.MultiCell(0, 5, t("recovery_words_hint"), "", "L", false)
-			p.Ln(5)
-
-			// English fallback grid
-			englishWords, _ := data.Share.Words()
-			renderWordGridPDF(p, englishWords, t("recovery_words_title_english", len(englishWords)), leftMargin, contentWidth)
-			p.SetFont(fontSans, "I", bodySize)
-			p.MultiCell(0, 5, t("recovery_words_dual_hint"), "", "L", false)
-			p.Ln(5)
-		} else {
-			// English only: single grid
-			renderWordGridPDF(p, nativeWords, t("recovery_words_title", len(nativeWords)), leftMargin, contentWidth)
-			p.SetFont(fontSans, "I", bodySize)
-			p.MultiCell(0, 5, t("recovery_words_hint"), "", "L", false)
-			p.Ln(5)
+// renderSplitQR places the parts of a split QR code side by side, as large
+// as fit in a row, each numbered so a missing one is easy to spot.
+func renderSplitQR(r *readmeRenderer, name string, codes []string) error {
+	p := r.p
+	const gap = 6.0
+	size := min(qrSizeMM, (r.contentWidth-gap*float64(len(codes)-1))/float64(len(codes)))
+	rowWidth := size*float64(len(codes)) + gap*float64(len(codes)-1)
+	y := p.GetY()
+	p.SetFont(fontSans, "", smallMono)
+	for i, code := range codes {
+		x := r.leftMargin + (r.contentWidth-rowWidth)/2 + float64(i)*(size+gap)
+		p.SetY(y)
+		if err := placeQR(p, fmt.Sprintf("%s-%d", name, i+1), code, x, size); err != nil {
+			return err
+		}
+		p.SetXY(x, y+size+1)
+		p.CellFormat(size, 4, fmt.Sprintf("%d / %d", i+1, len(codes)), "", 0, "C", false, 0, "")
+	}
+	p.SetY(y + size + 6)
+
+	p.SetFont(fontSans, "I", bodySize)
+	p.MultiCell(0, 5, r.t("qr_caption_split", len(codes)), "", "C", false)
+	p.Ln(2)
+	return nil
+}
+
+// placeQR draws a QR code holding content, size mm square, at x on the current line.
+func placeQR(p *fpdf.Fpdf, name, content string, x, size float64) error {
+	qrPNG, err := generateQRPNG(content)
+	if err != nil {
+		return fmt.Errorf("generating QR code: %w", err)
+	}
+	opts := fpdf.ImageOptions{ImageType: "PNG", ReadDpi: true}
+	p.RegisterImageOptionsReader(name, opts, bytes.NewReader(qrPNG))
+	p.ImageOptions(name, x, p.GetY(), size, size, false, opts, 0, "")
+	return nil
+}
+
+// groupChars splits s into space-separated groups of n characters.
+func groupChars(s string, n int) string {
+	var sb strings.Builder
+	for i := 0; i < len(s); i += n {
+		if i > 0 {
+			sb.WriteByte(' ')
 		}
+		sb.WriteString(s[i:min(i+n, len(s))])
 	}
+	return sb.String()
+}
+
+// Word grids (recovery words in two columns)
+func renderWords(r *readmeRenderer) error {
+	p := r.p
+	lang := r.lang
+	if r.data.WordList != "" {
+		lang = r.data.WordList
+	}
+	nativeWords, _ := r.data.Share.WordsForLang(core.Lang(lang))
+	if len(nativeWords) == 0 {
+		return nil
+	}
+	if lang != "en" {
+		// Non-English: show native language grid first, then English
+		langName := r.t("lang_" + lang)
+		renderWordGridPDF(p, nativeWords, r.t("recovery_words_title_lang", len(nativeWords), langName), r.leftMargin, r.contentWidth)
+		p.SetFont(fontSans, "I", bodySize)
+		p.MultiCell(0, 5, r.t("recovery_words_hint"), "", "L", false)
+		p.Ln(5)
+
+		// English fallback grid
+		englishWords, _ := r.data.Share.Words()
+		renderWordGridPDF(p, englishWords, r.t("recovery_words_title_english", len(englishWords)), r.leftMargin, r.contentWidth)
+		p.SetFont(fontSans, "I", bodySize)
+		p.MultiCell(0, 5, r.t("recovery_words_dual_hint"), "", "L", false)
+		p.Ln(5)
+	} else {
+		// English only: single grid
+		renderWordGridPDF(p, nativeWords, r.t("recovery_words_title", len(nativeWords)), r.leftMargin, r.contentWidth)
+		p.SetFont(fontSans, "I", bodySize)
+		p.MultiCell(0, 5, r.t("recovery_words_hint"), "", "L", false)
+		p.Ln(5)
+	}
+	renderDigits(r)
+	return nil
+}
+
+// renderDigits writes the share as digit groups, six to a line, under the words.
+func renderDigits(r *readmeRenderer) {
+	groups, err := r.data.Share.Digits()
+	if err != nil {
+		return
+	}
+	p := r.p
+	ensureSpace(p, 10+3*6+15)
+	addSection(p, r.t("recovery_digits_title"))
+	p.SetFont(fontMono, "", bodySize)
+	for i := 0; i < len(groups); i += 6 {
+		p.CellFormat(0, 6, strings.Join(groups[i:i+6], "   "), "", 1, "C", false, 0, "")
+	}
+	p.Ln(2)
+	p.SetFont(fontSans, "I", bodySize)
+	p.MultiCell(0, 5, r.t("recovery_digits_hint"), "", "L", false)
+	p.Ln(5)
+}
 
-	// PEM block (machine-readable format)
+// PEM block (machine-readable format)
+func renderMachineReadable(r *readmeRenderer) error {
+	p := r.p
 	// Ensure PEM block starts on a page with enough room for the header + content
-	shareText := data.Share.Encode()
+	shareText := core.EncodeShares(append([]*core.Share{r.data.Share}, r.data.Extra...))
 	shareLines := strings.Split(shareText, "\n")
 	pemHeight := 10.0 // section header
 	for _, line := range shareLines {
@@ -292,15 +572,8 @@ func GenerateReadme(data ReadmeData) ([]byte, error) {
 			pemHeight += 1.5
 		}
 	}
-	{
-		_, pageHeight := p.GetPageSize()
-		_, _, _, bottomMargin := p.GetMargins()
-		usableBottom := pageHeight - bottomMargin
-		if p.GetY()+pemHeight > usableBottom {
-			p.AddPage()
-		}
-	}
-	addSection(p, t("machine_readable"))
+	ensureSpace(p, pemHeight)
+	addSection(p, r.t("machine_readable"))
 	p.SetFont(fontMono, "", smallMono)
 	p.SetFillColor(245, 245, 245)
 
@@ -312,61 +585,145 @@ func GenerateReadme(data ReadmeData) ([]byte, error) {
 		}
 	}
 	p.Ln(5)
+	return nil
+}
 
-	// Section: Browser recovery
-	addSection(p, t("recover_browser"))
-	addBody(p, t("recover_step1"))
-	p.Ln(2)
-	p.SetFont(fontSans, "B", bodySize)
-	p.MultiCell(0, 5, "   "+t("recover_share_loaded"), "", "L", false)
-	p.SetFont(fontSans, "", bodySize)
-	p.MultiCell(0, 5, "   "+t("recover_no_html"), "", "L", false)
-	p.Ln(2)
-	if data.ManifestEmbedded {
-		addBody(p, t("recover_step2_embedded"))
-		addBody(p, "   "+t("recover_step2_embedded_hint"))
-	} else {
-		addBody(p, t("recover_step2"))
-		addBody(p, "   "+t("recover_step2_drag"))
-		addBody(p, "   "+t("recover_step2_click"))
-	}
-	p.Ln(2)
-	if data.Anonymous {
-		addBody(p, t("recover_anon_step3"))
-		addBody(p, "   "+t("recover_anon_step3_drag"))
-		addBody(p, "   "+t("recover_anon_step3_paste"))
-		p.Ln(2)
-		addBody(p, t("recover_anon_step4_auto", data.Threshold))
-		p.Ln(2)
-		addBody(p, t("recover_anon_step5"))
-	} else {
-		addBody(p, t("recover_step3_contact"))
-		addBody(p, "   "+t("recover_step3_ask"))
-		p.Ln(2)
-		addBody(p, t("recover_step4"))
-		addBody(p, "   "+t("recover_step4_drag"))
-		addBody(p, "   "+t("recover_step4_paste"))
-		p.Ln(2)
-		addBody(p, t("recover_step5_checkmarks"))
-		addBody(p, "   "+t("recover_step5_auto", data.Threshold))
-		p.Ln(2)
-		addBody(p, t("recover_step6"))
+// Section: Browser recovery
+func renderRecoverBrowser(r *readmeRenderer) error {
+	p := r.p
+	addSection(p, r.t("recover_browser"))
+	n := 0
+	for _, name := range r.locale.Steps {
+		if recoverySteps[name](r, n+1) {
+			n++
+			p.Ln(2)
+		}
 	}
-	p.Ln(2)
 	p.SetFont(fontSans, "I", bodySize)
-	p.MultiCell(0, 5, t("recover_offline"), "", "L", false)
+	p.MultiCell(0, 5, r.t("recover_offline"), "", "L", false)
 	p.Ln(5)
+	return nil
+}
 
-	// Section: CLI fallback
-	addSection(p, t("recover_cli"))
-	addBody(p, t("recover_cli_hint"))
+// recoverySteps maps the step names a Locale orders to the code that draws
+// each one as step number n. A step that doesn't apply to this bundle
+// draws nothing and returns false, so the steps after it keep counting
+// from n.
+var recoverySteps = map[string]func(r *readmeRenderer, n int) bool{
+	"open": func(r *readmeRenderer, n int) bool {
+		p := r.p
+		addBody(p, stepNumber(n, r.t("recover_step1")))
+		p.Ln(2)
+		p.SetFont(fontSans, "B", bodySize)
+		p.MultiCell(0, 5, "   "+r.t("recover_share_loaded"), "", "L", false)
+		p.SetFont(fontSans, "", bodySize)
+		p.MultiCell(0, 5, "   "+r.t("recover_no_html"), "", "L", false)
+		return true
+	},
+	"manifest": func(r *readmeRenderer, n int) bool {
+		if r.data.ManifestEmbedded {
+			addBody(r.p, stepNumber(n, r.t("recover_step2_embedded")))
+			addBody(r.p, "   "+r.t("recover_step2_embedded_hint"))
+		} else {
+			addBody(r.p, stepNumber(n, r.t("recover_step2")))
+			addBody(r.p, "   "+r.t("recover_step2_drag"))
+			addBody(r.p, "   "+r.t("recover_step2_click"))
+		}
+		return true
+	},
+	"contact": func(r *readmeRenderer, n int) bool {
+		if !r.data.Privacy.ListsOthers() {
+			return false
+		}
+		addBody(r.p, stepNumber(n, r.t("recover_step3_contact")))
+		addBody(r.p, "   "+r.t("recover_step3_ask"))
+		return true
+	},
+	"add": func(r *readmeRenderer, n int) bool {
+		if !r.data.Privacy.ListsOthers() {
+			addBody(r.p, stepNumber(n, r.t("recover_anon_step3")))
+			addBody(r.p, "   "+r.t("recover_anon_step3_drag"))
+			addBody(r.p, "   "+r.t("recover_anon_step3_paste"))
+		} else {
+			addBody(r.p, stepNumber(n, r.t("recover_step4")))
+			addBody(r.p, "   "+r.t("recover_step4_drag"))
+			addBody(r.p, "   "+r.t("recover_step4_paste"))
+		}
+		return true
+	},
+	"wait": func(r *readmeRenderer, n int) bool {
+		grouped := r.data.Groups != nil
+		switch {
+		case !r.data.Privacy.ListsOthers() && grouped:
+			addBody(r.p, stepNumber(n, r.t("recover_anon_step4_auto_groups")))
+		case !r.data.Privacy.ListsOthers():
+			addBody(r.p, stepNumber(n, r.t("recover_anon_step4_auto", r.data.Threshold)))
+		default:
+			addBody(r.p, stepNumber(n, r.t("recover_step5_checkmarks")))
+			if grouped {
+				addBody(r.p, "   "+r.t("recover_step5_auto_groups"))
+			} else {
+				addBody(r.p, "   "+r.t("recover_step5_auto", r.data.Threshold))
+			}
+		}
+		return true
+	},
+	"download": func(r *readmeRenderer, n int) bool {
+		if !r.data.Privacy.ListsOthers() {
+			addBody(r.p, stepNumber(n, r.t("recover_anon_step5")))
+		} else {
+			addBody(r.p, stepNumber(n, r.t("recover_step6")))
+		}
+		return true
+	},
+}
+
+// stepNumber numbers a translated step by where it falls in the locale's
+// order, replacing the number the translation starts with (which follows
+// the built-in order README.txt uses).
+func stepNumber(n int, text string) string {
+	return fmt.Sprintf("%d. %s", n, stepPrefix.ReplaceAllString(text, ""))
+}
+
+var stepPrefix = regexp.MustCompile(`^\d+\.\s*`)
+
+// Section: CLI fallback
+func renderRecoverCLI(r *readmeRenderer) error {
+	p := r.p
+	addSection(p, r.t("recover_cli"))
+	addBody(p, r.t("recover_cli_hint"))
 	p.SetFont(fontMono, "", monoSize)
-	p.MultiCell(0, 5, data.GitHubReleaseURL, "", "L", false)
+	p.MultiCell(0, 5, r.data.GitHubReleaseURL, "", "L", false)
 	p.Ln(2)
-	addBody(p, t("recover_cli_usage"))
+	addBody(p, r.t("recover_cli_usage"))
 	p.Ln(5)
+	return nil
+}
 
-	// Footer: Metadata
+// Section: Questions a holder may have, answered from the seal
+func renderFAQ(r *readmeRenderer) error {
+	entries := faq.Build(r.data.FAQ, r.t)
+	if len(entries) == 0 {
+		return nil
+	}
+	p := r.p
+	ensureSpace(p, 30)
+	addSection(p, r.t("faq_title"))
+	for _, e := range entries {
+		ensureSpace(p, 15)
+		p.SetFont(fontSans, "B", bodySize)
+		p.MultiCell(0, 5, e.Question, "", "L", false)
+		addBody(p, e.Answer)
+		p.Ln(2)
+	}
+	p.Ln(3)
+	return nil
+}
+
+// Footer: Metadata
+func renderMetadata(r *readmeRenderer) error {
+	p := r.p
+	data := r.data
 	p.SetFont(fontSans, "B", smallMono)
 	p.CellFormat(0, 5, "METADATA", "", 1, "L", false, 0, "")
 	p.SetFont(fontMono, "", smallMono)
@@ -379,14 +736,16 @@ func GenerateReadme(data ReadmeData) ([]byte, error) {
 	addMeta(p, "github-release", data.GitHubReleaseURL)
 	addMeta(p, "checksum-manifest", data.ManifestChecksum)
 	addMeta(p, "checksum-recover-html", data.RecoverChecksum)
+	return nil
+}
 
-	// Write to buffer
-	var buf bytes.Buffer
-	if err := p.Output(&buf); err != nil {
-		return nil, fmt.Errorf("writing PDF: %w", err)
+// ensureSpace starts a new page unless height mm still fit on this one.
+func ensureSpace(p *fpdf.Fpdf, height float64) {
+	_, pageHeight := p.GetPageSize()
+	_, _, _, bottomMargin := p.GetMargins()
+	if p.GetY()+height > pageHeight-bottomMargin {
+		p.AddPage()
 	}
-
-	return buf.Bytes(), nil
 }
 
 // renderWordGridPDF renders a two-column word grid with page-break detection.
@@ -426,12 +785,52 @@ func renderWordGridPDF(p *fpdf.Fpdf, words []string, title string, leftMargin, c
 }
 
 func addSection(pdf *fpdf.Fpdf, title string) {
-	pdf.SetFont(fontSans, "B", headingSize)
 	pdf.SetFillColor(230, 230, 230)
-	pdf.CellFormat(0, 8, " "+title, "", 1, "L", true, 0, "")
+	fitCell(pdf, " "+title, "B", headingSize, 8, "L", true)
 	pdf.Ln(2)
 }
 
+// minFontScale is how far fitLines shrinks text before it wraps instead.
+const minFontScale = 0.8
+
+// fitLines sizes text for one line of width mm, shrinking the font down
+// to minFontScale of size if it must. Text that still doesn't fit is
+// wrapped at the smallest size instead, so a long translation never runs
+// off the page or loses words. It returns the font size and the lines.
+func fitLines(p *fpdf.Fpdf, text, style string, size, width float64) (float64, []string) {
+	p.SetFont(fontSans, style, size)
+	textWidth := p.GetStringWidth(text)
+	avail := width - 2*p.GetCellMargin()
+	if textWidth <= avail {
+		return size, []string{text}
+	}
+	// Text width grows with the font size; round down to half a point
+	if s := math.Floor(2*size*avail/textWidth) / 2; s >= size*minFontScale {
+		return s, []string{text}
+	}
+	p.SetFont(fontSans, style, size*minFontScale)
+	return size * minFontScale, p.SplitText(text, width)
+}
+
+// fitCell writes text from the current position to the right margin, on
+// one line of height h when it fits and wrapped when it doesn't (see fitLines).
+func fitCell(p *fpdf.Fpdf, text, style string, size, h float64, align string, fill bool) {
+	pageWidth, _ := p.GetPageSize()
+	_, _, rightMargin, _ := p.GetMargins()
+	width := pageWidth - rightMargin - p.GetX()
+	s, lines := fitLines(p, text, style, size, width)
+	p.SetFont(fontSans, style, s)
+	if len(lines) == 1 {
+		p.CellFormat(width, h, text, "", 1, align, fill, 0, "")
+		return
+	}
+	// Wrapped lines sit closer together, but the block keeps at least h
+	lineH := max(h/float64(len(lines)), p.PointConvert(s)*1.4)
+	for _, line := range lines {
+		p.CellFormat(width, lineH, line, "", 1, align, fill, 0, "")
+	}
+}
+
 func addBody(pdf *fpdf.Fpdf, text string) {
 	pdf.SetFont(fontSans, "", bodySize)
 	pdf.MultiCell(0, 5, text, "", "L", false)
@@ -4,11 +4,15 @@ import (
 	"bytes"
 	"image/png"
 	"net/url"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/go-pdf/fpdf"
+
 	"github.com/eljojo/rememory/internal/core"
 	"github.com/eljojo/rememory/internal/project"
+	"github.com/eljojo/rememory/internal/translations"
 )
 
 func testReadmeData() ReadmeData {
@@ -45,7 +49,7 @@ func TestGenerateReadme(t *testing.T) {
 
 func TestGenerateReadmeAnonymous(t *testing.T) {
 	data := testReadmeData()
-	data.Anonymous = true
+	data.Privacy = project.PrivacyAnonymous
 	data.OtherFriends = nil
 	pdfBytes, err := GenerateReadme(data)
 	if err != nil {
@@ -78,6 +82,34 @@ func TestQRContentWithRecoveryURL(t *testing.T) {
 	}
 }
 
+func TestQRCodesSplitLongContent(t *testing.T) {
+	data := testReadmeData()
+	if codes := data.QRCodes(); len(codes) != 1 || codes[0] != data.QRContent() {
+		t.Errorf("default content split into %d codes", len(codes))
+	}
+
+	data.RecoveryURL = "https://files.example.org/" + strings.Repeat("family-archive/", 10) + "recover.html"
+	codes := data.QRCodes()
+	if len(codes) < 2 {
+		t.Fatalf("content of %d bytes not split", len(data.QRContent()))
+	}
+	chunks := make([]*core.QRChunk, len(codes))
+	for i, code := range codes {
+		chunk, err := core.ParseQRChunk(code)
+		if err != nil {
+			t.Fatal(err)
+		}
+		chunks[i] = chunk
+	}
+	if joined, err := core.JoinQRChunks(chunks); err != nil || joined != data.QRContent() {
+		t.Errorf("joined %q, %v", joined, err)
+	}
+
+	if _, err := GenerateReadme(data); err != nil {
+		t.Fatalf("GenerateReadme: %v", err)
+	}
+}
+
 func TestQRCodeGeneratesValidPNG(t *testing.T) {
 	data := testReadmeData()
 
@@ -181,3 +213,102 @@ func TestQRCodeContentMatchesCompact(t *testing.T) {
 		t.Error("parsed share data mismatch")
 	}
 }
+
+func TestFitLines(t *testing.T) {
+	p := fpdf.New("P", "mm", "A4", "")
+	registerUTF8Fonts(p)
+	p.AddPage()
+
+	size, lines := fitLines(p, "Short", "B", headingSize, 170)
+	if size != headingSize || len(lines) != 1 {
+		t.Errorf("short text: size %g, %d lines; want it left alone", size, len(lines))
+	}
+
+	// A bit too wide: shrinks, stays on one line
+	p.SetFont(fontSans, "B", headingSize)
+	text := "Wiederherstellungsanleitung für Ihre Vertrauenspersonen"
+	width := p.GetStringWidth(text)*0.9 + 2*p.GetCellMargin()
+	size, lines = fitLines(p, text, "B", headingSize, width)
+	if size >= headingSize || size < headingSize*minFontScale || len(lines) != 1 {
+		t.Errorf("slightly long text: size %g, %d lines; want it shrunk onto one line", size, len(lines))
+	}
+
+	// Far too wide: wraps at the smallest size without losing a word
+	text = strings.Repeat("Vertrauensperson ", 12)
+	size, lines = fitLines(p, text, "B", headingSize, 80)
+	if size > headingSize*minFontScale+0.01 || len(lines) < 2 {
+		t.Errorf("long text: size %g, %d lines; want it wrapped", size, len(lines))
+	}
+	if got := strings.Join(strings.Fields(strings.Join(lines, " ")), " "); got != strings.TrimSpace(text) {
+		t.Errorf("wrapping lost text: %q", got)
+	}
+}
+
+func TestRecoveryRuleGrowsWithText(t *testing.T) {
+	ruleHeight := func(layout *Layout) float64 {
+		data := testReadmeData()
+		p := fpdf.New("P", "mm", "A4", "")
+		registerUTF8Fonts(p)
+		p.AddPage()
+		r := &readmeRenderer{p: p, data: data, lang: "de", pageWidth: 210, leftMargin: 20, rightMargin: 20, contentWidth: 170, locale: layout.locale("de")}
+		start := p.GetY()
+		if err := renderRecoveryRule(r); err != nil {
+			t.Fatal(err)
+		}
+		return p.GetY() - start
+	}
+
+	short := ruleHeight(DefaultLayout())
+	l, err := ParseLayout([]byte("locales:\n  de:\n    text:\n      recovery_rule: \"" + strings.Repeat("Sie brauchen mehrere Teile, um die Dateien wiederherzustellen. ", 4) + "\"\n"))
+	if err != nil {
+		t.Fatalf("ParseLayout: %v", err)
+	}
+	if long := ruleHeight(l); long <= short {
+		t.Errorf("box height %g with long text, %g without; want it to grow", long, short)
+	}
+}
+
+func TestLocaleTextAndDates(t *testing.T) {
+	l, err := ParseLayout([]byte("locales:\n  de:\n    text:\n      recover_anon_step4_auto: \"Mit {0} Teilen geht es von selbst\"\n"))
+	if err != nil {
+		t.Fatalf("ParseLayout: %v", err)
+	}
+	r := &readmeRenderer{lang: "de", locale: l.locale("de")}
+	if got := r.t("recover_anon_step4_auto", 3); got != "Mit 3 Teilen geht es von selbst" {
+		t.Errorf("override = %q", got)
+	}
+	if got := r.t("recover_offline"); got == "recover_offline" || got == translations.T("readme", "en", "recover_offline") {
+		t.Errorf("keys the layout leaves out should keep the German translation, got %q", got)
+	}
+	if got := r.date(time.Date(2031, 3, 9, 0, 0, 0, 0, time.UTC)); got != "09.03.2031" {
+		t.Errorf("date = %q, want 09.03.2031", got)
+	}
+}
+
+func TestStepNumber(t *testing.T) {
+	for text, want := range map[string]string{
+		"3. You'll see a contact list": "2. You'll see a contact list",
+		"3.使用任何":                       "2. 使用任何",
+		"No number":                    "2. No number",
+	} {
+		if got := stepNumber(2, text); got != want {
+			t.Errorf("stepNumber(2, %q) = %q, want %q", text, got, want)
+		}
+	}
+}
+
+func TestGenerateReadmeStepOrder(t *testing.T) {
+	l, err := ParseLayout([]byte("locales:\n  en:\n    steps: [open, contact, manifest, add, wait, download]\n"))
+	if err != nil {
+		t.Fatalf("ParseLayout: %v", err)
+	}
+	for _, privacy := range project.PrivacyLevels {
+		data := testReadmeData()
+		data.Privacy = privacy
+		data.Share.ReviewBy = time.Date(2031, 3, 9, 0, 0, 0, 0, time.UTC)
+		data.Layout = l
+		if _, err := GenerateReadme(data); err != nil {
+			t.Errorf("GenerateReadme (%s): %v", privacy, err)
+		}
+	}
+}
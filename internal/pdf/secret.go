@@ -0,0 +1,109 @@
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-pdf/fpdf"
+
+	"github.com/eljojo/rememory/internal/core"
+)
+
+// SecretPieceData is what README.pdf of a piece from 'rememory split' shows.
+type SecretPieceData struct {
+	Name             string // What was split, as the owner named it
+	Holder           string
+	Shares           []*core.Share // The holder's pieces
+	Threshold        int
+	Total            int
+	Version          string
+	GitHubReleaseURL string
+	Created          time.Time
+}
+
+// GenerateSecretPiece creates README.pdf for a piece of a secret split on
+// its own: what it is, how to combine it, and the piece itself. It has no
+// QR code or recovery words, since recover.html can't read these pieces.
+// Like README.txt for these pieces, it is in English only.
+func GenerateSecretPiece(data SecretPieceData) ([]byte, error) {
+	if len(data.Shares) == 0 {
+		return nil, fmt.Errorf("no piece to print")
+	}
+	layout := DefaultLayout()
+	p := fpdf.New("P", "mm", layout.PageSize, "")
+	if !data.Created.IsZero() {
+		p.SetCreationDate(data.Created)
+		p.SetModificationDate(data.Created)
+	}
+	p.SetCatalogSort(true)
+	p.SetMargins(layout.Margins.Left, layout.Margins.Top, layout.Margins.Right)
+	p.SetAutoPageBreak(true, layout.Margins.Bottom)
+	registerUTF8Fonts(p)
+	bc := bundleColors[(data.Shares[0].Index-1)%len(bundleColors)]
+	p.SetFooterFunc(func() {
+		p.SetY(-15)
+		p.SetFont(fontSans, "", 7)
+		p.SetTextColor(180, 180, 180)
+		p.CellFormat(0, 10, fmt.Sprintf("%d", p.PageNo()), "", 0, "C", false, 0, "")
+		p.SetTextColor(46, 42, 38)
+	})
+
+	p.AddPage()
+	pageWidth, _ := p.GetPageSize()
+	p.SetFillColor(bc[0], bc[1], bc[2])
+	p.Rect(0, 0, pageWidth, 4, "F")
+
+	p.Ln(12)
+	fitCell(p, "Piece of a Secret", "B", titleSize, 12, "C", false)
+	p.Ln(3)
+	fitCell(p, fmt.Sprintf("%q for %s", data.Name, data.Holder), "", 14, 8, "C", false)
+	p.Ln(12)
+
+	addSection(p, "What is this?")
+	addBody(p, fmt.Sprintf("This is your piece of %q, a secret that was split into %d pieces and given to people its owner trusts. "+
+		"One piece on its own reveals nothing. Any %d of them together give the secret back, exactly as it was.", data.Name, data.Total, data.Threshold))
+	if len(data.Shares) > 1 {
+		addBody(p, fmt.Sprintf("You hold %d of the pieces.", len(data.Shares)))
+	}
+	addBody(p, "Store it somewhere safe and private, and don't combine it with other pieces until the owner asks you to, or can't.")
+	p.Ln(5)
+
+	addSection(p, "To get the secret back")
+	addBody(p, fmt.Sprintf("1. Gather at least %d pieces, from this page or the bundle's README.txt.", data.Threshold))
+	addBody(p, "2. Download ReMemory from "+data.GitHubReleaseURL)
+	addBody(p, "3. Put each piece in a text file and run:")
+	p.SetFont(fontMono, "", monoSize)
+	p.CellFormat(0, 5, "   rememory combine -o secret first.txt second.txt ...", "", 1, "L", false, 0, "")
+	addBody(p, "The file \"secret\" then holds what was split. These pieces can't be typed in as words or read by recover.html.")
+	p.Ln(5)
+
+	shareLines := strings.Split(core.EncodeShares(data.Shares), "\n")
+	ensureSpace(p, 10+3.5*float64(len(shareLines)))
+	addSection(p, "Your piece")
+	p.SetFont(fontMono, "", smallMono)
+	p.SetFillColor(245, 245, 245)
+	for _, line := range shareLines {
+		if line != "" {
+			p.CellFormat(0, 3.5, line, "", 1, "L", true, 0, "")
+		} else {
+			p.Ln(1.5)
+		}
+	}
+	p.Ln(5)
+
+	p.SetFont(fontSans, "B", smallMono)
+	p.CellFormat(0, 5, "METADATA", "", 1, "L", false, 0, "")
+	p.SetFont(fontMono, "", smallMono)
+	addMeta(p, "rememory-version", data.Version)
+	addMeta(p, "created", data.Created.Format(time.RFC3339))
+	addMeta(p, "threshold", fmt.Sprintf("%d", data.Threshold))
+	addMeta(p, "total", fmt.Sprintf("%d", data.Total))
+
+	var buf bytes.Buffer
+	if err := p.Output(&buf); err != nil {
+		return nil, fmt.Errorf("writing PDF: %w", err)
+	}
+	return buf.Bytes(), nil
+}
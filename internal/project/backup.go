@@ -0,0 +1,358 @@
+package project
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/eljojo/rememory/internal/core"
+)
+
+const (
+	// BackupsDir holds snapshots of the project taken before commands change
+	// it, one directory each, for 'rememory undo'.
+	BackupsDir = "backups"
+
+	// DefaultBackups is how many snapshots are kept when project.yml doesn't
+	// say.
+	DefaultBackups = 10
+
+	backupTimeFormat = "20060102T150405.000000000Z"
+
+	// outputListFile, in a snapshot, lists the files under output/ when it
+	// was taken, so undo can tell which ones the command made.
+	outputListFile = "output.txt"
+)
+
+// Backup is one snapshot of a project's state: project.yml, and when the
+// project was sealed, the files of sealedFiles, which a new seal replaces.
+type Backup struct {
+	Path    string    // Directory the snapshot is kept in
+	At      time.Time // When it was taken
+	Command string    // The command it was taken before, e.g. "seal"
+}
+
+// sealedFiles lists what a snapshot of a sealed project keeps from output/,
+// by path relative to it: the files a seal writes that bundles are made
+// from, and folders of them (dir), which Restore puts back whole. Snapshot,
+// Restore, and Artifacts all go by it, so undo never deletes a file it has
+// just put back.
+var sealedFiles = []struct {
+	name string
+	dir  bool
+}{
+	{"MANIFEST.age", false},
+	{core.CatalogFile, false},
+	{core.DecoyFile, false},
+	{core.TimelockFile, false},
+	{OwnerEscrowFile, false},
+	{core.RecipientsFile, false},
+	{MasterCodeFile, false},
+	{SharesDir, true},
+	{DuressDir, true},
+}
+
+// BackupsPath returns the path to the backups directory.
+func (p *Project) BackupsPath() string {
+	return filepath.Join(p.Path, BackupsDir)
+}
+
+// BackupsToKeep returns how many snapshots are kept, 0 when they're turned
+// off.
+func (p *Project) BackupsToKeep() int {
+	switch {
+	case p.Backups < 0:
+		return 0
+	case p.Backups == 0:
+		return DefaultBackups
+	}
+	return p.Backups
+}
+
+// Snapshot copies the project's state as it is on disk into a new backup,
+// before command changes it, and removes the oldest backups past
+// BackupsToKeep. It returns nil when backups are turned off.
+func (p *Project) Snapshot(command string) (*Backup, error) {
+	keep := p.BackupsToKeep()
+	if keep == 0 {
+		return nil, nil
+	}
+
+	b := Backup{At: time.Now().UTC(), Command: command}
+	b.Path = filepath.Join(p.BackupsPath(), b.At.Format(backupTimeFormat)+"-"+command)
+	if err := os.MkdirAll(b.Path, 0700); err != nil {
+		return nil, fmt.Errorf("creating backup: %w", err)
+	}
+	if err := copyFile(filepath.Join(p.Path, ProjectFileName), filepath.Join(b.Path, ProjectFileName)); err != nil {
+		return nil, fmt.Errorf("backing up %s: %w", ProjectFileName, err)
+	}
+	if err := writeOutputList(p.OutputPath(), filepath.Join(b.Path, outputListFile)); err != nil {
+		return nil, fmt.Errorf("backing up the list of output files: %w", err)
+	}
+	if p.Sealed != nil {
+		for _, f := range sealedFiles {
+			src, dst := filepath.Join(p.OutputPath(), f.name), filepath.Join(b.Path, OutputDir, f.name)
+			backUp := copyIfExists
+			if f.dir {
+				backUp = copyDir
+			}
+			if err := backUp(src, dst); err != nil {
+				return nil, fmt.Errorf("backing up %s: %w", f.name, err)
+			}
+		}
+	}
+
+	backups, err := p.ListBackups()
+	if err != nil {
+		return nil, err
+	}
+	for _, old := range backups[min(keep, len(backups)):] {
+		if err := os.RemoveAll(old.Path); err != nil {
+			return nil, fmt.Errorf("removing old backup: %w", err)
+		}
+	}
+	return &b, nil
+}
+
+// ListBackups returns the project's snapshots, newest first.
+func (p *Project) ListBackups() ([]Backup, error) {
+	entries, err := os.ReadDir(p.BackupsPath())
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading backups: %w", err)
+	}
+
+	var backups []Backup
+	for _, e := range entries {
+		stamp, command, ok := strings.Cut(e.Name(), "-")
+		if !e.IsDir() || !ok {
+			continue
+		}
+		at, err := time.Parse(backupTimeFormat, stamp)
+		if err != nil {
+			continue
+		}
+		backups = append(backups, Backup{Path: filepath.Join(p.BackupsPath(), e.Name()), At: at, Command: command})
+	}
+	sort.Slice(backups, func(i, j int) bool { return backups[i].At.After(backups[j].At) })
+	return backups, nil
+}
+
+// Restore puts a snapshot's files back in the project, replacing project.yml
+// and those of sealedFiles the snapshot has, then removes the snapshot.
+// Bundles aren't part of a snapshot; 'rememory bundle' makes them again
+// from the restored files.
+func (p *Project) Restore(b Backup) error {
+	if err := copyFile(filepath.Join(b.Path, ProjectFileName), filepath.Join(p.Path, ProjectFileName)); err != nil {
+		return fmt.Errorf("restoring %s: %w", ProjectFileName, err)
+	}
+	for _, f := range sealedFiles {
+		src, dst := filepath.Join(b.Path, OutputDir, f.name), filepath.Join(p.OutputPath(), f.name)
+		if !fileExists(src) {
+			continue
+		}
+		if f.dir {
+			if err := os.RemoveAll(dst); err != nil {
+				return fmt.Errorf("clearing %s: %w", f.name, err)
+			}
+			if err := copyDir(src, dst); err != nil {
+				return fmt.Errorf("restoring %s: %w", f.name, err)
+			}
+		} else if err := copyFile(src, dst); err != nil {
+			return fmt.Errorf("restoring %s: %w", f.name, err)
+		}
+	}
+	return os.RemoveAll(b.Path)
+}
+
+// restores reports whether Restore puts rel, a path under output/, back
+// from b.
+func restores(b Backup, rel string) bool {
+	for _, f := range sealedFiles {
+		if f.dir && filepath.Dir(rel) == f.name || !f.dir && rel == f.name {
+			return fileExists(filepath.Join(b.Path, OutputDir, f.name))
+		}
+	}
+	return false
+}
+
+// Artifacts returns the files under output/ that the command b was taken
+// before made or rewrote, such as bundles and printouts, leaving out those
+// Restore puts back. Paths are relative to the project, sorted. Snapshots
+// taken before the list of output files was kept return none.
+func (p *Project) Artifacts(b Backup) ([]string, error) {
+	before, err := readOutputList(filepath.Join(b.Path, outputListFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading the list of output files: %w", err)
+	}
+	now, err := listOutput(p.OutputPath())
+	if err != nil {
+		return nil, fmt.Errorf("listing output files: %w", err)
+	}
+	var made []string
+	for rel, stamp := range now {
+		if stamp == before[rel] || restores(b, rel) {
+			continue
+		}
+		made = append(made, filepath.Join(OutputDir, rel))
+	}
+	sort.Strings(made)
+	return made, nil
+}
+
+// RemoveArtifacts deletes files returned by Artifacts, then the directories
+// under output/ they leave empty.
+func (p *Project) RemoveArtifacts(paths []string) error {
+	for _, rel := range paths {
+		if err := os.Remove(filepath.Join(p.Path, rel)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("removing %s: %w", rel, err)
+		}
+		// Fails, and stops, at the first directory that isn't empty
+		for dir := filepath.Dir(rel); dir != OutputDir && dir != "."; dir = filepath.Dir(dir) {
+			if os.Remove(filepath.Join(p.Path, dir)) != nil {
+				break
+			}
+		}
+	}
+	return nil
+}
+
+// fileStamp tells whether a file changed: its size and modification time.
+type fileStamp struct {
+	size    int64
+	modTime int64 // Unix nanoseconds
+}
+
+// listOutput returns every file under dir, by path relative to it. A
+// missing dir has none.
+func listOutput(dir string) (map[string]fileStamp, error) {
+	files := make(map[string]fileStamp)
+	err := filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			if path == dir && errors.Is(err, os.ErrNotExist) {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		files[rel] = fileStamp{size: info.Size(), modTime: info.ModTime().UnixNano()}
+		return nil
+	})
+	return files, err
+}
+
+// writeOutputList writes the files under dir to path, one per line as size,
+// modification time, and path relative to dir.
+func writeOutputList(dir, path string) error {
+	files, err := listOutput(dir)
+	if err != nil {
+		return err
+	}
+	var sb strings.Builder
+	for rel, stamp := range files {
+		sb.WriteString(fmt.Sprintf("%d %d %s\n", stamp.size, stamp.modTime, filepath.ToSlash(rel)))
+	}
+	return os.WriteFile(path, []byte(sb.String()), 0600)
+}
+
+// readOutputList reads a list written by writeOutputList.
+func readOutputList(path string) (map[string]fileStamp, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	files := make(map[string]fileStamp)
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		fields := strings.SplitN(line, " ", 3)
+		if len(fields) != 3 {
+			continue
+		}
+		var stamp fileStamp
+		if _, err := fmt.Sscanf(fields[0]+" "+fields[1], "%d %d", &stamp.size, &stamp.modTime); err != nil {
+			continue
+		}
+		files[filepath.FromSlash(fields[2])] = stamp
+	}
+	return files, nil
+}
+
+// fileExists reports whether path exists.
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// copyIfExists copies src to dst, doing nothing when src doesn't exist.
+func copyIfExists(src, dst string) error {
+	if _, err := os.Stat(src); errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return copyFile(src, dst)
+}
+
+// copyDir copies the files directly in src to dst, doing nothing when src
+// doesn't exist.
+func copyDir(src, dst string) error {
+	entries, err := os.ReadDir(src)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		if err := copyFile(filepath.Join(src, e.Name()), filepath.Join(dst, e.Name())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// copyFile copies src to dst, keeping src's permissions and creating dst's
+// directory.
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	info, err := in.Stat()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0700); err != nil {
+		return err
+	}
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,101 @@
+package project
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"time"
+
+	"github.com/eljojo/rememory/internal/core"
+)
+
+// EventLogFileName is the project's log of what was done to it, one JSON
+// event per line. Lines are only ever added: undo restores project.yml but
+// not the log, and records itself in it.
+const EventLogFileName = "events.jsonl"
+
+// Event is one thing done to a project, such as a seal or a reminder sent.
+type Event struct {
+	At      time.Time `json:"at"`
+	Who     string    `json:"who,omitempty"`    // user@host that ran the command, when known
+	Command string    `json:"command"`          // e.g. "seal", "friend-add", "notify"
+	Detail  string    `json:"detail,omitempty"` // What it did, in a few words
+}
+
+// EventLogPath returns the path to the project's event log.
+func (p *Project) EventLogPath() string {
+	return filepath.Join(p.Path, EventLogFileName)
+}
+
+// LogEvent adds an event for command to the project's log.
+func (p *Project) LogEvent(command, detail string) error {
+	line, err := json.Marshal(Event{At: core.Now(), Who: whoami(), Command: command, Detail: detail})
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(p.EventLogPath(), os.O_RDWR|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return fmt.Errorf("opening event log: %w", err)
+	}
+	// Start on a line of its own after one cut short by a crash
+	if info, err := f.Stat(); err == nil && info.Size() > 0 {
+		last := make([]byte, 1)
+		if _, err := f.ReadAt(last, info.Size()-1); err == nil && last[0] != '\n' {
+			line = append([]byte{'\n'}, line...)
+		}
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return fmt.Errorf("writing event log: %w", err)
+	}
+	return f.Close()
+}
+
+// Events returns the project's logged events, oldest first. Lines that
+// can't be read, such as one cut short by a crash, are skipped.
+func (p *Project) Events() ([]Event, error) {
+	f, err := os.Open(p.EventLogPath())
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("opening event log: %w", err)
+	}
+	defer f.Close()
+
+	var events []Event
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var e Event
+		if json.Unmarshal(scanner.Bytes(), &e) == nil && e.Command != "" {
+			events = append(events, e)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading event log: %w", err)
+	}
+	return events, nil
+}
+
+// whoami names the user and machine running the command, as user@host.
+// Deterministic mode leaves it out, as the machine differs between runs.
+func whoami() string {
+	if core.Deterministic() {
+		return ""
+	}
+	name := ""
+	if u, err := user.Current(); err == nil {
+		name = u.Username
+	}
+	if host, err := os.Hostname(); err == nil && host != "" {
+		if name == "" {
+			return host
+		}
+		return name + "@" + host
+	}
+	return name
+}
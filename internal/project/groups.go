@@ -0,0 +1,134 @@
+package project
+
+import (
+	"fmt"
+
+	"github.com/eljojo/rememory/internal/core"
+)
+
+// Group is a set of friends who together hold one part of the passphrase,
+// for policies like "2 of the 3 family members and 1 of the 2 lawyers".
+// Friends join a group by naming it; the project's threshold then counts
+// the groups needed rather than friends.
+type Group struct {
+	Name      string `yaml:"name"`
+	Threshold int    `yaml:"threshold"` // Friends of this group who must come together
+}
+
+// Grouped reports whether the project splits the passphrase by groups.
+func (p *Project) Grouped() bool {
+	return len(p.Groups) > 0
+}
+
+// GroupIndex returns the 1-indexed group a friend belongs to, or 0 when the
+// project has no groups or the friend names an unknown one.
+func (p *Project) GroupIndex(f Friend) int {
+	for i, g := range p.Groups {
+		if g.Name == f.Group {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// GroupSpecs returns each group's size and threshold, in the order of
+// project.yml, for core.SplitGroups.
+func (p *Project) GroupSpecs() []core.GroupSpec {
+	specs := make([]core.GroupSpec, len(p.Groups))
+	for i, g := range p.Groups {
+		specs[i].Threshold = g.Threshold
+	}
+	for _, f := range p.Friends {
+		if g := p.GroupIndex(f); g > 0 {
+			specs[g-1].Size++
+		}
+	}
+	return specs
+}
+
+// GroupMembers returns the friends in a group, by 1-indexed group number.
+func (p *Project) GroupMembers(group int) []Friend {
+	var members []Friend
+	for _, f := range p.Friends {
+		if p.GroupIndex(f) == group {
+			members = append(members, f)
+		}
+	}
+	return members
+}
+
+// MinPieces is the fewest pieces that can recover the passphrase.
+func (p *Project) MinPieces() int {
+	if !p.Grouped() {
+		return p.Threshold
+	}
+	return core.MinGroupPieces(p.Threshold, p.GroupSpecs())
+}
+
+// GroupPolicy is a grouped project's recovery rule, as a friend's bundle
+// spells it out.
+type GroupPolicy struct {
+	Needed int         // Groups that must each bring their own threshold of pieces
+	Groups []GroupRule // Every group, in the order of project.yml
+}
+
+// GroupRule is one group of a GroupPolicy.
+type GroupRule struct {
+	Name      string
+	Size      int  // Friends in the group
+	Threshold int  // Friends of the group needed
+	Yours     bool // Whether the bundle's holder is in this group
+}
+
+// GroupPolicy returns the recovery rule for a friend's bundle, or nil when
+// the project has no groups.
+func (p *Project) GroupPolicy(f Friend) *GroupPolicy {
+	if !p.Grouped() {
+		return nil
+	}
+	specs := p.GroupSpecs()
+	policy := &GroupPolicy{Needed: p.Threshold, Groups: make([]GroupRule, len(p.Groups))}
+	for i, g := range p.Groups {
+		policy.Groups[i] = GroupRule{Name: g.Name, Size: specs[i].Size, Threshold: g.Threshold, Yours: g.Name == f.Group}
+	}
+	return policy
+}
+
+// validateGroups checks the groups and that every friend is in one.
+func (p *Project) validateGroups() error {
+	seen := make(map[string]bool)
+	for i, g := range p.Groups {
+		if g.Name == "" {
+			return fmt.Errorf("group %d: name is required", i+1)
+		}
+		if seen[g.Name] {
+			return fmt.Errorf("group %q is listed twice", g.Name)
+		}
+		seen[g.Name] = true
+	}
+	for _, f := range p.Friends {
+		if f.Group == "" {
+			return fmt.Errorf("friend %q: group is required when the project has groups", f.Name)
+		}
+		if !seen[f.Group] {
+			return fmt.Errorf("friend %q: unknown group %q", f.Name, f.Group)
+		}
+	}
+	if p.SLIP39 || p.SSKR || p.SSSS {
+		return fmt.Errorf("groups can't be combined with slip39, sskr, or ssss, which split the passphrase their own way")
+	}
+
+	specs := p.GroupSpecs()
+	for i, g := range p.Groups {
+		if specs[i].Size == 0 {
+			return fmt.Errorf("group %q has no friends", g.Name)
+		}
+		if g.Threshold < 1 || g.Threshold > specs[i].Size {
+			return fmt.Errorf("group %q: threshold must be between 1 and %d, got %d", g.Name, specs[i].Size, g.Threshold)
+		}
+	}
+	if p.Threshold < 1 || p.Threshold > len(p.Groups) {
+		return fmt.Errorf("threshold counts the groups needed, so it must be between 1 and %d, got %d", len(p.Groups), p.Threshold)
+	}
+	return core.ValidateGroupParams(p.Threshold, specs)
+}
@@ -0,0 +1,107 @@
+package project
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Types of medium a copy of a bundle can be on. How long a copy keeps
+// depends on it: flash loses its charge after a few years without power,
+// while a burned disc fades over a decade or more.
+const (
+	MediaUSB   = "usb"   // USB flash drive
+	MediaSD    = "sd"    // SD or microSD card
+	MediaSSD   = "ssd"   // Solid-state drive
+	MediaHDD   = "hdd"   // Spinning hard drive
+	MediaDisc  = "disc"  // Burned CD, DVD, or Blu-ray
+	MediaMDisc = "mdisc" // M-DISC, made to be archived
+)
+
+// MediaTypes lists the types of medium, in the order they're shown.
+var MediaTypes = []string{MediaUSB, MediaSD, MediaSSD, MediaHDD, MediaDisc, MediaMDisc}
+
+// DefaultMediaRefresh is how many years a copy is trusted on each type of
+// medium before it's due to be written again. A copy of unknown type gets
+// the shortest.
+var DefaultMediaRefresh = map[string]int{
+	MediaUSB:   3,
+	MediaSD:    3,
+	MediaSSD:   3,
+	MediaHDD:   5,
+	MediaDisc:  10,
+	MediaMDisc: 25,
+}
+
+// Medium is a USB drive, SD card, or disc holding a copy of a friend's
+// bundle, and what reading it back last found.
+type Medium struct {
+	Serial   string    `yaml:"serial"`             // The drive's serial number, or its volume's ID when it has none
+	Label    string    `yaml:"label,omitempty"`    // Volume label
+	Type     string    `yaml:"type,omitempty"`     // One of MediaTypes; empty when not known
+	Written  time.Time `yaml:"written,omitempty"`  // When the bundle was written to it
+	Verified time.Time `yaml:"verified"`           // When it was last read back
+	Manifest string    `yaml:"manifest,omitempty"` // Checksum of the MANIFEST.age sealed into the copy, to tell copies of an earlier seal
+	Failed   string    `yaml:"failed,omitempty"`   // Why the last read-back failed; empty when it matched
+}
+
+// CheckMediaType checks that kind is one of MediaTypes.
+func CheckMediaType(kind string) error {
+	if _, ok := DefaultMediaRefresh[kind]; !ok {
+		return fmt.Errorf("unknown type of medium %q (use %s)", kind, strings.Join(MediaTypes, ", "))
+	}
+	return nil
+}
+
+// Medium returns the friend's record of the medium named serial.
+func (f *Friend) Medium(serial string) (Medium, bool) {
+	for _, m := range f.Media {
+		if m.Serial == serial {
+			return m, true
+		}
+	}
+	return Medium{}, false
+}
+
+// RecordMedium records a read-back of a medium, replacing the earlier one
+// of the same medium. What m leaves out of its type and when it was
+// written is kept from the earlier one.
+func (f *Friend) RecordMedium(m Medium) {
+	for i := range f.Media {
+		if f.Media[i].Serial == m.Serial {
+			if m.Type == "" {
+				m.Type = f.Media[i].Type
+			}
+			if m.Written.IsZero() {
+				m.Written = f.Media[i].Written
+			}
+			f.Media[i] = m
+			return
+		}
+	}
+	f.Media = append(f.Media, m)
+}
+
+// RefreshYears returns how many years a copy on a medium of type kind is
+// trusted before it's due to be written again: media_refresh's, or
+// DefaultMediaRefresh's. A copy of unknown type is treated as flash.
+func (p *Project) RefreshYears(kind string) int {
+	if _, ok := DefaultMediaRefresh[kind]; !ok {
+		kind = MediaUSB
+	}
+	if years, ok := p.MediaRefresh[kind]; ok {
+		return years
+	}
+	return DefaultMediaRefresh[kind]
+}
+
+// RefreshDue returns when the copy on m is due to be written again,
+// counting from when it was written, or from when it was first read back
+// when that isn't known.
+func (p *Project) RefreshDue(m Medium) time.Time {
+	from := m.Written
+	if from.IsZero() {
+		from = m.Verified
+	}
+	return from.AddDate(p.RefreshYears(m.Type), 0, 0)
+}
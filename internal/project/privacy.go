@@ -0,0 +1,100 @@
+package project
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Privacy is how much each bundle tells its holder, and whoever reads it
+// during a recovery, about the people involved. It decides the name printed
+// on each piece and which other friends every bundle lists.
+type Privacy string
+
+const (
+	// PrivacyFull names every friend, with their contact details.
+	PrivacyFull Privacy = "full"
+	// PrivacyFirstNames lists the other friends by first name only, with no
+	// contact details, and signs each piece with its holder's first name.
+	PrivacyFirstNames Privacy = "first-names"
+	// PrivacyAnonymous names nobody: pieces are numbered, and bundles don't
+	// list the other friends.
+	PrivacyAnonymous Privacy = "anonymous"
+)
+
+// PrivacyLevels lists the privacy settings, from most to least revealing.
+var PrivacyLevels = []Privacy{PrivacyFull, PrivacyFirstNames, PrivacyAnonymous}
+
+// ParsePrivacy checks a privacy setting. Empty means PrivacyFull.
+func ParsePrivacy(s string) (Privacy, error) {
+	if s == "" {
+		return PrivacyFull, nil
+	}
+	for _, v := range PrivacyLevels {
+		if Privacy(s) == v {
+			return v, nil
+		}
+	}
+	names := make([]string, len(PrivacyLevels))
+	for i, v := range PrivacyLevels {
+		names[i] = string(v)
+	}
+	return "", fmt.Errorf("unknown privacy %q (supported: %s)", s, strings.Join(names, ", "))
+}
+
+// Holder is the name printed on a friend's own piece and bundle; index is
+// the piece's number.
+func (v Privacy) Holder(f Friend, index int) string {
+	switch v {
+	case PrivacyFirstNames:
+		return FirstName(f.Name)
+	case PrivacyAnonymous:
+		return fmt.Sprintf("Share %d", index)
+	}
+	return f.Name
+}
+
+// ListsOthers reports whether bundles list the other friends at all.
+func (v Privacy) ListsOthers() bool {
+	return v != PrivacyAnonymous
+}
+
+// Shown is what a bundle may say about another friend: their name and
+// contact details as the privacy level allows. Only call it when
+// ListsOthers is true.
+func (v Privacy) Shown(f Friend) Friend {
+	if v == PrivacyFirstNames {
+		return Friend{Name: FirstName(f.Name)}
+	}
+	return Friend{Name: f.Name, Contact: f.Contact}
+}
+
+// FirstName returns the first word of a name.
+func FirstName(name string) string {
+	if fields := strings.Fields(name); len(fields) > 0 {
+		return fields[0]
+	}
+	return name
+}
+
+// PrivacyLevel is the project's privacy setting. Projects written before
+// the privacy setting existed say "anonymous: true" instead.
+func (p *Project) PrivacyLevel() Privacy {
+	if p.Privacy != "" {
+		return p.Privacy
+	}
+	if p.Anonymous {
+		return PrivacyAnonymous
+	}
+	return PrivacyFull
+}
+
+// SetPrivacy changes the project's privacy setting. Anonymous projects keep
+// "anonymous: true" in project.yml so earlier releases still read them as
+// anonymous.
+func (p *Project) SetPrivacy(v Privacy) {
+	p.Anonymous = v == PrivacyAnonymous
+	p.Privacy = ""
+	if v == PrivacyFirstNames {
+		p.Privacy = v
+	}
+}
@@ -2,10 +2,15 @@ package project
 
 import (
 	"fmt"
+	"net/url"
 	"os"
 	"path/filepath"
 	"time"
 
+	"github.com/eljojo/rememory/internal/core"
+	"github.com/eljojo/rememory/internal/crypto"
+	"github.com/eljojo/rememory/internal/manifest"
+	"github.com/eljojo/rememory/internal/rotation"
 	"gopkg.in/yaml.v3"
 )
 
@@ -14,13 +19,45 @@ const (
 	ManifestDir     = "manifest"
 	OutputDir       = "output"
 	SharesDir       = "shares"
+	DuressDir       = "duress"
+	SplitDir        = "split"
 )
 
 // Friend represents a person who will hold a share.
 type Friend struct {
-	Name     string `yaml:"name"`
-	Contact  string `yaml:"contact,omitempty"`
-	Language string `yaml:"language,omitempty"` // Bundle language override (e.g. "en", "es", "de", "fr", "sl", "pt", "zh-TW")
+	Name      string `yaml:"name"`
+	Contact   string `yaml:"contact,omitempty"`
+	Language  string `yaml:"language,omitempty"`  // Bundle language override (e.g. "en", "es", "de", "fr", "sl", "pt", "zh-TW")
+	Words     string `yaml:"words,omitempty"`     // Recovery word list: a language, "eff-long", or "eff-short"; defaults to the bundle language
+	Location  string `yaml:"location,omitempty"`  // Where they keep their bundle (e.g. a city), for 'rememory analyze'
+	Household string `yaml:"household,omitempty"` // Same tag for friends who live together, for 'rememory analyze'
+	Group     string `yaml:"group,omitempty"`     // Name of the group the friend belongs to, when the project has groups
+	Shares    int    `yaml:"shares,omitempty"`    // Pieces the friend holds, for more weight in recovery; 0 means 1
+	PIN       bool   `yaml:"pin,omitempty"`       // Lock the friend's pieces with a PIN, told to them in person at seal time
+
+	// CheckIns records reminders sent with 'rememory notify' and the answers.
+	CheckIns []CheckIn `yaml:"check_ins,omitempty"`
+
+	// Media records the physical copies of the friend's bundle that were
+	// read back with 'rememory verify-media' or 'rememory usb prepare'.
+	Media []Medium `yaml:"media,omitempty"`
+}
+
+// CheckIn is one reminder asking a friend whether they still have their
+// bundle, and what they said. Answers recorded without a reminder have no
+// Sent time.
+type CheckIn struct {
+	Sent     time.Time `yaml:"sent,omitempty"`
+	Answered time.Time `yaml:"answered,omitempty"`
+	Response string    `yaml:"response,omitempty"` // In the owner's words, e.g. "still has it"
+}
+
+// LastCheckIn returns the friend's most recent check-in, or nil.
+func (f *Friend) LastCheckIn() *CheckIn {
+	if len(f.CheckIns) == 0 {
+		return nil
+	}
+	return &f.CheckIns[len(f.CheckIns)-1]
 }
 
 // ShareInfo stores information about a generated share.
@@ -35,18 +72,156 @@ type Sealed struct {
 	At               time.Time   `yaml:"at"`
 	ManifestChecksum string      `yaml:"manifest_checksum"`
 	VerificationHash string      `yaml:"verification_hash"`
+	Crypto           string      `yaml:"crypto,omitempty"`            // Crypto profile the seal was made under
+	RecoveryURL      string      `yaml:"recovery_url,omitempty"`      // Where the QR codes point, when not the default
+	BundleID         string      `yaml:"bundle_id,omitempty"`         // Recorded in every piece; empty for seals from before it was
+	WorkFactor       int         `yaml:"work_factor,omitempty"`       // scrypt log2(N) chosen for recovery_time or max_memory; 0 for age's default
+	VSS              []string    `yaml:"vss,omitempty"`               // Feldman commitments to the pieces' verifiable split (see core.SplitVSS); empty without one
+	PostQuantum      bool        `yaml:"post_quantum,omitempty"`      // MANIFEST.age was sealed in the post-quantum hybrid mode
+	TimelockChecksum string      `yaml:"timelock_checksum,omitempty"` // TIMELOCK.json, which MANIFEST.age can't open without; empty without a recovery delay
 	Shares           []ShareInfo `yaml:"shares"`
+
+	// LastIndex is the highest piece index the seal has handed out, counting
+	// friends removed since; 'rememory friend add' numbers pieces after it.
+	// Zero on seals from before it was recorded.
+	LastIndex int `yaml:"last_index,omitempty"`
+
+	// Updated is when 'rememory seal --update' last sealed manifest/ again
+	// under the same passphrase and pieces; zero if it never has.
+	Updated time.Time `yaml:"updated,omitempty"`
+
+	// Files lists what was in manifest/ when it was sealed, for 'rememory diff'.
+	// Projects sealed before this was recorded have none.
+	Files []manifest.File `yaml:"files,omitempty"`
+
+	// Rotation is the signed SUPERSEDED.txt for the bundles, when the project
+	// was sealed before. It is kept here because signing needs the passphrase.
+	Rotation *rotation.Note `yaml:"rotation,omitempty"`
+}
+
+// HTMLCustomization adds project-specific content to recover.html and maker.html.
+// File paths are relative to the project directory.
+type HTMLCustomization struct {
+	Banner string `yaml:"banner,omitempty"` // Plain text shown above the page content
+	Header string `yaml:"header,omitempty"` // HTML file inserted at the top of the page
+	Footer string `yaml:"footer,omitempty"` // HTML file appended to the page footer
+	CSS    string `yaml:"css,omitempty"`    // CSS file applied after the built-in styles
+}
+
+// RecoveryTime asks seal to tune scrypt, which guards MANIFEST.age, so that
+// unlocking it fits a time budget on the slowest device a friend is likely
+// to recover on.
+type RecoveryTime struct {
+	Budget string `yaml:"budget"`           // e.g. "30s" or "2m"
+	Device string `yaml:"device,omitempty"` // old-phone (default), phone, or laptop
+}
+
+// Catalog lets fewer pieces than the threshold open a list of what the seal
+// holds (file names, sizes, and a description), so an executor can tell
+// whether a full recovery is warranted. It's written to CATALOG.age and
+// embedded in recover.html.
+type Catalog struct {
+	Threshold   int    `yaml:"threshold"`             // Pieces needed to open the catalog; fewer than the project's threshold
+	Description string `yaml:"description,omitempty"` // Shown with the list, e.g. what the files are for
+}
+
+// Decoy seals a second, harmless set of files that the duress pieces open
+// instead of the real ones. Each friend gets a duress piece apart from their
+// bundle, to hand over if someone forces them to; recovering with duress
+// pieces looks like any other recovery.
+type Decoy struct {
+	Manifest string `yaml:"manifest,omitempty"` // Folder of harmless files, relative to the project; DefaultDecoyDir when empty
+}
+
+// MaxRecoveryDelay is the longest recovery_delay: the wait is measured on
+// the sealing computer, and a slower one can take several times as long.
+const MaxRecoveryDelay = 30 * 24 * time.Hour
+
+// DefaultDecoyDir is the decoy's folder when project.yml doesn't name one.
+const DefaultDecoyDir = "decoy"
+
+// NotifyConfig configures the reminders sent by 'rememory notify'.
+type NotifyConfig struct {
+	SMTP     *SMTPConfig `yaml:"smtp,omitempty"`
+	Subject  string      `yaml:"subject,omitempty"`  // Overrides the built-in subject line
+	Template string      `yaml:"template,omitempty"` // Optional message template, relative to the project directory
+}
+
+// SMTPConfig is the mail server reminders are sent through. The password is
+// never stored; it comes from REMEMORY_SMTP_PASSWORD or a prompt.
+type SMTPConfig struct {
+	Host     string `yaml:"host"`
+	Port     int    `yaml:"port,omitempty"` // Default 587 (STARTTLS); 465 uses TLS from the start
+	Username string `yaml:"username,omitempty"`
+	From     string `yaml:"from"` // e.g. "Ana <ana@example.com>"
 }
 
+// BeaconConfig is where the project's beacon is published: a small signed
+// file, with nothing secret in it, that says which seal is current, so a
+// holder who is online can tell whether their bundle is out of date. The
+// signing key is kept in BeaconKeyFile, next to project.yml.
+type BeaconConfig struct {
+	URL       string `yaml:"url"`              // Where holders fetch beacon.txt from, printed in every bundle
+	Upload    string `yaml:"upload,omitempty"` // Where 'rememory beacon publish' PUTs it, when that isn't URL
+	PublicKey string `yaml:"public_key"`       // Base64 Ed25519 key the beacon is signed with, printed in every bundle
+}
+
+// BeaconKeyFile holds the beacon's signing key. It stays the same from one
+// seal to the next, so bundles of earlier seals can check newer beacons.
+const BeaconKeyFile = "beacon.key"
+
+// MasterCodeFile is the printable sheet of a new master code. The code
+// isn't kept anywhere else, so it is meant to be printed and then deleted.
+const MasterCodeFile = "MASTER-CODE.pdf"
+
+// OwnerEscrowFile holds the passphrase encrypted with the owner's password,
+// written by 'rememory seal --owner-escrow'.
+const OwnerEscrowFile = "OWNER.age"
+
 // Project represents a rememory project configuration.
 type Project struct {
-	Name      string   `yaml:"name"`
-	Created   string   `yaml:"created"`
-	Threshold int      `yaml:"threshold"`
-	Anonymous bool     `yaml:"anonymous,omitempty"`
-	Language  string   `yaml:"language,omitempty"` // Default bundle language (e.g. "en", "es", "de", "fr", "sl", "pt", "zh-TW")
-	Friends   []Friend `yaml:"friends"`
-	Sealed    *Sealed  `yaml:"sealed,omitempty"`
+	Name           string             `yaml:"name"`
+	Created        string             `yaml:"created"`
+	Threshold      int                `yaml:"threshold"`           // Friends needed; with groups, the groups needed
+	Anonymous      bool               `yaml:"anonymous,omitempty"` // Same as privacy: anonymous; kept for earlier releases
+	Privacy        Privacy            `yaml:"privacy,omitempty"`   // What bundles tell about the friends: full (default), first-names, or anonymous
+	Language       string             `yaml:"language,omitempty"`  // Default bundle language (e.g. "en", "es", "de", "fr", "sl", "pt", "zh-TW")
+	Groups         []Group            `yaml:"groups,omitempty"`    // Split the passphrase by group; see Group
+	Friends        []Friend           `yaml:"friends"`
+	PDFLayout      string             `yaml:"pdf_layout,omitempty"`      // Optional layout file for README.pdf, relative to the project directory
+	ReadmeTemplate string             `yaml:"readme_template,omitempty"` // Optional template for README.txt, relative to the project directory
+	HTML           *HTMLCustomization `yaml:"html,omitempty"`
+	Crypto         string             `yaml:"crypto,omitempty"`    // Crypto profile: empty for the standard set, or "restricted"
+	SLIP39         bool               `yaml:"slip39,omitempty"`    // Also give each friend SLIP-0039 words, for recovery with other tools
+	SSKR           bool               `yaml:"sskr,omitempty"`      // Also give each friend an SSKR share, for Blockchain Commons tools
+	SSSS           bool               `yaml:"ssss,omitempty"`      // Also give each friend a share for the classic ssss-combine tool
+	Quiz           bool               `yaml:"quiz,omitempty"`      // Also give each friend QUIZ.txt, a few questions on keeping and using their piece
+	Audio          bool               `yaml:"audio,omitempty"`     // Also give each friend PIECE.wav, their piece's digit groups read aloud
+	ReviewBy       string             `yaml:"review_by,omitempty"` // Date (YYYY-MM-DD) after which recovery tools suggest looking for newer bundles
+	Expires        string             `yaml:"expires,omitempty"`   // Date (YYYY-MM-DD) after which recovery tools refuse the bundles unless told otherwise
+	Notify         *NotifyConfig      `yaml:"notify,omitempty"`
+	Beacon         *BeaconConfig      `yaml:"beacon,omitempty"`
+	Contact        string             `yaml:"contact,omitempty"`      // How friends reach the owner for the current bundle, printed in SUPERSEDED.txt
+	Recipients     []string           `yaml:"recipients,omitempty"`   // age public keys (age1..., or a plugin's, like age1yubikey1...) that can also unlock the passphrase, through RECIPIENTS.age
+	MasterCode     string             `yaml:"master_code,omitempty"`  // age public key of the owner's master code, printed once by 'rememory seal --master-code', which also unlocks the passphrase through RECIPIENTS.age
+	PostQuantum    bool               `yaml:"post_quantum,omitempty"` // Seal in the post-quantum hybrid mode (see core.EncryptWriterPQ); recipients must then be age1pq1... keys
+	Backups        int                `yaml:"backups,omitempty"`      // Snapshots kept for 'rememory undo': 0 means DefaultBackups, negative turns them off
+	RecoveryTime   *RecoveryTime      `yaml:"recovery_time,omitempty"`
+	MaxMemory      string             `yaml:"max_memory,omitempty"`     // Most memory unlocking MANIFEST.age may need, e.g. 128M, for heirs on old phones or small laptops; scrypt's default takes 256M
+	RecoveryDelay  string             `yaml:"recovery_delay,omitempty"` // How long recovery waits once enough pieces come together, e.g. 72h (on a computer like the one that seals)
+	VolumeSize     string             `yaml:"volume_size,omitempty"`    // Also split MANIFEST.age into volumes of at most this size, e.g. 4G for FAT32 drives or 4.7G for DVDs
+	Parity         int                `yaml:"parity,omitempty"`         // Percent of Reed-Solomon parity written next to MANIFEST.age, to repair bit rot
+	Exclude        []string           `yaml:"exclude,omitempty"`        // Patterns of files in manifest/ not to seal, in .gitignore's syntax, on top of manifest/.rememoryignore
+	Symlinks       string             `yaml:"symlinks,omitempty"`       // What sealing does with symlinks in manifest/: skip (the default), keep, or follow
+	Xattrs         bool               `yaml:"xattrs,omitempty"`         // Also seal the extended attributes of files and folders in manifest/
+	MediaRefresh   map[string]int     `yaml:"media_refresh,omitempty"`  // Years before a copy on each type of medium is due to be written again, over DefaultMediaRefresh
+	Catalog        *Catalog           `yaml:"catalog,omitempty"`
+	Decoy          *Decoy             `yaml:"decoy,omitempty"`
+	Sealed         *Sealed            `yaml:"sealed,omitempty"`
+
+	// Superseded lists earlier seals, whose bundles the current ones replace.
+	// 'rememory seal' adds the previous seal each time it seals again.
+	Superseded []rotation.Generation `yaml:"superseded,omitempty"`
 
 	// Path is the directory containing this project (not serialized)
 	Path string `yaml:"-"`
@@ -69,11 +244,20 @@ func Load(dir string) (*Project, error) {
 	return &p, nil
 }
 
+// Encode returns the project configuration as YAML.
+func (p *Project) Encode() ([]byte, error) {
+	data, err := yaml.Marshal(p)
+	if err != nil {
+		return nil, fmt.Errorf("encoding project: %w", err)
+	}
+	return data, nil
+}
+
 // Save writes the project configuration to disk.
 func (p *Project) Save() error {
-	data, err := yaml.Marshal(p)
+	data, err := p.Encode()
 	if err != nil {
-		return fmt.Errorf("encoding project: %w", err)
+		return err
 	}
 
 	path := filepath.Join(p.Path, ProjectFileName)
@@ -89,30 +273,283 @@ func (p *Project) Validate() error {
 	if p.Name == "" {
 		return fmt.Errorf("project name is required")
 	}
-	if len(p.Friends) < 2 {
-		return fmt.Errorf("need at least 2 friends, got %d", len(p.Friends))
+	// Grouped projects check their threshold with the groups
+	if !p.Grouped() {
+		if err := core.ValidateThreshold(p.Threshold, p.TotalPieces()); err != nil {
+			return err
+		}
 	}
-	if p.Threshold < 2 {
-		return fmt.Errorf("threshold must be at least 2, got %d", p.Threshold)
+	if err := p.validateWeights(); err != nil {
+		return err
 	}
-	if p.Threshold > len(p.Friends) {
-		return fmt.Errorf("threshold (%d) cannot exceed number of friends (%d)", p.Threshold, len(p.Friends))
+	holders := make([]core.Holder, len(p.Friends))
+	for i, f := range p.Friends {
+		holders[i] = core.Holder{Name: f.Name, Contact: f.Contact}
+	}
+	if err := core.ValidateHolders(holders); err != nil {
+		return err
 	}
 
-	for i, f := range p.Friends {
-		if f.Name == "" {
-			return fmt.Errorf("friend %d: name is required", i+1)
+	for _, f := range p.Friends {
+		if f.Words != "" && !core.IsWordList(core.Lang(f.Words)) {
+			return fmt.Errorf("friend %q: no %q word list (available: %s)", f.Name, f.Words, core.WordListLangs())
+		}
+		if f.Group != "" && !p.Grouped() {
+			return fmt.Errorf("friend %q: group %q, but the project has no groups", f.Name, f.Group)
+		}
+		// The other forms would hand over the piece without its lock
+		if f.PIN && (p.SLIP39 || p.SSKR || p.SSSS) {
+			return fmt.Errorf("friend %q: pin can't be combined with slip39, sskr, or ssss, which write the piece unlocked", f.Name)
+		}
+		if f.PIN && p.Audio {
+			return fmt.Errorf("friend %q: pin can't be combined with audio, whose digit groups have no room for the lock", f.Name)
+		}
+	}
+	if p.Grouped() {
+		if err := p.validateGroups(); err != nil {
+			return err
+		}
+		if p.Audio {
+			return fmt.Errorf("audio can't be combined with groups: grouped pieces have no digit groups to read")
+		}
+	}
+
+	if _, err := ParsePrivacy(string(p.Privacy)); err != nil {
+		return err
+	}
+	if p.Anonymous && p.Privacy != "" && p.Privacy != PrivacyAnonymous {
+		return fmt.Errorf("anonymous: true contradicts privacy: %s; keep one of them", p.Privacy)
+	}
+
+	if p.SLIP39 && len(p.Friends) > core.SLIP39MaxShares {
+		return fmt.Errorf("slip39 allows at most %d friends, got %d", core.SLIP39MaxShares, len(p.Friends))
+	}
+	if p.SSKR && len(p.Friends) > core.SSKRMaxShares {
+		return fmt.Errorf("sskr allows at most %d friends, got %d", core.SSKRMaxShares, len(p.Friends))
+	}
+
+	if err := core.ValidCryptoProfile(p.Crypto); err != nil {
+		return err
+	}
+	if _, err := crypto.ParseRecipients(p.Recipients); err != nil {
+		return err
+	}
+	if len(p.Recipients) > 0 && p.Crypto == core.CryptoRestricted {
+		return fmt.Errorf("recipients are outside the restricted crypto profile: they unlock the passphrase without the pieces")
+	}
+	if p.MasterCode != "" {
+		if _, err := core.ParseRecipients([]string{p.MasterCode}); err != nil {
+			return fmt.Errorf("master_code: %w", err)
+		}
+		if p.Crypto == core.CryptoRestricted {
+			return fmt.Errorf("master_code is outside the restricted crypto profile: it unlocks the passphrase without the pieces")
+		}
+		// age won't mix post-quantum keys with others in one file
+		if p.PostQuantum && !core.IsHybridRecipient(p.MasterCode) {
+			return fmt.Errorf("master_code isn't post-quantum; seal with --new-master-code to print one that is")
+		}
+		if !p.PostQuantum && core.IsHybridRecipient(p.MasterCode) {
+			return fmt.Errorf("master_code is post-quantum but post_quantum is off; seal with --new-master-code to print one that fits")
+		}
+	}
+	if p.PostQuantum {
+		if p.Crypto == core.CryptoRestricted {
+			return fmt.Errorf("post_quantum is outside the restricted crypto profile, which allows only age's scrypt recipient")
+		}
+		for _, key := range p.Recipients {
+			if !core.IsHybridRecipient(key) {
+				return fmt.Errorf("recipient %q isn't post-quantum: with post_quantum, recipients must be hybrid keys (age1pq1..., from 'age-keygen -pq')", key)
+			}
+		}
+	}
+
+	if _, _, err := p.RecoveryBudget(); err != nil {
+		return err
+	}
+	if max, err := p.MaxWorkFactor(); err != nil {
+		return err
+	} else if max > 0 && max < core.RestrictedMinWorkFactor && p.Crypto == core.CryptoRestricted {
+		return fmt.Errorf("max_memory %s is below the %dM of scrypt the restricted crypto profile requires", p.MaxMemory, core.ScryptMemory(core.RestrictedMinWorkFactor)>>20)
+	}
+	if delay, err := p.Delay(); err != nil {
+		return err
+	} else if delay > 0 && p.Crypto == core.CryptoRestricted {
+		return fmt.Errorf("recovery_delay is outside the restricted crypto profile: it adds a time-lock puzzle before MANIFEST.age opens")
+	}
+	if _, err := p.VolumeBytes(); err != nil {
+		return fmt.Errorf("volume_size: %w", err)
+	}
+	if p.Parity < 0 || p.Parity > 100 {
+		return fmt.Errorf("parity must be between 1 and 100 percent, not %d", p.Parity)
+	}
+	if _, err := manifest.ParseSymlinks(p.Symlinks); err != nil {
+		return err
+	}
+	if b := p.Beacon; b != nil {
+		if b.URL == "" || b.PublicKey == "" {
+			return fmt.Errorf("beacon needs a url and a public_key (set it up with 'rememory beacon init')")
+		}
+		if err := checkBeaconURL(b.URL); err != nil {
+			return fmt.Errorf("beacon url: %w", err)
+		}
+		if b.Upload != "" {
+			if err := checkBeaconURL(b.Upload); err != nil {
+				return fmt.Errorf("beacon upload: %w", err)
+			}
+		}
+	}
+	for kind, years := range p.MediaRefresh {
+		if err := CheckMediaType(kind); err != nil {
+			return fmt.Errorf("media_refresh: %w", err)
+		}
+		if years < 1 || years > 100 {
+			return fmt.Errorf("media_refresh: %s must be between 1 and 100 years, not %d", kind, years)
+		}
+	}
+	if c := p.Catalog; c != nil {
+		switch {
+		case p.Grouped():
+			return fmt.Errorf("catalog can't be used with groups")
+		case c.Threshold < 2 || c.Threshold >= p.Threshold:
+			return fmt.Errorf("catalog threshold must be at least 2 and less than the threshold (%d), got %d", p.Threshold, c.Threshold)
 		}
 	}
+	// Real pieces would carry a catalog line that duress pieces can't
+	if p.Decoy != nil && p.Catalog != nil {
+		return fmt.Errorf("decoy can't be used with catalog: the catalog would list the real files to anyone holding duress pieces")
+	}
+
+	reviewBy, expires, err := p.Sunset()
+	if err != nil {
+		return err
+	}
+	if !reviewBy.IsZero() && !expires.IsZero() && expires.Before(reviewBy) {
+		return fmt.Errorf("expires (%s) is before review_by (%s)", p.Expires, p.ReviewBy)
+	}
 
 	return nil
 }
 
+// Sunset returns the review and expiry dates recorded in each piece; either
+// is zero when unset.
+func (p *Project) Sunset() (reviewBy, expires time.Time, err error) {
+	if p.ReviewBy != "" {
+		if reviewBy, err = time.Parse(core.DateFormat, p.ReviewBy); err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("review_by must be a date like 2030-01-31, got %q", p.ReviewBy)
+		}
+	}
+	if p.Expires != "" {
+		if expires, err = time.Parse(core.DateFormat, p.Expires); err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("expires must be a date like 2030-01-31, got %q", p.Expires)
+		}
+	}
+	return reviewBy, expires, nil
+}
+
+// RecoveryBudget returns the recovery_time budget and device; a zero budget
+// means none is set and age's default work factor is used.
+func (p *Project) RecoveryBudget() (time.Duration, core.DeviceClass, error) {
+	if p.RecoveryTime == nil {
+		return 0, "", nil
+	}
+	budget, err := time.ParseDuration(p.RecoveryTime.Budget)
+	if err != nil || budget < time.Second {
+		return 0, "", fmt.Errorf("recovery_time budget must be a duration of a second or more, like 30s or 2m, got %q", p.RecoveryTime.Budget)
+	}
+	device := core.DeviceClass(p.RecoveryTime.Device)
+	if device == "" {
+		device = core.DeviceOldPhone
+	}
+	if err := core.ValidDeviceClass(device); err != nil {
+		return 0, "", fmt.Errorf("recovery_time: %w", err)
+	}
+	return budget, device, nil
+}
+
+// MaxWorkFactor returns the highest scrypt work factor max_memory allows,
+// or 0 when it isn't set.
+func (p *Project) MaxWorkFactor() (int, error) {
+	if p.MaxMemory == "" {
+		return 0, nil
+	}
+	max, err := core.ParseMaxMemory(p.MaxMemory)
+	if err != nil {
+		return 0, fmt.Errorf("max_memory: %w", err)
+	}
+	return max, nil
+}
+
+// WordList returns the word list a friend's recovery words are printed
+// from: their own words setting, or else their bundle language.
+func (p *Project) WordList(f Friend) core.Lang {
+	for _, lang := range []string{f.Words, f.Language, p.Language} {
+		if lang != "" {
+			if !core.IsWordList(core.Lang(lang)) {
+				break
+			}
+			return core.Lang(lang)
+		}
+	}
+	return core.LangEN
+}
+
+// BeaconKeyPath returns the path to the beacon's signing key.
+func (p *Project) BeaconKeyPath() string {
+	return filepath.Join(p.Path, BeaconKeyFile)
+}
+
+// checkBeaconURL checks that the beacon is fetched over HTTPS, or plain HTTP
+// on this computer, where there's nothing in between to tamper with it.
+func checkBeaconURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return fmt.Errorf("%q is not a URL", raw)
+	}
+	switch host := u.Hostname(); {
+	case u.Scheme == "https":
+	case u.Scheme == "http" && (host == "localhost" || host == "127.0.0.1" || host == "::1"):
+	default:
+		return fmt.Errorf("%q must start with https://", raw)
+	}
+	return nil
+}
+
+// RecipientKeys returns the age public keys RECIPIENTS.age is encrypted to:
+// the recipients, then the master code's.
+func (p *Project) RecipientKeys() []string {
+	if p.MasterCode == "" {
+		return p.Recipients
+	}
+	return append(append([]string{}, p.Recipients...), p.MasterCode)
+}
+
+// MasterCodePath returns the path to the master code sheet, written when
+// sealing makes a new master code.
+func (p *Project) MasterCodePath() string {
+	return filepath.Join(p.Path, OutputDir, MasterCodeFile)
+}
+
 // ManifestPath returns the path to the manifest directory.
 func (p *Project) ManifestPath() string {
 	return filepath.Join(p.Path, ManifestDir)
 }
 
+// ManifestIgnore returns what is left out when manifest/ is sealed: what
+// its .rememoryignore and the exclude patterns match, the symlinks, unless
+// the symlinks setting keeps or follows them, and extended attributes,
+// unless xattrs is set.
+func (p *Project) ManifestIgnore() (*manifest.Ignore, error) {
+	ig, err := manifest.LoadIgnore(p.ManifestPath(), p.Exclude)
+	if err != nil {
+		return nil, err
+	}
+	if ig.Symlinks, err = manifest.ParseSymlinks(p.Symlinks); err != nil {
+		return nil, err
+	}
+	ig.Xattrs = p.Xattrs
+	return ig, nil
+}
+
 // OutputPath returns the path to the output directory.
 func (p *Project) OutputPath() string {
 	return filepath.Join(p.Path, OutputDir)
@@ -123,15 +560,177 @@ func (p *Project) SharesPath() string {
 	return filepath.Join(p.Path, OutputDir, SharesDir)
 }
 
+// SharePath returns the path to a friend's share file. It is named after
+// the friend, whatever name the privacy setting prints on the piece.
+func (p *Project) SharePath(f Friend) string {
+	return filepath.Join(p.SharesPath(), fmt.Sprintf("SHARE-%s.txt", core.SanitizeFilename(f.Name)))
+}
+
+// SLIP39Path returns the path to a friend's SLIP-0039 words, written when
+// the project sets slip39.
+func (p *Project) SLIP39Path(f Friend) string {
+	return filepath.Join(p.SharesPath(), fmt.Sprintf("SLIP39-%s.txt", core.SanitizeFilename(f.Name)))
+}
+
+// SSKRPath returns the path to a friend's SSKR share, as a UR string,
+// written when the project sets sskr.
+func (p *Project) SSKRPath(f Friend) string {
+	return filepath.Join(p.SharesPath(), fmt.Sprintf("SSKR-%s.txt", core.SanitizeFilename(f.Name)))
+}
+
+// SSSSPath returns the path to a friend's ssss share line, written when the
+// project sets ssss.
+func (p *Project) SSSSPath(f Friend) string {
+	return filepath.Join(p.SharesPath(), fmt.Sprintf("SSSS-%s.txt", core.SanitizeFilename(f.Name)))
+}
+
 // ManifestAgePath returns the path to the encrypted manifest.
 func (p *Project) ManifestAgePath() string {
 	return filepath.Join(p.Path, OutputDir, "MANIFEST.age")
 }
 
+// CatalogPath returns the path to the encrypted catalog, written when the
+// project has a catalog.
+func (p *Project) CatalogPath() string {
+	return filepath.Join(p.Path, OutputDir, core.CatalogFile)
+}
+
+// ReadCatalog returns the sealed CATALOG.age, or nil when the seal has none.
+func (p *Project) ReadCatalog() ([]byte, error) {
+	data, err := os.ReadFile(p.CatalogPath())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", core.CatalogFile, err)
+	}
+	return data, nil
+}
+
+// Delay returns the recovery_delay; zero when none is set.
+func (p *Project) Delay() (time.Duration, error) {
+	if p.RecoveryDelay == "" {
+		return 0, nil
+	}
+	delay, err := time.ParseDuration(p.RecoveryDelay)
+	if err != nil || delay < time.Second || delay > MaxRecoveryDelay {
+		return 0, fmt.Errorf("recovery_delay must be a duration from a second to %s, like 24h or 72h, got %q", MaxRecoveryDelay, p.RecoveryDelay)
+	}
+	return delay, nil
+}
+
+// VolumeBytes returns the volume_size in bytes; zero when MANIFEST.age
+// isn't split.
+func (p *Project) VolumeBytes() (int64, error) {
+	if p.VolumeSize == "" {
+		return 0, nil
+	}
+	return core.ParseVolumeSize(p.VolumeSize)
+}
+
+// ManifestVolumeIndexPath returns the path to MANIFEST.age.sha256, which
+// lists the volumes MANIFEST.age was split into, next to it in output/.
+func (p *Project) ManifestVolumeIndexPath() string {
+	return p.ManifestAgePath() + core.VolumeIndexSuffix
+}
+
+// ManifestParityPath returns the path to MANIFEST.age.parity, next to
+// MANIFEST.age in output/.
+func (p *Project) ManifestParityPath() string {
+	return p.ManifestAgePath() + core.ParitySuffix
+}
+
+// ManifestVolumes returns the index of the volumes MANIFEST.age was last
+// split into, whose files are in output/; nil when it wasn't split.
+func (p *Project) ManifestVolumes() (*core.VolumeIndex, error) {
+	data, err := os.ReadFile(p.ManifestVolumeIndexPath())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading volume index: %w", err)
+	}
+	return core.ParseVolumeIndex(data)
+}
+
+// TimelockPath returns the path to the time-lock puzzle, written when the
+// project has a recovery delay.
+func (p *Project) TimelockPath() string {
+	return filepath.Join(p.Path, OutputDir, core.TimelockFile)
+}
+
+// ReadTimelock returns the sealed TIMELOCK.json, or nil when the seal has none.
+// It fails when the seal made one that is gone: without it nothing opens
+// MANIFEST.age, and bundles made without it would be useless.
+func (p *Project) ReadTimelock() ([]byte, error) {
+	data, err := os.ReadFile(p.TimelockPath())
+	if os.IsNotExist(err) {
+		if p.Sealed != nil && p.Sealed.TimelockChecksum != "" {
+			return nil, fmt.Errorf("%s is missing, and MANIFEST.age can't be opened without it; restore it from a backup or run 'rememory seal' again", core.TimelockFile)
+		}
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", core.TimelockFile, err)
+	}
+	return data, nil
+}
+
+// DecoyManifestPath returns the folder sealed as the decoy.
+func (p *Project) DecoyManifestPath() string {
+	if p.Decoy == nil || p.Decoy.Manifest == "" {
+		return filepath.Join(p.Path, DefaultDecoyDir)
+	}
+	return p.ResolvePath(p.Decoy.Manifest)
+}
+
+// DecoyPath returns the path to the sealed decoy, written when the project
+// has a decoy.
+func (p *Project) DecoyPath() string {
+	return filepath.Join(p.Path, OutputDir, core.DecoyFile)
+}
+
+// ReadDecoy returns the sealed DECOY.age, or nil when the seal has none.
+func (p *Project) ReadDecoy() ([]byte, error) {
+	data, err := os.ReadFile(p.DecoyPath())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", core.DecoyFile, err)
+	}
+	return data, nil
+}
+
+// DuressPath returns the directory of duress pieces.
+func (p *Project) DuressPath() string {
+	return filepath.Join(p.Path, OutputDir, DuressDir)
+}
+
+// DuressSharePath returns the path to a friend's duress piece. It's named
+// like their share file, so the two look alike once handed over.
+func (p *Project) DuressSharePath(f Friend) string {
+	return filepath.Join(p.DuressPath(), fmt.Sprintf("SHARE-%s.txt", core.SanitizeFilename(f.Name)))
+}
+
+// SplitPath returns the directory 'rememory split' writes its pieces and
+// bundles to.
+func (p *Project) SplitPath() string {
+	return filepath.Join(p.Path, OutputDir, SplitDir)
+}
+
+// ResolvePath returns path relative to the project directory, unless it is already absolute.
+func (p *Project) ResolvePath(path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(p.Path, path)
+}
+
 // FindProjectDir searches up the directory tree for a project.yml file.
 // Returns the directory containing the project, or an error if not found.
 func FindProjectDir(startDir string) (string, error) {
-	dir, err := filepath.Abs(startDir)
+	dir, err := manifest.AbsPath(startDir)
 	if err != nil {
 		return "", err
 	}
@@ -153,7 +752,7 @@ func FindProjectDir(startDir string) (string, error) {
 
 // New creates a new project with the given configuration.
 func New(dir, name string, threshold int, friends []Friend) (*Project, error) {
-	return NewWithOptions(dir, name, threshold, friends, false)
+	return NewWithOptions(dir, name, threshold, friends, PrivacyFull)
 }
 
 // NewAnonymous creates a new anonymous project (no contact info).
@@ -162,11 +761,17 @@ func NewAnonymous(dir, name string, threshold int, numShares int) (*Project, err
 	for i := 0; i < numShares; i++ {
 		friends[i] = Friend{Name: fmt.Sprintf("Share %d", i+1)}
 	}
-	return NewWithOptions(dir, name, threshold, friends, true)
+	return NewWithOptions(dir, name, threshold, friends, PrivacyAnonymous)
 }
 
 // NewWithOptions creates a new project with the given configuration.
-func NewWithOptions(dir, name string, threshold int, friends []Friend, anonymous bool) (*Project, error) {
+func NewWithOptions(dir, name string, threshold int, friends []Friend, privacy Privacy) (*Project, error) {
+	return NewGrouped(dir, name, threshold, nil, friends, privacy)
+}
+
+// NewGrouped creates a new project whose friends are split into groups;
+// threshold then counts the groups needed. With no groups it is NewWithOptions.
+func NewGrouped(dir, name string, threshold int, groups []Group, friends []Friend, privacy Privacy) (*Project, error) {
 	if err := os.MkdirAll(dir, 0755); err != nil {
 		return nil, fmt.Errorf("creating project directory: %w", err)
 	}
@@ -178,12 +783,13 @@ func NewWithOptions(dir, name string, threshold int, friends []Friend, anonymous
 
 	p := &Project{
 		Name:      name,
-		Created:   time.Now().Format("2006-01-02"),
+		Created:   core.Now().Format("2006-01-02"),
 		Threshold: threshold,
-		Anonymous: anonymous,
+		Groups:    groups,
 		Friends:   friends,
 		Path:      dir,
 	}
+	p.SetPrivacy(privacy)
 
 	if err := p.Validate(); err != nil {
 		return nil, err
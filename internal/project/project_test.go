@@ -4,7 +4,14 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
+	"time"
+
+	"filippo.io/age"
+
+	"github.com/eljojo/rememory/internal/core"
 )
 
 func TestNewAndLoad(t *testing.T) {
@@ -107,6 +114,146 @@ func TestValidate(t *testing.T) {
 			project: Project{Name: "test", Threshold: 2, Friends: []Friend{{Contact: "a@x.com"}, {Name: "B"}}},
 			wantErr: true,
 		},
+		{
+			name:    "friend word list",
+			project: Project{Name: "test", Threshold: 2, Friends: []Friend{{Name: "A", Words: "zh-TW"}, {Name: "B"}}},
+			wantErr: false,
+		},
+		{
+			name:    "friend EFF word list",
+			project: Project{Name: "test", Threshold: 2, Friends: []Friend{{Name: "A", Words: "eff-short"}, {Name: "B"}}},
+			wantErr: false,
+		},
+		{
+			name:    "unknown word list",
+			project: Project{Name: "test", Threshold: 2, Friends: []Friend{{Name: "A", Words: "tlh"}, {Name: "B"}}},
+			wantErr: true,
+		},
+		{
+			name:    "slip39 with too many friends",
+			project: Project{Name: "test", Threshold: 2, SLIP39: true, Friends: namedFriends(17)},
+			wantErr: true,
+		},
+		{
+			name:    "sskr with too many friends",
+			project: Project{Name: "test", Threshold: 2, SSKR: true, Friends: namedFriends(17)},
+			wantErr: true,
+		},
+		{
+			name:    "recovery time",
+			project: Project{Name: "test", Threshold: 2, RecoveryTime: &RecoveryTime{Budget: "30s", Device: "phone"}, Friends: namedFriends(2)},
+			wantErr: false,
+		},
+		{
+			name:    "recovery time without a unit",
+			project: Project{Name: "test", Threshold: 2, RecoveryTime: &RecoveryTime{Budget: "30"}, Friends: namedFriends(2)},
+			wantErr: true,
+		},
+		{
+			name:    "recovery time on an unknown device",
+			project: Project{Name: "test", Threshold: 2, RecoveryTime: &RecoveryTime{Budget: "30s", Device: "tablet"}, Friends: namedFriends(2)},
+			wantErr: true,
+		},
+		{
+			name:    "max memory",
+			project: Project{Name: "test", Threshold: 2, MaxMemory: "128M", Friends: namedFriends(2)},
+			wantErr: false,
+		},
+		{
+			name:    "max memory below scrypt's least",
+			project: Project{Name: "test", Threshold: 2, MaxMemory: "16M", Friends: namedFriends(2)},
+			wantErr: true,
+		},
+		{
+			name:    "max memory in the restricted profile",
+			project: Project{Name: "test", Threshold: 2, Crypto: core.CryptoRestricted, MaxMemory: "512M", Friends: namedFriends(2)},
+			wantErr: false,
+		},
+		{
+			name:    "max memory below the restricted profile's",
+			project: Project{Name: "test", Threshold: 2, Crypto: core.CryptoRestricted, MaxMemory: "128M", Friends: namedFriends(2)},
+			wantErr: true,
+		},
+		{
+			name:    "catalog",
+			project: Project{Name: "test", Threshold: 3, Catalog: &Catalog{Threshold: 2}, Friends: namedFriends(4)},
+			wantErr: false,
+		},
+		{
+			name:    "catalog at the threshold",
+			project: Project{Name: "test", Threshold: 3, Catalog: &Catalog{Threshold: 3}, Friends: namedFriends(4)},
+			wantErr: true,
+		},
+		{
+			name:    "catalog of one piece",
+			project: Project{Name: "test", Threshold: 3, Catalog: &Catalog{Threshold: 1}, Friends: namedFriends(4)},
+			wantErr: true,
+		},
+		{
+			name:    "decoy",
+			project: Project{Name: "test", Threshold: 2, Decoy: &Decoy{}, Friends: namedFriends(3)},
+			wantErr: false,
+		},
+		{
+			name:    "decoy with a catalog",
+			project: Project{Name: "test", Threshold: 3, Decoy: &Decoy{}, Catalog: &Catalog{Threshold: 2}, Friends: namedFriends(4)},
+			wantErr: true,
+		},
+		{
+			name:    "recovery delay",
+			project: Project{Name: "test", Threshold: 2, RecoveryDelay: "72h", Friends: namedFriends(2)},
+			wantErr: false,
+		},
+		{
+			name:    "recovery delay without a unit",
+			project: Project{Name: "test", Threshold: 2, RecoveryDelay: "72", Friends: namedFriends(2)},
+			wantErr: true,
+		},
+		{
+			name:    "recovery delay over the limit",
+			project: Project{Name: "test", Threshold: 2, RecoveryDelay: "8760h", Friends: namedFriends(2)},
+			wantErr: true,
+		},
+		{
+			name:    "recovery delay in the restricted profile",
+			project: Project{Name: "test", Threshold: 2, RecoveryDelay: "72h", Crypto: "restricted", Friends: namedFriends(2)},
+			wantErr: true,
+		},
+		{
+			name:    "friend pin",
+			project: Project{Name: "test", Threshold: 2, Friends: []Friend{{Name: "A", PIN: true}, {Name: "B"}}},
+			wantErr: false,
+		},
+		{
+			name:    "pin with ssss",
+			project: Project{Name: "test", Threshold: 2, SSSS: true, Friends: []Friend{{Name: "A", PIN: true}, {Name: "B"}}},
+			wantErr: true,
+		},
+		{
+			name:    "audio",
+			project: Project{Name: "test", Threshold: 2, Audio: true, Friends: namedFriends(3)},
+			wantErr: false,
+		},
+		{
+			name:    "pin with audio",
+			project: Project{Name: "test", Threshold: 2, Audio: true, Friends: []Friend{{Name: "A", PIN: true}, {Name: "B"}}},
+			wantErr: true,
+		},
+		{
+			name:    "review and expiry dates",
+			project: Project{Name: "test", Threshold: 2, ReviewBy: "2030-01-31", Expires: "2031-01-31", Friends: namedFriends(2)},
+			wantErr: false,
+		},
+		{
+			name:    "expiry before review",
+			project: Project{Name: "test", Threshold: 2, ReviewBy: "2031-01-31", Expires: "2030-01-31", Friends: namedFriends(2)},
+			wantErr: true,
+		},
+		{
+			name:    "expiry not a date",
+			project: Project{Name: "test", Threshold: 2, Expires: "next year", Friends: namedFriends(2)},
+			wantErr: true,
+		},
 		{
 			name: "anonymous valid without email",
 			project: Project{
@@ -148,6 +295,35 @@ func TestValidate(t *testing.T) {
 	}
 }
 
+func TestValidatePostQuantum(t *testing.T) {
+	classic, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatal(err)
+	}
+	hybrid, err := age.GenerateHybridIdentity()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p := Project{Name: "test", Threshold: 2, Friends: namedFriends(2), PostQuantum: true}
+	if err := p.Validate(); err != nil {
+		t.Errorf("post_quantum: %v", err)
+	}
+	p.Recipients = []string{hybrid.Recipient().String()}
+	if err := p.Validate(); err != nil {
+		t.Errorf("post_quantum with a hybrid recipient: %v", err)
+	}
+	p.Recipients = append(p.Recipients, classic.Recipient().String())
+	if err := p.Validate(); err == nil || !strings.Contains(err.Error(), "isn't post-quantum") {
+		t.Errorf("post_quantum with an X25519 recipient: got %v", err)
+	}
+	p.Recipients = nil
+	p.Crypto = core.CryptoRestricted
+	if err := p.Validate(); err == nil {
+		t.Error("post_quantum in the restricted profile should be rejected")
+	}
+}
+
 func TestFindProjectDir(t *testing.T) {
 	dir := t.TempDir()
 
@@ -328,7 +504,7 @@ func TestNewWithOptions(t *testing.T) {
 		{Name: "Share 3"},
 	}
 
-	p, err := NewWithOptions(projectDir, "test-options", 2, friends, true)
+	p, err := NewWithOptions(projectDir, "test-options", 2, friends, PrivacyAnonymous)
 	if err != nil {
 		t.Fatalf("NewWithOptions: %v", err)
 	}
@@ -341,6 +517,336 @@ func TestNewWithOptions(t *testing.T) {
 	}
 }
 
+func TestPrivacy(t *testing.T) {
+	friend := Friend{Name: "Alice Martin", Contact: "alice@example.com"}
+
+	tests := []struct {
+		privacy     Privacy
+		holder      string
+		listsOthers bool
+		shown       Friend
+	}{
+		{PrivacyFull, "Alice Martin", true, friend},
+		{PrivacyFirstNames, "Alice", true, Friend{Name: "Alice"}},
+		{PrivacyAnonymous, "Share 3", false, Friend{Name: "Alice Martin", Contact: "alice@example.com"}},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.privacy), func(t *testing.T) {
+			if got := tt.privacy.Holder(friend, 3); got != tt.holder {
+				t.Errorf("Holder: got %q, want %q", got, tt.holder)
+			}
+			if got := tt.privacy.ListsOthers(); got != tt.listsOthers {
+				t.Errorf("ListsOthers: got %v, want %v", got, tt.listsOthers)
+			}
+			if got := tt.privacy.Shown(friend); got.Name != tt.shown.Name || got.Contact != tt.shown.Contact {
+				t.Errorf("Shown: got %+v, want %+v", got, tt.shown)
+			}
+		})
+	}
+
+	if v, err := ParsePrivacy(""); err != nil || v != PrivacyFull {
+		t.Errorf("ParsePrivacy(\"\"): got %q, %v", v, err)
+	}
+	if _, err := ParsePrivacy("surnames"); err == nil {
+		t.Error("ParsePrivacy should reject unknown levels")
+	}
+}
+
+func TestPrivacyLevel(t *testing.T) {
+	// Projects from earlier releases only say anonymous: true
+	p := &Project{Anonymous: true}
+	if got := p.PrivacyLevel(); got != PrivacyAnonymous {
+		t.Errorf("legacy anonymous: got %q", got)
+	}
+
+	for _, v := range PrivacyLevels {
+		p := &Project{}
+		p.SetPrivacy(v)
+		if got := p.PrivacyLevel(); got != v {
+			t.Errorf("SetPrivacy(%q): level is %q", v, got)
+		}
+		if p.Anonymous != (v == PrivacyAnonymous) {
+			t.Errorf("SetPrivacy(%q): anonymous is %v", v, p.Anonymous)
+		}
+	}
+
+	conflict := &Project{
+		Name:      "test",
+		Threshold: 2,
+		Friends:   []Friend{{Name: "Alice"}, {Name: "Bob"}},
+		Anonymous: true,
+		Privacy:   PrivacyFirstNames,
+	}
+	if err := conflict.Validate(); err == nil {
+		t.Error("Validate should reject anonymous: true with privacy: first-names")
+	}
+}
+
+func TestGroups(t *testing.T) {
+	grouped := func() Project {
+		return Project{
+			Name:      "test",
+			Threshold: 2,
+			Groups:    []Group{{Name: "family", Threshold: 2}, {Name: "lawyers", Threshold: 1}},
+			Friends: []Friend{
+				{Name: "Alice", Group: "family"},
+				{Name: "Bob", Group: "family"},
+				{Name: "Carol", Group: "family"},
+				{Name: "Dave", Group: "lawyers"},
+				{Name: "Erin", Group: "lawyers"},
+			},
+		}
+	}
+
+	p := grouped()
+	if err := p.Validate(); err != nil {
+		t.Fatalf("valid project: %v", err)
+	}
+	specs := p.GroupSpecs()
+	if len(specs) != 2 || specs[0] != (core.GroupSpec{Size: 3, Threshold: 2}) || specs[1] != (core.GroupSpec{Size: 2, Threshold: 1}) {
+		t.Errorf("GroupSpecs: got %+v", specs)
+	}
+	if got := p.MinPieces(); got != 3 {
+		t.Errorf("MinPieces: got %d, want 3", got)
+	}
+	if got := len(p.GroupMembers(2)); got != 2 {
+		t.Errorf("GroupMembers(2): got %d friends, want 2", got)
+	}
+	policy := p.GroupPolicy(p.Friends[3])
+	if policy == nil || policy.Needed != 2 || policy.Groups[0].Yours || !policy.Groups[1].Yours {
+		t.Errorf("GroupPolicy: got %+v", policy)
+	}
+
+	tests := []struct {
+		name    string
+		modify  func(p *Project)
+		wantErr string
+	}{
+		{"friend without group", func(p *Project) { p.Friends[0].Group = "" }, "group is required"},
+		{"unknown group", func(p *Project) { p.Friends[0].Group = "neighbors" }, "unknown group"},
+		{"duplicate group", func(p *Project) { p.Groups[1].Name = "family" }, "listed twice"},
+		{"empty group", func(p *Project) { p.Friends[3].Group, p.Friends[4].Group = "family", "family" }, "has no friends"},
+		{"group threshold too high", func(p *Project) { p.Groups[1].Threshold = 3 }, "threshold must be between 1 and 2"},
+		{"too many groups needed", func(p *Project) { p.Threshold = 3 }, "groups needed"},
+		{"with slip39", func(p *Project) { p.SLIP39 = true }, "slip39"},
+		{"with audio", func(p *Project) { p.Audio = true }, "audio"},
+		{"single piece recovers", func(p *Project) { p.Threshold = 1 }, "single piece"},
+		{"group without project groups", func(p *Project) { p.Groups = nil }, "group"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := grouped()
+			tt.modify(&p)
+			err := p.Validate()
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("got %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestWeights(t *testing.T) {
+	weighted := func() Project {
+		return Project{
+			Name:      "test",
+			Threshold: 3,
+			Friends: []Friend{
+				{Name: "Alice", Shares: 2},
+				{Name: "Bob"},
+				{Name: "Carol", Shares: 2},
+				{Name: "Dave"},
+			},
+		}
+	}
+
+	p := weighted()
+	if err := p.Validate(); err != nil {
+		t.Fatalf("valid project: %v", err)
+	}
+	if !p.Weighted() || p.TotalPieces() != 6 {
+		t.Errorf("Weighted, TotalPieces: got %v, %d, want true, 6", p.Weighted(), p.TotalPieces())
+	}
+	// First pieces keep their friend's number; extras follow, in friend order
+	for i, want := range [][]int{{1, 5}, {2}, {3, 6}, {4}} {
+		if got := p.PieceIndexes(i); !slices.Equal(got, want) {
+			t.Errorf("PieceIndexes(%d): got %v, want %v", i, got, want)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		modify  func(p *Project)
+		wantErr string
+	}{
+		{"negative shares", func(p *Project) { p.Friends[1].Shares = -1 }, "at least 1"},
+		{"recovers alone", func(p *Project) { p.Friends[0].Shares = 3 }, "enough to recover alone"},
+		{"threshold above pieces", func(p *Project) { p.Threshold = 7 }, "cannot exceed"},
+		{"with groups", func(p *Project) {
+			p.Groups = []Group{{Name: "family", Threshold: 1}}
+			p.Threshold = 1
+			for i := range p.Friends {
+				p.Friends[i].Group = "family"
+			}
+		}, "groups"},
+		{"with sskr", func(p *Project) { p.SSKR = true }, "sskr"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := weighted()
+			tt.modify(&p)
+			err := p.Validate()
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("got %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestMediaRefresh(t *testing.T) {
+	p := Project{
+		Name:         "test",
+		Threshold:    2,
+		Friends:      []Friend{{Name: "Alice"}, {Name: "Bob"}},
+		MediaRefresh: map[string]int{MediaDisc: 15},
+	}
+	if err := p.Validate(); err != nil {
+		t.Fatalf("valid project: %v", err)
+	}
+
+	written := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	verified := written.AddDate(0, 6, 0)
+	tests := []struct {
+		medium Medium
+		want   time.Time
+	}{
+		{Medium{Type: MediaUSB, Written: written, Verified: verified}, written.AddDate(3, 0, 0)},
+		{Medium{Type: MediaHDD, Written: written, Verified: verified}, written.AddDate(5, 0, 0)},
+		{Medium{Type: MediaDisc, Written: written, Verified: verified}, written.AddDate(15, 0, 0)},
+		{Medium{Type: MediaMDisc, Written: written, Verified: verified}, written.AddDate(25, 0, 0)},
+		// Unknown type is treated as flash; unknown date counts from the first read-back
+		{Medium{Verified: verified}, verified.AddDate(3, 0, 0)},
+	}
+	for _, tt := range tests {
+		if got := p.RefreshDue(tt.medium); !got.Equal(tt.want) {
+			t.Errorf("RefreshDue(%q): got %s, want %s", tt.medium.Type, got, tt.want)
+		}
+	}
+
+	// A read-back that leaves out the type and date keeps the earlier ones
+	f := &p.Friends[0]
+	f.RecordMedium(Medium{Serial: "S1", Type: MediaSD, Written: written, Verified: written})
+	f.RecordMedium(Medium{Serial: "S2", Verified: written})
+	f.RecordMedium(Medium{Serial: "S1", Verified: verified, Failed: "bad sector"})
+	if len(f.Media) != 2 {
+		t.Fatalf("got %d media, want 2", len(f.Media))
+	}
+	if m, _ := f.Medium("S1"); m.Type != MediaSD || !m.Written.Equal(written) || !m.Verified.Equal(verified) || m.Failed == "" {
+		t.Errorf("S1 after read-back: %+v", m)
+	}
+
+	for _, refresh := range []map[string]int{{"floppy": 3}, {MediaUSB: 0}, {MediaDisc: 101}} {
+		p.MediaRefresh = refresh
+		if err := p.Validate(); err == nil || !strings.Contains(err.Error(), "media_refresh") {
+			t.Errorf("media_refresh %v: got %v, want error", refresh, err)
+		}
+	}
+}
+
+func TestBeaconConfig(t *testing.T) {
+	tests := []struct {
+		beacon  BeaconConfig
+		wantErr string
+	}{
+		{BeaconConfig{URL: "https://example.com/beacon.txt", PublicKey: "key"}, ""},
+		{BeaconConfig{URL: "https://example.com/beacon.txt", Upload: "https://dav.example.com/beacon.txt", PublicKey: "key"}, ""},
+		{BeaconConfig{URL: "http://localhost:8080/beacon.txt", PublicKey: "key"}, ""},
+		{BeaconConfig{URL: "http://example.com/beacon.txt", PublicKey: "key"}, "https://"},
+		{BeaconConfig{URL: "https://example.com/beacon.txt", Upload: "ftp://example.com/", PublicKey: "key"}, "upload"},
+		{BeaconConfig{URL: "example.com", PublicKey: "key"}, "not a URL"},
+		{BeaconConfig{URL: "https://example.com/beacon.txt"}, "public_key"},
+	}
+	for _, tt := range tests {
+		p := Project{Name: "test", Threshold: 2, Friends: []Friend{{Name: "Alice"}, {Name: "Bob"}}, Beacon: &tt.beacon}
+		err := p.Validate()
+		if tt.wantErr == "" && err != nil {
+			t.Errorf("%+v: %v", tt.beacon, err)
+		}
+		if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+			t.Errorf("%+v: got %v, want error containing %q", tt.beacon, err, tt.wantErr)
+		}
+	}
+}
+
+func TestSymlinksSetting(t *testing.T) {
+	p := Project{Name: "test", Path: t.TempDir(), Threshold: 2, Friends: []Friend{{Name: "Alice"}, {Name: "Bob"}}}
+	for _, policy := range []string{"", "skip", "keep", "follow"} {
+		p.Symlinks = policy
+		if err := p.Validate(); err != nil {
+			t.Errorf("%q: %v", policy, err)
+		}
+	}
+
+	p.Symlinks = "keep"
+	ig, err := p.ManifestIgnore()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ig.Symlinks != "keep" {
+		t.Errorf("ManifestIgnore().Symlinks = %q, want keep", ig.Symlinks)
+	}
+	if ig.Xattrs {
+		t.Error("ManifestIgnore() seals extended attributes without xattrs")
+	}
+	p.Xattrs = true
+	if ig, err = p.ManifestIgnore(); err != nil || !ig.Xattrs {
+		t.Errorf("ManifestIgnore() with xattrs: %+v, %v", ig, err)
+	}
+
+	p.Symlinks = "copy"
+	if err := p.Validate(); err == nil || !strings.Contains(err.Error(), "symlinks") {
+		t.Errorf("got %v, want an error about symlinks", err)
+	}
+}
+
+func TestMasterCodeSetting(t *testing.T) {
+	code, err := core.GenerateMasterCode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	classic, _ := core.MasterCodeRecipient(code, false)
+	hybrid, _ := core.MasterCodeRecipient(code, true)
+
+	p := Project{Name: "test", Path: t.TempDir(), Threshold: 2, Friends: []Friend{{Name: "Alice"}, {Name: "Bob"}}, Recipients: []string{classic}}
+	p.MasterCode = classic
+	if err := p.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if keys := p.RecipientKeys(); len(keys) != 2 || keys[1] != classic || len(p.Recipients) != 1 {
+		t.Errorf("RecipientKeys() = %v", keys)
+	}
+
+	for _, tc := range []struct {
+		name string
+		edit func(p *Project)
+	}{
+		{"not a key", func(p *Project) { p.MasterCode = "age1nope" }},
+		{"restricted", func(p *Project) { p.Crypto = core.CryptoRestricted; p.Recipients = nil }},
+		{"post-quantum project", func(p *Project) { p.PostQuantum = true; p.Recipients = nil }},
+		{"post-quantum code", func(p *Project) { p.MasterCode = hybrid }},
+	} {
+		q := p
+		tc.edit(&q)
+		if err := q.Validate(); err == nil || !strings.Contains(err.Error(), "master_code") {
+			t.Errorf("%s: got %v, want an error about master_code", tc.name, err)
+		}
+	}
+
+	p.Recipients, p.MasterCode, p.PostQuantum = nil, hybrid, true
+	if err := p.Validate(); err != nil {
+		t.Errorf("post-quantum: %v", err)
+	}
+}
+
 func TestSaveAndReload(t *testing.T) {
 	dir := t.TempDir()
 	projectDir := filepath.Join(dir, "test")
@@ -390,3 +896,241 @@ func containsAt(s, substr string, start int) bool {
 	}
 	return false
 }
+
+func namedFriends(n int) []Friend {
+	friends := make([]Friend, n)
+	for i := range friends {
+		friends[i] = Friend{Name: fmt.Sprintf("Friend %d", i+1)}
+	}
+	return friends
+}
+
+func TestSnapshotRestore(t *testing.T) {
+	p, err := New(filepath.Join(t.TempDir(), "test"), "test", 2, []Friend{{Name: "Alice"}, {Name: "Bob"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A friend is added before the project is sealed
+	if _, err := p.Snapshot("friend-add"); err != nil {
+		t.Fatalf("Snapshot: %v", err)
+	}
+	p.Friends = append(p.Friends, Friend{Name: "Carol"})
+	p.Sealed = &Sealed{ManifestChecksum: "sha256:first"}
+	p.Save()
+	os.MkdirAll(p.SharesPath(), 0755)
+	os.WriteFile(p.ManifestAgePath(), []byte("first seal"), 0644)
+	os.WriteFile(p.SharePath(p.Friends[0]), []byte("first piece"), 0600)
+
+	// Then sealed again, replacing the files
+	if _, err := p.Snapshot("seal"); err != nil {
+		t.Fatalf("Snapshot: %v", err)
+	}
+	p.Sealed.ManifestChecksum = "sha256:second"
+	p.Save()
+	os.WriteFile(p.ManifestAgePath(), []byte("second seal"), 0644)
+	os.WriteFile(p.SharePath(p.Friends[0]), []byte("second piece"), 0600)
+	os.WriteFile(p.SharePath(p.Friends[2]), []byte("carol's piece"), 0600)
+
+	backups, err := p.ListBackups()
+	if err != nil || len(backups) != 2 || backups[0].Command != "seal" || backups[1].Command != "friend-add" {
+		t.Fatalf("ListBackups = %+v, %v", backups, err)
+	}
+
+	// Undoing the second seal brings back the first one's files
+	if err := p.Restore(backups[0]); err != nil {
+		t.Fatalf("Restore: %v", err)
+	}
+	restored, _ := Load(p.Path)
+	if restored.Sealed == nil || restored.Sealed.ManifestChecksum != "sha256:first" {
+		t.Errorf("restored seal = %+v", restored.Sealed)
+	}
+	if data, _ := os.ReadFile(p.ManifestAgePath()); string(data) != "first seal" {
+		t.Errorf("MANIFEST.age = %q", data)
+	}
+	if data, _ := os.ReadFile(p.SharePath(p.Friends[0])); string(data) != "first piece" {
+		t.Errorf("Alice's share = %q", data)
+	}
+	if _, err := os.Stat(p.SharePath(p.Friends[2])); !os.IsNotExist(err) {
+		t.Error("a share written after the snapshot should be gone")
+	}
+
+	// And once more undoes adding Carol
+	backups, _ = p.ListBackups()
+	if len(backups) != 1 {
+		t.Fatalf("got %d backups after restoring one, want 1", len(backups))
+	}
+	p.Restore(backups[0])
+	restored, _ = Load(p.Path)
+	if len(restored.Friends) != 2 || restored.Sealed != nil {
+		t.Errorf("restored %d friends, sealed %v", len(restored.Friends), restored.Sealed != nil)
+	}
+}
+
+func TestArtifacts(t *testing.T) {
+	p, err := New(filepath.Join(t.TempDir(), "test"), "test", 2, []Friend{{Name: "Alice"}, {Name: "Bob"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	bundles := filepath.Join(p.OutputPath(), "bundles")
+	os.MkdirAll(bundles, 0755)
+	os.WriteFile(filepath.Join(p.OutputPath(), "notes.txt"), []byte("mine"), 0644)
+
+	// The first seal makes everything under output/
+	if _, err := p.Snapshot("seal"); err != nil {
+		t.Fatal(err)
+	}
+	p.Sealed = &Sealed{ManifestChecksum: "sha256:first"}
+	p.Save()
+	os.MkdirAll(p.SharesPath(), 0755)
+	os.WriteFile(p.ManifestAgePath(), []byte("first seal"), 0644)
+	os.WriteFile(p.SharePath(p.Friends[0]), []byte("first piece"), 0600)
+	os.WriteFile(filepath.Join(bundles, "bundle-alice.zip"), []byte("first bundle"), 0644)
+
+	backups, _ := p.ListBackups()
+	made, err := p.Artifacts(backups[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		filepath.Join(OutputDir, "MANIFEST.age"),
+		filepath.Join(OutputDir, "bundles", "bundle-alice.zip"),
+		filepath.Join(OutputDir, SharesDir, "SHARE-alice.txt"),
+	}
+	if !slices.Equal(made, want) {
+		t.Fatalf("first seal made %v, want %v", made, want)
+	}
+
+	// Sealing again rewrites the bundle; the shares and MANIFEST.age come
+	// back from the snapshot, so they aren't listed
+	if _, err := p.Snapshot("seal"); err != nil {
+		t.Fatal(err)
+	}
+	os.WriteFile(p.ManifestAgePath(), []byte("second seal"), 0644)
+	os.WriteFile(filepath.Join(bundles, "bundle-alice.zip"), []byte("second bundle!"), 0644)
+	backups, _ = p.ListBackups()
+	made, _ = p.Artifacts(backups[0])
+	if want := []string{filepath.Join(OutputDir, "bundles", "bundle-alice.zip")}; !slices.Equal(made, want) {
+		t.Errorf("second seal made %v, want %v", made, want)
+	}
+
+	// Undoing the first seal leaves only what was there before it
+	p.Restore(backups[0])
+	backups, _ = p.ListBackups()
+	made, _ = p.Artifacts(backups[0])
+	p.Restore(backups[0])
+	if err := p.RemoveArtifacts(made); err != nil {
+		t.Fatalf("RemoveArtifacts: %v", err)
+	}
+	left, _ := listOutput(p.OutputPath())
+	if len(left) != 1 || left["notes.txt"] == (fileStamp{}) {
+		t.Errorf("left %v, want only notes.txt", left)
+	}
+	if _, err := os.Stat(bundles); !os.IsNotExist(err) {
+		t.Error("the emptied bundles directory should be gone")
+	}
+}
+
+// Everything a seal writes that unlocks MANIFEST.age comes back on undo,
+// and none of it is deleted as made by the undone seal
+func TestRestoreSealedFiles(t *testing.T) {
+	p, err := New(filepath.Join(t.TempDir(), "test"), "test", 2, []Friend{{Name: "Alice"}, {Name: "Bob"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.Sealed = &Sealed{ManifestChecksum: "sha256:first"}
+	p.Save()
+	write := func(seal string) {
+		for _, f := range sealedFiles {
+			path := filepath.Join(p.OutputPath(), f.name)
+			if f.dir {
+				path = filepath.Join(path, "SHARE-alice.txt")
+			}
+			os.MkdirAll(filepath.Dir(path), 0755)
+			os.WriteFile(path, []byte(seal+" "+f.name), 0600)
+		}
+	}
+	write("first")
+
+	if _, err := p.Snapshot("seal"); err != nil {
+		t.Fatal(err)
+	}
+	write("second")
+	backups, _ := p.ListBackups()
+	made, err := p.Artifacts(backups[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(made) != 0 {
+		t.Errorf("listed %v as made, which restoring puts back", made)
+	}
+	if err := p.Restore(backups[0]); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"MANIFEST.age", OwnerEscrowFile, core.RecipientsFile, MasterCodeFile, core.TimelockFile, core.CatalogFile, core.DecoyFile} {
+		if data, _ := os.ReadFile(filepath.Join(p.OutputPath(), name)); string(data) != "first "+name {
+			t.Errorf("%s = %q after restoring", name, data)
+		}
+	}
+	if data, _ := os.ReadFile(filepath.Join(p.DuressPath(), "SHARE-alice.txt")); string(data) != "first "+DuressDir {
+		t.Errorf("duress piece = %q after restoring", data)
+	}
+}
+
+func TestEventLog(t *testing.T) {
+	p, err := New(filepath.Join(t.TempDir(), "test"), "test", 2, []Friend{{Name: "Alice"}, {Name: "Bob"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if events, err := p.Events(); err != nil || events != nil {
+		t.Fatalf("before logging: %v, %v", events, err)
+	}
+
+	p.LogEvent("init", "2 friends, 2 needed")
+	// A line cut short by a crash is skipped
+	f, _ := os.OpenFile(p.EventLogPath(), os.O_WRONLY|os.O_APPEND, 0)
+	f.WriteString(`{"at":"2026-`)
+	f.Close()
+	p.LogEvent("seal", "2 pieces")
+
+	events, err := p.Events()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, e := range events {
+		got = append(got, e.Command+": "+e.Detail)
+		if e.At.IsZero() {
+			t.Errorf("%s: no time", e.Command)
+		}
+	}
+	if want := []string{"init: 2 friends, 2 needed", "seal: 2 pieces"}; !slices.Equal(got, want) {
+		t.Errorf("events = %q, want %q", got, want)
+	}
+}
+
+func TestSnapshotRetention(t *testing.T) {
+	p, err := New(filepath.Join(t.TempDir(), "test"), "test", 2, []Friend{{Name: "Alice"}, {Name: "Bob"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.Backups = 3
+	for i := range 5 {
+		if _, err := p.Snapshot(fmt.Sprintf("step%d", i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	backups, _ := p.ListBackups()
+	var commands []string
+	for _, b := range backups {
+		commands = append(commands, b.Command)
+	}
+	if want := []string{"step4", "step3", "step2"}; !slices.Equal(commands, want) {
+		t.Errorf("kept %v, want %v", commands, want)
+	}
+
+	p.Backups = -1
+	if b, err := p.Snapshot("off"); b != nil || err != nil {
+		t.Errorf("with backups off: got %+v, %v", b, err)
+	}
+}
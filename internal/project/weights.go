@@ -0,0 +1,73 @@
+package project
+
+import "fmt"
+
+// Pieces is how many pieces the friend holds: more than one gives them more
+// weight in recovery, such as a spouse holding 2 of a 3-of-6 split.
+func (f Friend) Pieces() int {
+	return max(f.Shares, 1)
+}
+
+// Weighted reports whether any friend holds more than one piece.
+func (p *Project) Weighted() bool {
+	for _, f := range p.Friends {
+		if f.Pieces() > 1 {
+			return true
+		}
+	}
+	return false
+}
+
+// TotalPieces is how many pieces the passphrase is split into: one per
+// friend, plus the extra pieces of friends who hold more.
+func (p *Project) TotalPieces() int {
+	total := 0
+	for _, f := range p.Friends {
+		total += f.Pieces()
+	}
+	return total
+}
+
+// PieceIndexes returns the share indexes of the friend at position i. The
+// first is always i+1, so friends holding one piece are numbered as in an
+// unweighted project; extra pieces come after every friend's first, in
+// the order of the friends.
+func (p *Project) PieceIndexes(i int) []int {
+	indexes := []int{i + 1}
+	next := len(p.Friends) + 1
+	for j, f := range p.Friends {
+		extra := f.Pieces() - 1
+		if j == i {
+			for k := 0; k < extra; k++ {
+				indexes = append(indexes, next+k)
+			}
+			break
+		}
+		next += extra
+	}
+	return indexes
+}
+
+// validateWeights checks the friends' piece counts.
+func (p *Project) validateWeights() error {
+	for _, f := range p.Friends {
+		if f.Shares < 0 {
+			return fmt.Errorf("friend %q: shares must be at least 1, got %d", f.Name, f.Shares)
+		}
+	}
+	if !p.Weighted() {
+		return nil
+	}
+	if p.Grouped() {
+		return fmt.Errorf("friends can't hold several pieces in a project with groups; give the group a lower threshold instead")
+	}
+	if p.SLIP39 || p.SSKR || p.SSSS {
+		return fmt.Errorf("friends can't hold several pieces with slip39, sskr, or ssss, which give each friend one share")
+	}
+	for _, f := range p.Friends {
+		if f.Pieces() >= p.Threshold {
+			return fmt.Errorf("friend %q holds %d pieces, enough to recover alone with a threshold of %d", f.Name, f.Pieces(), p.Threshold)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,134 @@
+package recovercmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/eljojo/rememory/internal/core"
+	"github.com/eljojo/rememory/internal/recovery"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+var combineCmd = &cobra.Command{
+	Use:   "combine <piece>...",
+	Short: "Rebuild the passphrase from enough pieces",
+	Long: `Combine rebuilds the passphrase from enough pieces and prints it on stdout.
+
+The passphrase opens MANIFEST.age with any age-compatible tool:
+  age -d MANIFEST.age > manifest.tar.gz
+
+Pieces of a secret split on its own, with 'rememory split', give back the
+secret itself, written to stdout as it was split.`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runCombine,
+}
+
+func init() {
+	rootCmd.AddCommand(combineCmd)
+}
+
+func runCombine(cmd *cobra.Command, args []string) error {
+	split := false
+	secret, err := combinePieces(args, func(shares []*core.Share) (core.Secret, error) {
+		if len(shares) > 0 && shares[0].Secret {
+			split = true
+			return recovery.CombineSecret(shares)
+		}
+		return recovery.Combine(shares)
+	})
+	if err != nil {
+		return err
+	}
+	defer secret.Wipe()
+	if split {
+		_, err = os.Stdout.Write(secret)
+		return err
+	}
+	_, err = os.Stdout.Write(append(secret, '\n'))
+	return err
+}
+
+// passphraseFromPieces reads, checks, and combines the given share files.
+func passphraseFromPieces(paths []string) (core.Secret, error) {
+	return combinePieces(paths, recovery.Combine)
+}
+
+// combinePieces reads and checks the given share files, combining ReMemory
+// pieces with combine.
+func combinePieces(paths []string, combine func([]*core.Share) (core.Secret, error)) (core.Secret, error) {
+	status("Reading %d pieces...", len(paths))
+	if mnemonics, ok, err := recovery.ReadSLIP39Files(paths); err != nil {
+		return nil, err
+	} else if ok {
+		if restricted {
+			return nil, fmt.Errorf("SLIP-0039 words are outside the restricted crypto profile")
+		}
+		status("Combining %d sets of SLIP-0039 words...", len(mnemonics))
+		return recovery.CombineSLIP39(mnemonics)
+	}
+	if sskr, ok, err := recovery.ReadSSKRFiles(paths); err != nil {
+		return nil, err
+	} else if ok {
+		if restricted {
+			return nil, fmt.Errorf("SSKR shares are outside the restricted crypto profile")
+		}
+		status("Combining %d SSKR shares...", len(sskr))
+		return recovery.CombineSSKR(sskr)
+	}
+	if ssss, threshold, ok, err := recovery.ReadSSSSFiles(paths); err != nil {
+		return nil, err
+	} else if ok {
+		if restricted {
+			return nil, fmt.Errorf("ssss shares are outside the restricted crypto profile")
+		}
+		status("Combining %d ssss shares...", len(ssss))
+		return recovery.CombineSSSS(ssss, threshold)
+	}
+	shares, err := recovery.ReadShareFiles(paths)
+	if err != nil {
+		return nil, err
+	}
+	if restricted {
+		if err := recovery.CheckRestricted(shares, nil); err != nil {
+			return nil, err
+		}
+	}
+	warning, err := recovery.SunsetOf(shares).Check(time.Now(), ignoreExpiry)
+	if err != nil {
+		return nil, err
+	}
+	if warning != "" {
+		status("Warning: %s", warning)
+	}
+	if err := recovery.UnlockPINs(shares, askPIN); err != nil {
+		return nil, err
+	}
+	status("Combining %d pieces...", len(shares))
+	return combine(shares)
+}
+
+// pinInput is shared so consecutive prompts read consecutive piped lines.
+var pinInput = bufio.NewReader(os.Stdin)
+
+// askPIN reads a locked piece's PIN, prompting on stderr, without echo when
+// stdin is a terminal.
+func askPIN(share *core.Share, attempt int) (string, error) {
+	if attempt > 1 {
+		status("Wrong PIN, try again.")
+	}
+	fmt.Fprintf(os.Stderr, "%s: ", recovery.PINPrompt(share))
+	if fd := int(os.Stdin.Fd()); term.IsTerminal(fd) {
+		b, err := term.ReadPassword(fd)
+		fmt.Fprintln(os.Stderr)
+		return string(b), err
+	}
+	line, err := pinInput.ReadString('\n')
+	if err != nil && line == "" {
+		return "", fmt.Errorf("reading PIN: %w", err)
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
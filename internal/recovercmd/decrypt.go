@@ -0,0 +1,151 @@
+package recovercmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/eljojo/rememory/internal/core"
+	"github.com/eljojo/rememory/internal/crypto"
+	"github.com/eljojo/rememory/internal/recovery"
+	"github.com/spf13/cobra"
+)
+
+var (
+	decryptManifest       string
+	decryptOutput         string
+	decryptPassphraseFile string
+	decryptIdentities     []string
+)
+
+var decryptCmd = &cobra.Command{
+	Use:   "decrypt [<piece>...] --manifest <MANIFEST.age|recover.html>",
+	Short: "Decrypt MANIFEST.age into a .tar.gz archive",
+	Long: `Decrypt combines the pieces and decrypts MANIFEST.age, or the copy embedded
+in a personalized recover.html, into a .tar.gz archive. For a MANIFEST.age
+split into volumes, give one of them (MANIFEST.age.001) with the rest next
+to it. Unpack the archive
+with 'rememory-recover extract'.
+
+If you already have the passphrase (from 'rememory-recover combine'), pass it
+with --passphrase-file instead of the pieces.
+
+If you hold one of the age keys the owner listed as a recipient, pass its
+identity file with --identity. It unlocks the RECIPIENTS.age next to
+MANIFEST.age; identities are tried in the order given. The owner's master
+code works the same way: put its 24 words, or the key its QR code holds, in a
+file and pass that.`,
+	RunE: runDecrypt,
+}
+
+func init() {
+	decryptCmd.Flags().StringVarP(&decryptManifest, "manifest", "m", "", "MANIFEST.age, one of its volumes, or a personalized recover.html")
+	decryptCmd.Flags().StringVarP(&decryptOutput, "output", "o", "manifest.tar.gz", "Where to write the decrypted archive")
+	decryptCmd.Flags().StringVar(&decryptPassphraseFile, "passphrase-file", "", "Read the passphrase from a file instead of combining pieces")
+	decryptCmd.Flags().StringArrayVarP(&decryptIdentities, "identity", "i", nil, "Unlock RECIPIENTS.age with this age identity file instead of combining pieces (repeatable)")
+	decryptCmd.MarkFlagRequired("manifest")
+	rootCmd.AddCommand(decryptCmd)
+}
+
+func runDecrypt(cmd *cobra.Command, args []string) error {
+	var passphrase core.Secret
+	defer func() { passphrase.Wipe() }()
+	switch {
+	case decryptPassphraseFile != "" && len(args) > 0:
+		return fmt.Errorf("pass either pieces or --passphrase-file, not both")
+	case len(decryptIdentities) > 0 && (decryptPassphraseFile != "" || len(args) > 0):
+		return fmt.Errorf("pass either --identity, pieces, or --passphrase-file, not more than one")
+	case len(decryptIdentities) > 0:
+		if restricted {
+			return fmt.Errorf("--identity is outside the restricted crypto profile")
+		}
+		var err error
+		if passphrase, err = passphraseFromIdentities(filepath.Join(filepath.Dir(decryptManifest), core.RecipientsFile), decryptIdentities); err != nil {
+			return err
+		}
+	case decryptPassphraseFile != "":
+		data, err := os.ReadFile(decryptPassphraseFile)
+		if err != nil {
+			return fmt.Errorf("reading passphrase: %w", err)
+		}
+		passphrase = core.Secret(bytes.TrimSpace(data))
+	case len(args) > 0:
+		var err error
+		if passphrase, err = passphraseFromPieces(args); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("no pieces given")
+	}
+
+	if _, err := os.Stat(decryptOutput); err == nil {
+		return fmt.Errorf("%s already exists", decryptOutput)
+	}
+
+	encrypted, repaired, err := recovery.ReadManifest(decryptManifest)
+	if err != nil {
+		return err
+	}
+	if repaired > 0 {
+		status("Repaired %d damaged blocks of %s with its parity", repaired, decryptManifest)
+	}
+	if restricted {
+		if err := core.CheckRestrictedManifest(encrypted); err != nil {
+			return err
+		}
+	}
+
+	decoy, err := recovery.ReadDecoy(decryptManifest)
+	if err != nil {
+		return err
+	}
+	timelock, err := recovery.ReadTimelock(decryptManifest)
+	if err != nil {
+		return err
+	}
+	opener, err := recovery.WaitOut(os.Stderr, timelock, passphrase)
+	if err != nil {
+		return err
+	}
+	defer opener.Wipe()
+
+	status("Decrypting %s...", decryptManifest)
+	// Written as it's decrypted; a failure leaves no partial archive behind
+	out, err := os.OpenFile(decryptOutput, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return fmt.Errorf("writing archive: %w", err)
+	}
+	if err := recovery.Decrypt(out, encrypted, decoy, opener); err != nil {
+		out.Close()
+		os.Remove(decryptOutput)
+		return fmt.Errorf("decryption failed (pieces may be from a different seal): %w", err)
+	}
+	if err := out.Close(); err != nil {
+		os.Remove(decryptOutput)
+		return fmt.Errorf("writing archive: %w", err)
+	}
+
+	status("Decrypted to %s", decryptOutput)
+	status("Next: rememory-recover extract %s", decryptOutput)
+	return nil
+}
+
+// passphraseFromIdentities decrypts the RECIPIENTS.age at path with the age
+// identity files, trying their identities in order.
+func passphraseFromIdentities(path string, identityFiles []string) (core.Secret, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", core.RecipientsFile, err)
+	}
+	identities, err := crypto.ReadIdentities(identityFiles)
+	if err != nil {
+		return nil, err
+	}
+	status("Unlocking %s...", path)
+	passphrase, err := core.DecryptWithIdentities(data, identities)
+	if err != nil {
+		return nil, fmt.Errorf("none of the identities unlock %s: %w", core.RecipientsFile, crypto.PluginHint(err))
+	}
+	return passphrase, nil
+}
@@ -0,0 +1,60 @@
+package recovercmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/eljojo/rememory/internal/manifest"
+	"github.com/spf13/cobra"
+)
+
+var extractOutput string
+
+var extractCmd = &cobra.Command{
+	Use:   "extract <manifest.tar.gz>",
+	Short: "Unpack a decrypted archive",
+	Long: `Extract unpacks the archive written by 'rememory-recover decrypt'. Symlinks
+sealed as links are made again when they point within the recovered folder;
+those that don't, and paths that would land outside the output directory,
+are skipped. Files and folders get back the permissions, modification times,
+and extended attributes they were sealed with, unless --no-metadata is
+given.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runExtract,
+}
+
+func init() {
+	extractCmd.Flags().StringVarP(&extractOutput, "output", "o", "recovered", "Output directory")
+	extractCmd.Flags().BoolVar(&noMetadata, "no-metadata", false, "Don't restore the files' permissions, times, and extended attributes")
+	rootCmd.AddCommand(extractCmd)
+}
+
+func runExtract(cmd *cobra.Command, args []string) error {
+	f, err := os.Open(args[0])
+	if err != nil {
+		return fmt.Errorf("opening archive: %w", err)
+	}
+	defer f.Close()
+
+	result, err := manifest.Extract(f, extractOutput, !noMetadata)
+	if err != nil {
+		return fmt.Errorf("extracting: %w", err)
+	}
+	for _, warning := range result.Warnings {
+		status("  Warning: %s", warning)
+	}
+
+	fmt.Printf("Recovered to: %s/\n", result.Path)
+	return filepath.Walk(result.Path, func(path string, info os.FileInfo, err error) error {
+		if err != nil || path == result.Path {
+			return err
+		}
+		rel, _ := filepath.Rel(result.Path, path)
+		if info.IsDir() {
+			rel += "/"
+		}
+		fmt.Printf("  %s\n", rel)
+		return nil
+	})
+}
@@ -0,0 +1,340 @@
+package recovercmd
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/eljojo/rememory/internal/core"
+	"github.com/eljojo/rememory/internal/manifest"
+	"github.com/eljojo/rememory/internal/recovery"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+// pauseOnExit keeps the console window open until Enter is pressed. It is set
+// on Windows, where a program started by double-clicking or dropping files on
+// it gets a console that closes as soon as it exits.
+var pauseOnExit bool
+
+// newPrompter returns how the guided recovery talks to the person running it.
+// It is a terminal unless a platform file swaps in native dialogs.
+var newPrompter = newTerminal
+
+// prompter reports progress and asks for more files during guided recovery.
+type prompter interface {
+	// say reports one line of progress.
+	say(format string, args ...any)
+	// ask explains what is missing and returns file paths or pasted codes.
+	// more is false once the person has given up.
+	ask(missing string) (answers []string, more bool)
+	// confirm asks a yes-or-no question; anything but yes is no.
+	confirm(question string) bool
+	// pin asks for a locked piece's PIN, hiding it where it can. ok is
+	// false once the person has given up.
+	pin(question string) (pin string, ok bool)
+	// finish reports where the files went, or why recovery failed.
+	finish(outputDir string, err error)
+}
+
+// guide collects pieces and the manifest from files as they are dropped in.
+type guide struct {
+	prompter
+	shares   []*core.Share
+	manifest []byte
+	decoy    []byte         // DECOY.age, when the project sealed one
+	timelock *core.Timelock // TIMELOCK.json, when the project has a recovery delay
+	dir      string         // Where the recovered files go: next to the first file given
+}
+
+// runGuided is the root command: it accepts any mix of files, works out what
+// each one is, and asks for more until recovery is possible.
+func runGuided(cmd *cobra.Command, args []string) (err error) {
+	g := &guide{prompter: newPrompter()}
+	var outputDir string
+	defer func() { g.finish(outputDir, err) }()
+
+	for _, arg := range args {
+		g.add(arg)
+	}
+
+	for !g.ready() {
+		answers, more := g.ask(g.missing())
+		if !more || len(answers) == 0 && len(g.shares) == 0 && g.manifest == nil {
+			return fmt.Errorf("stopped before recovery. %s", g.missing())
+		}
+		for _, answer := range answers {
+			g.add(answer)
+		}
+	}
+
+	if restricted {
+		if err := recovery.CheckRestricted(g.shares, g.manifest); err != nil {
+			return err
+		}
+	}
+	sunset := recovery.SunsetOf(g.shares)
+	warning, err := sunset.Check(time.Now(), ignoreExpiry)
+	if err != nil {
+		if !g.confirm(fmt.Sprintf("These pieces expired on %s. The owner asked that they not be used after that date, as there may be newer bundles. Recover with them anyway?", sunset.Expires.Format(core.DateFormat))) {
+			return err
+		}
+		warning, _ = sunset.Check(time.Now(), true)
+	}
+	if warning != "" {
+		g.say("Warning: %s", warning)
+	}
+	err = recovery.UnlockPINs(g.shares, func(s *core.Share, attempt int) (string, error) {
+		question := recovery.PINPrompt(s)
+		if attempt > 1 {
+			question = "That PIN is wrong. " + question
+		}
+		pin, ok := g.pin(question)
+		if !ok {
+			return "", fmt.Errorf("stopped before recovery: piece %d needs the PIN its holder was given", s.Index)
+		}
+		return pin, nil
+	})
+	if err != nil {
+		return err
+	}
+	passphrase, err := recovery.Combine(g.shares)
+	if err != nil {
+		return err
+	}
+	defer passphrase.Wipe()
+	g.say("")
+	opener, err := recovery.WaitOut(os.Stdout, g.timelock, passphrase)
+	if err != nil {
+		return err
+	}
+	defer opener.Wipe()
+	g.say("Decrypting...")
+	var result *manifest.ExtractResult
+	decryptErr, err := recovery.Stream(func(w io.Writer) error {
+		return recovery.Decrypt(w, g.manifest, g.decoy, opener)
+	}, func(r io.Reader) error {
+		var err error
+		result, err = manifest.Extract(r, uniqueDir(filepath.Join(g.dir, "recovered-"+core.Now().Format("2006-01-02"))), !noMetadata)
+		return err
+	})
+	if decryptErr != nil {
+		return fmt.Errorf("these pieces don't open these files — are they all from the same set? (%w)", decryptErr)
+	}
+	if err != nil {
+		return fmt.Errorf("extracting: %w", err)
+	}
+	for _, warning := range result.Warnings {
+		g.say("  Warning: %s", warning)
+	}
+	outputDir = result.Path
+	return nil
+}
+
+// terminal is the prompter for a console: files are dragged into the window
+// and their paths arrive on stdin.
+type terminal struct {
+	input *bufio.Reader
+}
+
+func newTerminal() prompter {
+	fmt.Println("ReMemory recovery")
+	fmt.Println()
+	return &terminal{input: bufio.NewReader(os.Stdin)}
+}
+
+func (t *terminal) say(format string, args ...any) {
+	fmt.Printf(format+"\n", args...)
+}
+
+func (t *terminal) ask(missing string) ([]string, bool) {
+	fmt.Println()
+	fmt.Println(missing)
+	fmt.Println("Drag a file into this window and press Enter: a README.txt, recover.html,")
+	fmt.Println("MANIFEST.age, or bundle ZIP. You can also paste a code starting with RM")
+	fmt.Println("or rm1, a recovery link, or type the 25 recovery words on one line.")
+	fmt.Print("> ")
+	line, err := t.input.ReadString('\n')
+	if line = strings.TrimSpace(line); line != "" {
+		return []string{line}, true
+	}
+	return nil, err == nil
+}
+
+func (t *terminal) confirm(question string) bool {
+	fmt.Println()
+	fmt.Println(question)
+	fmt.Print("Type yes to go on: ")
+	line, _ := t.input.ReadString('\n')
+	return strings.EqualFold(strings.TrimSpace(line), "yes")
+}
+
+func (t *terminal) pin(question string) (string, bool) {
+	fmt.Println()
+	fmt.Print(question + ": ")
+	if fd := int(os.Stdin.Fd()); term.IsTerminal(fd) {
+		b, err := term.ReadPassword(fd)
+		fmt.Println()
+		return string(b), err == nil
+	}
+	line, err := t.input.ReadString('\n')
+	return strings.TrimSpace(line), err == nil || line != ""
+}
+
+func (t *terminal) finish(outputDir string, err error) {
+	if outputDir != "" {
+		t.say("")
+		t.say("✓ Done. Your files are in:")
+		t.say("  %s", outputDir)
+	}
+	if pauseOnExit {
+		if err != nil {
+			fmt.Printf("\nError: %v\n", err)
+		}
+		fmt.Print("\nPress Enter to close this window.")
+		t.input.ReadString('\n')
+	}
+}
+
+// add identifies one dropped file or pasted code and reports what it held.
+func (g *guide) add(input string) {
+	if looksTyped(input) {
+		share, err := recovery.ParseShareText(input)
+		if err != nil {
+			g.say("✗ That code doesn't look right: %v", err)
+			return
+		}
+		g.addShare(share, "pasted code")
+		return
+	}
+
+	path := cleanDroppedPath(input)
+	found, err := recovery.Identify(path)
+	if err != nil {
+		g.say("✗ %s: %v", filepath.Base(path), err)
+		return
+	}
+	if g.dir == "" {
+		if abs, err := manifest.AbsPath(path); err == nil {
+			g.dir = filepath.Dir(abs)
+		}
+	}
+	for _, share := range found.Shares() {
+		g.addShare(share, filepath.Base(path))
+	}
+	if found.Manifest != nil && g.manifest == nil {
+		g.manifest = found.Manifest
+		g.say("✓ %s: the encrypted files", filepath.Base(path))
+	}
+	if found.Decoy != nil && g.decoy == nil {
+		g.decoy = found.Decoy
+	}
+	if found.Timelock != nil && g.timelock == nil {
+		g.timelock = found.Timelock
+	}
+}
+
+// looksTyped reports whether input is a pasted code, link, or recovery words
+// rather than the path of a dropped file.
+func looksTyped(input string) bool {
+	if fileExists(cleanDroppedPath(input)) {
+		return false
+	}
+	return strings.HasPrefix(input, "RM") || core.IsBech32Share(input) || strings.Contains(input, "#share=") || len(strings.Fields(input)) >= 25
+}
+
+func (g *guide) addShare(share *core.Share, source string) {
+	for _, s := range g.shares {
+		if (s.Index == share.Index && share.Index != 0) || bytes.Equal(s.Data, share.Data) {
+			g.say("  %s: piece %d again, skipped", source, share.Index)
+			return
+		}
+	}
+	// Pieces typed in as words don't know the threshold; 0 matches any set
+	if t := g.threshold(); t != 0 && share.Threshold != 0 && share.Threshold != t {
+		g.say("✗ %s: this piece is from a different set", source)
+		return
+	}
+	g.shares = append(g.shares, share)
+	recovery.CompleteWordShares(g.shares)
+
+	holder := share.Holder
+	if holder == "" {
+		holder = fmt.Sprintf("piece %d", share.Index)
+	} else {
+		holder += "'s piece"
+	}
+	if t := g.threshold(); t == 0 {
+		g.say("✓ %s: %s (%d so far)", source, holder, len(g.shares))
+	} else {
+		g.say("✓ %s: %s (%d of %d needed)", source, holder, len(g.shares), t)
+	}
+}
+
+// threshold is the number of pieces needed, or 0 while only pieces typed in
+// as words have been added.
+func (g *guide) threshold() int {
+	for _, s := range g.shares {
+		if s.Threshold > 0 {
+			return s.Threshold
+		}
+	}
+	return 0
+}
+
+func (g *guide) ready() bool {
+	return g.manifest != nil && g.threshold() > 0 && len(g.shares) >= g.threshold()
+}
+
+// missing describes what is still needed, in plain words.
+func (g *guide) missing() string {
+	var needs []string
+	switch {
+	case len(g.shares) == 0:
+		needs = append(needs, "pieces from your friends")
+	case g.threshold() == 0:
+		needs = append(needs, "a piece from a file or QR code, which says how many pieces are needed")
+	case len(g.shares) < g.threshold():
+		if n := g.threshold() - len(g.shares); n == 1 {
+			needs = append(needs, "1 more piece")
+		} else {
+			needs = append(needs, fmt.Sprintf("%d more pieces", n))
+		}
+	}
+	if g.manifest == nil {
+		needs = append(needs, "the encrypted files (MANIFEST.age, or a recover.html that contains them)")
+	}
+	return "Still needed: " + strings.Join(needs, " and ") + "."
+}
+
+// cleanDroppedPath undoes the quoting terminals add when a file is dragged in.
+func cleanDroppedPath(s string) string {
+	s = strings.TrimSpace(s)
+	if len(s) >= 2 && (s[0] == '"' && s[len(s)-1] == '"' || s[0] == '\'' && s[len(s)-1] == '\'') {
+		s = s[1 : len(s)-1]
+	}
+	if runtime.GOOS != "windows" && !fileExists(s) {
+		s = strings.ReplaceAll(s, `\ `, " ")
+	}
+	return s
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// uniqueDir returns dir, or dir-2, dir-3, ... if it already exists.
+func uniqueDir(dir string) string {
+	candidate := dir
+	for i := 2; fileExists(candidate); i++ {
+		candidate = fmt.Sprintf("%s-%d", dir, i)
+	}
+	return candidate
+}
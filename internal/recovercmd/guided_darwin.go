@@ -0,0 +1,135 @@
+package recovercmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// Inside ReMemory Recover.app there is no terminal: Finder starts the binary
+// with no console, so the guided recovery asks with native dialogs instead.
+func init() {
+	exe, err := os.Executable()
+	if err != nil || !strings.Contains(exe, ".app/Contents/MacOS/") {
+		return
+	}
+	newPrompter = func() prompter { return &dialogs{} }
+
+	// Older macOS versions pass a process serial number when Finder opens an app
+	args := os.Args[:1]
+	for _, arg := range os.Args[1:] {
+		if !strings.HasPrefix(arg, "-psn_") {
+			args = append(args, arg)
+		}
+	}
+	os.Args = args
+}
+
+const dialogTitle = "ReMemory Recovery"
+
+// askScript offers to choose files or type a code, and returns one answer per
+// line. Cancelling the first dialog stops; cancelling a later one goes back.
+const askScript = `on run argv
+	activate
+	set choice to button returned of (display dialog (item 1 of argv) with title "` + dialogTitle + `" buttons {"Stop", "Type a Code…", "Choose Files…"} default button 3 cancel button 1)
+	try
+		if choice is "Type a Code…" then
+			return text returned of (display dialog "Type or paste the code that starts with RM:" with title "` + dialogTitle + `" default answer "")
+		end if
+		set paths to ""
+		repeat with f in (choose file with prompt "Choose the files you have:" with multiple selections allowed)
+			set paths to paths & POSIX path of f & linefeed
+		end repeat
+		return paths
+	on error number -128
+		return ""
+	end try
+end run`
+
+const doneScript = `on run argv
+	activate
+	return button returned of (display dialog (item 1 of argv) with title "` + dialogTitle + `" buttons {"Close", "Show in Finder"} default button 2)
+end run`
+
+const confirmScript = `on run argv
+	activate
+	return button returned of (display dialog (item 1 of argv) with title "` + dialogTitle + `" buttons {"Stop", "Recover Anyway"} default button 1 with icon caution)
+end run`
+
+const pinScript = `on run argv
+	activate
+	return text returned of (display dialog (item 1 of argv) with title "` + dialogTitle + `" default answer "" with hidden answer)
+end run`
+
+const failScript = `on run argv
+	activate
+	display alert "Recovery stopped" message (item 1 of argv) as critical
+end run`
+
+// dialogs is the prompter for the macOS app. Progress lines are collected
+// and shown in the next dialog.
+type dialogs struct {
+	notes []string
+}
+
+func (d *dialogs) say(format string, args ...any) {
+	line := fmt.Sprintf(format, args...)
+	fmt.Println(line)
+	if line = strings.TrimSpace(line); line != "" && line != "Decrypting..." {
+		d.notes = append(d.notes, line)
+	}
+}
+
+// takeNotes returns the progress since the last dialog, followed by a blank line.
+func (d *dialogs) takeNotes() string {
+	if len(d.notes) == 0 {
+		return ""
+	}
+	notes := strings.Join(d.notes, "\n") + "\n\n"
+	d.notes = nil
+	return notes
+}
+
+func (d *dialogs) ask(missing string) ([]string, bool) {
+	message := d.takeNotes() + missing + "\n\nChoose the README.txt, recover.html, MANIFEST.age, or bundle ZIP files you have."
+	out, err := osascript(askScript, message)
+	if err != nil {
+		return nil, false
+	}
+	var answers []string
+	for _, line := range strings.Split(out, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			answers = append(answers, line)
+		}
+	}
+	return answers, true
+}
+
+func (d *dialogs) confirm(question string) bool {
+	button, err := osascript(confirmScript, d.takeNotes()+question)
+	return err == nil && button == "Recover Anyway"
+}
+
+func (d *dialogs) pin(question string) (string, bool) {
+	pin, err := osascript(pinScript, d.takeNotes()+question+":")
+	return pin, err == nil
+}
+
+func (d *dialogs) finish(outputDir string, err error) {
+	if err != nil {
+		osascript(failScript, d.takeNotes()+err.Error())
+		return
+	}
+	button, _ := osascript(doneScript, d.takeNotes()+"Done. Your files are in:\n"+outputDir)
+	if button == "Show in Finder" {
+		exec.Command("open", outputDir).Run()
+	}
+}
+
+// osascript runs an AppleScript, passing args to its run handler so no text
+// ever needs escaping.
+func osascript(script string, args ...string) (string, error) {
+	out, err := exec.Command("osascript", append([]string{"-e", script}, args...)...).Output()
+	return strings.TrimSpace(string(out)), err
+}
@@ -0,0 +1,9 @@
+package recovercmd
+
+import "github.com/spf13/cobra"
+
+func init() {
+	pauseOnExit = true
+	// Started from Explorer is the expected case, not a mistake to warn about
+	cobra.MousetrapHelpText = ""
+}
@@ -0,0 +1,65 @@
+// Package recovercmd is the command tree of rememory-recover, a small
+// recovery-only binary for heirs. It has no project management and no bundle
+// generation, so it stays small enough to carry alongside the bundles.
+package recovercmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+var rootCmd = &cobra.Command{
+	Use:   "rememory-recover [file]...",
+	Short: "Recover files protected with ReMemory",
+	Long: `rememory-recover rebuilds the passphrase from friends' pieces and decrypts
+the files they protect. It can't create or change anything.
+
+The easiest way: drop the files you have (README.txt, recover.html, bundle
+ZIPs, MANIFEST.age) onto rememory-recover, or run it with their paths. It
+works out what each file is and asks for anything still missing.
+
+Or step by step:
+Check the pieces:        rememory-recover verify README-alice.txt README-bob.txt
+Decrypt the archive:     rememory-recover decrypt README-alice.txt README-bob.txt -m recover.html
+Unpack it:               rememory-recover extract manifest.tar.gz
+
+Each piece can be a README.txt from a bundle or a SHARE file.
+
+With --restricted, anything outside the restricted crypto profile (older
+share formats, other age recipients, a weakened scrypt) is refused.
+
+If the owner set an expiry date on the bundles and it has passed, recovery
+stops, since there may be newer bundles; --ignore-expiry goes ahead anyway.`,
+	Args:         cobra.ArbitraryArgs,
+	RunE:         runGuided,
+	SilenceUsage: true,
+}
+
+// restricted enforces the restricted crypto profile on every command.
+var restricted bool
+
+// ignoreExpiry recovers with pieces past the expiry date their owner set.
+var ignoreExpiry bool
+
+// noMetadata leaves recovered files as any new file is, instead of giving
+// them back the permissions, times, and extended attributes they were
+// sealed with.
+var noMetadata bool
+
+func init() {
+	rootCmd.PersistentFlags().BoolVar(&restricted, "restricted", false, "Refuse pieces and files outside the restricted crypto profile")
+	rootCmd.PersistentFlags().BoolVar(&ignoreExpiry, "ignore-expiry", false, "Recover even if the pieces are past the expiry date their owner set")
+	rootCmd.Flags().BoolVar(&noMetadata, "no-metadata", false, "Don't restore the files' permissions, times, and extended attributes")
+}
+
+func Execute(v string) error {
+	rootCmd.Version = v
+	return rootCmd.Execute()
+}
+
+// status prints progress to stderr, keeping stdout for results.
+func status(format string, args ...any) {
+	fmt.Fprintf(os.Stderr, format+"\n", args...)
+}
@@ -0,0 +1,102 @@
+package recovercmd
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/eljojo/rememory/internal/core"
+	"github.com/eljojo/rememory/internal/recovery"
+	"github.com/spf13/cobra"
+)
+
+var verifyManifest string
+
+var verifyCmd = &cobra.Command{
+	Use:   "verify <piece>...",
+	Short: "Check pieces without decrypting anything",
+	Long: `Verify checks each piece's checksum and whether the pieces belong together
+and are enough to recover. With --manifest it also checks that they open that
+MANIFEST.age (or recover.html), without writing anything to disk.`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runVerify,
+}
+
+func init() {
+	verifyCmd.Flags().StringVarP(&verifyManifest, "manifest", "m", "", "Also check that the pieces open this MANIFEST.age or recover.html")
+	rootCmd.AddCommand(verifyCmd)
+}
+
+func runVerify(cmd *cobra.Command, args []string) error {
+	shares, err := recovery.ReadShareFiles(args)
+	if err != nil {
+		return err
+	}
+	for i, s := range shares {
+		if s.Holder == "" {
+			// Compact pieces and recovery words don't carry holder details
+			fmt.Printf("✓ %s: piece %d\n", args[i], s.Index)
+			continue
+		}
+		fmt.Printf("✓ %s: piece %d of %d, held by %s (%s)\n", args[i], s.Index, s.Total, s.Holder, s.Created.Format("2006-01-02"))
+	}
+
+	if err := recovery.CheckCompatible(shares); err != nil {
+		return err
+	}
+	if restricted {
+		if err := recovery.CheckRestricted(shares, nil); err != nil {
+			return err
+		}
+	}
+	if first := shares[0]; first.Groups > 0 {
+		fmt.Printf("✓ %d pieces belong together (%d of %d groups needed)\n", len(shares), first.GroupsNeeded, first.Groups)
+	} else {
+		fmt.Printf("✓ %d pieces belong together (%d needed)\n", len(shares), first.Threshold)
+	}
+	if warning, _ := recovery.SunsetOf(shares).Check(time.Now(), true); warning != "" {
+		fmt.Printf("! %s\n", warning)
+	}
+
+	if verifyManifest == "" {
+		return nil
+	}
+	if err := recovery.UnlockPINs(shares, askPIN); err != nil {
+		return err
+	}
+	passphrase, err := recovery.Combine(shares)
+	if err != nil {
+		return err
+	}
+	defer passphrase.Wipe()
+	encrypted, repaired, err := recovery.ReadManifest(verifyManifest)
+	if err != nil {
+		return err
+	}
+	if repaired > 0 {
+		fmt.Printf("! %s: %d damaged blocks, repaired with its parity\n", verifyManifest, repaired)
+	}
+	if restricted {
+		if err := core.CheckRestrictedManifest(encrypted); err != nil {
+			return err
+		}
+	}
+	decoy, err := recovery.ReadDecoy(verifyManifest)
+	if err != nil {
+		return err
+	}
+	// Opening it would mean waiting out the whole delay
+	timelock, err := recovery.ReadTimelock(verifyManifest)
+	if err != nil {
+		return err
+	}
+	if timelock != nil {
+		fmt.Printf("! %s opens after a recovery delay of about %s, so it isn't opened here; 'rememory-recover decrypt' waits it out\n", verifyManifest, recovery.FormatWait(timelock.SealedDelay()))
+		return nil
+	}
+	if err := recovery.Decrypt(io.Discard, encrypted, decoy, passphrase); err != nil {
+		return fmt.Errorf("the pieces don't open %s: %w", verifyManifest, err)
+	}
+	fmt.Printf("✓ they open %s\n", verifyManifest)
+	return nil
+}
@@ -0,0 +1,81 @@
+package recovery
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/eljojo/rememory/internal/core"
+)
+
+// ReadCatalog reads an encrypted catalog from CATALOG.age, or from the copy
+// embedded in a personalized recover.html, on its own or inside a bundle
+// ZIP.
+func ReadCatalog(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading catalog: %w", err)
+	}
+	switch {
+	case bytes.HasPrefix(data, []byte("PK\x03\x04")):
+		html, err := recoverHTMLFromZip(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", filepath.Base(path), err)
+		}
+		return catalogFromHTML(filepath.Base(path), html)
+	case IsHTML(path):
+		return catalogFromHTML(filepath.Base(path), data)
+	}
+	return data, nil
+}
+
+// OpenCatalog recovers the catalog key from the pieces and opens the
+// catalog with it.
+func OpenCatalog(shares []*core.Share, data []byte) (*core.Catalog, error) {
+	key, err := core.CombineCatalogKey(shares)
+	if err != nil {
+		return nil, err
+	}
+	defer core.Wipe(key)
+	return core.DecryptCatalog(data, key)
+}
+
+func catalogFromHTML(name string, html []byte) ([]byte, error) {
+	p, err := readPersonalization(html)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	if p.CatalogB64 == "" {
+		return nil, fmt.Errorf("%s has no catalog (the project wasn't sealed with one)", name)
+	}
+	data, err := base64.StdEncoding.DecodeString(p.CatalogB64)
+	if err != nil {
+		return nil, fmt.Errorf("decoding catalog base64: %w", err)
+	}
+	return data, nil
+}
+
+// recoverHTMLFromZip returns the recover.html inside a bundle ZIP.
+func recoverHTMLFromZip(data []byte) ([]byte, error) {
+	r, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("opening ZIP: %w", err)
+	}
+	for _, f := range r.File {
+		if !strings.EqualFold(filepath.Base(f.Name), "recover.html") {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, fmt.Errorf("opening %s: %w", f.Name, err)
+		}
+		defer rc.Close()
+		return io.ReadAll(io.LimitReader(rc, core.MaxFileSize))
+	}
+	return nil, fmt.Errorf("no recover.html in the ZIP")
+}
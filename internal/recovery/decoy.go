@@ -0,0 +1,72 @@
+package recovery
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"filippo.io/age"
+	"github.com/eljojo/rememory/internal/core"
+)
+
+// ReadDecoy returns the decoy sealed with the manifest at manifestPath:
+// DECOY.age in the same folder as MANIFEST.age, or the copy embedded in
+// recover.html. It returns nil when the project has no decoy.
+func ReadDecoy(manifestPath string) ([]byte, error) {
+	if IsHTML(manifestPath) {
+		data, err := os.ReadFile(manifestPath)
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %w", manifestPath, err)
+		}
+		decoy, err := ExtractDecoyFromHTML(data)
+		if err != nil {
+			return nil, nil
+		}
+		return decoy, nil
+	}
+	data, err := os.ReadFile(filepath.Join(filepath.Dir(manifestPath), core.DecoyFile))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", core.DecoyFile, err)
+	}
+	return data, nil
+}
+
+// ExtractDecoyFromHTML returns the DECOY.age embedded in a personalized
+// recover.html.
+func ExtractDecoyFromHTML(htmlContent []byte) ([]byte, error) {
+	p, err := readPersonalization(htmlContent)
+	if err != nil {
+		return nil, err
+	}
+	if p.DecoyB64 == "" {
+		return nil, fmt.Errorf("no decoy in HTML")
+	}
+	data, err := base64.StdEncoding.DecodeString(p.DecoyB64)
+	if err != nil {
+		return nil, fmt.Errorf("decoding decoy base64: %w", err)
+	}
+	return data, nil
+}
+
+// Decrypt decrypts the manifest with the passphrase the pieces rebuilt
+// into dst. Duress pieces rebuild the decoy's passphrase instead, which
+// doesn't open the manifest; then the decoy is decrypted. Nothing says
+// which one opened, so recovering under duress looks like any other
+// recovery. decoy may be nil.
+func Decrypt(dst io.Writer, manifest, decoy []byte, passphrase core.Secret) error {
+	err := core.Decrypt(dst, bytes.NewReader(manifest), string(passphrase))
+	var wrong *age.NoIdentityMatchError
+	if decoy != nil && errors.As(err, &wrong) {
+		if core.Decrypt(dst, bytes.NewReader(decoy), string(passphrase)) == nil {
+			return nil
+		}
+	}
+	return err
+}
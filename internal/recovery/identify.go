@@ -0,0 +1,158 @@
+package recovery
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/eljojo/rememory/internal/core"
+)
+
+// ageHeader starts every age-encrypted file, including MANIFEST.age.
+const ageHeader = "age-encryption.org/"
+
+// maxTypedShareSize bounds the text files searched for a typed-in piece.
+const maxTypedShareSize = 16 << 10
+
+// Found is what a file contributes to a recovery: a share, the encrypted
+// manifest, or both (a bundle ZIP or a personalized recover.html).
+type Found struct {
+	Share    *core.Share
+	Extra    []*core.Share // The holder's further pieces, when they hold more than one
+	Manifest []byte
+	Decoy    []byte         // DECOY.age, when the project sealed a decoy
+	Timelock *core.Timelock // TIMELOCK.json, when the project has a recovery delay
+}
+
+// Shares returns every piece found: Share, then Extra.
+func (f *Found) Shares() []*core.Share {
+	if f.Share == nil {
+		return nil
+	}
+	return append([]*core.Share{f.Share}, f.Extra...)
+}
+
+// Identify looks inside a file of unknown type — a share file, README.txt,
+// MANIFEST.age, recover.html, a whole bundle ZIP, or a text file with a
+// compact piece, recovery link, or recovery words — and returns whatever it
+// holds that recovery can use.
+func Identify(path string) (*Found, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	found, err := identifyBytes(filepath.Base(path), data)
+	if err != nil {
+		return nil, err
+	}
+	if found.Share == nil && found.Manifest == nil && found.Decoy == nil && found.Timelock == nil {
+		return nil, fmt.Errorf("%s is not a ReMemory file", filepath.Base(path))
+	}
+	return found, nil
+}
+
+func identifyBytes(name string, data []byte) (*Found, error) {
+	found := &Found{}
+	switch {
+	case bytes.HasPrefix(data, []byte("PK\x03\x04")):
+		return identifyZip(data)
+	case strings.EqualFold(filepath.Base(name), core.TimelockFile):
+		tl, err := core.ParseTimelock(data)
+		if err != nil {
+			return nil, err
+		}
+		found.Timelock = tl
+	case bytes.HasPrefix(data, []byte(ageHeader)):
+		if strings.EqualFold(filepath.Base(name), core.DecoyFile) {
+			found.Decoy = data
+		} else {
+			found.Manifest = data
+		}
+	case IsHTML(name) || bytes.Contains(data, []byte("window.PERSONALIZATION")):
+		// Generic recover.html pages carry neither; that isn't an error
+		if shares, err := ExtractSharesFromHTML(data); err == nil && verifyAll(shares) == nil {
+			found.Share, found.Extra = shares[0], shares[1:]
+		}
+		if manifest, err := ExtractManifestFromHTML(data); err == nil {
+			found.Manifest = manifest
+		}
+		if decoy, err := ExtractDecoyFromHTML(data); err == nil {
+			found.Decoy = decoy
+		}
+		if tl, err := ExtractTimelockFromHTML(data); err == nil {
+			found.Timelock = tl
+		}
+	case bytes.Contains(data, []byte(core.ShareBegin)):
+		shares, err := core.ParseShares(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		if err := verifyAll(shares); err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		found.Share, found.Extra = shares[0], shares[1:]
+	case len(data) <= maxTypedShareSize:
+		// A text file holding a compact piece, a recovery link, or the words
+		if share, err := ParseShareText(string(data)); err == nil {
+			found.Share = share
+		}
+	}
+	return found, nil
+}
+
+// verifyAll checks every share's checksum.
+func verifyAll(shares []*core.Share) error {
+	for _, share := range shares {
+		if err := share.Verify(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// identifyZip collects the share and manifest from a bundle ZIP.
+func identifyZip(data []byte) (*Found, error) {
+	r, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("opening ZIP: %w", err)
+	}
+
+	found := &Found{}
+	for _, f := range r.File {
+		name := strings.ToLower(f.Name)
+		if !strings.HasSuffix(name, ".txt") && !strings.HasSuffix(name, ".age") && !IsHTML(name) && !strings.EqualFold(f.Name, core.TimelockFile) {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, fmt.Errorf("opening %s: %w", f.Name, err)
+		}
+		content, err := io.ReadAll(io.LimitReader(rc, core.MaxFileSize+1))
+		rc.Close()
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %w", f.Name, err)
+		}
+
+		inner, err := identifyBytes(f.Name, content)
+		if err != nil {
+			return nil, err
+		}
+		if found.Share == nil {
+			found.Share, found.Extra = inner.Share, inner.Extra
+		}
+		if found.Manifest == nil {
+			found.Manifest = inner.Manifest
+		}
+		if found.Decoy == nil {
+			found.Decoy = inner.Decoy
+		}
+		if found.Timelock == nil {
+			found.Timelock = inner.Timelock
+		}
+	}
+	return found, nil
+}
@@ -0,0 +1,188 @@
+package recovery
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/eljojo/rememory/internal/core"
+)
+
+// wordShareVersion is the share version implied by the 25 recovery words,
+// which don't record one. Only v2 pieces have words.
+const wordShareVersion = 2
+
+// numberedWordRe matches one entry of the numbered word grid in README.txt,
+// e.g. " 1. merit" or "13. ábaco". Accents may be combining marks.
+var numberedWordRe = regexp.MustCompile(`(\d+)\.\s+([\p{L}\p{M}]+(?:-[\p{L}\p{M}]+)*)`)
+
+// ParseShareInput reads a piece from any form a friend might hand over: a
+// share file or README.txt, a personalized recover.html, a bundle ZIP, a
+// compact share or recovery link (what the QR code holds), the rm1 string
+// printed under the QR code, the digit groups, or the 25 recovery words. name is used for error messages and to spot HTML
+// files.
+//
+// The words and digits don't record the threshold or total; pieces read from
+// them have both set to 0 until CompleteWordShares fills them in from the
+// others.
+func ParseShareInput(name string, data []byte) (*core.Share, error) {
+	shares, err := ParseShareInputs(name, data)
+	if err != nil {
+		return nil, err
+	}
+	return shares[0], nil
+}
+
+// ParseShareInputs is ParseShareInput for a friend who may hold several
+// pieces: it returns every piece in a share file, README.txt, recover.html,
+// or bundle ZIP, in order, instead of only the first.
+func ParseShareInputs(name string, data []byte) ([]*core.Share, error) {
+	if bytes.HasPrefix(data, []byte("PK\x03\x04")) || IsHTML(name) || bytes.Contains(data, []byte(core.ShareBegin)) {
+		found, err := identifyBytes(filepath.Base(name), data)
+		if err != nil {
+			return nil, err
+		}
+		if found.Share == nil {
+			return nil, fmt.Errorf("%s doesn't hold a piece", filepath.Base(name))
+		}
+		return found.Shares(), nil
+	}
+
+	share, err := ParseShareText(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filepath.Base(name), err)
+	}
+	return []*core.Share{share}, nil
+}
+
+// ParseShareText reads a piece typed or pasted as text: a compact share, a
+// recovery link with #share=, an rm1 string, the digit groups, or the 25
+// recovery words, plain or copied from the numbered grid in README.txt.
+func ParseShareText(text string) (*core.Share, error) {
+	text = strings.TrimSpace(text)
+
+	if _, fragment, ok := strings.Cut(text, "#share="); ok {
+		compact, err := url.QueryUnescape(strings.Fields(fragment + " ")[0])
+		if err != nil {
+			return nil, fmt.Errorf("invalid recovery link: %w", err)
+		}
+		return core.ParseCompact(compact)
+	}
+	if colons := strings.Count(text, ":"); strings.HasPrefix(text, "RM") && colons >= 5 && colons <= 7 {
+		return core.ParseCompact(text)
+	}
+	if core.IsBech32Share(text) {
+		return core.ParseBech32Share(text)
+	}
+
+	if core.LooksLikeShareDigits(text) {
+		data, index, err := core.DecodeShareDigits(text)
+		if err != nil {
+			return nil, err
+		}
+		return &core.Share{
+			Version:  wordShareVersion,
+			Index:    index,
+			Data:     data,
+			Checksum: core.HashBytes(data),
+		}, nil
+	}
+	if slip39 := extractSLIP39Words(text); len(slip39) != 25 && core.IsSLIP39Words(slip39) {
+		return nil, fmt.Errorf("these are SLIP-0039 words, which can only be combined with other SLIP-0039 words")
+	}
+	if _, err := ParseSSKRText(text); err == nil {
+		return nil, fmt.Errorf("this is an SSKR share, which can only be combined with other SSKR shares")
+	}
+	if _, err := ParseSSSSText(text); err == nil {
+		return nil, fmt.Errorf("this is an ssss share, which can only be combined with other ssss shares")
+	}
+	words := extractWords(text)
+	if !core.IsShareWordCount(len(words)) {
+		return nil, fmt.Errorf("not a share file, a compact piece, a recovery link, an rm1 string, the digit groups, or 25 recovery words")
+	}
+	data, index, _, err := core.DecodeShareWordsAuto(words)
+	if err != nil {
+		return nil, err
+	}
+	return &core.Share{
+		Version:  wordShareVersion,
+		Index:    index,
+		Data:     data,
+		Checksum: core.HashBytes(data),
+	}, nil
+}
+
+// extractWords returns the recovery words in text. README.txt prints them in
+// two numbered columns, so numbered words are put back in order by number.
+// Numbering restarts at 1 for each list, which tells the grid apart from the
+// numbered instructions before it; when a README has both a native and an
+// English grid, the first one wins.
+func extractWords(text string) []string {
+	var numbered map[int]string
+	// complete reports whether numbered holds words 1 to n for a share's n
+	complete := func() bool {
+		if !core.IsShareWordCount(len(numbered)) {
+			return false
+		}
+		for n := 1; n <= len(numbered); n++ {
+			if _, ok := numbered[n]; !ok {
+				return false
+			}
+		}
+		return true
+	}
+	for _, m := range numberedWordRe.FindAllStringSubmatch(text, -1) {
+		n, _ := strconv.Atoi(m[1])
+		if n == 1 {
+			if complete() {
+				break
+			}
+			numbered = make(map[int]string)
+		}
+		if _, ok := numbered[n]; numbered != nil && !ok && n <= core.MaxShareWords() {
+			numbered[n] = strings.ToLower(m[2])
+		}
+	}
+	if complete() {
+		words := make([]string, len(numbered))
+		for n, word := range numbered {
+			words[n-1] = word
+		}
+		return words
+	}
+
+	var words []string
+	for _, field := range strings.Fields(strings.ToLower(text)) {
+		// EFF words may hold a hyphen ("t-shirt")
+		if strings.IndexFunc(field, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsMark(r) && r != '-' }) == -1 && !strings.HasPrefix(field, "-") {
+			words = append(words, field)
+		}
+	}
+	return words
+}
+
+// CompleteWordShares fills in the threshold and total of pieces read from
+// recovery words, using any piece that has them.
+func CompleteWordShares(shares []*core.Share) {
+	var full *core.Share
+	for _, s := range shares {
+		if s.Threshold > 0 {
+			full = s
+			break
+		}
+	}
+	if full == nil {
+		return
+	}
+	for _, s := range shares {
+		if s.Threshold == 0 {
+			s.Threshold = full.Threshold
+			s.Total = full.Total
+		}
+	}
+}
@@ -0,0 +1,30 @@
+package recovery
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/eljojo/rememory/internal/core"
+)
+
+// readParity reads the parity file next to the manifest at path, or the
+// volumes it was split into: MANIFEST.age.parity. It returns nil when there
+// is none.
+func readParity(path string) ([]byte, error) {
+	base := filepath.Base(path)
+	if name, _, ok := core.ParseVolumeName(path); ok {
+		base = name
+	} else {
+		base = strings.TrimSuffix(base, core.VolumeIndexSuffix)
+	}
+	data, err := os.ReadFile(filepath.Join(filepath.Dir(path), base+core.ParitySuffix))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading %s%s: %w", base, core.ParitySuffix, err)
+	}
+	return data, nil
+}
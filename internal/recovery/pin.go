@@ -0,0 +1,53 @@
+package recovery
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/eljojo/rememory/internal/core"
+)
+
+// maxPINTries is how many times a piece's PIN is asked for before giving up.
+const maxPINTries = 3
+
+// AskPIN asks for the PIN of a locked piece. Attempt counts from 1, so a
+// prompt can say the last PIN was wrong.
+type AskPIN func(share *core.Share, attempt int) (string, error)
+
+// PINPrompt is the question asked for a locked piece's PIN.
+func PINPrompt(share *core.Share) string {
+	if share.Holder == "" {
+		return fmt.Sprintf("PIN for piece %d", share.Index)
+	}
+	return fmt.Sprintf("PIN for piece %d (%s)", share.Index, share.Holder)
+}
+
+// UnlockPINs unlocks the pieces locked with a PIN, asking for each one. A
+// friend's pieces share a PIN, so one they gave is tried on their others
+// before asking again.
+func UnlockPINs(shares []*core.Share, ask AskPIN) error {
+	known := make(map[string]string)
+	for _, s := range shares {
+		if !s.Locked() {
+			continue
+		}
+		if pin, ok := known[s.Holder]; ok && s.Holder != "" && s.UnlockPIN(pin) == nil {
+			continue
+		}
+		for attempt := 1; ; attempt++ {
+			pin, err := ask(s, attempt)
+			if err != nil {
+				return err
+			}
+			err = s.UnlockPIN(pin)
+			if err == nil {
+				known[s.Holder] = pin
+				break
+			}
+			if !errors.Is(err, core.ErrWrongPIN) || attempt == maxPINTries {
+				return fmt.Errorf("piece %d: %w", s.Index, err)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,320 @@
+// Package recovery holds the steps shared by every way of recovering a
+// manifest from the command line: reading share files, combining them, and
+// reading MANIFEST.age (directly or from a personalized recover.html).
+//
+// It deliberately doesn't import the html package, whose embedded assets
+// would add megabytes to the recovery-only rememory-recover binary.
+package recovery
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/eljojo/rememory/internal/core"
+)
+
+// ReadShareFiles parses and checksum-verifies share files. Each file can hold
+// a piece in any form ParseShareInput accepts, and forms can be mixed: pieces
+// read from recovery words take their threshold and total from the others.
+// A file holding several pieces of one friend contributes all of them.
+func ReadShareFiles(paths []string) ([]*core.Share, error) {
+	var shares []*core.Share
+	for _, path := range paths {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("reading share %s: %w", path, err)
+		}
+
+		pieces, err := ParseShareInputs(path, content)
+		if err != nil {
+			return nil, fmt.Errorf("parsing share %s: %w", path, err)
+		}
+
+		// Verify checksum
+		if err := verifyAll(pieces); err != nil {
+			return nil, fmt.Errorf("share %s: %w", path, err)
+		}
+
+		shares = append(shares, pieces...)
+	}
+	CompleteWordShares(shares)
+	return shares, nil
+}
+
+// CheckCompatible reports whether the shares belong together and are enough
+// to recover. Pieces read from recovery words alone have no threshold (0);
+// they're accepted alongside any other piece.
+func CheckCompatible(shares []*core.Share) error {
+	if len(shares) == 0 {
+		return fmt.Errorf("no shares provided")
+	}
+
+	// Pieces that record their bundle are checked against each other first:
+	// from another project, nothing else about them matters
+	if err := core.CheckSameBundle(shares); err != nil {
+		return err
+	}
+
+	// Total is informational only: it changes when a friend is added after
+	// sealing, while older pieces keep the number they were printed with.
+	first := shares[0]
+	threshold := 0
+	isGrouped := grouped(shares)
+	for i, share := range shares {
+		if share.Version != first.Version {
+			return fmt.Errorf("share %d has different version (v%d vs v%d) — all shares must be from the same bundle", i+1, share.Version, first.Version)
+		}
+		if share.Secret != first.Secret {
+			return fmt.Errorf("shares 1 and %d don't belong together: one rebuilds a seal's passphrase, the other a secret split on its own", i+1)
+		}
+		// Pieces of a grouped project have a threshold per group
+		if share.Threshold == 0 || isGrouped {
+			continue
+		}
+		if threshold != 0 && share.Threshold != threshold {
+			return fmt.Errorf("share %d has different threshold (%d vs %d)", i+1, share.Threshold, threshold)
+		}
+		threshold = share.Threshold
+	}
+
+	// Check we have enough shares
+	if len(shares) < threshold {
+		return fmt.Errorf("need at least %d shares to recover (you provided %d)", threshold, len(shares))
+	}
+
+	// Check for duplicates. Words for piece numbers above 15 don't record
+	// the number (index 0), so pieces are also compared by their data.
+	seen := make(map[int]bool)
+	for i, share := range shares {
+		if share.Index != 0 && seen[share.Index] {
+			return fmt.Errorf("duplicate share index %d", share.Index)
+		}
+		seen[share.Index] = true
+		for j, other := range shares[:i] {
+			if bytes.Equal(other.Data, share.Data) {
+				return fmt.Errorf("shares %d and %d are the same piece", j+1, i+1)
+			}
+		}
+	}
+	if grouped(shares) {
+		return core.CheckGroupShares(shares)
+	}
+	return nil
+}
+
+// grouped reports whether any of the shares comes from a project that splits
+// the passphrase by group.
+func grouped(shares []*core.Share) bool {
+	for _, s := range shares {
+		if s.Groups > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Combine checks that the shares belong together, reconstructs the
+// passphrase, and checks it against the shares' MACs. A damaged or altered
+// share is reported as a *core.MACError, naming it when spare shares let it
+// be found. The caller wipes the passphrase once it has been used.
+func Combine(shares []*core.Share) (core.Secret, error) {
+	if err := CheckCompatible(shares); err != nil {
+		return nil, err
+	}
+	if shares[0].Secret {
+		return nil, core.ErrSecretPiece
+	}
+	for _, share := range shares {
+		if share.Locked() {
+			return nil, fmt.Errorf("piece %d is locked with a PIN: unlock it before combining", share.Index)
+		}
+	}
+
+	if grouped(shares) {
+		recovered, err := core.CombineGroupShares(shares)
+		if err != nil {
+			return nil, fmt.Errorf("combining shares: %w", err)
+		}
+		defer core.Wipe(recovered)
+		if err := core.CheckMACs(shares, recovered); err != nil {
+			return nil, err
+		}
+		return core.RecoverPassphrase(recovered, shares[0].Version), nil
+	}
+
+	threshold := 0
+	for _, share := range shares {
+		threshold = max(threshold, share.Threshold)
+	}
+	recovered, err := core.CombineAuthenticated(shares, threshold)
+	var macErr *core.MACError
+	if errors.As(err, &macErr) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("combining shares: %w", err)
+	}
+	defer core.Wipe(recovered)
+	return core.RecoverPassphrase(recovered, shares[0].Version), nil
+}
+
+// CombineSecret reconstructs a secret split on its own by 'rememory split'
+// and checks it against the shares' MACs, as Combine does a passphrase.
+// The caller wipes the secret once it has been used.
+func CombineSecret(shares []*core.Share) (core.Secret, error) {
+	if err := CheckCompatible(shares); err != nil {
+		return nil, err
+	}
+	if !shares[0].Secret {
+		return nil, fmt.Errorf("these pieces are from a seal: they rebuild the passphrase for MANIFEST.age, not a secret of their own")
+	}
+	recovered, err := core.CombineAuthenticated(shares, shares[0].Threshold)
+	var macErr *core.MACError
+	if errors.As(err, &macErr) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("combining shares: %w", err)
+	}
+	return core.Secret(recovered), nil
+}
+
+// CheckRestricted applies the restricted crypto profile to the pieces and,
+// when it isn't nil, the encrypted manifest.
+func CheckRestricted(shares []*core.Share, manifest []byte) error {
+	for _, share := range shares {
+		if err := core.CheckRestrictedShare(share); err != nil {
+			return err
+		}
+	}
+	if manifest != nil {
+		return core.CheckRestrictedManifest(manifest)
+	}
+	return nil
+}
+
+// IsHTML reports whether path looks like a recover.html file rather than MANIFEST.age.
+func IsHTML(path string) bool {
+	lower := strings.ToLower(path)
+	return strings.HasSuffix(lower, ".html") || strings.HasSuffix(lower, ".htm")
+}
+
+// ReadManifest reads encrypted manifest bytes from MANIFEST.age, from the
+// volumes it was split into (given one of them or their index), or from the
+// copy embedded in a personalized recover.html. When MANIFEST.age.parity is
+// next to the file or volumes, blocks damaged since the seal are repaired
+// with it; ReadManifest also returns how many.
+func ReadManifest(path string) ([]byte, int, error) {
+	var parity []byte
+	if !IsHTML(path) {
+		var err error
+		if parity, err = readParity(path); err != nil {
+			return nil, 0, err
+		}
+	}
+	if IsVolume(path) {
+		parts, index, err := readVolumeParts(path)
+		if err != nil {
+			return nil, 0, err
+		}
+		return core.JoinVolumesWithParity(parts, index, parity)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, 0, fmt.Errorf("reading manifest: %w", err)
+	}
+	if !IsHTML(path) {
+		if parity != nil {
+			return core.RepairParity(data, parity)
+		}
+		return data, 0, nil
+	}
+	manifest, err := ExtractManifestFromHTML(data)
+	if err != nil {
+		return nil, 0, fmt.Errorf("extracting manifest from %s: %w", path, err)
+	}
+	return manifest, 0, nil
+}
+
+// personalization is the part of the PERSONALIZATION JSON embedded in
+// recover.html that recovery needs.
+type personalization struct {
+	HolderShare string          `json:"holderShare"`
+	ManifestB64 string          `json:"manifestB64"`
+	CatalogB64  string          `json:"catalogB64"`
+	DecoyB64    string          `json:"decoyB64"`
+	Timelock    json.RawMessage `json:"timelock"`
+}
+
+// personalizationRe matches the PERSONALIZATION JSON in recover.html.
+// The JSON is single-line (produced by json.Marshal) and appears as:
+//
+//	window.PERSONALIZATION = {...};
+var personalizationRe = regexp.MustCompile(`window\.PERSONALIZATION\s*=\s*(\{[^\n]*\})\s*;`)
+
+// ExtractManifestFromHTML extracts the MANIFEST.age bytes from a personalized
+// recover.html file. It finds the embedded PERSONALIZATION JSON, parses the
+// manifestB64 field, and base64-decodes it.
+//
+// Returns an error if the HTML doesn't contain personalization data, or if
+// the personalization doesn't include an embedded manifest (e.g., when
+// --no-embed-manifest was used or the manifest was too large).
+func ExtractManifestFromHTML(htmlContent []byte) ([]byte, error) {
+	p, err := readPersonalization(htmlContent)
+	if err != nil {
+		return nil, err
+	}
+	if p.ManifestB64 == "" {
+		return nil, fmt.Errorf("no embedded manifest in HTML (manifestB64 is empty)")
+	}
+
+	data, err := base64.StdEncoding.DecodeString(p.ManifestB64)
+	if err != nil {
+		return nil, fmt.Errorf("decoding manifest base64: %w", err)
+	}
+
+	return data, nil
+}
+
+// ExtractShareFromHTML returns the holder's own share from a personalized recover.html.
+func ExtractShareFromHTML(htmlContent []byte) (*core.Share, error) {
+	p, err := readPersonalization(htmlContent)
+	if err != nil {
+		return nil, err
+	}
+	if p.HolderShare == "" {
+		return nil, fmt.Errorf("no share in HTML (this recover.html isn't personalized)")
+	}
+	return core.ParseShare([]byte(p.HolderShare))
+}
+
+// ExtractSharesFromHTML returns all of the holder's pieces from a
+// personalized recover.html: one, or several when they hold more.
+func ExtractSharesFromHTML(htmlContent []byte) ([]*core.Share, error) {
+	p, err := readPersonalization(htmlContent)
+	if err != nil {
+		return nil, err
+	}
+	if p.HolderShare == "" {
+		return nil, fmt.Errorf("no share in HTML (this recover.html isn't personalized)")
+	}
+	return core.ParseShares([]byte(p.HolderShare))
+}
+
+func readPersonalization(htmlContent []byte) (*personalization, error) {
+	matches := personalizationRe.FindSubmatch(htmlContent)
+	if len(matches) < 2 {
+		return nil, fmt.Errorf("no PERSONALIZATION data found in HTML")
+	}
+	var p personalization
+	if err := json.Unmarshal(matches[1], &p); err != nil {
+		return nil, fmt.Errorf("parsing PERSONALIZATION JSON: %w", err)
+	}
+	return &p, nil
+}
@@ -0,0 +1,702 @@
+package recovery
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/eljojo/rememory/internal/core"
+)
+
+func testShares(t *testing.T) ([]*core.Share, string) {
+	t.Helper()
+	secret := make([]byte, 32)
+	for i := range secret {
+		secret[i] = byte(i)
+	}
+	parts, err := core.Split(secret, 3, 2)
+	if err != nil {
+		t.Fatalf("Split: %v", err)
+	}
+	shares := make([]*core.Share, len(parts))
+	for i, data := range parts {
+		shares[i] = core.NewShare(2, i+1, 3, 2, "Friend", data)
+	}
+	return shares, base64.RawURLEncoding.EncodeToString(secret)
+}
+
+func TestCombine(t *testing.T) {
+	shares, want := testShares(t)
+
+	got, err := Combine(shares[1:])
+	if err != nil {
+		t.Fatalf("Combine: %v", err)
+	}
+	if string(got) != want {
+		t.Errorf("passphrase = %q, want %q", string(got), want)
+	}
+
+	tests := []struct {
+		name   string
+		shares []*core.Share
+		want   string
+	}{
+		{"none", nil, "no shares"},
+		{"too few", shares[:1], "need at least 2"},
+		{"duplicate", []*core.Share{shares[0], shares[0]}, "duplicate share index"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Combine(tt.shares)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("got %v, want error containing %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestCombineDamaged(t *testing.T) {
+	shares, want := testShares(t)
+	secret, _ := base64.RawURLEncoding.DecodeString(want)
+	for _, s := range shares {
+		s.Authenticate(secret)
+	}
+	damaged := *shares[0]
+	damaged.Data = append([]byte{}, shares[0].Data...)
+	damaged.Data[3] ^= 0x80
+
+	var macErr *core.MACError
+	if _, err := Combine([]*core.Share{&damaged, shares[1]}); !errors.As(err, &macErr) || macErr.Located {
+		t.Errorf("two pieces: got %v, want an unlocated MACError", err)
+	}
+	if _, err := Combine([]*core.Share{&damaged, shares[1], shares[2]}); !errors.As(err, &macErr) || !macErr.Located || macErr.Indexes[0] != 1 {
+		t.Errorf("three pieces: got %v, want piece 1 located", err)
+	}
+	if got, err := Combine(shares[1:]); err != nil || string(got) != want {
+		t.Errorf("without the damaged piece: got %q, %v", got, err)
+	}
+}
+
+func TestCombineSecret(t *testing.T) {
+	id, err := core.NewBundleID()
+	if err != nil {
+		t.Fatal(err)
+	}
+	secret := []byte("correct horse battery staple")
+	split, err := core.SplitSecret(secret, 3, 2, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := CombineSecret(split[:2])
+	if err != nil || !bytes.Equal(got, secret) {
+		t.Errorf("got %q, %v; want %q", got, err, secret)
+	}
+
+	// Neither kind of piece goes through the other's way of combining
+	if _, err := Combine(split[:2]); !errors.Is(err, core.ErrSecretPiece) {
+		t.Errorf("Combine of split pieces: got %v", err)
+	}
+	sealed, _ := testShares(t)
+	if _, err := CombineSecret(sealed[:2]); err == nil || !strings.Contains(err.Error(), "from a seal") {
+		t.Errorf("CombineSecret of sealed pieces: got %v", err)
+	}
+	split[1].BundleID, split[1].BundleTag = "", ""
+	if _, err := CombineSecret([]*core.Share{split[1], sealed[0]}); err == nil || !strings.Contains(err.Error(), "don't belong together") {
+		t.Errorf("mixed pieces: got %v", err)
+	}
+}
+
+func TestUnlockPINs(t *testing.T) {
+	shares, want := testShares(t)
+	// Pieces 1 and 2 are the same friend's, locked with one PIN
+	for _, s := range shares[:2] {
+		if err := s.LockWithPIN("482-107"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := Combine(shares); err == nil || !strings.Contains(err.Error(), "locked with a PIN") {
+		t.Errorf("locked pieces: got %v", err)
+	}
+
+	var asked []int
+	err := UnlockPINs(shares, func(s *core.Share, attempt int) (string, error) {
+		asked = append(asked, s.Index)
+		if attempt == 1 {
+			return "000-000", nil
+		}
+		return "482-107", nil
+	})
+	if err != nil {
+		t.Fatalf("UnlockPINs: %v", err)
+	}
+	// A wrong PIN is asked for again; the second piece reuses the right one
+	if !slices.Equal(asked, []int{1, 1}) {
+		t.Errorf("asked for pieces %v, want [1 1]", asked)
+	}
+	if got, err := Combine(shares); err != nil || string(got) != want {
+		t.Errorf("unlocked pieces: got %q, %v", got, err)
+	}
+
+	shares, _ = testShares(t)
+	shares[0].LockWithPIN("482-107")
+	err = UnlockPINs(shares, func(*core.Share, int) (string, error) { return "000-000", nil })
+	if !errors.Is(err, core.ErrWrongPIN) {
+		t.Errorf("always wrong: got %v, want ErrWrongPIN", err)
+	}
+}
+
+func TestCombineGroups(t *testing.T) {
+	secret := make([]byte, 32)
+	for i := range secret {
+		secret[i] = byte(i)
+	}
+	// Two of three family members, and one of two lawyers
+	groups := []core.GroupSpec{{Size: 3, Threshold: 2}, {Size: 2, Threshold: 1}}
+	pieces, err := core.SplitGroups(secret, 2, groups)
+	if err != nil {
+		t.Fatalf("SplitGroups: %v", err)
+	}
+	var shares []*core.Share
+	for g, group := range pieces {
+		for _, data := range group {
+			s := core.NewShare(2, len(shares)+1, 5, groups[g].Threshold, "Friend", data)
+			s.Group, s.Groups, s.GroupsNeeded = g+1, 2, 2
+			shares = append(shares, s)
+		}
+	}
+
+	// Pieces pass through their compact form, as from a QR code
+	lawyer, err := ParseShareText(shares[4].CompactEncode())
+	if err != nil {
+		t.Fatalf("ParseShareText: %v", err)
+	}
+	got, err := Combine([]*core.Share{shares[1], lawyer, shares[2]})
+	if err != nil {
+		t.Fatalf("Combine: %v", err)
+	}
+	if want := base64.RawURLEncoding.EncodeToString(secret); string(got) != want {
+		t.Errorf("passphrase = %q, want %q", string(got), want)
+	}
+
+	if err := CheckCompatible(shares[:3]); err == nil || !strings.Contains(err.Error(), "need 2 complete groups") {
+		t.Errorf("family alone: got %v, want a missing-group error", err)
+	}
+}
+
+func TestExtractManifestFromHTML(t *testing.T) {
+	page := []byte("<script>window.PERSONALIZATION = {\"holder\":\"Alice\",\"manifestB64\":\"" +
+		base64.StdEncoding.EncodeToString([]byte("age data")) + "\"};</script>")
+	got, err := ExtractManifestFromHTML(page)
+	if err != nil {
+		t.Fatalf("ExtractManifestFromHTML: %v", err)
+	}
+	if string(got) != "age data" {
+		t.Errorf("manifest = %q, want %q", got, "age data")
+	}
+
+	if _, err := ExtractManifestFromHTML([]byte("<html></html>")); err == nil {
+		t.Error("expected an error for a page without personalization")
+	}
+}
+
+func TestDecryptDecoy(t *testing.T) {
+	seal := func(content, passphrase string) []byte {
+		var buf bytes.Buffer
+		if err := core.Encrypt(&buf, strings.NewReader(content), passphrase); err != nil {
+			t.Fatal(err)
+		}
+		return buf.Bytes()
+	}
+	manifest, decoy := seal("real", "real-passphrase"), seal("harmless", "duress-passphrase")
+
+	for _, tt := range []struct {
+		passphrase string
+		decoy      []byte
+		want       string
+	}{
+		{"real-passphrase", decoy, "real"},
+		{"duress-passphrase", decoy, "harmless"},
+		{"duress-passphrase", nil, ""},
+		{"wrong", decoy, ""},
+	} {
+		var out bytes.Buffer
+		err := Decrypt(&out, manifest, tt.decoy, core.Secret(tt.passphrase))
+		if tt.want == "" {
+			if err == nil {
+				t.Errorf("%s with decoy %v: expected an error", tt.passphrase, tt.decoy != nil)
+			}
+			continue
+		}
+		if err != nil || out.String() != tt.want {
+			t.Errorf("%s: got %q, %v; want %q", tt.passphrase, out.String(), err, tt.want)
+		}
+	}
+
+	page := []byte("<script>window.PERSONALIZATION = {\"holder\":\"Alice\",\"decoyB64\":\"" +
+		base64.StdEncoding.EncodeToString(decoy) + "\"};</script>")
+	found, err := identifyBytes("recover.html", page)
+	if err != nil || !bytes.Equal(found.Decoy, decoy) {
+		t.Errorf("identify recover.html: decoy = %d bytes, %v", len(found.Decoy), err)
+	}
+	found, err = identifyBytes(core.DecoyFile, decoy)
+	if err != nil || found.Manifest != nil || !bytes.Equal(found.Decoy, decoy) {
+		t.Errorf("identify %s: %+v, %v", core.DecoyFile, found, err)
+	}
+}
+
+func TestStream(t *testing.T) {
+	var sealed bytes.Buffer
+	content := strings.Repeat("a block of the manifest\n", 10000)
+	if err := core.Encrypt(&sealed, strings.NewReader(content), "passphrase"); err != nil {
+		t.Fatal(err)
+	}
+	decrypt := func(passphrase string) func(io.Writer) error {
+		return func(w io.Writer) error { return Decrypt(w, sealed.Bytes(), nil, core.Secret(passphrase)) }
+	}
+
+	var got []byte
+	decryptErr, readErr := Stream(decrypt("passphrase"), func(r io.Reader) error {
+		var err error
+		got, err = io.ReadAll(r)
+		return err
+	})
+	if decryptErr != nil || readErr != nil || string(got) != content {
+		t.Errorf("got %d bytes, %v, %v", len(got), decryptErr, readErr)
+	}
+
+	// Reading only the start still lets decryption finish
+	decryptErr, readErr = Stream(decrypt("passphrase"), func(r io.Reader) error {
+		_, err := r.Read(make([]byte, 10))
+		return err
+	})
+	if decryptErr != nil || readErr != nil {
+		t.Errorf("partial read: %v, %v", decryptErr, readErr)
+	}
+
+	// A wrong passphrase is a decryption error, whatever reading made of it
+	decryptErr, _ = Stream(decrypt("wrong"), func(r io.Reader) error {
+		_, err := io.ReadAll(r)
+		return err
+	})
+	if decryptErr == nil {
+		t.Error("expected a decryption error for the wrong passphrase")
+	}
+
+	// Reading that gives up stops decryption without blaming it
+	stop := errors.New("not an archive")
+	decryptErr, readErr = Stream(decrypt("passphrase"), func(r io.Reader) error { return stop })
+	if decryptErr != nil || readErr != stop {
+		t.Errorf("stopped read: %v, %v", decryptErr, readErr)
+	}
+}
+
+func TestIdentify(t *testing.T) {
+	shares, _ := testShares(t)
+	dir := t.TempDir()
+	write := func(name string, data []byte) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	var zipBuf bytes.Buffer
+	zw := zip.NewWriter(&zipBuf)
+	for name, content := range map[string]string{
+		"README.txt":   "Hello Bob\n\n" + shares[1].Encode(),
+		"MANIFEST.age": "age-encryption.org/v1\n...",
+		"README.pdf":   "%PDF-",
+	} {
+		w, _ := zw.Create(name)
+		w.Write([]byte(content))
+	}
+	zw.Close()
+
+	found, err := Identify(write("SHARE-alice.txt", []byte(shares[0].Encode())))
+	if err != nil || found.Share == nil || found.Share.Index != 1 || found.Manifest != nil {
+		t.Errorf("share file: got %+v, %v", found, err)
+	}
+	found, err = Identify(write("MANIFEST.age", []byte("age-encryption.org/v1\n...")))
+	if err != nil || found.Share != nil || found.Manifest == nil {
+		t.Errorf("manifest: got %+v, %v", found, err)
+	}
+	found, err = Identify(write("bundle-bob.zip", zipBuf.Bytes()))
+	if err != nil || found.Share == nil || found.Share.Index != 2 || found.Manifest == nil {
+		t.Errorf("bundle: got %+v, %v", found, err)
+	}
+	if _, err := Identify(write("notes.txt", []byte("groceries"))); err == nil {
+		t.Error("expected an error for an unrelated file")
+	}
+}
+
+// shareForms writes one piece in each form a friend might hand over and
+// returns the file names by form.
+func shareForms(t *testing.T, dir string, share *core.Share) map[string]string {
+	t.Helper()
+	write := func(name string, data []byte) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	// The word grid as README.txt prints it, Spanish first, then English
+	var grid strings.Builder
+	for _, lang := range []core.Lang{"es", "en"} {
+		words, err := share.WordsForLang(lang)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := 0; i < 13; i++ {
+			fmt.Fprintf(&grid, "%2d. %-18s", i+1, words[i])
+			if i+13 < len(words) {
+				fmt.Fprintf(&grid, "%2d. %s", i+14, words[i+13])
+			}
+			grid.WriteString("\n")
+		}
+		grid.WriteString("\n")
+	}
+
+	// The digit groups as README.txt prints them, six to a line
+	groups, err := share.Digits()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var digits strings.Builder
+	for i := 0; i < len(groups); i += 6 {
+		digits.WriteString("  " + strings.Join(groups[i:i+6], "  ") + "\n")
+	}
+
+	// The rm1 string as the PDF prints it under the QR code
+	b32, err := share.Bech32()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var typed strings.Builder
+	for i := 0; i < len(b32); i += 4 {
+		typed.WriteString(b32[i:min(i+4, len(b32))] + " ")
+	}
+
+	var zipBuf bytes.Buffer
+	zw := zip.NewWriter(&zipBuf)
+	w, _ := zw.Create("README.txt")
+	w.Write([]byte("Hello\n\n" + share.Encode()))
+	zw.Close()
+
+	n := share.Index
+	return map[string]string{
+		"pem":    write(fmt.Sprintf("README-%d.txt", n), []byte("Hello\n\n"+share.Encode())),
+		"qr":     write(fmt.Sprintf("qr-%d.txt", n), []byte(share.CompactEncode()+"\n")),
+		"url":    write(fmt.Sprintf("link-%d.txt", n), []byte("https://example.com/recover.html#share="+url.QueryEscape(share.CompactEncode()))),
+		"bech32": write(fmt.Sprintf("rm1-%d.txt", n), []byte(typed.String()+"\n")),
+		"words":  write(fmt.Sprintf("words-%d.txt", n), []byte(grid.String())),
+		"digits": write(fmt.Sprintf("digits-%d.txt", n), []byte(digits.String())),
+		"zip":    write(fmt.Sprintf("bundle-%d.zip", n), zipBuf.Bytes()),
+	}
+}
+
+// TestMixedForms checks that every combination of share forms recovers,
+// in any order, so a family can pool whatever each friend still has.
+func TestMixedForms(t *testing.T) {
+	secret := make([]byte, 32)
+	for i := range secret {
+		secret[i] = byte(i * 7)
+	}
+	want := base64.RawURLEncoding.EncodeToString(secret)
+	forms := []string{"pem", "qr", "url", "bech32", "words", "digits", "zip"}
+
+	for _, tc := range []struct{ total, threshold int }{{3, 2}, {5, 3}} {
+		parts, err := core.Split(secret, tc.total, tc.threshold)
+		if err != nil {
+			t.Fatal(err)
+		}
+		dir := t.TempDir()
+		files := make([]map[string]string, tc.threshold)
+		for i := range files {
+			files[i] = shareForms(t, dir, core.NewShare(2, i+1, tc.total, tc.threshold, fmt.Sprintf("Friend %d", i+1), parts[i]))
+		}
+
+		// Every assignment of forms to the pieces
+		combos := [][]string{{}}
+		for range files {
+			var next [][]string
+			for _, c := range combos {
+				for _, f := range forms {
+					next = append(next, append(slices.Clone(c), f))
+				}
+			}
+			combos = next
+		}
+		for _, combo := range combos {
+			paths := make([]string, len(combo))
+			for i, form := range combo {
+				paths[i] = files[i][form]
+			}
+			name := fmt.Sprintf("%d-of-%d/%s", tc.threshold, tc.total, strings.Join(combo, "+"))
+			shares, err := ReadShareFiles(paths)
+			if err != nil {
+				t.Errorf("%s: ReadShareFiles: %v", name, err)
+				continue
+			}
+			got, err := Combine(shares)
+			if err != nil || string(got) != want {
+				t.Errorf("%s: got %q, %v", name, got, err)
+			}
+		}
+	}
+}
+
+func TestParseShareText(t *testing.T) {
+	shares, _ := testShares(t)
+	share := shares[1]
+	words, _ := share.Words()
+	groups, _ := share.Digits()
+	b32, _ := share.Bech32()
+	long, _ := share.WordsForLang(core.WordListEFFLong)
+	short, _ := share.WordsForLang(core.WordListEFFShort)
+	var typed []string
+	for _, w := range short {
+		typed = append(typed, w[:3])
+	}
+	// A README grid for the EFF long list, then the English one
+	var grid strings.Builder
+	for i, w := range long {
+		fmt.Fprintf(&grid, "%2d. %s\n", i+1, w)
+	}
+	for i, w := range words {
+		fmt.Fprintf(&grid, "%2d. %s\n", i+1, w)
+	}
+
+	for _, text := range []string{
+		share.CompactEncode(),
+		b32,
+		strings.ToUpper(b32[:30] + " " + b32[30:]),
+		strings.Join(groups, " "),
+		strings.Join(groups, "-") + "\n",
+		"  https://example.com/recover.html#share=" + url.QueryEscape(share.CompactEncode()) + "\n",
+		strings.Join(words, " "),
+		strings.ToUpper(strings.Join(words, "\n")),
+		strings.Join(long, " "),
+		strings.Join(typed, " "),
+		grid.String(),
+	} {
+		got, err := ParseShareText(text)
+		if err != nil {
+			t.Errorf("ParseShareText(%.30q): %v", text, err)
+			continue
+		}
+		if got.Index != share.Index || !bytes.Equal(got.Data, share.Data) {
+			t.Errorf("ParseShareText(%.30q) = piece %d", text, got.Index)
+		}
+	}
+
+	if _, err := ParseShareText(strings.Join(words[:24], " ")); err == nil {
+		t.Error("expected an error for 24 words")
+	}
+	if _, err := ParseShareText(strings.Replace(b32, "q", "p", 1)); err == nil || !strings.Contains(err.Error(), "mistyped") {
+		t.Errorf("expected a mistyped rm1 string to be caught, got %v", err)
+	}
+	groups[2] = groups[3]
+	if _, err := ParseShareText(strings.Join(groups, " ")); err == nil || !strings.Contains(err.Error(), "group 3") {
+		t.Errorf("expected an error for group 3, got %v", err)
+	}
+	if _, err := ParseShareText("groceries: milk, eggs"); err == nil {
+		t.Error("expected an error for unrelated text")
+	}
+}
+
+func TestCheckCompatibleWordShares(t *testing.T) {
+	shares, want := testShares(t)
+	words, _ := shares[0].Words()
+	fromWords, err := ParseShareText(strings.Join(words, " "))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Words first: the threshold comes from the other piece
+	got, err := Combine([]*core.Share{fromWords, shares[2]})
+	if err != nil || string(got) != want {
+		t.Errorf("words + share: got %q, %v", got, err)
+	}
+	if err := CheckCompatible([]*core.Share{fromWords, shares[0]}); err == nil {
+		t.Error("expected the same piece as words and as a file to be caught")
+	}
+
+	// Once filled in, word pieces count towards the threshold like any other
+	batch := []*core.Share{fromWords, shares[1]}
+	CompleteWordShares(batch)
+	if fromWords.Threshold != 2 || fromWords.Total != 3 {
+		t.Errorf("completed word piece = %d of %d", fromWords.Threshold, fromWords.Total)
+	}
+}
+
+func TestCheckCompatibleBundles(t *testing.T) {
+	shares, want := testShares(t)
+	ours, _ := core.NewBundleID()
+	theirs, _ := core.NewBundleID()
+	for _, s := range shares {
+		s.Bind(ours)
+	}
+
+	// A piece typed in as words carries no bundle ID and still counts
+	words, _ := shares[0].Words()
+	fromWords, err := ParseShareText(strings.Join(words, " "))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := Combine([]*core.Share{fromWords, shares[1]}); err != nil || string(got) != want {
+		t.Errorf("words + bound share: got %q, %v", got, err)
+	}
+
+	stranger := core.NewShare(2, 3, 3, 2, "Stranger", shares[2].Data)
+	stranger.Bind(theirs)
+	err = CheckCompatible([]*core.Share{shares[0], stranger})
+	if err == nil || !strings.Contains(err.Error(), core.BundleFingerprint(theirs)) {
+		t.Errorf("mixed bundles: got %v, want an error naming %s", err, core.BundleFingerprint(theirs))
+	}
+}
+
+func TestExtractSLIP39Words(t *testing.T) {
+	mnemonics, err := core.SLIP39Split(bytes.Repeat([]byte{9}, 32), 2, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	words := strings.Fields(mnemonics[0])
+
+	var grid strings.Builder
+	grid.WriteString("1. Enter the words into any tool.\n2. It gives back a secret.\n\n")
+	for i, w := range words {
+		fmt.Fprintf(&grid, "%2d. %s\n", i+1, w)
+	}
+
+	for name, text := range map[string]string{
+		"one line":      mnemonics[0] + "\n",
+		"numbered grid": grid.String(),
+		"capitalized":   strings.ToUpper(mnemonics[0]),
+	} {
+		t.Run(name, func(t *testing.T) {
+			got := extractSLIP39Words(text)
+			if strings.ToLower(strings.Join(got, " ")) != mnemonics[0] {
+				t.Errorf("got %q", got)
+			}
+		})
+	}
+
+	if _, err := ParseShareText(mnemonics[1]); err == nil || !strings.Contains(err.Error(), "SLIP-0039") {
+		t.Errorf("expected a SLIP-0039 error, got %v", err)
+	}
+}
+
+func TestSunset(t *testing.T) {
+	shares, _ := testShares(t)
+	day := func(d int) time.Time { return time.Date(2030, 1, d, 0, 0, 0, 0, time.UTC) }
+	shares[0].ReviewBy, shares[0].Expires = day(10), day(20)
+	shares[1].ReviewBy, shares[1].Expires = day(12), day(18)
+
+	// The earliest dates win; pieces without dates don't count
+	s := SunsetOf(shares)
+	if !s.ReviewBy.Equal(day(10)) || !s.Expires.Equal(day(18)) {
+		t.Fatalf("got %+v", s)
+	}
+
+	tests := []struct {
+		name        string
+		now         time.Time
+		ignore      bool
+		wantWarning string
+		wantErr     bool
+	}{
+		{"before review", day(5), false, "", false},
+		{"on the review date", day(10).Add(23 * time.Hour), false, "", false},
+		{"past review", day(11), false, "due for review on 2030-01-10", false},
+		{"on the expiry date", day(18).Add(12 * time.Hour), false, "due for review", false},
+		{"past expiry", day(19), false, "", true},
+		{"past expiry, ignored", day(19), true, "expired on 2030-01-18", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			warning, err := s.Check(tt.now, tt.ignore)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v", err)
+			}
+			if tt.wantWarning == "" && warning != "" || !strings.Contains(warning, tt.wantWarning) {
+				t.Errorf("warning = %q, want %q", warning, tt.wantWarning)
+			}
+		})
+	}
+
+	if warning, err := SunsetOf(shares[2:]).Check(day(31), false); warning != "" || err != nil {
+		t.Errorf("pieces without dates: %q, %v", warning, err)
+	}
+}
+
+func TestWaitOutTimelock(t *testing.T) {
+	seal, err := core.NewTimelock(time.Second, 5000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	passphrase := core.Secret("rebuilt-passphrase")
+	var manifest bytes.Buffer
+	if err := core.Encrypt(&manifest, strings.NewReader("real"), string(seal.Lock(passphrase))); err != nil {
+		t.Fatal(err)
+	}
+	if err := Decrypt(io.Discard, manifest.Bytes(), nil, passphrase); err == nil {
+		t.Fatal("the pieces' passphrase opened MANIFEST.age without the wait")
+	}
+
+	data, err := seal.Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	manifestPath := filepath.Join(dir, "MANIFEST.age")
+	if err := os.WriteFile(manifestPath, manifest.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, core.TimelockFile), data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	tl, err := ReadTimelock(manifestPath)
+	if err != nil || tl == nil {
+		t.Fatalf("ReadTimelock: %v, %v", tl, err)
+	}
+
+	var progress bytes.Buffer
+	opener, err := WaitOut(&progress, tl, passphrase)
+	if err != nil {
+		t.Fatalf("WaitOut: %v", err)
+	}
+	var out bytes.Buffer
+	if err := Decrypt(&out, manifest.Bytes(), nil, opener); err != nil || out.String() != "real" {
+		t.Errorf("after the wait: got %q, %v", out.String(), err)
+	}
+	if !strings.Contains(progress.String(), "recovery delay") {
+		t.Errorf("WaitOut didn't say why it waits:\n%s", progress.String())
+	}
+
+	if same, err := WaitOut(io.Discard, nil, passphrase); err != nil || !bytes.Equal(same, passphrase) {
+		t.Errorf("without a time-lock: got %q, %v", same, err)
+	}
+
+	page := []byte("<script>window.PERSONALIZATION = {\"holder\":\"Alice\",\"timelock\":" + string(bytes.Join(bytes.Fields(data), nil)) + "};</script>")
+	found, err := identifyBytes("recover.html", page)
+	if err != nil || found.Timelock == nil || found.Timelock.Modulus != seal.Modulus {
+		t.Errorf("identify recover.html: timelock = %+v, %v", found.Timelock, err)
+	}
+	found, err = identifyBytes(core.TimelockFile, data)
+	if err != nil || found.Timelock == nil || found.Timelock.Squarings != seal.Squarings {
+		t.Errorf("identify %s: %+v, %v", core.TimelockFile, found, err)
+	}
+}
@@ -0,0 +1,74 @@
+package recovery
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/eljojo/rememory/internal/core"
+)
+
+// ReadSLIP39Files reads SLIP-0039 words, one friend per file: typed or
+// pasted, or a bundle's SLIP39.txt. ok is false, with no error, when the
+// first file holds something else, so the caller can read them as pieces.
+func ReadSLIP39Files(paths []string) (mnemonics []string, ok bool, err error) {
+	for i, path := range paths {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, false, fmt.Errorf("reading share %s: %w", path, err)
+		}
+		words := extractSLIP39Words(string(content))
+		if !core.IsSLIP39Words(words) {
+			if i == 0 {
+				return nil, false, nil
+			}
+			return nil, false, fmt.Errorf("%s doesn't hold SLIP-0039 words; they can only be combined with other SLIP-0039 words", filepath.Base(path))
+		}
+		mnemonics = append(mnemonics, strings.Join(words, " "))
+	}
+	return mnemonics, len(mnemonics) > 0, nil
+}
+
+// CombineSLIP39 recovers the passphrase from SLIP-0039 words written by
+// 'rememory seal' for a project that sets slip39.
+func CombineSLIP39(mnemonics []string) (core.Secret, error) {
+	secret, err := core.SLIP39Combine(mnemonics)
+	if err != nil {
+		return nil, err
+	}
+	defer core.Wipe(secret)
+	return core.RecoverPassphrase(secret, wordShareVersion), nil
+}
+
+// extractSLIP39Words returns the SLIP-0039 words in text. SLIP39.txt lists
+// them in a numbered grid after numbered instructions, so each run of
+// numbers starting at 1 is tried in turn; plain text falls back to its words.
+func extractSLIP39Words(text string) []string {
+	var runs []map[int]string
+	for _, m := range numberedWordRe.FindAllStringSubmatch(text, -1) {
+		n, _ := strconv.Atoi(m[1])
+		if n == 1 {
+			runs = append(runs, make(map[int]string))
+		}
+		if len(runs) > 0 {
+			runs[len(runs)-1][n] = strings.ToLower(m[2])
+		}
+	}
+	for _, run := range runs {
+		words := make([]string, len(run))
+		complete := true
+		for n, word := range run {
+			if n < 1 || n > len(run) {
+				complete = false
+				break
+			}
+			words[n-1] = word
+		}
+		if complete && core.IsSLIP39Words(words) {
+			return words
+		}
+	}
+	return extractWords(text)
+}
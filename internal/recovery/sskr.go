@@ -0,0 +1,53 @@
+package recovery
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+
+	"github.com/eljojo/rememory/internal/core"
+)
+
+var sskrURRe = regexp.MustCompile(`(?i)\bur:(?:crypto-)?sskr/[a-z]+`)
+
+// ReadSSKRFiles reads SSKR shares, one friend per file: a bundle's SSKR.txt,
+// or a UR or bytewords pasted on their own. ok is false, with no error, when
+// the first file holds something else, so the caller can read them as pieces.
+func ReadSSKRFiles(paths []string) (shares []*core.SSKRShare, ok bool, err error) {
+	for i, path := range paths {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, false, fmt.Errorf("reading share %s: %w", path, err)
+		}
+		share, err := ParseSSKRText(string(content))
+		if err != nil {
+			if i == 0 {
+				return nil, false, nil
+			}
+			return nil, false, fmt.Errorf("%s doesn't hold an SSKR share; SSKR shares can only be combined with each other", filepath.Base(path))
+		}
+		shares = append(shares, share)
+	}
+	return shares, len(shares) > 0, nil
+}
+
+// ParseSSKRText finds one SSKR share in text: the first UR in it, or else
+// the whole text read as bytewords.
+func ParseSSKRText(text string) (*core.SSKRShare, error) {
+	if ur := sskrURRe.FindString(text); ur != "" {
+		return core.ParseSSKR(ur)
+	}
+	return core.ParseSSKR(text)
+}
+
+// CombineSSKR recovers the passphrase from SSKR shares written by
+// 'rememory seal' for a project that sets sskr.
+func CombineSSKR(shares []*core.SSKRShare) (core.Secret, error) {
+	secret, err := core.SSKRCombine(shares)
+	if err != nil {
+		return nil, err
+	}
+	defer core.Wipe(secret)
+	return core.RecoverPassphrase(secret, wordShareVersion), nil
+}
@@ -0,0 +1,64 @@
+package recovery
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+
+	"github.com/eljojo/rememory/internal/core"
+)
+
+var (
+	ssssShareRe     = regexp.MustCompile(`(?m)^[ \t]*((?:[^\s-]+-)?[0-9]+-[0-9a-fA-F]{64})[ \t]*\r?$`)
+	ssssThresholdRe = regexp.MustCompile(`ssss-combine -t ([0-9]+)`)
+)
+
+// ReadSSSSFiles reads ssss shares, one friend per file: a bundle's SSSS.txt,
+// or a share line pasted on its own. threshold comes from the instructions
+// in SSSS.txt; with bare lines it is the number of shares given. ok is false,
+// with no error, when the first file holds something else.
+func ReadSSSSFiles(paths []string) (shares []string, threshold int, ok bool, err error) {
+	for i, path := range paths {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, 0, false, fmt.Errorf("reading share %s: %w", path, err)
+		}
+		share, err := ParseSSSSText(string(content))
+		if err != nil {
+			if i == 0 {
+				return nil, 0, false, nil
+			}
+			return nil, 0, false, fmt.Errorf("%s doesn't hold an ssss share; ssss shares can only be combined with each other", filepath.Base(path))
+		}
+		if m := ssssThresholdRe.FindStringSubmatch(string(content)); m != nil && threshold == 0 {
+			threshold, _ = strconv.Atoi(m[1])
+		}
+		shares = append(shares, share)
+	}
+	if threshold == 0 {
+		threshold = len(shares)
+	}
+	return shares, threshold, len(shares) > 0, nil
+}
+
+// ParseSSSSText finds the one ssss share line in text.
+func ParseSSSSText(text string) (string, error) {
+	m := ssssShareRe.FindStringSubmatch(text)
+	if m == nil {
+		return "", fmt.Errorf("no ssss share found")
+	}
+	return m[1], nil
+}
+
+// CombineSSSS recovers the passphrase from ssss shares written by
+// 'rememory seal' for a project that sets ssss.
+func CombineSSSS(shares []string, threshold int) (core.Secret, error) {
+	secret, err := core.SSSSCombine(shares, threshold)
+	if err != nil {
+		return nil, err
+	}
+	defer core.Wipe(secret)
+	return core.RecoverPassphrase(secret, wordShareVersion), nil
+}
@@ -0,0 +1,42 @@
+package recovery
+
+import (
+	"errors"
+	"io"
+)
+
+// Stream runs decrypt, writing into a pipe, while read reads from the other
+// end, so what is decrypted is used as it comes rather than held in memory:
+// a manifest of any size is unpacked to disk with a block of it in memory at
+// a time.
+//
+// What read leaves unread is drained, so decrypt still checks the end of
+// the stream. When read fails, decrypt is stopped, and its error is only
+// returned if it failed on its own: a wrong passphrase shows up as a
+// decryption error, not as the damaged archive it looks like to read.
+func Stream(decrypt func(w io.Writer) error, read func(r io.Reader) error) (decryptErr, readErr error) {
+	pr, pw := io.Pipe()
+	done := make(chan error, 1)
+	go func() {
+		err := decrypt(pw)
+		pw.CloseWithError(err)
+		done <- err
+	}()
+
+	readErr = read(pr)
+	if readErr == nil {
+		_, readErr = io.Copy(io.Discard, pr)
+	}
+	if readErr != nil {
+		pr.CloseWithError(errStopped)
+	}
+
+	decryptErr = <-done
+	if errors.Is(decryptErr, errStopped) {
+		decryptErr = nil
+	}
+	return decryptErr, readErr
+}
+
+// errStopped is what decrypt's writes fail with once read has failed.
+var errStopped = errors.New("stopped reading")
@@ -0,0 +1,52 @@
+package recovery
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/eljojo/rememory/internal/core"
+)
+
+// Sunset holds the review and expiry dates an owner set on a generation of
+// bundles. Either is zero when unset, and always is for pieces read from
+// words, digits, or the QR code, which don't carry them.
+type Sunset struct {
+	ReviewBy time.Time
+	Expires  time.Time
+}
+
+// SunsetOf returns the earliest review and expiry dates among the pieces.
+func SunsetOf(shares []*core.Share) Sunset {
+	var s Sunset
+	for _, share := range shares {
+		if !share.ReviewBy.IsZero() && (s.ReviewBy.IsZero() || share.ReviewBy.Before(s.ReviewBy)) {
+			s.ReviewBy = share.ReviewBy
+		}
+		if !share.Expires.IsZero() && (s.Expires.IsZero() || share.Expires.Before(s.Expires)) {
+			s.Expires = share.Expires
+		}
+	}
+	return s
+}
+
+// Past reports whether the whole of date's day is over as of now. A zero date
+// is never past.
+func Past(date, now time.Time) bool {
+	return !date.IsZero() && !now.UTC().Before(date.AddDate(0, 0, 1))
+}
+
+// Check returns a warning once the review date has passed, and an error once
+// the expiry date has, unless ignoreExpiry is set; then the expiry becomes
+// the warning instead.
+func (s Sunset) Check(now time.Time, ignoreExpiry bool) (warning string, err error) {
+	if Past(s.Expires, now) {
+		if !ignoreExpiry {
+			return "", fmt.Errorf("these pieces expired on %s: the owner asked that they not be used after that date, as there may be newer bundles. Ask the other friends for the newest ones, or use --ignore-expiry to recover with these anyway", s.Expires.Format(core.DateFormat))
+		}
+		return fmt.Sprintf("these pieces expired on %s; there may be newer bundles", s.Expires.Format(core.DateFormat)), nil
+	}
+	if Past(s.ReviewBy, now) {
+		return fmt.Sprintf("these pieces were due for review on %s; there may be newer bundles, so check with the other friends before relying on these", s.ReviewBy.Format(core.DateFormat)), nil
+	}
+	return "", nil
+}
@@ -0,0 +1,93 @@
+package recovery
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/eljojo/rememory/internal/core"
+)
+
+// ReadTimelock returns the time-lock puzzle sealed with the manifest at
+// manifestPath: TIMELOCK.json in the same folder as MANIFEST.age, or the
+// copy embedded in recover.html. It returns nil when the project has no
+// recovery delay.
+func ReadTimelock(manifestPath string) (*core.Timelock, error) {
+	if IsHTML(manifestPath) {
+		data, err := os.ReadFile(manifestPath)
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %w", manifestPath, err)
+		}
+		tl, err := ExtractTimelockFromHTML(data)
+		if err != nil {
+			return nil, nil
+		}
+		return tl, nil
+	}
+	data, err := os.ReadFile(filepath.Join(filepath.Dir(manifestPath), core.TimelockFile))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", core.TimelockFile, err)
+	}
+	return core.ParseTimelock(data)
+}
+
+// ExtractTimelockFromHTML returns the TIMELOCK.json embedded in a
+// personalized recover.html.
+func ExtractTimelockFromHTML(htmlContent []byte) (*core.Timelock, error) {
+	p, err := readPersonalization(htmlContent)
+	if err != nil {
+		return nil, err
+	}
+	if len(p.Timelock) == 0 {
+		return nil, fmt.Errorf("no recovery delay in HTML")
+	}
+	return core.ParseTimelock(p.Timelock)
+}
+
+// WaitOut solves the time-lock puzzle, writing progress to w, and returns
+// the passphrase that opens MANIFEST.age. Without a puzzle that is
+// passphrase itself.
+func WaitOut(w io.Writer, tl *core.Timelock, passphrase core.Secret) (core.Secret, error) {
+	if tl == nil {
+		return passphrase, nil
+	}
+	fmt.Fprintf(w, "These files open after a recovery delay the owner chose: about %s on the computer that sealed them.\n", FormatWait(tl.SealedDelay()))
+	fmt.Fprintln(w, "Leave this running; stopping starts the wait over.")
+
+	start := time.Now()
+	var shown time.Time
+	opener, err := tl.Unlock(passphrase, func(done, total uint64) {
+		now := time.Now()
+		if now.Sub(shown) < time.Second {
+			return
+		}
+		shown = now
+		left := time.Duration(float64(now.Sub(start)) * float64(total-done) / float64(done))
+		fmt.Fprintf(w, "\r  %d%% done, about %s left   ", done*100/total, FormatWait(left))
+	})
+	if err != nil {
+		return nil, err
+	}
+	fmt.Fprintf(w, "\r  Waited %s.%s\n", FormatWait(time.Since(start)), strings.Repeat(" ", 24))
+	return opener, nil
+}
+
+// FormatWait rounds a wait for display, such as "3h 20m" or "45s".
+func FormatWait(d time.Duration) string {
+	switch {
+	case d >= time.Hour:
+		d = d.Round(time.Minute)
+		return fmt.Sprintf("%dh %dm", int(d.Hours()), int(d.Minutes())%60)
+	case d >= time.Minute:
+		d = d.Round(time.Second)
+		return fmt.Sprintf("%dm %ds", int(d.Minutes()), int(d.Seconds())%60)
+	default:
+		return fmt.Sprintf("%ds", int(d.Round(time.Second).Seconds()))
+	}
+}
@@ -0,0 +1,91 @@
+package recovery
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/eljojo/rememory/internal/core"
+)
+
+// IsVolume reports whether path is one of the volumes MANIFEST.age was
+// split into, like MANIFEST.age.001, or their index, MANIFEST.age.sha256.
+func IsVolume(path string) bool {
+	if strings.HasSuffix(path, core.VolumeIndexSuffix) {
+		return true
+	}
+	_, _, ok := core.ParseVolumeName(path)
+	return ok
+}
+
+// FindVolumes returns the volume found first in dir, whose ReadVolumes
+// reads them all; empty when dir holds none.
+func FindVolumes(dir, base string) string {
+	index := filepath.Join(dir, base+core.VolumeIndexSuffix)
+	if _, err := os.Stat(index); err == nil {
+		return index
+	}
+	matches, _ := filepath.Glob(filepath.Join(dir, base+".[0-9][0-9][0-9]*"))
+	for _, m := range matches {
+		if IsVolume(m) {
+			return m
+		}
+	}
+	return ""
+}
+
+// ReadVolumes puts a split MANIFEST.age back together from the volumes in
+// the folder of path, which names one of them or their index. With the
+// index there, every volume is checked against it. It returns the number
+// of volumes too.
+func ReadVolumes(path string) ([]byte, int, error) {
+	parts, index, err := readVolumeParts(path)
+	if err != nil {
+		return nil, 0, err
+	}
+	data, err := core.JoinVolumes(parts, index)
+	if err != nil {
+		return nil, 0, err
+	}
+	return data, len(parts), nil
+}
+
+// readVolumeParts reads the volumes in the folder of path, and their index
+// when it's there.
+func readVolumeParts(path string) ([]core.VolumePart, *core.VolumeIndex, error) {
+	dir := filepath.Dir(path)
+	base, _, ok := core.ParseVolumeName(path)
+	if !ok {
+		base = strings.TrimSuffix(filepath.Base(path), core.VolumeIndexSuffix)
+	}
+
+	var index *core.VolumeIndex
+	if data, err := os.ReadFile(filepath.Join(dir, base+core.VolumeIndexSuffix)); err == nil {
+		if index, err = core.ParseVolumeIndex(data); err != nil {
+			return nil, nil, fmt.Errorf("%s%s: %w", base, core.VolumeIndexSuffix, err)
+		}
+	} else if !os.IsNotExist(err) {
+		return nil, nil, fmt.Errorf("reading volume index: %w", err)
+	}
+
+	matches, err := filepath.Glob(filepath.Join(dir, base+".[0-9]*"))
+	if err != nil {
+		return nil, nil, err
+	}
+	var parts []core.VolumePart
+	for _, m := range matches {
+		if name, _, ok := core.ParseVolumeName(m); !ok || name != base {
+			continue
+		}
+		data, err := os.ReadFile(m)
+		if err != nil {
+			return nil, nil, fmt.Errorf("reading %s: %w", filepath.Base(m), err)
+		}
+		parts = append(parts, core.VolumePart{Name: filepath.Base(m), Data: data})
+	}
+	if len(parts) == 0 {
+		return nil, nil, fmt.Errorf("no volumes of %s in %s", base, dir)
+	}
+	return parts, index, nil
+}
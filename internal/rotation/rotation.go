@@ -0,0 +1,269 @@
+// Package rotation records which earlier seals of a project the current
+// bundles replace. When a project is sealed again, its bundles carry
+// SUPERSEDED.txt: a note listing the fingerprints of the earlier seals, so
+// a friend who finds an old envelope can tell it is out of date and whom to
+// ask for the current one.
+//
+// The note is signed with an Ed25519 key derived from the new passphrase.
+// Anyone can check that the note is intact; once the passphrase is
+// recovered, anyone can also check that the key belongs to that seal, and
+// so that the note came from whoever made it.
+package rotation
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/eljojo/rememory/internal/core"
+)
+
+const (
+	// FileName is the note's name inside a bundle.
+	FileName = "SUPERSEDED.txt"
+
+	noteHeader   = "rememory-rotation-v1"
+	footerMarker = "SIGNED NOTE (machine-parseable)"
+
+	// fingerprintLength is how many hex digits of the manifest checksum
+	// identify a seal: enough to tell seals apart, few enough to compare by eye.
+	fingerprintLength = 16
+)
+
+// Generation identifies one seal of a project.
+type Generation struct {
+	Sealed      time.Time `yaml:"sealed"`
+	Fingerprint string    `yaml:"fingerprint"`
+}
+
+// Note is the signed list of seals a set of bundles replaces.
+type Note struct {
+	Project     string       `yaml:"project"`
+	Sealed      time.Time    `yaml:"sealed"`
+	Fingerprint string       `yaml:"fingerprint"`       // The seal this note belongs to
+	Contact     string       `yaml:"contact,omitempty"` // How to reach the owner for the current bundle
+	Superseded  []Generation `yaml:"superseded"`
+	PublicKey   string       `yaml:"public_key"` // Base64 Ed25519 key derived from the passphrase
+	Signature   string       `yaml:"signature"`  // Base64 signature over the fields above
+}
+
+// Fingerprint returns the short identifier of the seal whose MANIFEST.age
+// has the given checksum: the first hex digits after "sha256:", as printed
+// in every bundle's README metadata.
+func Fingerprint(manifestChecksum string) string {
+	hex := strings.TrimPrefix(manifestChecksum, "sha256:")
+	if len(hex) > fingerprintLength {
+		hex = hex[:fingerprintLength]
+	}
+	return hex
+}
+
+// Record adds a generation to the list, unless one with the same
+// fingerprint is already there.
+func Record(list []Generation, g Generation) []Generation {
+	for _, existing := range list {
+		if existing.Fingerprint == g.Fingerprint {
+			return list
+		}
+	}
+	return append(list, g)
+}
+
+// Sign makes the note for a new seal, signed with the key derived from its
+// passphrase.
+func Sign(project string, sealed time.Time, manifestChecksum, contact string, superseded []Generation, passphrase []byte) (*Note, error) {
+	for _, field := range []string{project, contact} {
+		if strings.ContainsAny(field, "\r\n") {
+			return nil, fmt.Errorf("project name and contact must fit on one line for the rotation note")
+		}
+	}
+	n := &Note{
+		Project:     project,
+		Sealed:      sealed.UTC(),
+		Fingerprint: Fingerprint(manifestChecksum),
+		Contact:     contact,
+		Superseded:  superseded,
+	}
+	priv := signingKey(passphrase)
+	n.PublicKey = base64.StdEncoding.EncodeToString(priv.Public().(ed25519.PublicKey))
+	n.Signature = base64.StdEncoding.EncodeToString(ed25519.Sign(priv, n.message()))
+	return n, nil
+}
+
+// Verify checks that the note is intact.
+func (n *Note) Verify() error {
+	pub, err := base64.StdEncoding.DecodeString(n.PublicKey)
+	if err != nil || len(pub) != ed25519.PublicKeySize {
+		return fmt.Errorf("invalid public key in %s", FileName)
+	}
+	sig, err := base64.StdEncoding.DecodeString(n.Signature)
+	if err != nil || len(sig) != ed25519.SignatureSize {
+		return fmt.Errorf("invalid signature in %s", FileName)
+	}
+	if !ed25519.Verify(pub, n.message(), sig) {
+		return fmt.Errorf("%s signature does not match — the note was changed or damaged", FileName)
+	}
+	return nil
+}
+
+// SignedBy reports whether the note's key is the one derived from passphrase,
+// that is, whether it was made by whoever made the seal the passphrase opens.
+func (n *Note) SignedBy(passphrase []byte) bool {
+	pub := signingKey(passphrase).Public().(ed25519.PublicKey)
+	return n.PublicKey == base64.StdEncoding.EncodeToString(pub)
+}
+
+// Supersedes returns the generation in the note that the bundle with the
+// given manifest checksum belongs to, if any.
+func (n *Note) Supersedes(manifestChecksum string) (Generation, bool) {
+	fingerprint := Fingerprint(manifestChecksum)
+	for _, g := range n.Superseded {
+		if g.Fingerprint == fingerprint {
+			return g, true
+		}
+	}
+	return Generation{}, false
+}
+
+// SupersededDates lists when the superseded seals were made, for the line in
+// the README that points to the note.
+func (n *Note) SupersededDates() string {
+	dates := make([]string, len(n.Superseded))
+	for i, g := range n.Superseded {
+		dates[i] = g.Sealed.Format(core.DateFormat)
+	}
+	return strings.Join(dates, ", ")
+}
+
+// signingKey derives the note's signing key from the passphrase. The
+// passphrase is 256 random bits, so a single hash is enough.
+func signingKey(passphrase []byte) ed25519.PrivateKey {
+	h := sha256.New()
+	h.Write([]byte(noteHeader + "\n"))
+	h.Write(passphrase)
+	seed := h.Sum(nil)
+	defer core.Wipe(seed)
+	return ed25519.NewKeyFromSeed(seed)
+}
+
+// message is what the signature covers.
+func (n *Note) message() []byte {
+	var sb strings.Builder
+	sb.WriteString(noteHeader + "\n")
+	writeFields(&sb, n)
+	return []byte(sb.String())
+}
+
+func writeFields(sb *strings.Builder, n *Note) {
+	sb.WriteString(fmt.Sprintf("project: %s\n", n.Project))
+	sb.WriteString(fmt.Sprintf("sealed: %s\n", n.Sealed.UTC().Format(time.RFC3339)))
+	sb.WriteString(fmt.Sprintf("fingerprint: %s\n", n.Fingerprint))
+	if n.Contact != "" {
+		sb.WriteString(fmt.Sprintf("contact: %s\n", n.Contact))
+	}
+	for _, g := range n.Superseded {
+		sb.WriteString(fmt.Sprintf("superseded: %s %s\n", g.Sealed.UTC().Format(time.RFC3339), g.Fingerprint))
+	}
+}
+
+// Text returns SUPERSEDED.txt: an explanation for whoever finds it, followed
+// by the signed fields. Like SLIP39.txt, it is in English only.
+func (n *Note) Text() string {
+	var sb strings.Builder
+
+	sb.WriteString(fmt.Sprintf("SUPERSEDED BUNDLES FOR %s\n", strings.ToUpper(n.Project)))
+	sb.WriteString(strings.Repeat("=", 80) + "\n\n")
+	sb.WriteString(fmt.Sprintf("This bundle for %q was sealed on %s. It replaces every\n", n.Project, n.Sealed.Format(core.DateFormat)))
+	sb.WriteString("earlier bundle listed below. If you come across one of those, whether an\n")
+	sb.WriteString("old envelope, a printout, or a file, it is out of date: don't use it for\n")
+	sb.WriteString("recovery, and destroy it as carefully as you would this one.\n\n")
+
+	sb.WriteString("To tell which seal a bundle belongs to, look at checksum-manifest at the\n")
+	sb.WriteString("end of its README. The fingerprint is how it starts, after \"sha256:\".\n\n")
+	sb.WriteString("  SEALED       FINGERPRINT\n")
+	for _, g := range n.Superseded {
+		sb.WriteString(fmt.Sprintf("  %s   %s\n", g.Sealed.Format(core.DateFormat), g.Fingerprint))
+	}
+	sb.WriteString(fmt.Sprintf("\nThis bundle:  %s   %s\n\n", n.Sealed.Format(core.DateFormat), n.Fingerprint))
+
+	if n.Contact != "" {
+		sb.WriteString(fmt.Sprintf("For the current bundle, contact: %s\n\n", n.Contact))
+	} else {
+		sb.WriteString("For the current bundle, ask whoever gave you this one.\n\n")
+	}
+
+	sb.WriteString("The note is signed with a key only this bundle's passphrase can rebuild.\n")
+	sb.WriteString("'rememory inspect' checks the signature, and 'rememory recover' checks\n")
+	sb.WriteString("the key once the passphrase is recovered.\n\n")
+
+	sb.WriteString(strings.Repeat("=", 80) + "\n")
+	sb.WriteString(footerMarker + "\n")
+	sb.WriteString(strings.Repeat("=", 80) + "\n")
+	sb.WriteString(noteHeader + "\n")
+	writeFields(&sb, n)
+	sb.WriteString(fmt.Sprintf("public-key: %s\n", n.PublicKey))
+	sb.WriteString(fmt.Sprintf("signature: %s\n", n.Signature))
+	sb.WriteString(strings.Repeat("=", 80) + "\n")
+	return sb.String()
+}
+
+var fieldRegex = regexp.MustCompile(`^([a-z-]+):\s*(.*)$`)
+
+// Parse reads the signed fields of SUPERSEDED.txt and verifies the signature.
+func Parse(text string) (*Note, error) {
+	start := strings.Index(text, footerMarker)
+	if start == -1 {
+		return nil, fmt.Errorf("%s has no signed note", FileName)
+	}
+	lines := strings.Split(strings.ReplaceAll(text[start:], "\r\n", "\n"), "\n")
+
+	n := &Note{}
+	header := false
+	for _, line := range lines[1:] {
+		line = strings.TrimSpace(line)
+		if line == noteHeader {
+			header = true
+			continue
+		}
+		m := fieldRegex.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		var err error
+		switch m[1] {
+		case "project":
+			n.Project = m[2]
+		case "sealed":
+			n.Sealed, err = time.Parse(time.RFC3339, m[2])
+		case "fingerprint":
+			n.Fingerprint = m[2]
+		case "contact":
+			n.Contact = m[2]
+		case "superseded":
+			date, fingerprint, ok := strings.Cut(m[2], " ")
+			g := Generation{Fingerprint: fingerprint}
+			if g.Sealed, err = time.Parse(time.RFC3339, date); err == nil && !ok {
+				err = fmt.Errorf("missing fingerprint")
+			}
+			n.Superseded = append(n.Superseded, g)
+		case "public-key":
+			n.PublicKey = m[2]
+		case "signature":
+			n.Signature = m[2]
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s line in %s: %w", m[1], FileName, err)
+		}
+	}
+	if !header {
+		return nil, fmt.Errorf("unrecognized note in %s", FileName)
+	}
+	if err := n.Verify(); err != nil {
+		return nil, err
+	}
+	return n, nil
+}
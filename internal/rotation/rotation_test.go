@@ -0,0 +1,86 @@
+package rotation
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+const (
+	oldChecksum = "sha256:3f9a12c0b7e455d1aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"
+	newChecksum = "sha256:ab12cd34ef560789bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb"
+)
+
+func testNote(t *testing.T, passphrase string) *Note {
+	t.Helper()
+	superseded := Record(nil, Generation{Sealed: time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC), Fingerprint: Fingerprint(oldChecksum)})
+	n, err := Sign("Family", time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC), newChecksum, "Ana, +1 555 0100", superseded, []byte(passphrase))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return n
+}
+
+func TestNoteRoundTrip(t *testing.T) {
+	n := testNote(t, "passphrase-one")
+	text := n.Text()
+	for _, want := range []string{"2025-03-01   3f9a12c0b7e455d1", "This bundle:  2026-01-02   ab12cd34ef560789", "contact: Ana, +1 555 0100"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("text is missing %q", want)
+		}
+	}
+
+	got, err := Parse(text)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Project != "Family" || got.Contact != n.Contact || got.Fingerprint != "ab12cd34ef560789" || len(got.Superseded) != 1 || !got.Sealed.Equal(n.Sealed) {
+		t.Errorf("got %+v", got)
+	}
+	if g, ok := got.Supersedes(oldChecksum); !ok || !g.Sealed.Equal(n.Superseded[0].Sealed) {
+		t.Errorf("Supersedes(old) = %v, %v", g, ok)
+	}
+	if _, ok := got.Supersedes(newChecksum); ok {
+		t.Error("note supersedes its own seal")
+	}
+
+	if !got.SignedBy([]byte("passphrase-one")) {
+		t.Error("SignedBy(passphrase) = false")
+	}
+	if got.SignedBy([]byte("passphrase-two")) {
+		t.Error("SignedBy(other passphrase) = true")
+	}
+}
+
+func TestParseRejectsTampering(t *testing.T) {
+	text := testNote(t, "passphrase-one").Text()
+	for _, edit := range [][2]string{
+		{"superseded: 2025-03-01T10:00:00Z 3f9a12c0b7e455d1", "superseded: 2025-03-01T10:00:00Z 0000000000000000"},
+		{"contact: Ana, +1 555 0100", "contact: Mallory"},
+		{"rememory-rotation-v1\n", ""},
+	} {
+		if !strings.Contains(text, edit[0]) {
+			t.Fatalf("text is missing %q", edit[0])
+		}
+		i := strings.LastIndex(text, edit[0])
+		if _, err := Parse(text[:i] + edit[1] + text[i+len(edit[0]):]); err == nil {
+			t.Errorf("edit %q accepted", edit[0])
+		}
+	}
+
+	// The explanation above the signed fields isn't covered
+	if _, err := Parse(strings.Replace(text, "out of date", "obsolete", 1)); err != nil {
+		t.Errorf("explanation edit: %v", err)
+	}
+}
+
+func TestRecordSkipsDuplicates(t *testing.T) {
+	g := Generation{Sealed: time.Now(), Fingerprint: Fingerprint(oldChecksum)}
+	list := Record(Record(nil, g), g)
+	if len(list) != 1 {
+		t.Errorf("got %d generations", len(list))
+	}
+	if _, err := Sign("Family\nEvil", time.Now(), newChecksum, "", list, []byte("p")); err == nil {
+		t.Error("newline in project name accepted")
+	}
+}
@@ -0,0 +1,191 @@
+// Package seed holds a cryptocurrency wallet's seed phrase, sealed as
+// seed-phrase.json. A wrong word in a seed phrase can't be fixed later, and
+// whoever reads it can empty the wallet, so the phrase is checked against
+// the BIP39 word lists and their checksum before it's sealed, and
+// recover.html shows it numbered, hidden until asked, with a warning never
+// to type it into a connected computer.
+package seed
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/eljojo/rememory/internal/core"
+)
+
+// FileName is the name the seed phrase is sealed under, inside manifest/.
+const FileName = "seed-phrase.json"
+
+// Format marks a sealed seed phrase, so recover.html can tell it from any
+// other JSON file.
+const Format = "rememory-seed"
+
+// Version is the version of the seed format this package writes.
+const Version = 1
+
+// MinWords is the shortest phrase accepted; no wallet uses fewer words.
+const MinWords = 12
+
+// bip39Langs are the word lists that are part of BIP39 itself. ReMemory's
+// German and Slovenian lists are for its own recovery words; no wallet
+// writes them.
+var bip39Langs = []core.Lang{core.LangEN, core.LangES, core.LangFR, core.LangPT, core.LangZH_TW, core.LangZH_CN, core.LangJA}
+
+// bip39Names names each list as wallets do.
+var bip39Names = map[core.Lang]string{
+	core.LangEN:    "English",
+	core.LangES:    "Spanish",
+	core.LangFR:    "French",
+	core.LangPT:    "Portuguese",
+	core.LangZH_TW: "Chinese (Traditional)",
+	core.LangZH_CN: "Chinese (Simplified)",
+	core.LangJA:    "Japanese",
+}
+
+// Phrase is a sealed seed phrase.
+type Phrase struct {
+	Format   string   `json:"format"`
+	Version  int      `json:"version"`
+	Label    string   `json:"label"`              // Which wallet the phrase belongs to
+	Words    []string `json:"words"`              // In order, as typed
+	Wordlist string   `json:"wordlist,omitempty"` // The BIP39 list the words are from, when checked
+}
+
+// Checked reports whether the phrase passed the BIP39 checks.
+func (p *Phrase) Checked() bool {
+	return p.Wordlist != ""
+}
+
+// Split reads the words of a phrase as typed or pasted: separated by spaces,
+// commas, or new lines, in any case, with numbering such as "1." or "2)"
+// dropped.
+func Split(text string) []string {
+	fields := strings.FieldsFunc(text, func(r rune) bool {
+		return unicode.IsSpace(r) || r == ',' || r == '　'
+	})
+	var words []string
+	for _, f := range fields {
+		f = strings.TrimRight(f, ".):")
+		if f == "" || strings.IndexFunc(f, func(r rune) bool { return !unicode.IsDigit(r) }) < 0 {
+			continue
+		}
+		words = append(words, strings.ToLower(f))
+	}
+	return words
+}
+
+// New checks a phrase and returns it ready to seal. A phrase whose words
+// mostly come from a BIP39 list must be a valid BIP39 phrase: every word in
+// the list, a length of 12 to 24 words, and a matching checksum. Wallets
+// that don't follow BIP39, like Electrum, need unchecked set; their phrases
+// are then only checked for length.
+func New(label, text string, unchecked bool) (*Phrase, error) {
+	label = strings.TrimSpace(label)
+	if label == "" {
+		return nil, fmt.Errorf("name the wallet the seed phrase belongs to")
+	}
+	words := Split(text)
+	if len(words) < MinWords {
+		return nil, fmt.Errorf("a seed phrase has at least %d words; got %d", MinWords, len(words))
+	}
+
+	p := &Phrase{Format: Format, Version: Version, Label: label, Words: words}
+	if unchecked {
+		return p, nil
+	}
+	lang, err := CheckBIP39(words)
+	if err != nil {
+		return nil, err
+	}
+	p.Wordlist = "BIP39 " + bip39Names[lang]
+	return p, nil
+}
+
+// CheckBIP39 checks words as a BIP39 phrase and returns its word list. Errors
+// name the word at fault by its position, never by itself, unless it's
+// misspelled.
+func CheckBIP39(words []string) (core.Lang, error) {
+	lang := detect(words)
+	if lang == "" {
+		return "", fmt.Errorf("these words aren't from a BIP39 word list; if your wallet uses its own words, add --seed-unchecked")
+	}
+	indices := make([]int, len(words))
+	for i, w := range words {
+		idx, ok := core.LookupWord(lang, w)
+		if !ok {
+			if s := core.SuggestWordLang(w, lang); s != "" {
+				return "", fmt.Errorf("word %d %q isn't in the BIP39 %s list — did you mean %q?", i+1, w, bip39Names[lang], s)
+			}
+			return "", fmt.Errorf("word %d %q isn't in the BIP39 %s list", i+1, w, bip39Names[lang])
+		}
+		indices[i] = idx
+	}
+	if n := len(words); n%3 != 0 || n > 24 {
+		return "", fmt.Errorf("a BIP39 phrase has 12, 15, 18, 21, or 24 words; got %d (add --seed-unchecked if your wallet uses another length)", n)
+	}
+	if !validChecksum(indices) {
+		return "", fmt.Errorf("the BIP39 checksum doesn't match: a word is wrong or out of order (Electrum and some other wallets don't use this checksum; for those, add --seed-unchecked)")
+	}
+	return lang, nil
+}
+
+// detect returns the BIP39 list holding most of words, if any holds more
+// than half.
+func detect(words []string) core.Lang {
+	var best core.Lang
+	bestCount := len(words) / 2
+	for _, lang := range bip39Langs {
+		count := 0
+		for _, w := range words {
+			if _, ok := core.LookupWord(lang, w); ok {
+				count++
+			}
+		}
+		if count > bestCount {
+			best, bestCount = lang, count
+		}
+	}
+	return best
+}
+
+// validChecksum checks BIP39's checksum: of the 11 bits per word, the last
+// one bit in 33 are the first bits of the SHA-256 of the rest.
+func validChecksum(indices []int) bool {
+	totalBits := len(indices) * 11
+	checksumBits := totalBits / 33
+	entropy := make([]byte, (totalBits-checksumBits)/8)
+	bit := func(i int) int { return (indices[i/11] >> (10 - i%11)) & 1 }
+	for i := range len(entropy) * 8 {
+		entropy[i/8] |= byte(bit(i) << (7 - i%8))
+	}
+	hash := sha256.Sum256(entropy)
+	for i := range checksumBits {
+		if bit(len(entropy)*8+i) != int(hash[i/8]>>(7-i%8))&1 {
+			return false
+		}
+	}
+	return true
+}
+
+// Differs returns the position, from 1, of the first word where a and b
+// differ, or 0 when they're the same phrase.
+func Differs(a, b []string) int {
+	for i := range max(len(a), len(b)) {
+		if i >= len(a) || i >= len(b) || a[i] != b[i] {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// Marshal writes p as indented JSON.
+func (p *Phrase) Marshal() ([]byte, error) {
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
@@ -0,0 +1,117 @@
+package seed
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/eljojo/rememory/internal/core"
+)
+
+// Test vectors from the BIP39 reference implementation (vectors.json).
+const (
+	abandon12 = "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about"
+	legal24   = "legal winner thank year wave sausage worth useful legal winner thank year wave sausage worth useful legal winner thank year wave sausage worth title"
+	zoo18     = "zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo when"
+)
+
+func TestSplit(t *testing.T) {
+	got := Split(" 1. Abandon, 2) abandon\n3: about\t ")
+	want := []string{"abandon", "abandon", "about"}
+	if strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("Split = %q, want %q", got, want)
+	}
+}
+
+func TestNew(t *testing.T) {
+	for _, phrase := range []string{abandon12, legal24, zoo18} {
+		p, err := New("Hardware wallet", phrase, false)
+		if err != nil {
+			t.Fatalf("%s: %v", phrase, err)
+		}
+		if !p.Checked() || p.Wordlist != "BIP39 English" || strings.Join(p.Words, " ") != phrase {
+			t.Errorf("New = %+v", p)
+		}
+	}
+
+	tests := []struct {
+		name, label, phrase, wantErr string
+	}{
+		{"no label", " ", abandon12, "name the wallet"},
+		{"too short", "w", "abandon about", "at least 12 words"},
+		{"bad checksum", "w", strings.Replace(abandon12, "about", "abandon", 1), "checksum doesn't match"},
+		{"swapped words", "w", "legal winner thank year wave sausage worth useful legal winner thank year wave sausage worth useful legal winner thank year wave sausage title worth", "checksum doesn't match"},
+		{"typo", "w", strings.Replace(abandon12, "about", "abuot", 1), `word 12 "abuot" isn't in the BIP39 English list — did you mean "about"?`},
+		{"odd length", "w", abandon12 + " abandon", "12, 15, 18, 21, or 24 words"},
+		{"not bip39", "w", "correct horse battery staple tiger lamp river ocean xylophonic quartzite marmalade zebraic", "aren't from a BIP39 word list"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New(tt.label, tt.phrase, false)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("err = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+
+	// Unchecked phrases skip the BIP39 checks, but not the length
+	p, err := New("Electrum", "correct horse battery staple tiger lamp river ocean xylophonic quartzite marmalade zebraic", true)
+	if err != nil || p.Checked() {
+		t.Errorf("unchecked: %+v, %v", p, err)
+	}
+	if _, err := New("Electrum", "correct horse", true); err == nil {
+		t.Error("expected a short unchecked phrase to be refused")
+	}
+}
+
+func TestCheckBIP39Spanish(t *testing.T) {
+	// The Spanish list's first word repeated, ending in the word that makes
+	// the checksum match, like the English "abandon ... about"
+	words := Split("ábaco ábaco ábaco ábaco ábaco ábaco ábaco ábaco ábaco ábaco ábaco abierto")
+	if _, err := CheckBIP39(words); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCheckBIP39Japanese(t *testing.T) {
+	// Japanese phrases are written with ideographic spaces
+	words := Split(strings.Repeat("あいこくしん　", 11) + "あおぞら")
+	lang, err := CheckBIP39(words)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lang != core.LangJA {
+		t.Errorf("detected %q, want ja", lang)
+	}
+}
+
+func TestDiffers(t *testing.T) {
+	a := Split(abandon12)
+	if n := Differs(a, Split(abandon12)); n != 0 {
+		t.Errorf("same phrase: %d", n)
+	}
+	if n := Differs(a, Split(strings.Replace(abandon12, "about", "abandon", 1))); n != 12 {
+		t.Errorf("last word: %d", n)
+	}
+	if n := Differs(a, a[:11]); n != 12 {
+		t.Errorf("shorter: %d", n)
+	}
+}
+
+func TestMarshal(t *testing.T) {
+	p, err := New("Hardware wallet", abandon12, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := p.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var back Phrase
+	if err := json.Unmarshal(data, &back); err != nil {
+		t.Fatal(err)
+	}
+	if back.Format != Format || back.Version != Version || back.Label != "Hardware wallet" || len(back.Words) != 12 {
+		t.Errorf("round trip = %+v", back)
+	}
+}
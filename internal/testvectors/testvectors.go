@@ -0,0 +1,396 @@
+// Package testvectors generates known-answer tests for independent
+// implementations of ReMemory recovery (mobile apps, audits, ports to other
+// languages). Every value is produced and then checked by the same code in
+// internal/core that the CLI and recover.html use.
+package testvectors
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/eljojo/rememory/internal/core"
+)
+
+// Format identifies the JSON document; bump FormatVersion on breaking changes.
+const (
+	Format        = "rememory-test-vectors"
+	FormatVersion = 1
+)
+
+// Created is the timestamp stamped on every vector share.
+var Created = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// Vectors is the document written by 'rememory testvectors'.
+type Vectors struct {
+	Format        string    `json:"format"`
+	FormatVersion int       `json:"formatVersion"`
+	Generator     string    `json:"generator"`
+	Notes         []string  `json:"notes"`
+	Cases         []Case    `json:"cases"`
+	Invalid       []Invalid `json:"invalid"`
+}
+
+// Case is one sealed secret: its shares in every encoding and the manifest
+// they open.
+type Case struct {
+	Name       string  `json:"name"`
+	Threshold  int     `json:"threshold"`
+	Total      int     `json:"total"`
+	SecretHex  string  `json:"secretHex"`  // The 32 raw bytes that are split
+	Passphrase string  `json:"passphrase"` // base64url (no padding) of the secret: the age passphrase
+	Shares     []Share `json:"shares"`
+
+	// Combinations lists share indices that must each reconstruct Passphrase.
+	Combinations [][]int `json:"combinations"`
+
+	Manifest Manifest `json:"manifest"`
+}
+
+// Share is one piece in all of its encodings.
+type Share struct {
+	Index    int               `json:"index"`
+	Holder   string            `json:"holder"`
+	DataHex  string            `json:"dataHex"` // Raw Shamir share: 32 bytes of y-values plus the x-coordinate
+	Checksum string            `json:"checksum"`
+	PEM      string            `json:"pem"`
+	PEMWorn  string            `json:"pemWorn"` // PEM with a misread and an unreadable character on every data line
+	Compact  string            `json:"compact"`
+	Words    map[string]string `json:"words"` // Language code or EFF list → space-separated words
+}
+
+// Manifest is the age-encrypted tar.gz the passphrase opens.
+type Manifest struct {
+	AgeBase64     string            `json:"ageBase64"`     // MANIFEST.age
+	ArchiveSHA256 string            `json:"archiveSha256"` // SHA-256 of the decrypted tar.gz
+	Files         map[string]string `json:"files"`         // Path inside the archive → contents
+}
+
+// Invalid is an input a correct implementation must reject.
+type Invalid struct {
+	Name     string `json:"name"`
+	Encoding string `json:"encoding"` // pem, compact, or words
+	Input    string `json:"input"`
+	Reason   string `json:"reason"`
+}
+
+var holders = []string{"Alice", "Bob", "Carol", "David", "Eve"}
+
+var manifestFiles = map[string]string{
+	"manifest/README.md":  "# Test vector manifest\n",
+	"manifest/secret.txt": "The secret passphrase is: correct-horse-battery-staple\n",
+}
+
+// Generate builds the vectors. Shares and ciphertexts use fresh randomness
+// on every run, so two files differ, but each one is self-consistent.
+func Generate(generator string) (*Vectors, error) {
+	v := &Vectors{
+		Format:        Format,
+		FormatVersion: FormatVersion,
+		Generator:     generator,
+		Notes: []string{
+			"Each case's shares, in any encoding, must reconstruct passphrase for every listed combination.",
+			"The passphrase is the base64url encoding (no padding) of the combined 32 bytes; it is an age scrypt passphrase.",
+			"Decrypting manifest.ageBase64 yields a tar.gz whose SHA-256 is archiveSha256 and which contains exactly manifest.files.",
+			"Shamir arithmetic is GF(2^8) as in HashiCorp Vault's shamir package; the last byte of each share is its x-coordinate.",
+			"PEM shares with 'Encoding: base32-rs' print their data as RFC 4648 base32 lines of up to 20 characters, each followed by 4 Reed-Solomon parity characters over GF(32) (x^5 + x^2 + 1, generator roots α^0..α^3). Spaces are ignored; pemWorn must parse to the same data as pem.",
+			"Every entry in invalid must be rejected.",
+		},
+	}
+
+	for _, tc := range []struct{ threshold, total int }{{2, 2}, {2, 3}, {3, 5}} {
+		c, err := generateCase(tc.threshold, tc.total)
+		if err != nil {
+			return nil, err
+		}
+		v.Cases = append(v.Cases, *c)
+	}
+
+	invalid, err := generateInvalid(v.Cases[1])
+	if err != nil {
+		return nil, err
+	}
+	v.Invalid = invalid
+	return v, nil
+}
+
+func generateCase(threshold, total int) (*Case, error) {
+	name := fmt.Sprintf("%d-of-%d", threshold, total)
+	secret := sha256.Sum256([]byte("rememory test vector " + name))
+	passphrase := base64.RawURLEncoding.EncodeToString(secret[:])
+
+	parts, err := core.Split(secret[:], total, threshold)
+	if err != nil {
+		return nil, fmt.Errorf("%s: splitting: %w", name, err)
+	}
+
+	c := &Case{
+		Name:       name,
+		Threshold:  threshold,
+		Total:      total,
+		SecretHex:  hex.EncodeToString(secret[:]),
+		Passphrase: passphrase,
+	}
+	for i, data := range parts {
+		share := core.NewShare(2, i+1, total, threshold, holders[i], data)
+		share.Created = Created
+		vs, err := encodeShare(share)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		c.Shares = append(c.Shares, *vs)
+	}
+
+	c.Combinations = combinations(total, threshold)
+	for _, combo := range c.Combinations {
+		if err := checkCombination(c, combo); err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+	}
+
+	c.Manifest, err = encryptManifest(passphrase)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return c, nil
+}
+
+// encodeShare renders a share in every encoding and checks each decodes back.
+func encodeShare(s *core.Share) (*Share, error) {
+	vs := &Share{
+		Index:    s.Index,
+		Holder:   s.Holder,
+		DataHex:  hex.EncodeToString(s.Data),
+		Checksum: s.Checksum,
+		PEM:      s.Encode(),
+		PEMWorn:  wearPEM(s.Encode()),
+		Compact:  s.CompactEncode(),
+		Words:    make(map[string]string),
+	}
+
+	if parsed, err := core.ParseShare([]byte(vs.PEM)); err != nil || !bytes.Equal(parsed.Data, s.Data) {
+		return nil, fmt.Errorf("share %d: PEM doesn't round-trip", s.Index)
+	}
+	if parsed, err := core.ParseShare([]byte(vs.PEMWorn)); err != nil || !bytes.Equal(parsed.Data, s.Data) {
+		return nil, fmt.Errorf("share %d: worn PEM isn't repaired", s.Index)
+	}
+	if parsed, err := core.ParseCompact(vs.Compact); err != nil || !bytes.Equal(parsed.Data, s.Data) {
+		return nil, fmt.Errorf("share %d: compact encoding doesn't round-trip", s.Index)
+	}
+	for _, lang := range core.AllWordLists() {
+		words, err := s.WordsForLang(lang)
+		if err != nil {
+			return nil, fmt.Errorf("share %d: %w", s.Index, err)
+		}
+		data, index, err := core.DecodeShareWords(words)
+		if err != nil || !bytes.Equal(data, s.Data) || index != s.Index {
+			return nil, fmt.Errorf("share %d: %s words don't round-trip", s.Index, lang)
+		}
+		vs.Words[string(lang)] = strings.Join(words, " ")
+	}
+	return vs, nil
+}
+
+func checkCombination(c *Case, combo []int) error {
+	data := make([][]byte, len(combo))
+	for i, index := range combo {
+		b, err := hex.DecodeString(c.Shares[index-1].DataHex)
+		if err != nil {
+			return err
+		}
+		data[i] = b
+	}
+	recovered, err := core.Combine(data)
+	if err != nil {
+		return fmt.Errorf("combining %v: %w", combo, err)
+	}
+	if got := core.RecoverPassphrase(recovered, 2); string(got) != c.Passphrase {
+		return fmt.Errorf("combining %v gave the wrong passphrase", combo)
+	}
+	return nil
+}
+
+func encryptManifest(passphrase string) (Manifest, error) {
+	archive, err := tarGz(manifestFiles)
+	if err != nil {
+		return Manifest{}, err
+	}
+
+	var encrypted bytes.Buffer
+	if err := core.Encrypt(&encrypted, bytes.NewReader(archive), passphrase); err != nil {
+		return Manifest{}, err
+	}
+	decrypted, err := core.DecryptBytes(encrypted.Bytes(), passphrase)
+	if err != nil || !bytes.Equal(decrypted, archive) {
+		return Manifest{}, fmt.Errorf("manifest doesn't decrypt back")
+	}
+
+	sum := sha256.Sum256(archive)
+	return Manifest{
+		AgeBase64:     base64.StdEncoding.EncodeToString(encrypted.Bytes()),
+		ArchiveSHA256: hex.EncodeToString(sum[:]),
+		Files:         manifestFiles,
+	}, nil
+}
+
+// tarGz archives files the way 'rememory seal' does: a manifest/ directory
+// entry followed by regular files.
+func tarGz(files map[string]string) ([]byte, error) {
+	paths := make([]string, 0, len(files))
+	for p := range files {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	if err := tw.WriteHeader(&tar.Header{Typeflag: tar.TypeDir, Name: "manifest/", Mode: 0755, ModTime: Created}); err != nil {
+		return nil, err
+	}
+	for _, p := range paths {
+		hdr := &tar.Header{Typeflag: tar.TypeReg, Name: p, Mode: 0644, Size: int64(len(files[p])), ModTime: Created}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return nil, err
+		}
+		if _, err := tw.Write([]byte(files[p])); err != nil {
+			return nil, err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return nil, err
+	}
+	if err := gz.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// generateInvalid corrupts one share from c in each encoding and checks that
+// core rejects the result.
+func generateInvalid(c Case) ([]Invalid, error) {
+	s := c.Shares[0]
+
+	// Misread more characters of the first PEM data line than its parity repairs
+	lines := strings.Split(s.PEM, "\n")
+	for i, line := range lines {
+		if i > 0 && lines[i-1] == "" && line != "" {
+			lines[i] = misread(line, 3)
+			break
+		}
+	}
+	badPEM := strings.Join(lines, "\n")
+
+	compactParts := strings.Split(s.Compact, ":")
+	compactParts[5] = flipChar(compactParts[5])
+	badCompact := strings.Join(compactParts, ":")
+
+	words := strings.Fields(s.Words["en"])
+	words[0], words[1] = words[1], words[0]
+	swappedWords := strings.Join(words, " ")
+
+	invalid := []Invalid{
+		{"pem-data-changed", "pem", badPEM, "more characters changed than the line's parity repairs, and the data no longer matches its checksum"},
+		{"compact-checksum", "compact", badCompact, "short checksum doesn't match the data"},
+		{"words-swapped", "words", swappedWords, "word checksum fails when two words trade places"},
+		{"words-24", "words", strings.Join(words[:24], " "), "a word share has exactly 25 words"},
+	}
+
+	for _, in := range invalid {
+		var err error
+		switch in.Encoding {
+		case "pem":
+			var share *core.Share
+			if share, err = core.ParseShare([]byte(in.Input)); err == nil {
+				err = share.Verify()
+			}
+		case "compact":
+			_, err = core.ParseCompact(in.Input)
+		case "words":
+			_, _, err = core.DecodeShareWords(strings.Fields(in.Input))
+		}
+		if err == nil {
+			return nil, fmt.Errorf("invalid vector %s was accepted", in.Name)
+		}
+	}
+	return invalid, nil
+}
+
+// wearPEM misreads the first character of every data line and makes the
+// last one unreadable, as water damage or bad handwriting might.
+func wearPEM(pem string) string {
+	lines := strings.Split(pem, "\n")
+	inData := false
+	for i, line := range lines {
+		switch {
+		case line == "":
+			inData = true
+		case line == core.ShareEnd:
+			inData = false
+		case inData:
+			lines[i] = misread(line, 1)[:len(line)-1] + "?"
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// misread replaces the first n characters of a printed data line with other
+// characters of the base32 alphabet.
+func misread(line string, n int) string {
+	b := []byte(line)
+	for i := 0; i < len(b) && n > 0; i++ {
+		if b[i] == ' ' {
+			continue
+		}
+		if b[i] == 'A' {
+			b[i] = 'B'
+		} else {
+			b[i] = 'A'
+		}
+		n--
+	}
+	return string(b)
+}
+
+// flipChar replaces the first character of s with a different one from the
+// same alphabet, so the result still parses but no longer matches.
+func flipChar(s string) string {
+	replacement := byte('A')
+	if s[0] == 'A' {
+		replacement = 'B'
+	}
+	if s[0] >= '0' && s[0] <= '9' {
+		replacement = '0'
+		if s[0] == '0' {
+			replacement = '1'
+		}
+	}
+	return string(replacement) + s[1:]
+}
+
+// combinations returns every threshold-sized set of 1-based share indices.
+func combinations(total, threshold int) [][]int {
+	var result [][]int
+	combo := make([]int, 0, threshold)
+	var gen func(start int)
+	gen = func(start int) {
+		if len(combo) == threshold {
+			result = append(result, append([]int(nil), combo...))
+			return
+		}
+		for i := start; i <= total; i++ {
+			combo = append(combo, i)
+			gen(i + 1)
+			combo = combo[:len(combo)-1]
+		}
+	}
+	gen(1)
+	return result
+}
@@ -0,0 +1,105 @@
+package testvectors
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/eljojo/rememory/internal/core"
+)
+
+// TestVectorsRecover follows the vectors the way an outside implementation
+// would: from the JSON alone, through every share encoding, to the files.
+func TestVectorsRecover(t *testing.T) {
+	generated, err := Generate("test")
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	data, err := json.Marshal(generated)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var v Vectors
+	if err := json.Unmarshal(data, &v); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, c := range v.Cases {
+		t.Run(c.Name, func(t *testing.T) {
+			byEncoding := map[string]func(Share) []byte{
+				"pem": func(s Share) []byte {
+					share, err := core.ParseShare([]byte(s.PEM))
+					if err != nil {
+						t.Fatalf("share %d PEM: %v", s.Index, err)
+					}
+					return share.Data
+				},
+				"pem-worn": func(s Share) []byte {
+					share, err := core.ParseShare([]byte(s.PEMWorn))
+					if err != nil {
+						t.Fatalf("share %d worn PEM: %v", s.Index, err)
+					}
+					return share.Data
+				},
+				"compact": func(s Share) []byte {
+					share, err := core.ParseCompact(s.Compact)
+					if err != nil {
+						t.Fatalf("share %d compact: %v", s.Index, err)
+					}
+					return share.Data
+				},
+				"words-zh-TW": func(s Share) []byte {
+					data, _, err := core.DecodeShareWords(strings.Fields(s.Words["zh-TW"]))
+					if err != nil {
+						t.Fatalf("share %d words: %v", s.Index, err)
+					}
+					return data
+				},
+			}
+			for encoding, decode := range byEncoding {
+				for _, combo := range c.Combinations {
+					parts := make([][]byte, len(combo))
+					for i, index := range combo {
+						parts[i] = decode(c.Shares[index-1])
+					}
+					recovered, err := core.Combine(parts)
+					if err != nil {
+						t.Fatalf("%s %v: %v", encoding, combo, err)
+					}
+					if got := core.RecoverPassphrase(recovered, 2); string(got) != c.Passphrase {
+						t.Errorf("%s %v: passphrase = %q, want %q", encoding, combo, got, c.Passphrase)
+					}
+				}
+			}
+
+			encrypted, err := base64.StdEncoding.DecodeString(c.Manifest.AgeBase64)
+			if err != nil {
+				t.Fatal(err)
+			}
+			archive, err := core.DecryptBytes(encrypted, c.Passphrase)
+			if err != nil {
+				t.Fatalf("decrypting manifest: %v", err)
+			}
+			if got := core.HashBytes(archive); got != "sha256:"+c.Manifest.ArchiveSHA256 {
+				t.Errorf("archive hash = %s, want %s", got, c.Manifest.ArchiveSHA256)
+			}
+			files, err := core.ExtractTarGz(archive)
+			if err != nil {
+				t.Fatalf("extracting: %v", err)
+			}
+			if len(files) != len(c.Manifest.Files) {
+				t.Errorf("got %d files, want %d", len(files), len(c.Manifest.Files))
+			}
+			for _, f := range files {
+				if want, ok := c.Manifest.Files[f.Name]; !ok || string(f.Data) != want {
+					t.Errorf("file %s = %q, want %q", f.Name, f.Data, want)
+				}
+			}
+		})
+	}
+
+	if len(v.Invalid) == 0 {
+		t.Error("no invalid inputs")
+	}
+}
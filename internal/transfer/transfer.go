@@ -0,0 +1,178 @@
+// Package transfer moves a prepared project from an online machine to an
+// air-gapped one. A transfer file is a ZIP holding project.yml, the archived
+// manifest, and an Ed25519 signature over both.
+//
+// The signing key is generated fresh for every transfer and thrown away.
+// The signature detects corruption or tampering in transit only when the
+// person carrying the file compares the fingerprint on both machines.
+package transfer
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/eljojo/rememory/internal/core"
+)
+
+const (
+	// FileName is the default name for a transfer file.
+	FileName = "transfer.rememory"
+
+	projectEntry   = "project.yml"
+	manifestEntry  = "manifest.tar.gz"
+	signatureEntry = "SIGNATURE"
+
+	signatureHeader = "rememory-transfer-v1"
+)
+
+// Contents holds what travels in a transfer file.
+type Contents struct {
+	Project  []byte // project.yml, without seal information
+	Manifest []byte // tar.gz of the manifest/ directory
+	Created  time.Time
+}
+
+// Write signs the contents with a one-time key and writes the transfer file.
+// It returns the fingerprint of the signing key.
+func Write(path string, c *Contents) (string, error) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return "", fmt.Errorf("generating signing key: %w", err)
+	}
+
+	sig := ed25519.Sign(priv, signedMessage(c))
+	signature := fmt.Sprintf("%s\npublic-key: %s\nsignature: %s\n",
+		signatureHeader,
+		base64.StdEncoding.EncodeToString(pub),
+		base64.StdEncoding.EncodeToString(sig))
+
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	entries := []struct {
+		name string
+		data []byte
+	}{
+		{projectEntry, c.Project},
+		{manifestEntry, c.Manifest},
+		{signatureEntry, []byte(signature)},
+	}
+	for _, e := range entries {
+		fw, err := w.CreateHeader(&zip.FileHeader{Name: e.name, Method: zip.Deflate, Modified: c.Created})
+		if err != nil {
+			return "", fmt.Errorf("creating entry %s: %w", e.name, err)
+		}
+		if _, err := fw.Write(e.data); err != nil {
+			return "", fmt.Errorf("writing entry %s: %w", e.name, err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		return "", fmt.Errorf("finishing transfer file: %w", err)
+	}
+
+	if err := os.WriteFile(path, buf.Bytes(), 0600); err != nil {
+		return "", fmt.Errorf("writing transfer file: %w", err)
+	}
+	return Fingerprint(pub), nil
+}
+
+// Read opens a transfer file and verifies its signature.
+// It returns the contents and the fingerprint of the key that signed them.
+func Read(path string) (*Contents, string, error) {
+	r, err := zip.OpenReader(path)
+	if err != nil {
+		return nil, "", fmt.Errorf("opening transfer file: %w", err)
+	}
+	defer r.Close()
+
+	c := &Contents{}
+	var signature []byte
+	for _, f := range r.File {
+		var dst *[]byte
+		switch f.Name {
+		case projectEntry:
+			dst = &c.Project
+		case manifestEntry:
+			dst = &c.Manifest
+			c.Created = f.Modified
+		case signatureEntry:
+			dst = &signature
+		default:
+			return nil, "", fmt.Errorf("unexpected file in transfer: %s", f.Name)
+		}
+
+		rc, err := f.Open()
+		if err != nil {
+			return nil, "", fmt.Errorf("opening %s: %w", f.Name, err)
+		}
+		data, err := io.ReadAll(io.LimitReader(rc, core.MaxTotalSize+1))
+		rc.Close()
+		if err != nil {
+			return nil, "", fmt.Errorf("reading %s: %w", f.Name, err)
+		}
+		*dst = data
+	}
+
+	if c.Project == nil || c.Manifest == nil || signature == nil {
+		return nil, "", fmt.Errorf("transfer file is incomplete")
+	}
+
+	pub, sig, err := parseSignature(string(signature))
+	if err != nil {
+		return nil, "", err
+	}
+	if !ed25519.Verify(pub, signedMessage(c), sig) {
+		return nil, "", fmt.Errorf("signature does not match — the transfer file was changed or damaged")
+	}
+
+	return c, Fingerprint(pub), nil
+}
+
+// Fingerprint returns a short, readable identifier for a signing key,
+// such as "3f9a-12c0-b7e4-55d1".
+func Fingerprint(pub ed25519.PublicKey) string {
+	sum := sha256.Sum256(pub)
+	h := hex.EncodeToString(sum[:8])
+	return fmt.Sprintf("%s-%s-%s-%s", h[0:4], h[4:8], h[8:12], h[12:16])
+}
+
+// signedMessage binds the signature to both payloads.
+func signedMessage(c *Contents) []byte {
+	return []byte(fmt.Sprintf("%s\nproject: %s\nmanifest: %s\n",
+		signatureHeader, core.HashBytes(c.Project), core.HashBytes(c.Manifest)))
+}
+
+func parseSignature(s string) (ed25519.PublicKey, []byte, error) {
+	lines := strings.Split(strings.TrimSpace(s), "\n")
+	if len(lines) != 3 || lines[0] != signatureHeader {
+		return nil, nil, fmt.Errorf("unrecognized transfer signature")
+	}
+
+	pubB64, ok := strings.CutPrefix(lines[1], "public-key: ")
+	if !ok {
+		return nil, nil, fmt.Errorf("transfer signature is missing the public key")
+	}
+	sigB64, ok := strings.CutPrefix(lines[2], "signature: ")
+	if !ok {
+		return nil, nil, fmt.Errorf("transfer signature is missing the signature")
+	}
+
+	pub, err := base64.StdEncoding.DecodeString(pubB64)
+	if err != nil || len(pub) != ed25519.PublicKeySize {
+		return nil, nil, fmt.Errorf("invalid public key in transfer signature")
+	}
+	sig, err := base64.StdEncoding.DecodeString(sigB64)
+	if err != nil || len(sig) != ed25519.SignatureSize {
+		return nil, nil, fmt.Errorf("invalid signature in transfer file")
+	}
+	return pub, sig, nil
+}
@@ -0,0 +1,77 @@
+package transfer
+
+import (
+	"archive/zip"
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWriteRead(t *testing.T) {
+	path := filepath.Join(t.TempDir(), FileName)
+	original := &Contents{
+		Project:  []byte("name: test\nthreshold: 2\n"),
+		Manifest: []byte("fake tar.gz data"),
+		Created:  time.Date(2026, 1, 2, 3, 4, 0, 0, time.UTC),
+	}
+
+	written, err := Write(path, original)
+	if err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+
+	got, read, err := Read(path)
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	if written != read {
+		t.Errorf("fingerprint: wrote %s, read %s", written, read)
+	}
+	if len(read) != 19 {
+		t.Errorf("fingerprint %q has unexpected length", read)
+	}
+	if !bytes.Equal(got.Project, original.Project) || !bytes.Equal(got.Manifest, original.Manifest) {
+		t.Error("contents changed in round trip")
+	}
+}
+
+func TestReadRejectsTampering(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, FileName)
+	if _, err := Write(path, &Contents{Project: []byte("threshold: 2\n"), Manifest: []byte("data")}); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+
+	// Rebuild the ZIP with a modified project.yml but the original signature
+	r, err := zip.OpenReader(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	for _, f := range r.File {
+		rc, _ := f.Open()
+		var data bytes.Buffer
+		data.ReadFrom(rc)
+		rc.Close()
+		if f.Name == projectEntry {
+			data.Reset()
+			data.WriteString("threshold: 1\n")
+		}
+		fw, _ := w.Create(f.Name)
+		fw.Write(data.Bytes())
+	}
+	w.Close()
+	r.Close()
+
+	tampered := filepath.Join(dir, "tampered.rememory")
+	if err := os.WriteFile(tampered, buf.Bytes(), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, err := Read(tampered); err == nil {
+		t.Error("expected tampered transfer file to be rejected")
+	}
+}
@@ -17,6 +17,9 @@ var makerFS embed.FS
 //go:embed readme/*.json
 var readmeFS embed.FS
 
+//go:embed verify/*.json
+var verifyFS embed.FS
+
 // Languages lists all supported language codes.
 var Languages = []string{"en", "es", "de", "fr", "sl", "pt", "zh-TW"}
 
@@ -219,6 +222,8 @@ func fsForComponent(component string) *embed.FS {
 		return &makerFS
 	case "readme":
 		return &readmeFS
+	case "verify":
+		return &verifyFS
 	default:
 		return nil
 	}
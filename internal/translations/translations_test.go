@@ -10,7 +10,7 @@ import (
 )
 
 func TestAllJSONFilesParseCorrectly(t *testing.T) {
-	for _, component := range []string{"recover", "maker", "readme"} {
+	for _, component := range []string{"recover", "maker", "readme", "verify"} {
 		for _, lang := range Languages {
 			t.Run(fmt.Sprintf("%s/%s", component, lang), func(t *testing.T) {
 				m, err := GetComponentTranslations(component, lang)
@@ -29,7 +29,7 @@ func TestAllLanguagesHaveSameKeys(t *testing.T) {
 	if os.Getenv("REMEMORY_CHECK_TRANSLATIONS") == "" {
 		t.Skip("Skipping translation parity check (set REMEMORY_CHECK_TRANSLATIONS=1 or run 'make check-translations')")
 	}
-	for _, component := range []string{"recover", "maker", "readme"} {
+	for _, component := range []string{"recover", "maker", "readme", "verify"} {
 		t.Run(component, func(t *testing.T) {
 			enKeys, err := GetComponentKeys(component)
 			if err != nil {
@@ -129,7 +129,7 @@ func TestLangDetectJS(t *testing.T) {
 }
 
 func TestGetTranslationsJSProducesValidJS(t *testing.T) {
-	for _, component := range []string{"recover", "maker"} {
+	for _, component := range []string{"recover", "maker", "verify"} {
 		t.Run(component, func(t *testing.T) {
 			js := GetTranslationsJS(component)
 
@@ -0,0 +1,92 @@
+package vault
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// bitwardenExport is an unencrypted Bitwarden JSON export
+// ("bw export --format json", or Tools > Export vault > .json).
+type bitwardenExport struct {
+	Encrypted bool `json:"encrypted"`
+	Folders   []struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	} `json:"folders"`
+	Items *[]bitwardenItem `json:"items"`
+}
+
+type bitwardenItem struct {
+	Type     int    `json:"type"` // 1 login, 2 secure note, 3 card, 4 identity
+	Name     string `json:"name"`
+	Notes    string `json:"notes"`
+	FolderID string `json:"folderId"`
+	Login    *struct {
+		Username string `json:"username"`
+		Password string `json:"password"`
+		TOTP     string `json:"totp"`
+		URIs     []struct {
+			URI string `json:"uri"`
+		} `json:"uris"`
+	} `json:"login"`
+	Card     map[string]any `json:"card"`
+	Identity map[string]any `json:"identity"`
+	Fields   []struct {
+		Name  string `json:"name"`
+		Value string `json:"value"`
+	} `json:"fields"`
+}
+
+// bitwardenCardFields and bitwardenIdentityFields name the card and
+// identity values worth keeping, in the order they're shown.
+var (
+	bitwardenCardFields     = [][2]string{{"cardholderName", "Cardholder"}, {"brand", "Brand"}, {"number", "Number"}, {"expMonth", "Expiry month"}, {"expYear", "Expiry year"}, {"code", "Security code"}}
+	bitwardenIdentityFields = [][2]string{{"title", "Title"}, {"firstName", "First name"}, {"middleName", "Middle name"}, {"lastName", "Last name"}, {"email", "Email"}, {"phone", "Phone"}, {"address1", "Address"}, {"address2", "Address 2"}, {"address3", "Address 3"}, {"city", "City"}, {"state", "State"}, {"postalCode", "Postal code"}, {"country", "Country"}, {"company", "Company"}, {"ssn", "Social security number"}, {"passportNumber", "Passport number"}, {"licenseNumber", "License number"}, {"username", "Username"}}
+)
+
+func parseBitwarden(data []byte) (*Vault, error) {
+	var export bitwardenExport
+	if err := json.Unmarshal(data, &export); err != nil {
+		return nil, fmt.Errorf("reading Bitwarden export: %w", err)
+	}
+	if export.Encrypted {
+		return nil, fmt.Errorf("this Bitwarden export is encrypted; export it again as plain .json (the file is sealed right away, so delete it afterwards)")
+	}
+	if export.Items == nil {
+		return nil, fmt.Errorf("unrecognized JSON export: expected a Bitwarden export with \"items\"")
+	}
+
+	folders := make(map[string]string, len(export.Folders))
+	for _, f := range export.Folders {
+		folders[f.ID] = f.Name
+	}
+
+	v := &Vault{Source: SourceBitwarden}
+	for _, item := range *export.Items {
+		e := Entry{Title: item.Name, Folder: folders[item.FolderID], Notes: item.Notes}
+		if item.Login != nil {
+			e.Username, e.Password, e.TOTP = item.Login.Username, item.Login.Password, item.Login.TOTP
+			for _, u := range item.Login.URIs {
+				e.URLs = append(e.URLs, u.URI)
+			}
+		}
+		e.Fields = append(e.Fields, namedValues(item.Card, bitwardenCardFields)...)
+		e.Fields = append(e.Fields, namedValues(item.Identity, bitwardenIdentityFields)...)
+		for _, f := range item.Fields {
+			e.Fields = append(e.Fields, Field{Name: f.Name, Value: f.Value})
+		}
+		v.add(e)
+	}
+	return v, nil
+}
+
+// namedValues picks the string values of m listed in names, labeled.
+func namedValues(m map[string]any, names [][2]string) []Field {
+	var fields []Field
+	for _, n := range names {
+		if s, ok := m[n[0]].(string); ok && s != "" {
+			fields = append(fields, Field{Name: n[1], Value: s})
+		}
+	}
+	return fields
+}
@@ -0,0 +1,86 @@
+package vault
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"strings"
+)
+
+// csvColumns maps the header names password managers use in their CSV
+// exports (Bitwarden, 1Password, KeePassXC, LastPass, Chrome, and others)
+// to Entry's fields.
+var csvColumns = map[string]string{
+	"title": "title", "name": "title",
+	"folder": "folder", "group": "folder", "grouping": "folder", "vault": "folder",
+	"username": "username", "login_username": "username", "user name": "username", "login": "username",
+	"password": "password", "login_password": "password",
+	"url": "url", "login_uri": "url", "website": "url", "web site": "url", "uri": "url",
+	"totp": "totp", "otp": "totp", "login_totp": "totp", "otpauth": "totp", "one-time password": "totp",
+	"notes": "notes", "note": "notes", "extra": "notes", "comments": "notes",
+}
+
+// csvIgnored are columns that say nothing an heir needs.
+var csvIgnored = map[string]bool{
+	"favorite": true, "fav": true, "type": true, "reprompt": true, "archived": true,
+	"last modified": true, "created": true, "icon": true, "tags": true,
+}
+
+func parseCSV(data []byte) (*Vault, error) {
+	r := csv.NewReader(bytes.NewReader(data))
+	r.FieldsPerRecord = -1
+	records, err := r.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("unrecognized export: not a Bitwarden, 1Password, or KeePass export, nor a CSV file (%v)", err)
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("the export is empty")
+	}
+
+	header := records[0]
+	columns := make([]string, len(header))
+	var known bool
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+		switch {
+		case csvColumns[name] != "":
+			columns[i] = csvColumns[name]
+			known = known || columns[i] == "password" || columns[i] == "username"
+		case csvIgnored[name]:
+			columns[i] = "-"
+		}
+	}
+	if !known {
+		return nil, fmt.Errorf("unrecognized CSV export: the first line should name the columns, including a username or password column")
+	}
+
+	v := &Vault{Source: SourceCSV}
+	for _, record := range records[1:] {
+		var e Entry
+		for i, value := range record {
+			if i >= len(columns) {
+				break
+			}
+			switch columns[i] {
+			case "title":
+				e.Title = value
+			case "folder":
+				e.Folder = value
+			case "username":
+				e.Username = value
+			case "password":
+				e.Password = value
+			case "url":
+				e.URLs = append(e.URLs, value)
+			case "totp":
+				e.TOTP = value
+			case "notes":
+				e.Notes = value
+			case "":
+				e.Fields = append(e.Fields, Field{Name: strings.TrimSpace(header[i]), Value: value})
+			}
+		}
+		v.add(e)
+	}
+	return v, nil
+}
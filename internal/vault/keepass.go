@@ -0,0 +1,85 @@
+package vault
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+)
+
+// keepassFile is a KeePass 2 XML export (File > Export > KeePass XML, or
+// "keepassxc-cli export --format xml"). Values are exported unprotected.
+type keepassFile struct {
+	XMLName xml.Name `xml:"KeePassFile"`
+	Root    struct {
+		Groups []keepassGroup `xml:"Group"`
+	} `xml:"Root"`
+}
+
+type keepassGroup struct {
+	Name    string         `xml:"Name"`
+	Entries []keepassEntry `xml:"Entry"`
+	Groups  []keepassGroup `xml:"Group"`
+}
+
+// keepassEntry leaves out <History>, the entry's earlier versions.
+type keepassEntry struct {
+	Strings []struct {
+		Key   string `xml:"Key"`
+		Value string `xml:"Value"`
+	} `xml:"String"`
+}
+
+// keepassRecycleBin is the group deleted entries wait in.
+const keepassRecycleBin = "Recycle Bin"
+
+func parseKeePass(data []byte) (*Vault, error) {
+	var file keepassFile
+	if err := xml.Unmarshal(data, &file); err != nil {
+		if strings.Contains(err.Error(), "expected element type <KeePassFile>") {
+			return nil, fmt.Errorf("unrecognized XML export: expected a KeePass XML export")
+		}
+		return nil, fmt.Errorf("reading KeePass export: %w", err)
+	}
+
+	v := &Vault{Source: SourceKeePass}
+	for _, g := range file.Root.Groups {
+		// The top group is the database itself; its name would prefix every folder
+		addKeePassGroup(v, g, "")
+	}
+	return v, nil
+}
+
+func addKeePassGroup(v *Vault, g keepassGroup, folder string) {
+	for _, entry := range g.Entries {
+		e := Entry{Folder: folder}
+		for _, s := range entry.Strings {
+			switch s.Key {
+			case "Title":
+				e.Title = s.Value
+			case "UserName":
+				e.Username = s.Value
+			case "Password":
+				e.Password = s.Value
+			case "URL":
+				e.URLs = []string{s.Value}
+			case "Notes":
+				e.Notes = s.Value
+			case "otp", "TOTP Seed", "TimeOtp-Secret-Base32":
+				e.TOTP = s.Value
+			default:
+				e.Fields = append(e.Fields, Field{Name: s.Key, Value: s.Value})
+			}
+		}
+		v.add(e)
+	}
+	for _, sub := range g.Groups {
+		if sub.Name == keepassRecycleBin {
+			continue
+		}
+		name := sub.Name
+		if folder != "" {
+			name = folder + "/" + sub.Name
+		}
+		addKeePassGroup(v, sub, name)
+	}
+}
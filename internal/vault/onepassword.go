@@ -0,0 +1,174 @@
+package vault
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// onePasswordExport is export.data inside a 1Password .1pux file
+// (File > Export > 1PUX).
+type onePasswordExport struct {
+	Accounts []struct {
+		Vaults []struct {
+			Attrs struct {
+				Name string `json:"name"`
+			} `json:"attrs"`
+			Items []onePasswordItem `json:"items"`
+		} `json:"vaults"`
+	} `json:"accounts"`
+}
+
+type onePasswordItem struct {
+	Overview struct {
+		Title string `json:"title"`
+		URL   string `json:"url"`
+		URLs  []struct {
+			URL string `json:"url"`
+		} `json:"urls"`
+	} `json:"overview"`
+	Details struct {
+		LoginFields []struct {
+			Name        string `json:"name"`
+			Value       string `json:"value"`
+			Designation string `json:"designation"`
+		} `json:"loginFields"`
+		NotesPlain string `json:"notesPlain"`
+		Password   string `json:"password"` // Password items keep it here
+		Sections   []struct {
+			Title  string `json:"title"`
+			Fields []struct {
+				Title string                     `json:"title"`
+				Value map[string]json.RawMessage `json:"value"`
+			} `json:"fields"`
+		} `json:"sections"`
+	} `json:"details"`
+}
+
+// maxOnePasswordData bounds export.data, which holds no attachments.
+const maxOnePasswordData = 256 << 20
+
+func parseOnePassword(data []byte) (*Vault, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("reading 1Password export: %w", err)
+	}
+	var exportData []byte
+	for _, f := range zr.File {
+		if f.Name != "export.data" {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, fmt.Errorf("reading 1Password export: %w", err)
+		}
+		exportData, err = io.ReadAll(io.LimitReader(rc, maxOnePasswordData))
+		rc.Close()
+		if err != nil {
+			return nil, fmt.Errorf("reading 1Password export: %w", err)
+		}
+	}
+	if exportData == nil {
+		return nil, fmt.Errorf("unrecognized ZIP file: expected a 1Password .1pux export with export.data")
+	}
+
+	var export onePasswordExport
+	if err := json.Unmarshal(exportData, &export); err != nil {
+		return nil, fmt.Errorf("reading 1Password export: %w", err)
+	}
+
+	v := &Vault{Source: SourceOnePassword}
+	for _, account := range export.Accounts {
+		for _, vault := range account.Vaults {
+			for _, item := range vault.Items {
+				v.add(onePasswordEntry(vault.Attrs.Name, item))
+			}
+		}
+	}
+	return v, nil
+}
+
+func onePasswordEntry(folder string, item onePasswordItem) Entry {
+	e := Entry{Title: item.Overview.Title, Folder: folder, Notes: item.Details.NotesPlain, Password: item.Details.Password}
+	if item.Overview.URL != "" {
+		e.URLs = append(e.URLs, item.Overview.URL)
+	}
+	for _, u := range item.Overview.URLs {
+		if u.URL != item.Overview.URL {
+			e.URLs = append(e.URLs, u.URL)
+		}
+	}
+	for _, f := range item.Details.LoginFields {
+		switch f.Designation {
+		case "username":
+			e.Username = f.Value
+		case "password":
+			e.Password = f.Value
+		default:
+			if f.Value != "" {
+				e.Fields = append(e.Fields, Field{Name: f.Name, Value: f.Value})
+			}
+		}
+	}
+	for _, section := range item.Details.Sections {
+		for _, f := range section.Fields {
+			value, kind := onePasswordValue(f.Value)
+			if kind == "totp" && e.TOTP == "" {
+				e.TOTP = value
+				continue
+			}
+			name := f.Title
+			if section.Title != "" && name != "" {
+				name = section.Title + ": " + name
+			} else if name == "" {
+				name = section.Title
+			}
+			e.Fields = append(e.Fields, Field{Name: name, Value: value})
+		}
+	}
+	return e
+}
+
+// onePasswordValue reads a section field's value, an object with one key
+// naming its kind: {"concealed": "..."}, {"date": 1700000000}, {"email":
+// {"email_address": "..."}}, and so on. Values that aren't text, such as
+// attached files, come back empty.
+func onePasswordValue(value map[string]json.RawMessage) (string, string) {
+	for kind, raw := range value {
+		var s string
+		if json.Unmarshal(raw, &s) == nil {
+			return s, kind
+		}
+		var n json.Number
+		if json.Unmarshal(raw, &n) == nil {
+			return n.String(), kind
+		}
+		var obj map[string]any
+		if json.Unmarshal(raw, &obj) == nil {
+			return joinStrings(obj), kind
+		}
+		return "", kind
+	}
+	return "", ""
+}
+
+// joinStrings joins the text values of an object such as an address or an
+// email, in key order so the result doesn't vary.
+func joinStrings(obj map[string]any) string {
+	keys := make([]string, 0, len(obj))
+	for k := range obj {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var parts []string
+	for _, k := range keys {
+		if s, ok := obj[k].(string); ok && s != "" {
+			parts = append(parts, s)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,166 @@
+// Package vault reads password-manager exports — Bitwarden JSON, 1Password
+// .1pux, KeePass XML, and the CSV files most managers write — and turns them
+// into one plain format that is sealed as passwords.json. recover.html
+// recognizes that file and shows its entries as a readable list, so an heir
+// doesn't have to make sense of a vendor's export format.
+//
+// Exports are read whole into memory: even a large vault is a few
+// megabytes.
+package vault
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// FileName is the name the vault is sealed under, inside manifest/.
+const FileName = "passwords.json"
+
+// Format marks a sealed vault, so recover.html can tell it from any other
+// JSON file.
+const Format = "rememory-vault"
+
+// Version is the version of the vault format this package writes.
+const Version = 1
+
+// Export formats, as recorded in Vault.Source.
+const (
+	SourceBitwarden   = "bitwarden"
+	SourceOnePassword = "1password"
+	SourceKeePass     = "keepass"
+	SourceCSV         = "csv"
+)
+
+// Vault is a normalized password-manager export.
+type Vault struct {
+	Format  string  `json:"format"`
+	Version int     `json:"version"`
+	Source  string  `json:"source"` // Which kind of export it was read from
+	Entries []Entry `json:"entries"`
+}
+
+// Entry is one login, card, note, or other item.
+type Entry struct {
+	Title    string   `json:"title"`
+	Folder   string   `json:"folder,omitempty"`
+	Username string   `json:"username,omitempty"`
+	Password string   `json:"password,omitempty"`
+	URLs     []string `json:"urls,omitempty"`
+	TOTP     string   `json:"totp,omitempty"` // One-time code secret or otpauth:// URL
+	Notes    string   `json:"notes,omitempty"`
+	Fields   []Field  `json:"fields,omitempty"` // Anything else: card numbers, security questions, custom fields
+}
+
+// Field is a named value that isn't one of Entry's own.
+type Field struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// Parse reads an export, recognizing its format from the contents.
+func Parse(data []byte) (*Vault, error) {
+	trimmed := bytes.TrimSpace(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")))
+	var (
+		v   *Vault
+		err error
+	)
+	switch {
+	case len(trimmed) == 0:
+		return nil, fmt.Errorf("the export is empty")
+	case bytes.HasPrefix(trimmed, []byte("PK\x03\x04")):
+		v, err = parseOnePassword(data)
+	case trimmed[0] == '{':
+		v, err = parseBitwarden(trimmed)
+	case trimmed[0] == '<':
+		v, err = parseKeePass(trimmed)
+	default:
+		v, err = parseCSV(trimmed)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(v.Entries) == 0 {
+		return nil, fmt.Errorf("no entries found in the %s export", v.Source)
+	}
+	v.Format, v.Version = Format, Version
+	v.sort()
+	return v, nil
+}
+
+// Marshal writes v as indented JSON, readable on its own too.
+func (v *Vault) Marshal() ([]byte, error) {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// sort orders entries by folder, then title, ignoring case.
+func (v *Vault) sort() {
+	sort.SliceStable(v.Entries, func(i, j int) bool {
+		a, b := v.Entries[i], v.Entries[j]
+		if fa, fb := strings.ToLower(a.Folder), strings.ToLower(b.Folder); fa != fb {
+			return fa < fb
+		}
+		return strings.ToLower(a.Title) < strings.ToLower(b.Title)
+	})
+}
+
+// add appends e, trimming its values and dropping empty fields; an entry
+// with nothing in it is skipped.
+func (v *Vault) add(e Entry) {
+	e.Title = strings.TrimSpace(e.Title)
+	e.Folder = strings.TrimSpace(e.Folder)
+	e.Username = strings.TrimSpace(e.Username)
+	e.TOTP = strings.TrimSpace(e.TOTP)
+	e.Notes = strings.TrimSpace(e.Notes)
+
+	var urls []string
+	for _, u := range e.URLs {
+		if u = strings.TrimSpace(u); u != "" {
+			urls = append(urls, u)
+		}
+	}
+	e.URLs = urls
+
+	var fields []Field
+	for _, f := range e.Fields {
+		f.Name, f.Value = strings.TrimSpace(f.Name), strings.TrimSpace(f.Value)
+		if f.Value != "" {
+			fields = append(fields, f)
+		}
+	}
+	e.Fields = fields
+
+	if e.Title == "" && e.Username == "" && e.Password == "" && len(e.URLs) == 0 && e.Notes == "" && len(e.Fields) == 0 {
+		return
+	}
+	if e.Title == "" {
+		e.Title = "(untitled)"
+	}
+	v.Entries = append(v.Entries, e)
+}
+
+// Command returns the command that prints an export from a password
+// manager's own command-line tool, for 'rememory seal --vault-from':
+// "bitwarden" runs bw, and "keepassxc:FILE" runs keepassxc-cli on the
+// database FILE. Both ask for the vault's password themselves.
+func Command(from string) (string, []string, error) {
+	tool, arg, _ := strings.Cut(from, ":")
+	switch strings.ToLower(tool) {
+	case "bitwarden", "bw":
+		return "bw", []string{"export", "--raw", "--format", "json"}, nil
+	case "keepassxc":
+		if arg == "" {
+			return "", nil, fmt.Errorf("name the database: --vault-from keepassxc:passwords.kdbx")
+		}
+		return "keepassxc-cli", []string{"export", "--format", "xml", arg}, nil
+	case "1password", "op":
+		return "", nil, fmt.Errorf("1Password's command-line tool can't export a whole vault; export a .1pux file from the app (File > Export) and use --vault")
+	}
+	return "", nil, fmt.Errorf("unknown --vault-from %q (available: bitwarden, keepassxc:FILE)", from)
+}
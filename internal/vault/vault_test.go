@@ -0,0 +1,188 @@
+package vault
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestParseBitwarden(t *testing.T) {
+	export := `{
+  "encrypted": false,
+  "folders": [{"id": "f1", "name": "Banking"}],
+  "items": [
+    {"type": 1, "name": "Bank", "folderId": "f1", "notes": "Branch on Main St",
+     "login": {"username": "ana", "password": "hunter2", "totp": "JBSWY3DPEHPK3PXP", "uris": [{"uri": "https://bank.example"}]},
+     "fields": [{"name": "PIN", "value": "4921", "type": 1}]},
+    {"type": 3, "name": "Visa", "folderId": null,
+     "card": {"cardholderName": "Ana", "number": "4111111111111111", "code": "123"}},
+    {"type": 2, "name": "", "notes": ""}
+  ]
+}`
+	v, err := Parse([]byte(export))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.Source != SourceBitwarden || v.Format != Format || len(v.Entries) != 2 {
+		t.Fatalf("got %s with %d entries", v.Source, len(v.Entries))
+	}
+	// Sorted by folder: the card has none
+	card, bank := v.Entries[0], v.Entries[1]
+	if bank.Title != "Bank" || bank.Folder != "Banking" || bank.Username != "ana" || bank.Password != "hunter2" ||
+		bank.TOTP != "JBSWY3DPEHPK3PXP" || bank.URLs[0] != "https://bank.example" || bank.Notes != "Branch on Main St" {
+		t.Errorf("bank = %+v", bank)
+	}
+	if len(bank.Fields) != 1 || bank.Fields[0] != (Field{Name: "PIN", Value: "4921"}) {
+		t.Errorf("bank fields = %+v", bank.Fields)
+	}
+	if len(card.Fields) != 3 || card.Fields[1] != (Field{Name: "Number", Value: "4111111111111111"}) {
+		t.Errorf("card fields = %+v", card.Fields)
+	}
+
+	if _, err := Parse([]byte(`{"encrypted": true, "items": []}`)); err == nil || !strings.Contains(err.Error(), "encrypted") {
+		t.Errorf("encrypted export: err = %v", err)
+	}
+	if _, err := Parse([]byte(`{"hello": "world"}`)); err == nil {
+		t.Error("expected an error for JSON that isn't an export")
+	}
+}
+
+func TestParseOnePassword(t *testing.T) {
+	data := `{"accounts": [{"vaults": [{"attrs": {"name": "Family"}, "items": [
+  {"overview": {"title": "Email", "url": "https://mail.example"},
+   "details": {"loginFields": [
+       {"designation": "username", "name": "username", "value": "ana@mail.example"},
+       {"designation": "password", "name": "password", "value": "s3cret"}],
+     "notesPlain": "Recovery email is Bob's",
+     "sections": [{"title": "Security", "fields": [
+       {"title": "one-time password", "value": {"totp": "otpauth://totp/mail?secret=ABC"}},
+       {"title": "question", "value": {"string": "first pet"}},
+       {"title": "since", "value": {"date": 1700000000}}]}]}}]}]}]}`
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	w, _ := zw.Create("export.attributes")
+	w.Write([]byte(`{"version": 3}`))
+	w, _ = zw.Create("export.data")
+	w.Write([]byte(data))
+	zw.Close()
+
+	v, err := Parse(buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.Source != SourceOnePassword || len(v.Entries) != 1 {
+		t.Fatalf("got %s with %d entries", v.Source, len(v.Entries))
+	}
+	e := v.Entries[0]
+	if e.Title != "Email" || e.Folder != "Family" || e.Username != "ana@mail.example" || e.Password != "s3cret" ||
+		e.TOTP != "otpauth://totp/mail?secret=ABC" || e.URLs[0] != "https://mail.example" {
+		t.Errorf("entry = %+v", e)
+	}
+	want := []Field{{"Security: question", "first pet"}, {"Security: since", "1700000000"}}
+	if len(e.Fields) != 2 || e.Fields[0] != want[0] || e.Fields[1] != want[1] {
+		t.Errorf("fields = %+v, want %+v", e.Fields, want)
+	}
+}
+
+func TestParseKeePass(t *testing.T) {
+	export := `<?xml version="1.0" encoding="utf-8" standalone="yes"?>
+<KeePassFile>
+  <Root>
+    <Group>
+      <Name>Passwords</Name>
+      <Entry>
+        <String><Key>Title</Key><Value>Router</Value></String>
+        <String><Key>UserName</Key><Value>admin</Value></String>
+        <String><Key>Password</Key><Value ProtectInMemory="True">r0uter</Value></String>
+        <String><Key>URL</Key><Value>http://192.168.1.1</Value></String>
+        <String><Key>Serial</Key><Value>XR-500</Value></String>
+        <History>
+          <Entry><String><Key>Password</Key><Value>old</Value></String></Entry>
+        </History>
+      </Entry>
+      <Group>
+        <Name>Work</Name>
+        <Entry><String><Key>Title</Key><Value>VPN</Value></String><String><Key>Password</Key><Value>vpn</Value></String></Entry>
+      </Group>
+      <Group>
+        <Name>Recycle Bin</Name>
+        <Entry><String><Key>Title</Key><Value>Deleted</Value></String></Entry>
+      </Group>
+    </Group>
+  </Root>
+</KeePassFile>`
+	v, err := Parse([]byte(export))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.Source != SourceKeePass || len(v.Entries) != 2 {
+		t.Fatalf("got %s with %+v", v.Source, v.Entries)
+	}
+	router, vpn := v.Entries[0], v.Entries[1]
+	if router.Title != "Router" || router.Folder != "" || router.Password != "r0uter" || router.Fields[0].Value != "XR-500" {
+		t.Errorf("router = %+v", router)
+	}
+	if vpn.Folder != "Work" {
+		t.Errorf("vpn folder = %q, want Work", vpn.Folder)
+	}
+}
+
+func TestParseCSV(t *testing.T) {
+	// Bitwarden's CSV columns
+	export := "folder,favorite,type,name,notes,fields,reprompt,login_uri,login_username,login_password,login_totp\n" +
+		"Social,,login,Forum,\"two\nlines\",,0,https://forum.example,ana,pw1,\n" +
+		",1,login,Shop,,color: blue,0,,ana,pw2,\n"
+	v, err := Parse([]byte(export))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.Source != SourceCSV || len(v.Entries) != 2 {
+		t.Fatalf("got %s with %d entries", v.Source, len(v.Entries))
+	}
+	shop, forum := v.Entries[0], v.Entries[1]
+	if forum.Folder != "Social" || forum.Notes != "two\nlines" || forum.URLs[0] != "https://forum.example" || forum.Password != "pw1" {
+		t.Errorf("forum = %+v", forum)
+	}
+	if len(shop.Fields) != 1 || shop.Fields[0] != (Field{Name: "fields", Value: "color: blue"}) {
+		t.Errorf("shop fields = %+v", shop.Fields)
+	}
+
+	if _, err := Parse([]byte("a,b,c\n1,2,3\n")); err == nil {
+		t.Error("expected an error for a CSV file without username or password columns")
+	}
+}
+
+func TestMarshal(t *testing.T) {
+	v, err := Parse([]byte("name,username,password\nMail,ana,pw\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := v.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var back Vault
+	if err := json.Unmarshal(data, &back); err != nil {
+		t.Fatal(err)
+	}
+	if back.Format != Format || back.Version != Version || back.Entries[0].Password != "pw" {
+		t.Errorf("round trip = %+v", back)
+	}
+}
+
+func TestCommand(t *testing.T) {
+	if tool, args, err := Command("bitwarden"); err != nil || tool != "bw" || args[0] != "export" {
+		t.Errorf("bitwarden: %s %v %v", tool, args, err)
+	}
+	if tool, args, err := Command("keepassxc:db.kdbx"); err != nil || tool != "keepassxc-cli" || args[len(args)-1] != "db.kdbx" {
+		t.Errorf("keepassxc: %s %v %v", tool, args, err)
+	}
+	for _, from := range []string{"keepassxc", "1password", "lastpass"} {
+		if _, _, err := Command(from); err == nil {
+			t.Errorf("Command(%q) should fail", from)
+		}
+	}
+}
@@ -7,15 +7,18 @@ import (
 	"archive/zip"
 	"bytes"
 	"compress/gzip"
+	"crypto/sha256"
 	"encoding/base64"
 	"fmt"
 	"strings"
+	"sync"
 	"syscall/js"
 	"time"
 
 	"github.com/eljojo/rememory/internal/bundle"
 	"github.com/eljojo/rememory/internal/core"
 	"github.com/eljojo/rememory/internal/crypto"
+	"github.com/eljojo/rememory/internal/faq"
 	"github.com/eljojo/rememory/internal/html"
 	"github.com/eljojo/rememory/internal/pdf"
 	"github.com/eljojo/rememory/internal/project"
@@ -45,8 +48,8 @@ type CreateBundlesConfig struct {
 	Files           []FileEntry
 	Version         string
 	GitHubURL       string
-	Anonymous       bool
-	DefaultLanguage string // Default bundle language for all friends
+	Privacy         project.Privacy // What bundles tell about the friends; empty means full
+	DefaultLanguage string          // Default bundle language for all friends
 }
 
 // BundleOutput represents a generated bundle for JavaScript.
@@ -72,7 +75,11 @@ func createBundlesJS(this js.Value, args []js.Value) any {
 		Threshold:   configJS.Get("threshold").Int(),
 		Version:     configJS.Get("version").String(),
 		GitHubURL:   configJS.Get("githubURL").String(),
-		Anonymous:   configJS.Get("anonymous").Bool(),
+	}
+	if privacy := configJS.Get("privacy"); !privacy.IsUndefined() && !privacy.IsNull() {
+		config.Privacy = project.Privacy(privacy.String())
+	} else if configJS.Get("anonymous").Bool() {
+		config.Privacy = project.PrivacyAnonymous
 	}
 	if defLang := configJS.Get("defaultLanguage"); !defLang.IsUndefined() && !defLang.IsNull() {
 		config.DefaultLanguage = defLang.String()
@@ -115,7 +122,7 @@ func createBundlesJS(this js.Value, args []js.Value) any {
 	// Create bundles
 	bundles, err := createBundles(config)
 	if err != nil {
-		return errorResult(err.Error())
+		return validationResult(err)
 	}
 
 	// Convert bundles to JavaScript array
@@ -142,24 +149,22 @@ func createBundles(config CreateBundlesConfig) ([]BundleOutput, error) {
 	if config.ProjectName == "" {
 		return nil, fmt.Errorf("project name is required")
 	}
-	if len(config.Friends) < 2 {
-		return nil, fmt.Errorf("need at least 2 friends, got %d", len(config.Friends))
-	}
-	if config.Threshold < 2 {
-		return nil, fmt.Errorf("threshold must be at least 2, got %d", config.Threshold)
-	}
-	if config.Threshold > len(config.Friends) {
-		return nil, fmt.Errorf("threshold (%d) cannot exceed number of friends (%d)", config.Threshold, len(config.Friends))
+	if err := core.ValidateThreshold(config.Threshold, len(config.Friends)); err != nil {
+		return nil, err
 	}
 	if len(config.Files) == 0 {
 		return nil, fmt.Errorf("no files provided")
 	}
+	if _, err := project.ParsePrivacy(string(config.Privacy)); err != nil {
+		return nil, err
+	}
 
-	// Validate friends
+	holders := make([]core.Holder, len(config.Friends))
 	for i, f := range config.Friends {
-		if f.Name == "" {
-			return nil, fmt.Errorf("friend %d: name is required", i+1)
-		}
+		holders[i] = core.Holder{Name: f.Name, Contact: f.Contact}
+	}
+	if err := core.ValidateHolders(holders); err != nil {
+		return nil, err
 	}
 
 	// Create tar.gz archive of files
@@ -168,15 +173,22 @@ func createBundles(config CreateBundlesConfig) ([]BundleOutput, error) {
 		return nil, fmt.Errorf("creating archive: %w", err)
 	}
 
-	// Generate random passphrase (v2: split raw bytes, not the base64 string)
-	raw, passphrase, err := crypto.GenerateRawPassphrase(crypto.DefaultPassphraseBytes)
+	// Generate random passphrase (v2: split raw bytes, not the base64 string),
+	// starting with the bundle header every piece will carry
+	bundleID, err := core.NewBundleID()
+	if err != nil {
+		return nil, err
+	}
+	raw, passphrase, err := crypto.GenerateBundlePassphrase(crypto.DefaultPassphraseBytes, bundleID)
 	if err != nil {
 		return nil, fmt.Errorf("generating passphrase: %w", err)
 	}
+	defer core.Wipe(raw)
+	defer passphrase.Wipe()
 
 	// Encrypt archive
 	var encryptedBuf bytes.Buffer
-	if err := core.Encrypt(&encryptedBuf, bytes.NewReader(archiveData), passphrase); err != nil {
+	if err := core.Encrypt(&encryptedBuf, bytes.NewReader(archiveData), string(passphrase)); err != nil {
 		return nil, fmt.Errorf("encrypting archive: %w", err)
 	}
 	manifestData := encryptedBuf.Bytes()
@@ -189,6 +201,9 @@ func createBundles(config CreateBundlesConfig) ([]BundleOutput, error) {
 	if err != nil {
 		return nil, fmt.Errorf("splitting passphrase: %w", err)
 	}
+	if err := core.BindShares(rawShares, bundleID); err != nil {
+		return nil, err
+	}
 
 	// Current timestamp for all bundles
 	now := time.Now().UTC()
@@ -196,6 +211,8 @@ func createBundles(config CreateBundlesConfig) ([]BundleOutput, error) {
 	// Get recovery WASM bytes for embedding in recover.html
 	// Note: In WASM context, we use the embedded recover.wasm (smaller, recovery-only)
 	wasmBytes := html.GetRecoverWASMBytes()
+	buildInfo := bundle.NewBuildInfo(config.Version, wasmBytes)
+	buildInfo.Tool = "maker.html"
 
 	// Create shares and bundles
 	bundles := make([]BundleOutput, n)
@@ -208,10 +225,17 @@ func createBundles(config CreateBundlesConfig) ([]BundleOutput, error) {
 			Index:     i + 1,
 			Total:     n,
 			Threshold: k,
-			Holder:    friend.Name,
+			Holder:    config.Privacy.Holder(project.Friend{Name: friend.Name}, i+1),
 			Created:   now,
 			Data:      rawShares[i],
 			Checksum:  core.HashBytes(rawShares[i]),
+			Encoding:  core.PaperEncoding,
+		}
+		share.Bind(bundleID)
+		share.Authenticate(raw)
+		wordProject := project.Project{Language: config.DefaultLanguage}
+		if lang := wordProject.WordList(project.Friend{Language: friend.Language}); lang != core.LangEN {
+			share.WordList = lang
 		}
 		shares[i] = share
 	}
@@ -226,6 +250,12 @@ func createBundles(config CreateBundlesConfig) ([]BundleOutput, error) {
 		}
 	}
 
+	commitments := bundle.NewCommitments(shares)
+	vss, err := core.SplitVSS(raw, k, shares)
+	if err != nil {
+		return nil, fmt.Errorf("splitting passphrase for VSS: %w", err)
+	}
+
 	// Generate bundle for each friend
 	for i, friend := range config.Friends {
 		share := shares[i]
@@ -239,18 +269,20 @@ func createBundles(config CreateBundlesConfig) ([]BundleOutput, error) {
 			lang = "en"
 		}
 
-		// Get other friends (excluding this one) - empty for anonymous mode
+		// Get other friends (excluding this one), as much as the privacy
+		// setting shows of them; none for anonymous projects
 		var otherFriends []project.Friend
 		var otherFriendsInfo []html.FriendInfo
-		if !config.Anonymous {
+		if config.Privacy.ListsOthers() {
 			otherFriends = make([]project.Friend, 0, n-1)
 			otherFriendsInfo = make([]html.FriendInfo, 0, n-1)
 			for j, f := range projectFriends {
 				if j != i {
-					otherFriends = append(otherFriends, f)
+					shown := config.Privacy.Shown(f)
+					otherFriends = append(otherFriends, shown)
 					otherFriendsInfo = append(otherFriendsInfo, html.FriendInfo{
-						Name:       f.Name,
-						Contact:    f.Contact,
+						Name:       shown.Name,
+						Contact:    shown.Contact,
 						ShareIndex: j + 1, // 1-based share index
 					})
 				}
@@ -259,12 +291,14 @@ func createBundles(config CreateBundlesConfig) ([]BundleOutput, error) {
 
 		// Generate personalized recover.html
 		personalization := &html.PersonalizationData{
-			Holder:       friend.Name,
-			HolderShare:  share.Encode(),
-			OtherFriends: otherFriendsInfo,
-			Threshold:    k,
-			Total:        n,
-			Language:     lang,
+			Holder:         share.Holder,
+			HolderShare:    share.Encode(),
+			OtherFriends:   otherFriendsInfo,
+			Threshold:      k,
+			Total:          n,
+			Language:       lang,
+			ShareChecksums: commitments,
+			VSS:            vss.Strings(),
 		}
 
 		// Embed manifest in recover.html when small enough
@@ -273,13 +307,25 @@ func createBundles(config CreateBundlesConfig) ([]BundleOutput, error) {
 			personalization.ManifestB64 = base64.StdEncoding.EncodeToString(manifestData)
 		}
 
-		recoverHTML := html.GenerateRecoverHTML(wasmBytes, config.Version, config.GitHubURL, personalization)
+		// Web-created bundles always use the GitHub Pages recovery URL
+		recoveryURL, hosted := faq.RecoveryPage("")
+		facts := faq.Facts{
+			Threshold:   k,
+			Total:       n,
+			Embedded:    manifestEmbedded,
+			RecoveryURL: recoveryURL,
+			Hosted:      hosted,
+			ListsOthers: config.Privacy.ListsOthers(),
+		}
+		personalization.FAQ = &facts
+
+		recoverHTML := html.GenerateRecoverHTML(wasmBytes, config.Version, config.GitHubURL, personalization, nil)
 		recoverChecksum := core.HashString(recoverHTML)
 
 		// Generate README.txt
 		readmeData := bundle.ReadmeData{
 			ProjectName:      config.ProjectName,
-			Holder:           friend.Name,
+			Holder:           share.Holder,
 			Share:            share,
 			OtherFriends:     otherFriends,
 			Threshold:        k,
@@ -289,9 +335,12 @@ func createBundles(config CreateBundlesConfig) ([]BundleOutput, error) {
 			ManifestChecksum: manifestChecksum,
 			RecoverChecksum:  recoverChecksum,
 			Created:          now,
-			Anonymous:        config.Anonymous,
+			Privacy:          config.Privacy,
 			Language:         lang,
 			ManifestEmbedded: manifestEmbedded,
+			Commitments:      commitments,
+			VSS:              vss,
+			FAQ:              facts,
 		}
 		readmeContent := bundle.GenerateReadme(readmeData)
 
@@ -299,7 +348,7 @@ func createBundles(config CreateBundlesConfig) ([]BundleOutput, error) {
 		// Web-created bundles always use the GitHub Pages recovery URL
 		pdfData := pdf.ReadmeData{
 			ProjectName:      config.ProjectName,
-			Holder:           friend.Name,
+			Holder:           share.Holder,
 			Share:            share,
 			OtherFriends:     otherFriends,
 			Threshold:        k,
@@ -309,9 +358,10 @@ func createBundles(config CreateBundlesConfig) ([]BundleOutput, error) {
 			ManifestChecksum: manifestChecksum,
 			RecoverChecksum:  recoverChecksum,
 			Created:          now,
-			Anonymous:        config.Anonymous,
+			Privacy:          config.Privacy,
 			Language:         lang,
 			ManifestEmbedded: manifestEmbedded,
+			FAQ:              facts,
 		}
 		pdfContent, err := pdf.GenerateReadme(pdfData)
 		if err != nil {
@@ -331,6 +381,11 @@ func createBundles(config CreateBundlesConfig) ([]BundleOutput, error) {
 		if !manifestEmbedded {
 			zipFiles = append(zipFiles, bundle.ZipFile{Name: "MANIFEST.age", Content: manifestData, ModTime: now})
 		}
+		buildInfoFile, err := buildInfo.File(zipFiles, now)
+		if err != nil {
+			return nil, err
+		}
+		zipFiles = append(zipFiles, buildInfoFile)
 
 		zipData, err := createZipInMemory(zipFiles)
 		if err != nil {
@@ -366,7 +421,8 @@ func createTarGz(files []FileEntry) ([]byte, error) {
 		return nil, fmt.Errorf("writing directory header: %w", err)
 	}
 
-	for _, f := range files {
+	paths := make([]string, len(files))
+	for i, f := range files {
 		// Normalize the file path - ensure it's under manifest/
 		name := f.Name
 		// Remove leading slashes or "manifest/" prefix if present
@@ -379,7 +435,17 @@ func createTarGz(files []FileEntry) ([]byte, error) {
 			name = name[9:]
 		}
 		// Add the manifest/ prefix
-		fullPath := rootDir + "/" + name
+		paths[i] = rootDir + "/" + name
+	}
+	if collisions := core.CaseCollisions(paths); len(collisions) > 0 {
+		return nil, fmt.Errorf("%s differ only in case, so on a Mac or Windows computer one would overwrite the other when recovered; rename one", strings.Join(collisions[0], " and "))
+	}
+
+	index := core.NewIndex()
+	stored := make(map[[sha256.Size]byte]string) // By SHA-256, the name first archived with it
+	for i, f := range files {
+		fullPath := paths[i]
+		sum := sha256.Sum256(f.Data)
 
 		header := &tar.Header{
 			Name:     fullPath,
@@ -388,14 +454,46 @@ func createTarGz(files []FileEntry) ([]byte, error) {
 			ModTime:  time.Now().UTC(),
 			Typeflag: tar.TypeReg,
 		}
+		// A copy of a file before it is stored once, as 'rememory seal'
+		// stores it: a hard link to the first
+		first, copied := stored[sum]
+		if copied && len(f.Data) > 0 {
+			header.Typeflag = tar.TypeLink
+			header.Linkname = first
+			header.Size = 0
+		}
 
 		if err := tw.WriteHeader(header); err != nil {
 			return nil, fmt.Errorf("writing header for %s: %w", f.Name, err)
 		}
 
-		if _, err := tw.Write(f.Data); err != nil {
-			return nil, fmt.Errorf("writing data for %s: %w", f.Name, err)
+		if header.Typeflag == tar.TypeReg {
+			if _, err := tw.Write(f.Data); err != nil {
+				return nil, fmt.Errorf("writing data for %s: %w", f.Name, err)
+			}
+			if !copied {
+				stored[sum] = fullPath
+			}
 		}
+		index.Add(fullPath, int64(len(f.Data)), sum[:], header.ModTime)
+	}
+
+	// The index of the files goes last, as 'rememory seal' writes it
+	indexData, err := index.Encode()
+	if err != nil {
+		return nil, fmt.Errorf("encoding the file index: %w", err)
+	}
+	if err := tw.WriteHeader(&tar.Header{
+		Name:     core.IndexFile,
+		Mode:     0600,
+		Size:     int64(len(indexData)),
+		ModTime:  time.Now().UTC(),
+		Typeflag: tar.TypeReg,
+	}); err != nil {
+		return nil, fmt.Errorf("writing the file index: %w", err)
+	}
+	if _, err := tw.Write(indexData); err != nil {
+		return nil, fmt.Errorf("writing the file index: %w", err)
 	}
 
 	if err := tw.Close(); err != nil {
@@ -445,6 +543,45 @@ func trimLeadingSlashes(s string) string {
 	return s
 }
 
+// bundleBaseSize is bundle.BaseSize for the embedded recovery tool, worked
+// out once: it compresses the whole recover.wasm.
+var bundleBaseSize = sync.OnceValue(func() int64 {
+	return bundle.BaseSize(html.GetRecoverWASMBytes())
+})
+
+// estimateCapacityJS measures the pieces and bundles a split would make, so
+// maker.html can show them while the numbers are being chosen.
+// Args: total (number), threshold (number), manifestSize (number, bytes)
+// Returns: { capacity: {...}, bundleSize: number, error: string|null }
+func estimateCapacityJS(this js.Value, args []js.Value) any {
+	if len(args) < 3 {
+		return errorResult("missing total, threshold, or manifestSize argument")
+	}
+	total, threshold := args[0].Int(), args[1].Int()
+	c := core.EstimateCapacity(total, threshold, 0, "")
+
+	warnings := make([]any, len(c.Warnings))
+	for i, w := range c.Warnings {
+		warnings[i] = map[string]any{"code": w.Code, "count": w.Count, "message": w.Message}
+	}
+	return js.ValueOf(map[string]any{
+		"capacity": map[string]any{
+			"shareBlock":  c.ShareBlock,
+			"compact":     c.Compact,
+			"qrContent":   c.QRContent,
+			"qrCodes":     c.QRCodes,
+			"qrVersion":   c.QRVersion,
+			"qrModules":   c.QRModules,
+			"words":       c.Words,
+			"digitGroups": c.DigitGroups,
+			"rm1":         c.Bech32,
+			"warnings":    warnings,
+		},
+		"bundleSize": bundle.EstimateSize(bundleBaseSize(), int64(args[2].Float())),
+		"error":      nil,
+	})
+}
+
 // parseProjectYAMLJS parses a project.yml file to extract friend information.
 // Args: yamlText (string)
 // Returns: { project: {...}, error: string|null }
@@ -475,6 +612,7 @@ func parseProjectYAMLJS(this js.Value, args []js.Value) any {
 			"name":      proj.Name,
 			"threshold": proj.Threshold,
 			"language":  proj.Language,
+			"privacy":   string((&project.Project{Anonymous: proj.Anonymous, Privacy: project.Privacy(proj.Privacy)}).PrivacyLevel()),
 			"friends":   friends,
 		},
 		"error": nil,
@@ -486,10 +624,17 @@ type ProjectYAML struct {
 	Name      string `yaml:"name"`
 	Threshold int    `yaml:"threshold"`
 	Language  string `yaml:"language,omitempty"`
-	Friends   []struct {
+	Anonymous bool   `yaml:"anonymous,omitempty"`
+	Privacy   string `yaml:"privacy,omitempty"`
+	Groups    []struct {
+		Name string `yaml:"name"`
+	} `yaml:"groups,omitempty"`
+	Friends []struct {
 		Name     string `yaml:"name"`
 		Contact  string `yaml:"contact,omitempty"`
 		Language string `yaml:"language,omitempty"`
+		Shares   int    `yaml:"shares,omitempty"`
+		PIN      bool   `yaml:"pin,omitempty"`
 	} `yaml:"friends"`
 }
 
@@ -499,6 +644,18 @@ func parseProjectYAML(yamlText string) (*ProjectYAML, error) {
 	if err := yaml.Unmarshal([]byte(yamlText), &proj); err != nil {
 		return nil, fmt.Errorf("parsing YAML: %w", err)
 	}
+	// The maker splits among friends only; importing would silently drop the groups
+	if len(proj.Groups) > 0 {
+		return nil, fmt.Errorf("this project has groups, which only the rememory command line can seal")
+	}
+	for _, f := range proj.Friends {
+		if f.Shares > 1 {
+			return nil, fmt.Errorf("%s holds %d pieces, which only the rememory command line can seal", f.Name, f.Shares)
+		}
+		if f.PIN {
+			return nil, fmt.Errorf("%s's piece is locked with a PIN, which only the rememory command line can seal", f.Name)
+		}
+	}
 	return &proj, nil
 }
 
@@ -3,32 +3,51 @@
 package main
 
 import (
+	"errors"
+	"io/fs"
 	"syscall/js"
+
+	"github.com/eljojo/rememory/internal/core"
 )
 
-// parseShareJS parses a share from text content.
+// pageRestricted reports whether this recover.html was made for a project
+// sealed under the restricted crypto profile.
+func pageRestricted() bool {
+	p := js.Global().Get("PERSONALIZATION")
+	if p.Type() != js.TypeObject {
+		return false
+	}
+	crypto := p.Get("crypto")
+	return crypto.Type() == js.TypeString && crypto.String() == core.CryptoRestricted
+}
+
+// parseShareJS parses a share from text content. A friend who holds several
+// pieces has the rest in extra.
 // Args: content (string)
-// Returns: { share: {...}, error: string|null }
+// Returns: { share: {...}, extra: [{...}], error: string|null }
 func parseShareJS(this js.Value, args []js.Value) any {
 	if len(args) < 1 {
 		return errorResult("missing content argument")
 	}
 
 	content := args[0].String()
-	share, err := parseShare(content)
+	shares, err := parseShares(content)
 	if err != nil {
 		return errorResult(err.Error())
 	}
 
 	return js.ValueOf(map[string]any{
-		"share": shareInfoToJS(share),
+		"share": shareInfoToJS(shares[0]),
+		"extra": sharesToJS(shares[1:]),
 		"error": nil,
 	})
 }
 
 // combineSharesJS combines multiple shares to recover the passphrase.
 // Args: sharesJSON (array of share objects with dataB64)
-// Returns: { passphrase: string, error: string|null }
+// Returns: { passphrase: Uint8Array, error: string|null }
+// The passphrase is bytes, not a string, so the page can zero it once the
+// manifest is open; the Go copy is zeroed before returning.
 func combineSharesJS(this js.Value, args []js.Value) any {
 	if len(args) < 1 {
 		return errorResult("missing shares argument")
@@ -46,21 +65,39 @@ func combineSharesJS(this js.Value, args []js.Value) any {
 			Threshold: shareObj.Get("threshold").Int(),
 			DataB64:   shareObj.Get("dataB64").String(),
 		}
+		if group := shareObj.Get("group"); group.Type() == js.TypeNumber {
+			shares[i].Group = group.Int()
+			shares[i].Groups = shareObj.Get("groups").Int()
+			shares[i].GroupsNeeded = shareObj.Get("groupsNeeded").Int()
+		}
+		if format := shareObj.Get("format"); format.Type() == js.TypeString {
+			shares[i].Format = format.String()
+		}
+		if id := shareObj.Get("bundleId"); id.Type() == js.TypeString {
+			shares[i].BundleID = id.String()
+		}
+		if mac := shareObj.Get("mac"); mac.Type() == js.TypeString {
+			shares[i].MAC = mac.String()
+		}
 	}
 
-	passphrase, err := combineShares(shares)
+	passphrase, err := combineShares(shares, pageRestricted())
 	if err != nil {
 		return errorResult(err.Error())
 	}
+	defer passphrase.Wipe()
+
+	jsPassphrase := js.Global().Get("Uint8Array").New(len(passphrase))
+	js.CopyBytesToJS(jsPassphrase, passphrase)
 
 	return js.ValueOf(map[string]any{
-		"passphrase": passphrase,
+		"passphrase": jsPassphrase,
 		"error":      nil,
 	})
 }
 
 // decryptManifestJS decrypts an age-encrypted manifest.
-// Args: encryptedData (Uint8Array), passphrase (string)
+// Args: encryptedData (Uint8Array), passphrase (Uint8Array)
 // Returns: { data: Uint8Array, error: string|null }
 func decryptManifestJS(this js.Value, args []js.Value) any {
 	if len(args) < 2 {
@@ -73,9 +110,12 @@ func decryptManifestJS(this js.Value, args []js.Value) any {
 	encryptedData := make([]byte, dataLen)
 	js.CopyBytesToGo(encryptedData, jsData)
 
-	passphrase := args[1].String()
+	jsPassphrase := args[1]
+	passphrase := make(core.Secret, jsPassphrase.Get("length").Int())
+	js.CopyBytesToGo(passphrase, jsPassphrase)
+	defer passphrase.Wipe()
 
-	decrypted, err := decryptManifest(encryptedData, passphrase)
+	decrypted, err := decryptManifest(encryptedData, passphrase, pageRestricted())
 	if err != nil {
 		return errorResult(err.Error())
 	}
@@ -90,9 +130,173 @@ func decryptManifestJS(this js.Value, args []js.Value) any {
 	})
 }
 
-// extractTarGzJS extracts files from tar.gz data.
+// checkMemoryJS says whether this device can spare the memory unlocking a
+// manifest takes, before trying: running out partway would stop the
+// recovery tool for good instead of returning an error.
+// Args: encryptedData (Uint8Array; only its header is read)
+// Returns: { ok: boolean, bytes: number, error: string|null }
+func checkMemoryJS(this js.Value, args []js.Value) any {
+	if len(args) < 1 {
+		return errorResult("missing encryptedData argument")
+	}
+	jsHeader := args[0].Call("subarray", 0, 64*1024)
+	header := make([]byte, jsHeader.Get("length").Int())
+	js.CopyBytesToGo(header, jsHeader)
+
+	need, err := manifestMemory(header)
+	if err != nil {
+		return errorResult(err.Error())
+	}
+	return js.ValueOf(map[string]any{
+		"ok":    need == 0 || canSpareMemory(need),
+		"bytes": float64(need),
+		"error": nil,
+	})
+}
+
+// canSpareMemory reports whether the browser gives this page size more
+// bytes of WebAssembly memory, by growing a memory of its own to that size
+// and letting it go. Go's own heap can't be asked: when the browser refuses
+// it more, the program ends.
+func canSpareMemory(size int64) (ok bool) {
+	const pageSize = 64 * 1024
+	pages := (size + pageSize - 1) / pageSize
+	if pages > 65536 { // 4 GiB, all a WebAssembly memory can hold
+		return false
+	}
+	defer func() {
+		if recover() != nil {
+			ok = false
+		}
+	}()
+	memory := js.Global().Get("WebAssembly").Get("Memory").New(map[string]any{"initial": 1, "maximum": pages})
+	memory.Call("grow", pages-1)
+	return true
+}
+
+// timelockStartJS starts solving the recovery-delay puzzle.
+// Args: timelockJSON (string, TIMELOCK.json), passphrase (Uint8Array)
+// Returns: { handle: number, total: number, delaySeconds: number, error: string|null }
+func timelockStartJS(this js.Value, args []js.Value) any {
+	if len(args) < 2 {
+		return errorResult("missing arguments (need timelockJSON, passphrase)")
+	}
+	jsPassphrase := args[1]
+	passphrase := make(core.Secret, jsPassphrase.Get("length").Int())
+	js.CopyBytesToGo(passphrase, jsPassphrase)
+	defer passphrase.Wipe()
+
+	handle, tl, err := startTimelock([]byte(args[0].String()), passphrase)
+	if err != nil {
+		return errorResult(err.Error())
+	}
+	return js.ValueOf(map[string]any{
+		"handle":       handle,
+		"total":        float64(tl.Squarings),
+		"delaySeconds": tl.SealedDelay().Seconds(),
+		"error":        nil,
+	})
+}
+
+// timelockStepJS does up to count more squarings of a puzzle.
+// Args: handle (number), count (number)
+// Returns: { done: number, total: number, passphrase: Uint8Array|null, error: string|null }
+// passphrase is set once the puzzle is solved.
+func timelockStepJS(this js.Value, args []js.Value) any {
+	if len(args) < 2 || args[0].Type() != js.TypeNumber || args[1].Type() != js.TypeNumber || args[1].Int() < 1 {
+		return errorResult("missing arguments (need handle, count)")
+	}
+	done, total, passphrase, err := stepTimelock(args[0].Int(), uint64(args[1].Int()))
+	if err != nil {
+		return errorResult(err.Error())
+	}
+	result := map[string]any{
+		"done":       float64(done),
+		"total":      float64(total),
+		"passphrase": nil,
+		"error":      nil,
+	}
+	if passphrase != nil {
+		jsPassphrase := js.Global().Get("Uint8Array").New(len(passphrase))
+		js.CopyBytesToJS(jsPassphrase, passphrase)
+		passphrase.Wipe()
+		result["passphrase"] = jsPassphrase
+	}
+	return js.ValueOf(result)
+}
+
+// openCatalogJS opens the catalog embedded in recover.html.
+// Args: catalogB64 (string), shares (array of {index, catalogThreshold, catalogB64})
+// Returns: { catalog: {project, description, sealed, files: [{path, size}]}, error: string|null }
+func openCatalogJS(this js.Value, args []js.Value) any {
+	if len(args) < 2 {
+		return errorResult("missing catalog or shares argument")
+	}
+	pieces := make([]ShareInfo, args[1].Length())
+	for i := range pieces {
+		obj := args[1].Index(i)
+		pieces[i] = ShareInfo{Index: obj.Get("index").Int()}
+		if t := obj.Get("catalogThreshold"); t.Type() == js.TypeNumber {
+			pieces[i].CatalogThreshold = t.Int()
+		}
+		if b := obj.Get("catalogB64"); b.Type() == js.TypeString {
+			pieces[i].CatalogB64 = b.String()
+		}
+	}
+
+	catalog, err := openCatalog(args[0].String(), pieces)
+	if err != nil {
+		return errorResult(err.Error())
+	}
+	files := make([]any, len(catalog.Files))
+	for i, f := range catalog.Files {
+		files[i] = map[string]any{"path": f.Path, "size": float64(f.Size)}
+	}
+	return js.ValueOf(map[string]any{
+		"catalog": map[string]any{
+			"project":     catalog.Project,
+			"description": catalog.Description,
+			"sealed":      catalog.Sealed.Format(core.DateFormat),
+			"files":       files,
+		},
+		"error": nil,
+	})
+}
+
+// checkVSSJS checks a piece against the seal's Feldman commitments.
+// Args: commitments (array of strings), index (number), vssB64 (string)
+// Returns: { ok: boolean, error: string|null }
+func checkVSSJS(this js.Value, args []js.Value) any {
+	if len(args) < 3 {
+		return errorResult("missing commitments, index or vssB64 argument")
+	}
+	commitments := make([]string, args[0].Length())
+	for i := range commitments {
+		commitments[i] = args[0].Index(i).String()
+	}
+	if err := checkVSS(commitments, args[1].Int(), args[2].String()); err != nil {
+		return js.ValueOf(map[string]any{"ok": false, "error": err.Error()})
+	}
+	return js.ValueOf(map[string]any{"ok": true, "error": nil})
+}
+
+// estimateUnlockJS estimates how long decrypting will take on this device.
+// Args: workFactor (number, the scrypt log2(N) pieces record)
+// Returns: { seconds: number, error: string|null }
+func estimateUnlockJS(this js.Value, args []js.Value) any {
+	if len(args) < 1 || args[0].Type() != js.TypeNumber || args[0].Int() < 1 {
+		return errorResult("missing workFactor argument")
+	}
+	return js.ValueOf(map[string]any{
+		"seconds": estimateUnlock(args[0].Int()),
+		"error":   nil,
+	})
+}
+
+// extractTarGzJS extracts files from tar.gz data, checking each against the
+// archive's index.
 // Args: tarGzData (Uint8Array)
-// Returns: { files: [{name: string, data: Uint8Array}], error: string|null }
+// Returns: { files: [{name, data, dir, link, status}], index: [{path, size, status}]|null, error: string|null }
 func extractTarGzJS(this js.Value, args []js.Value) any {
 	if len(args) < 1 {
 		return errorResult("missing tarGzData argument")
@@ -104,7 +308,7 @@ func extractTarGzJS(this js.Value, args []js.Value) any {
 	tarGzData := make([]byte, dataLen)
 	js.CopyBytesToGo(tarGzData, jsData)
 
-	files, err := extractTarGz(tarGzData)
+	files, checks, err := extractTarGz(tarGzData)
 	if err != nil {
 		return errorResult(err.Error())
 	}
@@ -115,17 +319,93 @@ func extractTarGzJS(this js.Value, args []js.Value) any {
 		jsFileData := js.Global().Get("Uint8Array").New(len(f.Data))
 		js.CopyBytesToJS(jsFileData, f.Data)
 		jsFiles[i] = map[string]any{
-			"name": f.Name,
-			"data": jsFileData,
+			"name":   f.Name,
+			"data":   jsFileData,
+			"dir":    f.Dir,
+			"link":   f.Link,
+			"status": f.Status,
+		}
+	}
+
+	// Missing files are only in the index, so it goes along too
+	var jsIndex any
+	if checks != nil {
+		entries := make([]any, len(checks))
+		for i, c := range checks {
+			entries[i] = map[string]any{
+				"path":   c.Path,
+				"size":   c.Size,
+				"status": c.Status,
+			}
 		}
+		jsIndex = entries
 	}
 
 	return js.ValueOf(map[string]any{
 		"files": jsFiles,
+		"index": jsIndex,
 		"error": nil,
 	})
 }
 
+// listTarGzJS lists the files in tar.gz data without extracting them.
+// Args: tarGzData (Uint8Array)
+// Returns: { files: [{path: string, size: number}], indexed: bool, error: string|null }
+func listTarGzJS(this js.Value, args []js.Value) any {
+	if len(args) < 1 {
+		return errorResult("missing tarGzData argument")
+	}
+
+	jsData := args[0]
+	tarGzData := make([]byte, jsData.Get("length").Int())
+	js.CopyBytesToGo(tarGzData, jsData)
+
+	entries, indexed, err := listTarGz(tarGzData)
+	if err != nil {
+		return errorResult(err.Error())
+	}
+
+	jsFiles := make([]any, len(entries))
+	for i, e := range entries {
+		jsFiles[i] = map[string]any{
+			"path": e.Path,
+			"size": e.Size,
+		}
+	}
+	return js.ValueOf(map[string]any{
+		"files":   jsFiles,
+		"indexed": indexed,
+		"error":   nil,
+	})
+}
+
+// selfTestJS checks that this copy of the page still recovers, with the
+// sample bundle built into it.
+// Returns: { checks: [{name, ok, error}], error: string|null }
+func selfTestJS(this js.Value, args []js.Value) any {
+	sample, err := fs.Sub(selfTestFiles, "selftest")
+	if err != nil {
+		return errorResult(err.Error())
+	}
+	checks, err := selfTest(sample)
+	if err != nil {
+		return errorResult(err.Error())
+	}
+
+	jsChecks := make([]any, len(checks))
+	for i, c := range checks {
+		check := map[string]any{"name": c.Name, "ok": c.Err == nil, "error": nil}
+		if c.Err != nil {
+			check["error"] = c.Err.Error()
+		}
+		jsChecks[i] = check
+	}
+	return js.ValueOf(map[string]any{
+		"checks": jsChecks,
+		"error":  nil,
+	})
+}
+
 // extractBundleJS extracts share and manifest from a bundle ZIP.
 // Args: zipData (Uint8Array)
 // Returns: { share: {...}, manifest: Uint8Array|null, error: string|null }
@@ -147,6 +427,7 @@ func extractBundleJS(this js.Value, args []js.Value) any {
 
 	result := map[string]any{
 		"share": shareInfoToJS(bundle.Share),
+		"extra": sharesToJS(bundle.Extra),
 		"error": nil,
 	}
 
@@ -158,6 +439,17 @@ func extractBundleJS(this js.Value, args []js.Value) any {
 	} else {
 		result["manifest"] = nil
 	}
+	if len(bundle.Decoy) > 0 {
+		jsDecoy := js.Global().Get("Uint8Array").New(len(bundle.Decoy))
+		js.CopyBytesToJS(jsDecoy, bundle.Decoy)
+		result["decoy"] = jsDecoy
+	}
+	if len(bundle.Timelock) > 0 {
+		result["timelock"] = string(bundle.Timelock)
+	}
+	if bundle.Repaired > 0 {
+		result["repaired"] = bundle.Repaired
+	}
 
 	return js.ValueOf(result)
 }
@@ -182,12 +474,138 @@ func parseCompactShareJS(this js.Value, args []js.Value) any {
 	})
 }
 
+// unlockShareJS unlocks a piece locked with a PIN.
+// Args: share (object with index, dataB64, pinSalt, pinCheck), pin (string)
+// Returns: { dataB64: string, wrongPin: bool, error: string|null }
+func unlockShareJS(this js.Value, args []js.Value) any {
+	if len(args) < 2 {
+		return errorResult("missing arguments (need share, pin)")
+	}
+	share := args[0]
+	data, err := unlockShare(share.Get("index").Int(), share.Get("dataB64").String(),
+		share.Get("pinSalt").String(), share.Get("pinCheck").String(), args[1].String())
+	if errors.Is(err, core.ErrWrongPIN) {
+		return js.ValueOf(map[string]any{"wrongPin": true, "error": err.Error()})
+	}
+	if err != nil {
+		return errorResult(err.Error())
+	}
+	return js.ValueOf(map[string]any{
+		"dataB64": data,
+		"error":   nil,
+	})
+}
+
+// parseSSKRShareJS parses an SSKR share, as a UR or as bytewords.
+// Args: text (string)
+// Returns: { share: {...}, error: string|null }
+func parseSSKRShareJS(this js.Value, args []js.Value) any {
+	if len(args) < 1 {
+		return errorResult("missing SSKR share argument")
+	}
+
+	share, err := parseSSKRShare(args[0].String())
+	if err != nil {
+		return errorResult(err.Error())
+	}
+
+	return js.ValueOf(map[string]any{
+		"share": shareInfoToJS(share),
+		"error": nil,
+	})
+}
+
+// joinQRChunksJS puts the parts of a split QR code back together.
+// Args: parts (string array, one per code)
+// Returns: { content: string, error: string|null }
+func joinQRChunksJS(this js.Value, args []js.Value) any {
+	if len(args) < 1 {
+		return errorResult("missing parts argument")
+	}
+
+	parts := make([]string, args[0].Length())
+	for i := range parts {
+		parts[i] = args[0].Index(i).String()
+	}
+	content, err := joinQRChunks(parts)
+	if err != nil {
+		return errorResult(err.Error())
+	}
+
+	return js.ValueOf(map[string]any{
+		"content": content,
+		"error":   nil,
+	})
+}
+
+// joinVolumesJS puts MANIFEST.age back together from the volumes it was
+// split into.
+// Args: volumes (array of { name: string, data: Uint8Array }), index (string, MANIFEST.age.sha256; may be empty), parity (Uint8Array, MANIFEST.age.parity; optional)
+// Returns: { data: Uint8Array, repaired: number, error: string|null }
+func joinVolumesJS(this js.Value, args []js.Value) any {
+	if len(args) < 2 {
+		return errorResult("missing volumes or index argument")
+	}
+	var parity []byte
+	if len(args) > 2 && !args[2].IsUndefined() && !args[2].IsNull() {
+		parity = make([]byte, args[2].Get("length").Int())
+		js.CopyBytesToGo(parity, args[2])
+	}
+
+	parts := make([]core.VolumePart, args[0].Length())
+	for i := range parts {
+		v := args[0].Index(i)
+		jsData := v.Get("data")
+		data := make([]byte, jsData.Get("length").Int())
+		js.CopyBytesToGo(data, jsData)
+		parts[i] = core.VolumePart{Name: v.Get("name").String(), Data: data}
+	}
+	manifest, repaired, err := joinVolumes(parts, []byte(args[1].String()), parity)
+	if err != nil {
+		return errorResult(err.Error())
+	}
+
+	jsResult := js.Global().Get("Uint8Array").New(len(manifest))
+	js.CopyBytesToJS(jsResult, manifest)
+	return js.ValueOf(map[string]any{
+		"data":     jsResult,
+		"repaired": repaired,
+		"error":    nil,
+	})
+}
+
+// repairManifestJS repairs MANIFEST.age with MANIFEST.age.parity, rebuilding
+// the blocks that went bad since the seal.
+// Args: manifest (Uint8Array), parity (Uint8Array)
+// Returns: { data: Uint8Array, repaired: number, error: string|null }
+func repairManifestJS(this js.Value, args []js.Value) any {
+	if len(args) < 2 {
+		return errorResult("missing manifest or parity argument")
+	}
+	manifest := make([]byte, args[0].Get("length").Int())
+	js.CopyBytesToGo(manifest, args[0])
+	parity := make([]byte, args[1].Get("length").Int())
+	js.CopyBytesToGo(parity, args[1])
+
+	repaired, n, err := core.RepairParity(manifest, parity)
+	if err != nil {
+		return errorResult(err.Error())
+	}
+	jsResult := js.Global().Get("Uint8Array").New(len(repaired))
+	js.CopyBytesToJS(jsResult, repaired)
+	return js.ValueOf(map[string]any{
+		"data":     jsResult,
+		"repaired": n,
+		"error":    nil,
+	})
+}
+
 // decodeWordsJS decodes 25 BIP39 words to raw share data bytes and share index.
 // The first 24 words encode the data; the 25th word packs 4 bits of index + 7 bits of checksum.
 // Returns index=0 if the share index was > 15 (sentinel for "unknown — UI should not highlight a specific contact").
 // Returns an error if the embedded checksum doesn't match (wrong word order, typos, etc.).
 // Args: words (string array)
-// Returns: { data: Uint8Array, index: number, checksum: string, error: string|null }
+// Returns: { data: Uint8Array, index: number, checksum: string, lang: string, bundle: string, error: string|null }
 func decodeWordsJS(this js.Value, args []js.Value) any {
 	if len(args) < 1 {
 		return errorResult("missing words argument")
@@ -213,6 +631,34 @@ func decodeWordsJS(this js.Value, args []js.Value) any {
 		"index":    index,
 		"checksum": checksum,
 		"lang":     lang,
+		"bundle":   core.DataBundle(data),
+		"error":    nil,
+	})
+}
+
+// decodeDigitsJS decodes the 18 digit groups to raw share data bytes and
+// share index. Each group's check digit catches a misheard digit as it is
+// typed; the last group checks the whole share.
+// Args: text (string)
+// Returns: { data: Uint8Array, index: number, checksum: string, bundle: string, error: string|null }
+func decodeDigitsJS(this js.Value, args []js.Value) any {
+	if len(args) < 1 {
+		return errorResult("missing digits argument")
+	}
+
+	data, index, checksum, err := decodeShareDigits(args[0].String())
+	if err != nil {
+		return errorResult(err.Error())
+	}
+
+	jsData := js.Global().Get("Uint8Array").New(len(data))
+	js.CopyBytesToJS(jsData, data)
+
+	return js.ValueOf(map[string]any{
+		"data":     jsData,
+		"index":    index,
+		"checksum": checksum,
+		"bundle":   core.DataBundle(data),
 		"error":    nil,
 	})
 }
@@ -220,16 +666,42 @@ func decodeWordsJS(this js.Value, args []js.Value) any {
 // shareInfoToJS converts a ShareInfo to a JS-compatible map.
 func shareInfoToJS(s *ShareInfo) map[string]any {
 	return map[string]any{
-		"version":   s.Version,
-		"index":     s.Index,
-		"total":     s.Total,
-		"threshold": s.Threshold,
-		"holder":    s.Holder,
-		"created":   s.Created,
-		"checksum":  s.Checksum,
-		"dataB64":   s.DataB64,
-		"compact":   s.Compact,
+		"version":      s.Version,
+		"index":        s.Index,
+		"total":        s.Total,
+		"threshold":    s.Threshold,
+		"holder":       s.Holder,
+		"group":        s.Group,
+		"groups":       s.Groups,
+		"groupsNeeded": s.GroupsNeeded,
+		"created":      s.Created,
+		"checksum":     s.Checksum,
+		"dataB64":      s.DataB64,
+		"compact":      s.Compact,
+		"format":       s.Format,
+		"reviewBy":     s.ReviewBy,
+		"expires":      s.Expires,
+		"bundleId":     s.BundleID,
+		"bundle":       s.Bundle,
+		"mac":          s.MAC,
+		"pinSalt":      s.PINSalt,
+		"pinCheck":     s.PINCheck,
+		"workFactor":   s.WorkFactor,
+
+		"catalogThreshold": s.CatalogThreshold,
+		"catalogB64":       s.CatalogB64,
+
+		"vssB64": s.VSSB64,
+	}
+}
+
+// sharesToJS converts shares to a JS array.
+func sharesToJS(shares []*ShareInfo) []any {
+	out := make([]any, len(shares))
+	for i, s := range shares {
+		out[i] = shareInfoToJS(s)
 	}
+	return out
 }
 
 func errorResult(msg string) any {
@@ -237,3 +709,16 @@ func errorResult(msg string) any {
 		"error": msg,
 	})
 }
+
+// validationResult is errorResult that also names the field a failed
+// check is about, when there is one.
+func validationResult(err error) any {
+	ve := core.AsValidationError(err)
+	if ve == nil {
+		return errorResult(err.Error())
+	}
+	return js.ValueOf(map[string]any{
+		"error": ve.Message,
+		"field": ve.Field,
+	})
+}
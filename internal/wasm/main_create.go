@@ -10,8 +10,11 @@ func main() {
 	// Register recovery functions (also needed for creation tool's recovery preview)
 	js.Global().Set("rememoryParseShare", js.FuncOf(parseShareJS))
 	js.Global().Set("rememoryCombineShares", js.FuncOf(combineSharesJS))
+	js.Global().Set("rememoryUnlockShare", js.FuncOf(unlockShareJS))
 	js.Global().Set("rememoryDecryptManifest", js.FuncOf(decryptManifestJS))
+	js.Global().Set("rememoryEstimateUnlock", js.FuncOf(estimateUnlockJS))
 	js.Global().Set("rememoryExtractTarGz", js.FuncOf(extractTarGzJS))
+	js.Global().Set("rememoryListTarGz", js.FuncOf(listTarGzJS))
 	js.Global().Set("rememoryExtractBundle", js.FuncOf(extractBundleJS))
 	js.Global().Set("rememoryParseCompactShare", js.FuncOf(parseCompactShareJS))
 	js.Global().Set("rememoryDecodeWords", js.FuncOf(decodeWordsJS))
@@ -19,6 +22,7 @@ func main() {
 	// Register bundle creation functions
 	js.Global().Set("rememoryCreateBundles", js.FuncOf(createBundlesJS))
 	js.Global().Set("rememoryParseProjectYAML", js.FuncOf(parseProjectYAMLJS))
+	js.Global().Set("rememoryEstimateCapacity", js.FuncOf(estimateCapacityJS))
 
 	// Signal that WASM is ready
 	js.Global().Set("rememoryReady", true)
@@ -1,4 +1,4 @@
-//go:build js && wasm && !create
+//go:build js && wasm && !create && !verify
 
 package main
 
@@ -10,11 +10,25 @@ func main() {
 	// Register recovery functions on the global object
 	js.Global().Set("rememoryParseShare", js.FuncOf(parseShareJS))
 	js.Global().Set("rememoryCombineShares", js.FuncOf(combineSharesJS))
+	js.Global().Set("rememoryUnlockShare", js.FuncOf(unlockShareJS))
 	js.Global().Set("rememoryDecryptManifest", js.FuncOf(decryptManifestJS))
+	js.Global().Set("rememoryEstimateUnlock", js.FuncOf(estimateUnlockJS))
+	js.Global().Set("rememoryCheckMemory", js.FuncOf(checkMemoryJS))
+	js.Global().Set("rememoryTimelockStart", js.FuncOf(timelockStartJS))
+	js.Global().Set("rememoryTimelockStep", js.FuncOf(timelockStepJS))
+	js.Global().Set("rememoryOpenCatalog", js.FuncOf(openCatalogJS))
+	js.Global().Set("rememoryCheckVSS", js.FuncOf(checkVSSJS))
 	js.Global().Set("rememoryExtractTarGz", js.FuncOf(extractTarGzJS))
+	js.Global().Set("rememoryListTarGz", js.FuncOf(listTarGzJS))
 	js.Global().Set("rememoryExtractBundle", js.FuncOf(extractBundleJS))
 	js.Global().Set("rememoryParseCompactShare", js.FuncOf(parseCompactShareJS))
 	js.Global().Set("rememoryDecodeWords", js.FuncOf(decodeWordsJS))
+	js.Global().Set("rememoryDecodeDigits", js.FuncOf(decodeDigitsJS))
+	js.Global().Set("rememoryParseSSKRShare", js.FuncOf(parseSSKRShareJS))
+	js.Global().Set("rememoryJoinQRChunks", js.FuncOf(joinQRChunksJS))
+	js.Global().Set("rememoryJoinVolumes", js.FuncOf(joinVolumesJS))
+	js.Global().Set("rememoryRepairManifest", js.FuncOf(repairManifestJS))
+	js.Global().Set("rememorySelfTest", js.FuncOf(selfTestJS))
 
 	// Signal that WASM is ready
 	js.Global().Set("rememoryReady", true)
@@ -0,0 +1,20 @@
+//go:build js && wasm && verify
+
+package main
+
+import (
+	"syscall/js"
+)
+
+func main() {
+	// Register only the checks VERIFY.html needs; nothing here can combine
+	// pieces or decrypt a manifest
+	js.Global().Set("rememoryVerifyPiece", js.FuncOf(verifyPieceJS))
+	js.Global().Set("rememoryVerifyBundleFiles", js.FuncOf(verifyBundleFilesJS))
+
+	// Signal that WASM is ready
+	js.Global().Set("rememoryReady", true)
+
+	// Keep the Go program running
+	select {}
+}
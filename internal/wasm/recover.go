@@ -6,8 +6,11 @@ import (
 	"archive/zip"
 	"bytes"
 	"encoding/base64"
+	"errors"
 	"fmt"
 	"io"
+	"strings"
+	"time"
 
 	"github.com/eljojo/rememory/internal/core"
 	"github.com/eljojo/rememory/internal/translations"
@@ -16,45 +19,89 @@ import (
 // ShareInfo contains parsed share metadata for JS interop.
 // This wraps core.Share with base64-encoded data for transport to/from JS.
 type ShareInfo struct {
-	Version   int
-	Index     int
-	Total     int
-	Threshold int
-	Holder    string
-	Created   string // RFC3339 formatted
-	Checksum  string
-	DataB64   string // Base64 encoded share data for transport
-	Compact   string // Compact-encoded share string (e.g. RM1:2:5:3:BASE64:CHECK)
+	Version      int
+	Index        int
+	Total        int
+	Threshold    int
+	Holder       string
+	Group        int // Group of a grouped project's piece, else 0
+	Groups       int
+	GroupsNeeded int
+	Created      string // RFC3339 formatted
+	Checksum     string
+	DataB64      string // Base64 encoded share data for transport
+	Compact      string // Compact-encoded share string (e.g. RM1:2:5:3:BASE64:CHECK)
+	Format       string // "sskr" for SSKR shares; empty for ReMemory pieces
+	ReviewBy     string // Review date (YYYY-MM-DD) the owner set, or empty
+	Expires      string // Expiry date (YYYY-MM-DD) the owner set, or empty
+	BundleID     string // Bundle ID the piece records, or empty
+	Bundle       string // Short form of the bundle the piece belongs to, from its ID or its data
+	MAC          string // MAC the piece records, or empty
+	PINSalt      string // Set when the piece is locked with a PIN
+	PINCheck     string
+	WorkFactor   int // scrypt log2(N) the piece records, or 0
+
+	CatalogThreshold int    // Pieces needed to open the catalog, or 0
+	CatalogB64       string // Base64 encoded share of the catalog key
+
+	VSSB64 string // Base64 encoded VSS value, or empty
 }
 
 // ShareData is minimal data needed for combining.
 type ShareData struct {
-	Version   int
-	Index     int
-	Threshold int
-	DataB64   string
+	Version      int
+	Index        int
+	Threshold    int
+	Group        int // 0 unless the piece is from a grouped project
+	Groups       int
+	GroupsNeeded int
+	DataB64      string
+	Format       string
+	BundleID     string // Empty for pieces read from words or digits
+	MAC          string // Empty for pieces read from a QR code, words, or digits
 }
 
 // parseShare extracts a share from text content (which might be a full README.txt).
 // Uses core.ParseShare for the actual parsing, then converts to ShareInfo for JS.
 func parseShare(content string) (*ShareInfo, error) {
-	share, err := core.ParseShare([]byte(content))
+	infos, err := parseShares(content)
 	if err != nil {
 		return nil, err
 	}
+	return infos[0], nil
+}
 
-	// Verify checksum (core.ParseShare doesn't do this automatically since
-	// the Verify method exists separately, but we want to catch corruption early)
-	if err := share.Verify(); err != nil {
+// parseShares is parseShare for a friend who holds several pieces: it
+// returns every share block in content, in order.
+func parseShares(content string) ([]*ShareInfo, error) {
+	shares, err := core.ParseShares([]byte(content))
+	if err != nil {
 		return nil, err
 	}
 
-	return shareToInfo(share), nil
+	infos := make([]*ShareInfo, len(shares))
+	for i, share := range shares {
+		// Verify checksum (core.ParseShares doesn't do this automatically since
+		// the Verify method exists separately, but we want to catch corruption early)
+		if err := share.Verify(); err != nil {
+			return nil, err
+		}
+		if share.Secret {
+			return nil, core.ErrSecretPiece
+		}
+		infos[i] = shareToInfo(share)
+	}
+	return infos, nil
 }
 
-// parseCompactShare parses a compact-encoded share string.
+// parseCompactShare parses a compact-encoded share string, or the rm1
+// string printed under the QR code.
 func parseCompactShare(compact string) (*ShareInfo, error) {
-	share, err := core.ParseCompact(compact)
+	parse := core.ParseCompact
+	if core.IsBech32Share(compact) {
+		parse = core.ParseBech32Share
+	}
+	share, err := parse(compact)
 	if err != nil {
 		return nil, err
 	}
@@ -62,74 +109,353 @@ func parseCompactShare(compact string) (*ShareInfo, error) {
 	return shareToInfo(share), nil
 }
 
+// unlockShare unlocks the data of a piece locked with a PIN, returning it
+// base64 encoded. A wrong PIN is reported as core.ErrWrongPIN.
+func unlockShare(index int, dataB64, pinSalt, pinCheck, pin string) (string, error) {
+	data, err := base64.StdEncoding.DecodeString(dataB64)
+	if err != nil {
+		return "", fmt.Errorf("decoding share %d: %w", index, err)
+	}
+	share := &core.Share{Index: index, Data: data, PINSalt: pinSalt, PINCheck: pinCheck}
+	if err := share.UnlockPIN(pin); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(share.Data), nil
+}
+
+// parseSSKRShare parses an SSKR share pasted as a UR or as bytewords.
+// Its member index, counted from 1, stands in for the piece index.
+func parseSSKRShare(text string) (*ShareInfo, error) {
+	share, err := core.ParseSSKR(text)
+	if err != nil {
+		return nil, err
+	}
+	raw := share.Bytes()
+	return &ShareInfo{
+		Version:   2,
+		Index:     share.MemberIndex + 1,
+		Threshold: share.MemberThreshold,
+		Checksum:  core.HashBytes(raw),
+		DataB64:   base64.StdEncoding.EncodeToString(raw),
+		Format:    formatSSKR,
+	}, nil
+}
+
+// formatSSKR marks shares parsed by parseSSKRShare.
+const formatSSKR = "sskr"
+
 // shareToInfo converts a core.Share to a ShareInfo for JS interop.
 func shareToInfo(share *core.Share) *ShareInfo {
 	return &ShareInfo{
-		Version:   share.Version,
-		Index:     share.Index,
-		Total:     share.Total,
-		Threshold: share.Threshold,
-		Holder:    share.Holder,
-		Created:   share.Created.Format("2006-01-02T15:04:05Z07:00"),
-		Checksum:  share.Checksum,
-		DataB64:   base64.StdEncoding.EncodeToString(share.Data),
-		Compact:   share.CompactEncode(),
+		Version:      share.Version,
+		Index:        share.Index,
+		Total:        share.Total,
+		Threshold:    share.Threshold,
+		Holder:       share.Holder,
+		Group:        share.Group,
+		Groups:       share.Groups,
+		GroupsNeeded: share.GroupsNeeded,
+		Created:      share.Created.Format("2006-01-02T15:04:05Z07:00"),
+		Checksum:     share.Checksum,
+		DataB64:      base64.StdEncoding.EncodeToString(share.Data),
+		Compact:      share.CompactEncode(),
+		ReviewBy:     formatDate(share.ReviewBy),
+		Expires:      formatDate(share.Expires),
+		BundleID:     share.BundleID,
+		Bundle:       share.Fingerprint(),
+		MAC:          share.MAC,
+		PINSalt:      share.PINSalt,
+		PINCheck:     share.PINCheck,
+		WorkFactor:   share.WorkFactor,
+
+		CatalogThreshold: share.CatalogThreshold,
+		CatalogB64:       base64.StdEncoding.EncodeToString(share.CatalogData),
+
+		VSSB64: base64.StdEncoding.EncodeToString(share.VSS),
 	}
 }
 
+// openCatalog opens CATALOG.age, base64 encoded in recover.html, from the
+// catalog shares the pieces carry.
+func openCatalog(catalogB64 string, pieces []ShareInfo) (*core.Catalog, error) {
+	catalog, err := base64.StdEncoding.DecodeString(catalogB64)
+	if err != nil {
+		return nil, fmt.Errorf("decoding catalog: %w", err)
+	}
+	shares := make([]*core.Share, 0, len(pieces))
+	for _, p := range pieces {
+		data, err := base64.StdEncoding.DecodeString(p.CatalogB64)
+		if err != nil {
+			return nil, fmt.Errorf("decoding catalog share: %w", err)
+		}
+		shares = append(shares, &core.Share{Index: p.Index, CatalogThreshold: p.CatalogThreshold, CatalogData: data})
+	}
+	key, err := core.CombineCatalogKey(shares)
+	if err != nil {
+		return nil, err
+	}
+	defer core.Wipe(key)
+	return core.DecryptCatalog(catalog, key)
+}
+
+// checkVSS checks a piece's VSS value against the Feldman commitments
+// recover.html was personalized with.
+func checkVSS(commitments []string, index int, vssB64 string) error {
+	c, err := core.ParseVSSCommitments(commitments)
+	if err != nil {
+		return err
+	}
+	value, err := base64.StdEncoding.DecodeString(vssB64)
+	if err != nil {
+		return fmt.Errorf("decoding VSS value: %w", err)
+	}
+	return c.Verify(index, value)
+}
+
+// estimateUnlock measures scrypt on this device and returns how many
+// seconds unlocking a manifest sealed at workFactor should take here.
+func estimateUnlock(workFactor int) float64 {
+	return core.EstimateScrypt(core.BenchmarkScrypt(), workFactor).Seconds()
+}
+
+// formatDate writes a review or expiry date, or "" when it isn't set.
+func formatDate(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(core.DateFormat)
+}
+
 // combineShares combines multiple shares to recover the passphrase.
 // Uses core.Combine for the actual combination.
-func combineShares(shares []ShareData) (string, error) {
+// restricted applies the restricted crypto profile to the shares.
+// The caller wipes the passphrase once the manifest is open.
+func combineShares(shares []ShareData, restricted bool) (core.Secret, error) {
+	// SSKR shares combine on their own. A personalized recover.html always
+	// holds its friend's own piece, so other pieces are set aside, not refused.
+	var sskr []ShareData
+	for _, s := range shares {
+		if s.Format == formatSSKR {
+			sskr = append(sskr, s)
+		}
+	}
+	if len(sskr) > 0 {
+		if restricted {
+			return nil, fmt.Errorf("SSKR shares are outside the restricted crypto profile")
+		}
+		return combineSSKRShares(sskr)
+	}
+
 	if len(shares) < 2 {
-		return "", fmt.Errorf("need at least 2 shares, got %d", len(shares))
+		return nil, fmt.Errorf("need at least 2 shares, got %d", len(shares))
+	}
+
+	if restricted {
+		for _, s := range shares {
+			if err := core.CheckRestrictedShare(&core.Share{Version: s.Version, Index: s.Index}); err != nil {
+				return nil, err
+			}
+		}
 	}
 
 	// Validate all shares have the same version
 	for i := 1; i < len(shares); i++ {
 		if shares[i].Version != shares[0].Version {
-			return "", fmt.Errorf("share %d has different version (v%d vs v%d) — all shares must be from the same bundle", i+1, shares[i].Version, shares[0].Version)
+			return nil, fmt.Errorf("share %d has different version (v%d vs v%d) — all shares must be from the same bundle", i+1, shares[i].Version, shares[0].Version)
 		}
 	}
 
-	// Validate threshold is met (shares carry the threshold from parsing)
-	if shares[0].Threshold > 0 && len(shares) < shares[0].Threshold {
-		return "", fmt.Errorf("need at least %d shares to recover, got %d", shares[0].Threshold, len(shares))
+	// Pieces from another project or seal would only combine into garbage
+	bundled := make([]*core.Share, len(shares))
+	for i, s := range shares {
+		data, _ := base64.StdEncoding.DecodeString(s.DataB64)
+		bundled[i] = &core.Share{BundleID: s.BundleID, Data: data}
+	}
+	if err := core.CheckSameBundle(bundled); err != nil {
+		return nil, err
+	}
+
+	// Pieces of a grouped project combine group by group
+	for _, s := range shares {
+		if s.Groups > 0 {
+			return combineGroupShares(shares)
+		}
+	}
+
+	// Validate threshold is met (shares carry the threshold from parsing).
+	// Shares typed in as words may not know it, so use any share that does.
+	threshold := 0
+	for _, s := range shares {
+		if s.Threshold > 0 {
+			threshold = s.Threshold
+			break
+		}
+	}
+	if len(shares) < threshold {
+		return nil, fmt.Errorf("need at least %d shares to recover, got %d", threshold, len(shares))
 	}
 
-	// Convert to raw bytes for core.Combine
-	rawShares := make([][]byte, len(shares))
+	parsed := make([]*core.Share, len(shares))
 	for i, s := range shares {
 		data, err := base64.StdEncoding.DecodeString(s.DataB64)
 		if err != nil {
-			return "", fmt.Errorf("decoding share %d: %w", i+1, err)
+			return nil, fmt.Errorf("decoding share %d: %w", i+1, err)
 		}
-		rawShares[i] = data
+		parsed[i] = &core.Share{Index: s.Index, Data: data, MAC: s.MAC}
 	}
 
-	// Use core.Combine
-	secret, err := core.Combine(rawShares)
+	// A damaged or altered piece is named when spare pieces let it be found
+	secret, err := core.CombineAuthenticated(parsed, threshold)
+	var macErr *core.MACError
+	if errors.As(err, &macErr) {
+		return nil, err
+	}
 	if err != nil {
-		return "", fmt.Errorf("combining shares: %w", err)
+		return nil, fmt.Errorf("combining shares: %w", err)
 	}
+	defer core.Wipe(secret)
+
+	return core.RecoverPassphrase(secret, shares[0].Version), nil
+}
 
+// combineGroupShares recovers the passphrase from the pieces of a grouped
+// project.
+func combineGroupShares(shares []ShareData) (core.Secret, error) {
+	parsed := make([]*core.Share, len(shares))
+	for i, s := range shares {
+		data, err := base64.StdEncoding.DecodeString(s.DataB64)
+		if err != nil {
+			return nil, fmt.Errorf("decoding share %d: %w", i+1, err)
+		}
+		parsed[i] = &core.Share{
+			Version:      s.Version,
+			Index:        s.Index,
+			Threshold:    s.Threshold,
+			Group:        s.Group,
+			Groups:       s.Groups,
+			GroupsNeeded: s.GroupsNeeded,
+			Data:         data,
+			MAC:          s.MAC,
+		}
+	}
+	secret, err := core.CombineGroupShares(parsed)
+	if err != nil {
+		return nil, err
+	}
+	defer core.Wipe(secret)
+	if err := core.CheckMACs(parsed, secret); err != nil {
+		return nil, err
+	}
 	return core.RecoverPassphrase(secret, shares[0].Version), nil
 }
 
+// combineSSKRShares recovers the passphrase from SSKR shares.
+func combineSSKRShares(shares []ShareData) (core.Secret, error) {
+	parsed := make([]*core.SSKRShare, len(shares))
+	for i, s := range shares {
+		raw, err := base64.StdEncoding.DecodeString(s.DataB64)
+		if err != nil {
+			return nil, fmt.Errorf("decoding SSKR share %d: %w", i+1, err)
+		}
+		if parsed[i], err = core.ParseSSKRBytes(raw); err != nil {
+			return nil, fmt.Errorf("SSKR share %d: %w", i+1, err)
+		}
+	}
+	secret, err := core.SSKRCombine(parsed)
+	if err != nil {
+		return nil, fmt.Errorf("combining shares: %w", err)
+	}
+	defer core.Wipe(secret)
+	return core.RecoverPassphrase(secret, 2), nil
+}
+
 // decryptManifest decrypts age-encrypted data using a passphrase.
 // Uses core.DecryptBytes for the actual decryption.
-func decryptManifest(encryptedData []byte, passphrase string) ([]byte, error) {
-	return core.DecryptBytes(encryptedData, passphrase)
+// restricted refuses manifests outside the restricted crypto profile.
+func decryptManifest(encryptedData []byte, passphrase core.Secret, restricted bool) ([]byte, error) {
+	if restricted {
+		if err := core.CheckRestrictedManifest(encryptedData); err != nil {
+			return nil, err
+		}
+	}
+	return core.DecryptBytes(encryptedData, string(passphrase))
+}
+
+// manifestMemory returns how many bytes of memory scrypt takes to unlock
+// MANIFEST.age, from its header, the start of encryptedData; 0 when it
+// isn't locked with a passphrase.
+func manifestMemory(encryptedData []byte) (int64, error) {
+	info, err := core.InspectManifest(bytes.NewReader(encryptedData))
+	if err != nil {
+		return 0, err
+	}
+	if info.WorkFactor == 0 {
+		return 0, nil
+	}
+	return core.ScryptMemory(info.WorkFactor), nil
+}
+
+// timelocks holds the recovery-delay puzzles being solved, by handle, so the
+// page can step through one between calls and show progress.
+var (
+	timelocks    = map[int]*core.TimelockSolver{}
+	nextTimelock = 1
+)
+
+// startTimelock starts solving a TIMELOCK.json puzzle for the passphrase
+// the pieces rebuilt. It returns the solver's handle and how many squarings
+// the puzzle takes.
+func startTimelock(timelockJSON []byte, passphrase core.Secret) (int, *core.Timelock, error) {
+	tl, err := core.ParseTimelock(timelockJSON)
+	if err != nil {
+		return 0, nil, err
+	}
+	solver, err := tl.Solver(passphrase)
+	if err != nil {
+		return 0, nil, err
+	}
+	handle := nextTimelock
+	nextTimelock++
+	timelocks[handle] = solver
+	return handle, tl, nil
+}
+
+// stepTimelock does up to count more squarings of a puzzle. Once it's
+// solved, it returns the passphrase that opens MANIFEST.age and forgets the
+// puzzle.
+func stepTimelock(handle int, count uint64) (done, total uint64, passphrase core.Secret, err error) {
+	solver, ok := timelocks[handle]
+	if !ok {
+		return 0, 0, nil, fmt.Errorf("no recovery delay in progress")
+	}
+	solved := solver.Step(count)
+	done, total = solver.Progress()
+	if !solved {
+		return done, total, nil, nil
+	}
+	delete(timelocks, handle)
+	defer solver.Wipe()
+	passphrase, err = solver.Passphrase()
+	return done, total, passphrase, err
+}
+
+// extractTarGz extracts files from tar.gz data in memory, checking each
+// against the archive's index. Uses core.ExtractTarGzChecked for the actual
+// extraction.
+func extractTarGz(tarGzData []byte) ([]core.ExtractedFile, []core.IndexCheck, error) {
+	return core.ExtractTarGzChecked(bytes.NewReader(tarGzData))
 }
 
-// extractTarGz extracts files from tar.gz data in memory.
-// Uses core.ExtractTarGz for the actual extraction.
-func extractTarGz(tarGzData []byte) ([]core.ExtractedFile, error) {
-	return core.ExtractTarGz(tarGzData)
+// listTarGz lists the files in tar.gz data without extracting them, and
+// says whether they come from the archive's index.
+func listTarGz(tarGzData []byte) ([]core.IndexEntry, bool, error) {
+	return core.ListTarGz(bytes.NewReader(tarGzData))
 }
 
-// decodeShareWords converts 25 BIP39 words to raw share data bytes and share index.
-// Auto-detects the word list language. The first 24 words encode the data;
-// the 25th word packs 4 bits of index + 7 bits of checksum.
+// decodeShareWords converts 25 BIP39 words, or the words from an EFF list, to
+// raw share data bytes and share index. Auto-detects the word list. The first
+// 24 BIP39 words encode the data; the 25th word packs 4 bits of index + 7
+// bits of checksum.
 // Returns the decoded bytes, share index (0 if share >15), checksum, detected language, and any error.
 func decodeShareWords(words []string) ([]byte, int, string, string, error) {
 	data, index, lang, err := core.DecodeShareWordsAuto(words)
@@ -139,10 +465,54 @@ func decodeShareWords(words []string) ([]byte, int, string, string, error) {
 	return data, index, core.HashBytes(data), string(lang), nil
 }
 
+// decodeShareDigits converts the 18 digit groups to raw share data bytes and
+// share index, like decodeShareWords. Returns index 0 if the share was
+// numbered above 255.
+func decodeShareDigits(text string) ([]byte, int, string, error) {
+	data, index, err := core.DecodeShareDigits(text)
+	if err != nil {
+		return nil, 0, "", err
+	}
+	return data, index, core.HashBytes(data), nil
+}
+
+// joinQRChunks puts a QR code split over several back together, from the
+// text of each part in any order.
+func joinQRChunks(parts []string) (string, error) {
+	chunks := make([]*core.QRChunk, len(parts))
+	for i, part := range parts {
+		chunk, err := core.ParseQRChunk(part)
+		if err != nil {
+			return "", err
+		}
+		chunks[i] = chunk
+	}
+	return core.JoinQRChunks(chunks)
+}
+
+// joinVolumes puts MANIFEST.age back together from the volumes it was
+// split into, in any order, checking them against MANIFEST.age.sha256 when
+// it's given, and repairing them with MANIFEST.age.parity when that is. It
+// also returns how many blocks were repaired.
+func joinVolumes(parts []core.VolumePart, indexData, parity []byte) ([]byte, int, error) {
+	var index *core.VolumeIndex
+	if len(indexData) > 0 {
+		var err error
+		if index, err = core.ParseVolumeIndex(indexData); err != nil {
+			return nil, 0, err
+		}
+	}
+	return core.JoinVolumesWithParity(parts, index, parity)
+}
+
 // BundleContents represents extracted content from a bundle ZIP.
 type BundleContents struct {
-	Share    *ShareInfo // Parsed share from README.txt
-	Manifest []byte     // Raw MANIFEST.age content
+	Share    *ShareInfo   // Parsed share from README.txt
+	Extra    []*ShareInfo // The holder's further pieces, when they hold more than one
+	Manifest []byte       // Raw MANIFEST.age content
+	Repaired int          // Blocks of MANIFEST.age repaired with MANIFEST.age.parity
+	Decoy    []byte       // Raw DECOY.age content, when the project sealed a decoy
+	Timelock []byte       // Raw TIMELOCK.json content, when the project has a recovery delay
 }
 
 // extractBundle extracts share and manifest from a bundle ZIP file.
@@ -156,7 +526,8 @@ func extractBundle(zipData []byte) (*BundleContents, error) {
 	}
 
 	var readmeContent string
-	var manifestData []byte
+	var manifestData, decoyData, timelockData, volumeIndex, parity []byte
+	var volumes []core.VolumePart
 	var totalSize int64
 
 	for _, f := range r.File {
@@ -186,21 +557,45 @@ func extractBundle(zipData []byte) (*BundleContents, error) {
 			readmeContent = string(data)
 		case f.Name == "MANIFEST.age":
 			manifestData = data
+		case f.Name == "MANIFEST.age"+core.VolumeIndexSuffix:
+			volumeIndex = data
+		case f.Name == "MANIFEST.age"+core.ParitySuffix:
+			parity = data
+		case strings.HasPrefix(f.Name, "MANIFEST.age."):
+			volumes = append(volumes, core.VolumePart{Name: f.Name, Data: data})
+		case f.Name == core.DecoyFile:
+			decoyData = data
+		case f.Name == core.TimelockFile:
+			timelockData = data
 		}
 	}
 
 	if readmeContent == "" {
 		return nil, fmt.Errorf("README file not found in bundle")
 	}
+	repaired := 0
+	if manifestData == nil && len(volumes) > 0 {
+		if manifestData, repaired, err = joinVolumes(volumes, volumeIndex, parity); err != nil {
+			return nil, err
+		}
+	} else if manifestData != nil && parity != nil {
+		if manifestData, repaired, err = core.RepairParity(manifestData, parity); err != nil {
+			return nil, err
+		}
+	}
 
-	// Parse share from README
-	share, err := parseShare(readmeContent)
+	// Parse shares from README
+	shares, err := parseShares(readmeContent)
 	if err != nil {
 		return nil, fmt.Errorf("parsing share from README: %w", err)
 	}
 
 	return &BundleContents{
-		Share:    share,
+		Share:    shares[0],
+		Extra:    shares[1:],
 		Manifest: manifestData,
+		Repaired: repaired,
+		Decoy:    decoyData,
+		Timelock: timelockData,
 	}, nil
 }
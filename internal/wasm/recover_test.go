@@ -0,0 +1,538 @@
+//go:build js && wasm
+
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/eljojo/rememory/internal/core"
+)
+
+// toShareData turns a share parsed from any form into what combineShares
+// takes, as app.ts does.
+func toShareData(info *ShareInfo) ShareData {
+	return ShareData{Version: info.Version, Index: info.Index, Threshold: info.Threshold, Group: info.Group, Groups: info.Groups, GroupsNeeded: info.GroupsNeeded, DataB64: info.DataB64, Format: info.Format, BundleID: info.BundleID, MAC: info.MAC}
+}
+
+// readForm parses one piece the way recover.html does for each form a friend
+// might hand over: a pasted README.txt, a scanned QR code (whole or split
+// over several), a recovery link,
+// the rm1 string typed from under the QR, typed words or digits, or a
+// dropped bundle ZIP.
+func readForm(t *testing.T, form string, share *core.Share) ShareData {
+	t.Helper()
+	switch form {
+	case "pem":
+		info, err := parseShare("Hello\n\n" + share.Encode())
+		if err != nil {
+			t.Fatalf("parseShare: %v", err)
+		}
+		return toShareData(info)
+	case "qr", "url", "split":
+		compact := share.CompactEncode()
+		if form != "qr" {
+			link := "https://example.com/recover.html#share=" + url.QueryEscape(compact)
+			if form == "split" {
+				// A link too long for one code, scanned last part first
+				link = "https://example.com/" + strings.Repeat("family-archive/", 8) + "recover.html#share=" + url.QueryEscape(compact)
+				codes := core.SplitQR(link)
+				slices.Reverse(codes)
+				joined, err := joinQRChunks(codes)
+				if err != nil || joined != link {
+					t.Fatalf("joinQRChunks(%d codes) = %q, %v", len(codes), joined, err)
+				}
+			}
+			// app.ts reads the fragment and decodes it before parsing
+			var err error
+			if compact, err = url.QueryUnescape(strings.SplitN(link, "#share=", 2)[1]); err != nil {
+				t.Fatal(err)
+			}
+		}
+		info, err := parseCompactShare(compact)
+		if err != nil {
+			t.Fatalf("parseCompactShare: %v", err)
+		}
+		return toShareData(info)
+	case "bech32":
+		text, err := share.Bech32()
+		if err != nil {
+			t.Fatal(err)
+		}
+		// Typed from the printed page: in groups, and in capitals
+		info, err := parseCompactShare(strings.ToUpper(text[:20] + " " + text[20:]))
+		if err != nil {
+			t.Fatalf("parseCompactShare: %v", err)
+		}
+		return toShareData(info)
+	case "words":
+		words, err := share.WordsForLang("es")
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, index, _, _, err := decodeShareWords(words)
+		if err != nil {
+			t.Fatalf("decodeShareWords: %v", err)
+		}
+		// Words carry no threshold; app.ts takes it from other pieces, if any
+		return ShareData{Version: 2, Index: index, DataB64: base64.StdEncoding.EncodeToString(data)}
+	case "digits":
+		groups, err := share.Digits()
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, index, _, err := decodeShareDigits(strings.Join(groups, " "))
+		if err != nil {
+			t.Fatalf("decodeShareDigits: %v", err)
+		}
+		return ShareData{Version: 2, Index: index, DataB64: base64.StdEncoding.EncodeToString(data)}
+	case "zip":
+		var buf bytes.Buffer
+		zw := zip.NewWriter(&buf)
+		w, _ := zw.Create("README.txt")
+		w.Write([]byte("Hello\n\n" + share.Encode()))
+		zw.Close()
+		contents, err := extractBundle(buf.Bytes())
+		if err != nil || contents.Share == nil {
+			t.Fatalf("extractBundle: %+v, %v", contents, err)
+		}
+		return toShareData(contents.Share)
+	}
+	t.Fatalf("unknown form %q", form)
+	return ShareData{}
+}
+
+// TestMixedForms checks that recover.html combines every mix of share forms,
+// in any order, matching the command-line guarantee.
+func TestMixedForms(t *testing.T) {
+	secret := make([]byte, 32)
+	for i := range secret {
+		secret[i] = byte(i * 7)
+	}
+	want := base64.RawURLEncoding.EncodeToString(secret)
+	forms := []string{"pem", "qr", "url", "split", "bech32", "words", "digits", "zip"}
+
+	for _, tc := range []struct{ total, threshold int }{{3, 2}, {5, 3}} {
+		parts, err := core.Split(secret, tc.total, tc.threshold)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pieces := make([]*core.Share, tc.threshold)
+		for i := range pieces {
+			pieces[i] = core.NewShare(2, i+1, tc.total, tc.threshold, fmt.Sprintf("Friend %d", i+1), parts[i])
+		}
+
+		combos := [][]string{{}}
+		for range pieces {
+			var next [][]string
+			for _, c := range combos {
+				for _, f := range forms {
+					next = append(next, append(slices.Clone(c), f))
+				}
+			}
+			combos = next
+		}
+		for _, combo := range combos {
+			shares := make([]ShareData, len(combo))
+			for i, form := range combo {
+				shares[i] = readForm(t, form, pieces[i])
+			}
+			got, err := combineShares(shares, true)
+			if err != nil || string(got) != want {
+				t.Errorf("%d-of-%d/%s: got %q, %v", tc.threshold, tc.total, strings.Join(combo, "+"), string(got), err)
+			}
+		}
+	}
+}
+
+// TestGroupedShares checks that recover.html combines a grouped project's
+// pieces from the forms that record the group.
+func TestGroupedShares(t *testing.T) {
+	secret := make([]byte, 32)
+	for i := range secret {
+		secret[i] = byte(i * 3)
+	}
+	want := base64.RawURLEncoding.EncodeToString(secret)
+	groups := []core.GroupSpec{{Size: 3, Threshold: 2}, {Size: 2, Threshold: 1}}
+	parts, err := core.SplitGroups(secret, 2, groups)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var pieces []*core.Share
+	for g, group := range parts {
+		for _, data := range group {
+			s := core.NewShare(2, len(pieces)+1, 5, groups[g].Threshold, fmt.Sprintf("Friend %d", len(pieces)+1), data)
+			s.Group, s.Groups, s.GroupsNeeded = g+1, 2, 2
+			pieces = append(pieces, s)
+		}
+	}
+
+	for _, form := range []string{"pem", "qr", "url", "split"} {
+		shares := []ShareData{readForm(t, form, pieces[0]), readForm(t, "pem", pieces[2]), readForm(t, form, pieces[3])}
+		if got, err := combineShares(shares, false); err != nil || string(got) != want {
+			t.Errorf("%s: got %q, %v", form, string(got), err)
+		}
+	}
+
+	if _, err := combineShares([]ShareData{readForm(t, "pem", pieces[0]), readForm(t, "pem", pieces[1])}, false); err == nil || !strings.Contains(err.Error(), "need 2 complete groups") {
+		t.Errorf("one group: got %v, want a missing-group error", err)
+	}
+}
+
+// TestWeightedShares checks that one README holding two of a friend's
+// pieces counts for both of them.
+func TestWeightedShares(t *testing.T) {
+	secret := make([]byte, 32)
+	for i := range secret {
+		secret[i] = byte(i * 5)
+	}
+	want := base64.RawURLEncoding.EncodeToString(secret)
+	parts, err := core.Split(secret, 6, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	alice := []*core.Share{core.NewShare(2, 1, 6, 3, "Alice", parts[0]), core.NewShare(2, 6, 6, 3, "Alice", parts[5])}
+
+	infos, err := parseShares("Hello\n\n" + core.EncodeShares(alice))
+	if err != nil {
+		t.Fatalf("parseShares: %v", err)
+	}
+	if len(infos) != 2 || infos[1].Index != 6 {
+		t.Fatalf("got %d pieces, want Alice's 2", len(infos))
+	}
+	shares := []ShareData{toShareData(infos[0]), toShareData(infos[1]), readForm(t, "pem", core.NewShare(2, 2, 6, 3, "Bob", parts[1]))}
+	if got, err := combineShares(shares, false); err != nil || string(got) != want {
+		t.Errorf("got %q, %v", string(got), err)
+	}
+}
+
+func TestCombineSharesOtherBundle(t *testing.T) {
+	secret := make([]byte, 32)
+	parts, err := core.Split(secret, 3, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ours, _ := core.NewBundleID()
+	theirs, _ := core.NewBundleID()
+	alice := core.NewShare(2, 1, 3, 2, "Alice", parts[0])
+	alice.Bind(ours)
+	bob := core.NewShare(2, 2, 3, 2, "Bob", parts[1])
+	bob.Bind(theirs)
+
+	for _, form := range []string{"pem", "qr"} {
+		a, b := readForm(t, form, alice), readForm(t, form, bob)
+		if a.BundleID != ours {
+			t.Errorf("%s: bundle ID %q, want %q", form, a.BundleID, ours)
+		}
+		if _, err := combineShares([]ShareData{a, b}, false); err == nil || !strings.Contains(err.Error(), "same bundle") {
+			t.Errorf("%s: got %v, want a bundle error", form, err)
+		}
+	}
+
+	// Typed words carry no ID and combine with a piece that has one
+	words := readForm(t, "words", bob)
+	if _, err := combineShares([]ShareData{readForm(t, "pem", alice), words}, false); err != nil {
+		t.Errorf("pem + words: %v", err)
+	}
+}
+
+func TestCombineSharesDamaged(t *testing.T) {
+	secret := make([]byte, 32)
+	parts, err := core.Split(secret, 3, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	shares := make([]ShareData, len(parts))
+	for i, data := range parts {
+		share := core.NewShare(2, i+1, 3, 2, "Friend", data)
+		share.Authenticate(secret)
+		shares[i] = readForm(t, "pem", share)
+		if shares[i].MAC == "" {
+			t.Fatalf("piece %d: MAC not read", i+1)
+		}
+	}
+	// Piece 2 changed after sealing, checksum and all
+	damaged := slices.Clone(parts[1])
+	damaged[0] ^= 0x01
+	shares[1].DataB64 = base64.StdEncoding.EncodeToString(damaged)
+
+	if _, err := combineShares(shares[:2], false); err == nil || !strings.Contains(err.Error(), "add another piece") {
+		t.Errorf("two pieces: got %v, want a damaged-piece error", err)
+	}
+	if _, err := combineShares(shares, false); err == nil || !strings.Contains(err.Error(), "piece 2 is damaged") {
+		t.Errorf("three pieces: got %v, want piece 2 named", err)
+	}
+	if _, err := combineShares([]ShareData{shares[0], shares[2]}, false); err != nil {
+		t.Errorf("without piece 2: %v", err)
+	}
+}
+
+func TestUnlockShare(t *testing.T) {
+	secret := make([]byte, 32)
+	parts, err := core.Split(secret, 2, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	locked := core.NewShare(2, 1, 2, 2, "Alice", parts[0])
+	locked.Authenticate(secret)
+	if err := locked.LockWithPIN("482-107"); err != nil {
+		t.Fatal(err)
+	}
+	info, err := parseShare("Hello\n\n" + locked.Encode())
+	if err != nil {
+		t.Fatalf("parseShare: %v", err)
+	}
+	if info.PINSalt == "" || info.PINCheck == "" {
+		t.Fatalf("lock not read: %+v", info)
+	}
+
+	if _, err := unlockShare(info.Index, info.DataB64, info.PINSalt, info.PINCheck, "000-000"); !errors.Is(err, core.ErrWrongPIN) {
+		t.Errorf("wrong PIN: got %v", err)
+	}
+	dataB64, err := unlockShare(info.Index, info.DataB64, info.PINSalt, info.PINCheck, "482107")
+	if err != nil {
+		t.Fatalf("unlockShare: %v", err)
+	}
+	// app.ts swaps in the unlocked data before combining
+	unlocked := toShareData(info)
+	unlocked.DataB64 = dataB64
+	other := readForm(t, "pem", core.NewShare(2, 2, 2, 2, "Bob", parts[1]))
+	if _, err := combineShares([]ShareData{unlocked, other}, false); err != nil {
+		t.Errorf("combining the unlocked piece: %v", err)
+	}
+}
+
+func TestTimelockSteps(t *testing.T) {
+	seal, err := core.NewTimelock(time.Second, 3000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := seal.Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	passphrase := core.Secret("rebuilt from the pieces")
+	handle, tl, err := startTimelock(data, passphrase)
+	if err != nil {
+		t.Fatalf("startTimelock: %v", err)
+	}
+	if tl.Squarings != 3000 {
+		t.Fatalf("puzzle has %d squarings, want 3000", tl.Squarings)
+	}
+
+	// The page steps until the passphrase comes back
+	var opener core.Secret
+	for steps := 0; opener == nil; steps++ {
+		if steps > 3 {
+			t.Fatal("puzzle not solved after 4 steps of 1000")
+		}
+		var done uint64
+		if done, _, opener, err = stepTimelock(handle, 1000); err != nil {
+			t.Fatalf("stepTimelock: %v", err)
+		}
+		if opener == nil && done != uint64(steps+1)*1000 {
+			t.Errorf("step %d: %d squarings done", steps+1, done)
+		}
+	}
+	if want := seal.Lock(passphrase); !bytes.Equal(opener, want) {
+		t.Errorf("stepping gave %q, sealing gave %q", opener, want)
+	}
+	if _, _, _, err := stepTimelock(handle, 1000); err == nil {
+		t.Error("expected a solved puzzle to be forgotten")
+	}
+}
+
+func TestOpenCatalog(t *testing.T) {
+	secret := make([]byte, 32)
+	parts, err := core.Split(secret, 3, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pieces := []*core.Share{
+		core.NewShare(2, 1, 3, 3, "Alice", parts[0]),
+		core.NewShare(2, 2, 3, 3, "Bob", parts[1]),
+		core.NewShare(2, 3, 3, 3, "Carol", parts[2]),
+	}
+	key, err := core.NewCatalogKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := core.SplitCatalogKey(key, 2, pieces); err != nil {
+		t.Fatal(err)
+	}
+	encrypted, err := core.EncryptCatalog(&core.Catalog{Project: "Family", Files: []core.CatalogEntry{{Path: "manifest/will.pdf", Size: 10}}}, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	catalogB64 := base64.StdEncoding.EncodeToString(encrypted)
+
+	var infos []ShareInfo
+	for _, s := range pieces[:2] {
+		info, err := parseShare(s.Encode())
+		if err != nil {
+			t.Fatal(err)
+		}
+		infos = append(infos, *info)
+	}
+	catalog, err := openCatalog(catalogB64, infos)
+	if err != nil {
+		t.Fatalf("openCatalog: %v", err)
+	}
+	if catalog.Project != "Family" || len(catalog.Files) != 1 {
+		t.Errorf("catalog = %+v", catalog)
+	}
+	if _, err := openCatalog(catalogB64, infos[:1]); err == nil {
+		t.Error("opened the catalog with one piece")
+	}
+}
+
+func TestCombineSharesWordsFirst(t *testing.T) {
+	secret := make([]byte, 32)
+	parts, err := core.Split(secret, 5, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	words := readForm(t, "words", core.NewShare(2, 1, 5, 3, "Alice", parts[0]))
+	pem := readForm(t, "pem", core.NewShare(2, 2, 5, 3, "Bob", parts[1]))
+
+	// The threshold comes from the second piece even though words came first
+	if _, err := combineShares([]ShareData{words, pem}, false); err == nil || !strings.Contains(err.Error(), "need at least 3") {
+		t.Errorf("got %v, want a threshold error", err)
+	}
+}
+
+func TestSSKRShares(t *testing.T) {
+	secret := make([]byte, 32)
+	for i := range secret {
+		secret[i] = byte(i * 5)
+	}
+	sskr, err := core.SSKRSplit(secret, 2, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parts, err := core.Split(secret, 3, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// One pasted as a UR, one as bytewords, next to the page's own piece
+	ur, err := parseSSKRShare(sskr[2].UR())
+	if err != nil {
+		t.Fatal(err)
+	}
+	words, err := parseSSKRShare(sskr[0].Bytewords())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ur.Index != 3 || ur.Threshold != 2 || ur.Format != formatSSKR {
+		t.Errorf("UR share = %+v", ur)
+	}
+	own := readForm(t, "pem", core.NewShare(2, 1, 3, 2, "Alice", parts[0]))
+
+	got, err := combineShares([]ShareData{own, toShareData(ur), toShareData(words)}, false)
+	if err != nil || string(got) != base64.RawURLEncoding.EncodeToString(secret) {
+		t.Errorf("got %q, %v", string(got), err)
+	}
+	if _, err := combineShares([]ShareData{own, toShareData(ur)}, false); err == nil {
+		t.Error("expected an error with one SSKR share")
+	}
+	if _, err := combineShares([]ShareData{toShareData(ur), toShareData(words)}, true); err == nil {
+		t.Error("expected the restricted profile to refuse SSKR shares")
+	}
+}
+
+func TestSelfTest(t *testing.T) {
+	sample, err := fs.Sub(selfTestFiles, "selftest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	checks, err := selfTest(sample)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(checks) != 3 {
+		t.Fatalf("got %d checks, want 3", len(checks))
+	}
+	for _, c := range checks {
+		if c.Err != nil {
+			t.Errorf("%s: %v", c.Name, c.Err)
+		}
+	}
+
+	// A damaged archive fails only the decrypt check
+	golden, _ := fs.ReadFile(sample, "v2-golden.json")
+	manifest, _ := fs.ReadFile(sample, "MANIFEST.age")
+	damaged := bytes.Clone(manifest)
+	damaged[len(damaged)-1] ^= 1
+	checks, err = selfTest(fstest.MapFS{"v2-golden.json": {Data: golden}, "MANIFEST.age": {Data: damaged}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range checks {
+		if (c.Err != nil) != (c.Name == "decrypt") {
+			t.Errorf("%s: %v", c.Name, c.Err)
+		}
+	}
+
+	if _, err := selfTest(fstest.MapFS{"v2-golden.json": {Data: golden}}); err == nil {
+		t.Error("expected an error without MANIFEST.age")
+	}
+}
+
+// The sample bundle is a copy of the golden one in internal/core/testdata,
+// which can't be embedded from here.
+func TestSelfTestMatchesCoreTestdata(t *testing.T) {
+	for name, source := range map[string]string{
+		"v2-golden.json": "v2-golden.json",
+		"MANIFEST.age":   filepath.Join("v2-bundle", "MANIFEST.age"),
+	} {
+		want, err := os.ReadFile(filepath.Join("..", "core", "testdata", source))
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := selfTestFiles.ReadFile("selftest/" + name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, want) {
+			t.Errorf("selftest/%s differs from internal/core/testdata/%s", name, filepath.ToSlash(source))
+		}
+	}
+}
+
+func TestManifestMemory(t *testing.T) {
+	manifest, err := selfTestFiles.ReadFile("selftest/MANIFEST.age")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Only the header is needed, which is all the page hands over
+	need, err := manifestMemory(manifest[:min(len(manifest), 64*1024)])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if need != 256<<20 {
+		t.Errorf("got %d bytes, want 256 MiB for N=2^18", need)
+	}
+	if _, err := manifestMemory([]byte("not an age file")); err == nil {
+		t.Error("expected an error for something that isn't MANIFEST.age")
+	}
+
+	if !canSpareMemory(1 << 20) {
+		t.Error("expected 1 MiB to be spared")
+	}
+	if canSpareMemory(8 << 30) {
+		t.Error("expected more than a WebAssembly memory holds to be refused")
+	}
+}
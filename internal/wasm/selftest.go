@@ -0,0 +1,203 @@
+//go:build js && wasm
+
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"embed"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"strings"
+
+	"github.com/eljojo/rememory/internal/core"
+	"golang.org/x/crypto/scrypt"
+)
+
+// A bundle sealed by an earlier release, copied from internal/core/testdata:
+// v2-golden.json holds its pieces in every form and the files it opens, and
+// MANIFEST.age is the archive itself. The tests keep the copies in step.
+//
+//go:embed selftest
+var selfTestFiles embed.FS
+
+// Known answers for what recovery rests on: SHA-256 of "abc" (FIPS 180-2),
+// and scrypt of "password" with salt "NaCl", N=1024, r=8, p=16 (RFC 7914).
+const (
+	katSHA256 = "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad"
+	katScrypt = "fdbabe1c9d3472007856e7190d01e9fe7c6ad7cbc8237830e77376634b373162" +
+		"2eaf30d92e22a3886ff109279d9830dac727afb94a83ee6d8360cbdfa2cc0640"
+)
+
+// selfTestCheck is one step of the self-test.
+type selfTestCheck struct {
+	Name string // "kat", "share" or "decrypt", which the page puts in words
+	Err  error  // Why it failed; nil when it passed
+}
+
+// selfTestBundle is the part of v2-golden.json the self-test reads.
+type selfTestBundle struct {
+	Passphrase string `json:"passphrase"`
+	Threshold  int    `json:"threshold"`
+	Shares     []struct {
+		Index   int    `json:"index"`
+		DataHex string `json:"data_hex"`
+		PEM     string `json:"pem"`
+		Compact string `json:"compact"`
+		Words   string `json:"words"`
+	} `json:"shares"`
+	Manifest struct {
+		Files map[string]string `json:"files"`
+	} `json:"manifest"`
+}
+
+// selfTest checks that this copy of the page still recovers, using only the
+// sample bundle in fsys: the known answers, reading the sample's pieces in
+// each form a friend might hand over, and opening its MANIFEST.age with
+// them the way a real recovery does. Nothing is sent anywhere; a holder can
+// run it years after saving the page, before it's needed.
+func selfTest(fsys fs.FS) ([]selfTestCheck, error) {
+	data, err := fs.ReadFile(fsys, "v2-golden.json")
+	if err != nil {
+		return nil, fmt.Errorf("reading the sample bundle: %w", err)
+	}
+	var bundle selfTestBundle
+	if err := json.Unmarshal(data, &bundle); err != nil {
+		return nil, fmt.Errorf("reading the sample bundle: %w", err)
+	}
+	if bundle.Threshold < 2 || len(bundle.Shares) < bundle.Threshold {
+		return nil, fmt.Errorf("the sample bundle has %d pieces of %d needed", len(bundle.Shares), bundle.Threshold)
+	}
+	manifest, err := fs.ReadFile(fsys, "MANIFEST.age")
+	if err != nil {
+		return nil, fmt.Errorf("reading the sample bundle: %w", err)
+	}
+
+	return []selfTestCheck{
+		{Name: "kat", Err: selfTestKnownAnswers(&bundle)},
+		{Name: "share", Err: selfTestShares(&bundle)},
+		{Name: "decrypt", Err: selfTestDecrypt(&bundle, manifest)},
+	}, nil
+}
+
+// selfTestKnownAnswers checks SHA-256 and scrypt against their published
+// answers, and that the sample's first pieces combine into its passphrase.
+func selfTestKnownAnswers(b *selfTestBundle) error {
+	if sum := sha256.Sum256([]byte("abc")); hex.EncodeToString(sum[:]) != katSHA256 {
+		return fmt.Errorf("SHA-256 gave the wrong answer")
+	}
+	key, err := scrypt.Key([]byte("password"), []byte("NaCl"), 1024, 8, 16, 64)
+	if err != nil {
+		return fmt.Errorf("scrypt: %w", err)
+	}
+	if hex.EncodeToString(key) != katScrypt {
+		return fmt.Errorf("scrypt gave the wrong answer")
+	}
+
+	parts := make([][]byte, b.Threshold)
+	for i := range parts {
+		if parts[i], err = hex.DecodeString(b.Shares[i].DataHex); err != nil {
+			return fmt.Errorf("piece %d: %w", b.Shares[i].Index, err)
+		}
+	}
+	secret, err := core.Combine(parts)
+	if err != nil {
+		return err
+	}
+	defer core.Wipe(secret)
+	passphrase := core.RecoverPassphrase(secret, 2)
+	defer passphrase.Wipe()
+	if string(passphrase) != b.Passphrase {
+		return fmt.Errorf("combining pieces gave the wrong passphrase")
+	}
+	return nil
+}
+
+// selfTestShares reads every sample piece as a README.txt, as the string
+// under its QR code, and as its words, and checks each gives its data.
+func selfTestShares(b *selfTestBundle) error {
+	for _, s := range b.Shares {
+		want, err := hex.DecodeString(s.DataHex)
+		if err != nil {
+			return fmt.Errorf("piece %d: %w", s.Index, err)
+		}
+
+		info, err := parseShare(s.PEM)
+		if err != nil {
+			return fmt.Errorf("piece %d as text: %w", s.Index, err)
+		}
+		if info.DataB64 != base64.StdEncoding.EncodeToString(want) || info.Index != s.Index {
+			return fmt.Errorf("piece %d as text: read the wrong data", s.Index)
+		}
+
+		info, err = parseCompactShare(s.Compact)
+		if err != nil {
+			return fmt.Errorf("piece %d from its QR code: %w", s.Index, err)
+		}
+		if info.DataB64 != base64.StdEncoding.EncodeToString(want) || info.Index != s.Index {
+			return fmt.Errorf("piece %d from its QR code: read the wrong data", s.Index)
+		}
+
+		data, index, _, _, err := decodeShareWords(strings.Fields(s.Words))
+		if err != nil {
+			return fmt.Errorf("piece %d as words: %w", s.Index, err)
+		}
+		if !bytes.Equal(data, want) || index != s.Index {
+			return fmt.Errorf("piece %d as words: read the wrong data", s.Index)
+		}
+	}
+	return nil
+}
+
+// selfTestDecrypt combines the sample's last pieces as recovery does,
+// opens MANIFEST.age with the passphrase, and compares what comes out with
+// the files it was sealed with.
+func selfTestDecrypt(b *selfTestBundle, manifest []byte) error {
+	shares := make([]ShareData, b.Threshold)
+	for i, s := range b.Shares[len(b.Shares)-b.Threshold:] {
+		info, err := parseShare(s.PEM)
+		if err != nil {
+			return fmt.Errorf("piece %d: %w", s.Index, err)
+		}
+		shares[i] = ShareData{Version: info.Version, Index: info.Index, Threshold: info.Threshold, DataB64: info.DataB64}
+	}
+	passphrase, err := combineShares(shares, false)
+	if err != nil {
+		return err
+	}
+	defer passphrase.Wipe()
+
+	if need, err := manifestMemory(manifest); err == nil && need > 0 && !canSpareMemory(need) {
+		return fmt.Errorf("this device can't spare the %dM of memory unlocking takes", need>>20)
+	}
+	archive, err := decryptManifest(manifest, passphrase, false)
+	if err != nil {
+		return fmt.Errorf("decrypting: %w", err)
+	}
+	files, _, err := extractTarGz(archive)
+	if err != nil {
+		return fmt.Errorf("extracting: %w", err)
+	}
+
+	found := 0
+	for _, f := range files {
+		if f.Dir {
+			continue
+		}
+		want, ok := b.Manifest.Files[f.Name]
+		if !ok {
+			return fmt.Errorf("unexpected file %s", f.Name)
+		}
+		if string(f.Data) != want {
+			return fmt.Errorf("%s doesn't match what was sealed", f.Name)
+		}
+		found++
+	}
+	if found != len(b.Manifest.Files) {
+		return fmt.Errorf("got %d files, want %d", found, len(b.Manifest.Files))
+	}
+	return nil
+}
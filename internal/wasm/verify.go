@@ -0,0 +1,243 @@
+//go:build js && wasm
+
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"io"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+	"syscall/js"
+
+	"github.com/eljojo/rememory/internal/core"
+	"github.com/eljojo/rememory/internal/translations"
+)
+
+// VerifyExpected is what VERIFY.html knows about one friend's bundle:
+// checksums only, never the piece itself.
+type VerifyExpected struct {
+	ShareChecksum string
+	Files         map[string]string // File name in the bundle → "sha256:..."
+}
+
+// FileCheck is the result of checking one bundle file.
+type FileCheck struct {
+	Name   string
+	Status string // "ok", "changed", "missing", or "unexpected"
+}
+
+// verifyExpected reads the expected checksums embedded in VERIFY.html.
+func verifyExpected() (*VerifyExpected, error) {
+	v := js.Global().Get("VERIFY")
+	if v.Type() != js.TypeObject {
+		return nil, fmt.Errorf("this page has no verification data")
+	}
+	exp := &VerifyExpected{
+		ShareChecksum: v.Get("shareChecksum").String(),
+		Files:         make(map[string]string),
+	}
+	files := v.Get("files")
+	keys := js.Global().Get("Object").Call("keys", files)
+	for i := 0; i < keys.Length(); i++ {
+		name := keys.Index(i).String()
+		exp.Files[name] = files.Get(name).String()
+	}
+	return exp, nil
+}
+
+// verifyPiece checks that text holds this friend's piece. The text can be a
+// README.txt or share file, a compact share or recovery URL, the rm1 string,
+// or the recovery words.
+func verifyPiece(text string, exp *VerifyExpected) (bool, error) {
+	text = strings.TrimSpace(text)
+
+	var data []byte
+	switch {
+	case strings.Contains(text, core.ShareBegin):
+		share, err := core.ParseShare([]byte(text))
+		if err != nil {
+			return false, err
+		}
+		if err := share.Verify(); err != nil {
+			return false, err
+		}
+		data = share.Data
+	case core.IsBech32Share(text):
+		share, err := core.ParseBech32Share(text)
+		if err != nil {
+			return false, err
+		}
+		data = share.Data
+	case strings.Contains(text, "#share="), strings.HasPrefix(text, "RM"):
+		if _, fragment, ok := strings.Cut(text, "#share="); ok {
+			unescaped, err := url.QueryUnescape(fragment)
+			if err != nil {
+				return false, fmt.Errorf("invalid recovery link: %w", err)
+			}
+			text = unescaped
+		}
+		share, err := core.ParseCompact(text)
+		if err != nil {
+			return false, err
+		}
+		data = share.Data
+	default:
+		words := strings.Fields(text)
+		if !core.IsShareWordCount(len(words)) {
+			return false, fmt.Errorf("not a README.txt, a compact piece, an rm1 string, or 25 recovery words")
+		}
+		decoded, _, _, _, err := decodeShareWords(words)
+		if err != nil {
+			return false, err
+		}
+		data = decoded
+	}
+
+	return core.VerifyHash(core.HashBytes(data), exp.ShareChecksum), nil
+}
+
+// verifyBundleFiles checks dropped files against the bundle's recorded
+// checksums. A bundle ZIP is opened and its files checked instead. When a ZIP
+// was given, files it lacks are reported missing; loose files are checked
+// one by one, so absent ones are simply skipped.
+func verifyBundleFiles(files map[string][]byte, exp *VerifyExpected) ([]FileCheck, *bool, error) {
+	fromZip := false
+	contents := make(map[string][]byte)
+	for name, data := range files {
+		if !strings.EqualFold(path.Ext(name), ".zip") {
+			contents[path.Base(name)] = data
+			continue
+		}
+		entries, err := readZipFiles(data)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: %w", name, err)
+		}
+		for entry, content := range entries {
+			contents[entry] = content
+		}
+		fromZip = true
+	}
+
+	var checks []FileCheck
+	var pieceOK *bool
+	for name, data := range contents {
+		status := "unexpected"
+		if want, ok := exp.Files[name]; ok {
+			status = "changed"
+			if core.VerifyHash(core.HashBytes(data), want) {
+				status = "ok"
+			}
+		}
+		checks = append(checks, FileCheck{Name: name, Status: status})
+
+		if translations.IsReadmeFile(name, ".txt") {
+			ok, err := verifyPiece(string(data), exp)
+			ok = ok && err == nil
+			pieceOK = &ok
+		}
+	}
+	if fromZip {
+		for name := range exp.Files {
+			if _, ok := contents[name]; !ok {
+				checks = append(checks, FileCheck{Name: name, Status: "missing"})
+			}
+		}
+	}
+
+	sort.Slice(checks, func(i, j int) bool { return checks[i].Name < checks[j].Name })
+	return checks, pieceOK, nil
+}
+
+// readZipFiles reads every file in a ZIP, with the same size limits as
+// extractBundle.
+func readZipFiles(zipData []byte) (map[string][]byte, error) {
+	r, err := zip.NewReader(bytes.NewReader(zipData), int64(len(zipData)))
+	if err != nil {
+		return nil, fmt.Errorf("opening zip: %w", err)
+	}
+
+	files := make(map[string][]byte)
+	var totalSize int64
+	for _, f := range r.File {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, fmt.Errorf("opening %s: %w", f.Name, err)
+		}
+		data, err := io.ReadAll(io.LimitReader(rc, core.MaxFileSize+1))
+		rc.Close()
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %w", f.Name, err)
+		}
+		if int64(len(data)) > core.MaxFileSize {
+			return nil, fmt.Errorf("file %s exceeds maximum allowed size (%d bytes)", f.Name, core.MaxFileSize)
+		}
+		totalSize += int64(len(data))
+		if totalSize > core.MaxTotalSize {
+			return nil, fmt.Errorf("bundle exceeds maximum total size (%d bytes)", core.MaxTotalSize)
+		}
+		files[f.Name] = data
+	}
+	return files, nil
+}
+
+// verifyPieceJS checks pasted text against this friend's piece.
+// Args: text (string)
+// Returns: { match: bool, error: string|null }
+func verifyPieceJS(this js.Value, args []js.Value) any {
+	if len(args) < 1 {
+		return errorResult("missing text argument")
+	}
+	exp, err := verifyExpected()
+	if err != nil {
+		return errorResult(err.Error())
+	}
+	match, err := verifyPiece(args[0].String(), exp)
+	if err != nil {
+		return errorResult(err.Error())
+	}
+	return js.ValueOf(map[string]any{"match": match, "error": nil})
+}
+
+// verifyBundleFilesJS checks dropped bundle files against their checksums.
+// Args: files (array of { name: string, data: Uint8Array })
+// Returns: { files: [{ name, status }], piece: bool|null, error: string|null }
+func verifyBundleFilesJS(this js.Value, args []js.Value) any {
+	if len(args) < 1 {
+		return errorResult("missing files argument")
+	}
+	exp, err := verifyExpected()
+	if err != nil {
+		return errorResult(err.Error())
+	}
+
+	files := make(map[string][]byte)
+	for i := 0; i < args[0].Length(); i++ {
+		f := args[0].Index(i)
+		jsData := f.Get("data")
+		data := make([]byte, jsData.Get("length").Int())
+		js.CopyBytesToGo(data, jsData)
+		files[f.Get("name").String()] = data
+	}
+
+	checks, pieceOK, err := verifyBundleFiles(files, exp)
+	if err != nil {
+		return errorResult(err.Error())
+	}
+
+	jsChecks := make([]any, len(checks))
+	for i, c := range checks {
+		jsChecks[i] = map[string]any{"name": c.Name, "status": c.Status}
+	}
+	var piece any
+	if pieceOK != nil {
+		piece = *pieceOK
+	}
+	return js.ValueOf(map[string]any{"files": jsChecks, "piece": piece, "error": nil})
+}